@@ -9,14 +9,19 @@ import (
 	"os/signal"
 	"syscall"
 	"time"
+	// 自動化ルールのタイムゾーンをOSのタイムゾーンデータに依存せず解決する
+	_ "time/tzdata"
 
 	"nocode-app/backend/internal/config"
 	"nocode-app/backend/internal/database"
 	"nocode-app/backend/internal/handlers"
+	"nocode-app/backend/internal/mail"
 	"nocode-app/backend/internal/middleware"
+	"nocode-app/backend/internal/realtime"
 	"nocode-app/backend/internal/repositories"
 	"nocode-app/backend/internal/router"
 	"nocode-app/backend/internal/services"
+	"nocode-app/backend/internal/storage"
 	"nocode-app/backend/internal/utils"
 )
 
@@ -48,6 +53,18 @@ func main() {
 		log.Println("外部データソース機能は利用できません。ENCRYPTION_KEY環境変数を設定してください。")
 	}
 
+	// 添付ファイルの保存先の初期化
+	fileStorage, localStorage, err := newFileStorage(&cfg.Storage)
+	if err != nil {
+		log.Fatalf("添付ファイルの保存先の初期化に失敗しました: %v", err)
+	}
+
+	// レポートの配信に使うメールの送信先の初期化
+	mailSender, err := newMailSender(&cfg.Mail)
+	if err != nil {
+		log.Fatalf("メールの送信先の初期化に失敗しました: %v", err)
+	}
+
 	// ユーティリティの初期化
 	jwtManager := utils.NewJWTManager(cfg.JWT.Secret, cfg.JWT.ExpiryHours)
 	validator := utils.NewValidator()
@@ -62,18 +79,49 @@ func main() {
 	dataSourceRepo := repositories.NewDataSourceRepository(db)
 	externalQuery := repositories.NewExternalQueryExecutor()
 	dashboardWidgetRepo := repositories.NewDashboardWidgetRepository(db)
+	groupRepo := repositories.NewGroupRepository(db)
+	appPermissionRepo := repositories.NewAppPermissionRepository(db)
+	recordRevisionRepo := repositories.NewRecordRevisionRepository(db)
+	webhookRepo := repositories.NewWebhookRepository(db)
+	webhookDeliveryRepo := repositories.NewWebhookDeliveryRepository(db)
+	automationRuleRepo := repositories.NewAutomationRuleRepository(db)
+	automationRunRepo := repositories.NewAutomationRunRepository(db)
+	advisoryLocker := repositories.NewAdvisoryLocker(db)
+	attachmentRepo := repositories.NewAttachmentRepository(db)
+	appIndexRepo := repositories.NewAppIndexRepository(db)
+	deletedRecordRepo := repositories.NewDeletedRecordRepository(db)
+	savedReportRepo := repositories.NewSavedReportRepository(db)
+	reportDeliveryRepo := repositories.NewReportDeliveryRepository(db)
+	transactor := repositories.NewTransactor(db)
 
 	// サービスの初期化
 	authService := services.NewAuthService(userRepo, jwtManager)
-	appService := services.NewAppService(appRepo, fieldRepo, dynamicQuery, dataSourceRepo)
-	fieldService := services.NewFieldService(fieldRepo, appRepo, dynamicQuery)
-	recordService := services.NewRecordService(appRepo, fieldRepo, dynamicQuery, dataSourceRepo, externalQuery)
-	viewService := services.NewViewService(viewRepo, appRepo)
-	chartService := services.NewChartService(chartRepo, appRepo, fieldRepo, dynamicQuery, dataSourceRepo, externalQuery)
+	permissionService := services.NewPermissionService(appPermissionRepo, groupRepo, appRepo, userRepo)
+	groupService := services.NewGroupService(groupRepo, userRepo)
+	// Webhookの送信先は登録時と送信時の両方で内部ネットワークのアドレスでないことを確認する
+	webhookGuard := services.NewWebhookAddressGuard(cfg.Webhook.AllowPrivateNetworks)
+	webhookService := services.NewWebhookService(webhookRepo, webhookDeliveryRepo, appRepo, webhookGuard)
+	// レコード・フィールド・アプリの変更はWebhookに加えて、PostgreSQLの通知を通じて全サーバーの購読者に配信する
+	realtimeBroker := realtime.NewBroker(realtime.NewPostgresNotifier(db))
+	eventPublisher := services.NewEventPublisher(webhookService, realtimeBroker)
+	attachmentService := services.NewAttachmentService(attachmentRepo, appRepo, fieldRepo, dynamicQuery, permissionService, fileStorage)
+	appService := services.NewAppService(appRepo, fieldRepo, dynamicQuery, dataSourceRepo, transactor, permissionService, eventPublisher, attachmentService)
+	fieldService := services.NewFieldService(fieldRepo, appRepo, dynamicQuery, permissionService, transactor, eventPublisher, attachmentService)
+	automationService := services.NewAutomationService(automationRuleRepo, automationRunRepo, appRepo, fieldRepo, dynamicQuery, recordRevisionRepo, webhookRepo, transactor, eventPublisher, permissionService, attachmentService)
+	recordService := services.NewRecordService(appRepo, fieldRepo, dynamicQuery, dataSourceRepo, externalQuery, permissionService, recordRevisionRepo, transactor, eventPublisher, automationService, attachmentService)
+	viewService := services.NewViewService(viewRepo, appRepo, permissionService)
+	chartService := services.NewChartService(chartRepo, appRepo, fieldRepo, dynamicQuery, dataSourceRepo, externalQuery, permissionService)
+	reportService := services.NewReportService(fieldRepo, dynamicQuery, dataSourceRepo, externalQuery, permissionService)
+	savedReportService := services.NewSavedReportService(savedReportRepo, reportDeliveryRepo, appRepo, fieldRepo, userRepo, chartService, reportService, recordService, permissionService, mailSender)
 	userService := services.NewUserService(userRepo)
 	dashboardService := services.NewDashboardService(userRepo, appRepo, dynamicQuery)
-	dashboardWidgetService := services.NewDashboardWidgetService(dashboardWidgetRepo, appRepo)
+	dashboardWidgetService := services.NewDashboardWidgetService(dashboardWidgetRepo, appRepo, permissionService)
 	dataSourceService := services.NewDataSourceService(dataSourceRepo, externalQuery)
+	globalSearchService := services.NewGlobalSearchService(appRepo, dynamicQuery, permissionService)
+	indexService := services.NewIndexService(appIndexRepo, appRepo, fieldRepo, viewRepo, dynamicQuery, permissionService)
+	trashService := services.NewTrashService(appRepo, fieldRepo, deletedRecordRepo, recordRevisionRepo, dynamicQuery, permissionService, eventPublisher, attachmentService, cfg.Trash.Retention)
+	appPackageService := services.NewAppPackageService(appRepo, fieldRepo, viewRepo, chartRepo, dynamicQuery, permissionService, appService, recordService)
+	realtimeService := services.NewRealtimeService(appRepo, permissionService, realtimeBroker, jwtManager)
 
 	// ハンドラーの初期化
 	authHandler := handlers.NewAuthHandler(authService, validator)
@@ -82,10 +130,23 @@ func main() {
 	recordHandler := handlers.NewRecordHandler(recordService, validator)
 	viewHandler := handlers.NewViewHandler(viewService, validator)
 	chartHandler := handlers.NewChartHandler(chartService, validator)
+	reportHandler := handlers.NewReportHandler(reportService, validator)
+	savedReportHandler := handlers.NewSavedReportHandler(savedReportService, validator)
+	realtimeHandler := handlers.NewRealtimeHandler(realtimeService)
 	userHandler := handlers.NewUserHandler(userService, validator)
 	dashboardHandler := handlers.NewDashboardHandler(dashboardService)
 	dashboardWidgetHandler := handlers.NewDashboardWidgetHandler(dashboardWidgetService, validator)
 	dataSourceHandler := handlers.NewDataSourceHandler(dataSourceService, validator)
+	permissionHandler := handlers.NewPermissionHandler(permissionService, validator)
+	groupHandler := handlers.NewGroupHandler(groupService, validator)
+	webhookHandler := handlers.NewWebhookHandler(webhookService, validator)
+	automationHandler := handlers.NewAutomationHandler(automationService, validator)
+	attachmentHandler := handlers.NewAttachmentHandler(attachmentService)
+	fileHandler := handlers.NewFileHandler(localStorage)
+	searchHandler := handlers.NewSearchHandler(globalSearchService)
+	indexHandler := handlers.NewIndexHandler(indexService, validator)
+	trashHandler := handlers.NewTrashHandler(trashService)
+	appPackageHandler := handlers.NewAppPackageHandler(appPackageService, validator)
 
 	// ミドルウェアの初期化
 	authMiddleware := middleware.NewAuthMiddleware(jwtManager)
@@ -110,6 +171,19 @@ func main() {
 		dashboardHandler,
 		dashboardWidgetHandler,
 		dataSourceHandler,
+		permissionHandler,
+		groupHandler,
+		webhookHandler,
+		automationHandler,
+		attachmentHandler,
+		fileHandler,
+		searchHandler,
+		indexHandler,
+		trashHandler,
+		appPackageHandler,
+		reportHandler,
+		savedReportHandler,
+		realtimeHandler,
 	)
 
 	// ルートの設定
@@ -122,6 +196,8 @@ func main() {
 		ReadTimeout:  cfg.Server.ReadTimeout,
 		WriteTimeout: cfg.Server.WriteTimeout,
 	}
+	// 購読中の接続はシャットダウンで閉じないため、購読を終了して応答を返させる
+	server.RegisterOnShutdown(realtimeBroker.Close)
 
 	// サーバーをgoroutineで起動
 	go func() {
@@ -131,6 +207,40 @@ func main() {
 		}
 	}()
 
+	// Webhook配信ワーカー、自動化ルールのスケジューラー、添付ファイルの削除処理、ごみ箱の完全な削除処理、レポートの定期配信、リアルタイム通知の受信をgoroutineで起動
+	// ユーザーのリクエストによらない処理のため、システム内部の呼び出しとして実行する
+	workerCtx, stopWorker := context.WithCancel(services.WithSystemCall(context.Background()))
+	workerDone := make(chan struct{})
+	schedulerDone := make(chan struct{})
+	cleanerDone := make(chan struct{})
+	purgerDone := make(chan struct{})
+	reportSchedulerDone := make(chan struct{})
+	listenerDone := make(chan struct{})
+	go func() {
+		defer close(workerDone)
+		runWebhookWorker(workerCtx, services.NewWebhookWorker(webhookDeliveryRepo, webhookGuard), 5*time.Second)
+	}()
+	go func() {
+		defer close(schedulerDone)
+		runAutomationScheduler(workerCtx, services.NewAutomationScheduler(automationService, advisoryLocker), 30*time.Second)
+	}()
+	go func() {
+		defer close(cleanerDone)
+		runAttachmentCleaner(workerCtx, services.NewAttachmentCleaner(attachmentRepo, fileStorage), 10*time.Minute)
+	}()
+	go func() {
+		defer close(purgerDone)
+		runTrashPurger(workerCtx, services.NewTrashPurger(trashService, advisoryLocker), time.Hour)
+	}()
+	go func() {
+		defer close(reportSchedulerDone)
+		runReportScheduler(workerCtx, services.NewReportScheduler(savedReportService, advisoryLocker), time.Minute)
+	}()
+	go func() {
+		defer close(listenerDone)
+		realtime.ListenPostgres(workerCtx, cfg.DB.DSN(), realtimeBroker)
+	}()
+
 	// 割り込みシグナルを待機
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
@@ -146,6 +256,15 @@ func main() {
 		log.Printf("サーバーの強制シャットダウン: %v", err)
 	}
 
+	// 送信中の配信は次回起動時に再送される
+	stopWorker()
+	<-workerDone
+	<-schedulerDone
+	<-cleanerDone
+	<-purgerDone
+	<-reportSchedulerDone
+	<-listenerDone
+
 	log.Println("サーバーを停止しました")
 }
 
@@ -166,3 +285,162 @@ func waitForDB(cfg *config.DBConfig, maxAttempts int) error {
 	}
 	return fmt.Errorf("%d回の試行後に接続に失敗しました: %w", maxAttempts, lastErr)
 }
+
+// runWebhookWorker コンテキストがキャンセルされるまで、一定間隔でWebhookの配信キューを処理する
+// 送信時刻を過ぎた配信が残っている間は待たずに続けて処理する
+func runWebhookWorker(ctx context.Context, worker *services.WebhookWorker, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		n, err := worker.ProcessDue(ctx)
+		if err != nil && ctx.Err() == nil {
+			log.Printf("Webhook配信の処理に失敗しました: %v", err)
+		}
+		if err == nil && n > 0 {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// runAutomationScheduler コンテキストがキャンセルされるまで、一定間隔で実行時刻を過ぎた自動化ルールを実行する
+// 複数のサーバーで起動した場合も、アドバイザリーロックを取得できた1台だけが実行する
+func runAutomationScheduler(ctx context.Context, scheduler *services.AutomationScheduler, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		n, err := scheduler.ProcessDue(ctx, time.Now())
+		if err != nil && ctx.Err() == nil {
+			log.Printf("自動化ルールの定期実行に失敗しました: %v", err)
+		}
+		if err == nil && n > 0 {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// runAttachmentCleaner コンテキストがキャンセルされるまで、一定間隔で不要になった添付ファイルをストレージから削除する
+// 削除対象が残っている間は待たずに続けて処理する
+func runAttachmentCleaner(ctx context.Context, cleaner *services.AttachmentCleaner, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		n, err := cleaner.ProcessOrphans(ctx, time.Now())
+		if err != nil && ctx.Err() == nil {
+			log.Printf("添付ファイルの削除に失敗しました: %v", err)
+		}
+		if err == nil && n > 0 {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// runTrashPurger コンテキストがキャンセルされるまで、一定間隔で保持期間を過ぎたごみ箱のレコード・フィールド・アプリを完全に削除する
+// 削除対象が残っている間は待たずに続けて処理する
+func runTrashPurger(ctx context.Context, purger *services.TrashPurger, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		n, err := purger.ProcessExpired(ctx, time.Now())
+		if err != nil && ctx.Err() == nil {
+			log.Printf("ごみ箱の完全な削除に失敗しました: %v", err)
+		}
+		if err == nil && n > 0 {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// runReportScheduler コンテキストがキャンセルされるまで、一定間隔で配信時刻を過ぎたレポートをメールで配信する
+// 複数のサーバーで起動した場合も、アドバイザリーロックを取得できた1台だけが配信する
+func runReportScheduler(ctx context.Context, scheduler *services.ReportScheduler, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		n, err := scheduler.ProcessDue(ctx, time.Now())
+		if err != nil && ctx.Err() == nil {
+			log.Printf("レポートの定期配信に失敗しました: %v", err)
+		}
+		if err == nil && n > 0 {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// newMailSender 設定に応じたメールの送信先を作成する
+// SMTP_HOST が未設定の場合は起動を続け、レポートの配信は失敗として配信履歴に記録する
+func newMailSender(cfg *config.MailConfig) (mail.Sender, error) {
+	if cfg.Host == "" {
+		log.Println("SMTP_HOST が設定されていないため、レポートのメール配信は利用できません")
+		return mail.DisabledSender{}, nil
+	}
+	return mail.NewSMTPSender(mail.SMTPConfig{
+		Host:     cfg.Host,
+		Port:     cfg.Port,
+		Username: cfg.Username,
+		Password: cfg.Password,
+		From:     cfg.From,
+	})
+}
+
+// newFileStorage 設定に応じた添付ファイルの保存先を作成する
+// ローカルストレージの場合は署名付きURLの配信に使うため、LocalStorageも返す
+func newFileStorage(cfg *config.StorageConfig) (storage.FileStorage, *storage.LocalStorage, error) {
+	switch cfg.Driver {
+	case "local":
+		local, err := storage.NewLocalStorage(cfg.LocalDir, cfg.PublicURL, []byte(cfg.SigningKey))
+		if err != nil {
+			return nil, nil, err
+		}
+		return local, local, nil
+	case "s3":
+		s3, err := storage.NewS3Storage(storage.S3Config{
+			Endpoint:        cfg.S3.Endpoint,
+			Region:          cfg.S3.Region,
+			Bucket:          cfg.S3.Bucket,
+			AccessKeyID:     cfg.S3.AccessKeyID,
+			SecretAccessKey: cfg.S3.SecretAccessKey,
+			UsePathStyle:    cfg.S3.UsePathStyle,
+		})
+		if err != nil {
+			return nil, nil, err
+		}
+		return s3, nil, nil
+	default:
+		return nil, nil, fmt.Errorf("STORAGE_DRIVER %q はサポートされていません（local または s3）", cfg.Driver)
+	}
+}
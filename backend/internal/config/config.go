@@ -10,9 +10,13 @@ import (
 
 // Config アプリケーションの全設定を保持する構造体
 type Config struct {
-	DB     DBConfig
-	JWT    JWTConfig
-	Server ServerConfig
+	DB      DBConfig
+	JWT     JWTConfig
+	Server  ServerConfig
+	Storage StorageConfig
+	Trash   TrashConfig
+	Mail    MailConfig
+	Webhook WebhookConfig
 }
 
 // DBConfig データベース設定を保持する構造体
@@ -42,6 +46,53 @@ type ServerConfig struct {
 	AllowedOrigins  []string
 }
 
+// StorageConfig 添付ファイルの保存先の設定を保持する構造体
+type StorageConfig struct {
+	// Driver 保存先（local: サーバーのディレクトリ、s3: S3互換ストレージ）
+	Driver string
+	// LocalDir local の保存先ディレクトリ
+	LocalDir string
+	// PublicURL local のダウンロードURLのベース（/api/v1/files を公開するURL）
+	PublicURL string
+	// SigningKey local のダウンロードURLの署名鍵
+	SigningKey string
+	S3         S3Config
+}
+
+// S3Config S3互換ストレージの接続設定を保持する構造体
+type S3Config struct {
+	Endpoint        string
+	Region          string
+	Bucket          string
+	AccessKeyID     string
+	SecretAccessKey string
+	// UsePathStyle バケット名をホスト名ではなくパスに含める（MinIOなど）
+	UsePathStyle bool
+}
+
+// TrashConfig ごみ箱の設定を保持する構造体
+type TrashConfig struct {
+	// Retention ごみ箱に移してから完全に削除するまでの期間
+	Retention time.Duration
+}
+
+// MailConfig レポートの配信に使うSMTPサーバーの設定を保持する構造体
+type MailConfig struct {
+	// Host SMTPサーバーのホスト名（空の場合はメールを送信しない）
+	Host     string
+	Port     string
+	Username string
+	Password string
+	// From 送信元のメールアドレス
+	From string
+}
+
+// WebhookConfig Webhookの送信の設定を保持する構造体
+type WebhookConfig struct {
+	// AllowPrivateNetworks ループバック・プライベート・リンクローカルなど内部ネットワークのアドレスへの送信を許可する
+	AllowPrivateNetworks bool
+}
+
 // Load 環境変数から設定を読み込む
 func Load() *Config {
 	expiryHours, err := strconv.Atoi(getEnv("JWT_EXPIRY_HOURS", "24"))
@@ -59,6 +110,22 @@ func Load() *Config {
 		maxIdleConns = 5
 	}
 
+	jwtSecret := getEnv("JWT_SECRET", "default-secret-key-change-in-production")
+	usePathStyle, err := strconv.ParseBool(getEnv("S3_USE_PATH_STYLE", "false"))
+	if err != nil {
+		usePathStyle = false
+	}
+
+	allowPrivateWebhooks, err := strconv.ParseBool(getEnv("WEBHOOK_ALLOW_PRIVATE_NETWORKS", "false"))
+	if err != nil {
+		allowPrivateWebhooks = false
+	}
+
+	retentionDays, err := strconv.Atoi(getEnv("TRASH_RETENTION_DAYS", "30"))
+	if err != nil || retentionDays < 1 {
+		retentionDays = 30
+	}
+
 	return &Config{
 		DB: DBConfig{
 			Host:            getEnv("DB_HOST", "localhost"),
@@ -71,7 +138,7 @@ func Load() *Config {
 			ConnMaxLifetime: 5 * time.Minute,
 		},
 		JWT: JWTConfig{
-			Secret:      getEnv("JWT_SECRET", "default-secret-key-change-in-production"),
+			Secret:      jwtSecret,
 			ExpiryHours: expiryHours,
 		},
 		Server: ServerConfig{
@@ -81,6 +148,34 @@ func Load() *Config {
 			ShutdownTimeout: 30 * time.Second,
 			AllowedOrigins:  parseOrigins(getEnv("ALLOWED_ORIGINS", "http://localhost:3000")),
 		},
+		Storage: StorageConfig{
+			Driver:    getEnv("STORAGE_DRIVER", "local"),
+			LocalDir:  getEnv("STORAGE_LOCAL_DIR", "./uploads"),
+			PublicURL: getEnv("STORAGE_PUBLIC_URL", "/api/v1/files"),
+			// 未設定の場合はJWTの署名鍵を使う
+			SigningKey: getEnv("STORAGE_SIGNING_KEY", jwtSecret),
+			S3: S3Config{
+				Endpoint:        getEnv("S3_ENDPOINT", ""),
+				Region:          getEnv("S3_REGION", "us-east-1"),
+				Bucket:          getEnv("S3_BUCKET", ""),
+				AccessKeyID:     getEnv("S3_ACCESS_KEY_ID", ""),
+				SecretAccessKey: getEnv("S3_SECRET_ACCESS_KEY", ""),
+				UsePathStyle:    usePathStyle,
+			},
+		},
+		Trash: TrashConfig{
+			Retention: time.Duration(retentionDays) * 24 * time.Hour,
+		},
+		Mail: MailConfig{
+			Host:     getEnv("SMTP_HOST", ""),
+			Port:     getEnv("SMTP_PORT", "587"),
+			Username: getEnv("SMTP_USERNAME", ""),
+			Password: getEnv("SMTP_PASSWORD", ""),
+			From:     getEnv("SMTP_FROM", "noreply@example.com"),
+		},
+		Webhook: WebhookConfig{
+			AllowPrivateNetworks: allowPrivateWebhooks,
+		},
 	}
 }
 
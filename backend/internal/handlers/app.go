@@ -67,6 +67,14 @@ func (h *AppHandler) Create(w http.ResponseWriter, r *http.Request) {
 
 	resp, err := h.appService.CreateApp(r.Context(), claims.UserID, &req)
 	if err != nil {
+		if errors.Is(err, services.ErrInvalidFieldOptions) || errors.Is(err, services.ErrInvalidSearchConfig) {
+			utils.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		if errors.Is(err, services.ErrPermissionDenied) {
+			utils.WriteErrorResponse(w, http.StatusForbidden, err.Error())
+			return
+		}
 		// エラーの詳細はログにのみ出力（クライアントには非公開）
 		log.Printf("アプリ作成エラー: %v", err)
 		utils.WriteErrorResponse(w, http.StatusInternalServerError, "アプリの作成に失敗しました")
@@ -95,6 +103,10 @@ func (h *AppHandler) Get(w http.ResponseWriter, r *http.Request) {
 			utils.WriteErrorResponse(w, http.StatusNotFound, err.Error())
 			return
 		}
+		if errors.Is(err, services.ErrPermissionDenied) {
+			utils.WriteErrorResponse(w, http.StatusForbidden, err.Error())
+			return
+		}
 		utils.WriteErrorResponse(w, http.StatusInternalServerError, "アプリの取得に失敗しました")
 		return
 	}
@@ -127,6 +139,14 @@ func (h *AppHandler) Update(w http.ResponseWriter, r *http.Request) {
 			utils.WriteErrorResponse(w, http.StatusNotFound, err.Error())
 			return
 		}
+		if errors.Is(err, services.ErrInvalidSearchConfig) {
+			utils.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		if errors.Is(err, services.ErrPermissionDenied) {
+			utils.WriteErrorResponse(w, http.StatusForbidden, err.Error())
+			return
+		}
 		utils.WriteErrorResponse(w, http.StatusInternalServerError, "アプリの更新に失敗しました")
 		return
 	}
@@ -152,6 +172,14 @@ func (h *AppHandler) Delete(w http.ResponseWriter, r *http.Request) {
 			utils.WriteErrorResponse(w, http.StatusNotFound, err.Error())
 			return
 		}
+		if errors.Is(err, services.ErrAppReferenced) {
+			utils.WriteErrorResponse(w, http.StatusConflict, err.Error())
+			return
+		}
+		if errors.Is(err, services.ErrPermissionDenied) {
+			utils.WriteErrorResponse(w, http.StatusForbidden, err.Error())
+			return
+		}
 		utils.WriteErrorResponse(w, http.StatusInternalServerError, "アプリの削除に失敗しました")
 		return
 	}
@@ -0,0 +1,264 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"nocode-app/backend/internal/middleware"
+	"nocode-app/backend/internal/models"
+	"nocode-app/backend/internal/services"
+	"nocode-app/backend/internal/utils"
+)
+
+// maxAppPackageSize インポートできるパッケージの最大サイズ
+const maxAppPackageSize = 32 << 20
+
+// AppPackageHandler アプリ定義のエクスポート・インポートとテンプレートのエンドポイントを処理する構造体
+type AppPackageHandler struct {
+	packageService services.AppPackageServiceInterface
+	validator      *utils.Validator
+}
+
+// NewAppPackageHandler 新しいAppPackageHandlerを作成する
+func NewAppPackageHandler(packageService services.AppPackageServiceInterface, validator *utils.Validator) *AppPackageHandler {
+	return &AppPackageHandler{
+		packageService: packageService,
+		validator:      validator,
+	}
+}
+
+// Export アプリの定義をパッケージファイルとしてダウンロードする
+// format で json（既定）/ yaml を指定し、records=true の場合はレコードも含める
+func (h *AppPackageHandler) Export(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		utils.WriteErrorResponse(w, http.StatusMethodNotAllowed, "メソッドが許可されていません")
+		return
+	}
+
+	appID, err := extractAppID(r.URL.Path)
+	if err != nil {
+		utils.WriteErrorResponse(w, http.StatusBadRequest, "無効なアプリIDです")
+		return
+	}
+
+	format := models.AppPackageFormat(utils.GetQueryParam(r, "format", string(models.AppPackageFormatJSON)))
+	if !format.IsValid() {
+		utils.WriteErrorResponse(w, http.StatusBadRequest, "形式は json または yaml を指定してください")
+		return
+	}
+	includeRecords := utils.GetQueryParam(r, "records", "") == "true"
+
+	pkg, err := h.packageService.ExportApp(r.Context(), appID, includeRecords)
+	if err != nil {
+		if writeAppPackageError(w, err) {
+			return
+		}
+		log.Printf("アプリエクスポートエラー: %v", err)
+		utils.WriteErrorResponse(w, http.StatusInternalServerError, "アプリのエクスポートに失敗しました")
+		return
+	}
+
+	var body []byte
+	if format == models.AppPackageFormatYAML {
+		body, err = yaml.Marshal(pkg)
+	} else {
+		body, err = json.MarshalIndent(pkg, "", "  ")
+	}
+	if err != nil {
+		log.Printf("アプリエクスポートエラー: %v", err)
+		utils.WriteErrorResponse(w, http.StatusInternalServerError, "アプリのエクスポートに失敗しました")
+		return
+	}
+
+	w.Header().Set("Content-Type", format.ContentType())
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="app_%d.%s"`, appID, format))
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(body)
+}
+
+// Import パッケージからアプリを作成する
+// Content-Type が YAML の場合、または format=yaml の場合は本文をYAMLとして読み込む
+func (h *AppPackageHandler) Import(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		utils.WriteErrorResponse(w, http.StatusMethodNotAllowed, "メソッドが許可されていません")
+		return
+	}
+
+	claims, ok := middleware.GetUserFromContext(r.Context())
+	if !ok {
+		utils.WriteErrorResponse(w, http.StatusUnauthorized, "認証されていません")
+		return
+	}
+
+	var req models.ImportAppRequest
+	if isYAMLRequest(r) {
+		body, err := io.ReadAll(io.LimitReader(r.Body, maxAppPackageSize+1))
+		if err != nil || len(body) > maxAppPackageSize {
+			utils.WriteErrorResponse(w, http.StatusBadRequest, "パッケージを読み込めません")
+			return
+		}
+		if err := utils.DecodeYAML(body, &req); err != nil {
+			utils.WriteErrorResponse(w, http.StatusBadRequest, "無効なYAMLです: "+err.Error())
+			return
+		}
+		if err := h.validator.Validate(&req); err != nil {
+			utils.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+			return
+		}
+	} else {
+		r.Body = http.MaxBytesReader(w, r.Body, maxAppPackageSize)
+		if err := h.validator.ParseAndValidate(r, &req); err != nil {
+			utils.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+
+	resp, err := h.packageService.ImportApp(r.Context(), claims.UserID, &req)
+	if err != nil {
+		if writeAppPackageError(w, err) {
+			return
+		}
+		log.Printf("アプリインポートエラー: %v", err)
+		utils.WriteErrorResponse(w, http.StatusInternalServerError, "アプリのインポートに失敗しました")
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusCreated, resp)
+}
+
+// ListTemplates 組み込みのテンプレートを一覧表示する
+func (h *AppPackageHandler) ListTemplates(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		utils.WriteErrorResponse(w, http.StatusMethodNotAllowed, "メソッドが許可されていません")
+		return
+	}
+
+	resp, err := h.packageService.GetTemplates(r.Context())
+	if err != nil {
+		utils.WriteErrorResponse(w, http.StatusInternalServerError, "テンプレートの取得に失敗しました")
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, resp)
+}
+
+// GetTemplate 組み込みのテンプレートをパッケージ付きで取得する
+func (h *AppPackageHandler) GetTemplate(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		utils.WriteErrorResponse(w, http.StatusMethodNotAllowed, "メソッドが許可されていません")
+		return
+	}
+
+	templateID, err := extractTemplateID(r.URL.Path)
+	if err != nil {
+		utils.WriteErrorResponse(w, http.StatusBadRequest, "無効なテンプレートIDです")
+		return
+	}
+
+	tmpl, err := h.packageService.GetTemplate(r.Context(), templateID)
+	if err != nil {
+		if writeAppPackageError(w, err) {
+			return
+		}
+		utils.WriteErrorResponse(w, http.StatusInternalServerError, "テンプレートの取得に失敗しました")
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, tmpl)
+}
+
+// CreateFromTemplate 組み込みのテンプレートからアプリを作成する
+func (h *AppPackageHandler) CreateFromTemplate(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		utils.WriteErrorResponse(w, http.StatusMethodNotAllowed, "メソッドが許可されていません")
+		return
+	}
+
+	claims, ok := middleware.GetUserFromContext(r.Context())
+	if !ok {
+		utils.WriteErrorResponse(w, http.StatusUnauthorized, "認証されていません")
+		return
+	}
+
+	templateID, err := extractTemplateID(r.URL.Path)
+	if err != nil {
+		utils.WriteErrorResponse(w, http.StatusBadRequest, "無効なテンプレートIDです")
+		return
+	}
+
+	var req models.CreateAppFromTemplateRequest
+	if r.ContentLength != 0 {
+		if err := h.validator.ParseAndValidate(r, &req); err != nil {
+			utils.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+
+	resp, err := h.packageService.CreateAppFromTemplate(r.Context(), claims.UserID, templateID, &req)
+	if err != nil {
+		if writeAppPackageError(w, err) {
+			return
+		}
+		log.Printf("テンプレートからのアプリ作成エラー: %v", err)
+		utils.WriteErrorResponse(w, http.StatusInternalServerError, "アプリの作成に失敗しました")
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusCreated, resp)
+}
+
+// writeAppPackageError エクスポート・インポートのエラーに対応するレスポンスを書き込み、書き込んだ場合はtrueを返す
+func writeAppPackageError(w http.ResponseWriter, err error) bool {
+	if writeRecordValidationError(w, err) {
+		return true
+	}
+	switch {
+	case errors.Is(err, services.ErrAppNotFound),
+		errors.Is(err, services.ErrAppTemplateNotFound):
+		utils.WriteErrorResponse(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, services.ErrPermissionDenied):
+		utils.WriteErrorResponse(w, http.StatusForbidden, err.Error())
+	case errors.Is(err, services.ErrExternalAppExport),
+		errors.Is(err, services.ErrUnsupportedPackageVersion),
+		errors.Is(err, services.ErrPackageTooManyRecords),
+		errors.Is(err, services.ErrInvalidFieldCode),
+		errors.Is(err, services.ErrInvalidFieldOptions),
+		errors.Is(err, services.ErrInvalidSearchConfig):
+		utils.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, services.ErrEncryptionNotInitialized):
+		utils.WriteErrorResponse(w, http.StatusServiceUnavailable, err.Error())
+	default:
+		return false
+	}
+	return true
+}
+
+// isYAMLRequest リクエストの本文がYAMLかどうかを判定する
+func isYAMLRequest(r *http.Request) bool {
+	if utils.GetQueryParam(r, "format", "") == string(models.AppPackageFormatYAML) {
+		return true
+	}
+	mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil {
+		return false
+	}
+	return strings.HasSuffix(mediaType, "/yaml") || strings.HasSuffix(mediaType, "/x-yaml")
+}
+
+// extractTemplateID URLパスからテンプレートIDを抽出する
+// 想定パス形式: /api/v1/apps/templates/{templateId}
+func extractTemplateID(path string) (string, error) {
+	parts := strings.Split(strings.Trim(path, "/"), "/")
+	if len(parts) < 5 || parts[4] == "" {
+		return "", errors.New("無効なパスです")
+	}
+	return parts[4], nil
+}
@@ -0,0 +1,239 @@
+package handlers_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"nocode-app/backend/internal/handlers"
+	"nocode-app/backend/internal/models"
+	"nocode-app/backend/internal/services"
+	"nocode-app/backend/internal/testhelpers/mocks"
+	"nocode-app/backend/internal/utils"
+)
+
+func testAppPackage() *models.AppPackage {
+	return &models.AppPackage{
+		Version: 1,
+		App:     models.AppPackageApp{Name: "顧客"},
+		Fields: []models.AppPackageField{
+			{FieldCode: "name", FieldName: "名前", FieldType: "text", DisplayOrder: 1},
+		},
+	}
+}
+
+func TestAppPackageHandler_Export(t *testing.T) {
+	t.Run("json download", func(t *testing.T) {
+		mockService := new(mocks.MockAppPackageService)
+		handler := handlers.NewAppPackageHandler(mockService, utils.NewValidator())
+
+		mockService.On("ExportApp", mock.Anything, uint64(1), true).Return(testAppPackage(), nil)
+
+		httpReq := httptest.NewRequest(http.MethodGet, "/api/v1/apps/1/export?records=true", nil)
+		rr := httptest.NewRecorder()
+
+		handler.Export(rr, httpReq)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, "application/json", rr.Header().Get("Content-Type"))
+		assert.Contains(t, rr.Header().Get("Content-Disposition"), `filename="app_1.json"`)
+
+		var result models.AppPackage
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &result))
+		assert.Equal(t, "顧客", result.App.Name)
+	})
+
+	t.Run("yaml download", func(t *testing.T) {
+		mockService := new(mocks.MockAppPackageService)
+		handler := handlers.NewAppPackageHandler(mockService, utils.NewValidator())
+
+		mockService.On("ExportApp", mock.Anything, uint64(1), false).Return(testAppPackage(), nil)
+
+		httpReq := httptest.NewRequest(http.MethodGet, "/api/v1/apps/1/export?format=yaml", nil)
+		rr := httptest.NewRecorder()
+
+		handler.Export(rr, httpReq)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Contains(t, rr.Body.String(), "field_code: name")
+
+		var result models.AppPackage
+		require.NoError(t, utils.DecodeYAML(rr.Body.Bytes(), &result))
+		assert.Equal(t, "顧客", result.App.Name)
+	})
+
+	t.Run("invalid format", func(t *testing.T) {
+		handler := handlers.NewAppPackageHandler(new(mocks.MockAppPackageService), utils.NewValidator())
+
+		httpReq := httptest.NewRequest(http.MethodGet, "/api/v1/apps/1/export?format=xml", nil)
+		rr := httptest.NewRecorder()
+
+		handler.Export(rr, httpReq)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+
+	t.Run("non-owner is denied", func(t *testing.T) {
+		mockService := new(mocks.MockAppPackageService)
+		handler := handlers.NewAppPackageHandler(mockService, utils.NewValidator())
+
+		mockService.On("ExportApp", mock.Anything, uint64(1), false).Return(nil, services.ErrPermissionDenied)
+
+		httpReq := httptest.NewRequest(http.MethodGet, "/api/v1/apps/1/export", nil)
+		rr := httptest.NewRecorder()
+
+		handler.Export(rr, httpReq)
+
+		assert.Equal(t, http.StatusForbidden, rr.Code)
+	})
+}
+
+func TestAppPackageHandler_Import(t *testing.T) {
+	t.Run("json body", func(t *testing.T) {
+		mockService := new(mocks.MockAppPackageService)
+		handler := handlers.NewAppPackageHandler(mockService, utils.NewValidator())
+
+		mockService.On("ImportApp", mock.Anything, uint64(1), mock.MatchedBy(func(req *models.ImportAppRequest) bool {
+			return req.Package.App.Name == "顧客" && req.FieldCodes["name"] == "customer_name"
+		})).Return(&models.ImportAppResponse{App: &models.AppResponse{ID: 5}}, nil)
+
+		body, _ := json.Marshal(models.ImportAppRequest{
+			Package:    *testAppPackage(),
+			FieldCodes: map[string]string{"name": "customer_name"},
+		})
+		httpReq := httptest.NewRequest(http.MethodPost, "/api/v1/apps/import", bytes.NewReader(body))
+		httpReq.Header.Set("Content-Type", "application/json")
+		httpReq = httpReq.WithContext(createContextWithClaims(httpReq.Context(), 1))
+		rr := httptest.NewRecorder()
+
+		handler.Import(rr, httpReq)
+
+		assert.Equal(t, http.StatusCreated, rr.Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("yaml body", func(t *testing.T) {
+		mockService := new(mocks.MockAppPackageService)
+		handler := handlers.NewAppPackageHandler(mockService, utils.NewValidator())
+
+		mockService.On("ImportApp", mock.Anything, uint64(1), mock.MatchedBy(func(req *models.ImportAppRequest) bool {
+			return req.Package.Version == 1 && len(req.Package.Fields) == 1 && req.SkipRecords
+		})).Return(&models.ImportAppResponse{App: &models.AppResponse{ID: 5}}, nil)
+
+		body := strings.Join([]string{
+			"skip_records: true",
+			"package:",
+			"  version: 1",
+			"  app:",
+			"    name: 顧客",
+			"  fields:",
+			"    - field_code: name",
+			"      field_name: 名前",
+			"      field_type: text",
+			"      display_order: 1",
+		}, "\n")
+		httpReq := httptest.NewRequest(http.MethodPost, "/api/v1/apps/import", strings.NewReader(body))
+		httpReq.Header.Set("Content-Type", "application/yaml")
+		httpReq = httpReq.WithContext(createContextWithClaims(httpReq.Context(), 1))
+		rr := httptest.NewRecorder()
+
+		handler.Import(rr, httpReq)
+
+		assert.Equal(t, http.StatusCreated, rr.Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("package without fields", func(t *testing.T) {
+		handler := handlers.NewAppPackageHandler(new(mocks.MockAppPackageService), utils.NewValidator())
+
+		body := `{"package":{"version":1,"app":{"name":"顧客"},"fields":[]}}`
+		httpReq := httptest.NewRequest(http.MethodPost, "/api/v1/apps/import", strings.NewReader(body))
+		httpReq.Header.Set("Content-Type", "application/json")
+		httpReq = httpReq.WithContext(createContextWithClaims(httpReq.Context(), 1))
+		rr := httptest.NewRecorder()
+
+		handler.Import(rr, httpReq)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+
+	t.Run("record validation error", func(t *testing.T) {
+		mockService := new(mocks.MockAppPackageService)
+		handler := handlers.NewAppPackageHandler(mockService, utils.NewValidator())
+
+		mockService.On("ImportApp", mock.Anything, uint64(1), mock.Anything).Return(nil, &services.RecordValidationError{
+			RecordErrors: []models.RecordFieldErrors{{Index: 3, Errors: models.FieldErrors{"name": "必須です"}}},
+		})
+
+		body, _ := json.Marshal(models.ImportAppRequest{Package: *testAppPackage()})
+		httpReq := httptest.NewRequest(http.MethodPost, "/api/v1/apps/import", bytes.NewReader(body))
+		httpReq.Header.Set("Content-Type", "application/json")
+		httpReq = httpReq.WithContext(createContextWithClaims(httpReq.Context(), 1))
+		rr := httptest.NewRecorder()
+
+		handler.Import(rr, httpReq)
+
+		assert.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+	})
+}
+
+func TestAppPackageHandler_Templates(t *testing.T) {
+	t.Run("list templates", func(t *testing.T) {
+		mockService := new(mocks.MockAppPackageService)
+		handler := handlers.NewAppPackageHandler(mockService, utils.NewValidator())
+
+		mockService.On("GetTemplates", mock.Anything).Return(&models.AppTemplateListResponse{
+			Templates: []models.AppTemplateSummary{{ID: "issue_tracker", Name: "課題管理", FieldCount: 6}},
+		}, nil)
+
+		httpReq := httptest.NewRequest(http.MethodGet, "/api/v1/apps/templates", nil)
+		rr := httptest.NewRecorder()
+
+		handler.ListTemplates(rr, httpReq)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+
+		var result models.AppTemplateListResponse
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &result))
+		require.Len(t, result.Templates, 1)
+		assert.Equal(t, "issue_tracker", result.Templates[0].ID)
+	})
+
+	t.Run("template not found", func(t *testing.T) {
+		mockService := new(mocks.MockAppPackageService)
+		handler := handlers.NewAppPackageHandler(mockService, utils.NewValidator())
+
+		mockService.On("GetTemplate", mock.Anything, "missing").Return(nil, services.ErrAppTemplateNotFound)
+
+		httpReq := httptest.NewRequest(http.MethodGet, "/api/v1/apps/templates/missing", nil)
+		rr := httptest.NewRecorder()
+
+		handler.GetTemplate(rr, httpReq)
+
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+	})
+
+	t.Run("create from template without body", func(t *testing.T) {
+		mockService := new(mocks.MockAppPackageService)
+		handler := handlers.NewAppPackageHandler(mockService, utils.NewValidator())
+
+		mockService.On("CreateAppFromTemplate", mock.Anything, uint64(1), "inventory", &models.CreateAppFromTemplateRequest{}).
+			Return(&models.ImportAppResponse{App: &models.AppResponse{ID: 7}}, nil)
+
+		httpReq := httptest.NewRequest(http.MethodPost, "/api/v1/apps/templates/inventory", nil)
+		httpReq = httpReq.WithContext(createContextWithClaims(httpReq.Context(), 1))
+		rr := httptest.NewRecorder()
+
+		handler.CreateFromTemplate(rr, httpReq)
+
+		assert.Equal(t, http.StatusCreated, rr.Code)
+		mockService.AssertExpectations(t)
+	})
+}
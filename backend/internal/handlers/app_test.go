@@ -6,6 +6,7 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
@@ -308,6 +309,23 @@ func TestAppHandler_Update(t *testing.T) {
 		mockService.AssertExpectations(t)
 	})
 
+	t.Run("invalid search config", func(t *testing.T) {
+		mockService := new(mocks.MockAppService)
+		handler := handlers.NewAppHandler(mockService, validator)
+
+		mockService.On("UpdateApp", mock.Anything, uint64(1), mock.MatchedBy(func(req *models.UpdateAppRequest) bool {
+			return req.SearchConfig == "klingon"
+		})).Return(nil, services.ErrInvalidSearchConfig)
+
+		httpReq := httptest.NewRequest(http.MethodPut, "/api/v1/apps/1", strings.NewReader(`{"search_config":"klingon"}`))
+		httpReq.Header.Set("Content-Type", "application/json")
+		rr := httptest.NewRecorder()
+
+		handler.Update(rr, httpReq)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+
 	t.Run("app not found", func(t *testing.T) {
 		mockService := new(mocks.MockAppService)
 		handler := handlers.NewAppHandler(mockService, validator)
@@ -380,6 +398,22 @@ func TestAppHandler_Delete(t *testing.T) {
 		mockService.AssertExpectations(t)
 	})
 
+	t.Run("app referenced", func(t *testing.T) {
+		mockService := new(mocks.MockAppService)
+		handler := handlers.NewAppHandler(mockService, validator)
+
+		mockService.On("DeleteApp", mock.Anything, uint64(2)).Return(services.ErrAppReferenced)
+
+		httpReq := httptest.NewRequest(http.MethodDelete, "/api/v1/apps/2", nil)
+		rr := httptest.NewRecorder()
+
+		handler.Delete(rr, httpReq)
+
+		assert.Equal(t, http.StatusConflict, rr.Code)
+
+		mockService.AssertExpectations(t)
+	})
+
 	t.Run("method not allowed", func(t *testing.T) {
 		mockService := new(mocks.MockAppService)
 		handler := handlers.NewAppHandler(mockService, validator)
@@ -0,0 +1,227 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"nocode-app/backend/internal/middleware"
+	"nocode-app/backend/internal/models"
+	"nocode-app/backend/internal/services"
+	"nocode-app/backend/internal/storage"
+	"nocode-app/backend/internal/utils"
+)
+
+const (
+	// maxAttachmentRequestSize 添付ファイルのアップロードで受け付けるリクエストの最大サイズ（ファイル以外の部分を含む）
+	maxAttachmentRequestSize = services.MaxAttachmentFileSize + 1<<20
+	// attachmentFormMemory アップロード中のファイルをメモリに保持する上限（超えた分は一時ファイルに書き出す）
+	attachmentFormMemory = 8 << 20
+	// filesPathPrefix ローカルストレージのファイルを配信するパス
+	filesPathPrefix = "/api/v1/files/"
+)
+
+// AttachmentHandler 添付ファイルエンドポイントを処理する構造体
+type AttachmentHandler struct {
+	attachmentService services.AttachmentServiceInterface
+}
+
+// NewAttachmentHandler 新しいAttachmentHandlerを作成する
+func NewAttachmentHandler(attachmentService services.AttachmentServiceInterface) *AttachmentHandler {
+	return &AttachmentHandler{
+		attachmentService: attachmentService,
+	}
+}
+
+// Upload 添付ファイルフィールドにファイルをアップロードする
+// multipart/form-data で以下を受け付ける
+//   - field: アップロード先の添付ファイルフィールドのフィールドコード
+//   - file: アップロードするファイル
+func (h *AttachmentHandler) Upload(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		utils.WriteErrorResponse(w, http.StatusMethodNotAllowed, "メソッドが許可されていません")
+		return
+	}
+
+	claims, ok := middleware.GetUserFromContext(r.Context())
+	if !ok {
+		utils.WriteErrorResponse(w, http.StatusUnauthorized, "認証されていません")
+		return
+	}
+
+	appID, err := extractAppID(r.URL.Path)
+	if err != nil {
+		utils.WriteErrorResponse(w, http.StatusBadRequest, "無効なアプリIDです")
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxAttachmentRequestSize)
+	if err := r.ParseMultipartForm(attachmentFormMemory); err != nil {
+		var maxErr *http.MaxBytesError
+		if errors.As(err, &maxErr) {
+			utils.WriteErrorResponse(w, http.StatusRequestEntityTooLarge, services.ErrAttachmentTooLarge.Error())
+			return
+		}
+		utils.WriteErrorResponse(w, http.StatusBadRequest, "multipart/form-data で送信してください")
+		return
+	}
+	defer func() { _ = r.MultipartForm.RemoveAll() }()
+
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		utils.WriteErrorResponse(w, http.StatusBadRequest, "ファイルを指定してください")
+		return
+	}
+	defer func() { _ = file.Close() }()
+
+	resp, err := h.attachmentService.Upload(r.Context(), appID, claims.UserID, &models.UploadAttachmentRequest{
+		FieldCode: r.FormValue("field"),
+		FileName:  header.Filename,
+		Size:      header.Size,
+		File:      file,
+	})
+	if err != nil {
+		if writeAttachmentError(w, err) {
+			return
+		}
+		log.Printf("添付ファイルのアップロードエラー: %v", err)
+		utils.WriteErrorResponse(w, http.StatusInternalServerError, "ファイルのアップロードに失敗しました")
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusCreated, resp)
+}
+
+// Get 添付ファイルの情報と期限付きのダウンロードURLを取得する
+func (h *AttachmentHandler) Get(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		utils.WriteErrorResponse(w, http.StatusMethodNotAllowed, "メソッドが許可されていません")
+		return
+	}
+
+	appID, attachmentID, err := extractAppAndAttachmentID(r.URL.Path)
+	if err != nil {
+		utils.WriteErrorResponse(w, http.StatusBadRequest, "無効な添付ファイルIDです")
+		return
+	}
+
+	resp, err := h.attachmentService.GetAttachmentURL(r.Context(), appID, attachmentID)
+	if err != nil {
+		if writeAttachmentError(w, err) {
+			return
+		}
+		utils.WriteErrorResponse(w, http.StatusInternalServerError, "添付ファイルの取得に失敗しました")
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, resp)
+}
+
+// writeAttachmentError 添付ファイル操作の既知のエラーをレスポンスに変換する
+// 書き込んだ場合はtrueを返す
+func writeAttachmentError(w http.ResponseWriter, err error) bool {
+	switch {
+	case errors.Is(err, services.ErrAppNotFound),
+		errors.Is(err, services.ErrAttachmentNotFound):
+		utils.WriteErrorResponse(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, services.ErrPermissionDenied),
+		errors.Is(err, services.ErrExternalAppReadOnly):
+		utils.WriteErrorResponse(w, http.StatusForbidden, err.Error())
+	case errors.Is(err, services.ErrAttachmentTooLarge):
+		utils.WriteErrorResponse(w, http.StatusRequestEntityTooLarge, err.Error())
+	case errors.Is(err, services.ErrAttachmentTypeNotAllowed):
+		utils.WriteErrorResponse(w, http.StatusUnsupportedMediaType, err.Error())
+	case errors.Is(err, services.ErrInvalidAttachment):
+		utils.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+	default:
+		return false
+	}
+	return true
+}
+
+// extractAppAndAttachmentID URLパスからアプリIDと添付ファイルIDを抽出する
+// 想定パス形式: /api/v1/apps/{appId}/attachments/{attachmentId}
+func extractAppAndAttachmentID(path string) (uint64, uint64, error) {
+	parts := strings.Split(strings.Trim(path, "/"), "/")
+	if len(parts) < 6 {
+		return 0, 0, errors.New("無効なパスです")
+	}
+
+	appID, err := strconv.ParseUint(parts[3], 10, 64)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	attachmentID, err := strconv.ParseUint(parts[5], 10, 64)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	return appID, attachmentID, nil
+}
+
+// FileHandler ローカルストレージに保存した添付ファイルを署名付きURLで配信する構造体
+type FileHandler struct {
+	storage *storage.LocalStorage
+}
+
+// NewFileHandler 新しいFileHandlerを作成する
+// S3互換ストレージを使う場合は localStorage に nil を渡す（ダウンロードはストレージから直接行う）
+func NewFileHandler(localStorage *storage.LocalStorage) *FileHandler {
+	return &FileHandler{
+		storage: localStorage,
+	}
+}
+
+// Download 署名付きURLを検証してファイルを返す
+// 認証ヘッダーを付けられないブラウザのリンクから開けるよう、認証の代わりにURLの署名で確認する
+func (h *FileHandler) Download(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		utils.WriteErrorResponse(w, http.StatusMethodNotAllowed, "メソッドが許可されていません")
+		return
+	}
+	if h.storage == nil {
+		utils.WriteErrorResponse(w, http.StatusNotFound, storage.ErrObjectNotFound.Error())
+		return
+	}
+
+	key := strings.TrimPrefix(r.URL.Path, filesPathPrefix)
+	opts, err := h.storage.VerifySignedURL(key, r.URL.Query())
+	if err != nil {
+		switch {
+		case errors.Is(err, storage.ErrInvalidKey):
+			utils.WriteErrorResponse(w, http.StatusNotFound, storage.ErrObjectNotFound.Error())
+		default:
+			utils.WriteErrorResponse(w, http.StatusForbidden, err.Error())
+		}
+		return
+	}
+
+	body, err := h.storage.Open(r.Context(), key)
+	if err != nil {
+		if errors.Is(err, storage.ErrObjectNotFound) {
+			utils.WriteErrorResponse(w, http.StatusNotFound, err.Error())
+			return
+		}
+		utils.WriteErrorResponse(w, http.StatusInternalServerError, "ファイルの読み込みに失敗しました")
+		return
+	}
+	defer func() { _ = body.Close() }()
+
+	contentType := opts.ContentType
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	// ブラウザで開かせずに必ずダウンロードさせ、内容からの種類の推測もさせない
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", storage.ContentDisposition(opts.FileName))
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.Header().Set("Cache-Control", "private, max-age=0, no-store")
+	w.WriteHeader(http.StatusOK)
+	if _, err := io.Copy(w, body); err != nil {
+		log.Printf("ファイルの送信エラー: %v", err)
+	}
+}
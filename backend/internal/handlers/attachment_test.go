@@ -0,0 +1,195 @@
+package handlers_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"nocode-app/backend/internal/handlers"
+	"nocode-app/backend/internal/models"
+	"nocode-app/backend/internal/services"
+	"nocode-app/backend/internal/storage"
+	"nocode-app/backend/internal/testhelpers/mocks"
+)
+
+// newUploadRequest 添付ファイルのアップロード用のmultipartリクエストを作成する
+func newUploadRequest(t *testing.T, field, filename, content string) *http.Request {
+	t.Helper()
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	require.NoError(t, mw.WriteField("field", field))
+	fw, err := mw.CreateFormFile("file", filename)
+	require.NoError(t, err)
+	_, err = fw.Write([]byte(content))
+	require.NoError(t, err)
+	require.NoError(t, mw.Close())
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/apps/1/attachments", &body)
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	return req.WithContext(createContextWithClaims(req.Context(), 5))
+}
+
+func TestAttachmentHandler_Upload(t *testing.T) {
+	t.Run("successful upload", func(t *testing.T) {
+		mockService := new(mocks.MockAttachmentService)
+		handler := handlers.NewAttachmentHandler(mockService)
+
+		var received *models.UploadAttachmentRequest
+		mockService.On("Upload", mock.Anything, uint64(1), uint64(5), mock.AnythingOfType("*models.UploadAttachmentRequest")).
+			Return(&models.AttachmentValue{ID: 9, FileName: "見積書.pdf", ContentType: "application/pdf", Size: 8}, nil).
+			Run(func(args mock.Arguments) {
+				received = args.Get(3).(*models.UploadAttachmentRequest)
+				content, _ := io.ReadAll(received.File)
+				assert.Equal(t, "%PDF-1.4", string(content))
+			})
+
+		rr := httptest.NewRecorder()
+		handler.Upload(rr, newUploadRequest(t, "files", "見積書.pdf", "%PDF-1.4"))
+
+		assert.Equal(t, http.StatusCreated, rr.Code)
+		require.NotNil(t, received)
+		assert.Equal(t, "files", received.FieldCode)
+		assert.Equal(t, "見積書.pdf", received.FileName)
+		assert.Equal(t, int64(8), received.Size)
+
+		var result models.AttachmentValue
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &result))
+		assert.Equal(t, uint64(9), result.ID)
+	})
+
+	t.Run("missing file", func(t *testing.T) {
+		mockService := new(mocks.MockAttachmentService)
+		handler := handlers.NewAttachmentHandler(mockService)
+
+		var body bytes.Buffer
+		mw := multipart.NewWriter(&body)
+		require.NoError(t, mw.WriteField("field", "files"))
+		require.NoError(t, mw.Close())
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/apps/1/attachments", &body)
+		req.Header.Set("Content-Type", mw.FormDataContentType())
+		req = req.WithContext(createContextWithClaims(req.Context(), 5))
+
+		rr := httptest.NewRecorder()
+		handler.Upload(rr, req)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+		mockService.AssertNotCalled(t, "Upload", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	errorCases := []struct {
+		name   string
+		err    error
+		status int
+	}{
+		{"too large", services.ErrAttachmentTooLarge, http.StatusRequestEntityTooLarge},
+		{"type not allowed", services.ErrAttachmentTypeNotAllowed, http.StatusUnsupportedMediaType},
+		{"invalid field", services.ErrInvalidAttachment, http.StatusBadRequest},
+		{"no edit permission", services.ErrPermissionDenied, http.StatusForbidden},
+		{"app not found", services.ErrAppNotFound, http.StatusNotFound},
+	}
+	for _, tc := range errorCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockService := new(mocks.MockAttachmentService)
+			handler := handlers.NewAttachmentHandler(mockService)
+			mockService.On("Upload", mock.Anything, uint64(1), uint64(5), mock.Anything).Return(nil, tc.err)
+
+			rr := httptest.NewRecorder()
+			handler.Upload(rr, newUploadRequest(t, "files", "a.txt", "hello"))
+
+			assert.Equal(t, tc.status, rr.Code)
+		})
+	}
+}
+
+func TestAttachmentHandler_Get(t *testing.T) {
+	t.Run("returns signed url", func(t *testing.T) {
+		mockService := new(mocks.MockAttachmentService)
+		handler := handlers.NewAttachmentHandler(mockService)
+
+		mockService.On("GetAttachmentURL", mock.Anything, uint64(1), uint64(9)).Return(&models.AttachmentURLResponse{
+			AttachmentValue: models.AttachmentValue{ID: 9, FileName: "a.pdf"},
+			URL:             "/api/v1/files/apps/1/abc?signature=x",
+		}, nil)
+
+		rr := httptest.NewRecorder()
+		handler.Get(rr, httptest.NewRequest(http.MethodGet, "/api/v1/apps/1/attachments/9", nil))
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		var result models.AttachmentURLResponse
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &result))
+		assert.Equal(t, "/api/v1/files/apps/1/abc?signature=x", result.URL)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		mockService := new(mocks.MockAttachmentService)
+		handler := handlers.NewAttachmentHandler(mockService)
+
+		mockService.On("GetAttachmentURL", mock.Anything, uint64(1), uint64(9)).Return(nil, services.ErrAttachmentNotFound)
+
+		rr := httptest.NewRecorder()
+		handler.Get(rr, httptest.NewRequest(http.MethodGet, "/api/v1/apps/1/attachments/9", nil))
+
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+	})
+}
+
+func TestFileHandler_Download(t *testing.T) {
+	ctx := context.Background()
+	local, err := storage.NewLocalStorage(t.TempDir(), "/api/v1/files", []byte("signing-key"))
+	require.NoError(t, err)
+	require.NoError(t, local.Put(ctx, "apps/1/abc", strings.NewReader("<html></html>"), 13, "text/html; charset=utf-8"))
+
+	signed, err := local.SignedURL(ctx, "apps/1/abc", storage.URLOptions{FileName: "page.html", ContentType: "text/html; charset=utf-8", Expires: time.Minute})
+	require.NoError(t, err)
+
+	t.Run("valid signature", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		handlers.NewFileHandler(local).Download(rr, httptest.NewRequest(http.MethodGet, signed, nil))
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, "<html></html>", rr.Body.String())
+		assert.Equal(t, "text/html; charset=utf-8", rr.Header().Get("Content-Type"))
+		// アップロードされたHTMLをブラウザで表示させない
+		assert.True(t, strings.HasPrefix(rr.Header().Get("Content-Disposition"), "attachment;"))
+		assert.Equal(t, "nosniff", rr.Header().Get("X-Content-Type-Options"))
+	})
+
+	t.Run("tampered url", func(t *testing.T) {
+		u, err := url.Parse(signed)
+		require.NoError(t, err)
+		q := u.Query()
+		q.Set("name", "other.html")
+		u.RawQuery = q.Encode()
+
+		rr := httptest.NewRecorder()
+		handlers.NewFileHandler(local).Download(rr, httptest.NewRequest(http.MethodGet, u.String(), nil))
+
+		assert.Equal(t, http.StatusForbidden, rr.Code)
+	})
+
+	t.Run("path traversal", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		handlers.NewFileHandler(local).Download(rr, httptest.NewRequest(http.MethodGet, "/api/v1/files/apps/../../etc/passwd", nil))
+
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+	})
+
+	t.Run("s3 storage", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		handlers.NewFileHandler(nil).Download(rr, httptest.NewRequest(http.MethodGet, signed, nil))
+
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+	})
+}
@@ -0,0 +1,216 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"nocode-app/backend/internal/middleware"
+	"nocode-app/backend/internal/models"
+	"nocode-app/backend/internal/services"
+	"nocode-app/backend/internal/utils"
+)
+
+// AutomationHandler 自動化ルールエンドポイントを処理する構造体
+type AutomationHandler struct {
+	automationService services.AutomationServiceInterface
+	validator         *utils.Validator
+}
+
+// NewAutomationHandler 新しいAutomationHandlerを作成する
+func NewAutomationHandler(automationService services.AutomationServiceInterface, validator *utils.Validator) *AutomationHandler {
+	return &AutomationHandler{
+		automationService: automationService,
+		validator:         validator,
+	}
+}
+
+// List アプリに登録された自動化ルールを一覧表示する
+func (h *AutomationHandler) List(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		utils.WriteErrorResponse(w, http.StatusMethodNotAllowed, "メソッドが許可されていません")
+		return
+	}
+
+	appID, err := extractAppID(r.URL.Path)
+	if err != nil {
+		utils.WriteErrorResponse(w, http.StatusBadRequest, "無効なアプリIDです")
+		return
+	}
+
+	resp, err := h.automationService.GetRules(r.Context(), appID)
+	if err != nil {
+		if writeAutomationError(w, err) {
+			return
+		}
+		utils.WriteErrorResponse(w, http.StatusInternalServerError, "自動化ルールの取得に失敗しました")
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, resp)
+}
+
+// Create アプリに自動化ルールを登録する
+func (h *AutomationHandler) Create(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		utils.WriteErrorResponse(w, http.StatusMethodNotAllowed, "メソッドが許可されていません")
+		return
+	}
+
+	claims, ok := middleware.GetUserFromContext(r.Context())
+	if !ok {
+		utils.WriteErrorResponse(w, http.StatusUnauthorized, "認証されていません")
+		return
+	}
+
+	appID, err := extractAppID(r.URL.Path)
+	if err != nil {
+		utils.WriteErrorResponse(w, http.StatusBadRequest, "無効なアプリIDです")
+		return
+	}
+
+	var req models.CreateAutomationRuleRequest
+	if err := h.validator.ParseAndValidate(r, &req); err != nil {
+		utils.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	rule, err := h.automationService.CreateRule(r.Context(), appID, claims.UserID, &req)
+	if err != nil {
+		if writeAutomationError(w, err) {
+			return
+		}
+		utils.WriteErrorResponse(w, http.StatusInternalServerError, "自動化ルールの登録に失敗しました")
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusCreated, rule)
+}
+
+// Update 自動化ルールを更新する
+func (h *AutomationHandler) Update(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPut {
+		utils.WriteErrorResponse(w, http.StatusMethodNotAllowed, "メソッドが許可されていません")
+		return
+	}
+
+	appID, ruleID, err := extractAppAndRuleID(r.URL.Path)
+	if err != nil {
+		utils.WriteErrorResponse(w, http.StatusBadRequest, "無効な自動化ルールIDです")
+		return
+	}
+
+	var req models.UpdateAutomationRuleRequest
+	if err := h.validator.ParseAndValidate(r, &req); err != nil {
+		utils.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	rule, err := h.automationService.UpdateRule(r.Context(), appID, ruleID, &req)
+	if err != nil {
+		if writeAutomationError(w, err) {
+			return
+		}
+		utils.WriteErrorResponse(w, http.StatusInternalServerError, "自動化ルールの更新に失敗しました")
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, rule)
+}
+
+// Delete 自動化ルールを削除する
+func (h *AutomationHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		utils.WriteErrorResponse(w, http.StatusMethodNotAllowed, "メソッドが許可されていません")
+		return
+	}
+
+	appID, ruleID, err := extractAppAndRuleID(r.URL.Path)
+	if err != nil {
+		utils.WriteErrorResponse(w, http.StatusBadRequest, "無効な自動化ルールIDです")
+		return
+	}
+
+	if err := h.automationService.DeleteRule(r.Context(), appID, ruleID); err != nil {
+		if writeAutomationError(w, err) {
+			return
+		}
+		utils.WriteErrorResponse(w, http.StatusInternalServerError, "自動化ルールの削除に失敗しました")
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, models.SuccessResponse{Message: "自動化ルールを削除しました"})
+}
+
+// Runs 自動化ルールの実行ログを新しい順に一覧表示する
+func (h *AutomationHandler) Runs(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		utils.WriteErrorResponse(w, http.StatusMethodNotAllowed, "メソッドが許可されていません")
+		return
+	}
+
+	appID, ruleID, err := extractAppAndRuleID(r.URL.Path)
+	if err != nil {
+		utils.WriteErrorResponse(w, http.StatusBadRequest, "無効な自動化ルールIDです")
+		return
+	}
+
+	page := utils.GetQueryParamInt(r, "page", 1)
+	if page < 1 {
+		page = 1
+	}
+	limit := utils.GetQueryParamInt(r, "limit", 20)
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+
+	resp, err := h.automationService.GetRuns(r.Context(), appID, ruleID, page, limit)
+	if err != nil {
+		if writeAutomationError(w, err) {
+			return
+		}
+		utils.WriteErrorResponse(w, http.StatusInternalServerError, "自動化ルールの実行ログの取得に失敗しました")
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, resp)
+}
+
+// writeAutomationError 自動化ルール操作の既知のエラーをレスポンスに変換する
+// 書き込んだ場合はtrueを返す
+func writeAutomationError(w http.ResponseWriter, err error) bool {
+	switch {
+	case errors.Is(err, services.ErrAppNotFound),
+		errors.Is(err, services.ErrAutomationRuleNotFound):
+		utils.WriteErrorResponse(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, services.ErrPermissionDenied):
+		utils.WriteErrorResponse(w, http.StatusForbidden, err.Error())
+	case errors.Is(err, services.ErrInvalidAutomationRule):
+		utils.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+	default:
+		return false
+	}
+	return true
+}
+
+// extractAppAndRuleID URLパスからアプリIDと自動化ルールIDを抽出する
+// 想定パス形式: /api/v1/apps/{appId}/automations/{ruleId}[/runs]
+func extractAppAndRuleID(path string) (uint64, uint64, error) {
+	parts := strings.Split(strings.Trim(path, "/"), "/")
+	if len(parts) < 6 {
+		return 0, 0, errors.New("無効なパスです")
+	}
+
+	appID, err := strconv.ParseUint(parts[3], 10, 64)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	ruleID, err := strconv.ParseUint(parts[5], 10, 64)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	return appID, ruleID, nil
+}
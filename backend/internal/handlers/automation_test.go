@@ -0,0 +1,179 @@
+package handlers_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"nocode-app/backend/internal/handlers"
+	"nocode-app/backend/internal/models"
+	"nocode-app/backend/internal/services"
+	"nocode-app/backend/internal/testhelpers/mocks"
+	"nocode-app/backend/internal/utils"
+)
+
+func TestAutomationHandler_List(t *testing.T) {
+	validator := utils.NewValidator()
+
+	t.Run("successful list", func(t *testing.T) {
+		mockService := new(mocks.MockAutomationService)
+		handler := handlers.NewAutomationHandler(mockService, validator)
+
+		mockService.On("GetRules", mock.Anything, uint64(1)).Return(&models.AutomationRuleListResponse{
+			Rules: []models.AutomationRule{{ID: 6, AppID: 1, Name: "高額案件", Trigger: models.AutomationTriggerRecordCreated, IsActive: true}},
+		}, nil)
+
+		httpReq := httptest.NewRequest(http.MethodGet, "/api/v1/apps/1/automations", nil)
+		rr := httptest.NewRecorder()
+
+		handler.List(rr, httpReq)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+
+		var result models.AutomationRuleListResponse
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &result))
+		require.Len(t, result.Rules, 1)
+		assert.Equal(t, models.AutomationTriggerRecordCreated, result.Rules[0].Trigger)
+	})
+
+	t.Run("not owner", func(t *testing.T) {
+		mockService := new(mocks.MockAutomationService)
+		handler := handlers.NewAutomationHandler(mockService, validator)
+
+		mockService.On("GetRules", mock.Anything, uint64(1)).Return(nil, services.ErrPermissionDenied)
+
+		httpReq := httptest.NewRequest(http.MethodGet, "/api/v1/apps/1/automations", nil)
+		rr := httptest.NewRecorder()
+
+		handler.List(rr, httpReq)
+
+		assert.Equal(t, http.StatusForbidden, rr.Code)
+	})
+}
+
+func TestAutomationHandler_Create(t *testing.T) {
+	validator := utils.NewValidator()
+
+	newRequest := func(body interface{}) *http.Request {
+		b, _ := json.Marshal(body)
+		httpReq := httptest.NewRequest(http.MethodPost, "/api/v1/apps/1/automations", bytes.NewReader(b))
+		httpReq.Header.Set("Content-Type", "application/json")
+		return httpReq.WithContext(recordContextWithClaims(context.Background(), 5))
+	}
+	validBody := map[string]interface{}{
+		"name":       "高額案件",
+		"trigger":    "record_created",
+		"conditions": []map[string]string{{"field": "amount", "operator": "gte", "value": "100"}},
+		"actions":    []map[string]interface{}{{"type": "update_record", "values": map[string]string{"status": "review"}}},
+	}
+
+	t.Run("successful create", func(t *testing.T) {
+		mockService := new(mocks.MockAutomationService)
+		handler := handlers.NewAutomationHandler(mockService, validator)
+
+		mockService.On("CreateRule", mock.Anything, uint64(1), uint64(5), mock.MatchedBy(func(req *models.CreateAutomationRuleRequest) bool {
+			return req.Trigger == models.AutomationTriggerRecordCreated && len(req.Actions) == 1 &&
+				req.Actions[0].Values["status"] == "review"
+		})).Return(&models.AutomationRule{ID: 6, AppID: 1, Name: "高額案件"}, nil)
+
+		rr := httptest.NewRecorder()
+		handler.Create(rr, newRequest(validBody))
+
+		assert.Equal(t, http.StatusCreated, rr.Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("request validation", func(t *testing.T) {
+		for name, body := range map[string]map[string]interface{}{
+			"no actions":      {"name": "ルール", "trigger": "record_created", "actions": []interface{}{}},
+			"unknown trigger": {"name": "ルール", "trigger": "record_deleted", "actions": validBody["actions"]},
+			"unknown action":  {"name": "ルール", "trigger": "record_created", "actions": []map[string]string{{"type": "send_mail"}}},
+		} {
+			mockService := new(mocks.MockAutomationService)
+			handler := handlers.NewAutomationHandler(mockService, validator)
+
+			rr := httptest.NewRecorder()
+			handler.Create(rr, newRequest(body))
+
+			assert.Equal(t, http.StatusBadRequest, rr.Code, name)
+			mockService.AssertNotCalled(t, "CreateRule", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+		}
+	})
+
+	t.Run("invalid rule", func(t *testing.T) {
+		mockService := new(mocks.MockAutomationService)
+		handler := handlers.NewAutomationHandler(mockService, validator)
+
+		mockService.On("CreateRule", mock.Anything, uint64(1), uint64(5), mock.Anything).
+			Return(nil, fmt.Errorf("%w: 条件のフィールド \"amount\" が存在しません", services.ErrInvalidAutomationRule))
+
+		rr := httptest.NewRecorder()
+		handler.Create(rr, newRequest(validBody))
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+		assert.Contains(t, rr.Body.String(), "amount")
+	})
+}
+
+func TestAutomationHandler_Delete(t *testing.T) {
+	validator := utils.NewValidator()
+
+	mockService := new(mocks.MockAutomationService)
+	handler := handlers.NewAutomationHandler(mockService, validator)
+
+	mockService.On("DeleteRule", mock.Anything, uint64(1), uint64(6)).Return(services.ErrAutomationRuleNotFound)
+
+	httpReq := httptest.NewRequest(http.MethodDelete, "/api/v1/apps/1/automations/6", nil)
+	rr := httptest.NewRecorder()
+
+	handler.Delete(rr, httpReq)
+
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+}
+
+func TestAutomationHandler_Runs(t *testing.T) {
+	validator := utils.NewValidator()
+
+	t.Run("successful list", func(t *testing.T) {
+		mockService := new(mocks.MockAutomationService)
+		handler := handlers.NewAutomationHandler(mockService, validator)
+
+		mockService.On("GetRuns", mock.Anything, uint64(1), uint64(6), 2, 20).Return(&models.AutomationRunListResponse{
+			Runs:       []models.AutomationRun{{ID: 3, RuleID: 6, RecordID: 7, Status: models.AutomationRunFailed, Error: "アクション1（call_webhook）: Webhookが無効になっています"}},
+			Pagination: models.NewPagination(2, 20, 21),
+		}, nil)
+
+		// 上限を超えるlimitは既定値にする
+		httpReq := httptest.NewRequest(http.MethodGet, "/api/v1/apps/1/automations/6/runs?page=2&limit=500", nil)
+		rr := httptest.NewRecorder()
+
+		handler.Runs(rr, httpReq)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+
+		var result models.AutomationRunListResponse
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &result))
+		require.Len(t, result.Runs, 1)
+		assert.Equal(t, models.AutomationRunFailed, result.Runs[0].Status)
+	})
+
+	t.Run("invalid rule id", func(t *testing.T) {
+		mockService := new(mocks.MockAutomationService)
+		handler := handlers.NewAutomationHandler(mockService, validator)
+
+		httpReq := httptest.NewRequest(http.MethodGet, "/api/v1/apps/1/automations/abc/runs", nil)
+		rr := httptest.NewRecorder()
+
+		handler.Runs(rr, httpReq)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+}
@@ -56,6 +56,12 @@ func (h *ChartHandler) GetData(w http.ResponseWriter, r *http.Request) {
 		utils.WriteErrorResponse(w, http.StatusBadRequest, "y_axis.field is required for non-count aggregations")
 		return
 	}
+	for _, m := range req.Measures {
+		if m.Aggregation != "count" && m.Aggregation != "" && m.Field == "" {
+			utils.WriteErrorResponse(w, http.StatusBadRequest, "measures.field is required for non-count aggregations")
+			return
+		}
+	}
 
 	resp, err := h.chartService.GetChartData(r.Context(), appID, &req)
 	if err != nil {
@@ -63,10 +69,18 @@ func (h *ChartHandler) GetData(w http.ResponseWriter, r *http.Request) {
 			utils.WriteErrorResponse(w, http.StatusNotFound, err.Error())
 			return
 		}
+		if errors.Is(err, services.ErrInvalidFilter) || errors.Is(err, services.ErrInvalidChartConfig) {
+			utils.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+			return
+		}
 		if errors.Is(err, services.ErrEncryptionNotInitialized) {
 			utils.WriteErrorResponse(w, http.StatusServiceUnavailable, err.Error())
 			return
 		}
+		if errors.Is(err, services.ErrPermissionDenied) {
+			utils.WriteErrorResponse(w, http.StatusForbidden, err.Error())
+			return
+		}
 		utils.WriteErrorResponse(w, http.StatusInternalServerError, "failed to get chart data")
 		return
 	}
@@ -89,6 +103,14 @@ func (h *ChartHandler) GetConfigs(w http.ResponseWriter, r *http.Request) {
 
 	configs, err := h.chartService.GetChartConfigs(r.Context(), appID)
 	if err != nil {
+		if errors.Is(err, services.ErrAppNotFound) {
+			utils.WriteErrorResponse(w, http.StatusNotFound, err.Error())
+			return
+		}
+		if errors.Is(err, services.ErrPermissionDenied) {
+			utils.WriteErrorResponse(w, http.StatusForbidden, err.Error())
+			return
+		}
 		utils.WriteErrorResponse(w, http.StatusInternalServerError, "failed to get chart configs")
 		return
 	}
@@ -127,6 +149,10 @@ func (h *ChartHandler) SaveConfig(w http.ResponseWriter, r *http.Request) {
 			utils.WriteErrorResponse(w, http.StatusNotFound, err.Error())
 			return
 		}
+		if errors.Is(err, services.ErrPermissionDenied) {
+			utils.WriteErrorResponse(w, http.StatusForbidden, err.Error())
+			return
+		}
 		utils.WriteErrorResponse(w, http.StatusInternalServerError, "failed to save chart config")
 		return
 	}
@@ -152,6 +178,14 @@ func (h *ChartHandler) DeleteConfig(w http.ResponseWriter, r *http.Request) {
 			utils.WriteErrorResponse(w, http.StatusNotFound, err.Error())
 			return
 		}
+		if errors.Is(err, services.ErrAppNotFound) {
+			utils.WriteErrorResponse(w, http.StatusNotFound, err.Error())
+			return
+		}
+		if errors.Is(err, services.ErrPermissionDenied) {
+			utils.WriteErrorResponse(w, http.StatusForbidden, err.Error())
+			return
+		}
 		utils.WriteErrorResponse(w, http.StatusInternalServerError, "failed to delete chart config")
 		return
 	}
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -337,14 +338,13 @@ func TestChartHandler_GetConfigs_ServiceError(t *testing.T) {
 		mockService := new(mocks.MockChartService)
 		handler := handlers.NewChartHandler(mockService, validator)
 
-		mockService.On("GetChartConfigs", mock.Anything, uint64(999)).Return(nil, services.ErrAppNotFound)
+		mockService.On("GetChartConfigs", mock.Anything, uint64(999)).Return(nil, errors.New("database error"))
 
 		httpReq := httptest.NewRequest(http.MethodGet, "/api/v1/apps/999/charts/config", nil)
 		rr := httptest.NewRecorder()
 
 		handler.GetConfigs(rr, httpReq)
 
-		// Handler doesn't specifically check for ErrAppNotFound in GetConfigs
 		assert.Equal(t, http.StatusInternalServerError, rr.Code)
 
 		mockService.AssertExpectations(t)
@@ -120,8 +120,17 @@ func (h *DashboardWidgetHandler) Update(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	response, err := h.widgetService.UpdateWidget(r.Context(), claims.UserID, widgetID, &req)
+	version, err := requestVersion(r, req.Version)
 	if err != nil {
+		writeVersionError(w, r, err)
+		return
+	}
+
+	response, err := h.widgetService.UpdateWidget(r.Context(), claims.UserID, widgetID, version, &req)
+	if err != nil {
+		if writeVersionError(w, r, err) {
+			return
+		}
 		if strings.Contains(err.Error(), "見つかりません") {
 			utils.WriteErrorResponse(w, http.StatusNotFound, err.Error())
 			return
@@ -134,6 +143,7 @@ func (h *DashboardWidgetHandler) Update(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	setETag(w, response.Version)
 	utils.WriteJSON(w, http.StatusOK, response)
 }
 
@@ -157,7 +167,16 @@ func (h *DashboardWidgetHandler) Delete(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	if err := h.widgetService.DeleteWidget(r.Context(), claims.UserID, widgetID); err != nil {
+	version, err := requestVersion(r, nil)
+	if err != nil {
+		writeVersionError(w, r, err)
+		return
+	}
+
+	if err := h.widgetService.DeleteWidget(r.Context(), claims.UserID, widgetID, version); err != nil {
+		if writeVersionError(w, r, err) {
+			return
+		}
 		if strings.Contains(err.Error(), "見つかりません") {
 			utils.WriteErrorResponse(w, http.StatusNotFound, err.Error())
 			return
@@ -226,7 +226,7 @@ func TestDashboardWidgetHandler_Update(t *testing.T) {
 				IsVisible: &isVisible,
 			},
 			mockSetup: func(m *mocks.MockDashboardWidgetService) {
-				m.On("UpdateWidget", mock.Anything, uint64(1), uint64(1), mock.Anything).Return(&models.DashboardWidgetResponse{
+				m.On("UpdateWidget", mock.Anything, uint64(1), uint64(1), int64(1), mock.Anything).Return(&models.DashboardWidgetResponse{
 					ID:           1,
 					UserID:       1,
 					AppID:        1,
@@ -243,7 +243,7 @@ func TestDashboardWidgetHandler_Update(t *testing.T) {
 			path: "/api/v1/dashboard/widgets/999",
 			body: models.UpdateDashboardWidgetRequest{},
 			mockSetup: func(m *mocks.MockDashboardWidgetService) {
-				m.On("UpdateWidget", mock.Anything, uint64(1), uint64(999), mock.Anything).Return(nil, errors.New("ウィジェットが見つかりません"))
+				m.On("UpdateWidget", mock.Anything, uint64(1), uint64(999), int64(1), mock.Anything).Return(nil, errors.New("ウィジェットが見つかりません"))
 			},
 			expectedStatus: http.StatusNotFound,
 		},
@@ -252,7 +252,7 @@ func TestDashboardWidgetHandler_Update(t *testing.T) {
 			path: "/api/v1/dashboard/widgets/1",
 			body: models.UpdateDashboardWidgetRequest{},
 			mockSetup: func(m *mocks.MockDashboardWidgetService) {
-				m.On("UpdateWidget", mock.Anything, uint64(1), uint64(1), mock.Anything).Return(nil, errors.New("このウィジェットを更新する権限がありません"))
+				m.On("UpdateWidget", mock.Anything, uint64(1), uint64(1), int64(1), mock.Anything).Return(nil, errors.New("このウィジェットを更新する権限がありません"))
 			},
 			expectedStatus: http.StatusForbidden,
 		},
@@ -271,6 +271,7 @@ func TestDashboardWidgetHandler_Update(t *testing.T) {
 			tt.mockSetup(mockService)
 
 			req := createAuthenticatedRequest(http.MethodPut, tt.path, tt.body)
+			req.Header.Set("If-Match", `"1"`)
 			rr := httptest.NewRecorder()
 
 			handler.Update(rr, req)
@@ -293,7 +294,7 @@ func TestDashboardWidgetHandler_Delete(t *testing.T) {
 			name: "正常系_ウィジェット削除",
 			path: "/api/v1/dashboard/widgets/1",
 			mockSetup: func(m *mocks.MockDashboardWidgetService) {
-				m.On("DeleteWidget", mock.Anything, uint64(1), uint64(1)).Return(nil)
+				m.On("DeleteWidget", mock.Anything, uint64(1), uint64(1), int64(1)).Return(nil)
 			},
 			expectedStatus: http.StatusNoContent,
 		},
@@ -301,7 +302,7 @@ func TestDashboardWidgetHandler_Delete(t *testing.T) {
 			name: "異常系_ウィジェットなし",
 			path: "/api/v1/dashboard/widgets/999",
 			mockSetup: func(m *mocks.MockDashboardWidgetService) {
-				m.On("DeleteWidget", mock.Anything, uint64(1), uint64(999)).Return(errors.New("ウィジェットが見つかりません"))
+				m.On("DeleteWidget", mock.Anything, uint64(1), uint64(999), int64(1)).Return(errors.New("ウィジェットが見つかりません"))
 			},
 			expectedStatus: http.StatusNotFound,
 		},
@@ -309,7 +310,7 @@ func TestDashboardWidgetHandler_Delete(t *testing.T) {
 			name: "異常系_権限なし",
 			path: "/api/v1/dashboard/widgets/1",
 			mockSetup: func(m *mocks.MockDashboardWidgetService) {
-				m.On("DeleteWidget", mock.Anything, uint64(1), uint64(1)).Return(errors.New("このウィジェットを削除する権限がありません"))
+				m.On("DeleteWidget", mock.Anything, uint64(1), uint64(1), int64(1)).Return(errors.New("このウィジェットを削除する権限がありません"))
 			},
 			expectedStatus: http.StatusForbidden,
 		},
@@ -321,6 +322,7 @@ func TestDashboardWidgetHandler_Delete(t *testing.T) {
 			tt.mockSetup(mockService)
 
 			req := createAuthenticatedRequest(http.MethodDelete, tt.path, nil)
+			req.Header.Set("If-Match", `"1"`)
 			rr := httptest.NewRecorder()
 
 			handler.Delete(rr, req)
@@ -40,6 +40,14 @@ func (h *FieldHandler) List(w http.ResponseWriter, r *http.Request) {
 
 	fields, err := h.fieldService.GetFields(r.Context(), appID)
 	if err != nil {
+		if errors.Is(err, services.ErrAppNotFound) {
+			utils.WriteErrorResponse(w, http.StatusNotFound, err.Error())
+			return
+		}
+		if errors.Is(err, services.ErrPermissionDenied) {
+			utils.WriteErrorResponse(w, http.StatusForbidden, err.Error())
+			return
+		}
 		utils.WriteErrorResponse(w, http.StatusInternalServerError, "フィールドの取得に失敗しました")
 		return
 	}
@@ -72,10 +80,22 @@ func (h *FieldHandler) Create(w http.ResponseWriter, r *http.Request) {
 			utils.WriteErrorResponse(w, http.StatusConflict, err.Error())
 			return
 		}
+		if errors.Is(err, services.ErrInvalidFieldOptions) {
+			utils.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		if errors.Is(err, services.ErrDuplicateValue) {
+			utils.WriteErrorResponse(w, http.StatusConflict, err.Error())
+			return
+		}
 		if errors.Is(err, services.ErrAppNotFound) {
 			utils.WriteErrorResponse(w, http.StatusNotFound, err.Error())
 			return
 		}
+		if errors.Is(err, services.ErrPermissionDenied) {
+			utils.WriteErrorResponse(w, http.StatusForbidden, err.Error())
+			return
+		}
 		utils.WriteErrorResponse(w, http.StatusInternalServerError, "フィールドの作成に失敗しました")
 		return
 	}
@@ -102,16 +122,42 @@ func (h *FieldHandler) Update(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	field, err := h.fieldService.UpdateField(r.Context(), fieldID, &req)
+	version, err := requestVersion(r, req.Version)
 	if err != nil {
+		writeVersionError(w, r, err)
+		return
+	}
+
+	field, err := h.fieldService.UpdateField(r.Context(), fieldID, version, &req)
+	if err != nil {
+		if writeVersionError(w, r, err) {
+			return
+		}
 		if errors.Is(err, services.ErrFieldNotFound) {
 			utils.WriteErrorResponse(w, http.StatusNotFound, err.Error())
 			return
 		}
+		if errors.Is(err, services.ErrInvalidFieldOptions) {
+			utils.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		if errors.Is(err, services.ErrDuplicateValue) {
+			utils.WriteErrorResponse(w, http.StatusConflict, err.Error())
+			return
+		}
+		if errors.Is(err, services.ErrAppNotFound) {
+			utils.WriteErrorResponse(w, http.StatusNotFound, err.Error())
+			return
+		}
+		if errors.Is(err, services.ErrPermissionDenied) {
+			utils.WriteErrorResponse(w, http.StatusForbidden, err.Error())
+			return
+		}
 		utils.WriteErrorResponse(w, http.StatusInternalServerError, "フィールドの更新に失敗しました")
 		return
 	}
 
+	setETag(w, field.Version)
 	utils.WriteJSON(w, http.StatusOK, field)
 }
 
@@ -128,15 +174,32 @@ func (h *FieldHandler) Delete(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := h.fieldService.DeleteField(r.Context(), appID, fieldID); err != nil {
+	version, err := requestVersion(r, nil)
+	if err != nil {
+		writeVersionError(w, r, err)
+		return
+	}
+
+	if err := h.fieldService.DeleteField(r.Context(), appID, fieldID, version); err != nil {
+		if writeVersionError(w, r, err) {
+			return
+		}
 		if errors.Is(err, services.ErrFieldNotFound) {
 			utils.WriteErrorResponse(w, http.StatusNotFound, err.Error())
 			return
 		}
+		if errors.Is(err, services.ErrFieldInUse) {
+			utils.WriteErrorResponse(w, http.StatusConflict, err.Error())
+			return
+		}
 		if errors.Is(err, services.ErrAppNotFound) {
 			utils.WriteErrorResponse(w, http.StatusNotFound, err.Error())
 			return
 		}
+		if errors.Is(err, services.ErrPermissionDenied) {
+			utils.WriteErrorResponse(w, http.StatusForbidden, err.Error())
+			return
+		}
 		utils.WriteErrorResponse(w, http.StatusInternalServerError, "フィールドの削除に失敗しました")
 		return
 	}
@@ -164,6 +227,18 @@ func (h *FieldHandler) UpdateOrder(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if err := h.fieldService.UpdateFieldOrder(r.Context(), appID, &req); err != nil {
+		if errors.Is(err, services.ErrAppNotFound) {
+			utils.WriteErrorResponse(w, http.StatusNotFound, err.Error())
+			return
+		}
+		if errors.Is(err, services.ErrFieldNotFound) {
+			utils.WriteErrorResponse(w, http.StatusNotFound, err.Error())
+			return
+		}
+		if errors.Is(err, services.ErrPermissionDenied) {
+			utils.WriteErrorResponse(w, http.StatusForbidden, err.Error())
+			return
+		}
 		utils.WriteErrorResponse(w, http.StatusInternalServerError, "フィールド順序の更新に失敗しました")
 		return
 	}
@@ -171,6 +246,90 @@ func (h *FieldHandler) UpdateOrder(w http.ResponseWriter, r *http.Request) {
 	utils.WriteJSON(w, http.StatusOK, models.SuccessResponse{Message: "フィールド順序を更新しました"})
 }
 
+// PreviewConversion フィールドの種類を変換した場合に値を変換できるレコード数を返す
+func (h *FieldHandler) PreviewConversion(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		utils.WriteErrorResponse(w, http.StatusMethodNotAllowed, "メソッドが許可されていません")
+		return
+	}
+
+	appID, fieldID, err := extractAppAndFieldID(r.URL.Path)
+	if err != nil {
+		utils.WriteErrorResponse(w, http.StatusBadRequest, "無効なフィールドIDです")
+		return
+	}
+
+	var req models.ConvertFieldRequest
+	if err := h.validator.ParseAndValidate(r, &req); err != nil {
+		utils.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	preview, err := h.fieldService.PreviewFieldConversion(r.Context(), appID, fieldID, &req)
+	if err != nil {
+		if writeFieldConversionError(w, err) {
+			return
+		}
+		utils.WriteErrorResponse(w, http.StatusInternalServerError, "フィールドの変換の確認に失敗しました")
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, preview)
+}
+
+// Convert フィールドの種類を変換し、既存の値を変換する
+func (h *FieldHandler) Convert(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		utils.WriteErrorResponse(w, http.StatusMethodNotAllowed, "メソッドが許可されていません")
+		return
+	}
+
+	appID, fieldID, err := extractAppAndFieldID(r.URL.Path)
+	if err != nil {
+		utils.WriteErrorResponse(w, http.StatusBadRequest, "無効なフィールドIDです")
+		return
+	}
+
+	var req models.ConvertFieldRequest
+	if err := h.validator.ParseAndValidate(r, &req); err != nil {
+		utils.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	result, err := h.fieldService.ConvertField(r.Context(), appID, fieldID, &req)
+	if err != nil {
+		if writeFieldConversionError(w, err) {
+			return
+		}
+		utils.WriteErrorResponse(w, http.StatusInternalServerError, "フィールドの変換に失敗しました")
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, result)
+}
+
+// writeFieldConversionError フィールドの種類の変換のエラーをステータスコードに変換して書き込む（該当しない場合はfalse）
+func writeFieldConversionError(w http.ResponseWriter, err error) bool {
+	switch {
+	case errors.Is(err, services.ErrAppNotFound),
+		errors.Is(err, services.ErrFieldNotFound):
+		utils.WriteErrorResponse(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, services.ErrPermissionDenied),
+		errors.Is(err, services.ErrExternalAppReadOnly):
+		utils.WriteErrorResponse(w, http.StatusForbidden, err.Error())
+	case errors.Is(err, services.ErrInvalidConversion),
+		errors.Is(err, services.ErrInvalidFieldOptions):
+		utils.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, services.ErrFieldCodeExists),
+		errors.Is(err, services.ErrUnconvertibleValues),
+		errors.Is(err, services.ErrDuplicateValue):
+		utils.WriteErrorResponse(w, http.StatusConflict, err.Error())
+	default:
+		return false
+	}
+	return true
+}
+
 // extractAppIDFromFieldPath URLパスからアプリIDを抽出する
 // 期待されるパス形式: /api/v1/apps/{appId}/fields
 func extractAppIDFromFieldPath(path string) (uint64, error) {
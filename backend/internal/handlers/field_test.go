@@ -3,6 +3,7 @@ package handlers_test
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -158,6 +159,30 @@ func TestFieldHandler_Create(t *testing.T) {
 		mockService.AssertExpectations(t)
 	})
 
+	t.Run("invalid reference options", func(t *testing.T) {
+		mockService := new(mocks.MockFieldService)
+		handler := handlers.NewFieldHandler(mockService, validator)
+
+		req := models.CreateFieldRequest{
+			FieldCode: "customer",
+			FieldName: "Customer",
+			FieldType: "reference",
+		}
+
+		mockService.On("CreateField", mock.Anything, uint64(1), mock.AnythingOfType("*models.CreateFieldRequest")).Return(nil, services.ErrInvalidFieldOptions)
+
+		body, _ := json.Marshal(req)
+		httpReq := httptest.NewRequest(http.MethodPost, "/api/v1/apps/1/fields", bytes.NewReader(body))
+		httpReq.Header.Set("Content-Type", "application/json")
+		rr := httptest.NewRecorder()
+
+		handler.Create(rr, httpReq)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+
+		mockService.AssertExpectations(t)
+	})
+
 	t.Run("method not allowed", func(t *testing.T) {
 		mockService := new(mocks.MockFieldService)
 		handler := handlers.NewFieldHandler(mockService, validator)
@@ -188,10 +213,11 @@ func TestFieldHandler_Update(t *testing.T) {
 			FieldType: "TEXT",
 		}
 
-		mockService.On("UpdateField", mock.Anything, uint64(1), mock.AnythingOfType("*models.UpdateFieldRequest")).Return(resp, nil)
+		mockService.On("UpdateField", mock.Anything, uint64(1), int64(1), mock.AnythingOfType("*models.UpdateFieldRequest")).Return(resp, nil)
 
 		body, _ := json.Marshal(req)
 		httpReq := httptest.NewRequest(http.MethodPut, "/api/v1/apps/1/fields/1", bytes.NewReader(body))
+		httpReq.Header.Set("If-Match", `"1"`)
 		httpReq.Header.Set("Content-Type", "application/json")
 		rr := httptest.NewRecorder()
 
@@ -212,10 +238,11 @@ func TestFieldHandler_Update(t *testing.T) {
 		handler := handlers.NewFieldHandler(mockService, validator)
 
 		req := models.UpdateFieldRequest{FieldName: "Updated"}
-		mockService.On("UpdateField", mock.Anything, uint64(999), mock.AnythingOfType("*models.UpdateFieldRequest")).Return(nil, services.ErrFieldNotFound)
+		mockService.On("UpdateField", mock.Anything, uint64(999), int64(1), mock.AnythingOfType("*models.UpdateFieldRequest")).Return(nil, services.ErrFieldNotFound)
 
 		body, _ := json.Marshal(req)
 		httpReq := httptest.NewRequest(http.MethodPut, "/api/v1/apps/1/fields/999", bytes.NewReader(body))
+		httpReq.Header.Set("If-Match", `"1"`)
 		httpReq.Header.Set("Content-Type", "application/json")
 		rr := httptest.NewRecorder()
 
@@ -246,9 +273,10 @@ func TestFieldHandler_Delete(t *testing.T) {
 		mockService := new(mocks.MockFieldService)
 		handler := handlers.NewFieldHandler(mockService, validator)
 
-		mockService.On("DeleteField", mock.Anything, uint64(1), uint64(1)).Return(nil)
+		mockService.On("DeleteField", mock.Anything, uint64(1), uint64(1), int64(1)).Return(nil)
 
 		httpReq := httptest.NewRequest(http.MethodDelete, "/api/v1/apps/1/fields/1", nil)
+		httpReq.Header.Set("If-Match", `"1"`)
 		rr := httptest.NewRecorder()
 
 		handler.Delete(rr, httpReq)
@@ -267,9 +295,10 @@ func TestFieldHandler_Delete(t *testing.T) {
 		mockService := new(mocks.MockFieldService)
 		handler := handlers.NewFieldHandler(mockService, validator)
 
-		mockService.On("DeleteField", mock.Anything, uint64(1), uint64(999)).Return(services.ErrFieldNotFound)
+		mockService.On("DeleteField", mock.Anything, uint64(1), uint64(999), int64(1)).Return(services.ErrFieldNotFound)
 
 		httpReq := httptest.NewRequest(http.MethodDelete, "/api/v1/apps/1/fields/999", nil)
+		httpReq.Header.Set("If-Match", `"1"`)
 		rr := httptest.NewRecorder()
 
 		handler.Delete(rr, httpReq)
@@ -279,6 +308,23 @@ func TestFieldHandler_Delete(t *testing.T) {
 		mockService.AssertExpectations(t)
 	})
 
+	t.Run("field in use", func(t *testing.T) {
+		mockService := new(mocks.MockFieldService)
+		handler := handlers.NewFieldHandler(mockService, validator)
+
+		mockService.On("DeleteField", mock.Anything, uint64(1), uint64(2), int64(1)).Return(services.ErrFieldInUse)
+
+		httpReq := httptest.NewRequest(http.MethodDelete, "/api/v1/apps/1/fields/2", nil)
+		httpReq.Header.Set("If-Match", `"1"`)
+		rr := httptest.NewRecorder()
+
+		handler.Delete(rr, httpReq)
+
+		assert.Equal(t, http.StatusConflict, rr.Code)
+
+		mockService.AssertExpectations(t)
+	})
+
 	t.Run("method not allowed", func(t *testing.T) {
 		mockService := new(mocks.MockFieldService)
 		handler := handlers.NewFieldHandler(mockService, validator)
@@ -319,9 +365,10 @@ func TestFieldHandler_Delete(t *testing.T) {
 		mockService := new(mocks.MockFieldService)
 		handler := handlers.NewFieldHandler(mockService, validator)
 
-		mockService.On("DeleteField", mock.Anything, uint64(999), uint64(1)).Return(services.ErrAppNotFound)
+		mockService.On("DeleteField", mock.Anything, uint64(999), uint64(1), int64(1)).Return(services.ErrAppNotFound)
 
 		httpReq := httptest.NewRequest(http.MethodDelete, "/api/v1/apps/999/fields/1", nil)
+		httpReq.Header.Set("If-Match", `"1"`)
 		rr := httptest.NewRecorder()
 
 		handler.Delete(rr, httpReq)
@@ -412,7 +459,7 @@ func TestFieldHandler_UpdateOrder(t *testing.T) {
 			},
 		}
 
-		mockService.On("UpdateFieldOrder", mock.Anything, uint64(1), mock.AnythingOfType("*models.UpdateFieldOrderRequest")).Return(services.ErrAppNotFound)
+		mockService.On("UpdateFieldOrder", mock.Anything, uint64(1), mock.AnythingOfType("*models.UpdateFieldOrderRequest")).Return(errors.New("database error"))
 
 		body, _ := json.Marshal(req)
 		httpReq := httptest.NewRequest(http.MethodPut, "/api/v1/apps/1/fields/order", bytes.NewReader(body))
@@ -421,7 +468,6 @@ func TestFieldHandler_UpdateOrder(t *testing.T) {
 
 		handler.UpdateOrder(rr, httpReq)
 
-		// Handler doesn't specifically check for ErrAppNotFound in UpdateOrder
 		assert.Equal(t, http.StatusInternalServerError, rr.Code)
 
 		mockService.AssertExpectations(t)
@@ -479,6 +525,7 @@ func TestFieldHandler_Update_AdditionalCases(t *testing.T) {
 		handler := handlers.NewFieldHandler(mockService, validator)
 
 		httpReq := httptest.NewRequest(http.MethodPut, "/api/v1/apps/1/fields/1", bytes.NewReader([]byte("invalid json")))
+		httpReq.Header.Set("If-Match", `"1"`)
 		httpReq.Header.Set("Content-Type", "application/json")
 		rr := httptest.NewRecorder()
 
@@ -495,16 +542,160 @@ func TestFieldHandler_List_ServiceError(t *testing.T) {
 		mockService := new(mocks.MockFieldService)
 		handler := handlers.NewFieldHandler(mockService, validator)
 
-		mockService.On("GetFields", mock.Anything, uint64(999)).Return(nil, services.ErrAppNotFound)
+		mockService.On("GetFields", mock.Anything, uint64(999)).Return(nil, errors.New("database error"))
 
 		httpReq := httptest.NewRequest(http.MethodGet, "/api/v1/apps/999/fields", nil)
 		rr := httptest.NewRecorder()
 
 		handler.List(rr, httpReq)
 
-		// Handler doesn't specifically check for ErrAppNotFound in List
 		assert.Equal(t, http.StatusInternalServerError, rr.Code)
 
 		mockService.AssertExpectations(t)
 	})
 }
+
+func TestFieldHandler_List_AccessErrors(t *testing.T) {
+	validator := utils.NewValidator()
+
+	tests := []struct {
+		name     string
+		err      error
+		expected int
+	}{
+		{"app not found", services.ErrAppNotFound, http.StatusNotFound},
+		{"permission denied", services.ErrPermissionDenied, http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(mocks.MockFieldService)
+			handler := handlers.NewFieldHandler(mockService, validator)
+
+			mockService.On("GetFields", mock.Anything, uint64(1)).Return(nil, tt.err)
+
+			httpReq := httptest.NewRequest(http.MethodGet, "/api/v1/apps/1/fields", nil)
+			rr := httptest.NewRecorder()
+
+			handler.List(rr, httpReq)
+
+			assert.Equal(t, tt.expected, rr.Code)
+		})
+	}
+}
+
+func TestFieldHandler_PreviewConversion(t *testing.T) {
+	validator := utils.NewValidator()
+
+	t.Run("successful preview", func(t *testing.T) {
+		mockService := new(mocks.MockFieldService)
+		handler := handlers.NewFieldHandler(mockService, validator)
+
+		mockService.On("PreviewFieldConversion", mock.Anything, uint64(1), uint64(3), mock.MatchedBy(func(req *models.ConvertFieldRequest) bool {
+			return req.FieldType == "number"
+		})).Return(&models.FieldConversionPreview{
+			FieldType: "number", Total: 10, Empty: 2, Convertible: 7, Unconvertible: 1,
+			Samples: []models.FieldConversionSample{{RecordID: 5, Value: "未定"}},
+		}, nil)
+
+		body, _ := json.Marshal(map[string]interface{}{"field_type": "number"})
+		httpReq := httptest.NewRequest(http.MethodPost, "/api/v1/apps/1/fields/3/convert/preview", bytes.NewBuffer(body))
+		rr := httptest.NewRecorder()
+
+		handler.PreviewConversion(rr, httpReq)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		var result models.FieldConversionPreview
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &result))
+		assert.Equal(t, int64(7), result.Convertible)
+		require.Len(t, result.Samples, 1)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("unsupported field type", func(t *testing.T) {
+		mockService := new(mocks.MockFieldService)
+		handler := handlers.NewFieldHandler(mockService, validator)
+
+		body, _ := json.Marshal(map[string]interface{}{"field_type": "attachment"})
+		httpReq := httptest.NewRequest(http.MethodPost, "/api/v1/apps/1/fields/3/convert/preview", bytes.NewBuffer(body))
+		rr := httptest.NewRecorder()
+
+		handler.PreviewConversion(rr, httpReq)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+		mockService.AssertNotCalled(t, "PreviewFieldConversion", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestFieldHandler_Convert(t *testing.T) {
+	validator := utils.NewValidator()
+
+	t.Run("successful conversion", func(t *testing.T) {
+		mockService := new(mocks.MockFieldService)
+		handler := handlers.NewFieldHandler(mockService, validator)
+
+		mockService.On("ConvertField", mock.Anything, uint64(1), uint64(3), mock.MatchedBy(func(req *models.ConvertFieldRequest) bool {
+			return req.FieldType == "number" && req.BackupFieldCode == "amount_text"
+		})).Return(&models.ConvertFieldResponse{
+			Field:         &models.FieldResponse{ID: 3, FieldCode: "amount", FieldType: "number"},
+			Unconvertible: 1,
+			BackupField:   &models.FieldResponse{ID: 9, FieldCode: "amount_text", FieldType: "textarea"},
+		}, nil)
+
+		body, _ := json.Marshal(map[string]interface{}{"field_type": "number", "backup_field_code": "amount_text"})
+		httpReq := httptest.NewRequest(http.MethodPost, "/api/v1/apps/1/fields/3/convert", bytes.NewBuffer(body))
+		rr := httptest.NewRecorder()
+
+		handler.Convert(rr, httpReq)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		var result models.ConvertFieldResponse
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &result))
+		assert.Equal(t, "number", result.Field.FieldType)
+		assert.Equal(t, "amount_text", result.BackupField.FieldCode)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("service errors", func(t *testing.T) {
+		tests := []struct {
+			name   string
+			err    error
+			status int
+		}{
+			{name: "unconvertible values", err: services.ErrUnconvertibleValues, status: http.StatusConflict},
+			{name: "backup field code exists", err: services.ErrFieldCodeExists, status: http.StatusConflict},
+			{name: "duplicate values", err: services.ErrDuplicateValue, status: http.StatusConflict},
+			{name: "invalid conversion", err: services.ErrInvalidConversion, status: http.StatusBadRequest},
+			{name: "external app", err: services.ErrExternalAppReadOnly, status: http.StatusForbidden},
+			{name: "field not found", err: services.ErrFieldNotFound, status: http.StatusNotFound},
+			{name: "internal error", err: errors.New("db error"), status: http.StatusInternalServerError},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				mockService := new(mocks.MockFieldService)
+				handler := handlers.NewFieldHandler(mockService, validator)
+
+				mockService.On("ConvertField", mock.Anything, uint64(1), uint64(3), mock.Anything).Return(nil, tt.err)
+
+				body, _ := json.Marshal(map[string]interface{}{"field_type": "number"})
+				httpReq := httptest.NewRequest(http.MethodPost, "/api/v1/apps/1/fields/3/convert", bytes.NewBuffer(body))
+				rr := httptest.NewRecorder()
+
+				handler.Convert(rr, httpReq)
+
+				assert.Equal(t, tt.status, rr.Code)
+			})
+		}
+	})
+
+	t.Run("method not allowed", func(t *testing.T) {
+		handler := handlers.NewFieldHandler(new(mocks.MockFieldService), validator)
+
+		httpReq := httptest.NewRequest(http.MethodGet, "/api/v1/apps/1/fields/3/convert", nil)
+		rr := httptest.NewRecorder()
+
+		handler.Convert(rr, httpReq)
+
+		assert.Equal(t, http.StatusMethodNotAllowed, rr.Code)
+	})
+}
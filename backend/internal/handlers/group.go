@@ -0,0 +1,243 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"nocode-app/backend/internal/models"
+	"nocode-app/backend/internal/services"
+	"nocode-app/backend/internal/utils"
+)
+
+// GroupHandler ユーザーグループエンドポイントを処理する構造体
+type GroupHandler struct {
+	groupService services.GroupServiceInterface
+	validator    *utils.Validator
+}
+
+// NewGroupHandler 新しいGroupHandlerを作成する
+func NewGroupHandler(groupService services.GroupServiceInterface, validator *utils.Validator) *GroupHandler {
+	return &GroupHandler{
+		groupService: groupService,
+		validator:    validator,
+	}
+}
+
+// List 全グループを一覧表示する
+func (h *GroupHandler) List(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		utils.WriteErrorResponse(w, http.StatusMethodNotAllowed, "メソッドが許可されていません")
+		return
+	}
+
+	resp, err := h.groupService.GetGroups(r.Context())
+	if err != nil {
+		utils.WriteErrorResponse(w, http.StatusInternalServerError, "グループの取得に失敗しました")
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, resp)
+}
+
+// Get グループをメンバー付きで取得する
+func (h *GroupHandler) Get(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		utils.WriteErrorResponse(w, http.StatusMethodNotAllowed, "メソッドが許可されていません")
+		return
+	}
+
+	groupID, err := extractGroupID(r.URL.Path)
+	if err != nil {
+		utils.WriteErrorResponse(w, http.StatusBadRequest, "無効なグループIDです")
+		return
+	}
+
+	resp, err := h.groupService.GetGroup(r.Context(), groupID)
+	if err != nil {
+		if errors.Is(err, services.ErrGroupNotFound) {
+			utils.WriteErrorResponse(w, http.StatusNotFound, err.Error())
+			return
+		}
+		utils.WriteErrorResponse(w, http.StatusInternalServerError, "グループの取得に失敗しました")
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, resp)
+}
+
+// Create 新しいグループを作成する
+func (h *GroupHandler) Create(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		utils.WriteErrorResponse(w, http.StatusMethodNotAllowed, "メソッドが許可されていません")
+		return
+	}
+
+	var req models.CreateGroupRequest
+	if err := h.validator.ParseAndValidate(r, &req); err != nil {
+		utils.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	resp, err := h.groupService.CreateGroup(r.Context(), &req)
+	if err != nil {
+		if errors.Is(err, services.ErrGroupNameExists) {
+			utils.WriteErrorResponse(w, http.StatusConflict, err.Error())
+			return
+		}
+		utils.WriteErrorResponse(w, http.StatusInternalServerError, "グループの作成に失敗しました")
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusCreated, resp)
+}
+
+// Update グループを更新する
+func (h *GroupHandler) Update(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPut {
+		utils.WriteErrorResponse(w, http.StatusMethodNotAllowed, "メソッドが許可されていません")
+		return
+	}
+
+	groupID, err := extractGroupID(r.URL.Path)
+	if err != nil {
+		utils.WriteErrorResponse(w, http.StatusBadRequest, "無効なグループIDです")
+		return
+	}
+
+	var req models.UpdateGroupRequest
+	if err := h.validator.ParseAndValidate(r, &req); err != nil {
+		utils.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	resp, err := h.groupService.UpdateGroup(r.Context(), groupID, &req)
+	if err != nil {
+		if errors.Is(err, services.ErrGroupNotFound) {
+			utils.WriteErrorResponse(w, http.StatusNotFound, err.Error())
+			return
+		}
+		if errors.Is(err, services.ErrGroupNameExists) {
+			utils.WriteErrorResponse(w, http.StatusConflict, err.Error())
+			return
+		}
+		utils.WriteErrorResponse(w, http.StatusInternalServerError, "グループの更新に失敗しました")
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, resp)
+}
+
+// Delete グループを削除する
+func (h *GroupHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		utils.WriteErrorResponse(w, http.StatusMethodNotAllowed, "メソッドが許可されていません")
+		return
+	}
+
+	groupID, err := extractGroupID(r.URL.Path)
+	if err != nil {
+		utils.WriteErrorResponse(w, http.StatusBadRequest, "無効なグループIDです")
+		return
+	}
+
+	if err := h.groupService.DeleteGroup(r.Context(), groupID); err != nil {
+		if errors.Is(err, services.ErrGroupNotFound) {
+			utils.WriteErrorResponse(w, http.StatusNotFound, err.Error())
+			return
+		}
+		utils.WriteErrorResponse(w, http.StatusInternalServerError, "グループの削除に失敗しました")
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, models.SuccessResponse{Message: "グループを削除しました"})
+}
+
+// AddMember グループにメンバーを追加する
+func (h *GroupHandler) AddMember(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		utils.WriteErrorResponse(w, http.StatusMethodNotAllowed, "メソッドが許可されていません")
+		return
+	}
+
+	groupID, err := extractGroupID(r.URL.Path)
+	if err != nil {
+		utils.WriteErrorResponse(w, http.StatusBadRequest, "無効なグループIDです")
+		return
+	}
+
+	var req models.AddGroupMemberRequest
+	if err := h.validator.ParseAndValidate(r, &req); err != nil {
+		utils.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	resp, err := h.groupService.AddMember(r.Context(), groupID, &req)
+	if err != nil {
+		if errors.Is(err, services.ErrGroupNotFound) || errors.Is(err, services.ErrUserNotFound) {
+			utils.WriteErrorResponse(w, http.StatusNotFound, err.Error())
+			return
+		}
+		utils.WriteErrorResponse(w, http.StatusInternalServerError, "メンバーの追加に失敗しました")
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, resp)
+}
+
+// RemoveMember グループからメンバーを削除する
+func (h *GroupHandler) RemoveMember(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		utils.WriteErrorResponse(w, http.StatusMethodNotAllowed, "メソッドが許可されていません")
+		return
+	}
+
+	groupID, userID, err := extractGroupAndMemberID(r.URL.Path)
+	if err != nil {
+		utils.WriteErrorResponse(w, http.StatusBadRequest, "無効なユーザーIDです")
+		return
+	}
+
+	if err := h.groupService.RemoveMember(r.Context(), groupID, userID); err != nil {
+		if errors.Is(err, services.ErrGroupNotFound) {
+			utils.WriteErrorResponse(w, http.StatusNotFound, err.Error())
+			return
+		}
+		utils.WriteErrorResponse(w, http.StatusInternalServerError, "メンバーの削除に失敗しました")
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, models.SuccessResponse{Message: "メンバーを削除しました"})
+}
+
+// extractGroupID URLパスからグループIDを抽出する
+// 想定パス形式: /api/v1/groups/{groupId}
+func extractGroupID(path string) (uint64, error) {
+	parts := strings.Split(strings.Trim(path, "/"), "/")
+	if len(parts) < 4 {
+		return 0, errors.New("無効なパスです")
+	}
+	return strconv.ParseUint(parts[3], 10, 64)
+}
+
+// extractGroupAndMemberID URLパスからグループIDとユーザーIDを抽出する
+// 想定パス形式: /api/v1/groups/{groupId}/members/{userId}
+func extractGroupAndMemberID(path string) (uint64, uint64, error) {
+	parts := strings.Split(strings.Trim(path, "/"), "/")
+	if len(parts) < 6 {
+		return 0, 0, errors.New("無効なパスです")
+	}
+
+	groupID, err := strconv.ParseUint(parts[3], 10, 64)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	userID, err := strconv.ParseUint(parts[5], 10, 64)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	return groupID, userID, nil
+}
@@ -0,0 +1,164 @@
+package handlers_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"nocode-app/backend/internal/handlers"
+	"nocode-app/backend/internal/models"
+	"nocode-app/backend/internal/services"
+	"nocode-app/backend/internal/testhelpers/mocks"
+	"nocode-app/backend/internal/utils"
+)
+
+func TestGroupHandler_List(t *testing.T) {
+	validator := utils.NewValidator()
+
+	mockService := new(mocks.MockGroupService)
+	handler := handlers.NewGroupHandler(mockService, validator)
+
+	mockService.On("GetGroups", mock.Anything).Return(&models.GroupListResponse{
+		Groups: []models.GroupResponse{{ID: 1, Name: "Sales"}},
+	}, nil)
+
+	httpReq := httptest.NewRequest(http.MethodGet, "/api/v1/groups", nil)
+	rr := httptest.NewRecorder()
+
+	handler.List(rr, httpReq)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+
+	var result models.GroupListResponse
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &result))
+	assert.Len(t, result.Groups, 1)
+}
+
+func TestGroupHandler_Create(t *testing.T) {
+	validator := utils.NewValidator()
+
+	t.Run("successful create", func(t *testing.T) {
+		mockService := new(mocks.MockGroupService)
+		handler := handlers.NewGroupHandler(mockService, validator)
+
+		mockService.On("CreateGroup", mock.Anything, mock.AnythingOfType("*models.CreateGroupRequest")).
+			Return(&models.GroupResponse{ID: 1, Name: "Sales"}, nil)
+
+		body, _ := json.Marshal(models.CreateGroupRequest{Name: "Sales"})
+		httpReq := httptest.NewRequest(http.MethodPost, "/api/v1/groups", bytes.NewReader(body))
+		httpReq.Header.Set("Content-Type", "application/json")
+		rr := httptest.NewRecorder()
+
+		handler.Create(rr, httpReq)
+
+		assert.Equal(t, http.StatusCreated, rr.Code)
+	})
+
+	t.Run("missing name", func(t *testing.T) {
+		mockService := new(mocks.MockGroupService)
+		handler := handlers.NewGroupHandler(mockService, validator)
+
+		body, _ := json.Marshal(map[string]string{})
+		httpReq := httptest.NewRequest(http.MethodPost, "/api/v1/groups", bytes.NewReader(body))
+		httpReq.Header.Set("Content-Type", "application/json")
+		rr := httptest.NewRecorder()
+
+		handler.Create(rr, httpReq)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+
+	t.Run("duplicate name", func(t *testing.T) {
+		mockService := new(mocks.MockGroupService)
+		handler := handlers.NewGroupHandler(mockService, validator)
+
+		mockService.On("CreateGroup", mock.Anything, mock.AnythingOfType("*models.CreateGroupRequest")).
+			Return(nil, services.ErrGroupNameExists)
+
+		body, _ := json.Marshal(models.CreateGroupRequest{Name: "Sales"})
+		httpReq := httptest.NewRequest(http.MethodPost, "/api/v1/groups", bytes.NewReader(body))
+		httpReq.Header.Set("Content-Type", "application/json")
+		rr := httptest.NewRecorder()
+
+		handler.Create(rr, httpReq)
+
+		assert.Equal(t, http.StatusConflict, rr.Code)
+	})
+}
+
+func TestGroupHandler_AddMember(t *testing.T) {
+	validator := utils.NewValidator()
+
+	t.Run("successful add", func(t *testing.T) {
+		mockService := new(mocks.MockGroupService)
+		handler := handlers.NewGroupHandler(mockService, validator)
+
+		mockService.On("AddMember", mock.Anything, uint64(1), &models.AddGroupMemberRequest{UserID: 2}).
+			Return(&models.GroupResponse{ID: 1, Members: []models.UserResponse{{ID: 2}}}, nil)
+
+		body, _ := json.Marshal(models.AddGroupMemberRequest{UserID: 2})
+		httpReq := httptest.NewRequest(http.MethodPost, "/api/v1/groups/1/members", bytes.NewReader(body))
+		httpReq.Header.Set("Content-Type", "application/json")
+		rr := httptest.NewRecorder()
+
+		handler.AddMember(rr, httpReq)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("user not found", func(t *testing.T) {
+		mockService := new(mocks.MockGroupService)
+		handler := handlers.NewGroupHandler(mockService, validator)
+
+		mockService.On("AddMember", mock.Anything, uint64(1), mock.Anything).Return(nil, services.ErrUserNotFound)
+
+		body, _ := json.Marshal(models.AddGroupMemberRequest{UserID: 999})
+		httpReq := httptest.NewRequest(http.MethodPost, "/api/v1/groups/1/members", bytes.NewReader(body))
+		httpReq.Header.Set("Content-Type", "application/json")
+		rr := httptest.NewRecorder()
+
+		handler.AddMember(rr, httpReq)
+
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+	})
+}
+
+func TestGroupHandler_RemoveMember(t *testing.T) {
+	validator := utils.NewValidator()
+
+	mockService := new(mocks.MockGroupService)
+	handler := handlers.NewGroupHandler(mockService, validator)
+
+	mockService.On("RemoveMember", mock.Anything, uint64(1), uint64(2)).Return(nil)
+
+	httpReq := httptest.NewRequest(http.MethodDelete, "/api/v1/groups/1/members/2", nil)
+	rr := httptest.NewRecorder()
+
+	handler.RemoveMember(rr, httpReq)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestGroupHandler_Delete_NotFound(t *testing.T) {
+	validator := utils.NewValidator()
+
+	mockService := new(mocks.MockGroupService)
+	handler := handlers.NewGroupHandler(mockService, validator)
+
+	mockService.On("DeleteGroup", mock.Anything, uint64(999)).Return(services.ErrGroupNotFound)
+
+	httpReq := httptest.NewRequest(http.MethodDelete, "/api/v1/groups/999", nil)
+	rr := httptest.NewRecorder()
+
+	handler.Delete(rr, httpReq)
+
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+}
@@ -0,0 +1,179 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"nocode-app/backend/internal/middleware"
+	"nocode-app/backend/internal/models"
+	"nocode-app/backend/internal/services"
+	"nocode-app/backend/internal/utils"
+)
+
+// IndexHandler 動的テーブルのインデックスエンドポイントを処理する構造体
+type IndexHandler struct {
+	indexService services.IndexServiceInterface
+	validator    *utils.Validator
+}
+
+// NewIndexHandler 新しいIndexHandlerを作成する
+func NewIndexHandler(indexService services.IndexServiceInterface, validator *utils.Validator) *IndexHandler {
+	return &IndexHandler{
+		indexService: indexService,
+		validator:    validator,
+	}
+}
+
+// List アプリに作成したインデックスを一覧表示する
+func (h *IndexHandler) List(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		utils.WriteErrorResponse(w, http.StatusMethodNotAllowed, "メソッドが許可されていません")
+		return
+	}
+
+	appID, err := extractAppID(r.URL.Path)
+	if err != nil {
+		utils.WriteErrorResponse(w, http.StatusBadRequest, "無効なアプリIDです")
+		return
+	}
+
+	indexes, err := h.indexService.GetIndexes(r.Context(), appID)
+	if err != nil {
+		if writeIndexError(w, err) {
+			return
+		}
+		utils.WriteErrorResponse(w, http.StatusInternalServerError, "インデックスの取得に失敗しました")
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, map[string]interface{}{"indexes": indexes})
+}
+
+// Create アプリの動的テーブルにインデックスを作成する
+func (h *IndexHandler) Create(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		utils.WriteErrorResponse(w, http.StatusMethodNotAllowed, "メソッドが許可されていません")
+		return
+	}
+
+	claims, ok := middleware.GetUserFromContext(r.Context())
+	if !ok {
+		utils.WriteErrorResponse(w, http.StatusUnauthorized, "認証されていません")
+		return
+	}
+
+	appID, err := extractAppID(r.URL.Path)
+	if err != nil {
+		utils.WriteErrorResponse(w, http.StatusBadRequest, "無効なアプリIDです")
+		return
+	}
+
+	var req models.CreateIndexRequest
+	if err := h.validator.ParseAndValidate(r, &req); err != nil {
+		utils.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	index, err := h.indexService.CreateIndex(r.Context(), appID, claims.UserID, &req)
+	if err != nil {
+		if writeIndexError(w, err) {
+			return
+		}
+		utils.WriteErrorResponse(w, http.StatusInternalServerError, "インデックスの作成に失敗しました")
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusCreated, index)
+}
+
+// Delete アプリの動的テーブルからインデックスを削除する
+func (h *IndexHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		utils.WriteErrorResponse(w, http.StatusMethodNotAllowed, "メソッドが許可されていません")
+		return
+	}
+
+	appID, indexID, err := extractAppAndIndexID(r.URL.Path)
+	if err != nil {
+		utils.WriteErrorResponse(w, http.StatusBadRequest, "無効なインデックスIDです")
+		return
+	}
+
+	if err := h.indexService.DeleteIndex(r.Context(), appID, indexID); err != nil {
+		if writeIndexError(w, err) {
+			return
+		}
+		utils.WriteErrorResponse(w, http.StatusInternalServerError, "インデックスの削除に失敗しました")
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, models.SuccessResponse{Message: "インデックスを削除しました"})
+}
+
+// Suggestions 保存済みのビューから作成を勧めるインデックスを一覧表示する
+func (h *IndexHandler) Suggestions(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		utils.WriteErrorResponse(w, http.StatusMethodNotAllowed, "メソッドが許可されていません")
+		return
+	}
+
+	appID, err := extractAppID(r.URL.Path)
+	if err != nil {
+		utils.WriteErrorResponse(w, http.StatusBadRequest, "無効なアプリIDです")
+		return
+	}
+
+	suggestions, err := h.indexService.SuggestIndexes(r.Context(), appID)
+	if err != nil {
+		if writeIndexError(w, err) {
+			return
+		}
+		utils.WriteErrorResponse(w, http.StatusInternalServerError, "インデックスの提案の取得に失敗しました")
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, map[string]interface{}{"suggestions": suggestions})
+}
+
+// writeIndexError インデックス操作のエラーをステータスコードに変換して書き込む（該当しない場合はfalse）
+func writeIndexError(w http.ResponseWriter, err error) bool {
+	switch {
+	case errors.Is(err, services.ErrAppNotFound),
+		errors.Is(err, services.ErrIndexNotFound):
+		utils.WriteErrorResponse(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, services.ErrPermissionDenied),
+		errors.Is(err, services.ErrExternalAppReadOnly):
+		utils.WriteErrorResponse(w, http.StatusForbidden, err.Error())
+	case errors.Is(err, services.ErrInvalidIndex):
+		utils.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, services.ErrIndexExists),
+		errors.Is(err, services.ErrDuplicateValue):
+		utils.WriteErrorResponse(w, http.StatusConflict, err.Error())
+	default:
+		return false
+	}
+	return true
+}
+
+// extractAppAndIndexID URLパスからアプリIDとインデックスIDを抽出する
+// 想定パス形式: /api/v1/apps/{appId}/indexes/{indexId}
+func extractAppAndIndexID(path string) (uint64, uint64, error) {
+	parts := strings.Split(strings.Trim(path, "/"), "/")
+	if len(parts) < 6 {
+		return 0, 0, errors.New("無効なパスです")
+	}
+
+	appID, err := strconv.ParseUint(parts[3], 10, 64)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	indexID, err := strconv.ParseUint(parts[5], 10, 64)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	return appID, indexID, nil
+}
@@ -0,0 +1,210 @@
+package handlers_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"nocode-app/backend/internal/handlers"
+	"nocode-app/backend/internal/models"
+	"nocode-app/backend/internal/services"
+	"nocode-app/backend/internal/testhelpers/mocks"
+	"nocode-app/backend/internal/utils"
+)
+
+func TestIndexHandler_List(t *testing.T) {
+	validator := utils.NewValidator()
+
+	t.Run("successful list indexes", func(t *testing.T) {
+		mockService := new(mocks.MockIndexService)
+		handler := handlers.NewIndexHandler(mockService, validator)
+
+		mockService.On("GetIndexes", mock.Anything, uint64(1)).Return([]models.IndexResponse{
+			{ID: 1, AppID: 1, IndexName: "app_data_1_status_idx", FieldCodes: []string{"status"}, SizeBytes: 16384, Scans: 3, Valid: true},
+		}, nil)
+
+		httpReq := httptest.NewRequest(http.MethodGet, "/api/v1/apps/1/indexes", nil)
+		rr := httptest.NewRecorder()
+
+		handler.List(rr, httpReq)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+
+		var result map[string][]models.IndexResponse
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &result))
+		require.Len(t, result["indexes"], 1)
+		assert.Equal(t, int64(16384), result["indexes"][0].SizeBytes)
+
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("external app", func(t *testing.T) {
+		mockService := new(mocks.MockIndexService)
+		handler := handlers.NewIndexHandler(mockService, validator)
+
+		mockService.On("GetIndexes", mock.Anything, uint64(2)).Return(nil, services.ErrExternalAppReadOnly)
+
+		httpReq := httptest.NewRequest(http.MethodGet, "/api/v1/apps/2/indexes", nil)
+		rr := httptest.NewRecorder()
+
+		handler.List(rr, httpReq)
+
+		assert.Equal(t, http.StatusForbidden, rr.Code)
+	})
+}
+
+func TestIndexHandler_Create(t *testing.T) {
+	validator := utils.NewValidator()
+
+	t.Run("successful create index", func(t *testing.T) {
+		mockService := new(mocks.MockIndexService)
+		handler := handlers.NewIndexHandler(mockService, validator)
+
+		mockService.On("CreateIndex", mock.Anything, uint64(1), uint64(1), mock.MatchedBy(func(req *models.CreateIndexRequest) bool {
+			return len(req.FieldCodes) == 2 && req.Unique
+		})).Return(&models.IndexResponse{ID: 4, AppID: 1, IndexName: "app_data_1_code_branch_idx", FieldCodes: []string{"code", "branch"}, Unique: true}, nil)
+
+		body, _ := json.Marshal(map[string]interface{}{"field_codes": []string{"code", "branch"}, "unique": true})
+		httpReq := httptest.NewRequest(http.MethodPost, "/api/v1/apps/1/indexes", bytes.NewBuffer(body))
+		httpReq = httpReq.WithContext(createContextWithClaims(httpReq.Context(), 1))
+		rr := httptest.NewRecorder()
+
+		handler.Create(rr, httpReq)
+
+		assert.Equal(t, http.StatusCreated, rr.Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("too many fields", func(t *testing.T) {
+		mockService := new(mocks.MockIndexService)
+		handler := handlers.NewIndexHandler(mockService, validator)
+
+		body, _ := json.Marshal(map[string]interface{}{"field_codes": []string{"a", "b", "c", "d", "e", "f"}})
+		httpReq := httptest.NewRequest(http.MethodPost, "/api/v1/apps/1/indexes", bytes.NewBuffer(body))
+		httpReq = httpReq.WithContext(createContextWithClaims(httpReq.Context(), 1))
+		rr := httptest.NewRecorder()
+
+		handler.Create(rr, httpReq)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+		mockService.AssertNotCalled(t, "CreateIndex", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("service errors", func(t *testing.T) {
+		tests := []struct {
+			name   string
+			err    error
+			status int
+		}{
+			{name: "invalid index", err: fmt.Errorf("%w: フィールドが見つかりません", services.ErrInvalidIndex), status: http.StatusBadRequest},
+			{name: "index exists", err: services.ErrIndexExists, status: http.StatusConflict},
+			{name: "duplicate values", err: fmt.Errorf("%w: 顧客コード", services.ErrDuplicateValue), status: http.StatusConflict},
+			{name: "permission denied", err: services.ErrPermissionDenied, status: http.StatusForbidden},
+			{name: "app not found", err: services.ErrAppNotFound, status: http.StatusNotFound},
+			{name: "internal error", err: errors.New("db error"), status: http.StatusInternalServerError},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				mockService := new(mocks.MockIndexService)
+				handler := handlers.NewIndexHandler(mockService, validator)
+
+				mockService.On("CreateIndex", mock.Anything, uint64(1), uint64(1), mock.Anything).Return(nil, tt.err)
+
+				body, _ := json.Marshal(map[string]interface{}{"field_codes": []string{"code"}})
+				httpReq := httptest.NewRequest(http.MethodPost, "/api/v1/apps/1/indexes", bytes.NewBuffer(body))
+				httpReq = httpReq.WithContext(createContextWithClaims(httpReq.Context(), 1))
+				rr := httptest.NewRecorder()
+
+				handler.Create(rr, httpReq)
+
+				assert.Equal(t, tt.status, rr.Code)
+			})
+		}
+	})
+
+	t.Run("unauthorized", func(t *testing.T) {
+		handler := handlers.NewIndexHandler(new(mocks.MockIndexService), validator)
+
+		body, _ := json.Marshal(map[string]interface{}{"field_codes": []string{"code"}})
+		httpReq := httptest.NewRequest(http.MethodPost, "/api/v1/apps/1/indexes", bytes.NewBuffer(body))
+		rr := httptest.NewRecorder()
+
+		handler.Create(rr, httpReq)
+
+		assert.Equal(t, http.StatusUnauthorized, rr.Code)
+	})
+}
+
+func TestIndexHandler_Delete(t *testing.T) {
+	validator := utils.NewValidator()
+
+	t.Run("successful delete index", func(t *testing.T) {
+		mockService := new(mocks.MockIndexService)
+		handler := handlers.NewIndexHandler(mockService, validator)
+
+		mockService.On("DeleteIndex", mock.Anything, uint64(1), uint64(4)).Return(nil)
+
+		httpReq := httptest.NewRequest(http.MethodDelete, "/api/v1/apps/1/indexes/4", nil)
+		rr := httptest.NewRecorder()
+
+		handler.Delete(rr, httpReq)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("index not found", func(t *testing.T) {
+		mockService := new(mocks.MockIndexService)
+		handler := handlers.NewIndexHandler(mockService, validator)
+
+		mockService.On("DeleteIndex", mock.Anything, uint64(1), uint64(9)).Return(services.ErrIndexNotFound)
+
+		httpReq := httptest.NewRequest(http.MethodDelete, "/api/v1/apps/1/indexes/9", nil)
+		rr := httptest.NewRecorder()
+
+		handler.Delete(rr, httpReq)
+
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+	})
+
+	t.Run("invalid index id", func(t *testing.T) {
+		handler := handlers.NewIndexHandler(new(mocks.MockIndexService), validator)
+
+		httpReq := httptest.NewRequest(http.MethodDelete, "/api/v1/apps/1/indexes/abc", nil)
+		rr := httptest.NewRecorder()
+
+		handler.Delete(rr, httpReq)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+}
+
+func TestIndexHandler_Suggestions(t *testing.T) {
+	mockService := new(mocks.MockIndexService)
+	handler := handlers.NewIndexHandler(mockService, utils.NewValidator())
+
+	mockService.On("SuggestIndexes", mock.Anything, uint64(1)).Return([]models.IndexSuggestion{
+		{FieldCodes: []string{"status", "due_date"}, Views: []models.IndexSuggestionView{{ID: 1, Name: "未対応"}}},
+	}, nil)
+
+	httpReq := httptest.NewRequest(http.MethodGet, "/api/v1/apps/1/indexes/suggestions", nil)
+	rr := httptest.NewRecorder()
+
+	handler.Suggestions(rr, httpReq)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+
+	var result map[string][]models.IndexSuggestion
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &result))
+	require.Len(t, result["suggestions"], 1)
+	assert.Equal(t, []string{"status", "due_date"}, result["suggestions"][0].FieldCodes)
+	mockService.AssertExpectations(t)
+}
@@ -0,0 +1,179 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"nocode-app/backend/internal/models"
+	"nocode-app/backend/internal/services"
+	"nocode-app/backend/internal/utils"
+)
+
+// PermissionHandler アプリ権限エンドポイントを処理する構造体
+type PermissionHandler struct {
+	permissionService services.PermissionServiceInterface
+	validator         *utils.Validator
+}
+
+// NewPermissionHandler 新しいPermissionHandlerを作成する
+func NewPermissionHandler(permissionService services.PermissionServiceInterface, validator *utils.Validator) *PermissionHandler {
+	return &PermissionHandler{
+		permissionService: permissionService,
+		validator:         validator,
+	}
+}
+
+// List アプリに設定された権限を一覧表示する
+func (h *PermissionHandler) List(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		utils.WriteErrorResponse(w, http.StatusMethodNotAllowed, "メソッドが許可されていません")
+		return
+	}
+
+	appID, err := extractAppID(r.URL.Path)
+	if err != nil {
+		utils.WriteErrorResponse(w, http.StatusBadRequest, "無効なアプリIDです")
+		return
+	}
+
+	resp, err := h.permissionService.GetPermissions(r.Context(), appID)
+	if err != nil {
+		if writePermissionError(w, err) {
+			return
+		}
+		utils.WriteErrorResponse(w, http.StatusInternalServerError, "権限の取得に失敗しました")
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, resp)
+}
+
+// Create ユーザーまたはグループに権限を付与する
+func (h *PermissionHandler) Create(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		utils.WriteErrorResponse(w, http.StatusMethodNotAllowed, "メソッドが許可されていません")
+		return
+	}
+
+	appID, err := extractAppID(r.URL.Path)
+	if err != nil {
+		utils.WriteErrorResponse(w, http.StatusBadRequest, "無効なアプリIDです")
+		return
+	}
+
+	var req models.CreateAppPermissionRequest
+	if err := h.validator.ParseAndValidate(r, &req); err != nil {
+		utils.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	resp, err := h.permissionService.GrantPermission(r.Context(), appID, &req)
+	if err != nil {
+		if writePermissionError(w, err) {
+			return
+		}
+		utils.WriteErrorResponse(w, http.StatusInternalServerError, "権限の付与に失敗しました")
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusCreated, resp)
+}
+
+// Update 権限を更新する
+func (h *PermissionHandler) Update(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPut {
+		utils.WriteErrorResponse(w, http.StatusMethodNotAllowed, "メソッドが許可されていません")
+		return
+	}
+
+	appID, permID, err := extractAppAndPermissionID(r.URL.Path)
+	if err != nil {
+		utils.WriteErrorResponse(w, http.StatusBadRequest, "無効な権限IDです")
+		return
+	}
+
+	var req models.UpdateAppPermissionRequest
+	if err := h.validator.ParseAndValidate(r, &req); err != nil {
+		utils.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	resp, err := h.permissionService.UpdatePermission(r.Context(), appID, permID, &req)
+	if err != nil {
+		if writePermissionError(w, err) {
+			return
+		}
+		utils.WriteErrorResponse(w, http.StatusInternalServerError, "権限の更新に失敗しました")
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, resp)
+}
+
+// Delete 権限を削除する
+func (h *PermissionHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		utils.WriteErrorResponse(w, http.StatusMethodNotAllowed, "メソッドが許可されていません")
+		return
+	}
+
+	appID, permID, err := extractAppAndPermissionID(r.URL.Path)
+	if err != nil {
+		utils.WriteErrorResponse(w, http.StatusBadRequest, "無効な権限IDです")
+		return
+	}
+
+	if err := h.permissionService.RevokePermission(r.Context(), appID, permID); err != nil {
+		if writePermissionError(w, err) {
+			return
+		}
+		utils.WriteErrorResponse(w, http.StatusInternalServerError, "権限の削除に失敗しました")
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, models.SuccessResponse{Message: "権限を削除しました"})
+}
+
+// writePermissionError 権限操作の既知のエラーをレスポンスに変換する
+// 書き込んだ場合はtrueを返す
+func writePermissionError(w http.ResponseWriter, err error) bool {
+	switch {
+	case errors.Is(err, services.ErrAppNotFound),
+		errors.Is(err, services.ErrPermissionNotFound),
+		errors.Is(err, services.ErrUserNotFound),
+		errors.Is(err, services.ErrGroupNotFound):
+		utils.WriteErrorResponse(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, services.ErrPermissionDenied):
+		utils.WriteErrorResponse(w, http.StatusForbidden, err.Error())
+	case errors.Is(err, services.ErrPermissionExists):
+		utils.WriteErrorResponse(w, http.StatusConflict, err.Error())
+	case errors.Is(err, services.ErrPermissionSubjectEmpty):
+		utils.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+	default:
+		return false
+	}
+	return true
+}
+
+// extractAppAndPermissionID URLパスからアプリIDと権限IDを抽出する
+// 想定パス形式: /api/v1/apps/{appId}/permissions/{permId}
+func extractAppAndPermissionID(path string) (uint64, uint64, error) {
+	parts := strings.Split(strings.Trim(path, "/"), "/")
+	if len(parts) < 6 {
+		return 0, 0, errors.New("無効なパスです")
+	}
+
+	appID, err := strconv.ParseUint(parts[3], 10, 64)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	permID, err := strconv.ParseUint(parts[5], 10, 64)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	return appID, permID, nil
+}
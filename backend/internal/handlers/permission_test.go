@@ -0,0 +1,180 @@
+package handlers_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"nocode-app/backend/internal/handlers"
+	"nocode-app/backend/internal/models"
+	"nocode-app/backend/internal/services"
+	"nocode-app/backend/internal/testhelpers/mocks"
+	"nocode-app/backend/internal/utils"
+)
+
+func TestPermissionHandler_List(t *testing.T) {
+	validator := utils.NewValidator()
+
+	t.Run("successful list", func(t *testing.T) {
+		mockService := new(mocks.MockPermissionService)
+		handler := handlers.NewPermissionHandler(mockService, validator)
+
+		userID := uint64(2)
+		resp := &models.AppPermissionListResponse{
+			Permissions: []models.AppPermissionResponse{
+				{ID: 1, AppID: 1, UserID: &userID, UserName: "User", Role: models.AppRoleEditor},
+			},
+		}
+		mockService.On("GetPermissions", mock.Anything, uint64(1)).Return(resp, nil)
+
+		httpReq := httptest.NewRequest(http.MethodGet, "/api/v1/apps/1/permissions", nil)
+		rr := httptest.NewRecorder()
+
+		handler.List(rr, httpReq)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+
+		var result models.AppPermissionListResponse
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &result))
+		assert.Len(t, result.Permissions, 1)
+		assert.Equal(t, models.AppRoleEditor, result.Permissions[0].Role)
+	})
+
+	t.Run("not owner", func(t *testing.T) {
+		mockService := new(mocks.MockPermissionService)
+		handler := handlers.NewPermissionHandler(mockService, validator)
+
+		mockService.On("GetPermissions", mock.Anything, uint64(1)).Return(nil, services.ErrPermissionDenied)
+
+		httpReq := httptest.NewRequest(http.MethodGet, "/api/v1/apps/1/permissions", nil)
+		rr := httptest.NewRecorder()
+
+		handler.List(rr, httpReq)
+
+		assert.Equal(t, http.StatusForbidden, rr.Code)
+	})
+}
+
+func TestPermissionHandler_Create(t *testing.T) {
+	validator := utils.NewValidator()
+
+	t.Run("successful grant", func(t *testing.T) {
+		mockService := new(mocks.MockPermissionService)
+		handler := handlers.NewPermissionHandler(mockService, validator)
+
+		groupID := uint64(3)
+		mockService.On("GrantPermission", mock.Anything, uint64(1), mock.AnythingOfType("*models.CreateAppPermissionRequest")).
+			Return(&models.AppPermissionResponse{ID: 1, AppID: 1, GroupID: &groupID, Role: models.AppRoleViewer}, nil)
+
+		body, _ := json.Marshal(map[string]interface{}{"group_id": 3, "role": "viewer"})
+		httpReq := httptest.NewRequest(http.MethodPost, "/api/v1/apps/1/permissions", bytes.NewReader(body))
+		httpReq.Header.Set("Content-Type", "application/json")
+		rr := httptest.NewRecorder()
+
+		handler.Create(rr, httpReq)
+
+		assert.Equal(t, http.StatusCreated, rr.Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("both user and group", func(t *testing.T) {
+		mockService := new(mocks.MockPermissionService)
+		handler := handlers.NewPermissionHandler(mockService, validator)
+
+		body, _ := json.Marshal(map[string]interface{}{"user_id": 2, "group_id": 3, "role": "viewer"})
+		httpReq := httptest.NewRequest(http.MethodPost, "/api/v1/apps/1/permissions", bytes.NewReader(body))
+		httpReq.Header.Set("Content-Type", "application/json")
+		rr := httptest.NewRecorder()
+
+		handler.Create(rr, httpReq)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+		mockService.AssertNotCalled(t, "GrantPermission", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("invalid role", func(t *testing.T) {
+		mockService := new(mocks.MockPermissionService)
+		handler := handlers.NewPermissionHandler(mockService, validator)
+
+		body, _ := json.Marshal(map[string]interface{}{"user_id": 2, "role": "superuser"})
+		httpReq := httptest.NewRequest(http.MethodPost, "/api/v1/apps/1/permissions", bytes.NewReader(body))
+		httpReq.Header.Set("Content-Type", "application/json")
+		rr := httptest.NewRecorder()
+
+		handler.Create(rr, httpReq)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+
+	t.Run("duplicate subject", func(t *testing.T) {
+		mockService := new(mocks.MockPermissionService)
+		handler := handlers.NewPermissionHandler(mockService, validator)
+
+		mockService.On("GrantPermission", mock.Anything, uint64(1), mock.AnythingOfType("*models.CreateAppPermissionRequest")).
+			Return(nil, services.ErrPermissionExists)
+
+		body, _ := json.Marshal(map[string]interface{}{"user_id": 2, "role": "editor"})
+		httpReq := httptest.NewRequest(http.MethodPost, "/api/v1/apps/1/permissions", bytes.NewReader(body))
+		httpReq.Header.Set("Content-Type", "application/json")
+		rr := httptest.NewRecorder()
+
+		handler.Create(rr, httpReq)
+
+		assert.Equal(t, http.StatusConflict, rr.Code)
+	})
+}
+
+func TestPermissionHandler_Update(t *testing.T) {
+	validator := utils.NewValidator()
+
+	mockService := new(mocks.MockPermissionService)
+	handler := handlers.NewPermissionHandler(mockService, validator)
+
+	mockService.On("UpdatePermission", mock.Anything, uint64(1), uint64(5), mock.AnythingOfType("*models.UpdateAppPermissionRequest")).
+		Return(nil, services.ErrPermissionNotFound)
+
+	body, _ := json.Marshal(map[string]interface{}{"role": "owner"})
+	httpReq := httptest.NewRequest(http.MethodPut, "/api/v1/apps/1/permissions/5", bytes.NewReader(body))
+	httpReq.Header.Set("Content-Type", "application/json")
+	rr := httptest.NewRecorder()
+
+	handler.Update(rr, httpReq)
+
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+}
+
+func TestPermissionHandler_Delete(t *testing.T) {
+	validator := utils.NewValidator()
+
+	t.Run("successful delete", func(t *testing.T) {
+		mockService := new(mocks.MockPermissionService)
+		handler := handlers.NewPermissionHandler(mockService, validator)
+
+		mockService.On("RevokePermission", mock.Anything, uint64(1), uint64(5)).Return(nil)
+
+		httpReq := httptest.NewRequest(http.MethodDelete, "/api/v1/apps/1/permissions/5", nil)
+		rr := httptest.NewRecorder()
+
+		handler.Delete(rr, httpReq)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+	})
+
+	t.Run("invalid permission id", func(t *testing.T) {
+		mockService := new(mocks.MockPermissionService)
+		handler := handlers.NewPermissionHandler(mockService, validator)
+
+		httpReq := httptest.NewRequest(http.MethodDelete, "/api/v1/apps/1/permissions/abc", nil)
+		rr := httptest.NewRecorder()
+
+		handler.Delete(rr, httpReq)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+}
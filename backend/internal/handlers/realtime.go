@@ -0,0 +1,199 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"nocode-app/backend/internal/models"
+	"nocode-app/backend/internal/realtime"
+	"nocode-app/backend/internal/services"
+	"nocode-app/backend/internal/utils"
+)
+
+const (
+	// realtimeHeartbeatInterval 接続を保つためのコメントを送り、権限を確認し直す間隔
+	realtimeHeartbeatInterval = 25 * time.Second
+	// realtimeRetryMillis 切断されたときにクライアントが接続し直すまでの時間
+	realtimeRetryMillis = 3000
+)
+
+// RealtimeHandler アプリの変更をServer-Sent Eventsで配信するエンドポイントを処理する構造体
+type RealtimeHandler struct {
+	realtimeService services.RealtimeServiceInterface
+}
+
+// NewRealtimeHandler 新しいRealtimeHandlerを作成する
+func NewRealtimeHandler(realtimeService services.RealtimeServiceInterface) *RealtimeHandler {
+	return &RealtimeHandler{realtimeService: realtimeService}
+}
+
+// Events アプリのレコード・フィールドの変更を購読する（text/event-stream）
+// 接続した直後に ready を送る。クライアントは ready と resync を受け取ったときに表示中のデータを取得し直す
+func (h *RealtimeHandler) Events(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		utils.WriteErrorResponse(w, http.StatusMethodNotAllowed, "メソッドが許可されていません")
+		return
+	}
+
+	appID, err := extractAppID(r.URL.Path)
+	if err != nil {
+		utils.WriteErrorResponse(w, http.StatusBadRequest, "無効なアプリIDです")
+		return
+	}
+
+	ctx := r.Context()
+	access, sub, err := h.realtimeService.Subscribe(ctx, appID)
+	if err != nil {
+		writeRealtimeError(w, err)
+		return
+	}
+	defer sub.Close()
+
+	// 接続を保ち続けるため、サーバーの書き込みのタイムアウトを解除する（対応していないResponseWriterでは何もしない）
+	rc := http.NewResponseController(w)
+	_ = rc.SetWriteDeadline(time.Time{})
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	// リバースプロキシにバッファリングさせない
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	if _, err := fmt.Fprintf(w, "retry: %d\n\n", realtimeRetryMillis); err != nil {
+		return
+	}
+	if err := writeServerSentEvent(w, "ready", map[string]uint64{"app_id": appID}); err != nil {
+		return
+	}
+	if err := rc.Flush(); err != nil {
+		return
+	}
+
+	ticker := time.NewTicker(realtimeHeartbeatInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case msg, ok := <-sub.Messages():
+			// 受け取りが追いつかなかった場合やサーバーの停止時は閉じられる。クライアントは接続し直す
+			if !ok {
+				return
+			}
+			if !canReceiveRealtimeMessage(access, &msg) {
+				continue
+			}
+			if err := writeRealtimeMessage(w, &msg); err != nil {
+				return
+			}
+			if err := rc.Flush(); err != nil {
+				return
+			}
+			if msg.Event == string(models.WebhookEventAppDeleted) {
+				return
+			}
+		case <-ticker.C:
+			// 購読中に権限が取り消された場合は終了する。自分のレコードのみへの制限の変更は以降のイベントに反映する
+			if access, err = h.realtimeService.Authorize(ctx, appID); err != nil {
+				_ = writeServerSentEvent(w, "error", map[string]string{"error": realtimeErrorMessage(err)})
+				_ = rc.Flush()
+				return
+			}
+			if _, err := fmt.Fprint(w, ": ping\n\n"); err != nil {
+				return
+			}
+			if err := rc.Flush(); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// Token EventSourceで購読するための短期間のトークンを発行する
+// EventSourceはヘッダーを指定できないため、発行したトークンを ?token= で渡して接続する
+func (h *RealtimeHandler) Token(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		utils.WriteErrorResponse(w, http.StatusMethodNotAllowed, "メソッドが許可されていません")
+		return
+	}
+
+	appID, err := extractAppID(r.URL.Path)
+	if err != nil {
+		utils.WriteErrorResponse(w, http.StatusBadRequest, "無効なアプリIDです")
+		return
+	}
+
+	resp, err := h.realtimeService.IssueToken(r.Context(), appID)
+	if err != nil {
+		writeRealtimeError(w, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, resp)
+}
+
+// canReceiveRealtimeMessage 自分のレコードのみ閲覧できる場合に、他のユーザーが作成したレコードのイベントを除く
+func canReceiveRealtimeMessage(access *models.AppAccess, msg *realtime.Message) bool {
+	if msg.RecordID == 0 {
+		return true
+	}
+	return access.CanAccessRecord(msg.CreatedBy)
+}
+
+// writeRealtimeMessage イベントを1件書き込む
+// 内容が省かれている場合は、イベントの種類とレコードIDのみを送る
+func writeRealtimeMessage(w http.ResponseWriter, msg *realtime.Message) error {
+	if msg.Payload != nil {
+		_, err := fmt.Fprintf(w, "event: %s\ndata: %s\n\n", msg.Event, msg.Payload)
+		return err
+	}
+	return writeServerSentEvent(w, msg.Event, struct {
+		Event     string `json:"event"`
+		AppID     uint64 `json:"app_id"`
+		RecordID  uint64 `json:"record_id,omitempty"`
+		Truncated bool   `json:"truncated,omitempty"`
+	}{
+		Event:     msg.Event,
+		AppID:     msg.AppID,
+		RecordID:  msg.RecordID,
+		Truncated: msg.Event != realtime.EventResync,
+	})
+}
+
+// writeServerSentEvent JSONにしたデータを1件のイベントとして書き込む
+func writeServerSentEvent(w http.ResponseWriter, event string, data interface{}) error {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event, payload)
+	return err
+}
+
+// writeRealtimeError 購読の開始に失敗した場合のエラーレスポンスを書き込む
+func writeRealtimeError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, services.ErrAppNotFound):
+		utils.WriteErrorResponse(w, http.StatusNotFound, realtimeErrorMessage(err))
+	case errors.Is(err, services.ErrPermissionDenied):
+		utils.WriteErrorResponse(w, http.StatusForbidden, realtimeErrorMessage(err))
+	default:
+		utils.WriteErrorResponse(w, http.StatusInternalServerError, realtimeErrorMessage(err))
+	}
+}
+
+// realtimeErrorMessage クライアントに返すエラーメッセージ
+func realtimeErrorMessage(err error) string {
+	switch {
+	case errors.Is(err, services.ErrAppNotFound):
+		return "アプリが見つかりません"
+	case errors.Is(err, services.ErrPermissionDenied):
+		return err.Error()
+	default:
+		return "アプリの変更の購読に失敗しました"
+	}
+}
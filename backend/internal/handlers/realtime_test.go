@@ -0,0 +1,126 @@
+package handlers_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"nocode-app/backend/internal/handlers"
+	"nocode-app/backend/internal/models"
+	"nocode-app/backend/internal/realtime"
+	"nocode-app/backend/internal/services"
+	"nocode-app/backend/internal/testhelpers/mocks"
+)
+
+func TestRealtimeHandler_Events(t *testing.T) {
+	t.Run("streams events the caller can view", func(t *testing.T) {
+		mockService := new(mocks.MockRealtimeService)
+		handler := handlers.NewRealtimeHandler(mockService)
+
+		broker := realtime.NewBroker(nil)
+		sub := broker.Subscribe(1)
+		mockService.On("Subscribe", mock.Anything, uint64(1)).Return(&models.AppAccess{UserID: 5, Role: models.AppRoleViewer, OwnRecordsOnly: true}, sub, nil)
+
+		// 購読を始める前に届いたイベントは、接続後に順に送る
+		for _, msg := range []realtime.Message{
+			{AppID: 1, Event: "record.created", RecordID: 10, CreatedBy: 6, Payload: json.RawMessage(`{"event":"record.created","data":{"record_id":10}}`)},
+			{AppID: 1, Event: "record.created", RecordID: 11, CreatedBy: 5, Payload: json.RawMessage(`{"event":"record.created","data":{"record_id":11}}`)},
+			{AppID: 1, Event: "record.updated", RecordID: 12, CreatedBy: 5},
+			{AppID: 1, Event: "field.updated", Payload: json.RawMessage(`{"event":"field.updated"}`)},
+			{AppID: 1, Event: realtime.EventResync},
+			{AppID: 1, Event: "app.deleted", Payload: json.RawMessage(`{"event":"app.deleted"}`)},
+			{AppID: 1, Event: "record.created", RecordID: 13, CreatedBy: 5, Payload: json.RawMessage(`{}`)},
+		} {
+			broker.Dispatch(&msg)
+		}
+
+		httpReq := httptest.NewRequest(http.MethodGet, "/api/v1/apps/1/events", nil)
+		rr := httptest.NewRecorder()
+		handler.Events(rr, httpReq)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, "text/event-stream", rr.Header().Get("Content-Type"))
+		assert.Equal(t, "retry: 3000\n\n"+
+			"event: ready\ndata: {\"app_id\":1}\n\n"+
+			"event: record.created\ndata: {\"event\":\"record.created\",\"data\":{\"record_id\":11}}\n\n"+
+			"event: record.updated\ndata: {\"event\":\"record.updated\",\"app_id\":1,\"record_id\":12,\"truncated\":true}\n\n"+
+			"event: field.updated\ndata: {\"event\":\"field.updated\"}\n\n"+
+			"event: resync\ndata: {\"event\":\"resync\",\"app_id\":1}\n\n"+
+			"event: app.deleted\ndata: {\"event\":\"app.deleted\"}\n\n", rr.Body.String())
+		// アプリが削除されたら購読を終了する
+		assert.Zero(t, broker.Subscribers(1))
+	})
+
+	t.Run("ends when the subscription is closed", func(t *testing.T) {
+		mockService := new(mocks.MockRealtimeService)
+		handler := handlers.NewRealtimeHandler(mockService)
+
+		broker := realtime.NewBroker(nil)
+		sub := broker.Subscribe(1)
+		mockService.On("Subscribe", mock.Anything, uint64(1)).Return(&models.AppAccess{UserID: 5, Role: models.AppRoleOwner}, sub, nil)
+		broker.Close()
+
+		rr := httptest.NewRecorder()
+		handler.Events(rr, httptest.NewRequest(http.MethodGet, "/api/v1/apps/1/events", nil))
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.True(t, strings.HasSuffix(rr.Body.String(), "event: ready\ndata: {\"app_id\":1}\n\n"))
+	})
+
+	tests := []struct {
+		name       string
+		path       string
+		err        error
+		wantStatus int
+	}{
+		{name: "app not found", path: "/api/v1/apps/1/events", err: services.ErrAppNotFound, wantStatus: http.StatusNotFound},
+		{name: "permission denied", path: "/api/v1/apps/1/events", err: services.ErrPermissionDenied, wantStatus: http.StatusForbidden},
+		{name: "invalid app id", path: "/api/v1/apps/abc/events", wantStatus: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(mocks.MockRealtimeService)
+			handler := handlers.NewRealtimeHandler(mockService)
+			if tt.err != nil {
+				mockService.On("Subscribe", mock.Anything, uint64(1)).Return(nil, nil, tt.err)
+			}
+
+			rr := httptest.NewRecorder()
+			handler.Events(rr, httptest.NewRequest(http.MethodGet, tt.path, nil))
+
+			assert.Equal(t, tt.wantStatus, rr.Code)
+		})
+	}
+}
+
+func TestRealtimeHandler_Token(t *testing.T) {
+	t.Run("issues token", func(t *testing.T) {
+		mockService := new(mocks.MockRealtimeService)
+		handler := handlers.NewRealtimeHandler(mockService)
+		mockService.On("IssueToken", mock.Anything, uint64(1)).Return(&models.RealtimeTokenResponse{Token: "scoped-token"}, nil)
+
+		rr := httptest.NewRecorder()
+		handler.Token(rr, httptest.NewRequest(http.MethodPost, "/api/v1/apps/1/events/token", nil))
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		var resp models.RealtimeTokenResponse
+		assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+		assert.Equal(t, "scoped-token", resp.Token)
+	})
+
+	t.Run("permission denied", func(t *testing.T) {
+		mockService := new(mocks.MockRealtimeService)
+		handler := handlers.NewRealtimeHandler(mockService)
+		mockService.On("IssueToken", mock.Anything, uint64(1)).Return(nil, services.ErrPermissionDenied)
+
+		rr := httptest.NewRecorder()
+		handler.Token(rr, httptest.NewRequest(http.MethodPost, "/api/v1/apps/1/events/token", nil))
+
+		assert.Equal(t, http.StatusForbidden, rr.Code)
+	})
+}
@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"encoding/json"
 	"errors"
 	"net/http"
 	"strconv"
@@ -40,13 +41,26 @@ func (h *RecordHandler) List(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	filter, err := parseFilterExpr(r)
+	if err != nil {
+		utils.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
 	// クエリオプションをパース
 	opts := repositories.RecordQueryOptions{
-		Page:    utils.GetQueryParamInt(r, "page", 1),
-		Limit:   utils.GetQueryParamInt(r, "limit", 20),
-		Sort:    utils.GetQueryParam(r, "sort", ""),
-		Order:   utils.GetQueryParam(r, "order", "desc"),
-		Filters: parseFilters(r),
+		Page:     utils.GetQueryParamInt(r, "page", 1),
+		Limit:    utils.GetQueryParamInt(r, "limit", 20),
+		Sort:     utils.GetQueryParam(r, "sort", ""),
+		Order:    utils.GetQueryParam(r, "order", "desc"),
+		Filters:  parseFilters(r),
+		Filter:   filter,
+		TimeZone: utils.GetQueryParam(r, "tz", ""),
+		Search:   utils.GetQueryParam(r, "q", ""),
+		// cursor パラメータがあればカーソル方式（1ページ目は空の値）
+		Keyset: r.URL.Query().Has("cursor"),
+		Cursor: r.URL.Query().Get("cursor"),
+		Count:  repositories.CountMode(utils.GetQueryParam(r, "count", "")),
 	}
 
 	resp, err := h.recordService.GetRecords(r.Context(), appID, opts)
@@ -55,10 +69,18 @@ func (h *RecordHandler) List(w http.ResponseWriter, r *http.Request) {
 			utils.WriteErrorResponse(w, http.StatusNotFound, err.Error())
 			return
 		}
+		if errors.Is(err, services.ErrInvalidFilter) || errors.Is(err, services.ErrInvalidSearch) || errors.Is(err, services.ErrInvalidPagination) {
+			utils.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+			return
+		}
 		if errors.Is(err, services.ErrEncryptionNotInitialized) {
 			utils.WriteErrorResponse(w, http.StatusServiceUnavailable, err.Error())
 			return
 		}
+		if errors.Is(err, services.ErrPermissionDenied) {
+			utils.WriteErrorResponse(w, http.StatusForbidden, err.Error())
+			return
+		}
 		utils.WriteErrorResponse(w, http.StatusInternalServerError, "レコードの取得に失敗しました")
 		return
 	}
@@ -101,6 +123,17 @@ func (h *RecordHandler) Create(w http.ResponseWriter, r *http.Request) {
 			utils.WriteErrorResponse(w, http.StatusForbidden, err.Error())
 			return
 		}
+		if writeRecordValidationError(w, err) {
+			return
+		}
+		if errors.Is(err, services.ErrDuplicateValue) {
+			utils.WriteErrorResponse(w, http.StatusConflict, err.Error())
+			return
+		}
+		if errors.Is(err, services.ErrPermissionDenied) {
+			utils.WriteErrorResponse(w, http.StatusForbidden, err.Error())
+			return
+		}
 		utils.WriteErrorResponse(w, http.StatusInternalServerError, "レコードの作成に失敗しました")
 		return
 	}
@@ -135,10 +168,15 @@ func (h *RecordHandler) Get(w http.ResponseWriter, r *http.Request) {
 			utils.WriteErrorResponse(w, http.StatusServiceUnavailable, err.Error())
 			return
 		}
+		if errors.Is(err, services.ErrPermissionDenied) {
+			utils.WriteErrorResponse(w, http.StatusForbidden, err.Error())
+			return
+		}
 		utils.WriteErrorResponse(w, http.StatusInternalServerError, "レコードの取得に失敗しました")
 		return
 	}
 
+	setETag(w, resp.Version)
 	utils.WriteJSON(w, http.StatusOK, resp)
 }
 
@@ -161,8 +199,17 @@ func (h *RecordHandler) Update(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	resp, err := h.recordService.UpdateRecord(r.Context(), appID, recordID, &req)
+	version, err := requestVersion(r, req.Version)
+	if err != nil {
+		writeVersionError(w, r, err)
+		return
+	}
+
+	resp, err := h.recordService.UpdateRecord(r.Context(), appID, recordID, version, &req)
 	if err != nil {
+		if writeVersionError(w, r, err) {
+			return
+		}
 		if errors.Is(err, services.ErrAppNotFound) {
 			utils.WriteErrorResponse(w, http.StatusNotFound, err.Error())
 			return
@@ -171,10 +218,26 @@ func (h *RecordHandler) Update(w http.ResponseWriter, r *http.Request) {
 			utils.WriteErrorResponse(w, http.StatusForbidden, err.Error())
 			return
 		}
+		if writeRecordValidationError(w, err) {
+			return
+		}
+		if errors.Is(err, services.ErrDuplicateValue) {
+			utils.WriteErrorResponse(w, http.StatusConflict, err.Error())
+			return
+		}
+		if errors.Is(err, services.ErrRecordNotFound) {
+			utils.WriteErrorResponse(w, http.StatusNotFound, err.Error())
+			return
+		}
+		if errors.Is(err, services.ErrPermissionDenied) {
+			utils.WriteErrorResponse(w, http.StatusForbidden, err.Error())
+			return
+		}
 		utils.WriteErrorResponse(w, http.StatusInternalServerError, "レコードの更新に失敗しました")
 		return
 	}
 
+	setETag(w, resp.Version)
 	utils.WriteJSON(w, http.StatusOK, resp)
 }
 
@@ -191,7 +254,16 @@ func (h *RecordHandler) Delete(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := h.recordService.DeleteRecord(r.Context(), appID, recordID); err != nil {
+	version, err := requestVersion(r, nil)
+	if err != nil {
+		writeVersionError(w, r, err)
+		return
+	}
+
+	if err := h.recordService.DeleteRecord(r.Context(), appID, recordID, version); err != nil {
+		if writeVersionError(w, r, err) {
+			return
+		}
 		if errors.Is(err, services.ErrAppNotFound) {
 			utils.WriteErrorResponse(w, http.StatusNotFound, err.Error())
 			return
@@ -200,6 +272,18 @@ func (h *RecordHandler) Delete(w http.ResponseWriter, r *http.Request) {
 			utils.WriteErrorResponse(w, http.StatusForbidden, err.Error())
 			return
 		}
+		if errors.Is(err, services.ErrRecordNotFound) {
+			utils.WriteErrorResponse(w, http.StatusNotFound, err.Error())
+			return
+		}
+		if errors.Is(err, services.ErrRecordReferenced) {
+			utils.WriteErrorResponse(w, http.StatusConflict, err.Error())
+			return
+		}
+		if errors.Is(err, services.ErrPermissionDenied) {
+			utils.WriteErrorResponse(w, http.StatusForbidden, err.Error())
+			return
+		}
 		utils.WriteErrorResponse(w, http.StatusInternalServerError, "レコードの削除に失敗しました")
 		return
 	}
@@ -242,6 +326,17 @@ func (h *RecordHandler) BulkCreate(w http.ResponseWriter, r *http.Request) {
 			utils.WriteErrorResponse(w, http.StatusForbidden, err.Error())
 			return
 		}
+		if writeRecordValidationError(w, err) {
+			return
+		}
+		if errors.Is(err, services.ErrDuplicateValue) {
+			utils.WriteErrorResponse(w, http.StatusConflict, err.Error())
+			return
+		}
+		if errors.Is(err, services.ErrPermissionDenied) {
+			utils.WriteErrorResponse(w, http.StatusForbidden, err.Error())
+			return
+		}
 		utils.WriteErrorResponse(w, http.StatusInternalServerError, "レコードの作成に失敗しました")
 		return
 	}
@@ -277,6 +372,18 @@ func (h *RecordHandler) BulkDelete(w http.ResponseWriter, r *http.Request) {
 			utils.WriteErrorResponse(w, http.StatusForbidden, err.Error())
 			return
 		}
+		if errors.Is(err, services.ErrRecordNotFound) {
+			utils.WriteErrorResponse(w, http.StatusNotFound, err.Error())
+			return
+		}
+		if errors.Is(err, services.ErrRecordReferenced) {
+			utils.WriteErrorResponse(w, http.StatusConflict, err.Error())
+			return
+		}
+		if errors.Is(err, services.ErrPermissionDenied) {
+			utils.WriteErrorResponse(w, http.StatusForbidden, err.Error())
+			return
+		}
 		utils.WriteErrorResponse(w, http.StatusInternalServerError, "レコードの削除に失敗しました")
 		return
 	}
@@ -284,6 +391,111 @@ func (h *RecordHandler) BulkDelete(w http.ResponseWriter, r *http.Request) {
 	utils.WriteJSON(w, http.StatusOK, models.SuccessResponse{Message: "レコードを削除しました"})
 }
 
+// History レコードの変更履歴を取得する
+func (h *RecordHandler) History(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		utils.WriteErrorResponse(w, http.StatusMethodNotAllowed, "メソッドが許可されていません")
+		return
+	}
+
+	appID, recordID, err := extractAppAndRecordID(r.URL.Path)
+	if err != nil {
+		utils.WriteErrorResponse(w, http.StatusBadRequest, "無効なレコードIDです")
+		return
+	}
+
+	// ページネーションパラメータをパース
+	page := utils.GetQueryParamInt(r, "page", 1)
+	if page < 1 {
+		page = 1
+	}
+	limit := utils.GetQueryParamInt(r, "limit", 20)
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+
+	resp, err := h.recordService.GetRecordHistory(r.Context(), appID, recordID, page, limit)
+	if err != nil {
+		if errors.Is(err, services.ErrAppNotFound) || errors.Is(err, services.ErrRecordNotFound) {
+			utils.WriteErrorResponse(w, http.StatusNotFound, err.Error())
+			return
+		}
+		if errors.Is(err, services.ErrPermissionDenied) {
+			utils.WriteErrorResponse(w, http.StatusForbidden, err.Error())
+			return
+		}
+		utils.WriteErrorResponse(w, http.StatusInternalServerError, "変更履歴の取得に失敗しました")
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, resp)
+}
+
+// Revert レコードを指定した変更履歴の時点の内容に戻す
+func (h *RecordHandler) Revert(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		utils.WriteErrorResponse(w, http.StatusMethodNotAllowed, "メソッドが許可されていません")
+		return
+	}
+
+	appID, recordID, revisionID, err := extractAppRecordAndRevisionID(r.URL.Path)
+	if err != nil {
+		utils.WriteErrorResponse(w, http.StatusBadRequest, "無効な変更履歴IDです")
+		return
+	}
+
+	version, err := requestVersion(r, nil)
+	if err != nil {
+		writeVersionError(w, r, err)
+		return
+	}
+
+	resp, err := h.recordService.RevertRecord(r.Context(), appID, recordID, revisionID, version)
+	if err != nil {
+		if writeVersionError(w, r, err) {
+			return
+		}
+		if errors.Is(err, services.ErrAppNotFound) ||
+			errors.Is(err, services.ErrRecordNotFound) ||
+			errors.Is(err, services.ErrRevisionNotFound) {
+			utils.WriteErrorResponse(w, http.StatusNotFound, err.Error())
+			return
+		}
+		if errors.Is(err, services.ErrExternalAppReadOnly) || errors.Is(err, services.ErrPermissionDenied) {
+			utils.WriteErrorResponse(w, http.StatusForbidden, err.Error())
+			return
+		}
+		if writeRecordValidationError(w, err) {
+			return
+		}
+		if errors.Is(err, services.ErrDuplicateValue) {
+			utils.WriteErrorResponse(w, http.StatusConflict, err.Error())
+			return
+		}
+		utils.WriteErrorResponse(w, http.StatusInternalServerError, "レコードの復元に失敗しました")
+		return
+	}
+
+	setETag(w, resp.Version)
+	utils.WriteJSON(w, http.StatusOK, resp)
+}
+
+// writeRecordValidationError 入力値エラーであれば422レスポンスを書き込み、trueを返す
+func writeRecordValidationError(w http.ResponseWriter, err error) bool {
+	var vErr *services.RecordValidationError
+	if !errors.As(err, &vErr) {
+		return false
+	}
+	utils.WriteJSON(w, http.StatusUnprocessableEntity, models.ValidationErrorResponse{
+		Error:        http.StatusText(http.StatusUnprocessableEntity),
+		Message:      vErr.Error(),
+		Code:         http.StatusUnprocessableEntity,
+		Errors:       vErr.Errors,
+		RecordErrors: vErr.RecordErrors,
+	})
+	return true
+}
+
 // extractAppIDFromRecordPath URLパスからアプリIDを抽出する
 // 期待されるパス形式: /api/v1/apps/{appId}/records
 func extractAppIDFromRecordPath(path string) (uint64, error) {
@@ -312,22 +524,59 @@ func extractAppAndRecordID(path string) (appID, recordID uint64, err error) {
 	return appID, recordID, nil
 }
 
+// extractAppRecordAndRevisionID URLパスからアプリID、レコードID、変更履歴IDを抽出する
+// 期待されるパス形式: /api/v1/apps/{appId}/records/{recordId}/history/{revisionId}/revert
+func extractAppRecordAndRevisionID(path string) (appID, recordID, revisionID uint64, err error) {
+	appID, recordID, err = extractAppAndRecordID(path)
+	if err != nil {
+		return 0, 0, 0, err
+	}
+	parts := strings.Split(strings.Trim(path, "/"), "/")
+	if len(parts) < 8 {
+		return 0, 0, 0, errors.New("無効なパスです")
+	}
+	revisionID, err = strconv.ParseUint(parts[7], 10, 64)
+	if err != nil {
+		return 0, 0, 0, err
+	}
+	return appID, recordID, revisionID, nil
+}
+
 // parseFilters フィルタークエリパラメータをパースする
-// 形式: filter=field:op:value
+// 形式: filter=field:op:value（複数の値はカンマ区切り、is_null・not_null は filter=field:op）
 func parseFilters(r *http.Request) []models.FilterItem {
 	var filters []models.FilterItem
 	filterParams := r.URL.Query()["filter"]
 
 	for _, param := range filterParams {
 		parts := strings.SplitN(param, ":", 3)
-		if len(parts) == 3 {
+		switch {
+		case len(parts) == 3:
 			filters = append(filters, models.FilterItem{
 				Field:    parts[0],
 				Operator: parts[1],
 				Value:    parts[2],
 			})
+		case len(parts) == 2 && !models.FilterOperatorTakesValue(parts[1]):
+			filters = append(filters, models.FilterItem{
+				Field:    parts[0],
+				Operator: parts[1],
+			})
 		}
 	}
 
 	return filters
 }
+
+// parseFilterExpr AND・OR・NOTを組み合わせた絞り込み条件をクエリパラメータ where（JSON）からパースする
+func parseFilterExpr(r *http.Request) (*models.FilterExpr, error) {
+	raw := r.URL.Query().Get("where")
+	if raw == "" {
+		return nil, nil
+	}
+	var expr models.FilterExpr
+	if err := json.Unmarshal([]byte(raw), &expr); err != nil {
+		return nil, errors.New("絞り込み条件（where）はJSONで指定してください")
+	}
+	return &expr, nil
+}
@@ -0,0 +1,95 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+
+	"nocode-app/backend/internal/models"
+	"nocode-app/backend/internal/repositories"
+	"nocode-app/backend/internal/services"
+	"nocode-app/backend/internal/utils"
+)
+
+// Export フィルターとソートを適用した全レコードをファイルとしてダウンロードする
+// クエリパラメータは一覧取得と同じ（page・limitは無視）。format で csv（既定）/ xlsx / ndjson を指定する
+func (h *RecordHandler) Export(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		utils.WriteErrorResponse(w, http.StatusMethodNotAllowed, "メソッドが許可されていません")
+		return
+	}
+
+	appID, err := extractAppIDFromRecordPath(r.URL.Path)
+	if err != nil {
+		utils.WriteErrorResponse(w, http.StatusBadRequest, "無効なアプリIDです")
+		return
+	}
+
+	filter, err := parseFilterExpr(r)
+	if err != nil {
+		utils.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	format := models.ExportFormat(utils.GetQueryParam(r, "format", string(models.ExportFormatCSV)))
+	opts := repositories.RecordQueryOptions{
+		Sort:     utils.GetQueryParam(r, "sort", ""),
+		Order:    utils.GetQueryParam(r, "order", "desc"),
+		Filters:  parseFilters(r),
+		Filter:   filter,
+		TimeZone: utils.GetQueryParam(r, "tz", ""),
+	}
+
+	out := &exportResponseWriter{
+		w:        w,
+		format:   format,
+		filename: fmt.Sprintf("app_%d_records.%s", appID, format),
+	}
+	err = h.recordService.ExportRecords(r.Context(), appID, opts, format, out)
+	if err == nil {
+		return
+	}
+
+	// 書き出し開始後はステータスを変更できないため、ログのみ残す
+	if out.started {
+		log.Printf("レコードエクスポートエラー: %v", err)
+		return
+	}
+	if errors.Is(err, services.ErrInvalidExportFormat) || errors.Is(err, services.ErrInvalidFilter) {
+		utils.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if errors.Is(err, services.ErrAppNotFound) {
+		utils.WriteErrorResponse(w, http.StatusNotFound, err.Error())
+		return
+	}
+	if errors.Is(err, services.ErrEncryptionNotInitialized) {
+		utils.WriteErrorResponse(w, http.StatusServiceUnavailable, err.Error())
+		return
+	}
+	if errors.Is(err, services.ErrPermissionDenied) {
+		utils.WriteErrorResponse(w, http.StatusForbidden, err.Error())
+		return
+	}
+	utils.WriteErrorResponse(w, http.StatusInternalServerError, "レコードのエクスポートに失敗しました")
+}
+
+// exportResponseWriter 最初の書き込みでダウンロード用のヘッダーを送るライター
+// 書き込み前に発生したエラーは通常のエラーレスポンスとして返せる
+type exportResponseWriter struct {
+	w        http.ResponseWriter
+	format   models.ExportFormat
+	filename string
+	started  bool
+}
+
+func (e *exportResponseWriter) Write(p []byte) (int, error) {
+	if !e.started {
+		e.started = true
+		e.w.Header().Set("Content-Type", e.format.ContentType())
+		e.w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", e.filename))
+		e.w.WriteHeader(http.StatusOK)
+	}
+	return e.w.Write(p)
+}
@@ -0,0 +1,174 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"mime/multipart"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"nocode-app/backend/internal/middleware"
+	"nocode-app/backend/internal/models"
+	"nocode-app/backend/internal/services"
+	"nocode-app/backend/internal/utils"
+)
+
+// maxImportFileSize インポートで受け付けるファイルの最大サイズ（10MB）
+const maxImportFileSize = 10 << 20
+
+// Import CSVまたはXLSXファイルからレコードをインポートする
+// multipart/form-data で以下を受け付ける
+//   - file: インポートするファイル（.csv / .xlsx）
+//   - mapping: 見出しをキー、フィールドコードを値とするJSON（省略時は見出しで自動照合）
+//   - dry_run: true の場合は検証のみ行う
+//   - mode: atomic（既定）または chunked
+//   - chunk_size: chunkedモードのチャンクサイズ
+//   - encoding: CSVの文字コード（utf-8 または shift_jis）
+func (h *RecordHandler) Import(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		utils.WriteErrorResponse(w, http.StatusMethodNotAllowed, "メソッドが許可されていません")
+		return
+	}
+
+	claims, ok := middleware.GetUserFromContext(r.Context())
+	if !ok {
+		utils.WriteErrorResponse(w, http.StatusUnauthorized, "認証されていません")
+		return
+	}
+
+	appID, err := extractAppIDFromRecordPath(r.URL.Path)
+	if err != nil {
+		utils.WriteErrorResponse(w, http.StatusBadRequest, "無効なアプリIDです")
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxImportFileSize)
+	if err := r.ParseMultipartForm(maxImportFileSize); err != nil {
+		var maxErr *http.MaxBytesError
+		if errors.As(err, &maxErr) {
+			utils.WriteErrorResponse(w, http.StatusRequestEntityTooLarge, "ファイルサイズは10MBまでです")
+			return
+		}
+		utils.WriteErrorResponse(w, http.StatusBadRequest, "multipart/form-data で送信してください")
+		return
+	}
+
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		utils.WriteErrorResponse(w, http.StatusBadRequest, "ファイルを指定してください")
+		return
+	}
+	defer func() { _ = file.Close() }()
+
+	req, err := parseImportForm(r)
+	if err != nil {
+		utils.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	rows, isXLSX, err := readImportFile(file, header, r.FormValue("encoding"))
+	if err != nil {
+		utils.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if len(rows) > 0 {
+		req.Headers = rows[0]
+		req.Rows = rows[1:]
+	}
+	req.SerialDates = isXLSX
+
+	result, err := h.recordService.ImportRecords(r.Context(), appID, claims.UserID, req)
+	if err != nil {
+		// チャンクの登録に失敗した場合もそれまでの進捗を返す
+		if errors.Is(err, services.ErrImportChunkFailed) && result != nil {
+			log.Printf("レコードインポートエラー: %v", err)
+			utils.WriteJSON(w, http.StatusOK, result)
+			return
+		}
+		if errors.Is(err, services.ErrAppNotFound) {
+			utils.WriteErrorResponse(w, http.StatusNotFound, err.Error())
+			return
+		}
+		if errors.Is(err, services.ErrExternalAppReadOnly) || errors.Is(err, services.ErrPermissionDenied) {
+			utils.WriteErrorResponse(w, http.StatusForbidden, err.Error())
+			return
+		}
+		if errors.Is(err, services.ErrImportNoHeader) ||
+			errors.Is(err, services.ErrImportMapping) ||
+			errors.Is(err, services.ErrImportTooManyRows) ||
+			errors.Is(err, services.ErrImportInvalidMode) {
+			utils.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		if errors.Is(err, services.ErrDuplicateValue) {
+			utils.WriteErrorResponse(w, http.StatusConflict, err.Error())
+			return
+		}
+		utils.WriteErrorResponse(w, http.StatusInternalServerError, "レコードのインポートに失敗しました")
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, result)
+}
+
+// parseImportForm インポートのオプションをフォームから読み取る
+func parseImportForm(r *http.Request) (*models.ImportRecordsRequest, error) {
+	req := &models.ImportRecordsRequest{
+		Mode: models.ImportMode(r.FormValue("mode")),
+	}
+
+	if v := r.FormValue("mapping"); v != "" {
+		if err := json.Unmarshal([]byte(v), &req.Mapping); err != nil {
+			return nil, errors.New("mapping は見出しとフィールドコードの対応を表すJSONオブジェクトで指定してください")
+		}
+	}
+	if v := r.FormValue("dry_run"); v != "" {
+		dryRun, err := strconv.ParseBool(v)
+		if err != nil {
+			return nil, errors.New("dry_run は true または false で指定してください")
+		}
+		req.DryRun = dryRun
+	}
+	if v := r.FormValue("chunk_size"); v != "" {
+		size, err := strconv.Atoi(v)
+		if err != nil || size < 1 {
+			return nil, errors.New("chunk_size は1以上の整数で指定してください")
+		}
+		req.ChunkSize = size
+	}
+	return req, nil
+}
+
+// readImportFile アップロードされたファイルを拡張子に応じて読み込む
+// XLSXの場合は2番目の戻り値がtrueになる
+func readImportFile(file multipart.File, header *multipart.FileHeader, encoding string) ([][]string, bool, error) {
+	switch strings.ToLower(filepath.Ext(header.Filename)) {
+	case ".csv", ".txt":
+		rows, err := utils.ReadCSV(file, encoding)
+		if err != nil {
+			if errors.Is(err, utils.ErrUnsupportedEncoding) {
+				return nil, false, err
+			}
+			return nil, false, errors.New("CSVファイルの読み込みに失敗しました")
+		}
+		return rows, false, nil
+	case ".xlsx":
+		// 見出し行の分を加えた行数を超えた時点で読み込みをやめる
+		rows, err := utils.ReadXLSX(file, header.Size, services.MaxImportRows+1)
+		if err != nil {
+			if errors.Is(err, utils.ErrTooManyRows) {
+				return nil, true, services.ErrImportTooManyRows
+			}
+			if errors.Is(err, utils.ErrXLSXTooLarge) {
+				return nil, true, err
+			}
+			return nil, true, utils.ErrInvalidXLSX
+		}
+		return rows, true, nil
+	default:
+		return nil, false, errors.New("CSV（.csv）またはExcel（.xlsx）ファイルを指定してください")
+	}
+}
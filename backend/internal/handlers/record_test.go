@@ -4,8 +4,13 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"testing"
 	"time"
 
@@ -16,6 +21,7 @@ import (
 	"nocode-app/backend/internal/handlers"
 	"nocode-app/backend/internal/middleware"
 	"nocode-app/backend/internal/models"
+	"nocode-app/backend/internal/repositories"
 	"nocode-app/backend/internal/services"
 	"nocode-app/backend/internal/testhelpers/mocks"
 	"nocode-app/backend/internal/utils"
@@ -140,6 +146,34 @@ func TestRecordHandler_Create(t *testing.T) {
 		mockService.AssertExpectations(t)
 	})
 
+	t.Run("validation error", func(t *testing.T) {
+		mockService := new(mocks.MockRecordService)
+		handler := handlers.NewRecordHandler(mockService, validator)
+
+		validationErr := &services.RecordValidationError{
+			Errors: models.FieldErrors{"name": "必須項目です"},
+		}
+		mockService.On("CreateRecord", mock.Anything, uint64(1), uint64(1), mock.AnythingOfType("*models.CreateRecordRequest")).Return(nil, validationErr)
+
+		req := models.CreateRecordRequest{Data: models.RecordData{"name": ""}}
+		body, _ := json.Marshal(req)
+		httpReq := httptest.NewRequest(http.MethodPost, "/api/v1/apps/1/records", bytes.NewReader(body))
+		httpReq.Header.Set("Content-Type", "application/json")
+		httpReq = httpReq.WithContext(recordContextWithClaims(httpReq.Context(), 1))
+		rr := httptest.NewRecorder()
+
+		handler.Create(rr, httpReq)
+
+		assert.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+
+		var result models.ValidationErrorResponse
+		err := json.Unmarshal(rr.Body.Bytes(), &result)
+		require.NoError(t, err)
+		assert.Equal(t, "必須項目です", result.Errors["name"])
+
+		mockService.AssertExpectations(t)
+	})
+
 	t.Run("unauthorized", func(t *testing.T) {
 		mockService := new(mocks.MockRecordService)
 		handler := handlers.NewRecordHandler(mockService, validator)
@@ -261,10 +295,11 @@ func TestRecordHandler_Update(t *testing.T) {
 			UpdatedAt: now,
 		}
 
-		mockService.On("UpdateRecord", mock.Anything, uint64(1), uint64(1), mock.AnythingOfType("*models.UpdateRecordRequest")).Return(resp, nil)
+		mockService.On("UpdateRecord", mock.Anything, uint64(1), uint64(1), int64(1), mock.AnythingOfType("*models.UpdateRecordRequest")).Return(resp, nil)
 
 		body, _ := json.Marshal(req)
 		httpReq := httptest.NewRequest(http.MethodPut, "/api/v1/apps/1/records/1", bytes.NewReader(body))
+		httpReq.Header.Set("If-Match", `"1"`)
 		httpReq.Header.Set("Content-Type", "application/json")
 		rr := httptest.NewRecorder()
 
@@ -309,6 +344,7 @@ func TestRecordHandler_Update(t *testing.T) {
 		handler := handlers.NewRecordHandler(mockService, validator)
 
 		httpReq := httptest.NewRequest(http.MethodPut, "/api/v1/apps/1/records/1", bytes.NewReader([]byte("invalid json")))
+		httpReq.Header.Set("If-Match", `"1"`)
 		httpReq.Header.Set("Content-Type", "application/json")
 		rr := httptest.NewRecorder()
 
@@ -325,10 +361,11 @@ func TestRecordHandler_Update(t *testing.T) {
 			Data: models.RecordData{"name": "Test"},
 		}
 
-		mockService.On("UpdateRecord", mock.Anything, uint64(999), uint64(1), mock.AnythingOfType("*models.UpdateRecordRequest")).Return(nil, services.ErrAppNotFound)
+		mockService.On("UpdateRecord", mock.Anything, uint64(999), uint64(1), int64(1), mock.AnythingOfType("*models.UpdateRecordRequest")).Return(nil, services.ErrAppNotFound)
 
 		body, _ := json.Marshal(req)
 		httpReq := httptest.NewRequest(http.MethodPut, "/api/v1/apps/999/records/1", bytes.NewReader(body))
+		httpReq.Header.Set("If-Match", `"1"`)
 		httpReq.Header.Set("Content-Type", "application/json")
 		rr := httptest.NewRecorder()
 
@@ -346,9 +383,10 @@ func TestRecordHandler_Delete(t *testing.T) {
 		mockService := new(mocks.MockRecordService)
 		handler := handlers.NewRecordHandler(mockService, validator)
 
-		mockService.On("DeleteRecord", mock.Anything, uint64(1), uint64(1)).Return(nil)
+		mockService.On("DeleteRecord", mock.Anything, uint64(1), uint64(1), int64(1)).Return(nil)
 
 		httpReq := httptest.NewRequest(http.MethodDelete, "/api/v1/apps/1/records/1", nil)
+		httpReq.Header.Set("If-Match", `"1"`)
 		rr := httptest.NewRecorder()
 
 		handler.Delete(rr, httpReq)
@@ -391,9 +429,10 @@ func TestRecordHandler_Delete(t *testing.T) {
 		mockService := new(mocks.MockRecordService)
 		handler := handlers.NewRecordHandler(mockService, validator)
 
-		mockService.On("DeleteRecord", mock.Anything, uint64(999), uint64(1)).Return(services.ErrAppNotFound)
+		mockService.On("DeleteRecord", mock.Anything, uint64(999), uint64(1), int64(1)).Return(services.ErrAppNotFound)
 
 		httpReq := httptest.NewRequest(http.MethodDelete, "/api/v1/apps/999/records/1", nil)
+		httpReq.Header.Set("If-Match", `"1"`)
 		rr := httptest.NewRecorder()
 
 		handler.Delete(rr, httpReq)
@@ -402,13 +441,30 @@ func TestRecordHandler_Delete(t *testing.T) {
 		mockService.AssertExpectations(t)
 	})
 
+	t.Run("record referenced", func(t *testing.T) {
+		mockService := new(mocks.MockRecordService)
+		handler := handlers.NewRecordHandler(mockService, validator)
+
+		mockService.On("DeleteRecord", mock.Anything, uint64(2), uint64(7), int64(1)).Return(services.ErrRecordReferenced)
+
+		httpReq := httptest.NewRequest(http.MethodDelete, "/api/v1/apps/2/records/7", nil)
+		httpReq.Header.Set("If-Match", `"1"`)
+		rr := httptest.NewRecorder()
+
+		handler.Delete(rr, httpReq)
+
+		assert.Equal(t, http.StatusConflict, rr.Code)
+		mockService.AssertExpectations(t)
+	})
+
 	t.Run("service error", func(t *testing.T) {
 		mockService := new(mocks.MockRecordService)
 		handler := handlers.NewRecordHandler(mockService, validator)
 
-		mockService.On("DeleteRecord", mock.Anything, uint64(1), uint64(999)).Return(services.ErrRecordNotFound)
+		mockService.On("DeleteRecord", mock.Anything, uint64(1), uint64(999), int64(1)).Return(errors.New("database error"))
 
 		httpReq := httptest.NewRequest(http.MethodDelete, "/api/v1/apps/1/records/999", nil)
+		httpReq.Header.Set("If-Match", `"1"`)
 		rr := httptest.NewRecorder()
 
 		handler.Delete(rr, httpReq)
@@ -418,6 +474,116 @@ func TestRecordHandler_Delete(t *testing.T) {
 	})
 }
 
+func TestRecordHandler_Version(t *testing.T) {
+	validator := utils.NewValidator()
+	updateBody := func(t *testing.T, req models.UpdateRecordRequest) *bytes.Reader {
+		body, err := json.Marshal(req)
+		require.NoError(t, err)
+		return bytes.NewReader(body)
+	}
+	conflict := &services.VersionConflictError{
+		Version: 200,
+		Current: &models.RecordResponse{ID: 1, Data: models.RecordData{"name": "Theirs"}, Version: 200},
+	}
+
+	t.Run("get returns the version as etag", func(t *testing.T) {
+		mockService := new(mocks.MockRecordService)
+		handler := handlers.NewRecordHandler(mockService, validator)
+		mockService.On("GetRecord", mock.Anything, uint64(1), uint64(1)).Return(&models.RecordResponse{ID: 1, Version: 100}, nil)
+
+		rr := httptest.NewRecorder()
+		handler.Get(rr, httptest.NewRequest(http.MethodGet, "/api/v1/apps/1/records/1", nil))
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, `"100"`, rr.Header().Get("ETag"))
+	})
+
+	t.Run("update without version is rejected", func(t *testing.T) {
+		mockService := new(mocks.MockRecordService)
+		handler := handlers.NewRecordHandler(mockService, validator)
+
+		httpReq := httptest.NewRequest(http.MethodPut, "/api/v1/apps/1/records/1", updateBody(t, models.UpdateRecordRequest{Data: models.RecordData{"name": "Mine"}}))
+		rr := httptest.NewRecorder()
+		handler.Update(rr, httpReq)
+
+		assert.Equal(t, http.StatusPreconditionRequired, rr.Code)
+		mockService.AssertNotCalled(t, "UpdateRecord", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("stale if-match returns the current record", func(t *testing.T) {
+		mockService := new(mocks.MockRecordService)
+		handler := handlers.NewRecordHandler(mockService, validator)
+		mockService.On("UpdateRecord", mock.Anything, uint64(1), uint64(1), int64(100), mock.Anything).Return(nil, conflict)
+
+		httpReq := httptest.NewRequest(http.MethodPut, "/api/v1/apps/1/records/1", updateBody(t, models.UpdateRecordRequest{Data: models.RecordData{"name": "Mine"}}))
+		httpReq.Header.Set("If-Match", `"100"`)
+		rr := httptest.NewRecorder()
+		handler.Update(rr, httpReq)
+
+		assert.Equal(t, http.StatusPreconditionFailed, rr.Code)
+		assert.Equal(t, `"200"`, rr.Header().Get("ETag"))
+		var result struct {
+			Version int64                 `json:"version"`
+			Current models.RecordResponse `json:"current"`
+		}
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &result))
+		assert.Equal(t, int64(200), result.Version)
+		assert.Equal(t, "Theirs", result.Current.Data["name"])
+	})
+
+	t.Run("stale version in body is a conflict", func(t *testing.T) {
+		mockService := new(mocks.MockRecordService)
+		handler := handlers.NewRecordHandler(mockService, validator)
+		mockService.On("UpdateRecord", mock.Anything, uint64(1), uint64(1), int64(100), mock.Anything).Return(nil, conflict)
+
+		version := int64(100)
+		httpReq := httptest.NewRequest(http.MethodPut, "/api/v1/apps/1/records/1", updateBody(t, models.UpdateRecordRequest{Data: models.RecordData{"name": "Mine"}, Version: &version}))
+		rr := httptest.NewRecorder()
+		handler.Update(rr, httpReq)
+
+		assert.Equal(t, http.StatusConflict, rr.Code)
+		assert.Equal(t, `"200"`, rr.Header().Get("ETag"))
+	})
+
+	t.Run("if-match wildcard skips the check", func(t *testing.T) {
+		mockService := new(mocks.MockRecordService)
+		handler := handlers.NewRecordHandler(mockService, validator)
+		mockService.On("UpdateRecord", mock.Anything, uint64(1), uint64(1), int64(0), mock.Anything).Return(&models.RecordResponse{ID: 1, Version: 300}, nil)
+
+		httpReq := httptest.NewRequest(http.MethodPut, "/api/v1/apps/1/records/1", updateBody(t, models.UpdateRecordRequest{Data: models.RecordData{"name": "Mine"}}))
+		httpReq.Header.Set("If-Match", "*")
+		rr := httptest.NewRecorder()
+		handler.Update(rr, httpReq)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, `"300"`, rr.Header().Get("ETag"))
+	})
+
+	t.Run("weak etag is rejected", func(t *testing.T) {
+		mockService := new(mocks.MockRecordService)
+		handler := handlers.NewRecordHandler(mockService, validator)
+
+		httpReq := httptest.NewRequest(http.MethodDelete, "/api/v1/apps/1/records/1", nil)
+		httpReq.Header.Set("If-Match", `W/"100"`)
+		rr := httptest.NewRecorder()
+		handler.Delete(rr, httpReq)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+
+	t.Run("delete with version query parameter", func(t *testing.T) {
+		mockService := new(mocks.MockRecordService)
+		handler := handlers.NewRecordHandler(mockService, validator)
+		mockService.On("DeleteRecord", mock.Anything, uint64(1), uint64(1), int64(100)).Return(nil)
+
+		rr := httptest.NewRecorder()
+		handler.Delete(rr, httptest.NewRequest(http.MethodDelete, "/api/v1/apps/1/records/1?version=100", nil))
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		mockService.AssertExpectations(t)
+	})
+}
+
 func TestRecordHandler_BulkCreate(t *testing.T) {
 	validator := utils.NewValidator()
 
@@ -456,6 +622,43 @@ func TestRecordHandler_BulkCreate(t *testing.T) {
 		mockService.AssertExpectations(t)
 	})
 
+	t.Run("validation error", func(t *testing.T) {
+		mockService := new(mocks.MockRecordService)
+		handler := handlers.NewRecordHandler(mockService, validator)
+
+		validationErr := &services.RecordValidationError{
+			RecordErrors: []models.RecordFieldErrors{
+				{Index: 1, Errors: models.FieldErrors{"amount": "数値で入力してください"}},
+			},
+		}
+		mockService.On("BulkCreateRecords", mock.Anything, uint64(1), uint64(1), mock.AnythingOfType("*models.BulkCreateRecordRequest")).Return(nil, validationErr)
+
+		req := models.BulkCreateRecordRequest{
+			Records: []models.RecordData{
+				{"amount": 1},
+				{"amount": "abc"},
+			},
+		}
+		body, _ := json.Marshal(req)
+		httpReq := httptest.NewRequest(http.MethodPost, "/api/v1/apps/1/records/bulk", bytes.NewReader(body))
+		httpReq.Header.Set("Content-Type", "application/json")
+		httpReq = httpReq.WithContext(recordContextWithClaims(httpReq.Context(), 1))
+		rr := httptest.NewRecorder()
+
+		handler.BulkCreate(rr, httpReq)
+
+		assert.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+
+		var result models.ValidationErrorResponse
+		err := json.Unmarshal(rr.Body.Bytes(), &result)
+		require.NoError(t, err)
+		require.Len(t, result.RecordErrors, 1)
+		assert.Equal(t, 1, result.RecordErrors[0].Index)
+		assert.Equal(t, "数値で入力してください", result.RecordErrors[0].Errors["amount"])
+
+		mockService.AssertExpectations(t)
+	})
+
 	t.Run("unauthorized", func(t *testing.T) {
 		mockService := new(mocks.MockRecordService)
 		handler := handlers.NewRecordHandler(mockService, validator)
@@ -700,4 +903,547 @@ func TestRecordHandler_List_WithFilters(t *testing.T) {
 		assert.Equal(t, http.StatusOK, rr.Code)
 		mockService.AssertExpectations(t)
 	})
+
+	t.Run("with nested where expression", func(t *testing.T) {
+		mockService := new(mocks.MockRecordService)
+		handler := handlers.NewRecordHandler(mockService, validator)
+
+		var received repositories.RecordQueryOptions
+		mockService.On("GetRecords", mock.Anything, uint64(1), mock.AnythingOfType("repositories.RecordQueryOptions")).
+			Return(&models.RecordListResponse{Records: []models.RecordResponse{}, Pagination: &models.Pagination{Page: 1, Limit: 20}}, nil).
+			Run(func(args mock.Arguments) { received = args.Get(2).(repositories.RecordQueryOptions) })
+
+		where := `{"or":[{"field":"status","operator":"eq","value":"open"},{"not":{"field":"owner","operator":"is_null"}}]}`
+		q := url.Values{"where": {where}, "filter": {"tags:contains_any:a,b", "owner:not_null"}, "tz": {"Asia/Tokyo"}}
+		httpReq := httptest.NewRequest(http.MethodGet, "/api/v1/apps/1/records?"+q.Encode(), nil)
+		rr := httptest.NewRecorder()
+
+		handler.List(rr, httpReq)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, []models.FilterItem{
+			{Field: "tags", Operator: "contains_any", Value: "a,b"},
+			{Field: "owner", Operator: "not_null"},
+		}, received.Filters)
+		require.NotNil(t, received.Filter)
+		require.Len(t, received.Filter.Or, 2)
+		assert.Equal(t, "open", received.Filter.Or[0].Value)
+		require.NotNil(t, received.Filter.Or[1].Not)
+		assert.Equal(t, "is_null", received.Filter.Or[1].Not.Operator)
+		assert.Equal(t, "Asia/Tokyo", received.TimeZone)
+	})
+
+	t.Run("with cursor pagination", func(t *testing.T) {
+		mockService := new(mocks.MockRecordService)
+		handler := handlers.NewRecordHandler(mockService, validator)
+
+		var received []repositories.RecordQueryOptions
+		mockService.On("GetRecords", mock.Anything, uint64(1), mock.AnythingOfType("repositories.RecordQueryOptions")).
+			Return(&models.RecordListResponse{Records: []models.RecordResponse{}, Pagination: &models.Pagination{Limit: 20, NextCursor: "next"}}, nil).
+			Run(func(args mock.Arguments) { received = append(received, args.Get(2).(repositories.RecordQueryOptions)) })
+
+		for _, query := range []string{"cursor=&count=none", "cursor=abc", "page=2"} {
+			httpReq := httptest.NewRequest(http.MethodGet, "/api/v1/apps/1/records?"+query, nil)
+			rr := httptest.NewRecorder()
+			handler.List(rr, httpReq)
+			assert.Equal(t, http.StatusOK, rr.Code)
+		}
+
+		require.Len(t, received, 3)
+		assert.True(t, received[0].Keyset)
+		assert.Empty(t, received[0].Cursor)
+		assert.Equal(t, repositories.CountNone, received[0].Count)
+		assert.True(t, received[1].Keyset)
+		assert.Equal(t, "abc", received[1].Cursor)
+		assert.False(t, received[2].Keyset)
+	})
+
+	t.Run("invalid cursor", func(t *testing.T) {
+		mockService := new(mocks.MockRecordService)
+		handler := handlers.NewRecordHandler(mockService, validator)
+
+		mockService.On("GetRecords", mock.Anything, uint64(1), mock.AnythingOfType("repositories.RecordQueryOptions")).
+			Return(nil, fmt.Errorf("%w: カーソルの形式が正しくありません", services.ErrInvalidPagination))
+
+		httpReq := httptest.NewRequest(http.MethodGet, "/api/v1/apps/1/records?cursor=***", nil)
+		rr := httptest.NewRecorder()
+		handler.List(rr, httpReq)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+
+	t.Run("where is not json", func(t *testing.T) {
+		mockService := new(mocks.MockRecordService)
+		handler := handlers.NewRecordHandler(mockService, validator)
+
+		httpReq := httptest.NewRequest(http.MethodGet, "/api/v1/apps/1/records?where=status%3Dopen", nil)
+		rr := httptest.NewRecorder()
+
+		handler.List(rr, httpReq)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+		mockService.AssertNotCalled(t, "GetRecords", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("invalid filter", func(t *testing.T) {
+		mockService := new(mocks.MockRecordService)
+		handler := handlers.NewRecordHandler(mockService, validator)
+
+		mockService.On("GetRecords", mock.Anything, uint64(1), mock.AnythingOfType("repositories.RecordQueryOptions")).Return(nil, services.ErrInvalidFilter)
+
+		httpReq := httptest.NewRequest(http.MethodGet, "/api/v1/apps/1/records?filter=amount:like:1", nil)
+		rr := httptest.NewRecorder()
+
+		handler.List(rr, httpReq)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+}
+
+func TestRecordHandler_List_Search(t *testing.T) {
+	validator := utils.NewValidator()
+
+	t.Run("keyword is passed to service", func(t *testing.T) {
+		mockService := new(mocks.MockRecordService)
+		handler := handlers.NewRecordHandler(mockService, validator)
+
+		resp := &models.RecordListResponse{
+			Records: []models.RecordResponse{
+				{ID: 1, Data: models.RecordData{"name": "株式会社ABC"}, Search: &models.RecordSearchMatch{Rank: 0.8, Highlight: "株式会社<mark>ABC</mark>"}},
+			},
+			Pagination: &models.Pagination{Total: 1, Page: 1, Limit: 20},
+		}
+		mockService.On("GetRecords", mock.Anything, uint64(1), mock.MatchedBy(func(opts repositories.RecordQueryOptions) bool {
+			return opts.Search == "株式会社 ABC"
+		})).Return(resp, nil)
+
+		q := url.Values{"q": {"株式会社 ABC"}}
+		httpReq := httptest.NewRequest(http.MethodGet, "/api/v1/apps/1/records?"+q.Encode(), nil)
+		rr := httptest.NewRecorder()
+
+		handler.List(rr, httpReq)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		var result models.RecordListResponse
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &result))
+		require.Len(t, result.Records, 1)
+		require.NotNil(t, result.Records[0].Search)
+		assert.Equal(t, "株式会社<mark>ABC</mark>", result.Records[0].Search.Highlight)
+	})
+
+	t.Run("invalid keyword", func(t *testing.T) {
+		mockService := new(mocks.MockRecordService)
+		handler := handlers.NewRecordHandler(mockService, validator)
+
+		mockService.On("GetRecords", mock.Anything, uint64(1), mock.AnythingOfType("repositories.RecordQueryOptions")).Return(nil, services.ErrInvalidSearch)
+
+		httpReq := httptest.NewRequest(http.MethodGet, "/api/v1/apps/1/records?q=foo", nil)
+		rr := httptest.NewRecorder()
+
+		handler.List(rr, httpReq)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+}
+
+func TestRecordHandler_AccessErrors(t *testing.T) {
+	validator := utils.NewValidator()
+
+	t.Run("delete record of another user", func(t *testing.T) {
+		mockService := new(mocks.MockRecordService)
+		handler := handlers.NewRecordHandler(mockService, validator)
+
+		mockService.On("DeleteRecord", mock.Anything, uint64(1), uint64(5), int64(1)).Return(services.ErrRecordNotFound)
+
+		httpReq := httptest.NewRequest(http.MethodDelete, "/api/v1/apps/1/records/5", nil)
+		httpReq.Header.Set("If-Match", `"1"`)
+		rr := httptest.NewRecorder()
+
+		handler.Delete(rr, httpReq)
+
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+	})
+
+	t.Run("viewer cannot create", func(t *testing.T) {
+		mockService := new(mocks.MockRecordService)
+		handler := handlers.NewRecordHandler(mockService, validator)
+
+		mockService.On("CreateRecord", mock.Anything, uint64(1), uint64(1), mock.AnythingOfType("*models.CreateRecordRequest")).Return(nil, services.ErrPermissionDenied)
+
+		body, _ := json.Marshal(models.CreateRecordRequest{Data: models.RecordData{"name": "x"}})
+		httpReq := httptest.NewRequest(http.MethodPost, "/api/v1/apps/1/records", bytes.NewReader(body))
+		httpReq.Header.Set("Content-Type", "application/json")
+		httpReq = httpReq.WithContext(middleware.SetUserInContext(httpReq.Context(), &utils.JWTClaims{UserID: 1, Role: "user"}))
+		rr := httptest.NewRecorder()
+
+		handler.Create(rr, httpReq)
+
+		assert.Equal(t, http.StatusForbidden, rr.Code)
+	})
+}
+
+func TestRecordHandler_History(t *testing.T) {
+	validator := utils.NewValidator()
+
+	t.Run("successful get history", func(t *testing.T) {
+		mockService := new(mocks.MockRecordService)
+		handler := handlers.NewRecordHandler(mockService, validator)
+
+		resp := &models.RecordHistoryResponse{
+			Revisions: []models.RecordRevisionResponse{
+				{ID: 1, RecordID: 5, Action: models.RevisionActionCreate},
+			},
+			Pagination: models.NewPagination(1, 20, 1),
+		}
+		mockService.On("GetRecordHistory", mock.Anything, uint64(1), uint64(5), 1, 20).Return(resp, nil)
+
+		httpReq := httptest.NewRequest(http.MethodGet, "/api/v1/apps/1/records/5/history", nil)
+		rr := httptest.NewRecorder()
+
+		handler.History(rr, httpReq)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+
+		var body models.RecordHistoryResponse
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &body))
+		require.Len(t, body.Revisions, 1)
+		assert.Equal(t, models.RevisionActionCreate, body.Revisions[0].Action)
+	})
+
+	t.Run("out of range limit falls back to default", func(t *testing.T) {
+		mockService := new(mocks.MockRecordService)
+		handler := handlers.NewRecordHandler(mockService, validator)
+
+		mockService.On("GetRecordHistory", mock.Anything, uint64(1), uint64(5), 2, 20).Return(&models.RecordHistoryResponse{}, nil)
+
+		httpReq := httptest.NewRequest(http.MethodGet, "/api/v1/apps/1/records/5/history?page=2&limit=0", nil)
+		rr := httptest.NewRecorder()
+
+		handler.History(rr, httpReq)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("record not found", func(t *testing.T) {
+		mockService := new(mocks.MockRecordService)
+		handler := handlers.NewRecordHandler(mockService, validator)
+
+		mockService.On("GetRecordHistory", mock.Anything, uint64(1), uint64(5), 1, 20).Return(nil, services.ErrRecordNotFound)
+
+		httpReq := httptest.NewRequest(http.MethodGet, "/api/v1/apps/1/records/5/history", nil)
+		rr := httptest.NewRecorder()
+
+		handler.History(rr, httpReq)
+
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+	})
+}
+
+func TestRecordHandler_Revert(t *testing.T) {
+	validator := utils.NewValidator()
+
+	t.Run("successful revert", func(t *testing.T) {
+		mockService := new(mocks.MockRecordService)
+		handler := handlers.NewRecordHandler(mockService, validator)
+
+		record := &models.RecordResponse{ID: 5, Data: models.RecordData{"name": "Old"}, Version: 1}
+		mockService.On("RevertRecord", mock.Anything, uint64(1), uint64(5), uint64(3), int64(1)).Return(record, nil)
+
+		httpReq := httptest.NewRequest(http.MethodPost, "/api/v1/apps/1/records/5/history/3/revert", nil)
+		httpReq.Header.Set("If-Match", `"1"`)
+		rr := httptest.NewRecorder()
+
+		handler.Revert(rr, httpReq)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, `"1"`, rr.Header().Get("ETag"))
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("revert without version is rejected", func(t *testing.T) {
+		mockService := new(mocks.MockRecordService)
+		handler := handlers.NewRecordHandler(mockService, validator)
+
+		httpReq := httptest.NewRequest(http.MethodPost, "/api/v1/apps/1/records/5/history/3/revert", nil)
+		rr := httptest.NewRecorder()
+
+		handler.Revert(rr, httpReq)
+
+		assert.Equal(t, http.StatusPreconditionRequired, rr.Code)
+		mockService.AssertNotCalled(t, "RevertRecord", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("stale if-match returns the current record", func(t *testing.T) {
+		mockService := new(mocks.MockRecordService)
+		handler := handlers.NewRecordHandler(mockService, validator)
+
+		conflict := &services.VersionConflictError{Version: 2, Current: &models.RecordResponse{ID: 5, Version: 2}}
+		mockService.On("RevertRecord", mock.Anything, uint64(1), uint64(5), uint64(3), int64(1)).Return(nil, conflict)
+
+		httpReq := httptest.NewRequest(http.MethodPost, "/api/v1/apps/1/records/5/history/3/revert", nil)
+		httpReq.Header.Set("If-Match", `"1"`)
+		rr := httptest.NewRecorder()
+
+		handler.Revert(rr, httpReq)
+
+		assert.Equal(t, http.StatusPreconditionFailed, rr.Code)
+		assert.Equal(t, `"2"`, rr.Header().Get("ETag"))
+	})
+
+	t.Run("revision not found", func(t *testing.T) {
+		mockService := new(mocks.MockRecordService)
+		handler := handlers.NewRecordHandler(mockService, validator)
+
+		mockService.On("RevertRecord", mock.Anything, uint64(1), uint64(5), uint64(3), int64(1)).Return(nil, services.ErrRevisionNotFound)
+
+		httpReq := httptest.NewRequest(http.MethodPost, "/api/v1/apps/1/records/5/history/3/revert", nil)
+		httpReq.Header.Set("If-Match", `"1"`)
+		rr := httptest.NewRecorder()
+
+		handler.Revert(rr, httpReq)
+
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+	})
+
+	t.Run("snapshot no longer valid", func(t *testing.T) {
+		mockService := new(mocks.MockRecordService)
+		handler := handlers.NewRecordHandler(mockService, validator)
+
+		vErr := &services.RecordValidationError{Errors: models.FieldErrors{"status": "選択肢にない値です"}}
+		mockService.On("RevertRecord", mock.Anything, uint64(1), uint64(5), uint64(3), int64(1)).Return(nil, vErr)
+
+		httpReq := httptest.NewRequest(http.MethodPost, "/api/v1/apps/1/records/5/history/3/revert", nil)
+		httpReq.Header.Set("If-Match", `"1"`)
+		rr := httptest.NewRecorder()
+
+		handler.Revert(rr, httpReq)
+
+		assert.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+	})
+
+	t.Run("invalid revision id", func(t *testing.T) {
+		mockService := new(mocks.MockRecordService)
+		handler := handlers.NewRecordHandler(mockService, validator)
+
+		httpReq := httptest.NewRequest(http.MethodPost, "/api/v1/apps/1/records/5/history/abc/revert", nil)
+		rr := httptest.NewRecorder()
+
+		handler.Revert(rr, httpReq)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+}
+
+// newImportRequest インポート用のmultipartリクエストを作成する
+func newImportRequest(t *testing.T, filename, content string, fields map[string]string) *http.Request {
+	t.Helper()
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	fw, err := mw.CreateFormFile("file", filename)
+	require.NoError(t, err)
+	_, err = fw.Write([]byte(content))
+	require.NoError(t, err)
+	for k, v := range fields {
+		require.NoError(t, mw.WriteField(k, v))
+	}
+	require.NoError(t, mw.Close())
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/apps/1/records/import", &body)
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	return req.WithContext(recordContextWithClaims(req.Context(), 1))
+}
+
+func TestRecordHandler_Import(t *testing.T) {
+	validator := utils.NewValidator()
+
+	t.Run("successful csv import", func(t *testing.T) {
+		mockService := new(mocks.MockRecordService)
+		handler := handlers.NewRecordHandler(mockService, validator)
+
+		result := &models.ImportResult{Mode: models.ImportModeChunked, TotalRows: 1, ValidRows: 1, ImportedRows: 1}
+		mockService.On("ImportRecords", mock.Anything, uint64(1), uint64(1), mock.MatchedBy(func(req *models.ImportRecordsRequest) bool {
+			return assert.ObjectsAreEqual([]string{"氏名", "金額"}, req.Headers) &&
+				len(req.Rows) == 1 &&
+				req.Mapping["氏名"] == "name" &&
+				req.Mode == models.ImportModeChunked &&
+				req.ChunkSize == 100 &&
+				!req.DryRun &&
+				!req.SerialDates
+		})).Return(result, nil)
+
+		httpReq := newImportRequest(t, "records.csv", "氏名,金額\n山田,100\n", map[string]string{
+			"mapping":    `{"氏名":"name","金額":"amount"}`,
+			"mode":       "chunked",
+			"chunk_size": "100",
+		})
+		rr := httptest.NewRecorder()
+
+		handler.Import(rr, httpReq)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+
+		var body models.ImportResult
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &body))
+		assert.Equal(t, 1, body.ImportedRows)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("chunk failure returns progress", func(t *testing.T) {
+		mockService := new(mocks.MockRecordService)
+		handler := handlers.NewRecordHandler(mockService, validator)
+
+		result := &models.ImportResult{ImportedRows: 1, Chunks: []models.ImportChunkResult{{Index: 0, Imported: 1}, {Index: 1, Error: "failed"}}}
+		mockService.On("ImportRecords", mock.Anything, uint64(1), uint64(1), mock.Anything).Return(result, services.ErrImportChunkFailed)
+
+		httpReq := newImportRequest(t, "records.csv", "name\nA\nB\n", nil)
+		rr := httptest.NewRecorder()
+
+		handler.Import(rr, httpReq)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+	})
+
+	t.Run("unsupported file type", func(t *testing.T) {
+		mockService := new(mocks.MockRecordService)
+		handler := handlers.NewRecordHandler(mockService, validator)
+
+		httpReq := newImportRequest(t, "records.json", "[]", nil)
+		rr := httptest.NewRecorder()
+
+		handler.Import(rr, httpReq)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+		mockService.AssertNotCalled(t, "ImportRecords", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("invalid mapping json", func(t *testing.T) {
+		mockService := new(mocks.MockRecordService)
+		handler := handlers.NewRecordHandler(mockService, validator)
+
+		httpReq := newImportRequest(t, "records.csv", "name\nA\n", map[string]string{"mapping": "name"})
+		rr := httptest.NewRecorder()
+
+		handler.Import(rr, httpReq)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+
+	t.Run("mapping error from service", func(t *testing.T) {
+		mockService := new(mocks.MockRecordService)
+		handler := handlers.NewRecordHandler(mockService, validator)
+
+		mockService.On("ImportRecords", mock.Anything, uint64(1), uint64(1), mock.Anything).Return(nil, services.ErrImportMapping)
+
+		httpReq := newImportRequest(t, "records.csv", "unknown\nA\n", nil)
+		rr := httptest.NewRecorder()
+
+		handler.Import(rr, httpReq)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+
+	t.Run("method not allowed", func(t *testing.T) {
+		mockService := new(mocks.MockRecordService)
+		handler := handlers.NewRecordHandler(mockService, validator)
+
+		httpReq := httptest.NewRequest(http.MethodGet, "/api/v1/apps/1/records/import", nil)
+		rr := httptest.NewRecorder()
+
+		handler.Import(rr, httpReq)
+
+		assert.Equal(t, http.StatusMethodNotAllowed, rr.Code)
+	})
+}
+
+func TestRecordHandler_Export(t *testing.T) {
+	validator := utils.NewValidator()
+
+	t.Run("successful csv export", func(t *testing.T) {
+		mockService := new(mocks.MockRecordService)
+		handler := handlers.NewRecordHandler(mockService, validator)
+
+		opts := repositories.RecordQueryOptions{
+			Sort:    "amount",
+			Order:   "asc",
+			Filters: []models.FilterItem{{Field: "status", Operator: "eq", Value: "open"}},
+		}
+		mockService.On("ExportRecords", mock.Anything, uint64(1), opts, models.ExportFormatCSV, mock.Anything).Return(nil).Run(func(args mock.Arguments) {
+			w := args.Get(4).(io.Writer)
+			_, _ = w.Write([]byte("name\nA\n"))
+		})
+
+		httpReq := httptest.NewRequest(http.MethodGet, "/api/v1/apps/1/records/export?sort=amount&order=asc&filter=status:eq:open", nil)
+		rr := httptest.NewRecorder()
+
+		handler.Export(rr, httpReq)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, "text/csv; charset=utf-8", rr.Header().Get("Content-Type"))
+		assert.Equal(t, `attachment; filename="app_1_records.csv"`, rr.Header().Get("Content-Disposition"))
+		assert.Equal(t, "name\nA\n", rr.Body.String())
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("xlsx format", func(t *testing.T) {
+		mockService := new(mocks.MockRecordService)
+		handler := handlers.NewRecordHandler(mockService, validator)
+
+		mockService.On("ExportRecords", mock.Anything, uint64(1), mock.Anything, models.ExportFormatXLSX, mock.Anything).Return(nil).Run(func(args mock.Arguments) {
+			_, _ = args.Get(4).(io.Writer).Write([]byte("PK"))
+		})
+
+		httpReq := httptest.NewRequest(http.MethodGet, "/api/v1/apps/1/records/export?format=xlsx", nil)
+		rr := httptest.NewRecorder()
+
+		handler.Export(rr, httpReq)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet", rr.Header().Get("Content-Type"))
+	})
+
+	t.Run("invalid format", func(t *testing.T) {
+		mockService := new(mocks.MockRecordService)
+		handler := handlers.NewRecordHandler(mockService, validator)
+
+		mockService.On("ExportRecords", mock.Anything, uint64(1), mock.Anything, models.ExportFormat("pdf"), mock.Anything).Return(services.ErrInvalidExportFormat)
+
+		httpReq := httptest.NewRequest(http.MethodGet, "/api/v1/apps/1/records/export?format=pdf", nil)
+		rr := httptest.NewRecorder()
+
+		handler.Export(rr, httpReq)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+
+	t.Run("app not found", func(t *testing.T) {
+		mockService := new(mocks.MockRecordService)
+		handler := handlers.NewRecordHandler(mockService, validator)
+
+		mockService.On("ExportRecords", mock.Anything, uint64(1), mock.Anything, models.ExportFormatCSV, mock.Anything).Return(services.ErrAppNotFound)
+
+		httpReq := httptest.NewRequest(http.MethodGet, "/api/v1/apps/1/records/export", nil)
+		rr := httptest.NewRecorder()
+
+		handler.Export(rr, httpReq)
+
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+	})
+
+	t.Run("error after streaming started keeps status", func(t *testing.T) {
+		mockService := new(mocks.MockRecordService)
+		handler := handlers.NewRecordHandler(mockService, validator)
+
+		mockService.On("ExportRecords", mock.Anything, uint64(1), mock.Anything, models.ExportFormatCSV, mock.Anything).Return(errors.New("db error")).Run(func(args mock.Arguments) {
+			_, _ = args.Get(4).(io.Writer).Write([]byte("name\n"))
+		})
+
+		httpReq := httptest.NewRequest(http.MethodGet, "/api/v1/apps/1/records/export", nil)
+		rr := httptest.NewRecorder()
+
+		handler.Export(rr, httpReq)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, "name\n", rr.Body.String())
+	})
 }
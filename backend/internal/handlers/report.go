@@ -0,0 +1,139 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+
+	"nocode-app/backend/internal/models"
+	"nocode-app/backend/internal/repositories"
+	"nocode-app/backend/internal/services"
+	"nocode-app/backend/internal/utils"
+)
+
+// ReportHandler ピボット集計（クロス集計）のエンドポイントを処理する構造体
+type ReportHandler struct {
+	reportService services.ReportServiceInterface
+	validator     *utils.Validator
+}
+
+// NewReportHandler 新しいReportHandlerを作成する
+func NewReportHandler(reportService services.ReportServiceInterface, validator *utils.Validator) *ReportHandler {
+	return &ReportHandler{
+		reportService: reportService,
+		validator:     validator,
+	}
+}
+
+// Pivot 行・列のフィールドごとの集計値を小計・総計付きで取得する
+func (h *ReportHandler) Pivot(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		utils.WriteErrorResponse(w, http.StatusMethodNotAllowed, "メソッドが許可されていません")
+		return
+	}
+
+	appID, err := extractAppID(r.URL.Path)
+	if err != nil {
+		utils.WriteErrorResponse(w, http.StatusBadRequest, "無効なアプリIDです")
+		return
+	}
+
+	req, ok := h.parsePivotRequest(w, r)
+	if !ok {
+		return
+	}
+
+	resp, err := h.reportService.GetPivot(r.Context(), appID, req)
+	if err != nil {
+		if writeReportError(w, err) {
+			return
+		}
+		log.Printf("ピボット集計エラー: %v", err)
+		utils.WriteErrorResponse(w, http.StatusInternalServerError, "集計データの取得に失敗しました")
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, resp)
+}
+
+// ExportPivot ピボット集計の結果をファイルとしてダウンロードする
+// format で csv（既定）/ xlsx を指定する
+func (h *ReportHandler) ExportPivot(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		utils.WriteErrorResponse(w, http.StatusMethodNotAllowed, "メソッドが許可されていません")
+		return
+	}
+
+	appID, err := extractAppID(r.URL.Path)
+	if err != nil {
+		utils.WriteErrorResponse(w, http.StatusBadRequest, "無効なアプリIDです")
+		return
+	}
+
+	req, ok := h.parsePivotRequest(w, r)
+	if !ok {
+		return
+	}
+
+	format := models.ExportFormat(utils.GetQueryParam(r, "format", string(models.ExportFormatCSV)))
+	out := &exportResponseWriter{
+		w:        w,
+		format:   format,
+		filename: fmt.Sprintf("app_%d_pivot.%s", appID, format),
+	}
+	err = h.reportService.ExportPivot(r.Context(), appID, req, format, out)
+	if err == nil {
+		return
+	}
+
+	// 書き出し開始後はステータスを変更できないため、ログのみ残す
+	if out.started {
+		log.Printf("ピボット集計エクスポートエラー: %v", err)
+		return
+	}
+	if writeReportError(w, err) {
+		return
+	}
+	log.Printf("ピボット集計エクスポートエラー: %v", err)
+	utils.WriteErrorResponse(w, http.StatusInternalServerError, "集計データのエクスポートに失敗しました")
+}
+
+// parsePivotRequest リクエストボディを読み込んで検証する
+// 失敗した場合はエラーレスポンスを書き込んでfalseを返す
+func (h *ReportHandler) parsePivotRequest(w http.ResponseWriter, r *http.Request) (*models.PivotRequest, bool) {
+	var req models.PivotRequest
+	if err := h.validator.ParseAndValidate(r, &req); err != nil {
+		utils.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+		return nil, false
+	}
+	// count以外の集計には集計するフィールドが必須
+	for _, m := range req.Measures {
+		if m.Aggregation != "count" && m.Field == "" {
+			utils.WriteErrorResponse(w, http.StatusBadRequest, "count 以外の集計値には field を指定してください")
+			return nil, false
+		}
+	}
+	return &req, true
+}
+
+// writeReportError ピボット集計のエラーを対応するステータスコードで書き込む
+// 対応するステータスがない場合はfalseを返す
+func writeReportError(w http.ResponseWriter, err error) bool {
+	switch {
+	case errors.Is(err, services.ErrAppNotFound):
+		utils.WriteErrorResponse(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, services.ErrPermissionDenied):
+		utils.WriteErrorResponse(w, http.StatusForbidden, err.Error())
+	case errors.Is(err, services.ErrInvalidReportConfig),
+		errors.Is(err, services.ErrInvalidFilter),
+		errors.Is(err, services.ErrInvalidExportFormat),
+		errors.Is(err, repositories.ErrPivotTooLarge):
+		utils.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, services.ErrEncryptionNotInitialized):
+		utils.WriteErrorResponse(w, http.StatusServiceUnavailable, err.Error())
+	default:
+		return false
+	}
+	return true
+}
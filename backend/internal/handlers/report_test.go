@@ -0,0 +1,118 @@
+package handlers_test
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"nocode-app/backend/internal/handlers"
+	"nocode-app/backend/internal/models"
+	"nocode-app/backend/internal/repositories"
+	"nocode-app/backend/internal/services"
+	"nocode-app/backend/internal/testhelpers/mocks"
+	"nocode-app/backend/internal/utils"
+)
+
+const testPivotBody = `{"rows":[{"field":"region"}],"columns":[{"field":"status"}],"measures":[{"aggregation":"count"}]}`
+
+func TestReportHandler_Pivot(t *testing.T) {
+	t.Run("returns the matrix", func(t *testing.T) {
+		mockService := new(mocks.MockReportService)
+		handler := handlers.NewReportHandler(mockService, utils.NewValidator())
+
+		mockService.On("GetPivot", mock.Anything, uint64(1), mock.MatchedBy(func(req *models.PivotRequest) bool {
+			return req.Rows[0].Field == "region" && req.Columns[0].Field == "status"
+		})).Return(&models.PivotResponse{
+			RowFields: []string{"region"},
+			Measures:  []string{"count"},
+			Columns:   []models.PivotHeader{{Keys: []string{}, Total: true}},
+		}, nil)
+
+		httpReq := httptest.NewRequest(http.MethodPost, "/api/v1/apps/1/reports/pivot", strings.NewReader(testPivotBody))
+		httpReq.Header.Set("Content-Type", "application/json")
+		rr := httptest.NewRecorder()
+
+		handler.Pivot(rr, httpReq)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		var result models.PivotResponse
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &result))
+		assert.Equal(t, []string{"region"}, result.RowFields)
+	})
+
+	tests := []struct {
+		name       string
+		body       string
+		err        error
+		wantStatus int
+	}{
+		{name: "missing rows", body: `{"measures":[{"aggregation":"count"}]}`, wantStatus: http.StatusBadRequest},
+		{name: "sum without field", body: `{"rows":[{"field":"region"}],"measures":[{"aggregation":"sum"}]}`, wantStatus: http.StatusBadRequest},
+		{name: "invalid config", body: testPivotBody, err: services.ErrInvalidReportConfig, wantStatus: http.StatusBadRequest},
+		{name: "too large", body: testPivotBody, err: repositories.ErrPivotTooLarge, wantStatus: http.StatusBadRequest},
+		{name: "permission denied", body: testPivotBody, err: services.ErrPermissionDenied, wantStatus: http.StatusForbidden},
+		{name: "app not found", body: testPivotBody, err: services.ErrAppNotFound, wantStatus: http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(mocks.MockReportService)
+			handler := handlers.NewReportHandler(mockService, utils.NewValidator())
+			if tt.err != nil {
+				mockService.On("GetPivot", mock.Anything, uint64(1), mock.Anything).Return(nil, tt.err)
+			}
+
+			httpReq := httptest.NewRequest(http.MethodPost, "/api/v1/apps/1/reports/pivot", strings.NewReader(tt.body))
+			httpReq.Header.Set("Content-Type", "application/json")
+			rr := httptest.NewRecorder()
+
+			handler.Pivot(rr, httpReq)
+
+			assert.Equal(t, tt.wantStatus, rr.Code)
+		})
+	}
+}
+
+func TestReportHandler_ExportPivot(t *testing.T) {
+	t.Run("xlsx download", func(t *testing.T) {
+		mockService := new(mocks.MockReportService)
+		handler := handlers.NewReportHandler(mockService, utils.NewValidator())
+
+		mockService.On("ExportPivot", mock.Anything, uint64(1), mock.Anything, models.ExportFormatXLSX, mock.Anything).
+			Run(func(args mock.Arguments) {
+				_, _ = io.WriteString(args.Get(4).(io.Writer), "data")
+			}).Return(nil)
+
+		httpReq := httptest.NewRequest(http.MethodPost, "/api/v1/apps/1/reports/pivot/export?format=xlsx", strings.NewReader(testPivotBody))
+		httpReq.Header.Set("Content-Type", "application/json")
+		rr := httptest.NewRecorder()
+
+		handler.ExportPivot(rr, httpReq)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, models.ExportFormatXLSX.ContentType(), rr.Header().Get("Content-Type"))
+		assert.Contains(t, rr.Header().Get("Content-Disposition"), `filename="app_1_pivot.xlsx"`)
+	})
+
+	t.Run("unsupported format", func(t *testing.T) {
+		mockService := new(mocks.MockReportService)
+		handler := handlers.NewReportHandler(mockService, utils.NewValidator())
+
+		mockService.On("ExportPivot", mock.Anything, uint64(1), mock.Anything, models.ExportFormatNDJSON, mock.Anything).
+			Return(services.ErrInvalidExportFormat)
+
+		httpReq := httptest.NewRequest(http.MethodPost, "/api/v1/apps/1/reports/pivot/export?format=ndjson", strings.NewReader(testPivotBody))
+		httpReq.Header.Set("Content-Type", "application/json")
+		rr := httptest.NewRecorder()
+
+		handler.ExportPivot(rr, httpReq)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+}
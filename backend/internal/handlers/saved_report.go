@@ -0,0 +1,270 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"nocode-app/backend/internal/middleware"
+	"nocode-app/backend/internal/models"
+	"nocode-app/backend/internal/services"
+	"nocode-app/backend/internal/utils"
+)
+
+// SavedReportHandler 保存したレポートと配信のエンドポイントを処理する構造体
+type SavedReportHandler struct {
+	savedReportService services.SavedReportServiceInterface
+	validator          *utils.Validator
+}
+
+// NewSavedReportHandler 新しいSavedReportHandlerを作成する
+func NewSavedReportHandler(savedReportService services.SavedReportServiceInterface, validator *utils.Validator) *SavedReportHandler {
+	return &SavedReportHandler{
+		savedReportService: savedReportService,
+		validator:          validator,
+	}
+}
+
+// List アプリに保存されたレポートを一覧表示する
+func (h *SavedReportHandler) List(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		utils.WriteErrorResponse(w, http.StatusMethodNotAllowed, "メソッドが許可されていません")
+		return
+	}
+
+	appID, err := extractAppID(r.URL.Path)
+	if err != nil {
+		utils.WriteErrorResponse(w, http.StatusBadRequest, "無効なアプリIDです")
+		return
+	}
+
+	resp, err := h.savedReportService.GetReports(r.Context(), appID)
+	if err != nil {
+		if writeSavedReportError(w, err) {
+			return
+		}
+		utils.WriteErrorResponse(w, http.StatusInternalServerError, "レポートの取得に失敗しました")
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, resp)
+}
+
+// Get 保存したレポートを取得する
+func (h *SavedReportHandler) Get(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		utils.WriteErrorResponse(w, http.StatusMethodNotAllowed, "メソッドが許可されていません")
+		return
+	}
+
+	appID, reportID, err := extractAppAndSavedReportID(r.URL.Path)
+	if err != nil {
+		utils.WriteErrorResponse(w, http.StatusBadRequest, "無効なレポートIDです")
+		return
+	}
+
+	report, err := h.savedReportService.GetReport(r.Context(), appID, reportID)
+	if err != nil {
+		if writeSavedReportError(w, err) {
+			return
+		}
+		utils.WriteErrorResponse(w, http.StatusInternalServerError, "レポートの取得に失敗しました")
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, report)
+}
+
+// Create アプリにレポートを保存する
+func (h *SavedReportHandler) Create(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		utils.WriteErrorResponse(w, http.StatusMethodNotAllowed, "メソッドが許可されていません")
+		return
+	}
+
+	claims, ok := middleware.GetUserFromContext(r.Context())
+	if !ok {
+		utils.WriteErrorResponse(w, http.StatusUnauthorized, "認証されていません")
+		return
+	}
+
+	appID, err := extractAppID(r.URL.Path)
+	if err != nil {
+		utils.WriteErrorResponse(w, http.StatusBadRequest, "無効なアプリIDです")
+		return
+	}
+
+	var req models.SaveReportRequest
+	if err := h.validator.ParseAndValidate(r, &req); err != nil {
+		utils.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	report, err := h.savedReportService.CreateReport(r.Context(), appID, claims.UserID, &req)
+	if err != nil {
+		if writeSavedReportError(w, err) {
+			return
+		}
+		utils.WriteErrorResponse(w, http.StatusInternalServerError, "レポートの保存に失敗しました")
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusCreated, report)
+}
+
+// Update 保存したレポートを更新する
+func (h *SavedReportHandler) Update(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPut {
+		utils.WriteErrorResponse(w, http.StatusMethodNotAllowed, "メソッドが許可されていません")
+		return
+	}
+
+	appID, reportID, err := extractAppAndSavedReportID(r.URL.Path)
+	if err != nil {
+		utils.WriteErrorResponse(w, http.StatusBadRequest, "無効なレポートIDです")
+		return
+	}
+
+	var req models.SaveReportRequest
+	if err := h.validator.ParseAndValidate(r, &req); err != nil {
+		utils.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	report, err := h.savedReportService.UpdateReport(r.Context(), appID, reportID, &req)
+	if err != nil {
+		if writeSavedReportError(w, err) {
+			return
+		}
+		utils.WriteErrorResponse(w, http.StatusInternalServerError, "レポートの更新に失敗しました")
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, report)
+}
+
+// Delete 保存したレポートを削除する
+func (h *SavedReportHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		utils.WriteErrorResponse(w, http.StatusMethodNotAllowed, "メソッドが許可されていません")
+		return
+	}
+
+	appID, reportID, err := extractAppAndSavedReportID(r.URL.Path)
+	if err != nil {
+		utils.WriteErrorResponse(w, http.StatusBadRequest, "無効なレポートIDです")
+		return
+	}
+
+	if err := h.savedReportService.DeleteReport(r.Context(), appID, reportID); err != nil {
+		if writeSavedReportError(w, err) {
+			return
+		}
+		utils.WriteErrorResponse(w, http.StatusInternalServerError, "レポートの削除に失敗しました")
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, models.SuccessResponse{Message: "レポートを削除しました"})
+}
+
+// Send 保存したレポートをすぐに宛先へ送信し、配信履歴を返す
+// 集計やメールの送信に失敗した場合も、失敗した配信履歴を返す
+func (h *SavedReportHandler) Send(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		utils.WriteErrorResponse(w, http.StatusMethodNotAllowed, "メソッドが許可されていません")
+		return
+	}
+
+	appID, reportID, err := extractAppAndSavedReportID(r.URL.Path)
+	if err != nil {
+		utils.WriteErrorResponse(w, http.StatusBadRequest, "無効なレポートIDです")
+		return
+	}
+
+	delivery, err := h.savedReportService.SendReport(r.Context(), appID, reportID)
+	if err != nil {
+		if writeSavedReportError(w, err) {
+			return
+		}
+		utils.WriteErrorResponse(w, http.StatusInternalServerError, "レポートの送信に失敗しました")
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, delivery)
+}
+
+// Deliveries レポートの配信履歴を新しい順に一覧表示する
+func (h *SavedReportHandler) Deliveries(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		utils.WriteErrorResponse(w, http.StatusMethodNotAllowed, "メソッドが許可されていません")
+		return
+	}
+
+	appID, reportID, err := extractAppAndSavedReportID(r.URL.Path)
+	if err != nil {
+		utils.WriteErrorResponse(w, http.StatusBadRequest, "無効なレポートIDです")
+		return
+	}
+
+	page := utils.GetQueryParamInt(r, "page", 1)
+	if page < 1 {
+		page = 1
+	}
+	limit := utils.GetQueryParamInt(r, "limit", 20)
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+
+	resp, err := h.savedReportService.GetDeliveries(r.Context(), appID, reportID, page, limit)
+	if err != nil {
+		if writeSavedReportError(w, err) {
+			return
+		}
+		utils.WriteErrorResponse(w, http.StatusInternalServerError, "レポートの配信履歴の取得に失敗しました")
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, resp)
+}
+
+// writeSavedReportError 保存したレポート操作の既知のエラーをレスポンスに変換する
+// 書き込んだ場合はtrueを返す
+func writeSavedReportError(w http.ResponseWriter, err error) bool {
+	switch {
+	case errors.Is(err, services.ErrAppNotFound),
+		errors.Is(err, services.ErrSavedReportNotFound):
+		utils.WriteErrorResponse(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, services.ErrPermissionDenied):
+		utils.WriteErrorResponse(w, http.StatusForbidden, err.Error())
+	case errors.Is(err, services.ErrInvalidSavedReport),
+		errors.Is(err, services.ErrInvalidChartConfig),
+		errors.Is(err, services.ErrInvalidReportConfig),
+		errors.Is(err, services.ErrInvalidFilter):
+		utils.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+	default:
+		return false
+	}
+	return true
+}
+
+// extractAppAndSavedReportID URLパスからアプリIDとレポートIDを抽出する
+// 想定パス形式: /api/v1/apps/{appId}/reports/saved/{reportId}[/send|/deliveries]
+func extractAppAndSavedReportID(path string) (uint64, uint64, error) {
+	parts := strings.Split(strings.Trim(path, "/"), "/")
+	if len(parts) < 7 {
+		return 0, 0, errors.New("無効なパスです")
+	}
+
+	appID, err := strconv.ParseUint(parts[3], 10, 64)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	reportID, err := strconv.ParseUint(parts[6], 10, 64)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	return appID, reportID, nil
+}
@@ -0,0 +1,163 @@
+package handlers_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"nocode-app/backend/internal/handlers"
+	"nocode-app/backend/internal/models"
+	"nocode-app/backend/internal/services"
+	"nocode-app/backend/internal/testhelpers/mocks"
+	"nocode-app/backend/internal/utils"
+)
+
+const testSavedReportBody = `{
+	"name": "週次レポート",
+	"report_type": "pivot",
+	"config": {"pivot": {"rows": [{"field": "region"}], "measures": [{"aggregation": "count"}]}},
+	"frequency": "weekly",
+	"send_hour": 9,
+	"weekday": 1,
+	"timezone": "Asia/Tokyo",
+	"recipients": ["a@example.com"]
+}`
+
+func TestSavedReportHandler_Create(t *testing.T) {
+	validator := utils.NewValidator()
+	newRequest := func(body string) *http.Request {
+		httpReq := httptest.NewRequest(http.MethodPost, "/api/v1/apps/1/reports/saved", strings.NewReader(body))
+		httpReq.Header.Set("Content-Type", "application/json")
+		return httpReq.WithContext(recordContextWithClaims(context.Background(), 5))
+	}
+
+	t.Run("successful create", func(t *testing.T) {
+		mockService := new(mocks.MockSavedReportService)
+		handler := handlers.NewSavedReportHandler(mockService, validator)
+
+		mockService.On("CreateReport", mock.Anything, uint64(1), uint64(5), mock.MatchedBy(func(req *models.SaveReportRequest) bool {
+			return req.Frequency == models.ReportFrequencyWeekly && req.Config.Pivot != nil && req.Config.Pivot.Rows[0].Field == "region"
+		})).Return(&models.SavedReport{ID: 3, AppID: 1, Name: "週次レポート", ReportType: models.ReportTypePivot}, nil)
+
+		rr := httptest.NewRecorder()
+		handler.Create(rr, newRequest(testSavedReportBody))
+
+		assert.Equal(t, http.StatusCreated, rr.Code)
+		var result models.SavedReport
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &result))
+		assert.Equal(t, uint64(3), result.ID)
+	})
+
+	tests := []struct {
+		name       string
+		body       string
+		err        error
+		wantStatus int
+	}{
+		{name: "invalid recipient", body: strings.Replace(testSavedReportBody, "a@example.com", "not-an-email", 1), wantStatus: http.StatusBadRequest},
+		{name: "invalid frequency", body: strings.Replace(testSavedReportBody, `"weekly"`, `"hourly"`, 1), wantStatus: http.StatusBadRequest},
+		{name: "invalid nested pivot", body: strings.Replace(testSavedReportBody, `"count"`, `"median"`, 1), wantStatus: http.StatusBadRequest},
+		{name: "invalid report config", body: testSavedReportBody, err: services.ErrInvalidReportConfig, wantStatus: http.StatusBadRequest},
+		{name: "invalid saved report", body: testSavedReportBody, err: services.ErrInvalidSavedReport, wantStatus: http.StatusBadRequest},
+		{name: "permission denied", body: testSavedReportBody, err: services.ErrPermissionDenied, wantStatus: http.StatusForbidden},
+		{name: "app not found", body: testSavedReportBody, err: services.ErrAppNotFound, wantStatus: http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(mocks.MockSavedReportService)
+			handler := handlers.NewSavedReportHandler(mockService, validator)
+			if tt.err != nil {
+				mockService.On("CreateReport", mock.Anything, uint64(1), uint64(5), mock.Anything).Return(nil, tt.err)
+			}
+
+			rr := httptest.NewRecorder()
+			handler.Create(rr, newRequest(tt.body))
+
+			assert.Equal(t, tt.wantStatus, rr.Code)
+		})
+	}
+
+	t.Run("unauthenticated", func(t *testing.T) {
+		mockService := new(mocks.MockSavedReportService)
+		handler := handlers.NewSavedReportHandler(mockService, validator)
+
+		httpReq := httptest.NewRequest(http.MethodPost, "/api/v1/apps/1/reports/saved", strings.NewReader(testSavedReportBody))
+		rr := httptest.NewRecorder()
+		handler.Create(rr, httpReq)
+
+		assert.Equal(t, http.StatusUnauthorized, rr.Code)
+	})
+}
+
+func TestSavedReportHandler_Send(t *testing.T) {
+	validator := utils.NewValidator()
+
+	t.Run("returns the delivery", func(t *testing.T) {
+		mockService := new(mocks.MockSavedReportService)
+		handler := handlers.NewSavedReportHandler(mockService, validator)
+
+		mockService.On("SendReport", mock.Anything, uint64(1), uint64(3)).Return(&models.ReportDelivery{
+			ID: 8, ReportID: 3, Status: models.ReportDeliveryFailed, Error: "SMTPサーバーに接続できません",
+		}, nil)
+
+		httpReq := httptest.NewRequest(http.MethodPost, "/api/v1/apps/1/reports/saved/3/send", nil)
+		rr := httptest.NewRecorder()
+		handler.Send(rr, httpReq)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		var result models.ReportDelivery
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &result))
+		assert.Equal(t, models.ReportDeliveryFailed, result.Status)
+	})
+
+	t.Run("report not found", func(t *testing.T) {
+		mockService := new(mocks.MockSavedReportService)
+		handler := handlers.NewSavedReportHandler(mockService, validator)
+
+		mockService.On("SendReport", mock.Anything, uint64(1), uint64(3)).Return(nil, services.ErrSavedReportNotFound)
+
+		httpReq := httptest.NewRequest(http.MethodPost, "/api/v1/apps/1/reports/saved/3/send", nil)
+		rr := httptest.NewRecorder()
+		handler.Send(rr, httpReq)
+
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+	})
+
+	t.Run("invalid report id", func(t *testing.T) {
+		mockService := new(mocks.MockSavedReportService)
+		handler := handlers.NewSavedReportHandler(mockService, validator)
+
+		httpReq := httptest.NewRequest(http.MethodPost, "/api/v1/apps/1/reports/saved/abc/send", nil)
+		rr := httptest.NewRecorder()
+		handler.Send(rr, httpReq)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+}
+
+func TestSavedReportHandler_Deliveries(t *testing.T) {
+	mockService := new(mocks.MockSavedReportService)
+	handler := handlers.NewSavedReportHandler(mockService, utils.NewValidator())
+
+	mockService.On("GetDeliveries", mock.Anything, uint64(1), uint64(3), 2, 20).Return(&models.ReportDeliveryListResponse{
+		Deliveries: []models.ReportDelivery{{ID: 8, ReportID: 3, Status: models.ReportDeliverySucceeded}},
+		Pagination: models.NewPagination(2, 20, 21),
+	}, nil)
+
+	httpReq := httptest.NewRequest(http.MethodGet, "/api/v1/apps/1/reports/saved/3/deliveries?page=2&limit=500", nil)
+	rr := httptest.NewRecorder()
+	handler.Deliveries(rr, httpReq)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	var result models.ReportDeliveryListResponse
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &result))
+	require.Len(t, result.Deliveries, 1)
+	mockService.AssertExpectations(t)
+}
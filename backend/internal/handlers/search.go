@@ -0,0 +1,46 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"nocode-app/backend/internal/services"
+	"nocode-app/backend/internal/utils"
+)
+
+// SearchHandler 全アプリ横断検索エンドポイントを処理する構造体
+type SearchHandler struct {
+	searchService services.GlobalSearchServiceInterface
+}
+
+// NewSearchHandler 新しいSearchHandlerを作成する
+func NewSearchHandler(searchService services.GlobalSearchServiceInterface) *SearchHandler {
+	return &SearchHandler{
+		searchService: searchService,
+	}
+}
+
+// Search GET /api/v1/search を処理し、閲覧できる全アプリを横断してキーワードを検索する
+// クエリパラメータ q に検索キーワード、limit にアプリごとに返すレコード数を指定する
+func (h *SearchHandler) Search(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		utils.WriteErrorResponse(w, http.StatusMethodNotAllowed, "メソッドが許可されていません")
+		return
+	}
+
+	resp, err := h.searchService.Search(
+		r.Context(),
+		utils.GetQueryParam(r, "q", ""),
+		utils.GetQueryParamInt(r, "limit", services.DefaultGlobalSearchLimit),
+	)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidSearch) {
+			utils.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		utils.WriteErrorResponse(w, http.StatusInternalServerError, "検索に失敗しました")
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, resp)
+}
@@ -0,0 +1,85 @@
+package handlers_test
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"nocode-app/backend/internal/handlers"
+	"nocode-app/backend/internal/models"
+	"nocode-app/backend/internal/services"
+	"nocode-app/backend/internal/testhelpers/mocks"
+)
+
+func TestSearchHandler_Search(t *testing.T) {
+	t.Run("successful search", func(t *testing.T) {
+		mockService := new(mocks.MockGlobalSearchService)
+		mockService.On("Search", mock.Anything, "顧客", 10).Return(&models.GlobalSearchResponse{
+			Query: "顧客",
+			Results: []models.GlobalSearchAppResult{{
+				AppID:       1,
+				AppName:     "顧客管理",
+				NameMatched: true,
+				Fields:      []models.GlobalSearchFieldHit{},
+				Records:     []models.GlobalSearchRecordHit{{RecordID: 7, FieldCode: "memo", Highlight: "<mark>顧客</mark>"}},
+				RecordTotal: 1,
+			}},
+		}, nil)
+
+		handler := handlers.NewSearchHandler(mockService)
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/search?q=%E9%A1%A7%E5%AE%A2&limit=10", nil)
+		req = req.WithContext(createContextWithClaims(req.Context(), 2))
+		w := httptest.NewRecorder()
+		handler.Search(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		var resp models.GlobalSearchResponse
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+		require.Len(t, resp.Results, 1)
+		assert.Equal(t, uint64(7), resp.Results[0].Records[0].RecordID)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("invalid search", func(t *testing.T) {
+		mockService := new(mocks.MockGlobalSearchService)
+		mockService.On("Search", mock.Anything, "", services.DefaultGlobalSearchLimit).
+			Return(nil, fmt.Errorf("%w: 検索キーワードを指定してください", services.ErrInvalidSearch))
+
+		handler := handlers.NewSearchHandler(mockService)
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/search", nil)
+		req = req.WithContext(createContextWithClaims(req.Context(), 2))
+		w := httptest.NewRecorder()
+		handler.Search(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("service error", func(t *testing.T) {
+		mockService := new(mocks.MockGlobalSearchService)
+		mockService.On("Search", mock.Anything, "x", services.DefaultGlobalSearchLimit).Return(nil, errors.New("db error"))
+
+		handler := handlers.NewSearchHandler(mockService)
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/search?q=x", nil)
+		req = req.WithContext(createContextWithClaims(req.Context(), 2))
+		w := httptest.NewRecorder()
+		handler.Search(w, req)
+
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+	})
+
+	t.Run("method not allowed", func(t *testing.T) {
+		handler := handlers.NewSearchHandler(new(mocks.MockGlobalSearchService))
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/search?q=x", nil)
+		w := httptest.NewRecorder()
+		handler.Search(w, req)
+
+		assert.Equal(t, http.StatusMethodNotAllowed, w.Code)
+	})
+}
@@ -0,0 +1,272 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"nocode-app/backend/internal/models"
+	"nocode-app/backend/internal/services"
+	"nocode-app/backend/internal/utils"
+)
+
+// TrashHandler ごみ箱エンドポイントを処理する構造体
+type TrashHandler struct {
+	trashService services.TrashServiceInterface
+}
+
+// NewTrashHandler 新しいTrashHandlerを作成する
+func NewTrashHandler(trashService services.TrashServiceInterface) *TrashHandler {
+	return &TrashHandler{trashService: trashService}
+}
+
+// List アプリのごみ箱のレコードとフィールドを一覧表示する
+func (h *TrashHandler) List(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		utils.WriteErrorResponse(w, http.StatusMethodNotAllowed, "メソッドが許可されていません")
+		return
+	}
+
+	appID, err := extractAppID(r.URL.Path)
+	if err != nil {
+		utils.WriteErrorResponse(w, http.StatusBadRequest, "無効なアプリIDです")
+		return
+	}
+
+	// ページネーションパラメータをパース
+	page := utils.GetQueryParamInt(r, "page", 1)
+	if page < 1 {
+		page = 1
+	}
+	limit := utils.GetQueryParamInt(r, "limit", 20)
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+
+	resp, err := h.trashService.GetAppTrash(r.Context(), appID, page, limit)
+	if err != nil {
+		if writeTrashError(w, err) {
+			return
+		}
+		utils.WriteErrorResponse(w, http.StatusInternalServerError, "ごみ箱の取得に失敗しました")
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, resp)
+}
+
+// RestoreRecord ごみ箱のレコードを復元する
+func (h *TrashHandler) RestoreRecord(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		utils.WriteErrorResponse(w, http.StatusMethodNotAllowed, "メソッドが許可されていません")
+		return
+	}
+
+	appID, recordID, err := extractAppAndTrashItemID(r.URL.Path)
+	if err != nil {
+		utils.WriteErrorResponse(w, http.StatusBadRequest, "無効なレコードIDです")
+		return
+	}
+
+	record, err := h.trashService.RestoreRecord(r.Context(), appID, recordID)
+	if err != nil {
+		if writeTrashError(w, err) {
+			return
+		}
+		utils.WriteErrorResponse(w, http.StatusInternalServerError, "レコードの復元に失敗しました")
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, record)
+}
+
+// PurgeRecord ごみ箱のレコードを完全に削除する
+func (h *TrashHandler) PurgeRecord(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		utils.WriteErrorResponse(w, http.StatusMethodNotAllowed, "メソッドが許可されていません")
+		return
+	}
+
+	appID, recordID, err := extractAppAndTrashItemID(r.URL.Path)
+	if err != nil {
+		utils.WriteErrorResponse(w, http.StatusBadRequest, "無効なレコードIDです")
+		return
+	}
+
+	if err := h.trashService.PurgeRecord(r.Context(), appID, recordID); err != nil {
+		if writeTrashError(w, err) {
+			return
+		}
+		utils.WriteErrorResponse(w, http.StatusInternalServerError, "レコードの完全な削除に失敗しました")
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, models.SuccessResponse{Message: "レコードを完全に削除しました"})
+}
+
+// RestoreField ごみ箱のフィールドを復元する
+func (h *TrashHandler) RestoreField(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		utils.WriteErrorResponse(w, http.StatusMethodNotAllowed, "メソッドが許可されていません")
+		return
+	}
+
+	appID, fieldID, err := extractAppAndTrashItemID(r.URL.Path)
+	if err != nil {
+		utils.WriteErrorResponse(w, http.StatusBadRequest, "無効なフィールドIDです")
+		return
+	}
+
+	field, err := h.trashService.RestoreField(r.Context(), appID, fieldID)
+	if err != nil {
+		if writeTrashError(w, err) {
+			return
+		}
+		utils.WriteErrorResponse(w, http.StatusInternalServerError, "フィールドの復元に失敗しました")
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, field)
+}
+
+// PurgeField ごみ箱のフィールドを動的テーブルのカラムとともに完全に削除する
+func (h *TrashHandler) PurgeField(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		utils.WriteErrorResponse(w, http.StatusMethodNotAllowed, "メソッドが許可されていません")
+		return
+	}
+
+	appID, fieldID, err := extractAppAndTrashItemID(r.URL.Path)
+	if err != nil {
+		utils.WriteErrorResponse(w, http.StatusBadRequest, "無効なフィールドIDです")
+		return
+	}
+
+	if err := h.trashService.PurgeField(r.Context(), appID, fieldID); err != nil {
+		if writeTrashError(w, err) {
+			return
+		}
+		utils.WriteErrorResponse(w, http.StatusInternalServerError, "フィールドの完全な削除に失敗しました")
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, models.SuccessResponse{Message: "フィールドを完全に削除しました"})
+}
+
+// ListApps ごみ箱のアプリを一覧表示する
+func (h *TrashHandler) ListApps(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		utils.WriteErrorResponse(w, http.StatusMethodNotAllowed, "メソッドが許可されていません")
+		return
+	}
+
+	resp, err := h.trashService.GetTrashedApps(r.Context())
+	if err != nil {
+		utils.WriteErrorResponse(w, http.StatusInternalServerError, "ごみ箱のアプリの取得に失敗しました")
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, resp)
+}
+
+// RestoreApp ごみ箱のアプリを復元する
+func (h *TrashHandler) RestoreApp(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		utils.WriteErrorResponse(w, http.StatusMethodNotAllowed, "メソッドが許可されていません")
+		return
+	}
+
+	appID, err := extractTrashedAppID(r.URL.Path)
+	if err != nil {
+		utils.WriteErrorResponse(w, http.StatusBadRequest, "無効なアプリIDです")
+		return
+	}
+
+	app, err := h.trashService.RestoreApp(r.Context(), appID)
+	if err != nil {
+		if writeTrashError(w, err) {
+			return
+		}
+		utils.WriteErrorResponse(w, http.StatusInternalServerError, "アプリの復元に失敗しました")
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, app)
+}
+
+// PurgeApp ごみ箱のアプリを動的テーブルとともに完全に削除する
+func (h *TrashHandler) PurgeApp(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		utils.WriteErrorResponse(w, http.StatusMethodNotAllowed, "メソッドが許可されていません")
+		return
+	}
+
+	appID, err := extractTrashedAppID(r.URL.Path)
+	if err != nil {
+		utils.WriteErrorResponse(w, http.StatusBadRequest, "無効なアプリIDです")
+		return
+	}
+
+	if err := h.trashService.PurgeApp(r.Context(), appID); err != nil {
+		if writeTrashError(w, err) {
+			return
+		}
+		utils.WriteErrorResponse(w, http.StatusInternalServerError, "アプリの完全な削除に失敗しました")
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, models.SuccessResponse{Message: "アプリを完全に削除しました"})
+}
+
+// writeTrashError ごみ箱の操作のエラーをステータスコードに変換して書き込む（該当しない場合はfalse）
+func writeTrashError(w http.ResponseWriter, err error) bool {
+	switch {
+	case errors.Is(err, services.ErrAppNotFound),
+		errors.Is(err, services.ErrRecordNotFound),
+		errors.Is(err, services.ErrFieldNotFound):
+		utils.WriteErrorResponse(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, services.ErrPermissionDenied),
+		errors.Is(err, services.ErrExternalAppReadOnly):
+		utils.WriteErrorResponse(w, http.StatusForbidden, err.Error())
+	case errors.Is(err, services.ErrReferencedRecordMissing),
+		errors.Is(err, services.ErrInvalidFieldOptions),
+		errors.Is(err, services.ErrDuplicateValue):
+		// 削除後のアプリやフィールドの変更により、現在の状態には復元できない
+		utils.WriteErrorResponse(w, http.StatusConflict, err.Error())
+	default:
+		return false
+	}
+	return true
+}
+
+// extractAppAndTrashItemID URLパスからアプリIDとごみ箱のレコードIDまたはフィールドIDを抽出する
+// 想定パス形式: /api/v1/apps/{appId}/trash/{records|fields}/{id}[/restore]
+func extractAppAndTrashItemID(path string) (uint64, uint64, error) {
+	parts := strings.Split(strings.Trim(path, "/"), "/")
+	if len(parts) < 7 {
+		return 0, 0, errors.New("無効なパスです")
+	}
+
+	appID, err := strconv.ParseUint(parts[3], 10, 64)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	itemID, err := strconv.ParseUint(parts[6], 10, 64)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	return appID, itemID, nil
+}
+
+// extractTrashedAppID URLパスからごみ箱のアプリIDを抽出する
+// 想定パス形式: /api/v1/apps/trash/{appId}[/restore]
+func extractTrashedAppID(path string) (uint64, error) {
+	parts := strings.Split(strings.Trim(path, "/"), "/")
+	if len(parts) < 5 {
+		return 0, errors.New("無効なパスです")
+	}
+	return strconv.ParseUint(parts[4], 10, 64)
+}
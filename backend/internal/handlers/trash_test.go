@@ -0,0 +1,180 @@
+package handlers_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"nocode-app/backend/internal/handlers"
+	"nocode-app/backend/internal/models"
+	"nocode-app/backend/internal/services"
+	"nocode-app/backend/internal/testhelpers/mocks"
+)
+
+func TestTrashHandler_List(t *testing.T) {
+	t.Run("successful list trash", func(t *testing.T) {
+		mockService := new(mocks.MockTrashService)
+		handler := handlers.NewTrashHandler(mockService)
+
+		mockService.On("GetAppTrash", mock.Anything, uint64(1), 2, 10).Return(&models.AppTrashResponse{
+			Fields:     []models.TrashedFieldResponse{},
+			Records:    []models.TrashedRecordResponse{{ID: 7, Data: models.RecordData{"name": "山田"}}},
+			Pagination: models.NewPagination(2, 10, 11),
+		}, nil)
+
+		httpReq := httptest.NewRequest(http.MethodGet, "/api/v1/apps/1/trash?page=2&limit=10", nil)
+		rr := httptest.NewRecorder()
+
+		handler.List(rr, httpReq)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+
+		var result models.AppTrashResponse
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &result))
+		require.Len(t, result.Records, 1)
+		assert.Equal(t, uint64(7), result.Records[0].ID)
+
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("viewer is denied", func(t *testing.T) {
+		mockService := new(mocks.MockTrashService)
+		handler := handlers.NewTrashHandler(mockService)
+
+		mockService.On("GetAppTrash", mock.Anything, uint64(1), 1, 20).Return(nil, services.ErrPermissionDenied)
+
+		httpReq := httptest.NewRequest(http.MethodGet, "/api/v1/apps/1/trash", nil)
+		rr := httptest.NewRecorder()
+
+		handler.List(rr, httpReq)
+
+		assert.Equal(t, http.StatusForbidden, rr.Code)
+	})
+}
+
+func TestTrashHandler_RestoreRecord(t *testing.T) {
+	t.Run("successful restore", func(t *testing.T) {
+		mockService := new(mocks.MockTrashService)
+		handler := handlers.NewTrashHandler(mockService)
+
+		mockService.On("RestoreRecord", mock.Anything, uint64(1), uint64(7)).Return(&models.RecordResponse{ID: 7}, nil)
+
+		httpReq := httptest.NewRequest(http.MethodPost, "/api/v1/apps/1/trash/records/7/restore", nil)
+		rr := httptest.NewRecorder()
+
+		handler.RestoreRecord(rr, httpReq)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("referenced record is missing", func(t *testing.T) {
+		mockService := new(mocks.MockTrashService)
+		handler := handlers.NewTrashHandler(mockService)
+
+		mockService.On("RestoreRecord", mock.Anything, uint64(1), uint64(7)).Return(nil, services.ErrReferencedRecordMissing)
+
+		httpReq := httptest.NewRequest(http.MethodPost, "/api/v1/apps/1/trash/records/7/restore", nil)
+		rr := httptest.NewRecorder()
+
+		handler.RestoreRecord(rr, httpReq)
+
+		assert.Equal(t, http.StatusConflict, rr.Code)
+	})
+
+	t.Run("invalid record id", func(t *testing.T) {
+		handler := handlers.NewTrashHandler(new(mocks.MockTrashService))
+
+		httpReq := httptest.NewRequest(http.MethodPost, "/api/v1/apps/1/trash/records/abc/restore", nil)
+		rr := httptest.NewRecorder()
+
+		handler.RestoreRecord(rr, httpReq)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+}
+
+func TestTrashHandler_PurgeField(t *testing.T) {
+	t.Run("successful purge", func(t *testing.T) {
+		mockService := new(mocks.MockTrashService)
+		handler := handlers.NewTrashHandler(mockService)
+
+		mockService.On("PurgeField", mock.Anything, uint64(1), uint64(5)).Return(nil)
+
+		httpReq := httptest.NewRequest(http.MethodDelete, "/api/v1/apps/1/trash/fields/5", nil)
+		rr := httptest.NewRecorder()
+
+		handler.PurgeField(rr, httpReq)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("field not in trash", func(t *testing.T) {
+		mockService := new(mocks.MockTrashService)
+		handler := handlers.NewTrashHandler(mockService)
+
+		mockService.On("PurgeField", mock.Anything, uint64(1), uint64(5)).Return(services.ErrFieldNotFound)
+
+		httpReq := httptest.NewRequest(http.MethodDelete, "/api/v1/apps/1/trash/fields/5", nil)
+		rr := httptest.NewRecorder()
+
+		handler.PurgeField(rr, httpReq)
+
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+	})
+}
+
+func TestTrashHandler_RestoreApp(t *testing.T) {
+	t.Run("successful restore", func(t *testing.T) {
+		mockService := new(mocks.MockTrashService)
+		handler := handlers.NewTrashHandler(mockService)
+
+		mockService.On("RestoreApp", mock.Anything, uint64(3)).Return(&models.AppResponse{ID: 3, Name: "顧客"}, nil)
+
+		httpReq := httptest.NewRequest(http.MethodPost, "/api/v1/apps/trash/3/restore", nil)
+		rr := httptest.NewRecorder()
+
+		handler.RestoreApp(rr, httpReq)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+
+		var result models.AppResponse
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &result))
+		assert.Equal(t, "顧客", result.Name)
+	})
+
+	t.Run("app not in trash", func(t *testing.T) {
+		mockService := new(mocks.MockTrashService)
+		handler := handlers.NewTrashHandler(mockService)
+
+		mockService.On("RestoreApp", mock.Anything, uint64(3)).Return(nil, services.ErrAppNotFound)
+
+		httpReq := httptest.NewRequest(http.MethodPost, "/api/v1/apps/trash/3/restore", nil)
+		rr := httptest.NewRecorder()
+
+		handler.RestoreApp(rr, httpReq)
+
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+	})
+}
+
+func TestTrashHandler_PurgeApp(t *testing.T) {
+	mockService := new(mocks.MockTrashService)
+	handler := handlers.NewTrashHandler(mockService)
+
+	mockService.On("PurgeApp", mock.Anything, uint64(3)).Return(nil)
+
+	httpReq := httptest.NewRequest(http.MethodDelete, "/api/v1/apps/trash/3", nil)
+	rr := httptest.NewRecorder()
+
+	handler.PurgeApp(rr, httpReq)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	mockService.AssertExpectations(t)
+}
@@ -0,0 +1,90 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"nocode-app/backend/internal/models"
+	"nocode-app/backend/internal/services"
+	"nocode-app/backend/internal/utils"
+)
+
+// バージョン指定のエラー
+var (
+	errVersionRequired = errors.New("If-Matchヘッダーまたはversionで、取得したときのバージョンを指定してください")
+	errInvalidVersion  = errors.New("無効なバージョンです")
+)
+
+// setETag リソースのバージョンをETagヘッダーに設定する
+func setETag(w http.ResponseWriter, version int64) {
+	w.Header().Set("ETag", `"`+strconv.FormatInt(version, 10)+`"`)
+}
+
+// requestVersion 更新・削除するリソースを取得したときのバージョンを求める
+// If-Matchヘッダー、リクエストボディの version、クエリパラメータの version の順に参照する。
+// If-Match: * の場合は現在の内容に関わらず更新・削除するため0を返す
+func requestVersion(r *http.Request, bodyVersion *int64) (int64, error) {
+	if ifMatch := strings.TrimSpace(r.Header.Get("If-Match")); ifMatch != "" {
+		if ifMatch == "*" {
+			return 0, nil
+		}
+		// 強いETagを1つだけ受け付ける
+		if len(ifMatch) < 3 || ifMatch[0] != '"' || ifMatch[len(ifMatch)-1] != '"' {
+			return 0, errInvalidVersion
+		}
+		return parseVersion(ifMatch[1 : len(ifMatch)-1])
+	}
+	if bodyVersion != nil {
+		if *bodyVersion <= 0 {
+			return 0, errInvalidVersion
+		}
+		return *bodyVersion, nil
+	}
+	if v := r.URL.Query().Get("version"); v != "" {
+		return parseVersion(v)
+	}
+	return 0, errVersionRequired
+}
+
+// parseVersion 文字列のバージョンを解析する
+func parseVersion(s string) (int64, error) {
+	version, err := strconv.ParseInt(s, 10, 64)
+	if err != nil || version <= 0 {
+		return 0, errInvalidVersion
+	}
+	return version, nil
+}
+
+// writeVersionError バージョンの指定の誤りと、他の更新との競合のエラーレスポンスを書き込む
+// 競合した場合は、現在のバージョンをETagヘッダーに、現在の内容をレスポンスに含める。
+// If-Matchヘッダーで指定した場合は 412、リクエストボディ・クエリパラメータで指定した場合は 409 を返す
+func writeVersionError(w http.ResponseWriter, r *http.Request, err error) bool {
+	switch {
+	case errors.Is(err, errVersionRequired):
+		utils.WriteErrorResponse(w, http.StatusPreconditionRequired, err.Error())
+		return true
+	case errors.Is(err, errInvalidVersion):
+		utils.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+		return true
+	}
+
+	var conflict *services.VersionConflictError
+	if !errors.As(err, &conflict) {
+		return false
+	}
+	status := http.StatusConflict
+	if r.Header.Get("If-Match") != "" {
+		status = http.StatusPreconditionFailed
+	}
+	setETag(w, conflict.Version)
+	utils.WriteJSON(w, status, models.VersionConflictResponse{
+		Error:   http.StatusText(status),
+		Message: conflict.Error(),
+		Code:    status,
+		Version: conflict.Version,
+		Current: conflict.Current,
+	})
+	return true
+}
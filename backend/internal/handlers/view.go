@@ -40,6 +40,14 @@ func (h *ViewHandler) List(w http.ResponseWriter, r *http.Request) {
 
 	views, err := h.viewService.GetViews(r.Context(), appID)
 	if err != nil {
+		if errors.Is(err, services.ErrAppNotFound) {
+			utils.WriteErrorResponse(w, http.StatusNotFound, err.Error())
+			return
+		}
+		if errors.Is(err, services.ErrPermissionDenied) {
+			utils.WriteErrorResponse(w, http.StatusForbidden, err.Error())
+			return
+		}
 		utils.WriteErrorResponse(w, http.StatusInternalServerError, "failed to get views")
 		return
 	}
@@ -72,6 +80,10 @@ func (h *ViewHandler) Create(w http.ResponseWriter, r *http.Request) {
 			utils.WriteErrorResponse(w, http.StatusNotFound, err.Error())
 			return
 		}
+		if errors.Is(err, services.ErrPermissionDenied) {
+			utils.WriteErrorResponse(w, http.StatusForbidden, err.Error())
+			return
+		}
 		utils.WriteErrorResponse(w, http.StatusInternalServerError, "failed to create view")
 		return
 	}
@@ -98,16 +110,34 @@ func (h *ViewHandler) Update(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	view, err := h.viewService.UpdateView(r.Context(), viewID, &req)
+	version, err := requestVersion(r, req.Version)
 	if err != nil {
+		writeVersionError(w, r, err)
+		return
+	}
+
+	view, err := h.viewService.UpdateView(r.Context(), viewID, version, &req)
+	if err != nil {
+		if writeVersionError(w, r, err) {
+			return
+		}
 		if errors.Is(err, services.ErrViewNotFound) {
 			utils.WriteErrorResponse(w, http.StatusNotFound, err.Error())
 			return
 		}
+		if errors.Is(err, services.ErrAppNotFound) {
+			utils.WriteErrorResponse(w, http.StatusNotFound, err.Error())
+			return
+		}
+		if errors.Is(err, services.ErrPermissionDenied) {
+			utils.WriteErrorResponse(w, http.StatusForbidden, err.Error())
+			return
+		}
 		utils.WriteErrorResponse(w, http.StatusInternalServerError, "failed to update view")
 		return
 	}
 
+	setETag(w, view.Version)
 	utils.WriteJSON(w, http.StatusOK, view)
 }
 
@@ -124,11 +154,28 @@ func (h *ViewHandler) Delete(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := h.viewService.DeleteView(r.Context(), viewID); err != nil {
+	version, err := requestVersion(r, nil)
+	if err != nil {
+		writeVersionError(w, r, err)
+		return
+	}
+
+	if err := h.viewService.DeleteView(r.Context(), viewID, version); err != nil {
+		if writeVersionError(w, r, err) {
+			return
+		}
 		if errors.Is(err, services.ErrViewNotFound) {
 			utils.WriteErrorResponse(w, http.StatusNotFound, err.Error())
 			return
 		}
+		if errors.Is(err, services.ErrAppNotFound) {
+			utils.WriteErrorResponse(w, http.StatusNotFound, err.Error())
+			return
+		}
+		if errors.Is(err, services.ErrPermissionDenied) {
+			utils.WriteErrorResponse(w, http.StatusForbidden, err.Error())
+			return
+		}
 		utils.WriteErrorResponse(w, http.StatusInternalServerError, "failed to delete view")
 		return
 	}
@@ -156,10 +156,11 @@ func TestViewHandler_Update(t *testing.T) {
 			ViewType: "table",
 		}
 
-		mockService.On("UpdateView", mock.Anything, uint64(1), mock.AnythingOfType("*models.UpdateViewRequest")).Return(resp, nil)
+		mockService.On("UpdateView", mock.Anything, uint64(1), int64(1), mock.AnythingOfType("*models.UpdateViewRequest")).Return(resp, nil)
 
 		body, _ := json.Marshal(req)
 		httpReq := httptest.NewRequest(http.MethodPut, "/api/v1/apps/1/views/1", bytes.NewReader(body))
+		httpReq.Header.Set("If-Match", `"1"`)
 		httpReq.Header.Set("Content-Type", "application/json")
 		rr := httptest.NewRecorder()
 
@@ -180,10 +181,11 @@ func TestViewHandler_Update(t *testing.T) {
 		handler := handlers.NewViewHandler(mockService, validator)
 
 		req := models.UpdateViewRequest{Name: "Updated"}
-		mockService.On("UpdateView", mock.Anything, uint64(999), mock.AnythingOfType("*models.UpdateViewRequest")).Return(nil, services.ErrViewNotFound)
+		mockService.On("UpdateView", mock.Anything, uint64(999), int64(1), mock.AnythingOfType("*models.UpdateViewRequest")).Return(nil, services.ErrViewNotFound)
 
 		body, _ := json.Marshal(req)
 		httpReq := httptest.NewRequest(http.MethodPut, "/api/v1/apps/1/views/999", bytes.NewReader(body))
+		httpReq.Header.Set("If-Match", `"1"`)
 		httpReq.Header.Set("Content-Type", "application/json")
 		rr := httptest.NewRecorder()
 
@@ -214,9 +216,10 @@ func TestViewHandler_Delete(t *testing.T) {
 		mockService := new(mocks.MockViewService)
 		handler := handlers.NewViewHandler(mockService, validator)
 
-		mockService.On("DeleteView", mock.Anything, uint64(1)).Return(nil)
+		mockService.On("DeleteView", mock.Anything, uint64(1), int64(1)).Return(nil)
 
 		httpReq := httptest.NewRequest(http.MethodDelete, "/api/v1/apps/1/views/1", nil)
+		httpReq.Header.Set("If-Match", `"1"`)
 		rr := httptest.NewRecorder()
 
 		handler.Delete(rr, httpReq)
@@ -235,9 +238,10 @@ func TestViewHandler_Delete(t *testing.T) {
 		mockService := new(mocks.MockViewService)
 		handler := handlers.NewViewHandler(mockService, validator)
 
-		mockService.On("DeleteView", mock.Anything, uint64(999)).Return(services.ErrViewNotFound)
+		mockService.On("DeleteView", mock.Anything, uint64(999), int64(1)).Return(services.ErrViewNotFound)
 
 		httpReq := httptest.NewRequest(http.MethodDelete, "/api/v1/apps/1/views/999", nil)
+		httpReq.Header.Set("If-Match", `"1"`)
 		rr := httptest.NewRecorder()
 
 		handler.Delete(rr, httpReq)
@@ -0,0 +1,215 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"nocode-app/backend/internal/middleware"
+	"nocode-app/backend/internal/models"
+	"nocode-app/backend/internal/services"
+	"nocode-app/backend/internal/utils"
+)
+
+// WebhookHandler Webhookエンドポイントを処理する構造体
+type WebhookHandler struct {
+	webhookService services.WebhookServiceInterface
+	validator      *utils.Validator
+}
+
+// NewWebhookHandler 新しいWebhookHandlerを作成する
+func NewWebhookHandler(webhookService services.WebhookServiceInterface, validator *utils.Validator) *WebhookHandler {
+	return &WebhookHandler{
+		webhookService: webhookService,
+		validator:      validator,
+	}
+}
+
+// List アプリに登録されたWebhookを一覧表示する
+func (h *WebhookHandler) List(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		utils.WriteErrorResponse(w, http.StatusMethodNotAllowed, "メソッドが許可されていません")
+		return
+	}
+
+	appID, err := extractAppID(r.URL.Path)
+	if err != nil {
+		utils.WriteErrorResponse(w, http.StatusBadRequest, "無効なアプリIDです")
+		return
+	}
+
+	resp, err := h.webhookService.GetWebhooks(r.Context(), appID)
+	if err != nil {
+		if writeWebhookError(w, err) {
+			return
+		}
+		utils.WriteErrorResponse(w, http.StatusInternalServerError, "Webhookの取得に失敗しました")
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, resp)
+}
+
+// Create アプリにWebhookを登録する
+func (h *WebhookHandler) Create(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		utils.WriteErrorResponse(w, http.StatusMethodNotAllowed, "メソッドが許可されていません")
+		return
+	}
+
+	claims, ok := middleware.GetUserFromContext(r.Context())
+	if !ok {
+		utils.WriteErrorResponse(w, http.StatusUnauthorized, "認証されていません")
+		return
+	}
+
+	appID, err := extractAppID(r.URL.Path)
+	if err != nil {
+		utils.WriteErrorResponse(w, http.StatusBadRequest, "無効なアプリIDです")
+		return
+	}
+
+	var req models.CreateWebhookRequest
+	if err := h.validator.ParseAndValidate(r, &req); err != nil {
+		utils.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	resp, err := h.webhookService.CreateWebhook(r.Context(), appID, claims.UserID, &req)
+	if err != nil {
+		if writeWebhookError(w, err) {
+			return
+		}
+		utils.WriteErrorResponse(w, http.StatusInternalServerError, "Webhookの登録に失敗しました")
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusCreated, resp)
+}
+
+// Update Webhookを更新する
+func (h *WebhookHandler) Update(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPut {
+		utils.WriteErrorResponse(w, http.StatusMethodNotAllowed, "メソッドが許可されていません")
+		return
+	}
+
+	appID, webhookID, err := extractAppAndWebhookID(r.URL.Path)
+	if err != nil {
+		utils.WriteErrorResponse(w, http.StatusBadRequest, "無効なWebhook IDです")
+		return
+	}
+
+	var req models.UpdateWebhookRequest
+	if err := h.validator.ParseAndValidate(r, &req); err != nil {
+		utils.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	resp, err := h.webhookService.UpdateWebhook(r.Context(), appID, webhookID, &req)
+	if err != nil {
+		if writeWebhookError(w, err) {
+			return
+		}
+		utils.WriteErrorResponse(w, http.StatusInternalServerError, "Webhookの更新に失敗しました")
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, resp)
+}
+
+// Delete Webhookを削除する
+func (h *WebhookHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		utils.WriteErrorResponse(w, http.StatusMethodNotAllowed, "メソッドが許可されていません")
+		return
+	}
+
+	appID, webhookID, err := extractAppAndWebhookID(r.URL.Path)
+	if err != nil {
+		utils.WriteErrorResponse(w, http.StatusBadRequest, "無効なWebhook IDです")
+		return
+	}
+
+	if err := h.webhookService.DeleteWebhook(r.Context(), appID, webhookID); err != nil {
+		if writeWebhookError(w, err) {
+			return
+		}
+		utils.WriteErrorResponse(w, http.StatusInternalServerError, "Webhookの削除に失敗しました")
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, models.SuccessResponse{Message: "Webhookを削除しました"})
+}
+
+// Deliveries Webhookの配信ログを新しい順に一覧表示する
+func (h *WebhookHandler) Deliveries(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		utils.WriteErrorResponse(w, http.StatusMethodNotAllowed, "メソッドが許可されていません")
+		return
+	}
+
+	appID, webhookID, err := extractAppAndWebhookID(r.URL.Path)
+	if err != nil {
+		utils.WriteErrorResponse(w, http.StatusBadRequest, "無効なWebhook IDです")
+		return
+	}
+
+	page := utils.GetQueryParamInt(r, "page", 1)
+	if page < 1 {
+		page = 1
+	}
+	limit := utils.GetQueryParamInt(r, "limit", 20)
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+
+	resp, err := h.webhookService.GetDeliveries(r.Context(), appID, webhookID, page, limit)
+	if err != nil {
+		if writeWebhookError(w, err) {
+			return
+		}
+		utils.WriteErrorResponse(w, http.StatusInternalServerError, "Webhook配信ログの取得に失敗しました")
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, resp)
+}
+
+// writeWebhookError Webhook操作の既知のエラーをレスポンスに変換する
+// 書き込んだ場合はtrueを返す
+func writeWebhookError(w http.ResponseWriter, err error) bool {
+	switch {
+	case errors.Is(err, services.ErrAppNotFound),
+		errors.Is(err, services.ErrWebhookNotFound):
+		utils.WriteErrorResponse(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, services.ErrInvalidWebhookURL),
+		errors.Is(err, services.ErrWebhookPrivateAddress):
+		utils.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+	default:
+		return false
+	}
+	return true
+}
+
+// extractAppAndWebhookID URLパスからアプリIDとWebhook IDを抽出する
+// 想定パス形式: /api/v1/apps/{appId}/webhooks/{webhookId}[/deliveries]
+func extractAppAndWebhookID(path string) (uint64, uint64, error) {
+	parts := strings.Split(strings.Trim(path, "/"), "/")
+	if len(parts) < 6 {
+		return 0, 0, errors.New("無効なパスです")
+	}
+
+	appID, err := strconv.ParseUint(parts[3], 10, 64)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	webhookID, err := strconv.ParseUint(parts[5], 10, 64)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	return appID, webhookID, nil
+}
@@ -0,0 +1,225 @@
+package handlers_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"nocode-app/backend/internal/handlers"
+	"nocode-app/backend/internal/models"
+	"nocode-app/backend/internal/services"
+	"nocode-app/backend/internal/testhelpers/mocks"
+	"nocode-app/backend/internal/utils"
+)
+
+func TestWebhookHandler_List(t *testing.T) {
+	validator := utils.NewValidator()
+
+	t.Run("successful list", func(t *testing.T) {
+		mockService := new(mocks.MockWebhookService)
+		handler := handlers.NewWebhookHandler(mockService, validator)
+
+		resp := &models.WebhookListResponse{
+			Webhooks: []models.Webhook{
+				{ID: 1, AppID: 1, URL: "https://example.com/hook", Secret: "secret", Events: []models.WebhookEventType{models.WebhookEventRecordCreated}, IsActive: true},
+			},
+		}
+		mockService.On("GetWebhooks", mock.Anything, uint64(1)).Return(resp, nil)
+
+		httpReq := httptest.NewRequest(http.MethodGet, "/api/v1/apps/1/webhooks", nil)
+		rr := httptest.NewRecorder()
+
+		handler.List(rr, httpReq)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		// シークレットは一覧に含めない
+		assert.NotContains(t, rr.Body.String(), "secret")
+
+		var result models.WebhookListResponse
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &result))
+		require.Len(t, result.Webhooks, 1)
+		assert.Equal(t, "https://example.com/hook", result.Webhooks[0].URL)
+	})
+
+	t.Run("app not found", func(t *testing.T) {
+		mockService := new(mocks.MockWebhookService)
+		handler := handlers.NewWebhookHandler(mockService, validator)
+
+		mockService.On("GetWebhooks", mock.Anything, uint64(1)).Return(nil, services.ErrAppNotFound)
+
+		httpReq := httptest.NewRequest(http.MethodGet, "/api/v1/apps/1/webhooks", nil)
+		rr := httptest.NewRecorder()
+
+		handler.List(rr, httpReq)
+
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+	})
+}
+
+func TestWebhookHandler_Create(t *testing.T) {
+	validator := utils.NewValidator()
+
+	newRequest := func(body interface{}) *http.Request {
+		b, _ := json.Marshal(body)
+		httpReq := httptest.NewRequest(http.MethodPost, "/api/v1/apps/1/webhooks", bytes.NewReader(b))
+		httpReq.Header.Set("Content-Type", "application/json")
+		return httpReq.WithContext(recordContextWithClaims(context.Background(), 5))
+	}
+
+	t.Run("successful create", func(t *testing.T) {
+		mockService := new(mocks.MockWebhookService)
+		handler := handlers.NewWebhookHandler(mockService, validator)
+
+		mockService.On("CreateWebhook", mock.Anything, uint64(1), uint64(5), mock.AnythingOfType("*models.CreateWebhookRequest")).
+			Return(&models.WebhookResponse{Webhook: models.Webhook{ID: 1, AppID: 1, URL: "https://example.com/hook"}, Secret: "s3cr3t"}, nil)
+
+		rr := httptest.NewRecorder()
+		handler.Create(rr, newRequest(map[string]interface{}{
+			"url": "https://example.com/hook", "events": []string{"record.created", "record.deleted"},
+		}))
+
+		assert.Equal(t, http.StatusCreated, rr.Code)
+
+		var result models.WebhookResponse
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &result))
+		assert.Equal(t, "s3cr3t", result.Secret)
+	})
+
+	invalid := map[string]map[string]interface{}{
+		"missing url":   {"events": []string{"record.created"}},
+		"missing event": {"url": "https://example.com/hook", "events": []string{}},
+		"unknown event": {"url": "https://example.com/hook", "events": []string{"record.viewed"}},
+	}
+	for name, body := range invalid {
+		t.Run(name, func(t *testing.T) {
+			mockService := new(mocks.MockWebhookService)
+			handler := handlers.NewWebhookHandler(mockService, validator)
+
+			rr := httptest.NewRecorder()
+			handler.Create(rr, newRequest(body))
+
+			assert.Equal(t, http.StatusBadRequest, rr.Code)
+			mockService.AssertNotCalled(t, "CreateWebhook", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+		})
+	}
+
+	t.Run("invalid scheme", func(t *testing.T) {
+		mockService := new(mocks.MockWebhookService)
+		handler := handlers.NewWebhookHandler(mockService, validator)
+
+		mockService.On("CreateWebhook", mock.Anything, uint64(1), uint64(5), mock.AnythingOfType("*models.CreateWebhookRequest")).
+			Return(nil, services.ErrInvalidWebhookURL)
+
+		rr := httptest.NewRecorder()
+		handler.Create(rr, newRequest(map[string]interface{}{
+			"url": "ftp://example.com/hook", "events": []string{"record.created"},
+		}))
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+
+	t.Run("private address", func(t *testing.T) {
+		mockService := new(mocks.MockWebhookService)
+		handler := handlers.NewWebhookHandler(mockService, validator)
+
+		mockService.On("CreateWebhook", mock.Anything, uint64(1), uint64(5), mock.AnythingOfType("*models.CreateWebhookRequest")).
+			Return(nil, services.ErrWebhookPrivateAddress)
+
+		rr := httptest.NewRecorder()
+		handler.Create(rr, newRequest(map[string]interface{}{
+			"url": "http://169.254.169.254/latest/meta-data", "events": []string{"record.created"},
+		}))
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+}
+
+func TestWebhookHandler_Update(t *testing.T) {
+	validator := utils.NewValidator()
+	mockService := new(mocks.MockWebhookService)
+	handler := handlers.NewWebhookHandler(mockService, validator)
+
+	mockService.On("UpdateWebhook", mock.Anything, uint64(1), uint64(9), mock.AnythingOfType("*models.UpdateWebhookRequest")).
+		Return(nil, services.ErrWebhookNotFound)
+
+	body, _ := json.Marshal(map[string]interface{}{"is_active": false})
+	httpReq := httptest.NewRequest(http.MethodPut, "/api/v1/apps/1/webhooks/9", bytes.NewReader(body))
+	httpReq.Header.Set("Content-Type", "application/json")
+	rr := httptest.NewRecorder()
+
+	handler.Update(rr, httpReq)
+
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+}
+
+func TestWebhookHandler_Delete(t *testing.T) {
+	validator := utils.NewValidator()
+	mockService := new(mocks.MockWebhookService)
+	handler := handlers.NewWebhookHandler(mockService, validator)
+
+	mockService.On("DeleteWebhook", mock.Anything, uint64(1), uint64(9)).Return(nil)
+
+	httpReq := httptest.NewRequest(http.MethodDelete, "/api/v1/apps/1/webhooks/9", nil)
+	rr := httptest.NewRecorder()
+
+	handler.Delete(rr, httpReq)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestWebhookHandler_Deliveries(t *testing.T) {
+	validator := utils.NewValidator()
+
+	t.Run("returns payload as json", func(t *testing.T) {
+		mockService := new(mocks.MockWebhookService)
+		handler := handlers.NewWebhookHandler(mockService, validator)
+
+		delivery := models.WebhookDelivery{ID: 3, Event: models.WebhookEventRecordCreated, Payload: `{"event":"record.created"}`, Signature: "sha256=abc", Status: models.WebhookDeliverySucceeded, Attempts: 1}
+		mockService.On("GetDeliveries", mock.Anything, uint64(1), uint64(9), 2, 20).
+			Return(&models.WebhookDeliveryListResponse{
+				Deliveries: []models.WebhookDeliveryResponse{*delivery.ToResponse()},
+				Pagination: models.NewPagination(2, 20, 21),
+			}, nil)
+
+		// 上限を超えるlimitは既定値にする
+		httpReq := httptest.NewRequest(http.MethodGet, "/api/v1/apps/1/webhooks/9/deliveries?page=2&limit=500", nil)
+		rr := httptest.NewRecorder()
+
+		handler.Deliveries(rr, httpReq)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+
+		var result struct {
+			Deliveries []struct {
+				ID      uint64          `json:"id"`
+				Status  string          `json:"status"`
+				Payload json.RawMessage `json:"payload"`
+			} `json:"deliveries"`
+		}
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &result))
+		require.Len(t, result.Deliveries, 1)
+		assert.Equal(t, "succeeded", result.Deliveries[0].Status)
+		assert.JSONEq(t, `{"event":"record.created"}`, string(result.Deliveries[0].Payload))
+		assert.NotContains(t, rr.Body.String(), "sha256=abc")
+	})
+
+	t.Run("invalid webhook id", func(t *testing.T) {
+		mockService := new(mocks.MockWebhookService)
+		handler := handlers.NewWebhookHandler(mockService, validator)
+
+		httpReq := httptest.NewRequest(http.MethodGet, "/api/v1/apps/1/webhooks/abc/deliveries", nil)
+		rr := httptest.NewRecorder()
+
+		handler.Deliveries(rr, httpReq)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+}
@@ -0,0 +1,60 @@
+// Package mail レポートの配信などで使うメールの送信を扱う
+package mail
+
+import (
+	"context"
+	"errors"
+	"net/mail"
+)
+
+// メール送信関連エラー
+var (
+	ErrNotConfigured    = errors.New("メールの送信先サーバー（SMTP_HOST）が設定されていません")
+	ErrNoRecipients     = errors.New("メールの宛先がありません")
+	ErrInvalidRecipient = errors.New("無効なメールアドレスです")
+)
+
+// Attachment メールに添付するファイル
+type Attachment struct {
+	FileName    string
+	ContentType string
+	Data        []byte
+}
+
+// Message 送信するメール
+type Message struct {
+	To      []string
+	Subject string
+	// HTMLBody HTML形式の本文
+	HTMLBody    string
+	Attachments []Attachment
+}
+
+// Sender メールの送信先のインターフェースを定義
+type Sender interface {
+	// Send メールを送信する。送信先サーバーが設定されていない場合はErrNotConfiguredを返す
+	Send(ctx context.Context, msg *Message) error
+}
+
+// 実装がインターフェースを満たすことを確認
+var (
+	_ Sender = (*SMTPSender)(nil)
+	_ Sender = DisabledSender{}
+)
+
+// DisabledSender 送信先サーバーが設定されていない場合に使う、常にErrNotConfiguredを返すSender
+type DisabledSender struct{}
+
+// Send 常にErrNotConfiguredを返す
+func (DisabledSender) Send(ctx context.Context, msg *Message) error {
+	return ErrNotConfigured
+}
+
+// ValidateAddress 宛先に指定できるメールアドレス（表示名を含まない addr-spec）か確認する
+func ValidateAddress(address string) error {
+	parsed, err := mail.ParseAddress(address)
+	if err != nil || parsed.Name != "" || parsed.Address != address {
+		return ErrInvalidRecipient
+	}
+	return nil
+}
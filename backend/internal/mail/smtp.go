@@ -0,0 +1,184 @@
+package mail
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/smtp"
+	"net/textproto"
+	"strings"
+	"time"
+)
+
+// smtpTimeout コンテキストに期限がない場合の、1通の送信にかける最大の時間
+const smtpTimeout = time.Minute
+
+// SMTPConfig SMTPサーバーの接続設定
+type SMTPConfig struct {
+	Host string
+	Port string
+	// Username 認証に使うユーザー名（空の場合は認証しない）
+	Username string
+	Password string
+	// From 送信元のメールアドレス
+	From string
+}
+
+// SMTPSender SMTPサーバーを経由してメールを送信する構造体
+// サーバーが STARTTLS に対応している場合は暗号化してから認証・送信する
+type SMTPSender struct {
+	cfg    SMTPConfig
+	dialer net.Dialer
+}
+
+// NewSMTPSender 新しいSMTPSenderを作成する
+func NewSMTPSender(cfg SMTPConfig) (*SMTPSender, error) {
+	if cfg.Host == "" {
+		return nil, ErrNotConfigured
+	}
+	if cfg.Port == "" {
+		cfg.Port = "25"
+	}
+	if err := ValidateAddress(cfg.From); err != nil {
+		return nil, fmt.Errorf("送信元のメールアドレス %q が正しくありません", cfg.From)
+	}
+	return &SMTPSender{cfg: cfg}, nil
+}
+
+// Send メールを送信する
+func (s *SMTPSender) Send(ctx context.Context, msg *Message) error {
+	if len(msg.To) == 0 {
+		return ErrNoRecipients
+	}
+	for _, to := range msg.To {
+		if err := ValidateAddress(to); err != nil {
+			return fmt.Errorf("%w: %q", err, to)
+		}
+	}
+	body, err := buildMessage(s.cfg.From, msg, time.Now())
+	if err != nil {
+		return err
+	}
+
+	conn, err := s.dialer.DialContext(ctx, "tcp", net.JoinHostPort(s.cfg.Host, s.cfg.Port))
+	if err != nil {
+		return fmt.Errorf("SMTPサーバーに接続できません: %w", err)
+	}
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		deadline = time.Now().Add(smtpTimeout)
+	}
+	if err := conn.SetDeadline(deadline); err != nil {
+		_ = conn.Close()
+		return err
+	}
+
+	c, err := smtp.NewClient(conn, s.cfg.Host)
+	if err != nil {
+		_ = conn.Close()
+		return fmt.Errorf("SMTPサーバーに接続できません: %w", err)
+	}
+	defer func() { _ = c.Close() }()
+
+	if ok, _ := c.Extension("STARTTLS"); ok {
+		if err := c.StartTLS(&tls.Config{ServerName: s.cfg.Host, MinVersion: tls.VersionTLS12}); err != nil {
+			return fmt.Errorf("SMTPサーバーとの暗号化に失敗しました: %w", err)
+		}
+	}
+	if s.cfg.Username != "" {
+		if err := c.Auth(smtp.PlainAuth("", s.cfg.Username, s.cfg.Password, s.cfg.Host)); err != nil {
+			return fmt.Errorf("SMTPサーバーの認証に失敗しました: %w", err)
+		}
+	}
+
+	if err := c.Mail(s.cfg.From); err != nil {
+		return fmt.Errorf("メールの送信に失敗しました: %w", err)
+	}
+	for _, to := range msg.To {
+		if err := c.Rcpt(to); err != nil {
+			return fmt.Errorf("宛先 %q への送信に失敗しました: %w", to, err)
+		}
+	}
+	w, err := c.Data()
+	if err != nil {
+		return fmt.Errorf("メールの送信に失敗しました: %w", err)
+	}
+	if _, err := w.Write(body); err != nil {
+		return fmt.Errorf("メールの送信に失敗しました: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("メールの送信に失敗しました: %w", err)
+	}
+	return c.Quit()
+}
+
+// buildMessage 本文をHTML、添付ファイルを続くパートとする multipart/mixed 形式のメールを組み立てる
+// 件名とファイル名は日本語を含められるようエンコードし、本文と添付ファイルはbase64で送る
+func buildMessage(from string, msg *Message, now time.Time) ([]byte, error) {
+	var parts bytes.Buffer
+	mw := multipart.NewWriter(&parts)
+
+	if err := writeBase64Part(mw, textproto.MIMEHeader{
+		"Content-Type": {"text/html; charset=UTF-8"},
+	}, []byte(msg.HTMLBody)); err != nil {
+		return nil, err
+	}
+	for _, a := range msg.Attachments {
+		if strings.ContainsAny(a.FileName, "\r\n") {
+			return nil, errors.New("添付ファイル名に改行は使用できません")
+		}
+		contentType := a.ContentType
+		if contentType == "" {
+			contentType = "application/octet-stream"
+		}
+		if err := writeBase64Part(mw, textproto.MIMEHeader{
+			"Content-Type":        {mime.FormatMediaType(contentType, map[string]string{"name": a.FileName})},
+			"Content-Disposition": {mime.FormatMediaType("attachment", map[string]string{"filename": a.FileName})},
+		}, a.Data); err != nil {
+			return nil, err
+		}
+	}
+	if err := mw.Close(); err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	headers := [][2]string{
+		{"From", from},
+		{"To", strings.Join(msg.To, ", ")},
+		{"Subject", mime.BEncoding.Encode("UTF-8", msg.Subject)},
+		{"Date", now.Format(time.RFC1123Z)},
+		{"MIME-Version", "1.0"},
+		{"Content-Type", "multipart/mixed; boundary=" + mw.Boundary()},
+	}
+	for _, h := range headers {
+		fmt.Fprintf(&buf, "%s: %s\r\n", h[0], h[1])
+	}
+	buf.WriteString("\r\n")
+	buf.Write(parts.Bytes())
+	return buf.Bytes(), nil
+}
+
+// writeBase64Part dataをbase64で76文字ごとに改行したパートとして書き出す
+func writeBase64Part(mw *multipart.Writer, header textproto.MIMEHeader, data []byte) error {
+	header.Set("Content-Transfer-Encoding", "base64")
+	w, err := mw.CreatePart(header)
+	if err != nil {
+		return err
+	}
+	encoded := base64.StdEncoding.EncodeToString(data)
+	for len(encoded) > 76 {
+		if _, err := fmt.Fprintf(w, "%s\r\n", encoded[:76]); err != nil {
+			return err
+		}
+		encoded = encoded[76:]
+	}
+	_, err = fmt.Fprintf(w, "%s\r\n", encoded)
+	return err
+}
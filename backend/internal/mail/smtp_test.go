@@ -0,0 +1,163 @@
+package mail_test
+
+import (
+	"bufio"
+	"context"
+	"encoding/base64"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/mail"
+	"net/textproto"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	mailer "nocode-app/backend/internal/mail"
+)
+
+// fakeSMTPServer 1通だけ受け取るテスト用のSMTPサーバー
+type fakeSMTPServer struct {
+	listener net.Listener
+	from     string
+	rcpts    []string
+	data     string
+	done     chan struct{}
+}
+
+func startFakeSMTPServer(t *testing.T) *fakeSMTPServer {
+	t.Helper()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	s := &fakeSMTPServer{listener: l, done: make(chan struct{})}
+	t.Cleanup(func() { _ = l.Close() })
+
+	go func() {
+		defer close(s.done)
+		conn, err := l.Accept()
+		if err != nil {
+			return
+		}
+		defer func() { _ = conn.Close() }()
+		tp := textproto.NewConn(conn)
+		_ = tp.PrintfLine("220 localhost ESMTP")
+		for {
+			line, err := tp.ReadLine()
+			if err != nil {
+				return
+			}
+			cmd := strings.ToUpper(strings.SplitN(line, " ", 2)[0])
+			switch cmd {
+			case "EHLO", "HELO":
+				_ = tp.PrintfLine("250 localhost")
+			case "MAIL":
+				s.from = strings.Trim(strings.TrimPrefix(line, "MAIL FROM:"), "<>")
+				_ = tp.PrintfLine("250 OK")
+			case "RCPT":
+				s.rcpts = append(s.rcpts, strings.Trim(strings.TrimPrefix(line, "RCPT TO:"), "<>"))
+				_ = tp.PrintfLine("250 OK")
+			case "DATA":
+				_ = tp.PrintfLine("354 Start mail input")
+				data, err := io.ReadAll(tp.DotReader())
+				if err != nil {
+					return
+				}
+				s.data = string(data)
+				_ = tp.PrintfLine("250 OK")
+			case "QUIT":
+				_ = tp.PrintfLine("221 Bye")
+				return
+			default:
+				_ = tp.PrintfLine("502 Not implemented")
+			}
+		}
+	}()
+	return s
+}
+
+func TestSMTPSender_Send(t *testing.T) {
+	server := startFakeSMTPServer(t)
+	host, port, err := net.SplitHostPort(server.listener.Addr().String())
+	require.NoError(t, err)
+
+	sender, err := mailer.NewSMTPSender(mailer.SMTPConfig{Host: host, Port: port, From: "reports@example.com"})
+	require.NoError(t, err)
+
+	err = sender.Send(context.Background(), &mailer.Message{
+		To:       []string{"a@example.com", "b@example.com"},
+		Subject:  "[営業管理] 週次レポート",
+		HTMLBody: "<p>集計結果</p>",
+		Attachments: []mailer.Attachment{
+			{FileName: "週次レポート.csv", ContentType: "text/csv; charset=utf-8", Data: []byte("地域,件数\n東,2\n")},
+		},
+	})
+	require.NoError(t, err)
+	<-server.done
+
+	assert.Equal(t, "reports@example.com", server.from)
+	assert.Equal(t, []string{"a@example.com", "b@example.com"}, server.rcpts)
+
+	msg, err := mail.ReadMessage(bufio.NewReader(strings.NewReader(server.data)))
+	require.NoError(t, err)
+	subject, err := new(mime.WordDecoder).DecodeHeader(msg.Header.Get("Subject"))
+	require.NoError(t, err)
+	assert.Equal(t, "[営業管理] 週次レポート", subject)
+	assert.Equal(t, "a@example.com, b@example.com", msg.Header.Get("To"))
+
+	mediaType, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+	require.NoError(t, err)
+	assert.Equal(t, "multipart/mixed", mediaType)
+
+	mr := multipart.NewReader(msg.Body, params["boundary"])
+	readPart := func() (*multipart.Part, string) {
+		part, err := mr.NextPart()
+		require.NoError(t, err)
+		body, err := io.ReadAll(base64.NewDecoder(base64.StdEncoding, part))
+		require.NoError(t, err)
+		return part, string(body)
+	}
+
+	part, body := readPart()
+	assert.Equal(t, "text/html; charset=UTF-8", part.Header.Get("Content-Type"))
+	assert.Equal(t, "<p>集計結果</p>", body)
+
+	part, body = readPart()
+	assert.Equal(t, "週次レポート.csv", part.FileName())
+	assert.Equal(t, "地域,件数\n東,2\n", body)
+
+	_, err = mr.NextPart()
+	assert.ErrorIs(t, err, io.EOF)
+}
+
+func TestSMTPSender_Validation(t *testing.T) {
+	t.Run("host is required", func(t *testing.T) {
+		_, err := mailer.NewSMTPSender(mailer.SMTPConfig{From: "reports@example.com"})
+		assert.ErrorIs(t, err, mailer.ErrNotConfigured)
+	})
+
+	t.Run("invalid from address", func(t *testing.T) {
+		_, err := mailer.NewSMTPSender(mailer.SMTPConfig{Host: "localhost", From: "Reports <reports@example.com>"})
+		assert.Error(t, err)
+	})
+
+	sender, err := mailer.NewSMTPSender(mailer.SMTPConfig{Host: "localhost", Port: "1", From: "reports@example.com"})
+	require.NoError(t, err)
+
+	t.Run("no recipients", func(t *testing.T) {
+		err := sender.Send(context.Background(), &mailer.Message{Subject: "x"})
+		assert.ErrorIs(t, err, mailer.ErrNoRecipients)
+	})
+
+	t.Run("header injection in recipient", func(t *testing.T) {
+		err := sender.Send(context.Background(), &mailer.Message{To: []string{"a@example.com\r\nBcc: c@example.com"}})
+		assert.ErrorIs(t, err, mailer.ErrInvalidRecipient)
+	})
+
+	t.Run("disabled sender", func(t *testing.T) {
+		err := mailer.DisabledSender{}.Send(context.Background(), &mailer.Message{To: []string{"a@example.com"}})
+		assert.ErrorIs(t, err, mailer.ErrNotConfigured)
+	})
+}
@@ -56,6 +56,27 @@ func (m *AuthMiddleware) Authenticate(next http.Handler) http.Handler {
 	})
 }
 
+// AuthenticateQueryToken クエリ文字列 token の用途を限定したトークン、またはAuthorizationヘッダーのJWTで認証する
+// ヘッダーを指定できないブラウザのEventSourceなどのためのもので、通常のJWTはクエリ文字列では受け付けない
+func (m *AuthMiddleware) AuthenticateQueryToken(audience string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		tokenString := r.URL.Query().Get("token")
+		if tokenString == "" {
+			m.Authenticate(next).ServeHTTP(w, r)
+			return
+		}
+
+		claims, err := m.jwtManager.ValidateScopedToken(tokenString, audience)
+		if err != nil {
+			utils.WriteErrorResponse(w, http.StatusUnauthorized, "invalid or expired token")
+			return
+		}
+
+		ctx := context.WithValue(r.Context(), UserContextKey, claims)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
 // GetUserFromContext コンテキストからユーザークレームを取得する
 func GetUserFromContext(ctx context.Context) (*utils.JWTClaims, bool) {
 	claims, ok := ctx.Value(UserContextKey).(*utils.JWTClaims)
@@ -5,6 +5,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -101,6 +102,54 @@ func TestAuthMiddleware_Authenticate(t *testing.T) {
 	}
 }
 
+func TestAuthMiddleware_AuthenticateQueryToken(t *testing.T) {
+	jwtManager := utils.NewJWTManager("test-secret", 24)
+	m := middleware.NewAuthMiddleware(jwtManager)
+
+	validToken, err := jwtManager.GenerateToken(1, "test@example.com", "user")
+	require.NoError(t, err)
+	scopedToken, err := jwtManager.GenerateScopedToken(&utils.JWTClaims{UserID: 1, Email: "test@example.com", Role: "user"}, utils.RealtimeTokenAudience, time.Minute)
+	require.NoError(t, err)
+
+	nextHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		claims, ok := middleware.GetUserFromContext(r.Context())
+		if ok {
+			w.WriteHeader(http.StatusOK)
+			_, _ = w.Write([]byte(claims.Email))
+		} else {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	})
+
+	tests := []struct {
+		name           string
+		query          string
+		authHeader     string
+		wantStatusCode int
+	}{
+		{name: "scoped token in query", query: "?token=" + scopedToken, wantStatusCode: http.StatusOK},
+		{name: "authorization header", authHeader: "Bearer " + validToken, wantStatusCode: http.StatusOK},
+		{name: "access token in query", query: "?token=" + validToken, wantStatusCode: http.StatusUnauthorized},
+		{name: "invalid token in query", query: "?token=invalid-token", authHeader: "Bearer " + validToken, wantStatusCode: http.StatusUnauthorized},
+		{name: "missing token", wantStatusCode: http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/test"+tt.query, nil)
+			if tt.authHeader != "" {
+				req.Header.Set("Authorization", tt.authHeader)
+			}
+			rr := httptest.NewRecorder()
+
+			handler := m.AuthenticateQueryToken(utils.RealtimeTokenAudience, nextHandler)
+			handler.ServeHTTP(rr, req)
+
+			assert.Equal(t, tt.wantStatusCode, rr.Code)
+		})
+	}
+}
+
 func TestGetUserFromContext(t *testing.T) {
 	t.Run("with valid claims", func(t *testing.T) {
 		claims := &utils.JWTClaims{
@@ -34,6 +34,11 @@ func (rw *responseWriter) Write(b []byte) (int, error) {
 	return n, err
 }
 
+// Unwrap 元のResponseWriterを返す（http.ResponseControllerでフラッシュなどを行うため）
+func (rw *responseWriter) Unwrap() http.ResponseWriter {
+	return rw.ResponseWriter
+}
+
 // LoggerMiddleware ロギングミドルウェアを作成
 func LoggerMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -52,7 +57,7 @@ func LoggerMiddleware(next http.Handler) http.Handler {
 		log.Printf(
 			"[%s] %s %s %d %d %v",
 			r.Method,
-			r.RequestURI,
+			redactedRequestURI(r),
 			r.RemoteAddr,
 			rw.statusCode,
 			rw.written,
@@ -61,6 +66,16 @@ func LoggerMiddleware(next http.Handler) http.Handler {
 	})
 }
 
+// redactedRequestURI ログに残すURI（クエリ文字列の認証トークンは伏せる）
+func redactedRequestURI(r *http.Request) string {
+	query := r.URL.Query()
+	if !query.Has("token") {
+		return r.RequestURI
+	}
+	query.Set("token", "REDACTED")
+	return r.URL.Path + "?" + query.Encode()
+}
+
 // RecoveryMiddleware パニックリカバリーミドルウェアを作成
 func RecoveryMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -1,8 +1,11 @@
 package middleware_test
 
 import (
+	"bytes"
+	"log"
 	"net/http"
 	"net/http/httptest"
+	"os"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -61,6 +64,34 @@ func TestLoggerMiddleware(t *testing.T) {
 	}
 }
 
+func TestLoggerMiddleware_RedactsToken(t *testing.T) {
+	var buf bytes.Buffer
+	log.SetOutput(&buf)
+	defer log.SetOutput(os.Stderr)
+
+	nextHandler := http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/apps/1/events?token=secret-token", nil)
+	middleware.LoggerMiddleware(nextHandler).ServeHTTP(httptest.NewRecorder(), req)
+
+	assert.Contains(t, buf.String(), "/api/v1/apps/1/events?token=REDACTED")
+	assert.NotContains(t, buf.String(), "secret-token")
+}
+
+func TestLoggerMiddleware_Flush(t *testing.T) {
+	// ストリーミングのレスポンスはラップしたResponseWriterからもフラッシュできる
+	nextHandler := http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte("data: 1\n\n"))
+		assert.NoError(t, http.NewResponseController(w).Flush())
+	})
+
+	rr := httptest.NewRecorder()
+	middleware.LoggerMiddleware(nextHandler).ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/test", nil))
+
+	assert.True(t, rr.Flushed)
+}
+
 func TestRecoveryMiddleware(t *testing.T) {
 	t.Run("no panic", func(t *testing.T) {
 		nextHandler := http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
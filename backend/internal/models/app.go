@@ -10,29 +10,34 @@ import (
 type App struct {
 	bun.BaseModel `bun:"table:apps,alias:a"`
 
-	ID              uint64      `bun:"id,pk,autoincrement" json:"id"`
-	Name            string      `bun:"name,notnull" json:"name"`
-	Description     string      `bun:"description" json:"description"`
-	TableName       string      `bun:"table_name,notnull,unique" json:"table_name"`
-	Icon            string      `bun:"icon,notnull,default:'default'" json:"icon"`
-	IsExternal      bool        `bun:"is_external,notnull,default:false" json:"is_external"`
-	DataSourceID    *uint64     `bun:"data_source_id" json:"data_source_id,omitempty"`
-	SourceTableName *string     `bun:"source_table_name" json:"source_table_name,omitempty"`
-	CreatedBy       uint64      `bun:"created_by,notnull" json:"created_by"`
-	CreatedAt       time.Time   `bun:"created_at,notnull,default:current_timestamp" json:"created_at"`
-	UpdatedAt       time.Time   `bun:"updated_at,notnull,default:current_timestamp" json:"updated_at"`
-	Fields          []AppField  `bun:"rel:has-many,join:id=app_id" json:"fields,omitempty"`
-	Views           []AppView   `bun:"rel:has-many,join:id=app_id" json:"views,omitempty"`
-	Creator         *User       `bun:"rel:belongs-to,join:created_by=id" json:"creator,omitempty"`
-	DataSource      *DataSource `bun:"rel:belongs-to,join:data_source_id=id" json:"data_source,omitempty"`
+	ID              uint64    `bun:"id,pk,autoincrement" json:"id"`
+	Name            string    `bun:"name,notnull" json:"name"`
+	Description     string    `bun:"description" json:"description"`
+	TableName       string    `bun:"table_name,notnull,unique" json:"table_name"`
+	Icon            string    `bun:"icon,notnull,default:'default'" json:"icon"`
+	IsExternal      bool      `bun:"is_external,notnull,default:false" json:"is_external"`
+	DataSourceID    *uint64   `bun:"data_source_id" json:"data_source_id,omitempty"`
+	SourceTableName *string   `bun:"source_table_name" json:"source_table_name,omitempty"`
+	SearchConfig    *string   `bun:"search_config" json:"search_config,omitempty"`
+	CreatedBy       uint64    `bun:"created_by,notnull" json:"created_by"`
+	CreatedAt       time.Time `bun:"created_at,notnull,default:current_timestamp" json:"created_at"`
+	UpdatedAt       time.Time `bun:"updated_at,notnull,default:current_timestamp" json:"updated_at"`
+	// DeletedAt ごみ箱に移した日時（ごみ箱にないアプリはゼロ値）。ごみ箱のアプリは通常の取得では除外される
+	DeletedAt  time.Time   `bun:"deleted_at,soft_delete,nullzero" json:"-"`
+	Fields     []AppField  `bun:"rel:has-many,join:id=app_id" json:"fields,omitempty"`
+	Views      []AppView   `bun:"rel:has-many,join:id=app_id" json:"views,omitempty"`
+	Creator    *User       `bun:"rel:belongs-to,join:created_by=id" json:"creator,omitempty"`
+	DataSource *DataSource `bun:"rel:belongs-to,join:data_source_id=id" json:"data_source,omitempty"`
 }
 
 // CreateAppRequest アプリ作成リクエストの構造体
 type CreateAppRequest struct {
-	Name        string               `json:"name" validate:"required,min=1,max=100"`
-	Description string               `json:"description"`
-	Icon        string               `json:"icon"`
-	Fields      []CreateFieldRequest `json:"fields" validate:"required,min=1,dive"`
+	Name        string `json:"name" validate:"required,min=1,max=100"`
+	Description string `json:"description"`
+	Icon        string `json:"icon"`
+	// SearchConfig 全文検索用カラムの形式（テキスト検索設定名または trigram、省略時は simple）
+	SearchConfig string               `json:"search_config"`
+	Fields       []CreateFieldRequest `json:"fields" validate:"required,min=1,dive"`
 }
 
 // CreateExternalAppRequest 外部データソースからのアプリ作成リクエストの構造体
@@ -61,6 +66,8 @@ type UpdateAppRequest struct {
 	Name        string `json:"name" validate:"omitempty,min=1,max=100"`
 	Description string `json:"description"`
 	Icon        string `json:"icon"`
+	// SearchConfig 全文検索用カラムの形式。変更すると全文検索用カラムとインデックスを作り直す
+	SearchConfig string `json:"search_config"`
 }
 
 // AppResponse アプリデータのレスポンス構造体
@@ -73,11 +80,14 @@ type AppResponse struct {
 	IsExternal      bool            `json:"is_external"`
 	DataSourceID    *uint64         `json:"data_source_id,omitempty"`
 	SourceTableName *string         `json:"source_table_name,omitempty"`
+	SearchConfig    *string         `json:"search_config,omitempty"`
 	CreatedBy       uint64          `json:"created_by"`
 	CreatedAt       time.Time       `json:"created_at"`
 	UpdatedAt       time.Time       `json:"updated_at"`
 	Fields          []FieldResponse `json:"fields,omitempty"`
 	FieldCount      int             `json:"field_count"`
+	// MyRole 呼び出し元ユーザーの実効権限（アプリ詳細取得時のみ設定）
+	MyRole AppRole `json:"my_role,omitempty"`
 }
 
 // ToResponse AppをAppResponseに変換する
@@ -91,6 +101,7 @@ func (a *App) ToResponse() *AppResponse {
 		IsExternal:      a.IsExternal,
 		DataSourceID:    a.DataSourceID,
 		SourceTableName: a.SourceTableName,
+		SearchConfig:    a.SearchConfig,
 		CreatedBy:       a.CreatedBy,
 		CreatedAt:       a.CreatedAt,
 		UpdatedAt:       a.UpdatedAt,
@@ -0,0 +1,133 @@
+package models
+
+// AppPackageVersion アプリ定義パッケージの形式のバージョン
+// 形式を変更した場合は上げ、インポート時は以前のバージョンも受け付ける
+const AppPackageVersion = 1
+
+// AppPackageFormat アプリ定義パッケージのファイル形式を表す型
+type AppPackageFormat string
+
+// アプリ定義パッケージのファイル形式の定数
+const (
+	AppPackageFormatJSON AppPackageFormat = "json"
+	AppPackageFormatYAML AppPackageFormat = "yaml"
+)
+
+// IsValid ファイル形式が有効かどうかを確認
+func (f AppPackageFormat) IsValid() bool {
+	return f == AppPackageFormatJSON || f == AppPackageFormatYAML
+}
+
+// ContentType ファイル形式に対応するContent-Typeを返す
+func (f AppPackageFormat) ContentType() string {
+	if f == AppPackageFormatYAML {
+		return "application/yaml; charset=utf-8"
+	}
+	return "application/json"
+}
+
+// AppPackage アプリの定義（フィールド・ビュー・グラフ設定、任意でレコード）をまとめたパッケージ
+// 動的テーブル名やIDはサーバーごとに異なるため含めず、インポート時に新しく割り当てる
+type AppPackage struct {
+	Version int               `json:"version" yaml:"version" validate:"required,min=1"`
+	App     AppPackageApp     `json:"app" yaml:"app"`
+	Fields  []AppPackageField `json:"fields" yaml:"fields" validate:"required,min=1,dive"`
+	Views   []AppPackageView  `json:"views,omitempty" yaml:"views,omitempty" validate:"dive"`
+	Charts  []AppPackageChart `json:"charts,omitempty" yaml:"charts,omitempty" validate:"dive"`
+	Records []RecordData      `json:"records,omitempty" yaml:"records,omitempty"`
+}
+
+// AppPackageApp パッケージのアプリ情報
+type AppPackageApp struct {
+	Name         string `json:"name" yaml:"name" validate:"required,min=1,max=100"`
+	Description  string `json:"description,omitempty" yaml:"description,omitempty"`
+	Icon         string `json:"icon,omitempty" yaml:"icon,omitempty"`
+	SearchConfig string `json:"search_config,omitempty" yaml:"search_config,omitempty"`
+}
+
+// AppPackageField パッケージのフィールド定義
+// 参照フィールド・集計フィールドのオプションの app_id はエクスポート元のサーバーのアプリIDのまま保存する
+type AppPackageField struct {
+	FieldCode    string       `json:"field_code" yaml:"field_code" validate:"required,min=1,max=64,fieldcode"`
+	FieldName    string       `json:"field_name" yaml:"field_name" validate:"required,min=1,max=100"`
+	FieldType    string       `json:"field_type" yaml:"field_type" validate:"required,oneof=text textarea number date datetime select multiselect checkbox radio link attachment reference lookup formula rollup"`
+	Options      FieldOptions `json:"options,omitempty" yaml:"options,omitempty"`
+	Required     bool         `json:"required,omitempty" yaml:"required,omitempty"`
+	DisplayOrder int          `json:"display_order" yaml:"display_order"`
+}
+
+// AppPackageView パッケージのビュー設定
+type AppPackageView struct {
+	Name      string     `json:"name" yaml:"name" validate:"required,min=1,max=100"`
+	ViewType  string     `json:"view_type" yaml:"view_type" validate:"required,oneof=table list calendar chart"`
+	Config    ViewConfig `json:"config,omitempty" yaml:"config,omitempty"`
+	IsDefault bool       `json:"is_default,omitempty" yaml:"is_default,omitempty"`
+}
+
+// AppPackageChart パッケージの保存済みグラフ設定
+type AppPackageChart struct {
+	Name      string     `json:"name" yaml:"name" validate:"required,min=1,max=100"`
+	ChartType string     `json:"chart_type" yaml:"chart_type" validate:"required"`
+	Config    ViewConfig `json:"config" yaml:"config"`
+}
+
+// ImportAppRequest アプリ定義パッケージのインポートリクエストの構造体
+type ImportAppRequest struct {
+	Package AppPackage `json:"package"`
+	// Name 作成するアプリの名前（省略時はパッケージのアプリ名）
+	Name string `json:"name" validate:"omitempty,max=100"`
+	// FieldCodes 変更するフィールドコード（パッケージのフィールドコード → 新しいフィールドコード）
+	FieldCodes map[string]string `json:"field_codes"`
+	// ReferenceApps 参照先・集計するアプリの置き換え（パッケージのフィールドコード → このサーバーのアプリID）
+	ReferenceApps map[string]uint64 `json:"reference_apps"`
+	// SkipRecords パッケージにレコードが含まれていても登録しない
+	SkipRecords bool `json:"skip_records"`
+}
+
+// FieldCodeRename インポート時に変更したフィールドコード
+type FieldCodeRename struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// ImportAppResponse アプリ定義パッケージのインポート結果のレスポンス構造体
+type ImportAppResponse struct {
+	App *AppResponse `json:"app"`
+	// RenamedFields 指定または重複・予約語の回避のために変更したフィールドコード
+	RenamedFields []FieldCodeRename `json:"renamed_fields"`
+	// SkippedFields 作成しなかったフィールドのフィールドコード（集計フィールド）
+	SkippedFields   []string `json:"skipped_fields"`
+	ImportedViews   int      `json:"imported_views"`
+	ImportedCharts  int      `json:"imported_charts"`
+	ImportedRecords int      `json:"imported_records"`
+}
+
+// AppTemplate サーバーに組み込まれたアプリのテンプレート
+type AppTemplate struct {
+	ID          string     `json:"id"`
+	Name        string     `json:"name"`
+	Description string     `json:"description"`
+	Package     AppPackage `json:"package"`
+}
+
+// AppTemplateSummary テンプレート一覧の項目
+type AppTemplateSummary struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	FieldCount  int    `json:"field_count"`
+	ViewCount   int    `json:"view_count"`
+}
+
+// AppTemplateListResponse テンプレート一覧のレスポンス構造体
+type AppTemplateListResponse struct {
+	Templates []AppTemplateSummary `json:"templates"`
+}
+
+// CreateAppFromTemplateRequest テンプレートからのアプリ作成リクエストの構造体
+type CreateAppFromTemplateRequest struct {
+	// Name 作成するアプリの名前（省略時はテンプレートのアプリ名）
+	Name string `json:"name" validate:"omitempty,max=100"`
+	// SkipRecords テンプレートのサンプルレコードを登録しない
+	SkipRecords bool `json:"skip_records"`
+}
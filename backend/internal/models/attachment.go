@@ -0,0 +1,63 @@
+package models
+
+import (
+	"io"
+	"time"
+
+	"github.com/uptrace/bun"
+)
+
+// Attachment 添付ファイルフィールドにアップロードされたファイルを表す構造体
+// ファイルの本体はストレージにStorageKeyで保存する
+type Attachment struct {
+	bun.BaseModel `bun:"table:attachments,alias:att"`
+
+	ID        uint64 `bun:"id,pk,autoincrement" json:"id"`
+	AppID     uint64 `bun:"app_id,notnull" json:"app_id"`
+	FieldCode string `bun:"field_code,notnull" json:"field_code"`
+	// RecordID 添付先のレコードID（レコードに添付される前はnil）
+	RecordID    *uint64 `bun:"record_id" json:"record_id,omitempty"`
+	StorageKey  string  `bun:"storage_key,notnull" json:"-"`
+	FileName    string  `bun:"file_name,notnull" json:"file_name"`
+	ContentType string  `bun:"content_type,notnull" json:"content_type"`
+	Size        int64   `bun:"size,notnull" json:"size"`
+	UploadedBy  *uint64 `bun:"uploaded_by" json:"uploaded_by,omitempty"`
+	// OrphanedAt レコードやフィールドの削除で不要になった日時（ストレージからの削除待ち）
+	OrphanedAt *time.Time `bun:"orphaned_at" json:"-"`
+	CreatedAt  time.Time  `bun:"created_at,notnull,default:current_timestamp" json:"created_at"`
+}
+
+// ToValue レコードの添付ファイルフィールドに保存する値に変換する
+func (a *Attachment) ToValue() AttachmentValue {
+	return AttachmentValue{
+		ID:          a.ID,
+		FileName:    a.FileName,
+		ContentType: a.ContentType,
+		Size:        a.Size,
+	}
+}
+
+// AttachmentValue 添付ファイルフィールドの値（レコードに保存する添付ファイルの情報）を表す構造体
+type AttachmentValue struct {
+	ID          uint64 `json:"id"`
+	FileName    string `json:"file_name"`
+	ContentType string `json:"content_type"`
+	Size        int64  `json:"size"`
+}
+
+// UploadAttachmentRequest 添付ファイルのアップロードリクエスト
+type UploadAttachmentRequest struct {
+	// FieldCode アップロード先の添付ファイルフィールド
+	FieldCode string
+	FileName  string
+	Size      int64
+	// File ファイルの内容（種類の判定後に先頭へ戻して保存する）
+	File io.ReadSeeker
+}
+
+// AttachmentURLResponse 添付ファイルのダウンロードURLのレスポンス
+type AttachmentURLResponse struct {
+	AttachmentValue
+	URL       string    `json:"url"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
@@ -0,0 +1,145 @@
+package models
+
+import (
+	"time"
+
+	"github.com/uptrace/bun"
+)
+
+// AutomationTrigger 自動化ルールを実行する契機を表す型
+type AutomationTrigger string
+
+// 自動化ルールの実行契機の定数
+const (
+	// AutomationTriggerRecordCreated レコードが作成されたとき
+	AutomationTriggerRecordCreated AutomationTrigger = "record_created"
+	// AutomationTriggerRecordUpdated レコードの値が変わったとき
+	AutomationTriggerRecordUpdated AutomationTrigger = "record_updated"
+	// AutomationTriggerFieldChanged 指定したフィールドの値が変わったとき
+	AutomationTriggerFieldChanged AutomationTrigger = "field_changed"
+	// AutomationTriggerSchedule cron形式のスケジュールで指定した日時になったとき
+	AutomationTriggerSchedule AutomationTrigger = "schedule"
+	// AutomationTriggerDateReached 日付・日時フィールドの値から指定した日数が経過したとき
+	AutomationTriggerDateReached AutomationTrigger = "date_reached"
+)
+
+// IsScheduled レコードの変更ではなくスケジューラーが実行する契機かどうかを返す
+func (t AutomationTrigger) IsScheduled() bool {
+	return t == AutomationTriggerSchedule || t == AutomationTriggerDateReached
+}
+
+// AutomationActionType 自動化ルールで実行するアクションの種類を表す型
+type AutomationActionType string
+
+// 自動化アクションの種類の定数
+const (
+	// AutomationActionUpdateRecord 契機となったレコードのフィールドを更新する
+	AutomationActionUpdateRecord AutomationActionType = "update_record"
+	// AutomationActionCreateRecord 別のアプリ（または同じアプリ）にレコードを作成する
+	AutomationActionCreateRecord AutomationActionType = "create_record"
+	// AutomationActionCallWebhook 登録済みのWebhookに通知する
+	AutomationActionCallWebhook AutomationActionType = "call_webhook"
+)
+
+// AutomationRunStatus 自動化ルールの実行結果を表す型
+type AutomationRunStatus string
+
+// 自動化ルールの実行結果の定数
+const (
+	// AutomationRunSucceeded 全てのアクションが成功した
+	AutomationRunSucceeded AutomationRunStatus = "succeeded"
+	// AutomationRunFailed いずれかのアクションが失敗した（以降のアクションは実行しない）
+	AutomationRunFailed AutomationRunStatus = "failed"
+	// AutomationRunSkipped 無限ループを防ぐため実行しなかった
+	AutomationRunSkipped AutomationRunStatus = "skipped"
+)
+
+// AutomationAction 自動化ルールで実行する1つのアクションを表す構造体
+// Values の値に "{{field_code}}" と書くと契機となったレコードの値を、"{{id}}" と書くとレコードIDを使う
+type AutomationAction struct {
+	Type      AutomationActionType `json:"type" validate:"required,oneof=update_record create_record call_webhook"`
+	AppID     uint64               `json:"app_id,omitempty"`
+	WebhookID uint64               `json:"webhook_id,omitempty"`
+	Values    RecordData           `json:"values,omitempty"`
+}
+
+// AutomationRule アプリの自動化ルール（契機 → 条件 → アクション）を表す構造体
+type AutomationRule struct {
+	bun.BaseModel `bun:"table:automation_rules,alias:ar"`
+
+	ID           uint64             `bun:"id,pk,autoincrement" json:"id"`
+	AppID        uint64             `bun:"app_id,notnull" json:"app_id"`
+	Name         string             `bun:"name,notnull" json:"name"`
+	Trigger      AutomationTrigger  `bun:"trigger_type,notnull" json:"trigger"`
+	TriggerField string             `bun:"trigger_field,notnull" json:"trigger_field,omitempty"`
+	Schedule     string             `bun:"schedule,notnull" json:"schedule,omitempty"`
+	OffsetDays   int                `bun:"offset_days,notnull" json:"offset_days,omitempty"`
+	Timezone     string             `bun:"timezone,notnull" json:"timezone,omitempty"`
+	Conditions   []FilterItem       `bun:"conditions,type:jsonb" json:"conditions"`
+	Actions      []AutomationAction `bun:"actions,type:jsonb" json:"actions"`
+	IsActive     bool               `bun:"is_active,notnull,default:true" json:"is_active"`
+	NextRunAt    *time.Time         `bun:"next_run_at" json:"next_run_at,omitempty"`
+	LastRunAt    *time.Time         `bun:"last_run_at" json:"last_run_at,omitempty"`
+	CreatedBy    *uint64            `bun:"created_by" json:"created_by,omitempty"`
+	CreatedAt    time.Time          `bun:"created_at,notnull,default:current_timestamp" json:"created_at"`
+	UpdatedAt    time.Time          `bun:"updated_at,notnull,default:current_timestamp" json:"updated_at"`
+}
+
+// AutomationRun 自動化ルールの1回の実行ログを表す構造体
+type AutomationRun struct {
+	bun.BaseModel `bun:"table:automation_runs,alias:arun"`
+
+	ID        uint64              `bun:"id,pk,autoincrement" json:"id"`
+	RuleID    uint64              `bun:"rule_id,notnull" json:"rule_id"`
+	AppID     uint64              `bun:"app_id,notnull" json:"app_id"`
+	RecordID  uint64              `bun:"record_id,notnull" json:"record_id"`
+	Status    AutomationRunStatus `bun:"status,notnull" json:"status"`
+	Error     string              `bun:"error,notnull,default:''" json:"error,omitempty"`
+	Depth     int                 `bun:"depth,notnull,default:0" json:"depth"`
+	CreatedAt time.Time           `bun:"created_at,notnull,default:current_timestamp" json:"created_at"`
+}
+
+// WebhookAutomationData 自動化ルールからWebhookに通知するデータの構造体
+type WebhookAutomationData struct {
+	RuleID   uint64     `json:"rule_id"`
+	RuleName string     `json:"rule_name"`
+	RecordID uint64     `json:"record_id"`
+	Data     RecordData `json:"data"`
+}
+
+// CreateAutomationRuleRequest 自動化ルール作成リクエストの構造体
+type CreateAutomationRuleRequest struct {
+	Name         string             `json:"name" validate:"required,min=1,max=100"`
+	Trigger      AutomationTrigger  `json:"trigger" validate:"required,oneof=record_created record_updated field_changed schedule date_reached"`
+	TriggerField string             `json:"trigger_field" validate:"max=64"`
+	Schedule     string             `json:"schedule" validate:"max=100"`
+	OffsetDays   int                `json:"offset_days" validate:"min=-365,max=365"`
+	Timezone     string             `json:"timezone" validate:"max=64"`
+	Conditions   []FilterItem       `json:"conditions" validate:"max=20,dive"`
+	Actions      []AutomationAction `json:"actions" validate:"required,min=1,max=10,dive"`
+	IsActive     *bool              `json:"is_active"`
+}
+
+// UpdateAutomationRuleRequest 自動化ルール更新リクエストの構造体（ルール全体を置き換える）
+type UpdateAutomationRuleRequest struct {
+	Name         string             `json:"name" validate:"required,min=1,max=100"`
+	Trigger      AutomationTrigger  `json:"trigger" validate:"required,oneof=record_created record_updated field_changed schedule date_reached"`
+	TriggerField string             `json:"trigger_field" validate:"max=64"`
+	Schedule     string             `json:"schedule" validate:"max=100"`
+	OffsetDays   int                `json:"offset_days" validate:"min=-365,max=365"`
+	Timezone     string             `json:"timezone" validate:"max=64"`
+	Conditions   []FilterItem       `json:"conditions" validate:"max=20,dive"`
+	Actions      []AutomationAction `json:"actions" validate:"required,min=1,max=10,dive"`
+	IsActive     *bool              `json:"is_active"`
+}
+
+// AutomationRuleListResponse 自動化ルール一覧のレスポンス構造体
+type AutomationRuleListResponse struct {
+	Rules []AutomationRule `json:"rules"`
+}
+
+// AutomationRunListResponse 自動化ルール実行ログ一覧のレスポンス構造体
+type AutomationRunListResponse struct {
+	Runs       []AutomationRun `json:"runs"`
+	Pagination *Pagination     `json:"pagination"`
+}
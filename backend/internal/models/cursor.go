@@ -0,0 +1,43 @@
+package models
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+)
+
+// RecordCursor カーソル方式のページ送りで、前のページの最後のレコードの位置を表す構造体
+// クライアントには Encode した不透明な文字列として渡す
+type RecordCursor struct {
+	// Sort 並べ替えのフィールドコード（空の場合はIDのみで並べる）
+	Sort string `json:"s,omitempty"`
+	// Order 並べ替えの向き（asc または desc）
+	Order string `json:"o"`
+	// Value 並べ替えのキーの値をデータベースで文字列に変換したもの（NULLの場合はnil）
+	Value *string `json:"v,omitempty"`
+	// ID 並べ替えのキーが同じレコードを区別するためのレコードID
+	ID uint64 `json:"i"`
+}
+
+// Encode カーソルを不透明な文字列に変換する
+func (c *RecordCursor) Encode() string {
+	// フィールドは文字列と整数のみのため失敗しない
+	b, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// DecodeRecordCursor Encode で作成した文字列からカーソルを復元する
+func DecodeRecordCursor(s string) (*RecordCursor, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, errors.New("カーソルの形式が正しくありません")
+	}
+	var c RecordCursor
+	if err := json.Unmarshal(b, &c); err != nil {
+		return nil, errors.New("カーソルの形式が正しくありません")
+	}
+	if c.Order != "asc" && c.Order != "desc" {
+		return nil, errors.New("カーソルの並べ替えの向きが正しくありません")
+	}
+	return &c, nil
+}
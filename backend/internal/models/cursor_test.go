@@ -0,0 +1,41 @@
+package models_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"nocode-app/backend/internal/models"
+)
+
+func TestRecordCursor_EncodeDecode(t *testing.T) {
+	value := "2024-01-02 03:04:05.123456+00"
+	cursor := &models.RecordCursor{Sort: "created_at", Order: "desc", Value: &value, ID: 42}
+
+	decoded, err := models.DecodeRecordCursor(cursor.Encode())
+	require.NoError(t, err)
+	assert.Equal(t, cursor, decoded)
+
+	nullCursor := &models.RecordCursor{Order: "asc", ID: 7}
+	decoded, err = models.DecodeRecordCursor(nullCursor.Encode())
+	require.NoError(t, err)
+	assert.Nil(t, decoded.Value)
+	assert.Equal(t, uint64(7), decoded.ID)
+}
+
+func TestDecodeRecordCursor_Invalid(t *testing.T) {
+	for _, s := range []string{"!!!", "bm90IGpzb24", "eyJvIjoic2lkZXdheXMiLCJpIjoxfQ"} {
+		_, err := models.DecodeRecordCursor(s)
+		assert.Error(t, err, s)
+	}
+}
+
+func TestNewCursorPagination(t *testing.T) {
+	p := models.NewCursorPagination(20, 45, "next")
+	assert.Equal(t, &models.Pagination{Limit: 20, Total: 45, TotalPages: 3, NextCursor: "next"}, p)
+
+	p = models.NewCursorPagination(20, -1, "")
+	assert.Equal(t, int64(-1), p.Total)
+	assert.Equal(t, -1, p.TotalPages)
+}
@@ -140,6 +140,8 @@ type UpdateDashboardWidgetRequest struct {
 	IsVisible    *bool        `json:"is_visible"`
 	WidgetSize   string       `json:"widget_size" validate:"omitempty,oneof=small medium large"`
 	Config       WidgetConfig `json:"config"`
+	// Version 取得したときのバージョン（If-Matchヘッダーを送れない場合に指定する）
+	Version *int64 `json:"version,omitempty"`
 }
 
 // ReorderWidgetsRequest ウィジェット並び替えリクエストの構造体
@@ -159,6 +161,7 @@ type DashboardWidgetResponse struct {
 	Config       WidgetConfig `json:"config,omitempty"`
 	CreatedAt    time.Time    `json:"created_at"`
 	UpdatedAt    time.Time    `json:"updated_at"`
+	Version      int64        `json:"version"`
 	App          *AppResponse `json:"app,omitempty"`
 }
 
@@ -175,6 +178,7 @@ func (w *DashboardWidget) ToResponse() *DashboardWidgetResponse {
 		Config:       w.Config,
 		CreatedAt:    w.CreatedAt,
 		UpdatedAt:    w.UpdatedAt,
+		Version:      VersionOf(w.UpdatedAt),
 	}
 
 	if w.App != nil {
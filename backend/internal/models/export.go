@@ -0,0 +1,32 @@
+package models
+
+// ExportFormat レコードエクスポートのファイル形式を表す型
+type ExportFormat string
+
+// エクスポート形式の定数
+const (
+	ExportFormatCSV    ExportFormat = "csv"
+	ExportFormatXLSX   ExportFormat = "xlsx"
+	ExportFormatNDJSON ExportFormat = "ndjson"
+)
+
+// IsValid エクスポート形式が有効かどうかを確認
+func (f ExportFormat) IsValid() bool {
+	switch f {
+	case ExportFormatCSV, ExportFormatXLSX, ExportFormatNDJSON:
+		return true
+	}
+	return false
+}
+
+// ContentType エクスポート形式に対応するContent-Typeを返す
+func (f ExportFormat) ContentType() string {
+	switch f {
+	case ExportFormatXLSX:
+		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	case ExportFormatNDJSON:
+		return "application/x-ndjson"
+	default:
+		return "text/csv; charset=utf-8"
+	}
+}
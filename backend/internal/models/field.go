@@ -25,13 +25,92 @@ const (
 	FieldTypeRadio       FieldType = "radio"
 	FieldTypeLink        FieldType = "link"
 	FieldTypeAttachment  FieldType = "attachment"
+	FieldTypeReference   FieldType = "reference"
+	FieldTypeLookup      FieldType = "lookup"
+	FieldTypeFormula     FieldType = "formula"
+	FieldTypeRollup      FieldType = "rollup"
 )
 
+// ReferenceOnDelete 参照先レコードが削除されたときの動作を表す型
+type ReferenceOnDelete string
+
+// 参照先削除時の動作の定数
+const (
+	// ReferenceOnDeleteRestrict 参照されているレコードの削除を禁止する
+	ReferenceOnDeleteRestrict ReferenceOnDelete = "restrict"
+	// ReferenceOnDeleteSetNull 参照元の値をNULLにする
+	ReferenceOnDeleteSetNull ReferenceOnDelete = "set_null"
+	// ReferenceOnDeleteCascade 参照元のレコードも削除する
+	ReferenceOnDeleteCascade ReferenceOnDelete = "cascade"
+)
+
+// IsValid 参照先削除時の動作が有効かどうかを確認
+func (d ReferenceOnDelete) IsValid() bool {
+	switch d {
+	case ReferenceOnDeleteRestrict, ReferenceOnDeleteSetNull, ReferenceOnDeleteCascade:
+		return true
+	}
+	return false
+}
+
+// SQL 外部キー制約の ON DELETE 句に指定する動作を返す
+func (d ReferenceOnDelete) SQL() string {
+	switch d {
+	case ReferenceOnDeleteSetNull:
+		return "SET NULL"
+	case ReferenceOnDeleteCascade:
+		return "CASCADE"
+	default:
+		return "RESTRICT"
+	}
+}
+
+// RollupAggregation 集計フィールドの集計方法を表す型
+type RollupAggregation string
+
+// 集計方法の定数
+const (
+	RollupCount RollupAggregation = "count"
+	RollupSum   RollupAggregation = "sum"
+	RollupAvg   RollupAggregation = "avg"
+	RollupMin   RollupAggregation = "min"
+	RollupMax   RollupAggregation = "max"
+)
+
+// IsValid 集計方法が有効かどうかを確認
+func (a RollupAggregation) IsValid() bool {
+	switch a {
+	case RollupCount, RollupSum, RollupAvg, RollupMin, RollupMax:
+		return true
+	}
+	return false
+}
+
+// RollupOptions 集計フィールドのオプション
+type RollupOptions struct {
+	// AppID 集計する子レコードのアプリID
+	AppID uint64 `json:"app_id"`
+	// LinkField 子レコードで親レコードのIDを保持するフィールドコード
+	LinkField string `json:"link_field"`
+	// Aggregation 集計方法
+	Aggregation RollupAggregation `json:"aggregation"`
+	// TargetField 集計する子レコードのフィールドコード（countの場合は不要）
+	TargetField string `json:"target_field,omitempty"`
+	// Filters 集計する子レコードの絞り込み条件
+	Filters []FilterItem `json:"filters,omitempty"`
+}
+
 // PostgreSQLカラム型の定数
 const (
 	pgVarchar255 = "VARCHAR(255)"
 )
 
+// formulaResultTypeOption 計算フィールドの結果の型を保持するオプションキー
+const formulaResultTypeOption = "result_type"
+
+// uniqueOption 値の重複を禁止するかどうかを保持するオプションキー
+const uniqueOption = "unique"
+
 // FieldOptions フィールド固有のオプションをJSONとして保持する型
 type FieldOptions map[string]interface{}
 
@@ -80,13 +159,15 @@ type AppField struct {
 	DisplayOrder     int          `bun:"display_order,notnull,default:0" json:"display_order"`
 	CreatedAt        time.Time    `bun:"created_at,notnull,default:current_timestamp" json:"created_at"`
 	UpdatedAt        time.Time    `bun:"updated_at,notnull,default:current_timestamp" json:"updated_at"`
+	// DeletedAt ごみ箱に移した日時（ごみ箱にないフィールドはゼロ値）。ごみ箱のフィールドは通常の取得では除外される
+	DeletedAt time.Time `bun:"deleted_at,soft_delete,nullzero" json:"-"`
 }
 
 // CreateFieldRequest フィールド作成リクエストの構造体
 type CreateFieldRequest struct {
 	FieldCode        string       `json:"field_code" validate:"required,min=1,max=64,fieldcode"`
 	FieldName        string       `json:"field_name" validate:"required,min=1,max=100"`
-	FieldType        string       `json:"field_type" validate:"required,oneof=text textarea number date datetime select multiselect checkbox radio link attachment"`
+	FieldType        string       `json:"field_type" validate:"required,oneof=text textarea number date datetime select multiselect checkbox radio link attachment reference lookup formula rollup"`
 	SourceColumnName string       `json:"source_column_name"` // 外部データソースのカラム名（外部アプリの場合のみ使用）
 	Options          FieldOptions `json:"options"`
 	Required         bool         `json:"required"`
@@ -99,6 +180,8 @@ type UpdateFieldRequest struct {
 	Options      FieldOptions `json:"options"`
 	Required     *bool        `json:"required"`
 	DisplayOrder *int         `json:"display_order"`
+	// Version 取得したときのバージョン（If-Matchヘッダーを送れない場合に指定する）
+	Version *int64 `json:"version,omitempty"`
 }
 
 // UpdateFieldOrderRequest フィールド順序更新リクエストの構造体
@@ -125,6 +208,7 @@ type FieldResponse struct {
 	DisplayOrder     int          `json:"display_order"`
 	CreatedAt        time.Time    `json:"created_at"`
 	UpdatedAt        time.Time    `json:"updated_at"`
+	Version          int64        `json:"version"`
 }
 
 // ToResponse AppFieldをFieldResponseに変換する
@@ -141,12 +225,90 @@ func (f *AppField) ToResponse() *FieldResponse {
 		DisplayOrder:     f.DisplayOrder,
 		CreatedAt:        f.CreatedAt,
 		UpdatedAt:        f.UpdatedAt,
+		Version:          VersionOf(f.UpdatedAt),
+	}
+}
+
+// HasColumn このフィールドが動的テーブルにカラムを持つかどうかを返す
+// ルックアップは参照先レコードの値を表示するだけ、集計フィールドは取得時に子レコードを集計するため、カラムを持たない
+func (f *AppField) HasColumn() bool {
+	switch FieldType(f.FieldType) {
+	case FieldTypeLookup, FieldTypeRollup:
+		return false
+	}
+	return true
+}
+
+// IsComputed このフィールドの値が入力ではなく計算で決まるかどうかを返す
+// ルックアップ・計算フィールド・集計フィールドは入力値を受け付けない
+func (f *AppField) IsComputed() bool {
+	switch FieldType(f.FieldType) {
+	case FieldTypeLookup, FieldTypeFormula, FieldTypeRollup:
+		return true
+	}
+	return false
+}
+
+// Rollup 集計フィールドのオプションを構造体として返す
+func (f *AppField) Rollup() (*RollupOptions, error) {
+	if FieldType(f.FieldType) != FieldTypeRollup {
+		return nil, errors.New("集計フィールドではありません")
+	}
+	data, err := json.Marshal(f.Options)
+	if err != nil {
+		return nil, err
+	}
+	opts := new(RollupOptions)
+	if err := json.Unmarshal(data, opts); err != nil {
+		return nil, err
+	}
+	return opts, nil
+}
+
+// FormulaResultType 計算フィールドの計算結果の型を返す（計算フィールド以外は空文字）
+// 結果の型はフィールド作成時に計算式の型検査で求め、オプションの result_type に保存する
+func (f *AppField) FormulaResultType() FieldType {
+	if FieldType(f.FieldType) != FieldTypeFormula {
+		return ""
+	}
+	t, _ := f.Options[formulaResultTypeOption].(string)
+	return FieldType(t)
+}
+
+// IsUnique 値の重複を禁止する（カラムに一意制約を付ける）フィールドかどうかを返す
+func (f *AppField) IsUnique() bool {
+	unique, _ := f.Options[uniqueOption].(bool)
+	return unique && f.SupportsUnique()
+}
+
+// SupportsUnique 値の重複を禁止できるフィールドかどうかを返す
+// 入力値を1つの値として保存するフィールドのみで、JSONBで保存する複数選択・添付ファイルやチェックボックスは対象外
+func (f *AppField) SupportsUnique() bool {
+	switch FieldType(f.FieldType) {
+	case FieldTypeText, FieldTypeTextArea, FieldTypeNumber, FieldTypeDate, FieldTypeDateTime,
+		FieldTypeSelect, FieldTypeRadio, FieldTypeLink, FieldTypeReference:
+		return true
 	}
+	return false
 }
 
 // GetPostgresColumnType このフィールドのPostgreSQLカラム型を返す
 func (f *AppField) GetPostgresColumnType() string {
 	switch FieldType(f.FieldType) {
+	case FieldTypeFormula:
+		// 計算結果の型に応じた生成列とする
+		switch f.FormulaResultType() {
+		case FieldTypeNumber:
+			return "NUMERIC(18,4)"
+		case FieldTypeDate:
+			return "DATE"
+		case FieldTypeDateTime:
+			return "TIMESTAMP"
+		case FieldTypeCheckbox:
+			return "BOOLEAN"
+		default:
+			return "TEXT"
+		}
 	case FieldTypeText:
 		return pgVarchar255
 	case FieldTypeTextArea:
@@ -169,6 +331,9 @@ func (f *AppField) GetPostgresColumnType() string {
 		return "VARCHAR(500)"
 	case FieldTypeAttachment:
 		return "JSONB"
+	case FieldTypeReference:
+		// 参照先レコードのID（外部キー制約は別途付与する）
+		return "BIGINT"
 	default:
 		return pgVarchar255
 	}
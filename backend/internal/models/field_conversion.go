@@ -0,0 +1,81 @@
+package models
+
+// fieldConversions フィールドの種類ごとに変換できる種類
+// 1つの値をカラムに保存するフィールドと複数選択の間で、値の意味を保ったまま変換できる組み合わせのみを許可する
+var fieldConversions = map[FieldType][]FieldType{
+	FieldTypeText:        {FieldTypeTextArea, FieldTypeNumber, FieldTypeDate, FieldTypeDateTime, FieldTypeSelect, FieldTypeMultiSelect, FieldTypeCheckbox, FieldTypeRadio, FieldTypeLink},
+	FieldTypeTextArea:    {FieldTypeText, FieldTypeNumber, FieldTypeDate, FieldTypeDateTime, FieldTypeSelect, FieldTypeMultiSelect, FieldTypeCheckbox, FieldTypeRadio, FieldTypeLink},
+	FieldTypeNumber:      {FieldTypeText, FieldTypeTextArea, FieldTypeSelect, FieldTypeCheckbox, FieldTypeRadio},
+	FieldTypeDate:        {FieldTypeText, FieldTypeTextArea, FieldTypeDateTime, FieldTypeSelect, FieldTypeRadio},
+	FieldTypeDateTime:    {FieldTypeText, FieldTypeTextArea, FieldTypeDate},
+	FieldTypeSelect:      {FieldTypeText, FieldTypeTextArea, FieldTypeNumber, FieldTypeDate, FieldTypeDateTime, FieldTypeMultiSelect, FieldTypeCheckbox, FieldTypeRadio, FieldTypeLink},
+	FieldTypeRadio:       {FieldTypeText, FieldTypeTextArea, FieldTypeNumber, FieldTypeDate, FieldTypeDateTime, FieldTypeSelect, FieldTypeMultiSelect, FieldTypeCheckbox, FieldTypeLink},
+	FieldTypeLink:        {FieldTypeText, FieldTypeTextArea},
+	FieldTypeCheckbox:    {FieldTypeText, FieldTypeTextArea, FieldTypeNumber, FieldTypeSelect, FieldTypeRadio},
+	FieldTypeMultiSelect: {FieldTypeText, FieldTypeTextArea, FieldTypeSelect, FieldTypeRadio},
+}
+
+// CanConvertTo このフィールドを指定した種類に変換できるかどうかを返す
+func (f *AppField) CanConvertTo(fieldType FieldType) bool {
+	for _, t := range fieldConversions[FieldType(f.FieldType)] {
+		if t == fieldType {
+			return true
+		}
+	}
+	return false
+}
+
+// ConvertFieldRequest フィールドの種類の変換リクエストの構造体
+type ConvertFieldRequest struct {
+	FieldType string `json:"field_type" validate:"required,oneof=text textarea number date datetime select multiselect checkbox radio link"`
+	// Options 変換後のオプション（省略時は変換前のオプションを引き継ぐ）
+	Options FieldOptions `json:"options"`
+	// BackupFieldCode 変換できない値を変換前の文字列のまま保存する複数行テキストフィールドのコード
+	BackupFieldCode string `json:"backup_field_code" validate:"omitempty,min=1,max=64,fieldcode"`
+	// DiscardInvalid 変換できない値を空にして変換する（false で変換できない値がある場合は変換しない）
+	DiscardInvalid bool `json:"discard_invalid"`
+}
+
+// FieldConversion 動的テーブルのカラムの変換内容
+type FieldConversion struct {
+	// From 変換前のフィールド
+	From AppField
+	// To 変換後のフィールド（同じIDで種類・オプションを変更したもの）
+	To AppField
+	// Choices 変換後の選択肢（選択肢にない値は変換できない。空の場合は制限しない）
+	Choices []string
+	// Backup 変換できない値を保存するフィールド（保存しない場合はnil）
+	Backup *AppField
+	// DiscardInvalid Backup がない場合に変換できない値を空にして変換するかどうか
+	DiscardInvalid bool
+}
+
+// FieldConversionPreview フィールドの種類の変換を実行した場合の結果の見込み
+type FieldConversionPreview struct {
+	FieldType string `json:"field_type"`
+	// Total レコード数
+	Total int64 `json:"total"`
+	// Empty 値が未入力のレコード数
+	Empty int64 `json:"empty"`
+	// Convertible 値を変換できるレコード数
+	Convertible int64 `json:"convertible"`
+	// Unconvertible 値を変換できないレコード数
+	Unconvertible int64 `json:"unconvertible"`
+	// Samples 変換できない値の例（レコードIDの昇順）
+	Samples []FieldConversionSample `json:"samples"`
+}
+
+// FieldConversionSample 変換できない値の例
+type FieldConversionSample struct {
+	RecordID uint64 `json:"record_id"`
+	Value    string `json:"value"`
+}
+
+// ConvertFieldResponse フィールドの種類の変換結果のレスポンス構造体
+type ConvertFieldResponse struct {
+	Field *FieldResponse `json:"field"`
+	// Unconvertible 変換できずに空にした値の数
+	Unconvertible int64 `json:"unconvertible"`
+	// BackupField 変換できない値を保存したフィールド
+	BackupField *FieldResponse `json:"backup_field,omitempty"`
+}
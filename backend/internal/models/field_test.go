@@ -176,3 +176,84 @@ func TestAppField_GetPostgresColumnType(t *testing.T) {
 		})
 	}
 }
+
+func TestAppField_Formula(t *testing.T) {
+	tests := []struct {
+		resultType string
+		want       string
+	}{
+		{resultType: "number", want: "NUMERIC(18,4)"},
+		{resultType: "text", want: "TEXT"},
+		{resultType: "date", want: "DATE"},
+		{resultType: "datetime", want: "TIMESTAMP"},
+		{resultType: "checkbox", want: "BOOLEAN"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.resultType, func(t *testing.T) {
+			field := &models.AppField{FieldType: "formula", Options: models.FieldOptions{"result_type": tt.resultType}}
+			assert.Equal(t, models.FieldType(tt.resultType), field.FormulaResultType())
+			assert.Equal(t, tt.want, field.GetPostgresColumnType())
+			assert.True(t, field.IsComputed())
+			assert.True(t, field.HasColumn())
+		})
+	}
+
+	t.Run("not a formula", func(t *testing.T) {
+		field := &models.AppField{FieldType: "number", Options: models.FieldOptions{"result_type": "text"}}
+		assert.Empty(t, field.FormulaResultType())
+		assert.False(t, field.IsComputed())
+	})
+}
+
+func TestAppField_Rollup(t *testing.T) {
+	t.Run("parses options", func(t *testing.T) {
+		field := &models.AppField{FieldType: "rollup", Options: models.FieldOptions{
+			"app_id": float64(3), "link_field": "customer", "aggregation": "sum", "target_field": "amount",
+			"filters": []interface{}{map[string]interface{}{"field": "status", "operator": "eq", "value": "paid"}},
+		}}
+		opts, err := field.Rollup()
+		require.NoError(t, err)
+		assert.Equal(t, &models.RollupOptions{
+			AppID:       3,
+			LinkField:   "customer",
+			Aggregation: models.RollupSum,
+			TargetField: "amount",
+			Filters:     []models.FilterItem{{Field: "status", Operator: "eq", Value: "paid"}},
+		}, opts)
+		assert.True(t, opts.Aggregation.IsValid())
+		assert.True(t, field.IsComputed())
+		assert.False(t, field.HasColumn())
+	})
+
+	t.Run("not a rollup", func(t *testing.T) {
+		field := &models.AppField{FieldType: "number"}
+		_, err := field.Rollup()
+		assert.Error(t, err)
+	})
+
+	t.Run("invalid aggregation", func(t *testing.T) {
+		assert.False(t, models.RollupAggregation("median").IsValid())
+	})
+}
+
+func TestAppField_IsUnique(t *testing.T) {
+	tests := []struct {
+		name  string
+		field models.AppField
+		want  bool
+	}{
+		{name: "unique text", field: models.AppField{FieldType: "text", Options: models.FieldOptions{"unique": true}}, want: true},
+		{name: "unique reference", field: models.AppField{FieldType: "reference", Options: models.FieldOptions{"unique": true}}, want: true},
+		{name: "not unique", field: models.AppField{FieldType: "text", Options: models.FieldOptions{"unique": false}}},
+		{name: "no options", field: models.AppField{FieldType: "number"}},
+		{name: "non-boolean option", field: models.AppField{FieldType: "text", Options: models.FieldOptions{"unique": "true"}}},
+		{name: "unsupported type", field: models.AppField{FieldType: "multiselect", Options: models.FieldOptions{"unique": true}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.field.IsUnique())
+		})
+	}
+}
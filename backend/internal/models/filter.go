@@ -0,0 +1,57 @@
+package models
+
+// MaxFilterDepth フィルター式を入れ子にできる深さの上限
+const MaxFilterDepth = 5
+
+// フィルター条件の演算子
+const (
+	FilterOpEq          = "eq"
+	FilterOpNe          = "ne"
+	FilterOpGt          = "gt"
+	FilterOpGte         = "gte"
+	FilterOpLt          = "lt"
+	FilterOpLte         = "lte"
+	FilterOpLike        = "like"
+	FilterOpILike       = "ilike"
+	FilterOpIn          = "in"
+	FilterOpNotIn       = "not_in"
+	FilterOpBetween     = "between"
+	FilterOpIsNull      = "is_null"
+	FilterOpNotNull     = "not_null"
+	FilterOpInPeriod    = "in_period"
+	FilterOpContainsAny = "contains_any"
+	FilterOpContainsAll = "contains_all"
+)
+
+// FilterOperatorTakesValue 演算子が値を必要とするかどうかを返す
+func FilterOperatorTakesValue(op string) bool {
+	return op != FilterOpIsNull && op != FilterOpNotNull
+}
+
+// FilterOperatorTakesList 演算子が複数の値をとるかどうかを返す
+func FilterOperatorTakesList(op string) bool {
+	switch op {
+	case FilterOpIn, FilterOpNotIn, FilterOpBetween, FilterOpContainsAny, FilterOpContainsAll:
+		return true
+	}
+	return false
+}
+
+// FilterExpr AND・OR・NOTで入れ子にできる絞り込み条件を表す構造体
+// and・or・not のいずれか1つ、または単一の条件（field・operator・value）を指定する
+//
+//	{"or": [
+//	  {"field": "status", "operator": "eq", "value": "open"},
+//	  {"and": [{"field": "amount", "operator": "gte", "value": "1000"}, {"not": {"field": "owner", "operator": "is_null"}}]}
+//	]}
+type FilterExpr struct {
+	And []FilterExpr `json:"and,omitempty"`
+	Or  []FilterExpr `json:"or,omitempty"`
+	Not *FilterExpr  `json:"not,omitempty"`
+	FilterItem
+}
+
+// IsGroup 条件のグループ（and・or・not）かどうかを返す
+func (e *FilterExpr) IsGroup() bool {
+	return e.And != nil || e.Or != nil || e.Not != nil
+}
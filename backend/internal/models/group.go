@@ -0,0 +1,82 @@
+package models
+
+import (
+	"time"
+
+	"github.com/uptrace/bun"
+)
+
+// Group アプリ権限をまとめて付与するためのユーザーグループを表す構造体
+type Group struct {
+	bun.BaseModel `bun:"table:user_groups,alias:g"`
+
+	ID          uint64    `bun:"id,pk,autoincrement" json:"id"`
+	Name        string    `bun:"name,notnull,unique" json:"name"`
+	Description string    `bun:"description" json:"description"`
+	CreatedAt   time.Time `bun:"created_at,notnull,default:current_timestamp" json:"created_at"`
+	UpdatedAt   time.Time `bun:"updated_at,notnull,default:current_timestamp" json:"updated_at"`
+
+	// メンバー（リポジトリで別途読み込む）
+	Members []User `bun:"-" json:"members,omitempty"`
+}
+
+// GroupMember グループとユーザーの所属関係を表す構造体
+type GroupMember struct {
+	bun.BaseModel `bun:"table:user_group_members,alias:gm"`
+
+	GroupID   uint64    `bun:"group_id,pk" json:"group_id"`
+	UserID    uint64    `bun:"user_id,pk" json:"user_id"`
+	CreatedAt time.Time `bun:"created_at,notnull,default:current_timestamp" json:"created_at"`
+
+	// リレーション
+	Group *Group `bun:"rel:belongs-to,join:group_id=id" json:"-"`
+	User  *User  `bun:"rel:belongs-to,join:user_id=id" json:"-"`
+}
+
+// CreateGroupRequest グループ作成リクエストの構造体
+type CreateGroupRequest struct {
+	Name        string `json:"name" validate:"required,min=1,max=100"`
+	Description string `json:"description" validate:"max=500"`
+}
+
+// UpdateGroupRequest グループ更新リクエストの構造体
+type UpdateGroupRequest struct {
+	Name        string  `json:"name" validate:"omitempty,min=1,max=100"`
+	Description *string `json:"description" validate:"omitempty,max=500"`
+}
+
+// AddGroupMemberRequest グループメンバー追加リクエストの構造体
+type AddGroupMemberRequest struct {
+	UserID uint64 `json:"user_id" validate:"required"`
+}
+
+// GroupResponse グループデータのレスポンス構造体
+type GroupResponse struct {
+	ID          uint64         `json:"id"`
+	Name        string         `json:"name"`
+	Description string         `json:"description"`
+	Members     []UserResponse `json:"members"`
+	CreatedAt   time.Time      `json:"created_at"`
+	UpdatedAt   time.Time      `json:"updated_at"`
+}
+
+// ToResponse GroupをGroupResponseに変換する
+func (g *Group) ToResponse() *GroupResponse {
+	members := make([]UserResponse, len(g.Members))
+	for i := range g.Members {
+		members[i] = *g.Members[i].ToResponse()
+	}
+	return &GroupResponse{
+		ID:          g.ID,
+		Name:        g.Name,
+		Description: g.Description,
+		Members:     members,
+		CreatedAt:   g.CreatedAt,
+		UpdatedAt:   g.UpdatedAt,
+	}
+}
+
+// GroupListResponse グループ一覧のレスポンス構造体
+type GroupListResponse struct {
+	Groups []GroupResponse `json:"groups"`
+}
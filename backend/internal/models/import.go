@@ -0,0 +1,64 @@
+package models
+
+// ImportMode レコードインポートの書き込み方式を表す型
+type ImportMode string
+
+// インポート方式の定数
+const (
+	// ImportModeAtomic 1件でもエラーがあれば何も登録せず、全件を1つのトランザクションで登録する
+	ImportModeAtomic ImportMode = "atomic"
+	// ImportModeChunked エラー行を除外し、チャンクごとのトランザクションで登録する
+	ImportModeChunked ImportMode = "chunked"
+)
+
+// IsValid インポート方式が有効かどうかを確認
+func (m ImportMode) IsValid() bool {
+	return m == ImportModeAtomic || m == ImportModeChunked
+}
+
+// ImportRecordsRequest 読み込み済みのスプレッドシートからレコードをインポートするリクエスト
+type ImportRecordsRequest struct {
+	// Headers 1行目の見出し
+	Headers []string
+	// Rows 2行目以降のデータ行
+	Rows [][]string
+	// Mapping 見出しをキー、フィールドコードを値とする対応表（空の場合は見出しとフィールドコード・フィールド名を照合）
+	Mapping map[string]string
+	// DryRun trueの場合は検証のみ行い登録しない
+	DryRun bool
+	// Mode 書き込み方式
+	Mode ImportMode
+	// ChunkSize chunkedモードの1トランザクションあたりの行数
+	ChunkSize int
+	// SerialDates 数値を日付・日時フィールドのシリアル値として扱うかどうか（XLSX用）
+	SerialDates bool
+}
+
+// ImportRowError インポート時の1行分の入力エラー
+// Row はファイル上の行番号（見出し行を1行目とする）
+type ImportRowError struct {
+	Row    int         `json:"row"`
+	Errors FieldErrors `json:"errors"`
+}
+
+// ImportChunkResult chunkedモードの1チャンク分の登録結果
+type ImportChunkResult struct {
+	Index    int    `json:"index"`
+	StartRow int    `json:"start_row"`
+	EndRow   int    `json:"end_row"`
+	Imported int    `json:"imported"`
+	Error    string `json:"error,omitempty"`
+}
+
+// ImportResult レコードインポートの結果
+type ImportResult struct {
+	DryRun       bool                `json:"dry_run"`
+	Mode         ImportMode          `json:"mode"`
+	Mapping      map[string]string   `json:"mapping"`
+	TotalRows    int                 `json:"total_rows"`
+	ValidRows    int                 `json:"valid_rows"`
+	ImportedRows int                 `json:"imported_rows"`
+	FailedRows   int                 `json:"failed_rows"`
+	RowErrors    []ImportRowError    `json:"row_errors,omitempty"`
+	Chunks       []ImportChunkResult `json:"chunks,omitempty"`
+}
@@ -0,0 +1,89 @@
+package models
+
+import (
+	"time"
+
+	"github.com/uptrace/bun"
+)
+
+// MaxIndexFields 1つのインデックスに含められるフィールドの数の上限
+const MaxIndexFields = 5
+
+// AppIndex アプリの動的テーブルに作成したインデックスを表す構造体
+// インデックス本体はIndexNameの名前で動的テーブルに作成し、このテーブルには作成時の定義を保持する
+type AppIndex struct {
+	bun.BaseModel `bun:"table:app_indexes,alias:ai"`
+
+	ID        uint64 `bun:"id,pk,autoincrement" json:"id"`
+	AppID     uint64 `bun:"app_id,notnull" json:"app_id"`
+	IndexName string `bun:"index_name,notnull" json:"index_name"`
+	// FieldCodes インデックスのカラムとするフィールドコード（先頭から順に並べ替えのキーとなる）
+	FieldCodes []string `bun:"field_codes,type:jsonb" json:"field_codes"`
+	IsUnique   bool     `bun:"is_unique,notnull,default:false" json:"is_unique"`
+	// Filter 部分インデックスの対象とするレコードの条件（nilの場合は全レコード）
+	Filter    *FilterExpr `bun:"filter,type:jsonb" json:"filter,omitempty"`
+	CreatedBy *uint64     `bun:"created_by" json:"created_by,omitempty"`
+	CreatedAt time.Time   `bun:"created_at,notnull,default:current_timestamp" json:"created_at"`
+}
+
+// CreateIndexRequest インデックス作成リクエストの構造体
+type CreateIndexRequest struct {
+	FieldCodes []string `json:"field_codes" validate:"required,min=1,max=5,dive,required"`
+	Unique     bool     `json:"unique"`
+	// Filter 部分インデックスの条件（相対的な期間の条件は指定できない）
+	Filter *FilterExpr `json:"filter"`
+}
+
+// IndexUsage データベースの統計情報から求めたインデックスの大きさと利用状況
+type IndexUsage struct {
+	IndexName string
+	SizeBytes int64
+	Scans     int64
+	// Valid 作成に失敗したインデックスはfalseとなり、検索に使われない
+	Valid bool
+}
+
+// IndexResponse インデックスのレスポンス構造体
+type IndexResponse struct {
+	ID         uint64      `json:"id"`
+	AppID      uint64      `json:"app_id"`
+	IndexName  string      `json:"index_name"`
+	FieldCodes []string    `json:"field_codes"`
+	Unique     bool        `json:"unique"`
+	Filter     *FilterExpr `json:"filter,omitempty"`
+	SizeBytes  int64       `json:"size_bytes"`
+	Scans      int64       `json:"scans"`
+	Valid      bool        `json:"valid"`
+	CreatedBy  *uint64     `json:"created_by,omitempty"`
+	CreatedAt  time.Time   `json:"created_at"`
+}
+
+// ToResponse AppIndexと統計情報をIndexResponseに変換する
+func (i *AppIndex) ToResponse(usage IndexUsage) *IndexResponse {
+	return &IndexResponse{
+		ID:         i.ID,
+		AppID:      i.AppID,
+		IndexName:  i.IndexName,
+		FieldCodes: i.FieldCodes,
+		Unique:     i.IsUnique,
+		Filter:     i.Filter,
+		SizeBytes:  usage.SizeBytes,
+		Scans:      usage.Scans,
+		Valid:      usage.Valid,
+		CreatedBy:  i.CreatedBy,
+		CreatedAt:  i.CreatedAt,
+	}
+}
+
+// IndexSuggestion 保存済みのビューの絞り込み条件と並べ替えから提案するインデックス
+type IndexSuggestion struct {
+	FieldCodes []string `json:"field_codes"`
+	// Views このインデックスで速くなるビュー
+	Views []IndexSuggestionView `json:"views"`
+}
+
+// IndexSuggestionView インデックスの提案の根拠となったビュー
+type IndexSuggestionView struct {
+	ID   uint64 `json:"id"`
+	Name string `json:"name"`
+}
@@ -0,0 +1,151 @@
+package models
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/uptrace/bun"
+)
+
+// AppRole アプリに対する権限レベルを表す型
+type AppRole string
+
+// アプリ権限レベルの定数
+const (
+	// AppRoleViewer アプリ・フィールド・ビュー・レコードの閲覧のみ可能
+	AppRoleViewer AppRole = "viewer"
+	// AppRoleEditor 閲覧に加えてレコード・ビュー・グラフ設定の編集が可能
+	AppRoleEditor AppRole = "editor"
+	// AppRoleOwner 全ての操作（アプリ設定・フィールド・権限の管理を含む）が可能
+	AppRoleOwner AppRole = "owner"
+)
+
+// ValidAppRoles 有効なアプリ権限レベルのリスト
+var ValidAppRoles = []AppRole{
+	AppRoleViewer,
+	AppRoleEditor,
+	AppRoleOwner,
+}
+
+// IsValid アプリ権限レベルが有効かどうかを確認
+func (r AppRole) IsValid() bool {
+	return r.level() > 0
+}
+
+// Includes この権限レベルが指定された権限レベル以上かどうかを確認
+func (r AppRole) Includes(required AppRole) bool {
+	return r.level() >= required.level() && r.IsValid()
+}
+
+func (r AppRole) level() int {
+	switch r {
+	case AppRoleViewer:
+		return 1
+	case AppRoleEditor:
+		return 2
+	case AppRoleOwner:
+		return 3
+	default:
+		return 0
+	}
+}
+
+// AppAccess 呼び出し元ユーザーのアプリに対する実効権限を表す構造体
+type AppAccess struct {
+	// UserID 呼び出し元ユーザーID（システム内部の呼び出しの場合は0）
+	UserID uint64
+	// Role 実効権限レベル
+	Role AppRole
+	// OwnRecordsOnly 自分が作成したレコードのみ操作可能かどうか
+	OwnRecordsOnly bool
+}
+
+// CanAccessRecord 指定ユーザーが作成したレコードにアクセスできるかを確認する
+func (a *AppAccess) CanAccessRecord(createdBy uint64) bool {
+	return !a.OwnRecordsOnly || createdBy == a.UserID
+}
+
+// RecordFilters レコード取得・集計時に追加すべき作成者フィルターを返す
+func (a *AppAccess) RecordFilters() []FilterItem {
+	if !a.OwnRecordsOnly {
+		return nil
+	}
+	return []FilterItem{{
+		Field:    "created_by",
+		Operator: "eq",
+		Value:    strconv.FormatUint(a.UserID, 10),
+	}}
+}
+
+// AppPermission ユーザーまたはグループに付与されたアプリ権限を表す構造体
+// UserID と GroupID はどちらか一方のみが設定される
+type AppPermission struct {
+	bun.BaseModel `bun:"table:app_permissions,alias:ap"`
+
+	ID             uint64    `bun:"id,pk,autoincrement" json:"id"`
+	AppID          uint64    `bun:"app_id,notnull" json:"app_id"`
+	UserID         *uint64   `bun:"user_id" json:"user_id,omitempty"`
+	GroupID        *uint64   `bun:"group_id" json:"group_id,omitempty"`
+	Role           AppRole   `bun:"role,notnull" json:"role"`
+	OwnRecordsOnly bool      `bun:"own_records_only,notnull,default:false" json:"own_records_only"`
+	CreatedAt      time.Time `bun:"created_at,notnull,default:current_timestamp" json:"created_at"`
+	UpdatedAt      time.Time `bun:"updated_at,notnull,default:current_timestamp" json:"updated_at"`
+
+	// リレーション
+	User  *User  `bun:"rel:belongs-to,join:user_id=id" json:"user,omitempty"`
+	Group *Group `bun:"rel:belongs-to,join:group_id=id" json:"group,omitempty"`
+}
+
+// CreateAppPermissionRequest アプリ権限付与リクエストの構造体
+type CreateAppPermissionRequest struct {
+	UserID         *uint64 `json:"user_id" validate:"required_without=GroupID,excluded_with=GroupID"`
+	GroupID        *uint64 `json:"group_id" validate:"required_without=UserID,excluded_with=UserID"`
+	Role           AppRole `json:"role" validate:"required,oneof=viewer editor owner"`
+	OwnRecordsOnly bool    `json:"own_records_only"`
+}
+
+// UpdateAppPermissionRequest アプリ権限更新リクエストの構造体
+type UpdateAppPermissionRequest struct {
+	Role           AppRole `json:"role" validate:"omitempty,oneof=viewer editor owner"`
+	OwnRecordsOnly *bool   `json:"own_records_only"`
+}
+
+// AppPermissionResponse アプリ権限のレスポンス構造体
+type AppPermissionResponse struct {
+	ID             uint64    `json:"id"`
+	AppID          uint64    `json:"app_id"`
+	UserID         *uint64   `json:"user_id,omitempty"`
+	UserName       string    `json:"user_name,omitempty"`
+	GroupID        *uint64   `json:"group_id,omitempty"`
+	GroupName      string    `json:"group_name,omitempty"`
+	Role           AppRole   `json:"role"`
+	OwnRecordsOnly bool      `json:"own_records_only"`
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+// ToResponse AppPermissionをAppPermissionResponseに変換する
+func (p *AppPermission) ToResponse() *AppPermissionResponse {
+	resp := &AppPermissionResponse{
+		ID:             p.ID,
+		AppID:          p.AppID,
+		UserID:         p.UserID,
+		GroupID:        p.GroupID,
+		Role:           p.Role,
+		OwnRecordsOnly: p.OwnRecordsOnly,
+		CreatedAt:      p.CreatedAt,
+		UpdatedAt:      p.UpdatedAt,
+	}
+	if p.User != nil {
+		resp.UserName = p.User.Name
+	}
+	if p.Group != nil {
+		resp.GroupName = p.Group.Name
+	}
+	return resp
+}
+
+// AppPermissionListResponse アプリ権限一覧のレスポンス構造体
+type AppPermissionListResponse struct {
+	Permissions []AppPermissionResponse `json:"permissions"`
+}
@@ -0,0 +1,52 @@
+package models_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"nocode-app/backend/internal/models"
+)
+
+func TestAppRole_Includes(t *testing.T) {
+	tests := []struct {
+		role     models.AppRole
+		required models.AppRole
+		expected bool
+	}{
+		{models.AppRoleOwner, models.AppRoleViewer, true},
+		{models.AppRoleOwner, models.AppRoleOwner, true},
+		{models.AppRoleEditor, models.AppRoleViewer, true},
+		{models.AppRoleEditor, models.AppRoleOwner, false},
+		{models.AppRoleViewer, models.AppRoleEditor, false},
+		{models.AppRole(""), models.AppRoleViewer, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.role)+"_"+string(tt.required), func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.role.Includes(tt.required))
+		})
+	}
+}
+
+func TestAppRole_IsValid(t *testing.T) {
+	assert.True(t, models.AppRoleViewer.IsValid())
+	assert.True(t, models.AppRoleEditor.IsValid())
+	assert.True(t, models.AppRoleOwner.IsValid())
+	assert.False(t, models.AppRole("admin").IsValid())
+}
+
+func TestAppAccess_RecordFilters(t *testing.T) {
+	t.Run("unrestricted", func(t *testing.T) {
+		access := &models.AppAccess{UserID: 1, Role: models.AppRoleEditor}
+		assert.Nil(t, access.RecordFilters())
+		assert.True(t, access.CanAccessRecord(2))
+	})
+
+	t.Run("own records only", func(t *testing.T) {
+		access := &models.AppAccess{UserID: 1, Role: models.AppRoleEditor, OwnRecordsOnly: true}
+		assert.Equal(t, []models.FilterItem{{Field: "created_by", Operator: "eq", Value: "1"}}, access.RecordFilters())
+		assert.True(t, access.CanAccessRecord(1))
+		assert.False(t, access.CanAccessRecord(2))
+	})
+}
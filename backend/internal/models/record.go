@@ -1,11 +1,22 @@
 package models
 
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"strings"
+)
+
 // Pagination ページネーション情報を表す構造体
 type Pagination struct {
 	Page       int   `json:"page"`
 	Limit      int   `json:"limit"`
 	Total      int64 `json:"total"`
 	TotalPages int   `json:"total_pages"`
+	// TotalEstimated Total が統計情報から求めた推定値の場合はtrue
+	TotalEstimated bool `json:"total_estimated,omitempty"`
+	// NextCursor カーソル方式で次のページを取得するためのカーソル（次のページがない場合は空）
+	NextCursor string `json:"next_cursor,omitempty"`
 }
 
 // NewPagination 新しいPaginationインスタンスを作成する
@@ -22,9 +33,50 @@ func NewPagination(page, limit int, total int64) *Pagination {
 	}
 }
 
+// NewCursorPagination カーソル方式のページ送りのPaginationを作成する
+// ページ番号は使わないため Page は0、総件数を求めない場合（total が負）は Total・TotalPages を-1とする
+func NewCursorPagination(limit int, total int64, nextCursor string) *Pagination {
+	p := &Pagination{Limit: limit, Total: -1, TotalPages: -1, NextCursor: nextCursor}
+	if total >= 0 {
+		p.Total = total
+		p.TotalPages = NewPagination(1, limit, total).TotalPages
+	}
+	return p
+}
+
 // RecordData 動的なレコードデータを表す型
 type RecordData map[string]interface{}
 
+// Value RecordDataのdriver.Valuer実装
+func (rd RecordData) Value() (driver.Value, error) {
+	if rd == nil {
+		return "{}", nil
+	}
+	bytes, err := json.Marshal(rd)
+	if err != nil {
+		return nil, err
+	}
+	return string(bytes), nil
+}
+
+// Scan RecordDataのsql.Scanner実装
+func (rd *RecordData) Scan(value interface{}) error {
+	if value == nil {
+		*rd = nil
+		return nil
+	}
+	var data []byte
+	switch v := value.(type) {
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	default:
+		return errors.New("RecordDataのスキャンに失敗しました: サポートされていない型です")
+	}
+	return json.Unmarshal(data, rd)
+}
+
 // CreateRecordRequest レコード作成リクエストの構造体
 type CreateRecordRequest struct {
 	Data RecordData `json:"data" validate:"required"`
@@ -33,6 +85,8 @@ type CreateRecordRequest struct {
 // UpdateRecordRequest レコード更新リクエストの構造体
 type UpdateRecordRequest struct {
 	Data RecordData `json:"data" validate:"required"`
+	// Version 取得したときのバージョン（If-Matchヘッダーを送れない場合に指定する）
+	Version *int64 `json:"version,omitempty"`
 }
 
 // BulkCreateRecordRequest レコード一括作成リクエストの構造体
@@ -52,6 +106,22 @@ type RecordResponse struct {
 	CreatedBy uint64     `json:"created_by"`
 	CreatedAt string     `json:"created_at"`
 	UpdatedAt string     `json:"updated_at"`
+	// Version 楽観的排他制御に使うバージョン（更新・削除のときにIf-Matchヘッダーで送る）
+	Version int64 `json:"version"`
+	// References 参照フィールドのフィールドコードをキーとする参照先レコード（閲覧権限がある場合のみ）
+	References map[string]*RecordReference `json:"references,omitempty"`
+	// Search 全文検索（q）で取得した場合の一致度と一致箇所
+	Search *RecordSearchMatch `json:"search,omitempty"`
+	// Cursor カーソル方式で取得した場合の、このレコードの次から取得するためのカーソル
+	Cursor string `json:"-"`
+}
+
+// RecordReference 参照フィールドが指す他アプリのレコード
+type RecordReference struct {
+	AppID    uint64      `json:"app_id"`
+	RecordID uint64      `json:"record_id"`
+	Display  interface{} `json:"display"`
+	Data     RecordData  `json:"data"`
 }
 
 // RecordListResponse レコード一覧のレスポンス構造体
@@ -62,11 +132,24 @@ type RecordListResponse struct {
 
 // ChartDataRequest チャートデータリクエストの構造体
 type ChartDataRequest struct {
-	ChartType string       `json:"chart_type" validate:"required,oneof=bar horizontal_bar line pie doughnut scatter area"`
-	XAxis     ChartAxis    `json:"x_axis" validate:"required"`
-	YAxis     ChartAxis    `json:"y_axis" validate:"required"`
-	Filters   []FilterItem `json:"filters"`
-	GroupBy   string       `json:"group_by"`
+	ChartType string    `json:"chart_type" validate:"required,oneof=bar horizontal_bar line pie doughnut scatter area"`
+	XAxis     ChartAxis `json:"x_axis" validate:"required"`
+	YAxis     ChartAxis `json:"y_axis" validate:"required"`
+	// Measures y_axis に続けて集計する値（値ごとに1つのデータセットを返す）
+	Measures []ChartAxis  `json:"measures,omitempty" validate:"max=9,dive"`
+	Filters  []FilterItem `json:"filters"`
+	// Filter AND・OR・NOTを組み合わせた絞り込み条件（filters とはANDで結合する）
+	Filter *FilterExpr `json:"filter,omitempty" validate:"-"`
+	// TimeZone 相対的な期間（in_period）とX軸の日付の区切り（bucket）の基準とするタイムゾーン（IANA名、省略時はUTC）
+	TimeZone string `json:"timezone,omitempty"`
+	// GroupBy 系列に分けるフィールド（値ごとにデータセットを分ける）
+	GroupBy string `json:"group_by"`
+	// Sort X軸の並び順（label: 値の順（既定）、value: 最初の集計値の順）
+	Sort string `json:"sort,omitempty" validate:"omitempty,oneof=label value"`
+	// Order 並び順の向き（省略時は label は asc、value は desc）
+	Order string `json:"order,omitempty" validate:"omitempty,oneof=asc desc"`
+	// TopN 最初の集計値が大きい順にX軸の値を残す数（残りは「その他」にまとめる。0は制限なし）
+	TopN int `json:"top_n,omitempty" validate:"omitempty,min=1,max=100"`
 }
 
 // ChartAxis チャートの軸設定を表す構造体
@@ -74,13 +157,37 @@ type ChartAxis struct {
 	Field       string `json:"field"` // X軸および非count集計のY軸で必須
 	Label       string `json:"label"`
 	Aggregation string `json:"aggregation"` // count, sum, avg, min, max
+	// Bucket 日付・日時のX軸をまとめる単位（day, week, month, quarter, year）
+	Bucket string `json:"bucket,omitempty" validate:"omitempty,oneof=day week month quarter year"`
+	// DateOnly X軸が時刻を持たない日付のカラムかどうか（サービス層でフィールド定義から設定する）
+	DateOnly bool `json:"-"`
 }
 
+// ChartBucket X軸の日付をまとめる単位の定数
+const (
+	ChartBucketDay     = "day"
+	ChartBucketWeek    = "week"
+	ChartBucketMonth   = "month"
+	ChartBucketQuarter = "quarter"
+	ChartBucketYear    = "year"
+)
+
 // FilterItem フィルター条件を表す構造体
 type FilterItem struct {
 	Field    string `json:"field" validate:"required"`
-	Operator string `json:"operator" validate:"required,oneof=eq ne gt gte lt lte like in"`
+	Operator string `json:"operator" validate:"required,oneof=eq ne gt gte lt lte like ilike in not_in between is_null not_null in_period contains_any contains_all"`
 	Value    string `json:"value"`
+	// Values 複数の値をとる演算子（in・not_in・between・contains_any・contains_all）の値
+	Values []string `json:"values,omitempty"`
+}
+
+// ListValues 複数の値をとる演算子の値を返す
+// values を指定していない場合は value をカンマ区切りで分割する（クエリパラメータの filter=field:in:a,b 形式）
+func (f *FilterItem) ListValues() []string {
+	if len(f.Values) > 0 || f.Value == "" {
+		return f.Values
+	}
+	return strings.Split(f.Value, ",")
 }
 
 // ChartDataResponse チャートデータのレスポンス構造体
@@ -93,6 +200,8 @@ type ChartDataResponse struct {
 type ChartDataset struct {
 	Label string    `json:"label"`
 	Data  []float64 `json:"data"`
+	// Stack 積み上げるデータセットのまとまり（グループ化した場合に集計値のラベルを設定する）
+	Stack string `json:"stack,omitempty"`
 }
 
 // SaveChartConfigRequest チャート設定保存リクエストの構造体
@@ -110,6 +219,25 @@ type ErrorResponse struct {
 	Code    int    `json:"code,omitempty"`
 }
 
+// FieldErrors フィールドコードをキーとした入力エラーメッセージの集合
+type FieldErrors map[string]string
+
+// RecordFieldErrors 一括作成時の1レコード分の入力エラー
+type RecordFieldErrors struct {
+	Index  int         `json:"index"`
+	Errors FieldErrors `json:"errors"`
+}
+
+// ValidationErrorResponse レコード入力エラーのレスポンス構造体
+// 単一レコードの場合は Errors、一括作成の場合は RecordErrors に詳細が入る
+type ValidationErrorResponse struct {
+	Error        string              `json:"error"`
+	Message      string              `json:"message,omitempty"`
+	Code         int                 `json:"code,omitempty"`
+	Errors       FieldErrors         `json:"errors,omitempty"`
+	RecordErrors []RecordFieldErrors `json:"record_errors,omitempty"`
+}
+
 // SuccessResponse 成功レスポンスを表す構造体
 type SuccessResponse struct {
 	Message string      `json:"message"`
@@ -115,3 +115,9 @@ func TestRecordData(t *testing.T) {
 	assert.Equal(t, 123, data["field2"])
 	assert.Equal(t, true, data["field3"])
 }
+
+func TestFilterItem_ListValues(t *testing.T) {
+	assert.Equal(t, []string{"a", "b"}, (&models.FilterItem{Value: "a,b"}).ListValues())
+	assert.Equal(t, []string{"a,b", "c"}, (&models.FilterItem{Value: "x", Values: []string{"a,b", "c"}}).ListValues())
+	assert.Nil(t, (&models.FilterItem{}).ListValues())
+}
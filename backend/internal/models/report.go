@@ -0,0 +1,200 @@
+package models
+
+import (
+	"time"
+
+	"github.com/uptrace/bun"
+)
+
+// PivotDimension ピボット集計の行・列に並べるフィールド
+type PivotDimension struct {
+	Field string `json:"field" validate:"required"`
+	// Label 見出し（省略時はフィールドコード）
+	Label string `json:"label,omitempty"`
+	// Bucket 日付・日時のフィールドをまとめる単位（day, week, month, quarter, year）
+	Bucket string `json:"bucket,omitempty" validate:"omitempty,oneof=day week month quarter year"`
+	// DateOnly 時刻を持たない日付のカラムかどうか（サービス層でフィールド定義から設定する）
+	DateOnly bool `json:"-"`
+}
+
+// PivotMeasure ピボット集計の集計値
+type PivotMeasure struct {
+	// Field 集計するフィールド（count 以外で必須）
+	Field       string `json:"field"`
+	Aggregation string `json:"aggregation" validate:"required,oneof=count sum avg min max"`
+	// Label 見出し（省略時は集計方法とフィールド）
+	Label string `json:"label,omitempty"`
+}
+
+// PivotRequest ピボット集計（クロス集計）リクエストの構造体
+type PivotRequest struct {
+	Rows     []PivotDimension `json:"rows" validate:"required,min=1,max=3,dive"`
+	Columns  []PivotDimension `json:"columns" validate:"max=2,dive"`
+	Measures []PivotMeasure   `json:"measures" validate:"required,min=1,max=5,dive"`
+	Filters  []FilterItem     `json:"filters" validate:"dive"`
+	// Filter AND・OR・NOTを組み合わせた絞り込み条件（filters とはANDで結合する）
+	Filter *FilterExpr `json:"filter,omitempty" validate:"-"`
+	// TimeZone 相対的な期間（in_period）と日付の区切り（bucket）の基準とするタイムゾーン（IANA名、省略時はUTC）
+	TimeZone string `json:"timezone,omitempty"`
+}
+
+// PivotHeader ピボット集計の行・列の見出し
+type PivotHeader struct {
+	// Keys 行（列）のフィールドの値。小計・総計は集計したフィールドの分だけ短い
+	Keys []string `json:"keys"`
+	// Total 小計・総計かどうか
+	Total bool `json:"total"`
+}
+
+// PivotRow ピボット集計の1行
+type PivotRow struct {
+	PivotHeader
+	// Values 列ごとの集計値の一覧（[列][集計値]、該当するレコードがない場合はnull）
+	Values [][]*float64 `json:"values"`
+}
+
+// PivotResponse ピボット集計のレスポンス構造体
+// 行・列はフィールドの値の順に並べ、小計は対象の値の直後、総計は末尾に置く
+type PivotResponse struct {
+	RowFields    []string      `json:"row_fields"`
+	ColumnFields []string      `json:"column_fields"`
+	Measures     []string      `json:"measures"`
+	Columns      []PivotHeader `json:"columns"`
+	Rows         []PivotRow    `json:"rows"`
+}
+
+// ReportType 保存したレポートの種類を表す型
+type ReportType string
+
+// 保存したレポートの種類の定数
+const (
+	// ReportTypeChart グラフの集計結果
+	ReportTypeChart ReportType = "chart"
+	// ReportTypePivot ピボット集計の結果
+	ReportTypePivot ReportType = "pivot"
+	// ReportTypeRecords 絞り込んだレコードの一覧
+	ReportTypeRecords ReportType = "records"
+)
+
+// ReportFrequency 保存したレポートを配信する頻度を表す型
+type ReportFrequency string
+
+// 保存したレポートの配信頻度の定数
+const (
+	// ReportFrequencyNone 定期配信しない（手動で送信する）
+	ReportFrequencyNone ReportFrequency = ""
+	// ReportFrequencyDaily 毎日
+	ReportFrequencyDaily ReportFrequency = "daily"
+	// ReportFrequencyWeekly 毎週指定した曜日
+	ReportFrequencyWeekly ReportFrequency = "weekly"
+	// ReportFrequencyMonthly 毎月指定した日
+	ReportFrequencyMonthly ReportFrequency = "monthly"
+)
+
+// ReportDeliveryStatus レポートの配信結果を表す型
+type ReportDeliveryStatus string
+
+// レポートの配信結果の定数
+const (
+	// ReportDeliverySucceeded 全ての宛先に送信した
+	ReportDeliverySucceeded ReportDeliveryStatus = "succeeded"
+	// ReportDeliveryFailed 集計またはメールの送信に失敗した
+	ReportDeliveryFailed ReportDeliveryStatus = "failed"
+)
+
+// ReportDeliveryTrigger レポートを配信した契機を表す型
+type ReportDeliveryTrigger string
+
+// レポートの配信契機の定数
+const (
+	// ReportDeliveryTriggerSchedule 定期配信
+	ReportDeliveryTriggerSchedule ReportDeliveryTrigger = "schedule"
+	// ReportDeliveryTriggerManual 手動での送信
+	ReportDeliveryTriggerManual ReportDeliveryTrigger = "manual"
+)
+
+// RecordReportConfig 絞り込んだレコードの一覧のレポートの設定
+type RecordReportConfig struct {
+	Filters []FilterItem `json:"filters" validate:"dive"`
+	// Filter AND・OR・NOTを組み合わせた絞り込み条件（filters とはANDで結合する）
+	Filter *FilterExpr `json:"filter,omitempty" validate:"-"`
+	Sort   string      `json:"sort,omitempty" validate:"max=64"`
+	Order  string      `json:"order,omitempty" validate:"omitempty,oneof=asc desc"`
+	// TimeZone 相対的な期間（in_period）の基準とするタイムゾーン（IANA名、省略時はUTC）
+	TimeZone string `json:"timezone,omitempty"`
+}
+
+// SavedReportConfig 保存したレポートの集計の設定（レポートの種類に対応する1つだけを指定する）
+type SavedReportConfig struct {
+	Chart   *ChartDataRequest   `json:"chart,omitempty"`
+	Pivot   *PivotRequest       `json:"pivot,omitempty"`
+	Records *RecordReportConfig `json:"records,omitempty"`
+}
+
+// SavedReport 保存したレポート（グラフ・ピボット集計・レコードの一覧）と配信の設定を表す構造体
+type SavedReport struct {
+	bun.BaseModel `bun:"table:saved_reports,alias:sr"`
+
+	ID         uint64            `bun:"id,pk,autoincrement" json:"id"`
+	AppID      uint64            `bun:"app_id,notnull" json:"app_id"`
+	Name       string            `bun:"name,notnull" json:"name"`
+	ReportType ReportType        `bun:"report_type,notnull" json:"report_type"`
+	Config     SavedReportConfig `bun:"config,type:jsonb" json:"config"`
+	Frequency  ReportFrequency   `bun:"frequency,notnull" json:"frequency"`
+	// SendHour 配信する時刻（0〜23時）
+	SendHour int `bun:"send_hour,notnull" json:"send_hour"`
+	// Weekday 毎週配信する曜日（0: 日曜〜6: 土曜）
+	Weekday int `bun:"weekday,notnull" json:"weekday"`
+	// DayOfMonth 毎月配信する日（1〜28日）
+	DayOfMonth int        `bun:"day_of_month,notnull,default:1" json:"day_of_month"`
+	Timezone   string     `bun:"timezone,notnull" json:"timezone"`
+	Recipients []string   `bun:"recipients,type:jsonb" json:"recipients"`
+	IsActive   bool       `bun:"is_active,notnull,default:true" json:"is_active"`
+	NextRunAt  *time.Time `bun:"next_run_at" json:"next_run_at,omitempty"`
+	LastRunAt  *time.Time `bun:"last_run_at" json:"last_run_at,omitempty"`
+	CreatedBy  *uint64    `bun:"created_by" json:"created_by,omitempty"`
+	CreatedAt  time.Time  `bun:"created_at,notnull,default:current_timestamp" json:"created_at"`
+	UpdatedAt  time.Time  `bun:"updated_at,notnull,default:current_timestamp" json:"updated_at"`
+}
+
+// ReportDelivery 保存したレポートの1回の配信の履歴を表す構造体
+type ReportDelivery struct {
+	bun.BaseModel `bun:"table:report_deliveries,alias:rd"`
+
+	ID         uint64                `bun:"id,pk,autoincrement" json:"id"`
+	ReportID   uint64                `bun:"report_id,notnull" json:"report_id"`
+	AppID      uint64                `bun:"app_id,notnull" json:"app_id"`
+	Trigger    ReportDeliveryTrigger `bun:"trigger_type,notnull" json:"trigger"`
+	Status     ReportDeliveryStatus  `bun:"status,notnull" json:"status"`
+	Recipients []string              `bun:"recipients,type:jsonb" json:"recipients"`
+	Subject    string                `bun:"subject,notnull,default:''" json:"subject"`
+	// RowCount 添付したCSVのデータ行数
+	RowCount  int       `bun:"row_count,notnull,default:0" json:"row_count"`
+	Error     string    `bun:"error,notnull,default:''" json:"error,omitempty"`
+	CreatedAt time.Time `bun:"created_at,notnull,default:current_timestamp" json:"created_at"`
+}
+
+// SaveReportRequest レポートの保存（作成・更新）リクエストの構造体（更新時はレポート全体を置き換える）
+type SaveReportRequest struct {
+	Name       string            `json:"name" validate:"required,min=1,max=100"`
+	ReportType ReportType        `json:"report_type" validate:"required,oneof=chart pivot records"`
+	Config     SavedReportConfig `json:"config"`
+	Frequency  ReportFrequency   `json:"frequency" validate:"omitempty,oneof=daily weekly monthly"`
+	SendHour   int               `json:"send_hour" validate:"min=0,max=23"`
+	Weekday    int               `json:"weekday" validate:"min=0,max=6"`
+	DayOfMonth int               `json:"day_of_month" validate:"min=0,max=28"`
+	Timezone   string            `json:"timezone" validate:"max=64"`
+	Recipients []string          `json:"recipients" validate:"max=50,dive,email,max=254"`
+	IsActive   *bool             `json:"is_active"`
+}
+
+// SavedReportListResponse 保存したレポート一覧のレスポンス構造体
+type SavedReportListResponse struct {
+	Reports []SavedReport `json:"reports"`
+}
+
+// ReportDeliveryListResponse レポートの配信履歴一覧のレスポンス構造体
+type ReportDeliveryListResponse struct {
+	Deliveries []ReportDelivery `json:"deliveries"`
+	Pagination *Pagination      `json:"pagination"`
+}
@@ -0,0 +1,140 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"time"
+
+	"github.com/uptrace/bun"
+)
+
+// RevisionAction レコード変更履歴の操作種別を表す型
+type RevisionAction string
+
+// 操作種別の定数
+const (
+	RevisionActionCreate  RevisionAction = "create"
+	RevisionActionUpdate  RevisionAction = "update"
+	RevisionActionDelete  RevisionAction = "delete"
+	RevisionActionRevert  RevisionAction = "revert"
+	RevisionActionRestore RevisionAction = "restore"
+)
+
+// FieldChange 単一フィールドの変更前後の値を表す構造体
+type FieldChange struct {
+	Before interface{} `json:"before"`
+	After  interface{} `json:"after"`
+}
+
+// RecordChanges フィールドコードごとの変更内容をJSONとして保持する型
+type RecordChanges map[string]FieldChange
+
+// Value RecordChangesのdriver.Valuer実装
+func (rc RecordChanges) Value() (driver.Value, error) {
+	if rc == nil {
+		return "{}", nil
+	}
+	bytes, err := json.Marshal(rc)
+	if err != nil {
+		return nil, err
+	}
+	return string(bytes), nil
+}
+
+// Scan RecordChangesのsql.Scanner実装
+func (rc *RecordChanges) Scan(value interface{}) error {
+	if value == nil {
+		*rc = nil
+		return nil
+	}
+	var data []byte
+	switch v := value.(type) {
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	default:
+		return errors.New("RecordChangesのスキャンに失敗しました: サポートされていない型です")
+	}
+	return json.Unmarshal(data, rc)
+}
+
+// DiffRecordData 変更前後のレコードデータから値が異なるフィールドのみを抽出する
+// before または after が nil の場合は作成・削除として全フィールドを差分とする
+func DiffRecordData(before, after RecordData) RecordChanges {
+	changes := make(RecordChanges)
+	for code, afterValue := range after {
+		beforeValue := before[code]
+		if before != nil && reflect.DeepEqual(beforeValue, afterValue) {
+			continue
+		}
+		if before == nil && afterValue == nil {
+			continue
+		}
+		changes[code] = FieldChange{Before: beforeValue, After: afterValue}
+	}
+	for code, beforeValue := range before {
+		if _, ok := after[code]; ok || beforeValue == nil {
+			continue
+		}
+		changes[code] = FieldChange{Before: beforeValue, After: nil}
+	}
+	return changes
+}
+
+// RecordRevision 動的テーブルのレコードに対する1回の変更を表す構造体
+type RecordRevision struct {
+	bun.BaseModel `bun:"table:record_revisions,alias:rr"`
+
+	ID        uint64         `bun:"id,pk,autoincrement" json:"id"`
+	AppID     uint64         `bun:"app_id,notnull" json:"app_id"`
+	RecordID  uint64         `bun:"record_id,notnull" json:"record_id"`
+	Action    RevisionAction `bun:"action,notnull" json:"action"`
+	Changes   RecordChanges  `bun:"changes,type:jsonb" json:"changes"`
+	Snapshot  RecordData     `bun:"snapshot,type:jsonb" json:"snapshot"`
+	ChangedBy *uint64        `bun:"changed_by" json:"changed_by,omitempty"`
+	CreatedAt time.Time      `bun:"created_at,notnull,default:current_timestamp" json:"created_at"`
+	// RecordCreatedBy レコードの作成者（保存しない。変更を通知する相手の判定に使う）
+	RecordCreatedBy uint64 `bun:"-" json:"-"`
+
+	// リレーション
+	Actor *User `bun:"rel:belongs-to,join:changed_by=id" json:"actor,omitempty"`
+}
+
+// RecordRevisionResponse レコード変更履歴のレスポンス構造体
+// Snapshot は変更後のレコード全体（削除の場合は削除前の内容）
+type RecordRevisionResponse struct {
+	ID            uint64         `json:"id"`
+	RecordID      uint64         `json:"record_id"`
+	Action        RevisionAction `json:"action"`
+	Changes       RecordChanges  `json:"changes"`
+	Snapshot      RecordData     `json:"snapshot"`
+	ChangedBy     *uint64        `json:"changed_by,omitempty"`
+	ChangedByName string         `json:"changed_by_name,omitempty"`
+	CreatedAt     time.Time      `json:"created_at"`
+}
+
+// ToResponse RecordRevisionをRecordRevisionResponseに変換する
+func (r *RecordRevision) ToResponse() *RecordRevisionResponse {
+	resp := &RecordRevisionResponse{
+		ID:        r.ID,
+		RecordID:  r.RecordID,
+		Action:    r.Action,
+		Changes:   r.Changes,
+		Snapshot:  r.Snapshot,
+		ChangedBy: r.ChangedBy,
+		CreatedAt: r.CreatedAt,
+	}
+	if r.Actor != nil {
+		resp.ChangedByName = r.Actor.Name
+	}
+	return resp
+}
+
+// RecordHistoryResponse レコード変更履歴一覧のレスポンス構造体
+type RecordHistoryResponse struct {
+	Revisions  []RecordRevisionResponse `json:"revisions"`
+	Pagination *Pagination              `json:"pagination"`
+}
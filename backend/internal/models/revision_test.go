@@ -0,0 +1,35 @@
+package models_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"nocode-app/backend/internal/models"
+)
+
+func TestDiffRecordData(t *testing.T) {
+	t.Run("update keeps only changed fields", func(t *testing.T) {
+		before := models.RecordData{"name": "A", "amount": "10", "note": nil}
+		after := models.RecordData{"name": "B", "amount": "10", "note": nil}
+
+		changes := models.DiffRecordData(before, after)
+		assert.Equal(t, models.RecordChanges{"name": {Before: "A", After: "B"}}, changes)
+	})
+
+	t.Run("create lists non-empty values", func(t *testing.T) {
+		changes := models.DiffRecordData(nil, models.RecordData{"name": "A", "note": nil})
+		assert.Equal(t, models.RecordChanges{"name": {Before: nil, After: "A"}}, changes)
+	})
+
+	t.Run("delete lists non-empty values", func(t *testing.T) {
+		changes := models.DiffRecordData(models.RecordData{"name": "A", "note": nil}, nil)
+		assert.Equal(t, models.RecordChanges{"name": {Before: "A", After: nil}}, changes)
+	})
+
+	t.Run("array values are compared deeply", func(t *testing.T) {
+		before := models.RecordData{"tags": []interface{}{"a", "b"}}
+		after := models.RecordData{"tags": []interface{}{"a", "b"}}
+		assert.Empty(t, models.DiffRecordData(before, after))
+	})
+}
@@ -0,0 +1,202 @@
+package models
+
+import (
+	"html"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// 全文検索用カラムの形式
+const (
+	// SearchConfigSimple 単語を原形に変換しないテキスト検索設定（新しいアプリの既定値）
+	SearchConfigSimple = "simple"
+	// SearchConfigTrigram pg_trgm のトライグラムで部分一致を検索する（日本語など単語を空白で区切らない言語向け）
+	SearchConfigTrigram = "trigram"
+)
+
+// searchConfigs 全文検索用カラムに指定できる形式（PostgreSQL 組み込みのテキスト検索設定と trigram）
+var searchConfigs = map[string]bool{
+	"simple": true, "arabic": true, "armenian": true, "basque": true, "catalan": true, "danish": true,
+	"dutch": true, "english": true, "finnish": true, "french": true, "german": true, "greek": true,
+	"hindi": true, "hungarian": true, "indonesian": true, "irish": true, "italian": true, "lithuanian": true,
+	"nepali": true, "norwegian": true, "portuguese": true, "romanian": true, "russian": true, "serbian": true,
+	"spanish": true, "swedish": true, "tamil": true, "turkish": true, "yiddish": true,
+	SearchConfigTrigram: true,
+}
+
+// IsValidSearchConfig 全文検索用カラムの形式が有効かどうかを返す
+func IsValidSearchConfig(config string) bool {
+	return searchConfigs[config]
+}
+
+// IsSearchableField 全文検索（q）の対象になるフィールドかどうかを返す
+func IsSearchableField(field *AppField) bool {
+	switch FieldType(field.FieldType) {
+	case FieldTypeText, FieldTypeTextArea:
+		return true
+	}
+	return false
+}
+
+// RecordSearchMatch 全文検索（q）で一致したレコードの一致度と一致箇所
+type RecordSearchMatch struct {
+	// Rank 一致度（大きいほどよく一致する。全文検索用カラムを使わない検索では0）
+	Rank float64 `json:"rank"`
+	// Highlight 一致した箇所を <mark> で囲んだ抜粋（HTMLエスケープ済み）
+	Highlight string `json:"highlight"`
+}
+
+// 抜粋の中で一致箇所の前後を示す区切り文字（HTMLに変換するまでの一時的なもの）
+const (
+	HighlightStart = "\x02"
+	HighlightStop  = "\x03"
+)
+
+const (
+	// highlightContext 抜粋に含める最初の一致箇所より前の文字数
+	highlightContext = 30
+	// highlightLength 抜粋の最大文字数
+	highlightLength = 120
+)
+
+// FormatHighlight 区切り文字で一致箇所を示した抜粋をHTMLエスケープし、一致箇所を <mark> で囲む
+func FormatHighlight(raw string) string {
+	escaped := html.EscapeString(raw)
+	escaped = strings.ReplaceAll(escaped, HighlightStart, "<mark>")
+	return strings.ReplaceAll(escaped, HighlightStop, "</mark>")
+}
+
+// HighlightSnippet 最初にキーワードを含む文字列から、一致箇所を <mark> で囲んだ抜粋を作成する
+// 大文字・小文字は区別しない。いずれの文字列もキーワードを含まない場合は空文字を返す
+func HighlightSnippet(texts []string, terms []string) string {
+	for _, text := range texts {
+		runes := []rune(text)
+		lower := lowerRunes(runes)
+
+		marked := make([]bool, len(runes))
+		first := -1
+		for _, term := range terms {
+			t := lowerRunes([]rune(term))
+			if len(t) == 0 {
+				continue
+			}
+			for i := 0; i+len(t) <= len(lower); i++ {
+				if !slices.Equal(lower[i:i+len(t)], t) {
+					continue
+				}
+				for j := i; j < i+len(t); j++ {
+					marked[j] = true
+				}
+				if first < 0 || i < first {
+					first = i
+				}
+			}
+		}
+		if first < 0 {
+			continue
+		}
+
+		start := max(first-highlightContext, 0)
+		end := min(start+highlightLength, len(runes))
+		var b strings.Builder
+		if start > 0 {
+			b.WriteString("…")
+		}
+		inMark := false
+		for i := start; i < end; i++ {
+			if marked[i] != inMark {
+				inMark = marked[i]
+				if inMark {
+					b.WriteString(HighlightStart)
+				} else {
+					b.WriteString(HighlightStop)
+				}
+			}
+			// 元の文字列に含まれる区切り文字は一致箇所と区別できないため除く
+			if r := runes[i]; r != '\x02' && r != '\x03' {
+				b.WriteRune(r)
+			}
+		}
+		if inMark {
+			b.WriteString(HighlightStop)
+		}
+		if end < len(runes) {
+			b.WriteString("…")
+		}
+		return FormatHighlight(b.String())
+	}
+	return ""
+}
+
+// lowerRunes 文字ごとに小文字に変換する（文字数を変えない）
+func lowerRunes(runes []rune) []rune {
+	lower := make([]rune, len(runes))
+	for i, r := range runes {
+		lower[i] = unicode.ToLower(r)
+	}
+	return lower
+}
+
+// GlobalSearchResponse 全アプリ横断検索のレスポンス
+type GlobalSearchResponse struct {
+	Query string `json:"query"`
+	// Results 一致したアプリごとの結果（アプリ名・フィールド名・レコードのいずれも一致しないアプリは含まない）
+	Results []GlobalSearchAppResult `json:"results"`
+	// Partial 時間内に検索を終えられなかったアプリがある場合はtrue
+	Partial bool `json:"partial"`
+}
+
+// GlobalSearchAppResult 全アプリ横断検索のアプリごとの結果
+type GlobalSearchAppResult struct {
+	AppID   uint64 `json:"app_id"`
+	AppName string `json:"app_name"`
+	AppIcon string `json:"app_icon"`
+	// NameMatched アプリ名がすべての語を含むかどうか
+	NameMatched bool                    `json:"name_matched"`
+	Fields      []GlobalSearchFieldHit  `json:"fields"`
+	Records     []GlobalSearchRecordHit `json:"records"`
+	// RecordTotal 一致したレコードの総数（Records は一致度の高い順に上位のみ）
+	RecordTotal int64 `json:"record_total"`
+}
+
+// GlobalSearchFieldHit 全アプリ横断検索でフィールド名またはフィールドコードが一致したフィールド
+type GlobalSearchFieldHit struct {
+	FieldCode string `json:"field_code"`
+	FieldName string `json:"field_name"`
+}
+
+// GlobalSearchRecordHit 全アプリ横断検索で一致したレコード
+type GlobalSearchRecordHit struct {
+	RecordID uint64 `json:"record_id"`
+	// FieldCode・FieldName キーワードを含むフィールド（語形変化による一致などで特定できない場合は空）
+	FieldCode string  `json:"field_code,omitempty"`
+	FieldName string  `json:"field_name,omitempty"`
+	Rank      float64 `json:"rank"`
+	Highlight string  `json:"highlight"`
+}
+
+// ContainsAllTerms 文字列がすべての語を含むかどうかを返す（大文字・小文字は区別しない）
+func ContainsAllTerms(s string, terms []string) bool {
+	if len(terms) == 0 {
+		return false
+	}
+	lower := strings.ToLower(s)
+	for _, term := range terms {
+		if !strings.Contains(lower, strings.ToLower(term)) {
+			return false
+		}
+	}
+	return true
+}
+
+// ContainsAnyTerm 文字列がいずれかの語を含むかどうかを返す（大文字・小文字は区別しない）
+func ContainsAnyTerm(s string, terms []string) bool {
+	lower := strings.ToLower(s)
+	for _, term := range terms {
+		if term != "" && strings.Contains(lower, strings.ToLower(term)) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,53 @@
+package models_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"nocode-app/backend/internal/models"
+)
+
+func TestHighlightSnippet(t *testing.T) {
+	t.Run("marks every term case-insensitively", func(t *testing.T) {
+		got := models.HighlightSnippet([]string{"", "Go and go-kit <b>"}, []string{"GO"})
+		assert.Equal(t, "<mark>Go</mark> and <mark>go</mark>-kit &lt;b&gt;", got)
+	})
+
+	t.Run("japanese text is trimmed around the first match", func(t *testing.T) {
+		text := "あいうえおかきくけこさしすせそたちつてとなにぬねのはひふへほまみむめもやゆよらりるれろわをん株式会社ABCとの商談"
+		got := models.HighlightSnippet([]string{text}, []string{"商談", "abc"})
+		assert.Equal(t, "…なにぬねのはひふへほまみむめもやゆよらりるれろわをん株式会社<mark>ABC</mark>との<mark>商談</mark>", got)
+	})
+
+	t.Run("no match", func(t *testing.T) {
+		assert.Empty(t, models.HighlightSnippet([]string{"hello"}, []string{"world"}))
+	})
+
+	t.Run("marker characters in the source are dropped", func(t *testing.T) {
+		got := models.HighlightSnippet([]string{"a" + models.HighlightStart + "b c"}, []string{"c"})
+		assert.Equal(t, "ab <mark>c</mark>", got)
+	})
+}
+
+func TestFormatHighlight(t *testing.T) {
+	raw := "<script>" + models.HighlightStart + "x" + models.HighlightStop + " & y"
+	assert.Equal(t, "&lt;script&gt;<mark>x</mark> &amp; y", models.FormatHighlight(raw))
+}
+
+func TestIsValidSearchConfig(t *testing.T) {
+	assert.True(t, models.IsValidSearchConfig("simple"))
+	assert.True(t, models.IsValidSearchConfig("english"))
+	assert.True(t, models.IsValidSearchConfig("trigram"))
+	assert.False(t, models.IsValidSearchConfig("english'); DROP TABLE apps; --"))
+	assert.False(t, models.IsValidSearchConfig(""))
+}
+
+func TestContainsTerms(t *testing.T) {
+	assert.True(t, models.ContainsAllTerms("Customer 顧客管理", []string{"customer", "顧客"}))
+	assert.False(t, models.ContainsAllTerms("Customer", []string{"customer", "顧客"}))
+	assert.False(t, models.ContainsAllTerms("Customer", nil))
+
+	assert.True(t, models.ContainsAnyTerm("顧客から電話", []string{"メール", "電話"}))
+	assert.False(t, models.ContainsAnyTerm("顧客から電話", []string{"メール", ""}))
+}
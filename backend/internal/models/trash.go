@@ -0,0 +1,78 @@
+package models
+
+import (
+	"time"
+
+	"github.com/uptrace/bun"
+)
+
+// DeletedRecord ごみ箱に移したレコードを表す構造体
+type DeletedRecord struct {
+	bun.BaseModel `bun:"table:deleted_records,alias:dr"`
+
+	ID       uint64 `bun:"id,pk,autoincrement" json:"id"`
+	AppID    uint64 `bun:"app_id,notnull" json:"app_id"`
+	RecordID uint64 `bun:"record_id,notnull" json:"record_id"`
+	// Data 削除時の動的テーブルの全カラムの値（復元時にそのまま挿入し直す）
+	Data      RecordData `bun:"data,type:jsonb,notnull" json:"data"`
+	CreatedBy uint64     `bun:"created_by,notnull" json:"created_by"`
+	DeletedBy *uint64    `bun:"deleted_by" json:"deleted_by,omitempty"`
+	DeletedAt time.Time  `bun:"deleted_at,notnull,default:current_timestamp" json:"deleted_at"`
+}
+
+// TrashedRecordResponse ごみ箱のレコードのレスポンス構造体
+type TrashedRecordResponse struct {
+	ID uint64 `json:"id"`
+	// Data 現在のフィールドの削除時の値
+	Data      RecordData `json:"data"`
+	CreatedBy uint64     `json:"created_by"`
+	DeletedBy *uint64    `json:"deleted_by,omitempty"`
+	DeletedAt time.Time  `json:"deleted_at"`
+	// PurgeAt 完全に削除される日時
+	PurgeAt time.Time `json:"purge_at"`
+}
+
+// TrashedFieldResponse ごみ箱のフィールドのレスポンス構造体
+type TrashedFieldResponse struct {
+	FieldResponse
+	DeletedAt time.Time `json:"deleted_at"`
+	PurgeAt   time.Time `json:"purge_at"`
+}
+
+// AppTrashResponse アプリのごみ箱のレスポンス構造体
+// フィールドはオーナーのみに返し、レコードはページネーションする
+type AppTrashResponse struct {
+	Fields     []TrashedFieldResponse  `json:"fields"`
+	Records    []TrashedRecordResponse `json:"records"`
+	Pagination *Pagination             `json:"pagination"`
+}
+
+// TrashedAppResponse ごみ箱のアプリのレスポンス構造体
+type TrashedAppResponse struct {
+	AppResponse
+	DeletedAt time.Time `json:"deleted_at"`
+	PurgeAt   time.Time `json:"purge_at"`
+}
+
+// TrashedAppListResponse ごみ箱のアプリ一覧のレスポンス構造体
+type TrashedAppListResponse struct {
+	Apps []TrashedAppResponse `json:"apps"`
+}
+
+// ToResponse ごみ箱のレコードを、指定したフィールドの削除時の値のみを含むレスポンスに変換する
+func (r *DeletedRecord) ToResponse(fields []AppField, purgeAt time.Time) *TrashedRecordResponse {
+	data := make(RecordData, len(fields))
+	for i := range fields {
+		if value, ok := r.Data[fields[i].FieldCode]; ok {
+			data[fields[i].FieldCode] = value
+		}
+	}
+	return &TrashedRecordResponse{
+		ID:        r.RecordID,
+		Data:      data,
+		CreatedBy: r.CreatedBy,
+		DeletedBy: r.DeletedBy,
+		DeletedAt: r.DeletedAt,
+		PurgeAt:   purgeAt,
+	}
+}
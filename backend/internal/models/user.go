@@ -60,6 +60,12 @@ type AuthResponse struct {
 	User  *UserResponse `json:"user"`
 }
 
+// RealtimeTokenResponse アプリの変更の購読にのみ使える短期間のトークンのレスポンス
+type RealtimeTokenResponse struct {
+	Token     string    `json:"token"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
 // UpdateProfileRequest プロフィール更新リクエストの構造体
 type UpdateProfileRequest struct {
 	Name string `json:"name" validate:"required,min=1,max=100"`
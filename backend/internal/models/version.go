@@ -0,0 +1,20 @@
+package models
+
+import "time"
+
+// VersionOf 更新日時から楽観的排他制御に使うバージョンを求める
+// 更新日時はデータベースにマイクロ秒単位で保存され、更新のたびにトリガで設定されるため、
+// マイクロ秒単位のUNIX時刻をバージョンとする
+func VersionOf(updatedAt time.Time) int64 {
+	return updatedAt.UnixMicro()
+}
+
+// VersionConflictResponse 更新・削除の対象がクライアントの取得後に変更されていた場合のレスポンス構造体
+// Current はサーバーにある現在の内容
+type VersionConflictResponse struct {
+	Error   string      `json:"error"`
+	Message string      `json:"message,omitempty"`
+	Code    int         `json:"code,omitempty"`
+	Version int64       `json:"version"`
+	Current interface{} `json:"current"`
+}
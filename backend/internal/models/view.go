@@ -75,6 +75,8 @@ type UpdateViewRequest struct {
 	Name      string     `json:"name" validate:"omitempty,min=1,max=100"`
 	Config    ViewConfig `json:"config"`
 	IsDefault *bool      `json:"is_default"`
+	// Version 取得したときのバージョン（If-Matchヘッダーを送れない場合に指定する）
+	Version *int64 `json:"version,omitempty"`
 }
 
 // ViewResponse ビューデータのレスポンス構造体
@@ -87,6 +89,7 @@ type ViewResponse struct {
 	IsDefault bool       `json:"is_default"`
 	CreatedAt time.Time  `json:"created_at"`
 	UpdatedAt time.Time  `json:"updated_at"`
+	Version   int64      `json:"version"`
 }
 
 // ToResponse AppViewをViewResponseに変換する
@@ -100,6 +103,7 @@ func (v *AppView) ToResponse() *ViewResponse {
 		IsDefault: v.IsDefault,
 		CreatedAt: v.CreatedAt,
 		UpdatedAt: v.UpdatedAt,
+		Version:   VersionOf(v.UpdatedAt),
 	}
 }
 
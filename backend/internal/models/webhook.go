@@ -0,0 +1,166 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/uptrace/bun"
+)
+
+// WebhookEventType Webhookで通知するイベントの種類を表す型
+type WebhookEventType string
+
+// Webhookイベントの種類の定数
+const (
+	WebhookEventRecordCreated WebhookEventType = "record.created"
+	WebhookEventRecordUpdated WebhookEventType = "record.updated"
+	WebhookEventRecordDeleted WebhookEventType = "record.deleted"
+	WebhookEventFieldCreated  WebhookEventType = "field.created"
+	WebhookEventFieldUpdated  WebhookEventType = "field.updated"
+	WebhookEventFieldDeleted  WebhookEventType = "field.deleted"
+	WebhookEventAppDeleted    WebhookEventType = "app.deleted"
+	// WebhookEventAutomationTriggered 自動化ルールのアクションから送信するイベント（購読ではなくルールで送信先を指定する）
+	WebhookEventAutomationTriggered WebhookEventType = "automation.triggered"
+)
+
+// IsValid イベントの種類が有効かどうかを確認
+func (e WebhookEventType) IsValid() bool {
+	switch e {
+	case WebhookEventRecordCreated, WebhookEventRecordUpdated, WebhookEventRecordDeleted,
+		WebhookEventFieldCreated, WebhookEventFieldUpdated, WebhookEventFieldDeleted, WebhookEventAppDeleted:
+		return true
+	}
+	return false
+}
+
+// WebhookDeliveryStatus Webhook配信の状態を表す型
+type WebhookDeliveryStatus string
+
+// 配信状態の定数
+const (
+	// WebhookDeliveryPending 未送信または再送待ち
+	WebhookDeliveryPending WebhookDeliveryStatus = "pending"
+	// WebhookDeliverySucceeded 送信成功（2xxの応答）
+	WebhookDeliverySucceeded WebhookDeliveryStatus = "succeeded"
+	// WebhookDeliveryFailed 再送回数の上限に達して失敗
+	WebhookDeliveryFailed WebhookDeliveryStatus = "failed"
+)
+
+// Webhook アプリのイベントを通知するエンドポイントを表す構造体
+type Webhook struct {
+	bun.BaseModel `bun:"table:webhooks,alias:wh"`
+
+	ID        uint64             `bun:"id,pk,autoincrement" json:"id"`
+	AppID     uint64             `bun:"app_id,notnull" json:"app_id"`
+	URL       string             `bun:"url,notnull" json:"url"`
+	Secret    string             `bun:"secret,notnull" json:"-"`
+	Events    []WebhookEventType `bun:"events,type:jsonb" json:"events"`
+	IsActive  bool               `bun:"is_active,notnull,default:true" json:"is_active"`
+	CreatedBy *uint64            `bun:"created_by" json:"created_by,omitempty"`
+	CreatedAt time.Time          `bun:"created_at,notnull,default:current_timestamp" json:"created_at"`
+	UpdatedAt time.Time          `bun:"updated_at,notnull,default:current_timestamp" json:"updated_at"`
+}
+
+// Subscribes 指定したイベントを通知するかどうかを確認する
+func (w *Webhook) Subscribes(event WebhookEventType) bool {
+	if !w.IsActive {
+		return false
+	}
+	for _, e := range w.Events {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
+
+// WebhookDelivery Webhookの1回のイベント配信（配信キューと配信ログを兼ねる）を表す構造体
+// 送信先と署名はイベント発生時に確定させ、Webhookやアプリの削除後も配信できるようにする
+type WebhookDelivery struct {
+	bun.BaseModel `bun:"table:webhook_deliveries,alias:wd"`
+
+	ID             uint64                `bun:"id,pk,autoincrement" json:"id"`
+	WebhookID      *uint64               `bun:"webhook_id" json:"webhook_id,omitempty"`
+	AppID          uint64                `bun:"app_id,notnull" json:"app_id"`
+	Event          WebhookEventType      `bun:"event,notnull" json:"event"`
+	URL            string                `bun:"url,notnull" json:"url"`
+	Payload        string                `bun:"payload,notnull" json:"-"`
+	Signature      string                `bun:"signature,notnull" json:"-"`
+	Status         WebhookDeliveryStatus `bun:"status,notnull,default:'pending'" json:"status"`
+	Attempts       int                   `bun:"attempts,notnull,default:0" json:"attempts"`
+	NextAttemptAt  time.Time             `bun:"next_attempt_at,notnull,default:current_timestamp" json:"next_attempt_at"`
+	LastStatusCode *int                  `bun:"last_status_code" json:"last_status_code,omitempty"`
+	LastError      string                `bun:"last_error,notnull,default:''" json:"last_error,omitempty"`
+	DeliveredAt    *time.Time            `bun:"delivered_at" json:"delivered_at,omitempty"`
+	CreatedAt      time.Time             `bun:"created_at,notnull,default:current_timestamp" json:"created_at"`
+}
+
+// WebhookEvent Webhookで通知するイベントを表す構造体（送信するペイロードの形式）
+type WebhookEvent struct {
+	Event      WebhookEventType `json:"event"`
+	AppID      uint64           `json:"app_id"`
+	OccurredAt time.Time        `json:"occurred_at"`
+	Data       interface{}      `json:"data"`
+}
+
+// WebhookRecordData レコードのイベントで通知するデータの構造体
+// Data は変更後のレコード（削除の場合は削除前の内容）
+type WebhookRecordData struct {
+	RecordID  uint64        `json:"record_id"`
+	Data      RecordData    `json:"data"`
+	Changes   RecordChanges `json:"changes,omitempty"`
+	CreatedBy uint64        `json:"created_by,omitempty"`
+	ChangedBy *uint64       `json:"changed_by,omitempty"`
+}
+
+// WebhookAppData アプリ削除のイベントで通知するデータの構造体
+type WebhookAppData struct {
+	ID   uint64 `json:"id"`
+	Name string `json:"name"`
+}
+
+// CreateWebhookRequest Webhook登録リクエストの構造体
+type CreateWebhookRequest struct {
+	URL    string             `json:"url" validate:"required,url,max=2000"`
+	Events []WebhookEventType `json:"events" validate:"required,min=1,dive,oneof=record.created record.updated record.deleted field.created field.updated field.deleted app.deleted"`
+}
+
+// UpdateWebhookRequest Webhook更新リクエストの構造体
+type UpdateWebhookRequest struct {
+	URL          string             `json:"url" validate:"omitempty,url,max=2000"`
+	Events       []WebhookEventType `json:"events" validate:"omitempty,min=1,dive,oneof=record.created record.updated record.deleted field.created field.updated field.deleted app.deleted"`
+	IsActive     *bool              `json:"is_active"`
+	RotateSecret bool               `json:"rotate_secret"`
+}
+
+// WebhookResponse Webhookのレスポンス構造体
+// Secret は登録時と再発行時のみ返す
+type WebhookResponse struct {
+	Webhook
+	Secret string `json:"secret,omitempty"`
+}
+
+// WebhookListResponse Webhook一覧のレスポンス構造体
+type WebhookListResponse struct {
+	Webhooks []Webhook `json:"webhooks"`
+}
+
+// WebhookDeliveryResponse Webhook配信ログのレスポンス構造体
+type WebhookDeliveryResponse struct {
+	WebhookDelivery
+	Payload json.RawMessage `json:"payload"`
+}
+
+// ToResponse WebhookDeliveryをWebhookDeliveryResponseに変換する
+func (d *WebhookDelivery) ToResponse() *WebhookDeliveryResponse {
+	return &WebhookDeliveryResponse{
+		WebhookDelivery: *d,
+		Payload:         json.RawMessage(d.Payload),
+	}
+}
+
+// WebhookDeliveryListResponse Webhook配信ログ一覧のレスポンス構造体
+type WebhookDeliveryListResponse struct {
+	Deliveries []WebhookDeliveryResponse `json:"deliveries"`
+	Pagination *Pagination               `json:"pagination"`
+}
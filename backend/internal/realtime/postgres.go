@@ -0,0 +1,150 @@
+package realtime
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/uptrace/bun"
+)
+
+// PostgresChannel イベントを通知するPostgreSQLのチャネル名
+const PostgresChannel = "nocode_app_events"
+
+const (
+	// maxNotifyPayload NOTIFYで送れるペイロードの上限（PostgreSQLの上限8000バイトより少し小さくする）
+	maxNotifyPayload = 7900
+	// listenerPingInterval 通知がない間も接続が切れていないか確認する間隔
+	listenerPingInterval = 90 * time.Second
+	// listenerMinBackoff・listenerMaxBackoff 通知の受信に失敗したときに接続し直すまでの最短・最長の間隔
+	listenerMinBackoff = time.Second
+	listenerMaxBackoff = time.Minute
+)
+
+// errListenerClosed 通知の受信の接続が閉じられた場合のエラー
+var errListenerClosed = errors.New("リアルタイム通知の受信の接続が閉じられました")
+
+var _ Notifier = (*PostgresNotifier)(nil)
+
+// PostgresNotifier PostgreSQLの NOTIFY でイベントを通知する
+// 同じデータベースを使うすべてのサーバーが ListenPostgres で受け取り、それぞれの購読者に配信する
+type PostgresNotifier struct {
+	db bun.IDB
+}
+
+// NewPostgresNotifier 新しいPostgresNotifierを作成する
+func NewPostgresNotifier(db bun.IDB) *PostgresNotifier {
+	return &PostgresNotifier{db: db}
+}
+
+// Notify イベントを通知する
+// 上限を超える場合は内容を省き、クライアントに取得し直してもらう
+func (n *PostgresNotifier) Notify(ctx context.Context, msg *Message) error {
+	payload, err := encodeNotification(msg)
+	if err != nil {
+		return err
+	}
+	_, err = n.db.ExecContext(ctx, "SELECT pg_notify(?, ?)", PostgresChannel, string(payload))
+	return err
+}
+
+// encodeNotification 通知するペイロードを作成する
+func encodeNotification(msg *Message) ([]byte, error) {
+	payload, err := json.Marshal(msg)
+	if err != nil {
+		return nil, err
+	}
+	if len(payload) <= maxNotifyPayload {
+		return payload, nil
+	}
+	trimmed := *msg
+	trimmed.Payload = nil
+	return json.Marshal(&trimmed)
+}
+
+// ListenPostgres PostgreSQLの通知を受け取り、このサーバーの購読者に配信する
+// 接続できない間は間隔を延ばしながら接続し直し、その間はこのサーバーで発生したイベントを購読者に直接配信する
+// 接続が切れた間の通知は届かないため、接続し直したときに購読者へ取得し直すよう通知する。ctxがキャンセルされるまで戻らない
+func ListenPostgres(ctx context.Context, dsn string, broker *Broker) {
+	backoff := listenerMinBackoff
+	for {
+		listened, err := listenPostgres(ctx, dsn, broker)
+		broker.SetListening(false)
+		if ctx.Err() != nil {
+			return
+		}
+		if listened {
+			backoff = listenerMinBackoff
+		}
+		log.Printf("リアルタイム通知の受信が停止しました。%v後に接続し直します: %v", backoff, err)
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+		backoff = min(backoff*2, listenerMaxBackoff)
+	}
+}
+
+// listenPostgres 1つの接続で通知を受け取る。受信を開始できたかどうかと、停止した理由を返す
+// ctxがキャンセルされた場合は接続を閉じて戻る
+func listenPostgres(ctx context.Context, dsn string, broker *Broker) (bool, error) {
+	listener := pq.NewListener(dsn, listenerMinBackoff, listenerMaxBackoff, func(event pq.ListenerEventType, err error) {
+		switch event {
+		case pq.ListenerEventDisconnected:
+			// 接続し直すまでの通知は届かないため、このサーバーの購読者には直接配信する
+			broker.SetListening(false)
+		case pq.ListenerEventReconnected:
+			broker.SetListening(true)
+		}
+		if err != nil {
+			log.Printf("リアルタイム通知の受信の接続エラー: %v", err)
+		}
+	})
+	// 通信が止まっている間は Listen が接続のロックを持ったまま待つため、閉じ終わるのを待たずに戻る
+	defer func() { go func() { _ = listener.Close() }() }()
+
+	// Listen は接続できるまでctxに関係なく待ち続けるため、別のgoroutineで待ち、キャンセルされたらすぐに戻る
+	listenErr := make(chan error, 1)
+	go func() { listenErr <- listener.Listen(PostgresChannel) }()
+	select {
+	case <-ctx.Done():
+		return false, ctx.Err()
+	case err := <-listenErr:
+		if err != nil {
+			return false, err
+		}
+	}
+	broker.SetListening(true)
+	// 受信を開始するまでに他のサーバーで発生したイベントは届いていない
+	broker.Resync()
+
+	ticker := time.NewTicker(listenerPingInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return true, ctx.Err()
+		case notification, ok := <-listener.Notify:
+			if !ok {
+				return true, errListenerClosed
+			}
+			// nilは接続し直したことを表す
+			if notification == nil {
+				broker.Resync()
+				continue
+			}
+			var msg Message
+			if err := json.Unmarshal([]byte(notification.Extra), &msg); err != nil {
+				log.Printf("リアルタイム通知を読み取れません: %v", err)
+				continue
+			}
+			broker.Dispatch(&msg)
+		case <-ticker.C:
+			go func() { _ = listener.Ping() }()
+		}
+	}
+}
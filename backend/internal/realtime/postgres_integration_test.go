@@ -0,0 +1,71 @@
+//go:build integration
+// +build integration
+
+package realtime
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	_ "github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/dialect/pgdialect"
+
+	"nocode-app/backend/internal/testhelpers"
+)
+
+// TestPostgresNotifier_Integration 2台のサーバーを想定し、一方で通知したイベントが両方の購読者に届くことを確認する
+func TestPostgresNotifier_Integration(t *testing.T) {
+	if testing.Short() {
+		t.Skip("統合テストはショートモードでスキップされます")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+
+	container, err := testhelpers.SetupPostgresContainer(ctx)
+	require.NoError(t, err, "PostgreSQLコンテナのセットアップに失敗しました")
+	defer func() { _ = container.Terminate(context.Background()) }()
+
+	dsn := fmt.Sprintf("postgresql://%s:%s@%s:%d/%s?sslmode=disable",
+		container.Username, container.Password, container.Host, container.Port, container.Database)
+	sqldb, err := sql.Open("postgres", dsn)
+	require.NoError(t, err)
+	db := bun.NewDB(sqldb, pgdialect.New())
+	defer func() { _ = db.Close() }()
+
+	listenCtx, stopListening := context.WithCancel(ctx)
+	defer stopListening()
+	brokers := []*Broker{NewBroker(NewPostgresNotifier(db)), NewBroker(NewPostgresNotifier(db))}
+	subs := make([]*Subscription, len(brokers))
+	for i, broker := range brokers {
+		subs[i] = broker.Subscribe(1)
+		go func(broker *Broker) { ListenPostgres(listenCtx, dsn, broker) }(broker)
+	}
+
+	// LISTENの開始を待つため、届くまで通知し直す
+	receive := func(sub *Subscription) Message {
+		for {
+			brokers[0].Publish(ctx, Message{AppID: 1, Event: "record.created", RecordID: 10, Payload: json.RawMessage(`{"x":1}`)})
+			select {
+			case msg := <-sub.Messages():
+				return msg
+			case <-time.After(500 * time.Millisecond):
+			case <-ctx.Done():
+				t.Fatal("通知が届きませんでした")
+			}
+		}
+	}
+	for _, sub := range subs {
+		msg := receive(sub)
+		assert.Equal(t, "record.created", msg.Event)
+		assert.Equal(t, uint64(10), msg.RecordID)
+		assert.JSONEq(t, `{"x":1}`, string(msg.Payload))
+	}
+}
@@ -0,0 +1,30 @@
+package realtime
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestListenPostgres(t *testing.T) {
+	t.Run("returns on cancel while the database is unreachable", func(t *testing.T) {
+		broker := NewBroker(nil)
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			ListenPostgres(ctx, "postgres://nocode@127.0.0.1:1/nocode?sslmode=disable&connect_timeout=1", broker)
+		}()
+
+		time.Sleep(100 * time.Millisecond)
+		cancel()
+		select {
+		case <-done:
+		case <-time.After(5 * time.Second):
+			t.Fatal("キャンセルしてもListenPostgresが戻りませんでした")
+		}
+		assert.False(t, broker.listening.Load())
+	})
+}
@@ -0,0 +1,185 @@
+// Package realtime アプリの変更を接続中のクライアントへ即時に届けるためのイベントの中継を扱う
+package realtime
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"sync"
+	"sync/atomic"
+)
+
+// EventResync 通知を取りこぼした可能性があるときに送るイベント（クライアントは表示中のデータを取得し直す）
+const EventResync = "resync"
+
+// subscriptionBuffer 購読ごとに溜めておけるイベントの数
+// 溢れた購読は閉じ、クライアントには接続し直して取得し直してもらう
+const subscriptionBuffer = 64
+
+// Message 購読者に配信するイベント
+type Message struct {
+	AppID uint64 `json:"app_id"`
+	Event string `json:"event"`
+	// RecordID レコードのイベントの場合のレコードID
+	RecordID uint64 `json:"record_id,omitempty"`
+	// CreatedBy レコードの作成者（自分のレコードのみ閲覧できるユーザーに配信するかの判定に使う）
+	CreatedBy uint64 `json:"created_by,omitempty"`
+	// Payload クライアントに送る内容（通知の大きさの上限を超えた場合は空）
+	Payload json.RawMessage `json:"payload,omitempty"`
+}
+
+// Notifier 他のサーバーを含むすべての購読者へイベントを届ける仕組みのインターフェース
+// 届いたイベントは各サーバーで Broker.Dispatch に渡す
+type Notifier interface {
+	Notify(ctx context.Context, msg *Message) error
+}
+
+// Broker アプリごとの購読者にイベントを配信する
+type Broker struct {
+	notifier Notifier
+	// listening 他のサーバーを含む通知を受け取れる状態か（受け取れない間はこのサーバーの購読者に直接配信する）
+	listening atomic.Bool
+
+	mu     sync.RWMutex
+	subs   map[uint64]map[*Subscription]struct{}
+	closed bool
+}
+
+// NewBroker 新しいBrokerを作成する
+// notifier がnilの場合はこのサーバーの購読者にのみ配信する
+func NewBroker(notifier Notifier) *Broker {
+	return &Broker{
+		notifier: notifier,
+		subs:     make(map[uint64]map[*Subscription]struct{}),
+	}
+}
+
+// Publish イベントを配信する
+// 通知はデータの変更に付随するものなので、失敗しても記録するだけで呼び出し元には返さない
+// 通知を受け取れない間や通知に失敗した場合は、少なくともこのサーバーの購読者には直接配信する
+func (b *Broker) Publish(ctx context.Context, msgs ...Message) {
+	for i := range msgs {
+		if b.notifier == nil {
+			b.Dispatch(&msgs[i])
+			continue
+		}
+		if err := b.notifier.Notify(ctx, &msgs[i]); err != nil {
+			log.Printf("リアルタイム通知の送信に失敗しました（アプリ%d %s）: %v", msgs[i].AppID, msgs[i].Event, err)
+			b.Dispatch(&msgs[i])
+			continue
+		}
+		if !b.listening.Load() {
+			b.Dispatch(&msgs[i])
+		}
+	}
+}
+
+// SetListening 通知を受け取れる状態かを設定する
+// 通知の受信を開始・再開したときにtrue、接続が切れたときにfalseにする
+func (b *Broker) SetListening(listening bool) {
+	b.listening.Store(listening)
+}
+
+// Subscribe アプリのイベントの購読を開始する
+// サーバーの停止中は閉じた購読を返す
+func (b *Broker) Subscribe(appID uint64) *Subscription {
+	sub := &Subscription{appID: appID, broker: b, ch: make(chan Message, subscriptionBuffer)}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		close(sub.ch)
+		sub.closed = true
+		return sub
+	}
+	if b.subs[appID] == nil {
+		b.subs[appID] = make(map[*Subscription]struct{})
+	}
+	b.subs[appID][sub] = struct{}{}
+	return sub
+}
+
+// Dispatch このサーバーの購読者にイベントを配信する
+// 受け取りが追いつかない購読は閉じる
+func (b *Broker) Dispatch(msg *Message) {
+	var overflowed []*Subscription
+	b.mu.RLock()
+	for sub := range b.subs[msg.AppID] {
+		select {
+		case sub.ch <- *msg:
+		default:
+			overflowed = append(overflowed, sub)
+		}
+	}
+	b.mu.RUnlock()
+
+	for _, sub := range overflowed {
+		sub.Close()
+	}
+}
+
+// Resync このサーバーのすべての購読者に取得し直すよう通知する
+func (b *Broker) Resync() {
+	b.mu.RLock()
+	appIDs := make([]uint64, 0, len(b.subs))
+	for appID := range b.subs {
+		appIDs = append(appIDs, appID)
+	}
+	b.mu.RUnlock()
+
+	for _, appID := range appIDs {
+		b.Dispatch(&Message{AppID: appID, Event: EventResync})
+	}
+}
+
+// Close すべての購読を閉じ、以降の購読を受け付けない（サーバーの停止時に呼ぶ）
+func (b *Broker) Close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.closed = true
+	for appID, subs := range b.subs {
+		for sub := range subs {
+			sub.closed = true
+			close(sub.ch)
+		}
+		delete(b.subs, appID)
+	}
+}
+
+// Subscribers アプリの購読者の数を返す
+func (b *Broker) Subscribers(appID uint64) int {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return len(b.subs[appID])
+}
+
+// Subscription 1つのアプリのイベントの購読
+type Subscription struct {
+	appID  uint64
+	broker *Broker
+	ch     chan Message
+	// closed Broker.mu のロック中にのみ読み書きする
+	closed bool
+}
+
+// Messages 配信されたイベントを受け取るチャネルを返す
+// 購読が閉じられるとチャネルも閉じる
+func (s *Subscription) Messages() <-chan Message {
+	return s.ch
+}
+
+// Close 購読を終了する（複数回呼んでもよい）
+func (s *Subscription) Close() {
+	b := s.broker
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if s.closed {
+		return
+	}
+	s.closed = true
+	close(s.ch)
+	delete(b.subs[s.appID], s)
+	if len(b.subs[s.appID]) == 0 {
+		delete(b.subs, s.appID)
+	}
+}
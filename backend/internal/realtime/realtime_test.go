@@ -0,0 +1,167 @@
+package realtime
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingNotifier 通知したイベントを記録してから配信するテスト用のNotifier
+// broker がnilの場合は通知が届かない（受信が止まっている）状態を表す。err を設定すると通知に失敗する
+type recordingNotifier struct {
+	broker *Broker
+	sent   []Message
+	err    error
+}
+
+func (n *recordingNotifier) Notify(ctx context.Context, msg *Message) error {
+	if n.err != nil {
+		return n.err
+	}
+	payload, err := encodeNotification(msg)
+	if err != nil {
+		return err
+	}
+	var received Message
+	if err := json.Unmarshal(payload, &received); err != nil {
+		return err
+	}
+	n.sent = append(n.sent, received)
+	if n.broker != nil {
+		n.broker.Dispatch(&received)
+	}
+	return nil
+}
+
+func TestBroker_Publish(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("delivers to subscribers of the app", func(t *testing.T) {
+		broker := NewBroker(nil)
+		sub := broker.Subscribe(1)
+		other := broker.Subscribe(2)
+		defer sub.Close()
+		defer other.Close()
+
+		broker.Publish(ctx, Message{AppID: 1, Event: "record.created", RecordID: 10, Payload: json.RawMessage(`{"x":1}`)})
+
+		msg := <-sub.Messages()
+		assert.Equal(t, "record.created", msg.Event)
+		assert.Equal(t, uint64(10), msg.RecordID)
+		assert.Empty(t, other.Messages())
+	})
+
+	t.Run("through notifier", func(t *testing.T) {
+		notifier := &recordingNotifier{}
+		broker := NewBroker(notifier)
+		notifier.broker = broker
+		broker.SetListening(true)
+		sub := broker.Subscribe(1)
+		defer sub.Close()
+
+		large := json.RawMessage(`{"data":"` + strings.Repeat("a", maxNotifyPayload) + `"}`)
+		broker.Publish(ctx,
+			Message{AppID: 1, Event: "record.updated", RecordID: 10, CreatedBy: 5, Payload: json.RawMessage(`{"x":1}`)},
+			Message{AppID: 1, Event: "record.updated", RecordID: 11, CreatedBy: 5, Payload: large},
+		)
+
+		require.Len(t, notifier.sent, 2)
+		msg := <-sub.Messages()
+		assert.JSONEq(t, `{"x":1}`, string(msg.Payload))
+		// 上限を超える内容は省く
+		msg = <-sub.Messages()
+		assert.Equal(t, uint64(11), msg.RecordID)
+		assert.Equal(t, uint64(5), msg.CreatedBy)
+		assert.Nil(t, msg.Payload)
+	})
+
+	t.Run("dispatches locally while not listening", func(t *testing.T) {
+		notifier := &recordingNotifier{}
+		broker := NewBroker(notifier)
+		sub := broker.Subscribe(1)
+		defer sub.Close()
+
+		broker.Publish(ctx, Message{AppID: 1, Event: "record.created", RecordID: 10})
+
+		// 他のサーバーには通知し、このサーバーの購読者には直接届ける
+		require.Len(t, notifier.sent, 1)
+		assert.Equal(t, uint64(10), (<-sub.Messages()).RecordID)
+		assert.Empty(t, sub.Messages())
+	})
+
+	t.Run("dispatches locally when notify fails", func(t *testing.T) {
+		notifier := &recordingNotifier{err: errors.New("connection refused")}
+		broker := NewBroker(notifier)
+		notifier.broker = broker
+		broker.SetListening(true)
+		sub := broker.Subscribe(1)
+		defer sub.Close()
+
+		broker.Publish(ctx, Message{AppID: 1, Event: "record.created", RecordID: 10})
+
+		assert.Equal(t, uint64(10), (<-sub.Messages()).RecordID)
+		assert.Empty(t, sub.Messages())
+	})
+}
+
+func TestBroker_Subscription(t *testing.T) {
+	t.Run("close removes the subscriber", func(t *testing.T) {
+		broker := NewBroker(nil)
+		sub := broker.Subscribe(1)
+		assert.Equal(t, 1, broker.Subscribers(1))
+
+		sub.Close()
+		sub.Close()
+		assert.Zero(t, broker.Subscribers(1))
+		_, ok := <-sub.Messages()
+		assert.False(t, ok)
+	})
+
+	t.Run("slow subscriber is closed", func(t *testing.T) {
+		broker := NewBroker(nil)
+		sub := broker.Subscribe(1)
+		for i := 0; i <= subscriptionBuffer; i++ {
+			broker.Dispatch(&Message{AppID: 1, Event: "record.created"})
+		}
+
+		assert.Zero(t, broker.Subscribers(1))
+		n := 0
+		for range sub.Messages() {
+			n++
+		}
+		assert.Equal(t, subscriptionBuffer, n)
+	})
+
+	t.Run("resync notifies every subscriber", func(t *testing.T) {
+		broker := NewBroker(nil)
+		sub1 := broker.Subscribe(1)
+		sub2 := broker.Subscribe(2)
+		defer sub1.Close()
+		defer sub2.Close()
+
+		broker.Resync()
+
+		assert.Equal(t, Message{AppID: 1, Event: EventResync}, <-sub1.Messages())
+		assert.Equal(t, Message{AppID: 2, Event: EventResync}, <-sub2.Messages())
+	})
+
+	t.Run("broker close ends all subscriptions", func(t *testing.T) {
+		broker := NewBroker(nil)
+		sub := broker.Subscribe(1)
+
+		broker.Close()
+		sub.Close()
+		_, ok := <-sub.Messages()
+		assert.False(t, ok)
+
+		late := broker.Subscribe(1)
+		_, ok = <-late.Messages()
+		assert.False(t, ok)
+		assert.Zero(t, broker.Subscribers(1))
+	})
+}
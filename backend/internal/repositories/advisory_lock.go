@@ -0,0 +1,44 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/uptrace/bun"
+)
+
+// AdvisoryLocker PostgreSQLのアドバイザリーロックで複数のサーバー間の処理を排他する構造体
+type AdvisoryLocker struct {
+	db *bun.DB
+}
+
+// NewAdvisoryLocker 新しいAdvisoryLockerを作成する
+func NewAdvisoryLocker(db *bun.DB) *AdvisoryLocker {
+	return &AdvisoryLocker{db: db}
+}
+
+// TryWithLock keyのロックを取得できた場合のみfnを実行し、実行したかどうかを返す
+// 他のサーバーがロックを保持している場合は待たずにfalseを返す。
+// ロックは専用のコネクションで保持するため、fnの処理が失敗したりプロセスが停止したりしても
+// コネクションの切断とともに解放される
+func (l *AdvisoryLocker) TryWithLock(ctx context.Context, key int64, fn func(ctx context.Context) error) (bool, error) {
+	conn, err := l.db.Conn(ctx)
+	if err != nil {
+		return false, fmt.Errorf("ロック用のコネクション取得に失敗しました: %w", err)
+	}
+	defer func() { _ = conn.Close() }()
+
+	var acquired bool
+	if err := conn.QueryRowContext(ctx, "SELECT pg_try_advisory_lock(?)", key).Scan(&acquired); err != nil {
+		return false, fmt.Errorf("ロックの取得に失敗しました: %w", err)
+	}
+	if !acquired {
+		return false, nil
+	}
+	defer func() {
+		// キャンセル済みのコンテキストでも解放できるよう、新しいコンテキストを使う
+		_, _ = conn.ExecContext(context.WithoutCancel(ctx), "SELECT pg_advisory_unlock(?)", key)
+	}()
+
+	return true, fn(ctx)
+}
@@ -0,0 +1,46 @@
+package repositories_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"nocode-app/backend/internal/repositories"
+	"nocode-app/backend/internal/testhelpers"
+)
+
+func TestAdvisoryLocker_TryWithLock(t *testing.T) {
+	ctx := context.Background()
+	db, err := testhelpers.GetTestDB(ctx)
+	require.NoError(t, err)
+
+	locker := repositories.NewAdvisoryLocker(db)
+	other := repositories.NewAdvisoryLocker(db)
+	const key = 42
+
+	var innerAcquired, ran bool
+	acquired, err := locker.TryWithLock(ctx, key, func(ctx context.Context) error {
+		// ロックを保持している間は別のコネクションから取得できない
+		var innerErr error
+		innerAcquired, innerErr = other.TryWithLock(ctx, key, func(ctx context.Context) error {
+			ran = true
+			return nil
+		})
+		return innerErr
+	})
+	require.NoError(t, err)
+	assert.True(t, acquired)
+	assert.False(t, innerAcquired)
+	assert.False(t, ran)
+
+	// 処理が終わるとロックは解放される
+	acquired, err = other.TryWithLock(ctx, key, func(ctx context.Context) error {
+		ran = true
+		return nil
+	})
+	require.NoError(t, err)
+	assert.True(t, acquired)
+	assert.True(t, ran)
+}
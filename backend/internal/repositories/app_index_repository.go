@@ -0,0 +1,68 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/uptrace/bun"
+
+	"nocode-app/backend/internal/models"
+)
+
+// AppIndexRepository 動的テーブルに作成したインデックスの定義のデータベース操作を処理する構造体
+type AppIndexRepository struct {
+	db *bun.DB
+}
+
+// NewAppIndexRepository 新しいAppIndexRepositoryを作成する
+func NewAppIndexRepository(db *bun.DB) *AppIndexRepository {
+	return &AppIndexRepository{db: db}
+}
+
+// Create インデックスの定義を登録する
+func (r *AppIndexRepository) Create(ctx context.Context, index *models.AppIndex) error {
+	_, err := r.db.NewInsert().
+		Model(index).
+		Exec(ctx)
+	return err
+}
+
+// GetByID IDでインデックスの定義を取得する
+func (r *AppIndexRepository) GetByID(ctx context.Context, id uint64) (*models.AppIndex, error) {
+	index := new(models.AppIndex)
+	err := r.db.NewSelect().
+		Model(index).
+		Where("ai.id = ?", id).
+		Scan(ctx)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return index, nil
+}
+
+// GetByAppID アプリの全インデックスの定義を作成順に取得する
+func (r *AppIndexRepository) GetByAppID(ctx context.Context, appID uint64) ([]models.AppIndex, error) {
+	var indexes []models.AppIndex
+	err := r.db.NewSelect().
+		Model(&indexes).
+		Where("ai.app_id = ?", appID).
+		Order("ai.id ASC").
+		Scan(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return indexes, nil
+}
+
+// Delete インデックスの定義を削除する
+func (r *AppIndexRepository) Delete(ctx context.Context, id uint64) error {
+	_, err := r.db.NewDelete().
+		Model((*models.AppIndex)(nil)).
+		Where("id = ?", id).
+		Exec(ctx)
+	return err
+}
@@ -0,0 +1,136 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/uptrace/bun"
+
+	"nocode-app/backend/internal/models"
+)
+
+// AppPermissionRepository アプリ権限のデータベース操作を処理する構造体
+type AppPermissionRepository struct {
+	db *bun.DB
+}
+
+// NewAppPermissionRepository 新しいAppPermissionRepositoryを作成する
+func NewAppPermissionRepository(db *bun.DB) *AppPermissionRepository {
+	return &AppPermissionRepository{db: db}
+}
+
+// Create 新しいアプリ権限を作成する
+func (r *AppPermissionRepository) Create(ctx context.Context, perm *models.AppPermission) error {
+	_, err := r.db.NewInsert().
+		Model(perm).
+		Exec(ctx)
+	if err != nil {
+		return fmt.Errorf("アプリ権限の作成に失敗しました: %w", err)
+	}
+	return nil
+}
+
+// GetByID IDでアプリ権限を取得する
+func (r *AppPermissionRepository) GetByID(ctx context.Context, id uint64) (*models.AppPermission, error) {
+	perm := new(models.AppPermission)
+	err := r.db.NewSelect().
+		Model(perm).
+		Relation("User").
+		Relation("Group").
+		Where("ap.id = ?", id).
+		Scan(ctx)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("アプリ権限の取得に失敗しました: %w", err)
+	}
+	return perm, nil
+}
+
+// GetByAppID アプリに設定された全権限をユーザー・グループ情報付きで取得する
+func (r *AppPermissionRepository) GetByAppID(ctx context.Context, appID uint64) ([]models.AppPermission, error) {
+	var perms []models.AppPermission
+	err := r.db.NewSelect().
+		Model(&perms).
+		Relation("User").
+		Relation("Group").
+		Where("ap.app_id = ?", appID).
+		Order("ap.id ASC").
+		Scan(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("アプリ権限一覧の取得に失敗しました: %w", err)
+	}
+	return perms, nil
+}
+
+// GetForSubjects アプリの権限のうち、指定ユーザーまたは指定グループに付与されたものを取得する
+func (r *AppPermissionRepository) GetForSubjects(ctx context.Context, appID, userID uint64, groupIDs []uint64) ([]models.AppPermission, error) {
+	var perms []models.AppPermission
+	q := r.db.NewSelect().
+		Model(&perms).
+		Where("ap.app_id = ?", appID)
+	if len(groupIDs) > 0 {
+		q = q.WhereGroup(" AND ", func(sq *bun.SelectQuery) *bun.SelectQuery {
+			return sq.Where("ap.user_id = ?", userID).
+				WhereOr("ap.group_id IN (?)", bun.In(groupIDs))
+		})
+	} else {
+		q = q.Where("ap.user_id = ?", userID)
+	}
+	if err := q.Scan(ctx); err != nil {
+		return nil, fmt.Errorf("アプリ権限の取得に失敗しました: %w", err)
+	}
+	return perms, nil
+}
+
+// HasAny アプリに1件以上の権限が設定されているか確認する
+func (r *AppPermissionRepository) HasAny(ctx context.Context, appID uint64) (bool, error) {
+	return r.db.NewSelect().
+		Model((*models.AppPermission)(nil)).
+		Where("app_id = ?", appID).
+		Exists(ctx)
+}
+
+// SubjectExists 同じユーザーまたはグループへの権限が既に存在するか確認する
+func (r *AppPermissionRepository) SubjectExists(ctx context.Context, appID uint64, userID, groupID *uint64) (bool, error) {
+	q := r.db.NewSelect().
+		Model((*models.AppPermission)(nil)).
+		Where("app_id = ?", appID)
+	switch {
+	case userID != nil:
+		q = q.Where("user_id = ?", *userID)
+	case groupID != nil:
+		q = q.Where("group_id = ?", *groupID)
+	default:
+		return false, nil
+	}
+	return q.Exists(ctx)
+}
+
+// Update アプリ権限を更新する
+func (r *AppPermissionRepository) Update(ctx context.Context, perm *models.AppPermission) error {
+	_, err := r.db.NewUpdate().
+		Model(perm).
+		Column("role", "own_records_only", "updated_at").
+		WherePK().
+		Exec(ctx)
+	if err != nil {
+		return fmt.Errorf("アプリ権限の更新に失敗しました: %w", err)
+	}
+	return nil
+}
+
+// Delete アプリ権限を削除する
+func (r *AppPermissionRepository) Delete(ctx context.Context, id uint64) error {
+	_, err := r.db.NewDelete().
+		Model((*models.AppPermission)(nil)).
+		Where("id = ?", id).
+		Exec(ctx)
+	if err != nil {
+		return fmt.Errorf("アプリ権限の削除に失敗しました: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,121 @@
+package repositories_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"nocode-app/backend/internal/models"
+	"nocode-app/backend/internal/repositories"
+	"nocode-app/backend/internal/testhelpers"
+)
+
+func TestAppPermissionRepository_CreateAndGet(t *testing.T) {
+	ctx := context.Background()
+	db, err := testhelpers.GetTestDB(ctx)
+	require.NoError(t, err)
+
+	t.Cleanup(func() {
+		require.NoError(t, testhelpers.ResetDatabase(ctx))
+	})
+
+	repo := repositories.NewAppPermissionRepository(db)
+	app := createTestApp(ctx, t, "app_data_perm_create")
+	user := createTestUserForWidget(ctx, t, "perm_create")
+
+	hasAny, err := repo.HasAny(ctx, app.ID)
+	require.NoError(t, err)
+	assert.False(t, hasAny)
+
+	perm := &models.AppPermission{
+		AppID:          app.ID,
+		UserID:         &user.ID,
+		Role:           models.AppRoleEditor,
+		OwnRecordsOnly: true,
+		CreatedAt:      time.Now(),
+		UpdatedAt:      time.Now(),
+	}
+	require.NoError(t, repo.Create(ctx, perm))
+	assert.NotZero(t, perm.ID)
+
+	found, err := repo.GetByID(ctx, perm.ID)
+	require.NoError(t, err)
+	require.NotNil(t, found)
+	require.NotNil(t, found.User)
+	assert.Equal(t, user.Name, found.User.Name)
+	assert.True(t, found.OwnRecordsOnly)
+
+	hasAny, err = repo.HasAny(ctx, app.ID)
+	require.NoError(t, err)
+	assert.True(t, hasAny)
+
+	exists, err := repo.SubjectExists(ctx, app.ID, &user.ID, nil)
+	require.NoError(t, err)
+	assert.True(t, exists)
+}
+
+func TestAppPermissionRepository_GetForSubjects(t *testing.T) {
+	ctx := context.Background()
+	db, err := testhelpers.GetTestDB(ctx)
+	require.NoError(t, err)
+
+	t.Cleanup(func() {
+		require.NoError(t, testhelpers.ResetDatabase(ctx))
+	})
+
+	repo := repositories.NewAppPermissionRepository(db)
+	app := createTestApp(ctx, t, "app_data_perm_subjects")
+	user := createTestUserForWidget(ctx, t, "perm_subjects")
+	other := createTestUserForWidget(ctx, t, "perm_subjects_other")
+	group := createTestGroup(ctx, t, "Perm Group")
+
+	now := time.Now()
+	require.NoError(t, repo.Create(ctx, &models.AppPermission{AppID: app.ID, UserID: &user.ID, Role: models.AppRoleViewer, CreatedAt: now, UpdatedAt: now}))
+	require.NoError(t, repo.Create(ctx, &models.AppPermission{AppID: app.ID, GroupID: &group.ID, Role: models.AppRoleEditor, CreatedAt: now, UpdatedAt: now}))
+	require.NoError(t, repo.Create(ctx, &models.AppPermission{AppID: app.ID, UserID: &other.ID, Role: models.AppRoleOwner, CreatedAt: now, UpdatedAt: now}))
+
+	perms, err := repo.GetForSubjects(ctx, app.ID, user.ID, []uint64{group.ID})
+	require.NoError(t, err)
+	assert.Len(t, perms, 2)
+
+	perms, err = repo.GetForSubjects(ctx, app.ID, user.ID, nil)
+	require.NoError(t, err)
+	require.Len(t, perms, 1)
+	assert.Equal(t, models.AppRoleViewer, perms[0].Role)
+}
+
+func TestAppRepository_GetAccessibleByUserID(t *testing.T) {
+	ctx := context.Background()
+	db, err := testhelpers.GetTestDB(ctx)
+	require.NoError(t, err)
+
+	t.Cleanup(func() {
+		require.NoError(t, testhelpers.ResetDatabase(ctx))
+	})
+
+	appRepo := repositories.NewAppRepository(db)
+	permRepo := repositories.NewAppPermissionRepository(db)
+	user := createTestUserForWidget(ctx, t, "accessible")
+	other := createTestUserForWidget(ctx, t, "accessible_other")
+
+	open := createTestApp(ctx, t, "app_data_accessible_open")
+	restricted := createTestApp(ctx, t, "app_data_accessible_restricted")
+	granted := createTestApp(ctx, t, "app_data_accessible_granted")
+
+	now := time.Now()
+	require.NoError(t, permRepo.Create(ctx, &models.AppPermission{AppID: restricted.ID, UserID: &other.ID, Role: models.AppRoleViewer, CreatedAt: now, UpdatedAt: now}))
+	require.NoError(t, permRepo.Create(ctx, &models.AppPermission{AppID: granted.ID, UserID: &user.ID, Role: models.AppRoleViewer, CreatedAt: now, UpdatedAt: now}))
+
+	apps, total, err := appRepo.GetAccessibleByUserID(ctx, user.ID, 1, 10)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), total)
+
+	ids := make([]uint64, len(apps))
+	for i := range apps {
+		ids[i] = apps[i].ID
+	}
+	assert.ElementsMatch(t, []uint64{open.ID, granted.ID}, ids)
+}
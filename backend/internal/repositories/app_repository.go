@@ -4,6 +4,7 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"time"
 
 	"github.com/uptrace/bun"
 
@@ -78,6 +79,34 @@ func (r *AppRepository) GetAll(ctx context.Context, page, limit int) ([]models.A
 	return apps, int64(count), nil
 }
 
+// GetAccessibleByUserID ユーザーが閲覧可能なアプリをページネーション付きで取得する
+// 作成したアプリ、権限が1件も設定されていないアプリ、
+// ユーザーまたは所属グループに権限が付与されたアプリが対象となる
+func (r *AppRepository) GetAccessibleByUserID(ctx context.Context, userID uint64, page, limit int) ([]models.App, int64, error) {
+	var apps []models.App
+	count, err := r.db.NewSelect().
+		Model(&apps).
+		WhereGroup(" AND ", func(q *bun.SelectQuery) *bun.SelectQuery {
+			return q.Where("a.created_by = ?", userID).
+				WhereOr("NOT EXISTS (SELECT 1 FROM app_permissions AS ap WHERE ap.app_id = a.id)").
+				WhereOr(`EXISTS (
+					SELECT 1 FROM app_permissions AS ap
+					WHERE ap.app_id = a.id
+					  AND (ap.user_id = ? OR ap.group_id IN (
+						SELECT gm.group_id FROM user_group_members AS gm WHERE gm.user_id = ?
+					  ))
+				)`, userID, userID)
+		}).
+		Order("created_at DESC").
+		Limit(limit).
+		Offset((page - 1) * limit).
+		ScanAndCount(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+	return apps, int64(count), nil
+}
+
 // GetByUserID ユーザーが作成した全アプリを取得する
 func (r *AppRepository) GetByUserID(ctx context.Context, userID uint64, page, limit int) ([]models.App, int64, error) {
 	var apps []models.App
@@ -103,15 +132,83 @@ func (r *AppRepository) Update(ctx context.Context, app *models.App) error {
 	return err
 }
 
-// Delete アプリを削除する
+// Delete アプリを完全に削除する（ごみ箱のアプリも対象。フィールド・ビューなどはカスケードで削除される）
 func (r *AppRepository) Delete(ctx context.Context, id uint64) error {
 	_, err := r.db.NewDelete().
 		Model((*models.App)(nil)).
 		Where("id = ?", id).
+		ForceDelete().
+		Exec(ctx)
+	return err
+}
+
+// Trash アプリをごみ箱に移す（deleted_at を設定する）
+func (r *AppRepository) Trash(ctx context.Context, id uint64) error {
+	_, err := txOrDB(ctx, r.db).NewDelete().
+		Model((*models.App)(nil)).
+		Where("id = ?", id).
+		Exec(ctx)
+	return err
+}
+
+// Restore ごみ箱のアプリを元に戻す
+func (r *AppRepository) Restore(ctx context.Context, id uint64) error {
+	_, err := r.db.NewUpdate().
+		Model((*models.App)(nil)).
+		Set("deleted_at = NULL").
+		Where("id = ?", id).
+		WhereDeleted().
 		Exec(ctx)
 	return err
 }
 
+// GetTrashedByID IDでごみ箱のアプリを取得する
+func (r *AppRepository) GetTrashedByID(ctx context.Context, id uint64) (*models.App, error) {
+	app := new(models.App)
+	err := r.db.NewSelect().
+		Model(app).
+		Where("a.id = ?", id).
+		WhereDeleted().
+		Scan(ctx)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return app, nil
+}
+
+// GetTrashed ごみ箱の全アプリを、ごみ箱に移した日時の新しい順に取得する
+func (r *AppRepository) GetTrashed(ctx context.Context) ([]models.App, error) {
+	apps := make([]models.App, 0)
+	err := r.db.NewSelect().
+		Model(&apps).
+		WhereDeleted().
+		Order("a.deleted_at DESC", "a.id DESC").
+		Scan(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return apps, nil
+}
+
+// GetTrashedBefore before より前にごみ箱に移したアプリを、古い順に最大limit件取得する
+func (r *AppRepository) GetTrashedBefore(ctx context.Context, before time.Time, limit int) ([]models.App, error) {
+	apps := make([]models.App, 0)
+	err := r.db.NewSelect().
+		Model(&apps).
+		WhereDeleted().
+		Where("a.deleted_at < ?", before).
+		Order("a.deleted_at ASC", "a.id ASC").
+		Limit(limit).
+		Scan(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return apps, nil
+}
+
 // GetTableName アプリのテーブル名を取得する
 func (r *AppRepository) GetTableName(ctx context.Context, appID uint64) (string, error) {
 	app := new(models.App)
@@ -141,3 +238,24 @@ func (r *AppRepository) GetAllTableNames(ctx context.Context) ([]string, error)
 	}
 	return tableNames, nil
 }
+
+// GetByTableNames テーブル名に一致する内部アプリをフィールド付きで取得する（外部データソースのアプリは除く）
+func (r *AppRepository) GetByTableNames(ctx context.Context, tableNames []string) ([]models.App, error) {
+	if len(tableNames) == 0 {
+		return nil, nil
+	}
+	var apps []models.App
+	err := r.db.NewSelect().
+		Model(&apps).
+		Relation("Fields", func(q *bun.SelectQuery) *bun.SelectQuery {
+			return q.Order("display_order ASC")
+		}).
+		Where("a.table_name IN (?)", bun.In(tableNames)).
+		Where("a.is_external = false").
+		Order("a.id ASC").
+		Scan(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return apps, nil
+}
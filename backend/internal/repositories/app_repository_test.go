@@ -422,3 +422,63 @@ func TestAppRepository_GetTableName(t *testing.T) {
 		})
 	}
 }
+
+func TestAppRepository_GetByTableNames(t *testing.T) {
+	ctx := context.Background()
+	db, err := testhelpers.GetTestDB(ctx)
+	require.NoError(t, err)
+
+	t.Cleanup(func() {
+		require.NoError(t, testhelpers.ResetDatabase(ctx))
+	})
+
+	appRepo := repositories.NewAppRepository(db)
+	fieldRepo := repositories.NewFieldRepository(db)
+	adminID := getAdminUserID(ctx, t)
+
+	internal := &models.App{
+		Name:      "Internal App",
+		TableName: "app_data_by_table_1",
+		Icon:      "test",
+		CreatedBy: adminID,
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+	require.NoError(t, appRepo.Create(ctx, internal))
+	require.NoError(t, fieldRepo.CreateBatch(ctx, []models.AppField{
+		{AppID: internal.ID, FieldCode: "field2", FieldName: "Field 2", FieldType: "text", DisplayOrder: 2, CreatedAt: time.Now(), UpdatedAt: time.Now()},
+		{AppID: internal.ID, FieldCode: "field1", FieldName: "Field 1", FieldType: "text", DisplayOrder: 1, CreatedAt: time.Now(), UpdatedAt: time.Now()},
+	}))
+
+	other := &models.App{
+		Name:      "Other App",
+		TableName: "app_data_by_table_2",
+		Icon:      "test",
+		CreatedBy: adminID,
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+	require.NoError(t, appRepo.Create(ctx, other))
+
+	external := &models.App{
+		Name:       "External App",
+		TableName:  "external_by_table",
+		Icon:       "test",
+		IsExternal: true,
+		CreatedBy:  adminID,
+		CreatedAt:  time.Now(),
+		UpdatedAt:  time.Now(),
+	}
+	require.NoError(t, appRepo.Create(ctx, external))
+
+	apps, err := appRepo.GetByTableNames(ctx, []string{"app_data_by_table_1", "external_by_table", "app_data_missing"})
+	require.NoError(t, err)
+	require.Len(t, apps, 1)
+	assert.Equal(t, internal.ID, apps[0].ID)
+	require.Len(t, apps[0].Fields, 2)
+	assert.Equal(t, "field1", apps[0].Fields[0].FieldCode)
+
+	apps, err = appRepo.GetByTableNames(ctx, nil)
+	require.NoError(t, err)
+	assert.Empty(t, apps)
+}
@@ -0,0 +1,175 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/uptrace/bun"
+
+	"nocode-app/backend/internal/models"
+)
+
+// AttachmentRepository 添付ファイルのデータベース操作を処理する構造体
+type AttachmentRepository struct {
+	db *bun.DB
+}
+
+// NewAttachmentRepository 新しいAttachmentRepositoryを作成する
+func NewAttachmentRepository(db *bun.DB) *AttachmentRepository {
+	return &AttachmentRepository{db: db}
+}
+
+// Create アップロードした添付ファイルを登録する
+func (r *AttachmentRepository) Create(ctx context.Context, attachment *models.Attachment) error {
+	_, err := r.db.NewInsert().
+		Model(attachment).
+		Exec(ctx)
+	if err != nil {
+		return fmt.Errorf("添付ファイルの登録に失敗しました: %w", err)
+	}
+	return nil
+}
+
+// GetByID IDで添付ファイルを取得する
+func (r *AttachmentRepository) GetByID(ctx context.Context, id uint64) (*models.Attachment, error) {
+	attachment := new(models.Attachment)
+	err := r.db.NewSelect().
+		Model(attachment).
+		Where("att.id = ?", id).
+		Scan(ctx)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("添付ファイルの取得に失敗しました: %w", err)
+	}
+	return attachment, nil
+}
+
+// GetByIDs 複数のIDで添付ファイルを取得する（存在しないIDは結果に含まれない）
+func (r *AttachmentRepository) GetByIDs(ctx context.Context, ids []uint64) ([]models.Attachment, error) {
+	var attachments []models.Attachment
+	if len(ids) == 0 {
+		return attachments, nil
+	}
+	err := r.db.NewSelect().
+		Model(&attachments).
+		Where("att.id IN (?)", bun.In(ids)).
+		Scan(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("添付ファイルの取得に失敗しました: %w", err)
+	}
+	return attachments, nil
+}
+
+// SyncRecord レコードのフィールドに添付されているファイルをidsにそろえる
+// idsのうち未添付のファイルと削除待ちのファイルをレコードに添付し、それ以外のレコードの添付ファイルを削除待ちにする
+func (r *AttachmentRepository) SyncRecord(ctx context.Context, appID, recordID uint64, fieldCode string, ids []uint64, now time.Time) error {
+	err := txOrDB(ctx, r.db).RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		if len(ids) > 0 {
+			_, err := tx.NewUpdate().
+				Model((*models.Attachment)(nil)).
+				Set("record_id = ?", recordID).
+				Set("orphaned_at = NULL").
+				Where("app_id = ?", appID).
+				Where("field_code = ?", fieldCode).
+				Where("id IN (?)", bun.In(ids)).
+				Where("(record_id IS NULL OR record_id = ?)", recordID).
+				Exec(ctx)
+			if err != nil {
+				return err
+			}
+		}
+
+		q := tx.NewUpdate().
+			Model((*models.Attachment)(nil)).
+			Set("orphaned_at = ?", now).
+			Where("app_id = ?", appID).
+			Where("field_code = ?", fieldCode).
+			Where("record_id = ?", recordID).
+			Where("orphaned_at IS NULL")
+		if len(ids) > 0 {
+			q = q.Where("id NOT IN (?)", bun.In(ids))
+		}
+		_, err := q.Exec(ctx)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("添付ファイルの更新に失敗しました: %w", err)
+	}
+	return nil
+}
+
+// OrphanByRecords 削除したレコードの添付ファイルを削除待ちにする
+func (r *AttachmentRepository) OrphanByRecords(ctx context.Context, appID uint64, recordIDs []uint64, now time.Time) error {
+	if len(recordIDs) == 0 {
+		return nil
+	}
+	return r.orphan(ctx, now, func(q *bun.UpdateQuery) *bun.UpdateQuery {
+		return q.Where("app_id = ?", appID).Where("record_id IN (?)", bun.In(recordIDs))
+	})
+}
+
+// OrphanByField 削除したフィールドの添付ファイル（未添付のものを含む）を削除待ちにする
+func (r *AttachmentRepository) OrphanByField(ctx context.Context, appID uint64, fieldCode string, now time.Time) error {
+	return r.orphan(ctx, now, func(q *bun.UpdateQuery) *bun.UpdateQuery {
+		return q.Where("app_id = ?", appID).Where("field_code = ?", fieldCode)
+	})
+}
+
+// OrphanByApp 削除したアプリの添付ファイルを全て削除待ちにする
+func (r *AttachmentRepository) OrphanByApp(ctx context.Context, appID uint64, now time.Time) error {
+	return r.orphan(ctx, now, func(q *bun.UpdateQuery) *bun.UpdateQuery {
+		return q.Where("app_id = ?", appID)
+	})
+}
+
+// orphan 条件に一致する添付ファイルを削除待ちにする
+func (r *AttachmentRepository) orphan(ctx context.Context, now time.Time, where func(*bun.UpdateQuery) *bun.UpdateQuery) error {
+	q := r.db.NewUpdate().
+		Model((*models.Attachment)(nil)).
+		Set("orphaned_at = ?", now).
+		Where("orphaned_at IS NULL")
+	if _, err := where(q).Exec(ctx); err != nil {
+		return fmt.Errorf("添付ファイルの更新に失敗しました: %w", err)
+	}
+	return nil
+}
+
+// GetPurgeable ストレージから削除する添付ファイルを最大limit件取得する
+// 削除待ちのファイルと、pendingBefore より前にアップロードされたまま添付されていないファイルが対象
+func (r *AttachmentRepository) GetPurgeable(ctx context.Context, pendingBefore time.Time, limit int) ([]models.Attachment, error) {
+	var attachments []models.Attachment
+	err := r.db.NewSelect().
+		Model(&attachments).
+		Where("att.orphaned_at IS NOT NULL").
+		WhereOr("att.record_id IS NULL AND att.created_at < ?", pendingBefore).
+		Order("att.id ASC").
+		Limit(limit).
+		Scan(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("削除する添付ファイルの取得に失敗しました: %w", err)
+	}
+	return attachments, nil
+}
+
+// DeletePurgeable 添付ファイルがまだ削除の対象であれば登録を削除し、削除したかどうかを返す
+// 取得後にレコードに添付し直されたファイルは削除しない
+func (r *AttachmentRepository) DeletePurgeable(ctx context.Context, id uint64, pendingBefore time.Time) (bool, error) {
+	result, err := r.db.NewDelete().
+		Model((*models.Attachment)(nil)).
+		Where("id = ?", id).
+		Where("(orphaned_at IS NOT NULL OR (record_id IS NULL AND created_at < ?))", pendingBefore).
+		Exec(ctx)
+	if err != nil {
+		return false, fmt.Errorf("添付ファイルの削除に失敗しました: %w", err)
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("添付ファイルの削除に失敗しました: %w", err)
+	}
+	return n > 0, nil
+}
@@ -0,0 +1,111 @@
+package repositories_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"nocode-app/backend/internal/models"
+	"nocode-app/backend/internal/repositories"
+	"nocode-app/backend/internal/testhelpers"
+)
+
+func TestAttachmentRepository_Lifecycle(t *testing.T) {
+	ctx := context.Background()
+	db, err := testhelpers.GetTestDB(ctx)
+	require.NoError(t, err)
+
+	t.Cleanup(func() {
+		require.NoError(t, testhelpers.ResetDatabase(ctx))
+	})
+
+	repo := repositories.NewAttachmentRepository(db)
+	app := createTestApp(ctx, t, "app_data_attachment_lifecycle")
+	adminID := getAdminUserID(ctx, t)
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+
+	create := func(name string) *models.Attachment {
+		attachment := &models.Attachment{
+			AppID:       app.ID,
+			FieldCode:   "files",
+			StorageKey:  fmt.Sprintf("apps/%d/%s", app.ID, name),
+			FileName:    name,
+			ContentType: "text/plain; charset=utf-8",
+			Size:        5,
+			UploadedBy:  &adminID,
+			CreatedAt:   now,
+		}
+		require.NoError(t, repo.Create(ctx, attachment))
+		return attachment
+	}
+	a := create("a.txt")
+	b := create("b.txt")
+	c := create("c.txt")
+
+	found, err := repo.GetByID(ctx, a.ID)
+	require.NoError(t, err)
+	require.NotNil(t, found)
+	assert.Equal(t, "a.txt", found.FileName)
+	assert.Nil(t, found.RecordID)
+
+	// a と b をレコード10に添付
+	require.NoError(t, repo.SyncRecord(ctx, app.ID, 10, "files", []uint64{a.ID, b.ID}, now))
+	// 他のレコードに添付済みのファイルは添付できない
+	require.NoError(t, repo.SyncRecord(ctx, app.ID, 11, "files", []uint64{a.ID}, now))
+
+	list, err := repo.GetByIDs(ctx, []uint64{a.ID, b.ID, c.ID})
+	require.NoError(t, err)
+	require.Len(t, list, 3)
+	for i := range list {
+		switch list[i].ID {
+		case a.ID, b.ID:
+			require.NotNil(t, list[i].RecordID)
+			assert.Equal(t, uint64(10), *list[i].RecordID)
+		default:
+			assert.Nil(t, list[i].RecordID)
+		}
+	}
+
+	// b を外すと削除待ちになる
+	require.NoError(t, repo.SyncRecord(ctx, app.ID, 10, "files", []uint64{a.ID}, now))
+	purgeable, err := repo.GetPurgeable(ctx, now.Add(-time.Hour), 10)
+	require.NoError(t, err)
+	require.Len(t, purgeable, 1)
+	assert.Equal(t, b.ID, purgeable[0].ID)
+
+	// 削除待ちのファイルは添付し直せる（変更履歴からの復元）
+	require.NoError(t, repo.SyncRecord(ctx, app.ID, 10, "files", []uint64{a.ID, b.ID}, now))
+	purgeable, err = repo.GetPurgeable(ctx, now.Add(-time.Hour), 10)
+	require.NoError(t, err)
+	assert.Empty(t, purgeable)
+
+	// 添付されないまま期限を過ぎたファイルも削除対象
+	purgeable, err = repo.GetPurgeable(ctx, now.Add(time.Hour), 10)
+	require.NoError(t, err)
+	require.Len(t, purgeable, 1)
+	assert.Equal(t, c.ID, purgeable[0].ID)
+
+	// レコードの削除
+	require.NoError(t, repo.OrphanByRecords(ctx, app.ID, []uint64{10}, now))
+	purgeable, err = repo.GetPurgeable(ctx, now.Add(-time.Hour), 10)
+	require.NoError(t, err)
+	assert.Len(t, purgeable, 2)
+
+	deleted, err := repo.DeletePurgeable(ctx, a.ID, now.Add(-time.Hour))
+	require.NoError(t, err)
+	assert.True(t, deleted)
+	// 添付されていて削除待ちでもないファイルは削除しない
+	deleted, err = repo.DeletePurgeable(ctx, c.ID, now.Add(-time.Hour))
+	require.NoError(t, err)
+	assert.False(t, deleted)
+
+	// フィールドの削除（未添付のファイルを含む）
+	require.NoError(t, repo.OrphanByField(ctx, app.ID, "files", now))
+	purgeable, err = repo.GetPurgeable(ctx, now.Add(-time.Hour), 10)
+	require.NoError(t, err)
+	assert.Len(t, purgeable, 2)
+}
@@ -0,0 +1,122 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/uptrace/bun"
+
+	"nocode-app/backend/internal/models"
+)
+
+// AutomationRuleRepository 自動化ルールのデータベース操作を処理する構造体
+type AutomationRuleRepository struct {
+	db *bun.DB
+}
+
+// NewAutomationRuleRepository 新しいAutomationRuleRepositoryを作成する
+func NewAutomationRuleRepository(db *bun.DB) *AutomationRuleRepository {
+	return &AutomationRuleRepository{db: db}
+}
+
+// Create 新しい自動化ルールを登録する
+func (r *AutomationRuleRepository) Create(ctx context.Context, rule *models.AutomationRule) error {
+	_, err := r.db.NewInsert().
+		Model(rule).
+		Exec(ctx)
+	if err != nil {
+		return fmt.Errorf("自動化ルールの登録に失敗しました: %w", err)
+	}
+	return nil
+}
+
+// GetByID IDで自動化ルールを取得する
+func (r *AutomationRuleRepository) GetByID(ctx context.Context, id uint64) (*models.AutomationRule, error) {
+	rule := new(models.AutomationRule)
+	err := r.db.NewSelect().
+		Model(rule).
+		Where("ar.id = ?", id).
+		Scan(ctx)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("自動化ルールの取得に失敗しました: %w", err)
+	}
+	return rule, nil
+}
+
+// GetByAppID アプリに登録された全自動化ルールを登録順に取得する
+func (r *AutomationRuleRepository) GetByAppID(ctx context.Context, appID uint64) ([]models.AutomationRule, error) {
+	rules := make([]models.AutomationRule, 0)
+	err := r.db.NewSelect().
+		Model(&rules).
+		Where("ar.app_id = ?", appID).
+		Order("ar.id ASC").
+		Scan(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("自動化ルール一覧の取得に失敗しました: %w", err)
+	}
+	return rules, nil
+}
+
+// Update 自動化ルールの設定を更新する
+// 次回・前回の実行時刻はスケジューラーが並行して更新するため、UpdateSchedule でのみ更新する
+func (r *AutomationRuleRepository) Update(ctx context.Context, rule *models.AutomationRule) error {
+	_, err := r.db.NewUpdate().
+		Model(rule).
+		ExcludeColumn("next_run_at", "last_run_at").
+		WherePK().
+		Exec(ctx)
+	if err != nil {
+		return fmt.Errorf("自動化ルールの更新に失敗しました: %w", err)
+	}
+	return nil
+}
+
+// GetDue 次回実行時刻を過ぎた有効な定期実行ルールを、次回実行時刻の早い順に最大limit件取得する
+// ごみ箱にあるアプリのルールは実行しない
+func (r *AutomationRuleRepository) GetDue(ctx context.Context, now time.Time, limit int) ([]models.AutomationRule, error) {
+	rules := make([]models.AutomationRule, 0)
+	err := r.db.NewSelect().
+		Model(&rules).
+		Where("ar.next_run_at <= ?", now).
+		Where("ar.is_active = TRUE").
+		Where("ar.app_id IN (SELECT id FROM apps WHERE deleted_at IS NULL)").
+		Order("ar.next_run_at ASC", "ar.id ASC").
+		Limit(limit).
+		Scan(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("実行予定の自動化ルールの取得に失敗しました: %w", err)
+	}
+	return rules, nil
+}
+
+// UpdateSchedule 定期実行ルールの次回・前回の実行時刻のみを保存する
+// ルールの設定を編集中のユーザーの変更を上書きしないよう、他のカラムは更新しない
+func (r *AutomationRuleRepository) UpdateSchedule(ctx context.Context, rule *models.AutomationRule) error {
+	_, err := r.db.NewUpdate().
+		Model(rule).
+		Column("next_run_at", "last_run_at").
+		WherePK().
+		Exec(ctx)
+	if err != nil {
+		return fmt.Errorf("自動化ルールの実行時刻の保存に失敗しました: %w", err)
+	}
+	return nil
+}
+
+// Delete 自動化ルールを削除する（実行ログは外部キーのCASCADEで削除される）
+func (r *AutomationRuleRepository) Delete(ctx context.Context, id uint64) error {
+	_, err := r.db.NewDelete().
+		Model((*models.AutomationRule)(nil)).
+		Where("id = ?", id).
+		Exec(ctx)
+	if err != nil {
+		return fmt.Errorf("自動化ルールの削除に失敗しました: %w", err)
+	}
+	return nil
+}
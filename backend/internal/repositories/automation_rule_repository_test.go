@@ -0,0 +1,143 @@
+package repositories_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"nocode-app/backend/internal/models"
+	"nocode-app/backend/internal/repositories"
+	"nocode-app/backend/internal/testhelpers"
+)
+
+func TestAutomationRuleRepository_CRUD(t *testing.T) {
+	ctx := context.Background()
+	db, err := testhelpers.GetTestDB(ctx)
+	require.NoError(t, err)
+
+	t.Cleanup(func() {
+		require.NoError(t, testhelpers.ResetDatabase(ctx))
+	})
+
+	repo := repositories.NewAutomationRuleRepository(db)
+	runRepo := repositories.NewAutomationRunRepository(db)
+	app := createTestApp(ctx, t, "app_data_automation_crud")
+	adminID := getAdminUserID(ctx, t)
+
+	rule := &models.AutomationRule{
+		AppID:        app.ID,
+		Name:         "高額案件",
+		Trigger:      models.AutomationTriggerFieldChanged,
+		TriggerField: "amount",
+		Conditions:   []models.FilterItem{{Field: "amount", Operator: "gte", Value: "100"}},
+		Actions: []models.AutomationAction{
+			{Type: models.AutomationActionUpdateRecord, Values: models.RecordData{"status": "review"}},
+			{Type: models.AutomationActionCallWebhook, WebhookID: 3},
+		},
+		IsActive:  true,
+		CreatedBy: &adminID,
+	}
+	require.NoError(t, repo.Create(ctx, rule))
+	assert.NotZero(t, rule.ID)
+
+	found, err := repo.GetByID(ctx, rule.ID)
+	require.NoError(t, err)
+	require.NotNil(t, found)
+	assert.Equal(t, models.AutomationTriggerFieldChanged, found.Trigger)
+	assert.Equal(t, rule.Conditions, found.Conditions)
+	require.Len(t, found.Actions, 2)
+	assert.Equal(t, "review", found.Actions[0].Values["status"])
+	assert.Equal(t, uint64(3), found.Actions[1].WebhookID)
+
+	found.IsActive = false
+	require.NoError(t, repo.Update(ctx, found))
+
+	list, err := repo.GetByAppID(ctx, app.ID)
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+	assert.False(t, list[0].IsActive)
+
+	// 実行ログは新しい順に取得する
+	for _, status := range []models.AutomationRunStatus{models.AutomationRunSucceeded, models.AutomationRunFailed} {
+		require.NoError(t, runRepo.Create(ctx, &models.AutomationRun{
+			RuleID: rule.ID, AppID: app.ID, RecordID: 7, Status: status, CreatedAt: time.Now(),
+		}))
+	}
+	runs, total, err := runRepo.GetByRuleID(ctx, rule.ID, 1, 20)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), total)
+	require.Len(t, runs, 2)
+	assert.Equal(t, models.AutomationRunFailed, runs[0].Status)
+
+	// 削除すると実行ログも削除される
+	require.NoError(t, repo.Delete(ctx, rule.ID))
+
+	missing, err := repo.GetByID(ctx, rule.ID)
+	require.NoError(t, err)
+	assert.Nil(t, missing)
+
+	runs, total, err = runRepo.GetByRuleID(ctx, rule.ID, 1, 20)
+	require.NoError(t, err)
+	assert.Empty(t, runs)
+	assert.Zero(t, total)
+}
+
+func TestAutomationRuleRepository_GetDue(t *testing.T) {
+	ctx := context.Background()
+	db, err := testhelpers.GetTestDB(ctx)
+	require.NoError(t, err)
+
+	t.Cleanup(func() {
+		require.NoError(t, testhelpers.ResetDatabase(ctx))
+	})
+
+	repo := repositories.NewAutomationRuleRepository(db)
+	app := createTestApp(ctx, t, "app_data_automation_due")
+	now := time.Date(2026, 10, 16, 0, 0, 0, 0, time.UTC)
+	past, future := now.Add(-time.Minute), now.Add(time.Hour)
+
+	newRule := func(name string, next *time.Time, active bool) *models.AutomationRule {
+		rule := &models.AutomationRule{
+			AppID:     app.ID,
+			Name:      name,
+			Trigger:   models.AutomationTriggerSchedule,
+			Schedule:  "0 9 * * 1",
+			Timezone:  "Asia/Tokyo",
+			Actions:   []models.AutomationAction{{Type: models.AutomationActionCallWebhook, WebhookID: 1}},
+			IsActive:  active,
+			NextRunAt: next,
+		}
+		require.NoError(t, repo.Create(ctx, rule))
+		return rule
+	}
+	due := newRule("実行予定", &past, true)
+	newRule("未来", &future, true)
+	newRule("無効", &past, false)
+	newRule("予約なし", nil, true)
+
+	rules, err := repo.GetDue(ctx, now, 10)
+	require.NoError(t, err)
+	require.Len(t, rules, 1)
+	assert.Equal(t, due.ID, rules[0].ID)
+
+	// 実行時刻を保存すると対象外になる
+	rules[0].NextRunAt, rules[0].LastRunAt = &future, &now
+	require.NoError(t, repo.UpdateSchedule(ctx, &rules[0]))
+
+	rules, err = repo.GetDue(ctx, now, 10)
+	require.NoError(t, err)
+	assert.Empty(t, rules)
+
+	// 設定の更新は実行時刻を書き換えない
+	due.Name = "名前を変更"
+	require.NoError(t, repo.Update(ctx, due))
+
+	found, err := repo.GetByID(ctx, due.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "名前を変更", found.Name)
+	require.NotNil(t, found.NextRunAt)
+	assert.True(t, future.Equal(*found.NextRunAt))
+}
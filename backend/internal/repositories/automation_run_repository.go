@@ -0,0 +1,47 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/uptrace/bun"
+
+	"nocode-app/backend/internal/models"
+)
+
+// AutomationRunRepository 自動化ルールの実行ログのデータベース操作を処理する構造体
+type AutomationRunRepository struct {
+	db *bun.DB
+}
+
+// NewAutomationRunRepository 新しいAutomationRunRepositoryを作成する
+func NewAutomationRunRepository(db *bun.DB) *AutomationRunRepository {
+	return &AutomationRunRepository{db: db}
+}
+
+// Create 実行ログを1件記録する
+func (r *AutomationRunRepository) Create(ctx context.Context, run *models.AutomationRun) error {
+	_, err := r.db.NewInsert().
+		Model(run).
+		Exec(ctx)
+	if err != nil {
+		return fmt.Errorf("自動化ルールの実行ログの記録に失敗しました: %w", err)
+	}
+	return nil
+}
+
+// GetByRuleID 自動化ルールの実行ログを新しい順にページネーション付きで取得する
+func (r *AutomationRunRepository) GetByRuleID(ctx context.Context, ruleID uint64, page, limit int) ([]models.AutomationRun, int64, error) {
+	var runs []models.AutomationRun
+	count, err := r.db.NewSelect().
+		Model(&runs).
+		Where("arun.rule_id = ?", ruleID).
+		Order("arun.id DESC").
+		Limit(limit).
+		Offset((page - 1) * limit).
+		ScanAndCount(ctx)
+	if err != nil {
+		return nil, 0, fmt.Errorf("自動化ルールの実行ログの取得に失敗しました: %w", err)
+	}
+	return runs, int64(count), nil
+}
@@ -0,0 +1,431 @@
+package repositories
+
+import (
+	"database/sql"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"nocode-app/backend/internal/models"
+)
+
+const (
+	// chartEmptyLabel 値が空の場合のラベル
+	chartEmptyLabel = "(空)"
+	// chartOtherLabel 上位に入らなかった値をまとめるラベル
+	chartOtherLabel = "その他"
+	// maxChartSeries グループ化で返す系列の上限（超えた分は「その他」にまとめる）
+	maxChartSeries = 20
+)
+
+// chartBucketFormats X軸の日付の区切りごとのラベルの形式（to_char）
+// 週は月曜日始まりで、週の初日の日付をラベルにする
+var chartBucketFormats = map[string]string{
+	models.ChartBucketDay:     "YYYY-MM-DD",
+	models.ChartBucketWeek:    "YYYY-MM-DD",
+	models.ChartBucketMonth:   "YYYY-MM",
+	models.ChartBucketQuarter: `YYYY-"Q"Q`,
+	models.ChartBucketYear:    "YYYY",
+}
+
+// chartTimeZoneRegex SQLに埋め込むタイムゾーン名に使える文字（IANA名）
+var chartTimeZoneRegex = regexp.MustCompile(`^[A-Za-z0-9_+\-/]+$`)
+
+// chartQueryBuilder チャートの集計クエリを構築し、結果をデータセットに変換する構造体
+// カラム名は column で解決したクォート済みの名前のみを使う。
+// localTimestamp は日時のカラムをUTCの時刻として解釈する式を返す（内部アプリと外部データソースで型が異なるため）
+type chartQueryBuilder struct {
+	req            *models.ChartDataRequest
+	column         func(field string) (string, error)
+	localTimestamp func(quotedColumn string) string
+	measures       []models.ChartAxis
+}
+
+// newChartQueryBuilder 新しいchartQueryBuilderを作成する
+func newChartQueryBuilder(req *models.ChartDataRequest, column func(field string) (string, error), localTimestamp func(quotedColumn string) string) *chartQueryBuilder {
+	measures := make([]models.ChartAxis, 0, len(req.Measures)+1)
+	for _, m := range append([]models.ChartAxis{req.YAxis}, req.Measures...) {
+		switch m.Aggregation {
+		case "sum", "avg", "min", "max":
+		default:
+			m.Aggregation = "count"
+		}
+		measures = append(measures, m)
+	}
+	return &chartQueryBuilder{
+		req:            req,
+		column:         column,
+		localTimestamp: localTimestamp,
+		measures:       measures,
+	}
+}
+
+// grouped 系列に分けるかどうか
+func (b *chartQueryBuilder) grouped() bool {
+	return b.req.GroupBy != ""
+}
+
+// build SELECT句とGROUP BY・ORDER BY に使う列番号を返す
+// 平均は「その他」にまとめられるよう合計と件数を取得し、変換時に割る
+func (b *chartQueryBuilder) build() (selectSQL string, groupBy string, err error) {
+	label, err := b.labelExpr()
+	if err != nil {
+		return "", "", err
+	}
+	exprs := []string{label}
+	groupBy = "1"
+
+	if b.grouped() {
+		series, colErr := b.column(b.req.GroupBy)
+		if colErr != nil {
+			return "", "", fmt.Errorf("無効なグループ化フィールド: %w", colErr)
+		}
+		exprs = append(exprs, series)
+		groupBy = "1, 2"
+	}
+
+	for _, m := range b.measures {
+		if m.Aggregation == "count" {
+			exprs = append(exprs, "COUNT(*)")
+			continue
+		}
+		quoted, colErr := b.column(m.Field)
+		if colErr != nil {
+			return "", "", fmt.Errorf("無効なY軸フィールド: %w", colErr)
+		}
+		if m.Aggregation == "avg" {
+			exprs = append(exprs, fmt.Sprintf("SUM(%s)", quoted), fmt.Sprintf("COUNT(%s)", quoted))
+			continue
+		}
+		exprs = append(exprs, fmt.Sprintf("%s(%s)", strings.ToUpper(m.Aggregation), quoted))
+	}
+
+	return strings.Join(exprs, ", "), groupBy, nil
+}
+
+// labelExpr X軸の式を返す
+func (b *chartQueryBuilder) labelExpr() (string, error) {
+	quoted, err := b.column(b.req.XAxis.Field)
+	if err != nil {
+		return "", fmt.Errorf("無効なX軸フィールド: %w", err)
+	}
+	return bucketExpr(quoted, b.req.XAxis.Bucket, b.req.XAxis.DateOnly, b.req.TimeZone, b.localTimestamp)
+}
+
+// bucketExpr 日付の区切りを指定した場合は、カラムをタイムゾーンで区切った日付の文字列にする式を返す
+// 区切ったラベルは文字列の順が日付の順と一致する
+func bucketExpr(quoted, bucket string, dateOnly bool, timeZone string, localTimestamp func(quotedColumn string) string) (string, error) {
+	if bucket == "" {
+		return quoted, nil
+	}
+	format, ok := chartBucketFormats[bucket]
+	if !ok {
+		return "", fmt.Errorf("無効な日付の区切り: %s", bucket)
+	}
+
+	var local string
+	if dateOnly {
+		local = quoted + "::timestamp"
+	} else {
+		tz := timeZone
+		if tz == "" {
+			tz = "UTC"
+		}
+		if !chartTimeZoneRegex.MatchString(tz) {
+			return "", fmt.Errorf("無効なタイムゾーン: %s", tz)
+		}
+		if _, err := time.LoadLocation(tz); err != nil {
+			return "", fmt.Errorf("無効なタイムゾーン: %s", tz)
+		}
+		local = fmt.Sprintf("(%s AT TIME ZONE '%s')", localTimestamp(quoted), tz)
+	}
+	return fmt.Sprintf("to_char(date_trunc('%s', %s), '%s')", bucket, local, format), nil
+}
+
+// chartCell 1つのX軸の値・系列の集計値（平均は合計と件数）
+type chartCell struct {
+	value float64
+	count float64
+	valid bool
+}
+
+// merge 同じ集計方法の集計値を合わせる
+func (c chartCell) merge(aggregation string, other chartCell) chartCell {
+	if !other.valid {
+		return c
+	}
+	if !c.valid {
+		return other
+	}
+	switch aggregation {
+	case "min":
+		c.value = min(c.value, other.value)
+	case "max":
+		c.value = max(c.value, other.value)
+	default:
+		c.value += other.value
+		c.count += other.count
+	}
+	return c
+}
+
+// result グラフに表示する値を返す（値がない場合は0）
+func (c chartCell) result(aggregation string) float64 {
+	if !c.valid {
+		return 0
+	}
+	if aggregation == "avg" {
+		if c.count == 0 {
+			return 0
+		}
+		return c.value / c.count
+	}
+	return c.value
+}
+
+// chartTable 集計結果をX軸の値・系列ごとにまとめた表
+type chartTable struct {
+	measures []models.ChartAxis
+	labels   []string
+	series   []string
+	cells    map[[2]int][]chartCell
+}
+
+// scan 行を読み込んで表にする（行はX軸の値の順に並んでいる前提）
+func (b *chartQueryBuilder) scan(rows *sql.Rows) (*chartTable, error) {
+	t := &chartTable{measures: b.measures, cells: make(map[[2]int][]chartCell)}
+	labelIndex := make(map[string]int)
+	seriesIndex := make(map[string]int)
+
+	for rows.Next() {
+		var label, series interface{}
+		dest := []interface{}{&label}
+		if b.grouped() {
+			dest = append(dest, &series)
+		}
+		values := make([]sql.NullFloat64, 0, len(b.measures)*2)
+		for _, m := range b.measures {
+			values = append(values, sql.NullFloat64{})
+			if m.Aggregation == "avg" {
+				values = append(values, sql.NullFloat64{})
+			}
+		}
+		for i := range values {
+			dest = append(dest, &values[i])
+		}
+		if err := rows.Scan(dest...); err != nil {
+			return nil, err
+		}
+
+		li := indexOf(labelIndex, &t.labels, chartLabel(label))
+		si := 0
+		if b.grouped() {
+			si = indexOf(seriesIndex, &t.series, chartLabel(series))
+		}
+
+		key := [2]int{li, si}
+		cells := t.cells[key]
+		if cells == nil {
+			cells = make([]chartCell, len(b.measures))
+		}
+		col := 0
+		for i, m := range b.measures {
+			cell := chartCell{value: values[col].Float64, count: 1, valid: values[col].Valid}
+			col++
+			if m.Aggregation == "avg" {
+				cell.count = values[col].Float64
+				col++
+			}
+			cells[i] = cells[i].merge(m.Aggregation, cell)
+		}
+		t.cells[key] = cells
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if !b.grouped() {
+		t.series = []string{""}
+	}
+	return t, nil
+}
+
+// indexOf 値の番号を返す。初めての値は末尾に追加する
+func indexOf(index map[string]int, list *[]string, value string) int {
+	if i, ok := index[value]; ok {
+		return i
+	}
+	index[value] = len(*list)
+	*list = append(*list, value)
+	return index[value]
+}
+
+// chartLabel X軸・系列の値をラベルの文字列に変換する
+func chartLabel(v interface{}) string {
+	switch val := v.(type) {
+	case nil:
+		return chartEmptyLabel
+	case []byte:
+		return string(val)
+	case string:
+		return val
+	case time.Time:
+		if val.Hour() == 0 && val.Minute() == 0 && val.Second() == 0 && val.Nanosecond() == 0 {
+			return val.Format("2006-01-02")
+		}
+		return val.UTC().Format(time.RFC3339)
+	default:
+		return fmt.Sprintf("%v", val)
+	}
+}
+
+// response 表を系列の上限・上位N件・並び順を適用したレスポンスに変換する
+func (b *chartQueryBuilder) response(t *chartTable) *models.ChartDataResponse {
+	primary := t.measures[0].Aggregation
+
+	// 系列は最初の集計値の大きい順に並べ、上限を超えた分は「その他」にまとめる
+	seriesOrder := identityOrder(len(t.series))
+	if b.grouped() {
+		seriesOrder = rankByTotal(seriesOrder, primary, func(si int) chartCell {
+			return t.total(0, identityOrder(len(t.labels)), []int{si})
+		})
+	}
+	seriesGroups := limitGroups(seriesOrder, maxChartSeries)
+
+	// X軸の値は上位N件を最初の集計値の大きい順に選び、残りを「その他」にまとめる
+	labelOrder := identityOrder(len(t.labels))
+	var labelGroups [][]int
+	if b.req.TopN > 0 && len(labelOrder) > b.req.TopN {
+		ranked := rankByTotal(labelOrder, primary, func(li int) chartCell {
+			return t.total(0, []int{li}, seriesOrder)
+		})
+		kept := append([]int{}, ranked[:b.req.TopN]...)
+		sort.Ints(kept)
+		labelGroups = limitGroups(append(kept, ranked[b.req.TopN:]...), b.req.TopN+1)
+	} else {
+		labelGroups = limitGroups(labelOrder, len(labelOrder))
+	}
+
+	// その他を除いて並べ替える
+	sortable := labelGroups
+	var other [][]int
+	if len(labelGroups) > 0 && len(labelGroups[len(labelGroups)-1]) > 1 {
+		sortable, other = labelGroups[:len(labelGroups)-1], labelGroups[len(labelGroups)-1:]
+	}
+	if b.req.Sort == "value" {
+		sort.SliceStable(sortable, func(i, j int) bool {
+			vi := t.total(0, sortable[i], seriesOrder).result(primary)
+			vj := t.total(0, sortable[j], seriesOrder).result(primary)
+			if b.req.Order == "asc" {
+				return vi < vj
+			}
+			return vi > vj
+		})
+	} else if b.req.Order == "desc" {
+		for i, j := 0, len(sortable)-1; i < j; i, j = i+1, j-1 {
+			sortable[i], sortable[j] = sortable[j], sortable[i]
+		}
+	}
+	labelGroups = append(sortable, other...)
+
+	labels := make([]string, len(labelGroups))
+	for i, group := range labelGroups {
+		if len(group) > 1 {
+			labels[i] = chartOtherLabel
+		} else {
+			labels[i] = t.labels[group[0]]
+		}
+	}
+
+	datasets := make([]models.ChartDataset, 0, len(t.measures)*len(seriesGroups))
+	for mi, m := range t.measures {
+		measureLabel := chartMeasureLabel(m)
+		for _, seriesGroup := range seriesGroups {
+			if b.grouped() && len(t.labels) == 0 {
+				continue
+			}
+			dataset := models.ChartDataset{Label: measureLabel, Data: make([]float64, len(labelGroups))}
+			if b.grouped() {
+				seriesLabel := t.series[seriesGroup[0]]
+				if len(seriesGroup) > 1 {
+					seriesLabel = chartOtherLabel
+				}
+				dataset.Label = seriesLabel
+				if len(t.measures) > 1 {
+					dataset.Label = seriesLabel + " - " + measureLabel
+				}
+				dataset.Stack = measureLabel
+			}
+			for i, labelGroup := range labelGroups {
+				dataset.Data[i] = t.total(mi, labelGroup, seriesGroup).result(m.Aggregation)
+			}
+			datasets = append(datasets, dataset)
+		}
+	}
+
+	if len(labels) == 0 {
+		labels = nil
+	}
+	return &models.ChartDataResponse{Labels: labels, Datasets: datasets}
+}
+
+// total 指定したX軸の値・系列の集計値を合わせる
+func (t *chartTable) total(measure int, labels, series []int) chartCell {
+	aggregation := t.measures[measure].Aggregation
+	var total chartCell
+	for _, li := range labels {
+		for _, si := range series {
+			if cells, ok := t.cells[[2]int{li, si}]; ok {
+				total = total.merge(aggregation, cells[measure])
+			}
+		}
+	}
+	return total
+}
+
+// identityOrder 0からn-1までの番号を返す
+func identityOrder(n int) []int {
+	order := make([]int, n)
+	for i := range order {
+		order[i] = i
+	}
+	return order
+}
+
+// rankByTotal 番号を集計値の大きい順に並べ替えた複製を返す（同じ値は元の順）
+func rankByTotal(order []int, aggregation string, total func(i int) chartCell) []int {
+	ranked := append([]int{}, order...)
+	values := make(map[int]float64, len(ranked))
+	for _, i := range ranked {
+		values[i] = total(i).result(aggregation)
+	}
+	sort.SliceStable(ranked, func(a, b int) bool {
+		return values[ranked[a]] > values[ranked[b]]
+	})
+	return ranked
+}
+
+// limitGroups 番号を1つずつのまとまりにし、limit を超える場合は limit-1 番目以降を1つにまとめる
+func limitGroups(order []int, limit int) [][]int {
+	groups := make([][]int, 0, min(len(order), limit))
+	for i, idx := range order {
+		if len(order) > limit && i >= limit-1 {
+			groups = append(groups, order[i:])
+			break
+		}
+		groups = append(groups, []int{idx})
+	}
+	return groups
+}
+
+// chartMeasureLabel データセットのラベル（省略時は集計方法とフィールド）
+func chartMeasureLabel(m models.ChartAxis) string {
+	if m.Label != "" {
+		return m.Label
+	}
+	if m.Aggregation == "count" {
+		return "count"
+	}
+	return fmt.Sprintf("%s(%s)", m.Aggregation, m.Field)
+}
@@ -0,0 +1,172 @@
+package repositories
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"nocode-app/backend/internal/models"
+)
+
+// TestChartQueryBuilder_Build 系列・複数の集計値・日付の区切りのSELECT句をテストする
+func TestChartQueryBuilder_Build(t *testing.T) {
+	utcTimestamp := func(col string) string { return "(" + col + " AT TIME ZONE 'UTC')" }
+
+	tests := []struct {
+		name        string
+		req         *models.ChartDataRequest
+		wantSelect  string
+		wantGroupBy string
+		wantErr     bool
+	}{
+		{
+			name: "single count",
+			req: &models.ChartDataRequest{
+				XAxis: models.ChartAxis{Field: "status"},
+				YAxis: models.ChartAxis{Aggregation: "count"},
+			},
+			wantSelect:  `"status", COUNT(*)`,
+			wantGroupBy: "1",
+		},
+		{
+			name: "grouped with avg and sum",
+			req: &models.ChartDataRequest{
+				XAxis:    models.ChartAxis{Field: "status"},
+				YAxis:    models.ChartAxis{Field: "amount", Aggregation: "avg"},
+				Measures: []models.ChartAxis{{Field: "amount", Aggregation: "sum"}},
+				GroupBy:  "owner",
+			},
+			wantSelect:  `"status", "owner", SUM("amount"), COUNT("amount"), SUM("amount")`,
+			wantGroupBy: "1, 2",
+		},
+		{
+			name: "datetime bucket in time zone",
+			req: &models.ChartDataRequest{
+				XAxis:    models.ChartAxis{Field: "created_at", Bucket: "month"},
+				YAxis:    models.ChartAxis{Aggregation: "count"},
+				TimeZone: "Asia/Tokyo",
+			},
+			wantSelect:  `to_char(date_trunc('month', (("created_at" AT TIME ZONE 'UTC') AT TIME ZONE 'Asia/Tokyo')), 'YYYY-MM'), COUNT(*)`,
+			wantGroupBy: "1",
+		},
+		{
+			name: "date bucket ignores time zone",
+			req: &models.ChartDataRequest{
+				XAxis:    models.ChartAxis{Field: "due", Bucket: "quarter", DateOnly: true},
+				YAxis:    models.ChartAxis{Aggregation: "count"},
+				TimeZone: "Asia/Tokyo",
+			},
+			wantSelect:  `to_char(date_trunc('quarter', "due"::timestamp), 'YYYY-"Q"Q'), COUNT(*)`,
+			wantGroupBy: "1",
+		},
+		{
+			name: "invalid time zone",
+			req: &models.ChartDataRequest{
+				XAxis:    models.ChartAxis{Field: "created_at", Bucket: "day"},
+				YAxis:    models.ChartAxis{Aggregation: "count"},
+				TimeZone: "Asia/Tokyo'; DROP TABLE x; --",
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := newChartQueryBuilder(tt.req, quoteIdentifier, utcTimestamp)
+			selectSQL, groupBy, err := b.build()
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantSelect, selectSQL)
+			assert.Equal(t, tt.wantGroupBy, groupBy)
+		})
+	}
+}
+
+// TestChartQueryBuilder_Response 上位N件・その他・並び順・系列のデータセットへの変換をテストする
+func TestChartQueryBuilder_Response(t *testing.T) {
+	cell := func(value float64) chartCell { return chartCell{value: value, count: 1, valid: true} }
+	newTable := func(measures []models.ChartAxis, labels, series []string, cells map[[2]int][]chartCell) *chartTable {
+		return &chartTable{measures: measures, labels: labels, series: series, cells: cells}
+	}
+	count := []models.ChartAxis{{Aggregation: "count", Label: "件数"}}
+
+	t.Run("top n with other bucket", func(t *testing.T) {
+		req := &models.ChartDataRequest{TopN: 2}
+		b := newChartQueryBuilder(req, quoteIdentifier, nil)
+		table := newTable(count, []string{"A", "B", "C", "D"}, []string{""}, map[[2]int][]chartCell{
+			{0, 0}: {cell(1)}, {1, 0}: {cell(5)}, {2, 0}: {cell(3)}, {3, 0}: {cell(2)},
+		})
+
+		resp := b.response(table)
+		assert.Equal(t, []string{"B", "C", "その他"}, resp.Labels)
+		require.Len(t, resp.Datasets, 1)
+		assert.Equal(t, []float64{5, 3, 3}, resp.Datasets[0].Data)
+	})
+
+	t.Run("sort by value ascending", func(t *testing.T) {
+		req := &models.ChartDataRequest{Sort: "value", Order: "asc"}
+		b := newChartQueryBuilder(req, quoteIdentifier, nil)
+		table := newTable(count, []string{"A", "B", "C"}, []string{""}, map[[2]int][]chartCell{
+			{0, 0}: {cell(4)}, {1, 0}: {cell(1)}, {2, 0}: {cell(2)},
+		})
+
+		resp := b.response(table)
+		assert.Equal(t, []string{"B", "C", "A"}, resp.Labels)
+		assert.Equal(t, []float64{1, 2, 4}, resp.Datasets[0].Data)
+	})
+
+	t.Run("label descending", func(t *testing.T) {
+		req := &models.ChartDataRequest{Order: "desc"}
+		b := newChartQueryBuilder(req, quoteIdentifier, nil)
+		table := newTable(count, []string{"2026-01", "2026-02"}, []string{""}, map[[2]int][]chartCell{
+			{0, 0}: {cell(1)}, {1, 0}: {cell(2)},
+		})
+
+		resp := b.response(table)
+		assert.Equal(t, []string{"2026-02", "2026-01"}, resp.Labels)
+	})
+
+	t.Run("grouped series with multiple measures", func(t *testing.T) {
+		req := &models.ChartDataRequest{GroupBy: "owner", TopN: 1}
+		b := newChartQueryBuilder(req, quoteIdentifier, nil)
+		b.measures = []models.ChartAxis{{Aggregation: "count", Label: "件数"}, {Field: "amount", Aggregation: "avg", Label: "平均"}}
+		avg := func(sum, n float64) chartCell { return chartCell{value: sum, count: n, valid: true} }
+		table := newTable(b.measures, []string{"A", "B", "C"}, []string{"山田", "佐藤"}, map[[2]int][]chartCell{
+			{0, 0}: {cell(1), avg(100, 1)},
+			{1, 0}: {cell(2), avg(300, 2)},
+			{1, 1}: {cell(3), avg(600, 3)},
+			{2, 1}: {cell(1), avg(500, 1)},
+		})
+
+		resp := b.response(table)
+		// B の合計が最も多く、A と C はその他にまとめる
+		assert.Equal(t, []string{"B", "その他"}, resp.Labels)
+		require.Len(t, resp.Datasets, 4)
+		// 系列は件数の多い順
+		assert.Equal(t, models.ChartDataset{Label: "佐藤 - 件数", Data: []float64{3, 1}, Stack: "件数"}, resp.Datasets[0])
+		assert.Equal(t, models.ChartDataset{Label: "山田 - 件数", Data: []float64{2, 1}, Stack: "件数"}, resp.Datasets[1])
+		assert.Equal(t, models.ChartDataset{Label: "佐藤 - 平均", Data: []float64{200, 500}, Stack: "平均"}, resp.Datasets[2])
+		assert.Equal(t, models.ChartDataset{Label: "山田 - 平均", Data: []float64{150, 100}, Stack: "平均"}, resp.Datasets[3])
+	})
+
+	t.Run("too many series are merged", func(t *testing.T) {
+		req := &models.ChartDataRequest{GroupBy: "owner"}
+		b := newChartQueryBuilder(req, quoteIdentifier, nil)
+		series := make([]string, maxChartSeries+1)
+		cells := make(map[[2]int][]chartCell)
+		for i := range series {
+			series[i] = string(rune('a' + i))
+			cells[[2]int{0, i}] = []chartCell{cell(float64(100 - i))}
+		}
+
+		resp := b.response(newTable(count, []string{"A"}, series, cells))
+		require.Len(t, resp.Datasets, maxChartSeries)
+		last := resp.Datasets[maxChartSeries-1]
+		assert.Equal(t, "その他", last.Label)
+		assert.Equal(t, []float64{float64(100-(maxChartSeries-1)) + float64(100-maxChartSeries)}, last.Data)
+	})
+}
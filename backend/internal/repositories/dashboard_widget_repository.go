@@ -71,6 +71,7 @@ func (r *DashboardWidgetRepository) GetByUserID(ctx context.Context, userID uint
 }
 
 // GetByUserIDWithApps ユーザーIDでダッシュボードウィジェット一覧をアプリ情報付きで取得
+// ごみ箱にあるアプリのウィジェットは除く（アプリの結合条件で除かれるため、結合できなかったものを取り除く）
 func (r *DashboardWidgetRepository) GetByUserIDWithApps(ctx context.Context, userID uint64) ([]models.DashboardWidget, error) {
 	var widgets []models.DashboardWidget
 	err := r.db.NewSelect().
@@ -78,6 +79,7 @@ func (r *DashboardWidgetRepository) GetByUserIDWithApps(ctx context.Context, use
 		Relation("App").
 		Relation("App.Fields").
 		Where("dw.user_id = ?", userID).
+		Where("app.id IS NOT NULL").
 		Order("dw.display_order ASC").
 		Scan(ctx)
 	if err != nil {
@@ -103,6 +105,7 @@ func (r *DashboardWidgetRepository) GetByUserIDAndAppID(ctx context.Context, use
 }
 
 // GetVisibleByUserID ユーザーIDで表示中のダッシュボードウィジェット一覧を取得
+// ごみ箱にあるアプリのウィジェットは除く
 func (r *DashboardWidgetRepository) GetVisibleByUserID(ctx context.Context, userID uint64) ([]models.DashboardWidget, error) {
 	var widgets []models.DashboardWidget
 	err := r.db.NewSelect().
@@ -110,6 +113,7 @@ func (r *DashboardWidgetRepository) GetVisibleByUserID(ctx context.Context, user
 		Relation("App").
 		Relation("App.Fields").
 		Where("dw.user_id = ? AND dw.is_visible = ?", userID, true).
+		Where("app.id IS NOT NULL").
 		Order("dw.display_order ASC").
 		Scan(ctx)
 	if err != nil {
@@ -119,10 +123,12 @@ func (r *DashboardWidgetRepository) GetVisibleByUserID(ctx context.Context, user
 }
 
 // Update ダッシュボードウィジェットを更新
+// 更新日時はトリガで設定されるため、設定された値を widget に読み戻す
 func (r *DashboardWidgetRepository) Update(ctx context.Context, widget *models.DashboardWidget) error {
 	_, err := r.db.NewUpdate().
 		Model(widget).
 		WherePK().
+		Returning("updated_at").
 		Exec(ctx)
 	if err != nil {
 		return fmt.Errorf("ダッシュボードウィジェットの更新に失敗しました: %w", err)
@@ -130,6 +136,25 @@ func (r *DashboardWidgetRepository) Update(ctx context.Context, widget *models.D
 	return nil
 }
 
+// UpdateIfVersion バージョンが一致する場合のみダッシュボードウィジェットを更新（楽観的排他制御）
+// 他の更新と競合してバージョンが変わっていた、またはウィジェットが存在しない場合は false を返す
+func (r *DashboardWidgetRepository) UpdateIfVersion(ctx context.Context, widget *models.DashboardWidget, version int64) (bool, error) {
+	res, err := r.db.NewUpdate().
+		Model(widget).
+		WherePK().
+		Where(versionExpr("updated_at")+" = ?", version).
+		Returning("updated_at").
+		Exec(ctx)
+	if err != nil {
+		return false, fmt.Errorf("ダッシュボードウィジェットの更新に失敗しました: %w", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("ダッシュボードウィジェットの更新に失敗しました: %w", err)
+	}
+	return n > 0, nil
+}
+
 // Delete ダッシュボードウィジェットを削除
 func (r *DashboardWidgetRepository) Delete(ctx context.Context, id uint64) error {
 	_, err := r.db.NewDelete().
@@ -142,6 +167,24 @@ func (r *DashboardWidgetRepository) Delete(ctx context.Context, id uint64) error
 	return nil
 }
 
+// DeleteIfVersion バージョンが一致する場合のみダッシュボードウィジェットを削除（楽観的排他制御）
+// 他の更新と競合してバージョンが変わっていた、またはウィジェットが存在しない場合は false を返す
+func (r *DashboardWidgetRepository) DeleteIfVersion(ctx context.Context, id uint64, version int64) (bool, error) {
+	res, err := r.db.NewDelete().
+		Model((*models.DashboardWidget)(nil)).
+		Where("id = ?", id).
+		Where(versionExpr("updated_at")+" = ?", version).
+		Exec(ctx)
+	if err != nil {
+		return false, fmt.Errorf("ダッシュボードウィジェットの削除に失敗しました: %w", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("ダッシュボードウィジェットの削除に失敗しました: %w", err)
+	}
+	return n > 0, nil
+}
+
 // DeleteByUserIDAndAppID ユーザーIDとアプリIDでダッシュボードウィジェットを削除
 func (r *DashboardWidgetRepository) DeleteByUserIDAndAppID(ctx context.Context, userID, appID uint64) error {
 	_, err := r.db.NewDelete().
@@ -0,0 +1,89 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/uptrace/bun"
+
+	"nocode-app/backend/internal/models"
+)
+
+// DeletedRecordRepository ごみ箱のレコードのデータベース操作を処理する構造体
+// レコードをごみ箱に移す・ごみ箱から戻す操作は動的テーブルと同じトランザクションで行うため、DynamicQueryExecutor が担う
+type DeletedRecordRepository struct {
+	db *bun.DB
+}
+
+// NewDeletedRecordRepository 新しいDeletedRecordRepositoryを作成する
+func NewDeletedRecordRepository(db *bun.DB) *DeletedRecordRepository {
+	return &DeletedRecordRepository{db: db}
+}
+
+// GetByAppID アプリのごみ箱のレコードを、ごみ箱に移した日時の新しい順にページネーション付きで取得する
+// createdBy が0でない場合は、そのユーザーが作成したレコードのみを対象とする
+func (r *DeletedRecordRepository) GetByAppID(ctx context.Context, appID, createdBy uint64, page, limit int) ([]models.DeletedRecord, int64, error) {
+	records := make([]models.DeletedRecord, 0)
+	q := r.db.NewSelect().
+		Model(&records).
+		Where("dr.app_id = ?", appID)
+	if createdBy != 0 {
+		q = q.Where("dr.created_by = ?", createdBy)
+	}
+	count, err := q.
+		Order("dr.deleted_at DESC", "dr.id DESC").
+		Limit(limit).
+		Offset((page - 1) * limit).
+		ScanAndCount(ctx)
+	if err != nil {
+		return nil, 0, fmt.Errorf("ごみ箱のレコードの取得に失敗しました: %w", err)
+	}
+	return records, int64(count), nil
+}
+
+// GetByRecordID アプリのレコードIDでごみ箱のレコードを取得する
+func (r *DeletedRecordRepository) GetByRecordID(ctx context.Context, appID, recordID uint64) (*models.DeletedRecord, error) {
+	record := new(models.DeletedRecord)
+	err := r.db.NewSelect().
+		Model(record).
+		Where("dr.app_id = ?", appID).
+		Where("dr.record_id = ?", recordID).
+		Scan(ctx)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("ごみ箱のレコードの取得に失敗しました: %w", err)
+	}
+	return record, nil
+}
+
+// GetBefore before より前にごみ箱に移したレコードを全アプリから、古い順に最大limit件取得する
+func (r *DeletedRecordRepository) GetBefore(ctx context.Context, before time.Time, limit int) ([]models.DeletedRecord, error) {
+	records := make([]models.DeletedRecord, 0)
+	err := r.db.NewSelect().
+		Model(&records).
+		Where("dr.deleted_at < ?", before).
+		Order("dr.deleted_at ASC", "dr.id ASC").
+		Limit(limit).
+		Scan(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("保持期間を過ぎたレコードの取得に失敗しました: %w", err)
+	}
+	return records, nil
+}
+
+// Delete ごみ箱のレコードを完全に削除する
+func (r *DeletedRecordRepository) Delete(ctx context.Context, id uint64) error {
+	_, err := r.db.NewDelete().
+		Model((*models.DeletedRecord)(nil)).
+		Where("id = ?", id).
+		Exec(ctx)
+	if err != nil {
+		return fmt.Errorf("ごみ箱のレコードの削除に失敗しました: %w", err)
+	}
+	return nil
+}
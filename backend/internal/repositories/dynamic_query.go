@@ -4,11 +4,13 @@ import (
 	"context"
 	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"regexp"
 	"strings"
 	"time"
 
+	"github.com/lib/pq"
 	"github.com/uptrace/bun"
 
 	"nocode-app/backend/internal/models"
@@ -94,12 +96,15 @@ func (e *DynamicQueryExecutor) CreateTable(ctx context.Context, tableName string
 	columns = append(columns, "id BIGSERIAL PRIMARY KEY")
 
 	// フィールドからの動的カラム
+	// 計算フィールドの生成列は SetFormulaColumn で別途追加する
 	for i := range fields {
-		quotedCol, colErr := quoteIdentifier(fields[i].FieldCode)
+		if fields[i].IsComputed() {
+			continue
+		}
+		colDef, colErr := columnDefinition(tableName, &fields[i])
 		if colErr != nil {
 			return fmt.Errorf("無効なカラム名 %q: %w", fields[i].FieldCode, colErr)
 		}
-		colDef := fmt.Sprintf("%s %s", quotedCol, fields[i].GetPostgresColumnType())
 		columns = append(columns, colDef)
 	}
 
@@ -162,23 +167,73 @@ func (e *DynamicQueryExecutor) DropTable(ctx context.Context, tableName string)
 
 // AddColumn 動的テーブルにカラムを追加する
 func (e *DynamicQueryExecutor) AddColumn(ctx context.Context, tableName string, field *models.AppField) error {
+	// カラムを持たないフィールドは何もしない（計算フィールドの生成列は SetFormulaColumn で追加する）
+	if field.IsComputed() {
+		return nil
+	}
+
 	quotedTable, err := quoteIdentifier(tableName)
 	if err != nil {
 		return fmt.Errorf("無効なテーブル名: %w", err)
 	}
 
+	colDef, err := columnDefinition(tableName, field)
+	if err != nil {
+		return fmt.Errorf("無効なカラム名: %w", err)
+	}
+
+	query := fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s", quotedTable, colDef)
+	_, err = txOrDB(ctx, e.db).ExecContext(ctx, query)
+	return err
+}
+
+// columnDefinition フィールドのカラム定義を返す
+// 値の重複を禁止するフィールドは SetUniqueConstraint と同じ名前の一意制約を付ける
+func columnDefinition(tableName string, field *models.AppField) (string, error) {
 	quotedCol, err := quoteIdentifier(field.FieldCode)
+	if err != nil {
+		return "", err
+	}
+	colDef := quotedCol + " " + field.GetPostgresColumnType()
+	if field.IsUnique() {
+		quotedConstraint, err := quoteIdentifier(uniqueConstraintName(tableName, field.FieldCode))
+		if err != nil {
+			return "", fmt.Errorf("無効な制約名: %w", err)
+		}
+		colDef += " CONSTRAINT " + quotedConstraint + " UNIQUE"
+	}
+	return colDef, nil
+}
+
+// uniqueConstraintName フィールドの一意制約の名前を返す
+// PostgreSQL の既定の命名規則（{テーブル}_{カラム}_key）に合わせる
+func uniqueConstraintName(tableName, columnName string) string {
+	return tableName + "_" + columnName + "_key"
+}
+
+// SetUniqueConstraint カラムの一意制約を付け外しする
+// 既に重複した値がある場合は一意制約違反のエラーとなり、制約は変わらない
+func (e *DynamicQueryExecutor) SetUniqueConstraint(ctx context.Context, tableName, columnName string, unique bool) error {
+	quotedTable, err := quoteIdentifier(tableName)
+	if err != nil {
+		return fmt.Errorf("無効なテーブル名: %w", err)
+	}
+
+	quotedCol, err := quoteIdentifier(columnName)
 	if err != nil {
 		return fmt.Errorf("無効なカラム名: %w", err)
 	}
 
-	query := fmt.Sprintf(
-		"ALTER TABLE %s ADD COLUMN %s %s",
-		quotedTable,
-		quotedCol,
-		field.GetPostgresColumnType(),
-	)
-	_, err = e.db.ExecContext(ctx, query)
+	quotedConstraint, err := quoteIdentifier(uniqueConstraintName(tableName, columnName))
+	if err != nil {
+		return fmt.Errorf("無効な制約名: %w", err)
+	}
+
+	query := fmt.Sprintf("ALTER TABLE %s DROP CONSTRAINT IF EXISTS %s", quotedTable, quotedConstraint)
+	if unique {
+		query += fmt.Sprintf(", ADD CONSTRAINT %s UNIQUE (%s)", quotedConstraint, quotedCol)
+	}
+	_, err = txOrDB(ctx, e.db).ExecContext(ctx, query)
 	return err
 }
 
@@ -195,10 +250,106 @@ func (e *DynamicQueryExecutor) DropColumn(ctx context.Context, tableName, column
 	}
 
 	query := fmt.Sprintf("ALTER TABLE %s DROP COLUMN %s", quotedTable, quotedCol)
-	_, err = e.db.ExecContext(ctx, query)
+	_, err = txOrDB(ctx, e.db).ExecContext(ctx, query)
+	return err
+}
+
+// SetForeignKey 参照フィールドのカラムに参照先テーブルへの外部キー制約を設定する
+// 既に制約がある場合は置き換えるため、削除時の動作の変更にも使う
+func (e *DynamicQueryExecutor) SetForeignKey(ctx context.Context, tableName, columnName, refTableName string, onDelete models.ReferenceOnDelete) error {
+	quotedTable, err := quoteIdentifier(tableName)
+	if err != nil {
+		return fmt.Errorf("無効なテーブル名: %w", err)
+	}
+
+	quotedCol, err := quoteIdentifier(columnName)
+	if err != nil {
+		return fmt.Errorf("無効なカラム名: %w", err)
+	}
+
+	quotedRef, err := quoteIdentifier(refTableName)
+	if err != nil {
+		return fmt.Errorf("無効な参照先テーブル名: %w", err)
+	}
+
+	quotedConstraint, err := quoteIdentifier(foreignKeyName(tableName, columnName))
+	if err != nil {
+		return fmt.Errorf("無効な制約名: %w", err)
+	}
+
+	query := fmt.Sprintf(
+		"ALTER TABLE %s DROP CONSTRAINT IF EXISTS %s, ADD CONSTRAINT %s FOREIGN KEY (%s) REFERENCES %s (id) ON DELETE %s",
+		quotedTable,
+		quotedConstraint,
+		quotedConstraint,
+		quotedCol,
+		quotedRef,
+		onDelete.SQL(),
+	)
+	_, err = txOrDB(ctx, e.db).ExecContext(ctx, query)
 	return err
 }
 
+// DropForeignKey 参照フィールドのカラムの外部キー制約を削除する（制約がない場合は何もしない）
+func (e *DynamicQueryExecutor) DropForeignKey(ctx context.Context, tableName, columnName string) error {
+	quotedTable, err := quoteIdentifier(tableName)
+	if err != nil {
+		return fmt.Errorf("無効なテーブル名: %w", err)
+	}
+
+	quotedConstraint, err := quoteIdentifier(foreignKeyName(tableName, columnName))
+	if err != nil {
+		return fmt.Errorf("無効な制約名: %w", err)
+	}
+
+	query := fmt.Sprintf("ALTER TABLE %s DROP CONSTRAINT IF EXISTS %s", quotedTable, quotedConstraint)
+	_, err = txOrDB(ctx, e.db).ExecContext(ctx, query)
+	return err
+}
+
+// foreignKeyName 参照フィールドの外部キー制約の名前を返す
+// PostgreSQL の既定の命名規則（{テーブル}_{カラム}_fkey）に合わせる
+func foreignKeyName(tableName, columnName string) string {
+	return tableName + "_" + columnName + "_fkey"
+}
+
+// SetFormulaColumn 計算フィールドのカラムを計算式の生成列として作成する
+// 既にカラムがある場合は作り直すため、計算式の変更にも使う。expression は検証済みのSQL式であること
+func (e *DynamicQueryExecutor) SetFormulaColumn(ctx context.Context, tableName string, field *models.AppField, expression string) error {
+	quotedTable, err := quoteIdentifier(tableName)
+	if err != nil {
+		return fmt.Errorf("無効なテーブル名: %w", err)
+	}
+
+	quotedCol, err := quoteIdentifier(field.FieldCode)
+	if err != nil {
+		return fmt.Errorf("無効なカラム名: %w", err)
+	}
+
+	// 生成列の式は変更できないため、削除と追加を1つのトランザクションで行う
+	tx, err := txOrDB(ctx, e.db).BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("トランザクション開始に失敗しました: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	dropSQL := fmt.Sprintf("ALTER TABLE %s DROP COLUMN IF EXISTS %s", quotedTable, quotedCol)
+	if _, err := tx.ExecContext(ctx, dropSQL); err != nil {
+		return err
+	}
+	addSQL := fmt.Sprintf(
+		"ALTER TABLE %s ADD COLUMN %s %s GENERATED ALWAYS AS (%s) STORED",
+		quotedTable,
+		quotedCol,
+		field.GetPostgresColumnType(),
+		expression,
+	)
+	if _, err := tx.ExecContext(ctx, addSQL); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
 // InsertRecord 動的テーブルにレコードを挿入する
 func (e *DynamicQueryExecutor) InsertRecord(ctx context.Context, tableName string, data models.RecordData, userID uint64) (uint64, error) {
 	quotedTable, err := quoteIdentifier(tableName)
@@ -206,6 +357,57 @@ func (e *DynamicQueryExecutor) InsertRecord(ctx context.Context, tableName strin
 		return 0, fmt.Errorf("無効なテーブル名: %w", err)
 	}
 
+	query, values, err := buildInsertQuery(quotedTable, data, userID)
+	if err != nil {
+		return 0, err
+	}
+
+	var id uint64
+	if err := txOrDB(ctx, e.db).QueryRowContext(ctx, query, values...).Scan(&id); err != nil {
+		return 0, err
+	}
+	return id, nil
+}
+
+// InsertRecords 動的テーブルに複数のレコードを1つのトランザクションで挿入する
+// 1件でも失敗した場合は全件がロールバックされる
+func (e *DynamicQueryExecutor) InsertRecords(ctx context.Context, tableName string, rows []models.RecordData, userID uint64) ([]uint64, error) {
+	if len(rows) == 0 {
+		return nil, nil
+	}
+
+	quotedTable, err := quoteIdentifier(tableName)
+	if err != nil {
+		return nil, fmt.Errorf("無効なテーブル名: %w", err)
+	}
+
+	tx, err := txOrDB(ctx, e.db).BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("トランザクション開始に失敗しました: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	ids := make([]uint64, 0, len(rows))
+	for _, data := range rows {
+		query, values, err := buildInsertQuery(quotedTable, data, userID)
+		if err != nil {
+			return nil, err
+		}
+		var id uint64
+		if err := tx.QueryRowContext(ctx, query, values...).Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+// buildInsertQuery レコード挿入用のINSERT文と引数を組み立てる
+func buildInsertQuery(quotedTable string, data models.RecordData, userID uint64) (string, []interface{}, error) {
 	columns := []string{"created_by"}
 	placeholders := []string{"?"}
 	values := []interface{}{userID}
@@ -213,7 +415,7 @@ func (e *DynamicQueryExecutor) InsertRecord(ctx context.Context, tableName strin
 	for key, value := range data {
 		quotedCol, colErr := quoteIdentifier(key)
 		if colErr != nil {
-			return 0, fmt.Errorf("無効なカラム名 %q: %w", key, colErr)
+			return "", nil, fmt.Errorf("無効なカラム名 %q: %w", key, colErr)
 		}
 		columns = append(columns, quotedCol)
 		placeholders = append(placeholders, "?")
@@ -230,12 +432,7 @@ func (e *DynamicQueryExecutor) InsertRecord(ctx context.Context, tableName strin
 		strings.Join(columns, ", "),
 		strings.Join(placeholders, ", "),
 	)
-
-	var id uint64
-	if err := e.db.QueryRowContext(ctx, query, values...).Scan(&id); err != nil {
-		return 0, err
-	}
-	return id, nil
+	return query, values, nil
 }
 
 // UpdateRecord 動的テーブルのレコードを更新する
@@ -266,10 +463,59 @@ func (e *DynamicQueryExecutor) UpdateRecord(ctx context.Context, tableName strin
 		strings.Join(setClauses, ", "),
 	)
 
-	_, err = e.db.ExecContext(ctx, query, values...)
+	_, err = txOrDB(ctx, e.db).ExecContext(ctx, query, values...)
 	return err
 }
 
+// UpdateRecordIfVersion バージョンが一致する場合のみ動的テーブルのレコードを更新する（楽観的排他制御）
+// 他の更新と競合してバージョンが変わっていた、またはレコードが存在しない場合は false を返す
+func (e *DynamicQueryExecutor) UpdateRecordIfVersion(ctx context.Context, tableName string, recordID uint64, version int64, data models.RecordData) (bool, error) {
+	quotedTable, err := quoteIdentifier(tableName)
+	if err != nil {
+		return false, fmt.Errorf("無効なテーブル名: %w", err)
+	}
+
+	setClauses := make([]string, 0, len(data)+1)
+	values := make([]interface{}, 0, len(data)+2)
+
+	for key, value := range data {
+		quotedCol, colErr := quoteIdentifier(key)
+		if colErr != nil {
+			return false, fmt.Errorf("無効なカラム名 %q: %w", key, colErr)
+		}
+		setClauses = append(setClauses, quotedCol+" = ?")
+		values = append(values, value)
+	}
+	// 値を変更しない場合も、バージョンを進めるため updated_at はトリガで更新させる
+	if len(setClauses) == 0 {
+		setClauses = append(setClauses, "updated_at = updated_at")
+	}
+
+	values = append(values, recordID, version)
+
+	query := fmt.Sprintf(
+		"UPDATE %s SET %s WHERE id = ? AND %s = ?",
+		quotedTable,
+		strings.Join(setClauses, ", "),
+		versionExpr("updated_at"),
+	)
+
+	res, err := txOrDB(ctx, e.db).ExecContext(ctx, query, values...)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return n > 0, nil
+}
+
+// versionExpr 更新日時のカラムから models.VersionOf と同じバージョン（マイクロ秒単位のUNIX時刻）を求めるSQL式
+func versionExpr(column string) string {
+	return fmt.Sprintf("(EXTRACT(EPOCH FROM %s) * 1000000)::bigint", column)
+}
+
 // DeleteRecord 動的テーブルからレコードを削除する
 func (e *DynamicQueryExecutor) DeleteRecord(ctx context.Context, tableName string, recordID uint64) error {
 	quotedTable, err := quoteIdentifier(tableName)
@@ -317,91 +563,317 @@ type RecordQueryOptions struct {
 	Sort    string
 	Order   string
 	Filters []models.FilterItem
+	// Filter AND・OR・NOTを組み合わせた絞り込み条件（Filters とはANDで結合する）
+	Filter *models.FilterExpr
+	// TimeZone 相対的な期間（in_period）の基準とするタイムゾーン（IANA名、空の場合はUTC）
+	// 期間はサービス層で日付の範囲に置き換えるため、クエリの構築では使わない
+	TimeZone string
+	// Search 全文検索のキーワード（q）
+	Search string
+	// SearchConfig 全文検索用カラムの形式。空の場合は Search で絞り込まない
+	// （全文検索用カラムがないアプリの検索はサービス層で絞り込み条件に置き換える）
+	SearchConfig string
+	// Keyset true の場合は Page（OFFSET）を使わず、並べ替えのキーとIDで続きを取得する（カーソル方式）
+	Keyset bool
+	// Cursor 前のページの next_cursor（カーソル方式の2ページ目以降、ハンドラーで設定する）
+	Cursor string
+	// After Cursor を復号した前のページの最後のレコードの位置（サービス層で検証して設定する）
+	After *models.RecordCursor
+	// Count 総件数の求め方（空の場合は CountExact）
+	Count CountMode
 }
 
 // GetRecords ページネーションとフィルタリング付きで動的テーブルからレコードを取得する
 func (e *DynamicQueryExecutor) GetRecords(ctx context.Context, tableName string, fields []models.AppField, opts RecordQueryOptions) ([]models.RecordResponse, int64, error) {
+	fields = columnFields(fields)
 	quotedTable, err := quoteIdentifier(tableName)
 	if err != nil {
 		return nil, 0, fmt.Errorf("無効なテーブル名: %w", err)
 	}
 
 	// カラムリストとWHERE句を構築
-	columns, err := e.buildColumnList(fields)
+	columns, columnValues, err := e.buildColumnList(ctx, quotedTable, fields)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	whereSQL, whereValues, err := e.buildWhereClause(opts.Filters, opts.Filter)
 	if err != nil {
 		return nil, 0, err
 	}
 
-	whereSQL, whereValues, err := e.buildWhereClause(opts.Filters)
+	// 全文検索の条件を追加
+	search, err := buildRecordSearch(fields, opts)
 	if err != nil {
 		return nil, 0, err
 	}
+	if search != nil {
+		whereSQL = appendWhere(whereSQL, search.where)
+		whereValues = append(whereValues, search.whereValues...)
+	}
 
-	// 総件数を取得
-	total, err := e.getRecordCount(ctx, quotedTable, whereSQL, whereValues)
+	// 総件数を取得（前のページより後ろに絞り込む前の件数）
+	total, err := countRecords(ctx, e.db, opts.Count, quotedTable, whereSQL, whereValues, "?")
 	if err != nil {
 		return nil, 0, err
 	}
 
-	// ORDER BY句を構築
+	// カーソル方式では並べ替えのキーとIDで並べ、前のページの最後のレコードより後ろに絞り込む
+	if opts.Keyset {
+		if search != nil && opts.Sort == "" {
+			return nil, 0, fmt.Errorf("全文検索の一致度順ではカーソル方式のページ送りを使えません")
+		}
+		keyset, keysetErr := e.buildKeyset(opts)
+		if keysetErr != nil {
+			return nil, 0, keysetErr
+		}
+		if keyset.where != "" {
+			whereSQL = appendWhere(whereSQL, keyset.where)
+			whereValues = append(whereValues, keyset.whereValues...)
+		}
+		return e.executeRecordsQuery(ctx, quotedTable, columns, columnValues, whereSQL, whereValues, keyset.orderBy, opts, fields, total, search, keyset)
+	}
+
+	// ORDER BY句を構築（全文検索で並べ替えの指定がない場合は一致度の高い順）
 	orderBy, err := e.buildOrderBy(opts.Sort, opts.Order)
 	if err != nil {
 		return nil, 0, err
 	}
+	if search != nil && opts.Sort == "" {
+		orderBy = "_rank DESC, id DESC"
+	}
 
 	// メインクエリを構築して実行
-	return e.executeRecordsQuery(ctx, quotedTable, columns, whereSQL, whereValues, orderBy, opts, fields, total)
+	return e.executeRecordsQuery(ctx, quotedTable, columns, columnValues, whereSQL, whereValues, orderBy, opts, fields, total, search, nil)
+}
+
+// buildKeyset カーソル方式のページ送りの式を構築する（並べ替えの指定がない場合はIDの降順）
+func (e *DynamicQueryExecutor) buildKeyset(opts RecordQueryOptions) (*recordKeyset, error) {
+	if opts.Sort == "" {
+		return buildRecordKeyset("", "", "id", "desc", opts.After, bunPlaceholders()), nil
+	}
+	quotedSort, err := quoteIdentifier(opts.Sort)
+	if err != nil {
+		return nil, fmt.Errorf("無効なソートカラム: %w", err)
+	}
+	order := "asc"
+	if opts.Order == "desc" {
+		order = "desc"
+	}
+	return buildRecordKeyset(opts.Sort, quotedSort, "id", order, opts.After, bunPlaceholders()), nil
+}
+
+// columnFields 動的テーブルから取得するフィールド（カラムを持つフィールドと集計フィールド）のみを返す
+func columnFields(fields []models.AppField) []models.AppField {
+	selectable := func(f *models.AppField) bool {
+		return f.HasColumn() || models.FieldType(f.FieldType) == models.FieldTypeRollup
+	}
+	for i := range fields {
+		if !selectable(&fields[i]) {
+			filtered := make([]models.AppField, 0, len(fields))
+			for j := range fields {
+				if selectable(&fields[j]) {
+					filtered = append(filtered, fields[j])
+				}
+			}
+			return filtered
+		}
+	}
+	return fields
 }
 
 // buildColumnList SELECTカラムリストを構築する
-func (e *DynamicQueryExecutor) buildColumnList(fields []models.AppField) ([]string, error) {
+// 集計フィールドは子レコードを集計する相関サブクエリとし、サブクエリの絞り込み条件の値を返す
+func (e *DynamicQueryExecutor) buildColumnList(ctx context.Context, quotedTable string, fields []models.AppField) ([]string, []interface{}, error) {
 	columns := make([]string, 0, len(fields)+4)
 	columns = append(columns, "id", "created_by", "created_at", "updated_at")
 
+	sources, err := e.loadRollupSources(ctx, fields)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var values []interface{}
 	for i := range fields {
+		if models.FieldType(fields[i].FieldType) == models.FieldTypeRollup {
+			column, rollupValues, rollupErr := buildRollupColumn(quotedTable, &fields[i], sources)
+			if rollupErr != nil {
+				return nil, nil, rollupErr
+			}
+			columns = append(columns, column)
+			values = append(values, rollupValues...)
+			continue
+		}
 		quotedCol, colErr := quoteIdentifier(fields[i].FieldCode)
 		if colErr != nil {
-			return nil, fmt.Errorf("無効なカラム名 %q: %w", fields[i].FieldCode, colErr)
+			return nil, nil, fmt.Errorf("無効なカラム名 %q: %w", fields[i].FieldCode, colErr)
 		}
 		columns = append(columns, quotedCol)
 	}
-	return columns, nil
+	return columns, values, nil
 }
 
-// buildWhereClause フィルターからWHERE句を構築する。
-// プレースホルダは bun の ? を使い、引数は bun.DB が SQL リテラルとして
-// インライン化して PostgreSQL に渡す（pgdialect で適切にエスケープされる）。
-func (e *DynamicQueryExecutor) buildWhereClause(filters []models.FilterItem) (whereSQL string, whereValues []interface{}, err error) {
-	if len(filters) == 0 {
-		return "", nil, nil
+// rollupSource 集計フィールドが集計する子アプリのテーブル名とフィールドの種類
+type rollupSource struct {
+	tableName string
+	fields    map[string]models.FieldType
+}
+
+// loadRollupSources 集計フィールドが集計する子アプリの情報をまとめて取得する
+func (e *DynamicQueryExecutor) loadRollupSources(ctx context.Context, fields []models.AppField) (map[uint64]*rollupSource, error) {
+	var appIDs []uint64
+	seen := make(map[uint64]bool)
+	for i := range fields {
+		if models.FieldType(fields[i].FieldType) != models.FieldTypeRollup {
+			continue
+		}
+		opts, err := fields[i].Rollup()
+		if err != nil {
+			return nil, fmt.Errorf("集計フィールド %q のオプションが正しくありません: %w", fields[i].FieldCode, err)
+		}
+		if !seen[opts.AppID] {
+			seen[opts.AppID] = true
+			appIDs = append(appIDs, opts.AppID)
+		}
+	}
+	if len(appIDs) == 0 {
+		return nil, nil
+	}
+
+	var apps []models.App
+	err := e.db.NewSelect().
+		Model(&apps).
+		Relation("Fields").
+		Where("a.id IN (?)", bun.In(appIDs)).
+		Where("a.is_external = ?", false).
+		Scan(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	sources := make(map[uint64]*rollupSource, len(apps))
+	for i := range apps {
+		src := &rollupSource{tableName: apps[i].TableName, fields: make(map[string]models.FieldType, len(apps[i].Fields))}
+		for j := range apps[i].Fields {
+			if apps[i].Fields[j].HasColumn() {
+				src.fields[apps[i].Fields[j].FieldCode] = models.FieldType(apps[i].Fields[j].FieldType)
+			}
+		}
+		sources[apps[i].ID] = src
+	}
+	return sources, nil
+}
+
+// buildRollupColumn 集計フィールドの値を求める相関サブクエリを構築する
+// 子レコードのリンクフィールドが親レコードのIDと一致するものを絞り込み条件とともに集計する
+func buildRollupColumn(quotedTable string, field *models.AppField, sources map[uint64]*rollupSource) (string, []interface{}, error) {
+	quotedCode, err := quoteIdentifier(field.FieldCode)
+	if err != nil {
+		return "", nil, fmt.Errorf("無効なカラム名 %q: %w", field.FieldCode, err)
+	}
+	opts, err := field.Rollup()
+	if err != nil {
+		return "", nil, fmt.Errorf("集計フィールド %q のオプションが正しくありません: %w", field.FieldCode, err)
+	}
+	src, ok := sources[opts.AppID]
+	if !ok {
+		return "", nil, fmt.Errorf("集計フィールド %q の集計対象のアプリが見つかりません", field.FieldCode)
+	}
+	quotedSource, err := quoteIdentifier(src.tableName)
+	if err != nil {
+		return "", nil, fmt.Errorf("無効なテーブル名: %w", err)
+	}
+
+	childColumn := func(code string) (string, error) {
+		if _, ok := src.fields[code]; !ok {
+			return "", fmt.Errorf("集計フィールド %q が使う子アプリのフィールド %q が見つかりません", field.FieldCode, code)
+		}
+		quoted, err := quoteIdentifier(code)
+		if err != nil {
+			return "", err
+		}
+		return "child." + quoted, nil
 	}
 
-	whereClauses := make([]string, 0, len(filters))
-	whereValues = make([]interface{}, 0, len(filters))
+	link, err := childColumn(opts.LinkField)
+	if err != nil {
+		return "", nil, err
+	}
+	// 数値・参照以外のリンクフィールドは親レコードのIDを文字列として比較する
+	parentID := quotedTable + ".id"
+	switch src.fields[opts.LinkField] {
+	case models.FieldTypeNumber, models.FieldTypeReference:
+	default:
+		parentID = "CAST(" + parentID + " AS TEXT)"
+	}
 
-	for _, filter := range filters {
-		clause, value, filterErr := buildFilterClause(filter)
-		if filterErr != nil {
-			return "", nil, filterErr
+	var aggregate string
+	if opts.Aggregation == models.RollupCount {
+		aggregate = "COUNT(*)"
+	} else {
+		target, err := childColumn(opts.TargetField)
+		if err != nil {
+			return "", nil, err
 		}
-		if clause != "" {
-			whereClauses = append(whereClauses, clause)
-			whereValues = append(whereValues, value)
+		switch opts.Aggregation {
+		case models.RollupSum:
+			// 子レコードがない場合は0とする
+			aggregate = "COALESCE(SUM(" + target + "), 0)"
+		case models.RollupAvg, models.RollupMin, models.RollupMax:
+			aggregate = strings.ToUpper(string(opts.Aggregation)) + "(" + target + ")"
+		default:
+			return "", nil, fmt.Errorf("集計フィールド %q の集計方法が正しくありません", field.FieldCode)
 		}
 	}
 
-	if len(whereClauses) == 0 {
+	conditions := []string{link + " = " + parentID}
+	// 子アプリにないフィールドを親のカラムとして解釈しないよう、子アプリのカラムとして解決する
+	filters := newFilterBuilder(childColumn, bunPlaceholder)
+	clause, err := filters.build(opts.Filters, nil)
+	if err != nil {
+		return "", nil, err
+	}
+	if clause != "" {
+		conditions = append(conditions, clause)
+	}
+	values := filters.values
+
+	return fmt.Sprintf(
+		"(SELECT %s FROM %s AS child WHERE %s) AS %s",
+		aggregate,
+		quotedSource,
+		strings.Join(conditions, " AND "),
+		quotedCode,
+	), values, nil
+}
+
+// buildWhereClause フィルターからWHERE句を構築する。
+// プレースホルダは bun の ? を使い、引数は bun.DB が SQL リテラルとして
+// インライン化して PostgreSQL に渡す（pgdialect で適切にエスケープされる）。
+func (e *DynamicQueryExecutor) buildWhereClause(filters []models.FilterItem, expr *models.FilterExpr) (whereSQL string, whereValues []interface{}, err error) {
+	builder := newFilterBuilder(filterColumn, bunPlaceholder)
+	clause, err := builder.build(filters, expr)
+	if err != nil {
+		return "", nil, err
+	}
+	if clause == "" {
 		return "", nil, nil
 	}
-	return "WHERE " + strings.Join(whereClauses, " AND "), whereValues, nil
+	return "WHERE " + clause, builder.values, nil
 }
 
-// getRecordCount レコードの総件数を取得する
-func (e *DynamicQueryExecutor) getRecordCount(ctx context.Context, quotedTable, whereSQL string, whereValues []interface{}) (int64, error) {
-	countQuery := fmt.Sprintf("SELECT COUNT(*) FROM %s %s", quotedTable, whereSQL)
-	var total int64
-	err := e.db.QueryRowContext(ctx, countQuery, whereValues...).Scan(&total)
-	return total, err
+// filterColumn フィルターのフィールドコードをクォート済みのカラム名に変換する
+func filterColumn(field string) (string, error) {
+	quotedCol, err := quoteIdentifier(field)
+	if err != nil {
+		return "", fmt.Errorf("無効なフィルターフィールド %q: %w", field, err)
+	}
+	return quotedCol, nil
+}
+
+// bunPlaceholder bun のプレースホルダを返す
+func bunPlaceholder(int) string {
+	return "?"
 }
 
 // buildOrderBy ORDER BY句を構築する
@@ -427,25 +899,46 @@ func (e *DynamicQueryExecutor) executeRecordsQuery(
 	ctx context.Context,
 	quotedTable string,
 	columns []string,
+	columnValues []interface{},
 	whereSQL string,
 	whereValues []interface{},
 	orderBy string,
 	opts RecordQueryOptions,
 	fields []models.AppField,
 	total int64,
+	search *recordSearch,
+	keyset *recordKeyset,
 ) ([]models.RecordResponse, int64, error) {
+	// 全文検索では一致度（と抜粋）を末尾のカラムとして取得する
+	if search != nil {
+		columns = append(columns, search.columns[0]+" AS _rank")
+		if search.headline {
+			columns = append(columns, search.columns[1]+" AS _headline")
+		}
+		columnValues = append(columnValues, search.columnValues...)
+	}
+	// カーソル方式では並べ替えのキーを文字列として最後のカラムに取得する
+	if keyset != nil && keyset.column != "" {
+		columns = append(columns, keyset.column+" AS _cursor")
+	}
+
 	query := fmt.Sprintf(
-		"SELECT %s FROM %s %s ORDER BY %s LIMIT ? OFFSET ?",
+		"SELECT %s FROM %s %s ORDER BY %s LIMIT ?",
 		strings.Join(columns, ", "),
 		quotedTable,
 		whereSQL,
 		orderBy,
 	)
 
-	offset := (opts.Page - 1) * opts.Limit
-	args := make([]interface{}, 0, len(whereValues)+2)
+	// プレースホルダの出現順（カラムリスト、WHERE句、LIMIT/OFFSET）に引数を並べる
+	args := make([]interface{}, 0, len(columnValues)+len(whereValues)+2)
+	args = append(args, columnValues...)
 	args = append(args, whereValues...)
-	args = append(args, opts.Limit, offset)
+	args = append(args, opts.Limit)
+	if keyset == nil {
+		query += " OFFSET ?"
+		args = append(args, (opts.Page-1)*opts.Limit)
+	}
 
 	rows, err := e.db.QueryContext(ctx, query, args...)
 	if err != nil {
@@ -455,10 +948,31 @@ func (e *DynamicQueryExecutor) executeRecordsQuery(
 
 	var records []models.RecordResponse
 	for rows.Next() {
-		record, scanErr := scanRecordRow(rows, fields)
+		var extra []interface{}
+		var match *models.RecordSearchMatch
+		var headline, cursorValue sql.NullString
+		if search != nil {
+			match = &models.RecordSearchMatch{}
+			extra = append(extra, &match.Rank)
+			if search.headline {
+				extra = append(extra, &headline)
+			}
+		}
+		if keyset != nil && keyset.column != "" {
+			extra = append(extra, &cursorValue)
+		}
+
+		record, scanErr := scanRecordRow(rows, fields, extra...)
 		if scanErr != nil {
 			return nil, 0, scanErr
 		}
+		if match != nil {
+			match.Highlight = models.FormatHighlight(headline.String)
+			record.Search = match
+		}
+		if keyset != nil {
+			record.Cursor = keyset.cursorAfter(record.ID, cursorValue)
+		}
 		records = append(records, *record)
 	}
 
@@ -469,23 +983,112 @@ func (e *DynamicQueryExecutor) executeRecordsQuery(
 	return records, total, nil
 }
 
+// exportFetchSize サーバーサイドカーソルから一度に取得する行数
+const exportFetchSize = 500
+
+// RecordStreamFunc ストリーミング取得したレコード1件ごとに呼び出される関数
+// エラーを返すと取得を中断する
+type RecordStreamFunc func(record *models.RecordResponse) error
+
+// cursorQuerier カーソルからの取得に使うクエリ実行インターフェース（bun.Tx / sql.Tx）
+type cursorQuerier interface {
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+}
+
+// StreamRecords フィルターとソートを適用したレコードをサーバーサイドカーソルで先頭から順に取得する
+// LIMIT/OFFSETを使わないため、件数が多くても一定のメモリで全件を走査できる。Page・Limitは無視する
+func (e *DynamicQueryExecutor) StreamRecords(ctx context.Context, tableName string, fields []models.AppField, opts RecordQueryOptions, fn RecordStreamFunc) error {
+	fields = columnFields(fields)
+	quotedTable, err := quoteIdentifier(tableName)
+	if err != nil {
+		return fmt.Errorf("無効なテーブル名: %w", err)
+	}
+
+	columns, columnValues, err := e.buildColumnList(ctx, quotedTable, fields)
+	if err != nil {
+		return err
+	}
+
+	whereSQL, whereValues, err := e.buildWhereClause(opts.Filters, opts.Filter)
+	if err != nil {
+		return err
+	}
+
+	orderBy, err := e.buildOrderBy(opts.Sort, opts.Order)
+	if err != nil {
+		return err
+	}
+
+	query := fmt.Sprintf(
+		"DECLARE record_stream NO SCROLL CURSOR FOR SELECT %s FROM %s %s ORDER BY %s",
+		strings.Join(columns, ", "),
+		quotedTable,
+		whereSQL,
+		orderBy,
+	)
+
+	// カーソルはトランザクション内でのみ有効
+	tx, err := e.db.BeginTx(ctx, &sql.TxOptions{ReadOnly: true})
+	if err != nil {
+		return fmt.Errorf("トランザクション開始に失敗しました: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	args := make([]interface{}, 0, len(columnValues)+len(whereValues))
+	args = append(args, columnValues...)
+	args = append(args, whereValues...)
+	if _, err := tx.ExecContext(ctx, query, args...); err != nil {
+		return err
+	}
+
+	return fetchFromCursor(ctx, tx, "record_stream", func(rows *sql.Rows) error {
+		record, err := scanRecordRow(rows, fields)
+		if err != nil {
+			return err
+		}
+		return fn(record)
+	})
+}
+
+// fetchFromCursor カーソルが尽きるまでexportFetchSize件ずつ取得し、各行をscanに渡す
+func fetchFromCursor(ctx context.Context, q cursorQuerier, cursor string, scan func(rows *sql.Rows) error) error {
+	fetchQuery := fmt.Sprintf("FETCH FORWARD %d FROM %s", exportFetchSize, cursor)
+	for {
+		rows, err := q.QueryContext(ctx, fetchQuery)
+		if err != nil {
+			return err
+		}
+
+		n := 0
+		for rows.Next() {
+			n++
+			if err := scan(rows); err != nil {
+				_ = rows.Close()
+				return err
+			}
+		}
+		err = rows.Err()
+		_ = rows.Close()
+		if err != nil {
+			return err
+		}
+		if n < exportFetchSize {
+			return nil
+		}
+	}
+}
+
 // GetRecordByID IDで単一のレコードを取得する
 func (e *DynamicQueryExecutor) GetRecordByID(ctx context.Context, tableName string, fields []models.AppField, recordID uint64) (*models.RecordResponse, error) {
+	fields = columnFields(fields)
 	quotedTable, err := quoteIdentifier(tableName)
 	if err != nil {
 		return nil, fmt.Errorf("無効なテーブル名: %w", err)
 	}
 
-	// カラムリストを構築
-	columns := make([]string, 0, len(fields)+4)
-	columns = append(columns, "id", "created_by", "created_at", "updated_at")
-
-	for i := range fields {
-		quotedCol, colErr := quoteIdentifier(fields[i].FieldCode)
-		if colErr != nil {
-			return nil, fmt.Errorf("無効なカラム名 %q: %w", fields[i].FieldCode, colErr)
-		}
-		columns = append(columns, quotedCol)
+	columns, columnValues, err := e.buildColumnList(ctx, quotedTable, fields)
+	if err != nil {
+		return nil, err
 	}
 
 	query := fmt.Sprintf(
@@ -494,39 +1097,94 @@ func (e *DynamicQueryExecutor) GetRecordByID(ctx context.Context, tableName stri
 		quotedTable,
 	)
 
-	row := e.db.QueryRowContext(ctx, query, recordID)
+	row := txOrDB(ctx, e.db).QueryRowContext(ctx, query, append(columnValues, recordID)...)
 	return scanSingleRecordRow(row, fields)
 }
 
-// buildFilterClause 単一のフィルター句を構築する
-func buildFilterClause(filter models.FilterItem) (clause string, value interface{}, err error) {
-	quotedCol, err := quoteIdentifier(filter.Field)
-	if err != nil {
-		return "", nil, fmt.Errorf("無効なフィルターフィールド %q: %w", filter.Field, err)
-	}
-
-	switch filter.Operator {
-	case "eq":
-		return quotedCol + " = ?", filter.Value, nil
-	case "ne":
-		return quotedCol + " != ?", filter.Value, nil
-	case "gt":
-		return quotedCol + " > ?", filter.Value, nil
-	case "gte":
-		return quotedCol + " >= ?", filter.Value, nil
-	case "lt":
-		return quotedCol + " < ?", filter.Value, nil
-	case "lte":
-		return quotedCol + " <= ?", filter.Value, nil
-	case "like":
-		return quotedCol + " LIKE ?", "%" + filter.Value + "%", nil
-	default:
-		return "", nil, nil
+// GetRecordsByIDs 指定したIDのレコードをまとめて取得する
+// 存在しないIDは結果に含まれない。順序は保証しない
+func (e *DynamicQueryExecutor) GetRecordsByIDs(ctx context.Context, tableName string, fields []models.AppField, recordIDs []uint64) ([]models.RecordResponse, error) {
+	if len(recordIDs) == 0 {
+		return nil, nil
+	}
+
+	fields = columnFields(fields)
+	quotedTable, err := quoteIdentifier(tableName)
+	if err != nil {
+		return nil, fmt.Errorf("無効なテーブル名: %w", err)
 	}
+
+	columns, columnValues, err := e.buildColumnList(ctx, quotedTable, fields)
+	if err != nil {
+		return nil, err
+	}
+
+	placeholders := make([]string, len(recordIDs))
+	values := make([]interface{}, 0, len(columnValues)+len(recordIDs))
+	values = append(values, columnValues...)
+	for i, id := range recordIDs {
+		placeholders[i] = "?"
+		values = append(values, id)
+	}
+
+	query := fmt.Sprintf(
+		"SELECT %s FROM %s WHERE id IN (%s)",
+		strings.Join(columns, ", "),
+		quotedTable,
+		strings.Join(placeholders, ", "),
+	)
+
+	rows, err := e.db.QueryContext(ctx, query, values...)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	var records []models.RecordResponse
+	for rows.Next() {
+		record, scanErr := scanRecordRow(rows, fields)
+		if scanErr != nil {
+			return nil, scanErr
+		}
+		records = append(records, *record)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return records, nil
+}
+
+// IsForeignKeyViolation エラーが外部キー制約違反かどうかを判定する
+func IsForeignKeyViolation(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == "23503"
+}
+
+// uniqueViolationKeyPattern 一意制約違反のエラーの詳細から重複したカラムを取り出す正規表現
+// 例: Key (email, name)=(a@example.com, A) already exists.
+var uniqueViolationKeyPattern = regexp.MustCompile(`^Key \(([^)]*)\)=`)
+
+// UniqueViolationColumns エラーが一意制約違反かどうかを判定し、重複したカラムを返す
+// エラーの詳細からカラムを取り出せない場合は空のスライスを返す
+func UniqueViolationColumns(err error) ([]string, bool) {
+	var pqErr *pq.Error
+	if !errors.As(err, &pqErr) || pqErr.Code != "23505" {
+		return nil, false
+	}
+	m := uniqueViolationKeyPattern.FindStringSubmatch(pqErr.Detail)
+	if m == nil {
+		return []string{}, true
+	}
+	columns := strings.Split(m[1], ",")
+	for i := range columns {
+		columns[i] = strings.Trim(strings.TrimSpace(columns[i]), `"`)
+	}
+	return columns, true
 }
 
 // scanRecordRow 行からレコードをスキャンする
-func scanRecordRow(rows *sql.Rows, fields []models.AppField) (*models.RecordResponse, error) {
+// extra にはフィールドのカラムの後に続くカラムのスキャン先を指定する
+func scanRecordRow(rows *sql.Rows, fields []models.AppField, extra ...interface{}) (*models.RecordResponse, error) {
 	var id, createdBy uint64
 	var createdAt, updatedAt time.Time
 
@@ -540,6 +1198,7 @@ func scanRecordRow(rows *sql.Rows, fields []models.AppField) (*models.RecordResp
 	// スキャン先を構築
 	scanDest := []interface{}{&id, &createdBy, &createdAt, &updatedAt}
 	scanDest = append(scanDest, fieldPtrs...)
+	scanDest = append(scanDest, extra...)
 
 	if err := rows.Scan(scanDest...); err != nil {
 		return nil, err
@@ -557,6 +1216,7 @@ func scanRecordRow(rows *sql.Rows, fields []models.AppField) (*models.RecordResp
 		CreatedBy: createdBy,
 		CreatedAt: createdAt.UTC().Format(time.RFC3339),
 		UpdatedAt: updatedAt.UTC().Format(time.RFC3339),
+		Version:   models.VersionOf(updatedAt),
 	}, nil
 }
 
@@ -595,6 +1255,7 @@ func scanSingleRecordRow(row *sql.Row, fields []models.AppField) (*models.Record
 		CreatedBy: createdBy,
 		CreatedAt: createdAt.UTC().Format(time.RFC3339),
 		UpdatedAt: updatedAt.UTC().Format(time.RFC3339),
+		Version:   models.VersionOf(updatedAt),
 	}, nil
 }
 
@@ -636,25 +1297,24 @@ func convertBytesValue(b []byte) interface{} {
 }
 
 // GetAggregatedData チャート用の集計データを取得する
+// group_by を指定した場合は系列ごと、measures を指定した場合は集計値ごとにデータセットを返す
 func (e *DynamicQueryExecutor) GetAggregatedData(ctx context.Context, tableName string, req *models.ChartDataRequest) (*models.ChartDataResponse, error) {
 	quotedTable, err := quoteIdentifier(tableName)
 	if err != nil {
 		return nil, fmt.Errorf("無効なテーブル名: %w", err)
 	}
 
-	quotedXField, err := quoteIdentifier(req.XAxis.Field)
-	if err != nil {
-		return nil, fmt.Errorf("無効なX軸フィールド: %w", err)
-	}
-
-	// SELECT句を構築
-	selectClause, err := e.buildAggregationSelect(quotedXField, req.YAxis)
+	// 日時のカラムはUTCの時刻として保存している
+	builder := newChartQueryBuilder(req, quoteIdentifier, func(quotedColumn string) string {
+		return fmt.Sprintf("(%s AT TIME ZONE 'UTC')", quotedColumn)
+	})
+	selectClause, groupBy, err := builder.build()
 	if err != nil {
 		return nil, err
 	}
 
 	// フィルターからWHERE句を構築
-	whereSQL, whereValues, err := e.buildWhereClause(req.Filters)
+	whereSQL, whereValues, err := e.buildWhereClause(req.Filters, req.Filter)
 	if err != nil {
 		return nil, err
 	}
@@ -664,8 +1324,8 @@ func (e *DynamicQueryExecutor) GetAggregatedData(ctx context.Context, tableName
 		selectClause,
 		quotedTable,
 		whereSQL,
-		quotedXField,
-		quotedXField,
+		groupBy,
+		groupBy,
 	)
 
 	rows, err := e.db.QueryContext(ctx, query, whereValues...)
@@ -674,68 +1334,46 @@ func (e *DynamicQueryExecutor) GetAggregatedData(ctx context.Context, tableName
 	}
 	defer func() { _ = rows.Close() }()
 
-	return e.scanAggregatedData(rows, req.YAxis.Label)
-}
-
-// buildAggregationSelect 集計用のSELECT句を構築する
-func (e *DynamicQueryExecutor) buildAggregationSelect(quotedXField string, yAxis models.ChartAxis) (string, error) {
-	switch yAxis.Aggregation {
-	case "count":
-		return fmt.Sprintf("%s, COUNT(*) as value", quotedXField), nil
-	case "sum", "avg", "min", "max":
-		quotedYField, yErr := quoteIdentifier(yAxis.Field)
-		if yErr != nil {
-			return "", fmt.Errorf("無効なY軸フィールド: %w", yErr)
-		}
-		aggFunc := strings.ToUpper(yAxis.Aggregation)
-		return fmt.Sprintf("%s, %s(%s) as value", quotedXField, aggFunc, quotedYField), nil
-	default:
-		return fmt.Sprintf("%s, COUNT(*) as value", quotedXField), nil
+	table, err := builder.scan(rows)
+	if err != nil {
+		return nil, err
 	}
+	return builder.response(table), nil
 }
 
-// scanAggregatedData 行から集計データをスキャンする
-func (e *DynamicQueryExecutor) scanAggregatedData(rows *sql.Rows, yLabel string) (*models.ChartDataResponse, error) {
-	var labels []string
-	var data []float64
+// GetPivotData ピボット集計（小計・総計を含む）のデータを取得する
+func (e *DynamicQueryExecutor) GetPivotData(ctx context.Context, tableName string, req *models.PivotRequest) (*models.PivotResponse, error) {
+	quotedTable, err := quoteIdentifier(tableName)
+	if err != nil {
+		return nil, fmt.Errorf("無効なテーブル名: %w", err)
+	}
 
-	for rows.Next() {
-		var label interface{}
-		var value float64
-		if scanErr := rows.Scan(&label, &value); scanErr != nil {
-			return nil, scanErr
-		}
+	// 日時のカラムはUTCの時刻として保存している
+	builder := newPivotQueryBuilder(req, quoteIdentifier, func(quotedColumn string) string {
+		return fmt.Sprintf("(%s AT TIME ZONE 'UTC')", quotedColumn)
+	})
 
-		// ラベルを文字列に変換
-		var labelStr string
-		switch v := label.(type) {
-		case nil:
-			labelStr = "(空)"
-		case []byte:
-			labelStr = string(v)
-		case string:
-			labelStr = v
-		default:
-			labelStr = fmt.Sprintf("%v", v)
-		}
+	whereSQL, whereValues, err := e.buildWhereClause(req.Filters, req.Filter)
+	if err != nil {
+		return nil, err
+	}
 
-		labels = append(labels, labelStr)
-		data = append(data, value)
+	query, err := builder.query(quotedTable, whereSQL)
+	if err != nil {
+		return nil, err
 	}
 
-	if err := rows.Err(); err != nil {
+	rows, err := e.db.QueryContext(ctx, query, whereValues...)
+	if err != nil {
 		return nil, err
 	}
+	defer func() { _ = rows.Close() }()
 
-	return &models.ChartDataResponse{
-		Labels: labels,
-		Datasets: []models.ChartDataset{
-			{
-				Label: yLabel,
-				Data:  data,
-			},
-		},
-	}, nil
+	results, err := builder.scan(rows)
+	if err != nil {
+		return nil, err
+	}
+	return builder.response(results), nil
 }
 
 // CountRecords テーブル内のレコード総数を返す
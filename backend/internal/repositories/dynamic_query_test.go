@@ -2,9 +2,13 @@ package repositories_test
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"strconv"
 	"testing"
 	"time"
 
+	"github.com/lib/pq"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
@@ -248,6 +252,98 @@ func TestDynamicQueryExecutor_UpdateRecord(t *testing.T) {
 	assert.Equal(t, "published", record.Data["status"])
 }
 
+func TestDynamicQueryExecutor_UpdateRecordIfVersion(t *testing.T) {
+	ctx := context.Background()
+	db, err := testhelpers.GetTestDB(ctx)
+	require.NoError(t, err)
+
+	t.Cleanup(func() {
+		require.NoError(t, testhelpers.ResetDatabase(ctx))
+	})
+
+	executor := repositories.NewDynamicQueryExecutor(db)
+	adminID := getAdminUserID(ctx, t)
+
+	// テーブルを作成
+	fields := []models.AppField{
+		{FieldCode: "title", FieldName: "Title", FieldType: "text"},
+	}
+	require.NoError(t, executor.CreateTable(ctx, "app_data_update_version", fields))
+
+	// レコードを挿入
+	recordID, err := executor.InsertRecord(ctx, "app_data_update_version", models.RecordData{"title": "Original"}, adminID)
+	require.NoError(t, err)
+
+	record, err := executor.GetRecordByID(ctx, "app_data_update_version", fields, recordID)
+	require.NoError(t, err)
+	require.NotNil(t, record)
+	version := record.Version
+	assert.NotZero(t, version)
+
+	// 取得したときのバージョンで更新できる
+	updated, err := executor.UpdateRecordIfVersion(ctx, "app_data_update_version", recordID, version, models.RecordData{"title": "First"})
+	require.NoError(t, err)
+	assert.True(t, updated)
+
+	// 更新後は古いバージョンでは更新できない
+	updated, err = executor.UpdateRecordIfVersion(ctx, "app_data_update_version", recordID, version, models.RecordData{"title": "Second"})
+	require.NoError(t, err)
+	assert.False(t, updated)
+
+	record, err = executor.GetRecordByID(ctx, "app_data_update_version", fields, recordID)
+	require.NoError(t, err)
+	require.NotNil(t, record)
+	assert.Equal(t, "First", record.Data["title"])
+	assert.NotEqual(t, version, record.Version)
+}
+
+func TestDynamicQueryExecutor_TrashRecordIfVersion(t *testing.T) {
+	ctx := context.Background()
+	db, err := testhelpers.GetTestDB(ctx)
+	require.NoError(t, err)
+
+	t.Cleanup(func() {
+		require.NoError(t, testhelpers.ResetDatabase(ctx))
+	})
+
+	executor := repositories.NewDynamicQueryExecutor(db)
+	adminID := getAdminUserID(ctx, t)
+
+	// テーブルを作成
+	fields := []models.AppField{
+		{FieldCode: "title", FieldName: "Title", FieldType: "text"},
+	}
+	require.NoError(t, executor.CreateTable(ctx, "app_data_trash_version", fields))
+
+	// レコードを挿入
+	recordID, err := executor.InsertRecord(ctx, "app_data_trash_version", models.RecordData{"title": "Original"}, adminID)
+	require.NoError(t, err)
+
+	record, err := executor.GetRecordByID(ctx, "app_data_trash_version", fields, recordID)
+	require.NoError(t, err)
+	require.NotNil(t, record)
+	version := record.Version
+
+	// 他の更新でバージョンが変わった後は削除できない
+	require.NoError(t, executor.UpdateRecord(ctx, "app_data_trash_version", recordID, models.RecordData{"title": "Theirs"}))
+	trashed, err := executor.TrashRecordIfVersion(ctx, "app_data_trash_version", recordID, version, adminID)
+	require.NoError(t, err)
+	assert.False(t, trashed)
+
+	record, err = executor.GetRecordByID(ctx, "app_data_trash_version", fields, recordID)
+	require.NoError(t, err)
+	require.NotNil(t, record)
+
+	// 現在のバージョンでは削除できる
+	trashed, err = executor.TrashRecordIfVersion(ctx, "app_data_trash_version", recordID, record.Version, adminID)
+	require.NoError(t, err)
+	assert.True(t, trashed)
+
+	record, err = executor.GetRecordByID(ctx, "app_data_trash_version", fields, recordID)
+	require.NoError(t, err)
+	assert.Nil(t, record)
+}
+
 func TestDynamicQueryExecutor_DeleteRecord(t *testing.T) {
 	ctx := context.Background()
 	db, err := testhelpers.GetTestDB(ctx)
@@ -280,6 +376,547 @@ func TestDynamicQueryExecutor_DeleteRecord(t *testing.T) {
 	assert.Nil(t, record)
 }
 
+func TestDynamicQueryExecutor_SetForeignKey(t *testing.T) {
+	ctx := context.Background()
+	db, err := testhelpers.GetTestDB(ctx)
+	require.NoError(t, err)
+
+	t.Cleanup(func() {
+		require.NoError(t, testhelpers.ResetDatabase(ctx))
+	})
+
+	executor := repositories.NewDynamicQueryExecutor(db)
+	adminID := getAdminUserID(ctx, t)
+
+	customerFields := []models.AppField{
+		{FieldCode: "name", FieldName: "Name", FieldType: "text"},
+	}
+	orderFields := []models.AppField{
+		{FieldCode: "title", FieldName: "Title", FieldType: "text"},
+		{FieldCode: "customer", FieldName: "Customer", FieldType: "reference"},
+		{FieldCode: "customer_name", FieldName: "Customer Name", FieldType: "lookup"},
+	}
+	require.NoError(t, executor.CreateTable(ctx, "app_data_fk_customers", customerFields))
+	require.NoError(t, executor.CreateTable(ctx, "app_data_fk_orders", orderFields))
+
+	customerID, err := executor.InsertRecord(ctx, "app_data_fk_customers", models.RecordData{"name": "Acme"}, adminID)
+	require.NoError(t, err)
+
+	t.Run("restrict blocks delete", func(t *testing.T) {
+		require.NoError(t, executor.SetForeignKey(ctx, "app_data_fk_orders", "customer", "app_data_fk_customers", models.ReferenceOnDeleteRestrict))
+
+		_, err := executor.InsertRecord(ctx, "app_data_fk_orders", models.RecordData{"title": "Order", "customer": customerID}, adminID)
+		require.NoError(t, err)
+
+		err = executor.DeleteRecord(ctx, "app_data_fk_customers", customerID)
+		require.Error(t, err)
+		assert.True(t, repositories.IsForeignKeyViolation(err))
+	})
+
+	t.Run("unknown record is rejected", func(t *testing.T) {
+		_, err := executor.InsertRecord(ctx, "app_data_fk_orders", models.RecordData{"title": "Order", "customer": customerID + 1000}, adminID)
+		require.Error(t, err)
+		assert.True(t, repositories.IsForeignKeyViolation(err))
+	})
+
+	t.Run("set null clears reference", func(t *testing.T) {
+		require.NoError(t, executor.SetForeignKey(ctx, "app_data_fk_orders", "customer", "app_data_fk_customers", models.ReferenceOnDeleteSetNull))
+
+		orderID, err := executor.InsertRecord(ctx, "app_data_fk_orders", models.RecordData{"title": "Order", "customer": customerID}, adminID)
+		require.NoError(t, err)
+		require.NoError(t, executor.DeleteRecord(ctx, "app_data_fk_customers", customerID))
+
+		order, err := executor.GetRecordByID(ctx, "app_data_fk_orders", orderFields, orderID)
+		require.NoError(t, err)
+		require.NotNil(t, order)
+		assert.Nil(t, order.Data["customer"])
+		assert.NotContains(t, order.Data, "customer_name")
+	})
+
+	t.Run("cascade deletes referencing records", func(t *testing.T) {
+		require.NoError(t, executor.SetForeignKey(ctx, "app_data_fk_orders", "customer", "app_data_fk_customers", models.ReferenceOnDeleteCascade))
+
+		otherID, err := executor.InsertRecord(ctx, "app_data_fk_customers", models.RecordData{"name": "Other"}, adminID)
+		require.NoError(t, err)
+		orderID, err := executor.InsertRecord(ctx, "app_data_fk_orders", models.RecordData{"title": "Order", "customer": otherID}, adminID)
+		require.NoError(t, err)
+		require.NoError(t, executor.DeleteRecord(ctx, "app_data_fk_customers", otherID))
+
+		order, err := executor.GetRecordByID(ctx, "app_data_fk_orders", orderFields, orderID)
+		require.NoError(t, err)
+		assert.Nil(t, order)
+	})
+}
+
+func TestDynamicQueryExecutor_UniqueConstraint(t *testing.T) {
+	ctx := context.Background()
+	db, err := testhelpers.GetTestDB(ctx)
+	require.NoError(t, err)
+
+	t.Cleanup(func() {
+		require.NoError(t, testhelpers.ResetDatabase(ctx))
+	})
+
+	executor := repositories.NewDynamicQueryExecutor(db)
+	adminID := getAdminUserID(ctx, t)
+
+	fields := []models.AppField{
+		{FieldCode: "code", FieldName: "Code", FieldType: "text", Options: models.FieldOptions{"unique": true}},
+		{FieldCode: "name", FieldName: "Name", FieldType: "text"},
+	}
+	require.NoError(t, executor.CreateTable(ctx, "app_data_unique", fields))
+
+	_, err = executor.InsertRecord(ctx, "app_data_unique", models.RecordData{"code": "C001", "name": "Acme"}, adminID)
+	require.NoError(t, err)
+
+	t.Run("duplicate value is rejected", func(t *testing.T) {
+		_, err := executor.InsertRecord(ctx, "app_data_unique", models.RecordData{"code": "C001", "name": "Other"}, adminID)
+		require.Error(t, err)
+		columns, ok := repositories.UniqueViolationColumns(err)
+		assert.True(t, ok)
+		assert.Equal(t, []string{"code"}, columns)
+	})
+
+	t.Run("constraint can be toggled", func(t *testing.T) {
+		require.NoError(t, executor.SetUniqueConstraint(ctx, "app_data_unique", "code", false))
+		_, err := executor.InsertRecord(ctx, "app_data_unique", models.RecordData{"code": "C001", "name": "Other"}, adminID)
+		require.NoError(t, err)
+
+		// 既に重複した値がある場合は一意制約を付けられない
+		err = executor.SetUniqueConstraint(ctx, "app_data_unique", "code", true)
+		require.Error(t, err)
+		_, ok := repositories.UniqueViolationColumns(err)
+		assert.True(t, ok)
+	})
+
+	t.Run("added column", func(t *testing.T) {
+		field := &models.AppField{FieldCode: "email", FieldName: "Email", FieldType: "text", Options: models.FieldOptions{"unique": true}}
+		require.NoError(t, executor.AddColumn(ctx, "app_data_unique", field))
+
+		_, err := executor.InsertRecord(ctx, "app_data_unique", models.RecordData{"email": "a@example.com"}, adminID)
+		require.NoError(t, err)
+		_, err = executor.InsertRecord(ctx, "app_data_unique", models.RecordData{"email": "a@example.com"}, adminID)
+		columns, ok := repositories.UniqueViolationColumns(err)
+		assert.True(t, ok)
+		assert.Equal(t, []string{"email"}, columns)
+	})
+}
+
+func TestDynamicQueryExecutor_Indexes(t *testing.T) {
+	ctx := context.Background()
+	db, err := testhelpers.GetTestDB(ctx)
+	require.NoError(t, err)
+
+	t.Cleanup(func() {
+		require.NoError(t, testhelpers.ResetDatabase(ctx))
+	})
+
+	executor := repositories.NewDynamicQueryExecutor(db)
+	adminID := getAdminUserID(ctx, t)
+
+	fields := []models.AppField{
+		{FieldCode: "status", FieldName: "Status", FieldType: "select"},
+		{FieldCode: "due_date", FieldName: "Due Date", FieldType: "date"},
+	}
+	require.NoError(t, executor.CreateTable(ctx, "app_data_indexes", fields))
+
+	for _, status := range []string{"open", "open", "closed"} {
+		_, err := executor.InsertRecord(ctx, "app_data_indexes", models.RecordData{"status": status, "due_date": "2024-01-01"}, adminID)
+		require.NoError(t, err)
+	}
+
+	t.Run("composite and partial index", func(t *testing.T) {
+		require.NoError(t, executor.CreateIndex(ctx, "app_data_indexes", &models.AppIndex{
+			IndexName:  "app_data_indexes_status_due_date_idx",
+			FieldCodes: []string{"status", "due_date"},
+		}))
+		require.NoError(t, executor.CreateIndex(ctx, "app_data_indexes", &models.AppIndex{
+			IndexName:  "app_data_indexes_due_date_idx",
+			FieldCodes: []string{"due_date"},
+			Filter:     &models.FilterExpr{FilterItem: models.FilterItem{Field: "status", Operator: models.FilterOpEq, Value: "open"}},
+		}))
+
+		usage, err := executor.GetIndexUsage(ctx, "app_data_indexes")
+		require.NoError(t, err)
+		names := make([]string, len(usage))
+		for i, u := range usage {
+			names[i] = u.IndexName
+			assert.True(t, u.Valid)
+			assert.Positive(t, u.SizeBytes)
+		}
+		assert.ElementsMatch(t, []string{"app_data_indexes_pkey", "app_data_indexes_status_due_date_idx", "app_data_indexes_due_date_idx"}, names)
+	})
+
+	t.Run("failed unique index is dropped", func(t *testing.T) {
+		err := executor.CreateIndex(ctx, "app_data_indexes", &models.AppIndex{
+			IndexName:  "app_data_indexes_status_idx",
+			FieldCodes: []string{"status"},
+			IsUnique:   true,
+		})
+		require.Error(t, err)
+		_, ok := repositories.UniqueViolationColumns(err)
+		assert.True(t, ok)
+
+		usage, err := executor.GetIndexUsage(ctx, "app_data_indexes")
+		require.NoError(t, err)
+		for _, u := range usage {
+			assert.NotEqual(t, "app_data_indexes_status_idx", u.IndexName)
+		}
+	})
+
+	t.Run("drop index", func(t *testing.T) {
+		require.NoError(t, executor.DropIndex(ctx, "app_data_indexes_due_date_idx"))
+		require.NoError(t, executor.DropIndex(ctx, "app_data_indexes_due_date_idx"))
+
+		usage, err := executor.GetIndexUsage(ctx, "app_data_indexes")
+		require.NoError(t, err)
+		assert.Len(t, usage, 2)
+	})
+}
+
+func TestUniqueViolationColumns(t *testing.T) {
+	columns, ok := repositories.UniqueViolationColumns(&pq.Error{Code: "23505", Detail: `Key (code, "branch")=(C001, 1) already exists.`})
+	assert.True(t, ok)
+	assert.Equal(t, []string{"code", "branch"}, columns)
+
+	columns, ok = repositories.UniqueViolationColumns(fmt.Errorf("insert: %w", &pq.Error{Code: "23505"}))
+	assert.True(t, ok)
+	assert.Empty(t, columns)
+
+	_, ok = repositories.UniqueViolationColumns(&pq.Error{Code: "23503"})
+	assert.False(t, ok)
+
+	_, ok = repositories.UniqueViolationColumns(errors.New("db error"))
+	assert.False(t, ok)
+}
+
+func TestDynamicQueryExecutor_ConvertColumn(t *testing.T) {
+	ctx := context.Background()
+	db, err := testhelpers.GetTestDB(ctx)
+	require.NoError(t, err)
+
+	t.Cleanup(func() {
+		require.NoError(t, testhelpers.ResetDatabase(ctx))
+	})
+
+	executor := repositories.NewDynamicQueryExecutor(db)
+	adminID := getAdminUserID(ctx, t)
+	appID := createTestApp(ctx, t, "app_data_convert").ID
+
+	fields := []models.AppField{
+		{AppID: appID, FieldCode: "amount", FieldName: "Amount", FieldType: "text"},
+		{AppID: appID, FieldCode: "tags", FieldName: "Tags", FieldType: "text"},
+		{AppID: appID, FieldCode: "due", FieldName: "Due", FieldType: "text"},
+	}
+	for i := range fields {
+		_, err := db.NewInsert().Model(&fields[i]).Exec(ctx)
+		require.NoError(t, err)
+	}
+	require.NoError(t, executor.CreateTable(ctx, "app_data_convert", fields))
+
+	rows := []models.RecordData{
+		{"amount": "1,200", "tags": "a, b", "due": "2024-01-15"},
+		{"amount": " 35.5 ", "tags": "a", "due": "2024/02/30"},
+		{"amount": "未定", "tags": "c", "due": "来月"},
+		{"amount": "", "tags": nil, "due": nil},
+	}
+	var ids []uint64
+	for _, row := range rows {
+		id, err := executor.InsertRecord(ctx, "app_data_convert", row, adminID)
+		require.NoError(t, err)
+		ids = append(ids, id)
+	}
+
+	t.Run("preview counts convertible values", func(t *testing.T) {
+		to := fields[0]
+		to.FieldType = "number"
+		preview, err := executor.PreviewConversion(ctx, "app_data_convert", &models.FieldConversion{From: fields[0], To: to}, 10)
+		require.NoError(t, err)
+		assert.Equal(t, int64(4), preview.Total)
+		assert.Equal(t, int64(1), preview.Empty)
+		assert.Equal(t, int64(2), preview.Convertible)
+		assert.Equal(t, int64(1), preview.Unconvertible)
+		assert.Equal(t, []models.FieldConversionSample{{RecordID: ids[2], Value: "未定"}}, preview.Samples)
+	})
+
+	t.Run("invalid dates are not convertible", func(t *testing.T) {
+		to := fields[2]
+		to.FieldType = "date"
+		preview, err := executor.PreviewConversion(ctx, "app_data_convert", &models.FieldConversion{From: fields[2], To: to}, 10)
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), preview.Convertible)
+		assert.Equal(t, int64(2), preview.Unconvertible)
+	})
+
+	t.Run("unconvertible values stop conversion", func(t *testing.T) {
+		to := fields[0]
+		to.FieldType = "number"
+		count, err := executor.ConvertColumn(ctx, "app_data_convert", &models.FieldConversion{From: fields[0], To: to})
+		assert.ErrorIs(t, err, repositories.ErrUnconvertibleValues)
+		assert.Equal(t, int64(1), count)
+
+		record, err := executor.GetRecordByID(ctx, "app_data_convert", fields, ids[2])
+		require.NoError(t, err)
+		assert.Equal(t, "未定", record.Data["amount"])
+	})
+
+	t.Run("converts with backup", func(t *testing.T) {
+		to := fields[0]
+		to.FieldType = "number"
+		backup := &models.AppField{AppID: appID, FieldCode: "amount_text", FieldName: "Amount（変換前）", FieldType: "textarea", DisplayOrder: 4}
+		before, err := executor.GetRecordByID(ctx, "app_data_convert", fields, ids[0])
+		require.NoError(t, err)
+
+		count, err := executor.ConvertColumn(ctx, "app_data_convert", &models.FieldConversion{From: fields[0], To: to, Backup: backup})
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), count)
+		assert.NotZero(t, backup.ID)
+
+		converted := []models.AppField{to, fields[1], fields[2], *backup}
+		record, err := executor.GetRecordByID(ctx, "app_data_convert", converted, ids[0])
+		require.NoError(t, err)
+		assert.EqualValues(t, 1200, record.Data["amount"])
+		assert.Nil(t, record.Data["amount_text"])
+		// 値の変換ではレコードの更新日時を変えない
+		assert.Equal(t, before.UpdatedAt, record.UpdatedAt)
+
+		record, err = executor.GetRecordByID(ctx, "app_data_convert", converted, ids[2])
+		require.NoError(t, err)
+		assert.Nil(t, record.Data["amount"])
+		assert.Equal(t, "未定", record.Data["amount_text"])
+
+		saved := new(models.AppField)
+		require.NoError(t, db.NewSelect().Model(saved).Where("af.id = ?", to.ID).Scan(ctx))
+		assert.Equal(t, "number", saved.FieldType)
+	})
+
+	t.Run("text to multiselect with choices", func(t *testing.T) {
+		to := fields[1]
+		to.FieldType = "multiselect"
+		to.Options = models.FieldOptions{"choices": []interface{}{"a", "b"}}
+		count, err := executor.ConvertColumn(ctx, "app_data_convert", &models.FieldConversion{
+			From: fields[1], To: to, Choices: []string{"a", "b"}, DiscardInvalid: true,
+		})
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), count)
+
+		record, err := executor.GetRecordByID(ctx, "app_data_convert", []models.AppField{to}, ids[0])
+		require.NoError(t, err)
+		assert.Equal(t, []interface{}{"a", "b"}, record.Data["tags"])
+
+		// 複数選択から文字列に戻すと区切り文字で連結する
+		back := fields[1]
+		_, err = executor.ConvertColumn(ctx, "app_data_convert", &models.FieldConversion{From: to, To: back})
+		require.NoError(t, err)
+		record, err = executor.GetRecordByID(ctx, "app_data_convert", []models.AppField{back}, ids[0])
+		require.NoError(t, err)
+		assert.Equal(t, "a, b", record.Data["tags"])
+	})
+
+	t.Run("unsupported conversion", func(t *testing.T) {
+		to := fields[2]
+		to.FieldType = "attachment"
+		_, err := executor.ConvertColumn(ctx, "app_data_convert", &models.FieldConversion{From: fields[2], To: to})
+		assert.Error(t, err)
+	})
+}
+
+func TestDynamicQueryExecutor_SetFormulaColumn(t *testing.T) {
+	ctx := context.Background()
+	db, err := testhelpers.GetTestDB(ctx)
+	require.NoError(t, err)
+
+	t.Cleanup(func() {
+		require.NoError(t, testhelpers.ResetDatabase(ctx))
+	})
+
+	executor := repositories.NewDynamicQueryExecutor(db)
+	adminID := getAdminUserID(ctx, t)
+
+	fields := []models.AppField{
+		{FieldCode: "price", FieldName: "Price", FieldType: "number"},
+		{FieldCode: "quantity", FieldName: "Quantity", FieldType: "number"},
+		{FieldCode: "subtotal", FieldName: "Subtotal", FieldType: "formula", Options: models.FieldOptions{"result_type": "number"}},
+	}
+	require.NoError(t, executor.CreateTable(ctx, "app_data_formula", fields))
+	require.NoError(t, executor.SetFormulaColumn(ctx, "app_data_formula", &fields[2], `("price" * "quantity")`))
+
+	for _, data := range []models.RecordData{
+		{"price": 100, "quantity": 2},
+		{"price": 30, "quantity": 1},
+		{"price": 50},
+	} {
+		_, err := executor.InsertRecord(ctx, "app_data_formula", data, adminID)
+		require.NoError(t, err)
+	}
+
+	t.Run("filter and sort by generated column", func(t *testing.T) {
+		records, total, err := executor.GetRecords(ctx, "app_data_formula", fields, repositories.RecordQueryOptions{
+			Page: 1, Limit: 10, Sort: "subtotal", Order: "desc",
+			Filters: []models.FilterItem{{Field: "subtotal", Operator: "gte", Value: "30"}},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), total)
+		require.Len(t, records, 2)
+		assert.Equal(t, "200.0000", records[0].Data["subtotal"])
+		assert.Equal(t, "30.0000", records[1].Data["subtotal"])
+	})
+
+	t.Run("aggregate generated column", func(t *testing.T) {
+		resp, err := executor.GetAggregatedData(ctx, "app_data_formula", &models.ChartDataRequest{
+			XAxis: models.ChartAxis{Field: "price"},
+			YAxis: models.ChartAxis{Field: "subtotal", Aggregation: "sum"},
+		})
+		require.NoError(t, err)
+		assert.NotEmpty(t, resp.Labels)
+	})
+
+	t.Run("replacing expression recomputes values", func(t *testing.T) {
+		require.NoError(t, executor.SetFormulaColumn(ctx, "app_data_formula", &fields[2], `("price" + "quantity")`))
+
+		records, _, err := executor.GetRecords(ctx, "app_data_formula", fields, repositories.RecordQueryOptions{
+			Page: 1, Limit: 10,
+			Filters: []models.FilterItem{{Field: "subtotal", Operator: "gte", Value: "100"}},
+		})
+		require.NoError(t, err)
+		require.Len(t, records, 1)
+		assert.Equal(t, "102.0000", records[0].Data["subtotal"])
+	})
+
+	t.Run("generated column cannot be written", func(t *testing.T) {
+		_, err := executor.InsertRecord(ctx, "app_data_formula", models.RecordData{"price": 1, "subtotal": 5}, adminID)
+		assert.Error(t, err)
+	})
+}
+
+func TestDynamicQueryExecutor_Rollup(t *testing.T) {
+	ctx := context.Background()
+	db, err := testhelpers.GetTestDB(ctx)
+	require.NoError(t, err)
+
+	t.Cleanup(func() {
+		require.NoError(t, testhelpers.ResetDatabase(ctx))
+	})
+
+	executor := repositories.NewDynamicQueryExecutor(db)
+	fieldRepo := repositories.NewFieldRepository(db)
+	adminID := getAdminUserID(ctx, t)
+
+	customers := createTestApp(ctx, t, "app_data_rollup_customers")
+	orders := createTestApp(ctx, t, "app_data_rollup_orders")
+
+	orderFields := []models.AppField{
+		{AppID: orders.ID, FieldCode: "customer", FieldName: "Customer", FieldType: "reference", DisplayOrder: 1,
+			Options: models.FieldOptions{"app_id": customers.ID}, CreatedAt: time.Now(), UpdatedAt: time.Now()},
+		{AppID: orders.ID, FieldCode: "amount", FieldName: "Amount", FieldType: "number", DisplayOrder: 2,
+			CreatedAt: time.Now(), UpdatedAt: time.Now()},
+		{AppID: orders.ID, FieldCode: "status", FieldName: "Status", FieldType: "text", DisplayOrder: 3,
+			CreatedAt: time.Now(), UpdatedAt: time.Now()},
+	}
+	require.NoError(t, fieldRepo.CreateBatch(ctx, orderFields))
+	require.NoError(t, executor.CreateTable(ctx, orders.TableName, orderFields))
+
+	rollup := func(code string, options models.FieldOptions) models.AppField {
+		options["app_id"] = orders.ID
+		options["link_field"] = "customer"
+		return models.AppField{AppID: customers.ID, FieldCode: code, FieldName: code, FieldType: "rollup", Options: options}
+	}
+	customerFields := []models.AppField{
+		{AppID: customers.ID, FieldCode: "name", FieldName: "Name", FieldType: "text"},
+		rollup("order_count", models.FieldOptions{"aggregation": "count"}),
+		rollup("total", models.FieldOptions{"aggregation": "sum", "target_field": "amount"}),
+		rollup("paid_max", models.FieldOptions{"aggregation": "max", "target_field": "amount",
+			"filters": []interface{}{map[string]interface{}{"field": "status", "operator": "eq", "value": "paid"}}}),
+	}
+	require.NoError(t, executor.CreateTable(ctx, customers.TableName, customerFields))
+
+	alice, err := executor.InsertRecord(ctx, customers.TableName, models.RecordData{"name": "Alice"}, adminID)
+	require.NoError(t, err)
+	bob, err := executor.InsertRecord(ctx, customers.TableName, models.RecordData{"name": "Bob"}, adminID)
+	require.NoError(t, err)
+	for _, data := range []models.RecordData{
+		{"customer": alice, "amount": 100, "status": "paid"},
+		{"customer": alice, "amount": 300, "status": "open"},
+		{"customer": alice, "amount": 50, "status": "paid"},
+	} {
+		_, err := executor.InsertRecord(ctx, orders.TableName, data, adminID)
+		require.NoError(t, err)
+	}
+
+	t.Run("aggregates child records per parent", func(t *testing.T) {
+		records, total, err := executor.GetRecords(ctx, customers.TableName, customerFields, repositories.RecordQueryOptions{
+			Page: 1, Limit: 10, Sort: "total", Order: "desc",
+		})
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), total)
+		require.Len(t, records, 2)
+
+		assert.Equal(t, alice, records[0].ID)
+		assert.EqualValues(t, 3, records[0].Data["order_count"])
+		assert.Equal(t, "450.0000", records[0].Data["total"])
+		assert.Equal(t, "100.0000", records[0].Data["paid_max"])
+
+		// 子レコードがない場合、件数と合計は0、それ以外はnull
+		assert.Equal(t, bob, records[1].ID)
+		assert.EqualValues(t, 0, records[1].Data["order_count"])
+		assert.Equal(t, "0", records[1].Data["total"])
+		assert.Nil(t, records[1].Data["paid_max"])
+	})
+
+	t.Run("single record", func(t *testing.T) {
+		record, err := executor.GetRecordByID(ctx, customers.TableName, customerFields, alice)
+		require.NoError(t, err)
+		require.NotNil(t, record)
+		assert.EqualValues(t, 3, record.Data["order_count"])
+	})
+
+	t.Run("unknown child field", func(t *testing.T) {
+		broken := []models.AppField{rollup("broken", models.FieldOptions{"aggregation": "sum", "target_field": "missing"})}
+		_, err := executor.GetRecordByID(ctx, customers.TableName, broken, alice)
+		assert.Error(t, err)
+	})
+}
+
+func TestDynamicQueryExecutor_GetRecordsByIDs(t *testing.T) {
+	ctx := context.Background()
+	db, err := testhelpers.GetTestDB(ctx)
+	require.NoError(t, err)
+
+	t.Cleanup(func() {
+		require.NoError(t, testhelpers.ResetDatabase(ctx))
+	})
+
+	executor := repositories.NewDynamicQueryExecutor(db)
+	adminID := getAdminUserID(ctx, t)
+
+	fields := []models.AppField{
+		{FieldCode: "name", FieldName: "Name", FieldType: "text"},
+	}
+	require.NoError(t, executor.CreateTable(ctx, "app_data_by_ids", fields))
+
+	id1, err := executor.InsertRecord(ctx, "app_data_by_ids", models.RecordData{"name": "A"}, adminID)
+	require.NoError(t, err)
+	_, err = executor.InsertRecord(ctx, "app_data_by_ids", models.RecordData{"name": "B"}, adminID)
+	require.NoError(t, err)
+	id3, err := executor.InsertRecord(ctx, "app_data_by_ids", models.RecordData{"name": "C"}, adminID)
+	require.NoError(t, err)
+
+	records, err := executor.GetRecordsByIDs(ctx, "app_data_by_ids", fields, []uint64{id1, id3, id3 + 100})
+	require.NoError(t, err)
+	require.Len(t, records, 2)
+
+	names := map[uint64]interface{}{}
+	for _, r := range records {
+		names[r.ID] = r.Data["name"]
+	}
+	assert.Equal(t, "A", names[id1])
+	assert.Equal(t, "C", names[id3])
+
+	empty, err := executor.GetRecordsByIDs(ctx, "app_data_by_ids", fields, nil)
+	require.NoError(t, err)
+	assert.Empty(t, empty)
+}
+
 func TestDynamicQueryExecutor_DeleteRecords(t *testing.T) {
 	ctx := context.Background()
 	db, err := testhelpers.GetTestDB(ctx)
@@ -476,6 +1113,153 @@ func TestDynamicQueryExecutor_GetRecords_WithFilters(t *testing.T) {
 	}
 }
 
+func TestDynamicQueryExecutor_GetRecords_Keyset(t *testing.T) {
+	ctx := context.Background()
+	db, err := testhelpers.GetTestDB(ctx)
+	require.NoError(t, err)
+
+	t.Cleanup(func() {
+		require.NoError(t, testhelpers.ResetDatabase(ctx))
+	})
+
+	executor := repositories.NewDynamicQueryExecutor(db)
+	adminID := getAdminUserID(ctx, t)
+
+	fields := []models.AppField{
+		{FieldCode: "name", FieldName: "Name", FieldType: "text"},
+		{FieldCode: "amount", FieldName: "Amount", FieldType: "number"},
+	}
+	require.NoError(t, executor.CreateTable(ctx, "app_data_keyset", fields))
+
+	// 同じ値と NULL を含むレコードを挿入
+	testData := []models.RecordData{
+		{"name": "a", "amount": 300},
+		{"name": "b", "amount": 100},
+		{"name": "c"},
+		{"name": "d", "amount": 200},
+		{"name": "e", "amount": 100},
+		{"name": "f"},
+	}
+	for _, data := range testData {
+		_, insertErr := executor.InsertRecord(ctx, "app_data_keyset", data, adminID)
+		require.NoError(t, insertErr)
+	}
+
+	// 2件ずつ最後までページを送り、OFFSET 方式と同じ順序になることを確認
+	collect := func(t *testing.T, sort, order string) []interface{} {
+		var names []interface{}
+		opts := repositories.RecordQueryOptions{Limit: 2, Sort: sort, Order: order, Keyset: true, Count: repositories.CountNone}
+		for range len(testData) {
+			records, total, err := executor.GetRecords(ctx, "app_data_keyset", fields, opts)
+			require.NoError(t, err)
+			assert.Equal(t, int64(-1), total)
+			if len(records) == 0 {
+				break
+			}
+			for i := range records {
+				names = append(names, records[i].Data["name"])
+			}
+			opts.After, err = models.DecodeRecordCursor(records[len(records)-1].Cursor)
+			require.NoError(t, err)
+		}
+		return names
+	}
+
+	tests := []struct {
+		name  string
+		sort  string
+		order string
+		want  []interface{}
+	}{
+		{name: "IDの降順", want: []interface{}{"f", "e", "d", "c", "b", "a"}},
+		{name: "数値の昇順（NULLは末尾）", sort: "amount", order: "asc", want: []interface{}{"b", "e", "d", "a", "c", "f"}},
+		{name: "数値の降順（NULLは先頭）", sort: "amount", order: "desc", want: []interface{}{"f", "c", "a", "d", "e", "b"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, collect(t, tt.sort, tt.order))
+		})
+	}
+
+	t.Run("推定件数", func(t *testing.T) {
+		_, err := db.ExecContext(ctx, `ANALYZE "app_data_keyset"`)
+		require.NoError(t, err)
+		_, total, err := executor.GetRecords(ctx, "app_data_keyset", fields, repositories.RecordQueryOptions{Page: 1, Limit: 2, Count: repositories.CountEstimate})
+		require.NoError(t, err)
+		assert.Equal(t, int64(len(testData)), total)
+	})
+}
+
+func TestDynamicQueryExecutor_StreamRecords(t *testing.T) {
+	ctx := context.Background()
+	db, err := testhelpers.GetTestDB(ctx)
+	require.NoError(t, err)
+
+	t.Cleanup(func() {
+		require.NoError(t, testhelpers.ResetDatabase(ctx))
+	})
+
+	executor := repositories.NewDynamicQueryExecutor(db)
+	adminID := getAdminUserID(ctx, t)
+
+	fields := []models.AppField{
+		{FieldCode: "category", FieldName: "Category", FieldType: "text"},
+		{FieldCode: "amount", FieldName: "Amount", FieldType: "number"},
+	}
+	require.NoError(t, executor.CreateTable(ctx, "app_data_stream", fields))
+
+	// カーソルからの取得が複数回に分かれる件数を登録する
+	rows := make([]models.RecordData, 0, 1200)
+	for i := 0; i < 1200; i++ {
+		category := "A"
+		if i%3 == 0 {
+			category = "B"
+		}
+		rows = append(rows, models.RecordData{"category": category, "amount": i})
+	}
+	_, err = executor.InsertRecords(ctx, "app_data_stream", rows, adminID)
+	require.NoError(t, err)
+
+	t.Run("streams all records in sort order", func(t *testing.T) {
+		var amounts []float64
+		err := executor.StreamRecords(ctx, "app_data_stream", fields, repositories.RecordQueryOptions{Sort: "amount", Order: "asc"}, func(record *models.RecordResponse) error {
+			amount, ok := record.Data["amount"].(string)
+			require.True(t, ok)
+			n, parseErr := strconv.ParseFloat(amount, 64)
+			require.NoError(t, parseErr)
+			amounts = append(amounts, n)
+			return nil
+		})
+		require.NoError(t, err)
+		require.Len(t, amounts, 1200)
+		assert.Equal(t, float64(0), amounts[0])
+		assert.Equal(t, float64(1199), amounts[1199])
+	})
+
+	t.Run("applies filters", func(t *testing.T) {
+		count := 0
+		opts := repositories.RecordQueryOptions{Filters: []models.FilterItem{{Field: "category", Operator: "eq", Value: "B"}}}
+		err := executor.StreamRecords(ctx, "app_data_stream", fields, opts, func(record *models.RecordResponse) error {
+			assert.Equal(t, "B", record.Data["category"])
+			count++
+			return nil
+		})
+		require.NoError(t, err)
+		assert.Equal(t, 400, count)
+	})
+
+	t.Run("callback error stops streaming", func(t *testing.T) {
+		stop := errors.New("stop")
+		count := 0
+		err := executor.StreamRecords(ctx, "app_data_stream", fields, repositories.RecordQueryOptions{}, func(record *models.RecordResponse) error {
+			count++
+			return stop
+		})
+		assert.ErrorIs(t, err, stop)
+		assert.Equal(t, 1, count)
+	})
+}
+
 func TestDynamicQueryExecutor_GetRecordByID(t *testing.T) {
 	ctx := context.Background()
 	db, err := testhelpers.GetTestDB(ctx)
@@ -631,6 +1415,159 @@ func TestDynamicQueryExecutor_GetAggregatedData(t *testing.T) {
 	}
 }
 
+func TestDynamicQueryExecutor_GetAggregatedData_Series(t *testing.T) {
+	ctx := context.Background()
+	db, err := testhelpers.GetTestDB(ctx)
+	require.NoError(t, err)
+
+	t.Cleanup(func() {
+		require.NoError(t, testhelpers.ResetDatabase(ctx))
+	})
+
+	executor := repositories.NewDynamicQueryExecutor(db)
+	adminID := getAdminUserID(ctx, t)
+
+	fields := []models.AppField{
+		{FieldCode: "category", FieldName: "Category", FieldType: "text"},
+		{FieldCode: "owner", FieldName: "Owner", FieldType: "text"},
+		{FieldCode: "amount", FieldName: "Amount", FieldType: "number"},
+		{FieldCode: "due", FieldName: "Due", FieldType: "date"},
+		{FieldCode: "closed_at", FieldName: "Closed At", FieldType: "datetime"},
+	}
+	require.NoError(t, executor.CreateTable(ctx, "app_data_aggregate_series", fields))
+
+	testData := []models.RecordData{
+		{"category": "A", "owner": "山田", "amount": 100, "due": "2026-01-10", "closed_at": "2026-01-31T16:00:00Z"},
+		{"category": "A", "owner": "佐藤", "amount": 200, "due": "2026-02-10", "closed_at": "2026-02-01T01:00:00Z"},
+		{"category": "B", "owner": "山田", "amount": 300, "due": "2026-04-01", "closed_at": "2026-04-01T00:00:00Z"},
+	}
+	for _, data := range testData {
+		_, insertErr := executor.InsertRecord(ctx, "app_data_aggregate_series", data, adminID)
+		require.NoError(t, insertErr)
+	}
+
+	t.Run("group by returns a dataset per series", func(t *testing.T) {
+		result, err := executor.GetAggregatedData(ctx, "app_data_aggregate_series", &models.ChartDataRequest{
+			XAxis:   models.ChartAxis{Field: "category"},
+			YAxis:   models.ChartAxis{Field: "amount", Aggregation: "sum", Label: "Total"},
+			GroupBy: "owner",
+		})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"A", "B"}, result.Labels)
+		require.Len(t, result.Datasets, 2)
+		assert.Equal(t, models.ChartDataset{Label: "山田", Data: []float64{100, 300}, Stack: "Total"}, result.Datasets[0])
+		assert.Equal(t, models.ChartDataset{Label: "佐藤", Data: []float64{200, 0}, Stack: "Total"}, result.Datasets[1])
+	})
+
+	t.Run("multiple measures", func(t *testing.T) {
+		result, err := executor.GetAggregatedData(ctx, "app_data_aggregate_series", &models.ChartDataRequest{
+			XAxis:    models.ChartAxis{Field: "category"},
+			YAxis:    models.ChartAxis{Aggregation: "count", Label: "Count"},
+			Measures: []models.ChartAxis{{Field: "amount", Aggregation: "avg", Label: "Average"}},
+		})
+		require.NoError(t, err)
+		require.Len(t, result.Datasets, 2)
+		assert.Equal(t, []float64{2, 1}, result.Datasets[0].Data)
+		assert.Equal(t, []float64{150, 300}, result.Datasets[1].Data)
+	})
+
+	t.Run("date bucket by quarter", func(t *testing.T) {
+		result, err := executor.GetAggregatedData(ctx, "app_data_aggregate_series", &models.ChartDataRequest{
+			XAxis: models.ChartAxis{Field: "due", Bucket: "quarter", DateOnly: true},
+			YAxis: models.ChartAxis{Aggregation: "count", Label: "Count"},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"2026-Q1", "2026-Q2"}, result.Labels)
+		assert.Equal(t, []float64{2, 1}, result.Datasets[0].Data)
+	})
+
+	t.Run("datetime bucket in time zone", func(t *testing.T) {
+		result, err := executor.GetAggregatedData(ctx, "app_data_aggregate_series", &models.ChartDataRequest{
+			XAxis:    models.ChartAxis{Field: "closed_at", Bucket: "month"},
+			YAxis:    models.ChartAxis{Aggregation: "count", Label: "Count"},
+			TimeZone: "Asia/Tokyo",
+		})
+		require.NoError(t, err)
+		// 2026-01-31T16:00:00Z は日本時間では2月
+		assert.Equal(t, []string{"2026-02", "2026-04"}, result.Labels)
+		assert.Equal(t, []float64{2, 1}, result.Datasets[0].Data)
+	})
+}
+
+func TestDynamicQueryExecutor_GetPivotData(t *testing.T) {
+	ctx := context.Background()
+	db, err := testhelpers.GetTestDB(ctx)
+	require.NoError(t, err)
+
+	t.Cleanup(func() {
+		require.NoError(t, testhelpers.ResetDatabase(ctx))
+	})
+
+	executor := repositories.NewDynamicQueryExecutor(db)
+	adminID := getAdminUserID(ctx, t)
+
+	fields := []models.AppField{
+		{FieldCode: "region", FieldName: "Region", FieldType: "text"},
+		{FieldCode: "status", FieldName: "Status", FieldType: "text"},
+		{FieldCode: "amount", FieldName: "Amount", FieldType: "number"},
+		{FieldCode: "due", FieldName: "Due", FieldType: "date"},
+	}
+	require.NoError(t, executor.CreateTable(ctx, "app_data_pivot", fields))
+
+	testData := []models.RecordData{
+		{"region": "East", "status": "open", "amount": 100, "due": "2026-01-10"},
+		{"region": "East", "status": "open", "amount": 200, "due": "2026-02-10"},
+		{"region": "West", "status": "closed", "amount": 300, "due": "2026-02-20"},
+		{"region": "West", "status": "open", "amount": 400, "due": "2026-02-25"},
+	}
+	for _, data := range testData {
+		_, insertErr := executor.InsertRecord(ctx, "app_data_pivot", data, adminID)
+		require.NoError(t, insertErr)
+	}
+	num := func(v float64) *float64 { return &v }
+
+	t.Run("rows and columns with totals", func(t *testing.T) {
+		result, err := executor.GetPivotData(ctx, "app_data_pivot", &models.PivotRequest{
+			Rows:     []models.PivotDimension{{Field: "region"}},
+			Columns:  []models.PivotDimension{{Field: "status"}},
+			Measures: []models.PivotMeasure{{Field: "amount", Aggregation: "sum"}},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, []models.PivotHeader{
+			{Keys: []string{"closed"}},
+			{Keys: []string{"open"}},
+			{Keys: []string{}, Total: true},
+		}, result.Columns)
+		require.Len(t, result.Rows, 3)
+		assert.Equal(t, []string{"East"}, result.Rows[0].Keys)
+		assert.Equal(t, [][]*float64{{nil}, {num(300)}, {num(300)}}, result.Rows[0].Values)
+		assert.Equal(t, [][]*float64{{num(300)}, {num(400)}, {num(700)}}, result.Rows[1].Values)
+		assert.True(t, result.Rows[2].Total)
+		assert.Equal(t, [][]*float64{{num(300)}, {num(700)}, {num(1000)}}, result.Rows[2].Values)
+	})
+
+	t.Run("nested rows with subtotals and date bucket", func(t *testing.T) {
+		result, err := executor.GetPivotData(ctx, "app_data_pivot", &models.PivotRequest{
+			Rows: []models.PivotDimension{
+				{Field: "due", Bucket: "month", DateOnly: true},
+				{Field: "region"},
+			},
+			Measures: []models.PivotMeasure{{Aggregation: "count"}, {Field: "amount", Aggregation: "avg"}},
+			Filters:  []models.FilterItem{{Field: "amount", Operator: "gte", Value: "200"}},
+		})
+		require.NoError(t, err)
+		keys := make([][]string, len(result.Rows))
+		for i, row := range result.Rows {
+			keys[i] = row.Keys
+		}
+		assert.Equal(t, [][]string{
+			{"2026-02", "East"}, {"2026-02", "West"}, {"2026-02"}, {},
+		}, keys)
+		// 平均の小計は明細の平均ではなく元のレコードから求める
+		assert.Equal(t, [][]*float64{{num(3), num(300)}}, result.Rows[2].Values)
+	})
+}
+
 func TestDynamicQueryExecutor_GetAggregatedData_WithFilter(t *testing.T) {
 	ctx := context.Background()
 	db, err := testhelpers.GetTestDB(ctx)
@@ -203,6 +203,7 @@ func (e *ExternalQueryExecutor) GetRecords(ctx context.Context, ds *models.DataS
 
 	// カラムリストを構築（source_column_nameを使用）
 	columns := make([]string, 0, len(fields))
+	fieldCodeToColumn := make(map[string]string, len(fields))
 	for _, f := range fields {
 		colName := f.FieldCode
 		if f.SourceColumnName != nil && *f.SourceColumnName != "" {
@@ -212,20 +213,30 @@ func (e *ExternalQueryExecutor) GetRecords(ctx context.Context, ds *models.DataS
 		if colErr != nil {
 			return nil, 0, fmt.Errorf("無効なカラム名 %q: %w", colName, colErr)
 		}
+		fieldCodeToColumn[f.FieldCode] = quotedCol
 		columns = append(columns, quotedCol)
 	}
 
+	whereSQL, whereValues, err := buildExternalWhereClause(ds.DBType, fieldCodeToColumn, opts.Filters, opts.Filter)
+	if err != nil {
+		return nil, 0, err
+	}
+
 	// COUNT クエリ
-	countQuery := fmt.Sprintf("SELECT COUNT(*) FROM %s", quotedTable)
-	var total int64
-	if err := db.QueryRowContext(ctx, countQuery).Scan(&total); err != nil {
+	total, err := countRecords(ctx, db, opts.Count, quotedTable, whereSQL, whereValues, getPlaceholder(ds.DBType, 1))
+	if err != nil {
 		return nil, 0, fmt.Errorf("レコード数の取得に失敗しました: %w", err)
 	}
 
+	if opts.Keyset {
+		return e.getRecordsByKeyset(ctx, db, ds.DBType, quotedTable, columns, fieldCodeToColumn, whereSQL, whereValues, fields, opts, total)
+	}
+
 	// メインクエリ
-	query := fmt.Sprintf("SELECT %s FROM %s",
+	query := fmt.Sprintf("SELECT %s FROM %s %s",
 		strings.Join(columns, ", "),
-		quotedTable)
+		quotedTable,
+		whereSQL)
 
 	// ORDER BY
 	if opts.Sort != "" {
@@ -254,7 +265,7 @@ func (e *ExternalQueryExecutor) GetRecords(ctx context.Context, ds *models.DataS
 	offset := (opts.Page - 1) * opts.Limit
 	query += buildLimitOffset(ds.DBType, opts.Limit, offset)
 
-	rows, err := db.QueryContext(ctx, query)
+	rows, err := db.QueryContext(ctx, query, whereValues...)
 	if err != nil {
 		return nil, 0, fmt.Errorf("レコードの取得に失敗しました: %w", err)
 	}
@@ -276,6 +287,80 @@ func (e *ExternalQueryExecutor) GetRecords(ctx context.Context, ds *models.DataS
 	return records, total, nil
 }
 
+// getRecordsByKeyset 外部テーブルからカーソル方式でレコードを取得する
+// レコードを区別するため、フィールドコード id のフィールド（整数のカラム）が必要
+func (e *ExternalQueryExecutor) getRecordsByKeyset(
+	ctx context.Context,
+	db *sql.DB,
+	dbType models.DBType,
+	quotedTable string,
+	columns []string,
+	fieldCodeToColumn map[string]string,
+	whereSQL string,
+	whereValues []interface{},
+	fields []models.AppField,
+	opts RecordQueryOptions,
+	total int64,
+) ([]models.RecordResponse, int64, error) {
+	quotedID, ok := fieldCodeToColumn["id"]
+	if !ok {
+		return nil, 0, fmt.Errorf("カーソル方式のページ送りには、フィールドコード id のフィールドが必要です")
+	}
+	sort, quotedSort, order := "", "", "desc"
+	if opts.Sort != "" {
+		sort, order = opts.Sort, "asc"
+		if quotedSort, ok = fieldCodeToColumn[opts.Sort]; !ok {
+			return nil, 0, fmt.Errorf("無効なソートカラム %q", opts.Sort)
+		}
+		if opts.Order == "desc" {
+			order = "desc"
+		}
+	}
+
+	keyset := buildRecordKeyset(sort, quotedSort, quotedID, order, opts.After, numberedPlaceholders(dbType, len(whereValues)+1))
+	if keyset.where != "" {
+		whereSQL = appendWhere(whereSQL, keyset.where)
+		whereValues = append(whereValues, keyset.whereValues...)
+	}
+	if keyset.column != "" {
+		columns = append(columns, keyset.column+" AS _cursor")
+	}
+
+	query := fmt.Sprintf("SELECT %s FROM %s %s ORDER BY %s LIMIT %d",
+		strings.Join(columns, ", "),
+		quotedTable,
+		whereSQL,
+		keyset.orderBy,
+		opts.Limit)
+
+	rows, err := db.QueryContext(ctx, query, whereValues...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("レコードの取得に失敗しました: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var records []models.RecordResponse
+	for rows.Next() {
+		var cursorValue sql.NullString
+		var extra []interface{}
+		if keyset.column != "" {
+			extra = append(extra, &cursorValue)
+		}
+		record, scanErr := scanExternalRecordRow(rows, fields, extra...)
+		if scanErr != nil {
+			return nil, 0, scanErr
+		}
+		record.Cursor = keyset.cursorAfter(record.ID, cursorValue)
+		records = append(records, *record)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	return records, total, nil
+}
+
 // GetRecordByID 外部テーブルから単一のレコードを取得する
 func (e *ExternalQueryExecutor) GetRecordByID(ctx context.Context, ds *models.DataSource, password string, tableName string, fields []models.AppField, recordID uint64) (*models.RecordResponse, error) {
 	db, err := openConnection(ctx, ds, password)
@@ -358,6 +443,103 @@ func (e *ExternalQueryExecutor) GetRecordByID(ctx context.Context, ds *models.Da
 	}, nil
 }
 
+// StreamRecords 外部テーブルのレコードをサーバーサイドカーソルで先頭から順に取得する
+// フィルターとソートはフィールドコードで指定し、source_column_nameに読み替えて適用する
+func (e *ExternalQueryExecutor) StreamRecords(ctx context.Context, ds *models.DataSource, password string, tableName string, fields []models.AppField, opts RecordQueryOptions, fn RecordStreamFunc) error {
+	db, err := openConnection(ctx, ds, password)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = db.Close() }()
+
+	quotedTable, err := quoteIdentifierForDB(tableName)
+	if err != nil {
+		return fmt.Errorf("無効なテーブル名: %w", err)
+	}
+
+	// field_codeからsource_column_nameへのマッピングを構築
+	fieldCodeToColumn := make(map[string]string, len(fields))
+	columns := make([]string, 0, len(fields))
+	for _, f := range fields {
+		colName := f.FieldCode
+		if f.SourceColumnName != nil && *f.SourceColumnName != "" {
+			colName = *f.SourceColumnName
+		}
+		quotedCol, colErr := quoteIdentifierForDB(colName)
+		if colErr != nil {
+			return fmt.Errorf("無効なカラム名 %q: %w", colName, colErr)
+		}
+		fieldCodeToColumn[f.FieldCode] = quotedCol
+		columns = append(columns, quotedCol)
+	}
+
+	whereSQL, whereValues, err := buildExternalWhereClause(ds.DBType, fieldCodeToColumn, opts.Filters, opts.Filter)
+	if err != nil {
+		return err
+	}
+
+	query := fmt.Sprintf("DECLARE record_stream NO SCROLL CURSOR FOR SELECT %s FROM %s %s",
+		strings.Join(columns, ", "),
+		quotedTable,
+		whereSQL)
+
+	if opts.Sort != "" {
+		quotedSort, ok := fieldCodeToColumn[opts.Sort]
+		if !ok {
+			return fmt.Errorf("無効なソートカラム %q", opts.Sort)
+		}
+		order := "ASC"
+		if opts.Order == "desc" {
+			order = "DESC"
+		}
+		query += fmt.Sprintf(" ORDER BY %s %s", quotedSort, order)
+	}
+
+	// カーソルはトランザクション内でのみ有効
+	tx, err := db.BeginTx(ctx, &sql.TxOptions{ReadOnly: true})
+	if err != nil {
+		return fmt.Errorf("トランザクション開始に失敗しました: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if _, err := tx.ExecContext(ctx, query, whereValues...); err != nil {
+		return fmt.Errorf("レコードの取得に失敗しました: %w", err)
+	}
+
+	return fetchFromCursor(ctx, tx, "record_stream", func(rows *sql.Rows) error {
+		record, err := scanExternalRecordRow(rows, fields)
+		if err != nil {
+			return err
+		}
+		return fn(record)
+	})
+}
+
+// buildExternalWhereClause フィルターから外部DB用のWHERE句を構築する
+// columns はフィールドコードからクォート済みカラム名への対応表
+func buildExternalWhereClause(dbType models.DBType, columns map[string]string, filters []models.FilterItem, expr *models.FilterExpr) (string, []interface{}, error) {
+	builder := newFilterBuilder(
+		func(field string) (string, error) {
+			quotedCol, ok := columns[field]
+			if !ok {
+				return "", fmt.Errorf("無効なフィルターフィールド %q", field)
+			}
+			return quotedCol, nil
+		},
+		func(index int) string {
+			return getPlaceholder(dbType, index)
+		},
+	)
+	clause, err := builder.build(filters, expr)
+	if err != nil {
+		return "", nil, err
+	}
+	if clause == "" {
+		return "", nil, nil
+	}
+	return "WHERE " + clause, builder.values, nil
+}
+
 // GetAggregatedData 外部テーブルから集計データを取得する
 func (e *ExternalQueryExecutor) GetAggregatedData(ctx context.Context, ds *models.DataSource, password string, tableName string, fields []models.AppField, req *models.ChartDataRequest) (*models.ChartDataResponse, error) {
 	db, err := openConnection(ctx, ds, password)
@@ -376,89 +558,121 @@ func (e *ExternalQueryExecutor) GetAggregatedData(ctx context.Context, ds *model
 		}
 	}
 
-	xColumnName, ok := fieldCodeToColumn[req.XAxis.Field]
-	if !ok {
-		return nil, fmt.Errorf("x-axis field '%s' not found", req.XAxis.Field)
-	}
-	xField, err := quoteIdentifierForDB(xColumnName)
+	quotedTable, err := quoteIdentifierForDB(tableName)
 	if err != nil {
-		return nil, fmt.Errorf("無効なX軸フィールド %q: %w", xColumnName, err)
+		return nil, fmt.Errorf("無効なテーブル名: %w", err)
 	}
 
-	var selectClause string
-	switch req.YAxis.Aggregation {
-	case "count":
-		selectClause = fmt.Sprintf("%s, COUNT(*) as value", xField)
-	case "sum", "avg", "min", "max":
-		yColumnName, ok := fieldCodeToColumn[req.YAxis.Field]
-		if !ok {
-			return nil, fmt.Errorf("y-axis field '%s' not found", req.YAxis.Field)
+	// フィールドコードをクォート済みのカラム名に対応付ける
+	quotedColumns := make(map[string]string, len(fieldCodeToColumn))
+	for code, colName := range fieldCodeToColumn {
+		quotedCol, colErr := quoteIdentifierForDB(colName)
+		if colErr != nil {
+			return nil, fmt.Errorf("無効なカラム名 %q: %w", colName, colErr)
 		}
-		yField, yErr := quoteIdentifierForDB(yColumnName)
-		if yErr != nil {
-			return nil, fmt.Errorf("無効なY軸フィールド %q: %w", yColumnName, yErr)
+		quotedColumns[code] = quotedCol
+	}
+	column := func(field string) (string, error) {
+		quotedCol, ok := quotedColumns[field]
+		if !ok {
+			return "", fmt.Errorf("field '%s' not found", field)
 		}
-		selectClause = fmt.Sprintf("%s, %s(%s) as value", xField, strings.ToUpper(req.YAxis.Aggregation), yField)
-	default:
-		selectClause = fmt.Sprintf("%s, COUNT(*) as value", xField)
+		return quotedCol, nil
 	}
 
-	quotedTable, err := quoteIdentifierForDB(tableName)
+	// 外部テーブルの日時はタイムゾーン付きの型を想定し、タイムゾーンなしの型はセッションのタイムゾーンで解釈する
+	builder := newChartQueryBuilder(req, column, func(quotedColumn string) string {
+		return fmt.Sprintf("(%s)::timestamptz", quotedColumn)
+	})
+	selectClause, groupBy, err := builder.build()
 	if err != nil {
-		return nil, fmt.Errorf("無効なテーブル名: %w", err)
+		return nil, err
+	}
+
+	whereSQL, whereValues, err := buildExternalWhereClause(ds.DBType, quotedColumns, req.Filters, req.Filter)
+	if err != nil {
+		return nil, err
 	}
 
-	query := fmt.Sprintf("SELECT %s FROM %s GROUP BY %s ORDER BY %s",
+	query := fmt.Sprintf("SELECT %s FROM %s %s GROUP BY %s ORDER BY %s",
 		selectClause,
 		quotedTable,
-		xField,
-		xField)
+		whereSQL,
+		groupBy,
+		groupBy)
 
-	rows, err := db.QueryContext(ctx, query)
+	rows, err := db.QueryContext(ctx, query, whereValues...)
 	if err != nil {
 		return nil, fmt.Errorf("集計データの取得に失敗しました: %w", err)
 	}
 	defer func() { _ = rows.Close() }()
 
-	var labels []string
-	var data []float64
+	table, err := builder.scan(rows)
+	if err != nil {
+		return nil, err
+	}
+	return builder.response(table), nil
+}
 
-	for rows.Next() {
-		var label interface{}
-		var value float64
-		if err := rows.Scan(&label, &value); err != nil {
-			return nil, err
-		}
+// GetPivotData 外部テーブルからピボット集計（小計・総計を含む）のデータを取得する
+func (e *ExternalQueryExecutor) GetPivotData(ctx context.Context, ds *models.DataSource, password string, tableName string, fields []models.AppField, req *models.PivotRequest) (*models.PivotResponse, error) {
+	db, err := openConnection(ctx, ds, password)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = db.Close() }()
+
+	quotedTable, err := quoteIdentifierForDB(tableName)
+	if err != nil {
+		return nil, fmt.Errorf("無効なテーブル名: %w", err)
+	}
 
-		var labelStr string
-		switch v := label.(type) {
-		case nil:
-			labelStr = "(空)"
-		case []byte:
-			labelStr = string(v)
-		case string:
-			labelStr = v
-		default:
-			labelStr = fmt.Sprintf("%v", v)
+	// フィールドコードをクォート済みのカラム名に対応付ける
+	quotedColumns := make(map[string]string, len(fields))
+	for _, f := range fields {
+		colName := f.FieldCode
+		if f.SourceColumnName != nil && *f.SourceColumnName != "" {
+			colName = *f.SourceColumnName
+		}
+		quotedCol, colErr := quoteIdentifierForDB(colName)
+		if colErr != nil {
+			return nil, fmt.Errorf("無効なカラム名 %q: %w", colName, colErr)
 		}
+		quotedColumns[f.FieldCode] = quotedCol
+	}
+	column := func(field string) (string, error) {
+		quotedCol, ok := quotedColumns[field]
+		if !ok {
+			return "", fmt.Errorf("field '%s' not found", field)
+		}
+		return quotedCol, nil
+	}
+
+	builder := newPivotQueryBuilder(req, column, func(quotedColumn string) string {
+		return fmt.Sprintf("(%s)::timestamptz", quotedColumn)
+	})
 
-		labels = append(labels, labelStr)
-		data = append(data, value)
+	whereSQL, whereValues, err := buildExternalWhereClause(ds.DBType, quotedColumns, req.Filters, req.Filter)
+	if err != nil {
+		return nil, err
 	}
 
-	if err := rows.Err(); err != nil {
+	query, err := builder.query(quotedTable, whereSQL)
+	if err != nil {
 		return nil, err
 	}
 
-	return &models.ChartDataResponse{
-		Labels: labels,
-		Datasets: []models.ChartDataset{
-			{
-				Label: req.YAxis.Label,
-				Data:  data,
-			},
-		},
-	}, nil
+	rows, err := db.QueryContext(ctx, query, whereValues...)
+	if err != nil {
+		return nil, fmt.Errorf("集計データの取得に失敗しました: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	results, err := builder.scan(rows)
+	if err != nil {
+		return nil, err
+	}
+	return builder.response(results), nil
 }
 
 // CountRecords 外部テーブルのレコード数を取得する
@@ -515,14 +729,15 @@ func buildLimitOffset(_ models.DBType, limit, offset int) string {
 }
 
 // scanExternalRecordRow 外部DBの行からレコードをスキャンする
-func scanExternalRecordRow(rows *sql.Rows, fields []models.AppField) (*models.RecordResponse, error) {
+// extra にはフィールドの後ろに追加で取得したカラムのスキャン先を渡す
+func scanExternalRecordRow(rows *sql.Rows, fields []models.AppField, extra ...interface{}) (*models.RecordResponse, error) {
 	fieldValues := make([]interface{}, len(fields))
-	fieldPtrs := make([]interface{}, len(fields))
+	fieldPtrs := make([]interface{}, len(fields), len(fields)+len(extra))
 	for i := range fieldValues {
 		fieldPtrs[i] = &fieldValues[i]
 	}
 
-	if err := rows.Scan(fieldPtrs...); err != nil {
+	if err := rows.Scan(append(fieldPtrs, extra...)...); err != nil {
 		return nil, fmt.Errorf("レコードのスキャンに失敗しました: %w", err)
 	}
 
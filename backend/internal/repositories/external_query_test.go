@@ -119,6 +119,34 @@ func TestBuildLimitOffset(t *testing.T) {
 	}
 }
 
+// TestBuildExternalWhereClause 外部DB用のWHERE句の構築をテストする
+func TestBuildExternalWhereClause(t *testing.T) {
+	columns := map[string]string{"name": `"顧客名"`, "amount": `"amount"`}
+
+	t.Run("filters are mapped to source columns", func(t *testing.T) {
+		whereSQL, values, err := buildExternalWhereClause(models.DBTypePostgreSQL, columns, []models.FilterItem{
+			{Field: "name", Operator: "like", Value: "山"},
+			{Field: "amount", Operator: "gte", Value: "100"},
+			{Field: "amount", Operator: "unknown", Value: "1"},
+		}, nil)
+		assert.NoError(t, err)
+		assert.Equal(t, `WHERE "顧客名" LIKE $1 AND "amount" >= $2`, whereSQL)
+		assert.Equal(t, []interface{}{"%山%", "100"}, values)
+	})
+
+	t.Run("no filters", func(t *testing.T) {
+		whereSQL, values, err := buildExternalWhereClause(models.DBTypePostgreSQL, columns, nil, nil)
+		assert.NoError(t, err)
+		assert.Empty(t, whereSQL)
+		assert.Nil(t, values)
+	})
+
+	t.Run("unknown field", func(t *testing.T) {
+		_, _, err := buildExternalWhereClause(models.DBTypePostgreSQL, columns, []models.FilterItem{{Field: "missing", Operator: "eq", Value: "x"}}, nil)
+		assert.Error(t, err)
+	})
+}
+
 // TestBuildDSN PostgreSQL の DSN 構築と非対応 DB のエラーをテストする
 func TestBuildDSN(t *testing.T) {
 	tests := []struct {
@@ -0,0 +1,322 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/uptrace/bun"
+
+	"nocode-app/backend/internal/models"
+)
+
+// 文字列から変換する値として受け付ける形式
+const (
+	conversionNumberPattern   = `^[+-]?([0-9]+(\.[0-9]*)?|\.[0-9]+)$`
+	conversionDatePattern     = `^[0-9]{4}[-/][0-9]{1,2}[-/][0-9]{1,2}$`
+	conversionDatetimePattern = `^[0-9]{4}[-/][0-9]{1,2}[-/][0-9]{1,2}([ T][0-9]{1,2}:[0-9]{2}(:[0-9]{2}(\.[0-9]+)?)?)?$`
+)
+
+// ErrUnconvertibleValues 変換できない値を保存も破棄もしない変換で、変換できない値があった場合のエラー
+var ErrUnconvertibleValues = errors.New("変換できない値があります")
+
+// conversionColumn フィールドの種類の変換中に変換後の値を書き込む一時的なカラム
+const conversionColumn = `"__field_conversion"`
+
+// sqlExpr 引数を伴うSQL式
+// 引数は bun がリテラルとしてインライン化する（ALTER TABLE ... USING にはパラメータを使えない）
+type sqlExpr struct {
+	sql  string
+	args []interface{}
+}
+
+// exprf 書式の %s に順に式を埋め込み、引数を埋め込んだ順に連結する
+// 書式では各 %s を1回ずつ、前から順に使うこと
+func exprf(format string, parts ...sqlExpr) sqlExpr {
+	strs := make([]interface{}, len(parts))
+	var args []interface{}
+	for i, p := range parts {
+		strs[i] = p.sql
+		args = append(args, p.args...)
+	}
+	return sqlExpr{sql: fmt.Sprintf(format, strs...), args: args}
+}
+
+// literal 値をリテラルとして埋め込む式を返す
+func literal(value interface{}) sqlExpr {
+	return sqlExpr{sql: "?", args: []interface{}{value}}
+}
+
+// columnConversion フィールドの値の変換を表すSQL式
+type columnConversion struct {
+	// raw 変換前の値の文字列表現（変換できない値のバックアップに使う）
+	raw sqlExpr
+	// empty 変換前の値が未入力かどうか
+	empty sqlExpr
+	// value 変換後の値（未入力でない値を変換できない場合はNULL）
+	value sqlExpr
+}
+
+// using ALTER COLUMN ... TYPE ... USING に指定する式（未入力と変換できない値はNULLになる）
+func (c *columnConversion) using() sqlExpr {
+	return exprf("CASE WHEN %s THEN NULL ELSE %s END", c.empty, c.value)
+}
+
+// unconvertible 未入力でない値を変換できないかどうかの条件
+func (c *columnConversion) unconvertible() sqlExpr {
+	return exprf("(NOT %s AND (%s) IS NULL)", c.empty, c.value)
+}
+
+// newColumnConversion フィールドの種類の変換規則からカラムの値を変換するSQL式を組み立てる
+// 変換後の値は GetPostgresColumnType のカラム型の範囲に収まるもののみとし、choices が空でない場合は選択肢にある値のみとする
+func newColumnConversion(conversion *models.FieldConversion) (*columnConversion, error) {
+	from, to := &conversion.From, &conversion.To
+	if !from.CanConvertTo(models.FieldType(to.FieldType)) {
+		return nil, fmt.Errorf("フィールドの種類 %s を %s に変換できません", from.FieldType, to.FieldType)
+	}
+
+	quotedCol, err := quoteIdentifier(from.FieldCode)
+	if err != nil {
+		return nil, fmt.Errorf("無効なカラム名: %w", err)
+	}
+	col := sqlExpr{sql: quotedCol}
+
+	c := &columnConversion{}
+	switch models.FieldType(from.FieldType) {
+	case models.FieldTypeMultiSelect:
+		c.raw = exprf("(SELECT string_agg(e.v, ', ' ORDER BY e.n) FROM jsonb_array_elements_text(%s) WITH ORDINALITY AS e(v, n))", col)
+		c.empty = exprf("(COALESCE(jsonb_array_length(CASE WHEN jsonb_typeof(%s) = 'array' THEN %s END), 0) = 0)", col, col)
+	case models.FieldTypeNumber:
+		c.raw = exprf("trim_scale(%s)::text", col)
+		c.empty = exprf("(%s IS NULL)", col)
+	case models.FieldTypeDate:
+		c.raw = exprf("to_char(%s, 'YYYY-MM-DD')", col)
+		c.empty = exprf("(%s IS NULL)", col)
+	case models.FieldTypeDateTime:
+		c.raw = exprf(`to_char(%s, 'YYYY-MM-DD"T"HH24:MI:SS')`, col)
+		c.empty = exprf("(%s IS NULL)", col)
+	case models.FieldTypeCheckbox:
+		c.raw = exprf("%s::text", col)
+		c.empty = exprf("(%s IS NULL)", col)
+	default:
+		c.raw = exprf("%s::text", col)
+		c.empty = exprf("(btrim(COALESCE(%s, '')) = '')", col)
+	}
+	trimmed := exprf("btrim(%s)", c.raw)
+
+	var choices sqlExpr
+	if len(conversion.Choices) > 0 {
+		choices = literal(bun.In(conversion.Choices))
+	}
+
+	switch models.FieldType(to.FieldType) {
+	case models.FieldTypeText:
+		c.value = exprf("CASE WHEN char_length(%s) <= 255 THEN %s END", c.raw, c.raw)
+	case models.FieldTypeLink:
+		c.value = exprf("CASE WHEN char_length(%s) <= 500 THEN %s END", c.raw, c.raw)
+	case models.FieldTypeTextArea:
+		c.value = c.raw
+	case models.FieldTypeNumber:
+		if models.FieldType(from.FieldType) == models.FieldTypeCheckbox {
+			c.value = exprf("CASE WHEN %s THEN 1 ELSE 0 END", col)
+			break
+		}
+		// 桁区切りのカンマは取り除く
+		number := exprf("replace(%s, ',', '')", trimmed)
+		c.value = exprf("CASE WHEN %s ~ %s AND pg_input_is_valid(%s, 'numeric(18,4)') THEN (%s)::numeric(18,4) END",
+			number, literal(conversionNumberPattern), number, number)
+	case models.FieldTypeDate:
+		if models.FieldType(from.FieldType) == models.FieldTypeDateTime {
+			c.value = exprf("%s::date", col)
+			break
+		}
+		c.value = exprf("CASE WHEN %s ~ %s AND pg_input_is_valid(%s, 'date') THEN (%s)::date END",
+			trimmed, literal(conversionDatePattern), trimmed, trimmed)
+	case models.FieldTypeDateTime:
+		if models.FieldType(from.FieldType) == models.FieldTypeDate {
+			c.value = exprf("%s::timestamp", col)
+			break
+		}
+		c.value = exprf("CASE WHEN %s ~ %s AND pg_input_is_valid(%s, 'timestamp') THEN (%s)::timestamp END",
+			trimmed, literal(conversionDatetimePattern), trimmed, trimmed)
+	case models.FieldTypeCheckbox:
+		if models.FieldType(from.FieldType) == models.FieldTypeNumber {
+			c.value = exprf("(%s <> 0)", col)
+			break
+		}
+		// レコード入力値の検証（strconv.ParseBool）と同じ表記を受け付ける
+		c.value = exprf("CASE WHEN lower(%s) IN ('1', 't', 'true') THEN true WHEN lower(%s) IN ('0', 'f', 'false') THEN false END",
+			trimmed, trimmed)
+	case models.FieldTypeSelect, models.FieldTypeRadio:
+		choice := trimmed
+		if models.FieldType(from.FieldType) == models.FieldTypeMultiSelect {
+			// 選択が1つだけの値のみ変換できる
+			choice = exprf("CASE WHEN jsonb_array_length(%s) = 1 THEN btrim(%s->>0) END", col, col)
+		}
+		if choices.sql != "" {
+			c.value = exprf("CASE WHEN char_length(%s) <= 255 AND %s IN (%s) THEN %s END", choice, choice, choices, choice)
+		} else {
+			c.value = exprf("CASE WHEN char_length(%s) <= 255 THEN %s END", choice, choice)
+		}
+	case models.FieldTypeMultiSelect:
+		switch models.FieldType(from.FieldType) {
+		case models.FieldTypeSelect, models.FieldTypeRadio:
+			if choices.sql != "" {
+				c.value = exprf("CASE WHEN %s IN (%s) THEN jsonb_build_array(%s) END", trimmed, choices, trimmed)
+			} else {
+				c.value = exprf("jsonb_build_array(%s)", trimmed)
+			}
+		default:
+			// 文字列はカンマで区切った各値を選択肢とする
+			items := exprf("(SELECT jsonb_agg(btrim(e.v) ORDER BY e.n) FROM unnest(string_to_array(%s, ',')) WITH ORDINALITY AS e(v, n) WHERE btrim(e.v) <> '')", trimmed)
+			if choices.sql != "" {
+				c.value = exprf("CASE WHEN NOT EXISTS (SELECT 1 FROM unnest(string_to_array(%s, ',')) AS e(v) WHERE btrim(e.v) <> '' AND btrim(e.v) NOT IN (%s)) THEN %s END",
+					trimmed, choices, items)
+			} else {
+				c.value = items
+			}
+		}
+	default:
+		return nil, fmt.Errorf("フィールドの種類 %s には変換できません", to.FieldType)
+	}
+	return c, nil
+}
+
+// PreviewConversion フィールドの種類を変換した場合に値を変換できるレコード数と、変換できない値の例を取得する
+func (e *DynamicQueryExecutor) PreviewConversion(ctx context.Context, tableName string, conversion *models.FieldConversion, sampleLimit int) (*models.FieldConversionPreview, error) {
+	quotedTable, err := quoteIdentifier(tableName)
+	if err != nil {
+		return nil, fmt.Errorf("無効なテーブル名: %w", err)
+	}
+
+	c, err := newColumnConversion(conversion)
+	if err != nil {
+		return nil, err
+	}
+
+	preview := &models.FieldConversionPreview{
+		FieldType: conversion.To.FieldType,
+		Samples:   []models.FieldConversionSample{},
+	}
+	counts := exprf("SELECT count(*), count(*) FILTER (WHERE %s), count(*) FILTER (WHERE %s) FROM "+quotedTable, c.empty, c.unconvertible())
+	if err := e.db.QueryRowContext(ctx, counts.sql, counts.args...).Scan(&preview.Total, &preview.Empty, &preview.Unconvertible); err != nil {
+		return nil, err
+	}
+	preview.Convertible = preview.Total - preview.Empty - preview.Unconvertible
+
+	if preview.Unconvertible == 0 || sampleLimit <= 0 {
+		return preview, nil
+	}
+	samples := exprf("SELECT id, %s FROM "+quotedTable+" WHERE %s ORDER BY id LIMIT %s", c.raw, c.unconvertible(), literal(sampleLimit))
+	rows, err := e.db.QueryContext(ctx, samples.sql, samples.args...)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+	for rows.Next() {
+		var sample models.FieldConversionSample
+		if err := rows.Scan(&sample.RecordID, &sample.Value); err != nil {
+			return nil, err
+		}
+		preview.Samples = append(preview.Samples, sample)
+	}
+	return preview, rows.Err()
+}
+
+// ConvertColumn フィールドの種類を変換し、カラムの型と値を変換する
+// 変換できない値は空にし、Backup を指定した場合はそのフィールドのカラムに変換前の文字列を保存する。
+// Backup も DiscardInvalid も指定しない場合に変換できない値があれば、変換せずにその数と ErrUnconvertibleValues を返す。
+// カラムの変換・一意制約の付け外し・フィールド定義の保存（Backup の作成を含む）は1つのトランザクションで行い、
+// 変換できずに空にした値の数を返す
+func (e *DynamicQueryExecutor) ConvertColumn(ctx context.Context, tableName string, conversion *models.FieldConversion) (int64, error) {
+	quotedTable, err := quoteIdentifier(tableName)
+	if err != nil {
+		return 0, fmt.Errorf("無効なテーブル名: %w", err)
+	}
+
+	c, err := newColumnConversion(conversion)
+	if err != nil {
+		return 0, err
+	}
+	quotedCol, err := quoteIdentifier(conversion.To.FieldCode)
+	if err != nil {
+		return 0, fmt.Errorf("無効なカラム名: %w", err)
+	}
+	quotedConstraint, err := quoteIdentifier(uniqueConstraintName(tableName, conversion.To.FieldCode))
+	if err != nil {
+		return 0, fmt.Errorf("無効な制約名: %w", err)
+	}
+	columnType := conversion.To.GetPostgresColumnType()
+
+	tx, err := txOrDB(ctx, e.db).BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("トランザクション開始に失敗しました: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	// 変換中に値が変わらないよう、テーブルをロックしてから数える
+	if _, err := tx.ExecContext(ctx, "LOCK TABLE "+quotedTable+" IN SHARE ROW EXCLUSIVE MODE"); err != nil {
+		return 0, err
+	}
+	var unconvertible int64
+	count := exprf("SELECT count(*) FROM "+quotedTable+" WHERE %s", c.unconvertible())
+	if err := tx.QueryRowContext(ctx, count.sql, count.args...).Scan(&unconvertible); err != nil {
+		return 0, err
+	}
+	if unconvertible > 0 && conversion.Backup == nil && !conversion.DiscardInvalid {
+		return unconvertible, ErrUnconvertibleValues
+	}
+
+	// USING にはサブクエリを使えないため、変換後の値を一時的なカラムに書き込んでから型を変える。
+	// 値の変換はレコードの更新ではないため、updated_at のトリガは止めておく
+	statements := []sqlExpr{
+		{sql: fmt.Sprintf("ALTER TABLE %s DISABLE TRIGGER USER", quotedTable)},
+		{sql: fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", quotedTable, conversionColumn, columnType)},
+		exprf(fmt.Sprintf("UPDATE %s SET %s = %%s", quotedTable, conversionColumn), c.using()),
+	}
+	if backup := conversion.Backup; backup != nil {
+		colDef, err := columnDefinition(tableName, backup)
+		if err != nil {
+			return 0, fmt.Errorf("無効なカラム名: %w", err)
+		}
+		quotedBackup, err := quoteIdentifier(backup.FieldCode)
+		if err != nil {
+			return 0, fmt.Errorf("無効なカラム名: %w", err)
+		}
+		statements = append(statements,
+			sqlExpr{sql: fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s", quotedTable, colDef)},
+			exprf(fmt.Sprintf("UPDATE %s SET %s = %%s WHERE %%s", quotedTable, quotedBackup), c.raw, c.unconvertible()),
+		)
+	}
+	// 変換後も一意制約を付ける場合は、型の変換でインデックスが作り直される
+	if conversion.From.IsUnique() && !conversion.To.IsUnique() {
+		statements = append(statements, sqlExpr{sql: fmt.Sprintf("ALTER TABLE %s DROP CONSTRAINT IF EXISTS %s", quotedTable, quotedConstraint)})
+	}
+	statements = append(statements,
+		sqlExpr{sql: fmt.Sprintf("ALTER TABLE %s ALTER COLUMN %s TYPE %s USING %s", quotedTable, quotedCol, columnType, conversionColumn)},
+		sqlExpr{sql: fmt.Sprintf("ALTER TABLE %s DROP COLUMN %s", quotedTable, conversionColumn)},
+	)
+	if conversion.To.IsUnique() && !conversion.From.IsUnique() {
+		statements = append(statements, sqlExpr{sql: fmt.Sprintf("ALTER TABLE %s ADD CONSTRAINT %s UNIQUE (%s)", quotedTable, quotedConstraint, quotedCol)})
+	}
+	statements = append(statements, sqlExpr{sql: fmt.Sprintf("ALTER TABLE %s ENABLE TRIGGER USER", quotedTable)})
+
+	for _, stmt := range statements {
+		if _, err := tx.ExecContext(ctx, stmt.sql, stmt.args...); err != nil {
+			return 0, err
+		}
+	}
+
+	if conversion.Backup != nil {
+		if _, err := tx.NewInsert().Model(conversion.Backup).Exec(ctx); err != nil {
+			return 0, err
+		}
+	}
+	if _, err := tx.NewUpdate().Model(&conversion.To).WherePK().Exec(ctx); err != nil {
+		return 0, err
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+	return unconvertible, nil
+}
@@ -0,0 +1,74 @@
+package repositories
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"nocode-app/backend/internal/models"
+)
+
+func TestNewColumnConversion(t *testing.T) {
+	text := models.AppField{FieldCode: "status", FieldType: "text"}
+
+	t.Run("arguments follow placeholders", func(t *testing.T) {
+		tests := []struct {
+			name    string
+			to      string
+			choices []string
+		}{
+			{name: "number", to: "number"},
+			{name: "date", to: "date"},
+			{name: "datetime", to: "datetime"},
+			{name: "checkbox", to: "checkbox"},
+			{name: "select with choices", to: "select", choices: []string{"open", "closed"}},
+			{name: "multiselect with choices", to: "multiselect", choices: []string{"open", "closed"}},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				to := text
+				to.FieldType = tt.to
+				c, err := newColumnConversion(&models.FieldConversion{From: text, To: to, Choices: tt.choices})
+				require.NoError(t, err)
+
+				for _, expr := range []sqlExpr{c.using(), c.unconvertible()} {
+					assert.Equal(t, strings.Count(expr.sql, "?"), len(expr.args))
+				}
+			})
+		}
+	})
+
+	t.Run("select without choices", func(t *testing.T) {
+		to := text
+		to.FieldType = "select"
+		c, err := newColumnConversion(&models.FieldConversion{From: text, To: to})
+		require.NoError(t, err)
+		assert.NotContains(t, c.value.sql, " IN ")
+		assert.Empty(t, c.value.args)
+	})
+
+	t.Run("unsupported conversion", func(t *testing.T) {
+		to := text
+		to.FieldType = "reference"
+		_, err := newColumnConversion(&models.FieldConversion{From: text, To: to})
+		assert.Error(t, err)
+	})
+
+	t.Run("invalid column", func(t *testing.T) {
+		from := models.AppField{FieldCode: "bad-code", FieldType: "text"}
+		to := from
+		to.FieldType = "number"
+		_, err := newColumnConversion(&models.FieldConversion{From: from, To: to})
+		assert.Error(t, err)
+	})
+}
+
+func TestExprf(t *testing.T) {
+	a := sqlExpr{sql: "lower(?)", args: []interface{}{"A"}}
+	b := literal(2)
+	expr := exprf("%s = %s OR %s", a, b, a)
+	assert.Equal(t, "lower(?) = ? OR lower(?)", expr.sql)
+	assert.Equal(t, []interface{}{"A", 2, "A"}, expr.args)
+}
@@ -4,6 +4,8 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"strconv"
+	"time"
 
 	"github.com/uptrace/bun"
 
@@ -22,7 +24,7 @@ func NewFieldRepository(db *bun.DB) *FieldRepository {
 
 // Create 新しいフィールドを作成する
 func (r *FieldRepository) Create(ctx context.Context, field *models.AppField) error {
-	_, err := r.db.NewInsert().
+	_, err := txOrDB(ctx, r.db).NewInsert().
 		Model(field).
 		Exec(ctx)
 	return err
@@ -42,7 +44,7 @@ func (r *FieldRepository) CreateBatch(ctx context.Context, fields []models.AppFi
 // GetByID IDでフィールドを取得する
 func (r *FieldRepository) GetByID(ctx context.Context, id uint64) (*models.AppField, error) {
 	field := new(models.AppField)
-	err := r.db.NewSelect().
+	err := txOrDB(ctx, r.db).NewSelect().
 		Model(field).
 		Where("id = ?", id).
 		Scan(ctx)
@@ -58,7 +60,7 @@ func (r *FieldRepository) GetByID(ctx context.Context, id uint64) (*models.AppFi
 // GetByAppID アプリの全フィールドを取得する
 func (r *FieldRepository) GetByAppID(ctx context.Context, appID uint64) ([]models.AppField, error) {
 	var fields []models.AppField
-	err := r.db.NewSelect().
+	err := txOrDB(ctx, r.db).NewSelect().
 		Model(&fields).
 		Where("app_id = ?", appID).
 		Order("display_order ASC").
@@ -85,28 +87,152 @@ func (r *FieldRepository) GetByAppIDAndCode(ctx context.Context, appID uint64, f
 	return field, nil
 }
 
+// GetReferencingFields 指定したアプリを参照先とする参照フィールドと、集計対象とする集計フィールドを全アプリから取得する
+// 参照先・集計対象のアプリIDはオプションの app_id に保持されている
+func (r *FieldRepository) GetReferencingFields(ctx context.Context, appID uint64) ([]models.AppField, error) {
+	var fields []models.AppField
+	err := r.db.NewSelect().
+		Model(&fields).
+		Where("field_type IN (?)", bun.In([]string{string(models.FieldTypeReference), string(models.FieldTypeRollup)})).
+		Where("options->>'app_id' = ?", strconv.FormatUint(appID, 10)).
+		Order("app_id ASC", "display_order ASC").
+		Scan(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return fields, nil
+}
+
 // Update フィールドを更新する
+// 更新日時はトリガで設定されるため、設定された値を field に読み戻す
 func (r *FieldRepository) Update(ctx context.Context, field *models.AppField) error {
-	_, err := r.db.NewUpdate().
+	_, err := txOrDB(ctx, r.db).NewUpdate().
 		Model(field).
 		WherePK().
+		Returning("updated_at").
 		Exec(ctx)
 	return err
 }
 
-// Delete フィールドを削除する
+// UpdateIfVersion バージョンが一致する場合のみフィールドを更新する（楽観的排他制御）
+// 他の更新と競合してバージョンが変わっていた、またはフィールドが存在しない場合は false を返す
+func (r *FieldRepository) UpdateIfVersion(ctx context.Context, field *models.AppField, version int64) (bool, error) {
+	res, err := txOrDB(ctx, r.db).NewUpdate().
+		Model(field).
+		WherePK().
+		Where(versionExpr("updated_at")+" = ?", version).
+		Returning("updated_at").
+		Exec(ctx)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return n > 0, nil
+}
+
+// Delete フィールドを完全に削除する（ごみ箱のフィールドも対象）
 func (r *FieldRepository) Delete(ctx context.Context, id uint64) error {
-	_, err := r.db.NewDelete().
+	_, err := txOrDB(ctx, r.db).NewDelete().
+		Model((*models.AppField)(nil)).
+		Where("id = ?", id).
+		ForceDelete().
+		Exec(ctx)
+	return err
+}
+
+// Trash フィールドをごみ箱に移す（deleted_at を設定する）
+func (r *FieldRepository) Trash(ctx context.Context, id uint64) error {
+	_, err := txOrDB(ctx, r.db).NewDelete().
+		Model((*models.AppField)(nil)).
+		Where("id = ?", id).
+		Exec(ctx)
+	return err
+}
+
+// TrashIfVersion バージョンが一致する場合のみフィールドをごみ箱に移す（楽観的排他制御）
+// 他の更新と競合してバージョンが変わっていた、またはフィールドが存在しない場合は false を返す
+func (r *FieldRepository) TrashIfVersion(ctx context.Context, id uint64, version int64) (bool, error) {
+	res, err := txOrDB(ctx, r.db).NewDelete().
+		Model((*models.AppField)(nil)).
+		Where("id = ?", id).
+		Where(versionExpr("updated_at")+" = ?", version).
+		Exec(ctx)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return n > 0, nil
+}
+
+// Restore ごみ箱のフィールドを元に戻す
+func (r *FieldRepository) Restore(ctx context.Context, id uint64) error {
+	_, err := r.db.NewUpdate().
 		Model((*models.AppField)(nil)).
+		Set("deleted_at = NULL").
 		Where("id = ?", id).
+		WhereDeleted().
 		Exec(ctx)
 	return err
 }
 
+// GetTrashedByID IDでごみ箱のフィールドを取得する
+func (r *FieldRepository) GetTrashedByID(ctx context.Context, id uint64) (*models.AppField, error) {
+	field := new(models.AppField)
+	err := r.db.NewSelect().
+		Model(field).
+		Where("id = ?", id).
+		WhereDeleted().
+		Scan(ctx)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return field, nil
+}
+
+// GetTrashedByAppID アプリのごみ箱の全フィールドを、ごみ箱に移した日時の新しい順に取得する
+func (r *FieldRepository) GetTrashedByAppID(ctx context.Context, appID uint64) ([]models.AppField, error) {
+	fields := make([]models.AppField, 0)
+	err := r.db.NewSelect().
+		Model(&fields).
+		Where("app_id = ?", appID).
+		WhereDeleted().
+		Order("deleted_at DESC", "id DESC").
+		Scan(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return fields, nil
+}
+
+// GetTrashedBefore before より前にごみ箱に移したフィールドを全アプリから、古い順に最大limit件取得する
+func (r *FieldRepository) GetTrashedBefore(ctx context.Context, before time.Time, limit int) ([]models.AppField, error) {
+	fields := make([]models.AppField, 0)
+	err := r.db.NewSelect().
+		Model(&fields).
+		WhereDeleted().
+		Where("deleted_at < ?", before).
+		Order("deleted_at ASC", "id ASC").
+		Limit(limit).
+		Scan(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return fields, nil
+}
+
 // UpdateOrder フィールドの表示順序を更新する
 func (r *FieldRepository) UpdateOrder(ctx context.Context, items []models.FieldOrderItem) error {
 	for _, item := range items {
-		_, err := r.db.NewUpdate().
+		_, err := txOrDB(ctx, r.db).NewUpdate().
 			Model((*models.AppField)(nil)).
 			Set("display_order = ?", item.DisplayOrder).
 			Where("id = ?", item.ID).
@@ -119,10 +245,12 @@ func (r *FieldRepository) UpdateOrder(ctx context.Context, items []models.FieldO
 }
 
 // FieldCodeExists アプリ内にフィールドコードが既に存在するか確認する
+// ごみ箱のフィールドもカラムが残っているため、フィールドコードは使用中とみなす
 func (r *FieldRepository) FieldCodeExists(ctx context.Context, appID uint64, fieldCode string) (bool, error) {
 	count, err := r.db.NewSelect().
 		Model((*models.AppField)(nil)).
 		Where("app_id = ? AND field_code = ?", appID, fieldCode).
+		WhereAllWithDeleted().
 		Count(ctx)
 	if err != nil {
 		return false, err
@@ -214,6 +214,42 @@ func TestFieldRepository_GetByAppID(t *testing.T) {
 	assert.Equal(t, "c_field", result[2].FieldCode)
 }
 
+func TestFieldRepository_GetReferencingFields(t *testing.T) {
+	ctx := context.Background()
+	db, err := testhelpers.GetTestDB(ctx)
+	require.NoError(t, err)
+
+	t.Cleanup(func() {
+		require.NoError(t, testhelpers.ResetDatabase(ctx))
+	})
+
+	repo := repositories.NewFieldRepository(db)
+	customers := createTestApp(ctx, t, "app_data_field_ref_customers")
+	orders := createTestApp(ctx, t, "app_data_field_ref_orders")
+
+	fields := []models.AppField{
+		{AppID: orders.ID, FieldCode: "customer", FieldName: "Customer", FieldType: "reference", DisplayOrder: 1,
+			Options: models.FieldOptions{"app_id": customers.ID}, CreatedAt: time.Now(), UpdatedAt: time.Now()},
+		{AppID: orders.ID, FieldCode: "title", FieldName: "Title", FieldType: "text", DisplayOrder: 2,
+			Options: models.FieldOptions{"app_id": customers.ID}, CreatedAt: time.Now(), UpdatedAt: time.Now()},
+		{AppID: customers.ID, FieldCode: "order_count", FieldName: "Order Count", FieldType: "rollup", DisplayOrder: 1,
+			Options: models.FieldOptions{"app_id": orders.ID, "link_field": "customer", "aggregation": "count"}, CreatedAt: time.Now(), UpdatedAt: time.Now()},
+	}
+	require.NoError(t, repo.CreateBatch(ctx, fields))
+
+	result, err := repo.GetReferencingFields(ctx, customers.ID)
+	require.NoError(t, err)
+	require.Len(t, result, 1)
+	assert.Equal(t, "customer", result[0].FieldCode)
+	assert.Equal(t, orders.ID, result[0].AppID)
+
+	result, err = repo.GetReferencingFields(ctx, orders.ID)
+	require.NoError(t, err)
+	require.Len(t, result, 1)
+	assert.Equal(t, "order_count", result[0].FieldCode)
+	assert.Equal(t, customers.ID, result[0].AppID)
+}
+
 func TestFieldRepository_GetByAppIDAndCode(t *testing.T) {
 	ctx := context.Background()
 	db, err := testhelpers.GetTestDB(ctx)
@@ -0,0 +1,186 @@
+package repositories
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"nocode-app/backend/internal/models"
+)
+
+// filterBuilder フィルター条件をWHERE句の条件に変換する構造体
+// カラム名は column で解決したクォート済みの名前のみを使い、値はすべてプレースホルダで渡す。
+// 条件はサービス層でフィールド定義と照合して検証・正規化されている前提とし、
+// 内部アプリ（bunの ?）と外部データソース（$N）で同じSQLを生成できるよう、フィールドの型に依存しない式にする。
+type filterBuilder struct {
+	column      func(field string) (string, error)
+	placeholder func(index int) string
+	values      []interface{}
+}
+
+// newFilterBuilder 新しいfilterBuilderを作成する
+func newFilterBuilder(column func(field string) (string, error), placeholder func(index int) string) *filterBuilder {
+	return &filterBuilder{
+		column:      column,
+		placeholder: placeholder,
+	}
+}
+
+// build ANDで結合するフィルター条件とフィルター式から条件を構築する（条件がない場合は空文字）
+func (b *filterBuilder) build(items []models.FilterItem, expr *models.FilterExpr) (string, error) {
+	clauses := make([]string, 0, len(items)+1)
+	for i := range items {
+		clause, err := b.item(&items[i])
+		if err != nil {
+			return "", err
+		}
+		if clause != "" {
+			clauses = append(clauses, clause)
+		}
+	}
+	if expr != nil {
+		clause, err := b.expr(expr, 0)
+		if err != nil {
+			return "", err
+		}
+		if clause != "" {
+			clauses = append(clauses, clause)
+		}
+	}
+	return strings.Join(clauses, " AND "), nil
+}
+
+// bind 値を追加してプレースホルダを返す
+func (b *filterBuilder) bind(value interface{}) string {
+	b.values = append(b.values, value)
+	return b.placeholder(len(b.values))
+}
+
+// expr フィルター式を条件に変換する
+func (b *filterBuilder) expr(e *models.FilterExpr, depth int) (string, error) {
+	// サービス層での置き換え（空の判定・相対的な期間の展開と条件の結合）で増える2段まで許容する
+	if depth > models.MaxFilterDepth+2 {
+		return "", fmt.Errorf("フィルター式の入れ子は%d段までです", models.MaxFilterDepth)
+	}
+	switch {
+	case e.Not != nil:
+		clause, err := b.expr(e.Not, depth+1)
+		if err != nil || clause == "" {
+			return "", err
+		}
+		return "NOT (" + clause + ")", nil
+	case e.And != nil:
+		return b.group(e.And, " AND ", depth)
+	case e.Or != nil:
+		return b.group(e.Or, " OR ", depth)
+	}
+	return b.item(&e.FilterItem)
+}
+
+// group 条件のグループを括弧でくくって結合する
+func (b *filterBuilder) group(children []models.FilterExpr, sep string, depth int) (string, error) {
+	clauses := make([]string, 0, len(children))
+	for i := range children {
+		clause, err := b.expr(&children[i], depth+1)
+		if err != nil {
+			return "", err
+		}
+		if clause != "" {
+			clauses = append(clauses, clause)
+		}
+	}
+	switch len(clauses) {
+	case 0:
+		return "", nil
+	case 1:
+		return clauses[0], nil
+	}
+	return "(" + strings.Join(clauses, sep) + ")", nil
+}
+
+// item 単一の条件を変換する
+// 未知の演算子の条件は従来どおり無視する
+func (b *filterBuilder) item(f *models.FilterItem) (string, error) {
+	col, err := b.column(f.Field)
+	if err != nil {
+		return "", err
+	}
+
+	switch f.Operator {
+	case models.FilterOpEq:
+		return col + " = " + b.bind(f.Value), nil
+	case models.FilterOpNe:
+		return col + " != " + b.bind(f.Value), nil
+	case models.FilterOpGt:
+		return col + " > " + b.bind(f.Value), nil
+	case models.FilterOpGte:
+		return col + " >= " + b.bind(f.Value), nil
+	case models.FilterOpLt:
+		return col + " < " + b.bind(f.Value), nil
+	case models.FilterOpLte:
+		return col + " <= " + b.bind(f.Value), nil
+	case models.FilterOpLike:
+		return col + " LIKE " + b.bind("%"+f.Value+"%"), nil
+	case models.FilterOpILike:
+		return col + " ILIKE " + b.bind("%"+f.Value+"%"), nil
+	case models.FilterOpIsNull:
+		return col + " IS NULL", nil
+	case models.FilterOpNotNull:
+		return col + " IS NOT NULL", nil
+	case models.FilterOpIn, models.FilterOpNotIn:
+		values := f.ListValues()
+		if len(values) == 0 {
+			return "", fmt.Errorf("フィルターフィールド %q の %s には値を1つ以上指定してください", f.Field, f.Operator)
+		}
+		placeholders := make([]string, len(values))
+		for i, v := range values {
+			placeholders[i] = b.bind(v)
+		}
+		op := " IN ("
+		if f.Operator == models.FilterOpNotIn {
+			op = " NOT IN ("
+		}
+		return col + op + strings.Join(placeholders, ", ") + ")", nil
+	case models.FilterOpBetween:
+		values := f.ListValues()
+		if len(values) != 2 {
+			return "", fmt.Errorf("フィルターフィールド %q の between には値を2つ指定してください", f.Field)
+		}
+		return col + " BETWEEN " + b.bind(values[0]) + " AND " + b.bind(values[1]), nil
+	case models.FilterOpContainsAll:
+		// 複数選択（JSONBの配列）がすべての値を含む
+		values := f.ListValues()
+		if len(values) == 0 {
+			return "", fmt.Errorf("フィルターフィールド %q の %s には値を1つ以上指定してください", f.Field, f.Operator)
+		}
+		array, err := json.Marshal(values)
+		if err != nil {
+			return "", err
+		}
+		return col + " @> CAST(" + b.bind(string(array)) + " AS jsonb)", nil
+	case models.FilterOpContainsAny:
+		// 複数選択（JSONBの配列）がいずれかの値を含む
+		values := f.ListValues()
+		if len(values) == 0 {
+			return "", fmt.Errorf("フィルターフィールド %q の %s には値を1つ以上指定してください", f.Field, f.Operator)
+		}
+		clauses := make([]string, len(values))
+		for i, v := range values {
+			array, err := json.Marshal([]string{v})
+			if err != nil {
+				return "", err
+			}
+			clauses[i] = col + " @> CAST(" + b.bind(string(array)) + " AS jsonb)"
+		}
+		if len(clauses) == 1 {
+			return clauses[0], nil
+		}
+		return "(" + strings.Join(clauses, " OR ") + ")", nil
+	case models.FilterOpInPeriod:
+		// 相対的な期間は基準日時とタイムゾーンに依存するため、サービス層で日付の範囲に置き換える
+		return "", errors.New("相対的な期間の条件は日付の範囲に変換してから指定してください")
+	default:
+		return "", nil
+	}
+}
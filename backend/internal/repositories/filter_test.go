@@ -0,0 +1,111 @@
+package repositories
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"nocode-app/backend/internal/models"
+)
+
+// TestFilterBuilder 入れ子のフィルター式と各演算子の変換をテストする
+func TestFilterBuilder(t *testing.T) {
+	columns := map[string]string{"status": `"status"`, "amount": `"amount"`, "tags": `"tags"`, "owner": `"owner"`}
+	leaf := func(field, op, value string, values ...string) models.FilterExpr {
+		return models.FilterExpr{FilterItem: models.FilterItem{Field: field, Operator: op, Value: value, Values: values}}
+	}
+
+	tests := []struct {
+		name       string
+		items      []models.FilterItem
+		expr       *models.FilterExpr
+		wantSQL    string
+		wantValues []interface{}
+	}{
+		{
+			name: "or group combined with and items",
+			items: []models.FilterItem{
+				{Field: "amount", Operator: "gte", Value: "100"},
+			},
+			expr: &models.FilterExpr{Or: []models.FilterExpr{
+				leaf("status", "eq", "open"),
+				{And: []models.FilterExpr{leaf("status", "eq", "hold"), leaf("owner", "is_null", "")}},
+			}},
+			wantSQL:    `WHERE "amount" >= $1 AND ("status" = $2 OR ("status" = $3 AND "owner" IS NULL))`,
+			wantValues: []interface{}{"100", "open", "hold"},
+		},
+		{
+			name:       "not",
+			expr:       &models.FilterExpr{Not: &models.FilterExpr{Or: []models.FilterExpr{leaf("status", "in", "", "a", "b"), leaf("owner", "not_null", "")}}},
+			wantSQL:    `WHERE NOT (("status" IN ($1, $2) OR "owner" IS NOT NULL))`,
+			wantValues: []interface{}{"a", "b"},
+		},
+		{
+			name:       "in from comma separated value",
+			items:      []models.FilterItem{{Field: "status", Operator: "not_in", Value: "a,b"}},
+			wantSQL:    `WHERE "status" NOT IN ($1, $2)`,
+			wantValues: []interface{}{"a", "b"},
+		},
+		{
+			name:       "between and ilike",
+			items:      []models.FilterItem{{Field: "amount", Operator: "between", Values: []string{"10", "20"}}, {Field: "status", Operator: "ilike", Value: "Op"}},
+			wantSQL:    `WHERE "amount" BETWEEN $1 AND $2 AND "status" ILIKE $3`,
+			wantValues: []interface{}{"10", "20", "%Op%"},
+		},
+		{
+			name:       "multiselect contains",
+			items:      []models.FilterItem{{Field: "tags", Operator: "contains_all", Values: []string{"a", "b"}}, {Field: "tags", Operator: "contains_any", Values: []string{"c", `"d"`}}},
+			wantSQL:    `WHERE "tags" @> CAST($1 AS jsonb) AND ("tags" @> CAST($2 AS jsonb) OR "tags" @> CAST($3 AS jsonb))`,
+			wantValues: []interface{}{`["a","b"]`, `["c"]`, `["\"d\""]`},
+		},
+		{
+			name:       "single child group is not parenthesized",
+			expr:       &models.FilterExpr{And: []models.FilterExpr{leaf("status", "eq", "open")}},
+			wantSQL:    `WHERE "status" = $1`,
+			wantValues: []interface{}{"open"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			whereSQL, values, err := buildExternalWhereClause(models.DBTypePostgreSQL, columns, tt.items, tt.expr)
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantSQL, whereSQL)
+			assert.Equal(t, tt.wantValues, values)
+		})
+	}
+
+	t.Run("dynamic tables use bun placeholders", func(t *testing.T) {
+		e := &DynamicQueryExecutor{}
+		whereSQL, values, err := e.buildWhereClause(nil, &models.FilterExpr{Or: []models.FilterExpr{leaf("status", "eq", "open"), leaf("amount", "lt", "5")}})
+		require.NoError(t, err)
+		assert.Equal(t, `WHERE ("status" = ? OR "amount" < ?)`, whereSQL)
+		assert.Equal(t, []interface{}{"open", "5"}, values)
+	})
+
+	t.Run("unresolved relative period", func(t *testing.T) {
+		_, _, err := buildExternalWhereClause(models.DBTypePostgreSQL, columns, []models.FilterItem{{Field: "status", Operator: "in_period", Value: "this_week"}}, nil)
+		assert.Error(t, err)
+	})
+
+	t.Run("between needs two values", func(t *testing.T) {
+		_, _, err := buildExternalWhereClause(models.DBTypePostgreSQL, columns, []models.FilterItem{{Field: "amount", Operator: "between", Value: "1"}}, nil)
+		assert.Error(t, err)
+	})
+
+	t.Run("unknown field in nested expression", func(t *testing.T) {
+		_, _, err := buildExternalWhereClause(models.DBTypePostgreSQL, columns, nil, &models.FilterExpr{Not: &models.FilterExpr{FilterItem: models.FilterItem{Field: "missing", Operator: "eq"}}})
+		assert.Error(t, err)
+	})
+
+	t.Run("too deep", func(t *testing.T) {
+		expr := leaf("status", "eq", "x")
+		for i := 0; i < models.MaxFilterDepth+3; i++ {
+			inner := expr
+			expr = models.FilterExpr{Not: &inner}
+		}
+		_, _, err := buildExternalWhereClause(models.DBTypePostgreSQL, columns, nil, &expr)
+		assert.Error(t, err)
+	})
+}
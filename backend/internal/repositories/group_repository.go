@@ -0,0 +1,158 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/uptrace/bun"
+
+	"nocode-app/backend/internal/models"
+)
+
+// GroupRepository ユーザーグループのデータベース操作を処理する構造体
+type GroupRepository struct {
+	db *bun.DB
+}
+
+// NewGroupRepository 新しいGroupRepositoryを作成する
+func NewGroupRepository(db *bun.DB) *GroupRepository {
+	return &GroupRepository{db: db}
+}
+
+// Create 新しいグループを作成する
+func (r *GroupRepository) Create(ctx context.Context, group *models.Group) error {
+	_, err := r.db.NewInsert().
+		Model(group).
+		Exec(ctx)
+	if err != nil {
+		return fmt.Errorf("グループの作成に失敗しました: %w", err)
+	}
+	return nil
+}
+
+// GetByID IDでグループをメンバー付きで取得する
+func (r *GroupRepository) GetByID(ctx context.Context, id uint64) (*models.Group, error) {
+	group := new(models.Group)
+	err := r.db.NewSelect().
+		Model(group).
+		Where("g.id = ?", id).
+		Scan(ctx)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("グループの取得に失敗しました: %w", err)
+	}
+
+	members, err := r.GetMembers(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	group.Members = members
+	return group, nil
+}
+
+// GetAll 全グループを名前順に取得する（メンバーは含まない）
+func (r *GroupRepository) GetAll(ctx context.Context) ([]models.Group, error) {
+	var groups []models.Group
+	err := r.db.NewSelect().
+		Model(&groups).
+		Order("g.name ASC").
+		Scan(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("グループ一覧の取得に失敗しました: %w", err)
+	}
+	return groups, nil
+}
+
+// Update グループを更新する
+func (r *GroupRepository) Update(ctx context.Context, group *models.Group) error {
+	_, err := r.db.NewUpdate().
+		Model(group).
+		Column("name", "description", "updated_at").
+		WherePK().
+		Exec(ctx)
+	if err != nil {
+		return fmt.Errorf("グループの更新に失敗しました: %w", err)
+	}
+	return nil
+}
+
+// Delete グループを削除する（メンバーとアプリ権限はカスケードで削除される）
+func (r *GroupRepository) Delete(ctx context.Context, id uint64) error {
+	_, err := r.db.NewDelete().
+		Model((*models.Group)(nil)).
+		Where("id = ?", id).
+		Exec(ctx)
+	if err != nil {
+		return fmt.Errorf("グループの削除に失敗しました: %w", err)
+	}
+	return nil
+}
+
+// NameExists グループ名が既に存在するか確認する（excludeIDは除外、0の場合は除外しない）
+func (r *GroupRepository) NameExists(ctx context.Context, name string, excludeID uint64) (bool, error) {
+	q := r.db.NewSelect().
+		Model((*models.Group)(nil)).
+		Where("name = ?", name)
+	if excludeID > 0 {
+		q = q.Where("id != ?", excludeID)
+	}
+	return q.Exists(ctx)
+}
+
+// GetMembers グループに所属するユーザーを名前順に取得する
+func (r *GroupRepository) GetMembers(ctx context.Context, groupID uint64) ([]models.User, error) {
+	var users []models.User
+	err := r.db.NewSelect().
+		Model(&users).
+		Join("JOIN user_group_members AS gm ON gm.user_id = u.id").
+		Where("gm.group_id = ?", groupID).
+		Order("u.name ASC").
+		Scan(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("グループメンバーの取得に失敗しました: %w", err)
+	}
+	return users, nil
+}
+
+// AddMember グループにユーザーを追加する（既に所属している場合は何もしない）
+func (r *GroupRepository) AddMember(ctx context.Context, groupID, userID uint64) error {
+	member := &models.GroupMember{GroupID: groupID, UserID: userID}
+	_, err := r.db.NewInsert().
+		Model(member).
+		On("CONFLICT (group_id, user_id) DO NOTHING").
+		Exec(ctx)
+	if err != nil {
+		return fmt.Errorf("グループメンバーの追加に失敗しました: %w", err)
+	}
+	return nil
+}
+
+// RemoveMember グループからユーザーを削除する
+func (r *GroupRepository) RemoveMember(ctx context.Context, groupID, userID uint64) error {
+	_, err := r.db.NewDelete().
+		Model((*models.GroupMember)(nil)).
+		Where("group_id = ? AND user_id = ?", groupID, userID).
+		Exec(ctx)
+	if err != nil {
+		return fmt.Errorf("グループメンバーの削除に失敗しました: %w", err)
+	}
+	return nil
+}
+
+// GetGroupIDsByUserID ユーザーが所属するグループIDの一覧を取得する
+func (r *GroupRepository) GetGroupIDsByUserID(ctx context.Context, userID uint64) ([]uint64, error) {
+	var ids []uint64
+	err := r.db.NewSelect().
+		Model((*models.GroupMember)(nil)).
+		Column("group_id").
+		Where("user_id = ?", userID).
+		Scan(ctx, &ids)
+	if err != nil {
+		return nil, fmt.Errorf("所属グループの取得に失敗しました: %w", err)
+	}
+	return ids, nil
+}
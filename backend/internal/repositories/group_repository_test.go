@@ -0,0 +1,105 @@
+package repositories_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"nocode-app/backend/internal/models"
+	"nocode-app/backend/internal/repositories"
+	"nocode-app/backend/internal/testhelpers"
+)
+
+func createTestGroup(ctx context.Context, t *testing.T, name string) *models.Group {
+	db, err := testhelpers.GetTestDB(ctx)
+	require.NoError(t, err)
+
+	group := &models.Group{
+		Name:      name,
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+	require.NoError(t, repositories.NewGroupRepository(db).Create(ctx, group))
+	return group
+}
+
+func TestGroupRepository_CreateAndGetByID(t *testing.T) {
+	ctx := context.Background()
+	db, err := testhelpers.GetTestDB(ctx)
+	require.NoError(t, err)
+
+	t.Cleanup(func() {
+		require.NoError(t, testhelpers.ResetDatabase(ctx))
+	})
+
+	repo := repositories.NewGroupRepository(db)
+	group := createTestGroup(ctx, t, "Sales")
+	assert.NotZero(t, group.ID)
+
+	found, err := repo.GetByID(ctx, group.ID)
+	require.NoError(t, err)
+	require.NotNil(t, found)
+	assert.Equal(t, "Sales", found.Name)
+	assert.Empty(t, found.Members)
+
+	notFound, err := repo.GetByID(ctx, 99999)
+	require.NoError(t, err)
+	assert.Nil(t, notFound)
+}
+
+func TestGroupRepository_NameExists(t *testing.T) {
+	ctx := context.Background()
+	db, err := testhelpers.GetTestDB(ctx)
+	require.NoError(t, err)
+
+	t.Cleanup(func() {
+		require.NoError(t, testhelpers.ResetDatabase(ctx))
+	})
+
+	repo := repositories.NewGroupRepository(db)
+	group := createTestGroup(ctx, t, "Engineering")
+
+	exists, err := repo.NameExists(ctx, "Engineering", 0)
+	require.NoError(t, err)
+	assert.True(t, exists)
+
+	exists, err = repo.NameExists(ctx, "Engineering", group.ID)
+	require.NoError(t, err)
+	assert.False(t, exists)
+}
+
+func TestGroupRepository_Members(t *testing.T) {
+	ctx := context.Background()
+	db, err := testhelpers.GetTestDB(ctx)
+	require.NoError(t, err)
+
+	t.Cleanup(func() {
+		require.NoError(t, testhelpers.ResetDatabase(ctx))
+	})
+
+	repo := repositories.NewGroupRepository(db)
+	group := createTestGroup(ctx, t, "Support")
+	user := createTestUserForWidget(ctx, t, "group_member")
+
+	require.NoError(t, repo.AddMember(ctx, group.ID, user.ID))
+	// 重複追加はエラーにならない
+	require.NoError(t, repo.AddMember(ctx, group.ID, user.ID))
+
+	members, err := repo.GetMembers(ctx, group.ID)
+	require.NoError(t, err)
+	require.Len(t, members, 1)
+	assert.Equal(t, user.ID, members[0].ID)
+
+	groupIDs, err := repo.GetGroupIDsByUserID(ctx, user.ID)
+	require.NoError(t, err)
+	assert.Equal(t, []uint64{group.ID}, groupIDs)
+
+	require.NoError(t, repo.RemoveMember(ctx, group.ID, user.ID))
+
+	members, err = repo.GetMembers(ctx, group.ID)
+	require.NoError(t, err)
+	assert.Empty(t, members)
+}
@@ -0,0 +1,101 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/lib/pq"
+
+	"nocode-app/backend/internal/models"
+)
+
+// CreateIndex 動的テーブルにインデックスを作成する
+// 作成中もレコードを書き込めるよう CONCURRENTLY で作成し、失敗した場合は作成途中の無効なインデックスを削除する。
+// 部分インデックスの条件は相対的な期間を日付の範囲に置き換えたものであること
+func (e *DynamicQueryExecutor) CreateIndex(ctx context.Context, tableName string, index *models.AppIndex) error {
+	quotedTable, err := quoteIdentifier(tableName)
+	if err != nil {
+		return fmt.Errorf("無効なテーブル名: %w", err)
+	}
+
+	quotedIndex, err := quoteIdentifier(index.IndexName)
+	if err != nil {
+		return fmt.Errorf("無効なインデックス名: %w", err)
+	}
+
+	columns := make([]string, len(index.FieldCodes))
+	for i, code := range index.FieldCodes {
+		if columns[i], err = quoteIdentifier(code); err != nil {
+			return fmt.Errorf("無効なカラム名 %q: %w", code, err)
+		}
+	}
+
+	unique := ""
+	if index.IsUnique {
+		unique = "UNIQUE "
+	}
+	query := fmt.Sprintf("CREATE %sINDEX CONCURRENTLY %s ON %s (%s)", unique, quotedIndex, quotedTable, strings.Join(columns, ", "))
+
+	// 条件の値は bun がリテラルとしてインライン化する（インデックスの条件にはパラメータを使えない）
+	builder := newFilterBuilder(filterColumn, bunPlaceholder)
+	clause, err := builder.build(nil, index.Filter)
+	if err != nil {
+		return err
+	}
+	if clause != "" {
+		query += " WHERE " + clause
+	}
+
+	if _, err := e.db.ExecContext(ctx, query, builder.values...); err != nil {
+		// 同じ名前のインデックスが既にある場合は、そのインデックスを削除しない
+		var pqErr *pq.Error
+		if !errors.As(err, &pqErr) || pqErr.Code != "42P07" {
+			_, _ = e.db.ExecContext(context.WithoutCancel(ctx), "DROP INDEX CONCURRENTLY IF EXISTS "+quotedIndex)
+		}
+		return err
+	}
+	return nil
+}
+
+// DropIndex 動的テーブルのインデックスを削除する（既にない場合は何もしない）
+func (e *DynamicQueryExecutor) DropIndex(ctx context.Context, indexName string) error {
+	quotedIndex, err := quoteIdentifier(indexName)
+	if err != nil {
+		return fmt.Errorf("無効なインデックス名: %w", err)
+	}
+
+	_, err = e.db.ExecContext(ctx, "DROP INDEX CONCURRENTLY IF EXISTS "+quotedIndex)
+	return err
+}
+
+// GetIndexUsage 動的テーブルの全インデックス（主キー・一意制約を含む）の大きさと利用状況を取得する
+func (e *DynamicQueryExecutor) GetIndexUsage(ctx context.Context, tableName string) ([]models.IndexUsage, error) {
+	quotedTable, err := quoteIdentifier(tableName)
+	if err != nil {
+		return nil, fmt.Errorf("無効なテーブル名: %w", err)
+	}
+
+	rows, err := e.db.QueryContext(ctx, `
+		SELECT c.relname, pg_relation_size(c.oid), COALESCE(s.idx_scan, 0), i.indisvalid
+		FROM pg_index i
+		JOIN pg_class c ON c.oid = i.indexrelid
+		LEFT JOIN pg_stat_user_indexes s ON s.indexrelid = i.indexrelid
+		WHERE i.indrelid = to_regclass(?)
+		ORDER BY c.relname`, quotedTable)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	var usage []models.IndexUsage
+	for rows.Next() {
+		var u models.IndexUsage
+		if err := rows.Scan(&u.IndexName, &u.SizeBytes, &u.Scans, &u.Valid); err != nil {
+			return nil, err
+		}
+		usage = append(usage, u)
+	}
+	return usage, rows.Err()
+}
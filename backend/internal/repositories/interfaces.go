@@ -3,6 +3,7 @@ package repositories
 
 import (
 	"context"
+	"time"
 
 	"nocode-app/backend/internal/models"
 )
@@ -27,10 +28,17 @@ type AppRepositoryInterface interface {
 	GetByIDWithFields(ctx context.Context, id uint64) (*models.App, error)
 	GetAll(ctx context.Context, page, limit int) ([]models.App, int64, error)
 	GetByUserID(ctx context.Context, userID uint64, page, limit int) ([]models.App, int64, error)
+	GetAccessibleByUserID(ctx context.Context, userID uint64, page, limit int) ([]models.App, int64, error)
 	Update(ctx context.Context, app *models.App) error
 	Delete(ctx context.Context, id uint64) error
 	GetTableName(ctx context.Context, appID uint64) (string, error)
 	GetAllTableNames(ctx context.Context) ([]string, error)
+	GetByTableNames(ctx context.Context, tableNames []string) ([]models.App, error)
+	Trash(ctx context.Context, id uint64) error
+	Restore(ctx context.Context, id uint64) error
+	GetTrashedByID(ctx context.Context, id uint64) (*models.App, error)
+	GetTrashed(ctx context.Context) ([]models.App, error)
+	GetTrashedBefore(ctx context.Context, before time.Time, limit int) ([]models.App, error)
 }
 
 // FieldRepositoryInterface フィールドデータベース操作のインターフェースを定義
@@ -40,11 +48,19 @@ type FieldRepositoryInterface interface {
 	GetByID(ctx context.Context, id uint64) (*models.AppField, error)
 	GetByAppID(ctx context.Context, appID uint64) ([]models.AppField, error)
 	GetByAppIDAndCode(ctx context.Context, appID uint64, fieldCode string) (*models.AppField, error)
+	GetReferencingFields(ctx context.Context, appID uint64) ([]models.AppField, error)
 	Update(ctx context.Context, field *models.AppField) error
+	UpdateIfVersion(ctx context.Context, field *models.AppField, version int64) (bool, error)
 	Delete(ctx context.Context, id uint64) error
 	UpdateOrder(ctx context.Context, items []models.FieldOrderItem) error
 	FieldCodeExists(ctx context.Context, appID uint64, fieldCode string) (bool, error)
 	GetMaxDisplayOrder(ctx context.Context, appID uint64) (int, error)
+	Trash(ctx context.Context, id uint64) error
+	TrashIfVersion(ctx context.Context, id uint64, version int64) (bool, error)
+	Restore(ctx context.Context, id uint64) error
+	GetTrashedByID(ctx context.Context, id uint64) (*models.AppField, error)
+	GetTrashedByAppID(ctx context.Context, appID uint64) ([]models.AppField, error)
+	GetTrashedBefore(ctx context.Context, before time.Time, limit int) ([]models.AppField, error)
 }
 
 // ViewRepositoryInterface ビューデータベース操作のインターフェースを定義
@@ -54,7 +70,9 @@ type ViewRepositoryInterface interface {
 	GetByAppID(ctx context.Context, appID uint64) ([]models.AppView, error)
 	GetDefaultByAppID(ctx context.Context, appID uint64) (*models.AppView, error)
 	Update(ctx context.Context, view *models.AppView) error
+	UpdateIfVersion(ctx context.Context, view *models.AppView, version int64) (bool, error)
 	Delete(ctx context.Context, id uint64) error
+	DeleteIfVersion(ctx context.Context, id uint64, version int64) (bool, error)
 	ClearDefaultByAppID(ctx context.Context, appID uint64) error
 }
 
@@ -73,13 +91,31 @@ type DynamicQueryExecutorInterface interface {
 	DropTable(ctx context.Context, tableName string) error
 	AddColumn(ctx context.Context, tableName string, field *models.AppField) error
 	DropColumn(ctx context.Context, tableName, columnName string) error
+	SetForeignKey(ctx context.Context, tableName, columnName, refTableName string, onDelete models.ReferenceOnDelete) error
+	DropForeignKey(ctx context.Context, tableName, columnName string) error
+	SetFormulaColumn(ctx context.Context, tableName string, field *models.AppField, expression string) error
+	SetSearchColumn(ctx context.Context, tableName string, fields []models.AppField, config string) error
+	SetUniqueConstraint(ctx context.Context, tableName, columnName string, unique bool) error
+	CreateIndex(ctx context.Context, tableName string, index *models.AppIndex) error
+	DropIndex(ctx context.Context, indexName string) error
+	GetIndexUsage(ctx context.Context, tableName string) ([]models.IndexUsage, error)
+	PreviewConversion(ctx context.Context, tableName string, conversion *models.FieldConversion, sampleLimit int) (*models.FieldConversionPreview, error)
+	ConvertColumn(ctx context.Context, tableName string, conversion *models.FieldConversion) (int64, error)
 	InsertRecord(ctx context.Context, tableName string, data models.RecordData, userID uint64) (uint64, error)
+	InsertRecords(ctx context.Context, tableName string, rows []models.RecordData, userID uint64) ([]uint64, error)
 	UpdateRecord(ctx context.Context, tableName string, recordID uint64, data models.RecordData) error
+	UpdateRecordIfVersion(ctx context.Context, tableName string, recordID uint64, version int64, data models.RecordData) (bool, error)
 	DeleteRecord(ctx context.Context, tableName string, recordID uint64) error
 	DeleteRecords(ctx context.Context, tableName string, recordIDs []uint64) error
+	TrashRecords(ctx context.Context, tableName string, recordIDs []uint64, userID uint64) error
+	TrashRecordIfVersion(ctx context.Context, tableName string, recordID uint64, version int64, userID uint64) (bool, error)
+	RestoreRecord(ctx context.Context, tableName string, deletedRecordID uint64) error
 	GetRecords(ctx context.Context, tableName string, fields []models.AppField, opts RecordQueryOptions) ([]models.RecordResponse, int64, error)
+	StreamRecords(ctx context.Context, tableName string, fields []models.AppField, opts RecordQueryOptions, fn RecordStreamFunc) error
 	GetRecordByID(ctx context.Context, tableName string, fields []models.AppField, recordID uint64) (*models.RecordResponse, error)
+	GetRecordsByIDs(ctx context.Context, tableName string, fields []models.AppField, recordIDs []uint64) ([]models.RecordResponse, error)
 	GetAggregatedData(ctx context.Context, tableName string, req *models.ChartDataRequest) (*models.ChartDataResponse, error)
+	GetPivotData(ctx context.Context, tableName string, req *models.PivotRequest) (*models.PivotResponse, error)
 	CountRecords(ctx context.Context, tableName string) (int64, error)
 	CountTodaysUpdates(ctx context.Context, tableName string) (int64, error)
 }
@@ -102,8 +138,10 @@ type ExternalQueryExecutorInterface interface {
 	GetTables(ctx context.Context, ds *models.DataSource, password string) ([]models.TableInfo, error)
 	GetColumns(ctx context.Context, ds *models.DataSource, password string, tableName string) ([]models.ColumnInfo, error)
 	GetRecords(ctx context.Context, ds *models.DataSource, password string, tableName string, fields []models.AppField, opts RecordQueryOptions) ([]models.RecordResponse, int64, error)
+	StreamRecords(ctx context.Context, ds *models.DataSource, password string, tableName string, fields []models.AppField, opts RecordQueryOptions, fn RecordStreamFunc) error
 	GetRecordByID(ctx context.Context, ds *models.DataSource, password string, tableName string, fields []models.AppField, recordID uint64) (*models.RecordResponse, error)
 	GetAggregatedData(ctx context.Context, ds *models.DataSource, password string, tableName string, fields []models.AppField, req *models.ChartDataRequest) (*models.ChartDataResponse, error)
+	GetPivotData(ctx context.Context, ds *models.DataSource, password string, tableName string, fields []models.AppField, req *models.PivotRequest) (*models.PivotResponse, error)
 	CountRecords(ctx context.Context, ds *models.DataSource, password string, tableName string) (int64, error)
 }
 
@@ -116,13 +154,139 @@ type DashboardWidgetRepositoryInterface interface {
 	GetByUserIDAndAppID(ctx context.Context, userID, appID uint64) (*models.DashboardWidget, error)
 	GetVisibleByUserID(ctx context.Context, userID uint64) ([]models.DashboardWidget, error)
 	Update(ctx context.Context, widget *models.DashboardWidget) error
+	UpdateIfVersion(ctx context.Context, widget *models.DashboardWidget, version int64) (bool, error)
 	Delete(ctx context.Context, id uint64) error
+	DeleteIfVersion(ctx context.Context, id uint64, version int64) (bool, error)
 	DeleteByUserIDAndAppID(ctx context.Context, userID, appID uint64) error
 	UpdateDisplayOrders(ctx context.Context, userID uint64, widgetIDs []uint64) error
 	GetMaxDisplayOrder(ctx context.Context, userID uint64) (int, error)
 	Exists(ctx context.Context, userID, appID uint64) (bool, error)
 }
 
+// GroupRepositoryInterface ユーザーグループデータベース操作のインターフェースを定義
+type GroupRepositoryInterface interface {
+	Create(ctx context.Context, group *models.Group) error
+	GetByID(ctx context.Context, id uint64) (*models.Group, error)
+	GetAll(ctx context.Context) ([]models.Group, error)
+	Update(ctx context.Context, group *models.Group) error
+	Delete(ctx context.Context, id uint64) error
+	NameExists(ctx context.Context, name string, excludeID uint64) (bool, error)
+	GetMembers(ctx context.Context, groupID uint64) ([]models.User, error)
+	AddMember(ctx context.Context, groupID, userID uint64) error
+	RemoveMember(ctx context.Context, groupID, userID uint64) error
+	GetGroupIDsByUserID(ctx context.Context, userID uint64) ([]uint64, error)
+}
+
+// AppPermissionRepositoryInterface アプリ権限データベース操作のインターフェースを定義
+type AppPermissionRepositoryInterface interface {
+	Create(ctx context.Context, perm *models.AppPermission) error
+	GetByID(ctx context.Context, id uint64) (*models.AppPermission, error)
+	GetByAppID(ctx context.Context, appID uint64) ([]models.AppPermission, error)
+	GetForSubjects(ctx context.Context, appID, userID uint64, groupIDs []uint64) ([]models.AppPermission, error)
+	HasAny(ctx context.Context, appID uint64) (bool, error)
+	SubjectExists(ctx context.Context, appID uint64, userID, groupID *uint64) (bool, error)
+	Update(ctx context.Context, perm *models.AppPermission) error
+	Delete(ctx context.Context, id uint64) error
+}
+
+// RecordRevisionRepositoryInterface レコード変更履歴データベース操作のインターフェースを定義
+type RecordRevisionRepositoryInterface interface {
+	Create(ctx context.Context, revision *models.RecordRevision) error
+	CreateBatch(ctx context.Context, revisions []models.RecordRevision) error
+	GetByID(ctx context.Context, id uint64) (*models.RecordRevision, error)
+	GetByRecordID(ctx context.Context, appID, recordID uint64, page, limit int) ([]models.RecordRevision, int64, error)
+}
+
+// WebhookRepositoryInterface Webhookデータベース操作のインターフェースを定義
+type WebhookRepositoryInterface interface {
+	Create(ctx context.Context, webhook *models.Webhook) error
+	GetByID(ctx context.Context, id uint64) (*models.Webhook, error)
+	GetByAppID(ctx context.Context, appID uint64) ([]models.Webhook, error)
+	Update(ctx context.Context, webhook *models.Webhook) error
+	Delete(ctx context.Context, id uint64) error
+}
+
+// WebhookDeliveryRepositoryInterface Webhook配信キューのデータベース操作のインターフェースを定義
+type WebhookDeliveryRepositoryInterface interface {
+	CreateBatch(ctx context.Context, deliveries []models.WebhookDelivery) error
+	GetByWebhookID(ctx context.Context, webhookID uint64, page, limit int) ([]models.WebhookDelivery, int64, error)
+	ClaimDue(ctx context.Context, now time.Time, limit int, lease time.Duration) ([]models.WebhookDelivery, error)
+	UpdateResult(ctx context.Context, delivery *models.WebhookDelivery) error
+}
+
+// AutomationRuleRepositoryInterface 自動化ルールのデータベース操作のインターフェースを定義
+type AutomationRuleRepositoryInterface interface {
+	Create(ctx context.Context, rule *models.AutomationRule) error
+	GetByID(ctx context.Context, id uint64) (*models.AutomationRule, error)
+	GetByAppID(ctx context.Context, appID uint64) ([]models.AutomationRule, error)
+	Update(ctx context.Context, rule *models.AutomationRule) error
+	Delete(ctx context.Context, id uint64) error
+	GetDue(ctx context.Context, now time.Time, limit int) ([]models.AutomationRule, error)
+	UpdateSchedule(ctx context.Context, rule *models.AutomationRule) error
+}
+
+// AutomationRunRepositoryInterface 自動化ルールの実行ログのデータベース操作のインターフェースを定義
+type AutomationRunRepositoryInterface interface {
+	Create(ctx context.Context, run *models.AutomationRun) error
+	GetByRuleID(ctx context.Context, ruleID uint64, page, limit int) ([]models.AutomationRun, int64, error)
+}
+
+// AdvisoryLockerInterface 複数のサーバー間で処理を排他するロックのインターフェースを定義
+type AdvisoryLockerInterface interface {
+	TryWithLock(ctx context.Context, key int64, fn func(ctx context.Context) error) (bool, error)
+}
+
+// TransactorInterface 複数のリポジトリの操作を1つのトランザクションで実行するインターフェースを定義
+type TransactorInterface interface {
+	RunInTx(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+// AttachmentRepositoryInterface 添付ファイルのデータベース操作のインターフェースを定義
+type AttachmentRepositoryInterface interface {
+	Create(ctx context.Context, attachment *models.Attachment) error
+	GetByID(ctx context.Context, id uint64) (*models.Attachment, error)
+	GetByIDs(ctx context.Context, ids []uint64) ([]models.Attachment, error)
+	SyncRecord(ctx context.Context, appID, recordID uint64, fieldCode string, ids []uint64, now time.Time) error
+	OrphanByRecords(ctx context.Context, appID uint64, recordIDs []uint64, now time.Time) error
+	OrphanByField(ctx context.Context, appID uint64, fieldCode string, now time.Time) error
+	OrphanByApp(ctx context.Context, appID uint64, now time.Time) error
+	GetPurgeable(ctx context.Context, pendingBefore time.Time, limit int) ([]models.Attachment, error)
+	DeletePurgeable(ctx context.Context, id uint64, pendingBefore time.Time) (bool, error)
+}
+
+// AppIndexRepositoryInterface 動的テーブルのインデックスの定義のデータベース操作のインターフェースを定義
+type AppIndexRepositoryInterface interface {
+	Create(ctx context.Context, index *models.AppIndex) error
+	GetByID(ctx context.Context, id uint64) (*models.AppIndex, error)
+	GetByAppID(ctx context.Context, appID uint64) ([]models.AppIndex, error)
+	Delete(ctx context.Context, id uint64) error
+}
+
+// DeletedRecordRepositoryInterface ごみ箱のレコードのデータベース操作のインターフェースを定義
+type DeletedRecordRepositoryInterface interface {
+	GetByAppID(ctx context.Context, appID, createdBy uint64, page, limit int) ([]models.DeletedRecord, int64, error)
+	GetByRecordID(ctx context.Context, appID, recordID uint64) (*models.DeletedRecord, error)
+	GetBefore(ctx context.Context, before time.Time, limit int) ([]models.DeletedRecord, error)
+	Delete(ctx context.Context, id uint64) error
+}
+
+// SavedReportRepositoryInterface 保存したレポートのデータベース操作のインターフェースを定義
+type SavedReportRepositoryInterface interface {
+	Create(ctx context.Context, report *models.SavedReport) error
+	GetByID(ctx context.Context, id uint64) (*models.SavedReport, error)
+	GetByAppID(ctx context.Context, appID uint64) ([]models.SavedReport, error)
+	Update(ctx context.Context, report *models.SavedReport) error
+	Delete(ctx context.Context, id uint64) error
+	GetDue(ctx context.Context, now time.Time, limit int) ([]models.SavedReport, error)
+	UpdateSchedule(ctx context.Context, report *models.SavedReport) error
+}
+
+// ReportDeliveryRepositoryInterface レポートの配信履歴のデータベース操作のインターフェースを定義
+type ReportDeliveryRepositoryInterface interface {
+	Create(ctx context.Context, delivery *models.ReportDelivery) error
+	GetByReportID(ctx context.Context, reportID uint64, page, limit int) ([]models.ReportDelivery, int64, error)
+}
+
 // 実装がインターフェースを満たすことを確認
 var (
 	_ UserRepositoryInterface            = (*UserRepository)(nil)
@@ -134,4 +298,18 @@ var (
 	_ DataSourceRepositoryInterface      = (*DataSourceRepository)(nil)
 	_ ExternalQueryExecutorInterface     = (*ExternalQueryExecutor)(nil)
 	_ DashboardWidgetRepositoryInterface = (*DashboardWidgetRepository)(nil)
+	_ GroupRepositoryInterface           = (*GroupRepository)(nil)
+	_ AppPermissionRepositoryInterface   = (*AppPermissionRepository)(nil)
+	_ RecordRevisionRepositoryInterface  = (*RecordRevisionRepository)(nil)
+	_ WebhookRepositoryInterface         = (*WebhookRepository)(nil)
+	_ WebhookDeliveryRepositoryInterface = (*WebhookDeliveryRepository)(nil)
+	_ AutomationRuleRepositoryInterface  = (*AutomationRuleRepository)(nil)
+	_ AutomationRunRepositoryInterface   = (*AutomationRunRepository)(nil)
+	_ AdvisoryLockerInterface            = (*AdvisoryLocker)(nil)
+	_ TransactorInterface                = (*Transactor)(nil)
+	_ AttachmentRepositoryInterface      = (*AttachmentRepository)(nil)
+	_ AppIndexRepositoryInterface        = (*AppIndexRepository)(nil)
+	_ DeletedRecordRepositoryInterface   = (*DeletedRecordRepository)(nil)
+	_ SavedReportRepositoryInterface     = (*SavedReportRepository)(nil)
+	_ ReportDeliveryRepositoryInterface  = (*ReportDeliveryRepository)(nil)
 )
@@ -0,0 +1,190 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"nocode-app/backend/internal/models"
+)
+
+// CountMode レコード一覧の総件数の求め方
+type CountMode string
+
+const (
+	// CountExact COUNT(*) で正確な件数を求める（既定）
+	CountExact CountMode = "exact"
+	// CountEstimate 統計情報（pg_class.reltuples、絞り込み条件がある場合は実行計画の推定行数）から推定する
+	CountEstimate CountMode = "estimate"
+	// CountNone 件数を求めない（総件数は-1になる）
+	CountNone CountMode = "none"
+)
+
+// IsValid 総件数の求め方が有効かどうかを返す（空は CountExact として扱う）
+func (m CountMode) IsValid() bool {
+	switch m {
+	case "", CountExact, CountEstimate, CountNone:
+		return true
+	}
+	return false
+}
+
+// rowQuerier 1行を返すクエリの実行インターフェース（bun.DB / sql.DB）
+type rowQuerier interface {
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// countRecords 総件数の求め方に従ってレコード数を返す（CountNone の場合は-1）
+// placeholder は pg_class を引くときのテーブル名の引数に使うプレースホルダ
+func countRecords(ctx context.Context, q rowQuerier, mode CountMode, quotedTable, whereSQL string, whereValues []interface{}, placeholder string) (int64, error) {
+	switch mode {
+	case CountNone:
+		return -1, nil
+	case CountEstimate:
+		estimate, err := estimateRecordCount(ctx, q, quotedTable, whereSQL, whereValues, placeholder)
+		if err != nil {
+			return 0, err
+		}
+		// 一度も ANALYZE されていないテーブルは推定できないため数える
+		if estimate >= 0 {
+			return estimate, nil
+		}
+	}
+
+	var total int64
+	countQuery := fmt.Sprintf("SELECT COUNT(*) FROM %s %s", quotedTable, whereSQL)
+	err := q.QueryRowContext(ctx, countQuery, whereValues...).Scan(&total)
+	return total, err
+}
+
+// estimateRecordCount 統計情報からレコード数を推定する（推定できない場合は-1）
+// 絞り込み条件がない場合は pg_class.reltuples、ある場合は実行計画の推定行数を使う
+func estimateRecordCount(ctx context.Context, q rowQuerier, quotedTable, whereSQL string, whereValues []interface{}, placeholder string) (int64, error) {
+	if whereSQL == "" {
+		var reltuples sql.NullFloat64
+		query := fmt.Sprintf("SELECT reltuples FROM pg_class WHERE oid = to_regclass(%s)", placeholder)
+		if err := q.QueryRowContext(ctx, query, quotedTable).Scan(&reltuples); err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return -1, nil
+			}
+			return 0, err
+		}
+		// PostgreSQL 14 以降は未集計のテーブルで-1、それ以前は0を返す
+		if !reltuples.Valid || reltuples.Float64 <= 0 {
+			return -1, nil
+		}
+		return int64(reltuples.Float64), nil
+	}
+
+	var plan string
+	query := fmt.Sprintf("EXPLAIN (FORMAT JSON) SELECT 1 FROM %s %s", quotedTable, whereSQL)
+	if err := q.QueryRowContext(ctx, query, whereValues...).Scan(&plan); err != nil {
+		return 0, err
+	}
+	return parsePlanRows(plan)
+}
+
+// parsePlanRows EXPLAIN (FORMAT JSON) の出力から最上位のノードの推定行数を取り出す
+func parsePlanRows(plan string) (int64, error) {
+	var explained []struct {
+		Plan struct {
+			PlanRows float64 `json:"Plan Rows"`
+		} `json:"Plan"`
+	}
+	if err := json.Unmarshal([]byte(plan), &explained); err != nil {
+		return 0, fmt.Errorf("実行計画の解析に失敗しました: %w", err)
+	}
+	if len(explained) == 0 {
+		return -1, nil
+	}
+	return int64(explained[0].Plan.PlanRows), nil
+}
+
+// recordKeyset カーソル方式のページ送りに使う並べ替えと絞り込みの式
+type recordKeyset struct {
+	sort  string
+	order string
+	// column 並べ替えのキーを文字列として取得するSELECT句の式（IDのみで並べる場合は空）
+	column string
+	// orderBy 並べ替えのキーとIDによるORDER BY句
+	orderBy string
+	// where 前のページの最後のレコードより後ろのレコードに絞り込む条件（1ページ目は空）
+	where       string
+	whereValues []interface{}
+}
+
+// buildRecordKeyset 並べ替えのキー（quotedSort、空の場合はIDのみ）とIDでページを送る式を構築する
+// PostgreSQL の既定の NULL の位置（昇順では末尾、降順では先頭）に合わせて NULL のキーを扱う。
+// placeholder は呼び出しごとに次のプレースホルダを返す関数
+func buildRecordKeyset(sort, quotedSort, quotedID, order string, after *models.RecordCursor, placeholder func() string) *recordKeyset {
+	desc := order == "desc"
+	dir, op := "ASC", ">"
+	if desc {
+		dir, op = "DESC", "<"
+	}
+
+	k := &recordKeyset{sort: sort, order: order}
+	if quotedSort == "" {
+		k.orderBy = quotedID + " " + dir
+	} else {
+		k.column = "CAST(" + quotedSort + " AS TEXT)"
+		k.orderBy = fmt.Sprintf("%s %s, %s %s", quotedSort, dir, quotedID, dir)
+	}
+	if after == nil {
+		return k
+	}
+
+	if quotedSort == "" {
+		k.where = fmt.Sprintf("%s %s %s", quotedID, op, placeholder())
+		k.whereValues = []interface{}{after.ID}
+		return k
+	}
+
+	switch {
+	case after.Value != nil && !desc:
+		// 値が大きいもの、同じ値でIDが大きいもの、末尾の NULL
+		k.where = fmt.Sprintf("(%[1]s > %[3]s OR (%[1]s = %[4]s AND %[2]s > %[5]s) OR %[1]s IS NULL)",
+			quotedSort, quotedID, placeholder(), placeholder(), placeholder())
+		k.whereValues = []interface{}{*after.Value, *after.Value, after.ID}
+	case after.Value != nil && desc:
+		// 値が小さいもの、同じ値でIDが小さいもの（NULL は先頭で取得済み）
+		k.where = fmt.Sprintf("(%[1]s < %[3]s OR (%[1]s = %[4]s AND %[2]s < %[5]s))",
+			quotedSort, quotedID, placeholder(), placeholder(), placeholder())
+		k.whereValues = []interface{}{*after.Value, *after.Value, after.ID}
+	case !desc:
+		// 末尾の NULL の中でIDが大きいもの
+		k.where = fmt.Sprintf("(%[1]s IS NULL AND %[2]s > %[3]s)", quotedSort, quotedID, placeholder())
+		k.whereValues = []interface{}{after.ID}
+	default:
+		// 先頭の NULL の中でIDが小さいものと、NULL 以外の全て
+		k.where = fmt.Sprintf("((%[1]s IS NULL AND %[2]s < %[3]s) OR %[1]s IS NOT NULL)", quotedSort, quotedID, placeholder())
+		k.whereValues = []interface{}{after.ID}
+	}
+	return k
+}
+
+// cursorAfter レコードの次から取得するためのカーソルを作成する
+func (k *recordKeyset) cursorAfter(id uint64, value sql.NullString) string {
+	c := &models.RecordCursor{Sort: k.sort, Order: k.order, ID: id}
+	if k.column != "" && value.Valid {
+		c.Value = &value.String
+	}
+	return c.Encode()
+}
+
+// bunPlaceholders bun のプレースホルダを返す関数を作成する
+func bunPlaceholders() func() string {
+	return func() string { return "?" }
+}
+
+// numberedPlaceholders start 番目から順に PostgreSQL の $N プレースホルダを返す関数を作成する
+func numberedPlaceholders(dbType models.DBType, start int) func() string {
+	next := start
+	return func() string {
+		p := getPlaceholder(dbType, next)
+		next++
+		return p
+	}
+}
@@ -0,0 +1,109 @@
+package repositories
+
+import (
+	"database/sql"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"nocode-app/backend/internal/models"
+)
+
+func TestBuildRecordKeyset(t *testing.T) {
+	value := "100"
+
+	t.Run("first page", func(t *testing.T) {
+		k := buildRecordKeyset("amount", `"amount"`, "id", "asc", nil, bunPlaceholders())
+		assert.Equal(t, `CAST("amount" AS TEXT)`, k.column)
+		assert.Equal(t, `"amount" ASC, id ASC`, k.orderBy)
+		assert.Empty(t, k.where)
+	})
+
+	t.Run("id only", func(t *testing.T) {
+		k := buildRecordKeyset("", "", "id", "desc", &models.RecordCursor{Order: "desc", ID: 10}, bunPlaceholders())
+		assert.Empty(t, k.column)
+		assert.Equal(t, "id DESC", k.orderBy)
+		assert.Equal(t, "id < ?", k.where)
+		assert.Equal(t, []interface{}{uint64(10)}, k.whereValues)
+	})
+
+	tests := []struct {
+		name   string
+		order  string
+		value  *string
+		where  string
+		values []interface{}
+	}{
+		{
+			name:   "ascending after a value includes trailing nulls",
+			order:  "asc",
+			value:  &value,
+			where:  `("amount" > ? OR ("amount" = ? AND id > ?) OR "amount" IS NULL)`,
+			values: []interface{}{"100", "100", uint64(5)},
+		},
+		{
+			name:   "descending after a value",
+			order:  "desc",
+			value:  &value,
+			where:  `("amount" < ? OR ("amount" = ? AND id < ?))`,
+			values: []interface{}{"100", "100", uint64(5)},
+		},
+		{
+			name:   "ascending after a null stays in nulls",
+			order:  "asc",
+			where:  `("amount" IS NULL AND id > ?)`,
+			values: []interface{}{uint64(5)},
+		},
+		{
+			name:   "descending after a null moves on to values",
+			order:  "desc",
+			where:  `(("amount" IS NULL AND id < ?) OR "amount" IS NOT NULL)`,
+			values: []interface{}{uint64(5)},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			after := &models.RecordCursor{Sort: "amount", Order: tt.order, Value: tt.value, ID: 5}
+			k := buildRecordKeyset("amount", `"amount"`, "id", tt.order, after, bunPlaceholders())
+			assert.Equal(t, tt.where, k.where)
+			assert.Equal(t, tt.values, k.whereValues)
+		})
+	}
+
+	t.Run("numbered placeholders", func(t *testing.T) {
+		after := &models.RecordCursor{Sort: "amount", Order: "desc", Value: &value, ID: 5}
+		k := buildRecordKeyset("amount", `"amount"`, `"id"`, "desc", after, numberedPlaceholders(models.DBTypePostgreSQL, 3))
+		assert.Equal(t, `("amount" < $3 OR ("amount" = $4 AND "id" < $5))`, k.where)
+	})
+}
+
+func TestRecordKeyset_CursorAfter(t *testing.T) {
+	k := buildRecordKeyset("amount", `"amount"`, "id", "asc", nil, bunPlaceholders())
+	cursor, err := models.DecodeRecordCursor(k.cursorAfter(9, sql.NullString{String: "12.5", Valid: true}))
+	require.NoError(t, err)
+	require.NotNil(t, cursor.Value)
+	assert.Equal(t, "12.5", *cursor.Value)
+	assert.Equal(t, uint64(9), cursor.ID)
+	assert.Equal(t, "amount", cursor.Sort)
+
+	cursor, err = models.DecodeRecordCursor(k.cursorAfter(9, sql.NullString{}))
+	require.NoError(t, err)
+	assert.Nil(t, cursor.Value)
+}
+
+func TestParsePlanRows(t *testing.T) {
+	rows, err := parsePlanRows(`[{"Plan": {"Node Type": "Seq Scan", "Plan Rows": 1234, "Plan Width": 4}}]`)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1234), rows)
+
+	_, err = parsePlanRows("not json")
+	assert.Error(t, err)
+}
+
+func TestCountMode_IsValid(t *testing.T) {
+	for _, mode := range []CountMode{"", CountExact, CountEstimate, CountNone} {
+		assert.True(t, mode.IsValid(), mode)
+	}
+	assert.False(t, CountMode("fast").IsValid())
+}
@@ -0,0 +1,243 @@
+package repositories
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"nocode-app/backend/internal/models"
+)
+
+// MaxPivotCells ピボット集計で取得できる行・列の組み合わせ（小計を含む）の上限
+const MaxPivotCells = 20000
+
+// ErrPivotTooLarge ピボット集計の結果が大きすぎる
+var ErrPivotTooLarge = fmt.Errorf("集計結果が多すぎます（行と列の組み合わせは%d件まで）。行・列のフィールドを減らすか、絞り込んでください", MaxPivotCells)
+
+// pivotQueryBuilder ピボット集計のクエリを構築し、結果を行列に変換する構造体
+// 小計・総計は GROUPING SETS でデータベースに集計させるため、平均・最小・最大の小計も正確に求まる
+type pivotQueryBuilder struct {
+	req            *models.PivotRequest
+	column         func(field string) (string, error)
+	localTimestamp func(quotedColumn string) string
+	rowExprs       []string
+	columnExprs    []string
+}
+
+// newPivotQueryBuilder 新しいpivotQueryBuilderを作成する
+func newPivotQueryBuilder(req *models.PivotRequest, column func(field string) (string, error), localTimestamp func(quotedColumn string) string) *pivotQueryBuilder {
+	return &pivotQueryBuilder{
+		req:            req,
+		column:         column,
+		localTimestamp: localTimestamp,
+	}
+}
+
+// build SELECT句・GROUP BY句・ORDER BY句を返す
+//
+// 行のフィールドの先頭i個と列のフィールドの先頭j個の全ての組み合わせを GROUPING SETS で集計する。
+// 並び順は行のフィールドごとに「値があるもの → 小計」の順にし、小計が対象の値の直後、総計が末尾になるようにする
+func (b *pivotQueryBuilder) build() (selectSQL, groupBy, orderBy string, err error) {
+	if len(b.req.Rows) == 0 || len(b.req.Measures) == 0 {
+		return "", "", "", errors.New("行のフィールドと集計値を指定してください")
+	}
+	b.rowExprs, err = b.dimensionExprs(b.req.Rows)
+	if err != nil {
+		return "", "", "", err
+	}
+	b.columnExprs, err = b.dimensionExprs(b.req.Columns)
+	if err != nil {
+		return "", "", "", err
+	}
+	dims := append(append([]string{}, b.rowExprs...), b.columnExprs...)
+
+	exprs := make([]string, 0, len(dims)*2+len(b.req.Measures))
+	exprs = append(exprs, dims...)
+	for _, d := range dims {
+		exprs = append(exprs, fmt.Sprintf("GROUPING(%s)", d))
+	}
+	for _, m := range b.req.Measures {
+		if m.Aggregation == "count" {
+			exprs = append(exprs, "COUNT(*)")
+			continue
+		}
+		quoted, colErr := b.column(m.Field)
+		if colErr != nil {
+			return "", "", "", fmt.Errorf("無効な集計フィールド: %w", colErr)
+		}
+		switch m.Aggregation {
+		case "sum", "avg", "min", "max":
+			exprs = append(exprs, fmt.Sprintf("%s(%s)", strings.ToUpper(m.Aggregation), quoted))
+		default:
+			return "", "", "", fmt.Errorf("無効な集計方法: %s", m.Aggregation)
+		}
+	}
+
+	sets := make([]string, 0, (len(b.rowExprs)+1)*(len(b.columnExprs)+1))
+	for i := len(b.rowExprs); i >= 0; i-- {
+		for j := len(b.columnExprs); j >= 0; j-- {
+			set := append(append([]string{}, b.rowExprs[:i]...), b.columnExprs[:j]...)
+			sets = append(sets, "("+strings.Join(set, ", ")+")")
+		}
+	}
+
+	orders := make([]string, 0, len(dims)*2)
+	for _, d := range dims {
+		orders = append(orders, fmt.Sprintf("GROUPING(%s)", d), d)
+	}
+
+	return strings.Join(exprs, ", "), "GROUPING SETS (" + strings.Join(sets, ", ") + ")", strings.Join(orders, ", "), nil
+}
+
+// dimensionExprs 行・列のフィールドの式を返す
+func (b *pivotQueryBuilder) dimensionExprs(dims []models.PivotDimension) ([]string, error) {
+	exprs := make([]string, len(dims))
+	for i, d := range dims {
+		quoted, err := b.column(d.Field)
+		if err != nil {
+			return nil, fmt.Errorf("無効な行・列のフィールド: %w", err)
+		}
+		exprs[i], err = bucketExpr(quoted, d.Bucket, d.DateOnly, b.req.TimeZone, b.localTimestamp)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return exprs, nil
+}
+
+// query 集計クエリを返す。上限を超えたかどうかを判定できるよう1行多く取得する
+func (b *pivotQueryBuilder) query(quotedTable, whereSQL string) (string, error) {
+	selectSQL, groupBy, orderBy, err := b.build()
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("SELECT %s FROM %s %s GROUP BY %s ORDER BY %s LIMIT %d",
+		selectSQL, quotedTable, whereSQL, groupBy, orderBy, MaxPivotCells+1), nil
+}
+
+// pivotKey 行・列の見出しを一意に表すキー
+func pivotKey(keys []string) string {
+	return strings.Join(keys, "\x00")
+}
+
+// pivotResult 集計クエリの1行（行・列の見出しと集計値）
+type pivotResult struct {
+	row    models.PivotHeader
+	column models.PivotHeader
+	values []*float64
+}
+
+// scan 集計クエリの結果を読み込む
+func (b *pivotQueryBuilder) scan(rows *sql.Rows) ([]pivotResult, error) {
+	nRows, nCols := len(b.rowExprs), len(b.columnExprs)
+	nDims := nRows + nCols
+
+	var results []pivotResult
+	for rows.Next() {
+		if len(results) >= MaxPivotCells {
+			return nil, ErrPivotTooLarge
+		}
+
+		dimValues := make([]interface{}, nDims)
+		grouping := make([]int, nDims)
+		values := make([]sql.NullFloat64, len(b.req.Measures))
+		dest := make([]interface{}, 0, nDims*2+len(values))
+		for i := range dimValues {
+			dest = append(dest, &dimValues[i])
+		}
+		for i := range grouping {
+			dest = append(dest, &grouping[i])
+		}
+		for i := range values {
+			dest = append(dest, &values[i])
+		}
+		if err := rows.Scan(dest...); err != nil {
+			return nil, err
+		}
+
+		// GROUPING が1のフィールドは集計済み（小計・総計）
+		header := func(offset, n int) models.PivotHeader {
+			keys := make([]string, 0, n)
+			for i := 0; i < n && grouping[offset+i] == 0; i++ {
+				keys = append(keys, chartLabel(dimValues[offset+i]))
+			}
+			return models.PivotHeader{Keys: keys, Total: len(keys) < n}
+		}
+		result := pivotResult{
+			row:    header(0, nRows),
+			column: header(nRows, nCols),
+			values: make([]*float64, len(values)),
+		}
+		for i := range values {
+			if values[i].Valid {
+				v := values[i].Float64
+				result.values[i] = &v
+			}
+		}
+		results = append(results, result)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// response 集計クエリの結果を行列に変換する
+// 行の順は最初に現れた順、列の順は総計の行に現れた順（いずれも ORDER BY により値の順になる）
+func (b *pivotQueryBuilder) response(results []pivotResult) *models.PivotResponse {
+	type cellKey struct{ row, column string }
+	cells := make(map[cellKey][]*float64, len(results))
+	rowHeaders := []models.PivotHeader{}
+	columnHeaders := []models.PivotHeader{}
+	rowIndex := make(map[string]bool)
+	columnIndex := make(map[string]bool)
+
+	for _, r := range results {
+		rowKey, columnKey := pivotKey(r.row.Keys), pivotKey(r.column.Keys)
+		if !rowIndex[rowKey] {
+			rowIndex[rowKey] = true
+			rowHeaders = append(rowHeaders, r.row)
+		}
+		if len(r.row.Keys) == 0 && !columnIndex[columnKey] {
+			columnIndex[columnKey] = true
+			columnHeaders = append(columnHeaders, r.column)
+		}
+		cells[cellKey{rowKey, columnKey}] = r.values
+	}
+
+	resp := &models.PivotResponse{
+		RowFields:    pivotDimensionLabels(b.req.Rows),
+		ColumnFields: pivotDimensionLabels(b.req.Columns),
+		Measures:     make([]string, len(b.req.Measures)),
+		Columns:      columnHeaders,
+		Rows:         make([]models.PivotRow, len(rowHeaders)),
+	}
+	for i, m := range b.req.Measures {
+		resp.Measures[i] = chartMeasureLabel(models.ChartAxis{Field: m.Field, Aggregation: m.Aggregation, Label: m.Label})
+	}
+	for i, rh := range rowHeaders {
+		row := models.PivotRow{PivotHeader: rh, Values: make([][]*float64, len(columnHeaders))}
+		for j, ch := range columnHeaders {
+			values, ok := cells[cellKey{pivotKey(rh.Keys), pivotKey(ch.Keys)}]
+			if !ok {
+				values = make([]*float64, len(b.req.Measures))
+			}
+			row.Values[j] = values
+		}
+		resp.Rows[i] = row
+	}
+	return resp
+}
+
+// pivotDimensionLabels 行・列のフィールドの見出し（省略時はフィールドコード）
+func pivotDimensionLabels(dims []models.PivotDimension) []string {
+	labels := make([]string, len(dims))
+	for i, d := range dims {
+		labels[i] = d.Label
+		if labels[i] == "" {
+			labels[i] = d.Field
+		}
+	}
+	return labels
+}
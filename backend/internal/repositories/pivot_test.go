@@ -0,0 +1,125 @@
+package repositories
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"nocode-app/backend/internal/models"
+)
+
+// TestPivotQueryBuilder_Build 小計・総計を求める GROUPING SETS と並び順をテストする
+func TestPivotQueryBuilder_Build(t *testing.T) {
+	utcTimestamp := func(col string) string { return "(" + col + " AT TIME ZONE 'UTC')" }
+
+	t.Run("rows and columns", func(t *testing.T) {
+		req := &models.PivotRequest{
+			Rows:     []models.PivotDimension{{Field: "region"}},
+			Columns:  []models.PivotDimension{{Field: "status"}},
+			Measures: []models.PivotMeasure{{Aggregation: "count"}, {Field: "amount", Aggregation: "avg"}},
+		}
+		b := newPivotQueryBuilder(req, quoteIdentifier, utcTimestamp)
+		selectSQL, groupBy, orderBy, err := b.build()
+		require.NoError(t, err)
+		assert.Equal(t, `"region", "status", GROUPING("region"), GROUPING("status"), COUNT(*), AVG("amount")`, selectSQL)
+		assert.Equal(t, `GROUPING SETS (("region", "status"), ("region"), ("status"), ())`, groupBy)
+		assert.Equal(t, `GROUPING("region"), "region", GROUPING("status"), "status"`, orderBy)
+	})
+
+	t.Run("nested rows with date bucket", func(t *testing.T) {
+		req := &models.PivotRequest{
+			Rows: []models.PivotDimension{
+				{Field: "region"},
+				{Field: "due", Bucket: "month", DateOnly: true},
+			},
+			Measures: []models.PivotMeasure{{Field: "amount", Aggregation: "sum"}},
+		}
+		b := newPivotQueryBuilder(req, quoteIdentifier, utcTimestamp)
+		_, groupBy, _, err := b.build()
+		require.NoError(t, err)
+		assert.Equal(t, `GROUPING SETS (("region", to_char(date_trunc('month', "due"::timestamp), 'YYYY-MM')), ("region"), ())`, groupBy)
+	})
+
+	t.Run("invalid field", func(t *testing.T) {
+		req := &models.PivotRequest{
+			Rows:     []models.PivotDimension{{Field: `region"; DROP TABLE x; --`}},
+			Measures: []models.PivotMeasure{{Aggregation: "count"}},
+		}
+		_, _, _, err := newPivotQueryBuilder(req, quoteIdentifier, utcTimestamp).build()
+		assert.Error(t, err)
+	})
+
+	t.Run("query has a row limit", func(t *testing.T) {
+		req := &models.PivotRequest{
+			Rows:     []models.PivotDimension{{Field: "region"}},
+			Measures: []models.PivotMeasure{{Aggregation: "count"}},
+		}
+		query, err := newPivotQueryBuilder(req, quoteIdentifier, utcTimestamp).query(`"app_data_1"`, "")
+		require.NoError(t, err)
+		assert.Contains(t, query, `FROM "app_data_1"  GROUP BY GROUPING SETS`)
+		assert.Contains(t, query, "LIMIT 20001")
+	})
+}
+
+// TestPivotQueryBuilder_Response 集計クエリの結果から行列を組み立てる処理をテストする
+func TestPivotQueryBuilder_Response(t *testing.T) {
+	num := func(v float64) *float64 { return &v }
+	header := func(total bool, keys ...string) models.PivotHeader {
+		if keys == nil {
+			keys = []string{}
+		}
+		return models.PivotHeader{Keys: keys, Total: total}
+	}
+
+	t.Run("subtotals and grand total", func(t *testing.T) {
+		req := &models.PivotRequest{
+			Rows:     []models.PivotDimension{{Field: "region", Label: "地域"}},
+			Columns:  []models.PivotDimension{{Field: "status"}},
+			Measures: []models.PivotMeasure{{Aggregation: "count"}},
+		}
+		b := newPivotQueryBuilder(req, quoteIdentifier, nil)
+		// ORDER BY による並び順で返される
+		resp := b.response([]pivotResult{
+			{row: header(false, "東"), column: header(false, "open"), values: []*float64{num(2)}},
+			{row: header(false, "東"), column: header(true), values: []*float64{num(2)}},
+			{row: header(false, "西"), column: header(false, "closed"), values: []*float64{num(1)}},
+			{row: header(false, "西"), column: header(false, "open"), values: []*float64{num(3)}},
+			{row: header(false, "西"), column: header(true), values: []*float64{num(4)}},
+			{row: header(true), column: header(false, "closed"), values: []*float64{num(1)}},
+			{row: header(true), column: header(false, "open"), values: []*float64{num(5)}},
+			{row: header(true), column: header(true), values: []*float64{num(6)}},
+		})
+
+		assert.Equal(t, []string{"地域"}, resp.RowFields)
+		assert.Equal(t, []string{"status"}, resp.ColumnFields)
+		assert.Equal(t, []string{"count"}, resp.Measures)
+		assert.Equal(t, []models.PivotHeader{header(false, "closed"), header(false, "open"), header(true)}, resp.Columns)
+		require.Len(t, resp.Rows, 3)
+		assert.Equal(t, header(false, "東"), resp.Rows[0].PivotHeader)
+		// 該当するレコードがない組み合わせはnull
+		assert.Equal(t, [][]*float64{{nil}, {num(2)}, {num(2)}}, resp.Rows[0].Values)
+		assert.Equal(t, [][]*float64{{num(1)}, {num(3)}, {num(4)}}, resp.Rows[1].Values)
+		assert.Equal(t, header(true), resp.Rows[2].PivotHeader)
+		assert.Equal(t, [][]*float64{{num(1)}, {num(5)}, {num(6)}}, resp.Rows[2].Values)
+	})
+
+	t.Run("without columns", func(t *testing.T) {
+		req := &models.PivotRequest{
+			Rows:     []models.PivotDimension{{Field: "region"}, {Field: "owner"}},
+			Measures: []models.PivotMeasure{{Field: "amount", Aggregation: "sum"}, {Field: "amount", Aggregation: "max", Label: "最大"}},
+		}
+		b := newPivotQueryBuilder(req, quoteIdentifier, nil)
+		resp := b.response([]pivotResult{
+			{row: header(false, "東", "山田"), column: header(false), values: []*float64{num(100), num(60)}},
+			{row: header(true, "東"), column: header(false), values: []*float64{num(100), num(60)}},
+			{row: header(true), column: header(false), values: []*float64{num(100), num(60)}},
+		})
+
+		assert.Equal(t, []string{"sum(amount)", "最大"}, resp.Measures)
+		assert.Equal(t, []models.PivotHeader{header(false)}, resp.Columns)
+		require.Len(t, resp.Rows, 3)
+		assert.Equal(t, header(true, "東"), resp.Rows[1].PivotHeader)
+		assert.Equal(t, [][]*float64{{num(100), num(60)}}, resp.Rows[2].Values)
+	})
+}
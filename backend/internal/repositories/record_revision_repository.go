@@ -0,0 +1,82 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/uptrace/bun"
+
+	"nocode-app/backend/internal/models"
+)
+
+// RecordRevisionRepository レコード変更履歴のデータベース操作を処理する構造体
+type RecordRevisionRepository struct {
+	db *bun.DB
+}
+
+// NewRecordRevisionRepository 新しいRecordRevisionRepositoryを作成する
+func NewRecordRevisionRepository(db *bun.DB) *RecordRevisionRepository {
+	return &RecordRevisionRepository{db: db}
+}
+
+// Create 変更履歴を記録する
+func (r *RecordRevisionRepository) Create(ctx context.Context, revision *models.RecordRevision) error {
+	_, err := txOrDB(ctx, r.db).NewInsert().
+		Model(revision).
+		Exec(ctx)
+	if err != nil {
+		return fmt.Errorf("変更履歴の記録に失敗しました: %w", err)
+	}
+	return nil
+}
+
+// CreateBatch 複数の変更履歴をまとめて記録する
+func (r *RecordRevisionRepository) CreateBatch(ctx context.Context, revisions []models.RecordRevision) error {
+	if len(revisions) == 0 {
+		return nil
+	}
+	_, err := txOrDB(ctx, r.db).NewInsert().
+		Model(&revisions).
+		Exec(ctx)
+	if err != nil {
+		return fmt.Errorf("変更履歴の記録に失敗しました: %w", err)
+	}
+	return nil
+}
+
+// GetByID IDで変更履歴を取得する
+func (r *RecordRevisionRepository) GetByID(ctx context.Context, id uint64) (*models.RecordRevision, error) {
+	revision := new(models.RecordRevision)
+	err := r.db.NewSelect().
+		Model(revision).
+		Relation("Actor").
+		Where("rr.id = ?", id).
+		Scan(ctx)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("変更履歴の取得に失敗しました: %w", err)
+	}
+	return revision, nil
+}
+
+// GetByRecordID レコードの変更履歴を新しい順にページネーション付きで取得する
+func (r *RecordRevisionRepository) GetByRecordID(ctx context.Context, appID, recordID uint64, page, limit int) ([]models.RecordRevision, int64, error) {
+	var revisions []models.RecordRevision
+	count, err := r.db.NewSelect().
+		Model(&revisions).
+		Relation("Actor").
+		Where("rr.app_id = ?", appID).
+		Where("rr.record_id = ?", recordID).
+		Order("rr.id DESC").
+		Limit(limit).
+		Offset((page - 1) * limit).
+		ScanAndCount(ctx)
+	if err != nil {
+		return nil, 0, fmt.Errorf("変更履歴一覧の取得に失敗しました: %w", err)
+	}
+	return revisions, int64(count), nil
+}
@@ -0,0 +1,87 @@
+package repositories_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"nocode-app/backend/internal/models"
+	"nocode-app/backend/internal/repositories"
+	"nocode-app/backend/internal/testhelpers"
+)
+
+func TestRecordRevisionRepository_CreateAndGet(t *testing.T) {
+	ctx := context.Background()
+	db, err := testhelpers.GetTestDB(ctx)
+	require.NoError(t, err)
+
+	t.Cleanup(func() {
+		require.NoError(t, testhelpers.ResetDatabase(ctx))
+	})
+
+	repo := repositories.NewRecordRevisionRepository(db)
+	app := createTestApp(ctx, t, "app_data_revision_create")
+	adminID := getAdminUserID(ctx, t)
+
+	revision := &models.RecordRevision{
+		AppID:     app.ID,
+		RecordID:  1,
+		Action:    models.RevisionActionUpdate,
+		Changes:   models.RecordChanges{"name": {Before: "A", After: "B"}},
+		Snapshot:  models.RecordData{"name": "B", "tags": []interface{}{"x"}},
+		ChangedBy: &adminID,
+		CreatedAt: time.Now(),
+	}
+	require.NoError(t, repo.Create(ctx, revision))
+	assert.NotZero(t, revision.ID)
+
+	found, err := repo.GetByID(ctx, revision.ID)
+	require.NoError(t, err)
+	require.NotNil(t, found)
+	assert.Equal(t, models.RevisionActionUpdate, found.Action)
+	assert.Equal(t, "A", found.Changes["name"].Before)
+	assert.Equal(t, []interface{}{"x"}, found.Snapshot["tags"])
+	require.NotNil(t, found.Actor)
+	assert.Equal(t, "Admin", found.Actor.Name)
+
+	missing, err := repo.GetByID(ctx, 99999)
+	require.NoError(t, err)
+	assert.Nil(t, missing)
+}
+
+func TestRecordRevisionRepository_GetByRecordID(t *testing.T) {
+	ctx := context.Background()
+	db, err := testhelpers.GetTestDB(ctx)
+	require.NoError(t, err)
+
+	t.Cleanup(func() {
+		require.NoError(t, testhelpers.ResetDatabase(ctx))
+	})
+
+	repo := repositories.NewRecordRevisionRepository(db)
+	app := createTestApp(ctx, t, "app_data_revision_list")
+
+	revisions := []models.RecordRevision{
+		{AppID: app.ID, RecordID: 1, Action: models.RevisionActionCreate, Snapshot: models.RecordData{"name": "A"}, CreatedAt: time.Now()},
+		{AppID: app.ID, RecordID: 1, Action: models.RevisionActionUpdate, Snapshot: models.RecordData{"name": "B"}, CreatedAt: time.Now()},
+		{AppID: app.ID, RecordID: 2, Action: models.RevisionActionCreate, Snapshot: models.RecordData{"name": "C"}, CreatedAt: time.Now()},
+	}
+	require.NoError(t, repo.CreateBatch(ctx, revisions))
+
+	found, total, err := repo.GetByRecordID(ctx, app.ID, 1, 1, 10)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), total)
+	require.Len(t, found, 2)
+	// 新しい順に並ぶ
+	assert.Equal(t, models.RevisionActionUpdate, found[0].Action)
+	assert.Nil(t, found[0].Actor)
+
+	paged, total, err := repo.GetByRecordID(ctx, app.ID, 1, 2, 1)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), total)
+	require.Len(t, paged, 1)
+	assert.Equal(t, models.RevisionActionCreate, paged[0].Action)
+}
@@ -0,0 +1,47 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/uptrace/bun"
+
+	"nocode-app/backend/internal/models"
+)
+
+// ReportDeliveryRepository レポートの配信履歴のデータベース操作を処理する構造体
+type ReportDeliveryRepository struct {
+	db *bun.DB
+}
+
+// NewReportDeliveryRepository 新しいReportDeliveryRepositoryを作成する
+func NewReportDeliveryRepository(db *bun.DB) *ReportDeliveryRepository {
+	return &ReportDeliveryRepository{db: db}
+}
+
+// Create 配信履歴を1件記録する
+func (r *ReportDeliveryRepository) Create(ctx context.Context, delivery *models.ReportDelivery) error {
+	_, err := r.db.NewInsert().
+		Model(delivery).
+		Exec(ctx)
+	if err != nil {
+		return fmt.Errorf("レポートの配信履歴の記録に失敗しました: %w", err)
+	}
+	return nil
+}
+
+// GetByReportID レポートの配信履歴を新しい順にページネーション付きで取得する
+func (r *ReportDeliveryRepository) GetByReportID(ctx context.Context, reportID uint64, page, limit int) ([]models.ReportDelivery, int64, error) {
+	var deliveries []models.ReportDelivery
+	count, err := r.db.NewSelect().
+		Model(&deliveries).
+		Where("rd.report_id = ?", reportID).
+		Order("rd.id DESC").
+		Limit(limit).
+		Offset((page - 1) * limit).
+		ScanAndCount(ctx)
+	if err != nil {
+		return nil, 0, fmt.Errorf("レポートの配信履歴の取得に失敗しました: %w", err)
+	}
+	return deliveries, int64(count), nil
+}
@@ -0,0 +1,122 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/uptrace/bun"
+
+	"nocode-app/backend/internal/models"
+)
+
+// SavedReportRepository 保存したレポートのデータベース操作を処理する構造体
+type SavedReportRepository struct {
+	db *bun.DB
+}
+
+// NewSavedReportRepository 新しいSavedReportRepositoryを作成する
+func NewSavedReportRepository(db *bun.DB) *SavedReportRepository {
+	return &SavedReportRepository{db: db}
+}
+
+// Create 新しいレポートを保存する
+func (r *SavedReportRepository) Create(ctx context.Context, report *models.SavedReport) error {
+	_, err := r.db.NewInsert().
+		Model(report).
+		Exec(ctx)
+	if err != nil {
+		return fmt.Errorf("レポートの保存に失敗しました: %w", err)
+	}
+	return nil
+}
+
+// GetByID IDで保存したレポートを取得する
+func (r *SavedReportRepository) GetByID(ctx context.Context, id uint64) (*models.SavedReport, error) {
+	report := new(models.SavedReport)
+	err := r.db.NewSelect().
+		Model(report).
+		Where("sr.id = ?", id).
+		Scan(ctx)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("レポートの取得に失敗しました: %w", err)
+	}
+	return report, nil
+}
+
+// GetByAppID アプリに保存された全レポートを保存順に取得する
+func (r *SavedReportRepository) GetByAppID(ctx context.Context, appID uint64) ([]models.SavedReport, error) {
+	reports := make([]models.SavedReport, 0)
+	err := r.db.NewSelect().
+		Model(&reports).
+		Where("sr.app_id = ?", appID).
+		Order("sr.id ASC").
+		Scan(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("レポート一覧の取得に失敗しました: %w", err)
+	}
+	return reports, nil
+}
+
+// Update 保存したレポートの設定を更新する
+// 次回・前回の配信時刻はスケジューラーが並行して更新するため、UpdateSchedule でのみ更新する
+func (r *SavedReportRepository) Update(ctx context.Context, report *models.SavedReport) error {
+	_, err := r.db.NewUpdate().
+		Model(report).
+		ExcludeColumn("next_run_at", "last_run_at").
+		WherePK().
+		Exec(ctx)
+	if err != nil {
+		return fmt.Errorf("レポートの更新に失敗しました: %w", err)
+	}
+	return nil
+}
+
+// GetDue 次回配信時刻を過ぎた有効なレポートを、次回配信時刻の早い順に最大limit件取得する
+// ごみ箱にあるアプリのレポートは配信しない
+func (r *SavedReportRepository) GetDue(ctx context.Context, now time.Time, limit int) ([]models.SavedReport, error) {
+	reports := make([]models.SavedReport, 0)
+	err := r.db.NewSelect().
+		Model(&reports).
+		Where("sr.next_run_at <= ?", now).
+		Where("sr.is_active = TRUE").
+		Where("sr.app_id IN (SELECT id FROM apps WHERE deleted_at IS NULL)").
+		Order("sr.next_run_at ASC", "sr.id ASC").
+		Limit(limit).
+		Scan(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("配信予定のレポートの取得に失敗しました: %w", err)
+	}
+	return reports, nil
+}
+
+// UpdateSchedule レポートの次回・前回の配信時刻のみを保存する
+// レポートの設定を編集中のユーザーの変更を上書きしないよう、他のカラムは更新しない
+func (r *SavedReportRepository) UpdateSchedule(ctx context.Context, report *models.SavedReport) error {
+	_, err := r.db.NewUpdate().
+		Model(report).
+		Column("next_run_at", "last_run_at").
+		WherePK().
+		Exec(ctx)
+	if err != nil {
+		return fmt.Errorf("レポートの配信時刻の保存に失敗しました: %w", err)
+	}
+	return nil
+}
+
+// Delete 保存したレポートを削除する（配信履歴は外部キーのCASCADEで削除される）
+func (r *SavedReportRepository) Delete(ctx context.Context, id uint64) error {
+	_, err := r.db.NewDelete().
+		Model((*models.SavedReport)(nil)).
+		Where("id = ?", id).
+		Exec(ctx)
+	if err != nil {
+		return fmt.Errorf("レポートの削除に失敗しました: %w", err)
+	}
+	return nil
+}
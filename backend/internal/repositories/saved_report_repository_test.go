@@ -0,0 +1,152 @@
+package repositories_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"nocode-app/backend/internal/models"
+	"nocode-app/backend/internal/repositories"
+	"nocode-app/backend/internal/testhelpers"
+)
+
+func TestSavedReportRepository_CRUD(t *testing.T) {
+	ctx := context.Background()
+	db, err := testhelpers.GetTestDB(ctx)
+	require.NoError(t, err)
+
+	t.Cleanup(func() {
+		require.NoError(t, testhelpers.ResetDatabase(ctx))
+	})
+
+	repo := repositories.NewSavedReportRepository(db)
+	deliveryRepo := repositories.NewReportDeliveryRepository(db)
+	app := createTestApp(ctx, t, "app_data_saved_report_crud")
+	adminID := getAdminUserID(ctx, t)
+
+	report := &models.SavedReport{
+		AppID:      app.ID,
+		Name:       "地域別件数",
+		ReportType: models.ReportTypePivot,
+		Config: models.SavedReportConfig{Pivot: &models.PivotRequest{
+			Rows:     []models.PivotDimension{{Field: "region"}},
+			Measures: []models.PivotMeasure{{Aggregation: "count"}},
+			Filters:  []models.FilterItem{{Field: "amount", Operator: "gt", Value: "0"}},
+		}},
+		Frequency:  models.ReportFrequencyWeekly,
+		SendHour:   9,
+		Weekday:    1,
+		Timezone:   "Asia/Tokyo",
+		Recipients: []string{"a@example.com", "b@example.com"},
+		IsActive:   true,
+		CreatedBy:  &adminID,
+	}
+	require.NoError(t, repo.Create(ctx, report))
+	assert.NotZero(t, report.ID)
+
+	found, err := repo.GetByID(ctx, report.ID)
+	require.NoError(t, err)
+	require.NotNil(t, found)
+	assert.Equal(t, models.ReportTypePivot, found.ReportType)
+	require.NotNil(t, found.Config.Pivot)
+	assert.Equal(t, report.Config.Pivot.Filters, found.Config.Pivot.Filters)
+	assert.Nil(t, found.Config.Chart)
+	assert.Equal(t, []string{"a@example.com", "b@example.com"}, found.Recipients)
+	// 毎月の配信日を指定しない場合は1日
+	assert.Equal(t, 1, found.DayOfMonth)
+
+	found.IsActive = false
+	require.NoError(t, repo.Update(ctx, found))
+
+	list, err := repo.GetByAppID(ctx, app.ID)
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+	assert.False(t, list[0].IsActive)
+
+	// 配信履歴は新しい順に取得する
+	for _, status := range []models.ReportDeliveryStatus{models.ReportDeliverySucceeded, models.ReportDeliveryFailed} {
+		require.NoError(t, deliveryRepo.Create(ctx, &models.ReportDelivery{
+			ReportID: report.ID, AppID: app.ID, Trigger: models.ReportDeliveryTriggerSchedule, Status: status,
+			Recipients: report.Recipients, Subject: "[テスト] 地域別件数", RowCount: 3, CreatedAt: time.Now(),
+		}))
+	}
+	deliveries, total, err := deliveryRepo.GetByReportID(ctx, report.ID, 1, 20)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), total)
+	require.Len(t, deliveries, 2)
+	assert.Equal(t, models.ReportDeliveryFailed, deliveries[0].Status)
+	assert.Equal(t, report.Recipients, deliveries[0].Recipients)
+
+	// 削除すると配信履歴も削除される
+	require.NoError(t, repo.Delete(ctx, report.ID))
+
+	missing, err := repo.GetByID(ctx, report.ID)
+	require.NoError(t, err)
+	assert.Nil(t, missing)
+
+	deliveries, total, err = deliveryRepo.GetByReportID(ctx, report.ID, 1, 20)
+	require.NoError(t, err)
+	assert.Empty(t, deliveries)
+	assert.Zero(t, total)
+}
+
+func TestSavedReportRepository_GetDue(t *testing.T) {
+	ctx := context.Background()
+	db, err := testhelpers.GetTestDB(ctx)
+	require.NoError(t, err)
+
+	t.Cleanup(func() {
+		require.NoError(t, testhelpers.ResetDatabase(ctx))
+	})
+
+	repo := repositories.NewSavedReportRepository(db)
+	app := createTestApp(ctx, t, "app_data_saved_report_due")
+	now := time.Date(2026, 10, 16, 0, 0, 0, 0, time.UTC)
+	past, future := now.Add(-time.Minute), now.Add(time.Hour)
+
+	newReport := func(name string, next *time.Time, active bool) *models.SavedReport {
+		report := &models.SavedReport{
+			AppID:      app.ID,
+			Name:       name,
+			ReportType: models.ReportTypeRecords,
+			Config:     models.SavedReportConfig{Records: &models.RecordReportConfig{}},
+			Frequency:  models.ReportFrequencyDaily,
+			Timezone:   "UTC",
+			Recipients: []string{"a@example.com"},
+			IsActive:   active,
+			NextRunAt:  next,
+		}
+		require.NoError(t, repo.Create(ctx, report))
+		return report
+	}
+	due := newReport("配信予定", &past, true)
+	newReport("未来", &future, true)
+	newReport("無効", &past, false)
+	newReport("予約なし", nil, true)
+
+	reports, err := repo.GetDue(ctx, now, 10)
+	require.NoError(t, err)
+	require.Len(t, reports, 1)
+	assert.Equal(t, due.ID, reports[0].ID)
+
+	// 配信時刻を保存すると対象外になる
+	reports[0].NextRunAt, reports[0].LastRunAt = &future, &now
+	require.NoError(t, repo.UpdateSchedule(ctx, &reports[0]))
+
+	reports, err = repo.GetDue(ctx, now, 10)
+	require.NoError(t, err)
+	assert.Empty(t, reports)
+
+	// 設定の更新は配信時刻を書き換えない
+	due.Name = "名前を変更"
+	require.NoError(t, repo.Update(ctx, due))
+
+	found, err := repo.GetByID(ctx, due.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "名前を変更", found.Name)
+	require.NotNil(t, found.NextRunAt)
+	assert.True(t, future.Equal(*found.NextRunAt))
+}
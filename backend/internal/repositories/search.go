@@ -0,0 +1,153 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"nocode-app/backend/internal/models"
+)
+
+// searchColumn 全文検索用の生成列の名前（フィールドコードは英字で始まるため重複しない）
+const searchColumn = `"_search"`
+
+// searchHeadlineOptions ts_headline のオプション（一致箇所を区切り文字で示し、HTMLへの変換はアプリ側で行う）
+var searchHeadlineOptions = fmt.Sprintf(
+	`StartSel="%s", StopSel="%s", MaxFragments=2, MaxWords=20, MinWords=5, FragmentDelimiter=" … "`,
+	models.HighlightStart, models.HighlightStop,
+)
+
+// SetSearchColumn 全文検索用の生成列とGINインデックスを作成する
+// 既にある場合は作り直すため、検索対象のフィールドや形式の変更にも使う。
+// テキスト検索設定の場合は tsvector、trigram の場合は検索対象のフィールドを連結した文字列を pg_trgm で索引する
+func (e *DynamicQueryExecutor) SetSearchColumn(ctx context.Context, tableName string, fields []models.AppField, config string) error {
+	if !models.IsValidSearchConfig(config) {
+		return fmt.Errorf("無効な全文検索の形式: %q", config)
+	}
+
+	quotedTable, err := quoteIdentifier(tableName)
+	if err != nil {
+		return fmt.Errorf("無効なテーブル名: %w", err)
+	}
+	quotedIndex, err := quoteIdentifier("idx_" + tableName + "_search")
+	if err != nil {
+		return fmt.Errorf("無効なインデックス名: %w", err)
+	}
+	document, err := searchDocument(fields)
+	if err != nil {
+		return err
+	}
+
+	columnDef := fmt.Sprintf("tsvector GENERATED ALWAYS AS (to_tsvector('%s'::regconfig, %s)) STORED", config, document)
+	indexDef := searchColumn
+	if config == models.SearchConfigTrigram {
+		columnDef = fmt.Sprintf("text GENERATED ALWAYS AS (%s) STORED", document)
+		indexDef = searchColumn + " gin_trgm_ops"
+	}
+
+	// 生成列の式は変更できないため、削除（インデックスも削除される）と追加を1つのトランザクションで行う
+	tx, err := txOrDB(ctx, e.db).BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("トランザクション開始に失敗しました: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	statements := []string{
+		fmt.Sprintf("ALTER TABLE %s DROP COLUMN IF EXISTS %s", quotedTable, searchColumn),
+		fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", quotedTable, searchColumn, columnDef),
+		fmt.Sprintf("CREATE INDEX %s ON %s USING GIN (%s)", quotedIndex, quotedTable, indexDef),
+	}
+	for _, stmt := range statements {
+		if _, err := tx.ExecContext(ctx, stmt); err != nil {
+			return fmt.Errorf("全文検索用カラムの作成に失敗しました: %w", err)
+		}
+	}
+	return tx.Commit()
+}
+
+// searchDocument 検索対象のフィールドを空白で連結する式を返す（対象がない場合は空文字）
+func searchDocument(fields []models.AppField) (string, error) {
+	parts := make([]string, 0, len(fields))
+	for i := range fields {
+		if !models.IsSearchableField(&fields[i]) {
+			continue
+		}
+		quotedCol, err := quoteIdentifier(fields[i].FieldCode)
+		if err != nil {
+			return "", fmt.Errorf("無効なカラム名 %q: %w", fields[i].FieldCode, err)
+		}
+		parts = append(parts, fmt.Sprintf("coalesce(%s, '')", quotedCol))
+	}
+	if len(parts) == 0 {
+		return "''::text", nil
+	}
+	return strings.Join(parts, " || ' ' || "), nil
+}
+
+// recordSearch 全文検索用カラムを使った検索の条件・一致度・抜粋の式
+type recordSearch struct {
+	where       string
+	whereValues []interface{}
+	// columns 一致度（と抜粋）を取得するSELECT句の式
+	columns      []string
+	columnValues []interface{}
+	// headline 抜粋をデータベースで作成するかどうか（trigram ではアプリ側で作成する）
+	headline bool
+}
+
+// buildRecordSearch 全文検索のキーワードから検索の式を構築する（検索しない場合はnil）
+// テキスト検索設定の場合は websearch_to_tsquery で解釈し、trigram の場合は空白で区切った語をすべて含むものを探す
+func buildRecordSearch(fields []models.AppField, opts RecordQueryOptions) (*recordSearch, error) {
+	query := strings.TrimSpace(opts.Search)
+	if query == "" || opts.SearchConfig == "" {
+		return nil, nil
+	}
+	if !models.IsValidSearchConfig(opts.SearchConfig) {
+		return nil, fmt.Errorf("無効な全文検索の形式: %q", opts.SearchConfig)
+	}
+
+	if opts.SearchConfig == models.SearchConfigTrigram {
+		terms := strings.Fields(query)
+		conditions := make([]string, len(terms))
+		values := make([]interface{}, len(terms))
+		for i, term := range terms {
+			conditions[i] = searchColumn + " ILIKE ?"
+			values[i] = "%" + escapeLike(term) + "%"
+		}
+		return &recordSearch{
+			where:        strings.Join(conditions, " AND "),
+			whereValues:  values,
+			columns:      []string{"word_similarity(?, " + searchColumn + ")"},
+			columnValues: []interface{}{query},
+		}, nil
+	}
+
+	document, err := searchDocument(fields)
+	if err != nil {
+		return nil, err
+	}
+	tsquery := fmt.Sprintf("websearch_to_tsquery('%s'::regconfig, ?)", opts.SearchConfig)
+	return &recordSearch{
+		where:       searchColumn + " @@ " + tsquery,
+		whereValues: []interface{}{query},
+		columns: []string{
+			fmt.Sprintf("ts_rank_cd(%s, %s)", searchColumn, tsquery),
+			fmt.Sprintf("ts_headline('%s'::regconfig, %s, %s, ?)", opts.SearchConfig, document, tsquery),
+		},
+		columnValues: []interface{}{query, query, searchHeadlineOptions},
+		headline:     true,
+	}, nil
+}
+
+// escapeLike LIKE のパターンで特別な意味を持つ文字をエスケープする
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
+
+// appendWhere WHERE句に条件をANDで追加する
+func appendWhere(whereSQL, condition string) string {
+	if whereSQL == "" {
+		return "WHERE " + condition
+	}
+	return whereSQL + " AND " + condition
+}
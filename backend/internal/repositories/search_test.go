@@ -0,0 +1,76 @@
+package repositories
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"nocode-app/backend/internal/models"
+)
+
+func TestSearchDocument(t *testing.T) {
+	fields := []models.AppField{
+		{FieldCode: "title", FieldType: "text"},
+		{FieldCode: "amount", FieldType: "number"},
+		{FieldCode: "memo", FieldType: "textarea"},
+	}
+	document, err := searchDocument(fields)
+	require.NoError(t, err)
+	assert.Equal(t, `coalesce("title", '') || ' ' || coalesce("memo", '')`, document)
+
+	document, err = searchDocument(fields[1:2])
+	require.NoError(t, err)
+	assert.Equal(t, `''::text`, document)
+}
+
+func TestBuildRecordSearch(t *testing.T) {
+	fields := []models.AppField{
+		{FieldCode: "title", FieldType: "text"},
+		{FieldCode: "memo", FieldType: "textarea"},
+	}
+
+	t.Run("text search configuration", func(t *testing.T) {
+		search, err := buildRecordSearch(fields, RecordQueryOptions{Search: " quick fox ", SearchConfig: "english"})
+		require.NoError(t, err)
+		require.NotNil(t, search)
+		assert.Equal(t, `"_search" @@ websearch_to_tsquery('english'::regconfig, ?)`, search.where)
+		assert.Equal(t, []interface{}{"quick fox"}, search.whereValues)
+		assert.Equal(t, []string{
+			`ts_rank_cd("_search", websearch_to_tsquery('english'::regconfig, ?))`,
+			`ts_headline('english'::regconfig, coalesce("title", '') || ' ' || coalesce("memo", ''), websearch_to_tsquery('english'::regconfig, ?), ?)`,
+		}, search.columns)
+		assert.Equal(t, []interface{}{"quick fox", "quick fox", searchHeadlineOptions}, search.columnValues)
+		assert.True(t, search.headline)
+	})
+
+	t.Run("trigram requires every term", func(t *testing.T) {
+		search, err := buildRecordSearch(fields, RecordQueryOptions{Search: "株式会社 100%_", SearchConfig: "trigram"})
+		require.NoError(t, err)
+		require.NotNil(t, search)
+		assert.Equal(t, `"_search" ILIKE ? AND "_search" ILIKE ?`, search.where)
+		assert.Equal(t, []interface{}{"%株式会社%", `%100\%\_%`}, search.whereValues)
+		assert.Equal(t, []string{`word_similarity(?, "_search")`}, search.columns)
+		assert.False(t, search.headline)
+	})
+
+	t.Run("no search", func(t *testing.T) {
+		search, err := buildRecordSearch(fields, RecordQueryOptions{Search: "fox"})
+		require.NoError(t, err)
+		assert.Nil(t, search)
+
+		search, err = buildRecordSearch(fields, RecordQueryOptions{Search: "  ", SearchConfig: "simple"})
+		require.NoError(t, err)
+		assert.Nil(t, search)
+	})
+
+	t.Run("unknown configuration", func(t *testing.T) {
+		_, err := buildRecordSearch(fields, RecordQueryOptions{Search: "fox", SearchConfig: "english'::regconfig"})
+		assert.Error(t, err)
+	})
+}
+
+func TestAppendWhere(t *testing.T) {
+	assert.Equal(t, `WHERE "_search" @@ x`, appendWhere("", `"_search" @@ x`))
+	assert.Equal(t, `WHERE "a" = ? AND "_search" @@ x`, appendWhere(`WHERE "a" = ?`, `"_search" @@ x`))
+}
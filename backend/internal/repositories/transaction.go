@@ -0,0 +1,67 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/uptrace/bun"
+)
+
+// txKey コンテキストに実行中のトランザクションを保持するキー
+type txKey struct{}
+
+// txState 実行中のトランザクションと、コミット後に実行する処理
+type txState struct {
+	tx          bun.Tx
+	afterCommit []func()
+}
+
+// Transactor 複数のリポジトリの操作を1つのトランザクションで実行する構造体
+type Transactor struct {
+	db *bun.DB
+}
+
+// NewTransactor 新しいTransactorを作成する
+func NewTransactor(db *bun.DB) *Transactor {
+	return &Transactor{db: db}
+}
+
+// RunInTx fnに渡したコンテキストを使うリポジトリの操作を1つのトランザクションで実行する
+// fnがエラーを返した場合はロールバックする。既にトランザクション中の場合はそのトランザクションに含める
+func (t *Transactor) RunInTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	if _, ok := ctx.Value(txKey{}).(*txState); ok {
+		return fn(ctx)
+	}
+
+	state := &txState{}
+	err := t.db.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		state.tx = tx
+		return fn(context.WithValue(ctx, txKey{}, state))
+	})
+	if err != nil {
+		return err
+	}
+	for _, f := range state.afterCommit {
+		f()
+	}
+	return nil
+}
+
+// AfterCommit ctxのトランザクションがコミットされた後にfnを実行する
+// トランザクション中でない場合はすぐに実行し、ロールバックされた場合は実行しない
+func AfterCommit(ctx context.Context, fn func()) {
+	state, ok := ctx.Value(txKey{}).(*txState)
+	if !ok {
+		fn()
+		return
+	}
+	state.afterCommit = append(state.afterCommit, fn)
+}
+
+// txOrDB ctxがトランザクション中であればそのトランザクションを、そうでなければdbを返す
+// トランザクション中に独自のトランザクションを開始するとセーブポイントになる
+func txOrDB(ctx context.Context, db bun.IDB) bun.IDB {
+	if state, ok := ctx.Value(txKey{}).(*txState); ok {
+		return state.tx
+	}
+	return db
+}
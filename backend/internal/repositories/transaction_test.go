@@ -0,0 +1,87 @@
+package repositories_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"nocode-app/backend/internal/models"
+	"nocode-app/backend/internal/repositories"
+	"nocode-app/backend/internal/testhelpers"
+)
+
+func TestTransactor_RunInTx(t *testing.T) {
+	ctx := context.Background()
+	db, err := testhelpers.GetTestDB(ctx)
+	require.NoError(t, err)
+
+	t.Cleanup(func() {
+		require.NoError(t, testhelpers.ResetDatabase(ctx))
+	})
+
+	transactor := repositories.NewTransactor(db)
+	executor := repositories.NewDynamicQueryExecutor(db)
+	revisionRepo := repositories.NewRecordRevisionRepository(db)
+	adminID := getAdminUserID(ctx, t)
+
+	fields := []models.AppField{
+		{FieldCode: "title", FieldName: "Title", FieldType: "text"},
+	}
+	require.NoError(t, executor.CreateTable(ctx, "app_data_tx", fields))
+
+	t.Run("rolls back every repository on error", func(t *testing.T) {
+		committed := false
+		err := transactor.RunInTx(ctx, func(ctx context.Context) error {
+			recordID, err := executor.InsertRecord(ctx, "app_data_tx", models.RecordData{"title": "Rolled back"}, adminID)
+			if err != nil {
+				return err
+			}
+			// トランザクション中は挿入したレコードを取得できる
+			record, err := executor.GetRecordByID(ctx, "app_data_tx", fields, recordID)
+			if err != nil {
+				return err
+			}
+			require.NotNil(t, record)
+
+			revision := models.RecordRevision{AppID: 1, RecordID: recordID, Action: models.RevisionActionCreate, Changes: models.RecordChanges{}}
+			if err := revisionRepo.Create(ctx, &revision); err != nil {
+				return err
+			}
+			repositories.AfterCommit(ctx, func() { committed = true })
+			return errors.New("abort")
+		})
+		require.EqualError(t, err, "abort")
+		assert.False(t, committed)
+
+		records, total, err := executor.GetRecords(ctx, "app_data_tx", fields, repositories.RecordQueryOptions{Page: 1, Limit: 10})
+		require.NoError(t, err)
+		assert.Zero(t, total)
+		assert.Empty(t, records)
+	})
+
+	t.Run("commits and runs after-commit functions", func(t *testing.T) {
+		committed := false
+		var recordID uint64
+		err := transactor.RunInTx(ctx, func(ctx context.Context) error {
+			var err error
+			recordID, err = executor.InsertRecord(ctx, "app_data_tx", models.RecordData{"title": "Committed"}, adminID)
+			if err != nil {
+				return err
+			}
+			repositories.AfterCommit(ctx, func() { committed = true })
+			// コミットするまでは実行しない
+			assert.False(t, committed)
+			return nil
+		})
+		require.NoError(t, err)
+		assert.True(t, committed)
+
+		record, err := executor.GetRecordByID(ctx, "app_data_tx", fields, recordID)
+		require.NoError(t, err)
+		require.NotNil(t, record)
+		assert.Equal(t, "Committed", record.Data["title"])
+	})
+}
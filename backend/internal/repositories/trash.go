@@ -0,0 +1,219 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/uptrace/bun"
+)
+
+// ErrDeletedRecordNotFound 復元するレコードがごみ箱にない場合のエラー
+var ErrDeletedRecordNotFound = errors.New("ごみ箱にレコードがありません")
+
+// cascadeReference 参照先のレコードを削除すると連鎖して削除される参照元のカラム（削除時の動作が cascade の外部キー）
+type cascadeReference struct {
+	TableName  string `bun:"table_name"`
+	ColumnName string `bun:"column_name"`
+}
+
+// trashTarget ごみ箱に移すテーブルとレコードID
+type trashTarget struct {
+	tableName string
+	recordIDs []uint64
+}
+
+// TrashRecords レコードの全カラムの値をごみ箱に保存し、動的テーブルから削除する
+// 削除時の動作が cascade の参照フィールドで連鎖して削除される参照元のレコードも、それぞれのアプリのごみ箱に保存する。
+// restrict の参照フィールドから参照されている場合は外部キー制約違反のエラーとなり、何も削除しない
+func (e *DynamicQueryExecutor) TrashRecords(ctx context.Context, tableName string, recordIDs []uint64, userID uint64) error {
+	if len(recordIDs) == 0 {
+		return nil
+	}
+	_, err := e.trashRecords(ctx, tableName, recordIDs, 0, userID)
+	return err
+}
+
+// TrashRecordIfVersion バージョンが一致する場合のみレコードをごみ箱に移す（楽観的排他制御）
+// 他の更新と競合してバージョンが変わっていた、またはレコードが存在しない場合は何も削除せず false を返す
+func (e *DynamicQueryExecutor) TrashRecordIfVersion(ctx context.Context, tableName string, recordID uint64, version int64, userID uint64) (bool, error) {
+	return e.trashRecords(ctx, tableName, []uint64{recordID}, version, userID)
+}
+
+// trashRecords レコードをごみ箱に移す。version が0でない場合は、バージョンが一致するときのみ移す
+func (e *DynamicQueryExecutor) trashRecords(ctx context.Context, tableName string, recordIDs []uint64, version int64, userID uint64) (bool, error) {
+	quotedTable, err := quoteIdentifier(tableName)
+	if err != nil {
+		return false, fmt.Errorf("無効なテーブル名: %w", err)
+	}
+
+	tx, err := txOrDB(ctx, e.db).BeginTx(ctx, nil)
+	if err != nil {
+		return false, fmt.Errorf("トランザクション開始に失敗しました: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	// バージョンが一致するレコードを削除まで他の更新からロックする
+	if version != 0 {
+		var ids []uint64
+		query := fmt.Sprintf("SELECT id FROM %s WHERE id IN (?) AND %s = ? FOR UPDATE", quotedTable, versionExpr("updated_at"))
+		if err := tx.NewRaw(query, bun.In(recordIDs), version).Scan(ctx, &ids); err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return false, err
+		}
+		if len(ids) != len(recordIDs) {
+			return false, nil
+		}
+	}
+
+	var deletedBy interface{}
+	if userID != 0 {
+		deletedBy = userID
+	}
+
+	// 削除するレコードから参照元をたどり、連鎖して削除されるレコードを削除前に保存する
+	// 自己参照や循環する参照で同じレコードを2回保存しないよう、保存済みのレコードは除く
+	saved := make(map[string]map[uint64]bool)
+	queue := []trashTarget{{tableName: tableName, recordIDs: recordIDs}}
+	for len(queue) > 0 {
+		target := queue[0]
+		queue = queue[1:]
+
+		if saved[target.tableName] == nil {
+			saved[target.tableName] = make(map[uint64]bool)
+		}
+		ids := make([]uint64, 0, len(target.recordIDs))
+		for _, id := range target.recordIDs {
+			if !saved[target.tableName][id] {
+				saved[target.tableName][id] = true
+				ids = append(ids, id)
+			}
+		}
+		if len(ids) == 0 {
+			continue
+		}
+
+		children, err := saveDeletedRecords(ctx, tx, target.tableName, ids, deletedBy)
+		if err != nil {
+			return false, err
+		}
+		queue = append(queue, children...)
+	}
+
+	query := fmt.Sprintf("DELETE FROM %s WHERE id IN (?)", quotedTable)
+	if _, err := tx.ExecContext(ctx, query, bun.In(recordIDs)); err != nil {
+		return false, err
+	}
+	if err := tx.Commit(); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// saveDeletedRecords テーブルのレコードをごみ箱に保存し、連鎖して削除される参照元のレコードを返す
+func saveDeletedRecords(ctx context.Context, tx bun.Tx, tableName string, recordIDs []uint64, deletedBy interface{}) ([]trashTarget, error) {
+	quotedTable, err := quoteIdentifier(tableName)
+	if err != nil {
+		return nil, fmt.Errorf("無効なテーブル名: %w", err)
+	}
+
+	insertSQL := fmt.Sprintf(`INSERT INTO deleted_records (app_id, record_id, data, created_by, deleted_by)
+		SELECT a.id, t.id, to_jsonb(t), t.created_by, ?
+		FROM %s AS t
+		JOIN apps AS a ON a.table_name = ?
+		WHERE t.id IN (?)`, quotedTable)
+	if _, err := tx.ExecContext(ctx, insertSQL, deletedBy, tableName, bun.In(recordIDs)); err != nil {
+		return nil, fmt.Errorf("ごみ箱への保存に失敗しました: %w", err)
+	}
+
+	var refs []cascadeReference
+	err = tx.NewRaw(`SELECT child.relname AS table_name, att.attname AS column_name
+		FROM pg_constraint AS c
+		JOIN pg_class AS child ON child.oid = c.conrelid
+		JOIN pg_attribute AS att ON att.attrelid = c.conrelid AND att.attnum = c.conkey[1]
+		WHERE c.contype = 'f' AND c.confdeltype = 'c' AND c.confrelid = to_regclass(?)
+		ORDER BY child.relname, att.attname`, quotedTable).
+		Scan(ctx, &refs)
+	if err != nil {
+		return nil, fmt.Errorf("参照元のテーブルの取得に失敗しました: %w", err)
+	}
+
+	children := make([]trashTarget, 0, len(refs))
+	for _, ref := range refs {
+		quotedChild, err := quoteIdentifier(ref.TableName)
+		if err != nil {
+			return nil, fmt.Errorf("無効な参照元のテーブル名: %w", err)
+		}
+		quotedCol, err := quoteIdentifier(ref.ColumnName)
+		if err != nil {
+			return nil, fmt.Errorf("無効な参照元のカラム名: %w", err)
+		}
+
+		var ids []uint64
+		query := fmt.Sprintf("SELECT id FROM %s WHERE %s IN (?)", quotedChild, quotedCol)
+		if err := tx.NewRaw(query, bun.In(recordIDs)).Scan(ctx, &ids); err != nil {
+			return nil, fmt.Errorf("参照元のレコードの取得に失敗しました: %w", err)
+		}
+		if len(ids) > 0 {
+			children = append(children, trashTarget{tableName: ref.TableName, recordIDs: ids})
+		}
+	}
+	return children, nil
+}
+
+// RestoreRecord ごみ箱のレコードを削除時と同じIDで動的テーブルに挿入し直し、ごみ箱から取り除く
+// 削除後に追加されたカラムはNULL、削除後になくなったカラムの値は捨てる。計算フィールドと全文検索用の生成列は挿入しない。
+// 値はデータベース内で変換するため、数値の精度は変わらない
+func (e *DynamicQueryExecutor) RestoreRecord(ctx context.Context, tableName string, deletedRecordID uint64) error {
+	quotedTable, err := quoteIdentifier(tableName)
+	if err != nil {
+		return fmt.Errorf("無効なテーブル名: %w", err)
+	}
+
+	tx, err := e.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("トランザクション開始に失敗しました: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	var columns []string
+	err = tx.NewRaw(`SELECT attname FROM pg_attribute
+		WHERE attrelid = to_regclass(?) AND attnum > 0 AND NOT attisdropped AND attgenerated = ''
+		ORDER BY attnum`, quotedTable).
+		Scan(ctx, &columns)
+	if err != nil {
+		return fmt.Errorf("カラムの取得に失敗しました: %w", err)
+	}
+	if len(columns) == 0 {
+		return fmt.Errorf("テーブル %s がありません", tableName)
+	}
+
+	quotedCols := make([]string, len(columns))
+	sourceCols := make([]string, len(columns))
+	for i, column := range columns {
+		quotedCol, err := quoteIdentifier(column)
+		if err != nil {
+			return fmt.Errorf("無効なカラム名: %w", err)
+		}
+		quotedCols[i] = quotedCol
+		sourceCols[i] = "r." + quotedCol
+	}
+
+	insertSQL := fmt.Sprintf(`INSERT INTO %s (%s)
+		SELECT %s FROM deleted_records AS dr, jsonb_populate_record(NULL::%s, dr.data) AS r
+		WHERE dr.id = ?`,
+		quotedTable, strings.Join(quotedCols, ", "), strings.Join(sourceCols, ", "), quotedTable)
+	result, err := tx.ExecContext(ctx, insertSQL, deletedRecordID)
+	if err != nil {
+		return err
+	}
+	if n, err := result.RowsAffected(); err == nil && n == 0 {
+		return ErrDeletedRecordNotFound
+	}
+
+	if _, err := tx.ExecContext(ctx, "DELETE FROM deleted_records WHERE id = ?", deletedRecordID); err != nil {
+		return fmt.Errorf("ごみ箱からの削除に失敗しました: %w", err)
+	}
+	return tx.Commit()
+}
@@ -75,14 +75,35 @@ func (r *ViewRepository) GetDefaultByAppID(ctx context.Context, appID uint64) (*
 }
 
 // Update ビューを更新する
+// 更新日時はトリガで設定されるため、設定された値を view に読み戻す
 func (r *ViewRepository) Update(ctx context.Context, view *models.AppView) error {
 	_, err := r.db.NewUpdate().
 		Model(view).
 		WherePK().
+		Returning("updated_at").
 		Exec(ctx)
 	return err
 }
 
+// UpdateIfVersion バージョンが一致する場合のみビューを更新する（楽観的排他制御）
+// 他の更新と競合してバージョンが変わっていた、またはビューが存在しない場合は false を返す
+func (r *ViewRepository) UpdateIfVersion(ctx context.Context, view *models.AppView, version int64) (bool, error) {
+	res, err := r.db.NewUpdate().
+		Model(view).
+		WherePK().
+		Where(versionExpr("updated_at")+" = ?", version).
+		Returning("updated_at").
+		Exec(ctx)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return n > 0, nil
+}
+
 // Delete ビューを削除する
 func (r *ViewRepository) Delete(ctx context.Context, id uint64) error {
 	_, err := r.db.NewDelete().
@@ -92,6 +113,24 @@ func (r *ViewRepository) Delete(ctx context.Context, id uint64) error {
 	return err
 }
 
+// DeleteIfVersion バージョンが一致する場合のみビューを削除する（楽観的排他制御）
+// 他の更新と競合してバージョンが変わっていた、またはビューが存在しない場合は false を返す
+func (r *ViewRepository) DeleteIfVersion(ctx context.Context, id uint64, version int64) (bool, error) {
+	res, err := r.db.NewDelete().
+		Model((*models.AppView)(nil)).
+		Where("id = ?", id).
+		Where(versionExpr("updated_at")+" = ?", version).
+		Exec(ctx)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return n > 0, nil
+}
+
 // ClearDefaultByAppID アプリの全ビューのデフォルトフラグをクリアする
 func (r *ViewRepository) ClearDefaultByAppID(ctx context.Context, appID uint64) error {
 	_, err := r.db.NewUpdate().
@@ -241,6 +241,56 @@ func TestViewRepository_Delete(t *testing.T) {
 	assert.Nil(t, deleted)
 }
 
+func TestViewRepository_IfVersion(t *testing.T) {
+	ctx := context.Background()
+	db, err := testhelpers.GetTestDB(ctx)
+	require.NoError(t, err)
+
+	t.Cleanup(func() {
+		require.NoError(t, testhelpers.ResetDatabase(ctx))
+	})
+
+	repo := repositories.NewViewRepository(db)
+	app := createTestApp(ctx, t, "app_data_view_version")
+
+	view := &models.AppView{
+		AppID:     app.ID,
+		Name:      "Original",
+		ViewType:  "table",
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+	require.NoError(t, repo.Create(ctx, view))
+	stored, err := repo.GetByID(ctx, view.ID)
+	require.NoError(t, err)
+	version := models.VersionOf(stored.UpdatedAt)
+
+	// 取得したときのバージョンで更新できる
+	view.Name = "First"
+	updated, err := repo.UpdateIfVersion(ctx, view, version)
+	require.NoError(t, err)
+	assert.True(t, updated)
+
+	// 更新後は古いバージョンでは更新も削除もできない
+	view.Name = "Second"
+	updated, err = repo.UpdateIfVersion(ctx, view, version)
+	require.NoError(t, err)
+	assert.False(t, updated)
+	deleted, err := repo.DeleteIfVersion(ctx, view.ID, version)
+	require.NoError(t, err)
+	assert.False(t, deleted)
+
+	stored, err = repo.GetByID(ctx, view.ID)
+	require.NoError(t, err)
+	require.NotNil(t, stored)
+	assert.Equal(t, "First", stored.Name)
+
+	// 現在のバージョンでは削除できる
+	deleted, err = repo.DeleteIfVersion(ctx, view.ID, models.VersionOf(stored.UpdatedAt))
+	require.NoError(t, err)
+	assert.True(t, deleted)
+}
+
 func TestViewRepository_ClearDefaultByAppID(t *testing.T) {
 	ctx := context.Background()
 	db, err := testhelpers.GetTestDB(ctx)
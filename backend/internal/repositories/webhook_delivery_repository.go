@@ -0,0 +1,91 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/uptrace/bun"
+
+	"nocode-app/backend/internal/models"
+)
+
+// WebhookDeliveryRepository Webhook配信キューのデータベース操作を処理する構造体
+type WebhookDeliveryRepository struct {
+	db *bun.DB
+}
+
+// NewWebhookDeliveryRepository 新しいWebhookDeliveryRepositoryを作成する
+func NewWebhookDeliveryRepository(db *bun.DB) *WebhookDeliveryRepository {
+	return &WebhookDeliveryRepository{db: db}
+}
+
+// CreateBatch 複数の配信をまとめて配信キューに登録する
+func (r *WebhookDeliveryRepository) CreateBatch(ctx context.Context, deliveries []models.WebhookDelivery) error {
+	if len(deliveries) == 0 {
+		return nil
+	}
+	_, err := txOrDB(ctx, r.db).NewInsert().
+		Model(&deliveries).
+		Exec(ctx)
+	if err != nil {
+		return fmt.Errorf("Webhook配信の登録に失敗しました: %w", err)
+	}
+	return nil
+}
+
+// GetByWebhookID Webhookの配信ログを新しい順にページネーション付きで取得する
+func (r *WebhookDeliveryRepository) GetByWebhookID(ctx context.Context, webhookID uint64, page, limit int) ([]models.WebhookDelivery, int64, error) {
+	var deliveries []models.WebhookDelivery
+	count, err := r.db.NewSelect().
+		Model(&deliveries).
+		Where("wd.webhook_id = ?", webhookID).
+		Order("wd.id DESC").
+		Limit(limit).
+		Offset((page - 1) * limit).
+		ScanAndCount(ctx)
+	if err != nil {
+		return nil, 0, fmt.Errorf("Webhook配信ログの取得に失敗しました: %w", err)
+	}
+	return deliveries, int64(count), nil
+}
+
+// ClaimDue 送信時刻を過ぎた未送信の配信を最大limit件取得し、試行回数を1増やす
+// 取得した配信の次回送信時刻をleaseだけ先に延ばすため、複数のワーカーが同じ配信を同時に送信せず、
+// 送信中にプロセスが停止した配信もlease経過後に再送される
+func (r *WebhookDeliveryRepository) ClaimDue(ctx context.Context, now time.Time, limit int, lease time.Duration) ([]models.WebhookDelivery, error) {
+	due := r.db.NewSelect().
+		Model((*models.WebhookDelivery)(nil)).
+		Column("id").
+		Where("status = ?", models.WebhookDeliveryPending).
+		Where("next_attempt_at <= ?", now).
+		Order("next_attempt_at ASC", "id ASC").
+		Limit(limit).
+		For("UPDATE SKIP LOCKED")
+
+	var deliveries []models.WebhookDelivery
+	_, err := r.db.NewUpdate().
+		Model((*models.WebhookDelivery)(nil)).
+		Set("attempts = attempts + 1").
+		Set("next_attempt_at = ?", now.Add(lease)).
+		Where("id IN (?)", due).
+		Returning("*").
+		Exec(ctx, &deliveries)
+	if err != nil {
+		return nil, fmt.Errorf("Webhook配信の取得に失敗しました: %w", err)
+	}
+	return deliveries, nil
+}
+
+// UpdateResult 配信の送信結果（状態・応答・次回送信時刻）を保存する
+func (r *WebhookDeliveryRepository) UpdateResult(ctx context.Context, delivery *models.WebhookDelivery) error {
+	_, err := r.db.NewUpdate().
+		Model(delivery).
+		Column("status", "next_attempt_at", "last_status_code", "last_error", "delivered_at").
+		WherePK().
+		Exec(ctx)
+	if err != nil {
+		return fmt.Errorf("Webhook配信結果の保存に失敗しました: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,105 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/uptrace/bun"
+
+	"nocode-app/backend/internal/models"
+)
+
+// WebhookRepository Webhookのデータベース操作を処理する構造体
+type WebhookRepository struct {
+	db *bun.DB
+}
+
+// NewWebhookRepository 新しいWebhookRepositoryを作成する
+func NewWebhookRepository(db *bun.DB) *WebhookRepository {
+	return &WebhookRepository{db: db}
+}
+
+// Create 新しいWebhookを登録する
+func (r *WebhookRepository) Create(ctx context.Context, webhook *models.Webhook) error {
+	_, err := r.db.NewInsert().
+		Model(webhook).
+		Exec(ctx)
+	if err != nil {
+		return fmt.Errorf("Webhookの登録に失敗しました: %w", err)
+	}
+	return nil
+}
+
+// GetByID IDでWebhookを取得する
+func (r *WebhookRepository) GetByID(ctx context.Context, id uint64) (*models.Webhook, error) {
+	webhook := new(models.Webhook)
+	err := r.db.NewSelect().
+		Model(webhook).
+		Where("wh.id = ?", id).
+		Scan(ctx)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("Webhookの取得に失敗しました: %w", err)
+	}
+	return webhook, nil
+}
+
+// GetByAppID アプリに登録された全Webhookを取得する
+func (r *WebhookRepository) GetByAppID(ctx context.Context, appID uint64) ([]models.Webhook, error) {
+	webhooks := make([]models.Webhook, 0)
+	err := r.db.NewSelect().
+		Model(&webhooks).
+		Where("wh.app_id = ?", appID).
+		Order("wh.id ASC").
+		Scan(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("Webhook一覧の取得に失敗しました: %w", err)
+	}
+	return webhooks, nil
+}
+
+// Update Webhookを更新する
+func (r *WebhookRepository) Update(ctx context.Context, webhook *models.Webhook) error {
+	_, err := r.db.NewUpdate().
+		Model(webhook).
+		WherePK().
+		Exec(ctx)
+	if err != nil {
+		return fmt.Errorf("Webhookの更新に失敗しました: %w", err)
+	}
+	return nil
+}
+
+// Delete Webhookとその配信ログを削除する
+// 未送信の配信も削除するため、削除後はイベントを送信しない
+func (r *WebhookRepository) Delete(ctx context.Context, id uint64) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("トランザクションの開始に失敗しました: %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	if _, err := tx.NewDelete().
+		Model((*models.WebhookDelivery)(nil)).
+		Where("webhook_id = ?", id).
+		Exec(ctx); err != nil {
+		return fmt.Errorf("Webhook配信ログの削除に失敗しました: %w", err)
+	}
+	if _, err := tx.NewDelete().
+		Model((*models.Webhook)(nil)).
+		Where("id = ?", id).
+		Exec(ctx); err != nil {
+		return fmt.Errorf("Webhookの削除に失敗しました: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("トランザクションのコミットに失敗しました: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,173 @@
+package repositories_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"nocode-app/backend/internal/models"
+	"nocode-app/backend/internal/repositories"
+	"nocode-app/backend/internal/testhelpers"
+)
+
+func TestWebhookRepository_CRUD(t *testing.T) {
+	ctx := context.Background()
+	db, err := testhelpers.GetTestDB(ctx)
+	require.NoError(t, err)
+
+	t.Cleanup(func() {
+		require.NoError(t, testhelpers.ResetDatabase(ctx))
+	})
+
+	repo := repositories.NewWebhookRepository(db)
+	deliveryRepo := repositories.NewWebhookDeliveryRepository(db)
+	app := createTestApp(ctx, t, "app_data_webhook_crud")
+	adminID := getAdminUserID(ctx, t)
+
+	webhook := &models.Webhook{
+		AppID:     app.ID,
+		URL:       "https://example.com/hook",
+		Secret:    "secret",
+		Events:    []models.WebhookEventType{models.WebhookEventRecordCreated, models.WebhookEventAppDeleted},
+		IsActive:  true,
+		CreatedBy: &adminID,
+	}
+	require.NoError(t, repo.Create(ctx, webhook))
+	assert.NotZero(t, webhook.ID)
+
+	found, err := repo.GetByID(ctx, webhook.ID)
+	require.NoError(t, err)
+	require.NotNil(t, found)
+	assert.Equal(t, "secret", found.Secret)
+	assert.Equal(t, webhook.Events, found.Events)
+
+	found.IsActive = false
+	found.Events = []models.WebhookEventType{models.WebhookEventRecordDeleted}
+	require.NoError(t, repo.Update(ctx, found))
+
+	list, err := repo.GetByAppID(ctx, app.ID)
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+	assert.False(t, list[0].IsActive)
+	assert.Equal(t, []models.WebhookEventType{models.WebhookEventRecordDeleted}, list[0].Events)
+
+	// 削除すると配信ログも削除される
+	require.NoError(t, deliveryRepo.CreateBatch(ctx, []models.WebhookDelivery{newTestDelivery(webhook, time.Now())}))
+	require.NoError(t, repo.Delete(ctx, webhook.ID))
+
+	missing, err := repo.GetByID(ctx, webhook.ID)
+	require.NoError(t, err)
+	assert.Nil(t, missing)
+
+	deliveries, total, err := deliveryRepo.GetByWebhookID(ctx, webhook.ID, 1, 20)
+	require.NoError(t, err)
+	assert.Empty(t, deliveries)
+	assert.Zero(t, total)
+}
+
+func TestWebhookDeliveryRepository_ClaimDue(t *testing.T) {
+	ctx := context.Background()
+	db, err := testhelpers.GetTestDB(ctx)
+	require.NoError(t, err)
+
+	t.Cleanup(func() {
+		require.NoError(t, testhelpers.ResetDatabase(ctx))
+	})
+
+	repo := repositories.NewWebhookRepository(db)
+	deliveryRepo := repositories.NewWebhookDeliveryRepository(db)
+	app := createTestApp(ctx, t, "app_data_webhook_claim")
+
+	webhook := &models.Webhook{AppID: app.ID, URL: "https://example.com/hook", Secret: "secret", IsActive: true,
+		Events: []models.WebhookEventType{models.WebhookEventRecordCreated}}
+	require.NoError(t, repo.Create(ctx, webhook))
+
+	now := time.Now()
+	due := newTestDelivery(webhook, now.Add(-time.Minute))
+	future := newTestDelivery(webhook, now.Add(time.Hour))
+	done := newTestDelivery(webhook, now.Add(-time.Minute))
+	done.Status = models.WebhookDeliverySucceeded
+	require.NoError(t, deliveryRepo.CreateBatch(ctx, []models.WebhookDelivery{due, future, done}))
+
+	// 送信時刻を過ぎた未送信の配信のみを取得し、試行回数を増やして送信時刻を延ばす
+	claimed, err := deliveryRepo.ClaimDue(ctx, now, 10, time.Minute)
+	require.NoError(t, err)
+	require.Len(t, claimed, 1)
+	assert.Equal(t, 1, claimed[0].Attempts)
+	assert.Equal(t, `{"event":"record.created"}`, claimed[0].Payload)
+	assert.WithinDuration(t, now.Add(time.Minute), claimed[0].NextAttemptAt, time.Second)
+
+	// 取得済みの配信はlease中は再取得されない
+	again, err := deliveryRepo.ClaimDue(ctx, now, 10, time.Minute)
+	require.NoError(t, err)
+	assert.Empty(t, again)
+
+	statusCode := 200
+	deliveredAt := time.Now()
+	claimed[0].Status = models.WebhookDeliverySucceeded
+	claimed[0].LastStatusCode = &statusCode
+	claimed[0].DeliveredAt = &deliveredAt
+	require.NoError(t, deliveryRepo.UpdateResult(ctx, &claimed[0]))
+
+	deliveries, total, err := deliveryRepo.GetByWebhookID(ctx, webhook.ID, 1, 20)
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), total)
+	require.Len(t, deliveries, 3)
+	// 新しい順
+	assert.Equal(t, models.WebhookDeliverySucceeded, deliveries[0].Status)
+	assert.Equal(t, claimed[0].ID, deliveries[2].ID)
+	assert.Equal(t, models.WebhookDeliverySucceeded, deliveries[2].Status)
+	require.NotNil(t, deliveries[2].LastStatusCode)
+	assert.Equal(t, 200, *deliveries[2].LastStatusCode)
+}
+
+func TestWebhookDeliveryRepository_KeepsDeliveriesAfterAppDeleted(t *testing.T) {
+	ctx := context.Background()
+	db, err := testhelpers.GetTestDB(ctx)
+	require.NoError(t, err)
+
+	t.Cleanup(func() {
+		require.NoError(t, testhelpers.ResetDatabase(ctx))
+	})
+
+	repo := repositories.NewWebhookRepository(db)
+	deliveryRepo := repositories.NewWebhookDeliveryRepository(db)
+	appRepo := repositories.NewAppRepository(db)
+	app := createTestApp(ctx, t, "app_data_webhook_app_deleted")
+
+	webhook := &models.Webhook{AppID: app.ID, URL: "https://example.com/hook", Secret: "secret", IsActive: true,
+		Events: []models.WebhookEventType{models.WebhookEventAppDeleted}}
+	require.NoError(t, repo.Create(ctx, webhook))
+	require.NoError(t, deliveryRepo.CreateBatch(ctx, []models.WebhookDelivery{newTestDelivery(webhook, time.Now().Add(-time.Second))}))
+
+	// アプリの削除でWebhookは削除されるが、未送信の配信は送信できる
+	require.NoError(t, appRepo.Delete(ctx, app.ID))
+
+	list, err := repo.GetByAppID(ctx, app.ID)
+	require.NoError(t, err)
+	assert.Empty(t, list)
+
+	claimed, err := deliveryRepo.ClaimDue(ctx, time.Now(), 10, time.Minute)
+	require.NoError(t, err)
+	require.Len(t, claimed, 1)
+	assert.Nil(t, claimed[0].WebhookID)
+	assert.Equal(t, "https://example.com/hook", claimed[0].URL)
+}
+
+func newTestDelivery(webhook *models.Webhook, nextAttemptAt time.Time) models.WebhookDelivery {
+	webhookID := webhook.ID
+	return models.WebhookDelivery{
+		WebhookID:     &webhookID,
+		AppID:         webhook.AppID,
+		Event:         models.WebhookEventRecordCreated,
+		URL:           webhook.URL,
+		Payload:       `{"event":"record.created"}`,
+		Signature:     "sha256=abc",
+		Status:        models.WebhookDeliveryPending,
+		NextAttemptAt: nextAttemptAt,
+		CreatedAt:     nextAttemptAt,
+	}
+}
@@ -6,6 +6,7 @@ import (
 
 	"nocode-app/backend/internal/handlers"
 	"nocode-app/backend/internal/middleware"
+	"nocode-app/backend/internal/utils"
 )
 
 // Router HTTPルーティングを処理する構造体
@@ -25,6 +26,19 @@ type Router struct {
 	dashboardHandler       *handlers.DashboardHandler
 	dashboardWidgetHandler *handlers.DashboardWidgetHandler
 	dataSourceHandler      *handlers.DataSourceHandler
+	permissionHandler      *handlers.PermissionHandler
+	groupHandler           *handlers.GroupHandler
+	webhookHandler         *handlers.WebhookHandler
+	automationHandler      *handlers.AutomationHandler
+	attachmentHandler      *handlers.AttachmentHandler
+	fileHandler            *handlers.FileHandler
+	searchHandler          *handlers.SearchHandler
+	indexHandler           *handlers.IndexHandler
+	trashHandler           *handlers.TrashHandler
+	appPackageHandler      *handlers.AppPackageHandler
+	reportHandler          *handlers.ReportHandler
+	savedReportHandler     *handlers.SavedReportHandler
+	realtimeHandler        *handlers.RealtimeHandler
 }
 
 // NewRouter 新しいRouterを作成する
@@ -41,6 +55,19 @@ func NewRouter(
 	dashboardHandler *handlers.DashboardHandler,
 	dashboardWidgetHandler *handlers.DashboardWidgetHandler,
 	dataSourceHandler *handlers.DataSourceHandler,
+	permissionHandler *handlers.PermissionHandler,
+	groupHandler *handlers.GroupHandler,
+	webhookHandler *handlers.WebhookHandler,
+	automationHandler *handlers.AutomationHandler,
+	attachmentHandler *handlers.AttachmentHandler,
+	fileHandler *handlers.FileHandler,
+	searchHandler *handlers.SearchHandler,
+	indexHandler *handlers.IndexHandler,
+	trashHandler *handlers.TrashHandler,
+	appPackageHandler *handlers.AppPackageHandler,
+	reportHandler *handlers.ReportHandler,
+	savedReportHandler *handlers.SavedReportHandler,
+	realtimeHandler *handlers.RealtimeHandler,
 ) *Router {
 	return &Router{
 		mux:                    http.NewServeMux(),
@@ -56,6 +83,19 @@ func NewRouter(
 		dashboardHandler:       dashboardHandler,
 		dashboardWidgetHandler: dashboardWidgetHandler,
 		dataSourceHandler:      dataSourceHandler,
+		permissionHandler:      permissionHandler,
+		groupHandler:           groupHandler,
+		webhookHandler:         webhookHandler,
+		automationHandler:      automationHandler,
+		attachmentHandler:      attachmentHandler,
+		fileHandler:            fileHandler,
+		searchHandler:          searchHandler,
+		indexHandler:           indexHandler,
+		trashHandler:           trashHandler,
+		appPackageHandler:      appPackageHandler,
+		reportHandler:          reportHandler,
+		savedReportHandler:     savedReportHandler,
+		realtimeHandler:        realtimeHandler,
 	}
 }
 
@@ -90,6 +130,20 @@ func (r *Router) routeAPI(w http.ResponseWriter, req *http.Request) {
 		return
 	}
 
+	// 添付ファイルのダウンロード（認証の代わりにURLの署名を検証する）
+	if strings.HasPrefix(path, "/api/v1/files/") {
+		r.fileHandler.Download(w, req)
+		return
+	}
+
+	// アプリの変更の購読（EventSourceはヘッダーを指定できないため、クエリ文字列の購読用のトークンでも認証する）
+	if isRealtimeEventsPath(path) {
+		r.authMiddleware.AuthenticateQueryToken(utils.RealtimeTokenAudience, http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			r.routeProtected(w, req)
+		})).ServeHTTP(w, req)
+		return
+	}
+
 	// 保護されたルート（認証必須）
 	r.authMiddleware.Authenticate(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
 		r.routeProtected(w, req)
@@ -138,6 +192,12 @@ func (r *Router) routeProtected(w http.ResponseWriter, req *http.Request) {
 		return
 	}
 
+	// 全アプリ横断検索ルート
+	if path == "/api/v1/search" {
+		r.searchHandler.Search(w, req)
+		return
+	}
+
 	// アプリルート
 	if strings.HasPrefix(path, "/api/v1/apps") {
 		r.routeApps(w, req)
@@ -156,6 +216,12 @@ func (r *Router) routeProtected(w http.ResponseWriter, req *http.Request) {
 		return
 	}
 
+	// グループルート（管理者専用）
+	if strings.HasPrefix(path, "/api/v1/groups") {
+		r.routeGroups(w, req)
+		return
+	}
+
 	http.NotFound(w, req)
 }
 
@@ -211,6 +277,29 @@ func (r *Router) routeApps(w http.ResponseWriter, req *http.Request) {
 		return
 	}
 
+	// /api/v1/apps/trash 以下（ごみ箱のアプリ）
+	if len(parts) >= 4 && parts[3] == "trash" {
+		r.routeTrashedApps(w, req, parts)
+		return
+	}
+
+	// /api/v1/apps/import
+	if len(parts) == 4 && parts[3] == "import" {
+		if req.Method == http.MethodPost {
+			// 管理者専用: パッケージからアプリ作成
+			middleware.RequireAdmin(r.appPackageHandler.Import)(w, req)
+			return
+		}
+		http.Error(w, "メソッドが許可されていません", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// /api/v1/apps/templates 以下（組み込みのテンプレート）
+	if len(parts) >= 4 && parts[3] == "templates" {
+		r.routeAppTemplates(w, req, parts)
+		return
+	}
+
 	// /api/v1/apps
 	if len(parts) == 3 {
 		switch req.Method {
@@ -231,11 +320,11 @@ func (r *Router) routeApps(w http.ResponseWriter, req *http.Request) {
 		case http.MethodGet:
 			r.appHandler.Get(w, req)
 		case http.MethodPut:
-			// 管理者専用: アプリ更新
-			middleware.RequireAdmin(r.appHandler.Update)(w, req)
+			// オーナー権限が必要（サービス層で確認）
+			r.appHandler.Update(w, req)
 		case http.MethodDelete:
-			// 管理者専用: アプリ削除
-			middleware.RequireAdmin(r.appHandler.Delete)(w, req)
+			// オーナー権限が必要（サービス層で確認）
+			r.appHandler.Delete(w, req)
 		default:
 			http.Error(w, "メソッドが許可されていません", http.StatusMethodNotAllowed)
 		}
@@ -255,6 +344,24 @@ func (r *Router) routeApps(w http.ResponseWriter, req *http.Request) {
 			r.routeViews(w, req, parts)
 		case "charts":
 			r.routeCharts(w, req, parts)
+		case "reports":
+			r.routeReports(w, req, parts)
+		case "permissions":
+			r.routePermissions(w, req, parts)
+		case "webhooks":
+			r.routeWebhooks(w, req, parts)
+		case "automations":
+			r.routeAutomations(w, req, parts)
+		case "attachments":
+			r.routeAttachments(w, req, parts)
+		case "indexes":
+			r.routeIndexes(w, req, parts)
+		case "trash":
+			r.routeTrash(w, req, parts)
+		case "export":
+			r.routeAppExport(w, req, parts)
+		case "events":
+			r.routeEvents(w, req, parts)
 		default:
 			http.NotFound(w, req)
 		}
@@ -272,8 +379,8 @@ func (r *Router) routeFields(w http.ResponseWriter, req *http.Request, parts []s
 		case http.MethodGet:
 			r.fieldHandler.List(w, req)
 		case http.MethodPost:
-			// 管理者専用: フィールド作成
-			middleware.RequireAdmin(r.fieldHandler.Create)(w, req)
+			// オーナー権限が必要（サービス層で確認）
+			r.fieldHandler.Create(w, req)
 		default:
 			http.Error(w, "メソッドが許可されていません", http.StatusMethodNotAllowed)
 		}
@@ -283,8 +390,8 @@ func (r *Router) routeFields(w http.ResponseWriter, req *http.Request, parts []s
 	// /api/v1/apps/{id}/fields/order
 	if len(parts) == 6 && parts[5] == "order" {
 		if req.Method == http.MethodPut {
-			// 管理者専用: フィールド順序更新
-			middleware.RequireAdmin(r.fieldHandler.UpdateOrder)(w, req)
+			// オーナー権限が必要（サービス層で確認）
+			r.fieldHandler.UpdateOrder(w, req)
 			return
 		}
 		http.Error(w, "メソッドが許可されていません", http.StatusMethodNotAllowed)
@@ -295,17 +402,33 @@ func (r *Router) routeFields(w http.ResponseWriter, req *http.Request, parts []s
 	if len(parts) == 6 {
 		switch req.Method {
 		case http.MethodPut:
-			// 管理者専用: フィールド更新
-			middleware.RequireAdmin(r.fieldHandler.Update)(w, req)
+			// オーナー権限が必要（サービス層で確認）
+			r.fieldHandler.Update(w, req)
 		case http.MethodDelete:
-			// 管理者専用: フィールド削除
-			middleware.RequireAdmin(r.fieldHandler.Delete)(w, req)
+			// オーナー権限が必要（サービス層で確認）
+			r.fieldHandler.Delete(w, req)
 		default:
 			http.Error(w, "メソッドが許可されていません", http.StatusMethodNotAllowed)
 		}
 		return
 	}
 
+	// /api/v1/apps/{id}/fields/{fieldId}/convert
+	// /api/v1/apps/{id}/fields/{fieldId}/convert/preview
+	if parts[6] == "convert" && (len(parts) == 7 || (len(parts) == 8 && parts[7] == "preview")) {
+		if req.Method != http.MethodPost {
+			http.Error(w, "メソッドが許可されていません", http.StatusMethodNotAllowed)
+			return
+		}
+		// オーナー権限が必要（サービス層で確認）
+		if len(parts) == 8 {
+			r.fieldHandler.PreviewConversion(w, req)
+		} else {
+			r.fieldHandler.Convert(w, req)
+		}
+		return
+	}
+
 	http.NotFound(w, req)
 }
 
@@ -317,23 +440,44 @@ func (r *Router) routeRecords(w http.ResponseWriter, req *http.Request, parts []
 		case http.MethodGet:
 			r.recordHandler.List(w, req)
 		case http.MethodPost:
-			// 管理者専用: レコード作成
-			middleware.RequireAdmin(r.recordHandler.Create)(w, req)
+			// 編集権限が必要（サービス層で確認）
+			r.recordHandler.Create(w, req)
 		default:
 			http.Error(w, "メソッドが許可されていません", http.StatusMethodNotAllowed)
 		}
 		return
 	}
 
+	// /api/v1/apps/{id}/records/import
+	if len(parts) == 6 && parts[5] == "import" {
+		if req.Method == http.MethodPost {
+			// 編集権限が必要（サービス層で確認）
+			r.recordHandler.Import(w, req)
+			return
+		}
+		http.Error(w, "メソッドが許可されていません", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// /api/v1/apps/{id}/records/export
+	if len(parts) == 6 && parts[5] == "export" {
+		if req.Method == http.MethodGet {
+			r.recordHandler.Export(w, req)
+			return
+		}
+		http.Error(w, "メソッドが許可されていません", http.StatusMethodNotAllowed)
+		return
+	}
+
 	// /api/v1/apps/{id}/records/bulk
 	if len(parts) == 6 && parts[5] == "bulk" {
 		switch req.Method {
 		case http.MethodPost:
-			// 管理者専用: レコード一括作成
-			middleware.RequireAdmin(r.recordHandler.BulkCreate)(w, req)
+			// 編集権限が必要（サービス層で確認）
+			r.recordHandler.BulkCreate(w, req)
 		case http.MethodDelete:
-			// 管理者専用: レコード一括削除
-			middleware.RequireAdmin(r.recordHandler.BulkDelete)(w, req)
+			// 編集権限が必要（サービス層で確認）
+			r.recordHandler.BulkDelete(w, req)
 		default:
 			http.Error(w, "メソッドが許可されていません", http.StatusMethodNotAllowed)
 		}
@@ -346,17 +490,38 @@ func (r *Router) routeRecords(w http.ResponseWriter, req *http.Request, parts []
 		case http.MethodGet:
 			r.recordHandler.Get(w, req)
 		case http.MethodPut:
-			// 管理者専用: レコード更新
-			middleware.RequireAdmin(r.recordHandler.Update)(w, req)
+			// 編集権限が必要（サービス層で確認）
+			r.recordHandler.Update(w, req)
 		case http.MethodDelete:
-			// 管理者専用: レコード削除
-			middleware.RequireAdmin(r.recordHandler.Delete)(w, req)
+			// 編集権限が必要（サービス層で確認）
+			r.recordHandler.Delete(w, req)
 		default:
 			http.Error(w, "メソッドが許可されていません", http.StatusMethodNotAllowed)
 		}
 		return
 	}
 
+	// /api/v1/apps/{id}/records/{recordId}/history
+	if len(parts) == 7 && parts[6] == "history" {
+		if req.Method == http.MethodGet {
+			r.recordHandler.History(w, req)
+			return
+		}
+		http.Error(w, "メソッドが許可されていません", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// /api/v1/apps/{id}/records/{recordId}/history/{revisionId}/revert
+	if len(parts) == 9 && parts[6] == "history" && parts[8] == "revert" {
+		if req.Method == http.MethodPost {
+			// 編集権限が必要（サービス層で確認）
+			r.recordHandler.Revert(w, req)
+			return
+		}
+		http.Error(w, "メソッドが許可されていません", http.StatusMethodNotAllowed)
+		return
+	}
+
 	http.NotFound(w, req)
 }
 
@@ -368,8 +533,8 @@ func (r *Router) routeViews(w http.ResponseWriter, req *http.Request, parts []st
 		case http.MethodGet:
 			r.viewHandler.List(w, req)
 		case http.MethodPost:
-			// 管理者専用: ビュー作成
-			middleware.RequireAdmin(r.viewHandler.Create)(w, req)
+			// 編集権限が必要（サービス層で確認）
+			r.viewHandler.Create(w, req)
 		default:
 			http.Error(w, "メソッドが許可されていません", http.StatusMethodNotAllowed)
 		}
@@ -380,11 +545,11 @@ func (r *Router) routeViews(w http.ResponseWriter, req *http.Request, parts []st
 	if len(parts) == 6 {
 		switch req.Method {
 		case http.MethodPut:
-			// 管理者専用: ビュー更新
-			middleware.RequireAdmin(r.viewHandler.Update)(w, req)
+			// 編集権限が必要（サービス層で確認）
+			r.viewHandler.Update(w, req)
 		case http.MethodDelete:
-			// 管理者専用: ビュー削除
-			middleware.RequireAdmin(r.viewHandler.Delete)(w, req)
+			// 編集権限が必要（サービス層で確認）
+			r.viewHandler.Delete(w, req)
 		default:
 			http.Error(w, "メソッドが許可されていません", http.StatusMethodNotAllowed)
 		}
@@ -412,8 +577,8 @@ func (r *Router) routeCharts(w http.ResponseWriter, req *http.Request, parts []s
 		case http.MethodGet:
 			r.chartHandler.GetConfigs(w, req)
 		case http.MethodPost:
-			// 管理者専用: チャート設定保存
-			middleware.RequireAdmin(r.chartHandler.SaveConfig)(w, req)
+			// 編集権限が必要（サービス層で確認）
+			r.chartHandler.SaveConfig(w, req)
 		default:
 			http.Error(w, "メソッドが許可されていません", http.StatusMethodNotAllowed)
 		}
@@ -423,8 +588,340 @@ func (r *Router) routeCharts(w http.ResponseWriter, req *http.Request, parts []s
 	// /api/v1/apps/{id}/charts/config/{configId}
 	if len(parts) == 7 && parts[5] == "config" {
 		if req.Method == http.MethodDelete {
-			// 管理者専用: チャート設定削除
-			middleware.RequireAdmin(r.chartHandler.DeleteConfig)(w, req)
+			// 編集権限が必要（サービス層で確認）
+			r.chartHandler.DeleteConfig(w, req)
+			return
+		}
+		http.Error(w, "メソッドが許可されていません", http.StatusMethodNotAllowed)
+		return
+	}
+
+	http.NotFound(w, req)
+}
+
+// routeReports ピボット集計・保存したレポートのエンドポイントをルーティングする
+func (r *Router) routeReports(w http.ResponseWriter, req *http.Request, parts []string) {
+	if len(parts) >= 6 && parts[5] == "saved" {
+		r.routeSavedReports(w, req, parts)
+		return
+	}
+	if len(parts) < 6 || parts[5] != "pivot" {
+		http.NotFound(w, req)
+		return
+	}
+
+	// /api/v1/apps/{id}/reports/pivot
+	if len(parts) == 6 {
+		if req.Method == http.MethodPost {
+			r.reportHandler.Pivot(w, req)
+			return
+		}
+		http.Error(w, "メソッドが許可されていません", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// /api/v1/apps/{id}/reports/pivot/export
+	if len(parts) == 7 && parts[6] == "export" {
+		if req.Method == http.MethodPost {
+			r.reportHandler.ExportPivot(w, req)
+			return
+		}
+		http.Error(w, "メソッドが許可されていません", http.StatusMethodNotAllowed)
+		return
+	}
+
+	http.NotFound(w, req)
+}
+
+// routeSavedReports 保存したレポートエンドポイントをルーティングする
+// 閲覧・編集権限の確認はサービス層で行う
+func (r *Router) routeSavedReports(w http.ResponseWriter, req *http.Request, parts []string) {
+	// /api/v1/apps/{id}/reports/saved
+	if len(parts) == 6 {
+		switch req.Method {
+		case http.MethodGet:
+			r.savedReportHandler.List(w, req)
+		case http.MethodPost:
+			r.savedReportHandler.Create(w, req)
+		default:
+			http.Error(w, "メソッドが許可されていません", http.StatusMethodNotAllowed)
+		}
+		return
+	}
+
+	// /api/v1/apps/{id}/reports/saved/{reportId}
+	if len(parts) == 7 {
+		switch req.Method {
+		case http.MethodGet:
+			r.savedReportHandler.Get(w, req)
+		case http.MethodPut:
+			r.savedReportHandler.Update(w, req)
+		case http.MethodDelete:
+			r.savedReportHandler.Delete(w, req)
+		default:
+			http.Error(w, "メソッドが許可されていません", http.StatusMethodNotAllowed)
+		}
+		return
+	}
+
+	// /api/v1/apps/{id}/reports/saved/{reportId}/send
+	if len(parts) == 8 && parts[7] == "send" {
+		if req.Method == http.MethodPost {
+			r.savedReportHandler.Send(w, req)
+			return
+		}
+		http.Error(w, "メソッドが許可されていません", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// /api/v1/apps/{id}/reports/saved/{reportId}/deliveries
+	if len(parts) == 8 && parts[7] == "deliveries" {
+		if req.Method == http.MethodGet {
+			r.savedReportHandler.Deliveries(w, req)
+			return
+		}
+		http.Error(w, "メソッドが許可されていません", http.StatusMethodNotAllowed)
+		return
+	}
+
+	http.NotFound(w, req)
+}
+
+// routePermissions アプリ権限エンドポイントをルーティングする
+// オーナー権限の確認はサービス層で行う
+func (r *Router) routePermissions(w http.ResponseWriter, req *http.Request, parts []string) {
+	// /api/v1/apps/{id}/permissions
+	if len(parts) == 5 {
+		switch req.Method {
+		case http.MethodGet:
+			r.permissionHandler.List(w, req)
+		case http.MethodPost:
+			r.permissionHandler.Create(w, req)
+		default:
+			http.Error(w, "メソッドが許可されていません", http.StatusMethodNotAllowed)
+		}
+		return
+	}
+
+	// /api/v1/apps/{id}/permissions/{permId}
+	if len(parts) == 6 {
+		switch req.Method {
+		case http.MethodPut:
+			r.permissionHandler.Update(w, req)
+		case http.MethodDelete:
+			r.permissionHandler.Delete(w, req)
+		default:
+			http.Error(w, "メソッドが許可されていません", http.StatusMethodNotAllowed)
+		}
+		return
+	}
+
+	http.NotFound(w, req)
+}
+
+// routeWebhooks Webhookエンドポイントをルーティングする（管理者専用）
+func (r *Router) routeWebhooks(w http.ResponseWriter, req *http.Request, parts []string) {
+	// /api/v1/apps/{id}/webhooks
+	if len(parts) == 5 {
+		switch req.Method {
+		case http.MethodGet:
+			middleware.RequireAdmin(r.webhookHandler.List)(w, req)
+		case http.MethodPost:
+			middleware.RequireAdmin(r.webhookHandler.Create)(w, req)
+		default:
+			http.Error(w, "メソッドが許可されていません", http.StatusMethodNotAllowed)
+		}
+		return
+	}
+
+	// /api/v1/apps/{id}/webhooks/{webhookId}
+	if len(parts) == 6 {
+		switch req.Method {
+		case http.MethodPut:
+			middleware.RequireAdmin(r.webhookHandler.Update)(w, req)
+		case http.MethodDelete:
+			middleware.RequireAdmin(r.webhookHandler.Delete)(w, req)
+		default:
+			http.Error(w, "メソッドが許可されていません", http.StatusMethodNotAllowed)
+		}
+		return
+	}
+
+	// /api/v1/apps/{id}/webhooks/{webhookId}/deliveries
+	if len(parts) == 7 && parts[6] == "deliveries" {
+		if req.Method == http.MethodGet {
+			middleware.RequireAdmin(r.webhookHandler.Deliveries)(w, req)
+			return
+		}
+		http.Error(w, "メソッドが許可されていません", http.StatusMethodNotAllowed)
+		return
+	}
+
+	http.NotFound(w, req)
+}
+
+// routeAutomations 自動化ルールエンドポイントをルーティングする
+func (r *Router) routeAutomations(w http.ResponseWriter, req *http.Request, parts []string) {
+	// /api/v1/apps/{id}/automations
+	if len(parts) == 5 {
+		switch req.Method {
+		case http.MethodGet:
+			// オーナー権限が必要（サービス層で確認）
+			r.automationHandler.List(w, req)
+		case http.MethodPost:
+			// オーナー権限が必要（サービス層で確認）
+			r.automationHandler.Create(w, req)
+		default:
+			http.Error(w, "メソッドが許可されていません", http.StatusMethodNotAllowed)
+		}
+		return
+	}
+
+	// /api/v1/apps/{id}/automations/{ruleId}
+	if len(parts) == 6 {
+		switch req.Method {
+		case http.MethodPut:
+			// オーナー権限が必要（サービス層で確認）
+			r.automationHandler.Update(w, req)
+		case http.MethodDelete:
+			// オーナー権限が必要（サービス層で確認）
+			r.automationHandler.Delete(w, req)
+		default:
+			http.Error(w, "メソッドが許可されていません", http.StatusMethodNotAllowed)
+		}
+		return
+	}
+
+	// /api/v1/apps/{id}/automations/{ruleId}/runs
+	if len(parts) == 7 && parts[6] == "runs" {
+		if req.Method == http.MethodGet {
+			// オーナー権限が必要（サービス層で確認）
+			r.automationHandler.Runs(w, req)
+			return
+		}
+		http.Error(w, "メソッドが許可されていません", http.StatusMethodNotAllowed)
+		return
+	}
+
+	http.NotFound(w, req)
+}
+
+// routeAttachments 添付ファイルエンドポイントをルーティングする
+func (r *Router) routeAttachments(w http.ResponseWriter, req *http.Request, parts []string) {
+	// /api/v1/apps/{id}/attachments
+	if len(parts) == 5 {
+		if req.Method == http.MethodPost {
+			// 編集権限が必要（サービス層で確認）
+			r.attachmentHandler.Upload(w, req)
+			return
+		}
+		http.Error(w, "メソッドが許可されていません", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// /api/v1/apps/{id}/attachments/{attachmentId}
+	if len(parts) == 6 {
+		if req.Method == http.MethodGet {
+			// 閲覧権限が必要（サービス層で確認）
+			r.attachmentHandler.Get(w, req)
+			return
+		}
+		http.Error(w, "メソッドが許可されていません", http.StatusMethodNotAllowed)
+		return
+	}
+
+	http.NotFound(w, req)
+}
+
+// routeIndexes インデックスエンドポイントをルーティングする
+func (r *Router) routeIndexes(w http.ResponseWriter, req *http.Request, parts []string) {
+	// /api/v1/apps/{id}/indexes/suggestions
+	if len(parts) == 6 && parts[5] == "suggestions" {
+		if req.Method == http.MethodGet {
+			// オーナー権限が必要（サービス層で確認）
+			r.indexHandler.Suggestions(w, req)
+			return
+		}
+		http.Error(w, "メソッドが許可されていません", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// /api/v1/apps/{id}/indexes
+	if len(parts) == 5 {
+		switch req.Method {
+		case http.MethodGet:
+			// オーナー権限が必要（サービス層で確認）
+			r.indexHandler.List(w, req)
+		case http.MethodPost:
+			// オーナー権限が必要（サービス層で確認）
+			r.indexHandler.Create(w, req)
+		default:
+			http.Error(w, "メソッドが許可されていません", http.StatusMethodNotAllowed)
+		}
+		return
+	}
+
+	// /api/v1/apps/{id}/indexes/{indexId}
+	if len(parts) == 6 {
+		if req.Method == http.MethodDelete {
+			// オーナー権限が必要（サービス層で確認）
+			r.indexHandler.Delete(w, req)
+			return
+		}
+		http.Error(w, "メソッドが許可されていません", http.StatusMethodNotAllowed)
+		return
+	}
+
+	http.NotFound(w, req)
+}
+
+// routeGroups グループエンドポイントをルーティングする
+func (r *Router) routeGroups(w http.ResponseWriter, req *http.Request) {
+	path := req.URL.Path
+	parts := strings.Split(strings.Trim(path, "/"), "/")
+
+	// /api/v1/groups
+	if len(parts) == 3 {
+		switch req.Method {
+		case http.MethodGet:
+			middleware.RequireAdmin(r.groupHandler.List)(w, req)
+		case http.MethodPost:
+			middleware.RequireAdmin(r.groupHandler.Create)(w, req)
+		default:
+			http.Error(w, "メソッドが許可されていません", http.StatusMethodNotAllowed)
+		}
+		return
+	}
+
+	// /api/v1/groups/{id}
+	if len(parts) == 4 {
+		switch req.Method {
+		case http.MethodGet:
+			middleware.RequireAdmin(r.groupHandler.Get)(w, req)
+		case http.MethodPut:
+			middleware.RequireAdmin(r.groupHandler.Update)(w, req)
+		case http.MethodDelete:
+			middleware.RequireAdmin(r.groupHandler.Delete)(w, req)
+		default:
+			http.Error(w, "メソッドが許可されていません", http.StatusMethodNotAllowed)
+		}
+		return
+	}
+
+	// /api/v1/groups/{id}/members
+	if len(parts) == 5 && parts[4] == "members" {
+		if req.Method == http.MethodPost {
+			middleware.RequireAdmin(r.groupHandler.AddMember)(w, req)
+			return
+		}
+		http.Error(w, "メソッドが許可されていません", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// /api/v1/groups/{id}/members/{userId}
+	if len(parts) == 6 && parts[4] == "members" {
+		if req.Method == http.MethodDelete {
+			middleware.RequireAdmin(r.groupHandler.RemoveMember)(w, req)
 			return
 		}
 		http.Error(w, "メソッドが許可されていません", http.StatusMethodNotAllowed)
@@ -553,3 +1050,172 @@ func (r *Router) routeDashboardWidgets(w http.ResponseWriter, req *http.Request)
 
 	http.NotFound(w, req)
 }
+
+// routeTrashedApps ごみ箱のアプリのエンドポイントをルーティングする
+func (r *Router) routeTrashedApps(w http.ResponseWriter, req *http.Request, parts []string) {
+	// /api/v1/apps/trash
+	if len(parts) == 4 {
+		if req.Method == http.MethodGet {
+			// オーナー権限を持つアプリのみを返す（サービス層で確認）
+			r.trashHandler.ListApps(w, req)
+			return
+		}
+		http.Error(w, "メソッドが許可されていません", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// /api/v1/apps/trash/{id}
+	if len(parts) == 5 {
+		if req.Method == http.MethodDelete {
+			middleware.RequireAdmin(r.trashHandler.PurgeApp)(w, req)
+			return
+		}
+		http.Error(w, "メソッドが許可されていません", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// /api/v1/apps/trash/{id}/restore
+	if len(parts) == 6 && parts[5] == "restore" {
+		if req.Method == http.MethodPost {
+			// オーナー権限が必要（サービス層で確認）
+			r.trashHandler.RestoreApp(w, req)
+			return
+		}
+		http.Error(w, "メソッドが許可されていません", http.StatusMethodNotAllowed)
+		return
+	}
+
+	http.NotFound(w, req)
+}
+
+// routeTrash アプリのごみ箱のエンドポイントをルーティングする
+func (r *Router) routeTrash(w http.ResponseWriter, req *http.Request, parts []string) {
+	// /api/v1/apps/{id}/trash
+	if len(parts) == 5 {
+		if req.Method == http.MethodGet {
+			// 編集権限が必要（サービス層で確認）
+			r.trashHandler.List(w, req)
+			return
+		}
+		http.Error(w, "メソッドが許可されていません", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if len(parts) < 7 {
+		http.NotFound(w, req)
+		return
+	}
+
+	var restore, purge http.HandlerFunc
+	switch parts[5] {
+	case "records":
+		// 復元は編集権限が必要（サービス層で確認）
+		restore, purge = r.trashHandler.RestoreRecord, r.trashHandler.PurgeRecord
+	case "fields":
+		// 復元はオーナー権限が必要（サービス層で確認）
+		restore, purge = r.trashHandler.RestoreField, r.trashHandler.PurgeField
+	default:
+		http.NotFound(w, req)
+		return
+	}
+
+	// /api/v1/apps/{id}/trash/{records|fields}/{itemId}
+	if len(parts) == 7 {
+		if req.Method == http.MethodDelete {
+			middleware.RequireAdmin(purge)(w, req)
+			return
+		}
+		http.Error(w, "メソッドが許可されていません", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// /api/v1/apps/{id}/trash/{records|fields}/{itemId}/restore
+	if len(parts) == 8 && parts[7] == "restore" {
+		if req.Method == http.MethodPost {
+			restore(w, req)
+			return
+		}
+		http.Error(w, "メソッドが許可されていません", http.StatusMethodNotAllowed)
+		return
+	}
+
+	http.NotFound(w, req)
+}
+
+// routeAppTemplates 組み込みのテンプレートのエンドポイントをルーティングする
+func (r *Router) routeAppTemplates(w http.ResponseWriter, req *http.Request, parts []string) {
+	// /api/v1/apps/templates
+	if len(parts) == 4 {
+		if req.Method == http.MethodGet {
+			r.appPackageHandler.ListTemplates(w, req)
+			return
+		}
+		http.Error(w, "メソッドが許可されていません", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// /api/v1/apps/templates/{templateId}
+	if len(parts) == 5 {
+		switch req.Method {
+		case http.MethodGet:
+			r.appPackageHandler.GetTemplate(w, req)
+		case http.MethodPost:
+			// 管理者専用: テンプレートからアプリ作成
+			middleware.RequireAdmin(r.appPackageHandler.CreateFromTemplate)(w, req)
+		default:
+			http.Error(w, "メソッドが許可されていません", http.StatusMethodNotAllowed)
+		}
+		return
+	}
+
+	http.NotFound(w, req)
+}
+
+// routeAppExport アプリ定義のエクスポートのエンドポイントをルーティングする
+func (r *Router) routeAppExport(w http.ResponseWriter, req *http.Request, parts []string) {
+	// /api/v1/apps/{id}/export
+	if len(parts) == 5 {
+		if req.Method == http.MethodGet {
+			// オーナー権限が必要（サービス層で確認）
+			r.appPackageHandler.Export(w, req)
+			return
+		}
+		http.Error(w, "メソッドが許可されていません", http.StatusMethodNotAllowed)
+		return
+	}
+
+	http.NotFound(w, req)
+}
+
+// routeEvents アプリの変更の購読エンドポイントをルーティングする
+func (r *Router) routeEvents(w http.ResponseWriter, req *http.Request, parts []string) {
+	// /api/v1/apps/{id}/events
+	if len(parts) == 5 {
+		if req.Method == http.MethodGet {
+			// 閲覧権限が必要（サービス層で確認）
+			r.realtimeHandler.Events(w, req)
+			return
+		}
+		http.Error(w, "メソッドが許可されていません", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// /api/v1/apps/{id}/events/token
+	if len(parts) == 6 && parts[5] == "token" {
+		if req.Method == http.MethodPost {
+			// 閲覧権限が必要（サービス層で確認）
+			r.realtimeHandler.Token(w, req)
+			return
+		}
+		http.Error(w, "メソッドが許可されていません", http.StatusMethodNotAllowed)
+		return
+	}
+
+	http.NotFound(w, req)
+}
+
+// isRealtimeEventsPath アプリの変更の購読エンドポイント（/api/v1/apps/{id}/events）かどうかを判定する
+func isRealtimeEventsPath(path string) bool {
+	parts := strings.Split(strings.Trim(path, "/"), "/")
+	return len(parts) == 5 && parts[2] == "apps" && parts[4] == "events"
+}
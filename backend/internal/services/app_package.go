@@ -0,0 +1,547 @@
+package services
+
+import (
+	"context"
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+
+	"nocode-app/backend/internal/models"
+	"nocode-app/backend/internal/repositories"
+	"nocode-app/backend/internal/utils"
+)
+
+// アプリ定義パッケージ関連エラー
+var (
+	ErrUnsupportedPackageVersion = fmt.Errorf("対応していないパッケージのバージョンです（%dまで対応）", models.AppPackageVersion)
+	ErrExternalAppExport         = errors.New("外部データソースのアプリはエクスポートできません")
+	ErrAppTemplateNotFound       = errors.New("テンプレートが見つかりません")
+	ErrPackageTooManyRecords     = fmt.Errorf("パッケージに含められるレコードは%d件までです", MaxImportRows)
+)
+
+// reservedColumnNames 動的テーブルの固定のカラム名（フィールドコードには使えない）
+var reservedColumnNames = map[string]bool{
+	"id":         true,
+	"created_by": true,
+	"created_at": true,
+	"updated_at": true,
+}
+
+// configFieldKeys ビュー・グラフ設定のうち、値がフィールドコードのキー
+var configFieldKeys = map[string]bool{
+	"field":      true,
+	"group_by":   true,
+	"date_field": true,
+}
+
+// appTemplateFiles サーバーに組み込むアプリのテンプレート（ファイル名がテンプレートID）
+//
+//go:embed app_templates/*.yaml
+var appTemplateFiles embed.FS
+
+// AppPackageService アプリ定義のエクスポート・インポートとテンプレートからの作成を処理する構造体
+type AppPackageService struct {
+	appRepo      repositories.AppRepositoryInterface
+	fieldRepo    repositories.FieldRepositoryInterface
+	viewRepo     repositories.ViewRepositoryInterface
+	chartRepo    repositories.ChartRepositoryInterface
+	dynamicQuery repositories.DynamicQueryExecutorInterface
+	permissions  PermissionServiceInterface
+	apps         AppServiceInterface
+	records      RecordServiceInterface
+	templates    []models.AppTemplate
+}
+
+// NewAppPackageService 新しいAppPackageServiceを作成する
+// 組み込みのテンプレートはビルド時に埋め込まれるため、読み込めない場合はパニックする
+func NewAppPackageService(
+	appRepo repositories.AppRepositoryInterface,
+	fieldRepo repositories.FieldRepositoryInterface,
+	viewRepo repositories.ViewRepositoryInterface,
+	chartRepo repositories.ChartRepositoryInterface,
+	dynamicQuery repositories.DynamicQueryExecutorInterface,
+	permissions PermissionServiceInterface,
+	apps AppServiceInterface,
+	records RecordServiceInterface,
+) *AppPackageService {
+	templates, err := loadAppTemplates(appTemplateFiles)
+	if err != nil {
+		panic("failed to load app templates: " + err.Error())
+	}
+	return &AppPackageService{
+		appRepo:      appRepo,
+		fieldRepo:    fieldRepo,
+		viewRepo:     viewRepo,
+		chartRepo:    chartRepo,
+		dynamicQuery: dynamicQuery,
+		permissions:  permissions,
+		apps:         apps,
+		records:      records,
+		templates:    templates,
+	}
+}
+
+// loadAppTemplates テンプレートのYAMLファイルを読み込み、テンプレートID順に返す
+func loadAppTemplates(fsys fs.FS) ([]models.AppTemplate, error) {
+	files, err := fs.Glob(fsys, "app_templates/*.yaml")
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(files)
+
+	templates := make([]models.AppTemplate, 0, len(files))
+	for _, file := range files {
+		data, err := fs.ReadFile(fsys, file)
+		if err != nil {
+			return nil, err
+		}
+		var tmpl models.AppTemplate
+		if err := utils.DecodeYAML(data, &tmpl); err != nil {
+			return nil, fmt.Errorf("%s: %w", file, err)
+		}
+		tmpl.ID = strings.TrimSuffix(path.Base(file), ".yaml")
+		templates = append(templates, tmpl)
+	}
+	return templates, nil
+}
+
+// ExportApp アプリの定義をパッケージとして取得する（オーナーのみ）
+// includeRecords の場合は入力値を持つフィールドのレコードも含める（添付ファイルは含めない）
+func (s *AppPackageService) ExportApp(ctx context.Context, appID uint64, includeRecords bool) (*models.AppPackage, error) {
+	app, err := s.appRepo.GetByID(ctx, appID)
+	if err != nil {
+		return nil, err
+	}
+	if app == nil {
+		return nil, ErrAppNotFound
+	}
+	if _, err := s.permissions.CheckAppAccess(ctx, app, models.AppRoleOwner); err != nil {
+		return nil, err
+	}
+	if app.IsExternal {
+		return nil, ErrExternalAppExport
+	}
+
+	fields, err := s.fieldRepo.GetByAppID(ctx, appID)
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(fields, func(i, j int) bool {
+		return fields[i].DisplayOrder < fields[j].DisplayOrder
+	})
+	views, err := s.viewRepo.GetByAppID(ctx, appID)
+	if err != nil {
+		return nil, err
+	}
+	charts, err := s.chartRepo.GetByAppID(ctx, appID)
+	if err != nil {
+		return nil, err
+	}
+
+	pkg := &models.AppPackage{
+		Version: models.AppPackageVersion,
+		App: models.AppPackageApp{
+			Name:        app.Name,
+			Description: app.Description,
+			Icon:        app.Icon,
+		},
+		Fields: make([]models.AppPackageField, len(fields)),
+	}
+	if app.SearchConfig != nil {
+		pkg.App.SearchConfig = *app.SearchConfig
+	}
+	for i := range fields {
+		pkg.Fields[i] = models.AppPackageField{
+			FieldCode:    fields[i].FieldCode,
+			FieldName:    fields[i].FieldName,
+			FieldType:    fields[i].FieldType,
+			Options:      fields[i].Options,
+			Required:     fields[i].Required,
+			DisplayOrder: fields[i].DisplayOrder,
+		}
+	}
+	for i := range views {
+		pkg.Views = append(pkg.Views, models.AppPackageView{
+			Name:      views[i].Name,
+			ViewType:  views[i].ViewType,
+			Config:    views[i].Config,
+			IsDefault: views[i].IsDefault,
+		})
+	}
+	for i := range charts {
+		pkg.Charts = append(pkg.Charts, models.AppPackageChart{
+			Name:      charts[i].Name,
+			ChartType: charts[i].ChartType,
+			Config:    charts[i].Config,
+		})
+	}
+
+	if includeRecords {
+		inputs := packageRecordFields(fields)
+		opts := repositories.RecordQueryOptions{Sort: "id", Order: "asc"}
+		err := s.dynamicQuery.StreamRecords(ctx, app.TableName, inputs, opts, func(record *models.RecordResponse) error {
+			if len(pkg.Records) >= MaxImportRows {
+				return ErrPackageTooManyRecords
+			}
+			data := make(models.RecordData, len(inputs))
+			for i := range inputs {
+				if v, ok := record.Data[inputs[i].FieldCode]; ok && v != nil {
+					data[inputs[i].FieldCode] = v
+				}
+			}
+			pkg.Records = append(pkg.Records, data)
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return pkg, nil
+}
+
+// packageRecordFields パッケージのレコードに含めるフィールド（入力値を持ち、添付ファイルでないもの）
+func packageRecordFields(fields []models.AppField) []models.AppField {
+	inputs := make([]models.AppField, 0, len(fields))
+	for i := range fields {
+		if fields[i].IsComputed() || models.FieldType(fields[i].FieldType) == models.FieldTypeAttachment {
+			continue
+		}
+		inputs = append(inputs, fields[i])
+	}
+	return inputs
+}
+
+// ImportApp パッケージからアプリを作成する
+//
+// フィールドコードは指定に従って変更し、予約語や他のフィールドと重複する場合は末尾に _2 などを付ける。
+// 変更したフィールドコードは計算式・ルックアップ・ビューとグラフの設定・レコードにも反映する。
+// 動的テーブル名は AppService.CreateApp が新しく割り当てる。集計フィールドは集計元のアプリがこのアプリを
+// 参照している必要があるため作成しない。途中で失敗した場合は作成したアプリを削除する
+func (s *AppPackageService) ImportApp(ctx context.Context, userID uint64, req *models.ImportAppRequest) (*models.ImportAppResponse, error) {
+	pkg := &req.Package
+	if pkg.Version < 1 || pkg.Version > models.AppPackageVersion {
+		return nil, ErrUnsupportedPackageVersion
+	}
+	if !req.SkipRecords && len(pkg.Records) > MaxImportRows {
+		return nil, ErrPackageTooManyRecords
+	}
+
+	codes, renamed, err := resolvePackageFieldCodes(pkg.Fields, req.FieldCodes)
+	if err != nil {
+		return nil, err
+	}
+	renames := make(map[string]string)
+	for i := range pkg.Fields {
+		if _, ok := renames[pkg.Fields[i].FieldCode]; !ok && codes[i] != pkg.Fields[i].FieldCode {
+			renames[pkg.Fields[i].FieldCode] = codes[i]
+		}
+	}
+
+	createReq := &models.CreateAppRequest{
+		Name:         pkg.App.Name,
+		Description:  pkg.App.Description,
+		Icon:         pkg.App.Icon,
+		SearchConfig: pkg.App.SearchConfig,
+	}
+	if req.Name != "" {
+		createReq.Name = req.Name
+	}
+	resp := &models.ImportAppResponse{RenamedFields: renamed, SkippedFields: []string{}}
+	// recordCodes レコードのキー（パッケージのフィールドコード → 新しいフィールドコード）
+	recordCodes := make(map[string]string)
+	for i := range pkg.Fields {
+		field := &pkg.Fields[i]
+		if models.FieldType(field.FieldType) == models.FieldTypeRollup {
+			resp.SkippedFields = append(resp.SkippedFields, field.FieldCode)
+			continue
+		}
+
+		options := rewritePackageFieldOptions(field, renames)
+		remapped := false
+		if targetID, ok := req.ReferenceApps[field.FieldCode]; ok && models.FieldType(field.FieldType) == models.FieldTypeReference {
+			options[OptionReferenceApp] = float64(targetID)
+			remapped = true
+		}
+		createReq.Fields = append(createReq.Fields, models.CreateFieldRequest{
+			FieldCode:    codes[i],
+			FieldName:    field.FieldName,
+			FieldType:    field.FieldType,
+			Options:      options,
+			Required:     field.Required,
+			DisplayOrder: field.DisplayOrder,
+		})
+
+		// 参照先を置き換えた参照フィールドの値はレコードIDが対応しないため登録しない
+		stored := models.AppField{FieldType: field.FieldType}
+		if _, dup := recordCodes[field.FieldCode]; !dup && !remapped && !stored.IsComputed() && models.FieldType(field.FieldType) != models.FieldTypeAttachment {
+			recordCodes[field.FieldCode] = codes[i]
+		}
+	}
+	if len(createReq.Fields) == 0 {
+		return nil, fmt.Errorf("%w: 作成できるフィールドがありません", ErrInvalidFieldOptions)
+	}
+
+	app, err := s.apps.CreateApp(ctx, userID, createReq)
+	if err != nil {
+		return nil, err
+	}
+	resp.App = app
+
+	if err := s.importContents(ctx, userID, app, pkg, renames, recordCodes, req.SkipRecords, resp); err != nil {
+		// 作成途中のアプリはごみ箱を経由せずに削除する
+		s.discardApp(ctx, app)
+		return nil, err
+	}
+	return resp, nil
+}
+
+// importContents 作成したアプリにパッケージのビュー・グラフ設定・レコードを登録する
+func (s *AppPackageService) importContents(ctx context.Context, userID uint64, app *models.AppResponse, pkg *models.AppPackage, renames, recordCodes map[string]string, skipRecords bool, resp *models.ImportAppResponse) error {
+	hasDefault := false
+	for i := range pkg.Views {
+		view := &models.AppView{
+			AppID:     app.ID,
+			Name:      pkg.Views[i].Name,
+			ViewType:  pkg.Views[i].ViewType,
+			Config:    renameConfigFields(pkg.Views[i].Config, renames),
+			IsDefault: pkg.Views[i].IsDefault && !hasDefault,
+		}
+		hasDefault = hasDefault || view.IsDefault
+		if err := s.viewRepo.Create(ctx, view); err != nil {
+			return err
+		}
+		resp.ImportedViews++
+	}
+
+	for i := range pkg.Charts {
+		config := &models.ChartConfig{
+			AppID:     app.ID,
+			Name:      pkg.Charts[i].Name,
+			ChartType: pkg.Charts[i].ChartType,
+			Config:    renameConfigFields(pkg.Charts[i].Config, renames),
+			CreatedBy: userID,
+		}
+		if err := s.chartRepo.Create(ctx, config); err != nil {
+			return err
+		}
+		resp.ImportedCharts++
+	}
+
+	if skipRecords {
+		return nil
+	}
+	for start := 0; start < len(pkg.Records); start += DefaultImportChunkSize {
+		end := min(start+DefaultImportChunkSize, len(pkg.Records))
+		chunk := make([]models.RecordData, 0, end-start)
+		for _, record := range pkg.Records[start:end] {
+			data := make(models.RecordData, len(record))
+			for code, value := range record {
+				if to, ok := recordCodes[code]; ok {
+					data[to] = value
+				}
+			}
+			chunk = append(chunk, data)
+		}
+
+		created, err := s.records.BulkCreateRecords(ctx, app.ID, userID, &models.BulkCreateRecordRequest{Records: chunk})
+		if err != nil {
+			// チャンク内の番号をパッケージ全体の番号に直す
+			var vErr *RecordValidationError
+			if errors.As(err, &vErr) {
+				for j := range vErr.RecordErrors {
+					vErr.RecordErrors[j].Index += start
+				}
+			}
+			return err
+		}
+		resp.ImportedRecords += len(created)
+	}
+	return nil
+}
+
+// discardApp インポートに失敗したアプリを動的テーブルとともに削除する
+// フィールド・ビュー・グラフ設定・変更履歴はカスケードで削除される
+func (s *AppPackageService) discardApp(ctx context.Context, app *models.AppResponse) {
+	if err := s.dynamicQuery.DropTable(ctx, app.TableName); err != nil {
+		return
+	}
+	_ = s.appRepo.Delete(ctx, app.ID)
+}
+
+// resolvePackageFieldCodes パッケージの各フィールドの新しいフィールドコードと、変更したフィールドコードを返す
+func resolvePackageFieldCodes(fields []models.AppPackageField, requested map[string]string) ([]string, []models.FieldCodeRename, error) {
+	codes := make([]string, len(fields))
+	renamed := make([]models.FieldCodeRename, 0)
+	used := make(map[string]bool, len(fields))
+	for i := range fields {
+		code := fields[i].FieldCode
+		if to, ok := requested[code]; ok {
+			if !utils.IsValidFieldCode(to) {
+				return nil, nil, fmt.Errorf("%w: %s", ErrInvalidFieldCode, to)
+			}
+			code = to
+		}
+		code = uniqueFieldCode(code, used)
+		used[strings.ToLower(code)] = true
+
+		codes[i] = code
+		if code != fields[i].FieldCode {
+			renamed = append(renamed, models.FieldCodeRename{From: fields[i].FieldCode, To: code})
+		}
+	}
+	return codes, renamed, nil
+}
+
+// uniqueFieldCode 予約語や使用済みのフィールドコードと重ならないよう、必要に応じて末尾に _2 などを付ける
+// 大文字・小文字だけが異なるフィールドコードも重複として扱う
+func uniqueFieldCode(code string, used map[string]bool) string {
+	available := func(c string) bool {
+		lower := strings.ToLower(c)
+		return !used[lower] && !reservedColumnNames[lower]
+	}
+	if available(code) {
+		return code
+	}
+	for n := 2; ; n++ {
+		suffix := fmt.Sprintf("_%d", n)
+		base := code
+		if len(base)+len(suffix) > 64 {
+			base = base[:64-len(suffix)]
+		}
+		if candidate := base + suffix; available(candidate) {
+			return candidate
+		}
+	}
+}
+
+// rewritePackageFieldOptions フィールドのオプションを複製し、同じアプリのフィールドコードを変更後のものに置き換える
+func rewritePackageFieldOptions(field *models.AppPackageField, renames map[string]string) models.FieldOptions {
+	options := make(models.FieldOptions, len(field.Options))
+	for k, v := range field.Options {
+		options[k] = v
+	}
+	if len(renames) == 0 {
+		return options
+	}
+
+	switch models.FieldType(field.FieldType) {
+	case models.FieldTypeLookup:
+		if ref, ok := optionString(options, OptionReferenceField); ok {
+			if to, ok := renames[ref]; ok {
+				options[OptionReferenceField] = to
+			}
+		}
+	case models.FieldTypeFormula:
+		// 構文が正しくない計算式はそのまま渡し、アプリ作成時の検証でエラーにする
+		if expr, ok := optionString(options, OptionExpression); ok {
+			if renamedExpr, err := utils.RenameFormulaFields(expr, renames); err == nil {
+				options[OptionExpression] = renamedExpr
+			}
+		}
+	}
+	return options
+}
+
+// renameConfigFields ビュー・グラフ設定の中のフィールドコードを置き換えた複製を返す
+// 設定の形式はビューの種類ごとに異なるため、フィールドコードを値に持つキー（field など）と
+// 表示する列の一覧（columns）の値を置き換える
+func renameConfigFields(config models.ViewConfig, renames map[string]string) models.ViewConfig {
+	if config == nil {
+		return nil
+	}
+	renamed, _ := renameConfigValue(map[string]interface{}(config), renames).(map[string]interface{})
+	return renamed
+}
+
+func renameConfigValue(value interface{}, renames map[string]string) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			switch s, isString := item.(string); {
+			case isString && configFieldKeys[key]:
+				out[key] = renameCode(s, renames)
+			case key == "columns":
+				out[key] = renameColumns(item, renames)
+			default:
+				out[key] = renameConfigValue(item, renames)
+			}
+		}
+		return out
+	case []interface{}:
+		out := make([]interface{}, len(v))
+		for i := range v {
+			out[i] = renameConfigValue(v[i], renames)
+		}
+		return out
+	default:
+		return value
+	}
+}
+
+func renameColumns(value interface{}, renames map[string]string) interface{} {
+	columns, ok := value.([]interface{})
+	if !ok {
+		return renameConfigValue(value, renames)
+	}
+	out := make([]interface{}, len(columns))
+	for i := range columns {
+		if s, ok := columns[i].(string); ok {
+			out[i] = renameCode(s, renames)
+		} else {
+			out[i] = renameConfigValue(columns[i], renames)
+		}
+	}
+	return out
+}
+
+func renameCode(code string, renames map[string]string) string {
+	if to, ok := renames[code]; ok {
+		return to
+	}
+	return code
+}
+
+// GetTemplates 組み込みのテンプレートの一覧を取得する
+func (s *AppPackageService) GetTemplates(ctx context.Context) (*models.AppTemplateListResponse, error) {
+	summaries := make([]models.AppTemplateSummary, len(s.templates))
+	for i := range s.templates {
+		summaries[i] = models.AppTemplateSummary{
+			ID:          s.templates[i].ID,
+			Name:        s.templates[i].Name,
+			Description: s.templates[i].Description,
+			FieldCount:  len(s.templates[i].Package.Fields),
+			ViewCount:   len(s.templates[i].Package.Views),
+		}
+	}
+	return &models.AppTemplateListResponse{Templates: summaries}, nil
+}
+
+// GetTemplate 組み込みのテンプレートをパッケージ付きで取得する
+func (s *AppPackageService) GetTemplate(ctx context.Context, templateID string) (*models.AppTemplate, error) {
+	for i := range s.templates {
+		if s.templates[i].ID == templateID {
+			tmpl := s.templates[i]
+			return &tmpl, nil
+		}
+	}
+	return nil, ErrAppTemplateNotFound
+}
+
+// CreateAppFromTemplate 組み込みのテンプレートからアプリを作成する
+func (s *AppPackageService) CreateAppFromTemplate(ctx context.Context, userID uint64, templateID string, req *models.CreateAppFromTemplateRequest) (*models.ImportAppResponse, error) {
+	tmpl, err := s.GetTemplate(ctx, templateID)
+	if err != nil {
+		return nil, err
+	}
+	return s.ImportApp(ctx, userID, &models.ImportAppRequest{
+		Package:     tmpl.Package,
+		Name:        req.Name,
+		SkipRecords: req.SkipRecords,
+	})
+}
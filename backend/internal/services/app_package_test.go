@@ -0,0 +1,240 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"nocode-app/backend/internal/models"
+	"nocode-app/backend/internal/repositories"
+	"nocode-app/backend/internal/testhelpers/mocks"
+	"nocode-app/backend/internal/utils"
+)
+
+func TestAppPackageService_ExportApp(t *testing.T) {
+	ctx := context.Background()
+	app := &models.App{ID: 1, Name: "顧客", TableName: "app_data_1", Icon: "users", CreatedBy: 10}
+	fields := []models.AppField{
+		{ID: 2, AppID: 1, FieldCode: "total", FieldName: "合計", FieldType: "formula", Options: models.FieldOptions{"expression": "price * 2"}, DisplayOrder: 3},
+		{ID: 1, AppID: 1, FieldCode: "price", FieldName: "価格", FieldType: "number", DisplayOrder: 1},
+		{ID: 3, AppID: 1, FieldCode: "file", FieldName: "資料", FieldType: "attachment", DisplayOrder: 2},
+	}
+
+	t.Run("owner exports definition with records", func(t *testing.T) {
+		mockAppRepo := new(mocks.MockAppRepository)
+		mockFieldRepo := new(mocks.MockFieldRepository)
+		mockViewRepo := new(mocks.MockViewRepository)
+		mockChartRepo := new(mocks.MockChartRepository)
+		mockDynamicQuery := new(mocks.MockDynamicQueryExecutor)
+		mockPermissions := new(mocks.MockPermissionService)
+		service := NewAppPackageService(mockAppRepo, mockFieldRepo, mockViewRepo, mockChartRepo, mockDynamicQuery, mockPermissions, new(mocks.MockAppService), new(mocks.MockRecordService))
+
+		mockAppRepo.On("GetByID", ctx, uint64(1)).Return(app, nil)
+		mockPermissions.On("CheckAppAccess", ctx, app, models.AppRoleOwner).Return(&models.AppAccess{Role: models.AppRoleOwner}, nil)
+		mockFieldRepo.On("GetByAppID", ctx, uint64(1)).Return(fields, nil)
+		mockViewRepo.On("GetByAppID", ctx, uint64(1)).Return([]models.AppView{
+			{ID: 5, AppID: 1, Name: "一覧", ViewType: "table", Config: models.ViewConfig{"columns": []interface{}{"price"}}, IsDefault: true},
+		}, nil)
+		mockChartRepo.On("GetByAppID", ctx, uint64(1)).Return([]models.ChartConfig{
+			{ID: 6, AppID: 1, Name: "価格", ChartType: "bar", Config: models.ViewConfig{"chart_type": "bar"}},
+		}, nil)
+		mockDynamicQuery.On("StreamRecords", ctx, "app_data_1", mock.MatchedBy(func(inputs []models.AppField) bool {
+			return len(inputs) == 1 && inputs[0].FieldCode == "price"
+		}), repositories.RecordQueryOptions{Sort: "id", Order: "asc"}, mock.Anything).
+			Run(func(args mock.Arguments) {
+				fn := args.Get(4).(repositories.RecordStreamFunc)
+				_ = fn(&models.RecordResponse{ID: 1, Data: models.RecordData{"price": float64(100)}})
+				_ = fn(&models.RecordResponse{ID: 2, Data: models.RecordData{"price": nil}})
+			}).Return(nil)
+
+		pkg, err := service.ExportApp(ctx, 1, true)
+		require.NoError(t, err)
+		assert.Equal(t, models.AppPackageVersion, pkg.Version)
+		assert.Equal(t, "顧客", pkg.App.Name)
+		require.Len(t, pkg.Fields, 3)
+		assert.Equal(t, []string{"price", "file", "total"}, []string{pkg.Fields[0].FieldCode, pkg.Fields[1].FieldCode, pkg.Fields[2].FieldCode})
+		require.Len(t, pkg.Views, 1)
+		assert.True(t, pkg.Views[0].IsDefault)
+		require.Len(t, pkg.Charts, 1)
+		assert.Equal(t, []models.RecordData{{"price": float64(100)}, {}}, pkg.Records)
+	})
+
+	t.Run("non-owner is denied", func(t *testing.T) {
+		mockAppRepo := new(mocks.MockAppRepository)
+		mockPermissions := new(mocks.MockPermissionService)
+		service := NewAppPackageService(mockAppRepo, new(mocks.MockFieldRepository), new(mocks.MockViewRepository), new(mocks.MockChartRepository), new(mocks.MockDynamicQueryExecutor), mockPermissions, new(mocks.MockAppService), new(mocks.MockRecordService))
+
+		mockAppRepo.On("GetByID", ctx, uint64(1)).Return(app, nil)
+		mockPermissions.On("CheckAppAccess", ctx, app, models.AppRoleOwner).Return(nil, ErrPermissionDenied)
+
+		_, err := service.ExportApp(ctx, 1, false)
+		assert.ErrorIs(t, err, ErrPermissionDenied)
+	})
+
+	t.Run("external app cannot be exported", func(t *testing.T) {
+		mockAppRepo := new(mocks.MockAppRepository)
+		mockPermissions := new(mocks.MockPermissionService)
+		service := NewAppPackageService(mockAppRepo, new(mocks.MockFieldRepository), new(mocks.MockViewRepository), new(mocks.MockChartRepository), new(mocks.MockDynamicQueryExecutor), mockPermissions, new(mocks.MockAppService), new(mocks.MockRecordService))
+
+		external := &models.App{ID: 1, IsExternal: true}
+		mockAppRepo.On("GetByID", ctx, uint64(1)).Return(external, nil)
+		mockPermissions.On("CheckAppAccess", ctx, external, models.AppRoleOwner).Return(&models.AppAccess{Role: models.AppRoleOwner}, nil)
+
+		_, err := service.ExportApp(ctx, 1, false)
+		assert.ErrorIs(t, err, ErrExternalAppExport)
+	})
+}
+
+func TestAppPackageService_ImportApp(t *testing.T) {
+	ctx := context.Background()
+	pkg := models.AppPackage{
+		Version: 1,
+		App:     models.AppPackageApp{Name: "案件"},
+		Fields: []models.AppPackageField{
+			{FieldCode: "id", FieldName: "番号", FieldType: "text", DisplayOrder: 1},
+			{FieldCode: "amount", FieldName: "金額", FieldType: "number", DisplayOrder: 2},
+			{FieldCode: "tax", FieldName: "税込", FieldType: "formula", Options: models.FieldOptions{"expression": "amount * 1.1"}, DisplayOrder: 3},
+			{FieldCode: "customer", FieldName: "顧客", FieldType: "reference", Options: models.FieldOptions{"app_id": float64(9)}, DisplayOrder: 4},
+			{FieldCode: "total", FieldName: "集計", FieldType: "rollup", Options: models.FieldOptions{"app_id": float64(9)}, DisplayOrder: 5},
+		},
+		Views: []models.AppPackageView{
+			{Name: "一覧", ViewType: "table", IsDefault: true, Config: models.ViewConfig{
+				"columns": []interface{}{"id", "amount"},
+				"sort":    map[string]interface{}{"field": "amount", "order": "desc"},
+			}},
+			{Name: "別の一覧", ViewType: "table", IsDefault: true},
+		},
+		Charts: []models.AppPackageChart{
+			{Name: "金額", ChartType: "bar", Config: models.ViewConfig{"x_axis": map[string]interface{}{"field": "id"}}},
+		},
+		Records: []models.RecordData{
+			{"id": "A-1", "amount": float64(100), "tax": float64(110), "customer": float64(3), "total": float64(5)},
+		},
+	}
+	created := &models.AppResponse{ID: 20, Name: "案件", TableName: "app_data_20"}
+
+	t.Run("renames conflicting codes and recreates views, charts and records", func(t *testing.T) {
+		mockViewRepo := new(mocks.MockViewRepository)
+		mockChartRepo := new(mocks.MockChartRepository)
+		mockApps := new(mocks.MockAppService)
+		mockRecords := new(mocks.MockRecordService)
+		service := NewAppPackageService(new(mocks.MockAppRepository), new(mocks.MockFieldRepository), mockViewRepo, mockChartRepo, new(mocks.MockDynamicQueryExecutor), new(mocks.MockPermissionService), mockApps, mockRecords)
+
+		mockApps.On("CreateApp", ctx, uint64(10), mock.MatchedBy(func(req *models.CreateAppRequest) bool {
+			return req.Name == "案件" && len(req.Fields) == 4 &&
+				req.Fields[0].FieldCode == "id_2" &&
+				req.Fields[1].FieldCode == "price" &&
+				req.Fields[2].Options["expression"] == "price * 1.1" &&
+				req.Fields[3].Options["app_id"] == float64(4)
+		})).Return(created, nil)
+		mockViewRepo.On("Create", ctx, mock.MatchedBy(func(view *models.AppView) bool {
+			return view.AppID == 20 && view.IsDefault && view.Config["columns"].([]interface{})[1] == "price" &&
+				view.Config["sort"].(map[string]interface{})["field"] == "price"
+		})).Return(nil).Once()
+		mockViewRepo.On("Create", ctx, mock.MatchedBy(func(view *models.AppView) bool {
+			return !view.IsDefault
+		})).Return(nil).Once()
+		mockChartRepo.On("Create", ctx, mock.MatchedBy(func(config *models.ChartConfig) bool {
+			return config.CreatedBy == 10 && config.Config["x_axis"].(map[string]interface{})["field"] == "id_2"
+		})).Return(nil)
+		mockRecords.On("BulkCreateRecords", ctx, uint64(20), uint64(10), &models.BulkCreateRecordRequest{
+			Records: []models.RecordData{{"id_2": "A-1", "price": float64(100)}},
+		}).Return([]models.RecordResponse{{ID: 1}}, nil)
+
+		resp, err := service.ImportApp(ctx, 10, &models.ImportAppRequest{
+			Package:       pkg,
+			FieldCodes:    map[string]string{"amount": "price"},
+			ReferenceApps: map[string]uint64{"customer": 4},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, []models.FieldCodeRename{{From: "id", To: "id_2"}, {From: "amount", To: "price"}}, resp.RenamedFields)
+		assert.Equal(t, []string{"total"}, resp.SkippedFields)
+		assert.Equal(t, 2, resp.ImportedViews)
+		assert.Equal(t, 1, resp.ImportedCharts)
+		assert.Equal(t, 1, resp.ImportedRecords)
+		mockViewRepo.AssertExpectations(t)
+	})
+
+	t.Run("invalid requested field code", func(t *testing.T) {
+		service := NewAppPackageService(new(mocks.MockAppRepository), new(mocks.MockFieldRepository), new(mocks.MockViewRepository), new(mocks.MockChartRepository), new(mocks.MockDynamicQueryExecutor), new(mocks.MockPermissionService), new(mocks.MockAppService), new(mocks.MockRecordService))
+
+		_, err := service.ImportApp(ctx, 10, &models.ImportAppRequest{
+			Package:    pkg,
+			FieldCodes: map[string]string{"amount": "1bad"},
+		})
+		assert.ErrorIs(t, err, ErrInvalidFieldCode)
+	})
+
+	t.Run("unsupported version", func(t *testing.T) {
+		service := NewAppPackageService(new(mocks.MockAppRepository), new(mocks.MockFieldRepository), new(mocks.MockViewRepository), new(mocks.MockChartRepository), new(mocks.MockDynamicQueryExecutor), new(mocks.MockPermissionService), new(mocks.MockAppService), new(mocks.MockRecordService))
+
+		newer := pkg
+		newer.Version = models.AppPackageVersion + 1
+
+		_, err := service.ImportApp(ctx, 10, &models.ImportAppRequest{Package: newer})
+		assert.ErrorIs(t, err, ErrUnsupportedPackageVersion)
+	})
+
+	t.Run("failed record import discards the created app", func(t *testing.T) {
+		mockAppRepo := new(mocks.MockAppRepository)
+		mockViewRepo := new(mocks.MockViewRepository)
+		mockChartRepo := new(mocks.MockChartRepository)
+		mockDynamicQuery := new(mocks.MockDynamicQueryExecutor)
+		mockApps := new(mocks.MockAppService)
+		mockRecords := new(mocks.MockRecordService)
+		service := NewAppPackageService(mockAppRepo, new(mocks.MockFieldRepository), mockViewRepo, mockChartRepo, mockDynamicQuery, new(mocks.MockPermissionService), mockApps, mockRecords)
+
+		mockApps.On("CreateApp", ctx, uint64(10), mock.Anything).Return(created, nil)
+		mockViewRepo.On("Create", ctx, mock.Anything).Return(nil)
+		mockChartRepo.On("Create", ctx, mock.Anything).Return(nil)
+		mockRecords.On("BulkCreateRecords", ctx, uint64(20), uint64(10), mock.Anything).Return(nil, &RecordValidationError{
+			RecordErrors: []models.RecordFieldErrors{{Index: 0}},
+		})
+		mockDynamicQuery.On("DropTable", ctx, "app_data_20").Return(nil)
+		mockAppRepo.On("Delete", ctx, uint64(20)).Return(nil)
+
+		_, err := service.ImportApp(ctx, 10, &models.ImportAppRequest{Package: pkg})
+		var vErr *RecordValidationError
+		require.ErrorAs(t, err, &vErr)
+		mockDynamicQuery.AssertExpectations(t)
+		mockAppRepo.AssertExpectations(t)
+	})
+}
+
+func TestUniqueFieldCode(t *testing.T) {
+	used := map[string]bool{"name": true, "name_2": true}
+
+	assert.Equal(t, "title", uniqueFieldCode("title", used))
+	assert.Equal(t, "Name_3", uniqueFieldCode("Name", used))
+	assert.Equal(t, "created_at_2", uniqueFieldCode("created_at", used))
+
+	long := "a123456789012345678901234567890123456789012345678901234567890123"
+	used[long] = true
+	got := uniqueFieldCode(long, used)
+	assert.Len(t, got, 64)
+	assert.True(t, utils.IsValidFieldCode(got))
+}
+
+func TestAppPackageService_Templates(t *testing.T) {
+	service := NewAppPackageService(new(mocks.MockAppRepository), new(mocks.MockFieldRepository), new(mocks.MockViewRepository), new(mocks.MockChartRepository), new(mocks.MockDynamicQueryExecutor), new(mocks.MockPermissionService), new(mocks.MockAppService), new(mocks.MockRecordService))
+
+	validator := utils.NewValidator()
+
+	list, err := service.GetTemplates(context.Background())
+	require.NoError(t, err)
+	require.NotEmpty(t, list.Templates)
+
+	for _, summary := range list.Templates {
+		tmpl, err := service.GetTemplate(context.Background(), summary.ID)
+		require.NoError(t, err)
+		assert.NotEmpty(t, tmpl.Name, summary.ID)
+		assert.NoError(t, validator.Validate(&tmpl.Package), summary.ID)
+		assert.Equal(t, len(tmpl.Package.Fields), summary.FieldCount)
+	}
+
+	_, err = service.GetTemplate(context.Background(), "missing")
+	assert.ErrorIs(t, err, ErrAppTemplateNotFound)
+}
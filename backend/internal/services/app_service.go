@@ -8,6 +8,7 @@ import (
 
 	"github.com/google/uuid"
 
+	"nocode-app/backend/internal/middleware"
 	"nocode-app/backend/internal/models"
 	"nocode-app/backend/internal/repositories"
 )
@@ -23,6 +24,10 @@ type AppService struct {
 	fieldRepo      repositories.FieldRepositoryInterface
 	dynamicQuery   repositories.DynamicQueryExecutorInterface
 	dataSourceRepo repositories.DataSourceRepositoryInterface
+	transactor     repositories.TransactorInterface
+	permissions    PermissionServiceInterface
+	webhooks       WebhookPublisherInterface
+	attachments    AttachmentManagerInterface
 }
 
 // NewAppService 新しいAppServiceを作成する
@@ -31,12 +36,20 @@ func NewAppService(
 	fieldRepo repositories.FieldRepositoryInterface,
 	dynamicQuery repositories.DynamicQueryExecutorInterface,
 	dataSourceRepo repositories.DataSourceRepositoryInterface,
+	transactor repositories.TransactorInterface,
+	permissions PermissionServiceInterface,
+	webhooks WebhookPublisherInterface,
+	attachments AttachmentManagerInterface,
 ) *AppService {
 	return &AppService{
 		appRepo:        appRepo,
 		fieldRepo:      fieldRepo,
 		dynamicQuery:   dynamicQuery,
 		dataSourceRepo: dataSourceRepo,
+		transactor:     transactor,
+		permissions:    permissions,
+		webhooks:       webhooks,
+		attachments:    attachments,
 	}
 }
 
@@ -44,18 +57,58 @@ func NewAppService(
 func (s *AppService) CreateApp(ctx context.Context, userID uint64, req *models.CreateAppRequest) (*models.AppResponse, error) {
 	now := time.Now()
 
+	// フィールドを組み立て、参照・ルックアップ・計算フィールドのオプションを先に検証
+	fields := make([]models.AppField, len(req.Fields))
+	for i, fieldReq := range req.Fields {
+		fields[i] = models.AppField{
+			FieldCode:    fieldReq.FieldCode,
+			FieldName:    fieldReq.FieldName,
+			FieldType:    fieldReq.FieldType,
+			Options:      fieldReq.Options,
+			Required:     fieldReq.Required,
+			DisplayOrder: fieldReq.DisplayOrder,
+			CreatedAt:    now,
+			UpdatedAt:    now,
+		}
+	}
+	resolver := &referenceResolver{appRepo: s.appRepo, fieldRepo: s.fieldRepo, permissions: s.permissions}
+	targets := make(map[string]*models.App)
+	for i := range fields {
+		target, err := resolver.resolveField(ctx, &models.App{}, &fields[i], fields)
+		if err != nil {
+			return nil, err
+		}
+		if target != nil {
+			targets[fields[i].FieldCode] = target
+		}
+		if models.FieldType(fields[i].FieldType) == models.FieldTypeAttachment {
+			if err := validateAttachmentOptions(fields[i].Options); err != nil {
+				return nil, err
+			}
+		}
+	}
+	formulas, err := compileFormulas(&models.App{}, fields)
+	if err != nil {
+		return nil, err
+	}
+	searchConfig, err := normalizeSearchConfig(req.SearchConfig)
+	if err != nil {
+		return nil, err
+	}
+
 	// 一時的なユニークテーブル名を生成（NOT NULL UNIQUE制約を満たすため）
 	tempTableName := fmt.Sprintf("temp_%s", uuid.New().String())
 
 	// アプリを作成
 	app := &models.App{
-		Name:        req.Name,
-		Description: req.Description,
-		Icon:        req.Icon,
-		TableName:   tempTableName,
-		CreatedBy:   userID,
-		CreatedAt:   now,
-		UpdatedAt:   now,
+		Name:         req.Name,
+		Description:  req.Description,
+		Icon:         req.Icon,
+		TableName:    tempTableName,
+		SearchConfig: &searchConfig,
+		CreatedBy:    userID,
+		CreatedAt:    now,
+		UpdatedAt:    now,
 	}
 
 	if app.Icon == "" {
@@ -74,19 +127,8 @@ func (s *AppService) CreateApp(ctx context.Context, userID uint64, req *models.C
 	}
 
 	// フィールドを作成
-	fields := make([]models.AppField, len(req.Fields))
-	for i, fieldReq := range req.Fields {
-		fields[i] = models.AppField{
-			AppID:        app.ID,
-			FieldCode:    fieldReq.FieldCode,
-			FieldName:    fieldReq.FieldName,
-			FieldType:    fieldReq.FieldType,
-			Options:      fieldReq.Options,
-			Required:     fieldReq.Required,
-			DisplayOrder: fieldReq.DisplayOrder,
-			CreatedAt:    now,
-			UpdatedAt:    now,
-		}
+	for i := range fields {
+		fields[i].AppID = app.ID
 	}
 
 	if err := s.fieldRepo.CreateBatch(ctx, fields); err != nil {
@@ -98,6 +140,32 @@ func (s *AppService) CreateApp(ctx context.Context, userID uint64, req *models.C
 		return nil, err
 	}
 
+	// 参照フィールドに外部キー制約を設定
+	for i := range fields {
+		target, ok := targets[fields[i].FieldCode]
+		if !ok {
+			continue
+		}
+		if err := s.dynamicQuery.SetForeignKey(ctx, app.TableName, fields[i].FieldCode, target.TableName, referenceOnDelete(fields[i].Options)); err != nil {
+			return nil, err
+		}
+	}
+
+	// 計算フィールドの生成列を追加
+	for i := range fields {
+		if models.FieldType(fields[i].FieldType) != models.FieldTypeFormula {
+			continue
+		}
+		if err := s.dynamicQuery.SetFormulaColumn(ctx, app.TableName, &fields[i], formulas.expression(fields[i].FieldCode)); err != nil {
+			return nil, err
+		}
+	}
+
+	// 全文検索用カラムを追加
+	if err := s.dynamicQuery.SetSearchColumn(ctx, app.TableName, fields, searchConfig); err != nil {
+		return nil, err
+	}
+
 	// 作成したアプリをフィールド付きで取得
 	createdApp, err := s.appRepo.GetByIDWithFields(ctx, app.ID)
 	if err != nil {
@@ -120,12 +188,32 @@ func (s *AppService) GetApp(ctx context.Context, appID uint64) (*models.AppRespo
 		return nil, ErrAppNotFound
 	}
 
-	return app.ToResponse(), nil
+	access, err := s.permissions.CheckAppAccess(ctx, app, models.AppRoleViewer)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := app.ToResponse()
+	resp.MyRole = access.Role
+	return resp, nil
 }
 
-// GetApps ページネーション付きで全アプリを取得する
+// GetApps ページネーション付きで閲覧可能な全アプリを取得する
 func (s *AppService) GetApps(ctx context.Context, page, limit int) (*models.AppListResponse, error) {
-	apps, total, err := s.appRepo.GetAll(ctx, page, limit)
+	var apps []models.App
+	var total int64
+	var err error
+
+	// 一般ユーザーには権限のあるアプリのみを返す
+	claims, ok := middleware.GetUserFromContext(ctx)
+	switch {
+	case ok && claims.Role != "admin":
+		apps, total, err = s.appRepo.GetAccessibleByUserID(ctx, claims.UserID, page, limit)
+	case ok || isSystemCall(ctx):
+		apps, total, err = s.appRepo.GetAll(ctx, page, limit)
+	default:
+		return nil, ErrPermissionDenied
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -158,6 +246,10 @@ func (s *AppService) UpdateApp(ctx context.Context, appID uint64, req *models.Up
 		return nil, ErrAppNotFound
 	}
 
+	if _, err := s.permissions.CheckAppAccess(ctx, app, models.AppRoleOwner); err != nil {
+		return nil, err
+	}
+
 	// フィールドを更新
 	if req.Name != "" {
 		app.Name = req.Name
@@ -168,6 +260,11 @@ func (s *AppService) UpdateApp(ctx context.Context, appID uint64, req *models.Up
 	if req.Icon != "" {
 		app.Icon = req.Icon
 	}
+	if req.SearchConfig != "" {
+		if err := s.updateSearchConfig(ctx, app, req.SearchConfig); err != nil {
+			return nil, err
+		}
+	}
 	app.UpdatedAt = time.Now()
 
 	if err := s.appRepo.Update(ctx, app); err != nil {
@@ -177,7 +274,32 @@ func (s *AppService) UpdateApp(ctx context.Context, appID uint64, req *models.Up
 	return app.ToResponse(), nil
 }
 
-// DeleteApp アプリとその動的テーブルを削除する
+// updateSearchConfig 全文検索用カラムの形式を変更し、カラムとインデックスを作り直す
+// 全文検索用カラムがないアプリにはカラムを追加する。外部データソースのアプリには設定できない
+func (s *AppService) updateSearchConfig(ctx context.Context, app *models.App, config string) error {
+	config, err := normalizeSearchConfig(config)
+	if err != nil {
+		return err
+	}
+	if app.IsExternal {
+		return fmt.Errorf("%w: 外部データソースのアプリには設定できません", ErrInvalidSearchConfig)
+	}
+	if app.SearchConfig != nil && *app.SearchConfig == config {
+		return nil
+	}
+
+	fields, err := s.fieldRepo.GetByAppID(ctx, app.ID)
+	if err != nil {
+		return err
+	}
+	if err := s.dynamicQuery.SetSearchColumn(ctx, app.TableName, fields, config); err != nil {
+		return err
+	}
+	app.SearchConfig = &config
+	return nil
+}
+
+// DeleteApp アプリをごみ箱に移す
 func (s *AppService) DeleteApp(ctx context.Context, appID uint64) error {
 	app, err := s.appRepo.GetByID(ctx, appID)
 	if err != nil {
@@ -187,15 +309,34 @@ func (s *AppService) DeleteApp(ctx context.Context, appID uint64) error {
 		return ErrAppNotFound
 	}
 
-	// 外部データソースのアプリは動的テーブルを削除しない
-	if !app.IsExternal {
-		if err := s.dynamicQuery.DropTable(ctx, app.TableName); err != nil {
-			return err
+	if _, err := s.permissions.CheckAppAccess(ctx, app, models.AppRoleOwner); err != nil {
+		return err
+	}
+
+	// 他のアプリの参照フィールドから参照されている場合は削除できない
+	referencing, err := s.fieldRepo.GetReferencingFields(ctx, appID)
+	if err != nil {
+		return err
+	}
+	for i := range referencing {
+		if referencing[i].AppID != appID {
+			return ErrAppReferenced
 		}
 	}
 
-	// アプリを削除（カスケードでフィールドとビューも削除される）
-	return s.appRepo.Delete(ctx, appID)
+	// 動的テーブル・フィールド・ビュー・添付ファイルは復元できるよう、ごみ箱から完全に削除するまで残す
+	// ごみ箱から完全に削除するとWebhookもカスケードで削除されるため、ごみ箱に移すのと同じトランザクションで通知を登録する
+	return s.transactor.RunInTx(ctx, func(ctx context.Context) error {
+		if err := s.appRepo.Trash(ctx, appID); err != nil {
+			return err
+		}
+		return s.webhooks.Publish(ctx, models.WebhookEvent{
+			Event:      models.WebhookEventAppDeleted,
+			AppID:      appID,
+			OccurredAt: time.Now(),
+			Data:       models.WebhookAppData{ID: app.ID, Name: app.Name},
+		})
+	})
 }
 
 // CreateExternalApp 外部データソースからアプリを作成する
@@ -16,7 +16,7 @@ import (
 )
 
 func TestAppService_CreateApp(t *testing.T) {
-	ctx := context.Background()
+	ctx := systemContext()
 
 	t.Run("successful creation", func(t *testing.T) {
 		mockAppRepo := new(mocks.MockAppRepository)
@@ -45,9 +45,10 @@ func TestAppService_CreateApp(t *testing.T) {
 		mockAppRepo.On("Update", ctx, mock.AnythingOfType("*models.App")).Return(nil)
 		mockFieldRepo.On("CreateBatch", ctx, mock.AnythingOfType("[]models.AppField")).Return(nil)
 		mockDynamicQuery.On("CreateTable", ctx, "app_data_1", mock.AnythingOfType("[]models.AppField")).Return(nil)
+		mockDynamicQuery.On("SetSearchColumn", ctx, "app_data_1", mock.AnythingOfType("[]models.AppField"), "simple").Return(nil)
 		mockAppRepo.On("GetByIDWithFields", ctx, uint64(1)).Return(createdApp, nil)
 
-		service := services.NewAppService(mockAppRepo, mockFieldRepo, mockDynamicQuery, mockDataSourceRepo)
+		service := services.NewAppService(mockAppRepo, mockFieldRepo, mockDynamicQuery, mockDataSourceRepo, newTestTransactor(), newTestPermissionService(mockAppRepo), newTestWebhookPublisher(), newTestAttachmentManager())
 
 		req := &models.CreateAppRequest{
 			Name:        "Test App",
@@ -76,7 +77,7 @@ func TestAppService_CreateApp(t *testing.T) {
 
 		mockAppRepo.On("Create", ctx, mock.AnythingOfType("*models.App")).Return(errors.New("db error"))
 
-		service := services.NewAppService(mockAppRepo, mockFieldRepo, mockDynamicQuery, mockDataSourceRepo)
+		service := services.NewAppService(mockAppRepo, mockFieldRepo, mockDynamicQuery, mockDataSourceRepo, newTestTransactor(), newTestPermissionService(mockAppRepo), newTestWebhookPublisher(), newTestAttachmentManager())
 
 		req := &models.CreateAppRequest{
 			Name:        "Test App",
@@ -91,7 +92,7 @@ func TestAppService_CreateApp(t *testing.T) {
 }
 
 func TestAppService_GetApp(t *testing.T) {
-	ctx := context.Background()
+	ctx := systemContext()
 
 	t.Run("successful get", func(t *testing.T) {
 		mockAppRepo := new(mocks.MockAppRepository)
@@ -115,7 +116,7 @@ func TestAppService_GetApp(t *testing.T) {
 
 		mockAppRepo.On("GetByIDWithFields", ctx, uint64(1)).Return(app, nil)
 
-		service := services.NewAppService(mockAppRepo, mockFieldRepo, mockDynamicQuery, mockDataSourceRepo)
+		service := services.NewAppService(mockAppRepo, mockFieldRepo, mockDynamicQuery, mockDataSourceRepo, newTestTransactor(), newTestPermissionService(mockAppRepo), newTestWebhookPublisher(), newTestAttachmentManager())
 
 		resp, err := service.GetApp(ctx, 1)
 		require.NoError(t, err)
@@ -133,7 +134,7 @@ func TestAppService_GetApp(t *testing.T) {
 
 		mockAppRepo.On("GetByIDWithFields", ctx, uint64(999)).Return(nil, nil)
 
-		service := services.NewAppService(mockAppRepo, mockFieldRepo, mockDynamicQuery, mockDataSourceRepo)
+		service := services.NewAppService(mockAppRepo, mockFieldRepo, mockDynamicQuery, mockDataSourceRepo, newTestTransactor(), newTestPermissionService(mockAppRepo), newTestWebhookPublisher(), newTestAttachmentManager())
 
 		_, err := service.GetApp(ctx, 999)
 		assert.ErrorIs(t, err, services.ErrAppNotFound)
@@ -143,7 +144,7 @@ func TestAppService_GetApp(t *testing.T) {
 }
 
 func TestAppService_GetApps(t *testing.T) {
-	ctx := context.Background()
+	ctx := systemContext()
 
 	t.Run("successful get apps", func(t *testing.T) {
 		mockAppRepo := new(mocks.MockAppRepository)
@@ -166,7 +167,7 @@ func TestAppService_GetApps(t *testing.T) {
 			{ID: 3, AppID: 2, FieldCode: "f3"},
 		}, nil)
 
-		service := services.NewAppService(mockAppRepo, mockFieldRepo, mockDynamicQuery, mockDataSourceRepo)
+		service := services.NewAppService(mockAppRepo, mockFieldRepo, mockDynamicQuery, mockDataSourceRepo, newTestTransactor(), newTestPermissionService(mockAppRepo), newTestWebhookPublisher(), newTestAttachmentManager())
 
 		resp, err := service.GetApps(ctx, 1, 10)
 		require.NoError(t, err)
@@ -189,7 +190,7 @@ func TestAppService_GetApps(t *testing.T) {
 
 		mockAppRepo.On("GetAll", ctx, 1, 10).Return(apps, int64(0), nil)
 
-		service := services.NewAppService(mockAppRepo, mockFieldRepo, mockDynamicQuery, mockDataSourceRepo)
+		service := services.NewAppService(mockAppRepo, mockFieldRepo, mockDynamicQuery, mockDataSourceRepo, newTestTransactor(), newTestPermissionService(mockAppRepo), newTestWebhookPublisher(), newTestAttachmentManager())
 
 		resp, err := service.GetApps(ctx, 1, 10)
 		require.NoError(t, err)
@@ -202,7 +203,7 @@ func TestAppService_GetApps(t *testing.T) {
 }
 
 func TestAppService_UpdateApp(t *testing.T) {
-	ctx := context.Background()
+	ctx := systemContext()
 
 	t.Run("successful update", func(t *testing.T) {
 		mockAppRepo := new(mocks.MockAppRepository)
@@ -224,7 +225,7 @@ func TestAppService_UpdateApp(t *testing.T) {
 		mockAppRepo.On("GetByID", ctx, uint64(1)).Return(existingApp, nil)
 		mockAppRepo.On("Update", ctx, mock.AnythingOfType("*models.App")).Return(nil)
 
-		service := services.NewAppService(mockAppRepo, mockFieldRepo, mockDynamicQuery, mockDataSourceRepo)
+		service := services.NewAppService(mockAppRepo, mockFieldRepo, mockDynamicQuery, mockDataSourceRepo, newTestTransactor(), newTestPermissionService(mockAppRepo), newTestWebhookPublisher(), newTestAttachmentManager())
 
 		req := &models.UpdateAppRequest{
 			Name:        "Updated Name",
@@ -241,6 +242,53 @@ func TestAppService_UpdateApp(t *testing.T) {
 		mockAppRepo.AssertExpectations(t)
 	})
 
+	t.Run("changing search config rebuilds search column", func(t *testing.T) {
+		mockAppRepo := new(mocks.MockAppRepository)
+		mockFieldRepo := new(mocks.MockFieldRepository)
+		mockDynamicQuery := new(mocks.MockDynamicQueryExecutor)
+
+		simple := models.SearchConfigSimple
+		existingApp := &models.App{ID: 1, Name: "Customers", TableName: "app_data_1", SearchConfig: &simple}
+		fields := []models.AppField{{ID: 1, FieldCode: "name", FieldType: "text"}}
+
+		mockAppRepo.On("GetByID", ctx, uint64(1)).Return(existingApp, nil)
+		mockFieldRepo.On("GetByAppID", ctx, uint64(1)).Return(fields, nil)
+		mockDynamicQuery.On("SetSearchColumn", ctx, "app_data_1", fields, "trigram").Return(nil)
+		mockAppRepo.On("Update", ctx, mock.AnythingOfType("*models.App")).Return(nil)
+
+		service := services.NewAppService(mockAppRepo, mockFieldRepo, mockDynamicQuery, new(mocks.MockDataSourceRepository), newTestTransactor(), newTestPermissionService(mockAppRepo), newTestWebhookPublisher(), newTestAttachmentManager())
+
+		resp, err := service.UpdateApp(ctx, 1, &models.UpdateAppRequest{SearchConfig: "Trigram"})
+		require.NoError(t, err)
+		require.NotNil(t, resp.SearchConfig)
+		assert.Equal(t, "trigram", *resp.SearchConfig)
+		mockDynamicQuery.AssertExpectations(t)
+	})
+
+	t.Run("invalid search config", func(t *testing.T) {
+		mockAppRepo := new(mocks.MockAppRepository)
+		mockDynamicQuery := new(mocks.MockDynamicQueryExecutor)
+
+		mockAppRepo.On("GetByID", ctx, uint64(1)).Return(&models.App{ID: 1, TableName: "app_data_1"}, nil)
+
+		service := services.NewAppService(mockAppRepo, new(mocks.MockFieldRepository), mockDynamicQuery, new(mocks.MockDataSourceRepository), newTestTransactor(), newTestPermissionService(mockAppRepo), newTestWebhookPublisher(), newTestAttachmentManager())
+
+		_, err := service.UpdateApp(ctx, 1, &models.UpdateAppRequest{SearchConfig: "klingon"})
+		assert.ErrorIs(t, err, services.ErrInvalidSearchConfig)
+		mockDynamicQuery.AssertNotCalled(t, "SetSearchColumn", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+		mockAppRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+	})
+
+	t.Run("search config on external app", func(t *testing.T) {
+		mockAppRepo := new(mocks.MockAppRepository)
+		mockAppRepo.On("GetByID", ctx, uint64(1)).Return(&models.App{ID: 1, IsExternal: true}, nil)
+
+		service := services.NewAppService(mockAppRepo, new(mocks.MockFieldRepository), new(mocks.MockDynamicQueryExecutor), new(mocks.MockDataSourceRepository), newTestTransactor(), newTestPermissionService(mockAppRepo), newTestWebhookPublisher(), newTestAttachmentManager())
+
+		_, err := service.UpdateApp(ctx, 1, &models.UpdateAppRequest{SearchConfig: "simple"})
+		assert.ErrorIs(t, err, services.ErrInvalidSearchConfig)
+	})
+
 	t.Run("app not found for update", func(t *testing.T) {
 		mockAppRepo := new(mocks.MockAppRepository)
 		mockFieldRepo := new(mocks.MockFieldRepository)
@@ -249,7 +297,7 @@ func TestAppService_UpdateApp(t *testing.T) {
 
 		mockAppRepo.On("GetByID", ctx, uint64(999)).Return(nil, nil)
 
-		service := services.NewAppService(mockAppRepo, mockFieldRepo, mockDynamicQuery, mockDataSourceRepo)
+		service := services.NewAppService(mockAppRepo, mockFieldRepo, mockDynamicQuery, mockDataSourceRepo, newTestTransactor(), newTestPermissionService(mockAppRepo), newTestWebhookPublisher(), newTestAttachmentManager())
 
 		req := &models.UpdateAppRequest{}
 
@@ -261,7 +309,7 @@ func TestAppService_UpdateApp(t *testing.T) {
 }
 
 func TestAppService_DeleteApp(t *testing.T) {
-	ctx := context.Background()
+	ctx := systemContext()
 
 	t.Run("successful delete", func(t *testing.T) {
 		mockAppRepo := new(mocks.MockAppRepository)
@@ -276,16 +324,17 @@ func TestAppService_DeleteApp(t *testing.T) {
 		}
 
 		mockAppRepo.On("GetByID", ctx, uint64(1)).Return(app, nil)
-		mockDynamicQuery.On("DropTable", ctx, "app_data_1").Return(nil)
-		mockAppRepo.On("Delete", ctx, uint64(1)).Return(nil)
+		mockFieldRepo.On("GetReferencingFields", ctx, uint64(1)).Return([]models.AppField{}, nil)
+		mockAppRepo.On("Trash", ctx, uint64(1)).Return(nil)
 
-		service := services.NewAppService(mockAppRepo, mockFieldRepo, mockDynamicQuery, mockDataSourceRepo)
+		service := services.NewAppService(mockAppRepo, mockFieldRepo, mockDynamicQuery, mockDataSourceRepo, newTestTransactor(), newTestPermissionService(mockAppRepo), newTestWebhookPublisher(), newTestAttachmentManager())
 
 		err := service.DeleteApp(ctx, 1)
 		require.NoError(t, err)
 
+		// 復元できるよう動的テーブルは残す
 		mockAppRepo.AssertExpectations(t)
-		mockDynamicQuery.AssertExpectations(t)
+		mockDynamicQuery.AssertNotCalled(t, "DropTable", mock.Anything, mock.Anything)
 	})
 
 	t.Run("app not found", func(t *testing.T) {
@@ -296,7 +345,7 @@ func TestAppService_DeleteApp(t *testing.T) {
 
 		mockAppRepo.On("GetByID", ctx, uint64(999)).Return(nil, nil)
 
-		service := services.NewAppService(mockAppRepo, mockFieldRepo, mockDynamicQuery, mockDataSourceRepo)
+		service := services.NewAppService(mockAppRepo, mockFieldRepo, mockDynamicQuery, mockDataSourceRepo, newTestTransactor(), newTestPermissionService(mockAppRepo), newTestWebhookPublisher(), newTestAttachmentManager())
 
 		err := service.DeleteApp(ctx, 999)
 		assert.ErrorIs(t, err, services.ErrAppNotFound)
@@ -306,7 +355,7 @@ func TestAppService_DeleteApp(t *testing.T) {
 }
 
 func TestAppService_CreateExternalApp(t *testing.T) {
-	ctx := context.Background()
+	ctx := systemContext()
 
 	t.Run("successful creation", func(t *testing.T) {
 		mockAppRepo := new(mocks.MockAppRepository)
@@ -349,7 +398,7 @@ func TestAppService_CreateExternalApp(t *testing.T) {
 		mockFieldRepo.On("CreateBatch", ctx, mock.AnythingOfType("[]models.AppField")).Return(nil)
 		mockAppRepo.On("GetByIDWithFields", ctx, uint64(1)).Return(createdApp, nil)
 
-		service := services.NewAppService(mockAppRepo, mockFieldRepo, mockDynamicQuery, mockDataSourceRepo)
+		service := services.NewAppService(mockAppRepo, mockFieldRepo, mockDynamicQuery, mockDataSourceRepo, newTestTransactor(), newTestPermissionService(mockAppRepo), newTestWebhookPublisher(), newTestAttachmentManager())
 
 		req := &models.CreateExternalAppRequest{
 			Name:            "External App",
@@ -380,7 +429,7 @@ func TestAppService_CreateExternalApp(t *testing.T) {
 
 		mockDataSourceRepo.On("GetByID", ctx, uint64(999)).Return(nil, nil)
 
-		service := services.NewAppService(mockAppRepo, mockFieldRepo, mockDynamicQuery, mockDataSourceRepo)
+		service := services.NewAppService(mockAppRepo, mockFieldRepo, mockDynamicQuery, mockDataSourceRepo, newTestTransactor(), newTestPermissionService(mockAppRepo), newTestWebhookPublisher(), newTestAttachmentManager())
 
 		req := &models.CreateExternalAppRequest{
 			Name:            "External App",
@@ -406,7 +455,7 @@ func TestAppService_CreateExternalApp(t *testing.T) {
 
 		mockDataSourceRepo.On("GetByID", ctx, uint64(1)).Return(nil, errors.New("db error"))
 
-		service := services.NewAppService(mockAppRepo, mockFieldRepo, mockDynamicQuery, mockDataSourceRepo)
+		service := services.NewAppService(mockAppRepo, mockFieldRepo, mockDynamicQuery, mockDataSourceRepo, newTestTransactor(), newTestPermissionService(mockAppRepo), newTestWebhookPublisher(), newTestAttachmentManager())
 
 		req := &models.CreateExternalAppRequest{
 			Name:            "External App",
@@ -424,3 +473,51 @@ func TestAppService_CreateExternalApp(t *testing.T) {
 		mockDataSourceRepo.AssertExpectations(t)
 	})
 }
+
+func TestAppService_GetApps_NonAdmin(t *testing.T) {
+	ctx := userContext(2, "user")
+
+	mockAppRepo := new(mocks.MockAppRepository)
+	mockFieldRepo := new(mocks.MockFieldRepository)
+
+	apps := []models.App{{ID: 1, Name: "Shared App", CreatedAt: time.Now(), UpdatedAt: time.Now()}}
+	mockAppRepo.On("GetAccessibleByUserID", ctx, uint64(2), 1, 10).Return(apps, int64(1), nil)
+	mockFieldRepo.On("GetByAppID", ctx, uint64(1)).Return([]models.AppField{}, nil)
+
+	service := services.NewAppService(mockAppRepo, mockFieldRepo, new(mocks.MockDynamicQueryExecutor), new(mocks.MockDataSourceRepository), newTestTransactor(), newTestPermissionService(mockAppRepo), newTestWebhookPublisher(), newTestAttachmentManager())
+
+	resp, err := service.GetApps(ctx, 1, 10)
+	require.NoError(t, err)
+	assert.Len(t, resp.Apps, 1)
+
+	mockAppRepo.AssertExpectations(t)
+	mockAppRepo.AssertNotCalled(t, "GetAll", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestAppService_GetApps_WithoutUser(t *testing.T) {
+	mockAppRepo := new(mocks.MockAppRepository)
+
+	service := services.NewAppService(mockAppRepo, new(mocks.MockFieldRepository), new(mocks.MockDynamicQueryExecutor), new(mocks.MockDataSourceRepository), newTestTransactor(), newTestPermissionService(mockAppRepo), newTestWebhookPublisher(), newTestAttachmentManager())
+
+	// 認証を経ずに呼び出された場合は、システム内部の呼び出しとして扱わない
+	_, err := service.GetApps(context.Background(), 1, 10)
+	assert.ErrorIs(t, err, services.ErrPermissionDenied)
+	mockAppRepo.AssertNotCalled(t, "GetAll", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestAppService_UpdateApp_PermissionDenied(t *testing.T) {
+	ctx := userContext(2, "user")
+
+	mockAppRepo := new(mocks.MockAppRepository)
+	mockPermissions := new(mocks.MockPermissionService)
+
+	app := &models.App{ID: 1, CreatedBy: 1}
+	mockAppRepo.On("GetByID", ctx, uint64(1)).Return(app, nil)
+	mockPermissions.On("CheckAppAccess", ctx, app, models.AppRoleOwner).Return(nil, services.ErrPermissionDenied)
+
+	service := services.NewAppService(mockAppRepo, new(mocks.MockFieldRepository), new(mocks.MockDynamicQueryExecutor), new(mocks.MockDataSourceRepository), newTestTransactor(), mockPermissions, newTestWebhookPublisher(), newTestAttachmentManager())
+
+	_, err := service.UpdateApp(ctx, 1, &models.UpdateAppRequest{Name: "Renamed"})
+	assert.ErrorIs(t, err, services.ErrPermissionDenied)
+	mockAppRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+}
@@ -0,0 +1,501 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"regexp"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/gabriel-vasile/mimetype"
+
+	"nocode-app/backend/internal/models"
+	"nocode-app/backend/internal/repositories"
+	"nocode-app/backend/internal/storage"
+)
+
+// 添付ファイル関連エラー
+var (
+	ErrAttachmentNotFound       = errors.New("添付ファイルが見つかりません")
+	ErrInvalidAttachment        = errors.New("添付ファイルの指定が正しくありません")
+	ErrAttachmentTooLarge       = errors.New("ファイルサイズが上限を超えています")
+	ErrAttachmentTypeNotAllowed = errors.New("この種類のファイルは添付できません")
+)
+
+// 添付ファイルフィールドのオプションのキー
+const (
+	// OptionMaxFileSize 1ファイルの最大サイズ（バイト）
+	OptionMaxFileSize = "max_file_size"
+	// OptionAllowedTypes 添付できるファイルの種類（MIMEタイプ。"image/*" のように指定すると同じ種類を全て許可）
+	OptionAllowedTypes = "allowed_types"
+	// OptionMaxFiles 1レコードに添付できるファイルの最大数
+	OptionMaxFiles = "max_files"
+)
+
+const (
+	// MaxAttachmentFileSize 添付ファイルの最大サイズとして指定できる上限（100MB）
+	MaxAttachmentFileSize = 100 << 20
+	// defaultAttachmentFileSize 最大サイズを指定していない添付ファイルフィールドの上限（10MB）
+	defaultAttachmentFileSize = 10 << 20
+	// maxAttachmentFiles 1レコードに添付できるファイルの最大数として指定できる上限
+	maxAttachmentFiles = 100
+	// maxAttachmentFileNameLength 保存するファイル名の最大文字数
+	maxAttachmentFileNameLength = 255
+	// attachmentURLExpiry ダウンロードURLの有効期間
+	attachmentURLExpiry = 15 * time.Minute
+	// attachmentPendingTTL アップロード後にレコードへ添付されないままのファイルを削除するまでの期間
+	attachmentPendingTTL = 24 * time.Hour
+)
+
+// mediaTypePattern 添付できるファイルの種類として指定できるMIMEタイプ
+var mediaTypePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9.+-]*/([a-z0-9][a-z0-9.+-]*|\*)$`)
+
+// AttachmentService 添付ファイルのアップロード・ダウンロードとレコードへの添付を処理する構造体
+type AttachmentService struct {
+	attachmentRepo repositories.AttachmentRepositoryInterface
+	appRepo        repositories.AppRepositoryInterface
+	fieldRepo      repositories.FieldRepositoryInterface
+	dynamicQuery   repositories.DynamicQueryExecutorInterface
+	permissions    PermissionServiceInterface
+	storage        storage.FileStorage
+}
+
+// NewAttachmentService 新しいAttachmentServiceを作成する
+func NewAttachmentService(
+	attachmentRepo repositories.AttachmentRepositoryInterface,
+	appRepo repositories.AppRepositoryInterface,
+	fieldRepo repositories.FieldRepositoryInterface,
+	dynamicQuery repositories.DynamicQueryExecutorInterface,
+	permissions PermissionServiceInterface,
+	fileStorage storage.FileStorage,
+) *AttachmentService {
+	return &AttachmentService{
+		attachmentRepo: attachmentRepo,
+		appRepo:        appRepo,
+		fieldRepo:      fieldRepo,
+		dynamicQuery:   dynamicQuery,
+		permissions:    permissions,
+		storage:        fileStorage,
+	}
+}
+
+// Upload 添付ファイルフィールドにファイルをアップロードする
+// アップロードしたファイルはレコードの作成・更新時にIDを指定して添付する。
+// 添付されないまま一定期間が過ぎたファイルは削除される
+func (s *AttachmentService) Upload(ctx context.Context, appID, userID uint64, req *models.UploadAttachmentRequest) (*models.AttachmentValue, error) {
+	app, _, err := s.permissions.AuthorizeApp(ctx, appID, models.AppRoleEditor)
+	if err != nil {
+		return nil, err
+	}
+	if app.IsExternal {
+		return nil, ErrExternalAppReadOnly
+	}
+
+	fields, err := s.fieldRepo.GetByAppID(ctx, appID)
+	if err != nil {
+		return nil, err
+	}
+	field := findField(fields, req.FieldCode)
+	if field == nil || models.FieldType(field.FieldType) != models.FieldTypeAttachment {
+		return nil, fmt.Errorf("%w: 添付ファイルフィールド %q が存在しません", ErrInvalidAttachment, req.FieldCode)
+	}
+
+	if req.Size <= 0 {
+		return nil, fmt.Errorf("%w: 空のファイルはアップロードできません", ErrInvalidAttachment)
+	}
+	if maxSize := attachmentMaxFileSize(field.Options); req.Size > maxSize {
+		return nil, fmt.Errorf("%w（%s まで）", ErrAttachmentTooLarge, formatFileSize(maxSize))
+	}
+
+	// ファイルの種類は拡張子やリクエストのContent-Typeではなく内容から判定する
+	detected, err := mimetype.DetectReader(req.File)
+	if err != nil {
+		return nil, fmt.Errorf("ファイルの読み込みに失敗しました: %w", err)
+	}
+	if _, err := req.File.Seek(0, io.SeekStart); err != nil {
+		return nil, fmt.Errorf("ファイルの読み込みに失敗しました: %w", err)
+	}
+	if !attachmentTypeAllowed(field.Options, detected) {
+		return nil, fmt.Errorf("%w（%s）", ErrAttachmentTypeNotAllowed, mediaTypeOf(detected))
+	}
+
+	key, err := newAttachmentKey(appID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.storage.Put(ctx, key, req.File, req.Size, detected.String()); err != nil {
+		return nil, err
+	}
+
+	attachment := &models.Attachment{
+		AppID:       appID,
+		FieldCode:   field.FieldCode,
+		StorageKey:  key,
+		FileName:    sanitizeFileName(req.FileName),
+		ContentType: detected.String(),
+		Size:        req.Size,
+		UploadedBy:  &userID,
+		CreatedAt:   time.Now(),
+	}
+	if err := s.attachmentRepo.Create(ctx, attachment); err != nil {
+		// 登録できなかったファイルはストレージに残さない
+		_ = s.storage.Delete(ctx, key)
+		return nil, err
+	}
+
+	value := attachment.ToValue()
+	return &value, nil
+}
+
+// GetAttachmentURL 添付ファイルの情報と期限付きのダウンロードURLを取得する
+// レコードに添付されたファイルはレコードを閲覧できる場合のみ、添付前のファイルはアップロードした本人のみ取得できる
+func (s *AttachmentService) GetAttachmentURL(ctx context.Context, appID, attachmentID uint64) (*models.AttachmentURLResponse, error) {
+	app, access, err := s.permissions.AuthorizeApp(ctx, appID, models.AppRoleViewer)
+	if err != nil {
+		return nil, err
+	}
+
+	attachment, err := s.attachmentRepo.GetByID(ctx, attachmentID)
+	if err != nil {
+		return nil, err
+	}
+	if attachment == nil || attachment.AppID != appID || attachment.OrphanedAt != nil {
+		return nil, ErrAttachmentNotFound
+	}
+
+	if attachment.RecordID == nil {
+		if attachment.UploadedBy == nil || *attachment.UploadedBy != access.UserID {
+			return nil, ErrAttachmentNotFound
+		}
+	} else if access.OwnRecordsOnly {
+		record, err := s.dynamicQuery.GetRecordByID(ctx, app.TableName, nil, *attachment.RecordID)
+		if err != nil {
+			return nil, err
+		}
+		if record == nil || !access.CanAccessRecord(record.CreatedBy) {
+			return nil, ErrAttachmentNotFound
+		}
+	}
+
+	expiresAt := time.Now().Add(attachmentURLExpiry)
+	url, err := s.storage.SignedURL(ctx, attachment.StorageKey, storage.URLOptions{
+		FileName:    attachment.FileName,
+		ContentType: attachment.ContentType,
+		Expires:     attachmentURLExpiry,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.AttachmentURLResponse{
+		AttachmentValue: attachment.ToValue(),
+		URL:             url,
+		ExpiresAt:       expiresAt,
+	}, nil
+}
+
+// ResolveValues 添付ファイルフィールドに指定されたIDを確認し、レコードに保存する添付ファイルの情報に置き換える
+// recordID は更新するレコードのID（作成時は0）、userID は操作者のID。行の添字ごとの入力エラーを返す（エラーがなければnil）。
+// 添付できるのは、同じフィールドにアップロードした添付前の自分のファイルと、更新するレコードに添付済み（削除待ちを含む）のファイル
+func (s *AttachmentService) ResolveValues(ctx context.Context, appID, recordID, userID uint64, fields []models.AppField, rows []models.RecordData) (map[int]models.FieldErrors, error) {
+	var ids []uint64
+	for i := range fields {
+		if models.FieldType(fields[i].FieldType) != models.FieldTypeAttachment {
+			continue
+		}
+		for _, row := range rows {
+			if list, ok := attachmentIDs(row[fields[i].FieldCode]); ok {
+				ids = append(ids, list...)
+			}
+		}
+	}
+
+	byID := make(map[uint64]*models.Attachment, len(ids))
+	if len(ids) > 0 {
+		attachments, err := s.attachmentRepo.GetByIDs(ctx, ids)
+		if err != nil {
+			return nil, err
+		}
+		for i := range attachments {
+			byID[attachments[i].ID] = &attachments[i]
+		}
+	}
+
+	var errs map[int]models.FieldErrors
+	// 同じファイルを複数のレコードに添付することはできない
+	claimed := make(map[uint64]bool, len(ids))
+	for i := range fields {
+		code := fields[i].FieldCode
+		if models.FieldType(fields[i].FieldType) != models.FieldTypeAttachment {
+			continue
+		}
+		for j, row := range rows {
+			value, ok := row[code]
+			if !ok {
+				continue
+			}
+			list, _ := attachmentIDs(value)
+			values := make([]models.AttachmentValue, 0, len(list))
+			for _, id := range list {
+				attachment := byID[id]
+				if attachment == nil || attachment.AppID != appID || attachment.FieldCode != code ||
+					!attachmentUsable(attachment, recordID, userID) || claimed[id] {
+					if errs == nil {
+						errs = make(map[int]models.FieldErrors)
+					}
+					if errs[j] == nil {
+						errs[j] = make(models.FieldErrors)
+					}
+					errs[j][code] = fmt.Sprintf("添付ファイル（ID: %d）が見つかりません", id)
+					break
+				}
+				claimed[id] = true
+				values = append(values, attachment.ToValue())
+			}
+			if len(values) == 0 {
+				row[code] = nil
+				continue
+			}
+			row[code] = values
+		}
+	}
+	return errs, nil
+}
+
+// AttachRecord レコードに保存した添付ファイルフィールドの値に合わせて添付ファイルを更新する
+// 値から外れたファイルは削除待ちにする
+func (s *AttachmentService) AttachRecord(ctx context.Context, appID, recordID uint64, fields []models.AppField, data models.RecordData) error {
+	now := time.Now()
+	for i := range fields {
+		code := fields[i].FieldCode
+		if models.FieldType(fields[i].FieldType) != models.FieldTypeAttachment {
+			continue
+		}
+		value, ok := data[code]
+		if !ok {
+			continue
+		}
+		ids, _ := attachmentIDs(value)
+		if err := s.attachmentRepo.SyncRecord(ctx, appID, recordID, code, ids, now); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ReleaseRecords 削除したレコードの添付ファイルを削除待ちにする
+func (s *AttachmentService) ReleaseRecords(ctx context.Context, appID uint64, recordIDs []uint64) error {
+	return s.attachmentRepo.OrphanByRecords(ctx, appID, recordIDs, time.Now())
+}
+
+// ReleaseField 削除したフィールドの添付ファイルを削除待ちにする
+func (s *AttachmentService) ReleaseField(ctx context.Context, appID uint64, fieldCode string) error {
+	return s.attachmentRepo.OrphanByField(ctx, appID, fieldCode, time.Now())
+}
+
+// ReleaseApp 削除したアプリの添付ファイルを削除待ちにする
+func (s *AttachmentService) ReleaseApp(ctx context.Context, appID uint64) error {
+	return s.attachmentRepo.OrphanByApp(ctx, appID, time.Now())
+}
+
+// checkRecordAttachments 1件のレコードの添付ファイルを確認し、保存する値に置き換える
+func (s *RecordService) checkRecordAttachments(ctx context.Context, appID, recordID, userID uint64, fields []models.AppField, data models.RecordData) error {
+	errs, err := s.attachments.ResolveValues(ctx, appID, recordID, userID, fields, []models.RecordData{data})
+	if err != nil {
+		return err
+	}
+	if errs != nil {
+		return &RecordValidationError{Errors: errs[0]}
+	}
+	return nil
+}
+
+// attachmentUsable 添付ファイルを指定したレコードに添付できるかどうかを確認する
+func attachmentUsable(attachment *models.Attachment, recordID, userID uint64) bool {
+	if attachment.RecordID == nil {
+		return attachment.OrphanedAt == nil && attachment.UploadedBy != nil && *attachment.UploadedBy == userID
+	}
+	return recordID != 0 && *attachment.RecordID == recordID
+}
+
+// attachmentIDs 添付ファイルフィールドの値から添付ファイルのIDを重複を除いて取り出す
+// IDの配列と、保存済みの値（id を含むオブジェクトの配列）のどちらも受け付ける
+func attachmentIDs(value interface{}) ([]uint64, bool) {
+	var items []interface{}
+	switch val := value.(type) {
+	case nil:
+		return []uint64{}, true
+	case []uint64:
+		return val, true
+	case []models.AttachmentValue:
+		ids := make([]uint64, len(val))
+		for i := range val {
+			ids[i] = val[i].ID
+		}
+		return ids, true
+	case []interface{}:
+		items = val
+	default:
+		return nil, false
+	}
+
+	ids := make([]uint64, 0, len(items))
+	seen := make(map[uint64]bool, len(items))
+	for _, item := range items {
+		if obj, ok := item.(map[string]interface{}); ok {
+			item = obj["id"]
+		}
+		id, ok := referenceID(item)
+		if !ok {
+			return nil, false
+		}
+		if !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+	return ids, true
+}
+
+// attachmentFileNames 添付ファイルフィールドの値からファイル名を取り出す（エクスポート用）
+func attachmentFileNames(value interface{}) interface{} {
+	items, ok := value.([]interface{})
+	if !ok {
+		return value
+	}
+	names := make([]interface{}, 0, len(items))
+	for _, item := range items {
+		if obj, ok := item.(map[string]interface{}); ok {
+			names = append(names, obj["file_name"])
+		}
+	}
+	return names
+}
+
+// validateAttachmentOptions 添付ファイルフィールドのオプションを検証する
+func validateAttachmentOptions(options models.FieldOptions) error {
+	if _, ok := options[OptionMaxFileSize]; ok {
+		size, ok := optionNumber(options, OptionMaxFileSize)
+		if !ok || size < 1 || size > MaxAttachmentFileSize || size != float64(int64(size)) {
+			return fmt.Errorf("%w: %s は1〜%dの整数（バイト）で指定してください", ErrInvalidFieldOptions, OptionMaxFileSize, MaxAttachmentFileSize)
+		}
+	}
+	if _, ok := options[OptionMaxFiles]; ok {
+		n, ok := optionNumber(options, OptionMaxFiles)
+		if !ok || n < 1 || n > maxAttachmentFiles || n != float64(int64(n)) {
+			return fmt.Errorf("%w: %s は1〜%dの整数で指定してください", ErrInvalidFieldOptions, OptionMaxFiles, maxAttachmentFiles)
+		}
+	}
+	if raw, ok := options[OptionAllowedTypes]; ok {
+		types, ok := optionStringList(raw)
+		if !ok || len(types) == 0 {
+			return fmt.Errorf("%w: %s はMIMEタイプの配列で指定してください", ErrInvalidFieldOptions, OptionAllowedTypes)
+		}
+		for _, t := range types {
+			if !mediaTypePattern.MatchString(strings.ToLower(strings.TrimSpace(t))) {
+				return fmt.Errorf("%w: %q はMIMEタイプ（例: application/pdf、image/*）ではありません", ErrInvalidFieldOptions, t)
+			}
+		}
+	}
+	return nil
+}
+
+// attachmentMaxFileSize 添付ファイルフィールドの1ファイルの最大サイズを返す
+func attachmentMaxFileSize(options models.FieldOptions) int64 {
+	if size, ok := optionNumber(options, OptionMaxFileSize); ok && size >= 1 && size <= MaxAttachmentFileSize {
+		return int64(size)
+	}
+	return defaultAttachmentFileSize
+}
+
+// attachmentTypeAllowed 判定したファイルの種類が添付できる種類に含まれるか確認する
+// 種類の上位の分類（docx に対する application/zip など）が含まれる場合も許可する
+func attachmentTypeAllowed(options models.FieldOptions, detected *mimetype.MIME) bool {
+	allowed, ok := optionStringList(options[OptionAllowedTypes])
+	if !ok || len(allowed) == 0 {
+		return true
+	}
+	for m := detected; m != nil; m = m.Parent() {
+		mediaType := mediaTypeOf(m)
+		for _, pattern := range allowed {
+			pattern = strings.ToLower(strings.TrimSpace(pattern))
+			if prefix, ok := strings.CutSuffix(pattern, "*"); ok && strings.HasPrefix(mediaType, prefix) {
+				return true
+			}
+			if m.Is(pattern) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// mediaTypeOf パラメーター（charset など）を除いたMIMEタイプを返す
+func mediaTypeOf(m *mimetype.MIME) string {
+	mediaType, _, _ := strings.Cut(m.String(), ";")
+	return strings.ToLower(strings.TrimSpace(mediaType))
+}
+
+// optionStringList オプションの値から文字列の配列を取得する
+func optionStringList(value interface{}) ([]string, bool) {
+	switch val := value.(type) {
+	case []string:
+		return val, true
+	case []interface{}:
+		list := make([]string, 0, len(val))
+		for _, item := range val {
+			s, ok := item.(string)
+			if !ok {
+				return nil, false
+			}
+			list = append(list, s)
+		}
+		return list, true
+	}
+	return nil, false
+}
+
+// newAttachmentKey 添付ファイルを保存するストレージのキーを生成する
+func newAttachmentKey(appID uint64) (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("ファイルのキーの生成に失敗しました: %w", err)
+	}
+	return fmt.Sprintf("apps/%d/%s", appID, hex.EncodeToString(b)), nil
+}
+
+// sanitizeFileName アップロードされたファイル名からディレクトリと制御文字を取り除く
+func sanitizeFileName(name string) string {
+	name = path.Base(strings.ReplaceAll(name, `\`, "/"))
+	name = strings.TrimSpace(strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) {
+			return -1
+		}
+		return r
+	}, name))
+	if name == "" || name == "." || name == "/" {
+		return "file"
+	}
+	if utf8.RuneCountInString(name) > maxAttachmentFileNameLength {
+		name = string([]rune(name)[:maxAttachmentFileNameLength])
+	}
+	return name
+}
+
+// formatFileSize ファイルサイズを読みやすい単位で表す
+func formatFileSize(size int64) string {
+	switch {
+	case size >= 1<<20 && size%(1<<20) == 0:
+		return fmt.Sprintf("%dMB", size>>20)
+	case size >= 1<<10 && size%(1<<10) == 0:
+		return fmt.Sprintf("%dKB", size>>10)
+	}
+	return fmt.Sprintf("%dバイト", size)
+}
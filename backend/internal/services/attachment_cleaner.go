@@ -0,0 +1,56 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"nocode-app/backend/internal/repositories"
+	"nocode-app/backend/internal/storage"
+)
+
+// attachmentCleanupBatchSize 1回の処理で削除する添付ファイルの最大件数
+const attachmentCleanupBatchSize = 50
+
+// AttachmentCleaner 不要になった添付ファイルをストレージから削除する構造体
+type AttachmentCleaner struct {
+	attachmentRepo repositories.AttachmentRepositoryInterface
+	storage        storage.FileStorage
+}
+
+// NewAttachmentCleaner 新しいAttachmentCleanerを作成する
+func NewAttachmentCleaner(attachmentRepo repositories.AttachmentRepositoryInterface, fileStorage storage.FileStorage) *AttachmentCleaner {
+	return &AttachmentCleaner{
+		attachmentRepo: attachmentRepo,
+		storage:        fileStorage,
+	}
+}
+
+// ProcessOrphans 削除待ちの添付ファイルと、アップロード後に添付されないまま期限を過ぎた添付ファイルを削除し、削除した件数を返す
+// 登録を先に削除するため、ストレージからの削除に失敗してもレコードから参照できないファイルが残るだけになる
+func (c *AttachmentCleaner) ProcessOrphans(ctx context.Context, now time.Time) (int, error) {
+	pendingBefore := now.Add(-attachmentPendingTTL)
+	attachments, err := c.attachmentRepo.GetPurgeable(ctx, pendingBefore, attachmentCleanupBatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	var errs []error
+	n := 0
+	for i := range attachments {
+		// 取得後にレコードへ添付し直されたファイルは削除しない
+		deleted, err := c.attachmentRepo.DeletePurgeable(ctx, attachments[i].ID, pendingBefore)
+		if err != nil {
+			return n, err
+		}
+		if !deleted {
+			continue
+		}
+		if err := c.storage.Delete(ctx, attachments[i].StorageKey); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", attachments[i].StorageKey, err))
+		}
+		n++
+	}
+	return n, errors.Join(errs...)
+}
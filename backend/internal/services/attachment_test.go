@@ -0,0 +1,442 @@
+package services_test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"nocode-app/backend/internal/models"
+	"nocode-app/backend/internal/services"
+	"nocode-app/backend/internal/storage"
+	"nocode-app/backend/internal/testhelpers/mocks"
+)
+
+// pngHeader PNGとして判定される最小限のファイル内容
+var pngHeader = []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR\x00\x00\x00\x01\x00\x00\x00\x01\x08\x02\x00\x00\x00")
+
+func attachmentField(options models.FieldOptions) models.AppField {
+	return models.AppField{ID: 3, AppID: 1, FieldCode: "files", FieldName: "添付", FieldType: string(models.FieldTypeAttachment), Options: options}
+}
+
+func uploadRequest(name string, content []byte) *models.UploadAttachmentRequest {
+	return &models.UploadAttachmentRequest{
+		FieldCode: "files",
+		FileName:  name,
+		Size:      int64(len(content)),
+		File:      bytes.NewReader(content),
+	}
+}
+
+func TestAttachmentService_Upload(t *testing.T) {
+	ctx := userContext(5, "user")
+	app := &models.App{ID: 1, TableName: "app_data_1", CreatedBy: 5}
+
+	t.Run("stores file with detected type", func(t *testing.T) {
+		mockAttachmentRepo := new(mocks.MockAttachmentRepository)
+		mockAppRepo := new(mocks.MockAppRepository)
+		mockFieldRepo := new(mocks.MockFieldRepository)
+		mockStorage := new(mocks.MockFileStorage)
+
+		mockAppRepo.On("GetByID", ctx, uint64(1)).Return(app, nil)
+		mockFieldRepo.On("GetByAppID", ctx, uint64(1)).Return([]models.AppField{
+			attachmentField(models.FieldOptions{services.OptionAllowedTypes: []interface{}{"image/*", "application/pdf"}}),
+		}, nil)
+
+		var stored []byte
+		var key string
+		mockStorage.On("Put", ctx, mock.AnythingOfType("string"), mock.Anything, int64(len(pngHeader)), "image/png").Return(nil).Run(func(args mock.Arguments) {
+			key = args.String(1)
+			stored, _ = io.ReadAll(args.Get(2).(io.Reader))
+		})
+		var created *models.Attachment
+		mockAttachmentRepo.On("Create", ctx, mock.AnythingOfType("*models.Attachment")).Return(nil).Run(func(args mock.Arguments) {
+			created = args.Get(1).(*models.Attachment)
+			created.ID = 9
+		})
+
+		service := services.NewAttachmentService(mockAttachmentRepo, mockAppRepo, mockFieldRepo, new(mocks.MockDynamicQueryExecutor), newTestPermissionService(mockAppRepo), mockStorage)
+
+		// 拡張子ではなく内容で種類を判定し、ディレクトリ部分は保存しない
+		resp, err := service.Upload(ctx, 1, 5, uploadRequest(`C:\Users\me\写真.txt`, pngHeader))
+		require.NoError(t, err)
+		assert.Equal(t, models.AttachmentValue{ID: 9, FileName: "写真.txt", ContentType: "image/png", Size: int64(len(pngHeader))}, *resp)
+
+		// 判定のために読んだ分も含めて全体が保存される
+		assert.Equal(t, pngHeader, stored)
+		assert.True(t, strings.HasPrefix(key, "apps/1/"), key)
+		require.NotNil(t, created)
+		assert.Equal(t, key, created.StorageKey)
+		assert.Nil(t, created.RecordID)
+		require.NotNil(t, created.UploadedBy)
+		assert.Equal(t, uint64(5), *created.UploadedBy)
+	})
+
+	t.Run("type not allowed", func(t *testing.T) {
+		mockAppRepo := new(mocks.MockAppRepository)
+		mockFieldRepo := new(mocks.MockFieldRepository)
+		mockStorage := new(mocks.MockFileStorage)
+
+		mockAppRepo.On("GetByID", ctx, uint64(1)).Return(app, nil)
+		mockFieldRepo.On("GetByAppID", ctx, uint64(1)).Return([]models.AppField{
+			attachmentField(models.FieldOptions{services.OptionAllowedTypes: []interface{}{"image/*"}}),
+		}, nil)
+
+		service := services.NewAttachmentService(new(mocks.MockAttachmentRepository), mockAppRepo, mockFieldRepo, new(mocks.MockDynamicQueryExecutor), newTestPermissionService(mockAppRepo), mockStorage)
+
+		// 画像の拡張子でも内容がHTMLであれば添付できない
+		_, err := service.Upload(ctx, 1, 5, uploadRequest("image.png", []byte("<html><script>alert(1)</script></html>")))
+		assert.ErrorIs(t, err, services.ErrAttachmentTypeNotAllowed)
+		mockStorage.AssertNotCalled(t, "Put", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("file too large", func(t *testing.T) {
+		mockAppRepo := new(mocks.MockAppRepository)
+		mockFieldRepo := new(mocks.MockFieldRepository)
+
+		mockAppRepo.On("GetByID", ctx, uint64(1)).Return(app, nil)
+		mockFieldRepo.On("GetByAppID", ctx, uint64(1)).Return([]models.AppField{
+			attachmentField(models.FieldOptions{services.OptionMaxFileSize: float64(10)}),
+		}, nil)
+
+		service := services.NewAttachmentService(new(mocks.MockAttachmentRepository), mockAppRepo, mockFieldRepo, new(mocks.MockDynamicQueryExecutor), newTestPermissionService(mockAppRepo), new(mocks.MockFileStorage))
+
+		_, err := service.Upload(ctx, 1, 5, uploadRequest("a.png", pngHeader))
+		assert.ErrorIs(t, err, services.ErrAttachmentTooLarge)
+	})
+
+	t.Run("not an attachment field", func(t *testing.T) {
+		mockAppRepo := new(mocks.MockAppRepository)
+		mockFieldRepo := new(mocks.MockFieldRepository)
+
+		mockAppRepo.On("GetByID", ctx, uint64(1)).Return(app, nil)
+		mockFieldRepo.On("GetByAppID", ctx, uint64(1)).Return([]models.AppField{
+			{ID: 2, AppID: 1, FieldCode: "files", FieldType: string(models.FieldTypeText)},
+		}, nil)
+
+		service := services.NewAttachmentService(new(mocks.MockAttachmentRepository), mockAppRepo, mockFieldRepo, new(mocks.MockDynamicQueryExecutor), newTestPermissionService(mockAppRepo), new(mocks.MockFileStorage))
+
+		_, err := service.Upload(ctx, 1, 5, uploadRequest("a.png", pngHeader))
+		assert.ErrorIs(t, err, services.ErrInvalidAttachment)
+	})
+
+	t.Run("removes stored file when registration fails", func(t *testing.T) {
+		mockAttachmentRepo := new(mocks.MockAttachmentRepository)
+		mockAppRepo := new(mocks.MockAppRepository)
+		mockFieldRepo := new(mocks.MockFieldRepository)
+		mockStorage := new(mocks.MockFileStorage)
+
+		mockAppRepo.On("GetByID", ctx, uint64(1)).Return(app, nil)
+		mockFieldRepo.On("GetByAppID", ctx, uint64(1)).Return([]models.AppField{attachmentField(nil)}, nil)
+		mockStorage.On("Put", ctx, mock.AnythingOfType("string"), mock.Anything, mock.Anything, mock.Anything).Return(nil)
+		mockStorage.On("Delete", ctx, mock.AnythingOfType("string")).Return(nil)
+		mockAttachmentRepo.On("Create", ctx, mock.Anything).Return(errors.New("db error"))
+
+		service := services.NewAttachmentService(mockAttachmentRepo, mockAppRepo, mockFieldRepo, new(mocks.MockDynamicQueryExecutor), newTestPermissionService(mockAppRepo), mockStorage)
+
+		_, err := service.Upload(ctx, 1, 5, uploadRequest("a.png", pngHeader))
+		require.Error(t, err)
+		mockStorage.AssertCalled(t, "Delete", ctx, mock.AnythingOfType("string"))
+	})
+
+	t.Run("external app", func(t *testing.T) {
+		mockAppRepo := new(mocks.MockAppRepository)
+		mockAppRepo.On("GetByID", ctx, uint64(1)).Return(&models.App{ID: 1, CreatedBy: 5, IsExternal: true}, nil)
+
+		service := services.NewAttachmentService(new(mocks.MockAttachmentRepository), mockAppRepo, new(mocks.MockFieldRepository), new(mocks.MockDynamicQueryExecutor), newTestPermissionService(mockAppRepo), new(mocks.MockFileStorage))
+
+		_, err := service.Upload(ctx, 1, 5, uploadRequest("a.png", pngHeader))
+		assert.ErrorIs(t, err, services.ErrExternalAppReadOnly)
+	})
+}
+
+func TestAttachmentService_GetAttachmentURL(t *testing.T) {
+	ctx := userContext(5, "user")
+	app := &models.App{ID: 1, TableName: "app_data_1", CreatedBy: 5}
+	recordID := uint64(20)
+	uploader := uint64(5)
+	other := uint64(6)
+
+	t.Run("signed url for attached file", func(t *testing.T) {
+		mockAttachmentRepo := new(mocks.MockAttachmentRepository)
+		mockAppRepo := new(mocks.MockAppRepository)
+		mockStorage := new(mocks.MockFileStorage)
+
+		mockAppRepo.On("GetByID", ctx, uint64(1)).Return(app, nil)
+		mockAttachmentRepo.On("GetByID", ctx, uint64(9)).Return(&models.Attachment{
+			ID: 9, AppID: 1, FieldCode: "files", RecordID: &recordID, StorageKey: "apps/1/abc",
+			FileName: "見積書.pdf", ContentType: "application/pdf", Size: 100, UploadedBy: &other,
+		}, nil)
+		mockStorage.On("SignedURL", ctx, "apps/1/abc", storage.URLOptions{
+			FileName: "見積書.pdf", ContentType: "application/pdf", Expires: 15 * time.Minute,
+		}).Return("/api/v1/files/apps/1/abc?signature=x", nil)
+
+		service := services.NewAttachmentService(mockAttachmentRepo, mockAppRepo, new(mocks.MockFieldRepository), new(mocks.MockDynamicQueryExecutor), newTestPermissionService(mockAppRepo), mockStorage)
+
+		resp, err := service.GetAttachmentURL(ctx, 1, 9)
+		require.NoError(t, err)
+		assert.Equal(t, "/api/v1/files/apps/1/abc?signature=x", resp.URL)
+		assert.Equal(t, "見積書.pdf", resp.FileName)
+		assert.WithinDuration(t, time.Now().Add(15*time.Minute), resp.ExpiresAt, time.Minute)
+	})
+
+	t.Run("pending file of another user", func(t *testing.T) {
+		mockAttachmentRepo := new(mocks.MockAttachmentRepository)
+		mockAppRepo := new(mocks.MockAppRepository)
+
+		mockAppRepo.On("GetByID", ctx, uint64(1)).Return(app, nil)
+		mockAttachmentRepo.On("GetByID", ctx, uint64(9)).Return(&models.Attachment{
+			ID: 9, AppID: 1, FieldCode: "files", StorageKey: "apps/1/abc", UploadedBy: &other,
+		}, nil)
+
+		service := services.NewAttachmentService(mockAttachmentRepo, mockAppRepo, new(mocks.MockFieldRepository), new(mocks.MockDynamicQueryExecutor), newTestPermissionService(mockAppRepo), new(mocks.MockFileStorage))
+
+		_, err := service.GetAttachmentURL(ctx, 1, 9)
+		assert.ErrorIs(t, err, services.ErrAttachmentNotFound)
+	})
+
+	t.Run("orphaned or other app", func(t *testing.T) {
+		now := time.Now()
+		for _, attachment := range []*models.Attachment{
+			{ID: 9, AppID: 1, RecordID: &recordID, UploadedBy: &uploader, OrphanedAt: &now},
+			{ID: 9, AppID: 2, RecordID: &recordID, UploadedBy: &uploader},
+		} {
+			mockAttachmentRepo := new(mocks.MockAttachmentRepository)
+			mockAppRepo := new(mocks.MockAppRepository)
+
+			mockAppRepo.On("GetByID", ctx, uint64(1)).Return(app, nil)
+			mockAttachmentRepo.On("GetByID", ctx, uint64(9)).Return(attachment, nil)
+
+			service := services.NewAttachmentService(mockAttachmentRepo, mockAppRepo, new(mocks.MockFieldRepository), new(mocks.MockDynamicQueryExecutor), newTestPermissionService(mockAppRepo), new(mocks.MockFileStorage))
+
+			_, err := service.GetAttachmentURL(ctx, 1, 9)
+			assert.ErrorIs(t, err, services.ErrAttachmentNotFound)
+		}
+	})
+}
+
+func TestAttachmentService_ResolveValues(t *testing.T) {
+	ctx := systemContext()
+	fields := []models.AppField{attachmentField(nil), {ID: 4, AppID: 1, FieldCode: "title", FieldType: string(models.FieldTypeText)}}
+	userID := uint64(5)
+	other := uint64(6)
+	recordID := uint64(20)
+	otherRecord := uint64(21)
+
+	attachments := []models.Attachment{
+		{ID: 1, AppID: 1, FieldCode: "files", FileName: "a.pdf", ContentType: "application/pdf", Size: 10, UploadedBy: &userID},
+		{ID: 2, AppID: 1, FieldCode: "files", FileName: "b.pdf", ContentType: "application/pdf", Size: 20, UploadedBy: &other},
+		{ID: 3, AppID: 1, FieldCode: "files", FileName: "c.pdf", ContentType: "application/pdf", Size: 30, RecordID: &recordID, UploadedBy: &other},
+		{ID: 4, AppID: 1, FieldCode: "files", FileName: "d.pdf", ContentType: "application/pdf", Size: 40, RecordID: &otherRecord, UploadedBy: &userID},
+		{ID: 5, AppID: 1, FieldCode: "other", FileName: "e.pdf", ContentType: "application/pdf", Size: 50, UploadedBy: &userID},
+	}
+	newService := func() *services.AttachmentService {
+		mockAttachmentRepo := new(mocks.MockAttachmentRepository)
+		mockAttachmentRepo.On("GetByIDs", ctx, mock.Anything).Return(attachments, nil)
+		return services.NewAttachmentService(mockAttachmentRepo, new(mocks.MockAppRepository), new(mocks.MockFieldRepository), new(mocks.MockDynamicQueryExecutor), newTestPermissionService(new(mocks.MockAppRepository)), new(mocks.MockFileStorage))
+	}
+
+	t.Run("replaces ids with file metadata", func(t *testing.T) {
+		rows := []models.RecordData{{"files": []uint64{1, 3}, "title": "x"}}
+
+		errs, err := newService().ResolveValues(ctx, 1, recordID, userID, fields, rows)
+		require.NoError(t, err)
+		assert.Nil(t, errs)
+		assert.Equal(t, []models.AttachmentValue{
+			{ID: 1, FileName: "a.pdf", ContentType: "application/pdf", Size: 10},
+			{ID: 3, FileName: "c.pdf", ContentType: "application/pdf", Size: 30},
+		}, rows[0]["files"])
+		assert.Equal(t, "x", rows[0]["title"])
+	})
+
+	t.Run("rejects files that cannot be attached", func(t *testing.T) {
+		for name, id := range map[string]uint64{
+			"pending file of another user": 2,
+			"file of another record":       4,
+			"file of another field":        5,
+			"missing file":                 99,
+		} {
+			rows := []models.RecordData{{"files": []uint64{id}}}
+			errs, err := newService().ResolveValues(ctx, 1, recordID, userID, fields, rows)
+			require.NoError(t, err, name)
+			require.Contains(t, errs, 0, name)
+			assert.Contains(t, errs[0], "files", name)
+		}
+	})
+
+	t.Run("attached file cannot be used on create", func(t *testing.T) {
+		rows := []models.RecordData{{"files": []uint64{3}}}
+		errs, err := newService().ResolveValues(ctx, 1, 0, userID, fields, rows)
+		require.NoError(t, err)
+		assert.Contains(t, errs, 0)
+	})
+
+	t.Run("same file in two records", func(t *testing.T) {
+		rows := []models.RecordData{{"files": []uint64{1}}, {"files": []uint64{1}}}
+		errs, err := newService().ResolveValues(ctx, 1, 0, userID, fields, rows)
+		require.NoError(t, err)
+		assert.NotContains(t, errs, 0)
+		assert.Contains(t, errs, 1)
+	})
+
+	t.Run("empty value clears field", func(t *testing.T) {
+		rows := []models.RecordData{{"files": nil}}
+		errs, err := newService().ResolveValues(ctx, 1, recordID, userID, fields, rows)
+		require.NoError(t, err)
+		assert.Nil(t, errs)
+		assert.Nil(t, rows[0]["files"])
+	})
+}
+
+func TestAttachmentService_AttachRecord(t *testing.T) {
+	ctx := systemContext()
+	mockAttachmentRepo := new(mocks.MockAttachmentRepository)
+	mockAttachmentRepo.On("SyncRecord", ctx, uint64(1), uint64(20), "files", []uint64{1, 3}, mock.AnythingOfType("time.Time")).Return(nil)
+
+	service := services.NewAttachmentService(mockAttachmentRepo, new(mocks.MockAppRepository), new(mocks.MockFieldRepository), new(mocks.MockDynamicQueryExecutor), newTestPermissionService(new(mocks.MockAppRepository)), new(mocks.MockFileStorage))
+
+	err := service.AttachRecord(ctx, 1, 20, []models.AppField{attachmentField(nil)}, models.RecordData{
+		"files": []models.AttachmentValue{{ID: 1}, {ID: 3}},
+	})
+	require.NoError(t, err)
+	mockAttachmentRepo.AssertExpectations(t)
+}
+
+func TestAttachmentCleaner_ProcessOrphans(t *testing.T) {
+	ctx := systemContext()
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	pendingBefore := now.Add(-24 * time.Hour)
+
+	mockAttachmentRepo := new(mocks.MockAttachmentRepository)
+	mockStorage := new(mocks.MockFileStorage)
+
+	mockAttachmentRepo.On("GetPurgeable", ctx, pendingBefore, mock.Anything).Return([]models.Attachment{
+		{ID: 1, StorageKey: "apps/1/a"},
+		{ID: 2, StorageKey: "apps/1/b"},
+		{ID: 3, StorageKey: "apps/1/c"},
+	}, nil)
+	mockAttachmentRepo.On("DeletePurgeable", ctx, uint64(1), pendingBefore).Return(true, nil)
+	// 取得後にレコードへ添付し直されたファイル
+	mockAttachmentRepo.On("DeletePurgeable", ctx, uint64(2), pendingBefore).Return(false, nil)
+	mockAttachmentRepo.On("DeletePurgeable", ctx, uint64(3), pendingBefore).Return(true, nil)
+	mockStorage.On("Delete", ctx, "apps/1/a").Return(errors.New("unavailable"))
+	mockStorage.On("Delete", ctx, "apps/1/c").Return(nil)
+
+	n, err := services.NewAttachmentCleaner(mockAttachmentRepo, mockStorage).ProcessOrphans(ctx, now)
+	// ストレージからの削除に失敗しても残りの処理は続ける
+	require.Error(t, err)
+	assert.Equal(t, 2, n)
+	mockStorage.AssertNotCalled(t, "Delete", ctx, "apps/1/b")
+	mockStorage.AssertExpectations(t)
+}
+
+func TestFieldService_AttachmentOptions(t *testing.T) {
+	ctx := systemContext()
+
+	for name, options := range map[string]models.FieldOptions{
+		"size above limit":    {services.OptionMaxFileSize: float64(services.MaxAttachmentFileSize + 1)},
+		"fractional size":     {services.OptionMaxFileSize: float64(1.5)},
+		"zero max files":      {services.OptionMaxFiles: float64(0)},
+		"types not array":     {services.OptionAllowedTypes: "image/png"},
+		"invalid media type":  {services.OptionAllowedTypes: []interface{}{"png"}},
+		"empty allowed types": {services.OptionAllowedTypes: []interface{}{}},
+	} {
+		mockFieldRepo := new(mocks.MockFieldRepository)
+		mockAppRepo := new(mocks.MockAppRepository)
+
+		mockAppRepo.On("GetByID", ctx, uint64(1)).Return(&models.App{ID: 1, TableName: "app_data_1"}, nil)
+		mockFieldRepo.On("FieldCodeExists", ctx, uint64(1), "files").Return(false, nil)
+		mockFieldRepo.On("GetMaxDisplayOrder", ctx, uint64(1)).Return(0, nil)
+
+		service := services.NewFieldService(mockFieldRepo, mockAppRepo, new(mocks.MockDynamicQueryExecutor), newTestPermissionService(mockAppRepo), newTestTransactor(), newTestWebhookPublisher(), newTestAttachmentManager())
+
+		_, err := service.CreateField(ctx, 1, &models.CreateFieldRequest{
+			FieldCode: "files", FieldName: "添付", FieldType: string(models.FieldTypeAttachment), Options: options,
+		})
+		assert.ErrorIs(t, err, services.ErrInvalidFieldOptions, name)
+		mockFieldRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	}
+}
+
+func TestFieldService_DeleteField_KeepsAttachments(t *testing.T) {
+	ctx := systemContext()
+	mockFieldRepo := new(mocks.MockFieldRepository)
+	mockAppRepo := new(mocks.MockAppRepository)
+	mockDynamicQuery := new(mocks.MockDynamicQueryExecutor)
+	mockAttachments := new(mocks.MockAttachmentManager)
+
+	field := attachmentField(nil)
+	mockFieldRepo.On("GetByID", ctx, uint64(3)).Return(&field, nil)
+	mockAppRepo.On("GetByID", ctx, uint64(1)).Return(&models.App{ID: 1, TableName: "app_data_1"}, nil)
+	mockFieldRepo.On("GetByAppID", ctx, uint64(1)).Return([]models.AppField{field}, nil)
+	mockFieldRepo.On("GetReferencingFields", ctx, uint64(1)).Return([]models.AppField{}, nil)
+	mockFieldRepo.On("Trash", ctx, uint64(3)).Return(nil)
+
+	service := services.NewFieldService(mockFieldRepo, mockAppRepo, mockDynamicQuery, newTestPermissionService(mockAppRepo), newTestTransactor(), newTestWebhookPublisher(), mockAttachments)
+
+	require.NoError(t, service.DeleteField(ctx, 1, 3, 0))
+	// ごみ箱から復元できるよう、カラムと添付ファイルは完全に削除するまで残す
+	mockFieldRepo.AssertExpectations(t)
+	mockDynamicQuery.AssertNotCalled(t, "DropColumn", mock.Anything, mock.Anything, mock.Anything)
+	mockAttachments.AssertNotCalled(t, "ReleaseField", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestRecordService_CreateRecord_Attachments(t *testing.T) {
+	ctx := userContext(5, "user")
+	app := &models.App{ID: 1, TableName: "app_data_1", CreatedBy: 5}
+	fields := []models.AppField{attachmentField(nil)}
+
+	t.Run("attaches uploaded files", func(t *testing.T) {
+		mockAppRepo := new(mocks.MockAppRepository)
+		mockFieldRepo := new(mocks.MockFieldRepository)
+		mockDynamicQuery := new(mocks.MockDynamicQueryExecutor)
+		mockRevisionRepo := new(mocks.MockRecordRevisionRepository)
+		mockAttachments := new(mocks.MockAttachmentManager)
+
+		values := []models.AttachmentValue{{ID: 7, FileName: "a.pdf", ContentType: "application/pdf", Size: 10}}
+		mockAppRepo.On("GetByID", ctx, uint64(1)).Return(app, nil)
+		mockFieldRepo.On("GetByAppID", ctx, uint64(1)).Return(fields, nil)
+		mockAttachments.On("ResolveValues", ctx, uint64(1), uint64(0), uint64(5), fields, mock.Anything).Return(nil, nil).Run(func(args mock.Arguments) {
+			rows := args.Get(5).([]models.RecordData)
+			assert.Equal(t, []uint64{7}, rows[0]["files"])
+			rows[0]["files"] = values
+		})
+		mockDynamicQuery.On("InsertRecord", ctx, "app_data_1", models.RecordData{"files": values}, uint64(5)).Return(uint64(20), nil)
+		mockAttachments.On("AttachRecord", ctx, uint64(1), uint64(20), fields, models.RecordData{"files": values}).Return(nil)
+		mockDynamicQuery.On("GetRecordByID", ctx, "app_data_1", fields, uint64(20)).Return(&models.RecordResponse{ID: 20, Data: models.RecordData{"files": values}}, nil)
+		mockRevisionRepo.On("Create", ctx, mock.Anything).Return(nil)
+
+		service := services.NewRecordService(mockAppRepo, mockFieldRepo, mockDynamicQuery, new(mocks.MockDataSourceRepository), new(mocks.MockExternalQueryExecutor), newTestPermissionService(mockAppRepo), mockRevisionRepo, newTestTransactor(), newTestWebhookPublisher(), newTestAutomationRunner(), mockAttachments)
+
+		_, err := service.CreateRecord(ctx, 1, 5, &models.CreateRecordRequest{Data: models.RecordData{"files": []interface{}{float64(7)}}})
+		require.NoError(t, err)
+		mockAttachments.AssertExpectations(t)
+	})
+
+	t.Run("unknown attachment", func(t *testing.T) {
+		mockAppRepo := new(mocks.MockAppRepository)
+		mockFieldRepo := new(mocks.MockFieldRepository)
+		mockDynamicQuery := new(mocks.MockDynamicQueryExecutor)
+		mockAttachments := new(mocks.MockAttachmentManager)
+
+		mockAppRepo.On("GetByID", ctx, uint64(1)).Return(app, nil)
+		mockFieldRepo.On("GetByAppID", ctx, uint64(1)).Return(fields, nil)
+		mockAttachments.On("ResolveValues", ctx, uint64(1), uint64(0), uint64(5), fields, mock.Anything).Return(map[int]models.FieldErrors{
+			0: {"files": "添付ファイル（ID: 7）が見つかりません"},
+		}, nil)
+
+		service := services.NewRecordService(mockAppRepo, mockFieldRepo, mockDynamicQuery, new(mocks.MockDataSourceRepository), new(mocks.MockExternalQueryExecutor), newTestPermissionService(mockAppRepo), new(mocks.MockRecordRevisionRepository), newTestTransactor(), newTestWebhookPublisher(), newTestAutomationRunner(), mockAttachments)
+
+		_, err := service.CreateRecord(ctx, 1, 5, &models.CreateRecordRequest{Data: models.RecordData{"files": []interface{}{float64(7)}}})
+		var validationErr *services.RecordValidationError
+		require.ErrorAs(t, err, &validationErr)
+		assert.Contains(t, validationErr.Errors, "files")
+		mockDynamicQuery.AssertNotCalled(t, "InsertRecord", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+}
@@ -0,0 +1,922 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"nocode-app/backend/internal/models"
+	"nocode-app/backend/internal/repositories"
+	"nocode-app/backend/internal/utils"
+)
+
+// 自動化ルール関連エラー
+var (
+	ErrAutomationRuleNotFound = errors.New("自動化ルールが見つかりません")
+	ErrInvalidAutomationRule  = errors.New("自動化ルールの設定が不正です")
+)
+
+const (
+	// automationMaxDepth 自動化ルールのアクションが別のルールを連鎖的に実行できる最大の深さ
+	automationMaxDepth = 5
+	// automationMaxErrorLength 実行ログに保存するエラーメッセージの最大文字数
+	automationMaxErrorLength = 500
+	// automationRecordIDSource テンプレートでレコードIDを表す名前
+	automationRecordIDSource = "id"
+	// automationDefaultTimezone タイムゾーンを指定しない定期実行ルールのタイムゾーン
+	automationDefaultTimezone = "UTC"
+	// automationDateCheckInterval 日付の経過を契機とするルールでレコードを確認する間隔
+	automationDateCheckInterval = time.Minute
+)
+
+// automationTemplatePattern 契機となったレコードの値を参照するテンプレート（"{{field_code}}" の形式）
+var automationTemplatePattern = regexp.MustCompile(`\{\{\s*([A-Za-z0-9_]+)\s*\}\}`)
+
+// automationConditionOperators 条件に指定できる演算子（レコード一覧のフィルターの比較演算子）
+var automationConditionOperators = map[string]bool{
+	"eq": true, "ne": true, "gt": true, "gte": true, "lt": true, "lte": true, "like": true,
+}
+
+// automationChainKey 実行中の自動化ルールの連鎖をコンテキストに保持するためのキー
+type automationChainKey struct{}
+
+// automationChain 1回のレコード操作から始まる自動化ルールの連鎖の状態
+// 同じ連鎖の中では、1つのルールは1件のレコードに対して1回だけ実行する
+type automationChain struct {
+	depth int
+	fired map[string]bool
+}
+
+// automationChainFromContext コンテキストから連鎖の状態を取得する（連鎖の開始時は新しく作成する）
+func automationChainFromContext(ctx context.Context) *automationChain {
+	if chain, ok := ctx.Value(automationChainKey{}).(*automationChain); ok {
+		return chain
+	}
+	return &automationChain{fired: make(map[string]bool)}
+}
+
+// AutomationService 自動化ルールの管理と、レコードの変更を契機としたルールの実行を処理する構造体
+type AutomationService struct {
+	ruleRepo     repositories.AutomationRuleRepositoryInterface
+	runRepo      repositories.AutomationRunRepositoryInterface
+	appRepo      repositories.AppRepositoryInterface
+	fieldRepo    repositories.FieldRepositoryInterface
+	dynamicQuery repositories.DynamicQueryExecutorInterface
+	revisionRepo repositories.RecordRevisionRepositoryInterface
+	webhookRepo  repositories.WebhookRepositoryInterface
+	transactor   repositories.TransactorInterface
+	webhooks     WebhookPublisherInterface
+	permissions  PermissionServiceInterface
+	attachments  AttachmentManagerInterface
+}
+
+// NewAutomationService 新しいAutomationServiceを作成する
+func NewAutomationService(
+	ruleRepo repositories.AutomationRuleRepositoryInterface,
+	runRepo repositories.AutomationRunRepositoryInterface,
+	appRepo repositories.AppRepositoryInterface,
+	fieldRepo repositories.FieldRepositoryInterface,
+	dynamicQuery repositories.DynamicQueryExecutorInterface,
+	revisionRepo repositories.RecordRevisionRepositoryInterface,
+	webhookRepo repositories.WebhookRepositoryInterface,
+	transactor repositories.TransactorInterface,
+	webhooks WebhookPublisherInterface,
+	permissions PermissionServiceInterface,
+	attachments AttachmentManagerInterface,
+) *AutomationService {
+	return &AutomationService{
+		ruleRepo:     ruleRepo,
+		runRepo:      runRepo,
+		appRepo:      appRepo,
+		fieldRepo:    fieldRepo,
+		dynamicQuery: dynamicQuery,
+		revisionRepo: revisionRepo,
+		webhookRepo:  webhookRepo,
+		transactor:   transactor,
+		webhooks:     webhooks,
+		permissions:  permissions,
+		attachments:  attachments,
+	}
+}
+
+// records アクションで書き込むレコードの参照先・添付ファイルをレコード操作と同じ方法で確認するRecordServiceを返す
+func (s *AutomationService) records() *RecordService {
+	return &RecordService{appRepo: s.appRepo, dynamicQuery: s.dynamicQuery, attachments: s.attachments}
+}
+
+// getRule アプリに登録された自動化ルールを取得する
+func (s *AutomationService) getRule(ctx context.Context, appID, ruleID uint64) (*models.App, *models.AutomationRule, error) {
+	app, _, err := s.permissions.AuthorizeApp(ctx, appID, models.AppRoleOwner)
+	if err != nil {
+		return nil, nil, err
+	}
+	rule, err := s.ruleRepo.GetByID(ctx, ruleID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if rule == nil || rule.AppID != appID {
+		return nil, nil, ErrAutomationRuleNotFound
+	}
+	return app, rule, nil
+}
+
+// GetRules アプリに登録された自動化ルールの一覧を取得する
+func (s *AutomationService) GetRules(ctx context.Context, appID uint64) (*models.AutomationRuleListResponse, error) {
+	if _, _, err := s.permissions.AuthorizeApp(ctx, appID, models.AppRoleOwner); err != nil {
+		return nil, err
+	}
+	rules, err := s.ruleRepo.GetByAppID(ctx, appID)
+	if err != nil {
+		return nil, err
+	}
+	return &models.AutomationRuleListResponse{Rules: rules}, nil
+}
+
+// CreateRule アプリに自動化ルールを登録する
+func (s *AutomationService) CreateRule(ctx context.Context, appID, userID uint64, req *models.CreateAutomationRuleRequest) (*models.AutomationRule, error) {
+	app, _, err := s.permissions.AuthorizeApp(ctx, appID, models.AppRoleOwner)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	rule := &models.AutomationRule{
+		AppID:        appID,
+		Name:         req.Name,
+		Trigger:      req.Trigger,
+		TriggerField: req.TriggerField,
+		Schedule:     req.Schedule,
+		OffsetDays:   req.OffsetDays,
+		Timezone:     req.Timezone,
+		Conditions:   req.Conditions,
+		Actions:      req.Actions,
+		IsActive:     req.IsActive == nil || *req.IsActive,
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	}
+	if userID != 0 {
+		rule.CreatedBy = &userID
+	}
+	if err := s.validateRule(ctx, app, rule); err != nil {
+		return nil, err
+	}
+	scheduleAutomationRule(rule, now)
+
+	if err := s.ruleRepo.Create(ctx, rule); err != nil {
+		return nil, err
+	}
+	return rule, nil
+}
+
+// UpdateRule 自動化ルールの設定を置き換える
+func (s *AutomationService) UpdateRule(ctx context.Context, appID, ruleID uint64, req *models.UpdateAutomationRuleRequest) (*models.AutomationRule, error) {
+	app, rule, err := s.getRule(ctx, appID, ruleID)
+	if err != nil {
+		return nil, err
+	}
+
+	before := *rule
+	rule.Name = req.Name
+	rule.Trigger = req.Trigger
+	rule.TriggerField = req.TriggerField
+	rule.Schedule = req.Schedule
+	rule.OffsetDays = req.OffsetDays
+	rule.Timezone = req.Timezone
+	rule.Conditions = req.Conditions
+	rule.Actions = req.Actions
+	if req.IsActive != nil {
+		rule.IsActive = *req.IsActive
+	}
+	rule.UpdatedAt = time.Now()
+	if err := s.validateRule(ctx, app, rule); err != nil {
+		return nil, err
+	}
+
+	if err := s.ruleRepo.Update(ctx, rule); err != nil {
+		return nil, err
+	}
+	// 実行のタイミングが変わった場合（再度有効にした場合を含む）は、現在時刻から次回の実行を予約し直す
+	if automationScheduleChanged(&before, rule) {
+		scheduleAutomationRule(rule, rule.UpdatedAt)
+		if err := s.ruleRepo.UpdateSchedule(ctx, rule); err != nil {
+			return nil, err
+		}
+	}
+	return rule, nil
+}
+
+// DeleteRule 自動化ルールと実行ログを削除する
+func (s *AutomationService) DeleteRule(ctx context.Context, appID, ruleID uint64) error {
+	if _, _, err := s.getRule(ctx, appID, ruleID); err != nil {
+		return err
+	}
+	return s.ruleRepo.Delete(ctx, ruleID)
+}
+
+// GetRuns 自動化ルールの実行ログを新しい順に取得する
+func (s *AutomationService) GetRuns(ctx context.Context, appID, ruleID uint64, page, limit int) (*models.AutomationRunListResponse, error) {
+	if _, _, err := s.getRule(ctx, appID, ruleID); err != nil {
+		return nil, err
+	}
+
+	runs, total, err := s.runRepo.GetByRuleID(ctx, ruleID, page, limit)
+	if err != nil {
+		return nil, err
+	}
+	return &models.AutomationRunListResponse{
+		Runs:       runs,
+		Pagination: models.NewPagination(page, limit, total),
+	}, nil
+}
+
+// validateRule 契機・条件・アクションがアプリのフィールド定義と整合しているか確認する
+func (s *AutomationService) validateRule(ctx context.Context, app *models.App, rule *models.AutomationRule) error {
+	// 外部データソースのアプリはレコードが変更されないため、ルールが実行されることがない
+	if app.IsExternal {
+		return invalidAutomationRule("外部データソースのアプリには設定できません")
+	}
+
+	fields, err := s.fieldRepo.GetByAppID(ctx, app.ID)
+	if err != nil {
+		return err
+	}
+	byCode := fieldsByCode(fields)
+
+	if err := validateAutomationTrigger(rule, byCode); err != nil {
+		return err
+	}
+
+	if rule.Conditions == nil {
+		rule.Conditions = []models.FilterItem{}
+	}
+	// 定期実行では対象のレコードがないため、条件で絞り込めない
+	if rule.Trigger == models.AutomationTriggerSchedule && len(rule.Conditions) > 0 {
+		return invalidAutomationRule("スケジュールで実行するルールには条件を指定できません")
+	}
+	for _, cond := range rule.Conditions {
+		field, ok := byCode[cond.Field]
+		if !ok || !field.HasColumn() {
+			return invalidAutomationRule("条件のフィールド %q が存在しません", cond.Field)
+		}
+		if !automationConditionOperators[cond.Operator] {
+			return invalidAutomationRule("条件に演算子 %q は使用できません", cond.Operator)
+		}
+	}
+
+	for i := range rule.Actions {
+		action := &rule.Actions[i]
+		if rule.Trigger == models.AutomationTriggerSchedule {
+			if action.Type == models.AutomationActionUpdateRecord {
+				return invalidAutomationRule("スケジュールで実行するルールでは、契機となるレコードがないためレコードを更新できません")
+			}
+			for _, value := range action.Values {
+				if len(automationTemplateSources(value)) > 0 {
+					return invalidAutomationRule("スケジュールで実行するルールではテンプレートを使用できません")
+				}
+			}
+		}
+		if err := s.validateAction(ctx, app, fields, action); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validateAutomationTrigger 契機の設定を確認し、契機の種類で使わない設定を空にする
+func validateAutomationTrigger(rule *models.AutomationRule, byCode map[string]*models.AppField) error {
+	switch rule.Trigger {
+	case models.AutomationTriggerFieldChanged:
+		field, ok := byCode[rule.TriggerField]
+		if !ok || !field.HasColumn() {
+			return invalidAutomationRule("契機とするフィールド %q が存在しません", rule.TriggerField)
+		}
+		rule.Schedule, rule.OffsetDays, rule.Timezone = "", 0, ""
+
+	case models.AutomationTriggerSchedule:
+		if _, err := utils.ParseCron(rule.Schedule); err != nil {
+			return invalidAutomationRule("スケジュール %q が不正です（%s）", rule.Schedule, err.Error())
+		}
+		rule.TriggerField, rule.OffsetDays = "", 0
+
+	case models.AutomationTriggerDateReached:
+		field, ok := byCode[rule.TriggerField]
+		if !ok || !field.HasColumn() {
+			return invalidAutomationRule("契機とするフィールド %q が存在しません", rule.TriggerField)
+		}
+		if automationDateType(field) == "" {
+			return invalidAutomationRule("契機とするフィールド %q は日付または日時のフィールドではありません", rule.TriggerField)
+		}
+		rule.Schedule = ""
+
+	default:
+		rule.TriggerField, rule.Schedule, rule.OffsetDays, rule.Timezone = "", "", 0, ""
+		return nil
+	}
+
+	if rule.Timezone == "" {
+		rule.Timezone = automationDefaultTimezone
+	}
+	if _, err := time.LoadLocation(rule.Timezone); err != nil {
+		return invalidAutomationRule("タイムゾーン %q が存在しません", rule.Timezone)
+	}
+	return nil
+}
+
+// automationDateType 日付の経過を契機にできるフィールドの値の型（日付・日時）を返す
+// 計算フィールドは計算結果の型で判定し、それ以外のフィールドは空文字を返す
+func automationDateType(field *models.AppField) models.FieldType {
+	fieldType := models.FieldType(field.FieldType)
+	if fieldType == models.FieldTypeFormula {
+		fieldType = field.FormulaResultType()
+	}
+	switch fieldType {
+	case models.FieldTypeDate, models.FieldTypeDateTime:
+		return fieldType
+	}
+	return ""
+}
+
+// scheduleAutomationRule 定期実行ルールの次回実行時刻を現在時刻から設定する
+// 日付の経過を契機とするルールは、設定した時点以降に経過したレコードのみを対象とする
+func scheduleAutomationRule(rule *models.AutomationRule, now time.Time) {
+	now = now.UTC()
+	rule.NextRunAt, rule.LastRunAt = nil, nil
+	switch rule.Trigger {
+	case models.AutomationTriggerSchedule:
+		if next, err := nextAutomationScheduleRun(rule, now); err == nil {
+			rule.NextRunAt = &next
+		}
+	case models.AutomationTriggerDateReached:
+		next := now.Add(automationDateCheckInterval)
+		rule.NextRunAt, rule.LastRunAt = &next, &now
+	}
+}
+
+// nextAutomationScheduleRun スケジュールをルールのタイムゾーンで評価し、nowより後の次回実行時刻をUTCで返す
+func nextAutomationScheduleRun(rule *models.AutomationRule, now time.Time) (time.Time, error) {
+	schedule, err := utils.ParseCron(rule.Schedule)
+	if err != nil {
+		return time.Time{}, err
+	}
+	loc, err := time.LoadLocation(rule.Timezone)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return schedule.Next(now.In(loc)).UTC(), nil
+}
+
+// automationScheduleChanged ルールの更新で実行のタイミングに関わる設定が変わったかどうかを確認する
+func automationScheduleChanged(before, after *models.AutomationRule) bool {
+	return before.Trigger != after.Trigger ||
+		before.TriggerField != after.TriggerField ||
+		before.Schedule != after.Schedule ||
+		before.OffsetDays != after.OffsetDays ||
+		before.Timezone != after.Timezone ||
+		(!before.IsActive && after.IsActive)
+}
+
+// validateAction アクションの対象と設定する値を確認する
+// fields は契機となるアプリのフィールドで、テンプレートの参照元となる
+func (s *AutomationService) validateAction(ctx context.Context, app *models.App, fields []models.AppField, action *models.AutomationAction) error {
+	switch action.Type {
+	case models.AutomationActionUpdateRecord:
+		action.AppID, action.WebhookID = 0, 0
+		if len(action.Values) == 0 {
+			return invalidAutomationRule("更新する値を指定してください")
+		}
+		return validateAutomationValues(fields, fields, action.Values, true)
+
+	case models.AutomationActionCreateRecord:
+		action.WebhookID = 0
+		target, err := s.appRepo.GetByID(ctx, action.AppID)
+		if err != nil {
+			return err
+		}
+		if target == nil {
+			return invalidAutomationRule("作成先のアプリが存在しません")
+		}
+		if target.IsExternal {
+			return invalidAutomationRule("外部データソースのアプリにはレコードを作成できません")
+		}
+		// ルールの登録者が作成先のアプリに書き込めない場合は登録させない
+		if _, err := s.permissions.CheckAppAccess(ctx, target, models.AppRoleEditor); err != nil {
+			if errors.Is(err, ErrAppNotFound) {
+				return invalidAutomationRule("作成先のアプリが存在しません")
+			}
+			return err
+		}
+		targetFields, err := s.fieldRepo.GetByAppID(ctx, target.ID)
+		if err != nil {
+			return err
+		}
+		return validateAutomationValues(targetFields, fields, action.Values, false)
+
+	case models.AutomationActionCallWebhook:
+		action.AppID = 0
+		if len(action.Values) > 0 {
+			return invalidAutomationRule("Webhookの呼び出しには値を指定できません")
+		}
+		webhook, err := s.webhookRepo.GetByID(ctx, action.WebhookID)
+		if err != nil {
+			return err
+		}
+		if webhook == nil || webhook.AppID != app.ID {
+			return invalidAutomationRule("呼び出すWebhookが存在しません")
+		}
+		return nil
+	}
+	return invalidAutomationRule("アクション %q は使用できません", action.Type)
+}
+
+// validateAutomationValues アクションで設定する値を対象アプリのフィールド定義で検証する
+// テンプレートの値は実行時に決まるため、参照元のフィールドが存在することのみ確認する
+func validateAutomationValues(fields, sourceFields []models.AppField, values models.RecordData, partial bool) error {
+	targets := fieldsByCode(fields)
+	sources := fieldsByCode(sourceFields)
+	literals := make(models.RecordData, len(values))
+	templated := make(map[string]bool)
+	for code, value := range values {
+		field, ok := targets[code]
+		if !ok {
+			return invalidAutomationRule("フィールド %q が存在しません", code)
+		}
+		if field.IsComputed() {
+			return invalidAutomationRule("フィールド %q は計算で決まるため値を設定できません", code)
+		}
+		if models.FieldType(field.FieldType) == models.FieldTypeAttachment {
+			return invalidAutomationRule("添付ファイルフィールド %q には値を設定できません", code)
+		}
+		if templateSources := automationTemplateSources(value); len(templateSources) > 0 {
+			for _, source := range templateSources {
+				if _, exists := sources[source]; !exists && source != automationRecordIDSource {
+					return invalidAutomationRule("テンプレートのフィールド %q が存在しません", source)
+				}
+			}
+			templated[code] = true
+			continue
+		}
+		literals[code] = value
+	}
+
+	validator := NewRecordValidator(fields)
+	var fieldErrs models.FieldErrors
+	if partial {
+		_, fieldErrs = validator.ValidateUpdate(literals)
+	} else {
+		// 必須フィールドはテンプレートで値を設定していれば満たしているものとする
+		_, fieldErrs = validator.ValidateCreate(literals)
+		for code := range templated {
+			delete(fieldErrs, code)
+		}
+	}
+	if len(fieldErrs) > 0 {
+		return invalidAutomationRule("%s", describeFieldErrors(fieldErrs))
+	}
+	return nil
+}
+
+// Run レコードの変更履歴を契機として、条件に一致する有効な自動化ルールを実行する
+// アクションの失敗は実行ログに記録し、呼び出し元のレコード操作は失敗させない
+// アクションによるレコードの変更もさらにルールを実行するが、同じルールを同じレコードに対して
+// 2回以上実行せず、連鎖が automationMaxDepth 段に達した場合は実行しない（無限ループ防止）
+func (s *AutomationService) Run(ctx context.Context, revisions ...models.RecordRevision) error {
+	if len(revisions) == 0 {
+		return nil
+	}
+
+	chain := automationChainFromContext(ctx)
+	rulesByApp := make(map[uint64][]models.AutomationRule)
+	for i := range revisions {
+		revision := &revisions[i]
+		if revision.Action == models.RevisionActionDelete {
+			continue
+		}
+
+		rules, ok := rulesByApp[revision.AppID]
+		if !ok {
+			var err error
+			if rules, err = s.ruleRepo.GetByAppID(ctx, revision.AppID); err != nil {
+				return err
+			}
+			rulesByApp[revision.AppID] = rules
+		}
+
+		for j := range rules {
+			rule := &rules[j]
+			if !rule.IsActive || !automationTriggered(rule, revision) {
+				continue
+			}
+			if err := s.execute(ctx, chain, rule, revision); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// execute 1つのルールを1件のレコードに対して実行し、実行ログを記録する
+func (s *AutomationService) execute(ctx context.Context, chain *automationChain, rule *models.AutomationRule, revision *models.RecordRevision) error {
+	key := fmt.Sprintf("%d:%d", rule.ID, revision.RecordID)
+	if chain.fired[key] {
+		return nil
+	}
+
+	run := &models.AutomationRun{
+		RuleID:    rule.ID,
+		AppID:     revision.AppID,
+		RecordID:  revision.RecordID,
+		Depth:     chain.depth,
+		CreatedAt: time.Now(),
+	}
+	if chain.depth >= automationMaxDepth {
+		run.Status = models.AutomationRunSkipped
+		run.Error = fmt.Sprintf("自動化ルールの連鎖が上限（%d段）に達したため実行しませんでした", automationMaxDepth)
+		return s.runRepo.Create(ctx, run)
+	}
+
+	chain.fired[key] = true
+	next := context.WithValue(ctx, automationChainKey{}, &automationChain{depth: chain.depth + 1, fired: chain.fired})
+	run.Status = models.AutomationRunSucceeded
+	for i := range rule.Actions {
+		if err := s.runAction(next, rule, &rule.Actions[i], revision); err != nil {
+			run.Status = models.AutomationRunFailed
+			run.Error = truncateAutomationError(fmt.Sprintf("アクション%d（%s）: %s", i+1, rule.Actions[i].Type, err.Error()))
+			break
+		}
+	}
+	return s.runRepo.Create(ctx, run)
+}
+
+// runAction 1つのアクションを実行する
+func (s *AutomationService) runAction(ctx context.Context, rule *models.AutomationRule, action *models.AutomationAction, revision *models.RecordRevision) error {
+	switch action.Type {
+	case models.AutomationActionUpdateRecord:
+		return s.updateRecord(ctx, action, revision)
+	case models.AutomationActionCreateRecord:
+		return s.createRecord(ctx, rule, action, revision)
+	case models.AutomationActionCallWebhook:
+		return s.webhooks.PublishTo(ctx, action.WebhookID, models.WebhookEvent{
+			Event:      models.WebhookEventAutomationTriggered,
+			AppID:      revision.AppID,
+			OccurredAt: time.Now(),
+			Data: models.WebhookAutomationData{
+				RuleID:   rule.ID,
+				RuleName: rule.Name,
+				RecordID: revision.RecordID,
+				Data:     revision.Snapshot,
+			},
+		})
+	}
+	return fmt.Errorf("アクション %q は使用できません", action.Type)
+}
+
+// updateRecord 契機となったレコードのフィールドを更新する
+func (s *AutomationService) updateRecord(ctx context.Context, action *models.AutomationAction, revision *models.RecordRevision) error {
+	app, fields, err := s.getWritableApp(ctx, revision.AppID)
+	if err != nil {
+		return err
+	}
+
+	data, fieldErrs := NewRecordValidator(fields).ValidateUpdate(resolveAutomationValues(action.Values, revision))
+	if fieldErrs != nil {
+		return errors.New(describeFieldErrors(fieldErrs))
+	}
+	// 自動化ルールによる変更は操作者を記録しないため、添付できるのはレコードに添付済みのファイルのみとする
+	if err := s.checkRecordValues(ctx, app.ID, revision.RecordID, 0, fields, data); err != nil {
+		return err
+	}
+
+	before, err := s.dynamicQuery.GetRecordByID(ctx, app.TableName, storedFields(fields), revision.RecordID)
+	if err != nil {
+		return err
+	}
+	if before == nil {
+		return ErrRecordNotFound
+	}
+
+	// レコードの更新・変更履歴・Webhookの配信キューへの登録をまとめて行う
+	var updated models.RecordRevision
+	err = s.transactor.RunInTx(ctx, func(ctx context.Context) error {
+		if err := s.dynamicQuery.UpdateRecord(ctx, app.TableName, revision.RecordID, data); err != nil {
+			return duplicateValueError(err, fields)
+		}
+		if err := s.attachments.AttachRecord(ctx, app.ID, revision.RecordID, fields, data); err != nil {
+			return err
+		}
+		after, err := s.dynamicQuery.GetRecordByID(ctx, app.TableName, storedFields(fields), revision.RecordID)
+		if err != nil {
+			return err
+		}
+		if after == nil {
+			return ErrRecordNotFound
+		}
+
+		// 自動化ルールによる変更は操作者を記録しない
+		updated = newRevision(app.ID, models.RevisionActionUpdate, before, after, 0)
+		if len(updated.Changes) == 0 {
+			return nil
+		}
+		return s.recordRevision(ctx, &updated)
+	})
+	if err != nil {
+		return err
+	}
+	if len(updated.Changes) > 0 {
+		s.runChained(ctx, updated)
+	}
+	return nil
+}
+
+// createRecord アクションで指定したアプリにレコードを作成する
+// 作成者は契機となった操作のユーザー（不明な場合はルールの登録者）とする
+func (s *AutomationService) createRecord(ctx context.Context, rule *models.AutomationRule, action *models.AutomationAction, revision *models.RecordRevision) error {
+	app, fields, err := s.getWritableApp(ctx, action.AppID)
+	if err != nil {
+		return err
+	}
+
+	data, fieldErrs := NewRecordValidator(fields).ValidateCreate(resolveAutomationValues(action.Values, revision))
+	if fieldErrs != nil {
+		return errors.New(describeFieldErrors(fieldErrs))
+	}
+
+	var createdBy uint64
+	switch {
+	case revision.ChangedBy != nil:
+		createdBy = *revision.ChangedBy
+	case rule.CreatedBy != nil:
+		createdBy = *rule.CreatedBy
+	}
+	if err := s.checkRecordValues(ctx, app.ID, 0, createdBy, fields, data); err != nil {
+		return err
+	}
+
+	// レコードの挿入・変更履歴・Webhookの配信キューへの登録をまとめて行う
+	var created models.RecordRevision
+	err = s.transactor.RunInTx(ctx, func(ctx context.Context) error {
+		recordID, err := s.dynamicQuery.InsertRecord(ctx, app.TableName, data, createdBy)
+		if err != nil {
+			return duplicateValueError(err, fields)
+		}
+		if err := s.attachments.AttachRecord(ctx, app.ID, recordID, fields, data); err != nil {
+			return err
+		}
+		record, err := s.dynamicQuery.GetRecordByID(ctx, app.TableName, storedFields(fields), recordID)
+		if err != nil {
+			return err
+		}
+
+		created = newRevision(app.ID, models.RevisionActionCreate, nil, record, 0)
+		return s.recordRevision(ctx, &created)
+	})
+	if err != nil {
+		return err
+	}
+	s.runChained(ctx, created)
+	return nil
+}
+
+// checkRecordValues アクションで書き込む値の参照先と添付ファイルを確認し、入力エラーをアクションのエラーとして返す
+func (s *AutomationService) checkRecordValues(ctx context.Context, appID, recordID, userID uint64, fields []models.AppField, data models.RecordData) error {
+	err := s.records().checkRecordReferences(ctx, fields, data)
+	if err == nil {
+		err = s.records().checkRecordAttachments(ctx, appID, recordID, userID, fields, data)
+	}
+	var validationErr *RecordValidationError
+	if errors.As(err, &validationErr) {
+		return errors.New(describeFieldErrors(validationErr.Errors))
+	}
+	return err
+}
+
+// getWritableApp アクションで書き込むアプリとフィールドを取得する
+func (s *AutomationService) getWritableApp(ctx context.Context, appID uint64) (*models.App, []models.AppField, error) {
+	app, err := s.appRepo.GetByID(ctx, appID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if app == nil {
+		return nil, nil, ErrAppNotFound
+	}
+	if app.IsExternal {
+		return nil, nil, ErrExternalAppReadOnly
+	}
+	fields, err := s.fieldRepo.GetByAppID(ctx, appID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return app, fields, nil
+}
+
+// recordRevision アクションによる変更を履歴に記録し、Webhookの配信キューに登録する
+func (s *AutomationService) recordRevision(ctx context.Context, revision *models.RecordRevision) error {
+	if err := s.revisionRepo.Create(ctx, revision); err != nil {
+		return err
+	}
+	return s.webhooks.Publish(ctx, revisionEvents(*revision)...)
+}
+
+// runChained コミットしたアクションによる変更を契機とする後続のルールを実行する
+// 後続のルールの失敗はそれぞれの実行ログに記録し、コミットしたアクションは失敗にしない
+func (s *AutomationService) runChained(ctx context.Context, revision models.RecordRevision) {
+	if err := s.Run(ctx, revision); err != nil {
+		log.Printf("自動化ルールの実行に失敗しました（アプリ%d）: %v", revision.AppID, err)
+	}
+}
+
+// automationTriggered 変更履歴がルールの契機と条件に一致するかどうかを確認する
+func automationTriggered(rule *models.AutomationRule, revision *models.RecordRevision) bool {
+	updated := revision.Action == models.RevisionActionUpdate || revision.Action == models.RevisionActionRevert
+	switch rule.Trigger {
+	case models.AutomationTriggerRecordCreated:
+		if revision.Action != models.RevisionActionCreate {
+			return false
+		}
+	case models.AutomationTriggerRecordUpdated:
+		if !updated || len(revision.Changes) == 0 {
+			return false
+		}
+	case models.AutomationTriggerFieldChanged:
+		if !updated {
+			return false
+		}
+		if _, ok := revision.Changes[rule.TriggerField]; !ok {
+			return false
+		}
+	default:
+		return false
+	}
+	return automationConditionsMatch(rule.Conditions, revision.Snapshot)
+}
+
+// automationConditionsMatch レコードの値がすべての条件に一致するかどうかを確認する
+func automationConditionsMatch(conditions []models.FilterItem, data models.RecordData) bool {
+	for _, cond := range conditions {
+		if !automationConditionMatches(cond, data[cond.Field]) {
+			return false
+		}
+	}
+	return true
+}
+
+// automationConditionMatches 値が条件に一致するかどうかを確認する
+// 複数選択の値はいずれかの要素が一致すれば一致とする（neはどの要素も一致しない場合に一致とする）
+func automationConditionMatches(cond models.FilterItem, value interface{}) bool {
+	if values, ok := value.([]interface{}); ok {
+		if cond.Operator == "ne" {
+			return !automationConditionMatches(models.FilterItem{Field: cond.Field, Operator: "eq", Value: cond.Value}, value)
+		}
+		for _, v := range values {
+			if compareAutomationValue(cond.Operator, v, cond.Value) {
+				return true
+			}
+		}
+		return false
+	}
+	return compareAutomationValue(cond.Operator, value, cond.Value)
+}
+
+// compareAutomationValue 値と条件の値を比較する
+// 両方が数値として解釈できる場合は数値で、それ以外は文字列で比較する（日付はISO 8601形式のため文字列の順序で比較できる）
+func compareAutomationValue(operator string, value interface{}, expected string) bool {
+	actual := automationValueString(value)
+	if operator == "like" {
+		return value != nil && strings.Contains(actual, expected)
+	}
+
+	var cmp int
+	a, errA := strconv.ParseFloat(actual, 64)
+	b, errB := strconv.ParseFloat(expected, 64)
+	switch {
+	case errA == nil && errB == nil:
+		switch {
+		case a < b:
+			cmp = -1
+		case a > b:
+			cmp = 1
+		}
+	default:
+		cmp = strings.Compare(actual, expected)
+	}
+
+	switch operator {
+	case "eq":
+		return cmp == 0
+	case "ne":
+		return cmp != 0
+	}
+	// 値が空の場合は大小比較の条件に一致させない
+	if value == nil {
+		return false
+	}
+	switch operator {
+	case "gt":
+		return cmp > 0
+	case "gte":
+		return cmp >= 0
+	case "lt":
+		return cmp < 0
+	case "lte":
+		return cmp <= 0
+	}
+	return false
+}
+
+// automationValueString レコードの値を条件と比較するための文字列に変換する
+func automationValueString(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// automationTemplateSources 値に含まれるテンプレートが参照するフィールドコードを返す
+func automationTemplateSources(value interface{}) []string {
+	str, ok := value.(string)
+	if !ok {
+		return nil
+	}
+	var sources []string
+	for _, m := range automationTemplatePattern.FindAllStringSubmatch(str, -1) {
+		sources = append(sources, m[1])
+	}
+	return sources
+}
+
+// resolveAutomationValues アクションの値のテンプレートを契機となったレコードの値に置き換える
+// 値全体が1つのテンプレートの場合は元の値を型を保ったまま使い、文字列の一部の場合は文字列として埋め込む
+func resolveAutomationValues(values models.RecordData, revision *models.RecordRevision) models.RecordData {
+	lookup := func(source string) interface{} {
+		if v, exists := revision.Snapshot[source]; exists || source != automationRecordIDSource {
+			return v
+		}
+		return float64(revision.RecordID)
+	}
+
+	resolved := make(models.RecordData, len(values))
+	for code, value := range values {
+		str, ok := value.(string)
+		if !ok {
+			resolved[code] = value
+			continue
+		}
+		if loc := automationTemplatePattern.FindStringSubmatchIndex(str); loc != nil && loc[0] == 0 && loc[1] == len(str) {
+			resolved[code] = lookup(str[loc[2]:loc[3]])
+			continue
+		}
+		resolved[code] = automationTemplatePattern.ReplaceAllStringFunc(str, func(match string) string {
+			source := automationTemplatePattern.FindStringSubmatch(match)[1]
+			return automationValueString(lookup(source))
+		})
+	}
+	return resolved
+}
+
+// fieldsByCode フィールドコードからフィールドを引くマップを作成する
+func fieldsByCode(fields []models.AppField) map[string]*models.AppField {
+	byCode := make(map[string]*models.AppField, len(fields))
+	for i := range fields {
+		byCode[fields[i].FieldCode] = &fields[i]
+	}
+	return byCode
+}
+
+// describeFieldErrors フィールド単位の入力エラーを1つのメッセージにまとめる
+func describeFieldErrors(errs models.FieldErrors) string {
+	codes := make([]string, 0, len(errs))
+	for code := range errs {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+
+	messages := make([]string, len(codes))
+	for i, code := range codes {
+		messages[i] = code + ": " + errs[code]
+	}
+	return strings.Join(messages, ", ")
+}
+
+// truncateAutomationError 実行ログに保存できる長さにエラーメッセージを切り詰める
+func truncateAutomationError(msg string) string {
+	if runes := []rune(msg); len(runes) > automationMaxErrorLength {
+		return string(runes[:automationMaxErrorLength])
+	}
+	return msg
+}
+
+// invalidAutomationRule ErrInvalidAutomationRule に理由を付けたエラーを返す
+func invalidAutomationRule(format string, args ...interface{}) error {
+	return fmt.Errorf("%w: %s", ErrInvalidAutomationRule, fmt.Sprintf(format, args...))
+}
@@ -0,0 +1,177 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"nocode-app/backend/internal/models"
+	"nocode-app/backend/internal/repositories"
+)
+
+const (
+	// automationSchedulerLockKey スケジューラーを1台のサーバーだけで実行するためのアドバイザリーロックのキー
+	automationSchedulerLockKey int64 = 0x6175746f6d617465
+	// automationSchedulerBatchSize 1回の処理で実行するルールの最大件数
+	automationSchedulerBatchSize = 20
+)
+
+// AutomationScheduler スケジュールや日付の経過を契機とする自動化ルールを実行する構造体
+// 複数のサーバーで起動しても、アドバイザリーロックを取得できた1台だけが実行する
+type AutomationScheduler struct {
+	automations *AutomationService
+	locker      repositories.AdvisoryLockerInterface
+}
+
+// NewAutomationScheduler 新しいAutomationSchedulerを作成する
+func NewAutomationScheduler(automations *AutomationService, locker repositories.AdvisoryLockerInterface) *AutomationScheduler {
+	return &AutomationScheduler{
+		automations: automations,
+		locker:      locker,
+	}
+}
+
+// ProcessDue 次回実行時刻を過ぎたルールを実行し、実行したルールの件数を返す
+// 他のサーバーが実行中の場合は何もせずに0を返す。
+// 次回実行時刻はアクションの実行前に保存するため、実行中にプロセスが停止しても同じ実行時刻で2回実行しない
+// ルールはユーザーの操作によらず実行するため、ctx は WithSystemCall で作成したものを渡す
+func (s *AutomationScheduler) ProcessDue(ctx context.Context, now time.Time) (int, error) {
+	now = now.UTC()
+	processed := 0
+	_, err := s.locker.TryWithLock(ctx, automationSchedulerLockKey, func(ctx context.Context) error {
+		rules, err := s.automations.ruleRepo.GetDue(ctx, now, automationSchedulerBatchSize)
+		if err != nil {
+			return err
+		}
+		for i := range rules {
+			if err := s.runRule(ctx, &rules[i], now); err != nil {
+				return err
+			}
+			processed++
+		}
+		return nil
+	})
+	return processed, err
+}
+
+// runRule 1つのルールを契機の種類に応じて実行する
+func (s *AutomationScheduler) runRule(ctx context.Context, rule *models.AutomationRule, now time.Time) error {
+	switch rule.Trigger {
+	case models.AutomationTriggerSchedule:
+		return s.runSchedule(ctx, rule, now)
+	case models.AutomationTriggerDateReached:
+		return s.runDateReached(ctx, rule, now)
+	}
+	// レコードの変更を契機とするルールは予約を取り消す
+	rule.NextRunAt, rule.LastRunAt = nil, nil
+	return s.automations.ruleRepo.UpdateSchedule(ctx, rule)
+}
+
+// runSchedule スケジュールで実行するルールのアクションを1回実行し、次回の実行を予約する
+// サーバーの停止中に過ぎた実行時刻があっても、まとめて1回だけ実行する
+func (s *AutomationScheduler) runSchedule(ctx context.Context, rule *models.AutomationRule, now time.Time) error {
+	next, err := nextAutomationScheduleRun(rule, now)
+	if err != nil {
+		return s.stop(ctx, rule, now, err)
+	}
+	rule.NextRunAt, rule.LastRunAt = &next, &now
+	if err := s.automations.ruleRepo.UpdateSchedule(ctx, rule); err != nil {
+		return err
+	}
+
+	return s.automations.execute(ctx, automationChainFromContext(ctx), rule, &models.RecordRevision{AppID: rule.AppID})
+}
+
+// runDateReached 前回の確認から今回までの間に、契機とするフィールドの値から指定した日数が経過したレコードに対してルールを実行する
+func (s *AutomationScheduler) runDateReached(ctx context.Context, rule *models.AutomationRule, now time.Time) error {
+	app, fields, err := s.automations.getWritableApp(ctx, rule.AppID)
+	if err != nil {
+		if errors.Is(err, ErrAppNotFound) || errors.Is(err, ErrExternalAppReadOnly) {
+			return s.stop(ctx, rule, now, err)
+		}
+		return err
+	}
+	field, ok := fieldsByCode(fields)[rule.TriggerField]
+	if !ok || !field.HasColumn() || automationDateType(field) == "" {
+		return s.stop(ctx, rule, now, fmt.Errorf("契機とするフィールド %q が存在しません", rule.TriggerField))
+	}
+	loc, err := time.LoadLocation(rule.Timezone)
+	if err != nil {
+		return s.stop(ctx, rule, now, err)
+	}
+
+	from := now
+	if rule.LastRunAt != nil {
+		from = *rule.LastRunAt
+	}
+	next := now.Add(automationDateCheckInterval)
+	rule.NextRunAt, rule.LastRunAt = &next, &now
+	if err := s.automations.ruleRepo.UpdateSchedule(ctx, rule); err != nil {
+		return err
+	}
+
+	filters := automationDateWindow(field, rule.OffsetDays, loc, from, now)
+	if filters == nil {
+		return nil
+	}
+	// アクションでレコードを書き込むため、対象のレコードを先に全て読み込んでから実行する
+	var records []models.RecordResponse
+	opts := repositories.RecordQueryOptions{Sort: "id", Order: "asc", Filters: filters}
+	err = s.automations.dynamicQuery.StreamRecords(ctx, app.TableName, fields, opts, func(record *models.RecordResponse) error {
+		records = append(records, *record)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for i := range records {
+		record := &records[i]
+		if !automationConditionsMatch(rule.Conditions, record.Data) {
+			continue
+		}
+		revision := &models.RecordRevision{AppID: app.ID, RecordID: record.ID, Snapshot: record.Data}
+		if err := s.automations.execute(ctx, automationChainFromContext(ctx), rule, revision); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// stop 設定どおりに実行できなくなったルールの予約を取り消し、理由を実行ログに記録する
+// ルールを編集して保存し直すと再び予約される
+func (s *AutomationScheduler) stop(ctx context.Context, rule *models.AutomationRule, now time.Time, reason error) error {
+	rule.NextRunAt, rule.LastRunAt = nil, &now
+	if err := s.automations.ruleRepo.UpdateSchedule(ctx, rule); err != nil {
+		return err
+	}
+	return s.automations.runRepo.Create(ctx, &models.AutomationRun{
+		RuleID:    rule.ID,
+		AppID:     rule.AppID,
+		Status:    models.AutomationRunFailed,
+		Error:     truncateAutomationError("ルールを実行できないため、定期実行を停止しました: " + reason.Error()),
+		CreatedAt: now,
+	})
+}
+
+// automationDateWindow fromより後からtoまでの間に、フィールドの値から offsetDays 日が経過したレコードを絞り込むフィルターを返す
+// 日付フィールドはルールのタイムゾーンでの日付で、日時フィールド（UTCで保存）は時刻で比較する。対象がない場合はnilを返す
+func automationDateWindow(field *models.AppField, offsetDays int, loc *time.Location, from, to time.Time) []models.FilterItem {
+	layout := "2006-01-02T15:04:05"
+	from, to = from.UTC(), to.UTC()
+	if automationDateType(field) == models.FieldTypeDate {
+		layout = "2006-01-02"
+		from, to = from.In(loc), to.In(loc)
+	}
+
+	start := from.AddDate(0, 0, -offsetDays).Format(layout)
+	end := to.AddDate(0, 0, -offsetDays).Format(layout)
+	if start >= end {
+		return nil
+	}
+	return []models.FilterItem{
+		{Field: field.FieldCode, Operator: "gt", Value: start},
+		{Field: field.FieldCode, Operator: "lte", Value: end},
+	}
+}
@@ -0,0 +1,161 @@
+package services_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"nocode-app/backend/internal/models"
+	"nocode-app/backend/internal/repositories"
+	"nocode-app/backend/internal/services"
+	"nocode-app/backend/internal/testhelpers/mocks"
+)
+
+func TestAutomationScheduler_ProcessDue(t *testing.T) {
+	ctx := context.Background()
+	// 2026-10-19（月）9:00 JST
+	now := time.Date(2026, 10, 19, 0, 0, 30, 0, time.UTC)
+
+	t.Run("does nothing without lock", func(t *testing.T) {
+		mockRuleRepo := new(mocks.MockAutomationRuleRepository)
+		mockAppRepo := new(mocks.MockAppRepository)
+		locker := new(mocks.MockAdvisoryLocker)
+		locker.On("TryWithLock", mock.Anything, mock.Anything).Return(false, nil)
+		scheduler := services.NewAutomationScheduler(services.NewAutomationService(mockRuleRepo, new(mocks.MockAutomationRunRepository), mockAppRepo, new(mocks.MockFieldRepository), new(mocks.MockDynamicQueryExecutor), new(mocks.MockRecordRevisionRepository), new(mocks.MockWebhookRepository), newTestTransactor(), newTestWebhookPublisher(), newTestPermissionService(mockAppRepo), newTestAttachmentManager()), locker)
+
+		n, err := scheduler.ProcessDue(ctx, now)
+		require.NoError(t, err)
+		assert.Zero(t, n)
+		mockRuleRepo.AssertNotCalled(t, "GetDue", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("runs scheduled rule and reserves next run", func(t *testing.T) {
+		mockRuleRepo := new(mocks.MockAutomationRuleRepository)
+		mockRunRepo := new(mocks.MockAutomationRunRepository)
+		mockAppRepo := new(mocks.MockAppRepository)
+		mockFieldRepo := new(mocks.MockFieldRepository)
+		mockDynamicQuery := new(mocks.MockDynamicQueryExecutor)
+		mockRevisionRepo := new(mocks.MockRecordRevisionRepository)
+		locker := new(mocks.MockAdvisoryLocker)
+		locker.On("TryWithLock", mock.Anything, mock.Anything).Return(true, nil)
+		scheduler := services.NewAutomationScheduler(services.NewAutomationService(mockRuleRepo, mockRunRepo, mockAppRepo, mockFieldRepo, mockDynamicQuery, mockRevisionRepo, new(mocks.MockWebhookRepository), newTestTransactor(), newTestWebhookPublisher(), newTestPermissionService(mockAppRepo), newTestAttachmentManager()), locker)
+
+		adminID := uint64(5)
+		fields := []models.AppField{{ID: 9, AppID: 2, FieldCode: "title", FieldName: "件名", FieldType: "text"}}
+		mockRuleRepo.On("GetDue", mock.Anything, now, mock.Anything).Return([]models.AutomationRule{{
+			ID: 6, AppID: 1, Name: "週報", Trigger: models.AutomationTriggerSchedule, Schedule: "0 9 * * 1", Timezone: "Asia/Tokyo",
+			IsActive: true, CreatedBy: &adminID,
+			Actions: []models.AutomationAction{{Type: models.AutomationActionCreateRecord, AppID: 2, Values: models.RecordData{"title": "週報"}}},
+		}}, nil)
+
+		var saved time.Time
+		mockRuleRepo.On("UpdateSchedule", mock.Anything, mock.AnythingOfType("*models.AutomationRule")).Return(nil).Run(func(args mock.Arguments) {
+			saved = *args.Get(1).(*models.AutomationRule).NextRunAt
+		}).Once()
+		mockAppRepo.On("GetByID", mock.Anything, uint64(2)).Return(&models.App{ID: 2, TableName: "app_data_2"}, nil)
+		mockFieldRepo.On("GetByAppID", mock.Anything, uint64(2)).Return(fields, nil)
+		// 作成者はルールの登録者
+		mockDynamicQuery.On("InsertRecord", mock.Anything, "app_data_2", models.RecordData{"title": "週報"}, adminID).Return(uint64(30), nil)
+		mockDynamicQuery.On("GetRecordByID", mock.Anything, "app_data_2", fields, uint64(30)).
+			Return(&models.RecordResponse{ID: 30, Data: models.RecordData{"title": "週報"}}, nil)
+		mockRevisionRepo.On("Create", mock.Anything, mock.Anything).Return(nil)
+		mockRuleRepo.On("GetByAppID", mock.Anything, uint64(2)).Return([]models.AutomationRule{}, nil)
+		mockRunRepo.On("Create", mock.Anything, mock.MatchedBy(func(run *models.AutomationRun) bool {
+			return run.RuleID == 6 && run.RecordID == 0 && run.Status == models.AutomationRunSucceeded
+		})).Return(nil).Once()
+
+		n, err := scheduler.ProcessDue(ctx, now)
+		require.NoError(t, err)
+		assert.Equal(t, 1, n)
+		// 次回は翌週の月曜9:00 JST
+		assert.Equal(t, time.Date(2026, 10, 26, 0, 0, 0, 0, time.UTC), saved)
+		mockDynamicQuery.AssertExpectations(t)
+		mockRunRepo.AssertExpectations(t)
+	})
+
+	t.Run("runs date reached rule for records in window", func(t *testing.T) {
+		mockRuleRepo := new(mocks.MockAutomationRuleRepository)
+		mockRunRepo := new(mocks.MockAutomationRunRepository)
+		mockAppRepo := new(mocks.MockAppRepository)
+		mockFieldRepo := new(mocks.MockFieldRepository)
+		mockDynamicQuery := new(mocks.MockDynamicQueryExecutor)
+		mockPublisher := newTestWebhookPublisher()
+		locker := new(mocks.MockAdvisoryLocker)
+		locker.On("TryWithLock", mock.Anything, mock.Anything).Return(true, nil)
+		scheduler := services.NewAutomationScheduler(services.NewAutomationService(mockRuleRepo, mockRunRepo, mockAppRepo, mockFieldRepo, mockDynamicQuery, new(mocks.MockRecordRevisionRepository), new(mocks.MockWebhookRepository), newTestTransactor(), mockPublisher, newTestPermissionService(mockAppRepo), newTestAttachmentManager()), locker)
+
+		fields := automationTestFields()
+		// 日本時間で日付が 10/19 に変わった直後
+		now := time.Date(2026, 10, 18, 15, 0, 30, 0, time.UTC)
+		last := now.Add(-time.Minute)
+		mockRuleRepo.On("GetDue", mock.Anything, now, mock.Anything).Return([]models.AutomationRule{{
+			ID: 6, AppID: 1, Trigger: models.AutomationTriggerDateReached, TriggerField: "due", OffsetDays: 3, Timezone: "Asia/Tokyo",
+			IsActive: true, LastRunAt: &last,
+			Conditions: []models.FilterItem{{Field: "status", Operator: "ne", Value: "done"}},
+			Actions:    []models.AutomationAction{{Type: models.AutomationActionCallWebhook, WebhookID: 3}},
+		}}, nil)
+		mockRuleRepo.On("UpdateSchedule", mock.Anything, mock.MatchedBy(func(rule *models.AutomationRule) bool {
+			return rule.LastRunAt.Equal(now) && rule.NextRunAt.Equal(now.Add(time.Minute))
+		})).Return(nil).Once()
+		mockAppRepo.On("GetByID", mock.Anything, uint64(1)).Return(&models.App{ID: 1, TableName: "app_data_1"}, nil)
+		mockFieldRepo.On("GetByAppID", mock.Anything, uint64(1)).Return(fields, nil)
+
+		// 期限が 10/16 のレコードが3日経過した
+		mockDynamicQuery.On("StreamRecords", mock.Anything, "app_data_1", fields, repositories.RecordQueryOptions{
+			Sort: "id", Order: "asc", Filters: []models.FilterItem{
+				{Field: "due", Operator: "gt", Value: "2026-10-15"},
+				{Field: "due", Operator: "lte", Value: "2026-10-16"},
+			},
+		}, mock.Anything).Return(nil).Run(func(args mock.Arguments) {
+			fn := args.Get(4).(repositories.RecordStreamFunc)
+			require.NoError(t, fn(&models.RecordResponse{ID: 7, Data: models.RecordData{"due": "2026-10-16", "status": "open"}}))
+			require.NoError(t, fn(&models.RecordResponse{ID: 8, Data: models.RecordData{"due": "2026-10-16", "status": "done"}}))
+		})
+		mockPublisher.On("PublishTo", mock.Anything, uint64(3), mock.MatchedBy(func(event models.WebhookEvent) bool {
+			return event.Data.(models.WebhookAutomationData).RecordID == 7
+		})).Return(nil).Once()
+		mockRunRepo.On("Create", mock.Anything, mock.MatchedBy(func(run *models.AutomationRun) bool {
+			return run.RecordID == 7 && run.Status == models.AutomationRunSucceeded
+		})).Return(nil).Once()
+
+		n, err := scheduler.ProcessDue(ctx, now)
+		require.NoError(t, err)
+		assert.Equal(t, 1, n)
+		mockPublisher.AssertExpectations(t)
+		mockRunRepo.AssertExpectations(t)
+	})
+
+	t.Run("stops rule whose trigger field was deleted", func(t *testing.T) {
+		mockRuleRepo := new(mocks.MockAutomationRuleRepository)
+		mockRunRepo := new(mocks.MockAutomationRunRepository)
+		mockAppRepo := new(mocks.MockAppRepository)
+		mockFieldRepo := new(mocks.MockFieldRepository)
+		mockDynamicQuery := new(mocks.MockDynamicQueryExecutor)
+		locker := new(mocks.MockAdvisoryLocker)
+		locker.On("TryWithLock", mock.Anything, mock.Anything).Return(true, nil)
+		scheduler := services.NewAutomationScheduler(services.NewAutomationService(mockRuleRepo, mockRunRepo, mockAppRepo, mockFieldRepo, mockDynamicQuery, new(mocks.MockRecordRevisionRepository), new(mocks.MockWebhookRepository), newTestTransactor(), newTestWebhookPublisher(), newTestPermissionService(mockAppRepo), newTestAttachmentManager()), locker)
+
+		mockRuleRepo.On("GetDue", mock.Anything, now, mock.Anything).Return([]models.AutomationRule{{
+			ID: 6, AppID: 1, Trigger: models.AutomationTriggerDateReached, TriggerField: "deadline", Timezone: "UTC", IsActive: true,
+			Actions: []models.AutomationAction{{Type: models.AutomationActionCallWebhook, WebhookID: 3}},
+		}}, nil)
+		mockAppRepo.On("GetByID", mock.Anything, uint64(1)).Return(&models.App{ID: 1, TableName: "app_data_1"}, nil)
+		mockFieldRepo.On("GetByAppID", mock.Anything, uint64(1)).Return(automationTestFields(), nil)
+		mockRuleRepo.On("UpdateSchedule", mock.Anything, mock.MatchedBy(func(rule *models.AutomationRule) bool {
+			return rule.NextRunAt == nil
+		})).Return(nil).Once()
+		mockRunRepo.On("Create", mock.Anything, mock.MatchedBy(func(run *models.AutomationRun) bool {
+			return run.Status == models.AutomationRunFailed
+		})).Return(nil).Once()
+
+		_, err := scheduler.ProcessDue(ctx, now)
+		require.NoError(t, err)
+		mockRuleRepo.AssertExpectations(t)
+		mockRunRepo.AssertExpectations(t)
+		mockDynamicQuery.AssertNotCalled(t, "StreamRecords", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+}
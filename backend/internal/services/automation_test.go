@@ -0,0 +1,611 @@
+package services_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"nocode-app/backend/internal/models"
+	"nocode-app/backend/internal/services"
+	"nocode-app/backend/internal/testhelpers/mocks"
+)
+
+func automationTestFields() []models.AppField {
+	return []models.AppField{
+		{ID: 1, AppID: 1, FieldCode: "name", FieldName: "名前", FieldType: "text", Required: true},
+		{ID: 2, AppID: 1, FieldCode: "amount", FieldName: "金額", FieldType: "number"},
+		{ID: 3, AppID: 1, FieldCode: "status", FieldName: "状態", FieldType: "text"},
+		{ID: 4, AppID: 1, FieldCode: "total", FieldName: "合計", FieldType: "formula"},
+		{ID: 5, AppID: 1, FieldCode: "due", FieldName: "期限", FieldType: "date"},
+	}
+}
+
+func TestAutomationService_CreateRule(t *testing.T) {
+	ctx := systemContext()
+
+	t.Run("stores valid rule", func(t *testing.T) {
+		mockRuleRepo := new(mocks.MockAutomationRuleRepository)
+		mockAppRepo := new(mocks.MockAppRepository)
+		mockFieldRepo := new(mocks.MockFieldRepository)
+		mockWebhookRepo := new(mocks.MockWebhookRepository)
+
+		mockAppRepo.On("GetByID", ctx, uint64(1)).Return(&models.App{ID: 1, TableName: "app_data_1"}, nil)
+		mockAppRepo.On("GetByID", ctx, uint64(2)).Return(&models.App{ID: 2, TableName: "app_data_2"}, nil)
+		mockFieldRepo.On("GetByAppID", ctx, uint64(1)).Return(automationTestFields(), nil)
+		mockFieldRepo.On("GetByAppID", ctx, uint64(2)).Return([]models.AppField{
+			{ID: 9, AppID: 2, FieldCode: "title", FieldName: "件名", FieldType: "text", Required: true},
+		}, nil)
+		mockWebhookRepo.On("GetByID", ctx, uint64(3)).Return(&models.Webhook{ID: 3, AppID: 1}, nil)
+		mockRuleRepo.On("Create", ctx, mock.AnythingOfType("*models.AutomationRule")).Return(nil).Run(func(args mock.Arguments) {
+			args.Get(1).(*models.AutomationRule).ID = 6
+		})
+
+		service := services.NewAutomationService(mockRuleRepo, new(mocks.MockAutomationRunRepository), mockAppRepo, mockFieldRepo, new(mocks.MockDynamicQueryExecutor), new(mocks.MockRecordRevisionRepository), mockWebhookRepo, newTestTransactor(), newTestWebhookPublisher(), newTestPermissionService(mockAppRepo), newTestAttachmentManager())
+
+		rule, err := service.CreateRule(ctx, 1, 5, &models.CreateAutomationRuleRequest{
+			Name:         "高額案件",
+			Trigger:      models.AutomationTriggerFieldChanged,
+			TriggerField: "amount",
+			Conditions:   []models.FilterItem{{Field: "amount", Operator: "gte", Value: "100"}},
+			Actions: []models.AutomationAction{
+				{Type: models.AutomationActionUpdateRecord, Values: models.RecordData{"status": "review"}},
+				// 必須フィールドはテンプレートで値を設定する
+				{Type: models.AutomationActionCreateRecord, AppID: 2, Values: models.RecordData{"title": "{{name}}"}},
+				{Type: models.AutomationActionCallWebhook, WebhookID: 3},
+			},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, uint64(6), rule.ID)
+		assert.Equal(t, "amount", rule.TriggerField)
+		assert.True(t, rule.IsActive)
+		require.NotNil(t, rule.CreatedBy)
+		assert.Equal(t, uint64(5), *rule.CreatedBy)
+	})
+
+	tests := []struct {
+		name string
+		req  models.CreateAutomationRuleRequest
+	}{
+		{
+			name: "unknown trigger field",
+			req: models.CreateAutomationRuleRequest{Trigger: models.AutomationTriggerFieldChanged, TriggerField: "missing",
+				Actions: []models.AutomationAction{{Type: models.AutomationActionUpdateRecord, Values: models.RecordData{"status": "x"}}}},
+		},
+		{
+			name: "unknown condition field",
+			req: models.CreateAutomationRuleRequest{Trigger: models.AutomationTriggerRecordCreated,
+				Conditions: []models.FilterItem{{Field: "missing", Operator: "eq", Value: "x"}},
+				Actions:    []models.AutomationAction{{Type: models.AutomationActionUpdateRecord, Values: models.RecordData{"status": "x"}}}},
+		},
+		{
+			name: "unsupported operator",
+			req: models.CreateAutomationRuleRequest{Trigger: models.AutomationTriggerRecordCreated,
+				Conditions: []models.FilterItem{{Field: "status", Operator: "in", Value: "a,b"}},
+				Actions:    []models.AutomationAction{{Type: models.AutomationActionUpdateRecord, Values: models.RecordData{"status": "x"}}}},
+		},
+		{
+			name: "computed field value",
+			req: models.CreateAutomationRuleRequest{Trigger: models.AutomationTriggerRecordCreated,
+				Actions: []models.AutomationAction{{Type: models.AutomationActionUpdateRecord, Values: models.RecordData{"total": 1}}}},
+		},
+		{
+			name: "invalid value type",
+			req: models.CreateAutomationRuleRequest{Trigger: models.AutomationTriggerRecordCreated,
+				Actions: []models.AutomationAction{{Type: models.AutomationActionUpdateRecord, Values: models.RecordData{"amount": "many"}}}},
+		},
+		{
+			name: "unknown template source",
+			req: models.CreateAutomationRuleRequest{Trigger: models.AutomationTriggerRecordCreated,
+				Actions: []models.AutomationAction{{Type: models.AutomationActionUpdateRecord, Values: models.RecordData{"status": "{{missing}}"}}}},
+		},
+		{
+			name: "missing required field of created record",
+			req: models.CreateAutomationRuleRequest{Trigger: models.AutomationTriggerRecordCreated,
+				Actions: []models.AutomationAction{{Type: models.AutomationActionCreateRecord, AppID: 1, Values: models.RecordData{"status": "x"}}}},
+		},
+		{
+			name: "webhook of another app",
+			req: models.CreateAutomationRuleRequest{Trigger: models.AutomationTriggerRecordCreated,
+				Actions: []models.AutomationAction{{Type: models.AutomationActionCallWebhook, WebhookID: 4}}},
+		},
+		{
+			name: "invalid schedule",
+			req: models.CreateAutomationRuleRequest{Trigger: models.AutomationTriggerSchedule, Schedule: "every monday",
+				Actions: []models.AutomationAction{{Type: models.AutomationActionCreateRecord, AppID: 1, Values: models.RecordData{"name": "週報"}}}},
+		},
+		{
+			name: "unknown timezone",
+			req: models.CreateAutomationRuleRequest{Trigger: models.AutomationTriggerSchedule, Schedule: "0 9 * * 1", Timezone: "Mars/Olympus",
+				Actions: []models.AutomationAction{{Type: models.AutomationActionCreateRecord, AppID: 1, Values: models.RecordData{"name": "週報"}}}},
+		},
+		{
+			name: "conditions on schedule",
+			req: models.CreateAutomationRuleRequest{Trigger: models.AutomationTriggerSchedule, Schedule: "0 9 * * 1",
+				Conditions: []models.FilterItem{{Field: "status", Operator: "eq", Value: "x"}},
+				Actions:    []models.AutomationAction{{Type: models.AutomationActionCreateRecord, AppID: 1, Values: models.RecordData{"name": "週報"}}}},
+		},
+		{
+			name: "record update on schedule",
+			req: models.CreateAutomationRuleRequest{Trigger: models.AutomationTriggerSchedule, Schedule: "0 9 * * 1",
+				Actions: []models.AutomationAction{{Type: models.AutomationActionUpdateRecord, Values: models.RecordData{"status": "x"}}}},
+		},
+		{
+			name: "template on schedule",
+			req: models.CreateAutomationRuleRequest{Trigger: models.AutomationTriggerSchedule, Schedule: "0 9 * * 1",
+				Actions: []models.AutomationAction{{Type: models.AutomationActionCreateRecord, AppID: 1, Values: models.RecordData{"name": "{{id}}"}}}},
+		},
+		{
+			name: "date reached on text field",
+			req: models.CreateAutomationRuleRequest{Trigger: models.AutomationTriggerDateReached, TriggerField: "status", OffsetDays: 3,
+				Actions: []models.AutomationAction{{Type: models.AutomationActionUpdateRecord, Values: models.RecordData{"status": "overdue"}}}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRuleRepo := new(mocks.MockAutomationRuleRepository)
+			mockAppRepo := new(mocks.MockAppRepository)
+			mockFieldRepo := new(mocks.MockFieldRepository)
+			mockWebhookRepo := new(mocks.MockWebhookRepository)
+
+			mockAppRepo.On("GetByID", ctx, uint64(1)).Return(&models.App{ID: 1, TableName: "app_data_1"}, nil)
+			mockFieldRepo.On("GetByAppID", ctx, uint64(1)).Return(automationTestFields(), nil)
+			mockWebhookRepo.On("GetByID", ctx, uint64(4)).Return(&models.Webhook{ID: 4, AppID: 2}, nil).Maybe()
+
+			tt.req.Name = "ルール"
+
+			service := services.NewAutomationService(mockRuleRepo, new(mocks.MockAutomationRunRepository), mockAppRepo, mockFieldRepo, new(mocks.MockDynamicQueryExecutor), new(mocks.MockRecordRevisionRepository), mockWebhookRepo, newTestTransactor(), newTestWebhookPublisher(), newTestPermissionService(mockAppRepo), newTestAttachmentManager())
+
+			_, err := service.CreateRule(ctx, 1, 5, &tt.req)
+			assert.ErrorIs(t, err, services.ErrInvalidAutomationRule)
+			mockRuleRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+		})
+	}
+
+	t.Run("schedules time based rules", func(t *testing.T) {
+		mockRuleRepo := new(mocks.MockAutomationRuleRepository)
+		mockAppRepo := new(mocks.MockAppRepository)
+		mockFieldRepo := new(mocks.MockFieldRepository)
+
+		mockAppRepo.On("GetByID", ctx, uint64(1)).Return(&models.App{ID: 1, TableName: "app_data_1"}, nil)
+		mockFieldRepo.On("GetByAppID", ctx, uint64(1)).Return(automationTestFields(), nil)
+		mockRuleRepo.On("Create", ctx, mock.AnythingOfType("*models.AutomationRule")).Return(nil)
+
+		service := services.NewAutomationService(mockRuleRepo, new(mocks.MockAutomationRunRepository), mockAppRepo, mockFieldRepo, new(mocks.MockDynamicQueryExecutor), new(mocks.MockRecordRevisionRepository), new(mocks.MockWebhookRepository), newTestTransactor(), newTestWebhookPublisher(), newTestPermissionService(mockAppRepo), newTestAttachmentManager())
+
+		weekly, err := service.CreateRule(ctx, 1, 5, &models.CreateAutomationRuleRequest{
+			Name: "週報", Trigger: models.AutomationTriggerSchedule, Schedule: "0 9 * * mon", Timezone: "Asia/Tokyo",
+			Actions: []models.AutomationAction{{Type: models.AutomationActionCreateRecord, AppID: 1, Values: models.RecordData{"name": "週報"}}},
+		})
+		require.NoError(t, err)
+		require.NotNil(t, weekly.NextRunAt)
+		assert.Nil(t, weekly.LastRunAt)
+		// 月曜9時（日本時間）は月曜0時（UTC）
+		assert.Equal(t, time.Monday, weekly.NextRunAt.Weekday())
+		assert.Equal(t, 0, weekly.NextRunAt.Hour())
+		assert.True(t, weekly.NextRunAt.After(time.Now()))
+
+		overdue, err := service.CreateRule(ctx, 1, 5, &models.CreateAutomationRuleRequest{
+			Name: "期限超過", Trigger: models.AutomationTriggerDateReached, TriggerField: "due", OffsetDays: 3,
+			Conditions: []models.FilterItem{{Field: "status", Operator: "ne", Value: "done"}},
+			Actions:    []models.AutomationAction{{Type: models.AutomationActionUpdateRecord, Values: models.RecordData{"status": "overdue"}}},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "UTC", overdue.Timezone)
+		require.NotNil(t, overdue.NextRunAt)
+		require.NotNil(t, overdue.LastRunAt)
+		// 登録した時点から日付の経過を確認する
+		assert.True(t, overdue.NextRunAt.After(*overdue.LastRunAt))
+	})
+
+	t.Run("external app", func(t *testing.T) {
+		mockAppRepo := new(mocks.MockAppRepository)
+
+		mockAppRepo.On("GetByID", ctx, uint64(1)).Return(&models.App{ID: 1, IsExternal: true}, nil)
+
+		service := services.NewAutomationService(new(mocks.MockAutomationRuleRepository), new(mocks.MockAutomationRunRepository), mockAppRepo, new(mocks.MockFieldRepository), new(mocks.MockDynamicQueryExecutor), new(mocks.MockRecordRevisionRepository), new(mocks.MockWebhookRepository), newTestTransactor(), newTestWebhookPublisher(), newTestPermissionService(mockAppRepo), newTestAttachmentManager())
+
+		_, err := service.CreateRule(ctx, 1, 5, &models.CreateAutomationRuleRequest{Name: "ルール", Trigger: models.AutomationTriggerRecordCreated,
+			Actions: []models.AutomationAction{{Type: models.AutomationActionCallWebhook, WebhookID: 3}}})
+		assert.ErrorIs(t, err, services.ErrInvalidAutomationRule)
+	})
+}
+
+func TestAutomationService_UpdateRule_RuleOfAnotherApp(t *testing.T) {
+	ctx := systemContext()
+
+	mockRuleRepo := new(mocks.MockAutomationRuleRepository)
+	mockAppRepo := new(mocks.MockAppRepository)
+
+	mockAppRepo.On("GetByID", ctx, uint64(1)).Return(&models.App{ID: 1}, nil)
+	mockRuleRepo.On("GetByID", ctx, uint64(6)).Return(&models.AutomationRule{ID: 6, AppID: 2}, nil)
+
+	service := services.NewAutomationService(mockRuleRepo, new(mocks.MockAutomationRunRepository), mockAppRepo, new(mocks.MockFieldRepository), new(mocks.MockDynamicQueryExecutor), new(mocks.MockRecordRevisionRepository), new(mocks.MockWebhookRepository), newTestTransactor(), newTestWebhookPublisher(), newTestPermissionService(mockAppRepo), newTestAttachmentManager())
+
+	_, err := service.UpdateRule(ctx, 1, 6, &models.UpdateAutomationRuleRequest{Name: "ルール", Trigger: models.AutomationTriggerRecordCreated})
+	assert.ErrorIs(t, err, services.ErrAutomationRuleNotFound)
+	mockRuleRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+}
+
+func TestAutomationService_UpdateRule_Reschedules(t *testing.T) {
+	ctx := systemContext()
+	next := time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC)
+	existing := func() *models.AutomationRule {
+		return &models.AutomationRule{ID: 6, AppID: 1, Name: "週報", Trigger: models.AutomationTriggerSchedule,
+			Schedule: "0 9 * * 1", Timezone: "Asia/Tokyo", IsActive: true, NextRunAt: &next}
+	}
+	req := models.UpdateAutomationRuleRequest{Name: "週報（全社）", Trigger: models.AutomationTriggerSchedule,
+		Schedule: "0 9 * * 1", Timezone: "Asia/Tokyo",
+		Actions: []models.AutomationAction{{Type: models.AutomationActionCreateRecord, AppID: 1, Values: models.RecordData{"name": "週報"}}}}
+
+	t.Run("keeps schedule when only name changes", func(t *testing.T) {
+		mockRuleRepo := new(mocks.MockAutomationRuleRepository)
+		mockAppRepo := new(mocks.MockAppRepository)
+		mockFieldRepo := new(mocks.MockFieldRepository)
+
+		mockAppRepo.On("GetByID", ctx, uint64(1)).Return(&models.App{ID: 1, TableName: "app_data_1"}, nil)
+		mockFieldRepo.On("GetByAppID", ctx, uint64(1)).Return(automationTestFields(), nil)
+		mockRuleRepo.On("GetByID", ctx, uint64(6)).Return(existing(), nil)
+		mockRuleRepo.On("Update", ctx, mock.AnythingOfType("*models.AutomationRule")).Return(nil)
+
+		service := services.NewAutomationService(mockRuleRepo, new(mocks.MockAutomationRunRepository), mockAppRepo, mockFieldRepo, new(mocks.MockDynamicQueryExecutor), new(mocks.MockRecordRevisionRepository), new(mocks.MockWebhookRepository), newTestTransactor(), newTestWebhookPublisher(), newTestPermissionService(mockAppRepo), newTestAttachmentManager())
+
+		rule, err := service.UpdateRule(ctx, 1, 6, &req)
+		require.NoError(t, err)
+		assert.Equal(t, &next, rule.NextRunAt)
+		mockRuleRepo.AssertNotCalled(t, "UpdateSchedule", mock.Anything, mock.Anything)
+	})
+
+	t.Run("reschedules when schedule changes", func(t *testing.T) {
+		mockRuleRepo := new(mocks.MockAutomationRuleRepository)
+		mockAppRepo := new(mocks.MockAppRepository)
+		mockFieldRepo := new(mocks.MockFieldRepository)
+
+		mockAppRepo.On("GetByID", ctx, uint64(1)).Return(&models.App{ID: 1, TableName: "app_data_1"}, nil)
+		mockFieldRepo.On("GetByAppID", ctx, uint64(1)).Return(automationTestFields(), nil)
+		mockRuleRepo.On("GetByID", ctx, uint64(6)).Return(existing(), nil)
+		mockRuleRepo.On("Update", ctx, mock.AnythingOfType("*models.AutomationRule")).Return(nil)
+		mockRuleRepo.On("UpdateSchedule", ctx, mock.MatchedBy(func(rule *models.AutomationRule) bool {
+			return rule.NextRunAt != nil && rule.NextRunAt.Weekday() == time.Friday
+		})).Return(nil).Once()
+
+		changed := req
+		changed.Schedule = "0 9 * * fri"
+
+		service := services.NewAutomationService(mockRuleRepo, new(mocks.MockAutomationRunRepository), mockAppRepo, mockFieldRepo, new(mocks.MockDynamicQueryExecutor), new(mocks.MockRecordRevisionRepository), new(mocks.MockWebhookRepository), newTestTransactor(), newTestWebhookPublisher(), newTestPermissionService(mockAppRepo), newTestAttachmentManager())
+
+		_, err := service.UpdateRule(ctx, 1, 6, &changed)
+		require.NoError(t, err)
+		mockRuleRepo.AssertExpectations(t)
+	})
+}
+
+func TestAutomationService_Run(t *testing.T) {
+	ctx := systemContext()
+	app := &models.App{ID: 1, TableName: "app_data_1"}
+	created := models.RecordRevision{AppID: 1, RecordID: 7, Action: models.RevisionActionCreate,
+		Snapshot: models.RecordData{"name": "A社", "amount": "150", "status": nil}}
+
+	t.Run("updates record when conditions match", func(t *testing.T) {
+		mockRuleRepo := new(mocks.MockAutomationRuleRepository)
+		mockRunRepo := new(mocks.MockAutomationRunRepository)
+		mockAppRepo := new(mocks.MockAppRepository)
+		mockFieldRepo := new(mocks.MockFieldRepository)
+		mockDynamicQuery := new(mocks.MockDynamicQueryExecutor)
+		mockRevisionRepo := new(mocks.MockRecordRevisionRepository)
+		mockPublisher := newTestWebhookPublisher()
+
+		fields := automationTestFields()
+		mockRuleRepo.On("GetByAppID", mock.Anything, uint64(1)).Return([]models.AutomationRule{{
+			ID: 6, AppID: 1, Trigger: models.AutomationTriggerRecordCreated, IsActive: true,
+			Conditions: []models.FilterItem{{Field: "amount", Operator: "gt", Value: "100"}, {Field: "name", Operator: "like", Value: "社"}},
+			Actions: []models.AutomationAction{
+				{Type: models.AutomationActionUpdateRecord, Values: models.RecordData{"status": "{{name}}様 #{{id}}", "amount": "{{id}}"}},
+			},
+		}}, nil)
+		mockAppRepo.On("GetByID", mock.Anything, uint64(1)).Return(app, nil)
+		mockFieldRepo.On("GetByAppID", mock.Anything, uint64(1)).Return(fields, nil)
+		mockDynamicQuery.On("GetRecordByID", mock.Anything, "app_data_1", fields, uint64(7)).
+			Return(&models.RecordResponse{ID: 7, Data: models.RecordData{"status": nil}}, nil).Once()
+		mockDynamicQuery.On("UpdateRecord", mock.Anything, "app_data_1", uint64(7), models.RecordData{"status": "A社様 #7", "amount": float64(7)}).Return(nil)
+		mockDynamicQuery.On("GetRecordByID", mock.Anything, "app_data_1", fields, uint64(7)).
+			Return(&models.RecordResponse{ID: 7, Data: models.RecordData{"status": "done"}}, nil).Once()
+		mockRevisionRepo.On("Create", mock.Anything, mock.MatchedBy(func(r *models.RecordRevision) bool {
+			return r.Action == models.RevisionActionUpdate && r.ChangedBy == nil
+		})).Return(nil)
+
+		var run *models.AutomationRun
+		mockRunRepo.On("Create", ctx, mock.AnythingOfType("*models.AutomationRun")).Return(nil).Run(func(args mock.Arguments) {
+			run = args.Get(1).(*models.AutomationRun)
+		})
+
+		service := services.NewAutomationService(mockRuleRepo, mockRunRepo, mockAppRepo, mockFieldRepo, mockDynamicQuery, mockRevisionRepo, new(mocks.MockWebhookRepository), newTestTransactor(), mockPublisher, newTestPermissionService(mockAppRepo), newTestAttachmentManager())
+
+		require.NoError(t, service.Run(ctx, created))
+
+		require.NotNil(t, run)
+		assert.Equal(t, models.AutomationRunSucceeded, run.Status)
+		assert.Equal(t, uint64(6), run.RuleID)
+		assert.Equal(t, uint64(7), run.RecordID)
+		assert.Zero(t, run.Depth)
+		mockDynamicQuery.AssertExpectations(t)
+		// アクションによる変更もWebhookに通知する
+		mockPublisher.AssertCalled(t, "Publish", mock.Anything, mock.Anything)
+	})
+
+	t.Run("skips when conditions do not match", func(t *testing.T) {
+		mockRuleRepo := new(mocks.MockAutomationRuleRepository)
+		mockRunRepo := new(mocks.MockAutomationRunRepository)
+		mockPublisher := newTestWebhookPublisher()
+
+		mockRuleRepo.On("GetByAppID", ctx, uint64(1)).Return([]models.AutomationRule{
+			{ID: 6, AppID: 1, Trigger: models.AutomationTriggerRecordCreated, IsActive: true,
+				Conditions: []models.FilterItem{{Field: "amount", Operator: "gt", Value: "1000"}},
+				Actions:    []models.AutomationAction{{Type: models.AutomationActionCallWebhook, WebhookID: 3}}},
+			{ID: 7, AppID: 1, Trigger: models.AutomationTriggerRecordUpdated, IsActive: true,
+				Actions: []models.AutomationAction{{Type: models.AutomationActionCallWebhook, WebhookID: 3}}},
+			{ID: 8, AppID: 1, Trigger: models.AutomationTriggerRecordCreated, IsActive: false,
+				Actions: []models.AutomationAction{{Type: models.AutomationActionCallWebhook, WebhookID: 3}}},
+		}, nil)
+
+		service := services.NewAutomationService(mockRuleRepo, mockRunRepo, new(mocks.MockAppRepository), new(mocks.MockFieldRepository), new(mocks.MockDynamicQueryExecutor), new(mocks.MockRecordRevisionRepository), new(mocks.MockWebhookRepository), newTestTransactor(), mockPublisher, newTestPermissionService(new(mocks.MockAppRepository)), newTestAttachmentManager())
+
+		require.NoError(t, service.Run(ctx, created))
+		mockRunRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+		mockPublisher.AssertNotCalled(t, "PublishTo", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("field changed trigger", func(t *testing.T) {
+		mockRuleRepo := new(mocks.MockAutomationRuleRepository)
+		mockRunRepo := new(mocks.MockAutomationRunRepository)
+		mockPublisher := newTestWebhookPublisher()
+
+		mockRuleRepo.On("GetByAppID", ctx, uint64(1)).Return([]models.AutomationRule{{
+			ID: 6, AppID: 1, Name: "状態変更", Trigger: models.AutomationTriggerFieldChanged, TriggerField: "status", IsActive: true,
+			Conditions: []models.FilterItem{{Field: "status", Operator: "eq", Value: "done"}},
+			Actions:    []models.AutomationAction{{Type: models.AutomationActionCallWebhook, WebhookID: 3}},
+		}}, nil)
+		mockPublisher.On("PublishTo", mock.Anything, uint64(3), mock.MatchedBy(func(event models.WebhookEvent) bool {
+			data := event.Data.(models.WebhookAutomationData)
+			return event.Event == models.WebhookEventAutomationTriggered && event.AppID == 1 &&
+				data.RuleID == 6 && data.RuleName == "状態変更" && data.RecordID == 7 && data.Data["status"] == "done"
+		})).Return(nil).Once()
+		mockRunRepo.On("Create", ctx, mock.MatchedBy(func(run *models.AutomationRun) bool {
+			return run.Status == models.AutomationRunSucceeded
+		})).Return(nil).Once()
+
+		service := services.NewAutomationService(mockRuleRepo, mockRunRepo, new(mocks.MockAppRepository), new(mocks.MockFieldRepository), new(mocks.MockDynamicQueryExecutor), new(mocks.MockRecordRevisionRepository), new(mocks.MockWebhookRepository), newTestTransactor(), mockPublisher, newTestPermissionService(new(mocks.MockAppRepository)), newTestAttachmentManager())
+
+		// 別のフィールドの変更では実行しない
+		require.NoError(t, service.Run(ctx, models.RecordRevision{AppID: 1, RecordID: 7, Action: models.RevisionActionUpdate,
+			Changes:  models.RecordChanges{"amount": {Before: "1", After: "2"}},
+			Snapshot: models.RecordData{"status": "done"}}))
+		require.NoError(t, service.Run(ctx, models.RecordRevision{AppID: 1, RecordID: 7, Action: models.RevisionActionUpdate,
+			Changes:  models.RecordChanges{"status": {Before: "open", After: "done"}},
+			Snapshot: models.RecordData{"status": "done"}}))
+
+		mockPublisher.AssertExpectations(t)
+		mockRunRepo.AssertExpectations(t)
+	})
+
+	t.Run("logs failed action", func(t *testing.T) {
+		mockRuleRepo := new(mocks.MockAutomationRuleRepository)
+		mockRunRepo := new(mocks.MockAutomationRunRepository)
+		mockPublisher := newTestWebhookPublisher()
+
+		mockRuleRepo.On("GetByAppID", ctx, uint64(1)).Return([]models.AutomationRule{{
+			ID: 6, AppID: 1, Trigger: models.AutomationTriggerRecordCreated, IsActive: true,
+			Actions: []models.AutomationAction{
+				{Type: models.AutomationActionCallWebhook, WebhookID: 3},
+				{Type: models.AutomationActionCallWebhook, WebhookID: 4},
+			},
+		}}, nil)
+		mockPublisher.On("PublishTo", mock.Anything, uint64(3), mock.Anything).Return(services.ErrWebhookInactive)
+
+		var run *models.AutomationRun
+		mockRunRepo.On("Create", ctx, mock.AnythingOfType("*models.AutomationRun")).Return(nil).Run(func(args mock.Arguments) {
+			run = args.Get(1).(*models.AutomationRun)
+		})
+
+		service := services.NewAutomationService(mockRuleRepo, mockRunRepo, new(mocks.MockAppRepository), new(mocks.MockFieldRepository), new(mocks.MockDynamicQueryExecutor), new(mocks.MockRecordRevisionRepository), new(mocks.MockWebhookRepository), newTestTransactor(), mockPublisher, newTestPermissionService(new(mocks.MockAppRepository)), newTestAttachmentManager())
+
+		// アクションの失敗は呼び出し元のレコード操作を失敗させない
+		require.NoError(t, service.Run(ctx, created))
+
+		require.NotNil(t, run)
+		assert.Equal(t, models.AutomationRunFailed, run.Status)
+		assert.Contains(t, run.Error, services.ErrWebhookInactive.Error())
+		// 失敗したアクション以降は実行しない
+		mockPublisher.AssertNotCalled(t, "PublishTo", mock.Anything, uint64(4), mock.Anything)
+	})
+
+	t.Run("does not rerun rule on record it changed", func(t *testing.T) {
+		mockRuleRepo := new(mocks.MockAutomationRuleRepository)
+		mockRunRepo := new(mocks.MockAutomationRunRepository)
+		mockAppRepo := new(mocks.MockAppRepository)
+		mockFieldRepo := new(mocks.MockFieldRepository)
+		mockDynamicQuery := new(mocks.MockDynamicQueryExecutor)
+		mockRevisionRepo := new(mocks.MockRecordRevisionRepository)
+
+		fields := automationTestFields()
+		mockRuleRepo.On("GetByAppID", mock.Anything, uint64(1)).Return([]models.AutomationRule{{
+			ID: 6, AppID: 1, Trigger: models.AutomationTriggerRecordUpdated, IsActive: true,
+			Actions: []models.AutomationAction{{Type: models.AutomationActionUpdateRecord, Values: models.RecordData{"status": "checked"}}},
+		}}, nil)
+		mockAppRepo.On("GetByID", mock.Anything, uint64(1)).Return(app, nil)
+		mockFieldRepo.On("GetByAppID", mock.Anything, uint64(1)).Return(fields, nil)
+		mockDynamicQuery.On("GetRecordByID", mock.Anything, "app_data_1", fields, uint64(7)).
+			Return(&models.RecordResponse{ID: 7, Data: models.RecordData{"status": "open"}}, nil).Once()
+		mockDynamicQuery.On("UpdateRecord", mock.Anything, "app_data_1", uint64(7), mock.Anything).Return(nil)
+		mockDynamicQuery.On("GetRecordByID", mock.Anything, "app_data_1", fields, uint64(7)).
+			Return(&models.RecordResponse{ID: 7, Data: models.RecordData{"status": "checked"}}, nil).Once()
+		mockRevisionRepo.On("Create", mock.Anything, mock.Anything).Return(nil)
+		mockRunRepo.On("Create", ctx, mock.Anything).Return(nil)
+
+		service := services.NewAutomationService(mockRuleRepo, mockRunRepo, mockAppRepo, mockFieldRepo, mockDynamicQuery, mockRevisionRepo, new(mocks.MockWebhookRepository), newTestTransactor(), newTestWebhookPublisher(), newTestPermissionService(mockAppRepo), newTestAttachmentManager())
+
+		require.NoError(t, service.Run(ctx, models.RecordRevision{AppID: 1, RecordID: 7, Action: models.RevisionActionUpdate,
+			Changes:  models.RecordChanges{"amount": {Before: "1", After: "2"}},
+			Snapshot: models.RecordData{"status": "open"}}))
+
+		// ルール自身の更新で再びルールが実行されることはない
+		mockDynamicQuery.AssertNumberOfCalls(t, "UpdateRecord", 1)
+		mockRunRepo.AssertNumberOfCalls(t, "Create", 1)
+	})
+
+	t.Run("stops chain at max depth", func(t *testing.T) {
+		mockRuleRepo := new(mocks.MockAutomationRuleRepository)
+		mockRunRepo := new(mocks.MockAutomationRunRepository)
+		mockAppRepo := new(mocks.MockAppRepository)
+		mockFieldRepo := new(mocks.MockFieldRepository)
+		mockDynamicQuery := new(mocks.MockDynamicQueryExecutor)
+		mockRevisionRepo := new(mocks.MockRecordRevisionRepository)
+
+		fields := []models.AppField{{ID: 1, AppID: 1, FieldCode: "name", FieldName: "名前", FieldType: "text"}}
+		// 作成したレコードが再び同じルールを実行し、レコードを作成し続ける設定
+		mockRuleRepo.On("GetByAppID", mock.Anything, uint64(1)).Return([]models.AutomationRule{{
+			ID: 6, AppID: 1, Trigger: models.AutomationTriggerRecordCreated, IsActive: true,
+			Actions: []models.AutomationAction{{Type: models.AutomationActionCreateRecord, AppID: 1, Values: models.RecordData{"name": "{{name}}"}}},
+		}}, nil)
+		mockAppRepo.On("GetByID", mock.Anything, uint64(1)).Return(app, nil)
+		mockFieldRepo.On("GetByAppID", mock.Anything, uint64(1)).Return(fields, nil)
+		for id := uint64(8); id <= 12; id++ {
+			mockDynamicQuery.On("InsertRecord", mock.Anything, "app_data_1", models.RecordData{"name": "A社"}, uint64(0)).Return(id, nil).Once()
+			mockDynamicQuery.On("GetRecordByID", mock.Anything, "app_data_1", fields, id).
+				Return(&models.RecordResponse{ID: id, Data: models.RecordData{"name": "A社"}}, nil)
+		}
+		mockRevisionRepo.On("Create", mock.Anything, mock.Anything).Return(nil)
+
+		var runs []*models.AutomationRun
+		mockRunRepo.On("Create", mock.Anything, mock.AnythingOfType("*models.AutomationRun")).Return(nil).Run(func(args mock.Arguments) {
+			runs = append(runs, args.Get(1).(*models.AutomationRun))
+		})
+
+		service := services.NewAutomationService(mockRuleRepo, mockRunRepo, mockAppRepo, mockFieldRepo, mockDynamicQuery, mockRevisionRepo, new(mocks.MockWebhookRepository), newTestTransactor(), newTestWebhookPublisher(), newTestPermissionService(mockAppRepo), newTestAttachmentManager())
+
+		require.NoError(t, service.Run(ctx, created))
+
+		mockDynamicQuery.AssertNumberOfCalls(t, "InsertRecord", 5)
+		require.Len(t, runs, 6)
+		// 最も深い実行から順に記録される
+		assert.Equal(t, models.AutomationRunSkipped, runs[0].Status)
+		assert.Equal(t, uint64(12), runs[0].RecordID)
+		assert.Equal(t, 5, runs[0].Depth)
+		for _, run := range runs[1:] {
+			assert.Equal(t, models.AutomationRunSucceeded, run.Status)
+		}
+		assert.Zero(t, runs[5].Depth)
+	})
+
+	t.Run("writes the action record in one transaction", func(t *testing.T) {
+		mockRuleRepo := new(mocks.MockAutomationRuleRepository)
+		mockRunRepo := new(mocks.MockAutomationRunRepository)
+		mockAppRepo := new(mocks.MockAppRepository)
+		mockFieldRepo := new(mocks.MockFieldRepository)
+		mockDynamicQuery := new(mocks.MockDynamicQueryExecutor)
+		mockRevisionRepo := new(mocks.MockRecordRevisionRepository)
+		mockTransactor := new(mocks.MockTransactor)
+		mockPublisher := new(mocks.MockWebhookPublisher)
+		mockAttachments := new(mocks.MockAttachmentManager)
+
+		fields := []models.AppField{{ID: 1, AppID: 2, FieldCode: "name", FieldName: "名前", FieldType: "text"}}
+		mockRuleRepo.On("GetByAppID", ctx, uint64(1)).Return([]models.AutomationRule{{
+			ID: 6, AppID: 1, Trigger: models.AutomationTriggerRecordCreated, IsActive: true,
+			Actions: []models.AutomationAction{{Type: models.AutomationActionCreateRecord, AppID: 2, Values: models.RecordData{"name": "{{name}}"}}},
+		}}, nil).Once()
+		mockAppRepo.On("GetByID", mock.Anything, uint64(2)).Return(&models.App{ID: 2, TableName: "app_data_2"}, nil)
+		mockFieldRepo.On("GetByAppID", mock.Anything, uint64(2)).Return(fields, nil)
+		mockAttachments.On("ResolveValues", mock.Anything, uint64(2), uint64(0), uint64(0), fields, []models.RecordData{{"name": "A社"}}).Return(nil, nil).Once()
+		mockTransactor.On("RunInTx", mock.Anything).Return(nil).Once()
+		mockDynamicQuery.On("InsertRecord", mock.Anything, "app_data_2", models.RecordData{"name": "A社"}, uint64(0)).Return(uint64(8), nil).Once()
+		mockAttachments.On("AttachRecord", mock.Anything, uint64(2), uint64(8), fields, models.RecordData{"name": "A社"}).Return(nil).Once()
+		mockDynamicQuery.On("GetRecordByID", mock.Anything, "app_data_2", fields, uint64(8)).
+			Return(&models.RecordResponse{ID: 8, Data: models.RecordData{"name": "A社"}}, nil).Once()
+		mockRevisionRepo.On("Create", mock.Anything, mock.Anything).Return(nil).Once()
+		mockPublisher.On("Publish", mock.Anything, mock.Anything).Return(errors.New("db error")).Once()
+
+		var run *models.AutomationRun
+		mockRunRepo.On("Create", ctx, mock.AnythingOfType("*models.AutomationRun")).Return(nil).Run(func(args mock.Arguments) {
+			run = args.Get(1).(*models.AutomationRun)
+		}).Once()
+
+		service := services.NewAutomationService(mockRuleRepo, mockRunRepo, mockAppRepo, mockFieldRepo, mockDynamicQuery, mockRevisionRepo, new(mocks.MockWebhookRepository), mockTransactor, mockPublisher, newTestPermissionService(mockAppRepo), mockAttachments)
+
+		require.NoError(t, service.Run(ctx, created))
+
+		// 通知の登録に失敗した場合はレコードの作成ごとロールバックし、後続のルールも実行しない
+		require.NotNil(t, run)
+		assert.Equal(t, models.AutomationRunFailed, run.Status)
+		assert.Contains(t, run.Error, "db error")
+		mockTransactor.AssertExpectations(t)
+		mockAttachments.AssertExpectations(t)
+		mockRuleRepo.AssertExpectations(t)
+		mockRuleRepo.AssertNotCalled(t, "GetByAppID", mock.Anything, uint64(2))
+	})
+
+	t.Run("rejects a reference to a missing record", func(t *testing.T) {
+		mockRuleRepo := new(mocks.MockAutomationRuleRepository)
+		mockRunRepo := new(mocks.MockAutomationRunRepository)
+		mockAppRepo := new(mocks.MockAppRepository)
+		mockFieldRepo := new(mocks.MockFieldRepository)
+		mockDynamicQuery := new(mocks.MockDynamicQueryExecutor)
+		mockTransactor := new(mocks.MockTransactor)
+
+		fields := []models.AppField{{ID: 1, AppID: 2, FieldCode: "customer", FieldName: "顧客", FieldType: "reference",
+			Options: models.FieldOptions{"app_id": float64(3)}}}
+		mockRuleRepo.On("GetByAppID", ctx, uint64(1)).Return([]models.AutomationRule{{
+			ID: 6, AppID: 1, Trigger: models.AutomationTriggerRecordCreated, IsActive: true,
+			Actions: []models.AutomationAction{{Type: models.AutomationActionCreateRecord, AppID: 2, Values: models.RecordData{"customer": "{{id}}"}}},
+		}}, nil)
+		mockAppRepo.On("GetByID", mock.Anything, uint64(2)).Return(&models.App{ID: 2, TableName: "app_data_2"}, nil)
+		mockAppRepo.On("GetByID", mock.Anything, uint64(3)).Return(&models.App{ID: 3, TableName: "app_data_3"}, nil)
+		mockFieldRepo.On("GetByAppID", mock.Anything, uint64(2)).Return(fields, nil)
+		mockDynamicQuery.On("GetRecordsByIDs", mock.Anything, "app_data_3", mock.Anything, []uint64{7}).Return([]models.RecordResponse{}, nil).Once()
+
+		var run *models.AutomationRun
+		mockRunRepo.On("Create", ctx, mock.AnythingOfType("*models.AutomationRun")).Return(nil).Run(func(args mock.Arguments) {
+			run = args.Get(1).(*models.AutomationRun)
+		})
+
+		service := services.NewAutomationService(mockRuleRepo, mockRunRepo, mockAppRepo, mockFieldRepo, mockDynamicQuery, new(mocks.MockRecordRevisionRepository), new(mocks.MockWebhookRepository), mockTransactor, new(mocks.MockWebhookPublisher), newTestPermissionService(mockAppRepo), newTestAttachmentManager())
+
+		require.NoError(t, service.Run(ctx, created))
+
+		// レコード操作と同じく、存在しないレコードは参照できない
+		require.NotNil(t, run)
+		assert.Equal(t, models.AutomationRunFailed, run.Status)
+		assert.Contains(t, run.Error, "customer: 参照先のレコードが存在しません")
+		mockDynamicQuery.AssertExpectations(t)
+		mockDynamicQuery.AssertNotCalled(t, "InsertRecord", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+		mockTransactor.AssertNotCalled(t, "RunInTx", mock.Anything)
+	})
+}
+
+func TestRecordService_CreateRecord_RunsAutomations(t *testing.T) {
+	ctx := systemContext()
+
+	mockAppRepo := new(mocks.MockAppRepository)
+	mockFieldRepo := new(mocks.MockFieldRepository)
+	mockDynamicQuery := new(mocks.MockDynamicQueryExecutor)
+	mockRevisionRepo := new(mocks.MockRecordRevisionRepository)
+	mockRunner := new(mocks.MockAutomationRunner)
+
+	fields := []models.AppField{{ID: 1, AppID: 1, FieldCode: "name", FieldName: "名前", FieldType: "text"}}
+	mockAppRepo.On("GetByID", ctx, uint64(1)).Return(&models.App{ID: 1, TableName: "app_data_1"}, nil)
+	mockFieldRepo.On("GetByAppID", ctx, uint64(1)).Return(fields, nil)
+	mockDynamicQuery.On("InsertRecord", ctx, "app_data_1", models.RecordData{"name": "A社"}, uint64(5)).Return(uint64(7), nil)
+	mockDynamicQuery.On("GetRecordByID", ctx, "app_data_1", fields, uint64(7)).Return(&models.RecordResponse{ID: 7, Data: models.RecordData{"name": "A社"}}, nil)
+	mockRevisionRepo.On("Create", ctx, mock.Anything).Return(nil)
+	mockRunner.On("Run", ctx, mock.MatchedBy(func(revisions []models.RecordRevision) bool {
+		return len(revisions) == 1 && revisions[0].Action == models.RevisionActionCreate && revisions[0].RecordID == 7
+	})).Return(nil)
+
+	service := services.NewRecordService(mockAppRepo, mockFieldRepo, mockDynamicQuery, new(mocks.MockDataSourceRepository), new(mocks.MockExternalQueryExecutor), newTestPermissionService(mockAppRepo), mockRevisionRepo, newTestTransactor(), newTestWebhookPublisher(), mockRunner, newTestAttachmentManager())
+
+	_, err := service.CreateRecord(ctx, 1, 5, &models.CreateRecordRequest{Data: models.RecordData{"name": "A社"}})
+	require.NoError(t, err)
+	mockRunner.AssertExpectations(t)
+}
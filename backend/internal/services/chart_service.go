@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"time"
 
 	"nocode-app/backend/internal/models"
@@ -14,6 +15,7 @@ import (
 // チャート関連エラー
 var (
 	ErrChartConfigNotFound = errors.New("チャート設定が見つかりません")
+	ErrInvalidChartConfig  = errors.New("グラフの設定が正しくありません")
 )
 
 // ChartService チャート操作を処理する構造体
@@ -24,6 +26,7 @@ type ChartService struct {
 	dynamicQuery  repositories.DynamicQueryExecutorInterface
 	dsRepo        repositories.DataSourceRepositoryInterface
 	externalQuery repositories.ExternalQueryExecutorInterface
+	permissions   PermissionServiceInterface
 }
 
 // NewChartService 新しいChartServiceを作成する
@@ -34,6 +37,7 @@ func NewChartService(
 	dynamicQuery repositories.DynamicQueryExecutorInterface,
 	dsRepo repositories.DataSourceRepositoryInterface,
 	externalQuery repositories.ExternalQueryExecutorInterface,
+	permissions PermissionServiceInterface,
 ) *ChartService {
 	return &ChartService{
 		chartRepo:     chartRepo,
@@ -42,18 +46,48 @@ func NewChartService(
 		dynamicQuery:  dynamicQuery,
 		dsRepo:        dsRepo,
 		externalQuery: externalQuery,
+		permissions:   permissions,
 	}
 }
 
 // GetChartData チャート用の集計データを取得する
 func (s *ChartService) GetChartData(ctx context.Context, appID uint64, req *models.ChartDataRequest) (*models.ChartDataResponse, error) {
-	// アプリ情報を取得
-	app, err := s.appRepo.GetByID(ctx, appID)
+	// アプリ情報を取得し権限を確認
+	app, access, err := s.permissions.AuthorizeApp(ctx, appID, models.AppRoleViewer)
 	if err != nil {
 		return nil, err
 	}
-	if app == nil {
-		return nil, ErrAppNotFound
+
+	// 絞り込み条件・系列・日付の区切りをフィールド定義と照合する
+	var fields []models.AppField
+	if len(req.Filters) > 0 || req.Filter != nil || req.GroupBy != "" || len(req.Measures) > 0 || req.XAxis.Bucket != "" {
+		fields, err = s.fieldRepo.GetByAppID(ctx, appID)
+		if err != nil {
+			return nil, err
+		}
+		req, err = resolveChartRequest(app, fields, req)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if len(req.Filters) > 0 || req.Filter != nil {
+		resolved := *req
+		resolved.Filters, resolved.Filter, err = resolveFilters(app, fields, req.TimeZone, req.Filters, req.Filter)
+		if err != nil {
+			return nil, err
+		}
+		req = &resolved
+	}
+
+	// 自分のレコードのみ閲覧可能な場合は作成者で絞り込む
+	if access.OwnRecordsOnly {
+		// 外部データソースには作成者の概念がないため集計できない
+		if app.IsExternal {
+			return nil, ErrPermissionDenied
+		}
+		filtered := *req
+		filtered.Filters = append(append([]models.FilterItem{}, req.Filters...), access.RecordFilters()...)
+		req = &filtered
 	}
 
 	// 外部データソースの場合は外部クエリを使用
@@ -77,9 +111,11 @@ func (s *ChartService) GetChartData(ctx context.Context, appID uint64, req *mode
 		}
 
 		// フィールド情報を取得（field_codeからsource_column_nameへのマッピング用）
-		fields, err := s.fieldRepo.GetByAppID(ctx, appID)
-		if err != nil {
-			return nil, err
+		if fields == nil {
+			fields, err = s.fieldRepo.GetByAppID(ctx, appID)
+			if err != nil {
+				return nil, err
+			}
 		}
 
 		return s.externalQuery.GetAggregatedData(ctx, ds, password, *app.SourceTableName, fields, req)
@@ -89,21 +125,66 @@ func (s *ChartService) GetChartData(ctx context.Context, appID uint64, req *mode
 	return s.dynamicQuery.GetAggregatedData(ctx, app.TableName, req)
 }
 
+// resolveChartRequest 系列・追加の集計値・日付の区切りに指定したフィールドを検証し、
+// X軸が日付のカラムかどうかを設定した複製を返す
+func resolveChartRequest(app *models.App, fields []models.AppField, req *models.ChartDataRequest) (*models.ChartDataRequest, error) {
+	// 絞り込みと同じく、カラムを持つフィールドの分類で判定する
+	r, err := newFilterResolver(app, fields, "", time.Now())
+	if err != nil {
+		return nil, err
+	}
+	kinds := r.kinds
+
+	resolved := *req
+	if req.XAxis.Bucket != "" {
+		switch kinds[req.XAxis.Field] {
+		case filterKindDate:
+			resolved.XAxis.DateOnly = true
+		case filterKindDateTime:
+		default:
+			return nil, fmt.Errorf("%w: 日付の区切りは日付・日時のフィールドにのみ指定できます", ErrInvalidChartConfig)
+		}
+		if req.TimeZone != "" {
+			if _, err := time.LoadLocation(req.TimeZone); err != nil {
+				return nil, fmt.Errorf("%w: タイムゾーン %q が正しくありません", ErrInvalidChartConfig, req.TimeZone)
+			}
+		}
+	}
+
+	if req.GroupBy != "" {
+		switch kind, ok := kinds[req.GroupBy]; {
+		case !ok:
+			return nil, fmt.Errorf("%w: グループ化するフィールド %q がありません", ErrInvalidChartConfig, req.GroupBy)
+		case kind == filterKindMultiSelect || kind == filterKindAttachment:
+			return nil, fmt.Errorf("%w: 複数選択・添付ファイルのフィールドではグループ化できません", ErrInvalidChartConfig)
+		}
+	}
+
+	for _, m := range req.Measures {
+		switch m.Aggregation {
+		case "sum", "avg", "min", "max":
+			if kinds[m.Field] != filterKindNumber {
+				return nil, fmt.Errorf("%w: %s で集計するフィールド %q は数値のフィールドを指定してください", ErrInvalidChartConfig, m.Aggregation, m.Field)
+			}
+		}
+	}
+	return &resolved, nil
+}
+
 // GetChartConfigs アプリの全チャート設定を取得する
 func (s *ChartService) GetChartConfigs(ctx context.Context, appID uint64) ([]models.ChartConfig, error) {
+	if _, _, err := s.permissions.AuthorizeApp(ctx, appID, models.AppRoleViewer); err != nil {
+		return nil, err
+	}
 	return s.chartRepo.GetByAppID(ctx, appID)
 }
 
 // SaveChartConfig チャート設定を保存する（IDがあれば更新、なければ新規作成）
 func (s *ChartService) SaveChartConfig(ctx context.Context, appID, userID uint64, req *models.SaveChartConfigRequest) (*models.ChartConfig, error) {
-	// アプリの存在確認
-	app, err := s.appRepo.GetByID(ctx, appID)
-	if err != nil {
+	// アプリの存在と権限を確認
+	if _, _, err := s.permissions.AuthorizeApp(ctx, appID, models.AppRoleEditor); err != nil {
 		return nil, err
 	}
-	if app == nil {
-		return nil, ErrAppNotFound
-	}
 
 	// 設定をViewConfig（JSON保存形式）に変換
 	configJSON, err := json.Marshal(req.Config)
@@ -171,6 +252,9 @@ func (s *ChartService) DeleteChartConfig(ctx context.Context, configID uint64) e
 	if config == nil {
 		return ErrChartConfigNotFound
 	}
+	if _, _, err := s.permissions.AuthorizeApp(ctx, config.AppID, models.AppRoleEditor); err != nil {
+		return err
+	}
 
 	return s.chartRepo.Delete(ctx, configID)
 }
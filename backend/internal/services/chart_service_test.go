@@ -1,7 +1,6 @@
 package services_test
 
 import (
-	"context"
 	"testing"
 	"time"
 
@@ -24,7 +23,7 @@ func encryptTestPassword(t *testing.T, password string) string {
 }
 
 func TestChartService_GetChartData(t *testing.T) {
-	ctx := context.Background()
+	ctx := systemContext()
 
 	t.Run("successful get chart data for internal app", func(t *testing.T) {
 		mockChartRepo := new(mocks.MockChartRepository)
@@ -49,7 +48,7 @@ func TestChartService_GetChartData(t *testing.T) {
 		mockAppRepo.On("GetByID", ctx, uint64(1)).Return(app, nil)
 		mockDynamicQuery.On("GetAggregatedData", ctx, "app_data_1", mock.AnythingOfType("*models.ChartDataRequest")).Return(chartData, nil)
 
-		service := services.NewChartService(mockChartRepo, mockAppRepo, mockFieldRepo, mockDynamicQuery, mockDSRepo, mockExternalQuery)
+		service := services.NewChartService(mockChartRepo, mockAppRepo, mockFieldRepo, mockDynamicQuery, mockDSRepo, mockExternalQuery, newTestPermissionService(mockAppRepo))
 
 		req := &models.ChartDataRequest{
 			ChartType: "bar",
@@ -133,7 +132,7 @@ func TestChartService_GetChartData(t *testing.T) {
 		mockFieldRepo.On("GetByAppID", ctx, uint64(2)).Return(fields, nil)
 		mockExternalQuery.On("GetAggregatedData", ctx, dataSource, "testpassword", sourceTableName, fields, mock.AnythingOfType("*models.ChartDataRequest")).Return(chartData, nil)
 
-		service := services.NewChartService(mockChartRepo, mockAppRepo, mockFieldRepo, mockDynamicQuery, mockDSRepo, mockExternalQuery)
+		service := services.NewChartService(mockChartRepo, mockAppRepo, mockFieldRepo, mockDynamicQuery, mockDSRepo, mockExternalQuery, newTestPermissionService(mockAppRepo))
 
 		req := &models.ChartDataRequest{
 			ChartType: "bar",
@@ -219,7 +218,7 @@ func TestChartService_GetChartData(t *testing.T) {
 		mockFieldRepo.On("GetByAppID", ctx, uint64(3)).Return(fields, nil)
 		mockExternalQuery.On("GetAggregatedData", ctx, dataSource, "pgpassword", sourceTableName, fields, mock.AnythingOfType("*models.ChartDataRequest")).Return(chartData, nil)
 
-		service := services.NewChartService(mockChartRepo, mockAppRepo, mockFieldRepo, mockDynamicQuery, mockDSRepo, mockExternalQuery)
+		service := services.NewChartService(mockChartRepo, mockAppRepo, mockFieldRepo, mockDynamicQuery, mockDSRepo, mockExternalQuery, newTestPermissionService(mockAppRepo))
 
 		req := &models.ChartDataRequest{
 			ChartType: "bar",
@@ -250,7 +249,7 @@ func TestChartService_GetChartData(t *testing.T) {
 
 		mockAppRepo.On("GetByID", ctx, uint64(999)).Return(nil, nil)
 
-		service := services.NewChartService(mockChartRepo, mockAppRepo, mockFieldRepo, mockDynamicQuery, mockDSRepo, mockExternalQuery)
+		service := services.NewChartService(mockChartRepo, mockAppRepo, mockFieldRepo, mockDynamicQuery, mockDSRepo, mockExternalQuery, newTestPermissionService(mockAppRepo))
 
 		req := &models.ChartDataRequest{}
 
@@ -282,7 +281,7 @@ func TestChartService_GetChartData(t *testing.T) {
 		mockAppRepo.On("GetByID", ctx, uint64(4)).Return(app, nil)
 		mockDSRepo.On("GetByID", ctx, uint64(999)).Return(nil, nil)
 
-		service := services.NewChartService(mockChartRepo, mockAppRepo, mockFieldRepo, mockDynamicQuery, mockDSRepo, mockExternalQuery)
+		service := services.NewChartService(mockChartRepo, mockAppRepo, mockFieldRepo, mockDynamicQuery, mockDSRepo, mockExternalQuery, newTestPermissionService(mockAppRepo))
 
 		req := &models.ChartDataRequest{
 			ChartType: "bar",
@@ -298,8 +297,99 @@ func TestChartService_GetChartData(t *testing.T) {
 	})
 }
 
+func TestChartService_GetChartData_Series(t *testing.T) {
+	ctx := systemContext()
+	app := &models.App{ID: 1, TableName: "app_data_1"}
+	fields := []models.AppField{
+		{ID: 1, AppID: 1, FieldCode: "status", FieldType: "select"},
+		{ID: 2, AppID: 1, FieldCode: "due", FieldType: "date"},
+		{ID: 3, AppID: 1, FieldCode: "amount", FieldType: "number"},
+		{ID: 4, AppID: 1, FieldCode: "tags", FieldType: "multiselect"},
+	}
+	newService := func() (*services.ChartService, *mocks.MockAppRepository, *mocks.MockFieldRepository, *mocks.MockDynamicQueryExecutor) {
+		mockAppRepo := new(mocks.MockAppRepository)
+		mockFieldRepo := new(mocks.MockFieldRepository)
+		mockDynamicQuery := new(mocks.MockDynamicQueryExecutor)
+		service := services.NewChartService(new(mocks.MockChartRepository), mockAppRepo, mockFieldRepo, mockDynamicQuery,
+			new(mocks.MockDataSourceRepository), new(mocks.MockExternalQueryExecutor), newTestPermissionService(mockAppRepo))
+		mockAppRepo.On("GetByID", ctx, uint64(1)).Return(app, nil)
+		mockFieldRepo.On("GetByAppID", ctx, uint64(1)).Return(fields, nil)
+		return service, mockAppRepo, mockFieldRepo, mockDynamicQuery
+	}
+
+	t.Run("date bucket marks the x axis as date only", func(t *testing.T) {
+		service, _, _, mockDynamicQuery := newService()
+		mockDynamicQuery.On("GetAggregatedData", ctx, "app_data_1", mock.MatchedBy(func(req *models.ChartDataRequest) bool {
+			return req.XAxis.DateOnly && req.GroupBy == "status"
+		})).Return(&models.ChartDataResponse{}, nil)
+
+		_, err := service.GetChartData(ctx, 1, &models.ChartDataRequest{
+			ChartType: "bar",
+			XAxis:     models.ChartAxis{Field: "due", Bucket: "month"},
+			YAxis:     models.ChartAxis{Aggregation: "count"},
+			GroupBy:   "status",
+			TimeZone:  "Asia/Tokyo",
+		})
+		require.NoError(t, err)
+		mockDynamicQuery.AssertExpectations(t)
+	})
+
+	tests := []struct {
+		name string
+		req  *models.ChartDataRequest
+	}{
+		{
+			name: "bucket on a text field",
+			req: &models.ChartDataRequest{
+				XAxis: models.ChartAxis{Field: "status", Bucket: "month"},
+				YAxis: models.ChartAxis{Aggregation: "count"},
+			},
+		},
+		{
+			name: "invalid time zone",
+			req: &models.ChartDataRequest{
+				XAxis:    models.ChartAxis{Field: "created_at", Bucket: "day"},
+				YAxis:    models.ChartAxis{Aggregation: "count"},
+				TimeZone: "Mars/Olympus",
+			},
+		},
+		{
+			name: "unknown group by field",
+			req: &models.ChartDataRequest{
+				XAxis:   models.ChartAxis{Field: "status"},
+				YAxis:   models.ChartAxis{Aggregation: "count"},
+				GroupBy: "missing",
+			},
+		},
+		{
+			name: "group by multiselect",
+			req: &models.ChartDataRequest{
+				XAxis:   models.ChartAxis{Field: "status"},
+				YAxis:   models.ChartAxis{Aggregation: "count"},
+				GroupBy: "tags",
+			},
+		},
+		{
+			name: "sum of a non-number measure",
+			req: &models.ChartDataRequest{
+				XAxis:    models.ChartAxis{Field: "status"},
+				YAxis:    models.ChartAxis{Aggregation: "count"},
+				Measures: []models.ChartAxis{{Field: "status", Aggregation: "sum"}},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, _, _, _ := newService()
+
+			_, err := service.GetChartData(ctx, 1, tt.req)
+			assert.ErrorIs(t, err, services.ErrInvalidChartConfig)
+		})
+	}
+}
+
 func TestChartService_GetChartConfigs(t *testing.T) {
-	ctx := context.Background()
+	ctx := systemContext()
 
 	t.Run("successful get configs", func(t *testing.T) {
 		mockChartRepo := new(mocks.MockChartRepository)
@@ -314,9 +404,10 @@ func TestChartService_GetChartConfigs(t *testing.T) {
 			{ID: 2, AppID: 1, Name: "Chart 2", ChartType: "pie", CreatedAt: time.Now(), UpdatedAt: time.Now()},
 		}
 
+		mockAppRepo.On("GetByID", ctx, uint64(1)).Return(&models.App{ID: 1}, nil)
 		mockChartRepo.On("GetByAppID", ctx, uint64(1)).Return(configs, nil)
 
-		service := services.NewChartService(mockChartRepo, mockAppRepo, mockFieldRepo, mockDynamicQuery, mockDSRepo, mockExternalQuery)
+		service := services.NewChartService(mockChartRepo, mockAppRepo, mockFieldRepo, mockDynamicQuery, mockDSRepo, mockExternalQuery, newTestPermissionService(mockAppRepo))
 
 		resp, err := service.GetChartConfigs(ctx, 1)
 		require.NoError(t, err)
@@ -327,7 +418,7 @@ func TestChartService_GetChartConfigs(t *testing.T) {
 }
 
 func TestChartService_SaveChartConfig(t *testing.T) {
-	ctx := context.Background()
+	ctx := systemContext()
 
 	t.Run("create new config", func(t *testing.T) {
 		mockChartRepo := new(mocks.MockChartRepository)
@@ -345,7 +436,7 @@ func TestChartService_SaveChartConfig(t *testing.T) {
 			config.ID = 1
 		})
 
-		service := services.NewChartService(mockChartRepo, mockAppRepo, mockFieldRepo, mockDynamicQuery, mockDSRepo, mockExternalQuery)
+		service := services.NewChartService(mockChartRepo, mockAppRepo, mockFieldRepo, mockDynamicQuery, mockDSRepo, mockExternalQuery, newTestPermissionService(mockAppRepo))
 
 		req := &models.SaveChartConfigRequest{
 			Name:      "New Chart",
@@ -375,7 +466,7 @@ func TestChartService_SaveChartConfig(t *testing.T) {
 
 		mockAppRepo.On("GetByID", ctx, uint64(999)).Return(nil, nil)
 
-		service := services.NewChartService(mockChartRepo, mockAppRepo, mockFieldRepo, mockDynamicQuery, mockDSRepo, mockExternalQuery)
+		service := services.NewChartService(mockChartRepo, mockAppRepo, mockFieldRepo, mockDynamicQuery, mockDSRepo, mockExternalQuery, newTestPermissionService(mockAppRepo))
 
 		req := &models.SaveChartConfigRequest{
 			Name:      "Test",
@@ -390,7 +481,7 @@ func TestChartService_SaveChartConfig(t *testing.T) {
 }
 
 func TestChartService_DeleteChartConfig(t *testing.T) {
-	ctx := context.Background()
+	ctx := systemContext()
 
 	t.Run("successful delete", func(t *testing.T) {
 		mockChartRepo := new(mocks.MockChartRepository)
@@ -403,9 +494,10 @@ func TestChartService_DeleteChartConfig(t *testing.T) {
 		config := &models.ChartConfig{ID: 1, AppID: 1}
 
 		mockChartRepo.On("GetByID", ctx, uint64(1)).Return(config, nil)
+		mockAppRepo.On("GetByID", ctx, uint64(1)).Return(&models.App{ID: 1}, nil)
 		mockChartRepo.On("Delete", ctx, uint64(1)).Return(nil)
 
-		service := services.NewChartService(mockChartRepo, mockAppRepo, mockFieldRepo, mockDynamicQuery, mockDSRepo, mockExternalQuery)
+		service := services.NewChartService(mockChartRepo, mockAppRepo, mockFieldRepo, mockDynamicQuery, mockDSRepo, mockExternalQuery, newTestPermissionService(mockAppRepo))
 
 		err := service.DeleteChartConfig(ctx, 1)
 		require.NoError(t, err)
@@ -423,7 +515,7 @@ func TestChartService_DeleteChartConfig(t *testing.T) {
 
 		mockChartRepo.On("GetByID", ctx, uint64(999)).Return(nil, nil)
 
-		service := services.NewChartService(mockChartRepo, mockAppRepo, mockFieldRepo, mockDynamicQuery, mockDSRepo, mockExternalQuery)
+		service := services.NewChartService(mockChartRepo, mockAppRepo, mockFieldRepo, mockDynamicQuery, mockDSRepo, mockExternalQuery, newTestPermissionService(mockAppRepo))
 
 		err := service.DeleteChartConfig(ctx, 999)
 		assert.ErrorIs(t, err, services.ErrChartConfigNotFound)
@@ -10,18 +10,21 @@ import (
 
 // DashboardWidgetService ダッシュボードウィジェットサービスの実装
 type DashboardWidgetService struct {
-	widgetRepo repositories.DashboardWidgetRepositoryInterface
-	appRepo    repositories.AppRepositoryInterface
+	widgetRepo  repositories.DashboardWidgetRepositoryInterface
+	appRepo     repositories.AppRepositoryInterface
+	permissions PermissionServiceInterface
 }
 
 // NewDashboardWidgetService 新しいDashboardWidgetServiceを作成
 func NewDashboardWidgetService(
 	widgetRepo repositories.DashboardWidgetRepositoryInterface,
 	appRepo repositories.AppRepositoryInterface,
+	permissions PermissionServiceInterface,
 ) *DashboardWidgetService {
 	return &DashboardWidgetService{
-		widgetRepo: widgetRepo,
-		appRepo:    appRepo,
+		widgetRepo:  widgetRepo,
+		appRepo:     appRepo,
+		permissions: permissions,
 	}
 }
 
@@ -32,14 +35,7 @@ func (s *DashboardWidgetService) GetWidgets(ctx context.Context, userID uint64)
 		return nil, err
 	}
 
-	response := &models.DashboardWidgetListResponse{
-		Widgets: make([]models.DashboardWidgetResponse, len(widgets)),
-	}
-	for i := range widgets {
-		response.Widgets[i] = *widgets[i].ToResponse()
-	}
-
-	return response, nil
+	return s.toListResponse(ctx, widgets)
 }
 
 // GetVisibleWidgets ユーザーの表示中ダッシュボードウィジェット一覧を取得
@@ -49,11 +45,26 @@ func (s *DashboardWidgetService) GetVisibleWidgets(ctx context.Context, userID u
 		return nil, err
 	}
 
+	return s.toListResponse(ctx, widgets)
+}
+
+// toListResponse 閲覧できるアプリのウィジェットのみを一覧のレスポンスに変換する
+// 権限を外されたアプリのウィジェットは削除せず、一覧にも含めない
+func (s *DashboardWidgetService) toListResponse(ctx context.Context, widgets []models.DashboardWidget) (*models.DashboardWidgetListResponse, error) {
 	response := &models.DashboardWidgetListResponse{
-		Widgets: make([]models.DashboardWidgetResponse, len(widgets)),
+		Widgets: make([]models.DashboardWidgetResponse, 0, len(widgets)),
 	}
 	for i := range widgets {
-		response.Widgets[i] = *widgets[i].ToResponse()
+		if widgets[i].App == nil {
+			continue
+		}
+		if _, err := s.permissions.CheckAppAccess(ctx, widgets[i].App, models.AppRoleViewer); err != nil {
+			if errors.Is(err, ErrAppNotFound) || errors.Is(err, ErrPermissionDenied) {
+				continue
+			}
+			return nil, err
+		}
+		response.Widgets = append(response.Widgets, *widgets[i].ToResponse())
 	}
 
 	return response, nil
@@ -61,14 +72,11 @@ func (s *DashboardWidgetService) GetVisibleWidgets(ctx context.Context, userID u
 
 // CreateWidget 新しいダッシュボードウィジェットを作成
 func (s *DashboardWidgetService) CreateWidget(ctx context.Context, userID uint64, req *models.CreateDashboardWidgetRequest) (*models.DashboardWidgetResponse, error) {
-	// アプリの存在確認
-	app, err := s.appRepo.GetByID(ctx, req.AppID)
+	// アプリの存在と閲覧権限を確認
+	app, _, err := s.permissions.AuthorizeApp(ctx, req.AppID, models.AppRoleViewer)
 	if err != nil {
 		return nil, err
 	}
-	if app == nil {
-		return nil, errors.New("指定されたアプリが見つかりません")
-	}
 
 	// 既に同じアプリのウィジェットが存在するか確認
 	exists, err := s.widgetRepo.Exists(ctx, userID, req.AppID)
@@ -125,7 +133,8 @@ func (s *DashboardWidgetService) CreateWidget(ctx context.Context, userID uint64
 }
 
 // UpdateWidget ダッシュボードウィジェットを更新
-func (s *DashboardWidgetService) UpdateWidget(ctx context.Context, userID, widgetID uint64, req *models.UpdateDashboardWidgetRequest) (*models.DashboardWidgetResponse, error) {
+// version が0でなく現在のバージョンと異なる場合は VersionConflictError を返す
+func (s *DashboardWidgetService) UpdateWidget(ctx context.Context, userID, widgetID uint64, version int64, req *models.UpdateDashboardWidgetRequest) (*models.DashboardWidgetResponse, error) {
 	// ウィジェットの取得と所有権確認
 	widget, err := s.widgetRepo.GetByID(ctx, widgetID)
 	if err != nil {
@@ -137,6 +146,12 @@ func (s *DashboardWidgetService) UpdateWidget(ctx context.Context, userID, widge
 	if widget.UserID != userID {
 		return nil, errors.New("このウィジェットを更新する権限がありません")
 	}
+	if _, _, err := s.permissions.AuthorizeApp(ctx, widget.AppID, models.AppRoleViewer); err != nil {
+		return nil, err
+	}
+	if err := checkVersion(version, models.VersionOf(widget.UpdatedAt), widget.ToResponse()); err != nil {
+		return nil, err
+	}
 
 	// リクエストから値を更新
 	if req.DisplayOrder != nil {
@@ -155,7 +170,16 @@ func (s *DashboardWidgetService) UpdateWidget(ctx context.Context, userID, widge
 		widget.Config = req.Config
 	}
 
-	if err := s.widgetRepo.Update(ctx, widget); err != nil {
+	// バージョンを指定した場合は、取得した後に他の更新がなかったときのみ更新する
+	if version != 0 {
+		updated, err := s.widgetRepo.UpdateIfVersion(ctx, widget, version)
+		if err != nil {
+			return nil, err
+		}
+		if !updated {
+			return nil, s.widgetVersionConflict(ctx, widgetID)
+		}
+	} else if err := s.widgetRepo.Update(ctx, widget); err != nil {
 		return nil, err
 	}
 
@@ -170,7 +194,8 @@ func (s *DashboardWidgetService) UpdateWidget(ctx context.Context, userID, widge
 }
 
 // DeleteWidget ダッシュボードウィジェットを削除
-func (s *DashboardWidgetService) DeleteWidget(ctx context.Context, userID, widgetID uint64) error {
+// version が0でなく現在のバージョンと異なる場合は VersionConflictError を返す
+func (s *DashboardWidgetService) DeleteWidget(ctx context.Context, userID, widgetID uint64, version int64) error {
 	// ウィジェットの取得と所有権確認
 	widget, err := s.widgetRepo.GetByID(ctx, widgetID)
 	if err != nil {
@@ -182,10 +207,37 @@ func (s *DashboardWidgetService) DeleteWidget(ctx context.Context, userID, widge
 	if widget.UserID != userID {
 		return errors.New("このウィジェットを削除する権限がありません")
 	}
+	if err := checkVersion(version, models.VersionOf(widget.UpdatedAt), widget.ToResponse()); err != nil {
+		return err
+	}
 
+	// バージョンを指定した場合は、取得した後に他の更新がなかったときのみ削除する
+	if version != 0 {
+		deleted, err := s.widgetRepo.DeleteIfVersion(ctx, widgetID, version)
+		if err != nil {
+			return err
+		}
+		if !deleted {
+			return s.widgetVersionConflict(ctx, widgetID)
+		}
+		return nil
+	}
 	return s.widgetRepo.Delete(ctx, widgetID)
 }
 
+// widgetVersionConflict 現在のウィジェットを取得し、バージョンの不一致を表すエラーを返す
+// 競合した更新でウィジェットが削除されていた場合は見つからないエラーを返す
+func (s *DashboardWidgetService) widgetVersionConflict(ctx context.Context, widgetID uint64) error {
+	current, err := s.widgetRepo.GetByID(ctx, widgetID)
+	if err != nil {
+		return err
+	}
+	if current == nil {
+		return errors.New("ウィジェットが見つかりません")
+	}
+	return &VersionConflictError{Version: models.VersionOf(current.UpdatedAt), Current: current.ToResponse()}
+}
+
 // ReorderWidgets ウィジェットの並び順を更新
 func (s *DashboardWidgetService) ReorderWidgets(ctx context.Context, userID uint64, req *models.ReorderWidgetsRequest) error {
 	// 指定されたウィジェットIDが全てユーザーのものか確認
@@ -221,6 +273,9 @@ func (s *DashboardWidgetService) ToggleVisibility(ctx context.Context, userID, w
 	if widget.UserID != userID {
 		return nil, errors.New("このウィジェットを更新する権限がありません")
 	}
+	if _, _, err := s.permissions.AuthorizeApp(ctx, widget.AppID, models.AppRoleViewer); err != nil {
+		return nil, err
+	}
 
 	// 表示状態を切り替え
 	widget.IsVisible = !widget.IsVisible
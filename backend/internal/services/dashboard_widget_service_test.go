@@ -9,10 +9,16 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 
+	"nocode-app/backend/internal/middleware"
 	"nocode-app/backend/internal/models"
 	"nocode-app/backend/internal/testhelpers/mocks"
+	"nocode-app/backend/internal/utils"
 )
 
+func widgetUserContext(userID uint64) context.Context {
+	return middleware.SetUserInContext(context.Background(), &utils.JWTClaims{UserID: userID, Role: "user"})
+}
+
 // TestDashboardWidgetService_GetWidgets GetWidgetsメソッドのテスト
 func TestDashboardWidgetService_GetWidgets(t *testing.T) {
 	tests := []struct {
@@ -35,7 +41,7 @@ func TestDashboardWidgetService_GetWidgets(t *testing.T) {
 						ViewType:     models.WidgetViewTypeTable,
 						IsVisible:    true,
 						WidgetSize:   models.WidgetSizeMedium,
-						App:          &models.App{ID: 1, Name: "Test App"},
+						App:          &models.App{ID: 1, Name: "Test App", CreatedBy: 1},
 					},
 					{
 						ID:           2,
@@ -45,7 +51,7 @@ func TestDashboardWidgetService_GetWidgets(t *testing.T) {
 						ViewType:     models.WidgetViewTypeList,
 						IsVisible:    false,
 						WidgetSize:   models.WidgetSizeLarge,
-						App:          &models.App{ID: 2, Name: "Test App 2"},
+						App:          &models.App{ID: 2, Name: "Test App 2", CreatedBy: 1},
 					},
 				}, nil)
 			},
@@ -78,8 +84,8 @@ func TestDashboardWidgetService_GetWidgets(t *testing.T) {
 			mockAppRepo := new(mocks.MockAppRepository)
 			tt.mockSetup(mockWidgetRepo)
 
-			service := NewDashboardWidgetService(mockWidgetRepo, mockAppRepo)
-			result, err := service.GetWidgets(context.Background(), tt.userID)
+			service := NewDashboardWidgetService(mockWidgetRepo, mockAppRepo, NewPermissionService(new(mocks.MockAppPermissionRepository), new(mocks.MockGroupRepository), mockAppRepo, new(mocks.MockUserRepository)))
+			result, err := service.GetWidgets(widgetUserContext(tt.userID), tt.userID)
 
 			if tt.expectedError {
 				assert.Error(t, err)
@@ -117,7 +123,7 @@ func TestDashboardWidgetService_GetVisibleWidgets(t *testing.T) {
 						ViewType:     models.WidgetViewTypeTable,
 						IsVisible:    true,
 						WidgetSize:   models.WidgetSizeMedium,
-						App:          &models.App{ID: 1, Name: "Test App"},
+						App:          &models.App{ID: 1, Name: "Test App", CreatedBy: 1},
 					},
 				}, nil)
 			},
@@ -141,8 +147,8 @@ func TestDashboardWidgetService_GetVisibleWidgets(t *testing.T) {
 			mockAppRepo := new(mocks.MockAppRepository)
 			tt.mockSetup(mockWidgetRepo)
 
-			service := NewDashboardWidgetService(mockWidgetRepo, mockAppRepo)
-			result, err := service.GetVisibleWidgets(context.Background(), tt.userID)
+			service := NewDashboardWidgetService(mockWidgetRepo, mockAppRepo, NewPermissionService(new(mocks.MockAppPermissionRepository), new(mocks.MockGroupRepository), mockAppRepo, new(mocks.MockUserRepository)))
+			result, err := service.GetVisibleWidgets(widgetUserContext(tt.userID), tt.userID)
 
 			if tt.expectedError {
 				assert.Error(t, err)
@@ -177,8 +183,9 @@ func TestDashboardWidgetService_CreateWidget(t *testing.T) {
 			},
 			mockSetup: func(mr *mocks.MockDashboardWidgetRepository, ma *mocks.MockAppRepository) {
 				ma.On("GetByID", mock.Anything, uint64(1)).Return(&models.App{
-					ID:   1,
-					Name: "Test App",
+					ID:        1,
+					Name:      "Test App",
+					CreatedBy: 1,
 				}, nil)
 				mr.On("Exists", mock.Anything, uint64(1), uint64(1)).Return(false, nil)
 				mr.On("GetMaxDisplayOrder", mock.Anything, uint64(1)).Return(0, nil)
@@ -217,8 +224,9 @@ func TestDashboardWidgetService_CreateWidget(t *testing.T) {
 			},
 			mockSetup: func(mr *mocks.MockDashboardWidgetRepository, ma *mocks.MockAppRepository) {
 				ma.On("GetByID", mock.Anything, uint64(1)).Return(&models.App{
-					ID:   1,
-					Name: "Test App",
+					ID:        1,
+					Name:      "Test App",
+					CreatedBy: 1,
 				}, nil)
 				mr.On("Exists", mock.Anything, uint64(1), uint64(1)).Return(true, nil)
 			},
@@ -233,8 +241,8 @@ func TestDashboardWidgetService_CreateWidget(t *testing.T) {
 			mockAppRepo := new(mocks.MockAppRepository)
 			tt.mockSetup(mockWidgetRepo, mockAppRepo)
 
-			service := NewDashboardWidgetService(mockWidgetRepo, mockAppRepo)
-			result, err := service.CreateWidget(context.Background(), tt.userID, tt.req)
+			service := NewDashboardWidgetService(mockWidgetRepo, mockAppRepo, NewPermissionService(new(mocks.MockAppPermissionRepository), new(mocks.MockGroupRepository), mockAppRepo, new(mocks.MockUserRepository)))
+			result, err := service.CreateWidget(widgetUserContext(tt.userID), tt.userID, tt.req)
 
 			if tt.expectedError {
 				assert.Error(t, err)
@@ -261,6 +269,7 @@ func TestDashboardWidgetService_UpdateWidget(t *testing.T) {
 		name          string
 		userID        uint64
 		widgetID      uint64
+		version       int64
 		req           *models.UpdateDashboardWidgetRequest
 		mockSetup     func(*mocks.MockDashboardWidgetRepository, *mocks.MockAppRepository)
 		expectedError bool
@@ -284,6 +293,7 @@ func TestDashboardWidgetService_UpdateWidget(t *testing.T) {
 					IsVisible:    true,
 					WidgetSize:   models.WidgetSizeMedium,
 				}, nil)
+				ma.On("GetByID", mock.Anything, uint64(1)).Return(&models.App{ID: 1, CreatedBy: 1}, nil)
 				mr.On("Update", mock.Anything, mock.Anything).Return(nil)
 				ma.On("GetByIDWithFields", mock.Anything, uint64(1)).Return(&models.App{
 					ID:   1,
@@ -318,6 +328,49 @@ func TestDashboardWidgetService_UpdateWidget(t *testing.T) {
 			expectedError: true,
 			errorContains: "権限がありません",
 		},
+		{
+			name:     "異常系_取得後に変更されている",
+			userID:   1,
+			widgetID: 1,
+			version:  1,
+			req:      &models.UpdateDashboardWidgetRequest{},
+			mockSetup: func(mr *mocks.MockDashboardWidgetRepository, ma *mocks.MockAppRepository) {
+				mr.On("GetByID", mock.Anything, uint64(1)).Return(&models.DashboardWidget{
+					ID:        1,
+					UserID:    1,
+					AppID:     1,
+					UpdatedAt: time.Date(2026, 10, 16, 9, 0, 0, 0, time.UTC),
+				}, nil)
+				ma.On("GetByID", mock.Anything, uint64(1)).Return(&models.App{ID: 1, CreatedBy: 1}, nil)
+			},
+			expectedError: true,
+			errorContains: "他のユーザーによって変更されています",
+		},
+		{
+			name:     "異常系_取得後から更新までに変更された",
+			userID:   1,
+			widgetID: 1,
+			version:  models.VersionOf(time.Date(2026, 10, 16, 9, 0, 0, 0, time.UTC)),
+			req:      &models.UpdateDashboardWidgetRequest{ViewType: viewTypeChart},
+			mockSetup: func(mr *mocks.MockDashboardWidgetRepository, ma *mocks.MockAppRepository) {
+				mr.On("GetByID", mock.Anything, uint64(1)).Return(&models.DashboardWidget{
+					ID:        1,
+					UserID:    1,
+					AppID:     1,
+					UpdatedAt: time.Date(2026, 10, 16, 9, 0, 0, 0, time.UTC),
+				}, nil).Once()
+				ma.On("GetByID", mock.Anything, uint64(1)).Return(&models.App{ID: 1, CreatedBy: 1}, nil)
+				mr.On("UpdateIfVersion", mock.Anything, mock.Anything, models.VersionOf(time.Date(2026, 10, 16, 9, 0, 0, 0, time.UTC))).Return(false, nil)
+				mr.On("GetByID", mock.Anything, uint64(1)).Return(&models.DashboardWidget{
+					ID:        1,
+					UserID:    1,
+					AppID:     1,
+					UpdatedAt: time.Date(2026, 10, 16, 9, 5, 0, 0, time.UTC),
+				}, nil).Once()
+			},
+			expectedError: true,
+			errorContains: "他のユーザーによって変更されています",
+		},
 	}
 
 	for _, tt := range tests {
@@ -326,8 +379,8 @@ func TestDashboardWidgetService_UpdateWidget(t *testing.T) {
 			mockAppRepo := new(mocks.MockAppRepository)
 			tt.mockSetup(mockWidgetRepo, mockAppRepo)
 
-			service := NewDashboardWidgetService(mockWidgetRepo, mockAppRepo)
-			result, err := service.UpdateWidget(context.Background(), tt.userID, tt.widgetID, tt.req)
+			service := NewDashboardWidgetService(mockWidgetRepo, mockAppRepo, NewPermissionService(new(mocks.MockAppPermissionRepository), new(mocks.MockGroupRepository), mockAppRepo, new(mocks.MockUserRepository)))
+			result, err := service.UpdateWidget(widgetUserContext(tt.userID), tt.userID, tt.widgetID, tt.version, tt.req)
 
 			if tt.expectedError {
 				assert.Error(t, err)
@@ -401,8 +454,8 @@ func TestDashboardWidgetService_DeleteWidget(t *testing.T) {
 			mockAppRepo := new(mocks.MockAppRepository)
 			tt.mockSetup(mockWidgetRepo)
 
-			service := NewDashboardWidgetService(mockWidgetRepo, mockAppRepo)
-			err := service.DeleteWidget(context.Background(), tt.userID, tt.widgetID)
+			service := NewDashboardWidgetService(mockWidgetRepo, mockAppRepo, NewPermissionService(new(mocks.MockAppPermissionRepository), new(mocks.MockGroupRepository), mockAppRepo, new(mocks.MockUserRepository)))
+			err := service.DeleteWidget(widgetUserContext(tt.userID), tt.userID, tt.widgetID, 0)
 
 			if tt.expectedError {
 				assert.Error(t, err)
@@ -466,8 +519,8 @@ func TestDashboardWidgetService_ReorderWidgets(t *testing.T) {
 			mockAppRepo := new(mocks.MockAppRepository)
 			tt.mockSetup(mockWidgetRepo)
 
-			service := NewDashboardWidgetService(mockWidgetRepo, mockAppRepo)
-			err := service.ReorderWidgets(context.Background(), tt.userID, tt.req)
+			service := NewDashboardWidgetService(mockWidgetRepo, mockAppRepo, NewPermissionService(new(mocks.MockAppPermissionRepository), new(mocks.MockGroupRepository), mockAppRepo, new(mocks.MockUserRepository)))
+			err := service.ReorderWidgets(widgetUserContext(tt.userID), tt.userID, tt.req)
 
 			if tt.expectedError {
 				assert.Error(t, err)
@@ -505,6 +558,7 @@ func TestDashboardWidgetService_ToggleVisibility(t *testing.T) {
 					AppID:     1,
 					IsVisible: true,
 				}, nil)
+				ma.On("GetByID", mock.Anything, uint64(1)).Return(&models.App{ID: 1, CreatedBy: 1}, nil)
 				mr.On("Update", mock.Anything, mock.Anything).Return(nil)
 				ma.On("GetByIDWithFields", mock.Anything, uint64(1)).Return(&models.App{
 					ID:   1,
@@ -525,6 +579,7 @@ func TestDashboardWidgetService_ToggleVisibility(t *testing.T) {
 					AppID:     1,
 					IsVisible: false,
 				}, nil)
+				ma.On("GetByID", mock.Anything, uint64(1)).Return(&models.App{ID: 1, CreatedBy: 1}, nil)
 				mr.On("Update", mock.Anything, mock.Anything).Return(nil)
 				ma.On("GetByIDWithFields", mock.Anything, uint64(1)).Return(&models.App{
 					ID:   1,
@@ -552,8 +607,8 @@ func TestDashboardWidgetService_ToggleVisibility(t *testing.T) {
 			mockAppRepo := new(mocks.MockAppRepository)
 			tt.mockSetup(mockWidgetRepo, mockAppRepo)
 
-			service := NewDashboardWidgetService(mockWidgetRepo, mockAppRepo)
-			result, err := service.ToggleVisibility(context.Background(), tt.userID, tt.widgetID)
+			service := NewDashboardWidgetService(mockWidgetRepo, mockAppRepo, NewPermissionService(new(mocks.MockAppPermissionRepository), new(mocks.MockGroupRepository), mockAppRepo, new(mocks.MockUserRepository)))
+			result, err := service.ToggleVisibility(widgetUserContext(tt.userID), tt.userID, tt.widgetID)
 
 			if tt.expectedError {
 				assert.Error(t, err)
@@ -572,6 +627,95 @@ func TestDashboardWidgetService_ToggleVisibility(t *testing.T) {
 	}
 }
 
+// TestDashboardWidgetService_DeniedApp 閲覧権限のないアプリのウィジェット操作のテスト
+func TestDashboardWidgetService_DeniedApp(t *testing.T) {
+	deniedApp := &models.App{ID: 2, Name: "Secret App", CreatedBy: 10}
+
+	t.Run("一覧から閲覧できないアプリのウィジェットを除外する", func(t *testing.T) {
+		mockWidgetRepo := new(mocks.MockDashboardWidgetRepository)
+		mockAppRepo := new(mocks.MockAppRepository)
+		mockPermRepo := new(mocks.MockAppPermissionRepository)
+		mockGroupRepo := new(mocks.MockGroupRepository)
+		mockWidgetRepo.On("GetByUserIDWithApps", mock.Anything, uint64(1)).Return([]models.DashboardWidget{
+			{ID: 1, UserID: 1, AppID: 1, App: &models.App{ID: 1, Name: "Test App", CreatedBy: 1}},
+			{ID: 2, UserID: 1, AppID: 2, App: deniedApp},
+		}, nil)
+		mockPermRepo.On("HasAny", mock.Anything, uint64(2)).Return(true, nil)
+		mockGroupRepo.On("GetGroupIDsByUserID", mock.Anything, uint64(1)).Return([]uint64{}, nil)
+		mockPermRepo.On("GetForSubjects", mock.Anything, uint64(2), uint64(1), []uint64{}).Return([]models.AppPermission{}, nil)
+
+		service := NewDashboardWidgetService(mockWidgetRepo, mockAppRepo, NewPermissionService(mockPermRepo, mockGroupRepo, mockAppRepo, new(mocks.MockUserRepository)))
+		result, err := service.GetWidgets(widgetUserContext(1), 1)
+
+		assert.NoError(t, err)
+		assert.Len(t, result.Widgets, 1)
+		assert.Equal(t, uint64(1), result.Widgets[0].ID)
+		mockWidgetRepo.AssertExpectations(t)
+		mockPermRepo.AssertExpectations(t)
+		mockGroupRepo.AssertExpectations(t)
+	})
+
+	t.Run("閲覧できないアプリのウィジェットは作成できない", func(t *testing.T) {
+		mockWidgetRepo := new(mocks.MockDashboardWidgetRepository)
+		mockAppRepo := new(mocks.MockAppRepository)
+		mockPermRepo := new(mocks.MockAppPermissionRepository)
+		mockGroupRepo := new(mocks.MockGroupRepository)
+		mockAppRepo.On("GetByID", mock.Anything, uint64(2)).Return(deniedApp, nil)
+		mockPermRepo.On("HasAny", mock.Anything, uint64(2)).Return(true, nil)
+		mockGroupRepo.On("GetGroupIDsByUserID", mock.Anything, uint64(1)).Return([]uint64{}, nil)
+		mockPermRepo.On("GetForSubjects", mock.Anything, uint64(2), uint64(1), []uint64{}).Return([]models.AppPermission{}, nil)
+
+		service := NewDashboardWidgetService(mockWidgetRepo, mockAppRepo, NewPermissionService(mockPermRepo, mockGroupRepo, mockAppRepo, new(mocks.MockUserRepository)))
+		result, err := service.CreateWidget(widgetUserContext(1), 1, &models.CreateDashboardWidgetRequest{AppID: 2})
+
+		assert.ErrorIs(t, err, ErrAppNotFound)
+		assert.Nil(t, result)
+		mockWidgetRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+		mockAppRepo.AssertExpectations(t)
+		mockPermRepo.AssertExpectations(t)
+	})
+
+	t.Run("閲覧できないアプリのウィジェットは更新できない", func(t *testing.T) {
+		mockWidgetRepo := new(mocks.MockDashboardWidgetRepository)
+		mockAppRepo := new(mocks.MockAppRepository)
+		mockPermRepo := new(mocks.MockAppPermissionRepository)
+		mockGroupRepo := new(mocks.MockGroupRepository)
+		mockWidgetRepo.On("GetByID", mock.Anything, uint64(2)).Return(&models.DashboardWidget{ID: 2, UserID: 1, AppID: 2}, nil)
+		mockAppRepo.On("GetByID", mock.Anything, uint64(2)).Return(deniedApp, nil)
+		mockPermRepo.On("HasAny", mock.Anything, uint64(2)).Return(true, nil)
+		mockGroupRepo.On("GetGroupIDsByUserID", mock.Anything, uint64(1)).Return([]uint64{}, nil)
+		mockPermRepo.On("GetForSubjects", mock.Anything, uint64(2), uint64(1), []uint64{}).Return([]models.AppPermission{}, nil)
+
+		service := NewDashboardWidgetService(mockWidgetRepo, mockAppRepo, NewPermissionService(mockPermRepo, mockGroupRepo, mockAppRepo, new(mocks.MockUserRepository)))
+		result, err := service.UpdateWidget(widgetUserContext(1), 1, 2, 0, &models.UpdateDashboardWidgetRequest{ViewType: "chart"})
+
+		assert.ErrorIs(t, err, ErrAppNotFound)
+		assert.Nil(t, result)
+		mockWidgetRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+		mockAppRepo.AssertNotCalled(t, "GetByIDWithFields", mock.Anything, mock.Anything)
+	})
+
+	t.Run("閲覧できないアプリのウィジェットは表示を切り替えられない", func(t *testing.T) {
+		mockWidgetRepo := new(mocks.MockDashboardWidgetRepository)
+		mockAppRepo := new(mocks.MockAppRepository)
+		mockPermRepo := new(mocks.MockAppPermissionRepository)
+		mockGroupRepo := new(mocks.MockGroupRepository)
+		mockWidgetRepo.On("GetByID", mock.Anything, uint64(2)).Return(&models.DashboardWidget{ID: 2, UserID: 1, AppID: 2, IsVisible: true}, nil)
+		mockAppRepo.On("GetByID", mock.Anything, uint64(2)).Return(deniedApp, nil)
+		mockPermRepo.On("HasAny", mock.Anything, uint64(2)).Return(true, nil)
+		mockGroupRepo.On("GetGroupIDsByUserID", mock.Anything, uint64(1)).Return([]uint64{}, nil)
+		mockPermRepo.On("GetForSubjects", mock.Anything, uint64(2), uint64(1), []uint64{}).Return([]models.AppPermission{}, nil)
+
+		service := NewDashboardWidgetService(mockWidgetRepo, mockAppRepo, NewPermissionService(mockPermRepo, mockGroupRepo, mockAppRepo, new(mocks.MockUserRepository)))
+		result, err := service.ToggleVisibility(widgetUserContext(1), 1, 2)
+
+		assert.ErrorIs(t, err, ErrAppNotFound)
+		assert.Nil(t, result)
+		mockWidgetRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+		mockAppRepo.AssertNotCalled(t, "GetByIDWithFields", mock.Anything, mock.Anything)
+	})
+}
+
 // TestWidgetViewType_IsValid WidgetViewTypeのバリデーションテスト
 func TestWidgetViewType_IsValid(t *testing.T) {
 	tests := []struct {
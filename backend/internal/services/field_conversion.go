@@ -0,0 +1,187 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"nocode-app/backend/internal/models"
+	"nocode-app/backend/internal/repositories"
+)
+
+// フィールドの種類の変換関連エラー
+var (
+	ErrInvalidConversion   = errors.New("このフィールドの種類には変換できません")
+	ErrUnconvertibleValues = errors.New("変換できない値があります")
+)
+
+const (
+	// conversionSampleLimit 変換の見込みで返す変換できない値の例の数
+	conversionSampleLimit = 10
+	// backupFieldNameSuffix 変換できない値を保存するフィールドの名前に付ける接尾辞
+	backupFieldNameSuffix = "（変換前）"
+	// maxFieldNameLength フィールド名の最大文字数
+	maxFieldNameLength = 100
+)
+
+// PreviewFieldConversion フィールドの種類を変換した場合に値を変換できるレコード数と、変換できない値の例を返す
+func (s *FieldService) PreviewFieldConversion(ctx context.Context, appID, fieldID uint64, req *models.ConvertFieldRequest) (*models.FieldConversionPreview, error) {
+	app, conversion, _, err := s.prepareConversion(ctx, appID, fieldID, req)
+	if err != nil {
+		return nil, err
+	}
+	return s.dynamicQuery.PreviewConversion(ctx, app.TableName, conversion, conversionSampleLimit)
+}
+
+// ConvertField フィールドの種類を変換し、既存の値を変換後の種類に変換する
+// 変換できない値がある場合、backup_field_code を指定すると変換前の文字列を新しい複数行テキストフィールドに保存し、
+// discard_invalid を指定すると空にする。どちらも指定しない場合は変換しない
+func (s *FieldService) ConvertField(ctx context.Context, appID, fieldID uint64, req *models.ConvertFieldRequest) (*models.ConvertFieldResponse, error) {
+	app, conversion, siblings, err := s.prepareConversion(ctx, appID, fieldID, req)
+	if err != nil {
+		return nil, err
+	}
+	conversion.DiscardInvalid = req.DiscardInvalid
+
+	if req.BackupFieldCode != "" {
+		exists, err := s.fieldRepo.FieldCodeExists(ctx, appID, req.BackupFieldCode)
+		if err != nil {
+			return nil, err
+		}
+		if exists {
+			return nil, ErrFieldCodeExists
+		}
+		maxOrder, err := s.fieldRepo.GetMaxDisplayOrder(ctx, appID)
+		if err != nil {
+			return nil, err
+		}
+		now := time.Now()
+		conversion.Backup = &models.AppField{
+			AppID:        appID,
+			FieldCode:    req.BackupFieldCode,
+			FieldName:    backupFieldName(conversion.From.FieldName),
+			FieldType:    string(models.FieldTypeTextArea),
+			DisplayOrder: maxOrder + 1,
+			CreatedAt:    now,
+			UpdatedAt:    now,
+		}
+	}
+
+	// カラムの変換・全文検索用カラムの作り直し・変更の通知をまとめて行う
+	var resp *models.ConvertFieldResponse
+	err = s.transactor.RunInTx(ctx, func(ctx context.Context) error {
+		// 全文検索用カラムは検索対象のカラムを使うため、変換するフィールドを除いて先に作り直す
+		searchable := models.IsSearchableField(&conversion.From)
+		if searchable {
+			remaining := make([]models.AppField, 0, len(siblings))
+			for i := range siblings {
+				if siblings[i].ID != fieldID {
+					remaining = append(remaining, siblings[i])
+				}
+			}
+			if err := rebuildSearchColumn(ctx, s.dynamicQuery, app, remaining); err != nil {
+				return err
+			}
+		}
+
+		unconvertible, err := s.dynamicQuery.ConvertColumn(ctx, app.TableName, conversion)
+		if err != nil {
+			if errors.Is(err, repositories.ErrUnconvertibleValues) {
+				return fmt.Errorf("%w: %d件の値を%sに変換できません", ErrUnconvertibleValues, unconvertible, conversion.To.FieldType)
+			}
+			return duplicateValueError(err, []models.AppField{conversion.To})
+		}
+
+		if searchable || models.IsSearchableField(&conversion.To) || conversion.Backup != nil {
+			if err := s.rebuildSearchColumn(ctx, app); err != nil {
+				return err
+			}
+		}
+
+		resp = &models.ConvertFieldResponse{
+			Field:         conversion.To.ToResponse(),
+			Unconvertible: unconvertible,
+		}
+		events := []models.WebhookEvent{fieldEvent(models.WebhookEventFieldUpdated, resp.Field)}
+		if conversion.Backup != nil {
+			resp.BackupField = conversion.Backup.ToResponse()
+			events = append(events, fieldEvent(models.WebhookEventFieldCreated, resp.BackupField))
+		}
+		return s.webhooks.Publish(ctx, events...)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// prepareConversion 変換するフィールドと変換後の種類・オプションを検証し、変換内容とアプリの全フィールドを返す
+func (s *FieldService) prepareConversion(ctx context.Context, appID, fieldID uint64, req *models.ConvertFieldRequest) (*models.App, *models.FieldConversion, []models.AppField, error) {
+	field, err := s.fieldRepo.GetByID(ctx, fieldID)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	// 別のアプリのフィールドは変換不可
+	if field == nil || field.AppID != appID {
+		return nil, nil, nil, ErrFieldNotFound
+	}
+
+	app, _, err := s.permissions.AuthorizeApp(ctx, appID, models.AppRoleOwner)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	// 外部データソースのカラムの型は変更できない
+	if app.IsExternal {
+		return nil, nil, nil, ErrExternalAppReadOnly
+	}
+
+	if field.FieldType == req.FieldType {
+		return nil, nil, nil, fmt.Errorf("%w: 変換前と同じ種類です", ErrInvalidConversion)
+	}
+	if !field.CanConvertTo(models.FieldType(req.FieldType)) {
+		return nil, nil, nil, fmt.Errorf("%w: %sから%sには変換できません", ErrInvalidConversion, field.FieldType, req.FieldType)
+	}
+
+	// 計算式・ルックアップ・集計から使われているフィールドは、値の型が変わると使えなくなるため変換できない
+	siblings, err := s.fieldRepo.GetByAppID(ctx, appID)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	if isUsedByFormula(siblings, field.FieldCode) {
+		return nil, nil, nil, fmt.Errorf("%w: 計算式から使用されています", ErrInvalidConversion)
+	}
+	if err := s.references().checkFieldInUse(ctx, field); err != nil {
+		if errors.Is(err, ErrFieldInUse) {
+			return nil, nil, nil, fmt.Errorf("%w: 他のフィールドから使用されています", ErrInvalidConversion)
+		}
+		return nil, nil, nil, err
+	}
+
+	converted := *field
+	converted.FieldType = req.FieldType
+	if req.Options != nil {
+		converted.Options = req.Options
+	}
+	converted.UpdatedAt = time.Now()
+	if err := validateUniqueOption(app, &converted); err != nil {
+		return nil, nil, nil, err
+	}
+
+	conversion := &models.FieldConversion{From: *field, To: converted}
+	switch models.FieldType(converted.FieldType) {
+	case models.FieldTypeSelect, models.FieldTypeRadio, models.FieldTypeMultiSelect:
+		conversion.Choices, _ = optionChoices(converted.Options)
+	}
+	return app, conversion, siblings, nil
+}
+
+// backupFieldName 変換できない値を保存するフィールドの名前を返す（フィールド名の最大文字数に収める）
+func backupFieldName(name string) string {
+	runes := []rune(name)
+	suffix := []rune(backupFieldNameSuffix)
+	if len(runes)+len(suffix) > maxFieldNameLength {
+		runes = runes[:maxFieldNameLength-len(suffix)]
+	}
+	return string(runes) + backupFieldNameSuffix
+}
@@ -0,0 +1,335 @@
+package services_test
+
+import (
+	"testing"
+
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"nocode-app/backend/internal/models"
+	"nocode-app/backend/internal/repositories"
+	"nocode-app/backend/internal/services"
+	"nocode-app/backend/internal/testhelpers/mocks"
+)
+
+func conversionTestFields() []models.AppField {
+	return []models.AppField{
+		{ID: 1, AppID: 1, FieldCode: "amount", FieldName: "金額", FieldType: "text"},
+		{ID: 2, AppID: 1, FieldCode: "status", FieldName: "ステータス", FieldType: "text"},
+		{ID: 3, AppID: 1, FieldCode: "price", FieldName: "単価", FieldType: "number"},
+		{ID: 4, AppID: 1, FieldCode: "total", FieldName: "合計", FieldType: "formula", Options: models.FieldOptions{"expression": "price * 2"}},
+	}
+}
+
+func TestFieldService_PreviewFieldConversion(t *testing.T) {
+	ctx := systemContext()
+	app := &models.App{ID: 1, TableName: "app_data_1"}
+
+	t.Run("returns preview", func(t *testing.T) {
+		mockFieldRepo := new(mocks.MockFieldRepository)
+		mockAppRepo := new(mocks.MockAppRepository)
+		mockDynamicQuery := new(mocks.MockDynamicQueryExecutor)
+
+		fields := conversionTestFields()
+		mockFieldRepo.On("GetByID", ctx, uint64(1)).Return(&fields[0], nil).Once()
+		mockAppRepo.On("GetByID", ctx, uint64(1)).Return(app, nil).Once()
+		mockFieldRepo.On("GetByAppID", ctx, uint64(1)).Return(fields, nil).Once()
+		mockFieldRepo.On("GetReferencingFields", ctx, uint64(1)).Return([]models.AppField{}, nil).Once()
+		preview := &models.FieldConversionPreview{FieldType: "number", Total: 3, Convertible: 2, Unconvertible: 1,
+			Samples: []models.FieldConversionSample{{RecordID: 2, Value: "未定"}}}
+		mockDynamicQuery.On("PreviewConversion", ctx, "app_data_1", mock.MatchedBy(func(c *models.FieldConversion) bool {
+			return c.From.FieldType == "text" && c.To.FieldType == "number" && c.To.ID == 1 && c.Backup == nil
+		}), 10).Return(preview, nil).Once()
+
+		service := services.NewFieldService(mockFieldRepo, mockAppRepo, mockDynamicQuery, newTestPermissionService(mockAppRepo), new(mocks.MockTransactor), new(mocks.MockWebhookPublisher), new(mocks.MockAttachmentManager))
+
+		resp, err := service.PreviewFieldConversion(ctx, 1, 1, &models.ConvertFieldRequest{FieldType: "number"})
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), resp.Unconvertible)
+		mockFieldRepo.AssertExpectations(t)
+		mockAppRepo.AssertExpectations(t)
+		mockDynamicQuery.AssertExpectations(t)
+	})
+
+	t.Run("select choices restrict values", func(t *testing.T) {
+		mockFieldRepo := new(mocks.MockFieldRepository)
+		mockAppRepo := new(mocks.MockAppRepository)
+		mockDynamicQuery := new(mocks.MockDynamicQueryExecutor)
+
+		fields := conversionTestFields()
+		mockFieldRepo.On("GetByID", ctx, uint64(2)).Return(&fields[1], nil).Once()
+		mockAppRepo.On("GetByID", ctx, uint64(1)).Return(app, nil).Once()
+		mockFieldRepo.On("GetByAppID", ctx, uint64(1)).Return(fields, nil).Once()
+		mockFieldRepo.On("GetReferencingFields", ctx, uint64(1)).Return([]models.AppField{}, nil).Once()
+		mockDynamicQuery.On("PreviewConversion", ctx, "app_data_1", mock.MatchedBy(func(c *models.FieldConversion) bool {
+			return assert.ObjectsAreEqual([]string{"未着手", "完了"}, c.Choices)
+		}), 10).Return(&models.FieldConversionPreview{}, nil).Once()
+
+		service := services.NewFieldService(mockFieldRepo, mockAppRepo, mockDynamicQuery, newTestPermissionService(mockAppRepo), new(mocks.MockTransactor), new(mocks.MockWebhookPublisher), new(mocks.MockAttachmentManager))
+
+		_, err := service.PreviewFieldConversion(ctx, 1, 2, &models.ConvertFieldRequest{
+			FieldType: "select",
+			Options:   models.FieldOptions{"choices": []interface{}{"未着手", "完了"}},
+		})
+		require.NoError(t, err)
+		mockFieldRepo.AssertExpectations(t)
+		mockAppRepo.AssertExpectations(t)
+		mockDynamicQuery.AssertExpectations(t)
+	})
+
+	t.Run("invalid conversions", func(t *testing.T) {
+		tests := []struct {
+			name     string
+			fieldID  uint64
+			req      models.ConvertFieldRequest
+			siblings bool
+		}{
+			{name: "same type", fieldID: 1, req: models.ConvertFieldRequest{FieldType: "text"}},
+			{name: "unsupported type", fieldID: 3, req: models.ConvertFieldRequest{FieldType: "date"}},
+			{name: "used by formula", fieldID: 3, req: models.ConvertFieldRequest{FieldType: "text"}, siblings: true},
+			{name: "computed field", fieldID: 4, req: models.ConvertFieldRequest{FieldType: "number"}},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				mockFieldRepo := new(mocks.MockFieldRepository)
+				mockAppRepo := new(mocks.MockAppRepository)
+				mockDynamicQuery := new(mocks.MockDynamicQueryExecutor)
+
+				fields := conversionTestFields()
+				mockFieldRepo.On("GetByID", ctx, tt.fieldID).Return(&fields[tt.fieldID-1], nil).Once()
+				mockAppRepo.On("GetByID", ctx, uint64(1)).Return(app, nil).Once()
+				if tt.siblings {
+					mockFieldRepo.On("GetByAppID", ctx, uint64(1)).Return(fields, nil).Once()
+				}
+
+				service := services.NewFieldService(mockFieldRepo, mockAppRepo, mockDynamicQuery, newTestPermissionService(mockAppRepo), new(mocks.MockTransactor), new(mocks.MockWebhookPublisher), new(mocks.MockAttachmentManager))
+
+				_, err := service.PreviewFieldConversion(ctx, 1, tt.fieldID, &tt.req)
+				assert.ErrorIs(t, err, services.ErrInvalidConversion)
+				mockFieldRepo.AssertExpectations(t)
+				mockAppRepo.AssertExpectations(t)
+				mockDynamicQuery.AssertNotCalled(t, "PreviewConversion", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+			})
+		}
+	})
+
+	t.Run("unique option on unsupported type", func(t *testing.T) {
+		mockFieldRepo := new(mocks.MockFieldRepository)
+		mockAppRepo := new(mocks.MockAppRepository)
+		mockDynamicQuery := new(mocks.MockDynamicQueryExecutor)
+
+		fields := conversionTestFields()
+		mockFieldRepo.On("GetByID", ctx, uint64(1)).Return(&fields[0], nil).Once()
+		mockAppRepo.On("GetByID", ctx, uint64(1)).Return(app, nil).Once()
+		mockFieldRepo.On("GetByAppID", ctx, uint64(1)).Return(fields, nil).Once()
+		mockFieldRepo.On("GetReferencingFields", ctx, uint64(1)).Return([]models.AppField{}, nil).Once()
+
+		service := services.NewFieldService(mockFieldRepo, mockAppRepo, mockDynamicQuery, newTestPermissionService(mockAppRepo), new(mocks.MockTransactor), new(mocks.MockWebhookPublisher), new(mocks.MockAttachmentManager))
+
+		_, err := service.PreviewFieldConversion(ctx, 1, 1, &models.ConvertFieldRequest{
+			FieldType: "checkbox",
+			Options:   models.FieldOptions{"unique": true},
+		})
+		assert.ErrorIs(t, err, services.ErrInvalidFieldOptions)
+		mockFieldRepo.AssertExpectations(t)
+		mockAppRepo.AssertExpectations(t)
+		mockDynamicQuery.AssertExpectations(t)
+	})
+
+	t.Run("field of another app", func(t *testing.T) {
+		mockFieldRepo := new(mocks.MockFieldRepository)
+		mockAppRepo := new(mocks.MockAppRepository)
+		mockDynamicQuery := new(mocks.MockDynamicQueryExecutor)
+
+		fields := conversionTestFields()
+		mockFieldRepo.On("GetByID", ctx, uint64(1)).Return(&fields[0], nil).Once()
+
+		service := services.NewFieldService(mockFieldRepo, mockAppRepo, mockDynamicQuery, newTestPermissionService(mockAppRepo), new(mocks.MockTransactor), new(mocks.MockWebhookPublisher), new(mocks.MockAttachmentManager))
+
+		_, err := service.PreviewFieldConversion(ctx, 2, 1, &models.ConvertFieldRequest{FieldType: "number"})
+		assert.ErrorIs(t, err, services.ErrFieldNotFound)
+		mockFieldRepo.AssertExpectations(t)
+		mockAppRepo.AssertExpectations(t)
+		mockDynamicQuery.AssertExpectations(t)
+	})
+
+	t.Run("external app", func(t *testing.T) {
+		mockFieldRepo := new(mocks.MockFieldRepository)
+		mockAppRepo := new(mocks.MockAppRepository)
+		mockDynamicQuery := new(mocks.MockDynamicQueryExecutor)
+
+		fields := conversionTestFields()
+		mockFieldRepo.On("GetByID", ctx, uint64(1)).Return(&fields[0], nil).Once()
+		mockAppRepo.On("GetByID", ctx, uint64(1)).Return(&models.App{ID: 1, IsExternal: true}, nil).Once()
+
+		service := services.NewFieldService(mockFieldRepo, mockAppRepo, mockDynamicQuery, newTestPermissionService(mockAppRepo), new(mocks.MockTransactor), new(mocks.MockWebhookPublisher), new(mocks.MockAttachmentManager))
+
+		_, err := service.PreviewFieldConversion(ctx, 1, 1, &models.ConvertFieldRequest{FieldType: "number"})
+		assert.ErrorIs(t, err, services.ErrExternalAppReadOnly)
+		mockFieldRepo.AssertExpectations(t)
+		mockAppRepo.AssertExpectations(t)
+		mockDynamicQuery.AssertExpectations(t)
+	})
+}
+
+func TestFieldService_ConvertField(t *testing.T) {
+	ctx := systemContext()
+	app := &models.App{ID: 1, TableName: "app_data_1"}
+
+	t.Run("converts with backup field", func(t *testing.T) {
+		mockFieldRepo := new(mocks.MockFieldRepository)
+		mockAppRepo := new(mocks.MockAppRepository)
+		mockDynamicQuery := new(mocks.MockDynamicQueryExecutor)
+		mockTransactor := new(mocks.MockTransactor)
+		mockPublisher := new(mocks.MockWebhookPublisher)
+
+		fields := conversionTestFields()
+		mockFieldRepo.On("GetByID", ctx, uint64(1)).Return(&fields[0], nil).Once()
+		mockAppRepo.On("GetByID", ctx, uint64(1)).Return(app, nil).Once()
+		mockFieldRepo.On("GetByAppID", ctx, uint64(1)).Return(fields, nil).Once()
+		mockFieldRepo.On("GetReferencingFields", ctx, uint64(1)).Return([]models.AppField{}, nil).Once()
+		mockFieldRepo.On("FieldCodeExists", ctx, uint64(1), "amount_text").Return(false, nil).Once()
+		mockFieldRepo.On("GetMaxDisplayOrder", ctx, uint64(1)).Return(4, nil).Once()
+		mockTransactor.On("RunInTx", ctx).Return(nil).Once()
+		mockDynamicQuery.On("ConvertColumn", ctx, "app_data_1", mock.MatchedBy(func(c *models.FieldConversion) bool {
+			return c.To.FieldType == "number" && c.Backup != nil &&
+				c.Backup.FieldCode == "amount_text" && c.Backup.FieldName == "金額（変換前）" &&
+				c.Backup.FieldType == "textarea" && c.Backup.DisplayOrder == 5
+		})).Return(int64(2), nil).Once()
+		mockPublisher.On("Publish", ctx, mock.MatchedBy(func(events []models.WebhookEvent) bool {
+			return len(events) == 2 && events[0].Event == models.WebhookEventFieldUpdated && events[1].Event == models.WebhookEventFieldCreated
+		})).Return(nil).Once()
+
+		service := services.NewFieldService(mockFieldRepo, mockAppRepo, mockDynamicQuery, newTestPermissionService(mockAppRepo), mockTransactor, mockPublisher, new(mocks.MockAttachmentManager))
+
+		resp, err := service.ConvertField(ctx, 1, 1, &models.ConvertFieldRequest{FieldType: "number", BackupFieldCode: "amount_text"})
+		require.NoError(t, err)
+		assert.Equal(t, "number", resp.Field.FieldType)
+		assert.Equal(t, int64(2), resp.Unconvertible)
+		require.NotNil(t, resp.BackupField)
+		assert.Equal(t, "amount_text", resp.BackupField.FieldCode)
+		mockFieldRepo.AssertExpectations(t)
+		mockAppRepo.AssertExpectations(t)
+		mockDynamicQuery.AssertExpectations(t)
+		mockTransactor.AssertExpectations(t)
+		mockPublisher.AssertExpectations(t)
+	})
+
+	t.Run("rebuilds search column", func(t *testing.T) {
+		mockFieldRepo := new(mocks.MockFieldRepository)
+		mockAppRepo := new(mocks.MockAppRepository)
+		mockDynamicQuery := new(mocks.MockDynamicQueryExecutor)
+		mockTransactor := new(mocks.MockTransactor)
+		mockPublisher := new(mocks.MockWebhookPublisher)
+
+		config := models.SearchConfigSimple
+		searchApp := &models.App{ID: 1, TableName: "app_data_1", SearchConfig: &config}
+		fields := conversionTestFields()
+		mockFieldRepo.On("GetByID", ctx, uint64(1)).Return(&fields[0], nil).Once()
+		mockAppRepo.On("GetByID", ctx, uint64(1)).Return(searchApp, nil).Once()
+		mockFieldRepo.On("GetByAppID", ctx, uint64(1)).Return(fields, nil).Twice()
+		mockFieldRepo.On("GetReferencingFields", ctx, uint64(1)).Return([]models.AppField{}, nil).Once()
+		mockTransactor.On("RunInTx", ctx).Return(nil).Once()
+		mockDynamicQuery.On("SetSearchColumn", ctx, "app_data_1", mock.MatchedBy(func(fields []models.AppField) bool {
+			return len(fields) == 3
+		}), config).Return(nil).Once()
+		mockDynamicQuery.On("ConvertColumn", ctx, "app_data_1", mock.Anything).Return(int64(0), nil).Once()
+		mockDynamicQuery.On("SetSearchColumn", ctx, "app_data_1", mock.Anything, config).Return(nil).Once()
+		mockPublisher.On("Publish", ctx, mock.Anything).Return(nil).Once()
+
+		service := services.NewFieldService(mockFieldRepo, mockAppRepo, mockDynamicQuery, newTestPermissionService(mockAppRepo), mockTransactor, mockPublisher, new(mocks.MockAttachmentManager))
+
+		_, err := service.ConvertField(ctx, 1, 1, &models.ConvertFieldRequest{FieldType: "number", DiscardInvalid: true})
+		require.NoError(t, err)
+		mockFieldRepo.AssertExpectations(t)
+		mockAppRepo.AssertExpectations(t)
+		mockDynamicQuery.AssertExpectations(t)
+		mockTransactor.AssertExpectations(t)
+		mockPublisher.AssertExpectations(t)
+	})
+
+	t.Run("unconvertible values", func(t *testing.T) {
+		mockFieldRepo := new(mocks.MockFieldRepository)
+		mockAppRepo := new(mocks.MockAppRepository)
+		mockDynamicQuery := new(mocks.MockDynamicQueryExecutor)
+		mockTransactor := new(mocks.MockTransactor)
+		mockPublisher := new(mocks.MockWebhookPublisher)
+
+		fields := conversionTestFields()
+		mockFieldRepo.On("GetByID", ctx, uint64(1)).Return(&fields[0], nil).Once()
+		mockAppRepo.On("GetByID", ctx, uint64(1)).Return(app, nil).Once()
+		mockFieldRepo.On("GetByAppID", ctx, uint64(1)).Return(fields, nil).Once()
+		mockFieldRepo.On("GetReferencingFields", ctx, uint64(1)).Return([]models.AppField{}, nil).Once()
+		mockTransactor.On("RunInTx", ctx).Return(nil).Once()
+		mockDynamicQuery.On("ConvertColumn", ctx, "app_data_1", mock.MatchedBy(func(c *models.FieldConversion) bool {
+			return c.Backup == nil && !c.DiscardInvalid
+		})).Return(int64(3), repositories.ErrUnconvertibleValues).Once()
+
+		service := services.NewFieldService(mockFieldRepo, mockAppRepo, mockDynamicQuery, newTestPermissionService(mockAppRepo), mockTransactor, mockPublisher, new(mocks.MockAttachmentManager))
+
+		_, err := service.ConvertField(ctx, 1, 1, &models.ConvertFieldRequest{FieldType: "number"})
+		assert.ErrorIs(t, err, services.ErrUnconvertibleValues)
+		assert.Contains(t, err.Error(), "3件")
+		mockFieldRepo.AssertExpectations(t)
+		mockAppRepo.AssertExpectations(t)
+		mockDynamicQuery.AssertExpectations(t)
+		mockTransactor.AssertExpectations(t)
+		mockPublisher.AssertExpectations(t)
+	})
+
+	t.Run("duplicate values for unique field", func(t *testing.T) {
+		mockFieldRepo := new(mocks.MockFieldRepository)
+		mockAppRepo := new(mocks.MockAppRepository)
+		mockDynamicQuery := new(mocks.MockDynamicQueryExecutor)
+		mockTransactor := new(mocks.MockTransactor)
+		mockPublisher := new(mocks.MockWebhookPublisher)
+
+		fields := conversionTestFields()
+		mockFieldRepo.On("GetByID", ctx, uint64(1)).Return(&fields[0], nil).Once()
+		mockAppRepo.On("GetByID", ctx, uint64(1)).Return(app, nil).Once()
+		mockFieldRepo.On("GetByAppID", ctx, uint64(1)).Return(fields, nil).Once()
+		mockFieldRepo.On("GetReferencingFields", ctx, uint64(1)).Return([]models.AppField{}, nil).Once()
+		mockTransactor.On("RunInTx", ctx).Return(nil).Once()
+		mockDynamicQuery.On("ConvertColumn", ctx, "app_data_1", mock.Anything).
+			Return(int64(0), &pq.Error{Code: "23505", Detail: "Key (amount)=(1.0000) already exists."}).Once()
+
+		service := services.NewFieldService(mockFieldRepo, mockAppRepo, mockDynamicQuery, newTestPermissionService(mockAppRepo), mockTransactor, mockPublisher, new(mocks.MockAttachmentManager))
+
+		_, err := service.ConvertField(ctx, 1, 1, &models.ConvertFieldRequest{
+			FieldType: "number",
+			Options:   models.FieldOptions{"unique": true},
+		})
+		assert.ErrorIs(t, err, services.ErrDuplicateValue)
+		assert.Contains(t, err.Error(), "金額")
+		mockFieldRepo.AssertExpectations(t)
+		mockAppRepo.AssertExpectations(t)
+		mockDynamicQuery.AssertExpectations(t)
+		mockTransactor.AssertExpectations(t)
+		mockPublisher.AssertExpectations(t)
+	})
+
+	t.Run("backup field code exists", func(t *testing.T) {
+		mockFieldRepo := new(mocks.MockFieldRepository)
+		mockAppRepo := new(mocks.MockAppRepository)
+		mockDynamicQuery := new(mocks.MockDynamicQueryExecutor)
+		mockTransactor := new(mocks.MockTransactor)
+
+		fields := conversionTestFields()
+		mockFieldRepo.On("GetByID", ctx, uint64(1)).Return(&fields[0], nil).Once()
+		mockAppRepo.On("GetByID", ctx, uint64(1)).Return(app, nil).Once()
+		mockFieldRepo.On("GetByAppID", ctx, uint64(1)).Return(fields, nil).Once()
+		mockFieldRepo.On("GetReferencingFields", ctx, uint64(1)).Return([]models.AppField{}, nil).Once()
+		mockFieldRepo.On("FieldCodeExists", ctx, uint64(1), "status").Return(true, nil).Once()
+
+		service := services.NewFieldService(mockFieldRepo, mockAppRepo, mockDynamicQuery, newTestPermissionService(mockAppRepo), mockTransactor, new(mocks.MockWebhookPublisher), new(mocks.MockAttachmentManager))
+
+		_, err := service.ConvertField(ctx, 1, 1, &models.ConvertFieldRequest{FieldType: "number", BackupFieldCode: "status"})
+		assert.ErrorIs(t, err, services.ErrFieldCodeExists)
+		mockFieldRepo.AssertExpectations(t)
+		mockAppRepo.AssertExpectations(t)
+		mockDynamicQuery.AssertExpectations(t)
+		mockTransactor.AssertExpectations(t)
+	})
+}
@@ -3,6 +3,8 @@ package services
 import (
 	"context"
 	"errors"
+	"fmt"
+	"strings"
 	"time"
 
 	"nocode-app/backend/internal/models"
@@ -21,6 +23,10 @@ type FieldService struct {
 	fieldRepo    repositories.FieldRepositoryInterface
 	appRepo      repositories.AppRepositoryInterface
 	dynamicQuery repositories.DynamicQueryExecutorInterface
+	permissions  PermissionServiceInterface
+	transactor   repositories.TransactorInterface
+	webhooks     WebhookPublisherInterface
+	attachments  AttachmentManagerInterface
 }
 
 // NewFieldService 新しいFieldServiceを作成する
@@ -28,16 +34,33 @@ func NewFieldService(
 	fieldRepo repositories.FieldRepositoryInterface,
 	appRepo repositories.AppRepositoryInterface,
 	dynamicQuery repositories.DynamicQueryExecutorInterface,
+	permissions PermissionServiceInterface,
+	transactor repositories.TransactorInterface,
+	webhooks WebhookPublisherInterface,
+	attachments AttachmentManagerInterface,
 ) *FieldService {
 	return &FieldService{
 		fieldRepo:    fieldRepo,
 		appRepo:      appRepo,
 		dynamicQuery: dynamicQuery,
+		permissions:  permissions,
+		transactor:   transactor,
+		webhooks:     webhooks,
+		attachments:  attachments,
 	}
 }
 
+// references 参照・ルックアップフィールドの定義を検証するreferenceResolverを返す
+func (s *FieldService) references() *referenceResolver {
+	return &referenceResolver{appRepo: s.appRepo, fieldRepo: s.fieldRepo, permissions: s.permissions}
+}
+
 // GetFields アプリの全フィールドを取得する
 func (s *FieldService) GetFields(ctx context.Context, appID uint64) ([]models.FieldResponse, error) {
+	if _, _, err := s.permissions.AuthorizeApp(ctx, appID, models.AppRoleViewer); err != nil {
+		return nil, err
+	}
+
 	fields, err := s.fieldRepo.GetByAppID(ctx, appID)
 	if err != nil {
 		return nil, err
@@ -54,13 +77,10 @@ func (s *FieldService) GetFields(ctx context.Context, appID uint64) ([]models.Fi
 // CreateField 新しいフィールドを作成し動的テーブルにカラムを追加する
 func (s *FieldService) CreateField(ctx context.Context, appID uint64, req *models.CreateFieldRequest) (*models.FieldResponse, error) {
 	// アプリを取得（外部データソースかどうかを判定するため）
-	app, err := s.appRepo.GetByID(ctx, appID)
+	app, _, err := s.permissions.AuthorizeApp(ctx, appID, models.AppRoleOwner)
 	if err != nil {
 		return nil, err
 	}
-	if app == nil {
-		return nil, ErrAppNotFound
-	}
 
 	// フィールドコードの存在確認
 	exists, err := s.fieldRepo.FieldCodeExists(ctx, appID, req.FieldCode)
@@ -99,25 +119,89 @@ func (s *FieldService) CreateField(ctx context.Context, appID uint64, req *model
 		field.SourceColumnName = &req.SourceColumnName
 	}
 
-	// データベースにフィールドを作成
-	if err := s.fieldRepo.Create(ctx, field); err != nil {
+	// 参照・ルックアップ・計算・集計フィールドのオプションを検証
+	var siblings []models.AppField
+	switch models.FieldType(field.FieldType) {
+	case models.FieldTypeLookup, models.FieldTypeFormula:
+		if siblings, err = s.fieldRepo.GetByAppID(ctx, appID); err != nil {
+			return nil, err
+		}
+	}
+	target, err := s.references().resolveField(ctx, app, field, siblings)
+	if err != nil {
 		return nil, err
 	}
-
-	// 外部データソースでない場合のみ、動的テーブルにカラムを追加
-	if !app.IsExternal {
-		if err := s.dynamicQuery.AddColumn(ctx, app.TableName, field); err != nil {
-			// フィールド作成をロールバック
-			_ = s.fieldRepo.Delete(ctx, field.ID)
+	var formulas *formulaCompiler
+	switch models.FieldType(field.FieldType) {
+	case models.FieldTypeFormula:
+		if formulas, err = compileFormulaField(app, field, siblings); err != nil {
+			return nil, err
+		}
+	case models.FieldTypeAttachment:
+		if err := validateAttachmentOptions(field.Options); err != nil {
 			return nil, err
 		}
 	}
+	if err := validateUniqueOption(app, field); err != nil {
+		return nil, err
+	}
 
-	return field.ToResponse(), nil
+	// フィールドの作成・動的テーブルの変更・変更の通知をまとめて行う
+	var resp *models.FieldResponse
+	err = s.transactor.RunInTx(ctx, func(ctx context.Context) error {
+		if err := s.fieldRepo.Create(ctx, field); err != nil {
+			return err
+		}
+
+		// 外部データソースでない場合のみ、動的テーブルにカラムを追加（計算フィールドは生成列とする）
+		if !app.IsExternal {
+			var err error
+			if formulas != nil {
+				err = s.dynamicQuery.SetFormulaColumn(ctx, app.TableName, field, formulas.expression(field.FieldCode))
+			} else {
+				err = s.dynamicQuery.AddColumn(ctx, app.TableName, field)
+			}
+			if err != nil {
+				return err
+			}
+		}
+
+		// 文字列のフィールドは全文検索の対象に加える
+		if models.IsSearchableField(field) {
+			if err := s.rebuildSearchColumn(ctx, app); err != nil {
+				return err
+			}
+		}
+
+		// 参照フィールドは参照先テーブルへの外部キー制約を設定
+		if target != nil {
+			if err := s.dynamicQuery.SetForeignKey(ctx, app.TableName, field.FieldCode, target.TableName, referenceOnDelete(field.Options)); err != nil {
+				return err
+			}
+		}
+
+		resp = field.ToResponse()
+		return s.webhooks.Publish(ctx, fieldEvent(models.WebhookEventFieldCreated, resp))
+	})
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// fieldEvent フィールドの変更を通知するイベントを作成する
+func fieldEvent(event models.WebhookEventType, field *models.FieldResponse) models.WebhookEvent {
+	return models.WebhookEvent{
+		Event:      event,
+		AppID:      field.AppID,
+		OccurredAt: field.UpdatedAt,
+		Data:       field,
+	}
 }
 
 // UpdateField フィールドを更新する
-func (s *FieldService) UpdateField(ctx context.Context, fieldID uint64, req *models.UpdateFieldRequest) (*models.FieldResponse, error) {
+// version が0でなく現在のバージョンと異なる場合は VersionConflictError を返す
+func (s *FieldService) UpdateField(ctx context.Context, fieldID uint64, version int64, req *models.UpdateFieldRequest) (*models.FieldResponse, error) {
 	field, err := s.fieldRepo.GetByID(ctx, fieldID)
 	if err != nil {
 		return nil, err
@@ -126,6 +210,16 @@ func (s *FieldService) UpdateField(ctx context.Context, fieldID uint64, req *mod
 		return nil, ErrFieldNotFound
 	}
 
+	app, _, err := s.permissions.AuthorizeApp(ctx, field.AppID, models.AppRoleOwner)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkVersion(version, models.VersionOf(field.UpdatedAt), field.ToResponse()); err != nil {
+		return nil, err
+	}
+
+	prevOptions := field.Options
+
 	// フィールドを更新
 	if req.FieldName != "" {
 		field.FieldName = req.FieldName
@@ -141,42 +235,346 @@ func (s *FieldService) UpdateField(ctx context.Context, fieldID uint64, req *mod
 	}
 	field.UpdatedAt = time.Now()
 
-	if err := s.fieldRepo.Update(ctx, field); err != nil {
+	// 参照・ルックアップ・計算・集計フィールドのオプションを検証
+	var target *models.App
+	var formulas *formulaCompiler
+	switch models.FieldType(field.FieldType) {
+	case models.FieldTypeReference:
+		if req.Options != nil {
+			if target, err = s.resolveReferenceUpdate(ctx, app, field, prevOptions); err != nil {
+				return nil, err
+			}
+		}
+	case models.FieldTypeLookup:
+		if req.Options != nil {
+			siblings, err := s.fieldRepo.GetByAppID(ctx, field.AppID)
+			if err != nil {
+				return nil, err
+			}
+			if _, err := s.references().resolveField(ctx, app, field, siblings); err != nil {
+				return nil, err
+			}
+		}
+		// ルックアップは入力しないため必須にできない
+		field.Required = false
+	case models.FieldTypeRollup:
+		// 値は読み取り時に集計するため、集計するアプリも変更できる
+		if req.Options != nil {
+			if _, err := s.references().resolveField(ctx, app, field, nil); err != nil {
+				return nil, err
+			}
+		}
+		// 集計フィールドは入力しないため必須にできない
+		field.Required = false
+	case models.FieldTypeFormula:
+		if req.Options != nil {
+			if formulas, err = s.compileFormulaUpdate(ctx, app, field, prevOptions); err != nil {
+				return nil, err
+			}
+		}
+		// 計算フィールドは入力しないため必須にできない
+		field.Required = false
+	case models.FieldTypeAttachment:
+		if err := validateAttachmentOptions(field.Options); err != nil {
+			return nil, err
+		}
+	}
+	if err := validateUniqueOption(app, field); err != nil {
 		return nil, err
 	}
 
+	// フィールドの保存・動的テーブルの変更・変更の通知をまとめて行う
+	prevField := *field
+	prevField.Options = prevOptions
+	conflict := false
+	err = s.transactor.RunInTx(ctx, func(ctx context.Context) error {
+		// バージョンを指定した場合は、取得した後に他の更新がなかったときのみ更新する
+		// 動的テーブルより先にフィールドを更新し、同じフィールドへの他の変更をトランザクションの終了まで待たせる
+		if version != 0 {
+			updated, err := s.fieldRepo.UpdateIfVersion(ctx, field, version)
+			if err != nil {
+				return err
+			}
+			if !updated {
+				conflict = true
+				return nil
+			}
+		} else if err := s.fieldRepo.Update(ctx, field); err != nil {
+			return err
+		}
+
+		// 値の重複の禁止が変わった場合は一意制約を付け外しする（既に重複した値がある場合は禁止できない）
+		if !app.IsExternal && field.IsUnique() != prevField.IsUnique() {
+			if err := s.dynamicQuery.SetUniqueConstraint(ctx, app.TableName, field.FieldCode, field.IsUnique()); err != nil {
+				return duplicateValueError(err, []models.AppField{*field})
+			}
+		}
+
+		// 削除時の動作が変わった場合は外部キー制約を設定し直す
+		if target != nil && referenceOnDelete(field.Options) != referenceOnDelete(prevOptions) {
+			if err := s.dynamicQuery.SetForeignKey(ctx, app.TableName, field.FieldCode, target.TableName, referenceOnDelete(field.Options)); err != nil {
+				return err
+			}
+		}
+
+		// 計算式が変わった場合は生成列を作り直す
+		if formulas != nil {
+			if err := s.setFormulaColumns(ctx, app, field, formulas); err != nil {
+				return err
+			}
+		}
+
+		return s.webhooks.Publish(ctx, fieldEvent(models.WebhookEventFieldUpdated, field.ToResponse()))
+	})
+	if err != nil {
+		return nil, err
+	}
+	if conflict {
+		return nil, s.fieldVersionConflict(ctx, fieldID)
+	}
 	return field.ToResponse(), nil
 }
 
-// DeleteField フィールドを削除し動的テーブルからカラムを削除する
-func (s *FieldService) DeleteField(ctx context.Context, appID, fieldID uint64) error {
+// fieldVersionConflict 現在のフィールドを取得し、バージョンの不一致を表すエラーを返す
+// 競合した更新でフィールドが削除されていた場合は ErrFieldNotFound を返す
+func (s *FieldService) fieldVersionConflict(ctx context.Context, fieldID uint64) error {
+	current, err := s.fieldRepo.GetByID(ctx, fieldID)
+	if err != nil {
+		return err
+	}
+	if current == nil {
+		return ErrFieldNotFound
+	}
+	return &VersionConflictError{Version: models.VersionOf(current.UpdatedAt), Current: current.ToResponse()}
+}
+
+// resolveReferenceUpdate 参照フィールドの変更後のオプションを検証し、参照先アプリを返す
+// 既存の値が参照先アプリのIDのため、参照先アプリは変更できない
+func (s *FieldService) resolveReferenceUpdate(ctx context.Context, app *models.App, field *models.AppField, prevOptions models.FieldOptions) (*models.App, error) {
+	prevAppID, _ := referenceAppID(prevOptions)
+	if _, ok := field.Options[OptionReferenceApp]; !ok {
+		field.Options = copyOptions(field.Options)
+		field.Options[OptionReferenceApp] = prevAppID
+	}
+	if appID, _ := referenceAppID(field.Options); appID != prevAppID {
+		return nil, fmt.Errorf("%w: 参照先アプリは変更できません", ErrInvalidFieldOptions)
+	}
+	return s.references().resolveField(ctx, app, field, nil)
+}
+
+// compileFormulaUpdate 計算フィールドの変更後の計算式を検査し、計算式が変わった場合は生成列を作り直すための計算式を返す
+// 計算式が変わっていない場合はnilを返す
+func (s *FieldService) compileFormulaUpdate(ctx context.Context, app *models.App, field *models.AppField, prevOptions models.FieldOptions) (*formulaCompiler, error) {
+	prevExpression, _ := optionString(prevOptions, OptionExpression)
+	if _, ok := field.Options[OptionExpression]; !ok {
+		field.Options = copyOptions(field.Options)
+		field.Options[OptionExpression] = prevExpression
+	}
+
+	siblings, err := s.fieldRepo.GetByAppID(ctx, field.AppID)
+	if err != nil {
+		return nil, err
+	}
+	formulas, err := compileFormulaField(app, field, siblings)
+	if err != nil {
+		return nil, err
+	}
+	if expression, _ := optionString(field.Options, OptionExpression); expression == strings.TrimSpace(prevExpression) {
+		return nil, nil
+	}
+	return formulas, nil
+}
+
+// setFormulaColumns 計算フィールドの生成列を作り直す
+// 計算式を展開して参照している他の計算フィールドの生成列も作り直す
+func (s *FieldService) setFormulaColumns(ctx context.Context, app *models.App, field *models.AppField, formulas *formulaCompiler) error {
+	if err := s.dynamicQuery.SetFormulaColumn(ctx, app.TableName, field, formulas.expression(field.FieldCode)); err != nil {
+		return err
+	}
+	for _, code := range formulas.dependents(field.FieldCode) {
+		dependent := formulas.field(code)
+		if err := s.dynamicQuery.SetFormulaColumn(ctx, app.TableName, dependent, formulas.expression(code)); err != nil {
+			return err
+		}
+		// 結果の型が変わっている場合があるため保存し直す
+		dependent.UpdatedAt = time.Now()
+		if err := s.fieldRepo.Update(ctx, dependent); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// compileFormulaField 作成・変更する計算フィールドを含めてアプリの計算フィールドを検査する
+// 検査済みのオプション（結果の型を含む）を field に反映する
+func compileFormulaField(app *models.App, field *models.AppField, siblings []models.AppField) (*formulaCompiler, error) {
+	fields := make([]models.AppField, 0, len(siblings)+1)
+	for i := range siblings {
+		if siblings[i].FieldCode != field.FieldCode {
+			fields = append(fields, siblings[i])
+		}
+	}
+	fields = append(fields, *field)
+
+	formulas, err := compileFormulas(app, fields)
+	if err != nil {
+		return nil, err
+	}
+	compiled := formulas.field(field.FieldCode)
+	field.Options = compiled.Options
+	field.Required = compiled.Required
+	return formulas, nil
+}
+
+// DeleteField フィールドをごみ箱に移す
+// 復元できるよう動的テーブルのカラムと値は残し、完全に削除するときにカラムを削除する。
+// 計算フィールドの生成列と参照フィールドの外部キー制約は、他のフィールドの削除や参照先のレコードの削除を妨げないよう先に削除する。
+// version が0でなく現在のバージョンと異なる場合は VersionConflictError を返す
+func (s *FieldService) DeleteField(ctx context.Context, appID, fieldID uint64, version int64) error {
 	field, err := s.fieldRepo.GetByID(ctx, fieldID)
 	if err != nil {
 		return err
 	}
-	if field == nil {
+	// 別のアプリのフィールドは削除不可
+	if field == nil || field.AppID != appID {
 		return ErrFieldNotFound
 	}
 
-	// テーブル名を取得
-	tableName, err := s.appRepo.GetTableName(ctx, appID)
+	app, _, err := s.permissions.AuthorizeApp(ctx, appID, models.AppRoleOwner)
 	if err != nil {
 		return err
 	}
-	if tableName == "" {
-		return ErrAppNotFound
+	if err := checkVersion(version, models.VersionOf(field.UpdatedAt), field.ToResponse()); err != nil {
+		return err
 	}
 
-	// 動的テーブルからカラムを削除
-	if err := s.dynamicQuery.DropColumn(ctx, tableName, field.FieldCode); err != nil {
+	// 計算式や参照・ルックアップフィールドから使われているフィールドは削除できない
+	var siblings []models.AppField
+	if !app.IsExternal {
+		if siblings, err = s.fieldRepo.GetByAppID(ctx, appID); err != nil {
+			return err
+		}
+		if isUsedByFormula(siblings, field.FieldCode) {
+			return ErrFieldInUse
+		}
+		if err := s.references().checkFieldInUse(ctx, field); err != nil {
+			return err
+		}
+	}
+
+	// フィールドのごみ箱への移動・動的テーブルの変更・変更の通知をまとめて行う
+	conflict := false
+	err = s.transactor.RunInTx(ctx, func(ctx context.Context) error {
+		// 添付ファイルは復元できるよう、ごみ箱から完全に削除するまで残す
+		// バージョンを指定した場合は、取得した後に他の更新がなかったときのみごみ箱に移す
+		if version != 0 {
+			trashed, err := s.fieldRepo.TrashIfVersion(ctx, fieldID, version)
+			if err != nil {
+				return err
+			}
+			if !trashed {
+				conflict = true
+				return nil
+			}
+		} else if err := s.fieldRepo.Trash(ctx, fieldID); err != nil {
+			return err
+		}
+
+		// 外部データソースでない場合のみ、動的テーブルを変更する
+		if !app.IsExternal {
+			if err := s.dropFieldColumns(ctx, app, field, siblings); err != nil {
+				return err
+			}
+		}
+
+		field.UpdatedAt = time.Now()
+		return s.webhooks.Publish(ctx, fieldEvent(models.WebhookEventFieldDeleted, field.ToResponse()))
+	})
+	if err != nil {
 		return err
 	}
+	if conflict {
+		return s.fieldVersionConflict(ctx, fieldID)
+	}
+	return nil
+}
 
-	// フィールドを削除
-	return s.fieldRepo.Delete(ctx, fieldID)
+// dropFieldColumns ごみ箱に移すフィールドを除いて全文検索用カラムを作り直し、生成列・外部キー制約を削除する
+func (s *FieldService) dropFieldColumns(ctx context.Context, app *models.App, field *models.AppField, siblings []models.AppField) error {
+
+	// 全文検索用カラムは検索対象のカラムを使うため、削除するフィールドを除いて作り直す
+	if models.IsSearchableField(field) {
+		remaining := make([]models.AppField, 0, len(siblings))
+		for i := range siblings {
+			if siblings[i].ID != field.ID {
+				remaining = append(remaining, siblings[i])
+			}
+		}
+		if err := rebuildSearchColumn(ctx, s.dynamicQuery, app, remaining); err != nil {
+			return err
+		}
+	}
+
+	// 計算フィールドの生成列・参照フィールドの外部キー制約を削除
+	switch models.FieldType(field.FieldType) {
+	case models.FieldTypeFormula:
+		return s.dynamicQuery.DropColumn(ctx, app.TableName, field.FieldCode)
+	case models.FieldTypeReference:
+		return s.dynamicQuery.DropForeignKey(ctx, app.TableName, field.FieldCode)
+	}
+	return nil
+}
+
+// rebuildSearchColumn アプリの現在のフィールドで全文検索用カラムを作り直す
+func (s *FieldService) rebuildSearchColumn(ctx context.Context, app *models.App) error {
+	if app.IsExternal || app.SearchConfig == nil {
+		return nil
+	}
+	fields, err := s.fieldRepo.GetByAppID(ctx, app.ID)
+	if err != nil {
+		return err
+	}
+	return rebuildSearchColumn(ctx, s.dynamicQuery, app, fields)
 }
 
 // UpdateFieldOrder フィールドの表示順序を更新する
-func (s *FieldService) UpdateFieldOrder(ctx context.Context, _ uint64, req *models.UpdateFieldOrderRequest) error {
-	return s.fieldRepo.UpdateOrder(ctx, req.Fields)
+func (s *FieldService) UpdateFieldOrder(ctx context.Context, appID uint64, req *models.UpdateFieldOrderRequest) error {
+	if _, _, err := s.permissions.AuthorizeApp(ctx, appID, models.AppRoleOwner); err != nil {
+		return err
+	}
+
+	// 他のアプリのフィールドが含まれていないことを確認
+	fields, err := s.fieldRepo.GetByAppID(ctx, appID)
+	if err != nil {
+		return err
+	}
+	owned := make(map[uint64]*models.AppField, len(fields))
+	for i := range fields {
+		owned[fields[i].ID] = &fields[i]
+	}
+	for _, item := range req.Fields {
+		if owned[item.ID] == nil {
+			return ErrFieldNotFound
+		}
+	}
+
+	// 表示順序が変わったフィールドごとに通知する
+	now := time.Now()
+	var events []models.WebhookEvent
+	for _, item := range req.Fields {
+		field := owned[item.ID]
+		if field.DisplayOrder == item.DisplayOrder {
+			continue
+		}
+		field.DisplayOrder = item.DisplayOrder
+		field.UpdatedAt = now
+		events = append(events, fieldEvent(models.WebhookEventFieldUpdated, field.ToResponse()))
+	}
+
+	return s.transactor.RunInTx(ctx, func(ctx context.Context) error {
+		if err := s.fieldRepo.UpdateOrder(ctx, req.Fields); err != nil {
+			return err
+		}
+		return s.webhooks.Publish(ctx, events...)
+	})
 }
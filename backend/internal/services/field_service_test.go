@@ -1,11 +1,11 @@
 package services_test
 
 import (
-	"context"
 	"errors"
 	"testing"
 	"time"
 
+	"github.com/lib/pq"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
@@ -16,7 +16,7 @@ import (
 )
 
 func TestFieldService_GetFields(t *testing.T) {
-	ctx := context.Background()
+	ctx := systemContext()
 
 	t.Run("successful get fields", func(t *testing.T) {
 		mockFieldRepo := new(mocks.MockFieldRepository)
@@ -28,9 +28,10 @@ func TestFieldService_GetFields(t *testing.T) {
 			{ID: 2, AppID: 1, FieldCode: "field2", FieldName: "Field 2", FieldType: "NUMBER", DisplayOrder: 2},
 		}
 
+		mockAppRepo.On("GetByID", ctx, uint64(1)).Return(&models.App{ID: 1, TableName: "app_data_1"}, nil)
 		mockFieldRepo.On("GetByAppID", ctx, uint64(1)).Return(fields, nil)
 
-		service := services.NewFieldService(mockFieldRepo, mockAppRepo, mockDynamicQuery)
+		service := services.NewFieldService(mockFieldRepo, mockAppRepo, mockDynamicQuery, newTestPermissionService(mockAppRepo), newTestTransactor(), newTestWebhookPublisher(), newTestAttachmentManager())
 
 		resp, err := service.GetFields(ctx, 1)
 		require.NoError(t, err)
@@ -45,9 +46,10 @@ func TestFieldService_GetFields(t *testing.T) {
 		mockAppRepo := new(mocks.MockAppRepository)
 		mockDynamicQuery := new(mocks.MockDynamicQueryExecutor)
 
+		mockAppRepo.On("GetByID", ctx, uint64(1)).Return(&models.App{ID: 1, TableName: "app_data_1"}, nil)
 		mockFieldRepo.On("GetByAppID", ctx, uint64(1)).Return(nil, errors.New("db error"))
 
-		service := services.NewFieldService(mockFieldRepo, mockAppRepo, mockDynamicQuery)
+		service := services.NewFieldService(mockFieldRepo, mockAppRepo, mockDynamicQuery, newTestPermissionService(mockAppRepo), newTestTransactor(), newTestWebhookPublisher(), newTestAttachmentManager())
 
 		_, err := service.GetFields(ctx, 1)
 		assert.Error(t, err)
@@ -57,7 +59,7 @@ func TestFieldService_GetFields(t *testing.T) {
 }
 
 func TestFieldService_CreateField(t *testing.T) {
-	ctx := context.Background()
+	ctx := systemContext()
 
 	t.Run("successful creation", func(t *testing.T) {
 		mockFieldRepo := new(mocks.MockFieldRepository)
@@ -79,7 +81,7 @@ func TestFieldService_CreateField(t *testing.T) {
 		})
 		mockDynamicQuery.On("AddColumn", ctx, "app_data_1", mock.AnythingOfType("*models.AppField")).Return(nil)
 
-		service := services.NewFieldService(mockFieldRepo, mockAppRepo, mockDynamicQuery)
+		service := services.NewFieldService(mockFieldRepo, mockAppRepo, mockDynamicQuery, newTestPermissionService(mockAppRepo), newTestTransactor(), newTestWebhookPublisher(), newTestAttachmentManager())
 
 		req := &models.CreateFieldRequest{
 			FieldCode: "new_field",
@@ -98,6 +100,32 @@ func TestFieldService_CreateField(t *testing.T) {
 		mockDynamicQuery.AssertExpectations(t)
 	})
 
+	t.Run("text field is added to search column", func(t *testing.T) {
+		mockFieldRepo := new(mocks.MockFieldRepository)
+		mockAppRepo := new(mocks.MockAppRepository)
+		mockDynamicQuery := new(mocks.MockDynamicQueryExecutor)
+
+		simple := models.SearchConfigSimple
+		mockApp := &models.App{ID: 1, TableName: "app_data_1", SearchConfig: &simple}
+		fields := []models.AppField{{ID: 1, FieldCode: "name", FieldType: "text"}, {ID: 2, FieldCode: "memo", FieldType: "textarea"}}
+
+		mockAppRepo.On("GetByID", ctx, uint64(1)).Return(mockApp, nil)
+		mockFieldRepo.On("FieldCodeExists", ctx, uint64(1), "memo").Return(false, nil)
+		mockFieldRepo.On("GetMaxDisplayOrder", ctx, uint64(1)).Return(1, nil)
+		mockFieldRepo.On("Create", ctx, mock.AnythingOfType("*models.AppField")).Return(nil).Run(func(args mock.Arguments) {
+			args.Get(1).(*models.AppField).ID = 2
+		})
+		mockDynamicQuery.On("AddColumn", ctx, "app_data_1", mock.AnythingOfType("*models.AppField")).Return(nil)
+		mockFieldRepo.On("GetByAppID", ctx, uint64(1)).Return(fields, nil)
+		mockDynamicQuery.On("SetSearchColumn", ctx, "app_data_1", fields, "simple").Return(nil)
+
+		service := services.NewFieldService(mockFieldRepo, mockAppRepo, mockDynamicQuery, newTestPermissionService(mockAppRepo), newTestTransactor(), newTestWebhookPublisher(), newTestAttachmentManager())
+
+		_, err := service.CreateField(ctx, 1, &models.CreateFieldRequest{FieldCode: "memo", FieldName: "Memo", FieldType: "textarea"})
+		require.NoError(t, err)
+		mockDynamicQuery.AssertExpectations(t)
+	})
+
 	t.Run("field code already exists", func(t *testing.T) {
 		mockFieldRepo := new(mocks.MockFieldRepository)
 		mockAppRepo := new(mocks.MockAppRepository)
@@ -111,7 +139,7 @@ func TestFieldService_CreateField(t *testing.T) {
 		mockAppRepo.On("GetByID", ctx, uint64(1)).Return(mockApp, nil)
 		mockFieldRepo.On("FieldCodeExists", ctx, uint64(1), "existing_field").Return(true, nil)
 
-		service := services.NewFieldService(mockFieldRepo, mockAppRepo, mockDynamicQuery)
+		service := services.NewFieldService(mockFieldRepo, mockAppRepo, mockDynamicQuery, newTestPermissionService(mockAppRepo), newTestTransactor(), newTestWebhookPublisher(), newTestAttachmentManager())
 
 		req := &models.CreateFieldRequest{
 			FieldCode: "existing_field",
@@ -133,7 +161,7 @@ func TestFieldService_CreateField(t *testing.T) {
 
 		mockAppRepo.On("GetByID", ctx, uint64(999)).Return(nil, nil)
 
-		service := services.NewFieldService(mockFieldRepo, mockAppRepo, mockDynamicQuery)
+		service := services.NewFieldService(mockFieldRepo, mockAppRepo, mockDynamicQuery, newTestPermissionService(mockAppRepo), newTestTransactor(), newTestWebhookPublisher(), newTestAttachmentManager())
 
 		req := &models.CreateFieldRequest{
 			FieldCode: "new_field",
@@ -172,7 +200,7 @@ func TestFieldService_CreateField(t *testing.T) {
 		})
 		// AddColumnは呼ばれない（外部データソースの場合）
 
-		service := services.NewFieldService(mockFieldRepo, mockAppRepo, mockDynamicQuery)
+		service := services.NewFieldService(mockFieldRepo, mockAppRepo, mockDynamicQuery, newTestPermissionService(mockAppRepo), newTestTransactor(), newTestWebhookPublisher(), newTestAttachmentManager())
 
 		req := &models.CreateFieldRequest{
 			FieldCode:        "customer_id",
@@ -198,7 +226,7 @@ func ptr[T any](v T) *T {
 }
 
 func TestFieldService_UpdateField(t *testing.T) {
-	ctx := context.Background()
+	ctx := systemContext()
 
 	t.Run("successful update", func(t *testing.T) {
 		mockFieldRepo := new(mocks.MockFieldRepository)
@@ -218,9 +246,10 @@ func TestFieldService_UpdateField(t *testing.T) {
 		}
 
 		mockFieldRepo.On("GetByID", ctx, uint64(1)).Return(field, nil)
+		mockAppRepo.On("GetByID", ctx, uint64(1)).Return(&models.App{ID: 1, TableName: "app_data_1"}, nil)
 		mockFieldRepo.On("Update", ctx, mock.AnythingOfType("*models.AppField")).Return(nil)
 
-		service := services.NewFieldService(mockFieldRepo, mockAppRepo, mockDynamicQuery)
+		service := services.NewFieldService(mockFieldRepo, mockAppRepo, mockDynamicQuery, newTestPermissionService(mockAppRepo), newTestTransactor(), newTestWebhookPublisher(), newTestAttachmentManager())
 
 		required := true
 		req := &models.UpdateFieldRequest{
@@ -228,7 +257,7 @@ func TestFieldService_UpdateField(t *testing.T) {
 			Required:  &required,
 		}
 
-		resp, err := service.UpdateField(ctx, 1, req)
+		resp, err := service.UpdateField(ctx, 1, 0, req)
 		require.NoError(t, err)
 		assert.Equal(t, "Updated Name", resp.FieldName)
 		assert.True(t, resp.Required)
@@ -243,19 +272,116 @@ func TestFieldService_UpdateField(t *testing.T) {
 
 		mockFieldRepo.On("GetByID", ctx, uint64(999)).Return(nil, nil)
 
-		service := services.NewFieldService(mockFieldRepo, mockAppRepo, mockDynamicQuery)
+		service := services.NewFieldService(mockFieldRepo, mockAppRepo, mockDynamicQuery, newTestPermissionService(mockAppRepo), newTestTransactor(), newTestWebhookPublisher(), newTestAttachmentManager())
 
 		req := &models.UpdateFieldRequest{}
 
-		_, err := service.UpdateField(ctx, 999, req)
+		_, err := service.UpdateField(ctx, 999, 0, req)
 		assert.ErrorIs(t, err, services.ErrFieldNotFound)
 
 		mockFieldRepo.AssertExpectations(t)
 	})
+
+	t.Run("unique option adds constraint", func(t *testing.T) {
+		mockFieldRepo := new(mocks.MockFieldRepository)
+		mockAppRepo := new(mocks.MockAppRepository)
+		mockDynamicQuery := new(mocks.MockDynamicQueryExecutor)
+
+		field := &models.AppField{ID: 1, AppID: 1, FieldCode: "code", FieldName: "顧客コード", FieldType: "text"}
+		mockFieldRepo.On("GetByID", ctx, uint64(1)).Return(field, nil)
+		mockAppRepo.On("GetByID", ctx, uint64(1)).Return(&models.App{ID: 1, TableName: "app_data_1"}, nil)
+		mockDynamicQuery.On("SetUniqueConstraint", ctx, "app_data_1", "code", true).Return(nil)
+		mockFieldRepo.On("Update", ctx, mock.AnythingOfType("*models.AppField")).Return(nil)
+
+		service := services.NewFieldService(mockFieldRepo, mockAppRepo, mockDynamicQuery, newTestPermissionService(mockAppRepo), newTestTransactor(), newTestWebhookPublisher(), newTestAttachmentManager())
+
+		resp, err := service.UpdateField(ctx, 1, 0, &models.UpdateFieldRequest{Options: models.FieldOptions{"unique": true}})
+		require.NoError(t, err)
+		assert.Equal(t, true, resp.Options["unique"])
+		mockDynamicQuery.AssertExpectations(t)
+	})
+
+	t.Run("unique option with duplicate values", func(t *testing.T) {
+		mockFieldRepo := new(mocks.MockFieldRepository)
+		mockAppRepo := new(mocks.MockAppRepository)
+		mockDynamicQuery := new(mocks.MockDynamicQueryExecutor)
+		mockTransactor := new(mocks.MockTransactor)
+		publisher := new(mocks.MockWebhookPublisher)
+
+		field := &models.AppField{ID: 1, AppID: 1, FieldCode: "code", FieldName: "顧客コード", FieldType: "text"}
+		mockFieldRepo.On("GetByID", ctx, uint64(1)).Return(field, nil)
+		mockAppRepo.On("GetByID", ctx, uint64(1)).Return(&models.App{ID: 1, TableName: "app_data_1"}, nil)
+		mockTransactor.On("RunInTx", ctx).Return(nil).Once()
+		mockFieldRepo.On("Update", ctx, mock.AnythingOfType("*models.AppField")).Return(nil)
+		mockDynamicQuery.On("SetUniqueConstraint", ctx, "app_data_1", "code", true).
+			Return(&pq.Error{Code: "23505", Detail: "Key (code)=(A001) already exists."})
+
+		service := services.NewFieldService(mockFieldRepo, mockAppRepo, mockDynamicQuery, newTestPermissionService(mockAppRepo), mockTransactor, publisher, newTestAttachmentManager())
+
+		// 一意制約を付けられない場合はフィールドの更新ごとロールバックする
+		_, err := service.UpdateField(ctx, 1, 0, &models.UpdateFieldRequest{Options: models.FieldOptions{"unique": true}})
+		assert.ErrorIs(t, err, services.ErrDuplicateValue)
+		assert.Contains(t, err.Error(), "顧客コード")
+		mockTransactor.AssertExpectations(t)
+		publisher.AssertNotCalled(t, "Publish", mock.Anything, mock.Anything)
+	})
+
+	t.Run("field changed before the constraint is added", func(t *testing.T) {
+		mockFieldRepo := new(mocks.MockFieldRepository)
+		mockAppRepo := new(mocks.MockAppRepository)
+		mockDynamicQuery := new(mocks.MockDynamicQueryExecutor)
+
+		updatedAt := time.Date(2026, 10, 16, 9, 0, 0, 0, time.UTC)
+		theirs := updatedAt.Add(time.Second)
+		mockFieldRepo.On("GetByID", ctx, uint64(1)).Return(&models.AppField{ID: 1, AppID: 1, FieldCode: "code", FieldType: "text", UpdatedAt: updatedAt}, nil).Once()
+		mockAppRepo.On("GetByID", ctx, uint64(1)).Return(&models.App{ID: 1, TableName: "app_data_1"}, nil)
+		mockFieldRepo.On("UpdateIfVersion", ctx, mock.AnythingOfType("*models.AppField"), models.VersionOf(updatedAt)).Return(false, nil)
+		mockFieldRepo.On("GetByID", ctx, uint64(1)).Return(&models.AppField{ID: 1, AppID: 1, FieldCode: "code", FieldType: "text", UpdatedAt: theirs}, nil).Once()
+
+		service := services.NewFieldService(mockFieldRepo, mockAppRepo, mockDynamicQuery, newTestPermissionService(mockAppRepo), newTestTransactor(), newTestWebhookPublisher(), newTestAttachmentManager())
+
+		_, err := service.UpdateField(ctx, 1, models.VersionOf(updatedAt), &models.UpdateFieldRequest{Options: models.FieldOptions{"unique": true}})
+		var conflict *services.VersionConflictError
+		require.ErrorAs(t, err, &conflict)
+		assert.Equal(t, models.VersionOf(theirs), conflict.Version)
+		mockDynamicQuery.AssertNotCalled(t, "SetUniqueConstraint", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("invalid options are rejected before the version is checked", func(t *testing.T) {
+		mockFieldRepo := new(mocks.MockFieldRepository)
+		mockAppRepo := new(mocks.MockAppRepository)
+		mockDynamicQuery := new(mocks.MockDynamicQueryExecutor)
+
+		updatedAt := time.Date(2026, 10, 16, 9, 0, 0, 0, time.UTC)
+		mockFieldRepo.On("GetByID", ctx, uint64(1)).Return(&models.AppField{ID: 1, AppID: 1, FieldCode: "tags", FieldType: "multiselect", UpdatedAt: updatedAt}, nil)
+		mockAppRepo.On("GetByID", ctx, uint64(1)).Return(&models.App{ID: 1, TableName: "app_data_1"}, nil)
+
+		service := services.NewFieldService(mockFieldRepo, mockAppRepo, mockDynamicQuery, newTestPermissionService(mockAppRepo), new(mocks.MockTransactor), newTestWebhookPublisher(), newTestAttachmentManager())
+
+		_, err := service.UpdateField(ctx, 1, models.VersionOf(updatedAt), &models.UpdateFieldRequest{Options: models.FieldOptions{"unique": true}})
+		assert.ErrorIs(t, err, services.ErrInvalidFieldOptions)
+		mockFieldRepo.AssertNotCalled(t, "UpdateIfVersion", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("unique option on unsupported type", func(t *testing.T) {
+		mockFieldRepo := new(mocks.MockFieldRepository)
+		mockAppRepo := new(mocks.MockAppRepository)
+		mockDynamicQuery := new(mocks.MockDynamicQueryExecutor)
+
+		field := &models.AppField{ID: 1, AppID: 1, FieldCode: "tags", FieldType: "multiselect"}
+		mockFieldRepo.On("GetByID", ctx, uint64(1)).Return(field, nil)
+		mockAppRepo.On("GetByID", ctx, uint64(1)).Return(&models.App{ID: 1, TableName: "app_data_1"}, nil)
+
+		service := services.NewFieldService(mockFieldRepo, mockAppRepo, mockDynamicQuery, newTestPermissionService(mockAppRepo), newTestTransactor(), newTestWebhookPublisher(), newTestAttachmentManager())
+
+		_, err := service.UpdateField(ctx, 1, 0, &models.UpdateFieldRequest{Options: models.FieldOptions{"unique": true}})
+		assert.ErrorIs(t, err, services.ErrInvalidFieldOptions)
+		mockDynamicQuery.AssertNotCalled(t, "SetUniqueConstraint", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
 }
 
 func TestFieldService_DeleteField(t *testing.T) {
-	ctx := context.Background()
+	ctx := systemContext()
 
 	t.Run("successful delete", func(t *testing.T) {
 		mockFieldRepo := new(mocks.MockFieldRepository)
@@ -265,18 +391,25 @@ func TestFieldService_DeleteField(t *testing.T) {
 		field := &models.AppField{ID: 1, AppID: 1, FieldCode: "field1"}
 
 		mockFieldRepo.On("GetByID", ctx, uint64(1)).Return(field, nil)
-		mockAppRepo.On("GetTableName", ctx, uint64(1)).Return("app_data_1", nil)
-		mockDynamicQuery.On("DropColumn", ctx, "app_data_1", "field1").Return(nil)
-		mockFieldRepo.On("Delete", ctx, uint64(1)).Return(nil)
-
-		service := services.NewFieldService(mockFieldRepo, mockAppRepo, mockDynamicQuery)
-
-		err := service.DeleteField(ctx, 1, 1)
+		mockAppRepo.On("GetByID", ctx, uint64(1)).Return(&models.App{ID: 1, TableName: "app_data_1"}, nil)
+		mockFieldRepo.On("GetByAppID", ctx, uint64(1)).Return([]models.AppField{*field}, nil)
+		mockFieldRepo.On("GetReferencingFields", ctx, uint64(1)).Return([]models.AppField{}, nil)
+		mockFieldRepo.On("Trash", ctx, uint64(1)).Return(nil)
+		publisher := new(mocks.MockWebhookPublisher)
+		publisher.On("Publish", ctx, mock.MatchedBy(func(events []models.WebhookEvent) bool {
+			return len(events) == 1 && events[0].Event == models.WebhookEventFieldDeleted && events[0].AppID == 1
+		})).Return(nil)
+
+		service := services.NewFieldService(mockFieldRepo, mockAppRepo, mockDynamicQuery, newTestPermissionService(mockAppRepo), newTestTransactor(), publisher, newTestAttachmentManager())
+
+		err := service.DeleteField(ctx, 1, 1, 0)
 		require.NoError(t, err)
 
+		// 復元できるようカラムは残す
 		mockFieldRepo.AssertExpectations(t)
 		mockAppRepo.AssertExpectations(t)
-		mockDynamicQuery.AssertExpectations(t)
+		publisher.AssertExpectations(t)
+		mockDynamicQuery.AssertNotCalled(t, "DropColumn", mock.Anything, mock.Anything, mock.Anything)
 	})
 
 	t.Run("field not found", func(t *testing.T) {
@@ -286,31 +419,139 @@ func TestFieldService_DeleteField(t *testing.T) {
 
 		mockFieldRepo.On("GetByID", ctx, uint64(999)).Return(nil, nil)
 
-		service := services.NewFieldService(mockFieldRepo, mockAppRepo, mockDynamicQuery)
+		service := services.NewFieldService(mockFieldRepo, mockAppRepo, mockDynamicQuery, newTestPermissionService(mockAppRepo), newTestTransactor(), newTestWebhookPublisher(), newTestAttachmentManager())
 
-		err := service.DeleteField(ctx, 1, 999)
+		err := service.DeleteField(ctx, 1, 999, 0)
 		assert.ErrorIs(t, err, services.ErrFieldNotFound)
 
 		mockFieldRepo.AssertExpectations(t)
 	})
+
+	t.Run("field changed since it was read", func(t *testing.T) {
+		mockFieldRepo := new(mocks.MockFieldRepository)
+		mockAppRepo := new(mocks.MockAppRepository)
+		mockDynamicQuery := new(mocks.MockDynamicQueryExecutor)
+
+		updatedAt := time.Date(2026, 10, 16, 9, 0, 0, 0, time.UTC)
+		mockFieldRepo.On("GetByID", ctx, uint64(1)).Return(&models.AppField{ID: 1, AppID: 1, FieldCode: "field1", UpdatedAt: updatedAt}, nil)
+		mockAppRepo.On("GetByID", ctx, uint64(1)).Return(&models.App{ID: 1, TableName: "app_data_1"}, nil)
+
+		service := services.NewFieldService(mockFieldRepo, mockAppRepo, mockDynamicQuery, newTestPermissionService(mockAppRepo), newTestTransactor(), newTestWebhookPublisher(), newTestAttachmentManager())
+
+		err := service.DeleteField(ctx, 1, 1, models.VersionOf(updatedAt)+1)
+		var conflict *services.VersionConflictError
+		require.ErrorAs(t, err, &conflict)
+		assert.Equal(t, "field1", conflict.Current.(*models.FieldResponse).FieldCode)
+		mockFieldRepo.AssertNotCalled(t, "Trash", mock.Anything, mock.Anything)
+	})
+
+	t.Run("field changed before it is trashed", func(t *testing.T) {
+		mockFieldRepo := new(mocks.MockFieldRepository)
+		mockAppRepo := new(mocks.MockAppRepository)
+		mockDynamicQuery := new(mocks.MockDynamicQueryExecutor)
+		mockTransactor := new(mocks.MockTransactor)
+
+		updatedAt := time.Date(2026, 10, 16, 9, 0, 0, 0, time.UTC)
+		theirs := updatedAt.Add(time.Second)
+		field := &models.AppField{ID: 1, AppID: 1, FieldCode: "field1", FieldType: "formula", UpdatedAt: updatedAt}
+		mockFieldRepo.On("GetByID", ctx, uint64(1)).Return(field, nil).Once()
+		mockAppRepo.On("GetByID", ctx, uint64(1)).Return(&models.App{ID: 1, TableName: "app_data_1"}, nil)
+		mockFieldRepo.On("GetByAppID", ctx, uint64(1)).Return([]models.AppField{*field}, nil)
+		mockFieldRepo.On("GetReferencingFields", ctx, uint64(1)).Return([]models.AppField{}, nil)
+		mockTransactor.On("RunInTx", ctx).Return(nil).Once()
+		mockFieldRepo.On("TrashIfVersion", ctx, uint64(1), models.VersionOf(updatedAt)).Return(false, nil)
+		mockFieldRepo.On("GetByID", ctx, uint64(1)).Return(&models.AppField{ID: 1, AppID: 1, FieldCode: "field1", FieldType: "formula", UpdatedAt: theirs}, nil).Once()
+
+		service := services.NewFieldService(mockFieldRepo, mockAppRepo, mockDynamicQuery, newTestPermissionService(mockAppRepo), mockTransactor, newTestWebhookPublisher(), newTestAttachmentManager())
+
+		err := service.DeleteField(ctx, 1, 1, models.VersionOf(updatedAt))
+		var conflict *services.VersionConflictError
+		require.ErrorAs(t, err, &conflict)
+		assert.Equal(t, models.VersionOf(theirs), conflict.Version)
+		mockTransactor.AssertExpectations(t)
+		mockDynamicQuery.AssertNotCalled(t, "DropColumn", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("trash and drop the generated column in one transaction", func(t *testing.T) {
+		mockFieldRepo := new(mocks.MockFieldRepository)
+		mockAppRepo := new(mocks.MockAppRepository)
+		mockDynamicQuery := new(mocks.MockDynamicQueryExecutor)
+		mockTransactor := new(mocks.MockTransactor)
+		publisher := new(mocks.MockWebhookPublisher)
+
+		updatedAt := time.Date(2026, 10, 16, 9, 0, 0, 0, time.UTC)
+		field := &models.AppField{ID: 1, AppID: 1, FieldCode: "total", FieldType: "formula", UpdatedAt: updatedAt}
+		mockFieldRepo.On("GetByID", ctx, uint64(1)).Return(field, nil)
+		mockAppRepo.On("GetByID", ctx, uint64(1)).Return(&models.App{ID: 1, TableName: "app_data_1"}, nil)
+		mockFieldRepo.On("GetByAppID", ctx, uint64(1)).Return([]models.AppField{*field}, nil)
+		mockFieldRepo.On("GetReferencingFields", ctx, uint64(1)).Return([]models.AppField{}, nil)
+		mockTransactor.On("RunInTx", ctx).Return(nil).Once()
+		mockFieldRepo.On("TrashIfVersion", ctx, uint64(1), models.VersionOf(updatedAt)).Return(true, nil)
+		mockDynamicQuery.On("DropColumn", ctx, "app_data_1", "total").Return(errors.New("lock timeout"))
+
+		service := services.NewFieldService(mockFieldRepo, mockAppRepo, mockDynamicQuery, newTestPermissionService(mockAppRepo), mockTransactor, publisher, newTestAttachmentManager())
+
+		// 生成列を削除できない場合はトランザクションごと失敗し、フィールドもごみ箱に移さない
+		err := service.DeleteField(ctx, 1, 1, models.VersionOf(updatedAt))
+		assert.EqualError(t, err, "lock timeout")
+		mockTransactor.AssertExpectations(t)
+		mockFieldRepo.AssertExpectations(t)
+		mockFieldRepo.AssertNotCalled(t, "UpdateIfVersion", mock.Anything, mock.Anything, mock.Anything)
+		publisher.AssertNotCalled(t, "Publish", mock.Anything, mock.Anything)
+	})
+
+	t.Run("search column is rebuilt without trashed text column", func(t *testing.T) {
+		mockFieldRepo := new(mocks.MockFieldRepository)
+		mockAppRepo := new(mocks.MockAppRepository)
+		mockDynamicQuery := new(mocks.MockDynamicQueryExecutor)
+
+		simple := models.SearchConfigSimple
+		field := &models.AppField{ID: 2, AppID: 1, FieldCode: "memo", FieldType: "textarea"}
+		other := models.AppField{ID: 1, AppID: 1, FieldCode: "name", FieldType: "text"}
+
+		mockFieldRepo.On("GetByID", ctx, uint64(2)).Return(field, nil)
+		mockAppRepo.On("GetByID", ctx, uint64(1)).Return(&models.App{ID: 1, TableName: "app_data_1", SearchConfig: &simple}, nil)
+		mockFieldRepo.On("GetByAppID", ctx, uint64(1)).Return([]models.AppField{other, *field}, nil)
+		mockFieldRepo.On("GetReferencingFields", ctx, uint64(1)).Return([]models.AppField{}, nil)
+		mockDynamicQuery.On("SetSearchColumn", ctx, "app_data_1", []models.AppField{other}, "simple").Return(nil)
+		mockFieldRepo.On("Trash", ctx, uint64(2)).Return(nil)
+
+		service := services.NewFieldService(mockFieldRepo, mockAppRepo, mockDynamicQuery, newTestPermissionService(mockAppRepo), newTestTransactor(), newTestWebhookPublisher(), newTestAttachmentManager())
+
+		require.NoError(t, service.DeleteField(ctx, 1, 2, 0))
+		mockDynamicQuery.AssertExpectations(t)
+		mockFieldRepo.AssertExpectations(t)
+	})
 }
 
 func TestFieldService_UpdateFieldOrder(t *testing.T) {
-	ctx := context.Background()
+	ctx := systemContext()
 
 	t.Run("successful order update", func(t *testing.T) {
 		mockFieldRepo := new(mocks.MockFieldRepository)
 		mockAppRepo := new(mocks.MockAppRepository)
 		mockDynamicQuery := new(mocks.MockDynamicQueryExecutor)
 
+		mockAppRepo.On("GetByID", ctx, uint64(1)).Return(&models.App{ID: 1, TableName: "app_data_1"}, nil)
+		mockFieldRepo.On("GetByAppID", ctx, uint64(1)).Return([]models.AppField{{ID: 1, AppID: 1, DisplayOrder: 1}, {ID: 2, AppID: 1, DisplayOrder: 2}, {ID: 3, AppID: 1, DisplayOrder: 3}}, nil)
 		mockFieldRepo.On("UpdateOrder", ctx, mock.AnythingOfType("[]models.FieldOrderItem")).Return(nil)
-
-		service := services.NewFieldService(mockFieldRepo, mockAppRepo, mockDynamicQuery)
+		// 表示順序が変わったフィールドのみ通知する
+		publisher := new(mocks.MockWebhookPublisher)
+		publisher.On("Publish", ctx, mock.MatchedBy(func(events []models.WebhookEvent) bool {
+			if len(events) != 2 {
+				return false
+			}
+			first := events[0].Data.(*models.FieldResponse)
+			return events[0].Event == models.WebhookEventFieldUpdated && first.ID == 1 && first.DisplayOrder == 2
+		})).Return(nil)
+
+		service := services.NewFieldService(mockFieldRepo, mockAppRepo, mockDynamicQuery, newTestPermissionService(mockAppRepo), newTestTransactor(), publisher, newTestAttachmentManager())
 
 		req := &models.UpdateFieldOrderRequest{
 			Fields: []models.FieldOrderItem{
 				{ID: 1, DisplayOrder: 2},
 				{ID: 2, DisplayOrder: 1},
+				{ID: 3, DisplayOrder: 3},
 			},
 		}
 
@@ -318,11 +559,12 @@ func TestFieldService_UpdateFieldOrder(t *testing.T) {
 		require.NoError(t, err)
 
 		mockFieldRepo.AssertExpectations(t)
+		publisher.AssertExpectations(t)
 	})
 }
 
 func TestFieldService_DeleteField_AppNotFound(t *testing.T) {
-	ctx := context.Background()
+	ctx := systemContext()
 
 	mockFieldRepo := new(mocks.MockFieldRepository)
 	mockAppRepo := new(mocks.MockAppRepository)
@@ -331,11 +573,11 @@ func TestFieldService_DeleteField_AppNotFound(t *testing.T) {
 	field := &models.AppField{ID: 1, AppID: 999, FieldCode: "field1"}
 
 	mockFieldRepo.On("GetByID", ctx, uint64(1)).Return(field, nil)
-	mockAppRepo.On("GetTableName", ctx, uint64(999)).Return("", nil)
+	mockAppRepo.On("GetByID", ctx, uint64(999)).Return(nil, nil)
 
-	service := services.NewFieldService(mockFieldRepo, mockAppRepo, mockDynamicQuery)
+	service := services.NewFieldService(mockFieldRepo, mockAppRepo, mockDynamicQuery, newTestPermissionService(mockAppRepo), newTestTransactor(), newTestWebhookPublisher(), newTestAttachmentManager())
 
-	err := service.DeleteField(ctx, 999, 1)
+	err := service.DeleteField(ctx, 999, 1, 0)
 	assert.ErrorIs(t, err, services.ErrAppNotFound)
 
 	mockFieldRepo.AssertExpectations(t)
@@ -343,7 +585,7 @@ func TestFieldService_DeleteField_AppNotFound(t *testing.T) {
 }
 
 func TestFieldService_UpdateField_DisplayOrder(t *testing.T) {
-	ctx := context.Background()
+	ctx := systemContext()
 
 	mockFieldRepo := new(mocks.MockFieldRepository)
 	mockAppRepo := new(mocks.MockAppRepository)
@@ -362,16 +604,17 @@ func TestFieldService_UpdateField_DisplayOrder(t *testing.T) {
 	}
 
 	mockFieldRepo.On("GetByID", ctx, uint64(1)).Return(field, nil)
+	mockAppRepo.On("GetByID", ctx, uint64(1)).Return(&models.App{ID: 1, TableName: "app_data_1"}, nil)
 	mockFieldRepo.On("Update", ctx, mock.AnythingOfType("*models.AppField")).Return(nil)
 
-	service := services.NewFieldService(mockFieldRepo, mockAppRepo, mockDynamicQuery)
+	service := services.NewFieldService(mockFieldRepo, mockAppRepo, mockDynamicQuery, newTestPermissionService(mockAppRepo), newTestTransactor(), newTestWebhookPublisher(), newTestAttachmentManager())
 
 	newOrder := 5
 	req := &models.UpdateFieldRequest{
 		DisplayOrder: &newOrder,
 	}
 
-	resp, err := service.UpdateField(ctx, 1, req)
+	resp, err := service.UpdateField(ctx, 1, 0, req)
 	require.NoError(t, err)
 	assert.Equal(t, 5, resp.DisplayOrder)
 
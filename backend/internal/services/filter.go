@@ -0,0 +1,416 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"nocode-app/backend/internal/models"
+	"nocode-app/backend/internal/repositories"
+)
+
+// ErrInvalidFilter 絞り込み条件の誤り
+var ErrInvalidFilter = errors.New("絞り込み条件が正しくありません")
+
+const (
+	// maxFilterConditions 1回の取得で指定できる条件の数の上限
+	maxFilterConditions = 50
+	// maxFilterListValues 複数の値をとる演算子に指定できる値の数の上限
+	maxFilterListValues = 100
+	// maxFilterPeriodDays last_N_days・next_N_days に指定できる日数の上限
+	maxFilterPeriodDays = 3650
+)
+
+// filterKind 使える演算子と値の形式を決めるカラムの分類
+type filterKind int
+
+const (
+	filterKindText filterKind = iota
+	filterKindNumber
+	filterKindDate
+	filterKindDateTime
+	filterKindBoolean
+	filterKindMultiSelect
+	filterKindAttachment
+)
+
+// filterKindOperators カラムの分類ごとに使える演算子
+var filterKindOperators = map[filterKind]map[string]bool{
+	filterKindText: {
+		"eq": true, "ne": true, "gt": true, "gte": true, "lt": true, "lte": true,
+		"like": true, "ilike": true, "in": true, "not_in": true, "between": true, "is_null": true, "not_null": true,
+	},
+	filterKindNumber: {
+		"eq": true, "ne": true, "gt": true, "gte": true, "lt": true, "lte": true,
+		"in": true, "not_in": true, "between": true, "is_null": true, "not_null": true,
+	},
+	filterKindDate: {
+		"eq": true, "ne": true, "gt": true, "gte": true, "lt": true, "lte": true,
+		"between": true, "in_period": true, "is_null": true, "not_null": true,
+	},
+	filterKindDateTime: {
+		"eq": true, "ne": true, "gt": true, "gte": true, "lt": true, "lte": true,
+		"between": true, "in_period": true, "is_null": true, "not_null": true,
+	},
+	filterKindBoolean: {
+		"eq": true, "ne": true, "is_null": true, "not_null": true,
+	},
+	filterKindMultiSelect: {
+		"contains_any": true, "contains_all": true, "is_null": true, "not_null": true,
+	},
+	filterKindAttachment: {
+		"is_null": true, "not_null": true,
+	},
+}
+
+// filterPeriodDaysPattern 直近・今後N日間の期間の形式
+var filterPeriodDaysPattern = regexp.MustCompile(`^(last|next)_(\d{1,4})_days$`)
+
+// filterResolver 絞り込み条件をフィールド定義と照合して検証し、データベースで評価できる形に置き換える構造体
+type filterResolver struct {
+	kinds      map[string]filterKind
+	now        time.Time
+	conditions int
+}
+
+// newFilterResolver アプリのフィールドから絞り込みに使えるカラムを求めてfilterResolverを作成する
+// 内部アプリはレコードID・作成者・作成日時・更新日時でも絞り込める。相対的な期間は now を timeZone で評価する
+func newFilterResolver(app *models.App, fields []models.AppField, timeZone string, now time.Time) (*filterResolver, error) {
+	loc := time.UTC
+	if timeZone != "" {
+		l, err := time.LoadLocation(timeZone)
+		if err != nil {
+			return nil, fmt.Errorf("%w: タイムゾーン %q が正しくありません", ErrInvalidFilter, timeZone)
+		}
+		loc = l
+	}
+
+	kinds := make(map[string]filterKind, len(fields)+4)
+	if !app.IsExternal {
+		kinds["id"] = filterKindNumber
+		kinds["created_by"] = filterKindNumber
+		kinds["created_at"] = filterKindDateTime
+		kinds["updated_at"] = filterKindDateTime
+	}
+	for i := range fields {
+		if kind, ok := fieldFilterKind(&fields[i]); ok {
+			kinds[fields[i].FieldCode] = kind
+		}
+	}
+	return &filterResolver{kinds: kinds, now: now.In(loc)}, nil
+}
+
+// fieldFilterKind フィールドの分類を返す（カラムを持たないフィールドは絞り込めない）
+func fieldFilterKind(field *models.AppField) (filterKind, bool) {
+	fieldType := models.FieldType(field.FieldType)
+	if fieldType == models.FieldTypeFormula {
+		fieldType = field.FormulaResultType()
+	}
+	switch fieldType {
+	case models.FieldTypeNumber, models.FieldTypeReference:
+		return filterKindNumber, true
+	case models.FieldTypeDate:
+		return filterKindDate, true
+	case models.FieldTypeDateTime:
+		return filterKindDateTime, true
+	case models.FieldTypeCheckbox:
+		return filterKindBoolean, true
+	case models.FieldTypeMultiSelect:
+		return filterKindMultiSelect, true
+	case models.FieldTypeAttachment:
+		return filterKindAttachment, true
+	case models.FieldTypeLookup, models.FieldTypeRollup:
+		return 0, false
+	default:
+		return filterKindText, true
+	}
+}
+
+// resolveFilters ANDで結合する条件とフィルター式を検証し、データベースで評価できる形に置き換えて返す
+// 置き換えで単一の条件でなくなった条件はフィルター式に移す
+func resolveFilters(app *models.App, fields []models.AppField, timeZone string, items []models.FilterItem, expr *models.FilterExpr) ([]models.FilterItem, *models.FilterExpr, error) {
+	if len(items) == 0 && expr == nil {
+		return items, expr, nil
+	}
+	r, err := newFilterResolver(app, fields, timeZone, time.Now())
+	if err != nil {
+		return nil, nil, err
+	}
+	return r.resolve(items, expr)
+}
+
+// resolveRecordQueryFilters レコード取得のオプションの絞り込み条件を検証して置き換える
+func resolveRecordQueryFilters(app *models.App, fields []models.AppField, opts *repositories.RecordQueryOptions) error {
+	filters, filter, err := resolveFilters(app, fields, opts.TimeZone, opts.Filters, opts.Filter)
+	if err != nil {
+		return err
+	}
+	opts.Filters, opts.Filter = filters, filter
+	return nil
+}
+
+// resolve 条件を検証して置き換える
+func (r *filterResolver) resolve(items []models.FilterItem, expr *models.FilterExpr) ([]models.FilterItem, *models.FilterExpr, error) {
+	var resolved []models.FilterItem
+	if items != nil {
+		resolved = make([]models.FilterItem, 0, len(items))
+	}
+	var groups []models.FilterExpr
+	for i := range items {
+		e, err := r.resolveItem(&items[i])
+		if err != nil {
+			return nil, nil, err
+		}
+		if e.IsGroup() {
+			groups = append(groups, *e)
+		} else {
+			resolved = append(resolved, e.FilterItem)
+		}
+	}
+	if expr != nil {
+		e, err := r.resolveExpr(expr, 0)
+		if err != nil {
+			return nil, nil, err
+		}
+		groups = append(groups, *e)
+	}
+
+	switch len(groups) {
+	case 0:
+		return resolved, nil, nil
+	case 1:
+		return resolved, &groups[0], nil
+	}
+	return resolved, &models.FilterExpr{And: groups}, nil
+}
+
+// resolveExpr フィルター式を検証して置き換える
+func (r *filterResolver) resolveExpr(e *models.FilterExpr, depth int) (*models.FilterExpr, error) {
+	if depth > models.MaxFilterDepth {
+		return nil, fmt.Errorf("%w: 条件の入れ子は%d段までです", ErrInvalidFilter, models.MaxFilterDepth)
+	}
+
+	kinds := 0
+	for _, set := range []bool{e.And != nil, e.Or != nil, e.Not != nil, e.Field != "" || e.Operator != ""} {
+		if set {
+			kinds++
+		}
+	}
+	if kinds != 1 {
+		return nil, fmt.Errorf("%w: and・or・not・条件（field・operator）のいずれか1つを指定してください", ErrInvalidFilter)
+	}
+
+	switch {
+	case e.Not != nil:
+		inner, err := r.resolveExpr(e.Not, depth+1)
+		if err != nil {
+			return nil, err
+		}
+		return &models.FilterExpr{Not: inner}, nil
+	case e.And != nil:
+		children, err := r.resolveGroup(e.And, depth)
+		if err != nil {
+			return nil, err
+		}
+		return &models.FilterExpr{And: children}, nil
+	case e.Or != nil:
+		children, err := r.resolveGroup(e.Or, depth)
+		if err != nil {
+			return nil, err
+		}
+		return &models.FilterExpr{Or: children}, nil
+	}
+	return r.resolveItem(&e.FilterItem)
+}
+
+// resolveGroup グループ内の条件を検証して置き換える
+func (r *filterResolver) resolveGroup(children []models.FilterExpr, depth int) ([]models.FilterExpr, error) {
+	if len(children) == 0 {
+		return nil, fmt.Errorf("%w: and・or には条件を1つ以上指定してください", ErrInvalidFilter)
+	}
+	resolved := make([]models.FilterExpr, 0, len(children))
+	for i := range children {
+		e, err := r.resolveExpr(&children[i], depth+1)
+		if err != nil {
+			return nil, err
+		}
+		resolved = append(resolved, *e)
+	}
+	return resolved, nil
+}
+
+// resolveItem 単一の条件をフィールドの分類と照合し、値を正規化する
+func (r *filterResolver) resolveItem(f *models.FilterItem) (*models.FilterExpr, error) {
+	r.conditions++
+	if r.conditions > maxFilterConditions {
+		return nil, fmt.Errorf("%w: 条件は%d個までです", ErrInvalidFilter, maxFilterConditions)
+	}
+
+	kind, ok := r.kinds[f.Field]
+	if !ok {
+		return nil, fmt.Errorf("%w: フィールド %q では絞り込めません", ErrInvalidFilter, f.Field)
+	}
+	if !filterKindOperators[kind][f.Operator] {
+		return nil, fmt.Errorf("%w: フィールド %q には演算子 %q を使用できません", ErrInvalidFilter, f.Field, f.Operator)
+	}
+
+	item := models.FilterItem{Field: f.Field, Operator: f.Operator}
+	switch {
+	case !models.FilterOperatorTakesValue(f.Operator):
+		return resolveNullFilter(item, kind), nil
+	case f.Operator == models.FilterOpInPeriod:
+		return r.resolvePeriod(f, kind)
+	case models.FilterOperatorTakesList(f.Operator):
+		values := f.ListValues()
+		if f.Operator == models.FilterOpBetween && len(values) != 2 {
+			return nil, fmt.Errorf("%w: フィールド %q の between には値を2つ指定してください", ErrInvalidFilter, f.Field)
+		}
+		if len(values) == 0 || len(values) > maxFilterListValues {
+			return nil, fmt.Errorf("%w: フィールド %q の %s には値を1〜%d個指定してください", ErrInvalidFilter, f.Field, f.Operator, maxFilterListValues)
+		}
+		item.Values = make([]string, len(values))
+		for i, v := range values {
+			normalized, err := normalizeFilterValue(f.Field, kind, v)
+			if err != nil {
+				return nil, err
+			}
+			item.Values[i] = normalized
+		}
+	default:
+		normalized, err := normalizeFilterValue(f.Field, kind, f.Value)
+		if err != nil {
+			return nil, err
+		}
+		item.Value = normalized
+	}
+	return &models.FilterExpr{FilterItem: item}, nil
+}
+
+// resolveNullFilter 空かどうかの条件を置き換える
+// 文字列の空文字、複数選択・添付ファイルの空の配列も空として扱う
+func resolveNullFilter(item models.FilterItem, kind filterKind) *models.FilterExpr {
+	var empty string
+	switch kind {
+	case filterKindText:
+		empty = ""
+	case filterKindMultiSelect, filterKindAttachment:
+		empty = "[]"
+	default:
+		return &models.FilterExpr{FilterItem: item}
+	}
+
+	if item.Operator == models.FilterOpIsNull {
+		return &models.FilterExpr{Or: []models.FilterExpr{
+			{FilterItem: item},
+			{FilterItem: models.FilterItem{Field: item.Field, Operator: models.FilterOpEq, Value: empty}},
+		}}
+	}
+	return &models.FilterExpr{And: []models.FilterExpr{
+		{FilterItem: item},
+		{FilterItem: models.FilterItem{Field: item.Field, Operator: models.FilterOpNe, Value: empty}},
+	}}
+}
+
+// resolvePeriod 相対的な期間の条件を日付の範囲の条件に置き換える
+// 日付フィールドはタイムゾーンでの日付で、日時フィールド（UTCで保存）は期間の開始・終了時刻で比較する
+func (r *filterResolver) resolvePeriod(f *models.FilterItem, kind filterKind) (*models.FilterExpr, error) {
+	start, end, ok := filterPeriodRange(f.Value, r.now)
+	if !ok {
+		return nil, fmt.Errorf("%w: 期間 %q は指定できません（today・this_week・last_30_days など）", ErrInvalidFilter, f.Value)
+	}
+
+	layout := "2006-01-02"
+	if kind == filterKindDateTime {
+		layout = "2006-01-02T15:04:05"
+		start, end = start.UTC(), end.UTC()
+	}
+	return &models.FilterExpr{And: []models.FilterExpr{
+		{FilterItem: models.FilterItem{Field: f.Field, Operator: models.FilterOpGte, Value: start.Format(layout)}},
+		{FilterItem: models.FilterItem{Field: f.Field, Operator: models.FilterOpLt, Value: end.Format(layout)}},
+	}}, nil
+}
+
+// filterPeriodRange 相対的な期間の開始（含む）と終了（含まない）を now のタイムゾーンの0時で返す
+// 週は月曜日から始まる。last_N_days・next_N_days は今日を含むN日間
+func filterPeriodRange(period string, now time.Time) (time.Time, time.Time, bool) {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	monday := today.AddDate(0, 0, -((int(today.Weekday()) + 6) % 7))
+	month := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+	quarter := time.Date(now.Year(), (now.Month()-1)/3*3+1, 1, 0, 0, 0, 0, now.Location())
+	year := time.Date(now.Year(), time.January, 1, 0, 0, 0, 0, now.Location())
+
+	switch period {
+	case "today":
+		return today, today.AddDate(0, 0, 1), true
+	case "yesterday":
+		return today.AddDate(0, 0, -1), today, true
+	case "tomorrow":
+		return today.AddDate(0, 0, 1), today.AddDate(0, 0, 2), true
+	case "this_week":
+		return monday, monday.AddDate(0, 0, 7), true
+	case "last_week":
+		return monday.AddDate(0, 0, -7), monday, true
+	case "next_week":
+		return monday.AddDate(0, 0, 7), monday.AddDate(0, 0, 14), true
+	case "this_month":
+		return month, month.AddDate(0, 1, 0), true
+	case "last_month":
+		return month.AddDate(0, -1, 0), month, true
+	case "next_month":
+		return month.AddDate(0, 1, 0), month.AddDate(0, 2, 0), true
+	case "this_quarter":
+		return quarter, quarter.AddDate(0, 3, 0), true
+	case "last_quarter":
+		return quarter.AddDate(0, -3, 0), quarter, true
+	case "next_quarter":
+		return quarter.AddDate(0, 3, 0), quarter.AddDate(0, 6, 0), true
+	case "this_year":
+		return year, year.AddDate(1, 0, 0), true
+	case "last_year":
+		return year.AddDate(-1, 0, 0), year, true
+	case "next_year":
+		return year.AddDate(1, 0, 0), year.AddDate(2, 0, 0), true
+	}
+
+	m := filterPeriodDaysPattern.FindStringSubmatch(period)
+	if m == nil {
+		return time.Time{}, time.Time{}, false
+	}
+	days, _ := strconv.Atoi(m[2])
+	if days < 1 || days > maxFilterPeriodDays {
+		return time.Time{}, time.Time{}, false
+	}
+	if m[1] == "last" {
+		return today.AddDate(0, 0, 1-days), today.AddDate(0, 0, 1), true
+	}
+	return today, today.AddDate(0, 0, days), true
+}
+
+// normalizeFilterValue 条件の値をフィールドの分類に合わせて検証し、データベースで比較できる形式にする
+func normalizeFilterValue(field string, kind filterKind, value string) (string, error) {
+	var normalized interface{}
+	var msg string
+	switch kind {
+	case filterKindNumber:
+		if _, err := strconv.ParseFloat(strings.TrimSpace(value), 64); err != nil {
+			msg = "数値で指定してください"
+		} else {
+			normalized = strings.TrimSpace(value)
+		}
+	case filterKindDate:
+		normalized, msg = validateDate(value)
+	case filterKindDateTime:
+		normalized, msg = validateDatetime(value)
+	case filterKindBoolean:
+		normalized, msg = validateCheckbox(value)
+	default:
+		return value, nil
+	}
+	if msg != "" {
+		return "", fmt.Errorf("%w: フィールド %q の値 %q: %s", ErrInvalidFilter, field, value, msg)
+	}
+	return fmt.Sprint(normalized), nil
+}
@@ -0,0 +1,197 @@
+package services
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"nocode-app/backend/internal/models"
+)
+
+// newTestFilterResolver 2026-10-14（水）12:00 UTCを基準日時とするfilterResolverを作成する
+func newTestFilterResolver(t *testing.T, timeZone string) *filterResolver {
+	t.Helper()
+	app := &models.App{ID: 1}
+	fields := []models.AppField{
+		{FieldCode: "name", FieldType: string(models.FieldTypeText)},
+		{FieldCode: "amount", FieldType: string(models.FieldTypeNumber)},
+		{FieldCode: "due", FieldType: string(models.FieldTypeDate)},
+		{FieldCode: "visited_at", FieldType: string(models.FieldTypeDateTime)},
+		{FieldCode: "done", FieldType: string(models.FieldTypeCheckbox)},
+		{FieldCode: "tags", FieldType: string(models.FieldTypeMultiSelect)},
+		{FieldCode: "customer_name", FieldType: string(models.FieldTypeLookup)},
+	}
+	r, err := newFilterResolver(app, fields, timeZone, time.Date(2026, 10, 14, 12, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+	return r
+}
+
+func leafFilter(field, op, value string, values ...string) models.FilterExpr {
+	return models.FilterExpr{FilterItem: models.FilterItem{Field: field, Operator: op, Value: value, Values: values}}
+}
+
+func TestFilterResolver_Resolve(t *testing.T) {
+	t.Run("values are normalized by field type", func(t *testing.T) {
+		r := newTestFilterResolver(t, "")
+		items, expr, err := r.resolve([]models.FilterItem{
+			{Field: "amount", Operator: "gte", Value: " 100 "},
+			{Field: "done", Operator: "eq", Value: "1"},
+			{Field: "visited_at", Operator: "lt", Value: "2026-10-01T09:00:00+09:00"},
+			{Field: "amount", Operator: "in", Value: "1,2"},
+		}, nil)
+		require.NoError(t, err)
+		assert.Nil(t, expr)
+		assert.Equal(t, []models.FilterItem{
+			{Field: "amount", Operator: "gte", Value: "100"},
+			{Field: "done", Operator: "eq", Value: "true"},
+			{Field: "visited_at", Operator: "lt", Value: "2026-10-01T09:00:00"},
+			{Field: "amount", Operator: "in", Values: []string{"1", "2"}},
+		}, items)
+	})
+
+	t.Run("nested expression", func(t *testing.T) {
+		r := newTestFilterResolver(t, "")
+		_, expr, err := r.resolve(nil, &models.FilterExpr{Or: []models.FilterExpr{
+			leafFilter("name", "eq", "A"),
+			{Not: &models.FilterExpr{And: []models.FilterExpr{leafFilter("amount", "lt", "5"), leafFilter("id", "gt", "10")}}},
+		}})
+		require.NoError(t, err)
+		require.NotNil(t, expr)
+		assert.Equal(t, &models.FilterExpr{Or: []models.FilterExpr{
+			leafFilter("name", "eq", "A"),
+			{Not: &models.FilterExpr{And: []models.FilterExpr{leafFilter("amount", "lt", "5"), leafFilter("id", "gt", "10")}}},
+		}}, expr)
+	})
+
+	t.Run("is_null on text also matches empty string", func(t *testing.T) {
+		r := newTestFilterResolver(t, "")
+		items, expr, err := r.resolve([]models.FilterItem{
+			{Field: "name", Operator: "is_null"},
+			{Field: "amount", Operator: "not_null"},
+		}, nil)
+		require.NoError(t, err)
+		assert.Equal(t, []models.FilterItem{{Field: "amount", Operator: "not_null"}}, items)
+		assert.Equal(t, &models.FilterExpr{Or: []models.FilterExpr{
+			leafFilter("name", "is_null", ""),
+			leafFilter("name", "eq", ""),
+		}}, expr)
+	})
+
+	t.Run("in_period on date uses local date", func(t *testing.T) {
+		r := newTestFilterResolver(t, "Asia/Tokyo")
+		_, expr, err := r.resolve([]models.FilterItem{{Field: "due", Operator: "in_period", Value: "this_week"}}, nil)
+		require.NoError(t, err)
+		assert.Equal(t, &models.FilterExpr{And: []models.FilterExpr{
+			leafFilter("due", "gte", "2026-10-12"),
+			leafFilter("due", "lt", "2026-10-19"),
+		}}, expr)
+	})
+
+	t.Run("in_period on datetime uses UTC instants", func(t *testing.T) {
+		r := newTestFilterResolver(t, "Asia/Tokyo")
+		_, expr, err := r.resolve([]models.FilterItem{{Field: "visited_at", Operator: "in_period", Value: "today"}}, nil)
+		require.NoError(t, err)
+		assert.Equal(t, &models.FilterExpr{And: []models.FilterExpr{
+			leafFilter("visited_at", "gte", "2026-10-13T15:00:00"),
+			leafFilter("visited_at", "lt", "2026-10-14T15:00:00"),
+		}}, expr)
+	})
+
+	t.Run("several rewritten items are combined with and", func(t *testing.T) {
+		r := newTestFilterResolver(t, "")
+		_, expr, err := r.resolve([]models.FilterItem{{Field: "tags", Operator: "not_null"}}, &models.FilterExpr{Not: &models.FilterExpr{FilterItem: models.FilterItem{Field: "tags", Operator: "contains_any", Value: "a"}}})
+		require.NoError(t, err)
+		require.NotNil(t, expr)
+		assert.Len(t, expr.And, 2)
+	})
+
+	errorCases := []struct {
+		name  string
+		items []models.FilterItem
+		expr  *models.FilterExpr
+	}{
+		{"unknown field", []models.FilterItem{{Field: "missing", Operator: "eq", Value: "1"}}, nil},
+		{"lookup field", []models.FilterItem{{Field: "customer_name", Operator: "eq", Value: "1"}}, nil},
+		{"operator not allowed for number", []models.FilterItem{{Field: "amount", Operator: "like", Value: "1"}}, nil},
+		{"operator not allowed for multiselect", []models.FilterItem{{Field: "tags", Operator: "eq", Value: "a"}}, nil},
+		{"invalid number", []models.FilterItem{{Field: "amount", Operator: "eq", Value: "abc"}}, nil},
+		{"invalid date", []models.FilterItem{{Field: "due", Operator: "gte", Value: "10/01"}}, nil},
+		{"between needs two values", []models.FilterItem{{Field: "amount", Operator: "between", Value: "1"}}, nil},
+		{"unknown period", []models.FilterItem{{Field: "due", Operator: "in_period", Value: "someday"}}, nil},
+		{"empty group", nil, &models.FilterExpr{Or: []models.FilterExpr{}}},
+		{"group and condition together", nil, &models.FilterExpr{And: []models.FilterExpr{leafFilter("name", "eq", "A")}, FilterItem: models.FilterItem{Field: "name", Operator: "eq"}}},
+		{"empty expression", nil, &models.FilterExpr{}},
+	}
+	for _, tc := range errorCases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := newTestFilterResolver(t, "")
+			_, _, err := r.resolve(tc.items, tc.expr)
+			assert.True(t, errors.Is(err, ErrInvalidFilter), "got %v", err)
+		})
+	}
+
+	t.Run("too deep", func(t *testing.T) {
+		expr := leafFilter("name", "eq", "A")
+		for i := 0; i <= models.MaxFilterDepth; i++ {
+			inner := expr
+			expr = models.FilterExpr{Not: &inner}
+		}
+		_, _, err := newTestFilterResolver(t, "").resolve(nil, &expr)
+		assert.ErrorIs(t, err, ErrInvalidFilter)
+	})
+
+	t.Run("too many conditions", func(t *testing.T) {
+		items := make([]models.FilterItem, maxFilterConditions+1)
+		for i := range items {
+			items[i] = models.FilterItem{Field: "name", Operator: "eq", Value: "A"}
+		}
+		_, _, err := newTestFilterResolver(t, "").resolve(items, nil)
+		assert.ErrorIs(t, err, ErrInvalidFilter)
+	})
+}
+
+func TestNewFilterResolver_InvalidTimeZone(t *testing.T) {
+	_, err := newFilterResolver(&models.App{ID: 1}, nil, "Mars/Olympus", time.Now())
+	assert.ErrorIs(t, err, ErrInvalidFilter)
+}
+
+func TestFilterPeriodRange(t *testing.T) {
+	// 2026-10-14（水）
+	now := time.Date(2026, 10, 14, 12, 0, 0, 0, time.UTC)
+	day := func(m time.Month, d int) time.Time { return time.Date(2026, m, d, 0, 0, 0, 0, time.UTC) }
+
+	tests := []struct {
+		period     string
+		start, end time.Time
+	}{
+		{"today", day(10, 14), day(10, 15)},
+		{"yesterday", day(10, 13), day(10, 14)},
+		{"tomorrow", day(10, 15), day(10, 16)},
+		{"this_week", day(10, 12), day(10, 19)},
+		{"last_week", day(10, 5), day(10, 12)},
+		{"next_week", day(10, 19), day(10, 26)},
+		{"this_month", day(10, 1), day(11, 1)},
+		{"last_month", day(9, 1), day(10, 1)},
+		{"this_quarter", day(10, 1), time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{"last_quarter", day(7, 1), day(10, 1)},
+		{"this_year", day(1, 1), time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{"last_7_days", day(10, 8), day(10, 15)},
+		{"next_30_days", day(10, 14), day(11, 13)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.period, func(t *testing.T) {
+			start, end, ok := filterPeriodRange(tt.period, now)
+			require.True(t, ok)
+			assert.Equal(t, tt.start, start)
+			assert.Equal(t, tt.end, end)
+		})
+	}
+
+	for _, period := range []string{"", "last_0_days", "next_9999_days", "last_days"} {
+		_, _, ok := filterPeriodRange(period, now)
+		assert.False(t, ok, period)
+	}
+}
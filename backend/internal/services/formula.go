@@ -0,0 +1,171 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+
+	"nocode-app/backend/internal/models"
+	"nocode-app/backend/internal/utils"
+)
+
+// 計算フィールドのオプションキー
+const (
+	// OptionExpression 計算式
+	OptionExpression = "expression"
+	// OptionResultType 計算結果の型（型検査の結果を保存する）
+	OptionResultType = "result_type"
+)
+
+// formulaCompiler アプリの計算フィールドの計算式を検査し、生成列のSQL式に変換する
+// 生成列は他の生成列を参照できないため、計算フィールドを参照する場合はその計算式を展開する
+type formulaCompiler struct {
+	fields   []models.AppField
+	byCode   map[string]*models.AppField
+	results  map[string]utils.FormulaOperand
+	uses     map[string]map[string]bool // 計算フィールドが展開後に参照するフィールドコード
+	visiting map[string]bool
+}
+
+// compileFormulas 全ての計算フィールドを検査し、結果の型をオプションに設定する
+// fields は作成・変更中のものを含むアプリの全フィールド。循環参照や存在しないフィールドの参照はエラーとする
+func compileFormulas(app *models.App, fields []models.AppField) (*formulaCompiler, error) {
+	c := &formulaCompiler{
+		fields:   fields,
+		byCode:   make(map[string]*models.AppField, len(fields)),
+		results:  make(map[string]utils.FormulaOperand),
+		uses:     make(map[string]map[string]bool),
+		visiting: make(map[string]bool),
+	}
+	for i := range fields {
+		c.byCode[fields[i].FieldCode] = &fields[i]
+	}
+
+	for i := range fields {
+		if models.FieldType(fields[i].FieldType) != models.FieldTypeFormula {
+			continue
+		}
+		if app.IsExternal {
+			return nil, fmt.Errorf("%w: 外部データソースのアプリには計算フィールドを作成できません", ErrInvalidFieldOptions)
+		}
+		if _, err := c.compile(&fields[i]); err != nil {
+			return nil, err
+		}
+	}
+	return c, nil
+}
+
+func (c *formulaCompiler) compile(field *models.AppField) (utils.FormulaOperand, error) {
+	code := field.FieldCode
+	if result, ok := c.results[code]; ok {
+		return result, nil
+	}
+	if c.visiting[code] {
+		return utils.FormulaOperand{}, fmt.Errorf("%w: 計算式が循環して参照しています（%s）", ErrInvalidFieldOptions, code)
+	}
+	c.visiting[code] = true
+	defer delete(c.visiting, code)
+
+	expression, _ := optionString(field.Options, OptionExpression)
+	formula, err := utils.ParseFormula(expression)
+	if err != nil {
+		return utils.FormulaOperand{}, fmt.Errorf("%w: %s: %v", ErrInvalidFieldOptions, code, err)
+	}
+
+	uses := make(map[string]bool)
+	operands := make(map[string]utils.FormulaOperand, len(formula.Refs()))
+	for _, ref := range formula.Refs() {
+		f, ok := c.byCode[ref]
+		if !ok {
+			return utils.FormulaOperand{}, fmt.Errorf("%w: %s: フィールド %q は存在しません", ErrInvalidFieldOptions, code, ref)
+		}
+		uses[ref] = true
+		if models.FieldType(f.FieldType) == models.FieldTypeFormula {
+			operand, err := c.compile(f)
+			if err != nil {
+				return utils.FormulaOperand{}, err
+			}
+			operands[ref] = operand
+			for used := range c.uses[ref] {
+				uses[used] = true
+			}
+			continue
+		}
+		t, ok := formulaOperandType(f)
+		if !ok {
+			return utils.FormulaOperand{}, fmt.Errorf("%w: %s: フィールド %q は計算式で使用できません", ErrInvalidFieldOptions, code, ref)
+		}
+		operands[ref] = utils.FormulaOperand{Type: t}
+	}
+
+	sql, resultType, err := formula.Compile(operands)
+	if err != nil {
+		return utils.FormulaOperand{}, fmt.Errorf("%w: %s: %v", ErrInvalidFieldOptions, code, err)
+	}
+
+	options := copyOptions(field.Options)
+	options[OptionExpression] = strings.TrimSpace(expression)
+	options[OptionResultType] = string(resultType)
+	field.Options = options
+	// 計算フィールドは入力しないため必須にできない
+	field.Required = false
+
+	// 変換後の式は括弧で閉じているため、展開先でそのまま使える
+	result := utils.FormulaOperand{Type: resultType, SQL: sql}
+	c.results[code] = result
+	c.uses[code] = uses
+	return result, nil
+}
+
+// expression 計算フィールドの生成列のSQL式を返す
+func (c *formulaCompiler) expression(code string) string {
+	return c.results[code].SQL
+}
+
+// field 検査済みの計算フィールドを返す（結果の型が設定されたオプションを含む）
+func (c *formulaCompiler) field(code string) *models.AppField {
+	return c.byCode[code]
+}
+
+// dependents 指定したフィールドを直接または間接的に参照する計算フィールドのコードを返す
+func (c *formulaCompiler) dependents(code string) []string {
+	var codes []string
+	for i := range c.fields {
+		if c.uses[c.fields[i].FieldCode][code] {
+			codes = append(codes, c.fields[i].FieldCode)
+		}
+	}
+	return codes
+}
+
+// formulaOperandType 計算式から参照するフィールドの値の型を返す
+// 複数の値を持つフィールドや参照・添付ファイルは計算式で使用できない
+func formulaOperandType(field *models.AppField) (models.FieldType, bool) {
+	switch models.FieldType(field.FieldType) {
+	case models.FieldTypeText, models.FieldTypeTextArea, models.FieldTypeSelect, models.FieldTypeRadio, models.FieldTypeLink:
+		return models.FieldTypeText, true
+	case models.FieldTypeNumber, models.FieldTypeDate, models.FieldTypeDateTime, models.FieldTypeCheckbox:
+		return models.FieldType(field.FieldType), true
+	default:
+		return "", false
+	}
+}
+
+// isUsedByFormula 指定したフィールドを計算式で参照している計算フィールドがあるかどうかを判定する
+func isUsedByFormula(fields []models.AppField, code string) bool {
+	for i := range fields {
+		if models.FieldType(fields[i].FieldType) != models.FieldTypeFormula || fields[i].FieldCode == code {
+			continue
+		}
+		expression, _ := optionString(fields[i].Options, OptionExpression)
+		formula, err := utils.ParseFormula(expression)
+		if err != nil {
+			continue
+		}
+		for _, ref := range formula.Refs() {
+			if ref == code {
+				return true
+			}
+		}
+	}
+	return false
+}
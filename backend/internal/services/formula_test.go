@@ -0,0 +1,318 @@
+package services_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"nocode-app/backend/internal/models"
+	"nocode-app/backend/internal/services"
+	"nocode-app/backend/internal/testhelpers/mocks"
+)
+
+// 計算フィールドのテストで使う見積アプリのフィールド
+var (
+	quoteApp    = &models.App{ID: 1, TableName: "app_data_1", CreatedBy: 1}
+	quoteFields = []models.AppField{
+		{ID: 1, AppID: 1, FieldCode: "price", FieldName: "単価", FieldType: "number", DisplayOrder: 1},
+		{ID: 2, AppID: 1, FieldCode: "quantity", FieldName: "数量", FieldType: "number", DisplayOrder: 2},
+		{ID: 3, AppID: 1, FieldCode: "subtotal", FieldName: "小計", FieldType: "formula", DisplayOrder: 3,
+			Options: models.FieldOptions{"expression": "price * quantity", "result_type": "number"}},
+		{ID: 4, AppID: 1, FieldCode: "total", FieldName: "合計", FieldType: "formula", DisplayOrder: 4,
+			Options: models.FieldOptions{"expression": "subtotal * 1.1", "result_type": "number"}},
+	}
+)
+
+// cloneFields テスト間でオプションを共有しないようフィールドを複製する
+func cloneFields(fields []models.AppField) []models.AppField {
+	cloned := make([]models.AppField, len(fields))
+	for i := range fields {
+		cloned[i] = fields[i]
+		cloned[i].Options = make(models.FieldOptions, len(fields[i].Options))
+		for k, v := range fields[i].Options {
+			cloned[i].Options[k] = v
+		}
+	}
+	return cloned
+}
+
+func TestFieldService_CreateField_Formula(t *testing.T) {
+	ctx := systemContext()
+
+	t.Run("creates generated column with inferred result type", func(t *testing.T) {
+		mockFieldRepo := new(mocks.MockFieldRepository)
+		mockAppRepo := new(mocks.MockAppRepository)
+		mockDynamicQuery := new(mocks.MockDynamicQueryExecutor)
+
+		mockAppRepo.On("GetByID", ctx, uint64(1)).Return(quoteApp, nil)
+		mockFieldRepo.On("FieldCodeExists", ctx, uint64(1), "label").Return(false, nil)
+		mockFieldRepo.On("GetByAppID", ctx, uint64(1)).Return(cloneFields(quoteFields), nil)
+		mockFieldRepo.On("Create", ctx, mock.AnythingOfType("*models.AppField")).Return(nil)
+		mockDynamicQuery.On("SetFormulaColumn", ctx, "app_data_1", mock.AnythingOfType("*models.AppField"), mock.AnythingOfType("string")).Return(nil)
+
+		service := services.NewFieldService(mockFieldRepo, mockAppRepo, mockDynamicQuery, newTestPermissionService(mockAppRepo), newTestTransactor(), newTestWebhookPublisher(), newTestAttachmentManager())
+
+		resp, err := service.CreateField(ctx, 1, &models.CreateFieldRequest{
+			FieldCode: "label", FieldName: "区分", FieldType: "formula", Required: true, DisplayOrder: 5,
+			Options: models.FieldOptions{"expression": `IF(total >= 10000, "大口", "通常")`},
+		})
+		require.NoError(t, err)
+		assert.False(t, resp.Required)
+		assert.Equal(t, "text", resp.Options["result_type"])
+		mockDynamicQuery.AssertNotCalled(t, "AddColumn", mock.Anything, mock.Anything, mock.Anything)
+
+		// 参照する計算フィールドは展開して生成列の式にする
+		call := mockDynamicQuery.Calls[0]
+		assert.Equal(t, "SetFormulaColumn", call.Method)
+		assert.Equal(t,
+			`(CASE WHEN ((("price" * "quantity") * CAST(1.1 AS NUMERIC)) >= CAST(10000 AS NUMERIC)) THEN CAST('大口' AS TEXT) ELSE CAST('通常' AS TEXT) END)`,
+			call.Arguments.String(3))
+	})
+
+	t.Run("rolls back when generated column fails", func(t *testing.T) {
+		mockFieldRepo := new(mocks.MockFieldRepository)
+		mockAppRepo := new(mocks.MockAppRepository)
+		mockDynamicQuery := new(mocks.MockDynamicQueryExecutor)
+		mockTransactor := new(mocks.MockTransactor)
+		publisher := new(mocks.MockWebhookPublisher)
+
+		mockAppRepo.On("GetByID", ctx, uint64(1)).Return(quoteApp, nil)
+		mockFieldRepo.On("FieldCodeExists", ctx, uint64(1), "half").Return(false, nil)
+		mockFieldRepo.On("GetByAppID", ctx, uint64(1)).Return(cloneFields(quoteFields), nil)
+		mockFieldRepo.On("Create", ctx, mock.AnythingOfType("*models.AppField")).Return(nil).Run(func(args mock.Arguments) {
+			args.Get(1).(*models.AppField).ID = 9
+		})
+		mockDynamicQuery.On("SetFormulaColumn", ctx, "app_data_1", mock.AnythingOfType("*models.AppField"), mock.AnythingOfType("string")).Return(assert.AnError)
+		mockTransactor.On("RunInTx", ctx).Return(nil).Once()
+
+		service := services.NewFieldService(mockFieldRepo, mockAppRepo, mockDynamicQuery, newTestPermissionService(mockAppRepo), mockTransactor, publisher, newTestAttachmentManager())
+
+		_, err := service.CreateField(ctx, 1, &models.CreateFieldRequest{
+			FieldCode: "half", FieldName: "半額", FieldType: "formula", DisplayOrder: 5,
+			Options: models.FieldOptions{"expression": "price / 2"},
+		})
+		assert.ErrorIs(t, err, assert.AnError)
+		mockFieldRepo.AssertExpectations(t)
+		mockTransactor.AssertExpectations(t)
+		mockFieldRepo.AssertNotCalled(t, "Delete", mock.Anything, mock.Anything)
+		publisher.AssertNotCalled(t, "Publish", mock.Anything, mock.Anything)
+	})
+
+	invalid := []struct {
+		name       string
+		expression string
+	}{
+		{name: "syntax error", expression: "price *"},
+		{name: "unknown field", expression: "price * discount"},
+		{name: "type error", expression: `price & "円" - 1`},
+		{name: "self reference", expression: "label + 1"},
+	}
+	for _, tt := range invalid {
+		t.Run(tt.name, func(t *testing.T) {
+			mockFieldRepo := new(mocks.MockFieldRepository)
+			mockAppRepo := new(mocks.MockAppRepository)
+
+			mockAppRepo.On("GetByID", ctx, uint64(1)).Return(quoteApp, nil)
+			mockFieldRepo.On("FieldCodeExists", ctx, uint64(1), "label").Return(false, nil)
+			mockFieldRepo.On("GetByAppID", ctx, uint64(1)).Return(cloneFields(quoteFields), nil)
+
+			service := services.NewFieldService(mockFieldRepo, mockAppRepo, new(mocks.MockDynamicQueryExecutor), newTestPermissionService(mockAppRepo), newTestTransactor(), newTestWebhookPublisher(), newTestAttachmentManager())
+
+			_, err := service.CreateField(ctx, 1, &models.CreateFieldRequest{
+				FieldCode: "label", FieldName: "区分", FieldType: "formula", DisplayOrder: 5,
+				Options: models.FieldOptions{"expression": tt.expression},
+			})
+			assert.ErrorIs(t, err, services.ErrInvalidFieldOptions)
+			mockFieldRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+		})
+	}
+
+	t.Run("external app", func(t *testing.T) {
+		mockFieldRepo := new(mocks.MockFieldRepository)
+		mockAppRepo := new(mocks.MockAppRepository)
+
+		mockAppRepo.On("GetByID", ctx, uint64(1)).Return(&models.App{ID: 1, IsExternal: true}, nil)
+		mockFieldRepo.On("FieldCodeExists", ctx, uint64(1), "label").Return(false, nil)
+		mockFieldRepo.On("GetByAppID", ctx, uint64(1)).Return(cloneFields(quoteFields[:2]), nil)
+
+		service := services.NewFieldService(mockFieldRepo, mockAppRepo, new(mocks.MockDynamicQueryExecutor), newTestPermissionService(mockAppRepo), newTestTransactor(), newTestWebhookPublisher(), newTestAttachmentManager())
+
+		_, err := service.CreateField(ctx, 1, &models.CreateFieldRequest{
+			FieldCode: "label", FieldName: "区分", FieldType: "formula", DisplayOrder: 5,
+			Options: models.FieldOptions{"expression": "price * quantity"},
+		})
+		assert.ErrorIs(t, err, services.ErrInvalidFieldOptions)
+	})
+}
+
+func TestFieldService_UpdateField_Formula(t *testing.T) {
+	ctx := systemContext()
+
+	t.Run("recreates dependent generated columns", func(t *testing.T) {
+		mockFieldRepo := new(mocks.MockFieldRepository)
+		mockAppRepo := new(mocks.MockAppRepository)
+		mockDynamicQuery := new(mocks.MockDynamicQueryExecutor)
+
+		fields := cloneFields(quoteFields)
+		subtotal := fields[2]
+		mockFieldRepo.On("GetByID", ctx, uint64(3)).Return(&subtotal, nil)
+		mockAppRepo.On("GetByID", ctx, uint64(1)).Return(quoteApp, nil)
+		mockFieldRepo.On("GetByAppID", ctx, uint64(1)).Return(fields, nil)
+		mockDynamicQuery.On("SetFormulaColumn", ctx, "app_data_1", mock.MatchedBy(func(f *models.AppField) bool { return f.FieldCode == "subtotal" }),
+			`(("price" * "quantity") - CAST(100 AS NUMERIC))`).Return(nil)
+		mockDynamicQuery.On("SetFormulaColumn", ctx, "app_data_1", mock.MatchedBy(func(f *models.AppField) bool { return f.FieldCode == "total" }),
+			`((("price" * "quantity") - CAST(100 AS NUMERIC)) * CAST(1.1 AS NUMERIC))`).Return(nil)
+		mockFieldRepo.On("Update", ctx, mock.AnythingOfType("*models.AppField")).Return(nil)
+
+		service := services.NewFieldService(mockFieldRepo, mockAppRepo, mockDynamicQuery, newTestPermissionService(mockAppRepo), newTestTransactor(), newTestWebhookPublisher(), newTestAttachmentManager())
+
+		resp, err := service.UpdateField(ctx, 3, 0, &models.UpdateFieldRequest{
+			Options: models.FieldOptions{"expression": "price * quantity - 100"},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "price * quantity - 100", resp.Options["expression"])
+		mockDynamicQuery.AssertExpectations(t)
+		// 依存する計算フィールドと自身の定義を保存する
+		mockFieldRepo.AssertNumberOfCalls(t, "Update", 2)
+	})
+
+	t.Run("unchanged expression keeps column", func(t *testing.T) {
+		mockFieldRepo := new(mocks.MockFieldRepository)
+		mockAppRepo := new(mocks.MockAppRepository)
+		mockDynamicQuery := new(mocks.MockDynamicQueryExecutor)
+
+		fields := cloneFields(quoteFields)
+		subtotal := fields[2]
+		mockFieldRepo.On("GetByID", ctx, uint64(3)).Return(&subtotal, nil)
+		mockAppRepo.On("GetByID", ctx, uint64(1)).Return(quoteApp, nil)
+		mockFieldRepo.On("GetByAppID", ctx, uint64(1)).Return(fields, nil)
+		mockFieldRepo.On("Update", ctx, mock.AnythingOfType("*models.AppField")).Return(nil)
+
+		service := services.NewFieldService(mockFieldRepo, mockAppRepo, mockDynamicQuery, newTestPermissionService(mockAppRepo), newTestTransactor(), newTestWebhookPublisher(), newTestAttachmentManager())
+
+		_, err := service.UpdateField(ctx, 3, 0, &models.UpdateFieldRequest{
+			FieldName: "小計（税抜）",
+			Options:   models.FieldOptions{"expression": " price * quantity "},
+		})
+		require.NoError(t, err)
+		mockDynamicQuery.AssertNotCalled(t, "SetFormulaColumn", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("cycle is rejected", func(t *testing.T) {
+		mockFieldRepo := new(mocks.MockFieldRepository)
+		mockAppRepo := new(mocks.MockAppRepository)
+		mockDynamicQuery := new(mocks.MockDynamicQueryExecutor)
+
+		fields := cloneFields(quoteFields)
+		subtotal := fields[2]
+		mockFieldRepo.On("GetByID", ctx, uint64(3)).Return(&subtotal, nil)
+		mockAppRepo.On("GetByID", ctx, uint64(1)).Return(quoteApp, nil)
+		mockFieldRepo.On("GetByAppID", ctx, uint64(1)).Return(fields, nil)
+
+		service := services.NewFieldService(mockFieldRepo, mockAppRepo, mockDynamicQuery, newTestPermissionService(mockAppRepo), newTestTransactor(), newTestWebhookPublisher(), newTestAttachmentManager())
+
+		_, err := service.UpdateField(ctx, 3, 0, &models.UpdateFieldRequest{
+			Options: models.FieldOptions{"expression": "total - price"},
+		})
+		assert.ErrorIs(t, err, services.ErrInvalidFieldOptions)
+		assert.Contains(t, err.Error(), "循環")
+		mockFieldRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+	})
+}
+
+func TestFieldService_DeleteField_UsedByFormula(t *testing.T) {
+	ctx := systemContext()
+
+	mockFieldRepo := new(mocks.MockFieldRepository)
+	mockAppRepo := new(mocks.MockAppRepository)
+	mockDynamicQuery := new(mocks.MockDynamicQueryExecutor)
+
+	fields := cloneFields(quoteFields)
+	mockFieldRepo.On("GetByID", ctx, uint64(2)).Return(&fields[1], nil)
+	mockAppRepo.On("GetByID", ctx, uint64(1)).Return(quoteApp, nil)
+	mockFieldRepo.On("GetByAppID", ctx, uint64(1)).Return(fields, nil)
+
+	service := services.NewFieldService(mockFieldRepo, mockAppRepo, mockDynamicQuery, newTestPermissionService(mockAppRepo), newTestTransactor(), newTestWebhookPublisher(), newTestAttachmentManager())
+
+	err := service.DeleteField(ctx, 1, 2, 0)
+	assert.ErrorIs(t, err, services.ErrFieldInUse)
+	mockDynamicQuery.AssertNotCalled(t, "DropColumn", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestAppService_CreateApp_Formula(t *testing.T) {
+	ctx := systemContext()
+
+	mockAppRepo := new(mocks.MockAppRepository)
+	mockFieldRepo := new(mocks.MockFieldRepository)
+	mockDynamicQuery := new(mocks.MockDynamicQueryExecutor)
+
+	mockAppRepo.On("Create", ctx, mock.AnythingOfType("*models.App")).Return(nil).Run(func(args mock.Arguments) {
+		args.Get(1).(*models.App).ID = 3
+	})
+	mockAppRepo.On("Update", ctx, mock.AnythingOfType("*models.App")).Return(nil)
+	mockFieldRepo.On("CreateBatch", ctx, mock.MatchedBy(func(fields []models.AppField) bool {
+		return fields[2].Options["result_type"] == "number"
+	})).Return(nil)
+	mockDynamicQuery.On("CreateTable", ctx, "app_data_3", mock.AnythingOfType("[]models.AppField")).Return(nil)
+	mockDynamicQuery.On("SetSearchColumn", ctx, "app_data_3", mock.AnythingOfType("[]models.AppField"), "simple").Return(nil)
+	mockDynamicQuery.On("SetFormulaColumn", ctx, "app_data_3", mock.MatchedBy(func(f *models.AppField) bool { return f.FieldCode == "days" }),
+		`CAST(("end_date" - "start_date") AS NUMERIC)`).Return(nil)
+	mockAppRepo.On("GetByIDWithFields", ctx, uint64(3)).Return(&models.App{ID: 3, Name: "Tasks"}, nil)
+
+	service := services.NewAppService(mockAppRepo, mockFieldRepo, mockDynamicQuery, new(mocks.MockDataSourceRepository), newTestTransactor(), newTestPermissionService(mockAppRepo), newTestWebhookPublisher(), newTestAttachmentManager())
+
+	_, err := service.CreateApp(ctx, 1, &models.CreateAppRequest{
+		Name: "Tasks",
+		Fields: []models.CreateFieldRequest{
+			{FieldCode: "start_date", FieldName: "開始日", FieldType: "date"},
+			{FieldCode: "end_date", FieldName: "終了日", FieldType: "date"},
+			{FieldCode: "days", FieldName: "日数", FieldType: "formula", Options: models.FieldOptions{"expression": "end_date - start_date"}},
+		},
+	})
+	require.NoError(t, err)
+	mockFieldRepo.AssertExpectations(t)
+	mockDynamicQuery.AssertExpectations(t)
+
+	t.Run("cycle between new formulas creates nothing", func(t *testing.T) {
+		mockAppRepo := new(mocks.MockAppRepository)
+
+		service := services.NewAppService(mockAppRepo, new(mocks.MockFieldRepository), new(mocks.MockDynamicQueryExecutor), new(mocks.MockDataSourceRepository), newTestTransactor(), newTestPermissionService(mockAppRepo), newTestWebhookPublisher(), newTestAttachmentManager())
+
+		_, err := service.CreateApp(ctx, 1, &models.CreateAppRequest{
+			Name: "Tasks",
+			Fields: []models.CreateFieldRequest{
+				{FieldCode: "a", FieldName: "A", FieldType: "formula", Options: models.FieldOptions{"expression": "b + 1"}},
+				{FieldCode: "b", FieldName: "B", FieldType: "formula", Options: models.FieldOptions{"expression": "a + 1"}},
+			},
+		})
+		assert.ErrorIs(t, err, services.ErrInvalidFieldOptions)
+		mockAppRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	})
+}
+
+func TestRecordService_CreateRecord_FormulaNotStored(t *testing.T) {
+	ctx := systemContext()
+
+	mockAppRepo := new(mocks.MockAppRepository)
+	mockFieldRepo := new(mocks.MockFieldRepository)
+	mockDynamicQuery := new(mocks.MockDynamicQueryExecutor)
+	mockRevisionRepo := new(mocks.MockRecordRevisionRepository)
+
+	fields := cloneFields(quoteFields)
+	fields[2].Required = true
+	mockAppRepo.On("GetByID", ctx, uint64(1)).Return(quoteApp, nil)
+	mockFieldRepo.On("GetByAppID", ctx, uint64(1)).Return(fields, nil)
+	mockDynamicQuery.On("InsertRecord", ctx, "app_data_1", models.RecordData{"price": float64(100), "quantity": float64(2)}, uint64(1)).Return(uint64(100), nil)
+	mockDynamicQuery.On("GetRecordByID", ctx, "app_data_1", fields, uint64(100)).Return(&models.RecordResponse{ID: 100}, nil)
+	mockRevisionRepo.On("Create", ctx, mock.AnythingOfType("*models.RecordRevision")).Return(nil)
+
+	service := services.NewRecordService(mockAppRepo, mockFieldRepo, mockDynamicQuery, new(mocks.MockDataSourceRepository), new(mocks.MockExternalQueryExecutor), newTestPermissionService(mockAppRepo), mockRevisionRepo, newTestTransactor(), newTestWebhookPublisher(), newTestAutomationRunner(), newTestAttachmentManager())
+
+	_, err := service.CreateRecord(ctx, 1, 1, &models.CreateRecordRequest{
+		Data: models.RecordData{"price": 100, "quantity": 2, "total": 999},
+	})
+	require.NoError(t, err)
+	mockDynamicQuery.AssertExpectations(t)
+}
@@ -0,0 +1,182 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"nocode-app/backend/internal/models"
+	"nocode-app/backend/internal/repositories"
+)
+
+const (
+	// globalSearchWorkers 全アプリ横断検索でレコードを同時に検索するアプリの数
+	globalSearchWorkers = 4
+	// globalSearchTimeout 全アプリ横断検索の制限時間（超えた分のアプリは結果に含めない）
+	globalSearchTimeout = 5 * time.Second
+	// DefaultGlobalSearchLimit 全アプリ横断検索でアプリごとに返すレコード数の既定値
+	DefaultGlobalSearchLimit = 5
+	// MaxGlobalSearchLimit 全アプリ横断検索でアプリごとに返すレコード数の上限
+	MaxGlobalSearchLimit = 20
+)
+
+// GlobalSearchService 呼び出し元が閲覧できる全ての内部アプリを横断して検索する構造体
+type GlobalSearchService struct {
+	appRepo      repositories.AppRepositoryInterface
+	dynamicQuery repositories.DynamicQueryExecutorInterface
+	permissions  PermissionServiceInterface
+	workers      int
+	timeout      time.Duration
+}
+
+// NewGlobalSearchService 新しいGlobalSearchServiceを作成する
+func NewGlobalSearchService(
+	appRepo repositories.AppRepositoryInterface,
+	dynamicQuery repositories.DynamicQueryExecutorInterface,
+	permissions PermissionServiceInterface,
+) *GlobalSearchService {
+	return &GlobalSearchService{
+		appRepo:      appRepo,
+		dynamicQuery: dynamicQuery,
+		permissions:  permissions,
+		workers:      globalSearchWorkers,
+		timeout:      globalSearchTimeout,
+	}
+}
+
+// globalSearchJob 全アプリ横断検索のアプリごとの検索結果
+type globalSearchJob struct {
+	result *models.GlobalSearchAppResult
+	// done 制限時間内に検索を終えたかどうか
+	done bool
+}
+
+// Search アプリ名・フィールド名・レコードの内容からキーワードを検索し、アプリごとにまとめて返す
+// アプリごとのレコードの検索は上限数のワーカーで並行して行い、制限時間を超えたアプリは結果に含めず Partial を設定する
+func (s *GlobalSearchService) Search(ctx context.Context, query string, limit int) (*models.GlobalSearchResponse, error) {
+	query = strings.TrimSpace(query)
+	terms, err := parseSearchTerms(query)
+	if err != nil {
+		return nil, err
+	}
+	if terms == nil {
+		return nil, fmt.Errorf("%w: 検索キーワードを指定してください", ErrInvalidSearch)
+	}
+	if limit <= 0 {
+		limit = DefaultGlobalSearchLimit
+	}
+	limit = min(limit, MaxGlobalSearchLimit)
+
+	tableNames, err := s.appRepo.GetAllTableNames(ctx)
+	if err != nil {
+		return nil, err
+	}
+	apps, err := s.appRepo.GetByTableNames(ctx, tableNames)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	jobs := make([]globalSearchJob, len(apps))
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for range min(s.workers, len(apps)) {
+		wg.Go(func() {
+			for i := range indexes {
+				result, err := s.searchApp(ctx, &apps[i], query, terms, limit)
+				// 時間切れ以外のエラー（テーブルがまだない場合など）はアプリを飛ばす
+				jobs[i] = globalSearchJob{result: result, done: err == nil || ctx.Err() == nil}
+			}
+		})
+	}
+feed:
+	for i := range apps {
+		select {
+		case indexes <- i:
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(indexes)
+	wg.Wait()
+
+	resp := &models.GlobalSearchResponse{Query: query, Results: []models.GlobalSearchAppResult{}}
+	for i := range jobs {
+		if !jobs[i].done {
+			resp.Partial = true
+			continue
+		}
+		if jobs[i].result != nil {
+			resp.Results = append(resp.Results, *jobs[i].result)
+		}
+	}
+	return resp, nil
+}
+
+// searchApp 1つのアプリのアプリ名・フィールド名・レコードを検索する（閲覧できない場合や一致しない場合はnil）
+func (s *GlobalSearchService) searchApp(ctx context.Context, app *models.App, query string, terms []string, limit int) (*models.GlobalSearchAppResult, error) {
+	access, err := s.permissions.CheckAppAccess(ctx, app, models.AppRoleViewer)
+	if err != nil {
+		if errors.Is(err, ErrAppNotFound) || errors.Is(err, ErrPermissionDenied) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	result := &models.GlobalSearchAppResult{
+		AppID:       app.ID,
+		AppName:     app.Name,
+		AppIcon:     app.Icon,
+		NameMatched: models.ContainsAllTerms(app.Name, terms),
+		Fields:      []models.GlobalSearchFieldHit{},
+		Records:     []models.GlobalSearchRecordHit{},
+	}
+	for i := range app.Fields {
+		if models.ContainsAllTerms(app.Fields[i].FieldName, terms) || models.ContainsAllTerms(app.Fields[i].FieldCode, terms) {
+			result.Fields = append(result.Fields, models.GlobalSearchFieldHit{
+				FieldCode: app.Fields[i].FieldCode,
+				FieldName: app.Fields[i].FieldName,
+			})
+		}
+	}
+
+	searchable := searchableFields(app.Fields)
+	if len(searchable) > 0 {
+		opts := repositories.RecordQueryOptions{Page: 1, Limit: limit, Search: query}
+		opts.Filters = append(opts.Filters, access.RecordFilters()...)
+		if _, err := applyRecordSearch(app, app.Fields, &opts); err != nil {
+			return nil, err
+		}
+		records, total, err := s.dynamicQuery.GetRecords(ctx, app.TableName, app.Fields, opts)
+		if err != nil {
+			return nil, err
+		}
+		highlightRecords(records, app.Fields, terms)
+
+		result.RecordTotal = total
+		for i := range records {
+			hit := models.GlobalSearchRecordHit{
+				RecordID:  records[i].ID,
+				Rank:      records[i].Search.Rank,
+				Highlight: records[i].Search.Highlight,
+			}
+			for j := range searchable {
+				if v, ok := records[i].Data[searchable[j].FieldCode].(string); ok && models.ContainsAnyTerm(v, terms) {
+					hit.FieldCode, hit.FieldName = searchable[j].FieldCode, searchable[j].FieldName
+					break
+				}
+			}
+			result.Records = append(result.Records, hit)
+		}
+	}
+
+	if !result.NameMatched && len(result.Fields) == 0 && len(result.Records) == 0 {
+		return nil, nil
+	}
+	return result, nil
+}
@@ -0,0 +1,145 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"nocode-app/backend/internal/middleware"
+	"nocode-app/backend/internal/models"
+	"nocode-app/backend/internal/repositories"
+	"nocode-app/backend/internal/testhelpers/mocks"
+	"nocode-app/backend/internal/utils"
+)
+
+func globalSearchTestApps() []models.App {
+	trigram := models.SearchConfigTrigram
+	return []models.App{
+		{
+			ID: 1, Name: "顧客管理", Icon: "users", TableName: "app_data_1", CreatedBy: 10,
+			Fields: []models.AppField{
+				{FieldCode: "name", FieldName: "顧客名", FieldType: "text"},
+				{FieldCode: "memo", FieldName: "メモ", FieldType: "textarea"},
+				{FieldCode: "amount", FieldName: "金額", FieldType: "number"},
+			},
+		},
+		{
+			ID: 2, Name: "在庫", Icon: "box", TableName: "app_data_2", CreatedBy: 10, SearchConfig: &trigram,
+			Fields: []models.AppField{{FieldCode: "item", FieldName: "品名", FieldType: "text"}},
+		},
+		{
+			ID: 3, Name: "顧客（非公開）", TableName: "app_data_3", CreatedBy: 99,
+			Fields: []models.AppField{{FieldCode: "name", FieldName: "顧客名", FieldType: "text"}},
+		},
+	}
+}
+
+func TestGlobalSearchService_Search(t *testing.T) {
+	t.Run("groups hits by accessible app", func(t *testing.T) {
+		mockAppRepo := new(mocks.MockAppRepository)
+		mockDynamicQuery := new(mocks.MockDynamicQueryExecutor)
+		mockPermRepo := new(mocks.MockAppPermissionRepository)
+		mockGroupRepo := new(mocks.MockGroupRepository)
+		service := NewGlobalSearchService(mockAppRepo, mockDynamicQuery, NewPermissionService(mockPermRepo, mockGroupRepo, mockAppRepo, new(mocks.MockUserRepository)))
+
+		apps := globalSearchTestApps()
+		tableNames := []string{"app_data_1", "app_data_2", "app_data_3", "external_4"}
+		mockAppRepo.On("GetAllTableNames", mock.Anything).Return(tableNames, nil)
+		mockAppRepo.On("GetByTableNames", mock.Anything, tableNames).Return(apps, nil)
+
+		// アプリ3は権限が設定されており、ユーザー10には付与されていない
+		mockPermRepo.On("HasAny", mock.Anything, uint64(3)).Return(true, nil)
+		mockGroupRepo.On("GetGroupIDsByUserID", mock.Anything, uint64(10)).Return([]uint64{}, nil)
+		mockPermRepo.On("GetForSubjects", mock.Anything, uint64(3), uint64(10), []uint64{}).Return([]models.AppPermission{}, nil)
+
+		// 全文検索用カラムのないアプリは絞り込み条件で検索する
+		mockDynamicQuery.On("GetRecords", mock.Anything, "app_data_1", apps[0].Fields, mock.MatchedBy(func(opts repositories.RecordQueryOptions) bool {
+			return opts.SearchConfig == "" && opts.Filter != nil && opts.Limit == DefaultGlobalSearchLimit
+		})).Return([]models.RecordResponse{
+			{ID: 7, Data: models.RecordData{"name": "山田", "memo": "顧客から電話"}},
+		}, int64(12), nil)
+		mockDynamicQuery.On("GetRecords", mock.Anything, "app_data_2", apps[1].Fields, mock.MatchedBy(func(opts repositories.RecordQueryOptions) bool {
+			return opts.Search == "顧客" && opts.SearchConfig == models.SearchConfigTrigram
+		})).Return([]models.RecordResponse{}, int64(0), nil)
+
+		ctx := middleware.SetUserInContext(context.Background(), &utils.JWTClaims{UserID: 10, Role: "user"})
+		resp, err := service.Search(ctx, " 顧客 ", 0)
+		require.NoError(t, err)
+
+		assert.Equal(t, "顧客", resp.Query)
+		assert.False(t, resp.Partial)
+		require.Len(t, resp.Results, 1)
+		result := resp.Results[0]
+		assert.Equal(t, uint64(1), result.AppID)
+		assert.True(t, result.NameMatched)
+		assert.Equal(t, []models.GlobalSearchFieldHit{{FieldCode: "name", FieldName: "顧客名"}}, result.Fields)
+		assert.Equal(t, int64(12), result.RecordTotal)
+		assert.Equal(t, []models.GlobalSearchRecordHit{{
+			RecordID:  7,
+			FieldCode: "memo",
+			FieldName: "メモ",
+			Highlight: "<mark>顧客</mark>から電話",
+		}}, result.Records)
+
+		mockDynamicQuery.AssertNotCalled(t, "GetRecords", mock.Anything, "app_data_3", mock.Anything, mock.Anything)
+		mockDynamicQuery.AssertExpectations(t)
+	})
+
+	t.Run("limit is capped", func(t *testing.T) {
+		mockAppRepo := new(mocks.MockAppRepository)
+		mockDynamicQuery := new(mocks.MockDynamicQueryExecutor)
+		service := NewGlobalSearchService(mockAppRepo, mockDynamicQuery, NewPermissionService(new(mocks.MockAppPermissionRepository), new(mocks.MockGroupRepository), mockAppRepo, new(mocks.MockUserRepository)))
+
+		apps := globalSearchTestApps()[1:2]
+		mockAppRepo.On("GetAllTableNames", mock.Anything).Return([]string{"app_data_2"}, nil)
+		mockAppRepo.On("GetByTableNames", mock.Anything, []string{"app_data_2"}).Return(apps, nil)
+		mockDynamicQuery.On("GetRecords", mock.Anything, "app_data_2", apps[0].Fields, mock.MatchedBy(func(opts repositories.RecordQueryOptions) bool {
+			return opts.Limit == MaxGlobalSearchLimit
+		})).Return([]models.RecordResponse{}, int64(0), nil)
+
+		resp, err := service.Search(WithSystemCall(context.Background()), "在庫", 1000)
+		require.NoError(t, err)
+		require.Len(t, resp.Results, 1)
+		assert.True(t, resp.Results[0].NameMatched)
+		assert.Empty(t, resp.Results[0].Records)
+		mockDynamicQuery.AssertExpectations(t)
+	})
+
+	t.Run("slow table does not block the response", func(t *testing.T) {
+		mockAppRepo := new(mocks.MockAppRepository)
+		mockDynamicQuery := new(mocks.MockDynamicQueryExecutor)
+		service := NewGlobalSearchService(mockAppRepo, mockDynamicQuery, NewPermissionService(new(mocks.MockAppPermissionRepository), new(mocks.MockGroupRepository), mockAppRepo, new(mocks.MockUserRepository)))
+
+		service.workers = 1
+		service.timeout = 50 * time.Millisecond
+		apps := globalSearchTestApps()[:2]
+		mockAppRepo.On("GetAllTableNames", mock.Anything).Return([]string{"app_data_1", "app_data_2"}, nil)
+		mockAppRepo.On("GetByTableNames", mock.Anything, mock.Anything).Return(apps, nil)
+		mockDynamicQuery.On("GetRecords", mock.Anything, "app_data_1", mock.Anything, mock.Anything).
+			Run(func(args mock.Arguments) {
+				<-args.Get(0).(context.Context).Done()
+			}).
+			Return(nil, int64(0), context.DeadlineExceeded)
+
+		start := time.Now()
+		resp, err := service.Search(WithSystemCall(context.Background()), "顧客", 0)
+		require.NoError(t, err)
+		assert.Less(t, time.Since(start), time.Second)
+		assert.True(t, resp.Partial)
+		assert.Empty(t, resp.Results)
+		mockDynamicQuery.AssertNotCalled(t, "GetRecords", mock.Anything, "app_data_2", mock.Anything, mock.Anything)
+	})
+
+	t.Run("empty query", func(t *testing.T) {
+		mockAppRepo := new(mocks.MockAppRepository)
+		service := NewGlobalSearchService(mockAppRepo, new(mocks.MockDynamicQueryExecutor), NewPermissionService(new(mocks.MockAppPermissionRepository), new(mocks.MockGroupRepository), mockAppRepo, new(mocks.MockUserRepository)))
+
+		_, err := service.Search(WithSystemCall(context.Background()), "  ", 0)
+		assert.ErrorIs(t, err, ErrInvalidSearch)
+		mockAppRepo.AssertNotCalled(t, "GetAllTableNames", mock.Anything)
+	})
+}
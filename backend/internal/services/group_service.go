@@ -0,0 +1,163 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"nocode-app/backend/internal/models"
+	"nocode-app/backend/internal/repositories"
+)
+
+// グループ関連エラー
+var (
+	ErrGroupNameExists = errors.New("グループ名は既に存在します")
+)
+
+// GroupService ユーザーグループ操作を処理する構造体
+type GroupService struct {
+	groupRepo repositories.GroupRepositoryInterface
+	userRepo  repositories.UserRepositoryInterface
+}
+
+// NewGroupService 新しいGroupServiceを作成する
+func NewGroupService(groupRepo repositories.GroupRepositoryInterface, userRepo repositories.UserRepositoryInterface) *GroupService {
+	return &GroupService{
+		groupRepo: groupRepo,
+		userRepo:  userRepo,
+	}
+}
+
+// GetGroups 全グループを取得する
+func (s *GroupService) GetGroups(ctx context.Context) (*models.GroupListResponse, error) {
+	groups, err := s.groupRepo.GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]models.GroupResponse, len(groups))
+	for i := range groups {
+		responses[i] = *groups[i].ToResponse()
+	}
+	return &models.GroupListResponse{Groups: responses}, nil
+}
+
+// GetGroup IDでグループをメンバー付きで取得する
+func (s *GroupService) GetGroup(ctx context.Context, groupID uint64) (*models.GroupResponse, error) {
+	group, err := s.groupRepo.GetByID(ctx, groupID)
+	if err != nil {
+		return nil, err
+	}
+	if group == nil {
+		return nil, ErrGroupNotFound
+	}
+	return group.ToResponse(), nil
+}
+
+// CreateGroup 新しいグループを作成する
+func (s *GroupService) CreateGroup(ctx context.Context, req *models.CreateGroupRequest) (*models.GroupResponse, error) {
+	exists, err := s.groupRepo.NameExists(ctx, req.Name, 0)
+	if err != nil {
+		return nil, err
+	}
+	if exists {
+		return nil, ErrGroupNameExists
+	}
+
+	now := time.Now()
+	group := &models.Group{
+		Name:        req.Name,
+		Description: req.Description,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	if err := s.groupRepo.Create(ctx, group); err != nil {
+		return nil, err
+	}
+	return group.ToResponse(), nil
+}
+
+// UpdateGroup グループを更新する
+func (s *GroupService) UpdateGroup(ctx context.Context, groupID uint64, req *models.UpdateGroupRequest) (*models.GroupResponse, error) {
+	group, err := s.groupRepo.GetByID(ctx, groupID)
+	if err != nil {
+		return nil, err
+	}
+	if group == nil {
+		return nil, ErrGroupNotFound
+	}
+
+	if req.Name != "" && req.Name != group.Name {
+		exists, err := s.groupRepo.NameExists(ctx, req.Name, groupID)
+		if err != nil {
+			return nil, err
+		}
+		if exists {
+			return nil, ErrGroupNameExists
+		}
+		group.Name = req.Name
+	}
+	if req.Description != nil {
+		group.Description = *req.Description
+	}
+	group.UpdatedAt = time.Now()
+
+	if err := s.groupRepo.Update(ctx, group); err != nil {
+		return nil, err
+	}
+	return group.ToResponse(), nil
+}
+
+// DeleteGroup グループを削除する
+func (s *GroupService) DeleteGroup(ctx context.Context, groupID uint64) error {
+	group, err := s.groupRepo.GetByID(ctx, groupID)
+	if err != nil {
+		return err
+	}
+	if group == nil {
+		return ErrGroupNotFound
+	}
+	return s.groupRepo.Delete(ctx, groupID)
+}
+
+// AddMember グループにユーザーを追加する
+func (s *GroupService) AddMember(ctx context.Context, groupID uint64, req *models.AddGroupMemberRequest) (*models.GroupResponse, error) {
+	group, err := s.groupRepo.GetByID(ctx, groupID)
+	if err != nil {
+		return nil, err
+	}
+	if group == nil {
+		return nil, ErrGroupNotFound
+	}
+
+	user, err := s.userRepo.GetByID(ctx, req.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, ErrUserNotFound
+	}
+
+	if err := s.groupRepo.AddMember(ctx, groupID, req.UserID); err != nil {
+		return nil, err
+	}
+
+	members, err := s.groupRepo.GetMembers(ctx, groupID)
+	if err != nil {
+		return nil, err
+	}
+	group.Members = members
+	return group.ToResponse(), nil
+}
+
+// RemoveMember グループからユーザーを削除する
+func (s *GroupService) RemoveMember(ctx context.Context, groupID, userID uint64) error {
+	group, err := s.groupRepo.GetByID(ctx, groupID)
+	if err != nil {
+		return err
+	}
+	if group == nil {
+		return ErrGroupNotFound
+	}
+	return s.groupRepo.RemoveMember(ctx, groupID, userID)
+}
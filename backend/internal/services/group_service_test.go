@@ -0,0 +1,131 @@
+package services_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"nocode-app/backend/internal/models"
+	"nocode-app/backend/internal/services"
+	"nocode-app/backend/internal/testhelpers/mocks"
+)
+
+func TestGroupService_CreateGroup(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("successful creation", func(t *testing.T) {
+		mockGroupRepo := new(mocks.MockGroupRepository)
+		mockUserRepo := new(mocks.MockUserRepository)
+
+		mockGroupRepo.On("NameExists", ctx, "Sales", uint64(0)).Return(false, nil)
+		mockGroupRepo.On("Create", ctx, mock.AnythingOfType("*models.Group")).Return(nil).Run(func(args mock.Arguments) {
+			args.Get(1).(*models.Group).ID = 1
+		})
+
+		service := services.NewGroupService(mockGroupRepo, mockUserRepo)
+
+		resp, err := service.CreateGroup(ctx, &models.CreateGroupRequest{Name: "Sales"})
+		require.NoError(t, err)
+		assert.Equal(t, uint64(1), resp.ID)
+		assert.Equal(t, "Sales", resp.Name)
+		assert.Empty(t, resp.Members)
+
+		mockGroupRepo.AssertExpectations(t)
+	})
+
+	t.Run("duplicate name", func(t *testing.T) {
+		mockGroupRepo := new(mocks.MockGroupRepository)
+
+		mockGroupRepo.On("NameExists", ctx, "Sales", uint64(0)).Return(true, nil)
+
+		service := services.NewGroupService(mockGroupRepo, new(mocks.MockUserRepository))
+
+		_, err := service.CreateGroup(ctx, &models.CreateGroupRequest{Name: "Sales"})
+		assert.ErrorIs(t, err, services.ErrGroupNameExists)
+	})
+}
+
+func TestGroupService_UpdateGroup(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("rename", func(t *testing.T) {
+		mockGroupRepo := new(mocks.MockGroupRepository)
+
+		mockGroupRepo.On("GetByID", ctx, uint64(1)).Return(&models.Group{ID: 1, Name: "Sales"}, nil)
+		mockGroupRepo.On("NameExists", ctx, "Marketing", uint64(1)).Return(false, nil)
+		mockGroupRepo.On("Update", ctx, mock.AnythingOfType("*models.Group")).Return(nil)
+
+		service := services.NewGroupService(mockGroupRepo, new(mocks.MockUserRepository))
+
+		description := "Marketing team"
+		resp, err := service.UpdateGroup(ctx, 1, &models.UpdateGroupRequest{Name: "Marketing", Description: &description})
+		require.NoError(t, err)
+		assert.Equal(t, "Marketing", resp.Name)
+		assert.Equal(t, "Marketing team", resp.Description)
+	})
+
+	t.Run("group not found", func(t *testing.T) {
+		mockGroupRepo := new(mocks.MockGroupRepository)
+
+		mockGroupRepo.On("GetByID", ctx, uint64(999)).Return(nil, nil)
+
+		service := services.NewGroupService(mockGroupRepo, new(mocks.MockUserRepository))
+
+		_, err := service.UpdateGroup(ctx, 999, &models.UpdateGroupRequest{Name: "x"})
+		assert.ErrorIs(t, err, services.ErrGroupNotFound)
+	})
+}
+
+func TestGroupService_AddMember(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("successful add", func(t *testing.T) {
+		mockGroupRepo := new(mocks.MockGroupRepository)
+		mockUserRepo := new(mocks.MockUserRepository)
+
+		user := models.User{ID: 2, Name: "Member", Email: "member@example.com", Role: "user"}
+		mockGroupRepo.On("GetByID", ctx, uint64(1)).Return(&models.Group{ID: 1, Name: "Sales"}, nil)
+		mockUserRepo.On("GetByID", ctx, uint64(2)).Return(&user, nil)
+		mockGroupRepo.On("AddMember", ctx, uint64(1), uint64(2)).Return(nil)
+		mockGroupRepo.On("GetMembers", ctx, uint64(1)).Return([]models.User{user}, nil)
+
+		service := services.NewGroupService(mockGroupRepo, mockUserRepo)
+
+		resp, err := service.AddMember(ctx, 1, &models.AddGroupMemberRequest{UserID: 2})
+		require.NoError(t, err)
+		require.Len(t, resp.Members, 1)
+		assert.Equal(t, "Member", resp.Members[0].Name)
+
+		mockGroupRepo.AssertExpectations(t)
+	})
+
+	t.Run("user not found", func(t *testing.T) {
+		mockGroupRepo := new(mocks.MockGroupRepository)
+		mockUserRepo := new(mocks.MockUserRepository)
+
+		mockGroupRepo.On("GetByID", ctx, uint64(1)).Return(&models.Group{ID: 1}, nil)
+		mockUserRepo.On("GetByID", ctx, uint64(999)).Return(nil, nil)
+
+		service := services.NewGroupService(mockGroupRepo, mockUserRepo)
+
+		_, err := service.AddMember(ctx, 1, &models.AddGroupMemberRequest{UserID: 999})
+		assert.ErrorIs(t, err, services.ErrUserNotFound)
+		mockGroupRepo.AssertNotCalled(t, "AddMember", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestGroupService_DeleteGroup(t *testing.T) {
+	ctx := context.Background()
+
+	mockGroupRepo := new(mocks.MockGroupRepository)
+	mockGroupRepo.On("GetByID", ctx, uint64(1)).Return(&models.Group{ID: 1}, nil)
+	mockGroupRepo.On("Delete", ctx, uint64(1)).Return(nil)
+
+	service := services.NewGroupService(mockGroupRepo, new(mocks.MockUserRepository))
+
+	require.NoError(t, service.DeleteGroup(ctx, 1))
+	mockGroupRepo.AssertExpectations(t)
+}
@@ -0,0 +1,39 @@
+package services_test
+
+import (
+	"github.com/stretchr/testify/mock"
+
+	"nocode-app/backend/internal/testhelpers/mocks"
+)
+
+// newTestWebhookPublisher 全てのイベント発行を受け付けるWebhook発行のモックを作成する
+func newTestWebhookPublisher() *mocks.MockWebhookPublisher {
+	publisher := new(mocks.MockWebhookPublisher)
+	publisher.On("Publish", mock.Anything, mock.Anything).Return(nil).Maybe()
+	return publisher
+}
+
+// newTestTransactor トランザクション内の処理をそのまま実行するトランザクションのモックを作成する
+func newTestTransactor() *mocks.MockTransactor {
+	transactor := new(mocks.MockTransactor)
+	transactor.On("RunInTx", mock.Anything).Return(nil).Maybe()
+	return transactor
+}
+
+// newTestAutomationRunner 全ての自動化ルールの実行を受け付ける自動化ルール実行のモックを作成する
+func newTestAutomationRunner() *mocks.MockAutomationRunner {
+	runner := new(mocks.MockAutomationRunner)
+	runner.On("Run", mock.Anything, mock.Anything).Return(nil).Maybe()
+	return runner
+}
+
+// newTestAttachmentManager 添付ファイルを指定しないレコード操作を受け付ける添付ファイル管理のモックを作成する
+func newTestAttachmentManager() *mocks.MockAttachmentManager {
+	manager := new(mocks.MockAttachmentManager)
+	manager.On("ResolveValues", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil, nil).Maybe()
+	manager.On("AttachRecord", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil).Maybe()
+	manager.On("ReleaseRecords", mock.Anything, mock.Anything, mock.Anything).Return(nil).Maybe()
+	manager.On("ReleaseField", mock.Anything, mock.Anything, mock.Anything).Return(nil).Maybe()
+	manager.On("ReleaseApp", mock.Anything, mock.Anything).Return(nil).Maybe()
+	return manager
+}
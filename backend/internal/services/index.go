@@ -0,0 +1,443 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"nocode-app/backend/internal/models"
+	"nocode-app/backend/internal/repositories"
+)
+
+// インデックス関連エラー
+var (
+	ErrIndexNotFound = errors.New("インデックスが見つかりません")
+	ErrInvalidIndex  = errors.New("インデックスの指定が正しくありません")
+	ErrIndexExists   = errors.New("同じ定義のインデックスが既にあります")
+	// ErrDuplicateValue 一意制約（フィールドの unique オプション・一意インデックス）に反する値の書き込み
+	ErrDuplicateValue = errors.New("同じ値のレコードが既にあります")
+)
+
+// OptionUnique 値の重複を禁止するかどうか（true の場合はカラムに一意制約を付ける）
+const OptionUnique = "unique"
+
+// maxIndexNameLength PostgreSQL の識別子の最大長（バイト）
+const maxIndexNameLength = 63
+
+// IndexService 動的テーブルのインデックスを管理する構造体
+type IndexService struct {
+	indexRepo    repositories.AppIndexRepositoryInterface
+	appRepo      repositories.AppRepositoryInterface
+	fieldRepo    repositories.FieldRepositoryInterface
+	viewRepo     repositories.ViewRepositoryInterface
+	dynamicQuery repositories.DynamicQueryExecutorInterface
+	permissions  PermissionServiceInterface
+}
+
+// NewIndexService 新しいIndexServiceを作成する
+func NewIndexService(
+	indexRepo repositories.AppIndexRepositoryInterface,
+	appRepo repositories.AppRepositoryInterface,
+	fieldRepo repositories.FieldRepositoryInterface,
+	viewRepo repositories.ViewRepositoryInterface,
+	dynamicQuery repositories.DynamicQueryExecutorInterface,
+	permissions PermissionServiceInterface,
+) *IndexService {
+	return &IndexService{
+		indexRepo:    indexRepo,
+		appRepo:      appRepo,
+		fieldRepo:    fieldRepo,
+		viewRepo:     viewRepo,
+		dynamicQuery: dynamicQuery,
+		permissions:  permissions,
+	}
+}
+
+// authorizeTableOwner 呼び出し元がアプリのオーナーであることを確認する
+// 外部データソースのアプリのテーブルは管理しないため対象外とする
+func (s *IndexService) authorizeTableOwner(ctx context.Context, appID uint64) (*models.App, error) {
+	app, _, err := s.permissions.AuthorizeApp(ctx, appID, models.AppRoleOwner)
+	if err != nil {
+		return nil, err
+	}
+	if app.IsExternal {
+		return nil, ErrExternalAppReadOnly
+	}
+	return app, nil
+}
+
+// GetIndexes アプリに作成したインデックスを大きさと利用状況とともに取得する
+// フィールドの削除でカラムとともに消えたインデックスの定義はここで削除する
+func (s *IndexService) GetIndexes(ctx context.Context, appID uint64) ([]models.IndexResponse, error) {
+	app, err := s.authorizeTableOwner(ctx, appID)
+	if err != nil {
+		return nil, err
+	}
+
+	indexes, err := s.indexRepo.GetByAppID(ctx, appID)
+	if err != nil {
+		return nil, err
+	}
+	usage, err := s.indexUsage(ctx, app.TableName)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]models.IndexResponse, 0, len(indexes))
+	for i := range indexes {
+		u, ok := usage[indexes[i].IndexName]
+		if !ok {
+			if err := s.indexRepo.Delete(ctx, indexes[i].ID); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		responses = append(responses, *indexes[i].ToResponse(u))
+	}
+	return responses, nil
+}
+
+// CreateIndex アプリの動的テーブルにインデックスを作成する
+// 一意インデックスは既に重複した値がある場合は作成できない
+func (s *IndexService) CreateIndex(ctx context.Context, appID, userID uint64, req *models.CreateIndexRequest) (*models.IndexResponse, error) {
+	app, err := s.authorizeTableOwner(ctx, appID)
+	if err != nil {
+		return nil, err
+	}
+
+	fields, err := s.fieldRepo.GetByAppID(ctx, appID)
+	if err != nil {
+		return nil, err
+	}
+	index := &models.AppIndex{
+		AppID:      appID,
+		FieldCodes: req.FieldCodes,
+		IsUnique:   req.Unique,
+		CreatedAt:  time.Now(),
+	}
+	if userID != 0 {
+		index.CreatedBy = &userID
+	}
+	if err := validateIndex(app, fields, index, req.Filter); err != nil {
+		return nil, err
+	}
+
+	existing, err := s.indexRepo.GetByAppID(ctx, appID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range existing {
+		if sameIndex(&existing[i], index) {
+			return nil, fmt.Errorf("%w: %s", ErrIndexExists, existing[i].IndexName)
+		}
+	}
+	usage, err := s.indexUsage(ctx, app.TableName)
+	if err != nil {
+		return nil, err
+	}
+	index.IndexName = indexName(app.TableName, index.FieldCodes, func(name string) bool {
+		_, taken := usage[name]
+		return taken || slices.ContainsFunc(existing, func(idx models.AppIndex) bool { return idx.IndexName == name })
+	})
+
+	if err := s.dynamicQuery.CreateIndex(ctx, app.TableName, index); err != nil {
+		return nil, duplicateValueError(err, fields)
+	}
+	if err := s.indexRepo.Create(ctx, index); err != nil {
+		// 定義を登録できなかったインデックスは管理できないため削除する
+		_ = s.dynamicQuery.DropIndex(ctx, index.IndexName)
+		return nil, err
+	}
+
+	return index.ToResponse(models.IndexUsage{IndexName: index.IndexName, Valid: true}), nil
+}
+
+// DeleteIndex アプリの動的テーブルからインデックスを削除する
+func (s *IndexService) DeleteIndex(ctx context.Context, appID, indexID uint64) error {
+	if _, err := s.authorizeTableOwner(ctx, appID); err != nil {
+		return err
+	}
+
+	index, err := s.indexRepo.GetByID(ctx, indexID)
+	if err != nil {
+		return err
+	}
+	if index == nil || index.AppID != appID {
+		return ErrIndexNotFound
+	}
+
+	if err := s.dynamicQuery.DropIndex(ctx, index.IndexName); err != nil {
+		return err
+	}
+	return s.indexRepo.Delete(ctx, indexID)
+}
+
+// SuggestIndexes 保存済みのビューの絞り込み条件と並べ替えから、作成すると速くなるインデックスを提案する
+func (s *IndexService) SuggestIndexes(ctx context.Context, appID uint64) ([]models.IndexSuggestion, error) {
+	if _, err := s.authorizeTableOwner(ctx, appID); err != nil {
+		return nil, err
+	}
+
+	fields, err := s.fieldRepo.GetByAppID(ctx, appID)
+	if err != nil {
+		return nil, err
+	}
+	views, err := s.viewRepo.GetByAppID(ctx, appID)
+	if err != nil {
+		return nil, err
+	}
+	indexes, err := s.indexRepo.GetByAppID(ctx, appID)
+	if err != nil {
+		return nil, err
+	}
+	return suggestIndexes(fields, views, indexes), nil
+}
+
+// indexUsage 動的テーブルのインデックスの統計情報をインデックス名ごとに返す
+func (s *IndexService) indexUsage(ctx context.Context, tableName string) (map[string]models.IndexUsage, error) {
+	usage, err := s.dynamicQuery.GetIndexUsage(ctx, tableName)
+	if err != nil {
+		return nil, err
+	}
+	byName := make(map[string]models.IndexUsage, len(usage))
+	for _, u := range usage {
+		byName[u.IndexName] = u
+	}
+	return byName, nil
+}
+
+// indexableColumns インデックスに含められるカラムを返す
+// カラムを持つフィールド（JSONBで保存する複数選択・添付ファイルを除く）と作成者・作成日時・更新日時。レコードIDは主キーのため除く
+func indexableColumns(fields []models.AppField) map[string]bool {
+	columns := map[string]bool{"created_by": true, "created_at": true, "updated_at": true}
+	for i := range fields {
+		switch models.FieldType(fields[i].FieldType) {
+		case models.FieldTypeMultiSelect, models.FieldTypeAttachment:
+			continue
+		}
+		if fields[i].HasColumn() {
+			columns[fields[i].FieldCode] = true
+		}
+	}
+	return columns
+}
+
+// validateIndex インデックスのカラムと部分インデックスの条件を検証し、条件をデータベースで評価できる形にして設定する
+func validateIndex(app *models.App, fields []models.AppField, index *models.AppIndex, filter *models.FilterExpr) error {
+	if len(index.FieldCodes) == 0 || len(index.FieldCodes) > models.MaxIndexFields {
+		return fmt.Errorf("%w: フィールドは1〜%d個指定してください", ErrInvalidIndex, models.MaxIndexFields)
+	}
+	columns := indexableColumns(fields)
+	for i, code := range index.FieldCodes {
+		if !columns[code] {
+			return fmt.Errorf("%w: %q はインデックスに含められません", ErrInvalidIndex, code)
+		}
+		if slices.Contains(index.FieldCodes[:i], code) {
+			return fmt.Errorf("%w: %q が重複しています", ErrInvalidIndex, code)
+		}
+	}
+
+	if filter == nil {
+		return nil
+	}
+	// 相対的な期間は作成した日時で固定されてしまうため部分インデックスの条件にできない
+	if filterUsesOperator(filter, models.FilterOpInPeriod) {
+		return fmt.Errorf("%w: 部分インデックスの条件に相対的な期間（in_period）は使えません", ErrInvalidIndex)
+	}
+	_, resolved, err := resolveFilters(app, fields, "", nil, filter)
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrInvalidIndex, err.Error())
+	}
+	index.Filter = resolved
+	return nil
+}
+
+// filterUsesOperator フィルター式に演算子 op の条件が含まれるかどうかを返す
+func filterUsesOperator(e *models.FilterExpr, op string) bool {
+	if !e.IsGroup() {
+		return e.Operator == op
+	}
+	for _, children := range [][]models.FilterExpr{e.And, e.Or} {
+		for i := range children {
+			if filterUsesOperator(&children[i], op) {
+				return true
+			}
+		}
+	}
+	return e.Not != nil && filterUsesOperator(e.Not, op)
+}
+
+// sameIndex 2つのインデックスの定義（カラム・一意かどうか・条件）が同じかどうかを返す
+func sameIndex(a, b *models.AppIndex) bool {
+	if a.IsUnique != b.IsUnique || !slices.Equal(a.FieldCodes, b.FieldCodes) {
+		return false
+	}
+	// 条件は検証済みの式をJSONとして比較する
+	fa, errA := json.Marshal(a.Filter)
+	fb, errB := json.Marshal(b.Filter)
+	return errA == nil && errB == nil && string(fa) == string(fb)
+}
+
+// indexName 動的テーブルのインデックスの名前を求める
+// PostgreSQL の既定の命名規則（{テーブル}_{カラム}_idx）に合わせ、使われている場合は末尾に番号を付ける。
+// 識別子の最大長を超える場合はカラム名を省く
+func indexName(tableName string, fieldCodes []string, taken func(name string) bool) string {
+	base := tableName + "_" + strings.Join(fieldCodes, "_") + "_idx"
+	// 末尾の番号の分の余裕を残す
+	if len(base)+4 > maxIndexNameLength {
+		base = tableName + "_idx"
+	}
+	name := base
+	for n := 1; taken(name); n++ {
+		name = base + strconv.Itoa(n)
+	}
+	return name
+}
+
+// viewQueryConfig インデックスの提案に使うビューの設定（並べ替えと絞り込み条件）
+type viewQueryConfig struct {
+	Sort *struct {
+		Field string `json:"field"`
+	} `json:"sort"`
+	Filters []models.FilterItem `json:"filters"`
+}
+
+// suggestIndexes ビューごとに絞り込み条件と並べ替えのカラムからインデックスを組み立て、同じものをまとめて提案する
+// 一致で絞り込むカラムを先に並べ、並べ替えのカラム（ない場合は範囲で絞り込むカラム）を続ける。
+// 既存のインデックスや一意制約の先頭のカラムで足りるものは提案しない
+func suggestIndexes(fields []models.AppField, views []models.AppView, indexes []models.AppIndex) []models.IndexSuggestion {
+	columns := indexableColumns(fields)
+
+	// 部分インデックスでない既存のインデックスと一意制約は、先頭のカラムで絞り込みと並べ替えに使える
+	var covering [][]string
+	for i := range indexes {
+		if indexes[i].Filter == nil {
+			covering = append(covering, indexes[i].FieldCodes)
+		}
+	}
+	for i := range fields {
+		if fields[i].IsUnique() {
+			covering = append(covering, []string{fields[i].FieldCode})
+		}
+	}
+
+	suggestions := []models.IndexSuggestion{}
+	byKey := map[string]int{}
+	for i := range views {
+		codes := viewIndexColumns(views[i].Config, columns)
+		if len(codes) == 0 || slices.ContainsFunc(covering, func(c []string) bool {
+			return len(c) >= len(codes) && slices.Equal(c[:len(codes)], codes)
+		}) {
+			continue
+		}
+
+		view := models.IndexSuggestionView{ID: views[i].ID, Name: views[i].Name}
+		key := strings.Join(codes, ",")
+		if n, ok := byKey[key]; ok {
+			suggestions[n].Views = append(suggestions[n].Views, view)
+			continue
+		}
+		byKey[key] = len(suggestions)
+		suggestions = append(suggestions, models.IndexSuggestion{FieldCodes: codes, Views: []models.IndexSuggestionView{view}})
+	}
+
+	// 多くのビューで使われるものを先にする
+	sort.SliceStable(suggestions, func(a, b int) bool {
+		return len(suggestions[a].Views) > len(suggestions[b].Views)
+	})
+	return suggestions
+}
+
+// viewIndexColumns ビューの設定からインデックスにするカラムを順に返す（インデックスで速くならない場合は空）
+func viewIndexColumns(config models.ViewConfig, columns map[string]bool) []string {
+	data, err := json.Marshal(config)
+	if err != nil {
+		return nil
+	}
+	var cfg viewQueryConfig
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return nil
+	}
+
+	var codes, ranges []string
+	for _, f := range cfg.Filters {
+		if !columns[f.Field] {
+			continue
+		}
+		switch f.Operator {
+		case models.FilterOpEq, models.FilterOpIn, models.FilterOpIsNull:
+			if !slices.Contains(codes, f.Field) {
+				codes = append(codes, f.Field)
+			}
+		case models.FilterOpGt, models.FilterOpGte, models.FilterOpLt, models.FilterOpLte,
+			models.FilterOpBetween, models.FilterOpInPeriod:
+			ranges = append(ranges, f.Field)
+		}
+	}
+
+	// B-tree は一致の条件のカラムの後に続く1つのカラムまで範囲の絞り込みと並べ替えに使える
+	next := ""
+	if cfg.Sort != nil && columns[cfg.Sort.Field] {
+		next = cfg.Sort.Field
+	} else if len(ranges) > 0 {
+		next = ranges[0]
+	}
+	if next != "" && !slices.Contains(codes, next) {
+		codes = append(codes, next)
+	}
+	if len(codes) > models.MaxIndexFields {
+		codes = codes[:models.MaxIndexFields]
+	}
+	return codes
+}
+
+// validateUniqueOption フィールドの unique オプションを検証する
+func validateUniqueOption(app *models.App, field *models.AppField) error {
+	value, ok := field.Options[OptionUnique]
+	if !ok {
+		return nil
+	}
+	unique, isBool := value.(bool)
+	if !isBool {
+		return fmt.Errorf("%w: %s には true または false を指定してください", ErrInvalidFieldOptions, OptionUnique)
+	}
+	if !unique {
+		return nil
+	}
+	if app.IsExternal {
+		return fmt.Errorf("%w: 外部データソースのアプリのフィールドは値の重複を禁止できません", ErrInvalidFieldOptions)
+	}
+	if !field.SupportsUnique() {
+		return fmt.Errorf("%w: %s フィールドは値の重複を禁止できません", ErrInvalidFieldOptions, field.FieldType)
+	}
+	return nil
+}
+
+// duplicateValueError 一意制約違反のエラーを重複したフィールドを示す ErrDuplicateValue に置き換える
+// 一意制約違反以外のエラーはそのまま返す
+func duplicateValueError(err error, fields []models.AppField) error {
+	columns, ok := repositories.UniqueViolationColumns(err)
+	if !ok {
+		return err
+	}
+	if len(columns) == 0 {
+		return ErrDuplicateValue
+	}
+	names := make([]string, len(columns))
+	for i, column := range columns {
+		names[i] = column
+		for j := range fields {
+			if fields[j].FieldCode == column {
+				names[i] = fields[j].FieldName
+				break
+			}
+		}
+	}
+	return fmt.Errorf("%w: %s", ErrDuplicateValue, strings.Join(names, "・"))
+}
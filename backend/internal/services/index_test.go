@@ -0,0 +1,323 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"nocode-app/backend/internal/middleware"
+	"nocode-app/backend/internal/models"
+	"nocode-app/backend/internal/testhelpers/mocks"
+	"nocode-app/backend/internal/utils"
+)
+
+func indexTestFields() []models.AppField {
+	return []models.AppField{
+		{FieldCode: "status", FieldName: "ステータス", FieldType: "select"},
+		{FieldCode: "due_date", FieldName: "期限", FieldType: "date"},
+		{FieldCode: "email", FieldName: "メールアドレス", FieldType: "text", Options: models.FieldOptions{"unique": true}},
+		{FieldCode: "tags", FieldName: "タグ", FieldType: "multiselect"},
+		{FieldCode: "customer_name", FieldName: "顧客名", FieldType: "lookup"},
+	}
+}
+
+func TestIndexService_CreateIndex(t *testing.T) {
+	ctx := WithSystemCall(context.Background())
+	app := &models.App{ID: 1, TableName: "app_data_1", CreatedBy: 10}
+
+	t.Run("creates composite index", func(t *testing.T) {
+		mockIndexRepo := new(mocks.MockAppIndexRepository)
+		mockAppRepo := new(mocks.MockAppRepository)
+		mockFieldRepo := new(mocks.MockFieldRepository)
+		mockDynamicQuery := new(mocks.MockDynamicQueryExecutor)
+		service := NewIndexService(mockIndexRepo, mockAppRepo, mockFieldRepo, new(mocks.MockViewRepository), mockDynamicQuery, NewPermissionService(new(mocks.MockAppPermissionRepository), new(mocks.MockGroupRepository), mockAppRepo, new(mocks.MockUserRepository)))
+
+		mockAppRepo.On("GetByID", ctx, uint64(1)).Return(app, nil)
+		mockFieldRepo.On("GetByAppID", ctx, uint64(1)).Return(indexTestFields(), nil)
+		mockIndexRepo.On("GetByAppID", ctx, uint64(1)).Return([]models.AppIndex{}, nil)
+		mockDynamicQuery.On("GetIndexUsage", ctx, "app_data_1").Return([]models.IndexUsage{
+			{IndexName: "app_data_1_pkey", Valid: true},
+			{IndexName: "app_data_1_status_due_date_idx", Valid: true},
+		}, nil)
+		mockDynamicQuery.On("CreateIndex", ctx, "app_data_1", mock.MatchedBy(func(idx *models.AppIndex) bool {
+			return idx.IndexName == "app_data_1_status_due_date_idx1" && !idx.IsUnique && idx.Filter == nil
+		})).Return(nil)
+		mockIndexRepo.On("Create", ctx, mock.AnythingOfType("*models.AppIndex")).Return(nil)
+
+		resp, err := service.CreateIndex(ctx, 1, 10, &models.CreateIndexRequest{FieldCodes: []string{"status", "due_date"}})
+		require.NoError(t, err)
+		assert.Equal(t, "app_data_1_status_due_date_idx1", resp.IndexName)
+		assert.Equal(t, []string{"status", "due_date"}, resp.FieldCodes)
+		assert.Equal(t, uint64(10), *resp.CreatedBy)
+		mockDynamicQuery.AssertExpectations(t)
+		mockIndexRepo.AssertExpectations(t)
+	})
+
+	t.Run("partial index filter is resolved", func(t *testing.T) {
+		mockIndexRepo := new(mocks.MockAppIndexRepository)
+		mockAppRepo := new(mocks.MockAppRepository)
+		mockFieldRepo := new(mocks.MockFieldRepository)
+		mockDynamicQuery := new(mocks.MockDynamicQueryExecutor)
+		service := NewIndexService(mockIndexRepo, mockAppRepo, mockFieldRepo, new(mocks.MockViewRepository), mockDynamicQuery, NewPermissionService(new(mocks.MockAppPermissionRepository), new(mocks.MockGroupRepository), mockAppRepo, new(mocks.MockUserRepository)))
+
+		mockAppRepo.On("GetByID", ctx, uint64(1)).Return(app, nil)
+		mockFieldRepo.On("GetByAppID", ctx, uint64(1)).Return(indexTestFields(), nil)
+		mockIndexRepo.On("GetByAppID", ctx, uint64(1)).Return([]models.AppIndex{}, nil)
+		mockDynamicQuery.On("GetIndexUsage", ctx, "app_data_1").Return([]models.IndexUsage{}, nil)
+		mockDynamicQuery.On("CreateIndex", ctx, "app_data_1", mock.MatchedBy(func(idx *models.AppIndex) bool {
+			return idx.Filter != nil && idx.Filter.Field == "status" && idx.Filter.Operator == models.FilterOpEq
+		})).Return(nil)
+		mockIndexRepo.On("Create", ctx, mock.AnythingOfType("*models.AppIndex")).Return(nil)
+
+		filter := &models.FilterExpr{FilterItem: models.FilterItem{Field: "status", Operator: "eq", Value: "open"}}
+		resp, err := service.CreateIndex(ctx, 1, 10, &models.CreateIndexRequest{FieldCodes: []string{"due_date"}, Filter: filter})
+		require.NoError(t, err)
+		assert.Equal(t, "app_data_1_due_date_idx", resp.IndexName)
+		assert.NotNil(t, resp.Filter)
+	})
+
+	t.Run("invalid definitions", func(t *testing.T) {
+		tests := []struct {
+			name string
+			req  models.CreateIndexRequest
+		}{
+			{name: "unknown field", req: models.CreateIndexRequest{FieldCodes: []string{"missing"}}},
+			{name: "field without column", req: models.CreateIndexRequest{FieldCodes: []string{"customer_name"}}},
+			{name: "multiselect field", req: models.CreateIndexRequest{FieldCodes: []string{"tags"}}},
+			{name: "primary key", req: models.CreateIndexRequest{FieldCodes: []string{"id"}}},
+			{name: "duplicate field", req: models.CreateIndexRequest{FieldCodes: []string{"status", "status"}}},
+			{name: "relative period", req: models.CreateIndexRequest{
+				FieldCodes: []string{"status"},
+				Filter:     &models.FilterExpr{FilterItem: models.FilterItem{Field: "due_date", Operator: "in_period", Value: "this_month"}},
+			}},
+			{name: "invalid filter", req: models.CreateIndexRequest{
+				FieldCodes: []string{"status"},
+				Filter:     &models.FilterExpr{FilterItem: models.FilterItem{Field: "due_date", Operator: "eq", Value: "tomorrow"}},
+			}},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				mockAppRepo := new(mocks.MockAppRepository)
+				mockFieldRepo := new(mocks.MockFieldRepository)
+				mockDynamicQuery := new(mocks.MockDynamicQueryExecutor)
+				service := NewIndexService(new(mocks.MockAppIndexRepository), mockAppRepo, mockFieldRepo, new(mocks.MockViewRepository), mockDynamicQuery, NewPermissionService(new(mocks.MockAppPermissionRepository), new(mocks.MockGroupRepository), mockAppRepo, new(mocks.MockUserRepository)))
+
+				mockAppRepo.On("GetByID", ctx, uint64(1)).Return(app, nil)
+				mockFieldRepo.On("GetByAppID", ctx, uint64(1)).Return(indexTestFields(), nil)
+
+				_, err := service.CreateIndex(ctx, 1, 10, &tt.req)
+				assert.ErrorIs(t, err, ErrInvalidIndex)
+				mockDynamicQuery.AssertNotCalled(t, "CreateIndex", mock.Anything, mock.Anything, mock.Anything)
+			})
+		}
+	})
+
+	t.Run("same definition exists", func(t *testing.T) {
+		mockIndexRepo := new(mocks.MockAppIndexRepository)
+		mockAppRepo := new(mocks.MockAppRepository)
+		mockFieldRepo := new(mocks.MockFieldRepository)
+		service := NewIndexService(mockIndexRepo, mockAppRepo, mockFieldRepo, new(mocks.MockViewRepository), new(mocks.MockDynamicQueryExecutor), NewPermissionService(new(mocks.MockAppPermissionRepository), new(mocks.MockGroupRepository), mockAppRepo, new(mocks.MockUserRepository)))
+
+		mockAppRepo.On("GetByID", ctx, uint64(1)).Return(app, nil)
+		mockFieldRepo.On("GetByAppID", ctx, uint64(1)).Return(indexTestFields(), nil)
+		mockIndexRepo.On("GetByAppID", ctx, uint64(1)).Return([]models.AppIndex{
+			{ID: 3, IndexName: "app_data_1_status_idx", FieldCodes: []string{"status"}},
+		}, nil)
+
+		_, err := service.CreateIndex(ctx, 1, 10, &models.CreateIndexRequest{FieldCodes: []string{"status"}})
+		assert.ErrorIs(t, err, ErrIndexExists)
+	})
+
+	t.Run("unique index with duplicate values", func(t *testing.T) {
+		mockIndexRepo := new(mocks.MockAppIndexRepository)
+		mockAppRepo := new(mocks.MockAppRepository)
+		mockFieldRepo := new(mocks.MockFieldRepository)
+		mockDynamicQuery := new(mocks.MockDynamicQueryExecutor)
+		service := NewIndexService(mockIndexRepo, mockAppRepo, mockFieldRepo, new(mocks.MockViewRepository), mockDynamicQuery, NewPermissionService(new(mocks.MockAppPermissionRepository), new(mocks.MockGroupRepository), mockAppRepo, new(mocks.MockUserRepository)))
+
+		mockAppRepo.On("GetByID", ctx, uint64(1)).Return(app, nil)
+		mockFieldRepo.On("GetByAppID", ctx, uint64(1)).Return(indexTestFields(), nil)
+		mockIndexRepo.On("GetByAppID", ctx, uint64(1)).Return([]models.AppIndex{}, nil)
+		mockDynamicQuery.On("GetIndexUsage", ctx, "app_data_1").Return([]models.IndexUsage{}, nil)
+		mockDynamicQuery.On("CreateIndex", ctx, "app_data_1", mock.Anything).
+			Return(&pq.Error{Code: "23505", Detail: "Key (status, due_date)=(open, 2024-01-01) is duplicated."})
+
+		_, err := service.CreateIndex(ctx, 1, 10, &models.CreateIndexRequest{FieldCodes: []string{"status", "due_date"}, Unique: true})
+		assert.ErrorIs(t, err, ErrDuplicateValue)
+		assert.Contains(t, err.Error(), "ステータス・期限")
+		mockIndexRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	})
+
+	t.Run("external app", func(t *testing.T) {
+		mockAppRepo := new(mocks.MockAppRepository)
+		service := NewIndexService(new(mocks.MockAppIndexRepository), mockAppRepo, new(mocks.MockFieldRepository), new(mocks.MockViewRepository), new(mocks.MockDynamicQueryExecutor), NewPermissionService(new(mocks.MockAppPermissionRepository), new(mocks.MockGroupRepository), mockAppRepo, new(mocks.MockUserRepository)))
+
+		mockAppRepo.On("GetByID", ctx, uint64(2)).Return(&models.App{ID: 2, IsExternal: true}, nil)
+
+		_, err := service.CreateIndex(ctx, 2, 10, &models.CreateIndexRequest{FieldCodes: []string{"status"}})
+		assert.ErrorIs(t, err, ErrExternalAppReadOnly)
+	})
+
+	t.Run("requires owner", func(t *testing.T) {
+		mockAppRepo := new(mocks.MockAppRepository)
+		mockPermRepo := new(mocks.MockAppPermissionRepository)
+		service := NewIndexService(new(mocks.MockAppIndexRepository), mockAppRepo, new(mocks.MockFieldRepository), new(mocks.MockViewRepository), new(mocks.MockDynamicQueryExecutor), NewPermissionService(mockPermRepo, new(mocks.MockGroupRepository), mockAppRepo, new(mocks.MockUserRepository)))
+
+		mockAppRepo.On("GetByID", mock.Anything, uint64(1)).Return(app, nil)
+		mockPermRepo.On("HasAny", mock.Anything, uint64(1)).Return(false, nil)
+
+		userCtx := middleware.SetUserInContext(ctx, &utils.JWTClaims{UserID: 20, Role: "user"})
+		_, err := service.CreateIndex(userCtx, 1, 20, &models.CreateIndexRequest{FieldCodes: []string{"status"}})
+		assert.ErrorIs(t, err, ErrPermissionDenied)
+	})
+}
+
+func TestIndexService_GetIndexes(t *testing.T) {
+	ctx := WithSystemCall(context.Background())
+	mockIndexRepo := new(mocks.MockAppIndexRepository)
+	mockAppRepo := new(mocks.MockAppRepository)
+	mockDynamicQuery := new(mocks.MockDynamicQueryExecutor)
+	service := NewIndexService(mockIndexRepo, mockAppRepo, new(mocks.MockFieldRepository), new(mocks.MockViewRepository), mockDynamicQuery, NewPermissionService(new(mocks.MockAppPermissionRepository), new(mocks.MockGroupRepository), mockAppRepo, new(mocks.MockUserRepository)))
+
+	mockAppRepo.On("GetByID", ctx, uint64(1)).Return(&models.App{ID: 1, TableName: "app_data_1"}, nil)
+	mockIndexRepo.On("GetByAppID", ctx, uint64(1)).Return([]models.AppIndex{
+		{ID: 1, AppID: 1, IndexName: "app_data_1_status_idx", FieldCodes: []string{"status"}},
+		// フィールドの削除でカラムとともに消えたインデックス
+		{ID: 2, AppID: 1, IndexName: "app_data_1_memo_idx", FieldCodes: []string{"memo"}},
+	}, nil)
+	mockDynamicQuery.On("GetIndexUsage", ctx, "app_data_1").Return([]models.IndexUsage{
+		{IndexName: "app_data_1_pkey", SizeBytes: 8192, Valid: true},
+		{IndexName: "app_data_1_status_idx", SizeBytes: 16384, Scans: 42, Valid: true},
+	}, nil)
+	mockIndexRepo.On("Delete", ctx, uint64(2)).Return(nil)
+
+	indexes, err := service.GetIndexes(ctx, 1)
+	require.NoError(t, err)
+	require.Len(t, indexes, 1)
+	assert.Equal(t, "app_data_1_status_idx", indexes[0].IndexName)
+	assert.Equal(t, int64(16384), indexes[0].SizeBytes)
+	assert.Equal(t, int64(42), indexes[0].Scans)
+	assert.True(t, indexes[0].Valid)
+	mockIndexRepo.AssertExpectations(t)
+}
+
+func TestIndexService_DeleteIndex(t *testing.T) {
+	ctx := WithSystemCall(context.Background())
+
+	t.Run("drops index", func(t *testing.T) {
+		mockIndexRepo := new(mocks.MockAppIndexRepository)
+		mockAppRepo := new(mocks.MockAppRepository)
+		mockDynamicQuery := new(mocks.MockDynamicQueryExecutor)
+		service := NewIndexService(mockIndexRepo, mockAppRepo, new(mocks.MockFieldRepository), new(mocks.MockViewRepository), mockDynamicQuery, NewPermissionService(new(mocks.MockAppPermissionRepository), new(mocks.MockGroupRepository), mockAppRepo, new(mocks.MockUserRepository)))
+
+		mockAppRepo.On("GetByID", ctx, uint64(1)).Return(&models.App{ID: 1, TableName: "app_data_1"}, nil)
+		mockIndexRepo.On("GetByID", ctx, uint64(5)).Return(&models.AppIndex{ID: 5, AppID: 1, IndexName: "app_data_1_status_idx"}, nil)
+		mockDynamicQuery.On("DropIndex", ctx, "app_data_1_status_idx").Return(nil)
+		mockIndexRepo.On("Delete", ctx, uint64(5)).Return(nil)
+
+		require.NoError(t, service.DeleteIndex(ctx, 1, 5))
+		mockDynamicQuery.AssertExpectations(t)
+		mockIndexRepo.AssertExpectations(t)
+	})
+
+	t.Run("index of another app", func(t *testing.T) {
+		mockIndexRepo := new(mocks.MockAppIndexRepository)
+		mockAppRepo := new(mocks.MockAppRepository)
+		mockDynamicQuery := new(mocks.MockDynamicQueryExecutor)
+		service := NewIndexService(mockIndexRepo, mockAppRepo, new(mocks.MockFieldRepository), new(mocks.MockViewRepository), mockDynamicQuery, NewPermissionService(new(mocks.MockAppPermissionRepository), new(mocks.MockGroupRepository), mockAppRepo, new(mocks.MockUserRepository)))
+
+		mockAppRepo.On("GetByID", ctx, uint64(1)).Return(&models.App{ID: 1, TableName: "app_data_1"}, nil)
+		mockIndexRepo.On("GetByID", ctx, uint64(5)).Return(&models.AppIndex{ID: 5, AppID: 2, IndexName: "app_data_2_status_idx"}, nil)
+
+		assert.ErrorIs(t, service.DeleteIndex(ctx, 1, 5), ErrIndexNotFound)
+		mockDynamicQuery.AssertNotCalled(t, "DropIndex", mock.Anything, mock.Anything)
+	})
+
+	t.Run("drop fails", func(t *testing.T) {
+		mockIndexRepo := new(mocks.MockAppIndexRepository)
+		mockAppRepo := new(mocks.MockAppRepository)
+		mockDynamicQuery := new(mocks.MockDynamicQueryExecutor)
+		service := NewIndexService(mockIndexRepo, mockAppRepo, new(mocks.MockFieldRepository), new(mocks.MockViewRepository), mockDynamicQuery, NewPermissionService(new(mocks.MockAppPermissionRepository), new(mocks.MockGroupRepository), mockAppRepo, new(mocks.MockUserRepository)))
+
+		mockAppRepo.On("GetByID", ctx, uint64(1)).Return(&models.App{ID: 1, TableName: "app_data_1"}, nil)
+		mockIndexRepo.On("GetByID", ctx, uint64(5)).Return(&models.AppIndex{ID: 5, AppID: 1, IndexName: "app_data_1_status_idx"}, nil)
+		mockDynamicQuery.On("DropIndex", ctx, "app_data_1_status_idx").Return(errors.New("db error"))
+
+		assert.Error(t, service.DeleteIndex(ctx, 1, 5))
+		mockIndexRepo.AssertNotCalled(t, "Delete", mock.Anything, mock.Anything)
+	})
+}
+
+func TestSuggestIndexes(t *testing.T) {
+	views := []models.AppView{
+		{ID: 1, Name: "未対応", Config: models.ViewConfig{
+			"sort": map[string]interface{}{"field": "due_date", "order": "asc"},
+			"filters": []interface{}{
+				map[string]interface{}{"field": "status", "operator": "eq", "value": "open"},
+				map[string]interface{}{"field": "due_date", "operator": "gte", "value": "2024-01-01"},
+			},
+		}},
+		{ID: 2, Name: "未対応（担当者別）", Config: models.ViewConfig{
+			"sort": map[string]interface{}{"field": "due_date", "order": "desc"},
+			"filters": []interface{}{
+				map[string]interface{}{"field": "status", "operator": "in", "value": "open,pending"},
+			},
+		}},
+		// 期限の範囲のみで絞り込む
+		{ID: 3, Name: "今月", Config: models.ViewConfig{
+			"filters": []interface{}{
+				map[string]interface{}{"field": "due_date", "operator": "in_period", "value": "this_month"},
+				map[string]interface{}{"field": "email", "operator": "ilike", "value": "example"},
+			},
+		}},
+		// 一意制約のあるフィールドで並べるだけのビューは提案しない
+		{ID: 4, Name: "メール順", Config: models.ViewConfig{"sort": map[string]interface{}{"field": "email"}}},
+		// インデックスにできないフィールドと設定のないビュー
+		{ID: 5, Name: "タグ", Config: models.ViewConfig{
+			"filters": []interface{}{map[string]interface{}{"field": "tags", "operator": "contains_any", "value": "a"}},
+		}},
+		{ID: 6, Name: "すべて"},
+	}
+
+	t.Run("groups views by columns", func(t *testing.T) {
+		suggestions := suggestIndexes(indexTestFields(), views, nil)
+		assert.Equal(t, []models.IndexSuggestion{
+			{FieldCodes: []string{"status", "due_date"}, Views: []models.IndexSuggestionView{{ID: 1, Name: "未対応"}, {ID: 2, Name: "未対応（担当者別）"}}},
+			{FieldCodes: []string{"due_date"}, Views: []models.IndexSuggestionView{{ID: 3, Name: "今月"}}},
+		}, suggestions)
+	})
+
+	t.Run("existing index covers prefix", func(t *testing.T) {
+		indexes := []models.AppIndex{
+			{FieldCodes: []string{"status", "due_date", "email"}},
+			// 部分インデックスは全てのレコードの検索には使えない
+			{FieldCodes: []string{"due_date"}, Filter: &models.FilterExpr{FilterItem: models.FilterItem{Field: "status", Operator: "eq", Value: "open"}}},
+		}
+		suggestions := suggestIndexes(indexTestFields(), views, indexes)
+		require.Len(t, suggestions, 1)
+		assert.Equal(t, []string{"due_date"}, suggestions[0].FieldCodes)
+	})
+
+	t.Run("no views", func(t *testing.T) {
+		assert.Empty(t, suggestIndexes(indexTestFields(), nil, nil))
+	})
+}
+
+func TestIndexName(t *testing.T) {
+	none := func(string) bool { return false }
+	assert.Equal(t, "app_data_1_status_idx", indexName("app_data_1", []string{"status"}, none))
+	assert.Equal(t, "app_data_1_status_due_date_idx", indexName("app_data_1", []string{"status", "due_date"}, none))
+
+	taken := func(name string) bool { return name == "app_data_1_status_idx" || name == "app_data_1_status_idx1" }
+	assert.Equal(t, "app_data_1_status_idx2", indexName("app_data_1", []string{"status"}, taken))
+
+	long := []string{"a_very_long_field_code_for_testing", "another_long_field_code"}
+	name := indexName("app_data_1", long, none)
+	assert.Equal(t, "app_data_1_idx", name)
+	assert.LessOrEqual(t, len(name), maxIndexNameLength)
+}
@@ -3,8 +3,10 @@ package services
 
 import (
 	"context"
+	"io"
 
 	"nocode-app/backend/internal/models"
+	"nocode-app/backend/internal/realtime"
 	"nocode-app/backend/internal/repositories"
 	"nocode-app/backend/internal/utils"
 )
@@ -31,9 +33,11 @@ type AppServiceInterface interface {
 type FieldServiceInterface interface {
 	GetFields(ctx context.Context, appID uint64) ([]models.FieldResponse, error)
 	CreateField(ctx context.Context, appID uint64, req *models.CreateFieldRequest) (*models.FieldResponse, error)
-	UpdateField(ctx context.Context, fieldID uint64, req *models.UpdateFieldRequest) (*models.FieldResponse, error)
-	DeleteField(ctx context.Context, appID, fieldID uint64) error
+	UpdateField(ctx context.Context, fieldID uint64, version int64, req *models.UpdateFieldRequest) (*models.FieldResponse, error)
+	DeleteField(ctx context.Context, appID, fieldID uint64, version int64) error
 	UpdateFieldOrder(ctx context.Context, appID uint64, req *models.UpdateFieldOrderRequest) error
+	PreviewFieldConversion(ctx context.Context, appID, fieldID uint64, req *models.ConvertFieldRequest) (*models.FieldConversionPreview, error)
+	ConvertField(ctx context.Context, appID, fieldID uint64, req *models.ConvertFieldRequest) (*models.ConvertFieldResponse, error)
 }
 
 // RecordServiceInterface レコード操作のインターフェースを定義
@@ -41,18 +45,22 @@ type RecordServiceInterface interface {
 	GetRecords(ctx context.Context, appID uint64, opts repositories.RecordQueryOptions) (*models.RecordListResponse, error)
 	GetRecord(ctx context.Context, appID, recordID uint64) (*models.RecordResponse, error)
 	CreateRecord(ctx context.Context, appID, userID uint64, req *models.CreateRecordRequest) (*models.RecordResponse, error)
-	UpdateRecord(ctx context.Context, appID, recordID uint64, req *models.UpdateRecordRequest) (*models.RecordResponse, error)
-	DeleteRecord(ctx context.Context, appID, recordID uint64) error
+	UpdateRecord(ctx context.Context, appID, recordID uint64, version int64, req *models.UpdateRecordRequest) (*models.RecordResponse, error)
+	DeleteRecord(ctx context.Context, appID, recordID uint64, version int64) error
 	BulkCreateRecords(ctx context.Context, appID, userID uint64, req *models.BulkCreateRecordRequest) ([]models.RecordResponse, error)
 	BulkDeleteRecords(ctx context.Context, appID uint64, req *models.BulkDeleteRecordRequest) error
+	GetRecordHistory(ctx context.Context, appID, recordID uint64, page, limit int) (*models.RecordHistoryResponse, error)
+	RevertRecord(ctx context.Context, appID, recordID, revisionID uint64, version int64) (*models.RecordResponse, error)
+	ImportRecords(ctx context.Context, appID, userID uint64, req *models.ImportRecordsRequest) (*models.ImportResult, error)
+	ExportRecords(ctx context.Context, appID uint64, opts repositories.RecordQueryOptions, format models.ExportFormat, w io.Writer) error
 }
 
 // ViewServiceInterface ビュー操作のインターフェースを定義
 type ViewServiceInterface interface {
 	GetViews(ctx context.Context, appID uint64) ([]models.ViewResponse, error)
 	CreateView(ctx context.Context, appID uint64, req *models.CreateViewRequest) (*models.ViewResponse, error)
-	UpdateView(ctx context.Context, viewID uint64, req *models.UpdateViewRequest) (*models.ViewResponse, error)
-	DeleteView(ctx context.Context, viewID uint64) error
+	UpdateView(ctx context.Context, viewID uint64, version int64, req *models.UpdateViewRequest) (*models.ViewResponse, error)
+	DeleteView(ctx context.Context, viewID uint64, version int64) error
 }
 
 // ChartServiceInterface チャート操作のインターフェースを定義
@@ -63,6 +71,12 @@ type ChartServiceInterface interface {
 	DeleteChartConfig(ctx context.Context, configID uint64) error
 }
 
+// ReportServiceInterface ピボット集計（クロス集計）のインターフェースを定義
+type ReportServiceInterface interface {
+	GetPivot(ctx context.Context, appID uint64, req *models.PivotRequest) (*models.PivotResponse, error)
+	ExportPivot(ctx context.Context, appID uint64, req *models.PivotRequest, format models.ExportFormat, w io.Writer) error
+}
+
 // UserServiceInterface ユーザー管理操作のインターフェースを定義
 type UserServiceInterface interface {
 	GetUsers(ctx context.Context, callerRole string, page, limit int) (*models.UserListResponse, error)
@@ -91,12 +105,129 @@ type DashboardWidgetServiceInterface interface {
 	GetWidgets(ctx context.Context, userID uint64) (*models.DashboardWidgetListResponse, error)
 	GetVisibleWidgets(ctx context.Context, userID uint64) (*models.DashboardWidgetListResponse, error)
 	CreateWidget(ctx context.Context, userID uint64, req *models.CreateDashboardWidgetRequest) (*models.DashboardWidgetResponse, error)
-	UpdateWidget(ctx context.Context, userID, widgetID uint64, req *models.UpdateDashboardWidgetRequest) (*models.DashboardWidgetResponse, error)
-	DeleteWidget(ctx context.Context, userID, widgetID uint64) error
+	UpdateWidget(ctx context.Context, userID, widgetID uint64, version int64, req *models.UpdateDashboardWidgetRequest) (*models.DashboardWidgetResponse, error)
+	DeleteWidget(ctx context.Context, userID, widgetID uint64, version int64) error
 	ReorderWidgets(ctx context.Context, userID uint64, req *models.ReorderWidgetsRequest) error
 	ToggleVisibility(ctx context.Context, userID, widgetID uint64) (*models.DashboardWidgetResponse, error)
 }
 
+// PermissionServiceInterface アプリ権限操作のインターフェースを定義
+type PermissionServiceInterface interface {
+	AuthorizeApp(ctx context.Context, appID uint64, required models.AppRole) (*models.App, *models.AppAccess, error)
+	CheckAppAccess(ctx context.Context, app *models.App, required models.AppRole) (*models.AppAccess, error)
+	GetPermissions(ctx context.Context, appID uint64) (*models.AppPermissionListResponse, error)
+	GrantPermission(ctx context.Context, appID uint64, req *models.CreateAppPermissionRequest) (*models.AppPermissionResponse, error)
+	UpdatePermission(ctx context.Context, appID, permID uint64, req *models.UpdateAppPermissionRequest) (*models.AppPermissionResponse, error)
+	RevokePermission(ctx context.Context, appID, permID uint64) error
+}
+
+// GroupServiceInterface ユーザーグループ操作のインターフェースを定義
+type GroupServiceInterface interface {
+	GetGroups(ctx context.Context) (*models.GroupListResponse, error)
+	GetGroup(ctx context.Context, groupID uint64) (*models.GroupResponse, error)
+	CreateGroup(ctx context.Context, req *models.CreateGroupRequest) (*models.GroupResponse, error)
+	UpdateGroup(ctx context.Context, groupID uint64, req *models.UpdateGroupRequest) (*models.GroupResponse, error)
+	DeleteGroup(ctx context.Context, groupID uint64) error
+	AddMember(ctx context.Context, groupID uint64, req *models.AddGroupMemberRequest) (*models.GroupResponse, error)
+	RemoveMember(ctx context.Context, groupID, userID uint64) error
+}
+
+// WebhookServiceInterface Webhook管理操作のインターフェースを定義
+type WebhookServiceInterface interface {
+	GetWebhooks(ctx context.Context, appID uint64) (*models.WebhookListResponse, error)
+	CreateWebhook(ctx context.Context, appID, userID uint64, req *models.CreateWebhookRequest) (*models.WebhookResponse, error)
+	UpdateWebhook(ctx context.Context, appID, webhookID uint64, req *models.UpdateWebhookRequest) (*models.WebhookResponse, error)
+	DeleteWebhook(ctx context.Context, appID, webhookID uint64) error
+	GetDeliveries(ctx context.Context, appID, webhookID uint64, page, limit int) (*models.WebhookDeliveryListResponse, error)
+}
+
+// WebhookPublisherInterface Webhookイベント発行のインターフェースを定義
+type WebhookPublisherInterface interface {
+	Publish(ctx context.Context, events ...models.WebhookEvent) error
+	PublishTo(ctx context.Context, webhookID uint64, event models.WebhookEvent) error
+}
+
+// AutomationServiceInterface 自動化ルール管理操作のインターフェースを定義
+type AutomationServiceInterface interface {
+	GetRules(ctx context.Context, appID uint64) (*models.AutomationRuleListResponse, error)
+	CreateRule(ctx context.Context, appID, userID uint64, req *models.CreateAutomationRuleRequest) (*models.AutomationRule, error)
+	UpdateRule(ctx context.Context, appID, ruleID uint64, req *models.UpdateAutomationRuleRequest) (*models.AutomationRule, error)
+	DeleteRule(ctx context.Context, appID, ruleID uint64) error
+	GetRuns(ctx context.Context, appID, ruleID uint64, page, limit int) (*models.AutomationRunListResponse, error)
+}
+
+// AutomationRunnerInterface レコードの変更を契機とした自動化ルール実行のインターフェースを定義
+type AutomationRunnerInterface interface {
+	Run(ctx context.Context, revisions ...models.RecordRevision) error
+}
+
+// AttachmentServiceInterface 添付ファイル操作のインターフェースを定義
+type AttachmentServiceInterface interface {
+	Upload(ctx context.Context, appID, userID uint64, req *models.UploadAttachmentRequest) (*models.AttachmentValue, error)
+	GetAttachmentURL(ctx context.Context, appID, attachmentID uint64) (*models.AttachmentURLResponse, error)
+}
+
+// AttachmentManagerInterface レコード・フィールド・アプリの変更に伴う添付ファイルの管理のインターフェースを定義
+type AttachmentManagerInterface interface {
+	ResolveValues(ctx context.Context, appID, recordID, userID uint64, fields []models.AppField, rows []models.RecordData) (map[int]models.FieldErrors, error)
+	AttachRecord(ctx context.Context, appID, recordID uint64, fields []models.AppField, data models.RecordData) error
+	ReleaseRecords(ctx context.Context, appID uint64, recordIDs []uint64) error
+	ReleaseField(ctx context.Context, appID uint64, fieldCode string) error
+	ReleaseApp(ctx context.Context, appID uint64) error
+}
+
+// GlobalSearchServiceInterface 全アプリ横断検索のインターフェースを定義
+type GlobalSearchServiceInterface interface {
+	Search(ctx context.Context, query string, limit int) (*models.GlobalSearchResponse, error)
+}
+
+// IndexServiceInterface 動的テーブルのインデックス管理操作のインターフェースを定義
+type IndexServiceInterface interface {
+	GetIndexes(ctx context.Context, appID uint64) ([]models.IndexResponse, error)
+	CreateIndex(ctx context.Context, appID, userID uint64, req *models.CreateIndexRequest) (*models.IndexResponse, error)
+	DeleteIndex(ctx context.Context, appID, indexID uint64) error
+	SuggestIndexes(ctx context.Context, appID uint64) ([]models.IndexSuggestion, error)
+}
+
+// TrashServiceInterface ごみ箱の操作のインターフェースを定義
+type TrashServiceInterface interface {
+	GetAppTrash(ctx context.Context, appID uint64, page, limit int) (*models.AppTrashResponse, error)
+	RestoreRecord(ctx context.Context, appID, recordID uint64) (*models.RecordResponse, error)
+	PurgeRecord(ctx context.Context, appID, recordID uint64) error
+	RestoreField(ctx context.Context, appID, fieldID uint64) (*models.FieldResponse, error)
+	PurgeField(ctx context.Context, appID, fieldID uint64) error
+	GetTrashedApps(ctx context.Context) (*models.TrashedAppListResponse, error)
+	RestoreApp(ctx context.Context, appID uint64) (*models.AppResponse, error)
+	PurgeApp(ctx context.Context, appID uint64) error
+}
+
+// AppPackageServiceInterface アプリ定義のエクスポート・インポートとテンプレート操作のインターフェースを定義
+type AppPackageServiceInterface interface {
+	ExportApp(ctx context.Context, appID uint64, includeRecords bool) (*models.AppPackage, error)
+	ImportApp(ctx context.Context, userID uint64, req *models.ImportAppRequest) (*models.ImportAppResponse, error)
+	GetTemplates(ctx context.Context) (*models.AppTemplateListResponse, error)
+	GetTemplate(ctx context.Context, templateID string) (*models.AppTemplate, error)
+	CreateAppFromTemplate(ctx context.Context, userID uint64, templateID string, req *models.CreateAppFromTemplateRequest) (*models.ImportAppResponse, error)
+}
+
+// SavedReportServiceInterface 保存したレポートの管理と配信操作のインターフェースを定義
+type SavedReportServiceInterface interface {
+	GetReports(ctx context.Context, appID uint64) (*models.SavedReportListResponse, error)
+	GetReport(ctx context.Context, appID, reportID uint64) (*models.SavedReport, error)
+	CreateReport(ctx context.Context, appID, userID uint64, req *models.SaveReportRequest) (*models.SavedReport, error)
+	UpdateReport(ctx context.Context, appID, reportID uint64, req *models.SaveReportRequest) (*models.SavedReport, error)
+	DeleteReport(ctx context.Context, appID, reportID uint64) error
+	SendReport(ctx context.Context, appID, reportID uint64) (*models.ReportDelivery, error)
+	GetDeliveries(ctx context.Context, appID, reportID uint64, page, limit int) (*models.ReportDeliveryListResponse, error)
+}
+
+// RealtimeServiceInterface アプリの変更の購読操作のインターフェースを定義
+type RealtimeServiceInterface interface {
+	Authorize(ctx context.Context, appID uint64) (*models.AppAccess, error)
+	Subscribe(ctx context.Context, appID uint64) (*models.AppAccess, *realtime.Subscription, error)
+	IssueToken(ctx context.Context, appID uint64) (*models.RealtimeTokenResponse, error)
+}
+
 // 実装がインターフェースを満たすことを確認
 var (
 	_ AuthServiceInterface            = (*AuthService)(nil)
@@ -105,7 +236,23 @@ var (
 	_ RecordServiceInterface          = (*RecordService)(nil)
 	_ ViewServiceInterface            = (*ViewService)(nil)
 	_ ChartServiceInterface           = (*ChartService)(nil)
+	_ ReportServiceInterface          = (*ReportService)(nil)
 	_ UserServiceInterface            = (*UserService)(nil)
 	_ DataSourceServiceInterface      = (*DataSourceService)(nil)
 	_ DashboardWidgetServiceInterface = (*DashboardWidgetService)(nil)
+	_ PermissionServiceInterface      = (*PermissionService)(nil)
+	_ GroupServiceInterface           = (*GroupService)(nil)
+	_ WebhookServiceInterface         = (*WebhookService)(nil)
+	_ WebhookPublisherInterface       = (*WebhookService)(nil)
+	_ WebhookPublisherInterface       = (*EventPublisher)(nil)
+	_ AutomationServiceInterface      = (*AutomationService)(nil)
+	_ AutomationRunnerInterface       = (*AutomationService)(nil)
+	_ AttachmentServiceInterface      = (*AttachmentService)(nil)
+	_ AttachmentManagerInterface      = (*AttachmentService)(nil)
+	_ GlobalSearchServiceInterface    = (*GlobalSearchService)(nil)
+	_ IndexServiceInterface           = (*IndexService)(nil)
+	_ TrashServiceInterface           = (*TrashService)(nil)
+	_ AppPackageServiceInterface      = (*AppPackageService)(nil)
+	_ SavedReportServiceInterface     = (*SavedReportService)(nil)
+	_ RealtimeServiceInterface        = (*RealtimeService)(nil)
 )
@@ -0,0 +1,89 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"nocode-app/backend/internal/models"
+	"nocode-app/backend/internal/repositories"
+)
+
+// ErrInvalidPagination ページ送りの指定（カーソル・総件数の求め方）の誤り
+var ErrInvalidPagination = errors.New("ページ送りの指定が正しくありません")
+
+// resolveRecordPagination レコード一覧のページ送りの指定を検証し、カーソルを復号する
+// カーソル方式では並べ替えのキーがカラムを持つフィールド（内部アプリはレコードID・作成者・作成日時・更新日時も可）である必要があり、
+// カーソルは同じ並べ替えで作成されたものに限る
+func resolveRecordPagination(app *models.App, fields []models.AppField, opts *repositories.RecordQueryOptions) error {
+	if !opts.Count.IsValid() {
+		return fmt.Errorf("%w: count は exact・estimate・none のいずれかを指定してください", ErrInvalidPagination)
+	}
+	if !opts.Keyset {
+		return nil
+	}
+
+	order := "desc"
+	if opts.Sort != "" {
+		if !isKeysetSortable(app, fields, opts.Sort) {
+			return fmt.Errorf("%w: カーソル方式では %q で並べ替えられません", ErrInvalidPagination, opts.Sort)
+		}
+		if opts.Order != "desc" {
+			order = "asc"
+		}
+	} else if strings.TrimSpace(opts.Search) != "" && !app.IsExternal && app.SearchConfig != nil {
+		return fmt.Errorf("%w: 全文検索の一致度順ではカーソル方式を使えません（sort を指定してください）", ErrInvalidPagination)
+	}
+	opts.Order = order
+
+	if opts.Cursor == "" {
+		return nil
+	}
+	after, err := models.DecodeRecordCursor(opts.Cursor)
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrInvalidPagination, err.Error())
+	}
+	if after.Sort != opts.Sort || after.Order != order {
+		return fmt.Errorf("%w: カーソルを作成したときと並べ替えが異なります", ErrInvalidPagination)
+	}
+	opts.After = after
+	return nil
+}
+
+// isKeysetSortable カーソル方式の並べ替えのキーに使えるかどうかを返す
+func isKeysetSortable(app *models.App, fields []models.AppField, sort string) bool {
+	if !app.IsExternal {
+		switch sort {
+		case "id", "created_by", "created_at", "updated_at":
+			return true
+		}
+	}
+	for i := range fields {
+		if fields[i].FieldCode == sort {
+			return fields[i].HasColumn()
+		}
+	}
+	return false
+}
+
+// newRecordListResponse 取得したレコードからレコード一覧のレスポンスを作成する
+// カーソル方式では次のページの有無を判定するため Limit より1件多く取得しておき、余分な1件を除いて次のカーソルにする
+func newRecordListResponse(records []models.RecordResponse, total int64, opts *repositories.RecordQueryOptions) *models.RecordListResponse {
+	if !opts.Keyset {
+		pagination := models.NewPagination(opts.Page, opts.Limit, total)
+		if total < 0 {
+			pagination.Total, pagination.TotalPages = -1, -1
+		}
+		pagination.TotalEstimated = opts.Count == repositories.CountEstimate && total >= 0
+		return &models.RecordListResponse{Records: records, Pagination: pagination}
+	}
+
+	var nextCursor string
+	if len(records) > opts.Limit {
+		records = records[:opts.Limit]
+		nextCursor = records[len(records)-1].Cursor
+	}
+	pagination := models.NewCursorPagination(opts.Limit, total, nextCursor)
+	pagination.TotalEstimated = opts.Count == repositories.CountEstimate && total >= 0
+	return &models.RecordListResponse{Records: records, Pagination: pagination}
+}
@@ -0,0 +1,88 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"nocode-app/backend/internal/models"
+	"nocode-app/backend/internal/repositories"
+)
+
+func TestResolveRecordPagination(t *testing.T) {
+	fields := []models.AppField{
+		{FieldCode: "amount", FieldType: "number"},
+		{FieldCode: "total", FieldType: "rollup"},
+	}
+	trigram := models.SearchConfigTrigram
+
+	t.Run("page mode is left as is", func(t *testing.T) {
+		opts := repositories.RecordQueryOptions{Page: 2, Limit: 10, Sort: "total", Count: repositories.CountEstimate}
+		require.NoError(t, resolveRecordPagination(&models.App{}, fields, &opts))
+		assert.Nil(t, opts.After)
+	})
+
+	t.Run("first page without sort orders by id descending", func(t *testing.T) {
+		opts := repositories.RecordQueryOptions{Keyset: true, Order: "asc"}
+		require.NoError(t, resolveRecordPagination(&models.App{}, fields, &opts))
+		assert.Equal(t, "desc", opts.Order)
+	})
+
+	t.Run("system column", func(t *testing.T) {
+		opts := repositories.RecordQueryOptions{Keyset: true, Sort: "created_at", Order: ""}
+		require.NoError(t, resolveRecordPagination(&models.App{}, fields, &opts))
+		assert.Equal(t, "asc", opts.Order)
+	})
+
+	t.Run("search without sort on a search column", func(t *testing.T) {
+		opts := repositories.RecordQueryOptions{Keyset: true, Search: "foo"}
+		assert.NoError(t, resolveRecordPagination(&models.App{}, fields, &opts))
+
+		opts = repositories.RecordQueryOptions{Keyset: true, Search: "foo"}
+		err := resolveRecordPagination(&models.App{SearchConfig: &trigram}, fields, &opts)
+		assert.ErrorIs(t, err, ErrInvalidPagination)
+	})
+
+	errorCases := []struct {
+		name string
+		app  *models.App
+		opts repositories.RecordQueryOptions
+	}{
+		{"unknown count mode", &models.App{}, repositories.RecordQueryOptions{Count: "fast"}},
+		{"rollup sort", &models.App{}, repositories.RecordQueryOptions{Keyset: true, Sort: "total"}},
+		{"system column on external app", &models.App{IsExternal: true}, repositories.RecordQueryOptions{Keyset: true, Sort: "created_at"}},
+		{"malformed cursor", &models.App{}, repositories.RecordQueryOptions{Keyset: true, Cursor: "***"}},
+		{"cursor with another order", &models.App{}, repositories.RecordQueryOptions{
+			Keyset: true, Sort: "amount", Order: "desc",
+			Cursor: (&models.RecordCursor{Sort: "amount", Order: "asc", ID: 1}).Encode(),
+		}},
+	}
+	for _, tc := range errorCases {
+		t.Run(tc.name, func(t *testing.T) {
+			opts := tc.opts
+			assert.ErrorIs(t, resolveRecordPagination(tc.app, fields, &opts), ErrInvalidPagination)
+		})
+	}
+}
+
+func TestNewRecordListResponse(t *testing.T) {
+	records := []models.RecordResponse{{ID: 1, Cursor: "c1"}, {ID: 2, Cursor: "c2"}}
+
+	t.Run("page mode", func(t *testing.T) {
+		opts := &repositories.RecordQueryOptions{Page: 2, Limit: 2, Count: repositories.CountEstimate}
+		resp := newRecordListResponse(records, 9, opts)
+		assert.Equal(t, &models.Pagination{Page: 2, Limit: 2, Total: 9, TotalPages: 5, TotalEstimated: true}, resp.Pagination)
+
+		resp = newRecordListResponse(records, -1, &repositories.RecordQueryOptions{Page: 1, Limit: 2, Count: repositories.CountNone})
+		assert.Equal(t, int64(-1), resp.Pagination.Total)
+		assert.Equal(t, -1, resp.Pagination.TotalPages)
+	})
+
+	t.Run("last page has no next cursor", func(t *testing.T) {
+		resp := newRecordListResponse(records, 2, &repositories.RecordQueryOptions{Limit: 2, Keyset: true})
+		assert.Len(t, resp.Records, 2)
+		assert.Empty(t, resp.Pagination.NextCursor)
+		assert.Equal(t, int64(2), resp.Pagination.Total)
+	})
+}
@@ -0,0 +1,272 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"nocode-app/backend/internal/middleware"
+	"nocode-app/backend/internal/models"
+	"nocode-app/backend/internal/repositories"
+)
+
+// アプリ権限関連エラー
+var (
+	ErrPermissionDenied       = errors.New("この操作を行う権限がありません")
+	ErrPermissionNotFound     = errors.New("権限設定が見つかりません")
+	ErrPermissionExists       = errors.New("このユーザーまたはグループには既に権限が設定されています")
+	ErrPermissionSubjectEmpty = errors.New("ユーザーまたはグループを指定してください")
+	ErrGroupNotFound          = errors.New("グループが見つかりません")
+)
+
+// PermissionService アプリ単位のアクセス制御を処理する構造体
+type PermissionService struct {
+	permRepo  repositories.AppPermissionRepositoryInterface
+	groupRepo repositories.GroupRepositoryInterface
+	appRepo   repositories.AppRepositoryInterface
+	userRepo  repositories.UserRepositoryInterface
+}
+
+// NewPermissionService 新しいPermissionServiceを作成する
+func NewPermissionService(
+	permRepo repositories.AppPermissionRepositoryInterface,
+	groupRepo repositories.GroupRepositoryInterface,
+	appRepo repositories.AppRepositoryInterface,
+	userRepo repositories.UserRepositoryInterface,
+) *PermissionService {
+	return &PermissionService{
+		permRepo:  permRepo,
+		groupRepo: groupRepo,
+		appRepo:   appRepo,
+		userRepo:  userRepo,
+	}
+}
+
+// systemCallKey システム内部の呼び出しであることを示すコンテキストキー
+type systemCallKey struct{}
+
+// WithSystemCall スケジューラーなど、ユーザーのリクエストによらない処理のコンテキストを作成する
+// コンテキストにユーザーがなく、このマークもない呼び出しはどのアプリにもアクセスできない
+func WithSystemCall(ctx context.Context) context.Context {
+	return context.WithValue(ctx, systemCallKey{}, true)
+}
+
+// isSystemCall コンテキストがシステム内部の呼び出しかどうかを判定する
+func isSystemCall(ctx context.Context) bool {
+	marked, _ := ctx.Value(systemCallKey{}).(bool)
+	return marked
+}
+
+// AuthorizeApp アプリを取得し、呼び出し元が required 以上の権限を持つか確認する
+func (s *PermissionService) AuthorizeApp(ctx context.Context, appID uint64, required models.AppRole) (*models.App, *models.AppAccess, error) {
+	app, err := s.appRepo.GetByID(ctx, appID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if app == nil {
+		return nil, nil, ErrAppNotFound
+	}
+	access, err := s.CheckAppAccess(ctx, app, required)
+	if err != nil {
+		return nil, nil, err
+	}
+	return app, access, nil
+}
+
+// CheckAppAccess 呼び出し元がアプリに対して required 以上の権限を持つか確認し、実効権限を返す
+//
+// 権限の決定規則:
+//   - コンテキストにユーザーがない場合は、WithSystemCall で作成したシステム内部の呼び出しに限り全権限を持つ
+//   - 管理者とアプリ作成者はオーナー権限を持つ
+//   - アプリに権限設定が1件もない場合は全ユーザーが閲覧権限を持つ
+//   - それ以外はユーザー本人と所属グループに付与された権限のうち最も高いものを採用する
+//
+// 閲覧権限すらない場合はアプリの存在を隠すため ErrAppNotFound を返す。
+func (s *PermissionService) CheckAppAccess(ctx context.Context, app *models.App, required models.AppRole) (*models.AppAccess, error) {
+	access, err := s.resolveAccess(ctx, app)
+	if err != nil {
+		return nil, err
+	}
+	if access == nil {
+		return nil, ErrAppNotFound
+	}
+	if !access.Role.Includes(required) {
+		return nil, ErrPermissionDenied
+	}
+	return access, nil
+}
+
+// resolveAccess 呼び出し元の実効権限を決定する（アクセス不可の場合はnil）
+func (s *PermissionService) resolveAccess(ctx context.Context, app *models.App) (*models.AppAccess, error) {
+	claims, ok := middleware.GetUserFromContext(ctx)
+	if !ok {
+		if isSystemCall(ctx) {
+			return &models.AppAccess{Role: models.AppRoleOwner}, nil
+		}
+		return nil, nil
+	}
+	if claims.Role == "admin" || app.CreatedBy == claims.UserID {
+		return &models.AppAccess{UserID: claims.UserID, Role: models.AppRoleOwner}, nil
+	}
+
+	hasAny, err := s.permRepo.HasAny(ctx, app.ID)
+	if err != nil {
+		return nil, err
+	}
+	if !hasAny {
+		return &models.AppAccess{UserID: claims.UserID, Role: models.AppRoleViewer}, nil
+	}
+
+	groupIDs, err := s.groupRepo.GetGroupIDsByUserID(ctx, claims.UserID)
+	if err != nil {
+		return nil, err
+	}
+	perms, err := s.permRepo.GetForSubjects(ctx, app.ID, claims.UserID, groupIDs)
+	if err != nil {
+		return nil, err
+	}
+	if len(perms) == 0 {
+		return nil, nil
+	}
+
+	// 最も高い権限を採用し、制限なしの権限が1つでもあればレコード制限を解除する
+	access := &models.AppAccess{UserID: claims.UserID, OwnRecordsOnly: true}
+	for i := range perms {
+		if !access.Role.Includes(perms[i].Role) {
+			access.Role = perms[i].Role
+		}
+		if !perms[i].OwnRecordsOnly {
+			access.OwnRecordsOnly = false
+		}
+	}
+	if access.Role == models.AppRoleOwner {
+		access.OwnRecordsOnly = false
+	}
+	return access, nil
+}
+
+// GetPermissions アプリに設定された権限の一覧を取得する（オーナー専用）
+func (s *PermissionService) GetPermissions(ctx context.Context, appID uint64) (*models.AppPermissionListResponse, error) {
+	if _, _, err := s.AuthorizeApp(ctx, appID, models.AppRoleOwner); err != nil {
+		return nil, err
+	}
+
+	perms, err := s.permRepo.GetByAppID(ctx, appID)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]models.AppPermissionResponse, len(perms))
+	for i := range perms {
+		responses[i] = *perms[i].ToResponse()
+	}
+	return &models.AppPermissionListResponse{Permissions: responses}, nil
+}
+
+// GrantPermission ユーザーまたはグループにアプリ権限を付与する（オーナー専用）
+func (s *PermissionService) GrantPermission(ctx context.Context, appID uint64, req *models.CreateAppPermissionRequest) (*models.AppPermissionResponse, error) {
+	if _, _, err := s.AuthorizeApp(ctx, appID, models.AppRoleOwner); err != nil {
+		return nil, err
+	}
+
+	switch {
+	case req.UserID != nil:
+		user, err := s.userRepo.GetByID(ctx, *req.UserID)
+		if err != nil {
+			return nil, err
+		}
+		if user == nil {
+			return nil, ErrUserNotFound
+		}
+	case req.GroupID != nil:
+		group, err := s.groupRepo.GetByID(ctx, *req.GroupID)
+		if err != nil {
+			return nil, err
+		}
+		if group == nil {
+			return nil, ErrGroupNotFound
+		}
+	default:
+		return nil, ErrPermissionSubjectEmpty
+	}
+
+	exists, err := s.permRepo.SubjectExists(ctx, appID, req.UserID, req.GroupID)
+	if err != nil {
+		return nil, err
+	}
+	if exists {
+		return nil, ErrPermissionExists
+	}
+
+	now := time.Now()
+	perm := &models.AppPermission{
+		AppID:          appID,
+		UserID:         req.UserID,
+		GroupID:        req.GroupID,
+		Role:           req.Role,
+		OwnRecordsOnly: req.OwnRecordsOnly && req.Role != models.AppRoleOwner,
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	}
+	if err := s.permRepo.Create(ctx, perm); err != nil {
+		return nil, err
+	}
+
+	created, err := s.permRepo.GetByID(ctx, perm.ID)
+	if err != nil {
+		return nil, err
+	}
+	if created == nil {
+		return nil, ErrPermissionNotFound
+	}
+	return created.ToResponse(), nil
+}
+
+// UpdatePermission アプリ権限を更新する（オーナー専用）
+func (s *PermissionService) UpdatePermission(ctx context.Context, appID, permID uint64, req *models.UpdateAppPermissionRequest) (*models.AppPermissionResponse, error) {
+	if _, _, err := s.AuthorizeApp(ctx, appID, models.AppRoleOwner); err != nil {
+		return nil, err
+	}
+
+	perm, err := s.permRepo.GetByID(ctx, permID)
+	if err != nil {
+		return nil, err
+	}
+	if perm == nil || perm.AppID != appID {
+		return nil, ErrPermissionNotFound
+	}
+
+	if req.Role != "" {
+		perm.Role = req.Role
+	}
+	if req.OwnRecordsOnly != nil {
+		perm.OwnRecordsOnly = *req.OwnRecordsOnly
+	}
+	// オーナー権限にはレコード制限を適用しない
+	if perm.Role == models.AppRoleOwner {
+		perm.OwnRecordsOnly = false
+	}
+	perm.UpdatedAt = time.Now()
+
+	if err := s.permRepo.Update(ctx, perm); err != nil {
+		return nil, err
+	}
+	return perm.ToResponse(), nil
+}
+
+// RevokePermission アプリ権限を削除する（オーナー専用）
+func (s *PermissionService) RevokePermission(ctx context.Context, appID, permID uint64) error {
+	if _, _, err := s.AuthorizeApp(ctx, appID, models.AppRoleOwner); err != nil {
+		return err
+	}
+
+	perm, err := s.permRepo.GetByID(ctx, permID)
+	if err != nil {
+		return err
+	}
+	if perm == nil || perm.AppID != appID {
+		return ErrPermissionNotFound
+	}
+
+	return s.permRepo.Delete(ctx, permID)
+}
@@ -0,0 +1,302 @@
+package services_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"nocode-app/backend/internal/middleware"
+	"nocode-app/backend/internal/models"
+	"nocode-app/backend/internal/repositories"
+	"nocode-app/backend/internal/services"
+	"nocode-app/backend/internal/testhelpers/mocks"
+	"nocode-app/backend/internal/utils"
+)
+
+// newTestPermissionService 権限チェックを伴うサービスのテスト用にPermissionServiceを作成する
+// アプリはテスト対象のサービスと同じ appRepo から取得する。
+// systemContext で作成したコンテキストではリポジトリを呼び出さずに全権限を返す
+func newTestPermissionService(appRepo repositories.AppRepositoryInterface) *services.PermissionService {
+	return services.NewPermissionService(
+		new(mocks.MockAppPermissionRepository),
+		new(mocks.MockGroupRepository),
+		appRepo,
+		new(mocks.MockUserRepository),
+	)
+}
+
+// systemContext ユーザーによらないシステム内部の呼び出しのコンテキストを作成する
+func systemContext() context.Context {
+	return services.WithSystemCall(context.Background())
+}
+
+func userContext(userID uint64, role string) context.Context {
+	return middleware.SetUserInContext(context.Background(), &utils.JWTClaims{UserID: userID, Role: role})
+}
+
+func TestPermissionService_CheckAppAccess(t *testing.T) {
+	app := &models.App{ID: 1, CreatedBy: 10}
+
+	t.Run("system call has owner access", func(t *testing.T) {
+		service := newTestPermissionService(new(mocks.MockAppRepository))
+
+		access, err := service.CheckAppAccess(systemContext(), app, models.AppRoleOwner)
+		require.NoError(t, err)
+		assert.Equal(t, models.AppRoleOwner, access.Role)
+	})
+
+	t.Run("call without user is denied", func(t *testing.T) {
+		service := newTestPermissionService(new(mocks.MockAppRepository))
+
+		_, err := service.CheckAppAccess(context.Background(), app, models.AppRoleViewer)
+		assert.ErrorIs(t, err, services.ErrAppNotFound)
+	})
+
+	t.Run("admin has owner access", func(t *testing.T) {
+		service := newTestPermissionService(new(mocks.MockAppRepository))
+
+		access, err := service.CheckAppAccess(userContext(99, "admin"), app, models.AppRoleOwner)
+		require.NoError(t, err)
+		assert.Equal(t, models.AppRoleOwner, access.Role)
+		assert.False(t, access.OwnRecordsOnly)
+	})
+
+	t.Run("creator has owner access", func(t *testing.T) {
+		service := newTestPermissionService(new(mocks.MockAppRepository))
+
+		access, err := service.CheckAppAccess(userContext(10, "user"), app, models.AppRoleOwner)
+		require.NoError(t, err)
+		assert.Equal(t, models.AppRoleOwner, access.Role)
+	})
+
+	t.Run("app without permissions is viewable by everyone", func(t *testing.T) {
+		mockPermRepo := new(mocks.MockAppPermissionRepository)
+		mockPermRepo.On("HasAny", mock.Anything, uint64(1)).Return(false, nil)
+
+		service := services.NewPermissionService(mockPermRepo, new(mocks.MockGroupRepository), new(mocks.MockAppRepository), new(mocks.MockUserRepository))
+
+		access, err := service.CheckAppAccess(userContext(20, "user"), app, models.AppRoleViewer)
+		require.NoError(t, err)
+		assert.Equal(t, models.AppRoleViewer, access.Role)
+
+		_, err = service.CheckAppAccess(userContext(20, "user"), app, models.AppRoleEditor)
+		assert.ErrorIs(t, err, services.ErrPermissionDenied)
+	})
+
+	t.Run("user without grant cannot see restricted app", func(t *testing.T) {
+		mockPermRepo := new(mocks.MockAppPermissionRepository)
+		mockGroupRepo := new(mocks.MockGroupRepository)
+		mockPermRepo.On("HasAny", mock.Anything, uint64(1)).Return(true, nil)
+		mockGroupRepo.On("GetGroupIDsByUserID", mock.Anything, uint64(20)).Return([]uint64{}, nil)
+		mockPermRepo.On("GetForSubjects", mock.Anything, uint64(1), uint64(20), []uint64{}).Return([]models.AppPermission{}, nil)
+
+		service := services.NewPermissionService(mockPermRepo, mockGroupRepo, new(mocks.MockAppRepository), new(mocks.MockUserRepository))
+
+		_, err := service.CheckAppAccess(userContext(20, "user"), app, models.AppRoleViewer)
+		assert.ErrorIs(t, err, services.ErrAppNotFound)
+	})
+
+	t.Run("highest role wins and unrestricted grant lifts record limit", func(t *testing.T) {
+		mockPermRepo := new(mocks.MockAppPermissionRepository)
+		mockGroupRepo := new(mocks.MockGroupRepository)
+		mockPermRepo.On("HasAny", mock.Anything, uint64(1)).Return(true, nil)
+		mockGroupRepo.On("GetGroupIDsByUserID", mock.Anything, uint64(20)).Return([]uint64{5}, nil)
+		mockPermRepo.On("GetForSubjects", mock.Anything, uint64(1), uint64(20), []uint64{5}).Return([]models.AppPermission{
+			{Role: models.AppRoleEditor, OwnRecordsOnly: true},
+			{Role: models.AppRoleViewer, OwnRecordsOnly: false},
+		}, nil)
+
+		service := services.NewPermissionService(mockPermRepo, mockGroupRepo, new(mocks.MockAppRepository), new(mocks.MockUserRepository))
+
+		access, err := service.CheckAppAccess(userContext(20, "user"), app, models.AppRoleEditor)
+		require.NoError(t, err)
+		assert.Equal(t, models.AppRoleEditor, access.Role)
+		assert.False(t, access.OwnRecordsOnly)
+	})
+
+	t.Run("own records only grant", func(t *testing.T) {
+		mockPermRepo := new(mocks.MockAppPermissionRepository)
+		mockGroupRepo := new(mocks.MockGroupRepository)
+		mockPermRepo.On("HasAny", mock.Anything, uint64(1)).Return(true, nil)
+		mockGroupRepo.On("GetGroupIDsByUserID", mock.Anything, uint64(20)).Return([]uint64{}, nil)
+		mockPermRepo.On("GetForSubjects", mock.Anything, uint64(1), uint64(20), []uint64{}).Return([]models.AppPermission{
+			{Role: models.AppRoleEditor, OwnRecordsOnly: true},
+		}, nil)
+
+		service := services.NewPermissionService(mockPermRepo, mockGroupRepo, new(mocks.MockAppRepository), new(mocks.MockUserRepository))
+
+		access, err := service.CheckAppAccess(userContext(20, "user"), app, models.AppRoleEditor)
+		require.NoError(t, err)
+		assert.True(t, access.OwnRecordsOnly)
+		assert.True(t, access.CanAccessRecord(20))
+		assert.False(t, access.CanAccessRecord(21))
+		require.Len(t, access.RecordFilters(), 1)
+		assert.Equal(t, "created_by", access.RecordFilters()[0].Field)
+	})
+}
+
+func TestPermissionService_AuthorizeApp(t *testing.T) {
+	t.Run("returns app and access", func(t *testing.T) {
+		mockAppRepo := new(mocks.MockAppRepository)
+		mockAppRepo.On("GetByID", mock.Anything, uint64(1)).Return(&models.App{ID: 1, CreatedBy: 10}, nil)
+		service := newTestPermissionService(mockAppRepo)
+
+		app, access, err := service.AuthorizeApp(userContext(10, "user"), 1, models.AppRoleOwner)
+		require.NoError(t, err)
+		assert.Equal(t, uint64(1), app.ID)
+		assert.Equal(t, models.AppRoleOwner, access.Role)
+	})
+
+	t.Run("app not found", func(t *testing.T) {
+		mockAppRepo := new(mocks.MockAppRepository)
+		mockAppRepo.On("GetByID", mock.Anything, uint64(1)).Return(nil, nil)
+		service := newTestPermissionService(mockAppRepo)
+
+		_, _, err := service.AuthorizeApp(systemContext(), 1, models.AppRoleViewer)
+		assert.ErrorIs(t, err, services.ErrAppNotFound)
+	})
+
+	t.Run("insufficient role", func(t *testing.T) {
+		mockAppRepo := new(mocks.MockAppRepository)
+		mockAppRepo.On("GetByID", mock.Anything, uint64(1)).Return(&models.App{ID: 1, CreatedBy: 10}, nil)
+		mockPermRepo := new(mocks.MockAppPermissionRepository)
+		mockPermRepo.On("HasAny", mock.Anything, uint64(1)).Return(false, nil)
+		service := services.NewPermissionService(mockPermRepo, new(mocks.MockGroupRepository), mockAppRepo, new(mocks.MockUserRepository))
+
+		_, _, err := service.AuthorizeApp(userContext(20, "user"), 1, models.AppRoleEditor)
+		assert.ErrorIs(t, err, services.ErrPermissionDenied)
+	})
+}
+
+func TestPermissionService_GrantPermission(t *testing.T) {
+	userID := uint64(20)
+	groupID := uint64(5)
+
+	t.Run("grant to user", func(t *testing.T) {
+		mockPermRepo := new(mocks.MockAppPermissionRepository)
+		mockAppRepo := new(mocks.MockAppRepository)
+		mockUserRepo := new(mocks.MockUserRepository)
+
+		mockAppRepo.On("GetByID", mock.Anything, uint64(1)).Return(&models.App{ID: 1, CreatedBy: 10}, nil)
+		mockUserRepo.On("GetByID", mock.Anything, userID).Return(&models.User{ID: userID, Name: "User"}, nil)
+		mockPermRepo.On("SubjectExists", mock.Anything, uint64(1), &userID, (*uint64)(nil)).Return(false, nil)
+		mockPermRepo.On("Create", mock.Anything, mock.AnythingOfType("*models.AppPermission")).Return(nil).Run(func(args mock.Arguments) {
+			args.Get(1).(*models.AppPermission).ID = 7
+		})
+		mockPermRepo.On("GetByID", mock.Anything, uint64(7)).Return(&models.AppPermission{
+			ID: 7, AppID: 1, UserID: &userID, Role: models.AppRoleEditor, OwnRecordsOnly: true,
+			User: &models.User{ID: userID, Name: "User"},
+		}, nil)
+
+		service := services.NewPermissionService(mockPermRepo, new(mocks.MockGroupRepository), mockAppRepo, mockUserRepo)
+
+		resp, err := service.GrantPermission(userContext(10, "user"), 1, &models.CreateAppPermissionRequest{
+			UserID: &userID, Role: models.AppRoleEditor, OwnRecordsOnly: true,
+		})
+		require.NoError(t, err)
+		assert.Equal(t, uint64(7), resp.ID)
+		assert.Equal(t, "User", resp.UserName)
+		assert.True(t, resp.OwnRecordsOnly)
+
+		mockPermRepo.AssertExpectations(t)
+	})
+
+	t.Run("non owner cannot grant", func(t *testing.T) {
+		mockPermRepo := new(mocks.MockAppPermissionRepository)
+		mockAppRepo := new(mocks.MockAppRepository)
+
+		mockAppRepo.On("GetByID", mock.Anything, uint64(1)).Return(&models.App{ID: 1, CreatedBy: 10}, nil)
+		mockPermRepo.On("HasAny", mock.Anything, uint64(1)).Return(false, nil)
+
+		service := services.NewPermissionService(mockPermRepo, new(mocks.MockGroupRepository), mockAppRepo, new(mocks.MockUserRepository))
+
+		_, err := service.GrantPermission(userContext(20, "user"), 1, &models.CreateAppPermissionRequest{
+			UserID: &userID, Role: models.AppRoleEditor,
+		})
+		assert.ErrorIs(t, err, services.ErrPermissionDenied)
+		mockPermRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	})
+
+	t.Run("group not found", func(t *testing.T) {
+		mockAppRepo := new(mocks.MockAppRepository)
+		mockGroupRepo := new(mocks.MockGroupRepository)
+
+		mockAppRepo.On("GetByID", mock.Anything, uint64(1)).Return(&models.App{ID: 1}, nil)
+		mockGroupRepo.On("GetByID", mock.Anything, groupID).Return(nil, nil)
+
+		service := services.NewPermissionService(new(mocks.MockAppPermissionRepository), mockGroupRepo, mockAppRepo, new(mocks.MockUserRepository))
+
+		_, err := service.GrantPermission(systemContext(), 1, &models.CreateAppPermissionRequest{
+			GroupID: &groupID, Role: models.AppRoleViewer,
+		})
+		assert.ErrorIs(t, err, services.ErrGroupNotFound)
+	})
+
+	t.Run("duplicate subject", func(t *testing.T) {
+		mockPermRepo := new(mocks.MockAppPermissionRepository)
+		mockAppRepo := new(mocks.MockAppRepository)
+		mockGroupRepo := new(mocks.MockGroupRepository)
+
+		mockAppRepo.On("GetByID", mock.Anything, uint64(1)).Return(&models.App{ID: 1}, nil)
+		mockGroupRepo.On("GetByID", mock.Anything, groupID).Return(&models.Group{ID: groupID}, nil)
+		mockPermRepo.On("SubjectExists", mock.Anything, uint64(1), (*uint64)(nil), &groupID).Return(true, nil)
+
+		service := services.NewPermissionService(mockPermRepo, mockGroupRepo, mockAppRepo, new(mocks.MockUserRepository))
+
+		_, err := service.GrantPermission(systemContext(), 1, &models.CreateAppPermissionRequest{
+			GroupID: &groupID, Role: models.AppRoleViewer,
+		})
+		assert.ErrorIs(t, err, services.ErrPermissionExists)
+	})
+}
+
+func TestPermissionService_UpdatePermission(t *testing.T) {
+	t.Run("owner role clears record limit", func(t *testing.T) {
+		mockPermRepo := new(mocks.MockAppPermissionRepository)
+		mockAppRepo := new(mocks.MockAppRepository)
+
+		mockAppRepo.On("GetByID", mock.Anything, uint64(1)).Return(&models.App{ID: 1}, nil)
+		mockPermRepo.On("GetByID", mock.Anything, uint64(7)).Return(&models.AppPermission{
+			ID: 7, AppID: 1, Role: models.AppRoleEditor, OwnRecordsOnly: true,
+		}, nil)
+		mockPermRepo.On("Update", mock.Anything, mock.AnythingOfType("*models.AppPermission")).Return(nil)
+
+		service := services.NewPermissionService(mockPermRepo, new(mocks.MockGroupRepository), mockAppRepo, new(mocks.MockUserRepository))
+
+		resp, err := service.UpdatePermission(systemContext(), 1, 7, &models.UpdateAppPermissionRequest{Role: models.AppRoleOwner})
+		require.NoError(t, err)
+		assert.Equal(t, models.AppRoleOwner, resp.Role)
+		assert.False(t, resp.OwnRecordsOnly)
+	})
+
+	t.Run("permission of another app", func(t *testing.T) {
+		mockPermRepo := new(mocks.MockAppPermissionRepository)
+		mockAppRepo := new(mocks.MockAppRepository)
+
+		mockAppRepo.On("GetByID", mock.Anything, uint64(1)).Return(&models.App{ID: 1}, nil)
+		mockPermRepo.On("GetByID", mock.Anything, uint64(7)).Return(&models.AppPermission{ID: 7, AppID: 2}, nil)
+
+		service := services.NewPermissionService(mockPermRepo, new(mocks.MockGroupRepository), mockAppRepo, new(mocks.MockUserRepository))
+
+		_, err := service.UpdatePermission(systemContext(), 1, 7, &models.UpdateAppPermissionRequest{Role: models.AppRoleViewer})
+		assert.ErrorIs(t, err, services.ErrPermissionNotFound)
+	})
+}
+
+func TestPermissionService_RevokePermission(t *testing.T) {
+	mockPermRepo := new(mocks.MockAppPermissionRepository)
+	mockAppRepo := new(mocks.MockAppRepository)
+
+	mockAppRepo.On("GetByID", mock.Anything, uint64(1)).Return(&models.App{ID: 1}, nil)
+	mockPermRepo.On("GetByID", mock.Anything, uint64(7)).Return(&models.AppPermission{ID: 7, AppID: 1}, nil)
+	mockPermRepo.On("Delete", mock.Anything, uint64(7)).Return(nil)
+
+	service := services.NewPermissionService(mockPermRepo, new(mocks.MockGroupRepository), mockAppRepo, new(mocks.MockUserRepository))
+
+	err := service.RevokePermission(systemContext(), 1, 7)
+	require.NoError(t, err)
+	mockPermRepo.AssertExpectations(t)
+}
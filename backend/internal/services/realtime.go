@@ -0,0 +1,142 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"nocode-app/backend/internal/middleware"
+	"nocode-app/backend/internal/models"
+	"nocode-app/backend/internal/realtime"
+	"nocode-app/backend/internal/repositories"
+	"nocode-app/backend/internal/utils"
+)
+
+// realtimeTokenTTL 購読用のトークンの有効期限（接続を開始するときにのみ確認する）
+const realtimeTokenTTL = time.Minute
+
+// EventPublisher Webhookの配信キューへの登録と、アプリを開いているクライアントへのリアルタイム配信をまとめて行う
+// レコード・フィールド・アプリの各サービスにはWebhookServiceの代わりにこれを渡す
+type EventPublisher struct {
+	webhooks WebhookPublisherInterface
+	broker   *realtime.Broker
+}
+
+// NewEventPublisher 新しいEventPublisherを作成する
+func NewEventPublisher(webhooks WebhookPublisherInterface, broker *realtime.Broker) *EventPublisher {
+	return &EventPublisher{
+		webhooks: webhooks,
+		broker:   broker,
+	}
+}
+
+// Publish イベントをWebhookの配信キューに登録し、購読中のクライアントに配信する
+// トランザクション中の場合、配信キューへの登録はそのトランザクションに含め、リアルタイム配信はコミットの後に行う。
+// リアルタイム配信の失敗は操作の失敗にしない
+func (p *EventPublisher) Publish(ctx context.Context, events ...models.WebhookEvent) error {
+	if len(events) == 0 {
+		return nil
+	}
+	if err := p.webhooks.Publish(ctx, events...); err != nil {
+		return err
+	}
+
+	msgs := make([]realtime.Message, 0, len(events))
+	for i := range events {
+		msg, err := realtimeMessage(&events[i])
+		if err != nil {
+			return err
+		}
+		msgs = append(msgs, msg)
+	}
+	repositories.AfterCommit(ctx, func() { p.broker.Publish(ctx, msgs...) })
+	return nil
+}
+
+// PublishTo 指定したWebhookにのみ配信する（自動化ルールのアクション用のため、リアルタイム配信はしない）
+func (p *EventPublisher) PublishTo(ctx context.Context, webhookID uint64, event models.WebhookEvent) error {
+	return p.webhooks.PublishTo(ctx, webhookID, event)
+}
+
+// realtimeMessage Webhookと同じ形式のイベントをリアルタイム配信のメッセージに変換する
+// レコードのイベントには、通知する相手を絞り込むためにレコードの作成者を付ける
+func realtimeMessage(event *models.WebhookEvent) (realtime.Message, error) {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return realtime.Message{}, err
+	}
+	msg := realtime.Message{
+		AppID:   event.AppID,
+		Event:   string(event.Event),
+		Payload: payload,
+	}
+	if data, ok := event.Data.(models.WebhookRecordData); ok {
+		msg.RecordID = data.RecordID
+		msg.CreatedBy = data.CreatedBy
+	}
+	return msg, nil
+}
+
+// RealtimeService アプリの変更の購読を処理する構造体
+type RealtimeService struct {
+	appRepo     repositories.AppRepositoryInterface
+	permissions PermissionServiceInterface
+	broker      *realtime.Broker
+	jwtManager  utils.JWTManagerInterface
+}
+
+// NewRealtimeService 新しいRealtimeServiceを作成する
+func NewRealtimeService(
+	appRepo repositories.AppRepositoryInterface,
+	permissions PermissionServiceInterface,
+	broker *realtime.Broker,
+	jwtManager utils.JWTManagerInterface,
+) *RealtimeService {
+	return &RealtimeService{
+		appRepo:     appRepo,
+		permissions: permissions,
+		broker:      broker,
+		jwtManager:  jwtManager,
+	}
+}
+
+// Authorize 呼び出し元がアプリを閲覧できるか確認し、実効権限を返す
+// 購読中も定期的に呼び出し、権限が変わっていないか確認する
+func (s *RealtimeService) Authorize(ctx context.Context, appID uint64) (*models.AppAccess, error) {
+	app, err := s.appRepo.GetByID(ctx, appID)
+	if err != nil {
+		return nil, err
+	}
+	if app == nil {
+		return nil, ErrAppNotFound
+	}
+	return s.permissions.CheckAppAccess(ctx, app, models.AppRoleViewer)
+}
+
+// Subscribe 閲覧権限を確認し、アプリの変更の購読を開始する
+// 自分のレコードのみ閲覧できる場合は、返した権限で他のユーザーのレコードのイベントを除く
+func (s *RealtimeService) Subscribe(ctx context.Context, appID uint64) (*models.AppAccess, *realtime.Subscription, error) {
+	access, err := s.Authorize(ctx, appID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return access, s.broker.Subscribe(appID), nil
+}
+
+// IssueToken 閲覧権限を確認し、EventSourceで購読するための有効期限の短いトークンを発行する
+// トークンはURLのクエリ文字列で渡すため、購読にのみ使えるよう用途を限定する
+func (s *RealtimeService) IssueToken(ctx context.Context, appID uint64) (*models.RealtimeTokenResponse, error) {
+	claims, ok := middleware.GetUserFromContext(ctx)
+	if !ok {
+		return nil, ErrPermissionDenied
+	}
+	if _, err := s.Authorize(ctx, appID); err != nil {
+		return nil, err
+	}
+	expiresAt := time.Now().Add(realtimeTokenTTL)
+	token, err := s.jwtManager.GenerateScopedToken(claims, utils.RealtimeTokenAudience, realtimeTokenTTL)
+	if err != nil {
+		return nil, err
+	}
+	return &models.RealtimeTokenResponse{Token: token, ExpiresAt: expiresAt}, nil
+}
@@ -0,0 +1,163 @@
+package services_test
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"nocode-app/backend/internal/models"
+	"nocode-app/backend/internal/realtime"
+	"nocode-app/backend/internal/services"
+	"nocode-app/backend/internal/testhelpers/mocks"
+	"nocode-app/backend/internal/utils"
+)
+
+func TestEventPublisher_Publish(t *testing.T) {
+	ctx := systemContext()
+	recordEvent := models.WebhookEvent{
+		Event:      models.WebhookEventRecordUpdated,
+		AppID:      1,
+		OccurredAt: time.Date(2026, 10, 16, 0, 0, 0, 0, time.UTC),
+		Data:       models.WebhookRecordData{RecordID: 10, Data: models.RecordData{"name": "山田"}, CreatedBy: 5},
+	}
+	fieldEvent := models.WebhookEvent{
+		Event: models.WebhookEventFieldUpdated,
+		AppID: 1,
+		Data:  &models.FieldResponse{ID: 3, AppID: 1, FieldCode: "name"},
+	}
+
+	t.Run("queues webhooks and delivers to subscribers", func(t *testing.T) {
+		webhooks := new(mocks.MockWebhookPublisher)
+		webhooks.On("Publish", ctx, []models.WebhookEvent{recordEvent, fieldEvent}).Return(nil)
+		broker := realtime.NewBroker(nil)
+		sub := broker.Subscribe(1)
+		defer sub.Close()
+
+		publisher := services.NewEventPublisher(webhooks, broker)
+		require.NoError(t, publisher.Publish(ctx, recordEvent, fieldEvent))
+
+		// Webhookと同じ形式で送り、レコードのイベントには作成者を付ける
+		msg := <-sub.Messages()
+		assert.Equal(t, "record.updated", msg.Event)
+		assert.Equal(t, uint64(10), msg.RecordID)
+		assert.Equal(t, uint64(5), msg.CreatedBy)
+		var payload models.WebhookEvent
+		require.NoError(t, json.Unmarshal(msg.Payload, &payload))
+		assert.Equal(t, models.WebhookEventRecordUpdated, payload.Event)
+
+		msg = <-sub.Messages()
+		assert.Equal(t, "field.updated", msg.Event)
+		assert.Zero(t, msg.RecordID)
+		webhooks.AssertExpectations(t)
+	})
+
+	t.Run("webhook failure is returned before delivery", func(t *testing.T) {
+		webhooks := new(mocks.MockWebhookPublisher)
+		webhooks.On("Publish", ctx, mock.Anything).Return(errors.New("db error"))
+		broker := realtime.NewBroker(nil)
+		sub := broker.Subscribe(1)
+		defer sub.Close()
+
+		publisher := services.NewEventPublisher(webhooks, broker)
+		assert.Error(t, publisher.Publish(ctx, recordEvent))
+		assert.Empty(t, sub.Messages())
+	})
+
+	t.Run("automation webhook is not delivered to subscribers", func(t *testing.T) {
+		webhooks := new(mocks.MockWebhookPublisher)
+		webhooks.On("PublishTo", ctx, uint64(7), mock.Anything).Return(nil)
+		broker := realtime.NewBroker(nil)
+		sub := broker.Subscribe(1)
+		defer sub.Close()
+
+		publisher := services.NewEventPublisher(webhooks, broker)
+		require.NoError(t, publisher.PublishTo(ctx, 7, models.WebhookEvent{Event: models.WebhookEventAutomationTriggered, AppID: 1}))
+		assert.Empty(t, sub.Messages())
+	})
+}
+
+func TestRealtimeService_Subscribe(t *testing.T) {
+	app := &models.App{ID: 1, CreatedBy: 1}
+
+	t.Run("viewer restricted to own records", func(t *testing.T) {
+		appRepo := new(mocks.MockAppRepository)
+		permRepo := new(mocks.MockAppPermissionRepository)
+		groupRepo := new(mocks.MockGroupRepository)
+		appRepo.On("GetByID", mock.Anything, uint64(1)).Return(app, nil)
+		permRepo.On("HasAny", mock.Anything, uint64(1)).Return(true, nil)
+		groupRepo.On("GetGroupIDsByUserID", mock.Anything, uint64(5)).Return([]uint64{}, nil)
+		permRepo.On("GetForSubjects", mock.Anything, uint64(1), uint64(5), []uint64{}).Return([]models.AppPermission{
+			{AppID: 1, Role: models.AppRoleViewer, OwnRecordsOnly: true},
+		}, nil)
+		permissions := services.NewPermissionService(permRepo, groupRepo, appRepo, new(mocks.MockUserRepository))
+		broker := realtime.NewBroker(nil)
+		service := services.NewRealtimeService(appRepo, permissions, broker, new(mocks.MockJWTManager))
+
+		access, sub, err := service.Subscribe(userContext(5, "user"), 1)
+		require.NoError(t, err)
+		defer sub.Close()
+		assert.True(t, access.OwnRecordsOnly)
+		assert.Equal(t, 1, broker.Subscribers(1))
+	})
+
+	t.Run("no access hides the app", func(t *testing.T) {
+		appRepo := new(mocks.MockAppRepository)
+		permRepo := new(mocks.MockAppPermissionRepository)
+		groupRepo := new(mocks.MockGroupRepository)
+		appRepo.On("GetByID", mock.Anything, uint64(1)).Return(app, nil)
+		permRepo.On("HasAny", mock.Anything, uint64(1)).Return(true, nil)
+		groupRepo.On("GetGroupIDsByUserID", mock.Anything, uint64(5)).Return([]uint64{}, nil)
+		permRepo.On("GetForSubjects", mock.Anything, uint64(1), uint64(5), []uint64{}).Return([]models.AppPermission{}, nil)
+		permissions := services.NewPermissionService(permRepo, groupRepo, appRepo, new(mocks.MockUserRepository))
+		broker := realtime.NewBroker(nil)
+		service := services.NewRealtimeService(appRepo, permissions, broker, new(mocks.MockJWTManager))
+
+		_, _, err := service.Subscribe(userContext(5, "user"), 1)
+		assert.ErrorIs(t, err, services.ErrAppNotFound)
+		assert.Zero(t, broker.Subscribers(1))
+	})
+
+	t.Run("app not found", func(t *testing.T) {
+		appRepo := new(mocks.MockAppRepository)
+		appRepo.On("GetByID", mock.Anything, uint64(9)).Return(nil, nil)
+		service := services.NewRealtimeService(appRepo, newTestPermissionService(appRepo), realtime.NewBroker(nil), new(mocks.MockJWTManager))
+
+		_, _, err := service.Subscribe(userContext(5, "user"), 9)
+		assert.ErrorIs(t, err, services.ErrAppNotFound)
+	})
+}
+
+func TestRealtimeService_IssueToken(t *testing.T) {
+	app := &models.App{ID: 1, TableName: "app_data_1", CreatedBy: 5}
+
+	t.Run("issues scoped token to a viewer", func(t *testing.T) {
+		appRepo := new(mocks.MockAppRepository)
+		jwtManager := new(mocks.MockJWTManager)
+		appRepo.On("GetByID", mock.Anything, uint64(1)).Return(app, nil)
+		jwtManager.On("GenerateScopedToken", mock.MatchedBy(func(claims *utils.JWTClaims) bool {
+			return claims.UserID == 5
+		}), utils.RealtimeTokenAudience, time.Minute).Return("scoped-token", nil)
+		service := services.NewRealtimeService(appRepo, newTestPermissionService(appRepo), realtime.NewBroker(nil), jwtManager)
+
+		resp, err := service.IssueToken(userContext(5, "user"), 1)
+		require.NoError(t, err)
+		assert.Equal(t, "scoped-token", resp.Token)
+		assert.WithinDuration(t, time.Now().Add(time.Minute), resp.ExpiresAt, 5*time.Second)
+	})
+
+	t.Run("app not found", func(t *testing.T) {
+		appRepo := new(mocks.MockAppRepository)
+		jwtManager := new(mocks.MockJWTManager)
+		appRepo.On("GetByID", mock.Anything, uint64(9)).Return(nil, nil)
+		service := services.NewRealtimeService(appRepo, newTestPermissionService(appRepo), realtime.NewBroker(nil), jwtManager)
+
+		_, err := service.IssueToken(userContext(5, "user"), 9)
+		assert.ErrorIs(t, err, services.ErrAppNotFound)
+		jwtManager.AssertNotCalled(t, "GenerateScopedToken", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
@@ -0,0 +1,185 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sort"
+	"strconv"
+
+	"nocode-app/backend/internal/models"
+	"nocode-app/backend/internal/repositories"
+	"nocode-app/backend/internal/utils"
+)
+
+// ErrInvalidExportFormat 無効なエクスポート形式
+var ErrInvalidExportFormat = errors.New("無効なエクスポート形式です（csv / xlsx / ndjson）")
+
+// ExportRecords フィルターとソートを適用した全レコードを指定した形式でwに書き出す
+// 見出しはフィールド名を表示順に並べたもの。レコードはサーバーサイドカーソルで順に読み出すため
+// 件数に関わらず一定のメモリで書き出せる。権限の確認とフィールドの取得が済むまでwには書き込まない
+func (s *RecordService) ExportRecords(ctx context.Context, appID uint64, opts repositories.RecordQueryOptions, format models.ExportFormat, w io.Writer) error {
+	if !format.IsValid() {
+		return ErrInvalidExportFormat
+	}
+
+	// アプリ情報を取得し権限を確認
+	app, access, err := s.permissions.AuthorizeApp(ctx, appID, models.AppRoleViewer)
+	if err != nil {
+		return err
+	}
+
+	// フィールドを取得
+	fields, err := s.fieldRepo.GetByAppID(ctx, appID)
+	if err != nil {
+		return err
+	}
+	sort.SliceStable(fields, func(i, j int) bool {
+		return fields[i].DisplayOrder < fields[j].DisplayOrder
+	})
+
+	// 絞り込み条件をフィールド定義と照合する
+	if err := resolveRecordQueryFilters(app, fields, &opts); err != nil {
+		return err
+	}
+
+	// 自分のレコードのみ閲覧可能な場合は作成者で絞り込む
+	if access.OwnRecordsOnly {
+		// 外部データソースには作成者の概念がないため閲覧できない
+		if app.IsExternal {
+			return ErrPermissionDenied
+		}
+		opts.Filters = append(opts.Filters, access.RecordFilters()...)
+	}
+
+	var stream func(fn repositories.RecordStreamFunc) error
+
+	// 外部データソースの場合は外部クエリを使用
+	if app.IsExternal && app.DataSourceID != nil && app.SourceTableName != nil {
+		// 暗号化が初期化されているか確認
+		if !utils.IsEncryptionInitialized() {
+			return ErrEncryptionNotInitialized
+		}
+
+		ds, err := s.dsRepo.GetByID(ctx, *app.DataSourceID)
+		if err != nil {
+			return err
+		}
+		if ds == nil {
+			return ErrDataSourceNotFound
+		}
+
+		password, err := utils.Decrypt(ds.EncryptedPassword)
+		if err != nil {
+			return err
+		}
+
+		stream = func(fn repositories.RecordStreamFunc) error {
+			return s.externalQuery.StreamRecords(ctx, ds, password, *app.SourceTableName, fields, opts, fn)
+		}
+	} else {
+		stream = func(fn repositories.RecordStreamFunc) error {
+			return s.dynamicQuery.StreamRecords(ctx, app.TableName, fields, opts, fn)
+		}
+	}
+
+	writer, err := newExportWriter(format, w)
+	if err != nil {
+		return err
+	}
+
+	headers := make([]string, len(fields))
+	for i := range fields {
+		headers[i] = fields[i].FieldName
+	}
+	if err := writer.WriteHeader(headers); err != nil {
+		return err
+	}
+
+	values := make([]interface{}, len(fields))
+	writeRecord := func(record *models.RecordResponse) error {
+		for i := range fields {
+			values[i] = exportValue(&fields[i], record.Data[fields[i].FieldCode])
+		}
+		return writer.WriteRow(values)
+	}
+
+	// ルックアップの値は参照先から取得し、集計の値は子アプリの権限で隠すため、一定件数ずつまとめて展開してから書き出す
+	if app.IsExternal || !hasExpandedField(fields) {
+		err = stream(writeRecord)
+	} else {
+		batch := make([]models.RecordResponse, 0, exportExpandBatchSize)
+		flush := func() error {
+			if err := s.expandReferences(ctx, fields, batch); err != nil {
+				return err
+			}
+			for i := range batch {
+				if err := writeRecord(&batch[i]); err != nil {
+					return err
+				}
+			}
+			batch = batch[:0]
+			return nil
+		}
+		err = stream(func(record *models.RecordResponse) error {
+			batch = append(batch, *record)
+			if len(batch) < exportExpandBatchSize {
+				return nil
+			}
+			return flush()
+		})
+		if err == nil {
+			err = flush()
+		}
+	}
+	if err != nil {
+		return err
+	}
+	return writer.Close()
+}
+
+// exportExpandBatchSize エクスポート時に参照をまとめて展開するレコード数
+const exportExpandBatchSize = 500
+
+// hasExpandedField 読み出し後に展開するルックアップ・集計フィールドを含むかどうかを判定する
+func hasExpandedField(fields []models.AppField) bool {
+	for i := range fields {
+		switch models.FieldType(fields[i].FieldType) {
+		case models.FieldTypeLookup, models.FieldTypeRollup:
+			return true
+		}
+	}
+	return false
+}
+
+// newExportWriter エクスポート形式に応じたTableWriterを作成する
+func newExportWriter(format models.ExportFormat, w io.Writer) (utils.TableWriter, error) {
+	switch format {
+	case models.ExportFormatXLSX:
+		return utils.NewXLSXTableWriter(w)
+	case models.ExportFormatNDJSON:
+		return utils.NewNDJSONTableWriter(w), nil
+	default:
+		return utils.NewCSVTableWriter(w), nil
+	}
+}
+
+// exportValue 数値フィールド、結果が数値の計算フィールド、集計フィールドの値を数値として書き出せるよう変換する
+// NUMERIC型の値はデータベースから文字列として読み出されるため。添付ファイルフィールドはファイル名の配列に変換する
+func exportValue(field *models.AppField, v interface{}) interface{} {
+	switch {
+	case models.FieldType(field.FieldType) == models.FieldTypeAttachment:
+		return attachmentFileNames(v)
+	case models.FieldType(field.FieldType) == models.FieldTypeNumber,
+		models.FieldType(field.FieldType) == models.FieldTypeRollup,
+		field.FormulaResultType() == models.FieldTypeNumber:
+	default:
+		return v
+	}
+	if s, ok := v.(string); ok {
+		if n, err := strconv.ParseFloat(s, 64); err == nil {
+			return n
+		}
+	}
+	return v
+}
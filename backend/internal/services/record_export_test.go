@@ -0,0 +1,108 @@
+package services_test
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"nocode-app/backend/internal/models"
+	"nocode-app/backend/internal/repositories"
+	"nocode-app/backend/internal/services"
+	"nocode-app/backend/internal/testhelpers/mocks"
+)
+
+func TestRecordService_ExportRecords(t *testing.T) {
+	ctx := systemContext()
+	app := &models.App{ID: 1, TableName: "app_data_1"}
+	// 表示順と取得順が異なっていても見出しは表示順に並ぶ
+	fields := []models.AppField{
+		{ID: 2, FieldCode: "amount", FieldName: "金額", FieldType: "number", DisplayOrder: 2},
+		{ID: 1, FieldCode: "name", FieldName: "氏名", FieldType: "text", DisplayOrder: 1},
+	}
+
+	t.Run("streams records as ndjson", func(t *testing.T) {
+		mockAppRepo := new(mocks.MockAppRepository)
+		mockFieldRepo := new(mocks.MockFieldRepository)
+		mockDynamicQuery := new(mocks.MockDynamicQueryExecutor)
+
+		opts := repositories.RecordQueryOptions{Sort: "amount", Order: "asc", Filters: []models.FilterItem{{Field: "name", Operator: "like", Value: "山"}}}
+		mockAppRepo.On("GetByID", ctx, uint64(1)).Return(app, nil)
+		mockFieldRepo.On("GetByAppID", ctx, uint64(1)).Return(fields, nil)
+		mockDynamicQuery.On("StreamRecords", ctx, "app_data_1", mock.Anything, opts, mock.Anything).Return(nil).Run(func(args mock.Arguments) {
+			fn := args.Get(4).(repositories.RecordStreamFunc)
+			require.NoError(t, fn(&models.RecordResponse{ID: 1, Data: models.RecordData{"name": "山田", "amount": "100.0000"}}))
+			require.NoError(t, fn(&models.RecordResponse{ID: 2, Data: models.RecordData{"name": "山本", "amount": nil}}))
+		})
+
+		service := services.NewRecordService(mockAppRepo, mockFieldRepo, mockDynamicQuery, new(mocks.MockDataSourceRepository), new(mocks.MockExternalQueryExecutor), newTestPermissionService(mockAppRepo), new(mocks.MockRecordRevisionRepository), newTestTransactor(), newTestWebhookPublisher(), newTestAutomationRunner(), newTestAttachmentManager())
+
+		var buf bytes.Buffer
+		err := service.ExportRecords(ctx, 1, opts, models.ExportFormatNDJSON, &buf)
+		require.NoError(t, err)
+		assert.Equal(t, "{\"氏名\":\"山田\",\"金額\":100}\n{\"氏名\":\"山本\",\"金額\":null}\n", buf.String())
+
+		mockDynamicQuery.AssertExpectations(t)
+	})
+
+	t.Run("own records only adds creator filter", func(t *testing.T) {
+		mockAppRepo := new(mocks.MockAppRepository)
+		mockFieldRepo := new(mocks.MockFieldRepository)
+		mockDynamicQuery := new(mocks.MockDynamicQueryExecutor)
+		mockPermissions := new(mocks.MockPermissionService)
+
+		mockPermissions.On("AuthorizeApp", mock.Anything, uint64(1), models.AppRoleViewer).Return(app, &models.AppAccess{UserID: 5, Role: models.AppRoleViewer, OwnRecordsOnly: true}, nil)
+		mockFieldRepo.On("GetByAppID", mock.Anything, uint64(1)).Return(fields, nil)
+		mockDynamicQuery.On("StreamRecords", mock.Anything, "app_data_1", mock.Anything, mock.MatchedBy(func(opts repositories.RecordQueryOptions) bool {
+			return len(opts.Filters) == 1 && opts.Filters[0].Field == "created_by" && opts.Filters[0].Value == "5"
+		}), mock.Anything).Return(nil)
+
+		service := services.NewRecordService(mockAppRepo, mockFieldRepo, mockDynamicQuery, new(mocks.MockDataSourceRepository), new(mocks.MockExternalQueryExecutor), mockPermissions, new(mocks.MockRecordRevisionRepository), newTestTransactor(), newTestWebhookPublisher(), newTestAutomationRunner(), newTestAttachmentManager())
+
+		var buf bytes.Buffer
+		err := service.ExportRecords(userContext(5, "user"), 1, repositories.RecordQueryOptions{}, models.ExportFormatCSV, &buf)
+		require.NoError(t, err)
+		assert.Equal(t, "\xEF\xBB\xBF氏名,金額\n", buf.String())
+		mockDynamicQuery.AssertExpectations(t)
+	})
+
+	t.Run("invalid format writes nothing", func(t *testing.T) {
+		service := services.NewRecordService(new(mocks.MockAppRepository), new(mocks.MockFieldRepository), new(mocks.MockDynamicQueryExecutor), new(mocks.MockDataSourceRepository), new(mocks.MockExternalQueryExecutor), newTestPermissionService(new(mocks.MockAppRepository)), new(mocks.MockRecordRevisionRepository), newTestTransactor(), newTestWebhookPublisher(), newTestAutomationRunner(), newTestAttachmentManager())
+
+		var buf bytes.Buffer
+		err := service.ExportRecords(ctx, 1, repositories.RecordQueryOptions{}, "pdf", &buf)
+		assert.ErrorIs(t, err, services.ErrInvalidExportFormat)
+		assert.Zero(t, buf.Len())
+	})
+
+	t.Run("app not found writes nothing", func(t *testing.T) {
+		mockAppRepo := new(mocks.MockAppRepository)
+		mockAppRepo.On("GetByID", ctx, uint64(999)).Return(nil, nil)
+
+		service := services.NewRecordService(mockAppRepo, new(mocks.MockFieldRepository), new(mocks.MockDynamicQueryExecutor), new(mocks.MockDataSourceRepository), new(mocks.MockExternalQueryExecutor), newTestPermissionService(mockAppRepo), new(mocks.MockRecordRevisionRepository), newTestTransactor(), newTestWebhookPublisher(), newTestAutomationRunner(), newTestAttachmentManager())
+
+		var buf bytes.Buffer
+		err := service.ExportRecords(ctx, 999, repositories.RecordQueryOptions{}, models.ExportFormatCSV, &buf)
+		assert.ErrorIs(t, err, services.ErrAppNotFound)
+		assert.Zero(t, buf.Len())
+	})
+
+	t.Run("stream error is returned", func(t *testing.T) {
+		mockAppRepo := new(mocks.MockAppRepository)
+		mockFieldRepo := new(mocks.MockFieldRepository)
+		mockDynamicQuery := new(mocks.MockDynamicQueryExecutor)
+
+		mockAppRepo.On("GetByID", ctx, uint64(1)).Return(app, nil)
+		mockFieldRepo.On("GetByAppID", ctx, uint64(1)).Return(fields, nil)
+		mockDynamicQuery.On("StreamRecords", ctx, "app_data_1", mock.Anything, mock.Anything, mock.Anything).Return(errors.New("db error"))
+
+		service := services.NewRecordService(mockAppRepo, mockFieldRepo, mockDynamicQuery, new(mocks.MockDataSourceRepository), new(mocks.MockExternalQueryExecutor), newTestPermissionService(mockAppRepo), new(mocks.MockRecordRevisionRepository), newTestTransactor(), newTestWebhookPublisher(), newTestAutomationRunner(), newTestAttachmentManager())
+
+		var buf bytes.Buffer
+		err := service.ExportRecords(ctx, 1, repositories.RecordQueryOptions{}, models.ExportFormatCSV, &buf)
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,386 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"nocode-app/backend/internal/models"
+)
+
+// インポートの上限値
+const (
+	// MaxImportRows 1回のインポートで受け付ける最大行数
+	MaxImportRows = 10000
+	// DefaultImportChunkSize chunkedモードの既定のチャンクサイズ
+	DefaultImportChunkSize = 500
+	// MaxImportChunkSize chunkedモードの最大チャンクサイズ
+	MaxImportChunkSize = 5000
+)
+
+// インポート関連エラー
+var (
+	ErrImportNoHeader    = errors.New("見出し行がありません")
+	ErrImportMapping     = errors.New("列の対応付けが正しくありません")
+	ErrImportTooManyRows = fmt.Errorf("一度にインポートできるのは%d行までです", MaxImportRows)
+	ErrImportInvalidMode = errors.New("無効なインポート方式です")
+	ErrImportChunkFailed = errors.New("チャンクの登録に失敗しました")
+)
+
+// excelEpoch Excelの日付シリアル値の基準日（1900年うるう年バグを考慮した1899-12-30）
+var excelEpoch = time.Date(1899, 12, 30, 0, 0, 0, 0, time.UTC)
+
+// importDateLayouts スプレッドシートでよく使われる日付の書式
+var importDateLayouts = []string{
+	"2006/01/02",
+	"2006/1/2",
+	"2006-1-2",
+}
+
+// importRow 検証済みのインポート行
+type importRow struct {
+	row  int
+	data models.RecordData
+}
+
+// ImportRecords スプレッドシートの行を列の対応付けに従って変換・検証し、レコードとして登録する
+// DryRunの場合は検証結果のみを返す。chunkedモードでチャンクの登録に失敗した場合は、
+// それまでの結果とともにErrImportChunkFailedを返す
+func (s *RecordService) ImportRecords(ctx context.Context, appID, userID uint64, req *models.ImportRecordsRequest) (*models.ImportResult, error) {
+	// アプリ情報を取得し権限を確認
+	app, _, err := s.permissions.AuthorizeApp(ctx, appID, models.AppRoleEditor)
+	if err != nil {
+		return nil, err
+	}
+
+	// 外部データソースのアプリは読み取り専用
+	if app.IsExternal {
+		return nil, ErrExternalAppReadOnly
+	}
+
+	mode := req.Mode
+	if mode == "" {
+		mode = models.ImportModeAtomic
+	}
+	if !mode.IsValid() {
+		return nil, ErrImportInvalidMode
+	}
+	if len(req.Headers) == 0 {
+		return nil, ErrImportNoHeader
+	}
+	if len(req.Rows) > MaxImportRows {
+		return nil, ErrImportTooManyRows
+	}
+
+	fields, err := s.fieldRepo.GetByAppID(ctx, appID)
+	if err != nil {
+		return nil, err
+	}
+
+	columns, mapping, err := resolveImportColumns(fields, req.Headers, req.Mapping)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &models.ImportResult{
+		DryRun:  req.DryRun,
+		Mode:    mode,
+		Mapping: mapping,
+	}
+
+	// 全行を変換・検証する
+	validator := NewRecordValidator(fields)
+	valid := make([]importRow, 0, len(req.Rows))
+	for i, values := range req.Rows {
+		if isBlankImportRow(values) {
+			continue
+		}
+		result.TotalRows++
+		// 見出し行を1行目とした行番号
+		rowNum := i + 2
+
+		raw := make(models.RecordData, len(mapping))
+		for col, field := range columns {
+			if field == nil || col >= len(values) {
+				continue
+			}
+			if value, ok := convertImportValue(field, values[col], req.SerialDates); ok {
+				raw[field.FieldCode] = value
+			}
+		}
+
+		data, fieldErrs := validator.ValidateCreate(raw)
+		if fieldErrs != nil {
+			result.RowErrors = append(result.RowErrors, models.ImportRowError{Row: rowNum, Errors: fieldErrs})
+			continue
+		}
+		valid = append(valid, importRow{row: rowNum, data: data})
+	}
+
+	// 参照先のレコードの存在をまとめて確認し、存在しない行はエラーとする
+	valid, err = s.checkImportReferences(ctx, fields, valid, result)
+	if err != nil {
+		return nil, err
+	}
+	result.ValidRows = len(valid)
+	result.FailedRows = len(result.RowErrors)
+
+	if req.DryRun || len(valid) == 0 {
+		return result, nil
+	}
+
+	if mode == models.ImportModeAtomic {
+		// 1件でも不正があれば何も登録しない
+		if result.FailedRows > 0 {
+			return result, nil
+		}
+		imported, err := s.insertImportRows(ctx, app, userID, valid)
+		if err != nil {
+			return nil, duplicateValueError(err, fields)
+		}
+		result.ImportedRows = imported
+		return result, nil
+	}
+
+	chunkSize := req.ChunkSize
+	if chunkSize <= 0 {
+		chunkSize = DefaultImportChunkSize
+	}
+	if chunkSize > MaxImportChunkSize {
+		chunkSize = MaxImportChunkSize
+	}
+
+	for start := 0; start < len(valid); start += chunkSize {
+		end := start + chunkSize
+		if end > len(valid) {
+			end = len(valid)
+		}
+		chunk := valid[start:end]
+		chunkResult := models.ImportChunkResult{
+			Index:    len(result.Chunks),
+			StartRow: chunk[0].row,
+			EndRow:   chunk[len(chunk)-1].row,
+		}
+
+		imported, err := s.insertImportRows(ctx, app, userID, chunk)
+		if err != nil {
+			// 以降のチャンクは登録せず、ここまでの進捗を返す
+			chunkResult.Error = ErrImportChunkFailed.Error()
+			result.Chunks = append(result.Chunks, chunkResult)
+			return result, fmt.Errorf("%w: %v", ErrImportChunkFailed, duplicateValueError(err, fields))
+		}
+		chunkResult.Imported = imported
+		result.ImportedRows += imported
+		result.Chunks = append(result.Chunks, chunkResult)
+	}
+
+	return result, nil
+}
+
+// insertImportRows 検証済みの行を1つのトランザクションで登録し、変更履歴を記録する
+func (s *RecordService) insertImportRows(ctx context.Context, app *models.App, userID uint64, rows []importRow) (int, error) {
+	data := make([]models.RecordData, len(rows))
+	for i := range rows {
+		data[i] = rows[i].data
+	}
+
+	ids, err := s.dynamicQuery.InsertRecords(ctx, app.TableName, data, userID)
+	if err != nil {
+		return 0, err
+	}
+
+	// 登録後のレコードを再取得せず、登録した値を作成時のスナップショットとする
+	revisions := make([]models.RecordRevision, len(ids))
+	for i, id := range ids {
+		record := &models.RecordResponse{ID: id, Data: data[i], CreatedBy: userID}
+		revisions[i] = newRevision(app.ID, models.RevisionActionCreate, nil, record, userID)
+	}
+	if err := s.revisionRepo.CreateBatch(ctx, revisions); err != nil {
+		return 0, err
+	}
+	if err := s.webhooks.Publish(ctx, revisionEvents(revisions...)...); err != nil {
+		return 0, err
+	}
+	if err := s.automations.Run(ctx, revisions...); err != nil {
+		return 0, err
+	}
+	return len(ids), nil
+}
+
+// checkImportReferences 参照先が存在しない行を行エラーに移し、残りの行を返す
+func (s *RecordService) checkImportReferences(ctx context.Context, fields []models.AppField, rows []importRow, result *models.ImportResult) ([]importRow, error) {
+	data := make([]models.RecordData, len(rows))
+	for i := range rows {
+		data[i] = rows[i].data
+	}
+	refErrs, err := s.checkReferences(ctx, fields, data)
+	if err != nil || refErrs == nil {
+		return rows, err
+	}
+
+	valid := rows[:0]
+	for i := range rows {
+		if fieldErrs, ok := refErrs[i]; ok {
+			result.RowErrors = append(result.RowErrors, models.ImportRowError{Row: rows[i].row, Errors: fieldErrs})
+			continue
+		}
+		valid = append(valid, rows[i])
+	}
+	// 行番号順に並べ直す
+	sort.SliceStable(result.RowErrors, func(i, j int) bool {
+		return result.RowErrors[i].Row < result.RowErrors[j].Row
+	})
+	return valid, nil
+}
+
+// resolveImportColumns 見出しの列位置ごとに対応するフィールドを求める
+// 対応表が空の場合は見出しとフィールドコード、フィールド名の順に照合する
+func resolveImportColumns(fields []models.AppField, headers []string, mapping map[string]string) ([]*models.AppField, map[string]string, error) {
+	byCode := make(map[string]*models.AppField, len(fields))
+	byName := make(map[string]*models.AppField, len(fields))
+	for i := range fields {
+		byCode[fields[i].FieldCode] = &fields[i]
+		byName[fields[i].FieldName] = &fields[i]
+	}
+
+	headerIndex := make(map[string]int, len(headers))
+	for i, h := range headers {
+		h = strings.TrimSpace(h)
+		if _, dup := headerIndex[h]; !dup && h != "" {
+			headerIndex[h] = i
+		}
+	}
+
+	columns := make([]*models.AppField, len(headers))
+	resolved := make(map[string]string)
+	used := make(map[string]string)
+
+	assign := func(header string, field *models.AppField) error {
+		if prev, ok := used[field.FieldCode]; ok {
+			return fmt.Errorf("%w: 列 %q と %q が同じフィールド %q に対応しています", ErrImportMapping, prev, header, field.FieldCode)
+		}
+		used[field.FieldCode] = header
+		columns[headerIndex[header]] = field
+		resolved[header] = field.FieldCode
+		return nil
+	}
+
+	if len(mapping) > 0 {
+		for header, code := range mapping {
+			header = strings.TrimSpace(header)
+			if code == "" {
+				// 空のフィールドコードは取り込まない列として扱う
+				continue
+			}
+			if _, ok := headerIndex[header]; !ok {
+				return nil, nil, fmt.Errorf("%w: 列 %q がファイルにありません", ErrImportMapping, header)
+			}
+			field, ok := byCode[code]
+			if !ok {
+				return nil, nil, fmt.Errorf("%w: フィールド %q は存在しません", ErrImportMapping, code)
+			}
+			if models.FieldType(field.FieldType) == models.FieldTypeAttachment {
+				return nil, nil, fmt.Errorf("%w: 添付ファイルフィールド %q はインポートできません", ErrImportMapping, code)
+			}
+			if err := assign(header, field); err != nil {
+				return nil, nil, err
+			}
+		}
+	} else {
+		for i, h := range headers {
+			h = strings.TrimSpace(h)
+			if h == "" || headerIndex[h] != i {
+				continue
+			}
+			field, ok := byCode[h]
+			if !ok {
+				field, ok = byName[h]
+			}
+			// 添付ファイルはファイルのアップロードが必要なため取り込まない
+			if !ok || models.FieldType(field.FieldType) == models.FieldTypeAttachment {
+				continue
+			}
+			// 同じフィールドに一致する列が複数ある場合は最初の列を使う
+			if _, dup := used[field.FieldCode]; dup {
+				continue
+			}
+			if err := assign(h, field); err != nil {
+				return nil, nil, err
+			}
+		}
+	}
+
+	if len(resolved) == 0 {
+		return nil, nil, fmt.Errorf("%w: フィールドに対応する列がありません", ErrImportMapping)
+	}
+	return columns, resolved, nil
+}
+
+// convertImportValue セルの文字列をフィールドタイプに応じた入力値に変換する
+// 空のセルは未入力として false を返す。最終的な型変換・検証は RecordValidator が行う
+func convertImportValue(field *models.AppField, raw string, serialDates bool) (interface{}, bool) {
+	value := strings.TrimSpace(raw)
+	if value == "" {
+		return nil, false
+	}
+
+	switch models.FieldType(field.FieldType) {
+	case models.FieldTypeMultiSelect:
+		// カンマ区切りの値を配列として扱う
+		items := make([]interface{}, 0)
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		return items, true
+	case models.FieldTypeDate:
+		if t, ok := parseSerialDate(value, serialDates); ok {
+			return t.Format("2006-01-02"), true
+		}
+		// スプレッドシートでよく使われる書式を YYYY-MM-DD にそろえる
+		for _, layout := range importDateLayouts {
+			if t, err := time.Parse(layout, value); err == nil {
+				return t.Format("2006-01-02"), true
+			}
+		}
+		return value, true
+	case models.FieldTypeDateTime:
+		if t, ok := parseSerialDate(value, serialDates); ok {
+			return t.Format("2006-01-02T15:04:05"), true
+		}
+		return value, true
+	default:
+		return value, true
+	}
+}
+
+// parseSerialDate Excelの日付シリアル値を日時に変換する
+func parseSerialDate(value string, serialDates bool) (time.Time, bool) {
+	if !serialDates {
+		return time.Time{}, false
+	}
+	serial, err := strconv.ParseFloat(value, 64)
+	if err != nil || serial < 0 || math.IsInf(serial, 0) {
+		return time.Time{}, false
+	}
+	days := math.Floor(serial)
+	// 秒未満の誤差を丸める
+	seconds := math.Round((serial - days) * 86400)
+	return excelEpoch.AddDate(0, 0, int(days)).Add(time.Duration(seconds) * time.Second), true
+}
+
+// isBlankImportRow 全てのセルが空の行かどうかを判定する
+func isBlankImportRow(values []string) bool {
+	for _, v := range values {
+		if strings.TrimSpace(v) != "" {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,289 @@
+package services_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"nocode-app/backend/internal/models"
+	"nocode-app/backend/internal/services"
+	"nocode-app/backend/internal/testhelpers/mocks"
+)
+
+func TestRecordService_ImportRecords(t *testing.T) {
+	ctx := systemContext()
+	app := &models.App{ID: 1, TableName: "app_data_1"}
+	fields := []models.AppField{
+		{ID: 1, FieldCode: "name", FieldName: "氏名", FieldType: "text", Required: true},
+		{ID: 2, FieldCode: "amount", FieldName: "金額", FieldType: "number"},
+		{ID: 3, FieldCode: "due", FieldName: "期日", FieldType: "date"},
+		{ID: 4, FieldCode: "tags", FieldName: "タグ", FieldType: "multiselect"},
+	}
+
+	t.Run("auto mapping by field name and code", func(t *testing.T) {
+		mockAppRepo := new(mocks.MockAppRepository)
+		mockFieldRepo := new(mocks.MockFieldRepository)
+		mockDynamicQuery := new(mocks.MockDynamicQueryExecutor)
+		mockRevisionRepo := new(mocks.MockRecordRevisionRepository)
+		mockPublisher := new(mocks.MockWebhookPublisher)
+		mockRunner := new(mocks.MockAutomationRunner)
+
+		mockAppRepo.On("GetByID", ctx, uint64(1)).Return(app, nil)
+		mockFieldRepo.On("GetByAppID", ctx, uint64(1)).Return(fields, nil)
+		expected := []models.RecordData{
+			{"name": "山田", "amount": float64(100), "due": "2024-01-05", "tags": []interface{}{"a", "b"}},
+		}
+		mockDynamicQuery.On("InsertRecords", ctx, "app_data_1", expected, uint64(7)).Return([]uint64{10}, nil)
+		mockRevisionRepo.On("CreateBatch", ctx, mock.MatchedBy(func(revs []models.RecordRevision) bool {
+			return len(revs) == 1 && revs[0].RecordID == 10 && revs[0].Action == models.RevisionActionCreate
+		})).Return(nil)
+		mockPublisher.On("Publish", ctx, mock.Anything).Return(nil).Once()
+		mockRunner.On("Run", ctx, mock.Anything).Return(nil).Once()
+
+		service := services.NewRecordService(mockAppRepo, mockFieldRepo, mockDynamicQuery, new(mocks.MockDataSourceRepository), new(mocks.MockExternalQueryExecutor), newTestPermissionService(mockAppRepo), mockRevisionRepo, new(mocks.MockTransactor), mockPublisher, mockRunner, new(mocks.MockAttachmentManager))
+
+		result, err := service.ImportRecords(ctx, 1, 7, &models.ImportRecordsRequest{
+			Headers: []string{"氏名", "amount", "期日", "タグ", "備考"},
+			Rows:    [][]string{{"山田", "100", "2024/1/5", "a, b", "無視される列"}},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, models.ImportModeAtomic, result.Mode)
+		assert.Equal(t, map[string]string{"氏名": "name", "amount": "amount", "期日": "due", "タグ": "tags"}, result.Mapping)
+		assert.Equal(t, 1, result.TotalRows)
+		assert.Equal(t, 1, result.ImportedRows)
+
+		mockDynamicQuery.AssertExpectations(t)
+		mockRevisionRepo.AssertExpectations(t)
+		mockPublisher.AssertExpectations(t)
+		mockRunner.AssertExpectations(t)
+	})
+
+	t.Run("explicit mapping with skipped column", func(t *testing.T) {
+		mockAppRepo := new(mocks.MockAppRepository)
+		mockFieldRepo := new(mocks.MockFieldRepository)
+		mockDynamicQuery := new(mocks.MockDynamicQueryExecutor)
+		mockRevisionRepo := new(mocks.MockRecordRevisionRepository)
+		mockPublisher := new(mocks.MockWebhookPublisher)
+		mockRunner := new(mocks.MockAutomationRunner)
+
+		mockAppRepo.On("GetByID", ctx, uint64(1)).Return(app, nil)
+		mockFieldRepo.On("GetByAppID", ctx, uint64(1)).Return(fields, nil)
+		mockDynamicQuery.On("InsertRecords", ctx, "app_data_1", []models.RecordData{{"name": "Alice"}}, uint64(7)).Return([]uint64{11}, nil)
+		mockRevisionRepo.On("CreateBatch", ctx, mock.Anything).Return(nil)
+		mockPublisher.On("Publish", ctx, mock.Anything).Return(nil).Once()
+		mockRunner.On("Run", ctx, mock.Anything).Return(nil).Once()
+
+		service := services.NewRecordService(mockAppRepo, mockFieldRepo, mockDynamicQuery, new(mocks.MockDataSourceRepository), new(mocks.MockExternalQueryExecutor), newTestPermissionService(mockAppRepo), mockRevisionRepo, new(mocks.MockTransactor), mockPublisher, mockRunner, new(mocks.MockAttachmentManager))
+
+		result, err := service.ImportRecords(ctx, 1, 7, &models.ImportRecordsRequest{
+			Headers: []string{"Customer", "金額"},
+			Rows:    [][]string{{"Alice", "999"}},
+			Mapping: map[string]string{"Customer": "name", "金額": ""},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, map[string]string{"Customer": "name"}, result.Mapping)
+		assert.Equal(t, 1, result.ImportedRows)
+		mockPublisher.AssertExpectations(t)
+		mockRunner.AssertExpectations(t)
+	})
+
+	t.Run("invalid mapping", func(t *testing.T) {
+		cases := map[string]map[string]string{
+			"unknown header":  {"Missing": "name"},
+			"unknown field":   {"氏名": "missing"},
+			"duplicate field": {"氏名": "name", "金額": "name"},
+		}
+		for name, mapping := range cases {
+			t.Run(name, func(t *testing.T) {
+				mockAppRepo := new(mocks.MockAppRepository)
+				mockFieldRepo := new(mocks.MockFieldRepository)
+
+				mockAppRepo.On("GetByID", ctx, uint64(1)).Return(app, nil)
+				mockFieldRepo.On("GetByAppID", ctx, uint64(1)).Return(fields, nil)
+
+				service := services.NewRecordService(mockAppRepo, mockFieldRepo, new(mocks.MockDynamicQueryExecutor), new(mocks.MockDataSourceRepository), new(mocks.MockExternalQueryExecutor), newTestPermissionService(mockAppRepo), new(mocks.MockRecordRevisionRepository), new(mocks.MockTransactor), new(mocks.MockWebhookPublisher), new(mocks.MockAutomationRunner), new(mocks.MockAttachmentManager))
+
+				_, err := service.ImportRecords(ctx, 1, 7, &models.ImportRecordsRequest{
+					Headers: []string{"氏名", "金額"},
+					Rows:    [][]string{{"a", "1"}},
+					Mapping: mapping,
+				})
+				assert.ErrorIs(t, err, services.ErrImportMapping)
+			})
+		}
+	})
+
+	t.Run("dry run reports row errors without inserting", func(t *testing.T) {
+		mockAppRepo := new(mocks.MockAppRepository)
+		mockFieldRepo := new(mocks.MockFieldRepository)
+		mockDynamicQuery := new(mocks.MockDynamicQueryExecutor)
+
+		mockAppRepo.On("GetByID", ctx, uint64(1)).Return(app, nil)
+		mockFieldRepo.On("GetByAppID", ctx, uint64(1)).Return(fields, nil)
+
+		service := services.NewRecordService(mockAppRepo, mockFieldRepo, mockDynamicQuery, new(mocks.MockDataSourceRepository), new(mocks.MockExternalQueryExecutor), newTestPermissionService(mockAppRepo), new(mocks.MockRecordRevisionRepository), new(mocks.MockTransactor), new(mocks.MockWebhookPublisher), new(mocks.MockAutomationRunner), new(mocks.MockAttachmentManager))
+
+		result, err := service.ImportRecords(ctx, 1, 7, &models.ImportRecordsRequest{
+			Headers: []string{"name", "amount"},
+			Rows:    [][]string{{"Alice", "10"}, {"", ""}, {"", "20"}, {"Bob", "abc"}},
+			DryRun:  true,
+		})
+		require.NoError(t, err)
+		assert.True(t, result.DryRun)
+		// 空行は件数に含めない
+		assert.Equal(t, 3, result.TotalRows)
+		assert.Equal(t, 1, result.ValidRows)
+		assert.Equal(t, 2, result.FailedRows)
+		require.Len(t, result.RowErrors, 2)
+		assert.Equal(t, 4, result.RowErrors[0].Row)
+		assert.Contains(t, result.RowErrors[0].Errors, "name")
+		assert.Equal(t, 5, result.RowErrors[1].Row)
+		assert.Contains(t, result.RowErrors[1].Errors, "amount")
+
+		mockDynamicQuery.AssertNotCalled(t, "InsertRecords", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("atomic mode writes nothing when a row is invalid", func(t *testing.T) {
+		mockAppRepo := new(mocks.MockAppRepository)
+		mockFieldRepo := new(mocks.MockFieldRepository)
+		mockDynamicQuery := new(mocks.MockDynamicQueryExecutor)
+
+		mockAppRepo.On("GetByID", ctx, uint64(1)).Return(app, nil)
+		mockFieldRepo.On("GetByAppID", ctx, uint64(1)).Return(fields, nil)
+
+		service := services.NewRecordService(mockAppRepo, mockFieldRepo, mockDynamicQuery, new(mocks.MockDataSourceRepository), new(mocks.MockExternalQueryExecutor), newTestPermissionService(mockAppRepo), new(mocks.MockRecordRevisionRepository), new(mocks.MockTransactor), new(mocks.MockWebhookPublisher), new(mocks.MockAutomationRunner), new(mocks.MockAttachmentManager))
+
+		result, err := service.ImportRecords(ctx, 1, 7, &models.ImportRecordsRequest{
+			Headers: []string{"name", "amount"},
+			Rows:    [][]string{{"Alice", "10"}, {"Bob", "abc"}},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, 0, result.ImportedRows)
+		assert.Equal(t, 1, result.FailedRows)
+
+		mockDynamicQuery.AssertNotCalled(t, "InsertRecords", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("chunked mode skips invalid rows and reports progress", func(t *testing.T) {
+		mockAppRepo := new(mocks.MockAppRepository)
+		mockFieldRepo := new(mocks.MockFieldRepository)
+		mockDynamicQuery := new(mocks.MockDynamicQueryExecutor)
+		mockRevisionRepo := new(mocks.MockRecordRevisionRepository)
+		mockPublisher := new(mocks.MockWebhookPublisher)
+		mockRunner := new(mocks.MockAutomationRunner)
+
+		mockAppRepo.On("GetByID", ctx, uint64(1)).Return(app, nil)
+		mockFieldRepo.On("GetByAppID", ctx, uint64(1)).Return(fields, nil)
+		mockDynamicQuery.On("InsertRecords", ctx, "app_data_1", []models.RecordData{{"name": "A"}, {"name": "B"}}, uint64(7)).Return([]uint64{1, 2}, nil)
+		mockDynamicQuery.On("InsertRecords", ctx, "app_data_1", []models.RecordData{{"name": "D"}}, uint64(7)).Return([]uint64{3}, nil)
+		mockRevisionRepo.On("CreateBatch", ctx, mock.Anything).Return(nil)
+		mockPublisher.On("Publish", ctx, mock.Anything).Return(nil).Twice()
+		mockRunner.On("Run", ctx, mock.Anything).Return(nil).Twice()
+
+		service := services.NewRecordService(mockAppRepo, mockFieldRepo, mockDynamicQuery, new(mocks.MockDataSourceRepository), new(mocks.MockExternalQueryExecutor), newTestPermissionService(mockAppRepo), mockRevisionRepo, new(mocks.MockTransactor), mockPublisher, mockRunner, new(mocks.MockAttachmentManager))
+
+		result, err := service.ImportRecords(ctx, 1, 7, &models.ImportRecordsRequest{
+			Headers:   []string{"name"},
+			Rows:      [][]string{{"A"}, {"B"}, {""}, {"D"}},
+			Mode:      models.ImportModeChunked,
+			ChunkSize: 2,
+		})
+		require.NoError(t, err)
+		assert.Equal(t, 3, result.ImportedRows)
+		require.Len(t, result.Chunks, 2)
+		assert.Equal(t, models.ImportChunkResult{Index: 0, StartRow: 2, EndRow: 3, Imported: 2}, result.Chunks[0])
+		assert.Equal(t, models.ImportChunkResult{Index: 1, StartRow: 5, EndRow: 5, Imported: 1}, result.Chunks[1])
+
+		mockDynamicQuery.AssertExpectations(t)
+		mockPublisher.AssertExpectations(t)
+		mockRunner.AssertExpectations(t)
+	})
+
+	t.Run("chunked mode stops at failed chunk", func(t *testing.T) {
+		mockAppRepo := new(mocks.MockAppRepository)
+		mockFieldRepo := new(mocks.MockFieldRepository)
+		mockDynamicQuery := new(mocks.MockDynamicQueryExecutor)
+		mockRevisionRepo := new(mocks.MockRecordRevisionRepository)
+		mockPublisher := new(mocks.MockWebhookPublisher)
+		mockRunner := new(mocks.MockAutomationRunner)
+
+		mockAppRepo.On("GetByID", ctx, uint64(1)).Return(app, nil)
+		mockFieldRepo.On("GetByAppID", ctx, uint64(1)).Return(fields, nil)
+		mockDynamicQuery.On("InsertRecords", ctx, "app_data_1", []models.RecordData{{"name": "A"}}, uint64(7)).Return([]uint64{1}, nil)
+		mockDynamicQuery.On("InsertRecords", ctx, "app_data_1", []models.RecordData{{"name": "B"}}, uint64(7)).Return(nil, errors.New("db error"))
+		mockRevisionRepo.On("CreateBatch", ctx, mock.Anything).Return(nil)
+		mockPublisher.On("Publish", ctx, mock.Anything).Return(nil).Once()
+		mockRunner.On("Run", ctx, mock.Anything).Return(nil).Once()
+
+		service := services.NewRecordService(mockAppRepo, mockFieldRepo, mockDynamicQuery, new(mocks.MockDataSourceRepository), new(mocks.MockExternalQueryExecutor), newTestPermissionService(mockAppRepo), mockRevisionRepo, new(mocks.MockTransactor), mockPublisher, mockRunner, new(mocks.MockAttachmentManager))
+
+		result, err := service.ImportRecords(ctx, 1, 7, &models.ImportRecordsRequest{
+			Headers:   []string{"name"},
+			Rows:      [][]string{{"A"}, {"B"}, {"C"}},
+			Mode:      models.ImportModeChunked,
+			ChunkSize: 1,
+		})
+		assert.ErrorIs(t, err, services.ErrImportChunkFailed)
+		require.NotNil(t, result)
+		assert.Equal(t, 1, result.ImportedRows)
+		require.Len(t, result.Chunks, 2)
+		assert.NotEmpty(t, result.Chunks[1].Error)
+		mockDynamicQuery.AssertNumberOfCalls(t, "InsertRecords", 2)
+		mockPublisher.AssertExpectations(t)
+		mockRunner.AssertExpectations(t)
+	})
+
+	t.Run("xlsx serial dates", func(t *testing.T) {
+		mockAppRepo := new(mocks.MockAppRepository)
+		mockFieldRepo := new(mocks.MockFieldRepository)
+		mockDynamicQuery := new(mocks.MockDynamicQueryExecutor)
+		mockRevisionRepo := new(mocks.MockRecordRevisionRepository)
+		mockPublisher := new(mocks.MockWebhookPublisher)
+		mockRunner := new(mocks.MockAutomationRunner)
+
+		mockAppRepo.On("GetByID", ctx, uint64(1)).Return(app, nil)
+		mockFieldRepo.On("GetByAppID", ctx, uint64(1)).Return(fields, nil)
+		mockDynamicQuery.On("InsertRecords", ctx, "app_data_1", []models.RecordData{{"name": "A", "due": "2024-01-15"}}, uint64(7)).Return([]uint64{1}, nil)
+		mockRevisionRepo.On("CreateBatch", ctx, mock.Anything).Return(nil)
+		mockPublisher.On("Publish", ctx, mock.Anything).Return(nil).Once()
+		mockRunner.On("Run", ctx, mock.Anything).Return(nil).Once()
+
+		service := services.NewRecordService(mockAppRepo, mockFieldRepo, mockDynamicQuery, new(mocks.MockDataSourceRepository), new(mocks.MockExternalQueryExecutor), newTestPermissionService(mockAppRepo), mockRevisionRepo, new(mocks.MockTransactor), mockPublisher, mockRunner, new(mocks.MockAttachmentManager))
+
+		_, err := service.ImportRecords(ctx, 1, 7, &models.ImportRecordsRequest{
+			Headers:     []string{"name", "due"},
+			Rows:        [][]string{{"A", "45306"}},
+			SerialDates: true,
+		})
+		require.NoError(t, err)
+		mockDynamicQuery.AssertExpectations(t)
+		mockPublisher.AssertExpectations(t)
+		mockRunner.AssertExpectations(t)
+	})
+
+	t.Run("invalid mode", func(t *testing.T) {
+		mockAppRepo := new(mocks.MockAppRepository)
+		mockAppRepo.On("GetByID", ctx, uint64(1)).Return(app, nil)
+
+		service := services.NewRecordService(mockAppRepo, new(mocks.MockFieldRepository), new(mocks.MockDynamicQueryExecutor), new(mocks.MockDataSourceRepository), new(mocks.MockExternalQueryExecutor), newTestPermissionService(mockAppRepo), new(mocks.MockRecordRevisionRepository), new(mocks.MockTransactor), new(mocks.MockWebhookPublisher), new(mocks.MockAutomationRunner), new(mocks.MockAttachmentManager))
+
+		_, err := service.ImportRecords(ctx, 1, 7, &models.ImportRecordsRequest{
+			Headers: []string{"name"},
+			Mode:    "partial",
+		})
+		assert.ErrorIs(t, err, services.ErrImportInvalidMode)
+	})
+
+	t.Run("external app is read-only", func(t *testing.T) {
+		mockAppRepo := new(mocks.MockAppRepository)
+		mockAppRepo.On("GetByID", ctx, uint64(2)).Return(&models.App{ID: 2, IsExternal: true}, nil)
+
+		service := services.NewRecordService(mockAppRepo, new(mocks.MockFieldRepository), new(mocks.MockDynamicQueryExecutor), new(mocks.MockDataSourceRepository), new(mocks.MockExternalQueryExecutor), newTestPermissionService(mockAppRepo), new(mocks.MockRecordRevisionRepository), new(mocks.MockTransactor), new(mocks.MockWebhookPublisher), new(mocks.MockAutomationRunner), new(mocks.MockAttachmentManager))
+
+		_, err := service.ImportRecords(ctx, 2, 7, &models.ImportRecordsRequest{Headers: []string{"name"}})
+		assert.ErrorIs(t, err, services.ErrExternalAppReadOnly)
+	})
+}
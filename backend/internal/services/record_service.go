@@ -3,6 +3,8 @@ package services
 import (
 	"context"
 	"errors"
+	"log"
+	"time"
 
 	"nocode-app/backend/internal/models"
 	"nocode-app/backend/internal/repositories"
@@ -13,6 +15,7 @@ import (
 var (
 	ErrRecordNotFound      = errors.New("レコードが見つかりません")
 	ErrExternalAppReadOnly = errors.New("外部データソースのアプリは読み取り専用です")
+	ErrRevisionNotFound    = errors.New("変更履歴が見つかりません")
 )
 
 // RecordService レコード操作を処理する構造体
@@ -22,6 +25,12 @@ type RecordService struct {
 	dynamicQuery  repositories.DynamicQueryExecutorInterface
 	dsRepo        repositories.DataSourceRepositoryInterface
 	externalQuery repositories.ExternalQueryExecutorInterface
+	permissions   PermissionServiceInterface
+	revisionRepo  repositories.RecordRevisionRepositoryInterface
+	transactor    repositories.TransactorInterface
+	webhooks      WebhookPublisherInterface
+	automations   AutomationRunnerInterface
+	attachments   AttachmentManagerInterface
 }
 
 // NewRecordService 新しいRecordServiceを作成する
@@ -31,6 +40,12 @@ func NewRecordService(
 	dynamicQuery repositories.DynamicQueryExecutorInterface,
 	dsRepo repositories.DataSourceRepositoryInterface,
 	externalQuery repositories.ExternalQueryExecutorInterface,
+	permissions PermissionServiceInterface,
+	revisionRepo repositories.RecordRevisionRepositoryInterface,
+	transactor repositories.TransactorInterface,
+	webhooks WebhookPublisherInterface,
+	automations AutomationRunnerInterface,
+	attachments AttachmentManagerInterface,
 ) *RecordService {
 	return &RecordService{
 		appRepo:       appRepo,
@@ -38,19 +53,66 @@ func NewRecordService(
 		dynamicQuery:  dynamicQuery,
 		dsRepo:        dsRepo,
 		externalQuery: externalQuery,
+		permissions:   permissions,
+		revisionRepo:  revisionRepo,
+		transactor:    transactor,
+		webhooks:      webhooks,
+		automations:   automations,
+		attachments:   attachments,
 	}
 }
 
+// getAccessibleRecord 操作対象のレコードを取得する（集計フィールドの値は含まない）
+// 存在しない、または自分のレコードのみ操作可能で作成者が異なる場合はErrRecordNotFoundを返す
+func (s *RecordService) getAccessibleRecord(ctx context.Context, app *models.App, fields []models.AppField, access *models.AppAccess, recordID uint64) (*models.RecordResponse, error) {
+	record, err := s.dynamicQuery.GetRecordByID(ctx, app.TableName, storedFields(fields), recordID)
+	if err != nil {
+		return nil, err
+	}
+	if record == nil || !access.CanAccessRecord(record.CreatedBy) {
+		return nil, ErrRecordNotFound
+	}
+	return record, nil
+}
+
+// newRevision 変更前後のレコードから変更履歴を組み立てる
+// 作成時は before、削除時は after に nil を渡す
+func newRevision(appID uint64, action models.RevisionAction, before, after *models.RecordResponse, actorID uint64) models.RecordRevision {
+	revision := models.RecordRevision{
+		AppID:     appID,
+		Action:    action,
+		CreatedAt: time.Now(),
+	}
+
+	var beforeData, afterData models.RecordData
+	if before != nil {
+		revision.RecordID = before.ID
+		beforeData = before.Data
+		revision.Snapshot = before.Data
+		revision.RecordCreatedBy = before.CreatedBy
+	}
+	if after != nil {
+		revision.RecordID = after.ID
+		afterData = after.Data
+		revision.Snapshot = after.Data
+		revision.RecordCreatedBy = after.CreatedBy
+	}
+	revision.Changes = models.DiffRecordData(beforeData, afterData)
+
+	// システム内部の呼び出しでは操作者を記録しない
+	if actorID != 0 {
+		revision.ChangedBy = &actorID
+	}
+	return revision
+}
+
 // GetRecords ページネーションとフィルタリング付きでレコードを取得する
 func (s *RecordService) GetRecords(ctx context.Context, appID uint64, opts repositories.RecordQueryOptions) (*models.RecordListResponse, error) {
-	// アプリ情報を取得
-	app, err := s.appRepo.GetByID(ctx, appID)
+	// アプリ情報を取得し権限を確認
+	app, access, err := s.permissions.AuthorizeApp(ctx, appID, models.AppRoleViewer)
 	if err != nil {
 		return nil, err
 	}
-	if app == nil {
-		return nil, ErrAppNotFound
-	}
 
 	// フィールドを取得
 	fields, err := s.fieldRepo.GetByAppID(ctx, appID)
@@ -58,6 +120,35 @@ func (s *RecordService) GetRecords(ctx context.Context, appID uint64, opts repos
 		return nil, err
 	}
 
+	// 絞り込み条件をフィールド定義と照合する
+	if err := resolveRecordQueryFilters(app, fields, &opts); err != nil {
+		return nil, err
+	}
+
+	// 自分のレコードのみ閲覧可能な場合は作成者で絞り込む
+	if access.OwnRecordsOnly {
+		// 外部データソースには作成者の概念がないため閲覧できない
+		if app.IsExternal {
+			return nil, ErrPermissionDenied
+		}
+		opts.Filters = append(opts.Filters, access.RecordFilters()...)
+	}
+
+	// ページ送りの方式を検証し、カーソル方式では次のページの有無を判定するため1件多く取得する
+	if err := resolveRecordPagination(app, fields, &opts); err != nil {
+		return nil, err
+	}
+	query := opts
+	if opts.Keyset {
+		query.Limit++
+	}
+
+	// 全文検索のキーワードを検索の条件にする
+	terms, err := applyRecordSearch(app, fields, &query)
+	if err != nil {
+		return nil, err
+	}
+
 	var records []models.RecordResponse
 	var total int64
 
@@ -81,34 +172,37 @@ func (s *RecordService) GetRecords(ctx context.Context, appID uint64, opts repos
 			return nil, err
 		}
 
-		records, total, err = s.externalQuery.GetRecords(ctx, ds, password, *app.SourceTableName, fields, opts)
+		records, total, err = s.externalQuery.GetRecords(ctx, ds, password, *app.SourceTableName, fields, query)
 		if err != nil {
 			return nil, err
 		}
 	} else {
 		// 内部アプリの場合は動的クエリを使用
-		records, total, err = s.dynamicQuery.GetRecords(ctx, app.TableName, fields, opts)
+		records, total, err = s.dynamicQuery.GetRecords(ctx, app.TableName, fields, query)
 		if err != nil {
 			return nil, err
 		}
+
+		// 参照先のレコードとルックアップの値を展開
+		if err := s.expandReferences(ctx, fields, records); err != nil {
+			return nil, err
+		}
 	}
 
-	return &models.RecordListResponse{
-		Records:    records,
-		Pagination: models.NewPagination(opts.Page, opts.Limit, total),
-	}, nil
+	if terms != nil {
+		highlightRecords(records, fields, terms)
+	}
+
+	return newRecordListResponse(records, total, &opts), nil
 }
 
 // GetRecord 単一のレコードを取得する
 func (s *RecordService) GetRecord(ctx context.Context, appID, recordID uint64) (*models.RecordResponse, error) {
-	// アプリ情報を取得
-	app, err := s.appRepo.GetByID(ctx, appID)
+	// アプリ情報を取得し権限を確認
+	app, access, err := s.permissions.AuthorizeApp(ctx, appID, models.AppRoleViewer)
 	if err != nil {
 		return nil, err
 	}
-	if app == nil {
-		return nil, ErrAppNotFound
-	}
 
 	// フィールドを取得
 	fields, err := s.fieldRepo.GetByAppID(ctx, appID)
@@ -150,103 +244,270 @@ func (s *RecordService) GetRecord(ctx context.Context, appID, recordID uint64) (
 		}
 	}
 
-	if record == nil {
+	// 閲覧範囲外のレコードは存在しないものとして扱う
+	if record == nil || !access.CanAccessRecord(record.CreatedBy) {
 		return nil, ErrRecordNotFound
 	}
 
+	// 参照先のレコードとルックアップの値を展開
+	if !app.IsExternal {
+		records := []models.RecordResponse{*record}
+		if err := s.expandReferences(ctx, fields, records); err != nil {
+			return nil, err
+		}
+		record = &records[0]
+	}
+
 	return record, nil
 }
 
 // CreateRecord 新しいレコードを作成する
 func (s *RecordService) CreateRecord(ctx context.Context, appID, userID uint64, req *models.CreateRecordRequest) (*models.RecordResponse, error) {
-	// アプリ情報を取得
-	app, err := s.appRepo.GetByID(ctx, appID)
+	// アプリ情報を取得し権限を確認
+	app, _, err := s.permissions.AuthorizeApp(ctx, appID, models.AppRoleEditor)
 	if err != nil {
 		return nil, err
 	}
-	if app == nil {
-		return nil, ErrAppNotFound
-	}
 
 	// 外部データソースのアプリは読み取り専用
 	if app.IsExternal {
 		return nil, ErrExternalAppReadOnly
 	}
 
-	// レコードを挿入
-	recordID, err := s.dynamicQuery.InsertRecord(ctx, app.TableName, req.Data, userID)
+	// フィールドを取得して入力値を検証
+	fields, err := s.fieldRepo.GetByAppID(ctx, appID)
 	if err != nil {
 		return nil, err
 	}
 
-	// フィールドを取得して作成したレコードを返す
-	fields, err := s.fieldRepo.GetByAppID(ctx, appID)
+	data, fieldErrs := NewRecordValidator(fields).ValidateCreate(req.Data)
+	if fieldErrs != nil {
+		return nil, &RecordValidationError{Errors: fieldErrs}
+	}
+	if err := s.checkRecordReferences(ctx, fields, data); err != nil {
+		return nil, err
+	}
+	if err := s.checkRecordAttachments(ctx, appID, 0, userID, fields, data); err != nil {
+		return nil, err
+	}
+
+	// レコードの挿入・変更履歴・Webhookの配信キューへの登録をまとめて行う
+	var record *models.RecordResponse
+	var revision models.RecordRevision
+	err = s.transactor.RunInTx(ctx, func(ctx context.Context) error {
+		recordID, err := s.dynamicQuery.InsertRecord(ctx, app.TableName, data, userID)
+		if err != nil {
+			return duplicateValueError(err, fields)
+		}
+		if err := s.attachments.AttachRecord(ctx, appID, recordID, fields, data); err != nil {
+			return err
+		}
+
+		record, err = s.dynamicQuery.GetRecordByID(ctx, app.TableName, storedFields(fields), recordID)
+		if err != nil {
+			return err
+		}
+
+		revision = newRevision(appID, models.RevisionActionCreate, nil, record, userID)
+		if err := s.revisionRepo.Create(ctx, &revision); err != nil {
+			return err
+		}
+		return s.webhooks.Publish(ctx, revisionEvents(revision)...)
+	})
 	if err != nil {
 		return nil, err
 	}
+	s.runAutomations(ctx, revision)
 
-	return s.dynamicQuery.GetRecordByID(ctx, app.TableName, fields, recordID)
+	return record, nil
 }
 
 // UpdateRecord レコードを更新する
-func (s *RecordService) UpdateRecord(ctx context.Context, appID, recordID uint64, req *models.UpdateRecordRequest) (*models.RecordResponse, error) {
-	// アプリ情報を取得
-	app, err := s.appRepo.GetByID(ctx, appID)
+// version にはクライアントが取得したときのバージョンを渡し、他の更新と競合した場合は VersionConflictError を返す（0の場合は確認しない）
+func (s *RecordService) UpdateRecord(ctx context.Context, appID, recordID uint64, version int64, req *models.UpdateRecordRequest) (*models.RecordResponse, error) {
+	// アプリ情報を取得し権限を確認
+	app, access, err := s.permissions.AuthorizeApp(ctx, appID, models.AppRoleEditor)
 	if err != nil {
 		return nil, err
 	}
-	if app == nil {
-		return nil, ErrAppNotFound
-	}
 
 	// 外部データソースのアプリは読み取り専用
 	if app.IsExternal {
 		return nil, ErrExternalAppReadOnly
 	}
 
-	// レコードを更新
-	if err := s.dynamicQuery.UpdateRecord(ctx, app.TableName, recordID, req.Data); err != nil {
+	// フィールドを取得して入力値を検証（指定されたフィールドのみ）
+	fields, err := s.fieldRepo.GetByAppID(ctx, appID)
+	if err != nil {
 		return nil, err
 	}
 
-	// フィールドを取得して更新したレコードを返す
-	fields, err := s.fieldRepo.GetByAppID(ctx, appID)
+	// 変更前の状態を取得（作成者の確認を兼ねる）
+	before, err := s.getAccessibleRecord(ctx, app, fields, access, recordID)
+	if err != nil {
+		return nil, err
+	}
+	if version != 0 && version != before.Version {
+		return nil, s.recordVersionConflict(ctx, appID, recordID)
+	}
+
+	data, fieldErrs := NewRecordValidator(fields).ValidateUpdate(req.Data)
+	if fieldErrs != nil {
+		return nil, &RecordValidationError{Errors: fieldErrs}
+	}
+	if err := s.checkRecordReferences(ctx, fields, data); err != nil {
+		return nil, err
+	}
+	if err := s.checkRecordAttachments(ctx, appID, recordID, access.UserID, fields, data); err != nil {
+		return nil, err
+	}
+
+	// レコードの更新・変更履歴・Webhookの配信キューへの登録をまとめて行う
+	var after *models.RecordResponse
+	var revision models.RecordRevision
+	conflict := false
+	err = s.transactor.RunInTx(ctx, func(ctx context.Context) error {
+		// バージョンを指定した場合は、取得した後に他の更新がなかったときのみ更新する
+		if version != 0 {
+			updated, err := s.dynamicQuery.UpdateRecordIfVersion(ctx, app.TableName, recordID, version, data)
+			if err != nil {
+				return duplicateValueError(err, fields)
+			}
+			if !updated {
+				conflict = true
+				return nil
+			}
+		} else if err := s.dynamicQuery.UpdateRecord(ctx, app.TableName, recordID, data); err != nil {
+			return duplicateValueError(err, fields)
+		}
+		if err := s.attachments.AttachRecord(ctx, appID, recordID, fields, data); err != nil {
+			return err
+		}
+
+		var err error
+		after, err = s.dynamicQuery.GetRecordByID(ctx, app.TableName, storedFields(fields), recordID)
+		if err != nil {
+			return err
+		}
+		if after == nil {
+			return ErrRecordNotFound
+		}
+
+		// 値が変わっていない場合は履歴を残さず、Webhookの通知も自動化ルールの実行もしない
+		revision = newRevision(appID, models.RevisionActionUpdate, before, after, access.UserID)
+		if len(revision.Changes) == 0 {
+			return nil
+		}
+		if err := s.revisionRepo.Create(ctx, &revision); err != nil {
+			return err
+		}
+		return s.webhooks.Publish(ctx, revisionEvents(revision)...)
+	})
 	if err != nil {
 		return nil, err
 	}
+	if conflict {
+		return nil, s.recordVersionConflict(ctx, appID, recordID)
+	}
+	if len(revision.Changes) > 0 {
+		s.runAutomations(ctx, revision)
+	}
 
-	return s.dynamicQuery.GetRecordByID(ctx, app.TableName, fields, recordID)
+	return after, nil
 }
 
-// DeleteRecord レコードを削除する
-func (s *RecordService) DeleteRecord(ctx context.Context, appID, recordID uint64) error {
-	// アプリ情報を取得
-	app, err := s.appRepo.GetByID(ctx, appID)
+// DeleteRecord レコードをごみ箱に移す
+// 削除時の動作が cascade の参照フィールドで連鎖して削除されるレコードも、それぞれのアプリのごみ箱に移す。
+// version が0でなく現在のバージョンと異なる場合は VersionConflictError を返す
+func (s *RecordService) DeleteRecord(ctx context.Context, appID, recordID uint64, version int64) error {
+	// アプリ情報を取得し権限を確認
+	app, access, err := s.permissions.AuthorizeApp(ctx, appID, models.AppRoleEditor)
 	if err != nil {
 		return err
 	}
-	if app == nil {
-		return ErrAppNotFound
-	}
 
 	// 外部データソースのアプリは読み取り専用
 	if app.IsExternal {
 		return ErrExternalAppReadOnly
 	}
 
-	return s.dynamicQuery.DeleteRecord(ctx, app.TableName, recordID)
+	fields, err := s.fieldRepo.GetByAppID(ctx, appID)
+	if err != nil {
+		return err
+	}
+
+	// 削除前の状態を取得（作成者の確認を兼ねる）
+	before, err := s.getAccessibleRecord(ctx, app, fields, access, recordID)
+	if err != nil {
+		return err
+	}
+	if version != 0 && version != before.Version {
+		return s.recordVersionConflict(ctx, appID, recordID)
+	}
+
+	conflict := false
+	err = s.transactor.RunInTx(ctx, func(ctx context.Context) error {
+		// 添付ファイルは復元できるよう、ごみ箱から完全に削除するまで残す
+		// バージョンを指定した場合は、取得した後に他の更新がなかったときのみ削除する
+		trashed := true
+		var err error
+		if version != 0 {
+			trashed, err = s.dynamicQuery.TrashRecordIfVersion(ctx, app.TableName, recordID, version, access.UserID)
+		} else {
+			err = s.dynamicQuery.TrashRecords(ctx, app.TableName, []uint64{recordID}, access.UserID)
+		}
+		if err != nil {
+			// 削除時の動作がrestrictの参照フィールドから参照されている
+			if repositories.IsForeignKeyViolation(err) {
+				return ErrRecordReferenced
+			}
+			return err
+		}
+		if !trashed {
+			conflict = true
+			return nil
+		}
+
+		revision := newRevision(appID, models.RevisionActionDelete, before, nil, access.UserID)
+		if err := s.revisionRepo.Create(ctx, &revision); err != nil {
+			return err
+		}
+		return s.webhooks.Publish(ctx, revisionEvents(revision)...)
+	})
+	if err != nil {
+		return err
+	}
+	if conflict {
+		return s.recordVersionConflict(ctx, appID, recordID)
+	}
+	return nil
+}
+
+// runAutomations コミットした変更履歴を契機として自動化ルールを実行する
+// レコードの変更は確定しているため、実行の失敗は記録するだけで呼び出し元には返さない
+func (s *RecordService) runAutomations(ctx context.Context, revisions ...models.RecordRevision) {
+	if err := s.automations.Run(ctx, revisions...); err != nil {
+		log.Printf("自動化ルールの実行に失敗しました（アプリ%d）: %v", revisions[0].AppID, err)
+	}
+}
+
+// recordVersionConflict 現在のレコードを取得し、バージョンの不一致を表すエラーを返す
+// 競合した更新でレコードが削除されていた場合は ErrRecordNotFound を返す
+func (s *RecordService) recordVersionConflict(ctx context.Context, appID, recordID uint64) error {
+	current, err := s.GetRecord(ctx, appID, recordID)
+	if err != nil {
+		return err
+	}
+	return &VersionConflictError{Version: current.Version, Current: current}
 }
 
 // BulkCreateRecords 複数のレコードを作成する
 func (s *RecordService) BulkCreateRecords(ctx context.Context, appID, userID uint64, req *models.BulkCreateRecordRequest) ([]models.RecordResponse, error) {
-	// アプリ情報を取得
-	app, err := s.appRepo.GetByID(ctx, appID)
+	// アプリ情報を取得し権限を確認
+	app, _, err := s.permissions.AuthorizeApp(ctx, appID, models.AppRoleEditor)
 	if err != nil {
 		return nil, err
 	}
-	if app == nil {
-		return nil, ErrAppNotFound
-	}
 
 	// 外部データソースのアプリは読み取り専用
 	if app.IsExternal {
@@ -259,40 +520,266 @@ func (s *RecordService) BulkCreateRecords(ctx context.Context, appID, userID uin
 		return nil, err
 	}
 
-	// レコードスライスを事前確保
-	records := make([]models.RecordResponse, 0, len(req.Records))
-	for _, data := range req.Records {
-		recordID, err := s.dynamicQuery.InsertRecord(ctx, app.TableName, data, userID)
-		if err != nil {
-			return nil, err
+	// 全レコードを挿入前に検証し、1件でも不正があれば何も作成しない
+	validator := NewRecordValidator(fields)
+	validated := make([]models.RecordData, 0, len(req.Records))
+	var recordErrs []models.RecordFieldErrors
+	for i, data := range req.Records {
+		normalized, fieldErrs := validator.ValidateCreate(data)
+		if fieldErrs != nil {
+			recordErrs = append(recordErrs, models.RecordFieldErrors{Index: i, Errors: fieldErrs})
+			continue
 		}
+		validated = append(validated, normalized)
+	}
+	if len(recordErrs) > 0 {
+		return nil, &RecordValidationError{RecordErrors: recordErrs}
+	}
 
-		record, err := s.dynamicQuery.GetRecordByID(ctx, app.TableName, fields, recordID)
-		if err != nil {
-			return nil, err
+	// 参照先のレコードの存在をまとめて確認
+	refErrs, err := s.checkReferences(ctx, fields, validated)
+	if err != nil {
+		return nil, err
+	}
+	if refErrs != nil {
+		for i := range validated {
+			if fieldErrs, ok := refErrs[i]; ok {
+				recordErrs = append(recordErrs, models.RecordFieldErrors{Index: i, Errors: fieldErrs})
+			}
+		}
+		return nil, &RecordValidationError{RecordErrors: recordErrs}
+	}
+
+	// 添付ファイルをまとめて確認
+	attachmentErrs, err := s.attachments.ResolveValues(ctx, appID, 0, userID, fields, validated)
+	if err != nil {
+		return nil, err
+	}
+	if attachmentErrs != nil {
+		for i := range validated {
+			if fieldErrs, ok := attachmentErrs[i]; ok {
+				recordErrs = append(recordErrs, models.RecordFieldErrors{Index: i, Errors: fieldErrs})
+			}
+		}
+		return nil, &RecordValidationError{RecordErrors: recordErrs}
+	}
+
+	// 1つのトランザクションで挿入し、途中で失敗した場合は1件も作成しない
+	records := make([]models.RecordResponse, 0, len(validated))
+	revisions := make([]models.RecordRevision, 0, len(validated))
+	err = s.transactor.RunInTx(ctx, func(ctx context.Context) error {
+		for _, data := range validated {
+			recordID, err := s.dynamicQuery.InsertRecord(ctx, app.TableName, data, userID)
+			if err != nil {
+				return duplicateValueError(err, fields)
+			}
+			if err := s.attachments.AttachRecord(ctx, appID, recordID, fields, data); err != nil {
+				return err
+			}
+
+			record, err := s.dynamicQuery.GetRecordByID(ctx, app.TableName, storedFields(fields), recordID)
+			if err != nil {
+				return err
+			}
+
+			records = append(records, *record)
+			revisions = append(revisions, newRevision(appID, models.RevisionActionCreate, nil, record, userID))
 		}
 
-		records = append(records, *record)
+		// 変更履歴をまとめて記録
+		if err := s.revisionRepo.CreateBatch(ctx, revisions); err != nil {
+			return err
+		}
+		return s.webhooks.Publish(ctx, revisionEvents(revisions...)...)
+	})
+	if err != nil {
+		return nil, err
 	}
+	s.runAutomations(ctx, revisions...)
 
 	return records, nil
 }
 
-// BulkDeleteRecords 複数のレコードを削除する
+// BulkDeleteRecords 複数のレコードをごみ箱に移す
 func (s *RecordService) BulkDeleteRecords(ctx context.Context, appID uint64, req *models.BulkDeleteRecordRequest) error {
-	// アプリ情報を取得
-	app, err := s.appRepo.GetByID(ctx, appID)
+	// アプリ情報を取得し権限を確認
+	app, access, err := s.permissions.AuthorizeApp(ctx, appID, models.AppRoleEditor)
 	if err != nil {
 		return err
 	}
-	if app == nil {
-		return ErrAppNotFound
-	}
 
 	// 外部データソースのアプリは読み取り専用
 	if app.IsExternal {
 		return ErrExternalAppReadOnly
 	}
 
-	return s.dynamicQuery.DeleteRecords(ctx, app.TableName, req.IDs)
+	fields, err := s.fieldRepo.GetByAppID(ctx, appID)
+	if err != nil {
+		return err
+	}
+
+	// 削除前の状態を取得（作成者の確認を兼ねる）
+	revisions := make([]models.RecordRevision, 0, len(req.IDs))
+	for _, recordID := range req.IDs {
+		before, err := s.getAccessibleRecord(ctx, app, fields, access, recordID)
+		if err != nil {
+			return err
+		}
+		revisions = append(revisions, newRevision(appID, models.RevisionActionDelete, before, nil, access.UserID))
+	}
+
+	return s.transactor.RunInTx(ctx, func(ctx context.Context) error {
+		if err := s.dynamicQuery.TrashRecords(ctx, app.TableName, req.IDs, access.UserID); err != nil {
+			// 削除時の動作がrestrictの参照フィールドから参照されている
+			if repositories.IsForeignKeyViolation(err) {
+				return ErrRecordReferenced
+			}
+			return err
+		}
+
+		if err := s.revisionRepo.CreateBatch(ctx, revisions); err != nil {
+			return err
+		}
+		return s.webhooks.Publish(ctx, revisionEvents(revisions...)...)
+	})
+}
+
+// GetRecordHistory レコードの変更履歴を新しい順に取得する
+func (s *RecordService) GetRecordHistory(ctx context.Context, appID, recordID uint64, page, limit int) (*models.RecordHistoryResponse, error) {
+	// アプリ情報を取得し権限を確認
+	app, access, err := s.permissions.AuthorizeApp(ctx, appID, models.AppRoleViewer)
+	if err != nil {
+		return nil, err
+	}
+
+	// 自分のレコードのみ閲覧可能な場合は現存する自分のレコードの履歴に限る
+	if access.OwnRecordsOnly {
+		if app.IsExternal {
+			return nil, ErrPermissionDenied
+		}
+		if _, err := s.getAccessibleRecord(ctx, app, nil, access, recordID); err != nil {
+			return nil, err
+		}
+	}
+
+	revisions, total, err := s.revisionRepo.GetByRecordID(ctx, appID, recordID, page, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]models.RecordRevisionResponse, len(revisions))
+	for i := range revisions {
+		responses[i] = *revisions[i].ToResponse()
+	}
+
+	return &models.RecordHistoryResponse{
+		Revisions:  responses,
+		Pagination: models.NewPagination(page, limit, total),
+	}, nil
+}
+
+// RevertRecord レコードを指定した変更履歴の時点の内容に戻す
+// 戻す内容は現在も存在するフィールドのみが対象で、削除済みのレコードは復元できない。
+// version にはクライアントが取得したときのバージョンを渡し、他の更新と競合した場合は VersionConflictError を返す（0の場合は確認しない）
+func (s *RecordService) RevertRecord(ctx context.Context, appID, recordID, revisionID uint64, version int64) (*models.RecordResponse, error) {
+	// アプリ情報を取得し権限を確認
+	app, access, err := s.permissions.AuthorizeApp(ctx, appID, models.AppRoleEditor)
+	if err != nil {
+		return nil, err
+	}
+
+	// 外部データソースのアプリは読み取り専用
+	if app.IsExternal {
+		return nil, ErrExternalAppReadOnly
+	}
+
+	revision, err := s.revisionRepo.GetByID(ctx, revisionID)
+	if err != nil {
+		return nil, err
+	}
+	if revision == nil || revision.AppID != appID || revision.RecordID != recordID {
+		return nil, ErrRevisionNotFound
+	}
+
+	fields, err := s.fieldRepo.GetByAppID(ctx, appID)
+	if err != nil {
+		return nil, err
+	}
+
+	before, err := s.getAccessibleRecord(ctx, app, fields, access, recordID)
+	if err != nil {
+		return nil, err
+	}
+	if version != 0 && version != before.Version {
+		return nil, s.recordVersionConflict(ctx, appID, recordID)
+	}
+
+	// 現在のフィールド定義に存在する値のみを復元し、現在の検証ルールで変換する
+	restore := make(models.RecordData, len(fields))
+	for i := range fields {
+		if value, ok := revision.Snapshot[fields[i].FieldCode]; ok {
+			restore[fields[i].FieldCode] = value
+		}
+	}
+	data, fieldErrs := NewRecordValidator(fields).ValidateUpdate(restore)
+	if fieldErrs != nil {
+		return nil, &RecordValidationError{Errors: fieldErrs}
+	}
+	// 参照先のレコードが削除されている場合は戻せない
+	if err := s.checkRecordReferences(ctx, fields, data); err != nil {
+		return nil, err
+	}
+	// 削除済みの添付ファイルは戻せない
+	if err := s.checkRecordAttachments(ctx, appID, recordID, access.UserID, fields, data); err != nil {
+		return nil, err
+	}
+
+	// レコードの更新・変更履歴・Webhookの配信キューへの登録をまとめて行う
+	var after *models.RecordResponse
+	var reverted models.RecordRevision
+	conflict := false
+	err = s.transactor.RunInTx(ctx, func(ctx context.Context) error {
+		if len(data) > 0 {
+			// バージョンを指定した場合は、取得した後に他の更新がなかったときのみ更新する
+			if version != 0 {
+				updated, err := s.dynamicQuery.UpdateRecordIfVersion(ctx, app.TableName, recordID, version, data)
+				if err != nil {
+					return duplicateValueError(err, fields)
+				}
+				if !updated {
+					conflict = true
+					return nil
+				}
+			} else if err := s.dynamicQuery.UpdateRecord(ctx, app.TableName, recordID, data); err != nil {
+				return duplicateValueError(err, fields)
+			}
+			if err := s.attachments.AttachRecord(ctx, appID, recordID, fields, data); err != nil {
+				return err
+			}
+		}
+
+		var err error
+		after, err = s.dynamicQuery.GetRecordByID(ctx, app.TableName, storedFields(fields), recordID)
+		if err != nil {
+			return err
+		}
+		if after == nil {
+			return ErrRecordNotFound
+		}
+
+		reverted = newRevision(appID, models.RevisionActionRevert, before, after, access.UserID)
+		if err := s.revisionRepo.Create(ctx, &reverted); err != nil {
+			return err
+		}
+		return s.webhooks.Publish(ctx, revisionEvents(reverted)...)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if conflict {
+		return nil, s.recordVersionConflict(ctx, appID, recordID)
+	}
+	s.runAutomations(ctx, reverted)
+
+	return after, nil
 }
@@ -2,9 +2,11 @@ package services_test
 
 import (
 	"context"
+	"errors"
 	"testing"
 	"time"
 
+	"github.com/lib/pq"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
@@ -16,7 +18,7 @@ import (
 )
 
 func TestRecordService_GetRecords(t *testing.T) {
-	ctx := context.Background()
+	ctx := systemContext()
 
 	t.Run("successful get records", func(t *testing.T) {
 		mockAppRepo := new(mocks.MockAppRepository)
@@ -42,7 +44,7 @@ func TestRecordService_GetRecords(t *testing.T) {
 		mockFieldRepo.On("GetByAppID", ctx, uint64(1)).Return(fields, nil)
 		mockDynamicQuery.On("GetRecords", ctx, "app_data_1", fields, mock.AnythingOfType("repositories.RecordQueryOptions")).Return(records, int64(2), nil)
 
-		service := services.NewRecordService(mockAppRepo, mockFieldRepo, mockDynamicQuery, mockDSRepo, mockExternalQuery)
+		service := services.NewRecordService(mockAppRepo, mockFieldRepo, mockDynamicQuery, mockDSRepo, mockExternalQuery, newTestPermissionService(mockAppRepo), new(mocks.MockRecordRevisionRepository), newTestTransactor(), newTestWebhookPublisher(), newTestAutomationRunner(), newTestAttachmentManager())
 
 		opts := repositories.RecordQueryOptions{Page: 1, Limit: 10}
 		resp, err := service.GetRecords(ctx, 1, opts)
@@ -64,7 +66,7 @@ func TestRecordService_GetRecords(t *testing.T) {
 
 		mockAppRepo.On("GetByID", ctx, uint64(999)).Return(nil, nil)
 
-		service := services.NewRecordService(mockAppRepo, mockFieldRepo, mockDynamicQuery, mockDSRepo, mockExternalQuery)
+		service := services.NewRecordService(mockAppRepo, mockFieldRepo, mockDynamicQuery, mockDSRepo, mockExternalQuery, newTestPermissionService(mockAppRepo), new(mocks.MockRecordRevisionRepository), newTestTransactor(), newTestWebhookPublisher(), newTestAutomationRunner(), newTestAttachmentManager())
 
 		opts := repositories.RecordQueryOptions{Page: 1, Limit: 10}
 		_, err := service.GetRecords(ctx, 999, opts)
@@ -72,10 +74,114 @@ func TestRecordService_GetRecords(t *testing.T) {
 
 		mockAppRepo.AssertExpectations(t)
 	})
+
+	t.Run("filters are resolved before querying", func(t *testing.T) {
+		mockAppRepo := new(mocks.MockAppRepository)
+		mockFieldRepo := new(mocks.MockFieldRepository)
+		mockDynamicQuery := new(mocks.MockDynamicQueryExecutor)
+
+		app := &models.App{ID: 1, TableName: "app_data_1"}
+		fields := []models.AppField{
+			{ID: 1, FieldCode: "name", FieldName: "Name", FieldType: "text"},
+			{ID: 2, FieldCode: "amount", FieldName: "Amount", FieldType: "number"},
+		}
+
+		mockAppRepo.On("GetByID", ctx, uint64(1)).Return(app, nil)
+		mockFieldRepo.On("GetByAppID", ctx, uint64(1)).Return(fields, nil)
+		var received repositories.RecordQueryOptions
+		mockDynamicQuery.On("GetRecords", ctx, "app_data_1", fields, mock.AnythingOfType("repositories.RecordQueryOptions")).
+			Return([]models.RecordResponse{}, int64(0), nil).
+			Run(func(args mock.Arguments) { received = args.Get(3).(repositories.RecordQueryOptions) })
+
+		service := services.NewRecordService(mockAppRepo, mockFieldRepo, mockDynamicQuery, new(mocks.MockDataSourceRepository), new(mocks.MockExternalQueryExecutor), newTestPermissionService(mockAppRepo), new(mocks.MockRecordRevisionRepository), newTestTransactor(), newTestWebhookPublisher(), newTestAutomationRunner(), newTestAttachmentManager())
+
+		opts := repositories.RecordQueryOptions{
+			Page: 1, Limit: 10,
+			Filters: []models.FilterItem{{Field: "amount", Operator: "gte", Value: " 100"}},
+			Filter: &models.FilterExpr{Or: []models.FilterExpr{
+				{FilterItem: models.FilterItem{Field: "name", Operator: "eq", Value: "A"}},
+				{FilterItem: models.FilterItem{Field: "name", Operator: "eq", Value: "B"}},
+			}},
+		}
+		_, err := service.GetRecords(ctx, 1, opts)
+		require.NoError(t, err)
+		assert.Equal(t, []models.FilterItem{{Field: "amount", Operator: "gte", Value: "100"}}, received.Filters)
+		require.NotNil(t, received.Filter)
+		assert.Len(t, received.Filter.Or, 2)
+	})
+
+	t.Run("invalid filter", func(t *testing.T) {
+		mockAppRepo := new(mocks.MockAppRepository)
+		mockFieldRepo := new(mocks.MockFieldRepository)
+		mockDynamicQuery := new(mocks.MockDynamicQueryExecutor)
+
+		app := &models.App{ID: 1, TableName: "app_data_1"}
+		fields := []models.AppField{{ID: 1, FieldCode: "amount", FieldName: "Amount", FieldType: "number"}}
+
+		mockAppRepo.On("GetByID", ctx, uint64(1)).Return(app, nil)
+		mockFieldRepo.On("GetByAppID", ctx, uint64(1)).Return(fields, nil)
+
+		service := services.NewRecordService(mockAppRepo, mockFieldRepo, mockDynamicQuery, new(mocks.MockDataSourceRepository), new(mocks.MockExternalQueryExecutor), newTestPermissionService(mockAppRepo), new(mocks.MockRecordRevisionRepository), newTestTransactor(), newTestWebhookPublisher(), newTestAutomationRunner(), newTestAttachmentManager())
+
+		opts := repositories.RecordQueryOptions{Page: 1, Limit: 10, Filters: []models.FilterItem{{Field: "amount", Operator: "like", Value: "1"}}}
+		_, err := service.GetRecords(ctx, 1, opts)
+		assert.ErrorIs(t, err, services.ErrInvalidFilter)
+		mockDynamicQuery.AssertNotCalled(t, "GetRecords", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("cursor pagination fetches one extra record for the next cursor", func(t *testing.T) {
+		mockAppRepo := new(mocks.MockAppRepository)
+		mockFieldRepo := new(mocks.MockFieldRepository)
+		mockDynamicQuery := new(mocks.MockDynamicQueryExecutor)
+
+		app := &models.App{ID: 1, TableName: "app_data_1"}
+		fields := []models.AppField{{ID: 1, FieldCode: "amount", FieldName: "Amount", FieldType: "number"}}
+		value := "100"
+		after := &models.RecordCursor{Sort: "amount", Order: "asc", Value: &value, ID: 3}
+
+		mockAppRepo.On("GetByID", ctx, uint64(1)).Return(app, nil)
+		mockFieldRepo.On("GetByAppID", ctx, uint64(1)).Return(fields, nil)
+		var received repositories.RecordQueryOptions
+		mockDynamicQuery.On("GetRecords", ctx, "app_data_1", fields, mock.AnythingOfType("repositories.RecordQueryOptions")).
+			Return([]models.RecordResponse{{ID: 4, Cursor: "c4"}, {ID: 5, Cursor: "c5"}, {ID: 6, Cursor: "c6"}}, int64(-1), nil).
+			Run(func(args mock.Arguments) { received = args.Get(3).(repositories.RecordQueryOptions) })
+
+		service := services.NewRecordService(mockAppRepo, mockFieldRepo, mockDynamicQuery, new(mocks.MockDataSourceRepository), new(mocks.MockExternalQueryExecutor), newTestPermissionService(mockAppRepo), new(mocks.MockRecordRevisionRepository), newTestTransactor(), newTestWebhookPublisher(), newTestAutomationRunner(), newTestAttachmentManager())
+
+		opts := repositories.RecordQueryOptions{Limit: 2, Sort: "amount", Order: "asc", Keyset: true, Cursor: after.Encode(), Count: repositories.CountNone}
+		resp, err := service.GetRecords(ctx, 1, opts)
+		require.NoError(t, err)
+		assert.Equal(t, 3, received.Limit)
+		assert.Equal(t, after, received.After)
+
+		require.Len(t, resp.Records, 2)
+		assert.Equal(t, "c5", resp.Pagination.NextCursor)
+		assert.Equal(t, int64(-1), resp.Pagination.Total)
+	})
+
+	t.Run("cursor created with another sort", func(t *testing.T) {
+		mockAppRepo := new(mocks.MockAppRepository)
+		mockFieldRepo := new(mocks.MockFieldRepository)
+		mockDynamicQuery := new(mocks.MockDynamicQueryExecutor)
+
+		app := &models.App{ID: 1, TableName: "app_data_1"}
+		fields := []models.AppField{{ID: 1, FieldCode: "amount", FieldName: "Amount", FieldType: "number"}}
+
+		mockAppRepo.On("GetByID", ctx, uint64(1)).Return(app, nil)
+		mockFieldRepo.On("GetByAppID", ctx, uint64(1)).Return(fields, nil)
+
+		service := services.NewRecordService(mockAppRepo, mockFieldRepo, mockDynamicQuery, new(mocks.MockDataSourceRepository), new(mocks.MockExternalQueryExecutor), newTestPermissionService(mockAppRepo), new(mocks.MockRecordRevisionRepository), newTestTransactor(), newTestWebhookPublisher(), newTestAutomationRunner(), newTestAttachmentManager())
+
+		cursor := (&models.RecordCursor{Order: "desc", ID: 3}).Encode()
+		opts := repositories.RecordQueryOptions{Limit: 2, Sort: "amount", Order: "desc", Keyset: true, Cursor: cursor}
+		_, err := service.GetRecords(ctx, 1, opts)
+		assert.ErrorIs(t, err, services.ErrInvalidPagination)
+		mockDynamicQuery.AssertNotCalled(t, "GetRecords", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
 }
 
 func TestRecordService_GetRecord(t *testing.T) {
-	ctx := context.Background()
+	ctx := systemContext()
 
 	t.Run("successful get record", func(t *testing.T) {
 		mockAppRepo := new(mocks.MockAppRepository)
@@ -104,7 +210,7 @@ func TestRecordService_GetRecord(t *testing.T) {
 		mockFieldRepo.On("GetByAppID", ctx, uint64(1)).Return(fields, nil)
 		mockDynamicQuery.On("GetRecordByID", ctx, "app_data_1", fields, uint64(1)).Return(record, nil)
 
-		service := services.NewRecordService(mockAppRepo, mockFieldRepo, mockDynamicQuery, mockDSRepo, mockExternalQuery)
+		service := services.NewRecordService(mockAppRepo, mockFieldRepo, mockDynamicQuery, mockDSRepo, mockExternalQuery, newTestPermissionService(mockAppRepo), new(mocks.MockRecordRevisionRepository), newTestTransactor(), newTestWebhookPublisher(), newTestAutomationRunner(), newTestAttachmentManager())
 
 		resp, err := service.GetRecord(ctx, 1, 1)
 		require.NoError(t, err)
@@ -134,7 +240,7 @@ func TestRecordService_GetRecord(t *testing.T) {
 		mockFieldRepo.On("GetByAppID", ctx, uint64(1)).Return(fields, nil)
 		mockDynamicQuery.On("GetRecordByID", ctx, "app_data_1", fields, uint64(999)).Return(nil, nil)
 
-		service := services.NewRecordService(mockAppRepo, mockFieldRepo, mockDynamicQuery, mockDSRepo, mockExternalQuery)
+		service := services.NewRecordService(mockAppRepo, mockFieldRepo, mockDynamicQuery, mockDSRepo, mockExternalQuery, newTestPermissionService(mockAppRepo), new(mocks.MockRecordRevisionRepository), newTestTransactor(), newTestWebhookPublisher(), newTestAutomationRunner(), newTestAttachmentManager())
 
 		_, err := service.GetRecord(ctx, 1, 999)
 		assert.ErrorIs(t, err, services.ErrRecordNotFound)
@@ -146,7 +252,7 @@ func TestRecordService_GetRecord(t *testing.T) {
 }
 
 func TestRecordService_CreateRecord(t *testing.T) {
-	ctx := context.Background()
+	ctx := systemContext()
 
 	t.Run("successful creation", func(t *testing.T) {
 		mockAppRepo := new(mocks.MockAppRepository)
@@ -176,7 +282,13 @@ func TestRecordService_CreateRecord(t *testing.T) {
 		mockDynamicQuery.On("InsertRecord", ctx, "app_data_1", mock.AnythingOfType("models.RecordData"), uint64(1)).Return(uint64(1), nil)
 		mockDynamicQuery.On("GetRecordByID", ctx, "app_data_1", fields, uint64(1)).Return(createdRecord, nil)
 
-		service := services.NewRecordService(mockAppRepo, mockFieldRepo, mockDynamicQuery, mockDSRepo, mockExternalQuery)
+		mockRevisionRepo := new(mocks.MockRecordRevisionRepository)
+		mockRevisionRepo.On("Create", ctx, mock.MatchedBy(func(rev *models.RecordRevision) bool {
+			return rev.Action == models.RevisionActionCreate && rev.RecordID == 1 &&
+				rev.Changes["name"].After == "New Record" && rev.ChangedBy != nil && *rev.ChangedBy == 1
+		})).Return(nil)
+
+		service := services.NewRecordService(mockAppRepo, mockFieldRepo, mockDynamicQuery, mockDSRepo, mockExternalQuery, newTestPermissionService(mockAppRepo), mockRevisionRepo, newTestTransactor(), newTestWebhookPublisher(), newTestAutomationRunner(), newTestAttachmentManager())
 
 		req := &models.CreateRecordRequest{
 			Data: models.RecordData{"name": "New Record"},
@@ -189,6 +301,7 @@ func TestRecordService_CreateRecord(t *testing.T) {
 		mockAppRepo.AssertExpectations(t)
 		mockFieldRepo.AssertExpectations(t)
 		mockDynamicQuery.AssertExpectations(t)
+		mockRevisionRepo.AssertExpectations(t)
 	})
 
 	t.Run("external app is read only", func(t *testing.T) {
@@ -206,7 +319,7 @@ func TestRecordService_CreateRecord(t *testing.T) {
 
 		mockAppRepo.On("GetByID", ctx, uint64(1)).Return(app, nil)
 
-		service := services.NewRecordService(mockAppRepo, mockFieldRepo, mockDynamicQuery, mockDSRepo, mockExternalQuery)
+		service := services.NewRecordService(mockAppRepo, mockFieldRepo, mockDynamicQuery, mockDSRepo, mockExternalQuery, newTestPermissionService(mockAppRepo), new(mocks.MockRecordRevisionRepository), newTestTransactor(), newTestWebhookPublisher(), newTestAutomationRunner(), newTestAttachmentManager())
 
 		req := &models.CreateRecordRequest{
 			Data: models.RecordData{"name": "New Record"},
@@ -218,10 +331,98 @@ func TestRecordService_CreateRecord(t *testing.T) {
 
 		mockAppRepo.AssertExpectations(t)
 	})
+
+	t.Run("duplicate value of unique field", func(t *testing.T) {
+		mockAppRepo := new(mocks.MockAppRepository)
+		mockFieldRepo := new(mocks.MockFieldRepository)
+		mockDynamicQuery := new(mocks.MockDynamicQueryExecutor)
+
+		app := &models.App{ID: 1, TableName: "app_data_1"}
+		fields := []models.AppField{
+			{ID: 1, FieldCode: "email", FieldName: "メールアドレス", FieldType: "text", Options: models.FieldOptions{"unique": true}},
+		}
+
+		mockAppRepo.On("GetByID", ctx, uint64(1)).Return(app, nil)
+		mockFieldRepo.On("GetByAppID", ctx, uint64(1)).Return(fields, nil)
+		mockDynamicQuery.On("InsertRecord", ctx, "app_data_1", mock.AnythingOfType("models.RecordData"), uint64(1)).
+			Return(uint64(0), &pq.Error{Code: "23505", Detail: "Key (email)=(a@example.com) already exists."})
+
+		service := services.NewRecordService(mockAppRepo, mockFieldRepo, mockDynamicQuery, new(mocks.MockDataSourceRepository), new(mocks.MockExternalQueryExecutor), newTestPermissionService(mockAppRepo), new(mocks.MockRecordRevisionRepository), newTestTransactor(), newTestWebhookPublisher(), newTestAutomationRunner(), newTestAttachmentManager())
+
+		_, err := service.CreateRecord(ctx, 1, 1, &models.CreateRecordRequest{Data: models.RecordData{"email": "a@example.com"}})
+		assert.ErrorIs(t, err, services.ErrDuplicateValue)
+		assert.Contains(t, err.Error(), "メールアドレス")
+	})
+
+	t.Run("automation failure does not fail the committed record", func(t *testing.T) {
+		mockAppRepo := new(mocks.MockAppRepository)
+		mockFieldRepo := new(mocks.MockFieldRepository)
+		mockDynamicQuery := new(mocks.MockDynamicQueryExecutor)
+		mockRevisionRepo := new(mocks.MockRecordRevisionRepository)
+		mockTransactor := new(mocks.MockTransactor)
+		mockRunner := new(mocks.MockAutomationRunner)
+
+		app := &models.App{ID: 1, TableName: "app_data_1"}
+		fields := []models.AppField{
+			{ID: 1, FieldCode: "name", FieldName: "Name", FieldType: "TEXT"},
+		}
+		createdRecord := &models.RecordResponse{ID: 1, Data: models.RecordData{"name": "New Record"}, CreatedBy: 1}
+
+		mockAppRepo.On("GetByID", ctx, uint64(1)).Return(app, nil)
+		mockFieldRepo.On("GetByAppID", ctx, uint64(1)).Return(fields, nil)
+		mockTransactor.On("RunInTx", ctx).Return(nil).Once()
+		mockDynamicQuery.On("InsertRecord", ctx, "app_data_1", mock.AnythingOfType("models.RecordData"), uint64(1)).Return(uint64(1), nil)
+		mockDynamicQuery.On("GetRecordByID", ctx, "app_data_1", fields, uint64(1)).Return(createdRecord, nil)
+		mockRevisionRepo.On("Create", ctx, mock.AnythingOfType("*models.RecordRevision")).Return(nil)
+		mockRunner.On("Run", ctx, mock.Anything).Return(errors.New("rule lookup failed"))
+
+		service := services.NewRecordService(mockAppRepo, mockFieldRepo, mockDynamicQuery, new(mocks.MockDataSourceRepository), new(mocks.MockExternalQueryExecutor), newTestPermissionService(mockAppRepo), mockRevisionRepo, mockTransactor, newTestWebhookPublisher(), mockRunner, newTestAttachmentManager())
+
+		resp, err := service.CreateRecord(ctx, 1, 1, &models.CreateRecordRequest{Data: models.RecordData{"name": "New Record"}})
+		require.NoError(t, err)
+		assert.Equal(t, uint64(1), resp.ID)
+
+		mockTransactor.AssertExpectations(t)
+		mockRunner.AssertExpectations(t)
+	})
+}
+
+func TestRecordService_CreateRecord_ValidationError(t *testing.T) {
+	ctx := systemContext()
+	mockAppRepo := new(mocks.MockAppRepository)
+	mockFieldRepo := new(mocks.MockFieldRepository)
+	mockDynamicQuery := new(mocks.MockDynamicQueryExecutor)
+	mockDSRepo := new(mocks.MockDataSourceRepository)
+	mockExternalQuery := new(mocks.MockExternalQueryExecutor)
+
+	app := &models.App{ID: 1, TableName: "app_data_1"}
+	fields := []models.AppField{
+		{ID: 1, FieldCode: "name", FieldName: "Name", FieldType: "text", Required: true},
+		{ID: 2, FieldCode: "status", FieldName: "Status", FieldType: "select", Options: models.FieldOptions{"choices": []interface{}{"open", "closed"}}},
+	}
+
+	mockAppRepo.On("GetByID", ctx, uint64(1)).Return(app, nil)
+	mockFieldRepo.On("GetByAppID", ctx, uint64(1)).Return(fields, nil)
+
+	service := services.NewRecordService(mockAppRepo, mockFieldRepo, mockDynamicQuery, mockDSRepo, mockExternalQuery, newTestPermissionService(mockAppRepo), new(mocks.MockRecordRevisionRepository), newTestTransactor(), newTestWebhookPublisher(), newTestAutomationRunner(), newTestAttachmentManager())
+
+	req := &models.CreateRecordRequest{
+		Data: models.RecordData{"status": "pending"},
+	}
+
+	_, err := service.CreateRecord(ctx, 1, 1, req)
+	require.ErrorIs(t, err, services.ErrRecordValidation)
+
+	var vErr *services.RecordValidationError
+	require.ErrorAs(t, err, &vErr)
+	assert.Contains(t, vErr.Errors, "name")
+	assert.Contains(t, vErr.Errors, "status")
+
+	mockDynamicQuery.AssertNotCalled(t, "InsertRecord", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
 }
 
 func TestRecordService_UpdateRecord(t *testing.T) {
-	ctx := context.Background()
+	ctx := systemContext()
 
 	t.Run("successful update", func(t *testing.T) {
 		mockAppRepo := new(mocks.MockAppRepository)
@@ -248,22 +449,73 @@ func TestRecordService_UpdateRecord(t *testing.T) {
 
 		mockAppRepo.On("GetByID", ctx, uint64(1)).Return(app, nil)
 		mockFieldRepo.On("GetByAppID", ctx, uint64(1)).Return(fields, nil)
+		mockDynamicQuery.On("GetRecordByID", ctx, "app_data_1", fields, uint64(1)).Return(&models.RecordResponse{ID: 1, Data: models.RecordData{"name": "Original"}, CreatedBy: 1}, nil).Once()
 		mockDynamicQuery.On("UpdateRecord", ctx, "app_data_1", uint64(1), mock.AnythingOfType("models.RecordData")).Return(nil)
-		mockDynamicQuery.On("GetRecordByID", ctx, "app_data_1", fields, uint64(1)).Return(updatedRecord, nil)
+		mockDynamicQuery.On("GetRecordByID", ctx, "app_data_1", fields, uint64(1)).Return(updatedRecord, nil).Once()
 
-		service := services.NewRecordService(mockAppRepo, mockFieldRepo, mockDynamicQuery, mockDSRepo, mockExternalQuery)
+		mockRevisionRepo := new(mocks.MockRecordRevisionRepository)
+		mockRevisionRepo.On("Create", ctx, mock.MatchedBy(func(rev *models.RecordRevision) bool {
+			change := rev.Changes["name"]
+			return rev.Action == models.RevisionActionUpdate && change.Before == "Original" && change.After == "Updated"
+		})).Return(nil)
+
+		service := services.NewRecordService(mockAppRepo, mockFieldRepo, mockDynamicQuery, mockDSRepo, mockExternalQuery, newTestPermissionService(mockAppRepo), mockRevisionRepo, newTestTransactor(), newTestWebhookPublisher(), newTestAutomationRunner(), newTestAttachmentManager())
 
 		req := &models.UpdateRecordRequest{
 			Data: models.RecordData{"name": "Updated"},
 		}
 
-		resp, err := service.UpdateRecord(ctx, 1, 1, req)
+		resp, err := service.UpdateRecord(ctx, 1, 1, 0, req)
 		require.NoError(t, err)
 		assert.Equal(t, "Updated", resp.Data["name"])
 
 		mockAppRepo.AssertExpectations(t)
 		mockFieldRepo.AssertExpectations(t)
 		mockDynamicQuery.AssertExpectations(t)
+		mockRevisionRepo.AssertExpectations(t)
+	})
+
+	t.Run("unchanged values are not recorded", func(t *testing.T) {
+		mockAppRepo := new(mocks.MockAppRepository)
+		mockFieldRepo := new(mocks.MockFieldRepository)
+		mockDynamicQuery := new(mocks.MockDynamicQueryExecutor)
+		mockRevisionRepo := new(mocks.MockRecordRevisionRepository)
+
+		app := &models.App{ID: 1, TableName: "app_data_1"}
+		fields := []models.AppField{
+			{ID: 1, FieldCode: "name", FieldName: "Name", FieldType: "text"},
+		}
+		record := &models.RecordResponse{ID: 1, Data: models.RecordData{"name": "Same"}, CreatedBy: 1}
+
+		mockAppRepo.On("GetByID", ctx, uint64(1)).Return(app, nil)
+		mockFieldRepo.On("GetByAppID", ctx, uint64(1)).Return(fields, nil)
+		mockDynamicQuery.On("GetRecordByID", ctx, "app_data_1", fields, uint64(1)).Return(record, nil)
+		mockDynamicQuery.On("UpdateRecord", ctx, "app_data_1", uint64(1), mock.AnythingOfType("models.RecordData")).Return(nil)
+
+		service := services.NewRecordService(mockAppRepo, mockFieldRepo, mockDynamicQuery, new(mocks.MockDataSourceRepository), new(mocks.MockExternalQueryExecutor), newTestPermissionService(mockAppRepo), mockRevisionRepo, newTestTransactor(), newTestWebhookPublisher(), newTestAutomationRunner(), newTestAttachmentManager())
+
+		_, err := service.UpdateRecord(ctx, 1, 1, 0, &models.UpdateRecordRequest{Data: models.RecordData{"name": "Same"}})
+		require.NoError(t, err)
+		mockRevisionRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	})
+
+	t.Run("record not found", func(t *testing.T) {
+		mockAppRepo := new(mocks.MockAppRepository)
+		mockFieldRepo := new(mocks.MockFieldRepository)
+		mockDynamicQuery := new(mocks.MockDynamicQueryExecutor)
+
+		app := &models.App{ID: 1, TableName: "app_data_1"}
+		fields := []models.AppField{}
+
+		mockAppRepo.On("GetByID", ctx, uint64(1)).Return(app, nil)
+		mockFieldRepo.On("GetByAppID", ctx, uint64(1)).Return(fields, nil)
+		mockDynamicQuery.On("GetRecordByID", ctx, "app_data_1", fields, uint64(999)).Return(nil, nil)
+
+		service := services.NewRecordService(mockAppRepo, mockFieldRepo, mockDynamicQuery, new(mocks.MockDataSourceRepository), new(mocks.MockExternalQueryExecutor), newTestPermissionService(mockAppRepo), new(mocks.MockRecordRevisionRepository), newTestTransactor(), newTestWebhookPublisher(), newTestAutomationRunner(), newTestAttachmentManager())
+
+		_, err := service.UpdateRecord(ctx, 1, 999, 0, &models.UpdateRecordRequest{Data: models.RecordData{}})
+		assert.ErrorIs(t, err, services.ErrRecordNotFound)
+		mockDynamicQuery.AssertNotCalled(t, "UpdateRecord", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
 	})
 
 	t.Run("external app is read only", func(t *testing.T) {
@@ -281,13 +533,13 @@ func TestRecordService_UpdateRecord(t *testing.T) {
 
 		mockAppRepo.On("GetByID", ctx, uint64(1)).Return(app, nil)
 
-		service := services.NewRecordService(mockAppRepo, mockFieldRepo, mockDynamicQuery, mockDSRepo, mockExternalQuery)
+		service := services.NewRecordService(mockAppRepo, mockFieldRepo, mockDynamicQuery, mockDSRepo, mockExternalQuery, newTestPermissionService(mockAppRepo), new(mocks.MockRecordRevisionRepository), newTestTransactor(), newTestWebhookPublisher(), newTestAutomationRunner(), newTestAttachmentManager())
 
 		req := &models.UpdateRecordRequest{
 			Data: models.RecordData{"name": "Updated"},
 		}
 
-		_, err := service.UpdateRecord(ctx, 1, 1, req)
+		_, err := service.UpdateRecord(ctx, 1, 1, 0, req)
 		require.Error(t, err)
 		assert.Equal(t, services.ErrExternalAppReadOnly, err)
 
@@ -296,7 +548,7 @@ func TestRecordService_UpdateRecord(t *testing.T) {
 }
 
 func TestRecordService_DeleteRecord(t *testing.T) {
-	ctx := context.Background()
+	ctx := systemContext()
 
 	t.Run("successful delete", func(t *testing.T) {
 		mockAppRepo := new(mocks.MockAppRepository)
@@ -311,16 +563,28 @@ func TestRecordService_DeleteRecord(t *testing.T) {
 			IsExternal: false,
 		}
 
+		fields := []models.AppField{
+			{ID: 1, FieldCode: "name", FieldName: "Name", FieldType: "TEXT"},
+		}
+
 		mockAppRepo.On("GetByID", ctx, uint64(1)).Return(app, nil)
-		mockDynamicQuery.On("DeleteRecord", ctx, "app_data_1", uint64(1)).Return(nil)
+		mockFieldRepo.On("GetByAppID", ctx, uint64(1)).Return(fields, nil)
+		mockDynamicQuery.On("GetRecordByID", ctx, "app_data_1", fields, uint64(1)).Return(&models.RecordResponse{ID: 1, Data: models.RecordData{"name": "Deleted"}, CreatedBy: 1}, nil)
+		mockDynamicQuery.On("TrashRecords", ctx, "app_data_1", []uint64{1}, uint64(0)).Return(nil)
+
+		mockRevisionRepo := new(mocks.MockRecordRevisionRepository)
+		mockRevisionRepo.On("Create", ctx, mock.MatchedBy(func(rev *models.RecordRevision) bool {
+			return rev.Action == models.RevisionActionDelete && rev.Snapshot["name"] == "Deleted" && rev.Changes["name"].After == nil
+		})).Return(nil)
 
-		service := services.NewRecordService(mockAppRepo, mockFieldRepo, mockDynamicQuery, mockDSRepo, mockExternalQuery)
+		service := services.NewRecordService(mockAppRepo, mockFieldRepo, mockDynamicQuery, mockDSRepo, mockExternalQuery, newTestPermissionService(mockAppRepo), mockRevisionRepo, newTestTransactor(), newTestWebhookPublisher(), newTestAutomationRunner(), newTestAttachmentManager())
 
-		err := service.DeleteRecord(ctx, 1, 1)
+		err := service.DeleteRecord(ctx, 1, 1, 0)
 		require.NoError(t, err)
 
 		mockAppRepo.AssertExpectations(t)
 		mockDynamicQuery.AssertExpectations(t)
+		mockRevisionRepo.AssertExpectations(t)
 	})
 
 	t.Run("external app is read only", func(t *testing.T) {
@@ -338,9 +602,9 @@ func TestRecordService_DeleteRecord(t *testing.T) {
 
 		mockAppRepo.On("GetByID", ctx, uint64(1)).Return(app, nil)
 
-		service := services.NewRecordService(mockAppRepo, mockFieldRepo, mockDynamicQuery, mockDSRepo, mockExternalQuery)
+		service := services.NewRecordService(mockAppRepo, mockFieldRepo, mockDynamicQuery, mockDSRepo, mockExternalQuery, newTestPermissionService(mockAppRepo), new(mocks.MockRecordRevisionRepository), newTestTransactor(), newTestWebhookPublisher(), newTestAutomationRunner(), newTestAttachmentManager())
 
-		err := service.DeleteRecord(ctx, 1, 1)
+		err := service.DeleteRecord(ctx, 1, 1, 0)
 		require.Error(t, err)
 		assert.Equal(t, services.ErrExternalAppReadOnly, err)
 
@@ -348,8 +612,95 @@ func TestRecordService_DeleteRecord(t *testing.T) {
 	})
 }
 
+func TestRecordService_Version(t *testing.T) {
+	ctx := systemContext()
+	app := &models.App{ID: 1, TableName: "app_data_1"}
+	fields := []models.AppField{
+		{ID: 1, FieldCode: "name", FieldName: "Name", FieldType: "text"},
+	}
+	req := &models.UpdateRecordRequest{Data: models.RecordData{"name": "Mine"}}
+
+	newService := func(dynamicQuery *mocks.MockDynamicQueryExecutor, revisionRepo *mocks.MockRecordRevisionRepository) *services.RecordService {
+		appRepo := new(mocks.MockAppRepository)
+		fieldRepo := new(mocks.MockFieldRepository)
+		appRepo.On("GetByID", ctx, uint64(1)).Return(app, nil)
+		fieldRepo.On("GetByAppID", ctx, uint64(1)).Return(fields, nil)
+		return services.NewRecordService(appRepo, fieldRepo, dynamicQuery, new(mocks.MockDataSourceRepository), new(mocks.MockExternalQueryExecutor), newTestPermissionService(appRepo), revisionRepo, newTestTransactor(), newTestWebhookPublisher(), newTestAutomationRunner(), newTestAttachmentManager())
+	}
+
+	t.Run("stale version returns the current record", func(t *testing.T) {
+		dynamicQuery := new(mocks.MockDynamicQueryExecutor)
+		dynamicQuery.On("GetRecordByID", ctx, "app_data_1", fields, uint64(1)).Return(&models.RecordResponse{ID: 1, Data: models.RecordData{"name": "Theirs"}, CreatedBy: 1, Version: 200}, nil)
+		service := newService(dynamicQuery, new(mocks.MockRecordRevisionRepository))
+
+		_, err := service.UpdateRecord(ctx, 1, 1, 100, req)
+		var conflict *services.VersionConflictError
+		require.ErrorAs(t, err, &conflict)
+		assert.ErrorIs(t, err, services.ErrVersionConflict)
+		assert.Equal(t, int64(200), conflict.Version)
+		assert.Equal(t, "Theirs", conflict.Current.(*models.RecordResponse).Data["name"])
+		dynamicQuery.AssertNotCalled(t, "UpdateRecordIfVersion", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("update between read and write is detected", func(t *testing.T) {
+		dynamicQuery := new(mocks.MockDynamicQueryExecutor)
+		dynamicQuery.On("GetRecordByID", ctx, "app_data_1", fields, uint64(1)).Return(&models.RecordResponse{ID: 1, Data: models.RecordData{"name": "Original"}, CreatedBy: 1, Version: 100}, nil).Once()
+		dynamicQuery.On("UpdateRecordIfVersion", ctx, "app_data_1", uint64(1), int64(100), mock.AnythingOfType("models.RecordData")).Return(false, nil)
+		dynamicQuery.On("GetRecordByID", ctx, "app_data_1", fields, uint64(1)).Return(&models.RecordResponse{ID: 1, Data: models.RecordData{"name": "Theirs"}, CreatedBy: 1, Version: 300}, nil).Once()
+		revisionRepo := new(mocks.MockRecordRevisionRepository)
+		service := newService(dynamicQuery, revisionRepo)
+
+		_, err := service.UpdateRecord(ctx, 1, 1, 100, req)
+		var conflict *services.VersionConflictError
+		require.ErrorAs(t, err, &conflict)
+		assert.Equal(t, int64(300), conflict.Version)
+		revisionRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	})
+
+	t.Run("matching version updates the record", func(t *testing.T) {
+		dynamicQuery := new(mocks.MockDynamicQueryExecutor)
+		dynamicQuery.On("GetRecordByID", ctx, "app_data_1", fields, uint64(1)).Return(&models.RecordResponse{ID: 1, Data: models.RecordData{"name": "Original"}, CreatedBy: 1, Version: 100}, nil).Once()
+		dynamicQuery.On("UpdateRecordIfVersion", ctx, "app_data_1", uint64(1), int64(100), models.RecordData{"name": "Mine"}).Return(true, nil)
+		dynamicQuery.On("GetRecordByID", ctx, "app_data_1", fields, uint64(1)).Return(&models.RecordResponse{ID: 1, Data: models.RecordData{"name": "Mine"}, CreatedBy: 1, Version: 300}, nil).Once()
+		revisionRepo := new(mocks.MockRecordRevisionRepository)
+		revisionRepo.On("Create", ctx, mock.Anything).Return(nil)
+		service := newService(dynamicQuery, revisionRepo)
+
+		resp, err := service.UpdateRecord(ctx, 1, 1, 100, req)
+		require.NoError(t, err)
+		assert.Equal(t, int64(300), resp.Version)
+		dynamicQuery.AssertNotCalled(t, "UpdateRecord", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("stale version is not deleted", func(t *testing.T) {
+		dynamicQuery := new(mocks.MockDynamicQueryExecutor)
+		dynamicQuery.On("GetRecordByID", ctx, "app_data_1", fields, uint64(1)).Return(&models.RecordResponse{ID: 1, Data: models.RecordData{"name": "Theirs"}, CreatedBy: 1, Version: 200}, nil)
+		service := newService(dynamicQuery, new(mocks.MockRecordRevisionRepository))
+
+		err := service.DeleteRecord(ctx, 1, 1, 100)
+		assert.ErrorIs(t, err, services.ErrVersionConflict)
+		dynamicQuery.AssertNotCalled(t, "TrashRecords", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+		dynamicQuery.AssertNotCalled(t, "TrashRecordIfVersion", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("update between read and delete is detected", func(t *testing.T) {
+		dynamicQuery := new(mocks.MockDynamicQueryExecutor)
+		dynamicQuery.On("GetRecordByID", ctx, "app_data_1", fields, uint64(1)).Return(&models.RecordResponse{ID: 1, Data: models.RecordData{"name": "Original"}, CreatedBy: 1, Version: 100}, nil).Once()
+		dynamicQuery.On("TrashRecordIfVersion", ctx, "app_data_1", uint64(1), int64(100), uint64(0)).Return(false, nil)
+		dynamicQuery.On("GetRecordByID", ctx, "app_data_1", fields, uint64(1)).Return(&models.RecordResponse{ID: 1, Data: models.RecordData{"name": "Theirs"}, CreatedBy: 1, Version: 300}, nil).Once()
+		revisionRepo := new(mocks.MockRecordRevisionRepository)
+		service := newService(dynamicQuery, revisionRepo)
+
+		err := service.DeleteRecord(ctx, 1, 1, 100)
+		var conflict *services.VersionConflictError
+		require.ErrorAs(t, err, &conflict)
+		assert.Equal(t, int64(300), conflict.Version)
+		revisionRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	})
+}
+
 func TestRecordService_BulkCreateRecords(t *testing.T) {
-	ctx := context.Background()
+	ctx := systemContext()
 
 	t.Run("successful bulk creation", func(t *testing.T) {
 		mockAppRepo := new(mocks.MockAppRepository)
@@ -374,7 +725,12 @@ func TestRecordService_BulkCreateRecords(t *testing.T) {
 		mockDynamicQuery.On("GetRecordByID", ctx, "app_data_1", fields, uint64(1)).Return(&models.RecordResponse{ID: 1, Data: models.RecordData{"name": "R1"}}, nil)
 		mockDynamicQuery.On("GetRecordByID", ctx, "app_data_1", fields, uint64(2)).Return(&models.RecordResponse{ID: 2, Data: models.RecordData{"name": "R2"}}, nil)
 
-		service := services.NewRecordService(mockAppRepo, mockFieldRepo, mockDynamicQuery, mockDSRepo, mockExternalQuery)
+		mockRevisionRepo := new(mocks.MockRecordRevisionRepository)
+		mockRevisionRepo.On("CreateBatch", ctx, mock.MatchedBy(func(revs []models.RecordRevision) bool {
+			return len(revs) == 2 && revs[0].RecordID == 1 && revs[1].RecordID == 2
+		})).Return(nil)
+
+		service := services.NewRecordService(mockAppRepo, mockFieldRepo, mockDynamicQuery, mockDSRepo, mockExternalQuery, newTestPermissionService(mockAppRepo), mockRevisionRepo, newTestTransactor(), newTestWebhookPublisher(), newTestAutomationRunner(), newTestAttachmentManager())
 
 		req := &models.BulkCreateRecordRequest{
 			Records: []models.RecordData{
@@ -390,11 +746,83 @@ func TestRecordService_BulkCreateRecords(t *testing.T) {
 		mockAppRepo.AssertExpectations(t)
 		mockFieldRepo.AssertExpectations(t)
 		mockDynamicQuery.AssertExpectations(t)
+		mockRevisionRepo.AssertExpectations(t)
 	})
+
+	t.Run("failure partway aborts the whole transaction", func(t *testing.T) {
+		mockAppRepo := new(mocks.MockAppRepository)
+		mockFieldRepo := new(mocks.MockFieldRepository)
+		mockDynamicQuery := new(mocks.MockDynamicQueryExecutor)
+		mockRevisionRepo := new(mocks.MockRecordRevisionRepository)
+		mockPublisher := new(mocks.MockWebhookPublisher)
+		mockTransactor := new(mocks.MockTransactor)
+		mockRunner := new(mocks.MockAutomationRunner)
+
+		app := &models.App{ID: 1, TableName: "app_data_1"}
+		fields := []models.AppField{
+			{ID: 1, FieldCode: "name", FieldName: "Name", FieldType: "TEXT"},
+		}
+
+		mockAppRepo.On("GetByID", ctx, uint64(1)).Return(app, nil)
+		mockFieldRepo.On("GetByAppID", ctx, uint64(1)).Return(fields, nil)
+		mockTransactor.On("RunInTx", ctx).Return(nil).Once()
+		mockDynamicQuery.On("InsertRecord", ctx, "app_data_1", mock.AnythingOfType("models.RecordData"), uint64(1)).Return(uint64(1), nil).Once()
+		mockDynamicQuery.On("InsertRecord", ctx, "app_data_1", mock.AnythingOfType("models.RecordData"), uint64(1)).Return(uint64(0), errors.New("connection reset")).Once()
+		mockDynamicQuery.On("GetRecordByID", ctx, "app_data_1", fields, uint64(1)).Return(&models.RecordResponse{ID: 1, Data: models.RecordData{"name": "R1"}}, nil)
+
+		service := services.NewRecordService(mockAppRepo, mockFieldRepo, mockDynamicQuery, new(mocks.MockDataSourceRepository), new(mocks.MockExternalQueryExecutor), newTestPermissionService(mockAppRepo), mockRevisionRepo, mockTransactor, mockPublisher, mockRunner, newTestAttachmentManager())
+
+		_, err := service.BulkCreateRecords(ctx, 1, 1, &models.BulkCreateRecordRequest{
+			Records: []models.RecordData{{"name": "R1"}, {"name": "R2"}},
+		})
+		require.Error(t, err)
+
+		mockTransactor.AssertExpectations(t)
+		mockRevisionRepo.AssertNotCalled(t, "CreateBatch", mock.Anything, mock.Anything)
+		mockPublisher.AssertNotCalled(t, "Publish", mock.Anything, mock.Anything)
+		mockRunner.AssertNotCalled(t, "Run", mock.Anything, mock.Anything)
+	})
+}
+
+func TestRecordService_BulkCreateRecords_ValidationError(t *testing.T) {
+	ctx := systemContext()
+	mockAppRepo := new(mocks.MockAppRepository)
+	mockFieldRepo := new(mocks.MockFieldRepository)
+	mockDynamicQuery := new(mocks.MockDynamicQueryExecutor)
+	mockDSRepo := new(mocks.MockDataSourceRepository)
+	mockExternalQuery := new(mocks.MockExternalQueryExecutor)
+
+	app := &models.App{ID: 1, TableName: "app_data_1"}
+	fields := []models.AppField{
+		{ID: 1, FieldCode: "amount", FieldName: "Amount", FieldType: "number"},
+	}
+
+	mockAppRepo.On("GetByID", ctx, uint64(1)).Return(app, nil)
+	mockFieldRepo.On("GetByAppID", ctx, uint64(1)).Return(fields, nil)
+
+	service := services.NewRecordService(mockAppRepo, mockFieldRepo, mockDynamicQuery, mockDSRepo, mockExternalQuery, newTestPermissionService(mockAppRepo), new(mocks.MockRecordRevisionRepository), newTestTransactor(), newTestWebhookPublisher(), newTestAutomationRunner(), newTestAttachmentManager())
+
+	req := &models.BulkCreateRecordRequest{
+		Records: []models.RecordData{
+			{"amount": float64(1)},
+			{"amount": "abc"},
+		},
+	}
+
+	_, err := service.BulkCreateRecords(ctx, 1, 1, req)
+
+	var vErr *services.RecordValidationError
+	require.ErrorAs(t, err, &vErr)
+	require.Len(t, vErr.RecordErrors, 1)
+	assert.Equal(t, 1, vErr.RecordErrors[0].Index)
+	assert.Contains(t, vErr.RecordErrors[0].Errors, "amount")
+
+	// 1件でも不正があれば挿入しない
+	mockDynamicQuery.AssertNotCalled(t, "InsertRecord", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
 }
 
 func TestRecordService_BulkDeleteRecords(t *testing.T) {
-	ctx := context.Background()
+	ctx := systemContext()
 
 	t.Run("successful bulk delete", func(t *testing.T) {
 		mockAppRepo := new(mocks.MockAppRepository)
@@ -409,10 +837,21 @@ func TestRecordService_BulkDeleteRecords(t *testing.T) {
 			IsExternal: false,
 		}
 
+		fields := []models.AppField{}
+
 		mockAppRepo.On("GetByID", ctx, uint64(1)).Return(app, nil)
-		mockDynamicQuery.On("DeleteRecords", ctx, "app_data_1", []uint64{1, 2, 3}).Return(nil)
+		mockFieldRepo.On("GetByAppID", ctx, uint64(1)).Return(fields, nil)
+		for _, id := range []uint64{1, 2, 3} {
+			mockDynamicQuery.On("GetRecordByID", ctx, "app_data_1", fields, id).Return(&models.RecordResponse{ID: id, Data: models.RecordData{}}, nil)
+		}
+		mockDynamicQuery.On("TrashRecords", ctx, "app_data_1", []uint64{1, 2, 3}, uint64(0)).Return(nil)
+
+		mockRevisionRepo := new(mocks.MockRecordRevisionRepository)
+		mockRevisionRepo.On("CreateBatch", ctx, mock.MatchedBy(func(revs []models.RecordRevision) bool {
+			return len(revs) == 3 && revs[2].Action == models.RevisionActionDelete
+		})).Return(nil)
 
-		service := services.NewRecordService(mockAppRepo, mockFieldRepo, mockDynamicQuery, mockDSRepo, mockExternalQuery)
+		service := services.NewRecordService(mockAppRepo, mockFieldRepo, mockDynamicQuery, mockDSRepo, mockExternalQuery, newTestPermissionService(mockAppRepo), mockRevisionRepo, newTestTransactor(), newTestWebhookPublisher(), newTestAutomationRunner(), newTestAttachmentManager())
 
 		req := &models.BulkDeleteRecordRequest{
 			IDs: []uint64{1, 2, 3},
@@ -423,6 +862,7 @@ func TestRecordService_BulkDeleteRecords(t *testing.T) {
 
 		mockAppRepo.AssertExpectations(t)
 		mockDynamicQuery.AssertExpectations(t)
+		mockRevisionRepo.AssertExpectations(t)
 	})
 
 	t.Run("external app is read only", func(t *testing.T) {
@@ -440,7 +880,7 @@ func TestRecordService_BulkDeleteRecords(t *testing.T) {
 
 		mockAppRepo.On("GetByID", ctx, uint64(1)).Return(app, nil)
 
-		service := services.NewRecordService(mockAppRepo, mockFieldRepo, mockDynamicQuery, mockDSRepo, mockExternalQuery)
+		service := services.NewRecordService(mockAppRepo, mockFieldRepo, mockDynamicQuery, mockDSRepo, mockExternalQuery, newTestPermissionService(mockAppRepo), new(mocks.MockRecordRevisionRepository), newTestTransactor(), newTestWebhookPublisher(), newTestAutomationRunner(), newTestAttachmentManager())
 
 		req := &models.BulkDeleteRecordRequest{
 			IDs: []uint64{1, 2, 3},
@@ -462,7 +902,7 @@ func TestRecordService_BulkDeleteRecords(t *testing.T) {
 
 		mockAppRepo.On("GetByID", ctx, uint64(999)).Return(nil, nil)
 
-		service := services.NewRecordService(mockAppRepo, mockFieldRepo, mockDynamicQuery, mockDSRepo, mockExternalQuery)
+		service := services.NewRecordService(mockAppRepo, mockFieldRepo, mockDynamicQuery, mockDSRepo, mockExternalQuery, newTestPermissionService(mockAppRepo), new(mocks.MockRecordRevisionRepository), newTestTransactor(), newTestWebhookPublisher(), newTestAutomationRunner(), newTestAttachmentManager())
 
 		req := &models.BulkDeleteRecordRequest{
 			IDs: []uint64{1, 2, 3},
@@ -476,7 +916,7 @@ func TestRecordService_BulkDeleteRecords(t *testing.T) {
 }
 
 func TestRecordService_CreateRecord_AppNotFound(t *testing.T) {
-	ctx := context.Background()
+	ctx := systemContext()
 
 	mockAppRepo := new(mocks.MockAppRepository)
 	mockFieldRepo := new(mocks.MockFieldRepository)
@@ -486,7 +926,7 @@ func TestRecordService_CreateRecord_AppNotFound(t *testing.T) {
 
 	mockAppRepo.On("GetByID", ctx, uint64(999)).Return(nil, nil)
 
-	service := services.NewRecordService(mockAppRepo, mockFieldRepo, mockDynamicQuery, mockDSRepo, mockExternalQuery)
+	service := services.NewRecordService(mockAppRepo, mockFieldRepo, mockDynamicQuery, mockDSRepo, mockExternalQuery, newTestPermissionService(mockAppRepo), new(mocks.MockRecordRevisionRepository), newTestTransactor(), newTestWebhookPublisher(), newTestAutomationRunner(), newTestAttachmentManager())
 
 	req := &models.CreateRecordRequest{
 		Data: models.RecordData{"name": "Test"},
@@ -499,7 +939,7 @@ func TestRecordService_CreateRecord_AppNotFound(t *testing.T) {
 }
 
 func TestRecordService_UpdateRecord_AppNotFound(t *testing.T) {
-	ctx := context.Background()
+	ctx := systemContext()
 
 	mockAppRepo := new(mocks.MockAppRepository)
 	mockFieldRepo := new(mocks.MockFieldRepository)
@@ -509,20 +949,20 @@ func TestRecordService_UpdateRecord_AppNotFound(t *testing.T) {
 
 	mockAppRepo.On("GetByID", ctx, uint64(999)).Return(nil, nil)
 
-	service := services.NewRecordService(mockAppRepo, mockFieldRepo, mockDynamicQuery, mockDSRepo, mockExternalQuery)
+	service := services.NewRecordService(mockAppRepo, mockFieldRepo, mockDynamicQuery, mockDSRepo, mockExternalQuery, newTestPermissionService(mockAppRepo), new(mocks.MockRecordRevisionRepository), newTestTransactor(), newTestWebhookPublisher(), newTestAutomationRunner(), newTestAttachmentManager())
 
 	req := &models.UpdateRecordRequest{
 		Data: models.RecordData{"name": "Test"},
 	}
 
-	_, err := service.UpdateRecord(ctx, 999, 1, req)
+	_, err := service.UpdateRecord(ctx, 999, 1, 0, req)
 	assert.ErrorIs(t, err, services.ErrAppNotFound)
 
 	mockAppRepo.AssertExpectations(t)
 }
 
 func TestRecordService_DeleteRecord_AppNotFound(t *testing.T) {
-	ctx := context.Background()
+	ctx := systemContext()
 
 	mockAppRepo := new(mocks.MockAppRepository)
 	mockFieldRepo := new(mocks.MockFieldRepository)
@@ -532,16 +972,16 @@ func TestRecordService_DeleteRecord_AppNotFound(t *testing.T) {
 
 	mockAppRepo.On("GetByID", ctx, uint64(999)).Return(nil, nil)
 
-	service := services.NewRecordService(mockAppRepo, mockFieldRepo, mockDynamicQuery, mockDSRepo, mockExternalQuery)
+	service := services.NewRecordService(mockAppRepo, mockFieldRepo, mockDynamicQuery, mockDSRepo, mockExternalQuery, newTestPermissionService(mockAppRepo), new(mocks.MockRecordRevisionRepository), newTestTransactor(), newTestWebhookPublisher(), newTestAutomationRunner(), newTestAttachmentManager())
 
-	err := service.DeleteRecord(ctx, 999, 1)
+	err := service.DeleteRecord(ctx, 999, 1, 0)
 	assert.ErrorIs(t, err, services.ErrAppNotFound)
 
 	mockAppRepo.AssertExpectations(t)
 }
 
 func TestRecordService_GetRecord_AppNotFound(t *testing.T) {
-	ctx := context.Background()
+	ctx := systemContext()
 
 	mockAppRepo := new(mocks.MockAppRepository)
 	mockFieldRepo := new(mocks.MockFieldRepository)
@@ -551,7 +991,7 @@ func TestRecordService_GetRecord_AppNotFound(t *testing.T) {
 
 	mockAppRepo.On("GetByID", ctx, uint64(999)).Return(nil, nil)
 
-	service := services.NewRecordService(mockAppRepo, mockFieldRepo, mockDynamicQuery, mockDSRepo, mockExternalQuery)
+	service := services.NewRecordService(mockAppRepo, mockFieldRepo, mockDynamicQuery, mockDSRepo, mockExternalQuery, newTestPermissionService(mockAppRepo), new(mocks.MockRecordRevisionRepository), newTestTransactor(), newTestWebhookPublisher(), newTestAutomationRunner(), newTestAttachmentManager())
 
 	_, err := service.GetRecord(ctx, 999, 1)
 	assert.ErrorIs(t, err, services.ErrAppNotFound)
@@ -560,7 +1000,7 @@ func TestRecordService_GetRecord_AppNotFound(t *testing.T) {
 }
 
 func TestRecordService_BulkCreateRecords_AppNotFound(t *testing.T) {
-	ctx := context.Background()
+	ctx := systemContext()
 
 	mockAppRepo := new(mocks.MockAppRepository)
 	mockFieldRepo := new(mocks.MockFieldRepository)
@@ -570,7 +1010,7 @@ func TestRecordService_BulkCreateRecords_AppNotFound(t *testing.T) {
 
 	mockAppRepo.On("GetByID", ctx, uint64(999)).Return(nil, nil)
 
-	service := services.NewRecordService(mockAppRepo, mockFieldRepo, mockDynamicQuery, mockDSRepo, mockExternalQuery)
+	service := services.NewRecordService(mockAppRepo, mockFieldRepo, mockDynamicQuery, mockDSRepo, mockExternalQuery, newTestPermissionService(mockAppRepo), new(mocks.MockRecordRevisionRepository), newTestTransactor(), newTestWebhookPublisher(), newTestAutomationRunner(), newTestAttachmentManager())
 
 	req := &models.BulkCreateRecordRequest{
 		Records: []models.RecordData{{"name": "R1"}},
@@ -581,3 +1021,307 @@ func TestRecordService_BulkCreateRecords_AppNotFound(t *testing.T) {
 
 	mockAppRepo.AssertExpectations(t)
 }
+
+func TestRecordService_OwnRecordsOnly(t *testing.T) {
+	ctx := systemContext()
+	app := &models.App{ID: 1, TableName: "app_data_1"}
+	fields := []models.AppField{
+		{ID: 1, FieldCode: "name", FieldName: "Name", FieldType: "text"},
+	}
+	access := &models.AppAccess{UserID: 2, Role: models.AppRoleEditor, OwnRecordsOnly: true}
+
+	t.Run("get records is filtered by creator", func(t *testing.T) {
+		mockAppRepo := new(mocks.MockAppRepository)
+		mockFieldRepo := new(mocks.MockFieldRepository)
+		mockDynamicQuery := new(mocks.MockDynamicQueryExecutor)
+		mockPermissions := new(mocks.MockPermissionService)
+
+		mockPermissions.On("AuthorizeApp", ctx, uint64(1), models.AppRoleViewer).Return(app, access, nil)
+		mockFieldRepo.On("GetByAppID", ctx, uint64(1)).Return(fields, nil)
+		mockDynamicQuery.On("GetRecords", ctx, "app_data_1", fields, mock.MatchedBy(func(opts repositories.RecordQueryOptions) bool {
+			return len(opts.Filters) == 1 && opts.Filters[0].Field == "created_by" && opts.Filters[0].Value == "2"
+		})).Return([]models.RecordResponse{}, int64(0), nil)
+
+		service := services.NewRecordService(mockAppRepo, mockFieldRepo, mockDynamicQuery, new(mocks.MockDataSourceRepository), new(mocks.MockExternalQueryExecutor), mockPermissions, new(mocks.MockRecordRevisionRepository), newTestTransactor(), newTestWebhookPublisher(), newTestAutomationRunner(), newTestAttachmentManager())
+
+		_, err := service.GetRecords(ctx, 1, repositories.RecordQueryOptions{Page: 1, Limit: 10})
+		require.NoError(t, err)
+
+		mockDynamicQuery.AssertExpectations(t)
+	})
+
+	t.Run("record of another user is hidden", func(t *testing.T) {
+		mockAppRepo := new(mocks.MockAppRepository)
+		mockFieldRepo := new(mocks.MockFieldRepository)
+		mockDynamicQuery := new(mocks.MockDynamicQueryExecutor)
+		mockPermissions := new(mocks.MockPermissionService)
+
+		mockPermissions.On("AuthorizeApp", ctx, uint64(1), models.AppRoleViewer).Return(app, access, nil)
+		mockFieldRepo.On("GetByAppID", ctx, uint64(1)).Return(fields, nil)
+		mockDynamicQuery.On("GetRecordByID", ctx, "app_data_1", fields, uint64(5)).Return(&models.RecordResponse{ID: 5, CreatedBy: 3}, nil)
+
+		service := services.NewRecordService(mockAppRepo, mockFieldRepo, mockDynamicQuery, new(mocks.MockDataSourceRepository), new(mocks.MockExternalQueryExecutor), mockPermissions, new(mocks.MockRecordRevisionRepository), newTestTransactor(), newTestWebhookPublisher(), newTestAutomationRunner(), newTestAttachmentManager())
+
+		_, err := service.GetRecord(ctx, 1, 5)
+		assert.ErrorIs(t, err, services.ErrRecordNotFound)
+	})
+
+	t.Run("cannot update record of another user", func(t *testing.T) {
+		mockAppRepo := new(mocks.MockAppRepository)
+		mockFieldRepo := new(mocks.MockFieldRepository)
+		mockDynamicQuery := new(mocks.MockDynamicQueryExecutor)
+		mockPermissions := new(mocks.MockPermissionService)
+
+		mockPermissions.On("AuthorizeApp", ctx, uint64(1), models.AppRoleEditor).Return(app, access, nil)
+		mockFieldRepo.On("GetByAppID", ctx, uint64(1)).Return(fields, nil)
+		mockDynamicQuery.On("GetRecordByID", ctx, "app_data_1", fields, uint64(5)).Return(&models.RecordResponse{ID: 5, CreatedBy: 3}, nil)
+
+		service := services.NewRecordService(mockAppRepo, mockFieldRepo, mockDynamicQuery, new(mocks.MockDataSourceRepository), new(mocks.MockExternalQueryExecutor), mockPermissions, new(mocks.MockRecordRevisionRepository), newTestTransactor(), newTestWebhookPublisher(), newTestAutomationRunner(), newTestAttachmentManager())
+
+		_, err := service.UpdateRecord(ctx, 1, 5, 0, &models.UpdateRecordRequest{Data: models.RecordData{"name": "x"}})
+		assert.ErrorIs(t, err, services.ErrRecordNotFound)
+		mockDynamicQuery.AssertNotCalled(t, "UpdateRecord", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("bulk delete stops at record of another user", func(t *testing.T) {
+		mockAppRepo := new(mocks.MockAppRepository)
+		mockFieldRepo := new(mocks.MockFieldRepository)
+		mockDynamicQuery := new(mocks.MockDynamicQueryExecutor)
+		mockPermissions := new(mocks.MockPermissionService)
+
+		mockPermissions.On("AuthorizeApp", ctx, uint64(1), models.AppRoleEditor).Return(app, access, nil)
+		mockFieldRepo.On("GetByAppID", ctx, uint64(1)).Return(fields, nil)
+		mockDynamicQuery.On("GetRecordByID", ctx, "app_data_1", fields, uint64(5)).Return(&models.RecordResponse{ID: 5, CreatedBy: 2}, nil)
+		mockDynamicQuery.On("GetRecordByID", ctx, "app_data_1", fields, uint64(6)).Return(&models.RecordResponse{ID: 6, CreatedBy: 3}, nil)
+
+		service := services.NewRecordService(mockAppRepo, mockFieldRepo, mockDynamicQuery, new(mocks.MockDataSourceRepository), new(mocks.MockExternalQueryExecutor), mockPermissions, new(mocks.MockRecordRevisionRepository), newTestTransactor(), newTestWebhookPublisher(), newTestAutomationRunner(), newTestAttachmentManager())
+
+		err := service.BulkDeleteRecords(ctx, 1, &models.BulkDeleteRecordRequest{IDs: []uint64{5, 6}})
+		assert.ErrorIs(t, err, services.ErrRecordNotFound)
+		mockDynamicQuery.AssertNotCalled(t, "TrashRecords", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestRecordService_CreateRecord_PermissionDenied(t *testing.T) {
+	ctx := context.Background()
+
+	mockAppRepo := new(mocks.MockAppRepository)
+	mockPermissions := new(mocks.MockPermissionService)
+
+	mockPermissions.On("AuthorizeApp", ctx, uint64(1), models.AppRoleEditor).Return(nil, nil, services.ErrPermissionDenied)
+
+	service := services.NewRecordService(mockAppRepo, new(mocks.MockFieldRepository), new(mocks.MockDynamicQueryExecutor), new(mocks.MockDataSourceRepository), new(mocks.MockExternalQueryExecutor), mockPermissions, new(mocks.MockRecordRevisionRepository), newTestTransactor(), newTestWebhookPublisher(), newTestAutomationRunner(), newTestAttachmentManager())
+
+	_, err := service.CreateRecord(ctx, 1, 2, &models.CreateRecordRequest{Data: models.RecordData{"name": "x"}})
+	assert.ErrorIs(t, err, services.ErrPermissionDenied)
+}
+
+func TestRecordService_GetRecordHistory(t *testing.T) {
+	ctx := systemContext()
+	app := &models.App{ID: 1, TableName: "app_data_1"}
+
+	t.Run("successful get history", func(t *testing.T) {
+		mockAppRepo := new(mocks.MockAppRepository)
+		mockRevisionRepo := new(mocks.MockRecordRevisionRepository)
+
+		actorID := uint64(1)
+		revisions := []models.RecordRevision{
+			{ID: 2, AppID: 1, RecordID: 5, Action: models.RevisionActionUpdate, ChangedBy: &actorID, Actor: &models.User{ID: 1, Name: "Admin"}},
+			{ID: 1, AppID: 1, RecordID: 5, Action: models.RevisionActionCreate, ChangedBy: &actorID},
+		}
+
+		mockAppRepo.On("GetByID", ctx, uint64(1)).Return(app, nil)
+		mockRevisionRepo.On("GetByRecordID", ctx, uint64(1), uint64(5), 1, 20).Return(revisions, int64(2), nil)
+
+		service := services.NewRecordService(mockAppRepo, new(mocks.MockFieldRepository), new(mocks.MockDynamicQueryExecutor), new(mocks.MockDataSourceRepository), new(mocks.MockExternalQueryExecutor), newTestPermissionService(mockAppRepo), mockRevisionRepo, newTestTransactor(), newTestWebhookPublisher(), newTestAutomationRunner(), newTestAttachmentManager())
+
+		resp, err := service.GetRecordHistory(ctx, 1, 5, 1, 20)
+		require.NoError(t, err)
+		require.Len(t, resp.Revisions, 2)
+		assert.Equal(t, "Admin", resp.Revisions[0].ChangedByName)
+		assert.Equal(t, int64(2), resp.Pagination.Total)
+	})
+
+	t.Run("history of another user's record is hidden", func(t *testing.T) {
+		mockAppRepo := new(mocks.MockAppRepository)
+		mockDynamicQuery := new(mocks.MockDynamicQueryExecutor)
+		mockPermissions := new(mocks.MockPermissionService)
+		mockRevisionRepo := new(mocks.MockRecordRevisionRepository)
+
+		access := &models.AppAccess{UserID: 2, Role: models.AppRoleViewer, OwnRecordsOnly: true}
+		mockPermissions.On("AuthorizeApp", ctx, uint64(1), models.AppRoleViewer).Return(app, access, nil)
+		mockDynamicQuery.On("GetRecordByID", ctx, "app_data_1", []models.AppField(nil), uint64(5)).Return(&models.RecordResponse{ID: 5, CreatedBy: 3}, nil)
+
+		service := services.NewRecordService(mockAppRepo, new(mocks.MockFieldRepository), mockDynamicQuery, new(mocks.MockDataSourceRepository), new(mocks.MockExternalQueryExecutor), mockPermissions, mockRevisionRepo, newTestTransactor(), newTestWebhookPublisher(), newTestAutomationRunner(), newTestAttachmentManager())
+
+		_, err := service.GetRecordHistory(ctx, 1, 5, 1, 20)
+		assert.ErrorIs(t, err, services.ErrRecordNotFound)
+		mockRevisionRepo.AssertNotCalled(t, "GetByRecordID", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestRecordService_RevertRecord(t *testing.T) {
+	ctx := systemContext()
+	app := &models.App{ID: 1, TableName: "app_data_1"}
+	fields := []models.AppField{
+		{ID: 1, FieldCode: "name", FieldName: "Name", FieldType: "text"},
+		{ID: 2, FieldCode: "amount", FieldName: "Amount", FieldType: "number"},
+	}
+
+	t.Run("successful revert", func(t *testing.T) {
+		mockAppRepo := new(mocks.MockAppRepository)
+		mockFieldRepo := new(mocks.MockFieldRepository)
+		mockDynamicQuery := new(mocks.MockDynamicQueryExecutor)
+		mockRevisionRepo := new(mocks.MockRecordRevisionRepository)
+
+		// 削除済みフィールドの値はスナップショットに残っていても復元しない
+		revision := &models.RecordRevision{
+			ID:       3,
+			AppID:    1,
+			RecordID: 5,
+			Action:   models.RevisionActionUpdate,
+			Snapshot: models.RecordData{"name": "Old", "amount": "100", "removed": "x"},
+		}
+		current := &models.RecordResponse{ID: 5, Data: models.RecordData{"name": "New", "amount": "200"}, CreatedBy: 1}
+		reverted := &models.RecordResponse{ID: 5, Data: models.RecordData{"name": "Old", "amount": "100"}, CreatedBy: 1}
+
+		mockAppRepo.On("GetByID", ctx, uint64(1)).Return(app, nil)
+		mockRevisionRepo.On("GetByID", ctx, uint64(3)).Return(revision, nil)
+		mockFieldRepo.On("GetByAppID", ctx, uint64(1)).Return(fields, nil)
+		mockDynamicQuery.On("GetRecordByID", ctx, "app_data_1", fields, uint64(5)).Return(current, nil).Once()
+		mockDynamicQuery.On("UpdateRecord", ctx, "app_data_1", uint64(5), models.RecordData{"name": "Old", "amount": float64(100)}).Return(nil)
+		mockDynamicQuery.On("GetRecordByID", ctx, "app_data_1", fields, uint64(5)).Return(reverted, nil).Once()
+		mockRevisionRepo.On("Create", ctx, mock.MatchedBy(func(rev *models.RecordRevision) bool {
+			return rev.Action == models.RevisionActionRevert && len(rev.Changes) == 2
+		})).Return(nil)
+
+		service := services.NewRecordService(mockAppRepo, mockFieldRepo, mockDynamicQuery, new(mocks.MockDataSourceRepository), new(mocks.MockExternalQueryExecutor), newTestPermissionService(mockAppRepo), mockRevisionRepo, newTestTransactor(), newTestWebhookPublisher(), newTestAutomationRunner(), newTestAttachmentManager())
+
+		resp, err := service.RevertRecord(ctx, 1, 5, 3, 0)
+		require.NoError(t, err)
+		assert.Equal(t, "Old", resp.Data["name"])
+
+		mockDynamicQuery.AssertExpectations(t)
+		mockRevisionRepo.AssertExpectations(t)
+	})
+
+	t.Run("automation failure does not fail the committed revert", func(t *testing.T) {
+		mockAppRepo := new(mocks.MockAppRepository)
+		mockFieldRepo := new(mocks.MockFieldRepository)
+		mockDynamicQuery := new(mocks.MockDynamicQueryExecutor)
+		mockRevisionRepo := new(mocks.MockRecordRevisionRepository)
+		mockTransactor := new(mocks.MockTransactor)
+		mockRunner := new(mocks.MockAutomationRunner)
+
+		revision := &models.RecordRevision{ID: 3, AppID: 1, RecordID: 5, Snapshot: models.RecordData{"name": "Old"}}
+		current := &models.RecordResponse{ID: 5, Data: models.RecordData{"name": "New"}, CreatedBy: 1}
+		reverted := &models.RecordResponse{ID: 5, Data: models.RecordData{"name": "Old"}, CreatedBy: 1}
+
+		mockAppRepo.On("GetByID", ctx, uint64(1)).Return(app, nil)
+		mockRevisionRepo.On("GetByID", ctx, uint64(3)).Return(revision, nil)
+		mockFieldRepo.On("GetByAppID", ctx, uint64(1)).Return(fields, nil)
+		mockDynamicQuery.On("GetRecordByID", ctx, "app_data_1", fields, uint64(5)).Return(current, nil).Once()
+		mockTransactor.On("RunInTx", ctx).Return(nil).Once()
+		mockDynamicQuery.On("UpdateRecord", ctx, "app_data_1", uint64(5), models.RecordData{"name": "Old"}).Return(nil)
+		mockDynamicQuery.On("GetRecordByID", ctx, "app_data_1", fields, uint64(5)).Return(reverted, nil).Once()
+		mockRevisionRepo.On("Create", ctx, mock.AnythingOfType("*models.RecordRevision")).Return(nil)
+		mockRunner.On("Run", ctx, mock.Anything).Return(errors.New("rule lookup failed"))
+
+		service := services.NewRecordService(mockAppRepo, mockFieldRepo, mockDynamicQuery, new(mocks.MockDataSourceRepository), new(mocks.MockExternalQueryExecutor), newTestPermissionService(mockAppRepo), mockRevisionRepo, mockTransactor, newTestWebhookPublisher(), mockRunner, newTestAttachmentManager())
+
+		resp, err := service.RevertRecord(ctx, 1, 5, 3, 0)
+		require.NoError(t, err)
+		assert.Equal(t, "Old", resp.Data["name"])
+
+		mockTransactor.AssertExpectations(t)
+		mockRunner.AssertExpectations(t)
+	})
+
+	t.Run("stale version is not reverted", func(t *testing.T) {
+		mockAppRepo := new(mocks.MockAppRepository)
+		mockFieldRepo := new(mocks.MockFieldRepository)
+		mockDynamicQuery := new(mocks.MockDynamicQueryExecutor)
+		mockRevisionRepo := new(mocks.MockRecordRevisionRepository)
+
+		mockAppRepo.On("GetByID", ctx, uint64(1)).Return(app, nil)
+		mockRevisionRepo.On("GetByID", ctx, uint64(3)).Return(&models.RecordRevision{ID: 3, AppID: 1, RecordID: 5, Snapshot: models.RecordData{"name": "Old"}}, nil)
+		mockFieldRepo.On("GetByAppID", ctx, uint64(1)).Return(fields, nil)
+		mockDynamicQuery.On("GetRecordByID", ctx, "app_data_1", fields, uint64(5)).Return(&models.RecordResponse{ID: 5, Data: models.RecordData{"name": "Theirs"}, CreatedBy: 1, Version: 200}, nil)
+
+		service := services.NewRecordService(mockAppRepo, mockFieldRepo, mockDynamicQuery, new(mocks.MockDataSourceRepository), new(mocks.MockExternalQueryExecutor), newTestPermissionService(mockAppRepo), mockRevisionRepo, newTestTransactor(), newTestWebhookPublisher(), newTestAutomationRunner(), newTestAttachmentManager())
+
+		_, err := service.RevertRecord(ctx, 1, 5, 3, 100)
+		var conflict *services.VersionConflictError
+		require.ErrorAs(t, err, &conflict)
+		assert.Equal(t, int64(200), conflict.Version)
+		mockDynamicQuery.AssertNotCalled(t, "UpdateRecordIfVersion", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+		mockDynamicQuery.AssertNotCalled(t, "UpdateRecord", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("update between read and revert is detected", func(t *testing.T) {
+		mockAppRepo := new(mocks.MockAppRepository)
+		mockFieldRepo := new(mocks.MockFieldRepository)
+		mockDynamicQuery := new(mocks.MockDynamicQueryExecutor)
+		mockRevisionRepo := new(mocks.MockRecordRevisionRepository)
+		mockRunner := new(mocks.MockAutomationRunner)
+
+		mockAppRepo.On("GetByID", ctx, uint64(1)).Return(app, nil)
+		mockRevisionRepo.On("GetByID", ctx, uint64(3)).Return(&models.RecordRevision{ID: 3, AppID: 1, RecordID: 5, Snapshot: models.RecordData{"name": "Old"}}, nil)
+		mockFieldRepo.On("GetByAppID", ctx, uint64(1)).Return(fields, nil)
+		mockDynamicQuery.On("GetRecordByID", ctx, "app_data_1", fields, uint64(5)).Return(&models.RecordResponse{ID: 5, Data: models.RecordData{"name": "New"}, CreatedBy: 1, Version: 100}, nil).Once()
+		mockDynamicQuery.On("UpdateRecordIfVersion", ctx, "app_data_1", uint64(5), int64(100), models.RecordData{"name": "Old"}).Return(false, nil)
+		mockDynamicQuery.On("GetRecordByID", ctx, "app_data_1", fields, uint64(5)).Return(&models.RecordResponse{ID: 5, Data: models.RecordData{"name": "Theirs"}, CreatedBy: 1, Version: 300}, nil).Once()
+
+		service := services.NewRecordService(mockAppRepo, mockFieldRepo, mockDynamicQuery, new(mocks.MockDataSourceRepository), new(mocks.MockExternalQueryExecutor), newTestPermissionService(mockAppRepo), mockRevisionRepo, newTestTransactor(), newTestWebhookPublisher(), mockRunner, newTestAttachmentManager())
+
+		_, err := service.RevertRecord(ctx, 1, 5, 3, 100)
+		var conflict *services.VersionConflictError
+		require.ErrorAs(t, err, &conflict)
+		assert.Equal(t, int64(300), conflict.Version)
+		mockRevisionRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+		mockRunner.AssertNotCalled(t, "Run", mock.Anything, mock.Anything)
+	})
+
+	t.Run("revision of another record", func(t *testing.T) {
+		mockAppRepo := new(mocks.MockAppRepository)
+		mockRevisionRepo := new(mocks.MockRecordRevisionRepository)
+
+		mockAppRepo.On("GetByID", ctx, uint64(1)).Return(app, nil)
+		mockRevisionRepo.On("GetByID", ctx, uint64(3)).Return(&models.RecordRevision{ID: 3, AppID: 1, RecordID: 6}, nil)
+
+		service := services.NewRecordService(mockAppRepo, new(mocks.MockFieldRepository), new(mocks.MockDynamicQueryExecutor), new(mocks.MockDataSourceRepository), new(mocks.MockExternalQueryExecutor), newTestPermissionService(mockAppRepo), mockRevisionRepo, newTestTransactor(), newTestWebhookPublisher(), newTestAutomationRunner(), newTestAttachmentManager())
+
+		_, err := service.RevertRecord(ctx, 1, 5, 3, 0)
+		assert.ErrorIs(t, err, services.ErrRevisionNotFound)
+	})
+
+	t.Run("deleted record cannot be reverted", func(t *testing.T) {
+		mockAppRepo := new(mocks.MockAppRepository)
+		mockFieldRepo := new(mocks.MockFieldRepository)
+		mockDynamicQuery := new(mocks.MockDynamicQueryExecutor)
+		mockRevisionRepo := new(mocks.MockRecordRevisionRepository)
+
+		mockAppRepo.On("GetByID", ctx, uint64(1)).Return(app, nil)
+		mockRevisionRepo.On("GetByID", ctx, uint64(3)).Return(&models.RecordRevision{ID: 3, AppID: 1, RecordID: 5, Action: models.RevisionActionDelete}, nil)
+		mockFieldRepo.On("GetByAppID", ctx, uint64(1)).Return(fields, nil)
+		mockDynamicQuery.On("GetRecordByID", ctx, "app_data_1", fields, uint64(5)).Return(nil, nil)
+
+		service := services.NewRecordService(mockAppRepo, mockFieldRepo, mockDynamicQuery, new(mocks.MockDataSourceRepository), new(mocks.MockExternalQueryExecutor), newTestPermissionService(mockAppRepo), mockRevisionRepo, newTestTransactor(), newTestWebhookPublisher(), newTestAutomationRunner(), newTestAttachmentManager())
+
+		_, err := service.RevertRecord(ctx, 1, 5, 3, 0)
+		assert.ErrorIs(t, err, services.ErrRecordNotFound)
+		mockDynamicQuery.AssertNotCalled(t, "UpdateRecord", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("viewer cannot revert", func(t *testing.T) {
+		mockAppRepo := new(mocks.MockAppRepository)
+		mockPermissions := new(mocks.MockPermissionService)
+
+		mockPermissions.On("AuthorizeApp", ctx, uint64(1), models.AppRoleEditor).Return(nil, nil, services.ErrPermissionDenied)
+
+		service := services.NewRecordService(mockAppRepo, new(mocks.MockFieldRepository), new(mocks.MockDynamicQueryExecutor), new(mocks.MockDataSourceRepository), new(mocks.MockExternalQueryExecutor), mockPermissions, new(mocks.MockRecordRevisionRepository), newTestTransactor(), newTestWebhookPublisher(), newTestAutomationRunner(), newTestAttachmentManager())
+
+		_, err := service.RevertRecord(ctx, 1, 5, 3, 0)
+		assert.ErrorIs(t, err, services.ErrPermissionDenied)
+	})
+}
@@ -0,0 +1,440 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"nocode-app/backend/internal/models"
+)
+
+// ErrRecordValidation レコードの入力値がフィールド定義に合致しない場合のエラー
+var ErrRecordValidation = errors.New("入力内容に誤りがあります")
+
+// フィールドオプションのキー
+const (
+	OptionChoices   = "choices"
+	OptionMinLength = "min_length"
+	OptionMaxLength = "max_length"
+	OptionMin       = "min"
+	OptionMax       = "max"
+	OptionPattern   = "pattern"
+)
+
+// カラム型に由来する上限値
+const (
+	maxVarcharLength = 255
+	maxLinkLength    = 500
+	// NUMERIC(18,4) の整数部は14桁まで
+	maxNumericAbs = 1e14
+)
+
+// 日時として受け付ける入力形式
+var datetimeLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05",
+	"2006-01-02T15:04",
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+}
+
+// RecordValidationError フィールド単位の入力エラーを保持するエラー
+type RecordValidationError struct {
+	Errors       models.FieldErrors
+	RecordErrors []models.RecordFieldErrors
+}
+
+// Error errorインターフェースを実装する
+func (e *RecordValidationError) Error() string {
+	return ErrRecordValidation.Error()
+}
+
+// Unwrap errors.Is で ErrRecordValidation と判定できるようにする
+func (e *RecordValidationError) Unwrap() error {
+	return ErrRecordValidation
+}
+
+// RecordValidator アプリのフィールド定義に基づいてレコードデータを検証する構造体
+type RecordValidator struct {
+	fields   []models.AppField
+	byCode   map[string]*models.AppField
+	patterns map[string]*regexp.Regexp
+}
+
+// NewRecordValidator フィールド定義から新しいRecordValidatorを作成する
+func NewRecordValidator(fields []models.AppField) *RecordValidator {
+	v := &RecordValidator{
+		fields:   fields,
+		byCode:   make(map[string]*models.AppField, len(fields)),
+		patterns: make(map[string]*regexp.Regexp),
+	}
+	for i := range fields {
+		f := &fields[i]
+		v.byCode[f.FieldCode] = f
+		if pattern, ok := optionString(f.Options, OptionPattern); ok && pattern != "" {
+			// 不正なパターンはフィールド定義側の問題のため検証対象から外す
+			if re, err := regexp.Compile(pattern); err == nil {
+				v.patterns[f.FieldCode] = re
+			}
+		}
+	}
+	return v
+}
+
+// ValidateCreate 新規作成用にレコードを検証し、型変換済みのデータを返す
+// 必須フィールドが未指定の場合もエラーとする
+func (v *RecordValidator) ValidateCreate(data models.RecordData) (models.RecordData, models.FieldErrors) {
+	return v.validate(data, false)
+}
+
+// ValidateUpdate 部分更新用にレコードを検証し、型変換済みのデータを返す
+// 指定されたフィールドのみを検証する
+func (v *RecordValidator) ValidateUpdate(data models.RecordData) (models.RecordData, models.FieldErrors) {
+	return v.validate(data, true)
+}
+
+func (v *RecordValidator) validate(data models.RecordData, partial bool) (models.RecordData, models.FieldErrors) {
+	normalized := make(models.RecordData, len(data))
+	errs := make(models.FieldErrors)
+
+	for code, value := range data {
+		field, ok := v.byCode[code]
+		if !ok {
+			errs[code] = "存在しないフィールドです"
+			continue
+		}
+		// ルックアップや計算フィールドは入力しない値のため、送られてきても保存しない
+		if field.IsComputed() {
+			continue
+		}
+
+		converted, msg := v.validateValue(field, value)
+		if msg != "" {
+			errs[code] = msg
+			continue
+		}
+		normalized[code] = converted
+	}
+
+	if !partial {
+		for i := range v.fields {
+			f := &v.fields[i]
+			if _, ok := data[f.FieldCode]; !ok && f.Required && !f.IsComputed() {
+				errs[f.FieldCode] = "必須項目です"
+			}
+		}
+	}
+
+	if len(errs) > 0 {
+		return nil, errs
+	}
+	return normalized, nil
+}
+
+// validateValue 単一フィールドの値を検証・変換する
+// エラーがある場合は空でないメッセージを返す
+func (v *RecordValidator) validateValue(field *models.AppField, value interface{}) (interface{}, string) {
+	if isEmptyValue(value) {
+		if field.Required {
+			return nil, "必須項目です"
+		}
+		switch models.FieldType(field.FieldType) {
+		case models.FieldTypeNumber, models.FieldTypeDate, models.FieldTypeDateTime, models.FieldTypeCheckbox, models.FieldTypeReference, models.FieldTypeAttachment:
+			// 空文字列はこれらの型のカラムに格納できないためNULLとして扱う
+			return nil, ""
+		}
+		return value, ""
+	}
+
+	switch models.FieldType(field.FieldType) {
+	case models.FieldTypeText, models.FieldTypeTextArea, models.FieldTypeLink:
+		return v.validateString(field, value)
+	case models.FieldTypeNumber:
+		return validateNumber(field, value)
+	case models.FieldTypeDate:
+		return validateDate(value)
+	case models.FieldTypeDateTime:
+		return validateDatetime(value)
+	case models.FieldTypeCheckbox:
+		return validateCheckbox(value)
+	case models.FieldTypeSelect, models.FieldTypeRadio:
+		return validateChoice(field, value)
+	case models.FieldTypeMultiSelect:
+		return validateMultiChoice(field, value)
+	case models.FieldTypeReference:
+		return validateReference(value)
+	case models.FieldTypeAttachment:
+		return validateAttachment(field, value)
+	default:
+		return value, ""
+	}
+}
+
+func (v *RecordValidator) validateString(field *models.AppField, value interface{}) (interface{}, string) {
+	var s string
+	switch val := value.(type) {
+	case string:
+		s = val
+	case float64:
+		s = strconv.FormatFloat(val, 'f', -1, 64)
+	case json.Number:
+		s = val.String()
+	case bool:
+		s = strconv.FormatBool(val)
+	default:
+		return nil, "文字列で入力してください"
+	}
+
+	length := utf8.RuneCountInString(s)
+	if limit := columnLengthLimit(models.FieldType(field.FieldType)); limit > 0 && length > limit {
+		return nil, fmt.Sprintf("%d文字以内で入力してください", limit)
+	}
+	if minLen, ok := optionNumber(field.Options, OptionMinLength); ok && float64(length) < minLen {
+		return nil, fmt.Sprintf("%s文字以上で入力してください", formatNumber(minLen))
+	}
+	if maxLen, ok := optionNumber(field.Options, OptionMaxLength); ok && float64(length) > maxLen {
+		return nil, fmt.Sprintf("%s文字以内で入力してください", formatNumber(maxLen))
+	}
+	if re, ok := v.patterns[field.FieldCode]; ok && !re.MatchString(s) {
+		return nil, "入力形式が正しくありません"
+	}
+	return s, ""
+}
+
+func validateNumber(field *models.AppField, value interface{}) (interface{}, string) {
+	var n float64
+	switch val := value.(type) {
+	case float64:
+		n = val
+	case int:
+		n = float64(val)
+	case int64:
+		n = float64(val)
+	case json.Number:
+		parsed, err := val.Float64()
+		if err != nil {
+			return nil, "数値で入力してください"
+		}
+		n = parsed
+	case string:
+		parsed, err := strconv.ParseFloat(strings.TrimSpace(val), 64)
+		if err != nil {
+			return nil, "数値で入力してください"
+		}
+		n = parsed
+	default:
+		return nil, "数値で入力してください"
+	}
+
+	if math.IsNaN(n) || math.IsInf(n, 0) || math.Abs(n) >= maxNumericAbs {
+		return nil, "数値が大きすぎます"
+	}
+	if minVal, ok := optionNumber(field.Options, OptionMin); ok && n < minVal {
+		return nil, fmt.Sprintf("%s以上の値を入力してください", formatNumber(minVal))
+	}
+	if maxVal, ok := optionNumber(field.Options, OptionMax); ok && n > maxVal {
+		return nil, fmt.Sprintf("%s以下の値を入力してください", formatNumber(maxVal))
+	}
+	return n, ""
+}
+
+func validateDate(value interface{}) (interface{}, string) {
+	s, ok := value.(string)
+	if !ok {
+		return nil, "日付で入力してください"
+	}
+	s = strings.TrimSpace(s)
+	if t, err := time.Parse("2006-01-02", s); err == nil {
+		return t.Format("2006-01-02"), ""
+	}
+	if t, err := time.Parse(time.RFC3339Nano, s); err == nil {
+		return t.Format("2006-01-02"), ""
+	}
+	return nil, "日付の形式が正しくありません（YYYY-MM-DD）"
+}
+
+func validateDatetime(value interface{}) (interface{}, string) {
+	s, ok := value.(string)
+	if !ok {
+		return nil, "日時で入力してください"
+	}
+	s = strings.TrimSpace(s)
+	for _, layout := range datetimeLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			// TIMESTAMP型はタイムゾーンを保持しないため、入力の壁時計時刻をそのまま格納する
+			return t.Format("2006-01-02T15:04:05"), ""
+		}
+	}
+	return nil, "日時の形式が正しくありません"
+}
+
+func validateCheckbox(value interface{}) (interface{}, string) {
+	switch val := value.(type) {
+	case bool:
+		return val, ""
+	case float64:
+		if val == 0 || val == 1 {
+			return val == 1, ""
+		}
+	case string:
+		if b, err := strconv.ParseBool(strings.TrimSpace(val)); err == nil {
+			return b, ""
+		}
+	}
+	return nil, "真偽値で入力してください"
+}
+
+func validateChoice(field *models.AppField, value interface{}) (interface{}, string) {
+	s, ok := value.(string)
+	if !ok {
+		return nil, "文字列で入力してください"
+	}
+	if utf8.RuneCountInString(s) > maxVarcharLength {
+		return nil, fmt.Sprintf("%d文字以内で入力してください", maxVarcharLength)
+	}
+	if choices, ok := optionChoices(field.Options); ok && !containsString(choices, s) {
+		return nil, "選択肢にない値です"
+	}
+	return s, ""
+}
+
+func validateMultiChoice(field *models.AppField, value interface{}) (interface{}, string) {
+	items, ok := value.([]interface{})
+	if !ok {
+		return nil, "配列で入力してください"
+	}
+	choices, hasChoices := optionChoices(field.Options)
+	for _, item := range items {
+		s, ok := item.(string)
+		if !ok {
+			return nil, "選択肢は文字列で指定してください"
+		}
+		if hasChoices && !containsString(choices, s) {
+			return nil, fmt.Sprintf("選択肢にない値です: %s", s)
+		}
+	}
+	return value, ""
+}
+
+// validateReference 参照先のレコードIDを検証する
+// 参照先に存在するかどうかは RecordService が確認する
+func validateReference(value interface{}) (interface{}, string) {
+	id, ok := referenceID(value)
+	if !ok {
+		return nil, "参照先のレコードIDを指定してください"
+	}
+	return id, ""
+}
+
+// validateAttachment 添付ファイルのIDの配列を検証する
+// 添付できるファイルかどうかは RecordService が確認する
+func validateAttachment(field *models.AppField, value interface{}) (interface{}, string) {
+	ids, ok := attachmentIDs(value)
+	if !ok {
+		return nil, "アップロードした添付ファイルのIDを配列で指定してください"
+	}
+	if maxFiles, ok := optionNumber(field.Options, OptionMaxFiles); ok && float64(len(ids)) > maxFiles {
+		return nil, fmt.Sprintf("添付できるファイルは%s件までです", formatNumber(maxFiles))
+	}
+	return ids, ""
+}
+
+// isEmptyValue 未入力とみなす値かどうかを判定する
+func isEmptyValue(value interface{}) bool {
+	switch val := value.(type) {
+	case nil:
+		return true
+	case string:
+		return strings.TrimSpace(val) == ""
+	case []interface{}:
+		return len(val) == 0
+	}
+	return false
+}
+
+// columnLengthLimit フィールド種類に対応するカラムの最大文字数を返す（制限なしは0）
+func columnLengthLimit(fieldType models.FieldType) int {
+	switch fieldType {
+	case models.FieldTypeText:
+		return maxVarcharLength
+	case models.FieldTypeLink:
+		return maxLinkLength
+	}
+	return 0
+}
+
+// optionString オプションから文字列値を取得する
+func optionString(options models.FieldOptions, key string) (string, bool) {
+	if options == nil {
+		return "", false
+	}
+	s, ok := options[key].(string)
+	return s, ok
+}
+
+// optionNumber オプションから数値を取得する
+// JSON由来のfloat64に加え、文字列で指定された数値も受け付ける
+func optionNumber(options models.FieldOptions, key string) (float64, bool) {
+	if options == nil {
+		return 0, false
+	}
+	switch val := options[key].(type) {
+	case float64:
+		return val, true
+	case int:
+		return float64(val), true
+	case int64:
+		return float64(val), true
+	case uint64:
+		return float64(val), true
+	case json.Number:
+		n, err := val.Float64()
+		return n, err == nil
+	case string:
+		n, err := strconv.ParseFloat(val, 64)
+		return n, err == nil
+	}
+	return 0, false
+}
+
+// optionChoices オプションから選択肢を取得する
+// 選択肢が定義されていない場合は false を返す
+func optionChoices(options models.FieldOptions) ([]string, bool) {
+	if options == nil {
+		return nil, false
+	}
+	var choices []string
+	switch val := options[OptionChoices].(type) {
+	case []interface{}:
+		for _, c := range val {
+			if s, ok := c.(string); ok {
+				choices = append(choices, s)
+			}
+		}
+	case []string:
+		choices = val
+	}
+	if len(choices) == 0 {
+		return nil, false
+	}
+	return choices, true
+}
+
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+func formatNumber(n float64) string {
+	return strconv.FormatFloat(n, 'f', -1, 64)
+}
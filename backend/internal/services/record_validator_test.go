@@ -0,0 +1,152 @@
+package services_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"nocode-app/backend/internal/models"
+	"nocode-app/backend/internal/services"
+)
+
+func validatorTestFields() []models.AppField {
+	return []models.AppField{
+		{FieldCode: "title", FieldType: "text", Required: true, Options: models.FieldOptions{"min_length": float64(2), "max_length": float64(10)}},
+		{FieldCode: "code", FieldType: "text", Options: models.FieldOptions{"pattern": "^[A-Z]{3}-[0-9]+$"}},
+		{FieldCode: "amount", FieldType: "number", Options: models.FieldOptions{"min": float64(0), "max": float64(100)}},
+		{FieldCode: "due", FieldType: "date"},
+		{FieldCode: "starts_at", FieldType: "datetime"},
+		{FieldCode: "done", FieldType: "checkbox"},
+		{FieldCode: "status", FieldType: "select", Options: models.FieldOptions{"choices": []interface{}{"open", "closed"}}},
+		{FieldCode: "priority", FieldType: "radio", Options: models.FieldOptions{"choices": []interface{}{"high", "low"}}},
+		{FieldCode: "tags", FieldType: "multiselect", Options: models.FieldOptions{"choices": []interface{}{"a", "b"}}},
+		{FieldCode: "customer", FieldType: "reference", Options: models.FieldOptions{"app_id": float64(2)}},
+		{FieldCode: "customer_name", FieldType: "lookup", Required: true, Options: models.FieldOptions{"reference_field": "customer", "lookup_field": "name"}},
+		{FieldCode: "files", FieldType: "attachment", Options: models.FieldOptions{"max_files": float64(2)}},
+	}
+}
+
+func TestRecordValidator_ValidateCreate(t *testing.T) {
+	validator := services.NewRecordValidator(validatorTestFields())
+
+	t.Run("valid data is normalized", func(t *testing.T) {
+		data, errs := validator.ValidateCreate(models.RecordData{
+			"title":     "Hello",
+			"code":      "ABC-123",
+			"amount":    "42.5",
+			"due":       "2024-03-01",
+			"starts_at": "2024-03-01T09:30",
+			"done":      "true",
+			"status":    "open",
+			"priority":  "high",
+			"tags":      []interface{}{"a", "b"},
+		})
+		require.Nil(t, errs)
+		assert.Equal(t, "Hello", data["title"])
+		assert.Equal(t, 42.5, data["amount"])
+		assert.Equal(t, "2024-03-01", data["due"])
+		assert.Equal(t, "2024-03-01T09:30:00", data["starts_at"])
+		assert.Equal(t, true, data["done"])
+		assert.Equal(t, []interface{}{"a", "b"}, data["tags"])
+	})
+
+	t.Run("missing required field", func(t *testing.T) {
+		_, errs := validator.ValidateCreate(models.RecordData{"amount": float64(1)})
+		require.NotNil(t, errs)
+		assert.Equal(t, "必須項目です", errs["title"])
+	})
+
+	t.Run("empty optional values become null", func(t *testing.T) {
+		data, errs := validator.ValidateCreate(models.RecordData{
+			"title":  "Hello",
+			"amount": "",
+			"due":    "",
+		})
+		require.Nil(t, errs)
+		assert.Nil(t, data["amount"])
+		assert.Nil(t, data["due"])
+	})
+
+	t.Run("reference is normalized and lookup is ignored", func(t *testing.T) {
+		data, errs := validator.ValidateCreate(models.RecordData{
+			"title":         "Hello",
+			"customer":      "7",
+			"customer_name": "山田商店",
+		})
+		require.Nil(t, errs)
+		assert.Equal(t, uint64(7), data["customer"])
+		assert.NotContains(t, data, "customer_name")
+	})
+
+	t.Run("attachment ids are deduplicated", func(t *testing.T) {
+		data, errs := validator.ValidateCreate(models.RecordData{
+			"title": "Hello",
+			"files": []interface{}{float64(3), "4", map[string]interface{}{"id": float64(3)}},
+		})
+		require.Nil(t, errs)
+		assert.Equal(t, []uint64{3, 4}, data["files"])
+	})
+
+	tests := []struct {
+		name  string
+		field string
+		value interface{}
+	}{
+		{"too short", "title", "a"},
+		{"too long", "title", "abcdefghijk"},
+		{"pattern mismatch", "code", "abc-1"},
+		{"not a number", "amount", "abc"},
+		{"below min", "amount", float64(-1)},
+		{"above max", "amount", float64(101)},
+		{"invalid date", "due", "2024/03/01"},
+		{"invalid datetime", "starts_at", "tomorrow"},
+		{"invalid checkbox", "done", "maybe"},
+		{"unknown select choice", "status", "pending"},
+		{"unknown radio choice", "priority", "medium"},
+		{"unknown multiselect choice", "tags", []interface{}{"a", "c"}},
+		{"multiselect not array", "tags", "a"},
+		{"invalid reference", "customer", "abc"},
+		{"non-positive reference", "customer", float64(0)},
+		{"fractional reference", "customer", float64(1.5)},
+		{"attachment not array", "files", float64(3)},
+		{"invalid attachment id", "files", []interface{}{"abc"}},
+		{"too many attachments", "files", []interface{}{float64(1), float64(2), float64(3)}},
+		{"undefined field", "unknown", "x"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, errs := validator.ValidateCreate(models.RecordData{
+				"title":  "Hello",
+				tt.field: tt.value,
+			})
+			assert.Nil(t, data)
+			require.NotNil(t, errs)
+			assert.Contains(t, errs, tt.field)
+			assert.Len(t, errs, 1)
+		})
+	}
+}
+
+func TestRecordValidator_ValidateUpdate(t *testing.T) {
+	validator := services.NewRecordValidator(validatorTestFields())
+
+	t.Run("missing required field is allowed", func(t *testing.T) {
+		data, errs := validator.ValidateUpdate(models.RecordData{"amount": float64(10)})
+		require.Nil(t, errs)
+		assert.Equal(t, float64(10), data["amount"])
+	})
+
+	t.Run("clearing required field is rejected", func(t *testing.T) {
+		_, errs := validator.ValidateUpdate(models.RecordData{"title": ""})
+		require.NotNil(t, errs)
+		assert.Equal(t, "必須項目です", errs["title"])
+	})
+}
+
+func TestRecordValidationError(t *testing.T) {
+	err := &services.RecordValidationError{Errors: models.FieldErrors{"title": "必須項目です"}}
+	assert.ErrorIs(t, err, services.ErrRecordValidation)
+	assert.Equal(t, services.ErrRecordValidation.Error(), err.Error())
+}
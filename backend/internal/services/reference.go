@@ -0,0 +1,492 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+
+	"nocode-app/backend/internal/models"
+	"nocode-app/backend/internal/repositories"
+)
+
+// 参照・ルックアップフィールドのオプションキー
+const (
+	// OptionReferenceApp 参照先アプリのID
+	OptionReferenceApp = "app_id"
+	// OptionDisplayField 参照先レコードの表示に使うフィールドコード
+	OptionDisplayField = "display_field"
+	// OptionOnDelete 参照先レコード削除時の動作
+	OptionOnDelete = "on_delete"
+	// OptionReferenceField ルックアップが経由する参照フィールドのフィールドコード
+	OptionReferenceField = "reference_field"
+	// OptionLookupField ルックアップで表示する参照先アプリのフィールドコード
+	OptionLookupField = "lookup_field"
+)
+
+// 参照関連エラー
+var (
+	ErrInvalidFieldOptions = errors.New("フィールドのオプションが正しくありません")
+	ErrFieldInUse          = errors.New("他のフィールドから使用されているため削除できません")
+	ErrAppReferenced       = errors.New("他のアプリの参照フィールドや集計フィールドから参照されているため削除できません")
+	ErrRecordReferenced    = errors.New("他のレコードから参照されているため削除できません")
+)
+
+// referenceResolver 参照・ルックアップフィールドの定義を検証する
+type referenceResolver struct {
+	appRepo     repositories.AppRepositoryInterface
+	fieldRepo   repositories.FieldRepositoryInterface
+	permissions PermissionServiceInterface
+}
+
+// resolveField 参照・ルックアップ・集計フィールドのオプションを検証し、既定値を補ったオプションを設定する
+// 参照フィールドの場合は参照先アプリを返す。siblings は同じアプリのフィールド（作成中のものを含む）
+func (r *referenceResolver) resolveField(ctx context.Context, app *models.App, field *models.AppField, siblings []models.AppField) (*models.App, error) {
+	switch models.FieldType(field.FieldType) {
+	case models.FieldTypeReference:
+		return r.resolveReference(ctx, app, field)
+	case models.FieldTypeLookup:
+		return nil, r.resolveLookup(ctx, app, field, siblings)
+	case models.FieldTypeRollup:
+		return nil, r.resolveRollup(ctx, app, field)
+	default:
+		return nil, nil
+	}
+}
+
+func (r *referenceResolver) resolveReference(ctx context.Context, app *models.App, field *models.AppField) (*models.App, error) {
+	if app.IsExternal {
+		return nil, fmt.Errorf("%w: 外部データソースのアプリには参照フィールドを作成できません", ErrInvalidFieldOptions)
+	}
+
+	targetID, ok := referenceAppID(field.Options)
+	if !ok {
+		return nil, fmt.Errorf("%w: 参照先アプリ（%s）を指定してください", ErrInvalidFieldOptions, OptionReferenceApp)
+	}
+	target, err := r.appRepo.GetByID(ctx, targetID)
+	if err != nil {
+		return nil, err
+	}
+	if target == nil {
+		return nil, fmt.Errorf("%w: 参照先アプリが見つかりません", ErrInvalidFieldOptions)
+	}
+	if target.IsExternal {
+		return nil, fmt.Errorf("%w: 外部データソースのアプリは参照先にできません", ErrInvalidFieldOptions)
+	}
+	// 閲覧できないアプリへの参照は作成させない
+	if _, err := r.permissions.CheckAppAccess(ctx, target, models.AppRoleViewer); err != nil {
+		if errors.Is(err, ErrAppNotFound) {
+			return nil, fmt.Errorf("%w: 参照先アプリが見つかりません", ErrInvalidFieldOptions)
+		}
+		return nil, err
+	}
+
+	onDelete := referenceOnDelete(field.Options)
+	if raw, ok := optionString(field.Options, OptionOnDelete); ok && raw != "" && !models.ReferenceOnDelete(raw).IsValid() {
+		return nil, fmt.Errorf("%w: %s は restrict / set_null / cascade のいずれかを指定してください", ErrInvalidFieldOptions, OptionOnDelete)
+	}
+
+	if display, ok := optionString(field.Options, OptionDisplayField); ok && display != "" {
+		targetFields, err := r.fieldRepo.GetByAppID(ctx, target.ID)
+		if err != nil {
+			return nil, err
+		}
+		if f := findField(targetFields, display); f == nil || !f.HasColumn() {
+			return nil, fmt.Errorf("%w: 表示フィールド %q は参照先アプリに存在しません", ErrInvalidFieldOptions, display)
+		}
+	}
+
+	options := copyOptions(field.Options)
+	options[OptionReferenceApp] = target.ID
+	options[OptionOnDelete] = string(onDelete)
+	field.Options = options
+	return target, nil
+}
+
+func (r *referenceResolver) resolveLookup(ctx context.Context, app *models.App, field *models.AppField, siblings []models.AppField) error {
+	if app.IsExternal {
+		return fmt.Errorf("%w: 外部データソースのアプリにはルックアップフィールドを作成できません", ErrInvalidFieldOptions)
+	}
+
+	refCode, _ := optionString(field.Options, OptionReferenceField)
+	ref := findField(siblings, refCode)
+	if ref == nil || models.FieldType(ref.FieldType) != models.FieldTypeReference {
+		return fmt.Errorf("%w: 経由する参照フィールド（%s）を指定してください", ErrInvalidFieldOptions, OptionReferenceField)
+	}
+	targetID, ok := referenceAppID(ref.Options)
+	if !ok {
+		return fmt.Errorf("%w: 参照フィールド %q の参照先アプリが正しくありません", ErrInvalidFieldOptions, refCode)
+	}
+
+	lookupCode, _ := optionString(field.Options, OptionLookupField)
+	targetFields, err := r.fieldRepo.GetByAppID(ctx, targetID)
+	if err != nil {
+		return err
+	}
+	// ルックアップのルックアップは辿らない
+	if f := findField(targetFields, lookupCode); f == nil || !f.HasColumn() {
+		return fmt.Errorf("%w: 表示するフィールド（%s）は参照先アプリのフィールドを指定してください", ErrInvalidFieldOptions, OptionLookupField)
+	}
+
+	// ルックアップは入力しないため必須にできない
+	field.Required = false
+	return nil
+}
+
+// checkFieldInUse 削除するフィールドが他のフィールドから使われていないか確認する
+// 同じアプリのルックアップが経由する参照フィールドと、他のアプリの参照フィールドの表示フィールドや
+// ルックアップで表示しているフィールド、集計フィールドが使っているフィールドは削除できない
+func (r *referenceResolver) checkFieldInUse(ctx context.Context, field *models.AppField) error {
+	if models.FieldType(field.FieldType) == models.FieldTypeReference {
+		siblings, err := r.fieldRepo.GetByAppID(ctx, field.AppID)
+		if err != nil {
+			return err
+		}
+		for i := range siblings {
+			if isLookupVia(&siblings[i], field.FieldCode) {
+				return ErrFieldInUse
+			}
+		}
+	}
+	if !field.HasColumn() {
+		return nil
+	}
+
+	referencing, err := r.fieldRepo.GetReferencingFields(ctx, field.AppID)
+	if err != nil {
+		return err
+	}
+	checked := make(map[uint64][]models.AppField)
+	for i := range referencing {
+		ref := &referencing[i]
+		if models.FieldType(ref.FieldType) == models.FieldTypeRollup {
+			if isRollupUsing(ref, field.FieldCode) {
+				return ErrFieldInUse
+			}
+			continue
+		}
+		if display, _ := optionString(ref.Options, OptionDisplayField); display == field.FieldCode {
+			return ErrFieldInUse
+		}
+		fields, ok := checked[ref.AppID]
+		if !ok {
+			if fields, err = r.fieldRepo.GetByAppID(ctx, ref.AppID); err != nil {
+				return err
+			}
+			checked[ref.AppID] = fields
+		}
+		for j := range fields {
+			lookupCode, _ := optionString(fields[j].Options, OptionLookupField)
+			if isLookupVia(&fields[j], ref.FieldCode) && lookupCode == field.FieldCode {
+				return ErrFieldInUse
+			}
+		}
+	}
+	return nil
+}
+
+// isLookupVia 指定した参照フィールドを経由するルックアップかどうかを判定する
+func isLookupVia(field *models.AppField, refCode string) bool {
+	if models.FieldType(field.FieldType) != models.FieldTypeLookup {
+		return false
+	}
+	code, _ := optionString(field.Options, OptionReferenceField)
+	return code == refCode
+}
+
+// referenceAppID 参照フィールドのオプションから参照先アプリIDを取得する
+func referenceAppID(options models.FieldOptions) (uint64, bool) {
+	n, ok := optionNumber(options, OptionReferenceApp)
+	if !ok || n < 1 || n != math.Trunc(n) {
+		return 0, false
+	}
+	return uint64(n), true
+}
+
+// referenceOnDelete 参照フィールドのオプションから参照先削除時の動作を取得する（既定はrestrict）
+func referenceOnDelete(options models.FieldOptions) models.ReferenceOnDelete {
+	if raw, ok := optionString(options, OptionOnDelete); ok && models.ReferenceOnDelete(raw).IsValid() {
+		return models.ReferenceOnDelete(raw)
+	}
+	return models.ReferenceOnDeleteRestrict
+}
+
+// referenceID 参照フィールドの値をレコードIDに変換する
+func referenceID(value interface{}) (uint64, bool) {
+	switch v := value.(type) {
+	case int64:
+		return uint64(v), v > 0
+	case int:
+		return uint64(v), v > 0
+	case uint64:
+		return v, v > 0
+	case float64:
+		if v < 1 || v != math.Trunc(v) {
+			return 0, false
+		}
+		return uint64(v), true
+	case json.Number:
+		n, err := strconv.ParseUint(v.String(), 10, 64)
+		return n, err == nil && n > 0
+	case string:
+		n, err := strconv.ParseUint(v, 10, 64)
+		return n, err == nil && n > 0
+	}
+	return 0, false
+}
+
+// findField フィールドコードでフィールドを探す
+func findField(fields []models.AppField, code string) *models.AppField {
+	if code == "" {
+		return nil
+	}
+	for i := range fields {
+		if fields[i].FieldCode == code {
+			return &fields[i]
+		}
+	}
+	return nil
+}
+
+// copyOptions フィールドオプションを複製する
+func copyOptions(options models.FieldOptions) models.FieldOptions {
+	copied := make(models.FieldOptions, len(options)+2)
+	for k, v := range options {
+		copied[k] = v
+	}
+	return copied
+}
+
+// referenceTarget 参照先アプリの情報（閲覧できない場合は app が nil）
+type referenceTarget struct {
+	app    *models.App
+	fields []models.AppField
+	access *models.AppAccess
+}
+
+// loadReferenceTarget 参照先アプリとそのフィールド、呼び出し元の権限を取得する
+// 参照先アプリが存在しない、または閲覧できない場合は空のreferenceTargetを返す
+func (s *RecordService) loadReferenceTarget(ctx context.Context, cache map[uint64]*referenceTarget, appID uint64) (*referenceTarget, error) {
+	if target, ok := cache[appID]; ok {
+		return target, nil
+	}
+
+	target := &referenceTarget{}
+	cache[appID] = target
+
+	app, err := s.appRepo.GetByID(ctx, appID)
+	if err != nil {
+		return nil, err
+	}
+	if app == nil {
+		return target, nil
+	}
+	access, err := s.permissions.CheckAppAccess(ctx, app, models.AppRoleViewer)
+	if err != nil {
+		if errors.Is(err, ErrAppNotFound) || errors.Is(err, ErrPermissionDenied) {
+			return target, nil
+		}
+		return nil, err
+	}
+	fields, err := s.fieldRepo.GetByAppID(ctx, appID)
+	if err != nil {
+		return nil, err
+	}
+
+	target.app = app
+	target.fields = fields
+	target.access = access
+	return target, nil
+}
+
+// expandReferences 参照フィールドの参照先レコードをReferencesに、ルックアップの値をDataに設定する
+// 参照先アプリを閲覧できない場合や、自分のレコードのみ閲覧可能で参照先の作成者が異なる場合は展開しない
+// 集計フィールドは子アプリを閲覧できない場合や、子アプリで自分のレコードのみ閲覧可能な場合に null とする
+func (s *RecordService) expandReferences(ctx context.Context, fields []models.AppField, records []models.RecordResponse) error {
+	lookups := make(map[string][]*models.AppField)
+	var refFields, rollups []*models.AppField
+	for i := range fields {
+		switch models.FieldType(fields[i].FieldType) {
+		case models.FieldTypeReference:
+			refFields = append(refFields, &fields[i])
+		case models.FieldTypeLookup:
+			refCode, _ := optionString(fields[i].Options, OptionReferenceField)
+			lookups[refCode] = append(lookups[refCode], &fields[i])
+		case models.FieldTypeRollup:
+			rollups = append(rollups, &fields[i])
+		}
+	}
+	if len(records) == 0 {
+		return nil
+	}
+
+	cache := make(map[uint64]*referenceTarget)
+	for _, rollup := range rollups {
+		childID, _ := referenceAppID(rollup.Options)
+		target, err := s.loadReferenceTarget(ctx, cache, childID)
+		if err != nil {
+			return err
+		}
+		// 他のユーザーのレコードを含む集計値から内容を推測させない
+		if target.app != nil && !target.access.OwnRecordsOnly {
+			continue
+		}
+		for i := range records {
+			records[i].Data[rollup.FieldCode] = nil
+		}
+	}
+
+	if len(refFields) == 0 {
+		return nil
+	}
+
+	// ルックアップの値は展開できない場合も null として返す
+	for i := range records {
+		for _, fs := range lookups {
+			for _, f := range fs {
+				records[i].Data[f.FieldCode] = nil
+			}
+		}
+	}
+
+	for _, ref := range refFields {
+		targetID, ok := referenceAppID(ref.Options)
+		if !ok {
+			continue
+		}
+		target, err := s.loadReferenceTarget(ctx, cache, targetID)
+		if err != nil {
+			return err
+		}
+		if target.app == nil {
+			continue
+		}
+
+		seen := make(map[uint64]bool)
+		var ids []uint64
+		for i := range records {
+			if id, ok := referenceID(records[i].Data[ref.FieldCode]); ok && !seen[id] {
+				seen[id] = true
+				ids = append(ids, id)
+			}
+		}
+		if len(ids) == 0 {
+			continue
+		}
+
+		referenced, err := s.dynamicQuery.GetRecordsByIDs(ctx, target.app.TableName, target.fields, ids)
+		if err != nil {
+			return err
+		}
+		byID := make(map[uint64]*models.RecordResponse, len(referenced))
+		for i := range referenced {
+			if target.access.CanAccessRecord(referenced[i].CreatedBy) {
+				byID[referenced[i].ID] = &referenced[i]
+			}
+		}
+
+		displayField, _ := optionString(ref.Options, OptionDisplayField)
+		if displayField == "" {
+			// 表示フィールドの指定がない場合は参照先アプリの先頭のフィールドを使う
+			for i := range target.fields {
+				if target.fields[i].HasColumn() {
+					displayField = target.fields[i].FieldCode
+					break
+				}
+			}
+		}
+
+		for i := range records {
+			id, ok := referenceID(records[i].Data[ref.FieldCode])
+			if !ok {
+				continue
+			}
+			rec, ok := byID[id]
+			if !ok {
+				continue
+			}
+			if records[i].References == nil {
+				records[i].References = make(map[string]*models.RecordReference)
+			}
+			records[i].References[ref.FieldCode] = &models.RecordReference{
+				AppID:    target.app.ID,
+				RecordID: rec.ID,
+				Display:  rec.Data[displayField],
+				Data:     rec.Data,
+			}
+			for _, lookup := range lookups[ref.FieldCode] {
+				lookupCode, _ := optionString(lookup.Options, OptionLookupField)
+				records[i].Data[lookup.FieldCode] = rec.Data[lookupCode]
+			}
+		}
+	}
+	return nil
+}
+
+// checkReferences 参照フィールドの値が参照先アプリに存在するレコードを指しているか確認する
+// 行の添字ごとの入力エラーを返す（エラーがなければnil）
+func (s *RecordService) checkReferences(ctx context.Context, fields []models.AppField, rows []models.RecordData) (map[int]models.FieldErrors, error) {
+	var errs map[int]models.FieldErrors
+	for i := range fields {
+		ref := &fields[i]
+		if models.FieldType(ref.FieldType) != models.FieldTypeReference {
+			continue
+		}
+
+		seen := make(map[uint64]bool)
+		var ids []uint64
+		for _, row := range rows {
+			if id, ok := referenceID(row[ref.FieldCode]); ok && !seen[id] {
+				seen[id] = true
+				ids = append(ids, id)
+			}
+		}
+		if len(ids) == 0 {
+			continue
+		}
+
+		existing := make(map[uint64]bool, len(ids))
+		if targetID, ok := referenceAppID(ref.Options); ok {
+			target, err := s.appRepo.GetByID(ctx, targetID)
+			if err != nil {
+				return nil, err
+			}
+			if target != nil {
+				records, err := s.dynamicQuery.GetRecordsByIDs(ctx, target.TableName, nil, ids)
+				if err != nil {
+					return nil, err
+				}
+				for j := range records {
+					existing[records[j].ID] = true
+				}
+			}
+		}
+
+		for j, row := range rows {
+			if id, ok := referenceID(row[ref.FieldCode]); ok && !existing[id] {
+				if errs == nil {
+					errs = make(map[int]models.FieldErrors)
+				}
+				if errs[j] == nil {
+					errs[j] = make(models.FieldErrors)
+				}
+				errs[j][ref.FieldCode] = "参照先のレコードが存在しません"
+			}
+		}
+	}
+	return errs, nil
+}
+
+// checkRecordReferences 1件のレコードの参照先を確認し、存在しない場合は入力エラーを返す
+func (s *RecordService) checkRecordReferences(ctx context.Context, fields []models.AppField, data models.RecordData) error {
+	errs, err := s.checkReferences(ctx, fields, []models.RecordData{data})
+	if err != nil {
+		return err
+	}
+	if errs != nil {
+		return &RecordValidationError{Errors: errs[0]}
+	}
+	return nil
+}
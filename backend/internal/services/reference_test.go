@@ -0,0 +1,597 @@
+package services_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"nocode-app/backend/internal/models"
+	"nocode-app/backend/internal/repositories"
+	"nocode-app/backend/internal/services"
+	"nocode-app/backend/internal/testhelpers/mocks"
+)
+
+// 参照のテストで使うアプリ構成: 注文（app 1）が顧客（app 2）を参照する
+var (
+	orderApp    = &models.App{ID: 1, TableName: "app_data_1", CreatedBy: 1}
+	customerApp = &models.App{ID: 2, TableName: "app_data_2", CreatedBy: 1}
+
+	customerFields = []models.AppField{
+		{ID: 10, AppID: 2, FieldCode: "name", FieldName: "顧客名", FieldType: "text", DisplayOrder: 1},
+		{ID: 11, AppID: 2, FieldCode: "email", FieldName: "メール", FieldType: "text", DisplayOrder: 2},
+	}
+	orderFields = []models.AppField{
+		{ID: 1, AppID: 1, FieldCode: "title", FieldName: "件名", FieldType: "text", DisplayOrder: 1},
+		{ID: 2, AppID: 1, FieldCode: "customer", FieldName: "顧客", FieldType: "reference", DisplayOrder: 2,
+			Options: models.FieldOptions{"app_id": float64(2), "display_field": "name", "on_delete": "restrict"}},
+		{ID: 3, AppID: 1, FieldCode: "customer_email", FieldName: "顧客メール", FieldType: "lookup", DisplayOrder: 3,
+			Options: models.FieldOptions{"reference_field": "customer", "lookup_field": "email"}},
+	}
+)
+
+func TestFieldService_CreateField_Reference(t *testing.T) {
+	ctx := systemContext()
+
+	t.Run("creates column and foreign key", func(t *testing.T) {
+		mockFieldRepo := new(mocks.MockFieldRepository)
+		mockAppRepo := new(mocks.MockAppRepository)
+		mockDynamicQuery := new(mocks.MockDynamicQueryExecutor)
+
+		mockAppRepo.On("GetByID", ctx, uint64(1)).Return(orderApp, nil)
+		mockAppRepo.On("GetByID", ctx, uint64(2)).Return(customerApp, nil)
+		mockFieldRepo.On("FieldCodeExists", ctx, uint64(1), "customer").Return(false, nil)
+		mockFieldRepo.On("GetByAppID", ctx, uint64(2)).Return(customerFields, nil)
+		mockFieldRepo.On("Create", ctx, mock.AnythingOfType("*models.AppField")).Return(nil)
+		mockDynamicQuery.On("AddColumn", ctx, "app_data_1", mock.AnythingOfType("*models.AppField")).Return(nil)
+		mockDynamicQuery.On("SetForeignKey", ctx, "app_data_1", "customer", "app_data_2", models.ReferenceOnDeleteCascade).Return(nil)
+
+		service := services.NewFieldService(mockFieldRepo, mockAppRepo, mockDynamicQuery, newTestPermissionService(mockAppRepo), newTestTransactor(), newTestWebhookPublisher(), newTestAttachmentManager())
+
+		resp, err := service.CreateField(ctx, 1, &models.CreateFieldRequest{
+			FieldCode:    "customer",
+			FieldName:    "顧客",
+			FieldType:    "reference",
+			DisplayOrder: 2,
+			Options:      models.FieldOptions{"app_id": float64(2), "display_field": "name", "on_delete": "cascade"},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, uint64(2), resp.Options["app_id"])
+
+		mockDynamicQuery.AssertExpectations(t)
+	})
+
+	t.Run("defaults on_delete to restrict", func(t *testing.T) {
+		mockFieldRepo := new(mocks.MockFieldRepository)
+		mockAppRepo := new(mocks.MockAppRepository)
+		mockDynamicQuery := new(mocks.MockDynamicQueryExecutor)
+
+		mockAppRepo.On("GetByID", ctx, uint64(1)).Return(orderApp, nil)
+		mockAppRepo.On("GetByID", ctx, uint64(2)).Return(customerApp, nil)
+		mockFieldRepo.On("FieldCodeExists", ctx, uint64(1), "customer").Return(false, nil)
+		mockFieldRepo.On("Create", ctx, mock.AnythingOfType("*models.AppField")).Return(nil)
+		mockDynamicQuery.On("AddColumn", ctx, "app_data_1", mock.AnythingOfType("*models.AppField")).Return(nil)
+		mockDynamicQuery.On("SetForeignKey", ctx, "app_data_1", "customer", "app_data_2", models.ReferenceOnDeleteRestrict).Return(nil)
+
+		service := services.NewFieldService(mockFieldRepo, mockAppRepo, mockDynamicQuery, newTestPermissionService(mockAppRepo), newTestTransactor(), newTestWebhookPublisher(), newTestAttachmentManager())
+
+		resp, err := service.CreateField(ctx, 1, &models.CreateFieldRequest{
+			FieldCode: "customer", FieldName: "顧客", FieldType: "reference", DisplayOrder: 2,
+			Options: models.FieldOptions{"app_id": float64(2)},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "restrict", resp.Options["on_delete"])
+
+		mockDynamicQuery.AssertExpectations(t)
+	})
+
+	t.Run("rolls back when foreign key fails", func(t *testing.T) {
+		mockFieldRepo := new(mocks.MockFieldRepository)
+		mockAppRepo := new(mocks.MockAppRepository)
+		mockDynamicQuery := new(mocks.MockDynamicQueryExecutor)
+		mockTransactor := new(mocks.MockTransactor)
+		publisher := new(mocks.MockWebhookPublisher)
+
+		mockAppRepo.On("GetByID", ctx, uint64(1)).Return(orderApp, nil)
+		mockAppRepo.On("GetByID", ctx, uint64(2)).Return(customerApp, nil)
+		mockFieldRepo.On("FieldCodeExists", ctx, uint64(1), "customer").Return(false, nil)
+		mockFieldRepo.On("Create", ctx, mock.AnythingOfType("*models.AppField")).Return(nil).Run(func(args mock.Arguments) {
+			args.Get(1).(*models.AppField).ID = 5
+		})
+		mockDynamicQuery.On("AddColumn", ctx, "app_data_1", mock.AnythingOfType("*models.AppField")).Return(nil)
+		mockDynamicQuery.On("SetForeignKey", ctx, "app_data_1", "customer", "app_data_2", models.ReferenceOnDeleteRestrict).Return(errors.New("db error"))
+		mockTransactor.On("RunInTx", ctx).Return(nil).Once()
+
+		service := services.NewFieldService(mockFieldRepo, mockAppRepo, mockDynamicQuery, newTestPermissionService(mockAppRepo), mockTransactor, publisher, newTestAttachmentManager())
+
+		_, err := service.CreateField(ctx, 1, &models.CreateFieldRequest{
+			FieldCode: "customer", FieldName: "顧客", FieldType: "reference", DisplayOrder: 2,
+			Options: models.FieldOptions{"app_id": float64(2)},
+		})
+		require.Error(t, err)
+
+		mockDynamicQuery.AssertExpectations(t)
+		mockFieldRepo.AssertExpectations(t)
+		mockTransactor.AssertExpectations(t)
+		mockDynamicQuery.AssertNotCalled(t, "DropColumn", mock.Anything, mock.Anything, mock.Anything)
+		mockFieldRepo.AssertNotCalled(t, "Delete", mock.Anything, mock.Anything)
+		publisher.AssertNotCalled(t, "Publish", mock.Anything, mock.Anything)
+	})
+
+	t.Run("invalid options", func(t *testing.T) {
+		tests := []struct {
+			name    string
+			app     *models.App
+			options models.FieldOptions
+		}{
+			{"missing app", orderApp, models.FieldOptions{}},
+			{"unknown app", orderApp, models.FieldOptions{"app_id": float64(99)}},
+			{"invalid on_delete", orderApp, models.FieldOptions{"app_id": float64(2), "on_delete": "ignore"}},
+			{"unknown display field", orderApp, models.FieldOptions{"app_id": float64(2), "display_field": "missing"}},
+			{"external source app", &models.App{ID: 1, TableName: "external_1", IsExternal: true}, models.FieldOptions{"app_id": float64(2)}},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				mockFieldRepo := new(mocks.MockFieldRepository)
+				mockAppRepo := new(mocks.MockAppRepository)
+				mockDynamicQuery := new(mocks.MockDynamicQueryExecutor)
+
+				mockAppRepo.On("GetByID", ctx, uint64(1)).Return(tt.app, nil)
+				mockAppRepo.On("GetByID", ctx, uint64(2)).Return(customerApp, nil)
+				mockAppRepo.On("GetByID", ctx, uint64(99)).Return(nil, nil)
+				mockFieldRepo.On("FieldCodeExists", ctx, uint64(1), "customer").Return(false, nil)
+				mockFieldRepo.On("GetByAppID", ctx, uint64(2)).Return(customerFields, nil)
+
+				service := services.NewFieldService(mockFieldRepo, mockAppRepo, mockDynamicQuery, newTestPermissionService(mockAppRepo), newTestTransactor(), newTestWebhookPublisher(), newTestAttachmentManager())
+
+				_, err := service.CreateField(ctx, 1, &models.CreateFieldRequest{
+					FieldCode: "customer", FieldName: "顧客", FieldType: "reference", DisplayOrder: 2,
+					Options: tt.options,
+				})
+				assert.ErrorIs(t, err, services.ErrInvalidFieldOptions)
+				mockFieldRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+			})
+		}
+	})
+}
+
+func TestFieldService_CreateField_Lookup(t *testing.T) {
+	ctx := systemContext()
+
+	t.Run("creates field without column", func(t *testing.T) {
+		mockFieldRepo := new(mocks.MockFieldRepository)
+		mockAppRepo := new(mocks.MockAppRepository)
+		mockDynamicQuery := new(mocks.MockDynamicQueryExecutor)
+
+		mockAppRepo.On("GetByID", ctx, uint64(1)).Return(orderApp, nil)
+		mockFieldRepo.On("FieldCodeExists", ctx, uint64(1), "customer_name").Return(false, nil)
+		mockFieldRepo.On("GetByAppID", ctx, uint64(1)).Return(orderFields, nil)
+		mockFieldRepo.On("GetByAppID", ctx, uint64(2)).Return(customerFields, nil)
+		mockFieldRepo.On("Create", ctx, mock.AnythingOfType("*models.AppField")).Return(nil)
+		mockDynamicQuery.On("AddColumn", ctx, "app_data_1", mock.AnythingOfType("*models.AppField")).Return(nil)
+
+		service := services.NewFieldService(mockFieldRepo, mockAppRepo, mockDynamicQuery, newTestPermissionService(mockAppRepo), newTestTransactor(), newTestWebhookPublisher(), newTestAttachmentManager())
+
+		resp, err := service.CreateField(ctx, 1, &models.CreateFieldRequest{
+			FieldCode: "customer_name", FieldName: "顧客名", FieldType: "lookup", Required: true, DisplayOrder: 4,
+			Options: models.FieldOptions{"reference_field": "customer", "lookup_field": "name"},
+		})
+		require.NoError(t, err)
+		assert.False(t, resp.Required)
+		mockDynamicQuery.AssertNotCalled(t, "SetForeignKey", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("reference field must exist", func(t *testing.T) {
+		mockFieldRepo := new(mocks.MockFieldRepository)
+		mockAppRepo := new(mocks.MockAppRepository)
+		mockDynamicQuery := new(mocks.MockDynamicQueryExecutor)
+
+		mockAppRepo.On("GetByID", ctx, uint64(1)).Return(orderApp, nil)
+		mockFieldRepo.On("FieldCodeExists", ctx, uint64(1), "customer_name").Return(false, nil)
+		mockFieldRepo.On("GetByAppID", ctx, uint64(1)).Return(orderFields, nil)
+
+		service := services.NewFieldService(mockFieldRepo, mockAppRepo, mockDynamicQuery, newTestPermissionService(mockAppRepo), newTestTransactor(), newTestWebhookPublisher(), newTestAttachmentManager())
+
+		_, err := service.CreateField(ctx, 1, &models.CreateFieldRequest{
+			FieldCode: "customer_name", FieldName: "顧客名", FieldType: "lookup", DisplayOrder: 4,
+			Options: models.FieldOptions{"reference_field": "title", "lookup_field": "name"},
+		})
+		assert.ErrorIs(t, err, services.ErrInvalidFieldOptions)
+	})
+
+	t.Run("lookup field must exist in referenced app", func(t *testing.T) {
+		mockFieldRepo := new(mocks.MockFieldRepository)
+		mockAppRepo := new(mocks.MockAppRepository)
+		mockDynamicQuery := new(mocks.MockDynamicQueryExecutor)
+
+		mockAppRepo.On("GetByID", ctx, uint64(1)).Return(orderApp, nil)
+		mockFieldRepo.On("FieldCodeExists", ctx, uint64(1), "customer_name").Return(false, nil)
+		mockFieldRepo.On("GetByAppID", ctx, uint64(1)).Return(orderFields, nil)
+		mockFieldRepo.On("GetByAppID", ctx, uint64(2)).Return(customerFields, nil)
+
+		service := services.NewFieldService(mockFieldRepo, mockAppRepo, mockDynamicQuery, newTestPermissionService(mockAppRepo), newTestTransactor(), newTestWebhookPublisher(), newTestAttachmentManager())
+
+		_, err := service.CreateField(ctx, 1, &models.CreateFieldRequest{
+			FieldCode: "customer_name", FieldName: "顧客名", FieldType: "lookup", DisplayOrder: 4,
+			Options: models.FieldOptions{"reference_field": "customer", "lookup_field": "phone"},
+		})
+		assert.ErrorIs(t, err, services.ErrInvalidFieldOptions)
+	})
+}
+
+func TestFieldService_UpdateField_Reference(t *testing.T) {
+	ctx := systemContext()
+
+	newField := func() *models.AppField {
+		f := orderFields[1]
+		f.Options = models.FieldOptions{"app_id": float64(2), "display_field": "name", "on_delete": "restrict"}
+		return &f
+	}
+
+	t.Run("changing on_delete replaces foreign key", func(t *testing.T) {
+		mockFieldRepo := new(mocks.MockFieldRepository)
+		mockAppRepo := new(mocks.MockAppRepository)
+		mockDynamicQuery := new(mocks.MockDynamicQueryExecutor)
+
+		mockFieldRepo.On("GetByID", ctx, uint64(2)).Return(newField(), nil)
+		mockAppRepo.On("GetByID", ctx, uint64(1)).Return(orderApp, nil)
+		mockAppRepo.On("GetByID", ctx, uint64(2)).Return(customerApp, nil)
+		mockDynamicQuery.On("SetForeignKey", ctx, "app_data_1", "customer", "app_data_2", models.ReferenceOnDeleteSetNull).Return(nil)
+		mockFieldRepo.On("Update", ctx, mock.AnythingOfType("*models.AppField")).Return(nil)
+
+		service := services.NewFieldService(mockFieldRepo, mockAppRepo, mockDynamicQuery, newTestPermissionService(mockAppRepo), newTestTransactor(), newTestWebhookPublisher(), newTestAttachmentManager())
+
+		resp, err := service.UpdateField(ctx, 2, 0, &models.UpdateFieldRequest{
+			Options: models.FieldOptions{"on_delete": "set_null"},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "set_null", resp.Options["on_delete"])
+
+		mockDynamicQuery.AssertExpectations(t)
+	})
+
+	t.Run("referenced app cannot change", func(t *testing.T) {
+		mockFieldRepo := new(mocks.MockFieldRepository)
+		mockAppRepo := new(mocks.MockAppRepository)
+		mockDynamicQuery := new(mocks.MockDynamicQueryExecutor)
+
+		mockFieldRepo.On("GetByID", ctx, uint64(2)).Return(newField(), nil)
+		mockAppRepo.On("GetByID", ctx, uint64(1)).Return(orderApp, nil)
+
+		service := services.NewFieldService(mockFieldRepo, mockAppRepo, mockDynamicQuery, newTestPermissionService(mockAppRepo), newTestTransactor(), newTestWebhookPublisher(), newTestAttachmentManager())
+
+		_, err := service.UpdateField(ctx, 2, 0, &models.UpdateFieldRequest{
+			Options: models.FieldOptions{"app_id": float64(3)},
+		})
+		assert.ErrorIs(t, err, services.ErrInvalidFieldOptions)
+		mockFieldRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+	})
+}
+
+func TestFieldService_DeleteField_Reference(t *testing.T) {
+	ctx := systemContext()
+
+	t.Run("reference used by lookup", func(t *testing.T) {
+		mockFieldRepo := new(mocks.MockFieldRepository)
+		mockAppRepo := new(mocks.MockAppRepository)
+		mockDynamicQuery := new(mocks.MockDynamicQueryExecutor)
+
+		field := orderFields[1]
+		mockFieldRepo.On("GetByID", ctx, uint64(2)).Return(&field, nil)
+		mockAppRepo.On("GetByID", ctx, uint64(1)).Return(orderApp, nil)
+		mockFieldRepo.On("GetByAppID", ctx, uint64(1)).Return(orderFields, nil)
+
+		service := services.NewFieldService(mockFieldRepo, mockAppRepo, mockDynamicQuery, newTestPermissionService(mockAppRepo), newTestTransactor(), newTestWebhookPublisher(), newTestAttachmentManager())
+
+		err := service.DeleteField(ctx, 1, 2, 0)
+		assert.ErrorIs(t, err, services.ErrFieldInUse)
+		mockDynamicQuery.AssertNotCalled(t, "DropColumn", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("field displayed by another app", func(t *testing.T) {
+		mockFieldRepo := new(mocks.MockFieldRepository)
+		mockAppRepo := new(mocks.MockAppRepository)
+		mockDynamicQuery := new(mocks.MockDynamicQueryExecutor)
+
+		field := customerFields[1]
+		mockFieldRepo.On("GetByID", ctx, uint64(11)).Return(&field, nil)
+		mockAppRepo.On("GetByID", ctx, uint64(2)).Return(customerApp, nil)
+		mockFieldRepo.On("GetByAppID", ctx, uint64(2)).Return(customerFields, nil)
+		mockFieldRepo.On("GetReferencingFields", ctx, uint64(2)).Return([]models.AppField{orderFields[1]}, nil)
+		mockFieldRepo.On("GetByAppID", ctx, uint64(1)).Return(orderFields, nil)
+
+		service := services.NewFieldService(mockFieldRepo, mockAppRepo, mockDynamicQuery, newTestPermissionService(mockAppRepo), newTestTransactor(), newTestWebhookPublisher(), newTestAttachmentManager())
+
+		// email は注文アプリのルックアップで表示されている
+		err := service.DeleteField(ctx, 2, 11, 0)
+		assert.ErrorIs(t, err, services.ErrFieldInUse)
+	})
+
+	t.Run("lookup has no column to drop", func(t *testing.T) {
+		mockFieldRepo := new(mocks.MockFieldRepository)
+		mockAppRepo := new(mocks.MockAppRepository)
+		mockDynamicQuery := new(mocks.MockDynamicQueryExecutor)
+
+		field := orderFields[2]
+		mockFieldRepo.On("GetByID", ctx, uint64(3)).Return(&field, nil)
+		mockAppRepo.On("GetByID", ctx, uint64(1)).Return(orderApp, nil)
+		mockFieldRepo.On("GetByAppID", ctx, uint64(1)).Return(orderFields, nil)
+		mockFieldRepo.On("Trash", ctx, uint64(3)).Return(nil)
+
+		service := services.NewFieldService(mockFieldRepo, mockAppRepo, mockDynamicQuery, newTestPermissionService(mockAppRepo), newTestTransactor(), newTestWebhookPublisher(), newTestAttachmentManager())
+
+		err := service.DeleteField(ctx, 1, 3, 0)
+		require.NoError(t, err)
+		mockDynamicQuery.AssertNotCalled(t, "DropColumn", mock.Anything, mock.Anything, mock.Anything)
+		mockFieldRepo.AssertExpectations(t)
+	})
+}
+
+func TestAppService_CreateApp_Reference(t *testing.T) {
+	ctx := systemContext()
+
+	mockAppRepo := new(mocks.MockAppRepository)
+	mockFieldRepo := new(mocks.MockFieldRepository)
+	mockDynamicQuery := new(mocks.MockDynamicQueryExecutor)
+
+	mockAppRepo.On("GetByID", ctx, uint64(2)).Return(customerApp, nil)
+	mockFieldRepo.On("GetByAppID", ctx, uint64(2)).Return(customerFields, nil)
+	mockAppRepo.On("Create", ctx, mock.AnythingOfType("*models.App")).Return(nil).Run(func(args mock.Arguments) {
+		args.Get(1).(*models.App).ID = 3
+	})
+	mockAppRepo.On("Update", ctx, mock.AnythingOfType("*models.App")).Return(nil)
+	mockFieldRepo.On("CreateBatch", ctx, mock.AnythingOfType("[]models.AppField")).Return(nil)
+	mockDynamicQuery.On("CreateTable", ctx, "app_data_3", mock.AnythingOfType("[]models.AppField")).Return(nil)
+	mockDynamicQuery.On("SetSearchColumn", ctx, "app_data_3", mock.AnythingOfType("[]models.AppField"), "simple").Return(nil)
+	mockDynamicQuery.On("SetForeignKey", ctx, "app_data_3", "customer", "app_data_2", models.ReferenceOnDeleteCascade).Return(nil)
+	mockAppRepo.On("GetByIDWithFields", ctx, uint64(3)).Return(&models.App{ID: 3, Name: "Orders"}, nil)
+
+	service := services.NewAppService(mockAppRepo, mockFieldRepo, mockDynamicQuery, new(mocks.MockDataSourceRepository), newTestTransactor(), newTestPermissionService(mockAppRepo), newTestWebhookPublisher(), newTestAttachmentManager())
+
+	_, err := service.CreateApp(ctx, 1, &models.CreateAppRequest{
+		Name: "Orders",
+		Fields: []models.CreateFieldRequest{
+			{FieldCode: "customer_name", FieldName: "顧客名", FieldType: "lookup",
+				Options: models.FieldOptions{"reference_field": "customer", "lookup_field": "name"}},
+			{FieldCode: "customer", FieldName: "顧客", FieldType: "reference",
+				Options: models.FieldOptions{"app_id": float64(2), "on_delete": "cascade"}},
+		},
+	})
+	require.NoError(t, err)
+	mockDynamicQuery.AssertExpectations(t)
+
+	t.Run("invalid reference creates nothing", func(t *testing.T) {
+		mockAppRepo := new(mocks.MockAppRepository)
+		mockFieldRepo := new(mocks.MockFieldRepository)
+		mockAppRepo.On("GetByID", ctx, uint64(99)).Return(nil, nil)
+
+		service := services.NewAppService(mockAppRepo, mockFieldRepo, new(mocks.MockDynamicQueryExecutor), new(mocks.MockDataSourceRepository), newTestTransactor(), newTestPermissionService(mockAppRepo), newTestWebhookPublisher(), newTestAttachmentManager())
+
+		_, err := service.CreateApp(ctx, 1, &models.CreateAppRequest{
+			Name: "Orders",
+			Fields: []models.CreateFieldRequest{
+				{FieldCode: "customer", FieldName: "顧客", FieldType: "reference", Options: models.FieldOptions{"app_id": float64(99)}},
+			},
+		})
+		assert.ErrorIs(t, err, services.ErrInvalidFieldOptions)
+		mockAppRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	})
+}
+
+func TestAppService_DeleteApp_Referenced(t *testing.T) {
+	ctx := systemContext()
+
+	mockAppRepo := new(mocks.MockAppRepository)
+	mockFieldRepo := new(mocks.MockFieldRepository)
+	mockDynamicQuery := new(mocks.MockDynamicQueryExecutor)
+
+	mockAppRepo.On("GetByID", ctx, uint64(2)).Return(customerApp, nil)
+	mockFieldRepo.On("GetReferencingFields", ctx, uint64(2)).Return([]models.AppField{orderFields[1]}, nil)
+
+	service := services.NewAppService(mockAppRepo, mockFieldRepo, mockDynamicQuery, new(mocks.MockDataSourceRepository), newTestTransactor(), newTestPermissionService(mockAppRepo), newTestWebhookPublisher(), newTestAttachmentManager())
+
+	err := service.DeleteApp(ctx, 2)
+	assert.ErrorIs(t, err, services.ErrAppReferenced)
+	mockDynamicQuery.AssertNotCalled(t, "DropTable", mock.Anything, mock.Anything)
+}
+
+func TestRecordService_GetRecords_ExpandsReferences(t *testing.T) {
+	ctx := systemContext()
+
+	records := []models.RecordResponse{
+		{ID: 100, Data: models.RecordData{"title": "注文A", "customer": int64(7)}},
+		{ID: 101, Data: models.RecordData{"title": "注文B", "customer": int64(7)}},
+		{ID: 102, Data: models.RecordData{"title": "注文C", "customer": nil}},
+	}
+	customers := []models.RecordResponse{
+		{ID: 7, CreatedBy: 1, Data: models.RecordData{"name": "山田商店", "email": "yamada@example.com"}},
+	}
+
+	t.Run("expands referenced record and lookup", func(t *testing.T) {
+		mockAppRepo := new(mocks.MockAppRepository)
+		mockFieldRepo := new(mocks.MockFieldRepository)
+		mockDynamicQuery := new(mocks.MockDynamicQueryExecutor)
+
+		mockAppRepo.On("GetByID", ctx, uint64(1)).Return(orderApp, nil)
+		mockAppRepo.On("GetByID", ctx, uint64(2)).Return(customerApp, nil)
+		mockFieldRepo.On("GetByAppID", ctx, uint64(1)).Return(orderFields, nil)
+		mockFieldRepo.On("GetByAppID", ctx, uint64(2)).Return(customerFields, nil)
+		mockDynamicQuery.On("GetRecords", ctx, "app_data_1", orderFields, mock.Anything).Return(cloneRecords(records), int64(3), nil)
+		mockDynamicQuery.On("GetRecordsByIDs", ctx, "app_data_2", customerFields, []uint64{7}).Return(customers, nil).Once()
+
+		service := services.NewRecordService(mockAppRepo, mockFieldRepo, mockDynamicQuery, new(mocks.MockDataSourceRepository), new(mocks.MockExternalQueryExecutor), newTestPermissionService(mockAppRepo), new(mocks.MockRecordRevisionRepository), newTestTransactor(), newTestWebhookPublisher(), newTestAutomationRunner(), newTestAttachmentManager())
+
+		resp, err := service.GetRecords(ctx, 1, repositories.RecordQueryOptions{Page: 1, Limit: 20})
+		require.NoError(t, err)
+		require.Len(t, resp.Records, 3)
+
+		ref := resp.Records[0].References["customer"]
+		require.NotNil(t, ref)
+		assert.Equal(t, uint64(2), ref.AppID)
+		assert.Equal(t, uint64(7), ref.RecordID)
+		assert.Equal(t, "山田商店", ref.Display)
+		assert.Equal(t, "yamada@example.com", resp.Records[0].Data["customer_email"])
+		assert.Equal(t, "yamada@example.com", resp.Records[1].Data["customer_email"])
+
+		assert.Nil(t, resp.Records[2].References)
+		assert.Contains(t, resp.Records[2].Data, "customer_email")
+		assert.Nil(t, resp.Records[2].Data["customer_email"])
+
+		mockDynamicQuery.AssertExpectations(t)
+	})
+
+	t.Run("no expansion without access to referenced app", func(t *testing.T) {
+		mockAppRepo := new(mocks.MockAppRepository)
+		mockFieldRepo := new(mocks.MockFieldRepository)
+		mockDynamicQuery := new(mocks.MockDynamicQueryExecutor)
+		mockPermissions := new(mocks.MockPermissionService)
+
+		mockAppRepo.On("GetByID", ctx, uint64(2)).Return(customerApp, nil)
+		mockPermissions.On("AuthorizeApp", ctx, uint64(1), models.AppRoleViewer).Return(orderApp, &models.AppAccess{UserID: 5, Role: models.AppRoleViewer}, nil)
+		mockPermissions.On("CheckAppAccess", ctx, customerApp, models.AppRoleViewer).Return(nil, services.ErrAppNotFound)
+		mockFieldRepo.On("GetByAppID", ctx, uint64(1)).Return(orderFields, nil)
+		mockDynamicQuery.On("GetRecords", ctx, "app_data_1", orderFields, mock.Anything).Return(cloneRecords(records), int64(3), nil)
+
+		service := services.NewRecordService(mockAppRepo, mockFieldRepo, mockDynamicQuery, new(mocks.MockDataSourceRepository), new(mocks.MockExternalQueryExecutor), mockPermissions, new(mocks.MockRecordRevisionRepository), newTestTransactor(), newTestWebhookPublisher(), newTestAutomationRunner(), newTestAttachmentManager())
+
+		resp, err := service.GetRecords(ctx, 1, repositories.RecordQueryOptions{Page: 1, Limit: 20})
+		require.NoError(t, err)
+		assert.Nil(t, resp.Records[0].References)
+		assert.Equal(t, int64(7), resp.Records[0].Data["customer"])
+		assert.Nil(t, resp.Records[0].Data["customer_email"])
+		mockDynamicQuery.AssertNotCalled(t, "GetRecordsByIDs", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("own records only hides other users' records", func(t *testing.T) {
+		mockAppRepo := new(mocks.MockAppRepository)
+		mockFieldRepo := new(mocks.MockFieldRepository)
+		mockDynamicQuery := new(mocks.MockDynamicQueryExecutor)
+		mockPermissions := new(mocks.MockPermissionService)
+
+		mockAppRepo.On("GetByID", ctx, uint64(2)).Return(customerApp, nil)
+		mockPermissions.On("AuthorizeApp", ctx, uint64(1), models.AppRoleViewer).Return(orderApp, &models.AppAccess{UserID: 5, Role: models.AppRoleViewer}, nil)
+		mockPermissions.On("CheckAppAccess", ctx, customerApp, models.AppRoleViewer).Return(&models.AppAccess{UserID: 5, Role: models.AppRoleViewer, OwnRecordsOnly: true}, nil)
+		mockFieldRepo.On("GetByAppID", ctx, uint64(1)).Return(orderFields, nil)
+		mockFieldRepo.On("GetByAppID", ctx, uint64(2)).Return(customerFields, nil)
+		mockDynamicQuery.On("GetRecords", ctx, "app_data_1", orderFields, mock.Anything).Return(cloneRecords(records), int64(3), nil)
+		mockDynamicQuery.On("GetRecordsByIDs", ctx, "app_data_2", customerFields, []uint64{7}).Return(customers, nil)
+
+		service := services.NewRecordService(mockAppRepo, mockFieldRepo, mockDynamicQuery, new(mocks.MockDataSourceRepository), new(mocks.MockExternalQueryExecutor), mockPermissions, new(mocks.MockRecordRevisionRepository), newTestTransactor(), newTestWebhookPublisher(), newTestAutomationRunner(), newTestAttachmentManager())
+
+		resp, err := service.GetRecords(ctx, 1, repositories.RecordQueryOptions{Page: 1, Limit: 20})
+		require.NoError(t, err)
+		assert.Nil(t, resp.Records[0].References)
+		assert.Nil(t, resp.Records[0].Data["customer_email"])
+	})
+}
+
+func TestRecordService_GetRecord_ExpandsReferences(t *testing.T) {
+	ctx := systemContext()
+
+	mockAppRepo := new(mocks.MockAppRepository)
+	mockFieldRepo := new(mocks.MockFieldRepository)
+	mockDynamicQuery := new(mocks.MockDynamicQueryExecutor)
+
+	mockAppRepo.On("GetByID", ctx, uint64(1)).Return(orderApp, nil)
+	mockAppRepo.On("GetByID", ctx, uint64(2)).Return(customerApp, nil)
+	mockFieldRepo.On("GetByAppID", ctx, uint64(1)).Return(orderFields, nil)
+	mockFieldRepo.On("GetByAppID", ctx, uint64(2)).Return(customerFields, nil)
+	mockDynamicQuery.On("GetRecordByID", ctx, "app_data_1", orderFields, uint64(100)).Return(&models.RecordResponse{
+		ID: 100, Data: models.RecordData{"title": "注文A", "customer": int64(7)},
+	}, nil)
+	mockDynamicQuery.On("GetRecordsByIDs", ctx, "app_data_2", customerFields, []uint64{7}).Return([]models.RecordResponse{
+		{ID: 7, Data: models.RecordData{"name": "山田商店", "email": "yamada@example.com"}},
+	}, nil)
+
+	service := services.NewRecordService(mockAppRepo, mockFieldRepo, mockDynamicQuery, new(mocks.MockDataSourceRepository), new(mocks.MockExternalQueryExecutor), newTestPermissionService(mockAppRepo), new(mocks.MockRecordRevisionRepository), newTestTransactor(), newTestWebhookPublisher(), newTestAutomationRunner(), newTestAttachmentManager())
+
+	record, err := service.GetRecord(ctx, 1, 100)
+	require.NoError(t, err)
+	require.Contains(t, record.References, "customer")
+	assert.Equal(t, "山田商店", record.References["customer"].Display)
+	assert.Equal(t, "yamada@example.com", record.Data["customer_email"])
+}
+
+func TestRecordService_CreateRecord_Reference(t *testing.T) {
+	ctx := systemContext()
+
+	t.Run("referenced record must exist", func(t *testing.T) {
+		mockAppRepo := new(mocks.MockAppRepository)
+		mockFieldRepo := new(mocks.MockFieldRepository)
+		mockDynamicQuery := new(mocks.MockDynamicQueryExecutor)
+
+		mockAppRepo.On("GetByID", ctx, uint64(1)).Return(orderApp, nil)
+		mockAppRepo.On("GetByID", ctx, uint64(2)).Return(customerApp, nil)
+		mockFieldRepo.On("GetByAppID", ctx, uint64(1)).Return(orderFields, nil)
+		mockDynamicQuery.On("GetRecordsByIDs", ctx, "app_data_2", []models.AppField(nil), []uint64{8}).Return([]models.RecordResponse{}, nil)
+
+		service := services.NewRecordService(mockAppRepo, mockFieldRepo, mockDynamicQuery, new(mocks.MockDataSourceRepository), new(mocks.MockExternalQueryExecutor), newTestPermissionService(mockAppRepo), new(mocks.MockRecordRevisionRepository), newTestTransactor(), newTestWebhookPublisher(), newTestAutomationRunner(), newTestAttachmentManager())
+
+		_, err := service.CreateRecord(ctx, 1, 1, &models.CreateRecordRequest{
+			Data: models.RecordData{"title": "注文A", "customer": float64(8)},
+		})
+		var validationErr *services.RecordValidationError
+		require.ErrorAs(t, err, &validationErr)
+		assert.Contains(t, validationErr.Errors, "customer")
+		mockDynamicQuery.AssertNotCalled(t, "InsertRecord", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("lookup values are not stored", func(t *testing.T) {
+		mockAppRepo := new(mocks.MockAppRepository)
+		mockFieldRepo := new(mocks.MockFieldRepository)
+		mockDynamicQuery := new(mocks.MockDynamicQueryExecutor)
+		mockRevisionRepo := new(mocks.MockRecordRevisionRepository)
+
+		mockAppRepo.On("GetByID", ctx, uint64(1)).Return(orderApp, nil)
+		mockAppRepo.On("GetByID", ctx, uint64(2)).Return(customerApp, nil)
+		mockFieldRepo.On("GetByAppID", ctx, uint64(1)).Return(orderFields, nil)
+		mockDynamicQuery.On("GetRecordsByIDs", ctx, "app_data_2", []models.AppField(nil), []uint64{7}).Return([]models.RecordResponse{{ID: 7}}, nil)
+		mockDynamicQuery.On("InsertRecord", ctx, "app_data_1", models.RecordData{"title": "注文A", "customer": uint64(7)}, uint64(1)).Return(uint64(100), nil)
+		mockDynamicQuery.On("GetRecordByID", ctx, "app_data_1", orderFields, uint64(100)).Return(&models.RecordResponse{ID: 100}, nil)
+		mockRevisionRepo.On("Create", ctx, mock.AnythingOfType("*models.RecordRevision")).Return(nil)
+
+		service := services.NewRecordService(mockAppRepo, mockFieldRepo, mockDynamicQuery, new(mocks.MockDataSourceRepository), new(mocks.MockExternalQueryExecutor), newTestPermissionService(mockAppRepo), mockRevisionRepo, newTestTransactor(), newTestWebhookPublisher(), newTestAutomationRunner(), newTestAttachmentManager())
+
+		_, err := service.CreateRecord(ctx, 1, 1, &models.CreateRecordRequest{
+			Data: models.RecordData{"title": "注文A", "customer": "7", "customer_email": "ignored"},
+		})
+		require.NoError(t, err)
+		mockDynamicQuery.AssertExpectations(t)
+	})
+}
+
+func TestRecordService_DeleteRecord_Referenced(t *testing.T) {
+	ctx := systemContext()
+
+	mockAppRepo := new(mocks.MockAppRepository)
+	mockFieldRepo := new(mocks.MockFieldRepository)
+	mockDynamicQuery := new(mocks.MockDynamicQueryExecutor)
+	mockRevisionRepo := new(mocks.MockRecordRevisionRepository)
+
+	mockAppRepo.On("GetByID", ctx, uint64(2)).Return(customerApp, nil)
+	mockFieldRepo.On("GetByAppID", ctx, uint64(2)).Return(customerFields, nil)
+	mockDynamicQuery.On("GetRecordByID", ctx, "app_data_2", customerFields, uint64(7)).Return(&models.RecordResponse{ID: 7}, nil)
+	mockDynamicQuery.On("TrashRecords", ctx, "app_data_2", []uint64{7}, uint64(0)).Return(&pq.Error{Code: "23503"})
+
+	service := services.NewRecordService(mockAppRepo, mockFieldRepo, mockDynamicQuery, new(mocks.MockDataSourceRepository), new(mocks.MockExternalQueryExecutor), newTestPermissionService(mockAppRepo), mockRevisionRepo, newTestTransactor(), newTestWebhookPublisher(), newTestAutomationRunner(), newTestAttachmentManager())
+
+	err := service.DeleteRecord(ctx, 2, 7, 0)
+	assert.ErrorIs(t, err, services.ErrRecordReferenced)
+	mockRevisionRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+// cloneRecords 展開でDataが書き換えられるため、サブテストごとにレコードを複製する
+func cloneRecords(records []models.RecordResponse) []models.RecordResponse {
+	cloned := make([]models.RecordResponse, len(records))
+	for i := range records {
+		cloned[i] = records[i]
+		cloned[i].Data = make(models.RecordData, len(records[i].Data))
+		for k, v := range records[i].Data {
+			cloned[i].Data[k] = v
+		}
+	}
+	return cloned
+}
@@ -0,0 +1,254 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"nocode-app/backend/internal/models"
+	"nocode-app/backend/internal/repositories"
+	"nocode-app/backend/internal/utils"
+)
+
+// ErrInvalidReportConfig ピボット集計の設定が正しくない
+var ErrInvalidReportConfig = errors.New("集計の設定が正しくありません")
+
+// 小計・総計の見出し
+const (
+	pivotSubtotalLabel = "小計"
+	pivotTotalLabel    = "総計"
+)
+
+// ReportService ピボット集計（クロス集計）を処理する構造体
+type ReportService struct {
+	fieldRepo     repositories.FieldRepositoryInterface
+	dynamicQuery  repositories.DynamicQueryExecutorInterface
+	dsRepo        repositories.DataSourceRepositoryInterface
+	externalQuery repositories.ExternalQueryExecutorInterface
+	permissions   PermissionServiceInterface
+}
+
+// NewReportService 新しいReportServiceを作成する
+func NewReportService(
+	fieldRepo repositories.FieldRepositoryInterface,
+	dynamicQuery repositories.DynamicQueryExecutorInterface,
+	dsRepo repositories.DataSourceRepositoryInterface,
+	externalQuery repositories.ExternalQueryExecutorInterface,
+	permissions PermissionServiceInterface,
+) *ReportService {
+	return &ReportService{
+		fieldRepo:     fieldRepo,
+		dynamicQuery:  dynamicQuery,
+		dsRepo:        dsRepo,
+		externalQuery: externalQuery,
+		permissions:   permissions,
+	}
+}
+
+// GetPivot 行・列のフィールドごとの集計値を小計・総計付きで取得する
+func (s *ReportService) GetPivot(ctx context.Context, appID uint64, req *models.PivotRequest) (*models.PivotResponse, error) {
+	app, access, err := s.permissions.AuthorizeApp(ctx, appID, models.AppRoleViewer)
+	if err != nil {
+		return nil, err
+	}
+
+	fields, err := s.fieldRepo.GetByAppID(ctx, appID)
+	if err != nil {
+		return nil, err
+	}
+	req, err = resolvePivotRequest(app, fields, req)
+	if err != nil {
+		return nil, err
+	}
+	if len(req.Filters) > 0 || req.Filter != nil {
+		req.Filters, req.Filter, err = resolveFilters(app, fields, req.TimeZone, req.Filters, req.Filter)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// 自分のレコードのみ閲覧可能な場合は作成者で絞り込む
+	if access.OwnRecordsOnly {
+		// 外部データソースには作成者の概念がないため集計できない
+		if app.IsExternal {
+			return nil, ErrPermissionDenied
+		}
+		req.Filters = append(append([]models.FilterItem{}, req.Filters...), access.RecordFilters()...)
+	}
+
+	// 外部データソースの場合は外部クエリを使用
+	if app.IsExternal && app.DataSourceID != nil && app.SourceTableName != nil {
+		// 暗号化が初期化されているか確認
+		if !utils.IsEncryptionInitialized() {
+			return nil, ErrEncryptionNotInitialized
+		}
+
+		ds, err := s.dsRepo.GetByID(ctx, *app.DataSourceID)
+		if err != nil {
+			return nil, err
+		}
+		if ds == nil {
+			return nil, ErrDataSourceNotFound
+		}
+
+		password, err := utils.Decrypt(ds.EncryptedPassword)
+		if err != nil {
+			return nil, err
+		}
+
+		return s.externalQuery.GetPivotData(ctx, ds, password, *app.SourceTableName, fields, req)
+	}
+
+	// 内部アプリの場合は動的クエリを使用
+	return s.dynamicQuery.GetPivotData(ctx, app.TableName, req)
+}
+
+// ExportPivot ピボット集計の結果を表としてCSVまたはExcel形式で書き出す
+func (s *ReportService) ExportPivot(ctx context.Context, appID uint64, req *models.PivotRequest, format models.ExportFormat, w io.Writer) error {
+	// 行列の表にならないNDJSONは対象外
+	if format != models.ExportFormatCSV && format != models.ExportFormatXLSX {
+		return ErrInvalidExportFormat
+	}
+
+	pivot, err := s.GetPivot(ctx, appID, req)
+	if err != nil {
+		return err
+	}
+
+	tw, err := newExportWriter(format, w)
+	if err != nil {
+		return err
+	}
+	if err := writePivotTable(tw, pivot); err != nil {
+		_ = tw.Close()
+		return err
+	}
+	return tw.Close()
+}
+
+// resolvePivotRequest 行・列・集計値に指定したフィールドを検証し、
+// 日付のカラムかどうかを設定した複製を返す
+func resolvePivotRequest(app *models.App, fields []models.AppField, req *models.PivotRequest) (*models.PivotRequest, error) {
+	// 絞り込みと同じく、カラムを持つフィールドの分類で判定する
+	r, err := newFilterResolver(app, fields, "", time.Now())
+	if err != nil {
+		return nil, err
+	}
+	kinds := r.kinds
+
+	if req.TimeZone != "" {
+		if _, err := time.LoadLocation(req.TimeZone); err != nil {
+			return nil, fmt.Errorf("%w: タイムゾーン %q が正しくありません", ErrInvalidReportConfig, req.TimeZone)
+		}
+	}
+
+	resolved := *req
+	resolveDimensions := func(dims []models.PivotDimension) ([]models.PivotDimension, error) {
+		out := make([]models.PivotDimension, len(dims))
+		seen := make(map[string]bool, len(dims))
+		for i, d := range dims {
+			kind, ok := kinds[d.Field]
+			switch {
+			case !ok:
+				return nil, fmt.Errorf("%w: 行・列のフィールド %q がありません", ErrInvalidReportConfig, d.Field)
+			case kind == filterKindMultiSelect || kind == filterKindAttachment:
+				return nil, fmt.Errorf("%w: 複数選択・添付ファイルのフィールドは行・列に指定できません", ErrInvalidReportConfig)
+			}
+			out[i] = d
+			if d.Bucket != "" {
+				switch kind {
+				case filterKindDate:
+					out[i].DateOnly = true
+				case filterKindDateTime:
+				default:
+					return nil, fmt.Errorf("%w: 日付の区切りは日付・日時のフィールドにのみ指定できます", ErrInvalidReportConfig)
+				}
+			}
+			// 同じフィールドを同じ区切りで複数回指定すると小計を区別できない
+			key := d.Field + "\x00" + d.Bucket
+			if seen[key] {
+				return nil, fmt.Errorf("%w: 行・列のフィールド %q が重複しています", ErrInvalidReportConfig, d.Field)
+			}
+			seen[key] = true
+		}
+		return out, nil
+	}
+	if resolved.Rows, err = resolveDimensions(req.Rows); err != nil {
+		return nil, err
+	}
+	if resolved.Columns, err = resolveDimensions(req.Columns); err != nil {
+		return nil, err
+	}
+
+	for _, m := range req.Measures {
+		switch m.Aggregation {
+		case "count":
+			// 件数はフィールドを省略できるが、指定した場合はアプリのフィールドでなければならない
+			if _, ok := kinds[m.Field]; m.Field != "" && !ok {
+				return nil, fmt.Errorf("%w: 件数を集計するフィールド %q がありません", ErrInvalidReportConfig, m.Field)
+			}
+		case "sum", "avg", "min", "max":
+			if kinds[m.Field] != filterKindNumber {
+				return nil, fmt.Errorf("%w: %s で集計するフィールド %q は数値のフィールドを指定してください", ErrInvalidReportConfig, m.Aggregation, m.Field)
+			}
+		}
+	}
+	return &resolved, nil
+}
+
+// writePivotTable ピボット集計の結果を1行目を見出しとする表として書き出す
+// 列は「列のフィールドの値 / 集計値」の組み合わせごとに並べ、小計・総計の見出しは空欄の代わりに「小計」「総計」とする
+func writePivotTable(tw utils.TableWriter, pivot *models.PivotResponse) error {
+	headers := append([]string{}, pivot.RowFields...)
+	for _, c := range pivot.Columns {
+		for _, m := range pivot.Measures {
+			parts := pivotHeaderLabels(c, len(pivot.ColumnFields))
+			if len(pivot.Measures) > 1 || len(parts) == 0 {
+				parts = append(parts, m)
+			}
+			headers = append(headers, strings.Join(parts, " / "))
+		}
+	}
+	if err := tw.WriteHeader(headers); err != nil {
+		return err
+	}
+
+	for _, row := range pivot.Rows {
+		values := make([]interface{}, 0, len(headers))
+		for _, label := range pivotHeaderLabels(row.PivotHeader, len(pivot.RowFields)) {
+			values = append(values, label)
+		}
+		for len(values) < len(pivot.RowFields) {
+			values = append(values, "")
+		}
+		for _, cell := range row.Values {
+			for _, v := range cell {
+				if v == nil {
+					values = append(values, nil)
+					continue
+				}
+				values = append(values, *v)
+			}
+		}
+		if err := tw.WriteRow(values); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// pivotHeaderLabels 行・列の見出しの値を返す。小計・総計の場合は末尾に「小計」「総計」を付ける
+func pivotHeaderLabels(h models.PivotHeader, n int) []string {
+	labels := append([]string{}, h.Keys...)
+	switch {
+	case n == 0 || !h.Total:
+	case len(h.Keys) == 0:
+		labels = append(labels, pivotTotalLabel)
+	default:
+		labels = append(labels, pivotSubtotalLabel)
+	}
+	return labels
+}
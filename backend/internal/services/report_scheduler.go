@@ -0,0 +1,101 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"nocode-app/backend/internal/models"
+	"nocode-app/backend/internal/repositories"
+)
+
+const (
+	// reportSchedulerLockKey スケジューラーを1台のサーバーだけで実行するためのアドバイザリーロックのキー
+	reportSchedulerLockKey int64 = 0x7265706f72747321
+	// reportSchedulerBatchSize 1回の処理で配信するレポートの最大件数
+	reportSchedulerBatchSize = 20
+)
+
+// ReportScheduler 配信時刻を過ぎた保存済みのレポートをメールで配信する構造体
+// 複数のサーバーで起動しても、アドバイザリーロックを取得できた1台だけが配信する
+type ReportScheduler struct {
+	reports *SavedReportService
+	locker  repositories.AdvisoryLockerInterface
+}
+
+// NewReportScheduler 新しいReportSchedulerを作成する
+func NewReportScheduler(reports *SavedReportService, locker repositories.AdvisoryLockerInterface) *ReportScheduler {
+	return &ReportScheduler{
+		reports: reports,
+		locker:  locker,
+	}
+}
+
+// ProcessDue 配信時刻を過ぎたレポートを配信し、配信したレポートの件数を返す
+// 他のサーバーが実行中の場合は何もせずに0を返す。
+// 次回配信時刻は送信前に保存するため、送信中にプロセスが停止しても同じ配信時刻で2回送信しない
+func (s *ReportScheduler) ProcessDue(ctx context.Context, now time.Time) (int, error) {
+	now = now.UTC()
+	processed := 0
+	_, err := s.locker.TryWithLock(ctx, reportSchedulerLockKey, func(ctx context.Context) error {
+		reports, err := s.reports.reportRepo.GetDue(ctx, now, reportSchedulerBatchSize)
+		if err != nil {
+			return err
+		}
+		for i := range reports {
+			if err := s.run(ctx, &reports[i], now); err != nil {
+				return err
+			}
+			processed++
+		}
+		return nil
+	})
+	return processed, err
+}
+
+// run 1つのレポートの次回の配信を予約してから、作成者の権限で集計して配信する
+// サーバーの停止中に過ぎた配信時刻があっても、まとめて1回だけ配信する
+func (s *ReportScheduler) run(ctx context.Context, report *models.SavedReport, now time.Time) error {
+	next, err := nextSavedReportRun(report, now)
+	if err != nil {
+		return s.stop(ctx, report, now, err)
+	}
+	report.NextRunAt, report.LastRunAt = &next, &now
+	if err := s.reports.reportRepo.UpdateSchedule(ctx, report); err != nil {
+		return err
+	}
+
+	app, err := s.reports.appRepo.GetByID(ctx, report.AppID)
+	if err != nil {
+		return err
+	}
+	if app == nil {
+		return s.stop(ctx, report, now, ErrAppNotFound)
+	}
+	runCtx, err := s.reports.creatorContext(ctx, report)
+	if err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		return s.stop(ctx, report, now, err)
+	}
+	_, err = s.reports.deliver(runCtx, app, report, models.ReportDeliveryTriggerSchedule, now)
+	return err
+}
+
+// stop 設定どおりに配信できなくなったレポートの予約を取り消し、理由を配信履歴に記録する
+// レポートを編集して保存し直すと再び予約される
+func (s *ReportScheduler) stop(ctx context.Context, report *models.SavedReport, now time.Time, reason error) error {
+	report.NextRunAt, report.LastRunAt = nil, &now
+	if err := s.reports.reportRepo.UpdateSchedule(ctx, report); err != nil {
+		return err
+	}
+	return s.reports.deliveryRepo.Create(ctx, &models.ReportDelivery{
+		ReportID:   report.ID,
+		AppID:      report.AppID,
+		Trigger:    models.ReportDeliveryTriggerSchedule,
+		Status:     models.ReportDeliveryFailed,
+		Recipients: report.Recipients,
+		Error:      truncateSavedReportError("レポートを配信できないため、定期配信を停止しました: " + reason.Error()),
+		CreatedAt:  now,
+	})
+}
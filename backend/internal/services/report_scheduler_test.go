@@ -0,0 +1,123 @@
+package services_test
+
+import (
+	"context"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"nocode-app/backend/internal/middleware"
+	"nocode-app/backend/internal/models"
+	"nocode-app/backend/internal/services"
+	"nocode-app/backend/internal/testhelpers/mocks"
+)
+
+func TestReportScheduler_ProcessDue(t *testing.T) {
+	ctx := context.Background()
+	// 2026-10-31（土）9:00 JST
+	now := time.Date(2026, 10, 31, 0, 0, 20, 0, time.UTC)
+	creatorID := uint64(5)
+	monthlyReport := func() models.SavedReport {
+		return models.SavedReport{
+			ID: 3, AppID: 1, Name: "月次レポート", ReportType: models.ReportTypePivot,
+			Config:    models.SavedReportConfig{Pivot: savedReportTestPivot()},
+			Frequency: models.ReportFrequencyMonthly, SendHour: 9, DayOfMonth: 28, Timezone: "Asia/Tokyo",
+			Recipients: []string{"a@example.com"}, IsActive: true, CreatedBy: &creatorID,
+		}
+	}
+
+	t.Run("does nothing without lock", func(t *testing.T) {
+		mockReportRepo := new(mocks.MockSavedReportRepository)
+		mockAppRepo := new(mocks.MockAppRepository)
+		locker := new(mocks.MockAdvisoryLocker)
+		locker.On("TryWithLock", mock.Anything, mock.Anything).Return(false, nil)
+		scheduler := services.NewReportScheduler(services.NewSavedReportService(mockReportRepo, new(mocks.MockReportDeliveryRepository), mockAppRepo, new(mocks.MockFieldRepository), new(mocks.MockUserRepository),
+			new(mocks.MockChartService), new(mocks.MockReportService), new(mocks.MockRecordService), newTestPermissionService(mockAppRepo), new(mocks.MockMailSender)), locker)
+
+		n, err := scheduler.ProcessDue(ctx, now)
+		require.NoError(t, err)
+		assert.Zero(t, n)
+		mockReportRepo.AssertNotCalled(t, "GetDue", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("delivers as the creator and reserves next run", func(t *testing.T) {
+		mockReportRepo := new(mocks.MockSavedReportRepository)
+		mockDeliveryRepo := new(mocks.MockReportDeliveryRepository)
+		mockAppRepo := new(mocks.MockAppRepository)
+		mockFieldRepo := new(mocks.MockFieldRepository)
+		mockUserRepo := new(mocks.MockUserRepository)
+		mockReports := new(mocks.MockReportService)
+		mockSender := new(mocks.MockMailSender)
+		locker := new(mocks.MockAdvisoryLocker)
+		locker.On("TryWithLock", mock.Anything, mock.Anything).Return(true, nil)
+		scheduler := services.NewReportScheduler(services.NewSavedReportService(mockReportRepo, mockDeliveryRepo, mockAppRepo, mockFieldRepo, mockUserRepo,
+			new(mocks.MockChartService), mockReports, new(mocks.MockRecordService), newTestPermissionService(mockAppRepo), mockSender), locker)
+		mockAppRepo.On("GetByID", mock.Anything, uint64(1)).Return(&models.App{ID: 1, Name: "営業管理", TableName: "app_data_1"}, nil)
+		mockFieldRepo.On("GetByAppID", mock.Anything, uint64(1)).Return(savedReportTestFields(), nil)
+
+		mockReportRepo.On("GetDue", mock.Anything, now, mock.Anything).Return([]models.SavedReport{monthlyReport()}, nil)
+
+		var saved time.Time
+		mockReportRepo.On("UpdateSchedule", mock.Anything, mock.AnythingOfType("*models.SavedReport")).Return(nil).Run(func(args mock.Arguments) {
+			saved = *args.Get(1).(*models.SavedReport).NextRunAt
+		}).Once()
+		mockUserRepo.On("GetByID", mock.Anything, creatorID).Return(&models.User{ID: creatorID, Role: "user"}, nil)
+		// 集計は作成者の権限で行う
+		mockReports.On("ExportPivot", mock.MatchedBy(func(ctx context.Context) bool {
+			claims, ok := middleware.GetUserFromContext(ctx)
+			return ok && claims.UserID == creatorID && claims.Role == "user"
+		}), uint64(1), mock.Anything, models.ExportFormatCSV, mock.Anything).Run(func(args mock.Arguments) {
+			_, _ = io.WriteString(args.Get(4).(io.Writer), "地域,件数\n東,2\n")
+		}).Return(nil)
+		mockSender.On("Send", mock.Anything, mock.Anything).Return(nil)
+		mockDeliveryRepo.On("Create", mock.Anything, mock.MatchedBy(func(d *models.ReportDelivery) bool {
+			return d.ReportID == 3 && d.Trigger == models.ReportDeliveryTriggerSchedule && d.Status == models.ReportDeliverySucceeded
+		})).Return(nil).Once()
+
+		n, err := scheduler.ProcessDue(ctx, now)
+		require.NoError(t, err)
+		assert.Equal(t, 1, n)
+		// 次回は11/28 9:00 JST
+		assert.Equal(t, time.Date(2026, 11, 28, 0, 0, 0, 0, time.UTC), saved)
+		mockReports.AssertExpectations(t)
+		mockDeliveryRepo.AssertExpectations(t)
+	})
+
+	t.Run("stops when the creator was deleted", func(t *testing.T) {
+		mockReportRepo := new(mocks.MockSavedReportRepository)
+		mockDeliveryRepo := new(mocks.MockReportDeliveryRepository)
+		mockAppRepo := new(mocks.MockAppRepository)
+		mockFieldRepo := new(mocks.MockFieldRepository)
+		mockUserRepo := new(mocks.MockUserRepository)
+		mockReports := new(mocks.MockReportService)
+		mockSender := new(mocks.MockMailSender)
+		locker := new(mocks.MockAdvisoryLocker)
+		locker.On("TryWithLock", mock.Anything, mock.Anything).Return(true, nil)
+		scheduler := services.NewReportScheduler(services.NewSavedReportService(mockReportRepo, mockDeliveryRepo, mockAppRepo, mockFieldRepo, mockUserRepo,
+			new(mocks.MockChartService), mockReports, new(mocks.MockRecordService), newTestPermissionService(mockAppRepo), mockSender), locker)
+		mockAppRepo.On("GetByID", mock.Anything, uint64(1)).Return(&models.App{ID: 1, Name: "営業管理", TableName: "app_data_1"}, nil)
+		mockFieldRepo.On("GetByAppID", mock.Anything, uint64(1)).Return(savedReportTestFields(), nil)
+
+		mockReportRepo.On("GetDue", mock.Anything, now, mock.Anything).Return([]models.SavedReport{monthlyReport()}, nil)
+		mockReportRepo.On("UpdateSchedule", mock.Anything, mock.AnythingOfType("*models.SavedReport")).Return(nil)
+		mockUserRepo.On("GetByID", mock.Anything, creatorID).Return(nil, nil)
+		mockDeliveryRepo.On("Create", mock.Anything, mock.MatchedBy(func(d *models.ReportDelivery) bool {
+			return d.Status == models.ReportDeliveryFailed && d.Error != ""
+		})).Return(nil).Once()
+
+		n, err := scheduler.ProcessDue(ctx, now)
+		require.NoError(t, err)
+		assert.Equal(t, 1, n)
+		// 最後の予約の取り消しで次回配信時刻が空になる
+		last := mockReportRepo.Calls[len(mockReportRepo.Calls)-1]
+		assert.Equal(t, "UpdateSchedule", last.Method)
+		assert.Nil(t, last.Arguments.Get(1).(*models.SavedReport).NextRunAt)
+		mockReports.AssertNotCalled(t, "ExportPivot", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+		mockSender.AssertNotCalled(t, "Send", mock.Anything, mock.Anything)
+		mockDeliveryRepo.AssertExpectations(t)
+	})
+}
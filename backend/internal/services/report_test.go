@@ -0,0 +1,207 @@
+package services_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"nocode-app/backend/internal/models"
+	"nocode-app/backend/internal/services"
+	"nocode-app/backend/internal/testhelpers/mocks"
+)
+
+func TestReportService_GetPivot(t *testing.T) {
+	ctx := systemContext()
+	app := &models.App{ID: 1, TableName: "app_data_1"}
+	fields := []models.AppField{
+		{ID: 1, AppID: 1, FieldCode: "status", FieldType: "select"},
+		{ID: 2, AppID: 1, FieldCode: "due", FieldType: "date"},
+		{ID: 3, AppID: 1, FieldCode: "amount", FieldType: "number"},
+		{ID: 4, AppID: 1, FieldCode: "tags", FieldType: "multiselect"},
+	}
+	newService := func() (*services.ReportService, *mocks.MockDynamicQueryExecutor) {
+		mockAppRepo := new(mocks.MockAppRepository)
+		mockFieldRepo := new(mocks.MockFieldRepository)
+		mockDynamicQuery := new(mocks.MockDynamicQueryExecutor)
+		service := services.NewReportService(mockFieldRepo, mockDynamicQuery,
+			new(mocks.MockDataSourceRepository), new(mocks.MockExternalQueryExecutor), newTestPermissionService(mockAppRepo))
+		mockAppRepo.On("GetByID", mock.Anything, uint64(1)).Return(app, nil)
+		mockFieldRepo.On("GetByAppID", mock.Anything, uint64(1)).Return(fields, nil)
+		return service, mockDynamicQuery
+	}
+
+	t.Run("resolves dimensions and filters", func(t *testing.T) {
+		service, mockDynamicQuery := newService()
+		mockDynamicQuery.On("GetPivotData", ctx, "app_data_1", mock.MatchedBy(func(req *models.PivotRequest) bool {
+			return req.Rows[0].DateOnly && !req.Columns[0].DateOnly && len(req.Filters) == 1
+		})).Return(&models.PivotResponse{}, nil)
+
+		_, err := service.GetPivot(ctx, 1, &models.PivotRequest{
+			Rows:     []models.PivotDimension{{Field: "due", Bucket: "month"}},
+			Columns:  []models.PivotDimension{{Field: "status"}},
+			Measures: []models.PivotMeasure{{Field: "amount", Aggregation: "sum"}},
+			Filters:  []models.FilterItem{{Field: "amount", Operator: "gt", Value: "0"}},
+		})
+		require.NoError(t, err)
+		mockDynamicQuery.AssertExpectations(t)
+	})
+
+	t.Run("own records only adds the creator filter", func(t *testing.T) {
+		mockAppRepo := new(mocks.MockAppRepository)
+		mockFieldRepo := new(mocks.MockFieldRepository)
+		mockDynamicQuery := new(mocks.MockDynamicQueryExecutor)
+		mockPermRepo := new(mocks.MockAppPermissionRepository)
+		mockGroupRepo := new(mocks.MockGroupRepository)
+		permissions := services.NewPermissionService(mockPermRepo, mockGroupRepo, mockAppRepo, new(mocks.MockUserRepository))
+		service := services.NewReportService(mockFieldRepo, mockDynamicQuery,
+			new(mocks.MockDataSourceRepository), new(mocks.MockExternalQueryExecutor), permissions)
+
+		userCtx := userContext(5, "user")
+		mockAppRepo.On("GetByID", userCtx, uint64(1)).Return(&models.App{ID: 1, TableName: "app_data_1", CreatedBy: 10}, nil)
+		mockFieldRepo.On("GetByAppID", userCtx, uint64(1)).Return(fields, nil)
+		mockPermRepo.On("HasAny", mock.Anything, uint64(1)).Return(true, nil)
+		mockGroupRepo.On("GetGroupIDsByUserID", mock.Anything, uint64(5)).Return([]uint64{}, nil)
+		mockPermRepo.On("GetForSubjects", mock.Anything, uint64(1), uint64(5), []uint64{}).Return([]models.AppPermission{
+			{Role: models.AppRoleViewer, OwnRecordsOnly: true},
+		}, nil)
+		mockDynamicQuery.On("GetPivotData", userCtx, "app_data_1", mock.MatchedBy(func(req *models.PivotRequest) bool {
+			return len(req.Filters) == 1 && req.Filters[0].Field == "created_by" && req.Filters[0].Value == "5"
+		})).Return(&models.PivotResponse{}, nil)
+
+		_, err := service.GetPivot(userCtx, 1, &models.PivotRequest{
+			Rows:     []models.PivotDimension{{Field: "status"}},
+			Measures: []models.PivotMeasure{{Aggregation: "count"}},
+		})
+		require.NoError(t, err)
+		mockDynamicQuery.AssertExpectations(t)
+	})
+
+	tests := []struct {
+		name string
+		req  *models.PivotRequest
+	}{
+		{
+			name: "unknown dimension",
+			req: &models.PivotRequest{
+				Rows:     []models.PivotDimension{{Field: "missing"}},
+				Measures: []models.PivotMeasure{{Aggregation: "count"}},
+			},
+		},
+		{
+			name: "multiselect dimension",
+			req: &models.PivotRequest{
+				Rows:     []models.PivotDimension{{Field: "tags"}},
+				Measures: []models.PivotMeasure{{Aggregation: "count"}},
+			},
+		},
+		{
+			name: "bucket on a select field",
+			req: &models.PivotRequest{
+				Rows:     []models.PivotDimension{{Field: "status", Bucket: "month"}},
+				Measures: []models.PivotMeasure{{Aggregation: "count"}},
+			},
+		},
+		{
+			name: "duplicated dimension",
+			req: &models.PivotRequest{
+				Rows:     []models.PivotDimension{{Field: "status"}, {Field: "status"}},
+				Measures: []models.PivotMeasure{{Aggregation: "count"}},
+			},
+		},
+		{
+			name: "sum of a non-number field",
+			req: &models.PivotRequest{
+				Rows:     []models.PivotDimension{{Field: "status"}},
+				Measures: []models.PivotMeasure{{Field: "due", Aggregation: "sum"}},
+			},
+		},
+		{
+			name: "count of an unknown field",
+			req: &models.PivotRequest{
+				Rows:     []models.PivotDimension{{Field: "status"}},
+				Measures: []models.PivotMeasure{{Field: "missing", Aggregation: "count"}},
+			},
+		},
+		{
+			name: "invalid time zone",
+			req: &models.PivotRequest{
+				Rows:     []models.PivotDimension{{Field: "due", Bucket: "day"}},
+				Measures: []models.PivotMeasure{{Aggregation: "count"}},
+				TimeZone: "Mars/Olympus",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, mockDynamicQuery := newService()
+
+			_, err := service.GetPivot(ctx, 1, tt.req)
+			assert.ErrorIs(t, err, services.ErrInvalidReportConfig)
+			mockDynamicQuery.AssertNotCalled(t, "GetPivotData", mock.Anything, mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestReportService_ExportPivot(t *testing.T) {
+	ctx := systemContext()
+	num := func(v float64) *float64 { return &v }
+	newService := func() (*services.ReportService, *mocks.MockDynamicQueryExecutor) {
+		mockAppRepo := new(mocks.MockAppRepository)
+		mockFieldRepo := new(mocks.MockFieldRepository)
+		mockDynamicQuery := new(mocks.MockDynamicQueryExecutor)
+		service := services.NewReportService(mockFieldRepo, mockDynamicQuery,
+			new(mocks.MockDataSourceRepository), new(mocks.MockExternalQueryExecutor), newTestPermissionService(mockAppRepo))
+		mockAppRepo.On("GetByID", ctx, uint64(1)).Return(&models.App{ID: 1, TableName: "app_data_1"}, nil)
+		mockFieldRepo.On("GetByAppID", ctx, uint64(1)).Return([]models.AppField{
+			{FieldCode: "region", FieldType: "text"},
+			{FieldCode: "status", FieldType: "select"},
+			{FieldCode: "amount", FieldType: "number"},
+		}, nil)
+		return service, mockDynamicQuery
+	}
+	req := &models.PivotRequest{
+		Rows:     []models.PivotDimension{{Field: "region"}},
+		Columns:  []models.PivotDimension{{Field: "status"}},
+		Measures: []models.PivotMeasure{{Aggregation: "count"}, {Field: "amount", Aggregation: "sum"}},
+	}
+
+	t.Run("csv with total labels", func(t *testing.T) {
+		service, mockDynamicQuery := newService()
+		mockDynamicQuery.On("GetPivotData", ctx, "app_data_1", mock.Anything).Return(&models.PivotResponse{
+			RowFields:    []string{"地域"},
+			ColumnFields: []string{"status"},
+			Measures:     []string{"件数", "金額"},
+			Columns: []models.PivotHeader{
+				{Keys: []string{"open"}},
+				{Keys: []string{}, Total: true},
+			},
+			Rows: []models.PivotRow{
+				{PivotHeader: models.PivotHeader{Keys: []string{"東"}}, Values: [][]*float64{{num(2), num(300)}, {num(2), num(300)}}},
+				{PivotHeader: models.PivotHeader{Keys: []string{"西"}}, Values: [][]*float64{{nil, nil}, {num(1), num(50)}}},
+				{PivotHeader: models.PivotHeader{Keys: []string{}, Total: true}, Values: [][]*float64{{num(2), num(300)}, {num(3), num(350)}}},
+			},
+		}, nil)
+
+		var buf bytes.Buffer
+		require.NoError(t, service.ExportPivot(ctx, 1, req, models.ExportFormatCSV, &buf))
+
+		lines := strings.Split(strings.TrimSpace(strings.TrimPrefix(buf.String(), "\ufeff")), "\n")
+		require.Len(t, lines, 4)
+		assert.Equal(t, "地域,open / 件数,open / 金額,総計 / 件数,総計 / 金額", lines[0])
+		assert.Equal(t, "東,2,300,2,300", lines[1])
+		assert.Equal(t, "西,,,1,50", lines[2])
+		assert.Equal(t, "総計,2,300,3,350", lines[3])
+	})
+
+	t.Run("ndjson is not supported", func(t *testing.T) {
+		service, mockDynamicQuery := newService()
+
+		err := service.ExportPivot(ctx, 1, req, models.ExportFormatNDJSON, &bytes.Buffer{})
+		assert.ErrorIs(t, err, services.ErrInvalidExportFormat)
+		mockDynamicQuery.AssertNotCalled(t, "GetPivotData", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
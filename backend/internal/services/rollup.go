@@ -0,0 +1,173 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"nocode-app/backend/internal/models"
+)
+
+// 集計フィールドのオプションキー（集計する子アプリのIDは OptionReferenceApp を使う）
+const (
+	// OptionLinkField 子レコードで親レコードのIDを保持するフィールドコード
+	OptionLinkField = "link_field"
+	// OptionAggregation 集計方法
+	OptionAggregation = "aggregation"
+	// OptionTargetField 集計する子レコードのフィールドコード
+	OptionTargetField = "target_field"
+	// OptionFilters 集計する子レコードの絞り込み条件
+	OptionFilters = "filters"
+)
+
+// rollupFilterOperators 集計フィールドの絞り込み条件で使える演算子
+var rollupFilterOperators = map[string]bool{
+	"eq": true, "ne": true, "gt": true, "gte": true, "lt": true, "lte": true, "like": true,
+}
+
+// resolveRollup 集計フィールドのオプションを検証し、正規化したオプションを設定する
+// 親レコードのIDを保持するリンクフィールドは、このアプリへの参照フィールド・数値・文字列のいずれかとする
+func (r *referenceResolver) resolveRollup(ctx context.Context, app *models.App, field *models.AppField) error {
+	if app.IsExternal {
+		return fmt.Errorf("%w: 外部データソースのアプリには集計フィールドを作成できません", ErrInvalidFieldOptions)
+	}
+
+	childID, ok := referenceAppID(field.Options)
+	if !ok {
+		return fmt.Errorf("%w: 集計するアプリ（%s）を指定してください", ErrInvalidFieldOptions, OptionReferenceApp)
+	}
+	child, err := r.appRepo.GetByID(ctx, childID)
+	if err != nil {
+		return err
+	}
+	if child == nil {
+		return fmt.Errorf("%w: 集計するアプリが見つかりません", ErrInvalidFieldOptions)
+	}
+	if child.IsExternal {
+		return fmt.Errorf("%w: 外部データソースのアプリは集計できません", ErrInvalidFieldOptions)
+	}
+	// 閲覧できないアプリは集計させない
+	if _, err := r.permissions.CheckAppAccess(ctx, child, models.AppRoleViewer); err != nil {
+		if errors.Is(err, ErrAppNotFound) {
+			return fmt.Errorf("%w: 集計するアプリが見つかりません", ErrInvalidFieldOptions)
+		}
+		return err
+	}
+
+	childFields, err := r.fieldRepo.GetByAppID(ctx, child.ID)
+	if err != nil {
+		return err
+	}
+
+	linkCode, _ := optionString(field.Options, OptionLinkField)
+	link := findField(childFields, linkCode)
+	if link == nil || !isRollupLink(link, app.ID) {
+		return fmt.Errorf("%w: 親レコードのIDを保持する子アプリのフィールド（%s）を指定してください", ErrInvalidFieldOptions, OptionLinkField)
+	}
+
+	raw, _ := optionString(field.Options, OptionAggregation)
+	aggregation := models.RollupAggregation(raw)
+	if !aggregation.IsValid() {
+		return fmt.Errorf("%w: %s は count / sum / avg / min / max のいずれかを指定してください", ErrInvalidFieldOptions, OptionAggregation)
+	}
+
+	options := copyOptions(field.Options)
+	options[OptionReferenceApp] = child.ID
+	options[OptionAggregation] = string(aggregation)
+	if aggregation == models.RollupCount {
+		delete(options, OptionTargetField)
+	} else {
+		targetCode, _ := optionString(field.Options, OptionTargetField)
+		target := findField(childFields, targetCode)
+		if target == nil || !isRollupTarget(target, aggregation) {
+			return fmt.Errorf("%w: 集計方法 %s で集計できる子アプリのフィールド（%s）を指定してください", ErrInvalidFieldOptions, aggregation, OptionTargetField)
+		}
+	}
+
+	// 絞り込み条件は構造体に変換して検証する
+	rollup := &models.AppField{FieldType: string(models.FieldTypeRollup), Options: options}
+	parsed, err := rollup.Rollup()
+	if err != nil {
+		return fmt.Errorf("%w: %s の形式が正しくありません", ErrInvalidFieldOptions, OptionFilters)
+	}
+	for _, filter := range parsed.Filters {
+		if f := findField(childFields, filter.Field); f == nil || !f.HasColumn() {
+			return fmt.Errorf("%w: 絞り込み条件のフィールド %q は子アプリに存在しません", ErrInvalidFieldOptions, filter.Field)
+		}
+		if !rollupFilterOperators[filter.Operator] {
+			return fmt.Errorf("%w: 絞り込み条件の演算子 %q は使用できません", ErrInvalidFieldOptions, filter.Operator)
+		}
+	}
+	if len(parsed.Filters) == 0 {
+		delete(options, OptionFilters)
+	} else {
+		options[OptionFilters] = parsed.Filters
+	}
+
+	field.Options = options
+	// 集計フィールドは入力しないため必須にできない
+	field.Required = false
+	return nil
+}
+
+// isRollupLink 子アプリのフィールドが親レコードのIDを保持できるかどうかを判定する
+func isRollupLink(link *models.AppField, parentID uint64) bool {
+	switch models.FieldType(link.FieldType) {
+	case models.FieldTypeReference:
+		targetID, ok := referenceAppID(link.Options)
+		return ok && targetID == parentID
+	case models.FieldTypeNumber, models.FieldTypeText:
+		return true
+	}
+	return false
+}
+
+// isRollupTarget 集計方法で子アプリのフィールドを集計できるかどうかを判定する
+// 計算フィールドは結果の型で判定する
+func isRollupTarget(target *models.AppField, aggregation models.RollupAggregation) bool {
+	t := models.FieldType(target.FieldType)
+	if t == models.FieldTypeFormula {
+		t = target.FormulaResultType()
+	}
+	switch aggregation {
+	case models.RollupSum, models.RollupAvg:
+		return t == models.FieldTypeNumber
+	case models.RollupMin, models.RollupMax:
+		return t == models.FieldTypeNumber || t == models.FieldTypeDate || t == models.FieldTypeDateTime
+	}
+	return false
+}
+
+// isRollupUsing 集計フィールドが子アプリのフィールドを使っているかどうかを判定する
+func isRollupUsing(rollup *models.AppField, code string) bool {
+	opts, err := rollup.Rollup()
+	if err != nil {
+		return false
+	}
+	if opts.LinkField == code || opts.TargetField == code {
+		return true
+	}
+	for _, filter := range opts.Filters {
+		if filter.Field == code {
+			return true
+		}
+	}
+	return false
+}
+
+// storedFields 集計フィールドを除いたフィールドを返す
+// 集計値は子レコードから読み取り時に求めるため、変更履歴や書き込み操作のレスポンスには含めない
+func storedFields(fields []models.AppField) []models.AppField {
+	for i := range fields {
+		if models.FieldType(fields[i].FieldType) == models.FieldTypeRollup {
+			stored := make([]models.AppField, 0, len(fields))
+			for j := range fields {
+				if models.FieldType(fields[j].FieldType) != models.FieldTypeRollup {
+					stored = append(stored, fields[j])
+				}
+			}
+			return stored
+		}
+	}
+	return fields
+}
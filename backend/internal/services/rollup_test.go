@@ -0,0 +1,207 @@
+package services_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"nocode-app/backend/internal/models"
+	"nocode-app/backend/internal/repositories"
+	"nocode-app/backend/internal/services"
+	"nocode-app/backend/internal/testhelpers/mocks"
+)
+
+// 集計のテストで使うアプリ構成: 顧客（app 2）が注文（app 3）を集計する
+var (
+	lineApp = &models.App{ID: 3, TableName: "app_data_3", CreatedBy: 1}
+
+	lineFields = []models.AppField{
+		{ID: 20, AppID: 3, FieldCode: "customer", FieldName: "顧客", FieldType: "reference", DisplayOrder: 1,
+			Options: models.FieldOptions{"app_id": float64(2), "on_delete": "restrict"}},
+		{ID: 21, AppID: 3, FieldCode: "amount", FieldName: "金額", FieldType: "number", DisplayOrder: 2},
+		{ID: 22, AppID: 3, FieldCode: "status", FieldName: "状態", FieldType: "text", DisplayOrder: 3},
+		{ID: 23, AppID: 3, FieldCode: "ordered_on", FieldName: "注文日", FieldType: "date", DisplayOrder: 4},
+	}
+	totalField = models.AppField{ID: 12, AppID: 2, FieldCode: "total", FieldName: "注文合計", FieldType: "rollup", DisplayOrder: 3,
+		Options: models.FieldOptions{"app_id": float64(3), "link_field": "customer", "aggregation": "sum", "target_field": "amount",
+			"filters": []interface{}{map[string]interface{}{"field": "status", "operator": "eq", "value": "paid"}}}}
+)
+
+func TestFieldService_CreateField_Rollup(t *testing.T) {
+	ctx := systemContext()
+
+	newService := func() (*services.FieldService, *mocks.MockFieldRepository, *mocks.MockDynamicQueryExecutor) {
+		mockFieldRepo := new(mocks.MockFieldRepository)
+		mockAppRepo := new(mocks.MockAppRepository)
+		mockDynamicQuery := new(mocks.MockDynamicQueryExecutor)
+
+		mockAppRepo.On("GetByID", ctx, uint64(2)).Return(customerApp, nil)
+		mockAppRepo.On("GetByID", ctx, uint64(3)).Return(lineApp, nil)
+		mockFieldRepo.On("FieldCodeExists", ctx, mock.Anything, mock.Anything).Return(false, nil)
+		mockFieldRepo.On("GetMaxDisplayOrder", ctx, mock.Anything).Return(2, nil)
+		mockFieldRepo.On("GetByAppID", ctx, uint64(3)).Return(lineFields, nil)
+		return services.NewFieldService(mockFieldRepo, mockAppRepo, mockDynamicQuery, newTestPermissionService(mockAppRepo), newTestTransactor(), newTestWebhookPublisher(), newTestAttachmentManager()), mockFieldRepo, mockDynamicQuery
+	}
+
+	t.Run("normalizes options", func(t *testing.T) {
+		service, mockFieldRepo, mockDynamicQuery := newService()
+		mockFieldRepo.On("Create", ctx, mock.AnythingOfType("*models.AppField")).Return(nil)
+		mockDynamicQuery.On("AddColumn", ctx, "app_data_2", mock.AnythingOfType("*models.AppField")).Return(nil)
+
+		resp, err := service.CreateField(ctx, 2, &models.CreateFieldRequest{
+			FieldCode: "order_count", FieldName: "注文数", FieldType: "rollup", Required: true,
+			Options: models.FieldOptions{"app_id": "3", "link_field": "customer", "aggregation": "count", "target_field": "amount"},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, uint64(3), resp.Options["app_id"])
+		assert.NotContains(t, resp.Options, "target_field")
+		assert.False(t, resp.Required)
+	})
+
+	t.Run("keeps filters", func(t *testing.T) {
+		service, mockFieldRepo, mockDynamicQuery := newService()
+		mockFieldRepo.On("Create", ctx, mock.AnythingOfType("*models.AppField")).Return(nil)
+		mockDynamicQuery.On("AddColumn", ctx, "app_data_2", mock.AnythingOfType("*models.AppField")).Return(nil)
+
+		resp, err := service.CreateField(ctx, 2, &models.CreateFieldRequest{
+			FieldCode: "total", FieldName: "注文合計", FieldType: "rollup", Options: totalField.Options,
+		})
+		require.NoError(t, err)
+		assert.Equal(t, []models.FilterItem{{Field: "status", Operator: "eq", Value: "paid"}}, resp.Options["filters"])
+	})
+
+	invalid := map[string]models.FieldOptions{
+		"missing app":            {"link_field": "customer", "aggregation": "count"},
+		"unknown link":           {"app_id": float64(3), "link_field": "missing", "aggregation": "count"},
+		"link is not id":         {"app_id": float64(3), "link_field": "ordered_on", "aggregation": "count"},
+		"unknown aggregation":    {"app_id": float64(3), "link_field": "customer", "aggregation": "median"},
+		"sum of text":            {"app_id": float64(3), "link_field": "customer", "aggregation": "sum", "target_field": "status"},
+		"missing target":         {"app_id": float64(3), "link_field": "customer", "aggregation": "max"},
+		"filter on unknown":      {"app_id": float64(3), "link_field": "customer", "aggregation": "count", "filters": []interface{}{map[string]interface{}{"field": "missing", "operator": "eq", "value": "1"}}},
+		"unsupported operator":   {"app_id": float64(3), "link_field": "customer", "aggregation": "count", "filters": []interface{}{map[string]interface{}{"field": "status", "operator": "in", "value": "a"}}},
+		"malformed filters":      {"app_id": float64(3), "link_field": "customer", "aggregation": "count", "filters": "status=paid"},
+		"reference to other app": {"app_id": float64(3), "link_field": "customer", "aggregation": "count"},
+	}
+	for name, options := range invalid {
+		t.Run(name, func(t *testing.T) {
+			service, _, _ := newService()
+			appID := uint64(2)
+			if name == "reference to other app" {
+				// 注文の顧客フィールドは顧客アプリを参照しているため、注文アプリ自身では使えない
+				appID = 3
+			}
+
+			_, err := service.CreateField(ctx, appID, &models.CreateFieldRequest{
+				FieldCode: "rollup", FieldName: "集計", FieldType: "rollup", Options: options,
+			})
+			assert.ErrorIs(t, err, services.ErrInvalidFieldOptions)
+		})
+	}
+
+	t.Run("max of date", func(t *testing.T) {
+		service, mockFieldRepo, mockDynamicQuery := newService()
+		mockFieldRepo.On("Create", ctx, mock.AnythingOfType("*models.AppField")).Return(nil)
+		mockDynamicQuery.On("AddColumn", ctx, "app_data_2", mock.AnythingOfType("*models.AppField")).Return(nil)
+
+		_, err := service.CreateField(ctx, 2, &models.CreateFieldRequest{
+			FieldCode: "last_ordered", FieldName: "最終注文日", FieldType: "rollup",
+			Options: models.FieldOptions{"app_id": float64(3), "link_field": "customer", "aggregation": "max", "target_field": "ordered_on"},
+		})
+		require.NoError(t, err)
+	})
+}
+
+func TestFieldService_DeleteField_UsedByRollup(t *testing.T) {
+	ctx := systemContext()
+
+	for _, fieldID := range []uint64{20, 21, 22} {
+		mockFieldRepo := new(mocks.MockFieldRepository)
+		mockAppRepo := new(mocks.MockAppRepository)
+		mockDynamicQuery := new(mocks.MockDynamicQueryExecutor)
+
+		field := findTestField(lineFields, fieldID)
+		mockFieldRepo.On("GetByID", ctx, fieldID).Return(field, nil)
+		mockAppRepo.On("GetByID", ctx, uint64(3)).Return(lineApp, nil)
+		mockFieldRepo.On("GetByAppID", ctx, uint64(3)).Return(lineFields, nil)
+		mockFieldRepo.On("GetReferencingFields", ctx, uint64(3)).Return([]models.AppField{totalField}, nil)
+
+		service := services.NewFieldService(mockFieldRepo, mockAppRepo, mockDynamicQuery, newTestPermissionService(mockAppRepo), newTestTransactor(), newTestWebhookPublisher(), newTestAttachmentManager())
+
+		// リンク・集計対象・絞り込み条件のフィールドはいずれも削除できない
+		err := service.DeleteField(ctx, 3, fieldID, 0)
+		assert.ErrorIs(t, err, services.ErrFieldInUse, field.FieldCode)
+		mockDynamicQuery.AssertNotCalled(t, "DropColumn", mock.Anything, mock.Anything, mock.Anything)
+	}
+}
+
+func TestAppService_DeleteApp_Rollup(t *testing.T) {
+	ctx := systemContext()
+
+	mockAppRepo := new(mocks.MockAppRepository)
+	mockFieldRepo := new(mocks.MockFieldRepository)
+	mockDynamicQuery := new(mocks.MockDynamicQueryExecutor)
+
+	mockAppRepo.On("GetByID", ctx, uint64(3)).Return(lineApp, nil)
+	mockFieldRepo.On("GetReferencingFields", ctx, uint64(3)).Return([]models.AppField{totalField}, nil)
+
+	service := services.NewAppService(mockAppRepo, mockFieldRepo, mockDynamicQuery, new(mocks.MockDataSourceRepository), newTestTransactor(), newTestPermissionService(mockAppRepo), newTestWebhookPublisher(), newTestAttachmentManager())
+
+	err := service.DeleteApp(ctx, 3)
+	assert.ErrorIs(t, err, services.ErrAppReferenced)
+	mockDynamicQuery.AssertNotCalled(t, "DropTable", mock.Anything, mock.Anything)
+}
+
+func TestRecordService_GetRecords_Rollup(t *testing.T) {
+	ctx := systemContext()
+
+	fields := append(cloneFields(customerFields), totalField)
+	records := []models.RecordResponse{
+		{ID: 7, CreatedBy: 5, Data: models.RecordData{"name": "山田商店", "total": "1500.0000"}},
+	}
+
+	tests := []struct {
+		name      string
+		access    *models.AppAccess
+		accessErr error
+		want      interface{}
+	}{
+		{name: "visible with access to child app", access: &models.AppAccess{UserID: 5, Role: models.AppRoleViewer}, want: "1500.0000"},
+		{name: "hidden without access to child app", accessErr: services.ErrPermissionDenied, want: nil},
+		{name: "hidden when child app is own records only", access: &models.AppAccess{UserID: 5, Role: models.AppRoleViewer, OwnRecordsOnly: true}, want: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockAppRepo := new(mocks.MockAppRepository)
+			mockFieldRepo := new(mocks.MockFieldRepository)
+			mockDynamicQuery := new(mocks.MockDynamicQueryExecutor)
+			mockPermissions := new(mocks.MockPermissionService)
+
+			mockAppRepo.On("GetByID", ctx, uint64(3)).Return(lineApp, nil)
+			mockPermissions.On("AuthorizeApp", ctx, uint64(2), models.AppRoleViewer).Return(customerApp, &models.AppAccess{UserID: 5, Role: models.AppRoleViewer}, nil)
+			mockPermissions.On("CheckAppAccess", ctx, lineApp, models.AppRoleViewer).Return(tt.access, tt.accessErr)
+			mockFieldRepo.On("GetByAppID", ctx, uint64(2)).Return(fields, nil)
+			mockFieldRepo.On("GetByAppID", ctx, uint64(3)).Return(lineFields, nil)
+			mockDynamicQuery.On("GetRecords", ctx, "app_data_2", fields, mock.Anything).Return(cloneRecords(records), int64(1), nil)
+
+			service := services.NewRecordService(mockAppRepo, mockFieldRepo, mockDynamicQuery, new(mocks.MockDataSourceRepository), new(mocks.MockExternalQueryExecutor), mockPermissions, new(mocks.MockRecordRevisionRepository), newTestTransactor(), newTestWebhookPublisher(), newTestAutomationRunner(), newTestAttachmentManager())
+
+			resp, err := service.GetRecords(ctx, 2, repositories.RecordQueryOptions{Page: 1, Limit: 20})
+			require.NoError(t, err)
+			require.Len(t, resp.Records, 1)
+			assert.Equal(t, tt.want, resp.Records[0].Data["total"])
+			assert.Equal(t, "山田商店", resp.Records[0].Data["name"])
+		})
+	}
+}
+
+func findTestField(fields []models.AppField, id uint64) *models.AppField {
+	for i := range fields {
+		if fields[i].ID == id {
+			field := fields[i]
+			return &field
+		}
+	}
+	return nil
+}
@@ -0,0 +1,638 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"html/template"
+	"io"
+	"strings"
+	"time"
+
+	"nocode-app/backend/internal/mail"
+	"nocode-app/backend/internal/middleware"
+	"nocode-app/backend/internal/models"
+	"nocode-app/backend/internal/repositories"
+	"nocode-app/backend/internal/utils"
+)
+
+// 保存したレポート関連エラー
+var (
+	ErrSavedReportNotFound = errors.New("レポートが見つかりません")
+	ErrInvalidSavedReport  = errors.New("レポートの設定が正しくありません")
+	// ErrReportTooLarge 添付するCSVファイルがメールで送れる大きさを超えた
+	ErrReportTooLarge = errors.New("レポートが大きすぎるためメールで送信できません。絞り込み条件で件数を減らしてください")
+)
+
+const (
+	// savedReportDefaultTimezone タイムゾーンを指定しないレポートのタイムゾーン
+	savedReportDefaultTimezone = "UTC"
+	// savedReportPreviewRows メール本文に表示する行数
+	savedReportPreviewRows = 20
+	// savedReportMaxAttachmentSize 添付するCSVファイルの最大サイズ
+	savedReportMaxAttachmentSize = 10 << 20
+	// savedReportMaxErrorLength 配信履歴に保存するエラーメッセージの最大文字数
+	savedReportMaxErrorLength = 500
+)
+
+// savedReportSortColumns レコードの一覧のレポートで、フィールド以外に並び替えに指定できるカラム
+var savedReportSortColumns = map[string]bool{"id": true, "created_by": true, "created_at": true, "updated_at": true}
+
+// savedReportMailTemplate 配信するメールの本文（先頭の行を表で表示し、全ての行はCSVファイルを添付する）
+var savedReportMailTemplate = template.Must(template.New("report").Parse(`<!DOCTYPE html>
+<html>
+<body style="font-family: sans-serif; color: #333;">
+<h2 style="margin-bottom: 4px;">{{.ReportName}}</h2>
+<p style="margin-top: 0; color: #666;">{{.AppName}} ／ {{.GeneratedAt}}（{{.Timezone}}）時点</p>
+{{if .Rows}}<p>全{{.RowCount}}行{{if .Truncated}}のうち先頭の{{len .Rows}}行を表示しています。全ての行は添付のCSVファイルをご覧ください{{end}}。</p>
+<table style="border-collapse: collapse; font-size: 13px;">
+<thead><tr>{{range .Headers}}<th style="border: 1px solid #ccc; background: #f5f5f5; padding: 4px 8px; text-align: left;">{{.}}</th>{{end}}</tr></thead>
+<tbody>
+{{range .Rows}}<tr>{{range .}}<td style="border: 1px solid #ccc; padding: 4px 8px;">{{.}}</td>{{end}}</tr>
+{{end}}</tbody>
+</table>{{else}}<p>該当するデータはありません。</p>{{end}}
+</body>
+</html>
+`))
+
+// savedReportMailData メール本文の表示内容
+type savedReportMailData struct {
+	ReportName  string
+	AppName     string
+	GeneratedAt string
+	Timezone    string
+	Headers     []string
+	Rows        [][]string
+	RowCount    int
+	Truncated   bool
+}
+
+// SavedReportService 保存したレポートの管理と、メールでの配信を処理する構造体
+type SavedReportService struct {
+	reportRepo   repositories.SavedReportRepositoryInterface
+	deliveryRepo repositories.ReportDeliveryRepositoryInterface
+	appRepo      repositories.AppRepositoryInterface
+	fieldRepo    repositories.FieldRepositoryInterface
+	userRepo     repositories.UserRepositoryInterface
+	charts       ChartServiceInterface
+	reports      ReportServiceInterface
+	records      RecordServiceInterface
+	permissions  PermissionServiceInterface
+	sender       mail.Sender
+}
+
+// NewSavedReportService 新しいSavedReportServiceを作成する
+func NewSavedReportService(
+	reportRepo repositories.SavedReportRepositoryInterface,
+	deliveryRepo repositories.ReportDeliveryRepositoryInterface,
+	appRepo repositories.AppRepositoryInterface,
+	fieldRepo repositories.FieldRepositoryInterface,
+	userRepo repositories.UserRepositoryInterface,
+	charts ChartServiceInterface,
+	reports ReportServiceInterface,
+	records RecordServiceInterface,
+	permissions PermissionServiceInterface,
+	sender mail.Sender,
+) *SavedReportService {
+	return &SavedReportService{
+		reportRepo:   reportRepo,
+		deliveryRepo: deliveryRepo,
+		appRepo:      appRepo,
+		fieldRepo:    fieldRepo,
+		userRepo:     userRepo,
+		charts:       charts,
+		reports:      reports,
+		records:      records,
+		permissions:  permissions,
+		sender:       sender,
+	}
+}
+
+// getReport アプリに保存されたレポートを取得する
+func (s *SavedReportService) getReport(ctx context.Context, appID, reportID uint64, required models.AppRole) (*models.App, *models.SavedReport, error) {
+	app, _, err := s.permissions.AuthorizeApp(ctx, appID, required)
+	if err != nil {
+		return nil, nil, err
+	}
+	report, err := s.reportRepo.GetByID(ctx, reportID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if report == nil || report.AppID != appID {
+		return nil, nil, ErrSavedReportNotFound
+	}
+	return app, report, nil
+}
+
+// GetReports アプリに保存されたレポートの一覧を取得する
+func (s *SavedReportService) GetReports(ctx context.Context, appID uint64) (*models.SavedReportListResponse, error) {
+	if _, _, err := s.permissions.AuthorizeApp(ctx, appID, models.AppRoleViewer); err != nil {
+		return nil, err
+	}
+	reports, err := s.reportRepo.GetByAppID(ctx, appID)
+	if err != nil {
+		return nil, err
+	}
+	return &models.SavedReportListResponse{Reports: reports}, nil
+}
+
+// GetReport 保存したレポートを取得する
+func (s *SavedReportService) GetReport(ctx context.Context, appID, reportID uint64) (*models.SavedReport, error) {
+	_, report, err := s.getReport(ctx, appID, reportID, models.AppRoleViewer)
+	return report, err
+}
+
+// CreateReport アプリにレポートを保存し、定期配信する場合は次回の配信を予約する
+func (s *SavedReportService) CreateReport(ctx context.Context, appID, userID uint64, req *models.SaveReportRequest) (*models.SavedReport, error) {
+	app, _, err := s.permissions.AuthorizeApp(ctx, appID, models.AppRoleEditor)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	report := &models.SavedReport{
+		AppID:     appID,
+		IsActive:  true,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if userID != 0 {
+		report.CreatedBy = &userID
+	}
+	applySaveReportRequest(report, req)
+	if err := s.validateReport(ctx, app, report); err != nil {
+		return nil, err
+	}
+	scheduleSavedReport(report, now)
+
+	if err := s.reportRepo.Create(ctx, report); err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+// UpdateReport 保存したレポートの設定を置き換える
+func (s *SavedReportService) UpdateReport(ctx context.Context, appID, reportID uint64, req *models.SaveReportRequest) (*models.SavedReport, error) {
+	app, report, err := s.getReport(ctx, appID, reportID, models.AppRoleEditor)
+	if err != nil {
+		return nil, err
+	}
+
+	before := *report
+	applySaveReportRequest(report, req)
+	report.UpdatedAt = time.Now()
+	if err := s.validateReport(ctx, app, report); err != nil {
+		return nil, err
+	}
+
+	if err := s.reportRepo.Update(ctx, report); err != nil {
+		return nil, err
+	}
+	// 配信のタイミングが変わった場合（再度有効にした場合を含む）は、現在時刻から次回の配信を予約し直す
+	if savedReportScheduleChanged(&before, report) {
+		scheduleSavedReport(report, report.UpdatedAt)
+		if err := s.reportRepo.UpdateSchedule(ctx, report); err != nil {
+			return nil, err
+		}
+	}
+	return report, nil
+}
+
+// DeleteReport 保存したレポートと配信履歴を削除する
+func (s *SavedReportService) DeleteReport(ctx context.Context, appID, reportID uint64) error {
+	if _, _, err := s.getReport(ctx, appID, reportID, models.AppRoleEditor); err != nil {
+		return err
+	}
+	return s.reportRepo.Delete(ctx, reportID)
+}
+
+// SendReport 保存したレポートを呼び出し元の権限で集計し、すぐに宛先へ送信する
+// 集計やメールの送信に失敗した場合も配信履歴に記録し、失敗した履歴を返す
+func (s *SavedReportService) SendReport(ctx context.Context, appID, reportID uint64) (*models.ReportDelivery, error) {
+	app, report, err := s.getReport(ctx, appID, reportID, models.AppRoleEditor)
+	if err != nil {
+		return nil, err
+	}
+	if len(report.Recipients) == 0 {
+		return nil, fmt.Errorf("%w: 宛先がありません", ErrInvalidSavedReport)
+	}
+	return s.deliver(ctx, app, report, models.ReportDeliveryTriggerManual, time.Now())
+}
+
+// GetDeliveries レポートの配信履歴を新しい順に取得する
+func (s *SavedReportService) GetDeliveries(ctx context.Context, appID, reportID uint64, page, limit int) (*models.ReportDeliveryListResponse, error) {
+	if _, _, err := s.getReport(ctx, appID, reportID, models.AppRoleViewer); err != nil {
+		return nil, err
+	}
+
+	deliveries, total, err := s.deliveryRepo.GetByReportID(ctx, reportID, page, limit)
+	if err != nil {
+		return nil, err
+	}
+	return &models.ReportDeliveryListResponse{
+		Deliveries: deliveries,
+		Pagination: models.NewPagination(page, limit, total),
+	}, nil
+}
+
+// applySaveReportRequest リクエストの内容をレポートに設定する
+func applySaveReportRequest(report *models.SavedReport, req *models.SaveReportRequest) {
+	report.Name = req.Name
+	report.ReportType = req.ReportType
+	report.Config = req.Config
+	report.Frequency = req.Frequency
+	report.SendHour = req.SendHour
+	report.Weekday = req.Weekday
+	report.DayOfMonth = req.DayOfMonth
+	report.Timezone = req.Timezone
+	report.Recipients = req.Recipients
+	if req.IsActive != nil {
+		report.IsActive = *req.IsActive
+	}
+}
+
+// validateReport 集計の設定がアプリのフィールド定義と整合しているか、配信の設定が正しいかを確認し、
+// レポートの種類や配信頻度で使わない設定を空にする
+func (s *SavedReportService) validateReport(ctx context.Context, app *models.App, report *models.SavedReport) error {
+	fields, err := s.fieldRepo.GetByAppID(ctx, app.ID)
+	if err != nil {
+		return err
+	}
+	if err := validateSavedReportConfig(app, fields, report); err != nil {
+		return err
+	}
+
+	if report.Timezone == "" {
+		report.Timezone = savedReportDefaultTimezone
+	}
+	if _, err := time.LoadLocation(report.Timezone); err != nil {
+		return fmt.Errorf("%w: タイムゾーン %q が存在しません", ErrInvalidSavedReport, report.Timezone)
+	}
+	switch report.Frequency {
+	case models.ReportFrequencyNone:
+		report.SendHour, report.Weekday, report.DayOfMonth = 0, 0, 1
+	case models.ReportFrequencyDaily:
+		report.Weekday, report.DayOfMonth = 0, 1
+	case models.ReportFrequencyWeekly:
+		report.DayOfMonth = 1
+	case models.ReportFrequencyMonthly:
+		report.Weekday = 0
+		if report.DayOfMonth == 0 {
+			report.DayOfMonth = 1
+		}
+	}
+
+	// 同じ宛先に2通送らない
+	recipients := make([]string, 0, len(report.Recipients))
+	seen := make(map[string]bool, len(report.Recipients))
+	for _, to := range report.Recipients {
+		if err := mail.ValidateAddress(to); err != nil {
+			return fmt.Errorf("%w: 宛先 %q は正しいメールアドレスではありません", ErrInvalidSavedReport, to)
+		}
+		if key := strings.ToLower(to); !seen[key] {
+			seen[key] = true
+			recipients = append(recipients, to)
+		}
+	}
+	report.Recipients = recipients
+	if report.Frequency != models.ReportFrequencyNone && len(report.Recipients) == 0 {
+		return fmt.Errorf("%w: 定期配信するレポートには宛先を指定してください", ErrInvalidSavedReport)
+	}
+	return nil
+}
+
+// validateSavedReportConfig レポートの種類に対応する集計の設定を確認し、他の種類の設定を空にする
+// 集計の設定の誤りはグラフ・ピボット集計・絞り込み条件のそれぞれのエラーとして返す
+func validateSavedReportConfig(app *models.App, fields []models.AppField, report *models.SavedReport) error {
+	config := report.Config
+	report.Config = models.SavedReportConfig{}
+
+	switch report.ReportType {
+	case models.ReportTypeChart:
+		chart := config.Chart
+		if chart == nil {
+			return fmt.Errorf("%w: グラフの設定を指定してください", ErrInvalidSavedReport)
+		}
+		if err := validateSavedChartAxes(fields, chart); err != nil {
+			return err
+		}
+		if _, err := resolveChartRequest(app, fields, chart); err != nil {
+			return err
+		}
+		if _, _, err := resolveFilters(app, fields, chart.TimeZone, chart.Filters, chart.Filter); err != nil {
+			return err
+		}
+		report.Config.Chart = chart
+
+	case models.ReportTypePivot:
+		pivot := config.Pivot
+		if pivot == nil {
+			return fmt.Errorf("%w: ピボット集計の設定を指定してください", ErrInvalidSavedReport)
+		}
+		if _, err := resolvePivotRequest(app, fields, pivot); err != nil {
+			return err
+		}
+		if _, _, err := resolveFilters(app, fields, pivot.TimeZone, pivot.Filters, pivot.Filter); err != nil {
+			return err
+		}
+		report.Config.Pivot = pivot
+
+	case models.ReportTypeRecords:
+		records := config.Records
+		if records == nil {
+			records = &models.RecordReportConfig{}
+		}
+		if records.Sort != "" && !savedReportSortColumns[records.Sort] {
+			if field, ok := fieldsByCode(fields)[records.Sort]; !ok || !field.HasColumn() {
+				return fmt.Errorf("%w: 並び替えるフィールド %q が存在しません", ErrInvalidSavedReport, records.Sort)
+			}
+		}
+		if _, _, err := resolveFilters(app, fields, records.TimeZone, records.Filters, records.Filter); err != nil {
+			return err
+		}
+		report.Config.Records = records
+
+	default:
+		return fmt.Errorf("%w: レポートの種類 %q はサポートされていません", ErrInvalidSavedReport, report.ReportType)
+	}
+	return nil
+}
+
+// validateSavedChartAxes グラフのX軸と集計値のフィールドが存在するか確認する
+// 集計時に初めて分かる誤りを、保存する時点で返すため
+func validateSavedChartAxes(fields []models.AppField, chart *models.ChartDataRequest) error {
+	byCode := fieldsByCode(fields)
+	if field, ok := byCode[chart.XAxis.Field]; !ok || !field.HasColumn() {
+		return fmt.Errorf("%w: X軸のフィールド %q が存在しません", ErrInvalidChartConfig, chart.XAxis.Field)
+	}
+	for _, axis := range append([]models.ChartAxis{chart.YAxis}, chart.Measures...) {
+		if axis.Aggregation == "count" {
+			continue
+		}
+		if field, ok := byCode[axis.Field]; !ok || !field.HasColumn() {
+			return fmt.Errorf("%w: 集計するフィールド %q が存在しません", ErrInvalidChartConfig, axis.Field)
+		}
+	}
+	return nil
+}
+
+// scheduleSavedReport 定期配信するレポートの次回配信時刻を現在時刻から設定する
+func scheduleSavedReport(report *models.SavedReport, now time.Time) {
+	report.NextRunAt = nil
+	if report.Frequency == models.ReportFrequencyNone {
+		return
+	}
+	if next, err := nextSavedReportRun(report, now.UTC()); err == nil {
+		report.NextRunAt = &next
+	}
+}
+
+// savedReportSchedule 配信頻度・時刻をcron形式のスケジュールに変換する
+func savedReportSchedule(report *models.SavedReport) string {
+	switch report.Frequency {
+	case models.ReportFrequencyDaily:
+		return fmt.Sprintf("0 %d * * *", report.SendHour)
+	case models.ReportFrequencyWeekly:
+		return fmt.Sprintf("0 %d * * %d", report.SendHour, report.Weekday)
+	case models.ReportFrequencyMonthly:
+		return fmt.Sprintf("0 %d %d * *", report.SendHour, report.DayOfMonth)
+	}
+	return ""
+}
+
+// nextSavedReportRun 配信頻度・時刻をレポートのタイムゾーンで評価し、nowより後の次回配信時刻をUTCで返す
+func nextSavedReportRun(report *models.SavedReport, now time.Time) (time.Time, error) {
+	schedule, err := utils.ParseCron(savedReportSchedule(report))
+	if err != nil {
+		return time.Time{}, err
+	}
+	loc, err := time.LoadLocation(report.Timezone)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return schedule.Next(now.In(loc)).UTC(), nil
+}
+
+// savedReportScheduleChanged レポートの更新で配信のタイミングに関わる設定が変わったかどうかを確認する
+func savedReportScheduleChanged(before, after *models.SavedReport) bool {
+	return before.Frequency != after.Frequency ||
+		before.SendHour != after.SendHour ||
+		before.Weekday != after.Weekday ||
+		before.DayOfMonth != after.DayOfMonth ||
+		before.Timezone != after.Timezone ||
+		before.IsActive != after.IsActive
+}
+
+// creatorContext 定期配信でレポートを作成したユーザーの権限で集計するためのコンテキストを返す
+// 作成者が削除されている場合は、誰の権限で集計するか決められないためエラーを返す
+func (s *SavedReportService) creatorContext(ctx context.Context, report *models.SavedReport) (context.Context, error) {
+	if report.CreatedBy == nil {
+		return nil, errors.New("レポートの作成者が削除されています")
+	}
+	user, err := s.userRepo.GetByID(ctx, *report.CreatedBy)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, errors.New("レポートの作成者が削除されています")
+	}
+	return middleware.SetUserInContext(ctx, &utils.JWTClaims{UserID: user.ID, Email: user.Email, Role: user.Role}), nil
+}
+
+// deliver レポートを集計してメールで送信し、結果を配信履歴に記録する
+// 集計やメールの送信の失敗は配信履歴に記録し、履歴を記録できなかった場合のみエラーを返す
+func (s *SavedReportService) deliver(ctx context.Context, app *models.App, report *models.SavedReport, trigger models.ReportDeliveryTrigger, now time.Time) (*models.ReportDelivery, error) {
+	delivery := &models.ReportDelivery{
+		ReportID:   report.ID,
+		AppID:      report.AppID,
+		Trigger:    trigger,
+		Status:     models.ReportDeliverySucceeded,
+		Recipients: report.Recipients,
+		Subject:    fmt.Sprintf("[%s] %s", app.Name, report.Name),
+		CreatedAt:  now,
+	}
+
+	if err := s.send(ctx, app, report, delivery, now); err != nil {
+		// サーバーの停止による中断は配信の失敗として記録しない
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		delivery.Status = models.ReportDeliveryFailed
+		delivery.Error = truncateSavedReportError(err.Error())
+	}
+	if err := s.deliveryRepo.Create(ctx, delivery); err != nil {
+		return nil, err
+	}
+	return delivery, nil
+}
+
+// send レポートをCSVファイルとして書き出し、先頭の行を本文に表示したメールに添付して送信する
+func (s *SavedReportService) send(ctx context.Context, app *models.App, report *models.SavedReport, delivery *models.ReportDelivery, now time.Time) error {
+	var buf bytes.Buffer
+	if err := s.writeReport(ctx, report, &limitedWriter{w: &buf, remaining: savedReportMaxAttachmentSize}); err != nil {
+		return err
+	}
+
+	headers, rows, err := readReportCSV(buf.Bytes())
+	if err != nil {
+		return err
+	}
+	delivery.RowCount = len(rows)
+
+	loc, err := time.LoadLocation(report.Timezone)
+	if err != nil {
+		loc = time.UTC
+	}
+	data := savedReportMailData{
+		ReportName:  report.Name,
+		AppName:     app.Name,
+		GeneratedAt: now.In(loc).Format("2006-01-02 15:04"),
+		Timezone:    loc.String(),
+		Headers:     headers,
+		Rows:        rows,
+		RowCount:    len(rows),
+	}
+	if len(rows) > savedReportPreviewRows {
+		data.Rows, data.Truncated = rows[:savedReportPreviewRows], true
+	}
+	var body strings.Builder
+	if err := savedReportMailTemplate.Execute(&body, data); err != nil {
+		return err
+	}
+
+	return s.sender.Send(ctx, &mail.Message{
+		To:       report.Recipients,
+		Subject:  delivery.Subject,
+		HTMLBody: body.String(),
+		Attachments: []mail.Attachment{{
+			FileName:    savedReportFileName(report.Name, now.In(loc)),
+			ContentType: "text/csv; charset=utf-8",
+			Data:        buf.Bytes(),
+		}},
+	})
+}
+
+// writeReport レポートの種類に応じて集計し、1行目を見出しとするCSVとしてwに書き出す
+// 集計はコンテキストのユーザーの権限で行うため、閲覧できないレコードは含まれない
+func (s *SavedReportService) writeReport(ctx context.Context, report *models.SavedReport, w io.Writer) error {
+	switch report.ReportType {
+	case models.ReportTypeChart:
+		if report.Config.Chart == nil {
+			break
+		}
+		chart, err := s.charts.GetChartData(ctx, report.AppID, report.Config.Chart)
+		if err != nil {
+			return err
+		}
+		tw := utils.NewCSVTableWriter(w)
+		if err := writeChartTable(tw, report.Config.Chart, chart); err != nil {
+			return err
+		}
+		return tw.Close()
+
+	case models.ReportTypePivot:
+		if report.Config.Pivot == nil {
+			break
+		}
+		return s.reports.ExportPivot(ctx, report.AppID, report.Config.Pivot, models.ExportFormatCSV, w)
+
+	case models.ReportTypeRecords:
+		config := report.Config.Records
+		if config == nil {
+			config = &models.RecordReportConfig{}
+		}
+		opts := repositories.RecordQueryOptions{
+			Sort:     config.Sort,
+			Order:    config.Order,
+			Filters:  config.Filters,
+			Filter:   config.Filter,
+			TimeZone: config.TimeZone,
+		}
+		return s.records.ExportRecords(ctx, report.AppID, opts, models.ExportFormatCSV, w)
+	}
+	return fmt.Errorf("%w: レポートの種類 %q の設定がありません", ErrInvalidSavedReport, report.ReportType)
+}
+
+// writeChartTable グラフの集計結果をX軸の値を行、データセットを列とする表として書き出す
+func writeChartTable(tw utils.TableWriter, req *models.ChartDataRequest, chart *models.ChartDataResponse) error {
+	xLabel := req.XAxis.Label
+	if xLabel == "" {
+		xLabel = req.XAxis.Field
+	}
+	headers := []string{xLabel}
+	for _, ds := range chart.Datasets {
+		label := ds.Label
+		if ds.Stack != "" && ds.Stack != ds.Label {
+			label = ds.Stack + " / " + ds.Label
+		}
+		headers = append(headers, label)
+	}
+	if err := tw.WriteHeader(headers); err != nil {
+		return err
+	}
+
+	values := make([]interface{}, len(headers))
+	for i, label := range chart.Labels {
+		values[0] = label
+		for j, ds := range chart.Datasets {
+			values[j+1] = nil
+			if i < len(ds.Data) {
+				values[j+1] = ds.Data[i]
+			}
+		}
+		if err := tw.WriteRow(values); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// readReportCSV 書き出したCSVを見出しとデータ行に分ける
+func readReportCSV(data []byte) ([]string, [][]string, error) {
+	r := csv.NewReader(bytes.NewReader(bytes.TrimPrefix(data, []byte("\ufeff"))))
+	r.FieldsPerRecord = -1
+	records, err := r.ReadAll()
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(records) == 0 {
+		return nil, nil, nil
+	}
+	return records[0], records[1:], nil
+}
+
+// savedReportFileName 添付するCSVファイルの名前（レポート名と集計日）を返す
+func savedReportFileName(name string, now time.Time) string {
+	name = strings.Map(func(r rune) rune {
+		switch r {
+		case '/', '\\', ':', '*', '?', '"', '<', '>', '|', '\r', '\n':
+			return '_'
+		}
+		return r
+	}, name)
+	return fmt.Sprintf("%s_%s.csv", name, now.Format("20060102"))
+}
+
+// truncateSavedReportError 配信履歴に保存できる長さにエラーメッセージを切り詰める
+func truncateSavedReportError(msg string) string {
+	if runes := []rune(msg); len(runes) > savedReportMaxErrorLength {
+		return string(runes[:savedReportMaxErrorLength])
+	}
+	return msg
+}
+
+// limitedWriter 書き込んだ合計が上限を超えるとErrReportTooLargeを返すWriter
+type limitedWriter struct {
+	w         io.Writer
+	remaining int64
+}
+
+func (l *limitedWriter) Write(p []byte) (int, error) {
+	if int64(len(p)) > l.remaining {
+		return 0, ErrReportTooLarge
+	}
+	l.remaining -= int64(len(p))
+	return l.w.Write(p)
+}
@@ -0,0 +1,369 @@
+package services_test
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"nocode-app/backend/internal/mail"
+	"nocode-app/backend/internal/models"
+	"nocode-app/backend/internal/repositories"
+	"nocode-app/backend/internal/services"
+	"nocode-app/backend/internal/testhelpers/mocks"
+)
+
+// savedReportTestMocks 保存したレポートのテストで使うモック
+func savedReportTestFields() []models.AppField {
+	return []models.AppField{
+		{ID: 1, AppID: 1, FieldCode: "region", FieldName: "地域", FieldType: "select"},
+		{ID: 2, AppID: 1, FieldCode: "amount", FieldName: "金額", FieldType: "number"},
+		{ID: 3, AppID: 1, FieldCode: "due", FieldName: "期日", FieldType: "date"},
+	}
+}
+
+func savedReportTestPivot() *models.PivotRequest {
+	return &models.PivotRequest{
+		Rows:     []models.PivotDimension{{Field: "region"}},
+		Measures: []models.PivotMeasure{{Aggregation: "count"}},
+	}
+}
+
+func TestSavedReportService_CreateReport(t *testing.T) {
+	ctx := systemContext()
+
+	t.Run("weekly report is scheduled in its time zone", func(t *testing.T) {
+		mockReportRepo := new(mocks.MockSavedReportRepository)
+		mockAppRepo := new(mocks.MockAppRepository)
+		mockFieldRepo := new(mocks.MockFieldRepository)
+		service := services.NewSavedReportService(mockReportRepo, new(mocks.MockReportDeliveryRepository), mockAppRepo, mockFieldRepo, new(mocks.MockUserRepository),
+			new(mocks.MockChartService), new(mocks.MockReportService), new(mocks.MockRecordService), newTestPermissionService(mockAppRepo), new(mocks.MockMailSender))
+		mockAppRepo.On("GetByID", mock.Anything, uint64(1)).Return(&models.App{ID: 1, Name: "営業管理", TableName: "app_data_1"}, nil)
+		mockFieldRepo.On("GetByAppID", mock.Anything, uint64(1)).Return(savedReportTestFields(), nil)
+
+		mockReportRepo.On("Create", ctx, mock.AnythingOfType("*models.SavedReport")).Return(nil)
+
+		before := time.Now()
+		report, err := service.CreateReport(ctx, 1, 5, &models.SaveReportRequest{
+			Name:       "週次レポート",
+			ReportType: models.ReportTypePivot,
+			Config: models.SavedReportConfig{
+				Pivot: savedReportTestPivot(),
+				// 種類に対応しない設定は保存しない
+				Records: &models.RecordReportConfig{Sort: "amount"},
+			},
+			Frequency:  models.ReportFrequencyWeekly,
+			SendHour:   9,
+			Weekday:    1,
+			DayOfMonth: 15,
+			Timezone:   "Asia/Tokyo",
+			Recipients: []string{"a@example.com", "A@example.com", "b@example.com"},
+		})
+		require.NoError(t, err)
+
+		assert.Equal(t, []string{"a@example.com", "b@example.com"}, report.Recipients)
+		assert.Nil(t, report.Config.Records)
+		assert.Equal(t, 1, report.DayOfMonth)
+		assert.True(t, report.IsActive)
+		require.NotNil(t, report.CreatedBy)
+		assert.Equal(t, uint64(5), *report.CreatedBy)
+
+		// 次回は月曜9:00（日本時間）
+		require.NotNil(t, report.NextRunAt)
+		next := report.NextRunAt.In(time.FixedZone("JST", 9*60*60))
+		assert.Equal(t, time.Monday, next.Weekday())
+		assert.Equal(t, 9, next.Hour())
+		assert.True(t, report.NextRunAt.After(before))
+		assert.True(t, report.NextRunAt.Before(before.Add(7*24*time.Hour+time.Minute)))
+	})
+
+	t.Run("manual report is not scheduled", func(t *testing.T) {
+		mockReportRepo := new(mocks.MockSavedReportRepository)
+		mockAppRepo := new(mocks.MockAppRepository)
+		mockFieldRepo := new(mocks.MockFieldRepository)
+		service := services.NewSavedReportService(mockReportRepo, new(mocks.MockReportDeliveryRepository), mockAppRepo, mockFieldRepo, new(mocks.MockUserRepository),
+			new(mocks.MockChartService), new(mocks.MockReportService), new(mocks.MockRecordService), newTestPermissionService(mockAppRepo), new(mocks.MockMailSender))
+		mockAppRepo.On("GetByID", mock.Anything, uint64(1)).Return(&models.App{ID: 1, Name: "営業管理", TableName: "app_data_1"}, nil)
+		mockFieldRepo.On("GetByAppID", mock.Anything, uint64(1)).Return(savedReportTestFields(), nil)
+
+		mockReportRepo.On("Create", ctx, mock.AnythingOfType("*models.SavedReport")).Return(nil)
+
+		report, err := service.CreateReport(ctx, 1, 5, &models.SaveReportRequest{
+			Name:       "一覧",
+			ReportType: models.ReportTypeRecords,
+			SendHour:   9,
+		})
+		require.NoError(t, err)
+		assert.Nil(t, report.NextRunAt)
+		assert.Equal(t, "UTC", report.Timezone)
+		assert.Zero(t, report.SendHour)
+		assert.NotNil(t, report.Config.Records)
+	})
+
+	tests := []struct {
+		name    string
+		req     models.SaveReportRequest
+		wantErr error
+	}{
+		{
+			name:    "missing chart config",
+			req:     models.SaveReportRequest{Name: "x", ReportType: models.ReportTypeChart},
+			wantErr: services.ErrInvalidSavedReport,
+		},
+		{
+			name: "unknown x axis field",
+			req: models.SaveReportRequest{Name: "x", ReportType: models.ReportTypeChart, Config: models.SavedReportConfig{
+				Chart: &models.ChartDataRequest{ChartType: "bar", XAxis: models.ChartAxis{Field: "missing"}, YAxis: models.ChartAxis{Aggregation: "count"}},
+			}},
+			wantErr: services.ErrInvalidChartConfig,
+		},
+		{
+			name: "invalid pivot",
+			req: models.SaveReportRequest{Name: "x", ReportType: models.ReportTypePivot, Config: models.SavedReportConfig{
+				Pivot: &models.PivotRequest{Rows: []models.PivotDimension{{Field: "region", Bucket: "month"}}, Measures: []models.PivotMeasure{{Aggregation: "count"}}},
+			}},
+			wantErr: services.ErrInvalidReportConfig,
+		},
+		{
+			name: "invalid filter",
+			req: models.SaveReportRequest{Name: "x", ReportType: models.ReportTypeRecords, Config: models.SavedReportConfig{
+				Records: &models.RecordReportConfig{Filters: []models.FilterItem{{Field: "missing", Operator: "eq", Value: "1"}}},
+			}},
+			wantErr: services.ErrInvalidFilter,
+		},
+		{
+			name: "unknown sort field",
+			req: models.SaveReportRequest{Name: "x", ReportType: models.ReportTypeRecords, Config: models.SavedReportConfig{
+				Records: &models.RecordReportConfig{Sort: "missing"},
+			}},
+			wantErr: services.ErrInvalidSavedReport,
+		},
+		{
+			name: "scheduled without recipients",
+			req: models.SaveReportRequest{Name: "x", ReportType: models.ReportTypePivot, Frequency: models.ReportFrequencyDaily,
+				Config: models.SavedReportConfig{Pivot: savedReportTestPivot()}},
+			wantErr: services.ErrInvalidSavedReport,
+		},
+		{
+			name: "invalid time zone",
+			req: models.SaveReportRequest{Name: "x", ReportType: models.ReportTypePivot, Timezone: "Mars/Olympus",
+				Config: models.SavedReportConfig{Pivot: savedReportTestPivot()}},
+			wantErr: services.ErrInvalidSavedReport,
+		},
+		{
+			name: "invalid recipient",
+			req: models.SaveReportRequest{Name: "x", ReportType: models.ReportTypePivot, Recipients: []string{"Sales <sales@example.com>"},
+				Config: models.SavedReportConfig{Pivot: savedReportTestPivot()}},
+			wantErr: services.ErrInvalidSavedReport,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockReportRepo := new(mocks.MockSavedReportRepository)
+			mockAppRepo := new(mocks.MockAppRepository)
+			mockFieldRepo := new(mocks.MockFieldRepository)
+			service := services.NewSavedReportService(mockReportRepo, new(mocks.MockReportDeliveryRepository), mockAppRepo, mockFieldRepo, new(mocks.MockUserRepository),
+				new(mocks.MockChartService), new(mocks.MockReportService), new(mocks.MockRecordService), newTestPermissionService(mockAppRepo), new(mocks.MockMailSender))
+			mockAppRepo.On("GetByID", mock.Anything, uint64(1)).Return(&models.App{ID: 1, Name: "営業管理", TableName: "app_data_1"}, nil)
+			mockFieldRepo.On("GetByAppID", mock.Anything, uint64(1)).Return(savedReportTestFields(), nil)
+
+			_, err := service.CreateReport(ctx, 1, 5, &tt.req)
+			assert.ErrorIs(t, err, tt.wantErr)
+			mockReportRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestSavedReportService_SendReport(t *testing.T) {
+	ctx := systemContext()
+	pivotReport := func() *models.SavedReport {
+		return &models.SavedReport{
+			ID: 3, AppID: 1, Name: "地域別件数", ReportType: models.ReportTypePivot, Timezone: "Asia/Tokyo",
+			Config:     models.SavedReportConfig{Pivot: savedReportTestPivot()},
+			Recipients: []string{"a@example.com"},
+		}
+	}
+	writeCSV := func(csv string) func(args mock.Arguments) {
+		return func(args mock.Arguments) {
+			_, _ = io.WriteString(args.Get(4).(io.Writer), "\ufeff"+csv)
+		}
+	}
+
+	t.Run("sends html summary with csv attachment", func(t *testing.T) {
+		mockReportRepo := new(mocks.MockSavedReportRepository)
+		mockDeliveryRepo := new(mocks.MockReportDeliveryRepository)
+		mockAppRepo := new(mocks.MockAppRepository)
+		mockFieldRepo := new(mocks.MockFieldRepository)
+		mockReports := new(mocks.MockReportService)
+		mockSender := new(mocks.MockMailSender)
+		service := services.NewSavedReportService(mockReportRepo, mockDeliveryRepo, mockAppRepo, mockFieldRepo, new(mocks.MockUserRepository),
+			new(mocks.MockChartService), mockReports, new(mocks.MockRecordService), newTestPermissionService(mockAppRepo), mockSender)
+		mockAppRepo.On("GetByID", mock.Anything, uint64(1)).Return(&models.App{ID: 1, Name: "営業管理", TableName: "app_data_1"}, nil)
+		mockFieldRepo.On("GetByAppID", mock.Anything, uint64(1)).Return(savedReportTestFields(), nil)
+
+		mockReportRepo.On("GetByID", ctx, uint64(3)).Return(pivotReport(), nil)
+		mockReports.On("ExportPivot", ctx, uint64(1), mock.Anything, models.ExportFormatCSV, mock.Anything).
+			Run(writeCSV("地域,件数\n東,2\n<西>,1\n総計,3\n")).Return(nil)
+
+		var sent *mail.Message
+		mockSender.On("Send", ctx, mock.Anything).Run(func(args mock.Arguments) {
+			sent = args.Get(1).(*mail.Message)
+		}).Return(nil)
+		mockDeliveryRepo.On("Create", ctx, mock.MatchedBy(func(d *models.ReportDelivery) bool {
+			return d.ReportID == 3 && d.Status == models.ReportDeliverySucceeded && d.Trigger == models.ReportDeliveryTriggerManual && d.RowCount == 3
+		})).Return(nil)
+
+		delivery, err := service.SendReport(ctx, 1, 3)
+		require.NoError(t, err)
+		assert.Equal(t, models.ReportDeliverySucceeded, delivery.Status)
+		mockDeliveryRepo.AssertExpectations(t)
+
+		require.NotNil(t, sent)
+		assert.Equal(t, []string{"a@example.com"}, sent.To)
+		assert.Equal(t, "[営業管理] 地域別件数", sent.Subject)
+		assert.Contains(t, sent.HTMLBody, "<th style=\"border: 1px solid #ccc; background: #f5f5f5; padding: 4px 8px; text-align: left;\">地域</th>")
+		// 値はHTMLとしてエスケープする
+		assert.Contains(t, sent.HTMLBody, "&lt;西&gt;")
+		assert.Contains(t, sent.HTMLBody, "全3行")
+		assert.NotContains(t, sent.HTMLBody, "先頭の")
+		require.Len(t, sent.Attachments, 1)
+		assert.True(t, strings.HasPrefix(sent.Attachments[0].FileName, "地域別件数_"))
+		assert.True(t, strings.HasSuffix(sent.Attachments[0].FileName, ".csv"))
+		assert.Contains(t, string(sent.Attachments[0].Data), "東,2")
+	})
+
+	t.Run("long list shows the first rows", func(t *testing.T) {
+		mockReportRepo := new(mocks.MockSavedReportRepository)
+		mockDeliveryRepo := new(mocks.MockReportDeliveryRepository)
+		mockAppRepo := new(mocks.MockAppRepository)
+		mockFieldRepo := new(mocks.MockFieldRepository)
+		mockRecords := new(mocks.MockRecordService)
+		mockSender := new(mocks.MockMailSender)
+		service := services.NewSavedReportService(mockReportRepo, mockDeliveryRepo, mockAppRepo, mockFieldRepo, new(mocks.MockUserRepository),
+			new(mocks.MockChartService), new(mocks.MockReportService), mockRecords, newTestPermissionService(mockAppRepo), mockSender)
+		mockAppRepo.On("GetByID", mock.Anything, uint64(1)).Return(&models.App{ID: 1, Name: "営業管理", TableName: "app_data_1"}, nil)
+		mockFieldRepo.On("GetByAppID", mock.Anything, uint64(1)).Return(savedReportTestFields(), nil)
+
+		report := &models.SavedReport{
+			ID: 4, AppID: 1, Name: "一覧", ReportType: models.ReportTypeRecords, Timezone: "UTC",
+			Config:     models.SavedReportConfig{Records: &models.RecordReportConfig{Sort: "amount", Order: "desc"}},
+			Recipients: []string{"a@example.com"},
+		}
+		mockReportRepo.On("GetByID", ctx, uint64(4)).Return(report, nil)
+		var csv strings.Builder
+		csv.WriteString("地域,金額\n")
+		for i := 0; i < 25; i++ {
+			fmt.Fprintf(&csv, "東,%d\n", i)
+		}
+		mockRecords.On("ExportRecords", ctx, uint64(1), mock.MatchedBy(func(opts repositories.RecordQueryOptions) bool {
+			return opts.Sort == "amount" && opts.Order == "desc"
+		}), models.ExportFormatCSV, mock.Anything).Run(writeCSV(csv.String())).Return(nil)
+
+		var sent *mail.Message
+		mockSender.On("Send", ctx, mock.Anything).Run(func(args mock.Arguments) {
+			sent = args.Get(1).(*mail.Message)
+		}).Return(nil)
+		mockDeliveryRepo.On("Create", ctx, mock.MatchedBy(func(d *models.ReportDelivery) bool {
+			return d.RowCount == 25
+		})).Return(nil)
+
+		_, err := service.SendReport(ctx, 1, 4)
+		require.NoError(t, err)
+		require.NotNil(t, sent)
+		assert.Contains(t, sent.HTMLBody, "全25行のうち先頭の20行")
+		assert.Contains(t, sent.HTMLBody, ">19</td>")
+		assert.NotContains(t, sent.HTMLBody, ">20</td>")
+	})
+
+	t.Run("send failure is recorded", func(t *testing.T) {
+		mockReportRepo := new(mocks.MockSavedReportRepository)
+		mockDeliveryRepo := new(mocks.MockReportDeliveryRepository)
+		mockAppRepo := new(mocks.MockAppRepository)
+		mockFieldRepo := new(mocks.MockFieldRepository)
+		mockReports := new(mocks.MockReportService)
+		mockSender := new(mocks.MockMailSender)
+		service := services.NewSavedReportService(mockReportRepo, mockDeliveryRepo, mockAppRepo, mockFieldRepo, new(mocks.MockUserRepository),
+			new(mocks.MockChartService), mockReports, new(mocks.MockRecordService), newTestPermissionService(mockAppRepo), mockSender)
+		mockAppRepo.On("GetByID", mock.Anything, uint64(1)).Return(&models.App{ID: 1, Name: "営業管理", TableName: "app_data_1"}, nil)
+		mockFieldRepo.On("GetByAppID", mock.Anything, uint64(1)).Return(savedReportTestFields(), nil)
+
+		mockReportRepo.On("GetByID", ctx, uint64(3)).Return(pivotReport(), nil)
+		mockReports.On("ExportPivot", ctx, uint64(1), mock.Anything, models.ExportFormatCSV, mock.Anything).
+			Run(writeCSV("地域,件数\n")).Return(nil)
+		mockSender.On("Send", ctx, mock.Anything).Return(mail.ErrNotConfigured)
+		mockDeliveryRepo.On("Create", ctx, mock.MatchedBy(func(d *models.ReportDelivery) bool {
+			return d.Status == models.ReportDeliveryFailed && d.Error == mail.ErrNotConfigured.Error()
+		})).Return(nil)
+
+		delivery, err := service.SendReport(ctx, 1, 3)
+		require.NoError(t, err)
+		assert.Equal(t, models.ReportDeliveryFailed, delivery.Status)
+		mockDeliveryRepo.AssertExpectations(t)
+	})
+
+	t.Run("report failure is recorded without sending", func(t *testing.T) {
+		mockReportRepo := new(mocks.MockSavedReportRepository)
+		mockDeliveryRepo := new(mocks.MockReportDeliveryRepository)
+		mockAppRepo := new(mocks.MockAppRepository)
+		mockFieldRepo := new(mocks.MockFieldRepository)
+		mockReports := new(mocks.MockReportService)
+		mockSender := new(mocks.MockMailSender)
+		service := services.NewSavedReportService(mockReportRepo, mockDeliveryRepo, mockAppRepo, mockFieldRepo, new(mocks.MockUserRepository),
+			new(mocks.MockChartService), mockReports, new(mocks.MockRecordService), newTestPermissionService(mockAppRepo), mockSender)
+		mockAppRepo.On("GetByID", mock.Anything, uint64(1)).Return(&models.App{ID: 1, Name: "営業管理", TableName: "app_data_1"}, nil)
+		mockFieldRepo.On("GetByAppID", mock.Anything, uint64(1)).Return(savedReportTestFields(), nil)
+
+		mockReportRepo.On("GetByID", ctx, uint64(3)).Return(pivotReport(), nil)
+		mockReports.On("ExportPivot", ctx, uint64(1), mock.Anything, models.ExportFormatCSV, mock.Anything).
+			Return(errors.New("集計に失敗しました"))
+		mockDeliveryRepo.On("Create", ctx, mock.MatchedBy(func(d *models.ReportDelivery) bool {
+			return d.Status == models.ReportDeliveryFailed && d.Error == "集計に失敗しました"
+		})).Return(nil)
+
+		_, err := service.SendReport(ctx, 1, 3)
+		require.NoError(t, err)
+		mockSender.AssertNotCalled(t, "Send", mock.Anything, mock.Anything)
+	})
+
+	t.Run("report of another app", func(t *testing.T) {
+		mockReportRepo := new(mocks.MockSavedReportRepository)
+		mockAppRepo := new(mocks.MockAppRepository)
+		mockFieldRepo := new(mocks.MockFieldRepository)
+		service := services.NewSavedReportService(mockReportRepo, new(mocks.MockReportDeliveryRepository), mockAppRepo, mockFieldRepo, new(mocks.MockUserRepository),
+			new(mocks.MockChartService), new(mocks.MockReportService), new(mocks.MockRecordService), newTestPermissionService(mockAppRepo), new(mocks.MockMailSender))
+		mockAppRepo.On("GetByID", mock.Anything, uint64(1)).Return(&models.App{ID: 1, Name: "営業管理", TableName: "app_data_1"}, nil)
+		mockFieldRepo.On("GetByAppID", mock.Anything, uint64(1)).Return(savedReportTestFields(), nil)
+
+		report := pivotReport()
+		report.AppID = 2
+		mockReportRepo.On("GetByID", ctx, uint64(3)).Return(report, nil)
+
+		_, err := service.SendReport(ctx, 1, 3)
+		assert.ErrorIs(t, err, services.ErrSavedReportNotFound)
+	})
+
+	t.Run("no recipients", func(t *testing.T) {
+		mockReportRepo := new(mocks.MockSavedReportRepository)
+		mockDeliveryRepo := new(mocks.MockReportDeliveryRepository)
+		mockAppRepo := new(mocks.MockAppRepository)
+		mockFieldRepo := new(mocks.MockFieldRepository)
+		service := services.NewSavedReportService(mockReportRepo, mockDeliveryRepo, mockAppRepo, mockFieldRepo, new(mocks.MockUserRepository),
+			new(mocks.MockChartService), new(mocks.MockReportService), new(mocks.MockRecordService), newTestPermissionService(mockAppRepo), new(mocks.MockMailSender))
+		mockAppRepo.On("GetByID", mock.Anything, uint64(1)).Return(&models.App{ID: 1, Name: "営業管理", TableName: "app_data_1"}, nil)
+		mockFieldRepo.On("GetByAppID", mock.Anything, uint64(1)).Return(savedReportTestFields(), nil)
+
+		report := pivotReport()
+		report.Recipients = []string{}
+		mockReportRepo.On("GetByID", ctx, uint64(3)).Return(report, nil)
+
+		_, err := service.SendReport(ctx, 1, 3)
+		assert.ErrorIs(t, err, services.ErrInvalidSavedReport)
+		mockDeliveryRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	})
+}
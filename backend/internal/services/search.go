@@ -0,0 +1,144 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"nocode-app/backend/internal/models"
+	"nocode-app/backend/internal/repositories"
+)
+
+// 全文検索関連エラー
+var (
+	ErrInvalidSearch       = errors.New("検索キーワードが正しくありません")
+	ErrInvalidSearchConfig = errors.New("全文検索の形式が正しくありません")
+)
+
+const (
+	// maxSearchLength 検索キーワードの最大文字数
+	maxSearchLength = 200
+	// maxSearchTerms 空白で区切った検索キーワードの最大の語数
+	maxSearchTerms = 10
+)
+
+// normalizeSearchConfig 全文検索用カラムの形式を検証する（空の場合は simple）
+func normalizeSearchConfig(config string) (string, error) {
+	config = strings.ToLower(strings.TrimSpace(config))
+	if config == "" {
+		return models.SearchConfigSimple, nil
+	}
+	if !models.IsValidSearchConfig(config) {
+		return "", fmt.Errorf("%w: %q は指定できません（simple・english などのテキスト検索設定または trigram）", ErrInvalidSearchConfig, config)
+	}
+	return config, nil
+}
+
+// rebuildSearchColumn 検索対象のフィールドが変わった内部アプリの全文検索用カラムを作り直す
+// 全文検索用カラムがないアプリでは何もしない
+func rebuildSearchColumn(ctx context.Context, dynamicQuery repositories.DynamicQueryExecutorInterface, app *models.App, fields []models.AppField) error {
+	if app.IsExternal || app.SearchConfig == nil {
+		return nil
+	}
+	return dynamicQuery.SetSearchColumn(ctx, app.TableName, fields, *app.SearchConfig)
+}
+
+// applyRecordSearch レコード取得のオプションの検索キーワードを検証し、検索に使う語を返す（検索しない場合はnil）
+// 全文検索用カラムがある内部アプリはカラムで検索し、それ以外（外部データソースを含む）は
+// 検索対象のフィールドのいずれかが各語を含む（大文字・小文字を区別しない）絞り込み条件に置き換える
+func applyRecordSearch(app *models.App, fields []models.AppField, opts *repositories.RecordQueryOptions) ([]string, error) {
+	query := strings.TrimSpace(opts.Search)
+	opts.Search, opts.SearchConfig = "", ""
+	terms, err := parseSearchTerms(query)
+	if err != nil || terms == nil {
+		return nil, err
+	}
+
+	searchable := searchableFields(fields)
+	if len(searchable) == 0 {
+		return nil, fmt.Errorf("%w: 検索できるフィールド（文字列・複数行テキスト）がありません", ErrInvalidSearch)
+	}
+
+	if !app.IsExternal && app.SearchConfig != nil {
+		opts.Search, opts.SearchConfig = query, *app.SearchConfig
+		return terms, nil
+	}
+
+	termExprs := make([]models.FilterExpr, len(terms))
+	for i, term := range terms {
+		fieldExprs := make([]models.FilterExpr, len(searchable))
+		for j := range searchable {
+			fieldExprs[j] = models.FilterExpr{FilterItem: models.FilterItem{
+				Field:    searchable[j].FieldCode,
+				Operator: models.FilterOpILike,
+				Value:    term,
+			}}
+		}
+		termExprs[i] = models.FilterExpr{Or: fieldExprs}
+	}
+	opts.Filter = andFilterExpr(opts.Filter, models.FilterExpr{And: termExprs})
+	return terms, nil
+}
+
+// parseSearchTerms 検索キーワードを検証し、空白で区切った語を返す（空の場合はnil）
+func parseSearchTerms(query string) ([]string, error) {
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return nil, nil
+	}
+	if utf8.RuneCountInString(query) > maxSearchLength {
+		return nil, fmt.Errorf("%w: %d文字以内で指定してください", ErrInvalidSearch, maxSearchLength)
+	}
+	terms := strings.Fields(query)
+	if len(terms) > maxSearchTerms {
+		return nil, fmt.Errorf("%w: 語は%d個までです", ErrInvalidSearch, maxSearchTerms)
+	}
+	return terms, nil
+}
+
+// searchableFields 全文検索の対象になるフィールドを返す
+func searchableFields(fields []models.AppField) []models.AppField {
+	var searchable []models.AppField
+	for i := range fields {
+		if models.IsSearchableField(&fields[i]) {
+			searchable = append(searchable, fields[i])
+		}
+	}
+	return searchable
+}
+
+// andFilterExpr フィルター式にANDで条件を追加する
+// 入れ子が深くならないよう、既存の式がANDの場合はその条件に加える
+func andFilterExpr(expr *models.FilterExpr, add models.FilterExpr) *models.FilterExpr {
+	switch {
+	case expr == nil:
+		return &add
+	case expr.And != nil:
+		return &models.FilterExpr{And: append(append([]models.FilterExpr{}, expr.And...), add)}
+	}
+	return &models.FilterExpr{And: []models.FilterExpr{*expr, add}}
+}
+
+// highlightRecords 一致箇所の抜粋がないレコードに、検索対象のフィールドの値から抜粋を付ける
+func highlightRecords(records []models.RecordResponse, fields []models.AppField, terms []string) {
+	for i := range records {
+		if records[i].Search == nil {
+			records[i].Search = &models.RecordSearchMatch{}
+		}
+		if records[i].Search.Highlight != "" {
+			continue
+		}
+		texts := make([]string, 0, len(fields))
+		for j := range fields {
+			if !models.IsSearchableField(&fields[j]) {
+				continue
+			}
+			if s, ok := records[i].Data[fields[j].FieldCode].(string); ok {
+				texts = append(texts, s)
+			}
+		}
+		records[i].Search.Highlight = models.HighlightSnippet(texts, terms)
+	}
+}
@@ -0,0 +1,122 @@
+package services
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"nocode-app/backend/internal/models"
+	"nocode-app/backend/internal/repositories"
+)
+
+func TestApplyRecordSearch(t *testing.T) {
+	fields := []models.AppField{
+		{FieldCode: "name", FieldType: "text"},
+		{FieldCode: "amount", FieldType: "number"},
+		{FieldCode: "memo", FieldType: "textarea"},
+	}
+	trigram := models.SearchConfigTrigram
+
+	t.Run("app with search column", func(t *testing.T) {
+		opts := repositories.RecordQueryOptions{Search: "  株式会社 ABC "}
+		terms, err := applyRecordSearch(&models.App{ID: 1, SearchConfig: &trigram}, fields, &opts)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"株式会社", "ABC"}, terms)
+		assert.Equal(t, "株式会社 ABC", opts.Search)
+		assert.Equal(t, "trigram", opts.SearchConfig)
+		assert.Nil(t, opts.Filter)
+	})
+
+	t.Run("external app falls back to ilike filters", func(t *testing.T) {
+		existing := &models.FilterExpr{And: []models.FilterExpr{
+			{FilterItem: models.FilterItem{Field: "amount", Operator: "gt", Value: "1"}},
+		}}
+		opts := repositories.RecordQueryOptions{Search: "foo bar", Filter: existing}
+		terms, err := applyRecordSearch(&models.App{ID: 1, IsExternal: true, SearchConfig: &trigram}, fields, &opts)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"foo", "bar"}, terms)
+		assert.Empty(t, opts.Search)
+		assert.Empty(t, opts.SearchConfig)
+
+		ilike := func(field, value string) models.FilterExpr {
+			return models.FilterExpr{FilterItem: models.FilterItem{Field: field, Operator: "ilike", Value: value}}
+		}
+		require.NotNil(t, opts.Filter)
+		assert.Equal(t, []models.FilterExpr{
+			existing.And[0],
+			{And: []models.FilterExpr{
+				{Or: []models.FilterExpr{ilike("name", "foo"), ilike("memo", "foo")}},
+				{Or: []models.FilterExpr{ilike("name", "bar"), ilike("memo", "bar")}},
+			}},
+		}, opts.Filter.And)
+		// 既存の条件は変更しない
+		assert.Len(t, existing.And, 1)
+	})
+
+	t.Run("app without search column falls back to ilike filters", func(t *testing.T) {
+		opts := repositories.RecordQueryOptions{Search: "foo"}
+		_, err := applyRecordSearch(&models.App{ID: 1}, fields, &opts)
+		require.NoError(t, err)
+		require.NotNil(t, opts.Filter)
+		assert.Len(t, opts.Filter.And, 1)
+		assert.Empty(t, opts.SearchConfig)
+	})
+
+	t.Run("empty query", func(t *testing.T) {
+		opts := repositories.RecordQueryOptions{Search: "   "}
+		terms, err := applyRecordSearch(&models.App{ID: 1, SearchConfig: &trigram}, fields, &opts)
+		require.NoError(t, err)
+		assert.Nil(t, terms)
+		assert.Empty(t, opts.SearchConfig)
+	})
+
+	errorCases := []struct {
+		name   string
+		query  string
+		fields []models.AppField
+	}{
+		{"too long", strings.Repeat("あ", maxSearchLength+1), fields},
+		{"too many terms", strings.Repeat("a ", maxSearchTerms+1), fields},
+		{"no searchable fields", "foo", fields[1:2]},
+	}
+	for _, tc := range errorCases {
+		t.Run(tc.name, func(t *testing.T) {
+			opts := repositories.RecordQueryOptions{Search: tc.query}
+			_, err := applyRecordSearch(&models.App{ID: 1}, tc.fields, &opts)
+			assert.ErrorIs(t, err, ErrInvalidSearch)
+		})
+	}
+}
+
+func TestHighlightRecords(t *testing.T) {
+	fields := []models.AppField{
+		{FieldCode: "name", FieldType: "text"},
+		{FieldCode: "memo", FieldType: "textarea"},
+	}
+	records := []models.RecordResponse{
+		{ID: 1, Data: models.RecordData{"name": "Acme", "memo": "annual contract"}},
+		{ID: 2, Data: models.RecordData{"name": "Beta"}, Search: &models.RecordSearchMatch{Rank: 0.5, Highlight: "<mark>from db</mark>"}},
+	}
+
+	highlightRecords(records, fields, []string{"contract"})
+
+	require.NotNil(t, records[0].Search)
+	assert.Equal(t, "annual <mark>contract</mark>", records[0].Search.Highlight)
+	assert.Equal(t, "<mark>from db</mark>", records[1].Search.Highlight)
+	assert.Equal(t, 0.5, records[1].Search.Rank)
+}
+
+func TestNormalizeSearchConfig(t *testing.T) {
+	config, err := normalizeSearchConfig("")
+	require.NoError(t, err)
+	assert.Equal(t, "simple", config)
+
+	config, err = normalizeSearchConfig(" English ")
+	require.NoError(t, err)
+	assert.Equal(t, "english", config)
+
+	_, err = normalizeSearchConfig("pg_catalog.english")
+	assert.ErrorIs(t, err, ErrInvalidSearchConfig)
+}
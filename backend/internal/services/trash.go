@@ -0,0 +1,435 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"nocode-app/backend/internal/middleware"
+	"nocode-app/backend/internal/models"
+	"nocode-app/backend/internal/repositories"
+)
+
+// ごみ箱関連エラー
+var (
+	// ErrReferencedRecordMissing 復元するレコードやフィールドの参照先のレコードが削除されている
+	ErrReferencedRecordMissing = errors.New("参照先のレコードが削除されているため復元できません")
+)
+
+// trashPurgeBatchSize 1回の処理で完全に削除するレコード・フィールド・アプリのそれぞれの最大件数
+const trashPurgeBatchSize = 50
+
+// TrashService ごみ箱のレコード・フィールド・アプリの一覧表示・復元・完全な削除を処理する構造体
+type TrashService struct {
+	appRepo           repositories.AppRepositoryInterface
+	fieldRepo         repositories.FieldRepositoryInterface
+	deletedRecordRepo repositories.DeletedRecordRepositoryInterface
+	revisionRepo      repositories.RecordRevisionRepositoryInterface
+	dynamicQuery      repositories.DynamicQueryExecutorInterface
+	permissions       PermissionServiceInterface
+	webhooks          WebhookPublisherInterface
+	attachments       AttachmentManagerInterface
+	// retention ごみ箱に移してから完全に削除するまでの期間
+	retention time.Duration
+}
+
+// NewTrashService 新しいTrashServiceを作成する
+func NewTrashService(
+	appRepo repositories.AppRepositoryInterface,
+	fieldRepo repositories.FieldRepositoryInterface,
+	deletedRecordRepo repositories.DeletedRecordRepositoryInterface,
+	revisionRepo repositories.RecordRevisionRepositoryInterface,
+	dynamicQuery repositories.DynamicQueryExecutorInterface,
+	permissions PermissionServiceInterface,
+	webhooks WebhookPublisherInterface,
+	attachments AttachmentManagerInterface,
+	retention time.Duration,
+) *TrashService {
+	return &TrashService{
+		appRepo:           appRepo,
+		fieldRepo:         fieldRepo,
+		deletedRecordRepo: deletedRecordRepo,
+		revisionRepo:      revisionRepo,
+		dynamicQuery:      dynamicQuery,
+		permissions:       permissions,
+		webhooks:          webhooks,
+		attachments:       attachments,
+		retention:         retention,
+	}
+}
+
+// requireAdmin 呼び出し元が管理者であることを確認する（WithSystemCall によるシステム内部の呼び出しは許可する）
+func requireAdmin(ctx context.Context) error {
+	claims, ok := middleware.GetUserFromContext(ctx)
+	if ok && claims.Role == "admin" || !ok && isSystemCall(ctx) {
+		return nil
+	}
+	return ErrPermissionDenied
+}
+
+// GetAppTrash アプリのごみ箱のレコード（ページネーション付き）とフィールドを取得する
+// 自分のレコードのみ操作可能な場合は自分が作成したレコードに限り、フィールドはオーナーにのみ返す
+func (s *TrashService) GetAppTrash(ctx context.Context, appID uint64, page, limit int) (*models.AppTrashResponse, error) {
+	app, access, err := s.permissions.AuthorizeApp(ctx, appID, models.AppRoleEditor)
+	if err != nil {
+		return nil, err
+	}
+
+	fields, err := s.fieldRepo.GetByAppID(ctx, appID)
+	if err != nil {
+		return nil, err
+	}
+
+	var createdBy uint64
+	if access.OwnRecordsOnly {
+		createdBy = access.UserID
+	}
+	records, total, err := s.deletedRecordRepo.GetByAppID(ctx, appID, createdBy, page, limit)
+	if err != nil {
+		return nil, err
+	}
+	recordResponses := make([]models.TrashedRecordResponse, len(records))
+	for i := range records {
+		recordResponses[i] = *records[i].ToResponse(storedFields(fields), records[i].DeletedAt.Add(s.retention))
+	}
+
+	fieldResponses := make([]models.TrashedFieldResponse, 0)
+	if access.Role.Includes(models.AppRoleOwner) {
+		trashed, err := s.fieldRepo.GetTrashedByAppID(ctx, app.ID)
+		if err != nil {
+			return nil, err
+		}
+		for i := range trashed {
+			fieldResponses = append(fieldResponses, models.TrashedFieldResponse{
+				FieldResponse: *trashed[i].ToResponse(),
+				DeletedAt:     trashed[i].DeletedAt,
+				PurgeAt:       trashed[i].DeletedAt.Add(s.retention),
+			})
+		}
+	}
+
+	return &models.AppTrashResponse{
+		Fields:     fieldResponses,
+		Records:    recordResponses,
+		Pagination: models.NewPagination(page, limit, total),
+	}, nil
+}
+
+// RestoreRecord ごみ箱のレコードを削除時と同じIDで復元する
+// 復元は変更履歴に記録し、Webhookにはレコードの作成として通知する（自動化ルールは実行しない）
+func (s *TrashService) RestoreRecord(ctx context.Context, appID, recordID uint64) (*models.RecordResponse, error) {
+	app, access, err := s.permissions.AuthorizeApp(ctx, appID, models.AppRoleEditor)
+	if err != nil {
+		return nil, err
+	}
+	if app.IsExternal {
+		return nil, ErrExternalAppReadOnly
+	}
+
+	deleted, err := s.deletedRecordRepo.GetByRecordID(ctx, appID, recordID)
+	if err != nil {
+		return nil, err
+	}
+	if deleted == nil || !access.CanAccessRecord(deleted.CreatedBy) {
+		return nil, ErrRecordNotFound
+	}
+
+	fields, err := s.fieldRepo.GetByAppID(ctx, appID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.dynamicQuery.RestoreRecord(ctx, app.TableName, deleted.ID); err != nil {
+		if errors.Is(err, repositories.ErrDeletedRecordNotFound) {
+			return nil, ErrRecordNotFound
+		}
+		// 参照フィールドの参照先のレコードが削除されている
+		if repositories.IsForeignKeyViolation(err) {
+			return nil, ErrReferencedRecordMissing
+		}
+		return nil, duplicateValueError(err, fields)
+	}
+
+	record, err := s.dynamicQuery.GetRecordByID(ctx, app.TableName, storedFields(fields), recordID)
+	if err != nil {
+		return nil, err
+	}
+	if record == nil {
+		return nil, ErrRecordNotFound
+	}
+
+	revision := newRevision(appID, models.RevisionActionRestore, nil, record, access.UserID)
+	if err := s.revisionRepo.Create(ctx, &revision); err != nil {
+		return nil, err
+	}
+	if err := s.webhooks.Publish(ctx, revisionEvents(revision)...); err != nil {
+		return nil, err
+	}
+	return record, nil
+}
+
+// PurgeRecord ごみ箱のレコードを完全に削除する（管理者のみ）
+func (s *TrashService) PurgeRecord(ctx context.Context, appID, recordID uint64) error {
+	if err := requireAdmin(ctx); err != nil {
+		return err
+	}
+	if _, _, err := s.permissions.AuthorizeApp(ctx, appID, models.AppRoleOwner); err != nil {
+		return err
+	}
+
+	deleted, err := s.deletedRecordRepo.GetByRecordID(ctx, appID, recordID)
+	if err != nil {
+		return err
+	}
+	if deleted == nil {
+		return ErrRecordNotFound
+	}
+	return s.purgeRecord(ctx, deleted)
+}
+
+// purgeRecord ごみ箱のレコードを削除し、添付ファイルを削除待ちにする
+func (s *TrashService) purgeRecord(ctx context.Context, deleted *models.DeletedRecord) error {
+	if err := s.deletedRecordRepo.Delete(ctx, deleted.ID); err != nil {
+		return err
+	}
+	return s.attachments.ReleaseRecords(ctx, deleted.AppID, []uint64{deleted.RecordID})
+}
+
+// RestoreField ごみ箱のフィールドを復元する
+// 計算フィールドの生成列と参照フィールドの外部キー制約は作り直す。
+// 削除後に計算式が使うフィールドや参照先アプリが削除された場合は復元できない
+func (s *TrashService) RestoreField(ctx context.Context, appID, fieldID uint64) (*models.FieldResponse, error) {
+	app, _, err := s.permissions.AuthorizeApp(ctx, appID, models.AppRoleOwner)
+	if err != nil {
+		return nil, err
+	}
+
+	field, err := s.fieldRepo.GetTrashedByID(ctx, fieldID)
+	if err != nil {
+		return nil, err
+	}
+	if field == nil || field.AppID != appID {
+		return nil, ErrFieldNotFound
+	}
+
+	if !app.IsExternal {
+		siblings, err := s.fieldRepo.GetByAppID(ctx, appID)
+		if err != nil {
+			return nil, err
+		}
+		resolver := &referenceResolver{appRepo: s.appRepo, fieldRepo: s.fieldRepo, permissions: s.permissions}
+		target, err := resolver.resolveField(ctx, app, field, siblings)
+		if err != nil {
+			return nil, err
+		}
+
+		switch {
+		case models.FieldType(field.FieldType) == models.FieldTypeFormula:
+			formulas, err := compileFormulaField(app, field, siblings)
+			if err != nil {
+				return nil, err
+			}
+			if err := s.dynamicQuery.SetFormulaColumn(ctx, app.TableName, field, formulas.expression(field.FieldCode)); err != nil {
+				return nil, err
+			}
+		case target != nil:
+			if err := s.dynamicQuery.SetForeignKey(ctx, app.TableName, field.FieldCode, target.TableName, referenceOnDelete(field.Options)); err != nil {
+				if repositories.IsForeignKeyViolation(err) {
+					return nil, ErrReferencedRecordMissing
+				}
+				return nil, err
+			}
+		}
+
+		// 文字列のフィールドは全文検索の対象に戻す
+		if models.IsSearchableField(field) {
+			if err := rebuildSearchColumn(ctx, s.dynamicQuery, app, append(siblings, *field)); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if err := s.fieldRepo.Restore(ctx, fieldID); err != nil {
+		return nil, err
+	}
+
+	// ごみ箱からの復元は、連携先から見るとフィールドの作成と同じ
+	field.UpdatedAt = time.Now()
+	resp := field.ToResponse()
+	if err := s.webhooks.Publish(ctx, fieldEvent(models.WebhookEventFieldCreated, resp)); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// PurgeField ごみ箱のフィールドを完全に削除する（管理者のみ）
+func (s *TrashService) PurgeField(ctx context.Context, appID, fieldID uint64) error {
+	if err := requireAdmin(ctx); err != nil {
+		return err
+	}
+	app, _, err := s.permissions.AuthorizeApp(ctx, appID, models.AppRoleOwner)
+	if err != nil {
+		return err
+	}
+
+	field, err := s.fieldRepo.GetTrashedByID(ctx, fieldID)
+	if err != nil {
+		return err
+	}
+	if field == nil || field.AppID != appID {
+		return ErrFieldNotFound
+	}
+	return s.purgeField(ctx, app, field)
+}
+
+// purgeField ごみ箱のフィールドとその動的テーブルのカラムを削除し、添付ファイルを削除待ちにする
+// 計算フィールドの生成列はごみ箱に移した時点で削除済み
+func (s *TrashService) purgeField(ctx context.Context, app *models.App, field *models.AppField) error {
+	if !app.IsExternal && field.HasColumn() && models.FieldType(field.FieldType) != models.FieldTypeFormula {
+		if err := s.dynamicQuery.DropColumn(ctx, app.TableName, field.FieldCode); err != nil {
+			return err
+		}
+	}
+	if err := s.fieldRepo.Delete(ctx, field.ID); err != nil {
+		return err
+	}
+	if models.FieldType(field.FieldType) == models.FieldTypeAttachment {
+		return s.attachments.ReleaseField(ctx, app.ID, field.FieldCode)
+	}
+	return nil
+}
+
+// GetTrashedApps ごみ箱のアプリのうち、呼び出し元がオーナー権限を持つものを取得する
+func (s *TrashService) GetTrashedApps(ctx context.Context) (*models.TrashedAppListResponse, error) {
+	apps, err := s.appRepo.GetTrashed(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]models.TrashedAppResponse, 0, len(apps))
+	for i := range apps {
+		if _, err := s.permissions.CheckAppAccess(ctx, &apps[i], models.AppRoleOwner); err != nil {
+			if errors.Is(err, ErrAppNotFound) || errors.Is(err, ErrPermissionDenied) {
+				continue
+			}
+			return nil, err
+		}
+		responses = append(responses, models.TrashedAppResponse{
+			AppResponse: *apps[i].ToResponse(),
+			DeletedAt:   apps[i].DeletedAt,
+			PurgeAt:     apps[i].DeletedAt.Add(s.retention),
+		})
+	}
+	return &models.TrashedAppListResponse{Apps: responses}, nil
+}
+
+// RestoreApp ごみ箱のアプリを復元する
+func (s *TrashService) RestoreApp(ctx context.Context, appID uint64) (*models.AppResponse, error) {
+	app, err := s.appRepo.GetTrashedByID(ctx, appID)
+	if err != nil {
+		return nil, err
+	}
+	if app == nil {
+		return nil, ErrAppNotFound
+	}
+	if _, err := s.permissions.CheckAppAccess(ctx, app, models.AppRoleOwner); err != nil {
+		return nil, err
+	}
+
+	if err := s.appRepo.Restore(ctx, appID); err != nil {
+		return nil, err
+	}
+
+	restored, err := s.appRepo.GetByIDWithFields(ctx, appID)
+	if err != nil {
+		return nil, err
+	}
+	if restored == nil {
+		return nil, ErrAppNotFound
+	}
+	return restored.ToResponse(), nil
+}
+
+// PurgeApp ごみ箱のアプリを動的テーブルとともに完全に削除する（管理者のみ）
+func (s *TrashService) PurgeApp(ctx context.Context, appID uint64) error {
+	if err := requireAdmin(ctx); err != nil {
+		return err
+	}
+
+	app, err := s.appRepo.GetTrashedByID(ctx, appID)
+	if err != nil {
+		return err
+	}
+	if app == nil {
+		return ErrAppNotFound
+	}
+	return s.purgeApp(ctx, app)
+}
+
+// purgeApp アプリと動的テーブルを削除し、添付ファイルを削除待ちにする
+// フィールド・ビュー・Webhook・ごみ箱のレコードはカスケードで削除される
+func (s *TrashService) purgeApp(ctx context.Context, app *models.App) error {
+	// 外部データソースのアプリは動的テーブルを削除しない
+	if !app.IsExternal {
+		if err := s.dynamicQuery.DropTable(ctx, app.TableName); err != nil {
+			return err
+		}
+	}
+	if err := s.appRepo.Delete(ctx, app.ID); err != nil {
+		return err
+	}
+	return s.attachments.ReleaseApp(ctx, app.ID)
+}
+
+// purgeExpired 保持期間を過ぎたごみ箱のレコード・フィールド・アプリを完全に削除し、削除した件数を返す
+func (s *TrashService) purgeExpired(ctx context.Context, now time.Time) (int, error) {
+	before := now.Add(-s.retention)
+	n := 0
+
+	records, err := s.deletedRecordRepo.GetBefore(ctx, before, trashPurgeBatchSize)
+	if err != nil {
+		return n, err
+	}
+	for i := range records {
+		if err := s.purgeRecord(ctx, &records[i]); err != nil {
+			return n, err
+		}
+		n++
+	}
+
+	fields, err := s.fieldRepo.GetTrashedBefore(ctx, before, trashPurgeBatchSize)
+	if err != nil {
+		return n, err
+	}
+	for i := range fields {
+		// アプリごとごみ箱に移したフィールドも対象とする
+		app, err := s.appRepo.GetByID(ctx, fields[i].AppID)
+		if err != nil {
+			return n, err
+		}
+		if app == nil {
+			if app, err = s.appRepo.GetTrashedByID(ctx, fields[i].AppID); err != nil {
+				return n, err
+			}
+		}
+		if app == nil {
+			continue
+		}
+		if err := s.purgeField(ctx, app, &fields[i]); err != nil {
+			return n, err
+		}
+		n++
+	}
+
+	apps, err := s.appRepo.GetTrashedBefore(ctx, before, trashPurgeBatchSize)
+	if err != nil {
+		return n, err
+	}
+	for i := range apps {
+		if err := s.purgeApp(ctx, &apps[i]); err != nil {
+			return n, err
+		}
+		n++
+	}
+	return n, nil
+}
@@ -0,0 +1,38 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"nocode-app/backend/internal/repositories"
+)
+
+// trashPurgerLockKey ごみ箱の完全な削除を1台のサーバーだけで実行するためのアドバイザリーロックのキー
+const trashPurgerLockKey int64 = 0x7472617368707267
+
+// TrashPurger 保持期間を過ぎたごみ箱のレコード・フィールド・アプリを完全に削除する構造体
+// 複数のサーバーで起動しても、アドバイザリーロックを取得できた1台だけが実行する
+type TrashPurger struct {
+	trash  *TrashService
+	locker repositories.AdvisoryLockerInterface
+}
+
+// NewTrashPurger 新しいTrashPurgerを作成する
+func NewTrashPurger(trash *TrashService, locker repositories.AdvisoryLockerInterface) *TrashPurger {
+	return &TrashPurger{
+		trash:  trash,
+		locker: locker,
+	}
+}
+
+// ProcessExpired 保持期間を過ぎたものを完全に削除し、削除した件数を返す
+// 他のサーバーが実行中の場合は何もせずに0を返す。ctx は WithSystemCall で作成したものを渡す
+func (p *TrashPurger) ProcessExpired(ctx context.Context, now time.Time) (int, error) {
+	purged := 0
+	_, err := p.locker.TryWithLock(ctx, trashPurgerLockKey, func(ctx context.Context) error {
+		var err error
+		purged, err = p.trash.purgeExpired(ctx, now)
+		return err
+	})
+	return purged, err
+}
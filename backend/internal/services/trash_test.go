@@ -0,0 +1,412 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"nocode-app/backend/internal/middleware"
+	"nocode-app/backend/internal/models"
+	"nocode-app/backend/internal/testhelpers/mocks"
+	"nocode-app/backend/internal/utils"
+)
+
+const trashTestRetention = 30 * 24 * time.Hour
+
+func trashUserContext(userID uint64, role string) context.Context {
+	return middleware.SetUserInContext(context.Background(), &utils.JWTClaims{UserID: userID, Role: role})
+}
+
+func TestTrashService_GetAppTrash(t *testing.T) {
+	app := &models.App{ID: 1, TableName: "app_data_1", CreatedBy: 10}
+	fields := []models.AppField{
+		{ID: 1, AppID: 1, FieldCode: "name", FieldType: "text"},
+	}
+	deletedAt := time.Date(2026, 10, 1, 9, 0, 0, 0, time.UTC)
+
+	t.Run("owner sees records and fields", func(t *testing.T) {
+		mockAppRepo := new(mocks.MockAppRepository)
+		mockFieldRepo := new(mocks.MockFieldRepository)
+		mockDeletedRecordRepo := new(mocks.MockDeletedRecordRepository)
+		service := NewTrashService(mockAppRepo, mockFieldRepo, mockDeletedRecordRepo, new(mocks.MockRecordRevisionRepository), new(mocks.MockDynamicQueryExecutor), NewPermissionService(new(mocks.MockAppPermissionRepository), new(mocks.MockGroupRepository), mockAppRepo, new(mocks.MockUserRepository)), new(mocks.MockWebhookPublisher), new(mocks.MockAttachmentManager), trashTestRetention)
+
+		ctx := trashUserContext(10, "user")
+		mockAppRepo.On("GetByID", ctx, uint64(1)).Return(app, nil)
+		mockFieldRepo.On("GetByAppID", ctx, uint64(1)).Return(fields, nil)
+		mockDeletedRecordRepo.On("GetByAppID", ctx, uint64(1), uint64(0), 1, 20).Return([]models.DeletedRecord{
+			{ID: 3, AppID: 1, RecordID: 7, Data: models.RecordData{"name": "山田", "memo": "削除済みフィールド"}, CreatedBy: 10, DeletedAt: deletedAt},
+		}, int64(1), nil)
+		mockFieldRepo.On("GetTrashedByAppID", ctx, uint64(1)).Return([]models.AppField{
+			{ID: 2, AppID: 1, FieldCode: "memo", FieldType: "textarea", DeletedAt: deletedAt},
+		}, nil)
+
+		resp, err := service.GetAppTrash(ctx, 1, 1, 20)
+		require.NoError(t, err)
+		require.Len(t, resp.Records, 1)
+		assert.Equal(t, uint64(7), resp.Records[0].ID)
+		assert.Equal(t, models.RecordData{"name": "山田"}, resp.Records[0].Data)
+		assert.Equal(t, deletedAt.Add(trashTestRetention), resp.Records[0].PurgeAt)
+		require.Len(t, resp.Fields, 1)
+		assert.Equal(t, "memo", resp.Fields[0].FieldCode)
+		assert.Equal(t, int64(1), resp.Pagination.Total)
+	})
+
+	t.Run("editor with own records only sees own records without fields", func(t *testing.T) {
+		mockAppRepo := new(mocks.MockAppRepository)
+		mockFieldRepo := new(mocks.MockFieldRepository)
+		mockDeletedRecordRepo := new(mocks.MockDeletedRecordRepository)
+		mockPermRepo := new(mocks.MockAppPermissionRepository)
+		mockGroupRepo := new(mocks.MockGroupRepository)
+		service := NewTrashService(mockAppRepo, mockFieldRepo, mockDeletedRecordRepo, new(mocks.MockRecordRevisionRepository), new(mocks.MockDynamicQueryExecutor), NewPermissionService(mockPermRepo, mockGroupRepo, mockAppRepo, new(mocks.MockUserRepository)), new(mocks.MockWebhookPublisher), new(mocks.MockAttachmentManager), trashTestRetention)
+
+		ctx := trashUserContext(20, "user")
+		mockAppRepo.On("GetByID", ctx, uint64(1)).Return(app, nil)
+		mockPermRepo.On("HasAny", ctx, uint64(1)).Return(true, nil)
+		mockGroupRepo.On("GetGroupIDsByUserID", ctx, uint64(20)).Return([]uint64{}, nil)
+		mockPermRepo.On("GetForSubjects", ctx, uint64(1), uint64(20), mock.Anything).Return([]models.AppPermission{
+			{AppID: 1, Role: models.AppRoleEditor, OwnRecordsOnly: true},
+		}, nil)
+		mockFieldRepo.On("GetByAppID", ctx, uint64(1)).Return(fields, nil)
+		mockDeletedRecordRepo.On("GetByAppID", ctx, uint64(1), uint64(20), 1, 20).Return([]models.DeletedRecord{}, int64(0), nil)
+
+		resp, err := service.GetAppTrash(ctx, 1, 1, 20)
+		require.NoError(t, err)
+		assert.Empty(t, resp.Records)
+		assert.Empty(t, resp.Fields)
+		mockFieldRepo.AssertNotCalled(t, "GetTrashedByAppID", mock.Anything, mock.Anything)
+	})
+
+	t.Run("viewer is denied", func(t *testing.T) {
+		mockAppRepo := new(mocks.MockAppRepository)
+		mockPermRepo := new(mocks.MockAppPermissionRepository)
+		service := NewTrashService(mockAppRepo, new(mocks.MockFieldRepository), new(mocks.MockDeletedRecordRepository), new(mocks.MockRecordRevisionRepository), new(mocks.MockDynamicQueryExecutor), NewPermissionService(mockPermRepo, new(mocks.MockGroupRepository), mockAppRepo, new(mocks.MockUserRepository)), new(mocks.MockWebhookPublisher), new(mocks.MockAttachmentManager), trashTestRetention)
+
+		ctx := trashUserContext(20, "user")
+		mockAppRepo.On("GetByID", ctx, uint64(1)).Return(app, nil)
+		mockPermRepo.On("HasAny", ctx, uint64(1)).Return(false, nil)
+
+		_, err := service.GetAppTrash(ctx, 1, 1, 20)
+		assert.ErrorIs(t, err, ErrPermissionDenied)
+	})
+}
+
+func TestTrashService_RestoreRecord(t *testing.T) {
+	ctx := WithSystemCall(context.Background())
+	app := &models.App{ID: 1, TableName: "app_data_1"}
+	fields := []models.AppField{{ID: 1, AppID: 1, FieldCode: "name", FieldType: "text"}}
+	deleted := &models.DeletedRecord{ID: 3, AppID: 1, RecordID: 7, CreatedBy: 10}
+
+	t.Run("restores record with revision and webhook", func(t *testing.T) {
+		mockAppRepo := new(mocks.MockAppRepository)
+		mockFieldRepo := new(mocks.MockFieldRepository)
+		mockDeletedRecordRepo := new(mocks.MockDeletedRecordRepository)
+		mockRevisionRepo := new(mocks.MockRecordRevisionRepository)
+		mockDynamicQuery := new(mocks.MockDynamicQueryExecutor)
+		mockPublisher := new(mocks.MockWebhookPublisher)
+		service := NewTrashService(mockAppRepo, mockFieldRepo, mockDeletedRecordRepo, mockRevisionRepo, mockDynamicQuery, NewPermissionService(new(mocks.MockAppPermissionRepository), new(mocks.MockGroupRepository), mockAppRepo, new(mocks.MockUserRepository)), mockPublisher, new(mocks.MockAttachmentManager), trashTestRetention)
+
+		mockAppRepo.On("GetByID", ctx, uint64(1)).Return(app, nil)
+		mockDeletedRecordRepo.On("GetByRecordID", ctx, uint64(1), uint64(7)).Return(deleted, nil)
+		mockFieldRepo.On("GetByAppID", ctx, uint64(1)).Return(fields, nil)
+		mockDynamicQuery.On("RestoreRecord", ctx, "app_data_1", uint64(3)).Return(nil)
+		mockDynamicQuery.On("GetRecordByID", ctx, "app_data_1", fields, uint64(7)).Return(&models.RecordResponse{ID: 7, Data: models.RecordData{"name": "山田"}}, nil)
+		mockRevisionRepo.On("Create", ctx, mock.MatchedBy(func(rev *models.RecordRevision) bool {
+			return rev.RecordID == 7 && rev.Action == models.RevisionActionRestore
+		})).Return(nil)
+		mockPublisher.On("Publish", ctx, mock.MatchedBy(func(events []models.WebhookEvent) bool {
+			return len(events) == 1 && events[0].Event == models.WebhookEventRecordCreated
+		})).Return(nil)
+
+		record, err := service.RestoreRecord(ctx, 1, 7)
+		require.NoError(t, err)
+		assert.Equal(t, uint64(7), record.ID)
+		mockRevisionRepo.AssertExpectations(t)
+		mockPublisher.AssertExpectations(t)
+	})
+
+	t.Run("referenced record is missing", func(t *testing.T) {
+		mockAppRepo := new(mocks.MockAppRepository)
+		mockFieldRepo := new(mocks.MockFieldRepository)
+		mockDeletedRecordRepo := new(mocks.MockDeletedRecordRepository)
+		mockRevisionRepo := new(mocks.MockRecordRevisionRepository)
+		mockDynamicQuery := new(mocks.MockDynamicQueryExecutor)
+		service := NewTrashService(mockAppRepo, mockFieldRepo, mockDeletedRecordRepo, mockRevisionRepo, mockDynamicQuery, NewPermissionService(new(mocks.MockAppPermissionRepository), new(mocks.MockGroupRepository), mockAppRepo, new(mocks.MockUserRepository)), new(mocks.MockWebhookPublisher), new(mocks.MockAttachmentManager), trashTestRetention)
+
+		mockAppRepo.On("GetByID", ctx, uint64(1)).Return(app, nil)
+		mockDeletedRecordRepo.On("GetByRecordID", ctx, uint64(1), uint64(7)).Return(deleted, nil)
+		mockFieldRepo.On("GetByAppID", ctx, uint64(1)).Return(fields, nil)
+		mockDynamicQuery.On("RestoreRecord", ctx, "app_data_1", uint64(3)).Return(&pq.Error{Code: "23503"})
+
+		_, err := service.RestoreRecord(ctx, 1, 7)
+		assert.ErrorIs(t, err, ErrReferencedRecordMissing)
+		mockRevisionRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	})
+
+	t.Run("other user's record is hidden from own records only editor", func(t *testing.T) {
+		mockAppRepo := new(mocks.MockAppRepository)
+		mockDeletedRecordRepo := new(mocks.MockDeletedRecordRepository)
+		mockDynamicQuery := new(mocks.MockDynamicQueryExecutor)
+		mockPermRepo := new(mocks.MockAppPermissionRepository)
+		mockGroupRepo := new(mocks.MockGroupRepository)
+		service := NewTrashService(mockAppRepo, new(mocks.MockFieldRepository), mockDeletedRecordRepo, new(mocks.MockRecordRevisionRepository), mockDynamicQuery, NewPermissionService(mockPermRepo, mockGroupRepo, mockAppRepo, new(mocks.MockUserRepository)), new(mocks.MockWebhookPublisher), new(mocks.MockAttachmentManager), trashTestRetention)
+
+		userCtx := trashUserContext(20, "user")
+		mockAppRepo.On("GetByID", userCtx, uint64(1)).Return(app, nil)
+		mockPermRepo.On("HasAny", userCtx, uint64(1)).Return(true, nil)
+		mockGroupRepo.On("GetGroupIDsByUserID", userCtx, uint64(20)).Return([]uint64{}, nil)
+		mockPermRepo.On("GetForSubjects", userCtx, uint64(1), uint64(20), mock.Anything).Return([]models.AppPermission{
+			{AppID: 1, Role: models.AppRoleEditor, OwnRecordsOnly: true},
+		}, nil)
+		mockDeletedRecordRepo.On("GetByRecordID", userCtx, uint64(1), uint64(7)).Return(deleted, nil)
+
+		_, err := service.RestoreRecord(userCtx, 1, 7)
+		assert.ErrorIs(t, err, ErrRecordNotFound)
+		mockDynamicQuery.AssertNotCalled(t, "RestoreRecord", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestTrashService_PurgeRecord(t *testing.T) {
+	app := &models.App{ID: 1, TableName: "app_data_1", CreatedBy: 10}
+
+	t.Run("admin purges record and releases attachments", func(t *testing.T) {
+		mockAppRepo := new(mocks.MockAppRepository)
+		mockDeletedRecordRepo := new(mocks.MockDeletedRecordRepository)
+		mockAttachments := new(mocks.MockAttachmentManager)
+		service := NewTrashService(mockAppRepo, new(mocks.MockFieldRepository), mockDeletedRecordRepo, new(mocks.MockRecordRevisionRepository), new(mocks.MockDynamicQueryExecutor), NewPermissionService(new(mocks.MockAppPermissionRepository), new(mocks.MockGroupRepository), mockAppRepo, new(mocks.MockUserRepository)), new(mocks.MockWebhookPublisher), mockAttachments, trashTestRetention)
+
+		ctx := trashUserContext(1, "admin")
+		mockAppRepo.On("GetByID", ctx, uint64(1)).Return(app, nil)
+		mockDeletedRecordRepo.On("GetByRecordID", ctx, uint64(1), uint64(7)).Return(&models.DeletedRecord{ID: 3, AppID: 1, RecordID: 7}, nil)
+		mockDeletedRecordRepo.On("Delete", ctx, uint64(3)).Return(nil)
+		mockAttachments.On("ReleaseRecords", ctx, uint64(1), []uint64{7}).Return(nil)
+
+		require.NoError(t, service.PurgeRecord(ctx, 1, 7))
+		mockDeletedRecordRepo.AssertExpectations(t)
+		mockAttachments.AssertExpectations(t)
+	})
+
+	t.Run("app owner who is not admin is denied", func(t *testing.T) {
+		mockAppRepo := new(mocks.MockAppRepository)
+		mockDeletedRecordRepo := new(mocks.MockDeletedRecordRepository)
+		service := NewTrashService(mockAppRepo, new(mocks.MockFieldRepository), mockDeletedRecordRepo, new(mocks.MockRecordRevisionRepository), new(mocks.MockDynamicQueryExecutor), NewPermissionService(new(mocks.MockAppPermissionRepository), new(mocks.MockGroupRepository), mockAppRepo, new(mocks.MockUserRepository)), new(mocks.MockWebhookPublisher), new(mocks.MockAttachmentManager), trashTestRetention)
+
+		ctx := trashUserContext(10, "user")
+
+		err := service.PurgeRecord(ctx, 1, 7)
+		assert.ErrorIs(t, err, ErrPermissionDenied)
+		mockDeletedRecordRepo.AssertNotCalled(t, "Delete", mock.Anything, mock.Anything)
+	})
+}
+
+func TestTrashService_RestoreField(t *testing.T) {
+	ctx := WithSystemCall(context.Background())
+	orderApp := &models.App{ID: 1, TableName: "app_data_1"}
+	customerApp := &models.App{ID: 2, TableName: "app_data_2"}
+
+	t.Run("reference field gets foreign key back", func(t *testing.T) {
+		mockAppRepo := new(mocks.MockAppRepository)
+		mockFieldRepo := new(mocks.MockFieldRepository)
+		mockDynamicQuery := new(mocks.MockDynamicQueryExecutor)
+		mockPublisher := new(mocks.MockWebhookPublisher)
+		service := NewTrashService(mockAppRepo, mockFieldRepo, new(mocks.MockDeletedRecordRepository), new(mocks.MockRecordRevisionRepository), mockDynamicQuery, NewPermissionService(new(mocks.MockAppPermissionRepository), new(mocks.MockGroupRepository), mockAppRepo, new(mocks.MockUserRepository)), mockPublisher, new(mocks.MockAttachmentManager), trashTestRetention)
+
+		field := &models.AppField{ID: 5, AppID: 1, FieldCode: "customer", FieldType: "reference", Options: models.FieldOptions{"app_id": float64(2)}}
+		mockAppRepo.On("GetByID", ctx, uint64(1)).Return(orderApp, nil)
+		mockAppRepo.On("GetByID", ctx, uint64(2)).Return(customerApp, nil)
+		mockFieldRepo.On("GetTrashedByID", ctx, uint64(5)).Return(field, nil)
+		mockFieldRepo.On("GetByAppID", ctx, uint64(1)).Return([]models.AppField{}, nil)
+		mockDynamicQuery.On("SetForeignKey", ctx, "app_data_1", "customer", "app_data_2", models.ReferenceOnDeleteRestrict).Return(nil)
+		mockFieldRepo.On("Restore", ctx, uint64(5)).Return(nil)
+		mockPublisher.On("Publish", ctx, mock.MatchedBy(func(events []models.WebhookEvent) bool {
+			return len(events) == 1 && events[0].Event == models.WebhookEventFieldCreated
+		})).Return(nil)
+
+		resp, err := service.RestoreField(ctx, 1, 5)
+		require.NoError(t, err)
+		assert.Equal(t, "customer", resp.FieldCode)
+		mockDynamicQuery.AssertExpectations(t)
+		mockFieldRepo.AssertExpectations(t)
+		mockPublisher.AssertExpectations(t)
+	})
+
+	t.Run("referenced records were purged", func(t *testing.T) {
+		mockAppRepo := new(mocks.MockAppRepository)
+		mockFieldRepo := new(mocks.MockFieldRepository)
+		mockDynamicQuery := new(mocks.MockDynamicQueryExecutor)
+		service := NewTrashService(mockAppRepo, mockFieldRepo, new(mocks.MockDeletedRecordRepository), new(mocks.MockRecordRevisionRepository), mockDynamicQuery, NewPermissionService(new(mocks.MockAppPermissionRepository), new(mocks.MockGroupRepository), mockAppRepo, new(mocks.MockUserRepository)), new(mocks.MockWebhookPublisher), new(mocks.MockAttachmentManager), trashTestRetention)
+
+		field := &models.AppField{ID: 5, AppID: 1, FieldCode: "customer", FieldType: "reference", Options: models.FieldOptions{"app_id": float64(2)}}
+		mockAppRepo.On("GetByID", ctx, uint64(1)).Return(orderApp, nil)
+		mockAppRepo.On("GetByID", ctx, uint64(2)).Return(customerApp, nil)
+		mockFieldRepo.On("GetTrashedByID", ctx, uint64(5)).Return(field, nil)
+		mockFieldRepo.On("GetByAppID", ctx, uint64(1)).Return([]models.AppField{}, nil)
+		mockDynamicQuery.On("SetForeignKey", ctx, "app_data_1", "customer", "app_data_2", models.ReferenceOnDeleteRestrict).Return(&pq.Error{Code: "23503"})
+
+		_, err := service.RestoreField(ctx, 1, 5)
+		assert.ErrorIs(t, err, ErrReferencedRecordMissing)
+		mockFieldRepo.AssertNotCalled(t, "Restore", mock.Anything, mock.Anything)
+	})
+
+	t.Run("field of another app", func(t *testing.T) {
+		mockAppRepo := new(mocks.MockAppRepository)
+		mockFieldRepo := new(mocks.MockFieldRepository)
+		service := NewTrashService(mockAppRepo, mockFieldRepo, new(mocks.MockDeletedRecordRepository), new(mocks.MockRecordRevisionRepository), new(mocks.MockDynamicQueryExecutor), NewPermissionService(new(mocks.MockAppPermissionRepository), new(mocks.MockGroupRepository), mockAppRepo, new(mocks.MockUserRepository)), new(mocks.MockWebhookPublisher), new(mocks.MockAttachmentManager), trashTestRetention)
+
+		mockAppRepo.On("GetByID", ctx, uint64(1)).Return(orderApp, nil)
+		mockFieldRepo.On("GetTrashedByID", ctx, uint64(5)).Return(&models.AppField{ID: 5, AppID: 2}, nil)
+
+		_, err := service.RestoreField(ctx, 1, 5)
+		assert.ErrorIs(t, err, ErrFieldNotFound)
+	})
+}
+
+func TestTrashService_GetTrashedApps(t *testing.T) {
+	mockAppRepo := new(mocks.MockAppRepository)
+	mockPermRepo := new(mocks.MockAppPermissionRepository)
+	service := NewTrashService(mockAppRepo, new(mocks.MockFieldRepository), new(mocks.MockDeletedRecordRepository), new(mocks.MockRecordRevisionRepository), new(mocks.MockDynamicQueryExecutor), NewPermissionService(mockPermRepo, new(mocks.MockGroupRepository), mockAppRepo, new(mocks.MockUserRepository)), new(mocks.MockWebhookPublisher), new(mocks.MockAttachmentManager), trashTestRetention)
+
+	ctx := trashUserContext(10, "user")
+	deletedAt := time.Date(2026, 10, 1, 9, 0, 0, 0, time.UTC)
+	mockAppRepo.On("GetTrashed", ctx).Return([]models.App{
+		{ID: 1, Name: "顧客", CreatedBy: 10, DeletedAt: deletedAt},
+		{ID: 2, Name: "他人のアプリ", CreatedBy: 20, DeletedAt: deletedAt},
+	}, nil)
+	mockPermRepo.On("HasAny", ctx, uint64(2)).Return(false, nil)
+
+	resp, err := service.GetTrashedApps(ctx)
+	require.NoError(t, err)
+	require.Len(t, resp.Apps, 1)
+	assert.Equal(t, uint64(1), resp.Apps[0].ID)
+	assert.Equal(t, deletedAt.Add(trashTestRetention), resp.Apps[0].PurgeAt)
+}
+
+func TestTrashService_RestoreApp(t *testing.T) {
+	ctx := WithSystemCall(context.Background())
+
+	t.Run("restores trashed app", func(t *testing.T) {
+		mockAppRepo := new(mocks.MockAppRepository)
+		service := NewTrashService(mockAppRepo, new(mocks.MockFieldRepository), new(mocks.MockDeletedRecordRepository), new(mocks.MockRecordRevisionRepository), new(mocks.MockDynamicQueryExecutor), NewPermissionService(new(mocks.MockAppPermissionRepository), new(mocks.MockGroupRepository), mockAppRepo, new(mocks.MockUserRepository)), new(mocks.MockWebhookPublisher), new(mocks.MockAttachmentManager), trashTestRetention)
+
+		mockAppRepo.On("GetTrashedByID", ctx, uint64(1)).Return(&models.App{ID: 1}, nil)
+		mockAppRepo.On("Restore", ctx, uint64(1)).Return(nil)
+		mockAppRepo.On("GetByIDWithFields", ctx, uint64(1)).Return(&models.App{ID: 1, Name: "顧客"}, nil)
+
+		resp, err := service.RestoreApp(ctx, 1)
+		require.NoError(t, err)
+		assert.Equal(t, "顧客", resp.Name)
+	})
+
+	t.Run("app is not in trash", func(t *testing.T) {
+		mockAppRepo := new(mocks.MockAppRepository)
+		service := NewTrashService(mockAppRepo, new(mocks.MockFieldRepository), new(mocks.MockDeletedRecordRepository), new(mocks.MockRecordRevisionRepository), new(mocks.MockDynamicQueryExecutor), NewPermissionService(new(mocks.MockAppPermissionRepository), new(mocks.MockGroupRepository), mockAppRepo, new(mocks.MockUserRepository)), new(mocks.MockWebhookPublisher), new(mocks.MockAttachmentManager), trashTestRetention)
+
+		mockAppRepo.On("GetTrashedByID", ctx, uint64(1)).Return(nil, nil)
+
+		_, err := service.RestoreApp(ctx, 1)
+		assert.ErrorIs(t, err, ErrAppNotFound)
+	})
+}
+
+func TestTrashService_PurgeApp(t *testing.T) {
+	t.Run("admin purges app with table", func(t *testing.T) {
+		mockAppRepo := new(mocks.MockAppRepository)
+		mockDynamicQuery := new(mocks.MockDynamicQueryExecutor)
+		mockAttachments := new(mocks.MockAttachmentManager)
+		service := NewTrashService(mockAppRepo, new(mocks.MockFieldRepository), new(mocks.MockDeletedRecordRepository), new(mocks.MockRecordRevisionRepository), mockDynamicQuery, NewPermissionService(new(mocks.MockAppPermissionRepository), new(mocks.MockGroupRepository), mockAppRepo, new(mocks.MockUserRepository)), new(mocks.MockWebhookPublisher), mockAttachments, trashTestRetention)
+
+		ctx := trashUserContext(1, "admin")
+		mockAppRepo.On("GetTrashedByID", ctx, uint64(1)).Return(&models.App{ID: 1, TableName: "app_data_1"}, nil)
+		mockDynamicQuery.On("DropTable", ctx, "app_data_1").Return(nil)
+		mockAppRepo.On("Delete", ctx, uint64(1)).Return(nil)
+		mockAttachments.On("ReleaseApp", ctx, uint64(1)).Return(nil)
+
+		require.NoError(t, service.PurgeApp(ctx, 1))
+		mockDynamicQuery.AssertExpectations(t)
+		mockAttachments.AssertExpectations(t)
+	})
+
+	t.Run("external app keeps table", func(t *testing.T) {
+		mockAppRepo := new(mocks.MockAppRepository)
+		mockDynamicQuery := new(mocks.MockDynamicQueryExecutor)
+		mockAttachments := new(mocks.MockAttachmentManager)
+		service := NewTrashService(mockAppRepo, new(mocks.MockFieldRepository), new(mocks.MockDeletedRecordRepository), new(mocks.MockRecordRevisionRepository), mockDynamicQuery, NewPermissionService(new(mocks.MockAppPermissionRepository), new(mocks.MockGroupRepository), mockAppRepo, new(mocks.MockUserRepository)), new(mocks.MockWebhookPublisher), mockAttachments, trashTestRetention)
+
+		ctx := trashUserContext(1, "admin")
+		mockAppRepo.On("GetTrashedByID", ctx, uint64(1)).Return(&models.App{ID: 1, TableName: "customers", IsExternal: true}, nil)
+		mockAppRepo.On("Delete", ctx, uint64(1)).Return(nil)
+		mockAttachments.On("ReleaseApp", ctx, uint64(1)).Return(nil)
+
+		require.NoError(t, service.PurgeApp(ctx, 1))
+		mockDynamicQuery.AssertNotCalled(t, "DropTable", mock.Anything, mock.Anything)
+	})
+
+	t.Run("non-admin is denied", func(t *testing.T) {
+		mockAppRepo := new(mocks.MockAppRepository)
+		service := NewTrashService(mockAppRepo, new(mocks.MockFieldRepository), new(mocks.MockDeletedRecordRepository), new(mocks.MockRecordRevisionRepository), new(mocks.MockDynamicQueryExecutor), NewPermissionService(new(mocks.MockAppPermissionRepository), new(mocks.MockGroupRepository), mockAppRepo, new(mocks.MockUserRepository)), new(mocks.MockWebhookPublisher), new(mocks.MockAttachmentManager), trashTestRetention)
+
+		err := service.PurgeApp(trashUserContext(10, "user"), 1)
+		assert.ErrorIs(t, err, ErrPermissionDenied)
+		mockAppRepo.AssertNotCalled(t, "Delete", mock.Anything, mock.Anything)
+	})
+}
+
+func TestTrashPurger_ProcessExpired(t *testing.T) {
+	ctx := WithSystemCall(context.Background())
+	now := time.Date(2026, 10, 16, 3, 0, 0, 0, time.UTC)
+	before := now.Add(-trashTestRetention)
+
+	t.Run("purges expired records, fields and apps", func(t *testing.T) {
+		mockAppRepo := new(mocks.MockAppRepository)
+		mockFieldRepo := new(mocks.MockFieldRepository)
+		mockDeletedRecordRepo := new(mocks.MockDeletedRecordRepository)
+		mockDynamicQuery := new(mocks.MockDynamicQueryExecutor)
+		mockAttachments := new(mocks.MockAttachmentManager)
+		service := NewTrashService(mockAppRepo, mockFieldRepo, mockDeletedRecordRepo, new(mocks.MockRecordRevisionRepository), mockDynamicQuery, NewPermissionService(new(mocks.MockAppPermissionRepository), new(mocks.MockGroupRepository), mockAppRepo, new(mocks.MockUserRepository)), new(mocks.MockWebhookPublisher), mockAttachments, trashTestRetention)
+
+		locker := new(mocks.MockAdvisoryLocker)
+		locker.On("TryWithLock", ctx, trashPurgerLockKey).Return(true, nil)
+
+		mockDeletedRecordRepo.On("GetBefore", ctx, before, trashPurgeBatchSize).Return([]models.DeletedRecord{{ID: 3, AppID: 1, RecordID: 7}}, nil)
+		mockDeletedRecordRepo.On("Delete", ctx, uint64(3)).Return(nil)
+		mockAttachments.On("ReleaseRecords", ctx, uint64(1), []uint64{7}).Return(nil)
+
+		// アプリごとごみ箱に移したフィールド
+		mockFieldRepo.On("GetTrashedBefore", ctx, before, trashPurgeBatchSize).Return([]models.AppField{
+			{ID: 5, AppID: 2, FieldCode: "memo", FieldType: "text"},
+		}, nil)
+		mockAppRepo.On("GetByID", ctx, uint64(2)).Return(nil, nil)
+		mockAppRepo.On("GetTrashedByID", ctx, uint64(2)).Return(&models.App{ID: 2, TableName: "app_data_2"}, nil)
+		mockDynamicQuery.On("DropColumn", ctx, "app_data_2", "memo").Return(nil)
+		mockFieldRepo.On("Delete", ctx, uint64(5)).Return(nil)
+
+		mockAppRepo.On("GetTrashedBefore", ctx, before, trashPurgeBatchSize).Return([]models.App{{ID: 2, TableName: "app_data_2"}}, nil)
+		mockDynamicQuery.On("DropTable", ctx, "app_data_2").Return(nil)
+		mockAppRepo.On("Delete", ctx, uint64(2)).Return(nil)
+		mockAttachments.On("ReleaseApp", ctx, uint64(2)).Return(nil)
+
+		purged, err := NewTrashPurger(service, locker).ProcessExpired(ctx, now)
+		require.NoError(t, err)
+		assert.Equal(t, 3, purged)
+		mockDynamicQuery.AssertExpectations(t)
+		mockAttachments.AssertExpectations(t)
+	})
+
+	t.Run("another server holds the lock", func(t *testing.T) {
+		mockAppRepo := new(mocks.MockAppRepository)
+		mockDeletedRecordRepo := new(mocks.MockDeletedRecordRepository)
+		service := NewTrashService(mockAppRepo, new(mocks.MockFieldRepository), mockDeletedRecordRepo, new(mocks.MockRecordRevisionRepository), new(mocks.MockDynamicQueryExecutor), NewPermissionService(new(mocks.MockAppPermissionRepository), new(mocks.MockGroupRepository), mockAppRepo, new(mocks.MockUserRepository)), new(mocks.MockWebhookPublisher), new(mocks.MockAttachmentManager), trashTestRetention)
+
+		locker := new(mocks.MockAdvisoryLocker)
+		locker.On("TryWithLock", ctx, trashPurgerLockKey).Return(false, nil)
+
+		purged, err := NewTrashPurger(service, locker).ProcessExpired(ctx, now)
+		require.NoError(t, err)
+		assert.Zero(t, purged)
+		mockDeletedRecordRepo.AssertNotCalled(t, "GetBefore", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
@@ -0,0 +1,31 @@
+package services
+
+import "errors"
+
+// ErrVersionConflict 更新・削除の対象がクライアントの取得後に変更されていた場合のエラー
+var ErrVersionConflict = errors.New("他のユーザーによって変更されています。最新の内容を取得してからやり直してください")
+
+// VersionConflictError バージョンの不一致を、サーバーにある現在の内容とともに保持するエラー
+type VersionConflictError struct {
+	Version int64
+	Current interface{}
+}
+
+// Error errorインターフェースを実装する
+func (e *VersionConflictError) Error() string {
+	return ErrVersionConflict.Error()
+}
+
+// Unwrap errors.Is で ErrVersionConflict と判定できるようにする
+func (e *VersionConflictError) Unwrap() error {
+	return ErrVersionConflict
+}
+
+// checkVersion クライアントが取得したときのバージョンと現在のバージョンを比べる
+// expected が0の場合（If-Match: *）は確認しない
+func checkVersion(expected, current int64, currentResource interface{}) error {
+	if expected == 0 || expected == current {
+		return nil
+	}
+	return &VersionConflictError{Version: current, Current: currentResource}
+}
@@ -16,20 +16,26 @@ var (
 
 // ViewService ビュー操作を処理する構造体
 type ViewService struct {
-	viewRepo repositories.ViewRepositoryInterface
-	appRepo  repositories.AppRepositoryInterface
+	viewRepo    repositories.ViewRepositoryInterface
+	appRepo     repositories.AppRepositoryInterface
+	permissions PermissionServiceInterface
 }
 
 // NewViewService 新しいViewServiceを作成する
-func NewViewService(viewRepo repositories.ViewRepositoryInterface, appRepo repositories.AppRepositoryInterface) *ViewService {
+func NewViewService(viewRepo repositories.ViewRepositoryInterface, appRepo repositories.AppRepositoryInterface, permissions PermissionServiceInterface) *ViewService {
 	return &ViewService{
-		viewRepo: viewRepo,
-		appRepo:  appRepo,
+		viewRepo:    viewRepo,
+		appRepo:     appRepo,
+		permissions: permissions,
 	}
 }
 
 // GetViews アプリの全ビューを取得する
 func (s *ViewService) GetViews(ctx context.Context, appID uint64) ([]models.ViewResponse, error) {
+	if _, _, err := s.permissions.AuthorizeApp(ctx, appID, models.AppRoleViewer); err != nil {
+		return nil, err
+	}
+
 	views, err := s.viewRepo.GetByAppID(ctx, appID)
 	if err != nil {
 		return nil, err
@@ -45,14 +51,10 @@ func (s *ViewService) GetViews(ctx context.Context, appID uint64) ([]models.View
 
 // CreateView 新しいビューを作成する
 func (s *ViewService) CreateView(ctx context.Context, appID uint64, req *models.CreateViewRequest) (*models.ViewResponse, error) {
-	// アプリの存在確認
-	app, err := s.appRepo.GetByID(ctx, appID)
-	if err != nil {
+	// アプリの存在と権限を確認
+	if _, _, err := s.permissions.AuthorizeApp(ctx, appID, models.AppRoleEditor); err != nil {
 		return nil, err
 	}
-	if app == nil {
-		return nil, ErrAppNotFound
-	}
 
 	now := time.Now()
 	view := &models.AppView{
@@ -80,7 +82,8 @@ func (s *ViewService) CreateView(ctx context.Context, appID uint64, req *models.
 }
 
 // UpdateView ビューを更新する
-func (s *ViewService) UpdateView(ctx context.Context, viewID uint64, req *models.UpdateViewRequest) (*models.ViewResponse, error) {
+// version が0でなく現在のバージョンと異なる場合は VersionConflictError を返す
+func (s *ViewService) UpdateView(ctx context.Context, viewID uint64, version int64, req *models.UpdateViewRequest) (*models.ViewResponse, error) {
 	view, err := s.viewRepo.GetByID(ctx, viewID)
 	if err != nil {
 		return nil, err
@@ -88,6 +91,12 @@ func (s *ViewService) UpdateView(ctx context.Context, viewID uint64, req *models
 	if view == nil {
 		return nil, ErrViewNotFound
 	}
+	if _, _, err := s.permissions.AuthorizeApp(ctx, view.AppID, models.AppRoleEditor); err != nil {
+		return nil, err
+	}
+	if err := checkVersion(version, models.VersionOf(view.UpdatedAt), view.ToResponse()); err != nil {
+		return nil, err
+	}
 
 	// フィールドを更新
 	if req.Name != "" {
@@ -107,7 +116,16 @@ func (s *ViewService) UpdateView(ctx context.Context, viewID uint64, req *models
 	}
 	view.UpdatedAt = time.Now()
 
-	if err := s.viewRepo.Update(ctx, view); err != nil {
+	// バージョンを指定した場合は、取得した後に他の更新がなかったときのみ更新する
+	if version != 0 {
+		updated, err := s.viewRepo.UpdateIfVersion(ctx, view, version)
+		if err != nil {
+			return nil, err
+		}
+		if !updated {
+			return nil, s.viewVersionConflict(ctx, viewID)
+		}
+	} else if err := s.viewRepo.Update(ctx, view); err != nil {
 		return nil, err
 	}
 
@@ -115,7 +133,8 @@ func (s *ViewService) UpdateView(ctx context.Context, viewID uint64, req *models
 }
 
 // DeleteView ビューを削除する
-func (s *ViewService) DeleteView(ctx context.Context, viewID uint64) error {
+// version が0でなく現在のバージョンと異なる場合は VersionConflictError を返す
+func (s *ViewService) DeleteView(ctx context.Context, viewID uint64, version int64) error {
 	view, err := s.viewRepo.GetByID(ctx, viewID)
 	if err != nil {
 		return err
@@ -123,6 +142,36 @@ func (s *ViewService) DeleteView(ctx context.Context, viewID uint64) error {
 	if view == nil {
 		return ErrViewNotFound
 	}
+	if _, _, err := s.permissions.AuthorizeApp(ctx, view.AppID, models.AppRoleEditor); err != nil {
+		return err
+	}
+	if err := checkVersion(version, models.VersionOf(view.UpdatedAt), view.ToResponse()); err != nil {
+		return err
+	}
 
+	// バージョンを指定した場合は、取得した後に他の更新がなかったときのみ削除する
+	if version != 0 {
+		deleted, err := s.viewRepo.DeleteIfVersion(ctx, viewID, version)
+		if err != nil {
+			return err
+		}
+		if !deleted {
+			return s.viewVersionConflict(ctx, viewID)
+		}
+		return nil
+	}
 	return s.viewRepo.Delete(ctx, viewID)
 }
+
+// viewVersionConflict 現在のビューを取得し、バージョンの不一致を表すエラーを返す
+// 競合した更新でビューが削除されていた場合は ErrViewNotFound を返す
+func (s *ViewService) viewVersionConflict(ctx context.Context, viewID uint64) error {
+	current, err := s.viewRepo.GetByID(ctx, viewID)
+	if err != nil {
+		return err
+	}
+	if current == nil {
+		return ErrViewNotFound
+	}
+	return &VersionConflictError{Version: models.VersionOf(current.UpdatedAt), Current: current.ToResponse()}
+}
@@ -1,7 +1,6 @@
 package services_test
 
 import (
-	"context"
 	"testing"
 	"time"
 
@@ -15,7 +14,7 @@ import (
 )
 
 func TestViewService_GetViews(t *testing.T) {
-	ctx := context.Background()
+	ctx := systemContext()
 
 	t.Run("successful get views", func(t *testing.T) {
 		mockViewRepo := new(mocks.MockViewRepository)
@@ -26,9 +25,10 @@ func TestViewService_GetViews(t *testing.T) {
 			{ID: 2, AppID: 1, Name: "List View", ViewType: "list", IsDefault: false, CreatedAt: time.Now(), UpdatedAt: time.Now()},
 		}
 
+		mockAppRepo.On("GetByID", ctx, uint64(1)).Return(&models.App{ID: 1}, nil)
 		mockViewRepo.On("GetByAppID", ctx, uint64(1)).Return(views, nil)
 
-		service := services.NewViewService(mockViewRepo, mockAppRepo)
+		service := services.NewViewService(mockViewRepo, mockAppRepo, newTestPermissionService(mockAppRepo))
 
 		resp, err := service.GetViews(ctx, 1)
 		require.NoError(t, err)
@@ -40,7 +40,7 @@ func TestViewService_GetViews(t *testing.T) {
 }
 
 func TestViewService_CreateView(t *testing.T) {
-	ctx := context.Background()
+	ctx := systemContext()
 
 	t.Run("successful creation with default", func(t *testing.T) {
 		mockViewRepo := new(mocks.MockViewRepository)
@@ -55,7 +55,7 @@ func TestViewService_CreateView(t *testing.T) {
 			view.ID = 1
 		})
 
-		service := services.NewViewService(mockViewRepo, mockAppRepo)
+		service := services.NewViewService(mockViewRepo, mockAppRepo, newTestPermissionService(mockAppRepo))
 
 		req := &models.CreateViewRequest{
 			Name:      "New View",
@@ -78,7 +78,7 @@ func TestViewService_CreateView(t *testing.T) {
 
 		mockAppRepo.On("GetByID", ctx, uint64(999)).Return(nil, nil)
 
-		service := services.NewViewService(mockViewRepo, mockAppRepo)
+		service := services.NewViewService(mockViewRepo, mockAppRepo, newTestPermissionService(mockAppRepo))
 
 		req := &models.CreateViewRequest{
 			Name:     "New View",
@@ -93,7 +93,7 @@ func TestViewService_CreateView(t *testing.T) {
 }
 
 func TestViewService_UpdateView(t *testing.T) {
-	ctx := context.Background()
+	ctx := systemContext()
 
 	t.Run("successful update", func(t *testing.T) {
 		mockViewRepo := new(mocks.MockViewRepository)
@@ -109,17 +109,18 @@ func TestViewService_UpdateView(t *testing.T) {
 			UpdatedAt: time.Now(),
 		}
 
+		mockAppRepo.On("GetByID", ctx, uint64(1)).Return(&models.App{ID: 1}, nil)
 		mockViewRepo.On("GetByID", ctx, uint64(1)).Return(view, nil)
 		mockViewRepo.On("Update", ctx, mock.AnythingOfType("*models.AppView")).Return(nil)
 
-		service := services.NewViewService(mockViewRepo, mockAppRepo)
+		service := services.NewViewService(mockViewRepo, mockAppRepo, newTestPermissionService(mockAppRepo))
 
 		name := "Updated Name"
 		req := &models.UpdateViewRequest{
 			Name: name,
 		}
 
-		resp, err := service.UpdateView(ctx, 1, req)
+		resp, err := service.UpdateView(ctx, 1, 0, req)
 		require.NoError(t, err)
 		assert.Equal(t, "Updated Name", resp.Name)
 
@@ -140,18 +141,19 @@ func TestViewService_UpdateView(t *testing.T) {
 			UpdatedAt: time.Now(),
 		}
 
+		mockAppRepo.On("GetByID", ctx, uint64(1)).Return(&models.App{ID: 1}, nil)
 		mockViewRepo.On("GetByID", ctx, uint64(1)).Return(view, nil)
 		mockViewRepo.On("ClearDefaultByAppID", ctx, uint64(1)).Return(nil)
 		mockViewRepo.On("Update", ctx, mock.AnythingOfType("*models.AppView")).Return(nil)
 
-		service := services.NewViewService(mockViewRepo, mockAppRepo)
+		service := services.NewViewService(mockViewRepo, mockAppRepo, newTestPermissionService(mockAppRepo))
 
 		isDefault := true
 		req := &models.UpdateViewRequest{
 			IsDefault: &isDefault,
 		}
 
-		resp, err := service.UpdateView(ctx, 1, req)
+		resp, err := service.UpdateView(ctx, 1, 0, req)
 		require.NoError(t, err)
 		assert.True(t, resp.IsDefault)
 
@@ -164,19 +166,60 @@ func TestViewService_UpdateView(t *testing.T) {
 
 		mockViewRepo.On("GetByID", ctx, uint64(999)).Return(nil, nil)
 
-		service := services.NewViewService(mockViewRepo, mockAppRepo)
+		service := services.NewViewService(mockViewRepo, mockAppRepo, newTestPermissionService(mockAppRepo))
 
 		req := &models.UpdateViewRequest{}
 
-		_, err := service.UpdateView(ctx, 999, req)
+		_, err := service.UpdateView(ctx, 999, 0, req)
 		assert.ErrorIs(t, err, services.ErrViewNotFound)
 
 		mockViewRepo.AssertExpectations(t)
 	})
+
+	t.Run("view changed since it was read", func(t *testing.T) {
+		mockViewRepo := new(mocks.MockViewRepository)
+		mockAppRepo := new(mocks.MockAppRepository)
+
+		updatedAt := time.Date(2026, 10, 16, 9, 0, 0, 123456000, time.UTC)
+		view := &models.AppView{ID: 1, AppID: 1, Name: "Theirs", UpdatedAt: updatedAt}
+
+		mockAppRepo.On("GetByID", ctx, uint64(1)).Return(&models.App{ID: 1}, nil)
+		mockViewRepo.On("GetByID", ctx, uint64(1)).Return(view, nil)
+
+		service := services.NewViewService(mockViewRepo, mockAppRepo, newTestPermissionService(mockAppRepo))
+
+		_, err := service.UpdateView(ctx, 1, models.VersionOf(updatedAt)-1, &models.UpdateViewRequest{Name: "Mine"})
+		var conflict *services.VersionConflictError
+		require.ErrorAs(t, err, &conflict)
+		assert.Equal(t, models.VersionOf(updatedAt), conflict.Version)
+		assert.Equal(t, "Theirs", conflict.Current.(*models.ViewResponse).Name)
+		mockViewRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+	})
+
+	t.Run("view changed between read and write", func(t *testing.T) {
+		mockViewRepo := new(mocks.MockViewRepository)
+		mockAppRepo := new(mocks.MockAppRepository)
+
+		updatedAt := time.Date(2026, 10, 16, 9, 0, 0, 123456000, time.UTC)
+		theirs := updatedAt.Add(time.Second)
+		mockAppRepo.On("GetByID", ctx, uint64(1)).Return(&models.App{ID: 1}, nil)
+		mockViewRepo.On("GetByID", ctx, uint64(1)).Return(&models.AppView{ID: 1, AppID: 1, Name: "Original", UpdatedAt: updatedAt}, nil).Once()
+		mockViewRepo.On("UpdateIfVersion", ctx, mock.AnythingOfType("*models.AppView"), models.VersionOf(updatedAt)).Return(false, nil)
+		mockViewRepo.On("GetByID", ctx, uint64(1)).Return(&models.AppView{ID: 1, AppID: 1, Name: "Theirs", UpdatedAt: theirs}, nil).Once()
+
+		service := services.NewViewService(mockViewRepo, mockAppRepo, newTestPermissionService(mockAppRepo))
+
+		_, err := service.UpdateView(ctx, 1, models.VersionOf(updatedAt), &models.UpdateViewRequest{Name: "Mine"})
+		var conflict *services.VersionConflictError
+		require.ErrorAs(t, err, &conflict)
+		assert.Equal(t, models.VersionOf(theirs), conflict.Version)
+		assert.Equal(t, "Theirs", conflict.Current.(*models.ViewResponse).Name)
+		mockViewRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+	})
 }
 
 func TestViewService_DeleteView(t *testing.T) {
-	ctx := context.Background()
+	ctx := systemContext()
 
 	t.Run("successful delete", func(t *testing.T) {
 		mockViewRepo := new(mocks.MockViewRepository)
@@ -184,12 +227,13 @@ func TestViewService_DeleteView(t *testing.T) {
 
 		view := &models.AppView{ID: 1, AppID: 1}
 
+		mockAppRepo.On("GetByID", ctx, uint64(1)).Return(&models.App{ID: 1}, nil)
 		mockViewRepo.On("GetByID", ctx, uint64(1)).Return(view, nil)
 		mockViewRepo.On("Delete", ctx, uint64(1)).Return(nil)
 
-		service := services.NewViewService(mockViewRepo, mockAppRepo)
+		service := services.NewViewService(mockViewRepo, mockAppRepo, newTestPermissionService(mockAppRepo))
 
-		err := service.DeleteView(ctx, 1)
+		err := service.DeleteView(ctx, 1, 0)
 		require.NoError(t, err)
 
 		mockViewRepo.AssertExpectations(t)
@@ -201,11 +245,47 @@ func TestViewService_DeleteView(t *testing.T) {
 
 		mockViewRepo.On("GetByID", ctx, uint64(999)).Return(nil, nil)
 
-		service := services.NewViewService(mockViewRepo, mockAppRepo)
+		service := services.NewViewService(mockViewRepo, mockAppRepo, newTestPermissionService(mockAppRepo))
 
-		err := service.DeleteView(ctx, 999)
+		err := service.DeleteView(ctx, 999, 0)
 		assert.ErrorIs(t, err, services.ErrViewNotFound)
 
 		mockViewRepo.AssertExpectations(t)
 	})
+
+	t.Run("delete with the current version", func(t *testing.T) {
+		mockViewRepo := new(mocks.MockViewRepository)
+		mockAppRepo := new(mocks.MockAppRepository)
+
+		updatedAt := time.Date(2026, 10, 16, 9, 0, 0, 123456000, time.UTC)
+		mockAppRepo.On("GetByID", ctx, uint64(1)).Return(&models.App{ID: 1}, nil)
+		mockViewRepo.On("GetByID", ctx, uint64(1)).Return(&models.AppView{ID: 1, AppID: 1, UpdatedAt: updatedAt}, nil)
+		mockViewRepo.On("DeleteIfVersion", ctx, uint64(1), models.VersionOf(updatedAt)).Return(true, nil)
+
+		service := services.NewViewService(mockViewRepo, mockAppRepo, newTestPermissionService(mockAppRepo))
+
+		require.NoError(t, service.DeleteView(ctx, 1, models.VersionOf(updatedAt)))
+		mockViewRepo.AssertExpectations(t)
+		mockViewRepo.AssertNotCalled(t, "Delete", mock.Anything, mock.Anything)
+	})
+
+	t.Run("view changed between read and delete", func(t *testing.T) {
+		mockViewRepo := new(mocks.MockViewRepository)
+		mockAppRepo := new(mocks.MockAppRepository)
+
+		updatedAt := time.Date(2026, 10, 16, 9, 0, 0, 123456000, time.UTC)
+		theirs := updatedAt.Add(time.Second)
+		mockAppRepo.On("GetByID", ctx, uint64(1)).Return(&models.App{ID: 1}, nil)
+		mockViewRepo.On("GetByID", ctx, uint64(1)).Return(&models.AppView{ID: 1, AppID: 1, UpdatedAt: updatedAt}, nil).Once()
+		mockViewRepo.On("DeleteIfVersion", ctx, uint64(1), models.VersionOf(updatedAt)).Return(false, nil)
+		mockViewRepo.On("GetByID", ctx, uint64(1)).Return(&models.AppView{ID: 1, AppID: 1, Name: "Theirs", UpdatedAt: theirs}, nil).Once()
+
+		service := services.NewViewService(mockViewRepo, mockAppRepo, newTestPermissionService(mockAppRepo))
+
+		err := service.DeleteView(ctx, 1, models.VersionOf(updatedAt))
+		var conflict *services.VersionConflictError
+		require.ErrorAs(t, err, &conflict)
+		assert.Equal(t, models.VersionOf(theirs), conflict.Version)
+		assert.Equal(t, "Theirs", conflict.Current.(*models.ViewResponse).Name)
+	})
 }
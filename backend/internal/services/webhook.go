@@ -0,0 +1,334 @@
+package services
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"nocode-app/backend/internal/models"
+	"nocode-app/backend/internal/repositories"
+)
+
+// Webhook関連エラー
+var (
+	ErrWebhookNotFound   = errors.New("Webhookが見つかりません")
+	ErrInvalidWebhookURL = errors.New("WebhookのURLはhttpまたはhttpsで指定してください")
+	ErrWebhookInactive   = errors.New("Webhookが無効になっています")
+)
+
+// webhookSecretBytes 署名用シークレットのバイト数
+const webhookSecretBytes = 32
+
+// WebhookService Webhookの登録管理とイベントの配信キューへの登録を処理する構造体
+type WebhookService struct {
+	webhookRepo  repositories.WebhookRepositoryInterface
+	deliveryRepo repositories.WebhookDeliveryRepositoryInterface
+	appRepo      repositories.AppRepositoryInterface
+	guard        *WebhookAddressGuard
+}
+
+// NewWebhookService 新しいWebhookServiceを作成する
+func NewWebhookService(
+	webhookRepo repositories.WebhookRepositoryInterface,
+	deliveryRepo repositories.WebhookDeliveryRepositoryInterface,
+	appRepo repositories.AppRepositoryInterface,
+	guard *WebhookAddressGuard,
+) *WebhookService {
+	return &WebhookService{
+		webhookRepo:  webhookRepo,
+		deliveryRepo: deliveryRepo,
+		appRepo:      appRepo,
+		guard:        guard,
+	}
+}
+
+// getApp アプリの存在を確認する
+func (s *WebhookService) getApp(ctx context.Context, appID uint64) error {
+	app, err := s.appRepo.GetByID(ctx, appID)
+	if err != nil {
+		return err
+	}
+	if app == nil {
+		return ErrAppNotFound
+	}
+	return nil
+}
+
+// getWebhook アプリに登録されたWebhookを取得する
+func (s *WebhookService) getWebhook(ctx context.Context, appID, webhookID uint64) (*models.Webhook, error) {
+	if err := s.getApp(ctx, appID); err != nil {
+		return nil, err
+	}
+	webhook, err := s.webhookRepo.GetByID(ctx, webhookID)
+	if err != nil {
+		return nil, err
+	}
+	if webhook == nil || webhook.AppID != appID {
+		return nil, ErrWebhookNotFound
+	}
+	return webhook, nil
+}
+
+// GetWebhooks アプリに登録されたWebhookの一覧を取得する
+func (s *WebhookService) GetWebhooks(ctx context.Context, appID uint64) (*models.WebhookListResponse, error) {
+	if err := s.getApp(ctx, appID); err != nil {
+		return nil, err
+	}
+	webhooks, err := s.webhookRepo.GetByAppID(ctx, appID)
+	if err != nil {
+		return nil, err
+	}
+	return &models.WebhookListResponse{Webhooks: webhooks}, nil
+}
+
+// CreateWebhook アプリにWebhookを登録する
+// 署名用シークレットを生成し、このレスポンスでのみ返す
+func (s *WebhookService) CreateWebhook(ctx context.Context, appID, userID uint64, req *models.CreateWebhookRequest) (*models.WebhookResponse, error) {
+	if err := s.getApp(ctx, appID); err != nil {
+		return nil, err
+	}
+	if err := s.guard.ValidateURL(ctx, req.URL); err != nil {
+		return nil, err
+	}
+
+	secret, err := generateWebhookSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	webhook := &models.Webhook{
+		AppID:     appID,
+		URL:       req.URL,
+		Secret:    secret,
+		Events:    uniqueEvents(req.Events),
+		IsActive:  true,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if userID != 0 {
+		webhook.CreatedBy = &userID
+	}
+	if err := s.webhookRepo.Create(ctx, webhook); err != nil {
+		return nil, err
+	}
+	return &models.WebhookResponse{Webhook: *webhook, Secret: secret}, nil
+}
+
+// UpdateWebhook Webhookを更新する
+// シークレットを再発行した場合のみ新しいシークレットを返す
+func (s *WebhookService) UpdateWebhook(ctx context.Context, appID, webhookID uint64, req *models.UpdateWebhookRequest) (*models.WebhookResponse, error) {
+	webhook, err := s.getWebhook(ctx, appID, webhookID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.URL != "" {
+		if err := s.guard.ValidateURL(ctx, req.URL); err != nil {
+			return nil, err
+		}
+		webhook.URL = req.URL
+	}
+	if len(req.Events) > 0 {
+		webhook.Events = uniqueEvents(req.Events)
+	}
+	if req.IsActive != nil {
+		webhook.IsActive = *req.IsActive
+	}
+
+	resp := &models.WebhookResponse{}
+	if req.RotateSecret {
+		secret, err := generateWebhookSecret()
+		if err != nil {
+			return nil, err
+		}
+		webhook.Secret = secret
+		resp.Secret = secret
+	}
+	webhook.UpdatedAt = time.Now()
+
+	if err := s.webhookRepo.Update(ctx, webhook); err != nil {
+		return nil, err
+	}
+	resp.Webhook = *webhook
+	return resp, nil
+}
+
+// DeleteWebhook Webhookと配信ログを削除する
+func (s *WebhookService) DeleteWebhook(ctx context.Context, appID, webhookID uint64) error {
+	if _, err := s.getWebhook(ctx, appID, webhookID); err != nil {
+		return err
+	}
+	return s.webhookRepo.Delete(ctx, webhookID)
+}
+
+// GetDeliveries Webhookの配信ログを新しい順に取得する
+func (s *WebhookService) GetDeliveries(ctx context.Context, appID, webhookID uint64, page, limit int) (*models.WebhookDeliveryListResponse, error) {
+	if _, err := s.getWebhook(ctx, appID, webhookID); err != nil {
+		return nil, err
+	}
+
+	deliveries, total, err := s.deliveryRepo.GetByWebhookID(ctx, webhookID, page, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]models.WebhookDeliveryResponse, len(deliveries))
+	for i := range deliveries {
+		responses[i] = *deliveries[i].ToResponse()
+	}
+	return &models.WebhookDeliveryListResponse{
+		Deliveries: responses,
+		Pagination: models.NewPagination(page, limit, total),
+	}, nil
+}
+
+// Publish イベントを購読している有効なWebhookごとに署名したペイロードを配信キューに登録する
+// 送信はWebhookWorkerが非同期に行うため、送信先の応答を待たない
+func (s *WebhookService) Publish(ctx context.Context, events ...models.WebhookEvent) error {
+	if len(events) == 0 {
+		return nil
+	}
+
+	webhooksByApp := make(map[uint64][]models.Webhook)
+	var deliveries []models.WebhookDelivery
+	for i := range events {
+		event := &events[i]
+		webhooks, ok := webhooksByApp[event.AppID]
+		if !ok {
+			var err error
+			if webhooks, err = s.webhookRepo.GetByAppID(ctx, event.AppID); err != nil {
+				return err
+			}
+			webhooksByApp[event.AppID] = webhooks
+		}
+
+		var payload []byte
+		for j := range webhooks {
+			webhook := &webhooks[j]
+			if !webhook.Subscribes(event.Event) {
+				continue
+			}
+			if payload == nil {
+				var err error
+				if payload, err = json.Marshal(event); err != nil {
+					return err
+				}
+			}
+			webhookID := webhook.ID
+			deliveries = append(deliveries, models.WebhookDelivery{
+				WebhookID:     &webhookID,
+				AppID:         event.AppID,
+				Event:         event.Event,
+				URL:           webhook.URL,
+				Payload:       string(payload),
+				Signature:     signWebhookPayload(webhook.Secret, payload),
+				Status:        models.WebhookDeliveryPending,
+				NextAttemptAt: event.OccurredAt,
+				CreatedAt:     event.OccurredAt,
+			})
+		}
+	}
+
+	return s.deliveryRepo.CreateBatch(ctx, deliveries)
+}
+
+// PublishTo 購読しているイベントに関係なく、指定したWebhookにイベントを1件配信キューに登録する
+// Webhookがイベントのアプリに登録されていない場合や無効になっている場合はエラーを返す
+func (s *WebhookService) PublishTo(ctx context.Context, webhookID uint64, event models.WebhookEvent) error {
+	webhook, err := s.webhookRepo.GetByID(ctx, webhookID)
+	if err != nil {
+		return err
+	}
+	if webhook == nil || webhook.AppID != event.AppID {
+		return ErrWebhookNotFound
+	}
+	if !webhook.IsActive {
+		return ErrWebhookInactive
+	}
+
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	return s.deliveryRepo.CreateBatch(ctx, []models.WebhookDelivery{{
+		WebhookID:     &webhookID,
+		AppID:         event.AppID,
+		Event:         event.Event,
+		URL:           webhook.URL,
+		Payload:       string(payload),
+		Signature:     signWebhookPayload(webhook.Secret, payload),
+		Status:        models.WebhookDeliveryPending,
+		NextAttemptAt: event.OccurredAt,
+		CreatedAt:     event.OccurredAt,
+	}})
+}
+
+// revisionEvents 変更履歴をレコードのイベントに変換する
+// 作成と削除は変更後（削除の場合は削除前）の内容のみを、更新と復元は変更内容も通知する
+func revisionEvents(revisions ...models.RecordRevision) []models.WebhookEvent {
+	events := make([]models.WebhookEvent, 0, len(revisions))
+	for i := range revisions {
+		revision := &revisions[i]
+		data := models.WebhookRecordData{
+			RecordID:  revision.RecordID,
+			Data:      revision.Snapshot,
+			CreatedBy: revision.RecordCreatedBy,
+			ChangedBy: revision.ChangedBy,
+		}
+
+		var event models.WebhookEventType
+		switch revision.Action {
+		case models.RevisionActionCreate, models.RevisionActionRestore:
+			// ごみ箱からの復元は、連携先から見るとレコードの作成と同じ
+			event = models.WebhookEventRecordCreated
+		case models.RevisionActionDelete:
+			event = models.WebhookEventRecordDeleted
+		default:
+			event = models.WebhookEventRecordUpdated
+			data.Changes = revision.Changes
+		}
+
+		events = append(events, models.WebhookEvent{
+			Event:      event,
+			AppID:      revision.AppID,
+			OccurredAt: revision.CreatedAt,
+			Data:       data,
+		})
+	}
+	return events
+}
+
+// signWebhookPayload ペイロードのHMAC-SHA256署名を "sha256=<16進数>" の形式で返す
+func signWebhookPayload(secret string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(payload)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// generateWebhookSecret 署名用のランダムなシークレットを生成する
+func generateWebhookSecret() (string, error) {
+	b := make([]byte, webhookSecretBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// uniqueEvents イベントの種類の重複を取り除く
+func uniqueEvents(events []models.WebhookEventType) []models.WebhookEventType {
+	seen := make(map[models.WebhookEventType]bool, len(events))
+	unique := make([]models.WebhookEventType, 0, len(events))
+	for _, e := range events {
+		if !seen[e] {
+			seen[e] = true
+			unique = append(unique, e)
+		}
+	}
+	return unique
+}
@@ -0,0 +1,120 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"syscall"
+	"time"
+)
+
+// ErrWebhookPrivateAddress 送信先が内部ネットワークのアドレスの場合のエラー
+var ErrWebhookPrivateAddress = errors.New("Webhookの送信先に内部ネットワークのアドレスは指定できません")
+
+// nonPublicPrefixes net/netip の判定に含まれない、インターネットから到達できないアドレスの範囲
+var nonPublicPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),
+	netip.MustParsePrefix("100.64.0.0/10"),
+	netip.MustParsePrefix("192.0.0.0/24"),
+	netip.MustParsePrefix("198.18.0.0/15"),
+	netip.MustParsePrefix("240.0.0.0/4"),
+}
+
+// isPublicAddr インターネット上の公開されたアドレスかどうかを判定する
+// ループバック・プライベート・リンクローカル（169.254.169.254 のメタデータサービスを含む）などは公開されていないとみなす
+func isPublicAddr(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	if !addr.IsValid() || addr.IsLoopback() || addr.IsPrivate() || addr.IsUnspecified() ||
+		addr.IsLinkLocalUnicast() || addr.IsLinkLocalMulticast() || addr.IsInterfaceLocalMulticast() || addr.IsMulticast() {
+		return false
+	}
+	for _, prefix := range nonPublicPrefixes {
+		if prefix.Contains(addr) {
+			return false
+		}
+	}
+	return true
+}
+
+// WebhookAddressGuard Webhookの送信先を公開されたアドレスに制限する
+// 登録時にホスト名を解決して確認し、送信時にも接続するアドレスを確認する（DNSの応答を後から変える攻撃を防ぐ）
+type WebhookAddressGuard struct {
+	// allowPrivateNetworks 内部ネットワークへの送信を許可する（社内のシステムへ送信する場合など）
+	allowPrivateNetworks bool
+	resolver             *net.Resolver
+}
+
+// NewWebhookAddressGuard 新しいWebhookAddressGuardを作成する
+func NewWebhookAddressGuard(allowPrivateNetworks bool) *WebhookAddressGuard {
+	return &WebhookAddressGuard{
+		allowPrivateNetworks: allowPrivateNetworks,
+		resolver:             net.DefaultResolver,
+	}
+}
+
+// ValidateURL 送信先がhttpまたはhttpsの絶対URLで、公開されたアドレスに解決されることを確認する
+func (g *WebhookAddressGuard) ValidateURL(ctx context.Context, raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" || u.Hostname() == "" {
+		return ErrInvalidWebhookURL
+	}
+	if g.allowPrivateNetworks {
+		return nil
+	}
+
+	host := u.Hostname()
+	if addr, err := netip.ParseAddr(host); err == nil {
+		if !isPublicAddr(addr) {
+			return ErrWebhookPrivateAddress
+		}
+		return nil
+	}
+	addrs, err := g.resolver.LookupNetIP(ctx, "ip", host)
+	if err != nil || len(addrs) == 0 {
+		return fmt.Errorf("%w: ホスト名 %s を解決できません", ErrInvalidWebhookURL, host)
+	}
+	// 1つでも内部のアドレスに解決される場合は、どれに接続するか分からないため拒否する
+	for _, addr := range addrs {
+		if !isPublicAddr(addr) {
+			return ErrWebhookPrivateAddress
+		}
+	}
+	return nil
+}
+
+// control 接続する直前に接続先のアドレスを確認する net.Dialer の Control
+func (g *WebhookAddressGuard) control(_, address string, _ syscall.RawConn) error {
+	if g.allowPrivateNetworks {
+		return nil
+	}
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	addr, err := netip.ParseAddr(host)
+	if err != nil {
+		return err
+	}
+	if !isPublicAddr(addr) {
+		return ErrWebhookPrivateAddress
+	}
+	return nil
+}
+
+// httpClient 接続先のアドレスを確認するHTTPクライアントを作成する
+// リダイレクト先への接続も確認する。プロキシを経由すると接続先を確認できないため、環境変数のプロキシ設定は使わない
+func (g *WebhookAddressGuard) httpClient(timeout time.Duration) *http.Client {
+	dialer := &net.Dialer{
+		Timeout:   timeout,
+		KeepAlive: 30 * time.Second,
+		Control:   g.control,
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+	return &http.Client{Timeout: timeout, Transport: transport}
+}
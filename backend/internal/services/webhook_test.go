@@ -0,0 +1,395 @@
+package services_test
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"nocode-app/backend/internal/models"
+	"nocode-app/backend/internal/services"
+	"nocode-app/backend/internal/testhelpers/mocks"
+)
+
+func TestWebhookService_CreateWebhook(t *testing.T) {
+	ctx := systemContext()
+
+	t.Run("generates secret", func(t *testing.T) {
+		mockWebhookRepo := new(mocks.MockWebhookRepository)
+		mockAppRepo := new(mocks.MockAppRepository)
+
+		mockAppRepo.On("GetByID", ctx, uint64(1)).Return(&models.App{ID: 1}, nil)
+		mockWebhookRepo.On("Create", ctx, mock.AnythingOfType("*models.Webhook")).Return(nil).Run(func(args mock.Arguments) {
+			args.Get(1).(*models.Webhook).ID = 4
+		})
+
+		service := services.NewWebhookService(mockWebhookRepo, new(mocks.MockWebhookDeliveryRepository), mockAppRepo, services.NewWebhookAddressGuard(true))
+
+		resp, err := service.CreateWebhook(ctx, 1, 5, &models.CreateWebhookRequest{
+			URL:    "https://example.com/hook",
+			Events: []models.WebhookEventType{models.WebhookEventRecordCreated, models.WebhookEventRecordCreated, models.WebhookEventAppDeleted},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, uint64(4), resp.ID)
+		assert.Len(t, resp.Secret, 64)
+		assert.Equal(t, resp.Secret, resp.Webhook.Secret)
+		assert.Equal(t, []models.WebhookEventType{models.WebhookEventRecordCreated, models.WebhookEventAppDeleted}, resp.Events)
+		assert.True(t, resp.IsActive)
+		require.NotNil(t, resp.CreatedBy)
+		assert.Equal(t, uint64(5), *resp.CreatedBy)
+	})
+
+	t.Run("invalid url", func(t *testing.T) {
+		for _, url := range []string{"ftp://example.com/hook", "example.com/hook", "https://"} {
+			mockWebhookRepo := new(mocks.MockWebhookRepository)
+			mockAppRepo := new(mocks.MockAppRepository)
+			mockAppRepo.On("GetByID", ctx, uint64(1)).Return(&models.App{ID: 1}, nil)
+
+			service := services.NewWebhookService(mockWebhookRepo, new(mocks.MockWebhookDeliveryRepository), mockAppRepo, services.NewWebhookAddressGuard(true))
+
+			_, err := service.CreateWebhook(ctx, 1, 5, &models.CreateWebhookRequest{URL: url, Events: []models.WebhookEventType{models.WebhookEventRecordCreated}})
+			assert.ErrorIs(t, err, services.ErrInvalidWebhookURL, url)
+			mockWebhookRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+		}
+	})
+
+	t.Run("app not found", func(t *testing.T) {
+		mockAppRepo := new(mocks.MockAppRepository)
+		mockAppRepo.On("GetByID", ctx, uint64(1)).Return(nil, nil)
+
+		service := services.NewWebhookService(new(mocks.MockWebhookRepository), new(mocks.MockWebhookDeliveryRepository), mockAppRepo, services.NewWebhookAddressGuard(true))
+
+		_, err := service.CreateWebhook(ctx, 1, 5, &models.CreateWebhookRequest{URL: "https://example.com/hook", Events: []models.WebhookEventType{models.WebhookEventRecordCreated}})
+		assert.ErrorIs(t, err, services.ErrAppNotFound)
+	})
+
+	t.Run("private address", func(t *testing.T) {
+		urls := []string{
+			"http://127.0.0.1:8080/hook",
+			"http://169.254.169.254/latest/meta-data",
+			"https://10.0.0.5/hook",
+			"https://192.168.1.10/hook",
+			"http://[::1]/hook",
+			"http://[::ffff:127.0.0.1]/hook",
+			"http://0.0.0.0/hook",
+		}
+		for _, url := range urls {
+			mockWebhookRepo := new(mocks.MockWebhookRepository)
+			mockAppRepo := new(mocks.MockAppRepository)
+			mockAppRepo.On("GetByID", ctx, uint64(1)).Return(&models.App{ID: 1}, nil)
+
+			service := services.NewWebhookService(mockWebhookRepo, new(mocks.MockWebhookDeliveryRepository), mockAppRepo, services.NewWebhookAddressGuard(false))
+
+			_, err := service.CreateWebhook(ctx, 1, 5, &models.CreateWebhookRequest{URL: url, Events: []models.WebhookEventType{models.WebhookEventRecordCreated}})
+			assert.ErrorIs(t, err, services.ErrWebhookPrivateAddress, url)
+			mockWebhookRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+		}
+	})
+
+	t.Run("public address", func(t *testing.T) {
+		mockWebhookRepo := new(mocks.MockWebhookRepository)
+		mockAppRepo := new(mocks.MockAppRepository)
+		mockAppRepo.On("GetByID", ctx, uint64(1)).Return(&models.App{ID: 1}, nil)
+		mockWebhookRepo.On("Create", ctx, mock.AnythingOfType("*models.Webhook")).Return(nil)
+
+		service := services.NewWebhookService(mockWebhookRepo, new(mocks.MockWebhookDeliveryRepository), mockAppRepo, services.NewWebhookAddressGuard(false))
+
+		_, err := service.CreateWebhook(ctx, 1, 5, &models.CreateWebhookRequest{URL: "https://203.0.114.10/hook", Events: []models.WebhookEventType{models.WebhookEventRecordCreated}})
+		require.NoError(t, err)
+	})
+}
+
+func TestWebhookService_UpdateWebhook(t *testing.T) {
+	ctx := systemContext()
+
+	newService := func(webhook *models.Webhook) (*services.WebhookService, *mocks.MockWebhookRepository) {
+		mockWebhookRepo := new(mocks.MockWebhookRepository)
+		mockAppRepo := new(mocks.MockAppRepository)
+		mockAppRepo.On("GetByID", ctx, uint64(1)).Return(&models.App{ID: 1}, nil)
+		mockWebhookRepo.On("GetByID", ctx, uint64(4)).Return(webhook, nil)
+		return services.NewWebhookService(mockWebhookRepo, new(mocks.MockWebhookDeliveryRepository), mockAppRepo, services.NewWebhookAddressGuard(true)), mockWebhookRepo
+	}
+
+	t.Run("rotates secret", func(t *testing.T) {
+		service, mockWebhookRepo := newService(&models.Webhook{ID: 4, AppID: 1, URL: "https://example.com/hook", Secret: "old", IsActive: true})
+		mockWebhookRepo.On("Update", ctx, mock.AnythingOfType("*models.Webhook")).Return(nil)
+
+		inactive := false
+		resp, err := service.UpdateWebhook(ctx, 1, 4, &models.UpdateWebhookRequest{IsActive: &inactive, RotateSecret: true})
+		require.NoError(t, err)
+		assert.False(t, resp.IsActive)
+		assert.NotEqual(t, "old", resp.Secret)
+		assert.Len(t, resp.Secret, 64)
+		assert.Equal(t, "https://example.com/hook", resp.URL)
+	})
+
+	t.Run("keeps secret hidden without rotation", func(t *testing.T) {
+		service, mockWebhookRepo := newService(&models.Webhook{ID: 4, AppID: 1, URL: "https://example.com/hook", Secret: "old", IsActive: true})
+		mockWebhookRepo.On("Update", ctx, mock.AnythingOfType("*models.Webhook")).Return(nil)
+
+		resp, err := service.UpdateWebhook(ctx, 1, 4, &models.UpdateWebhookRequest{URL: "https://example.com/v2"})
+		require.NoError(t, err)
+		assert.Empty(t, resp.Secret)
+		assert.Equal(t, "https://example.com/v2", resp.URL)
+	})
+
+	t.Run("webhook of another app", func(t *testing.T) {
+		service, mockWebhookRepo := newService(&models.Webhook{ID: 4, AppID: 2})
+
+		_, err := service.UpdateWebhook(ctx, 1, 4, &models.UpdateWebhookRequest{RotateSecret: true})
+		assert.ErrorIs(t, err, services.ErrWebhookNotFound)
+		mockWebhookRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+	})
+}
+
+func TestWebhookService_Publish(t *testing.T) {
+	ctx := systemContext()
+	occurredAt := time.Date(2024, 5, 1, 9, 0, 0, 0, time.UTC)
+
+	webhooks := []models.Webhook{
+		{ID: 1, AppID: 1, URL: "https://a.example.com", Secret: "secret-a", IsActive: true,
+			Events: []models.WebhookEventType{models.WebhookEventRecordCreated, models.WebhookEventRecordDeleted}},
+		{ID: 2, AppID: 1, URL: "https://b.example.com", Secret: "secret-b", IsActive: true,
+			Events: []models.WebhookEventType{models.WebhookEventRecordDeleted}},
+		{ID: 3, AppID: 1, URL: "https://c.example.com", Secret: "secret-c", IsActive: false,
+			Events: []models.WebhookEventType{models.WebhookEventRecordCreated}},
+	}
+
+	mockWebhookRepo := new(mocks.MockWebhookRepository)
+	mockDeliveryRepo := new(mocks.MockWebhookDeliveryRepository)
+	mockWebhookRepo.On("GetByAppID", ctx, uint64(1)).Return(webhooks, nil).Once()
+
+	var deliveries []models.WebhookDelivery
+	mockDeliveryRepo.On("CreateBatch", ctx, mock.Anything).Return(nil).Run(func(args mock.Arguments) {
+		deliveries = args.Get(1).([]models.WebhookDelivery)
+	})
+
+	service := services.NewWebhookService(mockWebhookRepo, mockDeliveryRepo, new(mocks.MockAppRepository), services.NewWebhookAddressGuard(true))
+
+	err := service.Publish(ctx,
+		models.WebhookEvent{Event: models.WebhookEventRecordCreated, AppID: 1, OccurredAt: occurredAt, Data: models.WebhookRecordData{RecordID: 7}},
+		models.WebhookEvent{Event: models.WebhookEventRecordUpdated, AppID: 1, OccurredAt: occurredAt, Data: models.WebhookRecordData{RecordID: 7}},
+		models.WebhookEvent{Event: models.WebhookEventRecordDeleted, AppID: 1, OccurredAt: occurredAt, Data: models.WebhookRecordData{RecordID: 8}},
+	)
+	require.NoError(t, err)
+	// Webhookはアプリごとに1回だけ取得する
+	mockWebhookRepo.AssertExpectations(t)
+
+	// 無効なWebhookと購読していないイベントは配信しない
+	require.Len(t, deliveries, 3)
+	assert.Equal(t, "https://a.example.com", deliveries[0].URL)
+	assert.Equal(t, models.WebhookEventRecordCreated, deliveries[0].Event)
+	assert.Equal(t, "https://a.example.com", deliveries[1].URL)
+	assert.Equal(t, models.WebhookEventRecordDeleted, deliveries[1].Event)
+	assert.Equal(t, "https://b.example.com", deliveries[2].URL)
+	assert.Equal(t, deliveries[1].Payload, deliveries[2].Payload)
+
+	for i, secret := range []string{"secret-a", "secret-a", "secret-b"} {
+		mac := hmac.New(sha256.New, []byte(secret))
+		mac.Write([]byte(deliveries[i].Payload))
+		assert.Equal(t, "sha256="+hex.EncodeToString(mac.Sum(nil)), deliveries[i].Signature)
+		assert.Equal(t, models.WebhookDeliveryPending, deliveries[i].Status)
+		assert.Equal(t, occurredAt, deliveries[i].NextAttemptAt)
+	}
+
+	var payload map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(deliveries[0].Payload), &payload))
+	assert.Equal(t, "record.created", payload["event"])
+	assert.Equal(t, float64(1), payload["app_id"])
+	assert.Equal(t, "2024-05-01T09:00:00Z", payload["occurred_at"])
+	assert.Equal(t, float64(7), payload["data"].(map[string]interface{})["record_id"])
+}
+
+func TestRecordService_BulkDeleteRecords_PublishesWebhookEvents(t *testing.T) {
+	ctx := systemContext()
+
+	mockAppRepo := new(mocks.MockAppRepository)
+	mockFieldRepo := new(mocks.MockFieldRepository)
+	mockDynamicQuery := new(mocks.MockDynamicQueryExecutor)
+	mockRevisionRepo := new(mocks.MockRecordRevisionRepository)
+	mockPublisher := new(mocks.MockWebhookPublisher)
+
+	app := &models.App{ID: 1, TableName: "app_data_1"}
+	fields := []models.AppField{}
+	mockAppRepo.On("GetByID", ctx, uint64(1)).Return(app, nil)
+	mockFieldRepo.On("GetByAppID", ctx, uint64(1)).Return(fields, nil)
+	for _, id := range []uint64{1, 2} {
+		mockDynamicQuery.On("GetRecordByID", ctx, "app_data_1", fields, id).Return(&models.RecordResponse{ID: id, Data: models.RecordData{"name": "before"}}, nil)
+	}
+	mockDynamicQuery.On("TrashRecords", ctx, "app_data_1", []uint64{1, 2}, uint64(0)).Return(nil)
+	mockRevisionRepo.On("CreateBatch", ctx, mock.Anything).Return(nil)
+
+	var events []models.WebhookEvent
+	mockPublisher.On("Publish", ctx, mock.Anything).Return(nil).Run(func(args mock.Arguments) {
+		events = args.Get(1).([]models.WebhookEvent)
+	})
+
+	service := services.NewRecordService(mockAppRepo, mockFieldRepo, mockDynamicQuery, new(mocks.MockDataSourceRepository), new(mocks.MockExternalQueryExecutor), newTestPermissionService(mockAppRepo), mockRevisionRepo, newTestTransactor(), mockPublisher, newTestAutomationRunner(), newTestAttachmentManager())
+
+	require.NoError(t, service.BulkDeleteRecords(ctx, 1, &models.BulkDeleteRecordRequest{IDs: []uint64{1, 2}}))
+
+	// 一括操作でもレコードごとにイベントを発行する
+	require.Len(t, events, 2)
+	for i, id := range []uint64{1, 2} {
+		assert.Equal(t, models.WebhookEventRecordDeleted, events[i].Event)
+		assert.Equal(t, uint64(1), events[i].AppID)
+		data := events[i].Data.(models.WebhookRecordData)
+		assert.Equal(t, id, data.RecordID)
+		assert.Equal(t, "before", data.Data["name"])
+		assert.Empty(t, data.Changes)
+	}
+}
+
+func TestRecordService_UpdateRecord_PublishesWebhookEvents(t *testing.T) {
+	ctx := systemContext()
+
+	fields := []models.AppField{{ID: 1, AppID: 1, FieldCode: "name", FieldName: "名前", FieldType: "text"}}
+
+	tests := []struct {
+		name       string
+		after      string
+		wantEvents int
+	}{
+		{name: "publishes changes", after: "after", wantEvents: 1},
+		{name: "skips unchanged record", after: "before", wantEvents: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockAppRepo := new(mocks.MockAppRepository)
+			mockFieldRepo := new(mocks.MockFieldRepository)
+			mockDynamicQuery := new(mocks.MockDynamicQueryExecutor)
+			mockRevisionRepo := new(mocks.MockRecordRevisionRepository)
+			mockPublisher := new(mocks.MockWebhookPublisher)
+
+			mockAppRepo.On("GetByID", ctx, uint64(1)).Return(&models.App{ID: 1, TableName: "app_data_1"}, nil)
+			mockFieldRepo.On("GetByAppID", ctx, uint64(1)).Return(fields, nil)
+			mockDynamicQuery.On("GetRecordByID", ctx, "app_data_1", fields, uint64(7)).
+				Return(&models.RecordResponse{ID: 7, Data: models.RecordData{"name": "before"}}, nil).Once()
+			mockDynamicQuery.On("UpdateRecord", ctx, "app_data_1", uint64(7), mock.Anything).Return(nil)
+			mockDynamicQuery.On("GetRecordByID", ctx, "app_data_1", fields, uint64(7)).
+				Return(&models.RecordResponse{ID: 7, Data: models.RecordData{"name": tt.after}}, nil).Once()
+			mockRevisionRepo.On("Create", ctx, mock.Anything).Return(nil).Maybe()
+			mockPublisher.On("Publish", ctx, mock.MatchedBy(func(events []models.WebhookEvent) bool {
+				data := events[0].Data.(models.WebhookRecordData)
+				return len(events) == 1 && events[0].Event == models.WebhookEventRecordUpdated &&
+					data.Changes["name"].Before == "before" && data.Changes["name"].After == "after"
+			})).Return(nil).Maybe()
+
+			service := services.NewRecordService(mockAppRepo, mockFieldRepo, mockDynamicQuery, new(mocks.MockDataSourceRepository), new(mocks.MockExternalQueryExecutor), newTestPermissionService(mockAppRepo), mockRevisionRepo, newTestTransactor(), mockPublisher, newTestAutomationRunner(), newTestAttachmentManager())
+
+			_, err := service.UpdateRecord(ctx, 1, 7, 0, &models.UpdateRecordRequest{Data: models.RecordData{"name": tt.after}})
+			require.NoError(t, err)
+			mockPublisher.AssertNumberOfCalls(t, "Publish", tt.wantEvents)
+		})
+	}
+}
+
+func TestFieldService_CreateField_PublishesWebhookEvent(t *testing.T) {
+	ctx := systemContext()
+
+	t.Run("publishes in the same transaction", func(t *testing.T) {
+		mockFieldRepo := new(mocks.MockFieldRepository)
+		mockAppRepo := new(mocks.MockAppRepository)
+		mockDynamicQuery := new(mocks.MockDynamicQueryExecutor)
+		mockPublisher := new(mocks.MockWebhookPublisher)
+		mockTransactor := new(mocks.MockTransactor)
+
+		var added bool
+		mockAppRepo.On("GetByID", ctx, uint64(1)).Return(&models.App{ID: 1, TableName: "app_data_1"}, nil)
+		mockFieldRepo.On("FieldCodeExists", ctx, uint64(1), "memo").Return(false, nil)
+		mockFieldRepo.On("GetMaxDisplayOrder", ctx, uint64(1)).Return(1, nil)
+		mockTransactor.On("RunInTx", ctx).Return(nil).Once()
+		mockFieldRepo.On("Create", ctx, mock.AnythingOfType("*models.AppField")).Return(nil)
+		mockDynamicQuery.On("AddColumn", ctx, "app_data_1", mock.AnythingOfType("*models.AppField")).Return(nil).Run(func(mock.Arguments) { added = true })
+		mockPublisher.On("Publish", ctx, mock.MatchedBy(func(events []models.WebhookEvent) bool {
+			field, ok := events[0].Data.(*models.FieldResponse)
+			return len(events) == 1 && events[0].Event == models.WebhookEventFieldCreated && events[0].AppID == 1 &&
+				ok && field.FieldCode == "memo"
+		})).Return(nil).Run(func(mock.Arguments) {
+			assert.True(t, added)
+		})
+
+		service := services.NewFieldService(mockFieldRepo, mockAppRepo, mockDynamicQuery, newTestPermissionService(mockAppRepo), mockTransactor, mockPublisher, newTestAttachmentManager())
+
+		_, err := service.CreateField(ctx, 1, &models.CreateFieldRequest{FieldCode: "memo", FieldName: "メモ", FieldType: "textarea"})
+		require.NoError(t, err)
+		mockTransactor.AssertExpectations(t)
+		mockPublisher.AssertExpectations(t)
+	})
+
+	t.Run("publish failure rolls back the field", func(t *testing.T) {
+		mockFieldRepo := new(mocks.MockFieldRepository)
+		mockAppRepo := new(mocks.MockAppRepository)
+		mockDynamicQuery := new(mocks.MockDynamicQueryExecutor)
+		mockPublisher := new(mocks.MockWebhookPublisher)
+		mockTransactor := new(mocks.MockTransactor)
+
+		mockAppRepo.On("GetByID", ctx, uint64(1)).Return(&models.App{ID: 1, TableName: "app_data_1"}, nil)
+		mockFieldRepo.On("FieldCodeExists", ctx, uint64(1), "memo").Return(false, nil)
+		mockFieldRepo.On("GetMaxDisplayOrder", ctx, uint64(1)).Return(1, nil)
+		mockTransactor.On("RunInTx", ctx).Return(nil).Once()
+		mockFieldRepo.On("Create", ctx, mock.AnythingOfType("*models.AppField")).Return(nil)
+		mockDynamicQuery.On("AddColumn", ctx, "app_data_1", mock.AnythingOfType("*models.AppField")).Return(nil)
+		mockPublisher.On("Publish", ctx, mock.Anything).Return(errors.New("db error"))
+
+		service := services.NewFieldService(mockFieldRepo, mockAppRepo, mockDynamicQuery, newTestPermissionService(mockAppRepo), mockTransactor, mockPublisher, newTestAttachmentManager())
+
+		// 通知の登録に失敗した場合はフィールドの作成ごとロールバックする
+		_, err := service.CreateField(ctx, 1, &models.CreateFieldRequest{FieldCode: "memo", FieldName: "メモ", FieldType: "textarea"})
+		assert.EqualError(t, err, "db error")
+		mockTransactor.AssertExpectations(t)
+		mockFieldRepo.AssertNotCalled(t, "Delete", mock.Anything, mock.Anything)
+		mockDynamicQuery.AssertNotCalled(t, "DropColumn", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestAppService_DeleteApp_PublishesWebhookEvent(t *testing.T) {
+	ctx := systemContext()
+	app := &models.App{ID: 1, Name: "顧客", TableName: "app_data_1"}
+
+	t.Run("publishes after trash in same transaction", func(t *testing.T) {
+		mockAppRepo := new(mocks.MockAppRepository)
+		mockFieldRepo := new(mocks.MockFieldRepository)
+		mockPublisher := new(mocks.MockWebhookPublisher)
+		mockTransactor := new(mocks.MockTransactor)
+		service := services.NewAppService(mockAppRepo, mockFieldRepo, new(mocks.MockDynamicQueryExecutor), new(mocks.MockDataSourceRepository), mockTransactor, newTestPermissionService(mockAppRepo), mockPublisher, newTestAttachmentManager())
+
+		var trashed bool
+		mockAppRepo.On("GetByID", ctx, uint64(1)).Return(app, nil)
+		mockFieldRepo.On("GetReferencingFields", ctx, uint64(1)).Return([]models.AppField{}, nil)
+		mockTransactor.On("RunInTx", ctx).Return(nil).Once()
+		mockAppRepo.On("Trash", ctx, uint64(1)).Return(nil).Run(func(mock.Arguments) { trashed = true })
+		// ごみ箱に移した後に、Webhookがカスケードで削除される前にイベントを登録する
+		mockPublisher.On("Publish", ctx, mock.MatchedBy(func(events []models.WebhookEvent) bool {
+			return len(events) == 1 && events[0].Event == models.WebhookEventAppDeleted &&
+				events[0].Data == models.WebhookAppData{ID: 1, Name: "顧客"}
+		})).Return(nil).Run(func(mock.Arguments) {
+			assert.True(t, trashed)
+		})
+
+		require.NoError(t, service.DeleteApp(ctx, 1))
+		mockTransactor.AssertExpectations(t)
+		mockPublisher.AssertExpectations(t)
+	})
+
+	t.Run("does not publish when trash fails", func(t *testing.T) {
+		mockAppRepo := new(mocks.MockAppRepository)
+		mockFieldRepo := new(mocks.MockFieldRepository)
+		mockPublisher := new(mocks.MockWebhookPublisher)
+		service := services.NewAppService(mockAppRepo, mockFieldRepo, new(mocks.MockDynamicQueryExecutor), new(mocks.MockDataSourceRepository), newTestTransactor(), newTestPermissionService(mockAppRepo), mockPublisher, newTestAttachmentManager())
+
+		mockAppRepo.On("GetByID", ctx, uint64(1)).Return(app, nil)
+		mockFieldRepo.On("GetReferencingFields", ctx, uint64(1)).Return([]models.AppField{}, nil)
+		mockAppRepo.On("Trash", ctx, uint64(1)).Return(errors.New("db error"))
+
+		require.Error(t, service.DeleteApp(ctx, 1))
+		mockPublisher.AssertNotCalled(t, "Publish", mock.Anything, mock.Anything)
+	})
+}
@@ -0,0 +1,127 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"nocode-app/backend/internal/models"
+	"nocode-app/backend/internal/repositories"
+)
+
+// Webhook送信時のHTTPヘッダー
+const (
+	// WebhookEventHeader イベントの種類
+	WebhookEventHeader = "X-Webhook-Event"
+	// WebhookDeliveryHeader 配信ID（再送時も同じ値）
+	WebhookDeliveryHeader = "X-Webhook-Delivery"
+	// WebhookSignatureHeader ペイロードのHMAC-SHA256署名（sha256=<16進数>）
+	WebhookSignatureHeader = "X-Webhook-Signature"
+)
+
+// Webhook配信の再送設定
+const (
+	// webhookMaxAttempts 失敗とするまでの最大送信回数
+	webhookMaxAttempts = 8
+	// webhookBaseBackoff 1回目の失敗後に再送するまでの待ち時間（以降は失敗のたびに2倍で、最長32分）
+	webhookBaseBackoff = 30 * time.Second
+	// webhookBatchSize 1回の処理で送信する配信の最大件数
+	webhookBatchSize = 20
+	// webhookLease 送信中の配信を他のワーカーが取得しないようにする時間（送信タイムアウトより長くする）
+	webhookLease = time.Minute
+	// webhookTimeout 1回の送信のタイムアウト
+	webhookTimeout = 10 * time.Second
+	// webhookMaxErrorLength 配信ログに保存するエラーメッセージの最大文字数
+	webhookMaxErrorLength = 500
+)
+
+// WebhookWorker 配信キューのWebhookを送信し、失敗した配信を指数バックオフで再送する構造体
+type WebhookWorker struct {
+	deliveryRepo repositories.WebhookDeliveryRepositoryInterface
+	client       *http.Client
+}
+
+// NewWebhookWorker 新しいWebhookWorkerを作成する
+// 送信先は guard で接続の直前に確認する（登録後にDNSの応答が内部のアドレスに変わった場合も送信しない）
+func NewWebhookWorker(deliveryRepo repositories.WebhookDeliveryRepositoryInterface, guard *WebhookAddressGuard) *WebhookWorker {
+	return &WebhookWorker{
+		deliveryRepo: deliveryRepo,
+		client:       guard.httpClient(webhookTimeout),
+	}
+}
+
+// ProcessDue 送信時刻を過ぎた配信を送信し、送信した件数を返す
+// 2xxの応答で成功とし、それ以外は再送を予約する（最大送信回数に達した場合は失敗とする）
+func (w *WebhookWorker) ProcessDue(ctx context.Context) (int, error) {
+	deliveries, err := w.deliveryRepo.ClaimDue(ctx, time.Now(), webhookBatchSize, webhookLease)
+	if err != nil {
+		return 0, err
+	}
+
+	for i := range deliveries {
+		delivery := &deliveries[i]
+		statusCode, sendErr := w.send(ctx, delivery)
+		recordDeliveryResult(delivery, statusCode, sendErr, time.Now())
+		if err := w.deliveryRepo.UpdateResult(ctx, delivery); err != nil {
+			return i, err
+		}
+	}
+	return len(deliveries), nil
+}
+
+// send 配信を1回送信し、応答のステータスコードを返す
+func (w *WebhookWorker) send(ctx context.Context, delivery *models.WebhookDelivery) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, delivery.URL, bytes.NewReader([]byte(delivery.Payload)))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(WebhookEventHeader, string(delivery.Event))
+	req.Header.Set(WebhookDeliveryHeader, strconv.FormatUint(delivery.ID, 10))
+	req.Header.Set(WebhookSignatureHeader, delivery.Signature)
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+	// コネクションを再利用できるよう応答本文を読み捨てる
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64*1024))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Errorf("送信先がステータス %d を返しました", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
+
+// recordDeliveryResult 送信結果から配信の状態と次回送信時刻を決定する
+func recordDeliveryResult(delivery *models.WebhookDelivery, statusCode int, sendErr error, now time.Time) {
+	delivery.LastStatusCode = nil
+	if statusCode != 0 {
+		delivery.LastStatusCode = &statusCode
+	}
+
+	if sendErr == nil {
+		delivery.Status = models.WebhookDeliverySucceeded
+		delivery.LastError = ""
+		delivery.DeliveredAt = &now
+		return
+	}
+
+	delivery.LastError = sendErr.Error()
+	if msg := []rune(delivery.LastError); len(msg) > webhookMaxErrorLength {
+		delivery.LastError = string(msg[:webhookMaxErrorLength])
+	}
+	if delivery.Attempts >= webhookMaxAttempts {
+		delivery.Status = models.WebhookDeliveryFailed
+		return
+	}
+	delivery.Status = models.WebhookDeliveryPending
+	delivery.NextAttemptAt = now.Add(webhookBaseBackoff << (delivery.Attempts - 1))
+}
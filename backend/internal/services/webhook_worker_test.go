@@ -0,0 +1,167 @@
+package services_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"nocode-app/backend/internal/models"
+	"nocode-app/backend/internal/services"
+	"nocode-app/backend/internal/testhelpers/mocks"
+)
+
+func TestWebhookWorker_ProcessDue(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("sends signed payload", func(t *testing.T) {
+		var received *http.Request
+		var body string
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			b, _ := io.ReadAll(r.Body)
+			received, body = r, string(b)
+			w.WriteHeader(http.StatusNoContent)
+		}))
+		defer server.Close()
+
+		mockDeliveryRepo := new(mocks.MockWebhookDeliveryRepository)
+		mockDeliveryRepo.On("ClaimDue", ctx, mock.Anything, mock.Anything, mock.Anything).Return([]models.WebhookDelivery{
+			{ID: 12, Event: models.WebhookEventRecordCreated, URL: server.URL, Payload: `{"event":"record.created"}`, Signature: "sha256=abc", Attempts: 1},
+		}, nil)
+
+		var result *models.WebhookDelivery
+		mockDeliveryRepo.On("UpdateResult", ctx, mock.AnythingOfType("*models.WebhookDelivery")).Return(nil).Run(func(args mock.Arguments) {
+			result = args.Get(1).(*models.WebhookDelivery)
+		})
+
+		n, err := services.NewWebhookWorker(mockDeliveryRepo, services.NewWebhookAddressGuard(true)).ProcessDue(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, 1, n)
+
+		require.NotNil(t, received)
+		assert.Equal(t, http.MethodPost, received.Method)
+		assert.Equal(t, "application/json", received.Header.Get("Content-Type"))
+		assert.Equal(t, "record.created", received.Header.Get(services.WebhookEventHeader))
+		assert.Equal(t, "12", received.Header.Get(services.WebhookDeliveryHeader))
+		assert.Equal(t, "sha256=abc", received.Header.Get(services.WebhookSignatureHeader))
+		assert.Equal(t, `{"event":"record.created"}`, body)
+
+		require.NotNil(t, result)
+		assert.Equal(t, models.WebhookDeliverySucceeded, result.Status)
+		require.NotNil(t, result.LastStatusCode)
+		assert.Equal(t, http.StatusNoContent, *result.LastStatusCode)
+		assert.NotNil(t, result.DeliveredAt)
+		assert.Empty(t, result.LastError)
+	})
+
+	tests := []struct {
+		name        string
+		attempts    int
+		wantStatus  models.WebhookDeliveryStatus
+		wantBackoff time.Duration
+	}{
+		{name: "retries after first failure", attempts: 1, wantStatus: models.WebhookDeliveryPending, wantBackoff: 30 * time.Second},
+		{name: "backs off exponentially", attempts: 4, wantStatus: models.WebhookDeliveryPending, wantBackoff: 4 * time.Minute},
+		{name: "waits longest before last attempt", attempts: 7, wantStatus: models.WebhookDeliveryPending, wantBackoff: 32 * time.Minute},
+		{name: "fails after max attempts", attempts: 8, wantStatus: models.WebhookDeliveryFailed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(http.StatusServiceUnavailable)
+			}))
+			defer server.Close()
+
+			mockDeliveryRepo := new(mocks.MockWebhookDeliveryRepository)
+			mockDeliveryRepo.On("ClaimDue", ctx, mock.Anything, mock.Anything, mock.Anything).Return([]models.WebhookDelivery{
+				{ID: 12, Event: models.WebhookEventRecordCreated, URL: server.URL, Payload: `{}`, Attempts: tt.attempts},
+			}, nil)
+
+			var result *models.WebhookDelivery
+			mockDeliveryRepo.On("UpdateResult", ctx, mock.AnythingOfType("*models.WebhookDelivery")).Return(nil).Run(func(args mock.Arguments) {
+				result = args.Get(1).(*models.WebhookDelivery)
+			})
+
+			before := time.Now()
+			_, err := services.NewWebhookWorker(mockDeliveryRepo, services.NewWebhookAddressGuard(true)).ProcessDue(ctx)
+			require.NoError(t, err)
+
+			require.NotNil(t, result)
+			assert.Equal(t, tt.wantStatus, result.Status)
+			require.NotNil(t, result.LastStatusCode)
+			assert.Equal(t, http.StatusServiceUnavailable, *result.LastStatusCode)
+			assert.Contains(t, result.LastError, "503")
+			assert.Nil(t, result.DeliveredAt)
+			if tt.wantStatus == models.WebhookDeliveryPending {
+				assert.WithinDuration(t, before.Add(tt.wantBackoff), result.NextAttemptAt, 5*time.Second)
+			}
+		})
+	}
+
+	t.Run("connection error", func(t *testing.T) {
+		server := httptest.NewServer(http.NotFoundHandler())
+		url := server.URL
+		server.Close()
+
+		mockDeliveryRepo := new(mocks.MockWebhookDeliveryRepository)
+		mockDeliveryRepo.On("ClaimDue", ctx, mock.Anything, mock.Anything, mock.Anything).Return([]models.WebhookDelivery{
+			{ID: 12, URL: url, Payload: `{}`, Attempts: 1},
+		}, nil)
+
+		var result *models.WebhookDelivery
+		mockDeliveryRepo.On("UpdateResult", ctx, mock.AnythingOfType("*models.WebhookDelivery")).Return(nil).Run(func(args mock.Arguments) {
+			result = args.Get(1).(*models.WebhookDelivery)
+		})
+
+		_, err := services.NewWebhookWorker(mockDeliveryRepo, services.NewWebhookAddressGuard(true)).ProcessDue(ctx)
+		require.NoError(t, err)
+
+		require.NotNil(t, result)
+		assert.Equal(t, models.WebhookDeliveryPending, result.Status)
+		assert.Nil(t, result.LastStatusCode)
+		assert.NotEmpty(t, result.LastError)
+	})
+
+	t.Run("private address is refused at dial time", func(t *testing.T) {
+		// 登録時は公開されたアドレスだったホストが、送信時に内部のアドレスに解決された場合を想定する
+		called := false
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			called = true
+		}))
+		defer server.Close()
+
+		mockDeliveryRepo := new(mocks.MockWebhookDeliveryRepository)
+		mockDeliveryRepo.On("ClaimDue", ctx, mock.Anything, mock.Anything, mock.Anything).Return([]models.WebhookDelivery{
+			{ID: 12, URL: server.URL, Payload: `{}`, Attempts: 1},
+		}, nil)
+
+		var result *models.WebhookDelivery
+		mockDeliveryRepo.On("UpdateResult", ctx, mock.AnythingOfType("*models.WebhookDelivery")).Return(nil).Run(func(args mock.Arguments) {
+			result = args.Get(1).(*models.WebhookDelivery)
+		})
+
+		_, err := services.NewWebhookWorker(mockDeliveryRepo, services.NewWebhookAddressGuard(false)).ProcessDue(ctx)
+		require.NoError(t, err)
+
+		assert.False(t, called)
+		require.NotNil(t, result)
+		assert.Equal(t, models.WebhookDeliveryPending, result.Status)
+		assert.Contains(t, result.LastError, services.ErrWebhookPrivateAddress.Error())
+	})
+
+	t.Run("nothing due", func(t *testing.T) {
+		mockDeliveryRepo := new(mocks.MockWebhookDeliveryRepository)
+		mockDeliveryRepo.On("ClaimDue", ctx, mock.Anything, mock.Anything, mock.Anything).Return([]models.WebhookDelivery{}, nil)
+
+		n, err := services.NewWebhookWorker(mockDeliveryRepo, services.NewWebhookAddressGuard(true)).ProcessDue(ctx)
+		require.NoError(t, err)
+		assert.Zero(t, n)
+		mockDeliveryRepo.AssertNotCalled(t, "UpdateResult", mock.Anything, mock.Anything)
+	})
+}
@@ -0,0 +1,163 @@
+package storage
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// LocalStorage サーバーのファイルシステムにファイルを保存する構造体
+// ダウンロードURLはHMACで署名し、サーバー自身が配信する（VerifySignedURLで検証する）
+type LocalStorage struct {
+	dir        string
+	publicURL  string
+	signingKey []byte
+	now        func() time.Time
+}
+
+// NewLocalStorage 新しいLocalStorageを作成する
+// publicURL はファイルを配信するエンドポイントのURL（例: /api/v1/files）
+func NewLocalStorage(dir, publicURL string, signingKey []byte) (*LocalStorage, error) {
+	if len(signingKey) == 0 {
+		return nil, errors.New("ダウンロードURLの署名鍵を指定してください")
+	}
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return nil, fmt.Errorf("保存先ディレクトリの作成に失敗しました: %w", err)
+	}
+	return &LocalStorage{
+		dir:        dir,
+		publicURL:  strings.TrimRight(publicURL, "/"),
+		signingKey: signingKey,
+		now:        time.Now,
+	}, nil
+}
+
+// path キーに対応するファイルのパスを返す
+func (s *LocalStorage) path(key string) (string, error) {
+	if err := validateKey(key); err != nil {
+		return "", err
+	}
+	return filepath.Join(s.dir, filepath.FromSlash(key)), nil
+}
+
+// Put keyにファイルを保存する
+// 一時ファイルに書き込んでから置き換えるため、書き込み途中のファイルが読まれることはない
+func (s *LocalStorage) Put(_ context.Context, key string, body io.Reader, size int64, _ string) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return fmt.Errorf("保存先ディレクトリの作成に失敗しました: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".upload-*")
+	if err != nil {
+		return fmt.Errorf("ファイルの保存に失敗しました: %w", err)
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }()
+
+	written, err := io.Copy(tmp, body)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("ファイルの保存に失敗しました: %w", err)
+	}
+	if size >= 0 && written != size {
+		return fmt.Errorf("ファイルの保存に失敗しました: サイズが一致しません（%d / %d バイト）", written, size)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("ファイルの保存に失敗しました: %w", err)
+	}
+	return nil
+}
+
+// Open keyのファイルを開く
+func (s *LocalStorage) Open(_ context.Context, key string) (io.ReadCloser, error) {
+	path, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, ErrObjectNotFound
+		}
+		return nil, fmt.Errorf("ファイルの読み込みに失敗しました: %w", err)
+	}
+	return f, nil
+}
+
+// Delete keyのファイルを削除する
+func (s *LocalStorage) Delete(_ context.Context, key string) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("ファイルの削除に失敗しました: %w", err)
+	}
+	return nil
+}
+
+// SignedURL 配信エンドポイントへの署名付きURLを返す
+// ファイル名・Content-Type・有効期限を署名に含めるため、URLを書き換えると検証に失敗する
+func (s *LocalStorage) SignedURL(_ context.Context, key string, opts URLOptions) (string, error) {
+	if err := validateKey(key); err != nil {
+		return "", err
+	}
+	expires := strconv.FormatInt(s.now().Add(opts.Expires).Unix(), 10)
+
+	query := url.Values{}
+	query.Set("expires", expires)
+	if opts.FileName != "" {
+		query.Set("name", opts.FileName)
+	}
+	if opts.ContentType != "" {
+		query.Set("type", opts.ContentType)
+	}
+	query.Set("signature", s.sign(key, expires, opts.FileName, opts.ContentType))
+	return s.publicURL + "/" + uriEncode(key, false) + "?" + query.Encode(), nil
+}
+
+// VerifySignedURL SignedURLで発行したURLのキーとクエリパラメータを検証し、ダウンロード時の設定を返す
+func (s *LocalStorage) VerifySignedURL(key string, query url.Values) (*URLOptions, error) {
+	if err := validateKey(key); err != nil {
+		return nil, err
+	}
+	expires := query.Get("expires")
+	opts := &URLOptions{FileName: query.Get("name"), ContentType: query.Get("type")}
+	expected := s.sign(key, expires, opts.FileName, opts.ContentType)
+	if !hmac.Equal([]byte(expected), []byte(query.Get("signature"))) {
+		return nil, ErrInvalidSignature
+	}
+
+	unix, err := strconv.ParseInt(expires, 10, 64)
+	if err != nil {
+		return nil, ErrInvalidSignature
+	}
+	remaining := time.Unix(unix, 0).Sub(s.now())
+	if remaining <= 0 {
+		return nil, ErrURLExpired
+	}
+	opts.Expires = remaining
+	return opts, nil
+}
+
+// sign URLの内容に対するHMAC-SHA256署名を16進数で返す
+func (s *LocalStorage) sign(key, expires, fileName, contentType string) string {
+	mac := hmac.New(sha256.New, s.signingKey)
+	mac.Write([]byte(strings.Join([]string{key, expires, fileName, contentType}, "\n")))
+	return hex.EncodeToString(mac.Sum(nil))
+}
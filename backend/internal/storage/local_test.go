@@ -0,0 +1,111 @@
+package storage_test
+
+import (
+	"context"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"nocode-app/backend/internal/storage"
+)
+
+func TestLocalStorage_PutOpenDelete(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	s, err := storage.NewLocalStorage(dir, "/api/v1/files", []byte("secret"))
+	require.NoError(t, err)
+
+	require.NoError(t, s.Put(ctx, "apps/1/abc", strings.NewReader("hello"), 5, "text/plain"))
+	saved, err := os.ReadFile(filepath.Join(dir, "apps", "1", "abc"))
+	require.NoError(t, err)
+	assert.Equal(t, "hello", string(saved))
+
+	rc, err := s.Open(ctx, "apps/1/abc")
+	require.NoError(t, err)
+	body, err := io.ReadAll(rc)
+	require.NoError(t, err)
+	require.NoError(t, rc.Close())
+	assert.Equal(t, "hello", string(body))
+
+	require.NoError(t, s.Delete(ctx, "apps/1/abc"))
+	_, err = s.Open(ctx, "apps/1/abc")
+	assert.ErrorIs(t, err, storage.ErrObjectNotFound)
+	assert.NoError(t, s.Delete(ctx, "apps/1/abc"))
+}
+
+func TestLocalStorage_Put_SizeMismatch(t *testing.T) {
+	dir := t.TempDir()
+	s, err := storage.NewLocalStorage(dir, "/api/v1/files", []byte("secret"))
+	require.NoError(t, err)
+
+	err = s.Put(context.Background(), "apps/1/abc", strings.NewReader("hello"), 10, "text/plain")
+	require.Error(t, err)
+	// 途中まで書き込んだファイルは残さない
+	_, err = os.Stat(filepath.Join(dir, "apps", "1", "abc"))
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
+
+func TestLocalStorage_RejectsInvalidKey(t *testing.T) {
+	s, err := storage.NewLocalStorage(t.TempDir(), "/api/v1/files", []byte("secret"))
+	require.NoError(t, err)
+
+	for _, key := range []string{"../secret", "apps/../../etc/passwd", "/etc/passwd", "apps//1", ""} {
+		t.Run(key, func(t *testing.T) {
+			err := s.Put(context.Background(), key, strings.NewReader(""), 0, "")
+			assert.ErrorIs(t, err, storage.ErrInvalidKey)
+		})
+	}
+}
+
+func TestLocalStorage_SignedURL(t *testing.T) {
+	s, err := storage.NewLocalStorage(t.TempDir(), "/api/v1/files/", []byte("secret"))
+	require.NoError(t, err)
+
+	signed, err := s.SignedURL(context.Background(), "apps/1/abc", storage.URLOptions{
+		FileName: "見積書.pdf", ContentType: "application/pdf", Expires: time.Minute,
+	})
+	require.NoError(t, err)
+	u, err := url.Parse(signed)
+	require.NoError(t, err)
+	assert.Equal(t, "/api/v1/files/apps/1/abc", u.Path)
+
+	t.Run("valid", func(t *testing.T) {
+		opts, err := s.VerifySignedURL("apps/1/abc", u.Query())
+		require.NoError(t, err)
+		assert.Equal(t, "見積書.pdf", opts.FileName)
+		assert.Equal(t, "application/pdf", opts.ContentType)
+	})
+
+	t.Run("tampered", func(t *testing.T) {
+		query := u.Query()
+		query.Set("type", "text/html")
+		_, err := s.VerifySignedURL("apps/1/abc", query)
+		assert.ErrorIs(t, err, storage.ErrInvalidSignature)
+
+		_, err = s.VerifySignedURL("apps/1/other", u.Query())
+		assert.ErrorIs(t, err, storage.ErrInvalidSignature)
+	})
+
+	t.Run("other key", func(t *testing.T) {
+		other, err := storage.NewLocalStorage(t.TempDir(), "/api/v1/files", []byte("other"))
+		require.NoError(t, err)
+		_, err = other.VerifySignedURL("apps/1/abc", u.Query())
+		assert.ErrorIs(t, err, storage.ErrInvalidSignature)
+	})
+
+	t.Run("expired", func(t *testing.T) {
+		expired, err := s.SignedURL(context.Background(), "apps/1/abc", storage.URLOptions{Expires: -time.Second})
+		require.NoError(t, err)
+		eu, err := url.Parse(expired)
+		require.NoError(t, err)
+		_, err = s.VerifySignedURL("apps/1/abc", eu.Query())
+		assert.ErrorIs(t, err, storage.ErrURLExpired)
+	})
+}
@@ -0,0 +1,292 @@
+package storage
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// s3UnsignedPayload 本文を署名に含めない場合のペイロードハッシュ
+	s3UnsignedPayload = "UNSIGNED-PAYLOAD"
+	// s3MaxPresignExpiry 署名付きURLの有効期間の上限（7日）
+	s3MaxPresignExpiry = 7 * 24 * time.Hour
+	// s3RequestTimeout 1回のリクエストのタイムアウト（アップロードを含む）
+	s3RequestTimeout = 5 * time.Minute
+)
+
+// S3Config S3互換ストレージの接続設定
+type S3Config struct {
+	// Endpoint エンドポイントのURL（例: https://s3.ap-northeast-1.amazonaws.com、http://localhost:9000）
+	Endpoint string
+	Region   string
+	Bucket   string
+	// AccessKeyID / SecretAccessKey 署名に使う認証情報
+	AccessKeyID     string
+	SecretAccessKey string
+	// UsePathStyle バケット名をホスト名ではなくパスに含める（MinIOなど）
+	UsePathStyle bool
+}
+
+// S3Storage S3互換のオブジェクトストレージにファイルを保存する構造体
+// リクエストは AWS Signature Version 4 で署名する
+type S3Storage struct {
+	cfg      S3Config
+	endpoint *url.URL
+	client   *http.Client
+	now      func() time.Time
+}
+
+// NewS3Storage 新しいS3Storageを作成する
+func NewS3Storage(cfg S3Config) (*S3Storage, error) {
+	endpoint, err := url.Parse(cfg.Endpoint)
+	if err != nil || (endpoint.Scheme != "http" && endpoint.Scheme != "https") || endpoint.Host == "" {
+		return nil, fmt.Errorf("S3のエンドポイント %q が正しくありません", cfg.Endpoint)
+	}
+	if cfg.Bucket == "" || cfg.Region == "" {
+		return nil, errors.New("S3のバケット名とリージョンを指定してください")
+	}
+	if cfg.AccessKeyID == "" || cfg.SecretAccessKey == "" {
+		return nil, errors.New("S3のアクセスキーを指定してください")
+	}
+	return &S3Storage{
+		cfg:      cfg,
+		endpoint: endpoint,
+		client:   &http.Client{Timeout: s3RequestTimeout},
+		now:      time.Now,
+	}, nil
+}
+
+// objectURL キーのオブジェクトのURLを返す（キーが空の場合はバケットのURL）
+func (s *S3Storage) objectURL(key string) *url.URL {
+	u := *s.endpoint
+	base := strings.TrimRight(u.Path, "/")
+	if s.cfg.UsePathStyle {
+		base += "/" + s.cfg.Bucket
+	} else {
+		u.Host = s.cfg.Bucket + "." + u.Host
+	}
+	u.Path = base + "/" + key
+	u.RawPath = uriEncode(base, false) + "/" + uriEncode(key, false)
+	u.RawQuery = ""
+	return &u
+}
+
+// Put keyにファイルをアップロードする
+func (s *S3Storage) Put(ctx context.Context, key string, body io.Reader, size int64, contentType string) error {
+	if err := validateKey(key); err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, s.objectURL(key).String(), body)
+	if err != nil {
+		return err
+	}
+	req.ContentLength = size
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	resp, err := s.send(req)
+	if err != nil {
+		return fmt.Errorf("ファイルのアップロードに失敗しました: %w", err)
+	}
+	_ = resp.Body.Close()
+	return nil
+}
+
+// Open keyのオブジェクトをダウンロードする
+func (s *S3Storage) Open(ctx context.Context, key string) (io.ReadCloser, error) {
+	if err := validateKey(key); err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.objectURL(key).String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := s.send(req)
+	if err != nil {
+		if errors.Is(err, ErrObjectNotFound) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("ファイルの読み込みに失敗しました: %w", err)
+	}
+	return resp.Body, nil
+}
+
+// Delete keyのオブジェクトを削除する
+func (s *S3Storage) Delete(ctx context.Context, key string) error {
+	if err := validateKey(key); err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodDelete, s.objectURL(key).String(), nil)
+	if err != nil {
+		return err
+	}
+	resp, err := s.send(req)
+	if err != nil {
+		if errors.Is(err, ErrObjectNotFound) {
+			return nil
+		}
+		return fmt.Errorf("ファイルの削除に失敗しました: %w", err)
+	}
+	_ = resp.Body.Close()
+	return nil
+}
+
+// SignedURL 署名付き（presigned）のダウンロードURLを返す
+// ファイル名とContent-Typeはレスポンスヘッダーの上書き指定として署名に含める
+func (s *S3Storage) SignedURL(_ context.Context, key string, opts URLOptions) (string, error) {
+	if err := validateKey(key); err != nil {
+		return "", err
+	}
+	expires := opts.Expires
+	if expires > s3MaxPresignExpiry {
+		expires = s3MaxPresignExpiry
+	}
+	if expires < time.Second {
+		expires = time.Second
+	}
+
+	now := s.now().UTC()
+	u := s.objectURL(key)
+	query := map[string]string{
+		"X-Amz-Algorithm":     "AWS4-HMAC-SHA256",
+		"X-Amz-Credential":    s.cfg.AccessKeyID + "/" + s.scope(now),
+		"X-Amz-Date":          now.Format("20060102T150405Z"),
+		"X-Amz-Expires":       strconv.Itoa(int(expires / time.Second)),
+		"X-Amz-SignedHeaders": "host",
+	}
+	if opts.FileName != "" {
+		query["response-content-disposition"] = ContentDisposition(opts.FileName)
+	}
+	if opts.ContentType != "" {
+		query["response-content-type"] = opts.ContentType
+	}
+
+	canonicalQuery := canonicalQueryString(query)
+	canonicalRequest := strings.Join([]string{
+		http.MethodGet,
+		u.EscapedPath(),
+		canonicalQuery,
+		"host:" + u.Host + "\n",
+		"host",
+		s3UnsignedPayload,
+	}, "\n")
+	signature := s.signature(now, canonicalRequest)
+
+	u.RawQuery = canonicalQuery + "&X-Amz-Signature=" + signature
+	return u.String(), nil
+}
+
+// send リクエストに署名して送信する
+// 2xx以外の応答はエラーとし、404の場合はErrObjectNotFoundを返す
+func (s *S3Storage) send(req *http.Request) (*http.Response, error) {
+	s.sign(req)
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return resp, nil
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, ErrObjectNotFound
+	}
+
+	// S3のエラー応答（XML）からエラーコードを取り出す
+	var s3Err struct {
+		Code    string `xml:"Code"`
+		Message string `xml:"Message"`
+	}
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+	if xml.Unmarshal(body, &s3Err) == nil && s3Err.Code != "" {
+		return nil, fmt.Errorf("S3がステータス %d を返しました: %s %s", resp.StatusCode, s3Err.Code, s3Err.Message)
+	}
+	return nil, fmt.Errorf("S3がステータス %d を返しました", resp.StatusCode)
+}
+
+// sign リクエストにAuthorizationヘッダーを設定する（本文は署名に含めない）
+func (s *S3Storage) sign(req *http.Request) {
+	now := s.now().UTC()
+	req.Header.Set("X-Amz-Date", now.Format("20060102T150405Z"))
+	req.Header.Set("X-Amz-Content-Sha256", s3UnsignedPayload)
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		canonicalQueryString(flattenQuery(req.URL.Query())),
+		"host:" + req.URL.Host + "\n" +
+			"x-amz-content-sha256:" + s3UnsignedPayload + "\n" +
+			"x-amz-date:" + req.Header.Get("X-Amz-Date") + "\n",
+		"host;x-amz-content-sha256;x-amz-date",
+		s3UnsignedPayload,
+	}, "\n")
+
+	req.Header.Set("Authorization", fmt.Sprintf(
+		"AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=host;x-amz-content-sha256;x-amz-date, Signature=%s",
+		s.cfg.AccessKeyID, s.scope(now), s.signature(now, canonicalRequest),
+	))
+}
+
+// scope 署名のスコープ（日付/リージョン/サービス/aws4_request）を返す
+func (s *S3Storage) scope(now time.Time) string {
+	return now.Format("20060102") + "/" + s.cfg.Region + "/s3/aws4_request"
+}
+
+// signature 正規リクエストに対する署名を16進数で返す
+func (s *S3Storage) signature(now time.Time, canonicalRequest string) string {
+	hash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := strings.Join([]string{
+		"AWS4-HMAC-SHA256",
+		now.Format("20060102T150405Z"),
+		s.scope(now),
+		hex.EncodeToString(hash[:]),
+	}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+s.cfg.SecretAccessKey), now.Format("20060102"))
+	key = hmacSHA256(key, s.cfg.Region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	return hex.EncodeToString(hmacSHA256(key, stringToSign))
+}
+
+// canonicalQueryString クエリパラメータを署名用の正規形式（キー順・RFC 3986 エンコード）にする
+func canonicalQueryString(query map[string]string) string {
+	keys := make([]string, 0, len(query))
+	for k := range query {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	pairs := make([]string, len(keys))
+	for i, k := range keys {
+		pairs[i] = uriEncode(k, true) + "=" + uriEncode(query[k], true)
+	}
+	return strings.Join(pairs, "&")
+}
+
+// flattenQuery url.Values をキーごとに最初の値だけを持つマップにする
+func flattenQuery(values url.Values) map[string]string {
+	query := make(map[string]string, len(values))
+	for k, v := range values {
+		query[k] = v[0]
+	}
+	return query
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
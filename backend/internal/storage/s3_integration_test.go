@@ -0,0 +1,88 @@
+//go:build integration
+// +build integration
+
+package storage
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"nocode-app/backend/internal/testhelpers"
+)
+
+// TestS3Storage_MinIO_Integration MinIOを使ったS3互換ストレージの統合テスト
+func TestS3Storage_MinIO_Integration(t *testing.T) {
+	if testing.Short() {
+		t.Skip("統合テストはショートモードでスキップされます")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+
+	container, err := testhelpers.SetupMinioContainer(ctx)
+	require.NoError(t, err, "MinIOコンテナのセットアップに失敗しました")
+	defer func() {
+		if termErr := container.Terminate(ctx); termErr != nil {
+			t.Logf("コンテナの終了に失敗しました: %v", termErr)
+		}
+	}()
+
+	s, err := NewS3Storage(S3Config{
+		Endpoint:        container.Endpoint,
+		Region:          "us-east-1",
+		Bucket:          "attachments",
+		AccessKeyID:     container.AccessKey,
+		SecretAccessKey: container.SecretKey,
+		UsePathStyle:    true,
+	})
+	require.NoError(t, err)
+
+	// バケットを作成
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, container.Endpoint+"/attachments", nil)
+	require.NoError(t, err)
+	resp, err := s.send(req)
+	require.NoError(t, err, "バケットの作成に失敗しました")
+	_ = resp.Body.Close()
+
+	t.Run("put, open and delete", func(t *testing.T) {
+		require.NoError(t, s.Put(ctx, "apps/1/abc", strings.NewReader("hello"), 5, "text/plain"))
+
+		rc, err := s.Open(ctx, "apps/1/abc")
+		require.NoError(t, err)
+		body, err := io.ReadAll(rc)
+		require.NoError(t, err)
+		require.NoError(t, rc.Close())
+		assert.Equal(t, "hello", string(body))
+
+		require.NoError(t, s.Delete(ctx, "apps/1/abc"))
+		_, err = s.Open(ctx, "apps/1/abc")
+		assert.ErrorIs(t, err, ErrObjectNotFound)
+	})
+
+	t.Run("signed url downloads without credentials", func(t *testing.T) {
+		require.NoError(t, s.Put(ctx, "apps/1/report", strings.NewReader("%PDF-1.4"), 8, "application/pdf"))
+
+		signed, err := s.SignedURL(ctx, "apps/1/report", URLOptions{
+			FileName: "見積書.pdf", ContentType: "application/pdf", Expires: time.Minute,
+		})
+		require.NoError(t, err)
+
+		resp, err := http.Get(signed)
+		require.NoError(t, err)
+		defer func() { _ = resp.Body.Close() }()
+		body, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, "%PDF-1.4", string(body))
+		assert.Equal(t, "application/pdf", resp.Header.Get("Content-Type"))
+		assert.Contains(t, resp.Header.Get("Content-Disposition"), "filename*=UTF-8''")
+	})
+}
@@ -0,0 +1,100 @@
+// Package storage 添付ファイルの保存先（ローカルファイルシステム・S3互換ストレージ）を扱う
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// ストレージ関連エラー
+var (
+	ErrObjectNotFound   = errors.New("ファイルが見つかりません")
+	ErrInvalidKey       = errors.New("無効なファイルのキーです")
+	ErrInvalidSignature = errors.New("ダウンロードURLの署名が正しくありません")
+	ErrURLExpired       = errors.New("ダウンロードURLの有効期限が切れています")
+)
+
+// keyPattern ファイルのキーとして使える文字列（"/" 区切りの英数字・"."・"_"・"-"）
+var keyPattern = regexp.MustCompile(`^[A-Za-z0-9_-][A-Za-z0-9._-]*(/[A-Za-z0-9_-][A-Za-z0-9._-]*)*$`)
+
+// URLOptions 署名付きダウンロードURLの設定
+type URLOptions struct {
+	// FileName ダウンロード時のファイル名
+	FileName string
+	// ContentType ダウンロード時のContent-Type
+	ContentType string
+	// Expires URLの有効期間
+	Expires time.Duration
+}
+
+// FileStorage 添付ファイルの保存先のインターフェースを定義
+type FileStorage interface {
+	// Put keyにファイルを保存する（同じキーのファイルは上書きする）
+	Put(ctx context.Context, key string, body io.Reader, size int64, contentType string) error
+	// Open keyのファイルを開く。存在しない場合はErrObjectNotFoundを返す
+	Open(ctx context.Context, key string) (io.ReadCloser, error)
+	// Delete keyのファイルを削除する。存在しない場合も成功とする
+	Delete(ctx context.Context, key string) error
+	// SignedURL 認証なしで期限までダウンロードできる署名付きURLを返す
+	SignedURL(ctx context.Context, key string, opts URLOptions) (string, error)
+}
+
+// 実装がインターフェースを満たすことを確認
+var (
+	_ FileStorage = (*LocalStorage)(nil)
+	_ FileStorage = (*S3Storage)(nil)
+)
+
+// validateKey キーがパスとして安全に使える形式か確認する
+func validateKey(key string) error {
+	if !keyPattern.MatchString(key) {
+		return ErrInvalidKey
+	}
+	for _, segment := range strings.Split(key, "/") {
+		if segment == "." || segment == ".." {
+			return ErrInvalidKey
+		}
+	}
+	return nil
+}
+
+// ContentDisposition ダウンロード時のファイル名を指定するContent-Dispositionヘッダーの値を返す
+// 日本語のファイル名も扱えるよう RFC 6266 の filename* を併記する
+func ContentDisposition(fileName string) string {
+	if fileName == "" {
+		return "attachment"
+	}
+	fallback := strings.Map(func(r rune) rune {
+		if r < 0x20 || r > 0x7e || r == '"' || r == '\\' {
+			return '_'
+		}
+		return r
+	}, fileName)
+	return `attachment; filename="` + fallback + `"; filename*=UTF-8''` + uriEncode(fileName, true)
+}
+
+// uriEncode RFC 3986 の非予約文字以外をパーセントエンコードする
+// encodeSlash が false の場合は "/" をそのまま残す
+func uriEncode(s string, encodeSlash bool) string {
+	const hex = "0123456789ABCDEF"
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case 'A' <= c && c <= 'Z', 'a' <= c && c <= 'z', '0' <= c && c <= '9',
+			c == '-', c == '_', c == '.', c == '~':
+			b.WriteByte(c)
+		case c == '/' && !encodeSlash:
+			b.WriteByte(c)
+		default:
+			b.WriteByte('%')
+			b.WriteByte(hex[c>>4])
+			b.WriteByte(hex[c&15])
+		}
+	}
+	return b.String()
+}
@@ -71,7 +71,7 @@ func ResetDatabase(ctx context.Context) error {
 
 	// 静的テーブルを TRUNCATE
 	if _, err := appTestDB.ExecContext(ctx,
-		`TRUNCATE "dashboard_widgets", "chart_configs", "app_views", "app_fields", "apps", "data_sources", "users" RESTART IDENTITY CASCADE`); err != nil {
+		`TRUNCATE "report_deliveries", "saved_reports", "deleted_records", "app_indexes", "attachments", "automation_runs", "automation_rules", "webhook_deliveries", "webhooks", "record_revisions", "app_permissions", "user_group_members", "user_groups", "dashboard_widgets", "chart_configs", "app_views", "app_fields", "apps", "data_sources", "users" RESTART IDENTITY CASCADE`); err != nil {
 		return fmt.Errorf("TRUNCATE 失敗: %w", err)
 	}
 
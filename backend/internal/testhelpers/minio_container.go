@@ -0,0 +1,62 @@
+package testhelpers
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/testcontainers/testcontainers-go"
+	"github.com/testcontainers/testcontainers-go/wait"
+)
+
+// MinioTestContainer S3互換ストレージ（MinIO）テストコンテナの設定
+type MinioTestContainer struct {
+	Container *testcontainers.DockerContainer
+	// Endpoint S3 APIのURL（http://host:port）
+	Endpoint  string
+	AccessKey string
+	SecretKey string
+}
+
+// SetupMinioContainer MinIOテストコンテナをセットアップする
+func SetupMinioContainer(ctx context.Context) (*MinioTestContainer, error) {
+	accessKey := "testaccess"
+	secretKey := "testsecret"
+
+	container, err := testcontainers.Run(ctx,
+		"minio/minio:latest",
+		testcontainers.WithExposedPorts("9000/tcp"),
+		testcontainers.WithEnv(map[string]string{
+			"MINIO_ROOT_USER":     accessKey,
+			"MINIO_ROOT_PASSWORD": secretKey,
+		}),
+		testcontainers.WithCmd("server", "/data"),
+		testcontainers.WithWaitStrategy(
+			wait.ForHTTP("/minio/health/live").
+				WithPort("9000/tcp").
+				WithStartupTimeout(60*time.Second)),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("MinIOコンテナの起動に失敗しました: %w", err)
+	}
+
+	endpoint, err := container.PortEndpoint(ctx, "9000/tcp", "http")
+	if err != nil {
+		return nil, fmt.Errorf("エンドポイントの取得に失敗しました: %w", err)
+	}
+
+	return &MinioTestContainer{
+		Container: container,
+		Endpoint:  endpoint,
+		AccessKey: accessKey,
+		SecretKey: secretKey,
+	}, nil
+}
+
+// Terminate コンテナを終了する
+func (m *MinioTestContainer) Terminate(ctx context.Context) error {
+	if m.Container != nil {
+		return m.Container.Terminate(ctx)
+	}
+	return nil
+}
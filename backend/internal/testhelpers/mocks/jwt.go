@@ -2,6 +2,8 @@
 package mocks
 
 import (
+	"time"
+
 	"github.com/stretchr/testify/mock"
 
 	"nocode-app/backend/internal/utils"
@@ -29,3 +31,16 @@ func (m *MockJWTManager) RefreshToken(claims *utils.JWTClaims) (string, error) {
 	args := m.Called(claims)
 	return args.String(0), args.Error(1)
 }
+
+func (m *MockJWTManager) GenerateScopedToken(claims *utils.JWTClaims, audience string, ttl time.Duration) (string, error) {
+	args := m.Called(claims, audience, ttl)
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockJWTManager) ValidateScopedToken(tokenString, audience string) (*utils.JWTClaims, error) {
+	args := m.Called(tokenString, audience)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*utils.JWTClaims), args.Error(1)
+}
@@ -0,0 +1,20 @@
+// Package mocks テスト用のモック実装を提供
+package mocks
+
+import (
+	"context"
+
+	"github.com/stretchr/testify/mock"
+
+	"nocode-app/backend/internal/mail"
+)
+
+// MockMailSender mail.Senderのモック実装
+type MockMailSender struct {
+	mock.Mock
+}
+
+func (m *MockMailSender) Send(ctx context.Context, msg *mail.Message) error {
+	args := m.Called(ctx, msg)
+	return args.Error(0)
+}
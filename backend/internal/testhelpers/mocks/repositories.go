@@ -3,6 +3,7 @@ package mocks
 
 import (
 	"context"
+	"time"
 
 	"github.com/stretchr/testify/mock"
 
@@ -105,6 +106,11 @@ func (m *MockAppRepository) GetByUserID(ctx context.Context, userID uint64, page
 	return args.Get(0).([]models.App), args.Get(1).(int64), args.Error(2)
 }
 
+func (m *MockAppRepository) GetAccessibleByUserID(ctx context.Context, userID uint64, page, limit int) ([]models.App, int64, error) {
+	args := m.Called(ctx, userID, page, limit)
+	return args.Get(0).([]models.App), args.Get(1).(int64), args.Error(2)
+}
+
 func (m *MockAppRepository) Update(ctx context.Context, app *models.App) error {
 	args := m.Called(ctx, app)
 	return args.Error(0)
@@ -128,6 +134,48 @@ func (m *MockAppRepository) GetAllTableNames(ctx context.Context) ([]string, err
 	return args.Get(0).([]string), args.Error(1)
 }
 
+func (m *MockAppRepository) GetByTableNames(ctx context.Context, tableNames []string) ([]models.App, error) {
+	args := m.Called(ctx, tableNames)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.App), args.Error(1)
+}
+
+func (m *MockAppRepository) Trash(ctx context.Context, id uint64) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockAppRepository) Restore(ctx context.Context, id uint64) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockAppRepository) GetTrashedByID(ctx context.Context, id uint64) (*models.App, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.App), args.Error(1)
+}
+
+func (m *MockAppRepository) GetTrashed(ctx context.Context) ([]models.App, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.App), args.Error(1)
+}
+
+func (m *MockAppRepository) GetTrashedBefore(ctx context.Context, before time.Time, limit int) ([]models.App, error) {
+	args := m.Called(ctx, before, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.App), args.Error(1)
+}
+
 // MockFieldRepository FieldRepositoryInterfaceのモック実装
 type MockFieldRepository struct {
 	mock.Mock
@@ -159,6 +207,14 @@ func (m *MockFieldRepository) GetByAppID(ctx context.Context, appID uint64) ([]m
 	return args.Get(0).([]models.AppField), args.Error(1)
 }
 
+func (m *MockFieldRepository) GetReferencingFields(ctx context.Context, appID uint64) ([]models.AppField, error) {
+	args := m.Called(ctx, appID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.AppField), args.Error(1)
+}
+
 func (m *MockFieldRepository) GetByAppIDAndCode(ctx context.Context, appID uint64, fieldCode string) (*models.AppField, error) {
 	args := m.Called(ctx, appID, fieldCode)
 	if args.Get(0) == nil {
@@ -177,6 +233,11 @@ func (m *MockFieldRepository) Delete(ctx context.Context, id uint64) error {
 	return args.Error(0)
 }
 
+func (m *MockFieldRepository) UpdateIfVersion(ctx context.Context, field *models.AppField, version int64) (bool, error) {
+	args := m.Called(ctx, field, version)
+	return args.Bool(0), args.Error(1)
+}
+
 func (m *MockFieldRepository) UpdateOrder(ctx context.Context, items []models.FieldOrderItem) error {
 	args := m.Called(ctx, items)
 	return args.Error(0)
@@ -192,6 +253,45 @@ func (m *MockFieldRepository) GetMaxDisplayOrder(ctx context.Context, appID uint
 	return args.Int(0), args.Error(1)
 }
 
+func (m *MockFieldRepository) Trash(ctx context.Context, id uint64) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockFieldRepository) TrashIfVersion(ctx context.Context, id uint64, version int64) (bool, error) {
+	args := m.Called(ctx, id, version)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockFieldRepository) Restore(ctx context.Context, id uint64) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockFieldRepository) GetTrashedByID(ctx context.Context, id uint64) (*models.AppField, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.AppField), args.Error(1)
+}
+
+func (m *MockFieldRepository) GetTrashedByAppID(ctx context.Context, appID uint64) ([]models.AppField, error) {
+	args := m.Called(ctx, appID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.AppField), args.Error(1)
+}
+
+func (m *MockFieldRepository) GetTrashedBefore(ctx context.Context, before time.Time, limit int) ([]models.AppField, error) {
+	args := m.Called(ctx, before, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.AppField), args.Error(1)
+}
+
 // MockViewRepository ViewRepositoryInterfaceのモック実装
 type MockViewRepository struct {
 	mock.Mock
@@ -231,11 +331,21 @@ func (m *MockViewRepository) Update(ctx context.Context, view *models.AppView) e
 	return args.Error(0)
 }
 
+func (m *MockViewRepository) UpdateIfVersion(ctx context.Context, view *models.AppView, version int64) (bool, error) {
+	args := m.Called(ctx, view, version)
+	return args.Bool(0), args.Error(1)
+}
+
 func (m *MockViewRepository) Delete(ctx context.Context, id uint64) error {
 	args := m.Called(ctx, id)
 	return args.Error(0)
 }
 
+func (m *MockViewRepository) DeleteIfVersion(ctx context.Context, id uint64, version int64) (bool, error) {
+	args := m.Called(ctx, id, version)
+	return args.Bool(0), args.Error(1)
+}
+
 func (m *MockViewRepository) ClearDefaultByAppID(ctx context.Context, appID uint64) error {
 	args := m.Called(ctx, appID)
 	return args.Error(0)
@@ -302,16 +412,85 @@ func (m *MockDynamicQueryExecutor) DropColumn(ctx context.Context, tableName, co
 	return args.Error(0)
 }
 
+func (m *MockDynamicQueryExecutor) SetUniqueConstraint(ctx context.Context, tableName, columnName string, unique bool) error {
+	args := m.Called(ctx, tableName, columnName, unique)
+	return args.Error(0)
+}
+
+func (m *MockDynamicQueryExecutor) CreateIndex(ctx context.Context, tableName string, index *models.AppIndex) error {
+	args := m.Called(ctx, tableName, index)
+	return args.Error(0)
+}
+
+func (m *MockDynamicQueryExecutor) DropIndex(ctx context.Context, indexName string) error {
+	args := m.Called(ctx, indexName)
+	return args.Error(0)
+}
+
+func (m *MockDynamicQueryExecutor) GetIndexUsage(ctx context.Context, tableName string) ([]models.IndexUsage, error) {
+	args := m.Called(ctx, tableName)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.IndexUsage), args.Error(1)
+}
+
+func (m *MockDynamicQueryExecutor) PreviewConversion(ctx context.Context, tableName string, conversion *models.FieldConversion, sampleLimit int) (*models.FieldConversionPreview, error) {
+	args := m.Called(ctx, tableName, conversion, sampleLimit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.FieldConversionPreview), args.Error(1)
+}
+
+func (m *MockDynamicQueryExecutor) ConvertColumn(ctx context.Context, tableName string, conversion *models.FieldConversion) (int64, error) {
+	args := m.Called(ctx, tableName, conversion)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockDynamicQueryExecutor) SetForeignKey(ctx context.Context, tableName, columnName, refTableName string, onDelete models.ReferenceOnDelete) error {
+	args := m.Called(ctx, tableName, columnName, refTableName, onDelete)
+	return args.Error(0)
+}
+
+func (m *MockDynamicQueryExecutor) DropForeignKey(ctx context.Context, tableName, columnName string) error {
+	args := m.Called(ctx, tableName, columnName)
+	return args.Error(0)
+}
+
+func (m *MockDynamicQueryExecutor) SetFormulaColumn(ctx context.Context, tableName string, field *models.AppField, expression string) error {
+	args := m.Called(ctx, tableName, field, expression)
+	return args.Error(0)
+}
+
+func (m *MockDynamicQueryExecutor) SetSearchColumn(ctx context.Context, tableName string, fields []models.AppField, config string) error {
+	args := m.Called(ctx, tableName, fields, config)
+	return args.Error(0)
+}
+
 func (m *MockDynamicQueryExecutor) InsertRecord(ctx context.Context, tableName string, data models.RecordData, userID uint64) (uint64, error) {
 	args := m.Called(ctx, tableName, data, userID)
 	return args.Get(0).(uint64), args.Error(1)
 }
 
+func (m *MockDynamicQueryExecutor) InsertRecords(ctx context.Context, tableName string, rows []models.RecordData, userID uint64) ([]uint64, error) {
+	args := m.Called(ctx, tableName, rows, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]uint64), args.Error(1)
+}
+
 func (m *MockDynamicQueryExecutor) UpdateRecord(ctx context.Context, tableName string, recordID uint64, data models.RecordData) error {
 	args := m.Called(ctx, tableName, recordID, data)
 	return args.Error(0)
 }
 
+func (m *MockDynamicQueryExecutor) UpdateRecordIfVersion(ctx context.Context, tableName string, recordID uint64, version int64, data models.RecordData) (bool, error) {
+	args := m.Called(ctx, tableName, recordID, version, data)
+	return args.Bool(0), args.Error(1)
+}
+
 func (m *MockDynamicQueryExecutor) DeleteRecord(ctx context.Context, tableName string, recordID uint64) error {
 	args := m.Called(ctx, tableName, recordID)
 	return args.Error(0)
@@ -322,6 +501,26 @@ func (m *MockDynamicQueryExecutor) DeleteRecords(ctx context.Context, tableName
 	return args.Error(0)
 }
 
+func (m *MockDynamicQueryExecutor) TrashRecords(ctx context.Context, tableName string, recordIDs []uint64, userID uint64) error {
+	args := m.Called(ctx, tableName, recordIDs, userID)
+	return args.Error(0)
+}
+
+func (m *MockDynamicQueryExecutor) TrashRecordIfVersion(ctx context.Context, tableName string, recordID uint64, version int64, userID uint64) (bool, error) {
+	args := m.Called(ctx, tableName, recordID, version, userID)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockDynamicQueryExecutor) RestoreRecord(ctx context.Context, tableName string, deletedRecordID uint64) error {
+	args := m.Called(ctx, tableName, deletedRecordID)
+	return args.Error(0)
+}
+
+func (m *MockDynamicQueryExecutor) StreamRecords(ctx context.Context, tableName string, fields []models.AppField, opts repositories.RecordQueryOptions, fn repositories.RecordStreamFunc) error {
+	args := m.Called(ctx, tableName, fields, opts, fn)
+	return args.Error(0)
+}
+
 func (m *MockDynamicQueryExecutor) GetRecords(ctx context.Context, tableName string, fields []models.AppField, opts repositories.RecordQueryOptions) ([]models.RecordResponse, int64, error) {
 	args := m.Called(ctx, tableName, fields, opts)
 	if args.Get(0) == nil {
@@ -338,6 +537,14 @@ func (m *MockDynamicQueryExecutor) GetRecordByID(ctx context.Context, tableName
 	return args.Get(0).(*models.RecordResponse), args.Error(1)
 }
 
+func (m *MockDynamicQueryExecutor) GetRecordsByIDs(ctx context.Context, tableName string, fields []models.AppField, recordIDs []uint64) ([]models.RecordResponse, error) {
+	args := m.Called(ctx, tableName, fields, recordIDs)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.RecordResponse), args.Error(1)
+}
+
 func (m *MockDynamicQueryExecutor) GetAggregatedData(ctx context.Context, tableName string, req *models.ChartDataRequest) (*models.ChartDataResponse, error) {
 	args := m.Called(ctx, tableName, req)
 	if args.Get(0) == nil {
@@ -346,6 +553,14 @@ func (m *MockDynamicQueryExecutor) GetAggregatedData(ctx context.Context, tableN
 	return args.Get(0).(*models.ChartDataResponse), args.Error(1)
 }
 
+func (m *MockDynamicQueryExecutor) GetPivotData(ctx context.Context, tableName string, req *models.PivotRequest) (*models.PivotResponse, error) {
+	args := m.Called(ctx, tableName, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.PivotResponse), args.Error(1)
+}
+
 func (m *MockDynamicQueryExecutor) CountRecords(ctx context.Context, tableName string) (int64, error) {
 	args := m.Called(ctx, tableName)
 	return args.Get(0).(int64), args.Error(1)
@@ -444,6 +659,11 @@ func (m *MockExternalQueryExecutor) GetRecords(ctx context.Context, ds *models.D
 	return args.Get(0).([]models.RecordResponse), args.Get(1).(int64), args.Error(2)
 }
 
+func (m *MockExternalQueryExecutor) StreamRecords(ctx context.Context, ds *models.DataSource, password string, tableName string, fields []models.AppField, opts repositories.RecordQueryOptions, fn repositories.RecordStreamFunc) error {
+	args := m.Called(ctx, ds, password, tableName, fields, opts, fn)
+	return args.Error(0)
+}
+
 func (m *MockExternalQueryExecutor) GetRecordByID(ctx context.Context, ds *models.DataSource, password string, tableName string, fields []models.AppField, recordID uint64) (*models.RecordResponse, error) {
 	args := m.Called(ctx, ds, password, tableName, fields, recordID)
 	if args.Get(0) == nil {
@@ -460,6 +680,14 @@ func (m *MockExternalQueryExecutor) GetAggregatedData(ctx context.Context, ds *m
 	return args.Get(0).(*models.ChartDataResponse), args.Error(1)
 }
 
+func (m *MockExternalQueryExecutor) GetPivotData(ctx context.Context, ds *models.DataSource, password string, tableName string, fields []models.AppField, req *models.PivotRequest) (*models.PivotResponse, error) {
+	args := m.Called(ctx, ds, password, tableName, fields, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.PivotResponse), args.Error(1)
+}
+
 func (m *MockExternalQueryExecutor) CountRecords(ctx context.Context, ds *models.DataSource, password string, tableName string) (int64, error) {
 	args := m.Called(ctx, ds, password, tableName)
 	return args.Get(0).(int64), args.Error(1)
@@ -520,11 +748,21 @@ func (m *MockDashboardWidgetRepository) Update(ctx context.Context, widget *mode
 	return args.Error(0)
 }
 
+func (m *MockDashboardWidgetRepository) UpdateIfVersion(ctx context.Context, widget *models.DashboardWidget, version int64) (bool, error) {
+	args := m.Called(ctx, widget, version)
+	return args.Bool(0), args.Error(1)
+}
+
 func (m *MockDashboardWidgetRepository) Delete(ctx context.Context, id uint64) error {
 	args := m.Called(ctx, id)
 	return args.Error(0)
 }
 
+func (m *MockDashboardWidgetRepository) DeleteIfVersion(ctx context.Context, id uint64, version int64) (bool, error) {
+	args := m.Called(ctx, id, version)
+	return args.Bool(0), args.Error(1)
+}
+
 func (m *MockDashboardWidgetRepository) DeleteByUserIDAndAppID(ctx context.Context, userID, appID uint64) error {
 	args := m.Called(ctx, userID, appID)
 	return args.Error(0)
@@ -544,3 +782,508 @@ func (m *MockDashboardWidgetRepository) Exists(ctx context.Context, userID, appI
 	args := m.Called(ctx, userID, appID)
 	return args.Bool(0), args.Error(1)
 }
+
+// MockGroupRepository GroupRepositoryInterfaceのモック実装
+type MockGroupRepository struct {
+	mock.Mock
+}
+
+func (m *MockGroupRepository) Create(ctx context.Context, group *models.Group) error {
+	args := m.Called(ctx, group)
+	return args.Error(0)
+}
+
+func (m *MockGroupRepository) GetByID(ctx context.Context, id uint64) (*models.Group, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Group), args.Error(1)
+}
+
+func (m *MockGroupRepository) GetAll(ctx context.Context) ([]models.Group, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.Group), args.Error(1)
+}
+
+func (m *MockGroupRepository) Update(ctx context.Context, group *models.Group) error {
+	args := m.Called(ctx, group)
+	return args.Error(0)
+}
+
+func (m *MockGroupRepository) Delete(ctx context.Context, id uint64) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockGroupRepository) NameExists(ctx context.Context, name string, excludeID uint64) (bool, error) {
+	args := m.Called(ctx, name, excludeID)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockGroupRepository) GetMembers(ctx context.Context, groupID uint64) ([]models.User, error) {
+	args := m.Called(ctx, groupID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.User), args.Error(1)
+}
+
+func (m *MockGroupRepository) AddMember(ctx context.Context, groupID, userID uint64) error {
+	args := m.Called(ctx, groupID, userID)
+	return args.Error(0)
+}
+
+func (m *MockGroupRepository) RemoveMember(ctx context.Context, groupID, userID uint64) error {
+	args := m.Called(ctx, groupID, userID)
+	return args.Error(0)
+}
+
+func (m *MockGroupRepository) GetGroupIDsByUserID(ctx context.Context, userID uint64) ([]uint64, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]uint64), args.Error(1)
+}
+
+// MockAppPermissionRepository AppPermissionRepositoryInterfaceのモック実装
+type MockAppPermissionRepository struct {
+	mock.Mock
+}
+
+func (m *MockAppPermissionRepository) Create(ctx context.Context, perm *models.AppPermission) error {
+	args := m.Called(ctx, perm)
+	return args.Error(0)
+}
+
+func (m *MockAppPermissionRepository) GetByID(ctx context.Context, id uint64) (*models.AppPermission, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.AppPermission), args.Error(1)
+}
+
+func (m *MockAppPermissionRepository) GetByAppID(ctx context.Context, appID uint64) ([]models.AppPermission, error) {
+	args := m.Called(ctx, appID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.AppPermission), args.Error(1)
+}
+
+func (m *MockAppPermissionRepository) GetForSubjects(ctx context.Context, appID, userID uint64, groupIDs []uint64) ([]models.AppPermission, error) {
+	args := m.Called(ctx, appID, userID, groupIDs)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.AppPermission), args.Error(1)
+}
+
+func (m *MockAppPermissionRepository) HasAny(ctx context.Context, appID uint64) (bool, error) {
+	args := m.Called(ctx, appID)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockAppPermissionRepository) SubjectExists(ctx context.Context, appID uint64, userID, groupID *uint64) (bool, error) {
+	args := m.Called(ctx, appID, userID, groupID)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockAppPermissionRepository) Update(ctx context.Context, perm *models.AppPermission) error {
+	args := m.Called(ctx, perm)
+	return args.Error(0)
+}
+
+func (m *MockAppPermissionRepository) Delete(ctx context.Context, id uint64) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+// MockRecordRevisionRepository RecordRevisionRepositoryInterfaceのモック実装
+type MockRecordRevisionRepository struct {
+	mock.Mock
+}
+
+func (m *MockRecordRevisionRepository) Create(ctx context.Context, revision *models.RecordRevision) error {
+	args := m.Called(ctx, revision)
+	return args.Error(0)
+}
+
+func (m *MockRecordRevisionRepository) CreateBatch(ctx context.Context, revisions []models.RecordRevision) error {
+	args := m.Called(ctx, revisions)
+	return args.Error(0)
+}
+
+func (m *MockRecordRevisionRepository) GetByID(ctx context.Context, id uint64) (*models.RecordRevision, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.RecordRevision), args.Error(1)
+}
+
+func (m *MockRecordRevisionRepository) GetByRecordID(ctx context.Context, appID, recordID uint64, page, limit int) ([]models.RecordRevision, int64, error) {
+	args := m.Called(ctx, appID, recordID, page, limit)
+	if args.Get(0) == nil {
+		return nil, args.Get(1).(int64), args.Error(2)
+	}
+	return args.Get(0).([]models.RecordRevision), args.Get(1).(int64), args.Error(2)
+}
+
+// MockWebhookRepository WebhookRepositoryInterfaceのモック実装
+type MockWebhookRepository struct {
+	mock.Mock
+}
+
+func (m *MockWebhookRepository) Create(ctx context.Context, webhook *models.Webhook) error {
+	args := m.Called(ctx, webhook)
+	return args.Error(0)
+}
+
+func (m *MockWebhookRepository) GetByID(ctx context.Context, id uint64) (*models.Webhook, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Webhook), args.Error(1)
+}
+
+func (m *MockWebhookRepository) GetByAppID(ctx context.Context, appID uint64) ([]models.Webhook, error) {
+	args := m.Called(ctx, appID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.Webhook), args.Error(1)
+}
+
+func (m *MockWebhookRepository) Update(ctx context.Context, webhook *models.Webhook) error {
+	args := m.Called(ctx, webhook)
+	return args.Error(0)
+}
+
+func (m *MockWebhookRepository) Delete(ctx context.Context, id uint64) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+// MockWebhookDeliveryRepository WebhookDeliveryRepositoryInterfaceのモック実装
+type MockWebhookDeliveryRepository struct {
+	mock.Mock
+}
+
+func (m *MockWebhookDeliveryRepository) CreateBatch(ctx context.Context, deliveries []models.WebhookDelivery) error {
+	args := m.Called(ctx, deliveries)
+	return args.Error(0)
+}
+
+func (m *MockWebhookDeliveryRepository) GetByWebhookID(ctx context.Context, webhookID uint64, page, limit int) ([]models.WebhookDelivery, int64, error) {
+	args := m.Called(ctx, webhookID, page, limit)
+	if args.Get(0) == nil {
+		return nil, args.Get(1).(int64), args.Error(2)
+	}
+	return args.Get(0).([]models.WebhookDelivery), args.Get(1).(int64), args.Error(2)
+}
+
+func (m *MockWebhookDeliveryRepository) ClaimDue(ctx context.Context, now time.Time, limit int, lease time.Duration) ([]models.WebhookDelivery, error) {
+	args := m.Called(ctx, now, limit, lease)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.WebhookDelivery), args.Error(1)
+}
+
+func (m *MockWebhookDeliveryRepository) UpdateResult(ctx context.Context, delivery *models.WebhookDelivery) error {
+	args := m.Called(ctx, delivery)
+	return args.Error(0)
+}
+
+// MockAutomationRuleRepository AutomationRuleRepositoryInterfaceのモック実装
+type MockAutomationRuleRepository struct {
+	mock.Mock
+}
+
+func (m *MockAutomationRuleRepository) Create(ctx context.Context, rule *models.AutomationRule) error {
+	args := m.Called(ctx, rule)
+	return args.Error(0)
+}
+
+func (m *MockAutomationRuleRepository) GetByID(ctx context.Context, id uint64) (*models.AutomationRule, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.AutomationRule), args.Error(1)
+}
+
+func (m *MockAutomationRuleRepository) GetByAppID(ctx context.Context, appID uint64) ([]models.AutomationRule, error) {
+	args := m.Called(ctx, appID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.AutomationRule), args.Error(1)
+}
+
+func (m *MockAutomationRuleRepository) Update(ctx context.Context, rule *models.AutomationRule) error {
+	args := m.Called(ctx, rule)
+	return args.Error(0)
+}
+
+func (m *MockAutomationRuleRepository) Delete(ctx context.Context, id uint64) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockAutomationRuleRepository) GetDue(ctx context.Context, now time.Time, limit int) ([]models.AutomationRule, error) {
+	args := m.Called(ctx, now, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.AutomationRule), args.Error(1)
+}
+
+func (m *MockAutomationRuleRepository) UpdateSchedule(ctx context.Context, rule *models.AutomationRule) error {
+	args := m.Called(ctx, rule)
+	return args.Error(0)
+}
+
+// MockAutomationRunRepository AutomationRunRepositoryInterfaceのモック実装
+type MockAutomationRunRepository struct {
+	mock.Mock
+}
+
+func (m *MockAutomationRunRepository) Create(ctx context.Context, run *models.AutomationRun) error {
+	args := m.Called(ctx, run)
+	return args.Error(0)
+}
+
+func (m *MockAutomationRunRepository) GetByRuleID(ctx context.Context, ruleID uint64, page, limit int) ([]models.AutomationRun, int64, error) {
+	args := m.Called(ctx, ruleID, page, limit)
+	if args.Get(0) == nil {
+		return nil, args.Get(1).(int64), args.Error(2)
+	}
+	return args.Get(0).([]models.AutomationRun), args.Get(1).(int64), args.Error(2)
+}
+
+// MockAdvisoryLocker AdvisoryLockerInterfaceのモック実装
+// ロックを取得できたことを返すよう設定した場合はfnを実行する
+type MockAdvisoryLocker struct {
+	mock.Mock
+}
+
+func (m *MockAdvisoryLocker) TryWithLock(ctx context.Context, key int64, fn func(ctx context.Context) error) (bool, error) {
+	args := m.Called(ctx, key)
+	if !args.Bool(0) || args.Error(1) != nil {
+		return args.Bool(0), args.Error(1)
+	}
+	return true, fn(ctx)
+}
+
+// MockTransactor TransactorInterfaceのモック実装
+// エラーを返すよう設定しなかった場合はfnを実行する
+type MockTransactor struct {
+	mock.Mock
+}
+
+func (m *MockTransactor) RunInTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	args := m.Called(ctx)
+	if err := args.Error(0); err != nil {
+		return err
+	}
+	return fn(ctx)
+}
+
+// MockAttachmentRepository AttachmentRepositoryInterfaceのモック実装
+type MockAttachmentRepository struct {
+	mock.Mock
+}
+
+func (m *MockAttachmentRepository) Create(ctx context.Context, attachment *models.Attachment) error {
+	args := m.Called(ctx, attachment)
+	return args.Error(0)
+}
+
+func (m *MockAttachmentRepository) GetByID(ctx context.Context, id uint64) (*models.Attachment, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Attachment), args.Error(1)
+}
+
+func (m *MockAttachmentRepository) GetByIDs(ctx context.Context, ids []uint64) ([]models.Attachment, error) {
+	args := m.Called(ctx, ids)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.Attachment), args.Error(1)
+}
+
+func (m *MockAttachmentRepository) SyncRecord(ctx context.Context, appID, recordID uint64, fieldCode string, ids []uint64, now time.Time) error {
+	args := m.Called(ctx, appID, recordID, fieldCode, ids, now)
+	return args.Error(0)
+}
+
+func (m *MockAttachmentRepository) OrphanByRecords(ctx context.Context, appID uint64, recordIDs []uint64, now time.Time) error {
+	args := m.Called(ctx, appID, recordIDs, now)
+	return args.Error(0)
+}
+
+func (m *MockAttachmentRepository) OrphanByField(ctx context.Context, appID uint64, fieldCode string, now time.Time) error {
+	args := m.Called(ctx, appID, fieldCode, now)
+	return args.Error(0)
+}
+
+func (m *MockAttachmentRepository) OrphanByApp(ctx context.Context, appID uint64, now time.Time) error {
+	args := m.Called(ctx, appID, now)
+	return args.Error(0)
+}
+
+func (m *MockAttachmentRepository) GetPurgeable(ctx context.Context, pendingBefore time.Time, limit int) ([]models.Attachment, error) {
+	args := m.Called(ctx, pendingBefore, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.Attachment), args.Error(1)
+}
+
+func (m *MockAttachmentRepository) DeletePurgeable(ctx context.Context, id uint64, pendingBefore time.Time) (bool, error) {
+	args := m.Called(ctx, id, pendingBefore)
+	return args.Bool(0), args.Error(1)
+}
+
+// MockAppIndexRepository AppIndexRepositoryInterfaceのモック実装
+type MockAppIndexRepository struct {
+	mock.Mock
+}
+
+func (m *MockAppIndexRepository) Create(ctx context.Context, index *models.AppIndex) error {
+	args := m.Called(ctx, index)
+	return args.Error(0)
+}
+
+func (m *MockAppIndexRepository) GetByID(ctx context.Context, id uint64) (*models.AppIndex, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.AppIndex), args.Error(1)
+}
+
+func (m *MockAppIndexRepository) GetByAppID(ctx context.Context, appID uint64) ([]models.AppIndex, error) {
+	args := m.Called(ctx, appID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.AppIndex), args.Error(1)
+}
+
+func (m *MockAppIndexRepository) Delete(ctx context.Context, id uint64) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+// MockDeletedRecordRepository DeletedRecordRepositoryInterfaceのモック実装
+type MockDeletedRecordRepository struct {
+	mock.Mock
+}
+
+func (m *MockDeletedRecordRepository) GetByAppID(ctx context.Context, appID, createdBy uint64, page, limit int) ([]models.DeletedRecord, int64, error) {
+	args := m.Called(ctx, appID, createdBy, page, limit)
+	if args.Get(0) == nil {
+		return nil, args.Get(1).(int64), args.Error(2)
+	}
+	return args.Get(0).([]models.DeletedRecord), args.Get(1).(int64), args.Error(2)
+}
+
+func (m *MockDeletedRecordRepository) GetByRecordID(ctx context.Context, appID, recordID uint64) (*models.DeletedRecord, error) {
+	args := m.Called(ctx, appID, recordID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.DeletedRecord), args.Error(1)
+}
+
+func (m *MockDeletedRecordRepository) GetBefore(ctx context.Context, before time.Time, limit int) ([]models.DeletedRecord, error) {
+	args := m.Called(ctx, before, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.DeletedRecord), args.Error(1)
+}
+
+func (m *MockDeletedRecordRepository) Delete(ctx context.Context, id uint64) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+// MockSavedReportRepository SavedReportRepositoryInterfaceのモック実装
+type MockSavedReportRepository struct {
+	mock.Mock
+}
+
+func (m *MockSavedReportRepository) Create(ctx context.Context, report *models.SavedReport) error {
+	args := m.Called(ctx, report)
+	return args.Error(0)
+}
+
+func (m *MockSavedReportRepository) GetByID(ctx context.Context, id uint64) (*models.SavedReport, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.SavedReport), args.Error(1)
+}
+
+func (m *MockSavedReportRepository) GetByAppID(ctx context.Context, appID uint64) ([]models.SavedReport, error) {
+	args := m.Called(ctx, appID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.SavedReport), args.Error(1)
+}
+
+func (m *MockSavedReportRepository) Update(ctx context.Context, report *models.SavedReport) error {
+	args := m.Called(ctx, report)
+	return args.Error(0)
+}
+
+func (m *MockSavedReportRepository) Delete(ctx context.Context, id uint64) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockSavedReportRepository) GetDue(ctx context.Context, now time.Time, limit int) ([]models.SavedReport, error) {
+	args := m.Called(ctx, now, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.SavedReport), args.Error(1)
+}
+
+func (m *MockSavedReportRepository) UpdateSchedule(ctx context.Context, report *models.SavedReport) error {
+	args := m.Called(ctx, report)
+	return args.Error(0)
+}
+
+// MockReportDeliveryRepository ReportDeliveryRepositoryInterfaceのモック実装
+type MockReportDeliveryRepository struct {
+	mock.Mock
+}
+
+func (m *MockReportDeliveryRepository) Create(ctx context.Context, delivery *models.ReportDelivery) error {
+	args := m.Called(ctx, delivery)
+	return args.Error(0)
+}
+
+func (m *MockReportDeliveryRepository) GetByReportID(ctx context.Context, reportID uint64, page, limit int) ([]models.ReportDelivery, int64, error) {
+	args := m.Called(ctx, reportID, page, limit)
+	if args.Get(0) == nil {
+		return nil, args.Get(1).(int64), args.Error(2)
+	}
+	return args.Get(0).([]models.ReportDelivery), args.Get(1).(int64), args.Error(2)
+}
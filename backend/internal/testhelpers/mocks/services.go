@@ -3,10 +3,12 @@ package mocks
 
 import (
 	"context"
+	"io"
 
 	"github.com/stretchr/testify/mock"
 
 	"nocode-app/backend/internal/models"
+	"nocode-app/backend/internal/realtime"
 	"nocode-app/backend/internal/repositories"
 	"nocode-app/backend/internal/utils"
 )
@@ -116,16 +118,16 @@ func (m *MockFieldService) CreateField(ctx context.Context, appID uint64, req *m
 	return args.Get(0).(*models.FieldResponse), args.Error(1)
 }
 
-func (m *MockFieldService) UpdateField(ctx context.Context, fieldID uint64, req *models.UpdateFieldRequest) (*models.FieldResponse, error) {
-	args := m.Called(ctx, fieldID, req)
+func (m *MockFieldService) UpdateField(ctx context.Context, fieldID uint64, version int64, req *models.UpdateFieldRequest) (*models.FieldResponse, error) {
+	args := m.Called(ctx, fieldID, version, req)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
 	return args.Get(0).(*models.FieldResponse), args.Error(1)
 }
 
-func (m *MockFieldService) DeleteField(ctx context.Context, appID, fieldID uint64) error {
-	args := m.Called(ctx, appID, fieldID)
+func (m *MockFieldService) DeleteField(ctx context.Context, appID, fieldID uint64, version int64) error {
+	args := m.Called(ctx, appID, fieldID, version)
 	return args.Error(0)
 }
 
@@ -134,6 +136,22 @@ func (m *MockFieldService) UpdateFieldOrder(ctx context.Context, appID uint64, r
 	return args.Error(0)
 }
 
+func (m *MockFieldService) PreviewFieldConversion(ctx context.Context, appID, fieldID uint64, req *models.ConvertFieldRequest) (*models.FieldConversionPreview, error) {
+	args := m.Called(ctx, appID, fieldID, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.FieldConversionPreview), args.Error(1)
+}
+
+func (m *MockFieldService) ConvertField(ctx context.Context, appID, fieldID uint64, req *models.ConvertFieldRequest) (*models.ConvertFieldResponse, error) {
+	args := m.Called(ctx, appID, fieldID, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.ConvertFieldResponse), args.Error(1)
+}
+
 // MockRecordService RecordServiceInterfaceのモック実装
 type MockRecordService struct {
 	mock.Mock
@@ -163,16 +181,16 @@ func (m *MockRecordService) CreateRecord(ctx context.Context, appID, userID uint
 	return args.Get(0).(*models.RecordResponse), args.Error(1)
 }
 
-func (m *MockRecordService) UpdateRecord(ctx context.Context, appID, recordID uint64, req *models.UpdateRecordRequest) (*models.RecordResponse, error) {
-	args := m.Called(ctx, appID, recordID, req)
+func (m *MockRecordService) UpdateRecord(ctx context.Context, appID, recordID uint64, version int64, req *models.UpdateRecordRequest) (*models.RecordResponse, error) {
+	args := m.Called(ctx, appID, recordID, version, req)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
 	return args.Get(0).(*models.RecordResponse), args.Error(1)
 }
 
-func (m *MockRecordService) DeleteRecord(ctx context.Context, appID, recordID uint64) error {
-	args := m.Called(ctx, appID, recordID)
+func (m *MockRecordService) DeleteRecord(ctx context.Context, appID, recordID uint64, version int64) error {
+	args := m.Called(ctx, appID, recordID, version)
 	return args.Error(0)
 }
 
@@ -189,6 +207,35 @@ func (m *MockRecordService) BulkDeleteRecords(ctx context.Context, appID uint64,
 	return args.Error(0)
 }
 
+func (m *MockRecordService) GetRecordHistory(ctx context.Context, appID, recordID uint64, page, limit int) (*models.RecordHistoryResponse, error) {
+	args := m.Called(ctx, appID, recordID, page, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.RecordHistoryResponse), args.Error(1)
+}
+
+func (m *MockRecordService) RevertRecord(ctx context.Context, appID, recordID, revisionID uint64, version int64) (*models.RecordResponse, error) {
+	args := m.Called(ctx, appID, recordID, revisionID, version)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.RecordResponse), args.Error(1)
+}
+
+func (m *MockRecordService) ImportRecords(ctx context.Context, appID, userID uint64, req *models.ImportRecordsRequest) (*models.ImportResult, error) {
+	args := m.Called(ctx, appID, userID, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.ImportResult), args.Error(1)
+}
+
+func (m *MockRecordService) ExportRecords(ctx context.Context, appID uint64, opts repositories.RecordQueryOptions, format models.ExportFormat, w io.Writer) error {
+	args := m.Called(ctx, appID, opts, format, w)
+	return args.Error(0)
+}
+
 // MockViewService ViewServiceInterfaceのモック実装
 type MockViewService struct {
 	mock.Mock
@@ -210,16 +257,16 @@ func (m *MockViewService) CreateView(ctx context.Context, appID uint64, req *mod
 	return args.Get(0).(*models.ViewResponse), args.Error(1)
 }
 
-func (m *MockViewService) UpdateView(ctx context.Context, viewID uint64, req *models.UpdateViewRequest) (*models.ViewResponse, error) {
-	args := m.Called(ctx, viewID, req)
+func (m *MockViewService) UpdateView(ctx context.Context, viewID uint64, version int64, req *models.UpdateViewRequest) (*models.ViewResponse, error) {
+	args := m.Called(ctx, viewID, version, req)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
 	return args.Get(0).(*models.ViewResponse), args.Error(1)
 }
 
-func (m *MockViewService) DeleteView(ctx context.Context, viewID uint64) error {
-	args := m.Called(ctx, viewID)
+func (m *MockViewService) DeleteView(ctx context.Context, viewID uint64, version int64) error {
+	args := m.Called(ctx, viewID, version)
 	return args.Error(0)
 }
 
@@ -257,6 +304,111 @@ func (m *MockChartService) DeleteChartConfig(ctx context.Context, configID uint6
 	return args.Error(0)
 }
 
+// MockReportService ReportServiceInterfaceのモック実装
+type MockReportService struct {
+	mock.Mock
+}
+
+func (m *MockReportService) GetPivot(ctx context.Context, appID uint64, req *models.PivotRequest) (*models.PivotResponse, error) {
+	args := m.Called(ctx, appID, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.PivotResponse), args.Error(1)
+}
+
+func (m *MockReportService) ExportPivot(ctx context.Context, appID uint64, req *models.PivotRequest, format models.ExportFormat, w io.Writer) error {
+	args := m.Called(ctx, appID, req, format, w)
+	return args.Error(0)
+}
+
+// MockSavedReportService SavedReportServiceInterfaceのモック実装
+type MockSavedReportService struct {
+	mock.Mock
+}
+
+func (m *MockSavedReportService) GetReports(ctx context.Context, appID uint64) (*models.SavedReportListResponse, error) {
+	args := m.Called(ctx, appID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.SavedReportListResponse), args.Error(1)
+}
+
+func (m *MockSavedReportService) GetReport(ctx context.Context, appID, reportID uint64) (*models.SavedReport, error) {
+	args := m.Called(ctx, appID, reportID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.SavedReport), args.Error(1)
+}
+
+func (m *MockSavedReportService) CreateReport(ctx context.Context, appID, userID uint64, req *models.SaveReportRequest) (*models.SavedReport, error) {
+	args := m.Called(ctx, appID, userID, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.SavedReport), args.Error(1)
+}
+
+func (m *MockSavedReportService) UpdateReport(ctx context.Context, appID, reportID uint64, req *models.SaveReportRequest) (*models.SavedReport, error) {
+	args := m.Called(ctx, appID, reportID, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.SavedReport), args.Error(1)
+}
+
+func (m *MockSavedReportService) DeleteReport(ctx context.Context, appID, reportID uint64) error {
+	args := m.Called(ctx, appID, reportID)
+	return args.Error(0)
+}
+
+func (m *MockSavedReportService) SendReport(ctx context.Context, appID, reportID uint64) (*models.ReportDelivery, error) {
+	args := m.Called(ctx, appID, reportID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.ReportDelivery), args.Error(1)
+}
+
+func (m *MockSavedReportService) GetDeliveries(ctx context.Context, appID, reportID uint64, page, limit int) (*models.ReportDeliveryListResponse, error) {
+	args := m.Called(ctx, appID, reportID, page, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.ReportDeliveryListResponse), args.Error(1)
+}
+
+// MockRealtimeService RealtimeServiceInterfaceのモック実装
+type MockRealtimeService struct {
+	mock.Mock
+}
+
+func (m *MockRealtimeService) Authorize(ctx context.Context, appID uint64) (*models.AppAccess, error) {
+	args := m.Called(ctx, appID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.AppAccess), args.Error(1)
+}
+
+func (m *MockRealtimeService) Subscribe(ctx context.Context, appID uint64) (*models.AppAccess, *realtime.Subscription, error) {
+	args := m.Called(ctx, appID)
+	if args.Get(0) == nil {
+		return nil, nil, args.Error(2)
+	}
+	return args.Get(0).(*models.AppAccess), args.Get(1).(*realtime.Subscription), args.Error(2)
+}
+
+func (m *MockRealtimeService) IssueToken(ctx context.Context, appID uint64) (*models.RealtimeTokenResponse, error) {
+	args := m.Called(ctx, appID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.RealtimeTokenResponse), args.Error(1)
+}
+
 // MockUserService UserServiceInterfaceのモック実装
 type MockUserService struct {
 	mock.Mock
@@ -425,16 +577,16 @@ func (m *MockDashboardWidgetService) CreateWidget(ctx context.Context, userID ui
 	return args.Get(0).(*models.DashboardWidgetResponse), args.Error(1)
 }
 
-func (m *MockDashboardWidgetService) UpdateWidget(ctx context.Context, userID, widgetID uint64, req *models.UpdateDashboardWidgetRequest) (*models.DashboardWidgetResponse, error) {
-	args := m.Called(ctx, userID, widgetID, req)
+func (m *MockDashboardWidgetService) UpdateWidget(ctx context.Context, userID, widgetID uint64, version int64, req *models.UpdateDashboardWidgetRequest) (*models.DashboardWidgetResponse, error) {
+	args := m.Called(ctx, userID, widgetID, version, req)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
 	return args.Get(0).(*models.DashboardWidgetResponse), args.Error(1)
 }
 
-func (m *MockDashboardWidgetService) DeleteWidget(ctx context.Context, userID, widgetID uint64) error {
-	args := m.Called(ctx, userID, widgetID)
+func (m *MockDashboardWidgetService) DeleteWidget(ctx context.Context, userID, widgetID uint64, version int64) error {
+	args := m.Called(ctx, userID, widgetID, version)
 	return args.Error(0)
 }
 
@@ -450,3 +602,423 @@ func (m *MockDashboardWidgetService) ToggleVisibility(ctx context.Context, userI
 	}
 	return args.Get(0).(*models.DashboardWidgetResponse), args.Error(1)
 }
+
+// MockPermissionService PermissionServiceInterfaceのモック実装
+type MockPermissionService struct {
+	mock.Mock
+}
+
+func (m *MockPermissionService) AuthorizeApp(ctx context.Context, appID uint64, required models.AppRole) (*models.App, *models.AppAccess, error) {
+	args := m.Called(ctx, appID, required)
+	if args.Get(0) == nil {
+		return nil, nil, args.Error(2)
+	}
+	return args.Get(0).(*models.App), args.Get(1).(*models.AppAccess), args.Error(2)
+}
+
+func (m *MockPermissionService) CheckAppAccess(ctx context.Context, app *models.App, required models.AppRole) (*models.AppAccess, error) {
+	args := m.Called(ctx, app, required)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.AppAccess), args.Error(1)
+}
+
+func (m *MockPermissionService) GetPermissions(ctx context.Context, appID uint64) (*models.AppPermissionListResponse, error) {
+	args := m.Called(ctx, appID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.AppPermissionListResponse), args.Error(1)
+}
+
+func (m *MockPermissionService) GrantPermission(ctx context.Context, appID uint64, req *models.CreateAppPermissionRequest) (*models.AppPermissionResponse, error) {
+	args := m.Called(ctx, appID, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.AppPermissionResponse), args.Error(1)
+}
+
+func (m *MockPermissionService) UpdatePermission(ctx context.Context, appID, permID uint64, req *models.UpdateAppPermissionRequest) (*models.AppPermissionResponse, error) {
+	args := m.Called(ctx, appID, permID, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.AppPermissionResponse), args.Error(1)
+}
+
+func (m *MockPermissionService) RevokePermission(ctx context.Context, appID, permID uint64) error {
+	args := m.Called(ctx, appID, permID)
+	return args.Error(0)
+}
+
+// MockGroupService GroupServiceInterfaceのモック実装
+type MockGroupService struct {
+	mock.Mock
+}
+
+func (m *MockGroupService) GetGroups(ctx context.Context) (*models.GroupListResponse, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.GroupListResponse), args.Error(1)
+}
+
+func (m *MockGroupService) GetGroup(ctx context.Context, groupID uint64) (*models.GroupResponse, error) {
+	args := m.Called(ctx, groupID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.GroupResponse), args.Error(1)
+}
+
+func (m *MockGroupService) CreateGroup(ctx context.Context, req *models.CreateGroupRequest) (*models.GroupResponse, error) {
+	args := m.Called(ctx, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.GroupResponse), args.Error(1)
+}
+
+func (m *MockGroupService) UpdateGroup(ctx context.Context, groupID uint64, req *models.UpdateGroupRequest) (*models.GroupResponse, error) {
+	args := m.Called(ctx, groupID, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.GroupResponse), args.Error(1)
+}
+
+func (m *MockGroupService) DeleteGroup(ctx context.Context, groupID uint64) error {
+	args := m.Called(ctx, groupID)
+	return args.Error(0)
+}
+
+func (m *MockGroupService) AddMember(ctx context.Context, groupID uint64, req *models.AddGroupMemberRequest) (*models.GroupResponse, error) {
+	args := m.Called(ctx, groupID, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.GroupResponse), args.Error(1)
+}
+
+func (m *MockGroupService) RemoveMember(ctx context.Context, groupID, userID uint64) error {
+	args := m.Called(ctx, groupID, userID)
+	return args.Error(0)
+}
+
+// MockWebhookService WebhookServiceInterfaceのモック実装
+type MockWebhookService struct {
+	mock.Mock
+}
+
+func (m *MockWebhookService) GetWebhooks(ctx context.Context, appID uint64) (*models.WebhookListResponse, error) {
+	args := m.Called(ctx, appID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.WebhookListResponse), args.Error(1)
+}
+
+func (m *MockWebhookService) CreateWebhook(ctx context.Context, appID, userID uint64, req *models.CreateWebhookRequest) (*models.WebhookResponse, error) {
+	args := m.Called(ctx, appID, userID, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.WebhookResponse), args.Error(1)
+}
+
+func (m *MockWebhookService) UpdateWebhook(ctx context.Context, appID, webhookID uint64, req *models.UpdateWebhookRequest) (*models.WebhookResponse, error) {
+	args := m.Called(ctx, appID, webhookID, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.WebhookResponse), args.Error(1)
+}
+
+func (m *MockWebhookService) DeleteWebhook(ctx context.Context, appID, webhookID uint64) error {
+	args := m.Called(ctx, appID, webhookID)
+	return args.Error(0)
+}
+
+func (m *MockWebhookService) GetDeliveries(ctx context.Context, appID, webhookID uint64, page, limit int) (*models.WebhookDeliveryListResponse, error) {
+	args := m.Called(ctx, appID, webhookID, page, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.WebhookDeliveryListResponse), args.Error(1)
+}
+
+// MockWebhookPublisher WebhookPublisherInterfaceのモック実装
+type MockWebhookPublisher struct {
+	mock.Mock
+}
+
+func (m *MockWebhookPublisher) Publish(ctx context.Context, events ...models.WebhookEvent) error {
+	args := m.Called(ctx, events)
+	return args.Error(0)
+}
+
+func (m *MockWebhookPublisher) PublishTo(ctx context.Context, webhookID uint64, event models.WebhookEvent) error {
+	args := m.Called(ctx, webhookID, event)
+	return args.Error(0)
+}
+
+// MockAutomationService AutomationServiceInterfaceのモック実装
+type MockAutomationService struct {
+	mock.Mock
+}
+
+func (m *MockAutomationService) GetRules(ctx context.Context, appID uint64) (*models.AutomationRuleListResponse, error) {
+	args := m.Called(ctx, appID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.AutomationRuleListResponse), args.Error(1)
+}
+
+func (m *MockAutomationService) CreateRule(ctx context.Context, appID, userID uint64, req *models.CreateAutomationRuleRequest) (*models.AutomationRule, error) {
+	args := m.Called(ctx, appID, userID, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.AutomationRule), args.Error(1)
+}
+
+func (m *MockAutomationService) UpdateRule(ctx context.Context, appID, ruleID uint64, req *models.UpdateAutomationRuleRequest) (*models.AutomationRule, error) {
+	args := m.Called(ctx, appID, ruleID, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.AutomationRule), args.Error(1)
+}
+
+func (m *MockAutomationService) DeleteRule(ctx context.Context, appID, ruleID uint64) error {
+	args := m.Called(ctx, appID, ruleID)
+	return args.Error(0)
+}
+
+func (m *MockAutomationService) GetRuns(ctx context.Context, appID, ruleID uint64, page, limit int) (*models.AutomationRunListResponse, error) {
+	args := m.Called(ctx, appID, ruleID, page, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.AutomationRunListResponse), args.Error(1)
+}
+
+// MockAutomationRunner AutomationRunnerInterfaceのモック実装
+type MockAutomationRunner struct {
+	mock.Mock
+}
+
+func (m *MockAutomationRunner) Run(ctx context.Context, revisions ...models.RecordRevision) error {
+	args := m.Called(ctx, revisions)
+	return args.Error(0)
+}
+
+// MockAttachmentService AttachmentServiceInterfaceのモック実装
+type MockAttachmentService struct {
+	mock.Mock
+}
+
+func (m *MockAttachmentService) Upload(ctx context.Context, appID, userID uint64, req *models.UploadAttachmentRequest) (*models.AttachmentValue, error) {
+	args := m.Called(ctx, appID, userID, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.AttachmentValue), args.Error(1)
+}
+
+func (m *MockAttachmentService) GetAttachmentURL(ctx context.Context, appID, attachmentID uint64) (*models.AttachmentURLResponse, error) {
+	args := m.Called(ctx, appID, attachmentID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.AttachmentURLResponse), args.Error(1)
+}
+
+// MockAttachmentManager AttachmentManagerInterfaceのモック実装
+type MockAttachmentManager struct {
+	mock.Mock
+}
+
+func (m *MockAttachmentManager) ResolveValues(ctx context.Context, appID, recordID, userID uint64, fields []models.AppField, rows []models.RecordData) (map[int]models.FieldErrors, error) {
+	args := m.Called(ctx, appID, recordID, userID, fields, rows)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[int]models.FieldErrors), args.Error(1)
+}
+
+func (m *MockAttachmentManager) AttachRecord(ctx context.Context, appID, recordID uint64, fields []models.AppField, data models.RecordData) error {
+	args := m.Called(ctx, appID, recordID, fields, data)
+	return args.Error(0)
+}
+
+func (m *MockAttachmentManager) ReleaseRecords(ctx context.Context, appID uint64, recordIDs []uint64) error {
+	args := m.Called(ctx, appID, recordIDs)
+	return args.Error(0)
+}
+
+func (m *MockAttachmentManager) ReleaseField(ctx context.Context, appID uint64, fieldCode string) error {
+	args := m.Called(ctx, appID, fieldCode)
+	return args.Error(0)
+}
+
+func (m *MockAttachmentManager) ReleaseApp(ctx context.Context, appID uint64) error {
+	args := m.Called(ctx, appID)
+	return args.Error(0)
+}
+
+// MockGlobalSearchService GlobalSearchServiceInterfaceのモック実装
+type MockGlobalSearchService struct {
+	mock.Mock
+}
+
+func (m *MockGlobalSearchService) Search(ctx context.Context, query string, limit int) (*models.GlobalSearchResponse, error) {
+	args := m.Called(ctx, query, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.GlobalSearchResponse), args.Error(1)
+}
+
+// MockIndexService IndexServiceInterfaceのモック実装
+type MockIndexService struct {
+	mock.Mock
+}
+
+func (m *MockIndexService) GetIndexes(ctx context.Context, appID uint64) ([]models.IndexResponse, error) {
+	args := m.Called(ctx, appID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.IndexResponse), args.Error(1)
+}
+
+func (m *MockIndexService) CreateIndex(ctx context.Context, appID, userID uint64, req *models.CreateIndexRequest) (*models.IndexResponse, error) {
+	args := m.Called(ctx, appID, userID, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.IndexResponse), args.Error(1)
+}
+
+func (m *MockIndexService) DeleteIndex(ctx context.Context, appID, indexID uint64) error {
+	args := m.Called(ctx, appID, indexID)
+	return args.Error(0)
+}
+
+func (m *MockIndexService) SuggestIndexes(ctx context.Context, appID uint64) ([]models.IndexSuggestion, error) {
+	args := m.Called(ctx, appID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.IndexSuggestion), args.Error(1)
+}
+
+// MockTrashService TrashServiceInterfaceのモック実装
+type MockTrashService struct {
+	mock.Mock
+}
+
+func (m *MockTrashService) GetAppTrash(ctx context.Context, appID uint64, page, limit int) (*models.AppTrashResponse, error) {
+	args := m.Called(ctx, appID, page, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.AppTrashResponse), args.Error(1)
+}
+
+func (m *MockTrashService) RestoreRecord(ctx context.Context, appID, recordID uint64) (*models.RecordResponse, error) {
+	args := m.Called(ctx, appID, recordID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.RecordResponse), args.Error(1)
+}
+
+func (m *MockTrashService) PurgeRecord(ctx context.Context, appID, recordID uint64) error {
+	args := m.Called(ctx, appID, recordID)
+	return args.Error(0)
+}
+
+func (m *MockTrashService) RestoreField(ctx context.Context, appID, fieldID uint64) (*models.FieldResponse, error) {
+	args := m.Called(ctx, appID, fieldID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.FieldResponse), args.Error(1)
+}
+
+func (m *MockTrashService) PurgeField(ctx context.Context, appID, fieldID uint64) error {
+	args := m.Called(ctx, appID, fieldID)
+	return args.Error(0)
+}
+
+func (m *MockTrashService) GetTrashedApps(ctx context.Context) (*models.TrashedAppListResponse, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.TrashedAppListResponse), args.Error(1)
+}
+
+func (m *MockTrashService) RestoreApp(ctx context.Context, appID uint64) (*models.AppResponse, error) {
+	args := m.Called(ctx, appID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.AppResponse), args.Error(1)
+}
+
+func (m *MockTrashService) PurgeApp(ctx context.Context, appID uint64) error {
+	args := m.Called(ctx, appID)
+	return args.Error(0)
+}
+
+// MockAppPackageService AppPackageServiceInterfaceのモック実装
+type MockAppPackageService struct {
+	mock.Mock
+}
+
+func (m *MockAppPackageService) ExportApp(ctx context.Context, appID uint64, includeRecords bool) (*models.AppPackage, error) {
+	args := m.Called(ctx, appID, includeRecords)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.AppPackage), args.Error(1)
+}
+
+func (m *MockAppPackageService) ImportApp(ctx context.Context, userID uint64, req *models.ImportAppRequest) (*models.ImportAppResponse, error) {
+	args := m.Called(ctx, userID, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.ImportAppResponse), args.Error(1)
+}
+
+func (m *MockAppPackageService) GetTemplates(ctx context.Context) (*models.AppTemplateListResponse, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.AppTemplateListResponse), args.Error(1)
+}
+
+func (m *MockAppPackageService) GetTemplate(ctx context.Context, templateID string) (*models.AppTemplate, error) {
+	args := m.Called(ctx, templateID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.AppTemplate), args.Error(1)
+}
+
+func (m *MockAppPackageService) CreateAppFromTemplate(ctx context.Context, userID uint64, templateID string, req *models.CreateAppFromTemplateRequest) (*models.ImportAppResponse, error) {
+	args := m.Called(ctx, userID, templateID, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.ImportAppResponse), args.Error(1)
+}
@@ -0,0 +1,39 @@
+// Package mocks テスト用のモック実装を提供
+package mocks
+
+import (
+	"context"
+	"io"
+
+	"github.com/stretchr/testify/mock"
+
+	"nocode-app/backend/internal/storage"
+)
+
+// MockFileStorage FileStorageのモック実装
+type MockFileStorage struct {
+	mock.Mock
+}
+
+func (m *MockFileStorage) Put(ctx context.Context, key string, body io.Reader, size int64, contentType string) error {
+	args := m.Called(ctx, key, body, size, contentType)
+	return args.Error(0)
+}
+
+func (m *MockFileStorage) Open(ctx context.Context, key string) (io.ReadCloser, error) {
+	args := m.Called(ctx, key)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(io.ReadCloser), args.Error(1)
+}
+
+func (m *MockFileStorage) Delete(ctx context.Context, key string) error {
+	args := m.Called(ctx, key)
+	return args.Error(0)
+}
+
+func (m *MockFileStorage) SignedURL(ctx context.Context, key string, opts storage.URLOptions) (string, error) {
+	args := m.Called(ctx, key, opts)
+	return args.String(0), args.Error(1)
+}
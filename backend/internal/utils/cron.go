@@ -0,0 +1,185 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// cronSearchLimit 次の実行時刻を探す範囲（この期間に一致する時刻がない指定は不正とする）
+const cronSearchLimit = 5 * 366 * 24 * time.Hour
+
+// cronMacros よく使う指定の別名
+var cronMacros = map[string]string{
+	"@yearly":  "0 0 1 1 *",
+	"@monthly": "0 0 1 * *",
+	"@weekly":  "0 0 * * 0",
+	"@daily":   "0 0 * * *",
+	"@hourly":  "0 * * * *",
+}
+
+// cronMonthNames 月フィールドに使える名前
+var cronMonthNames = map[string]int{
+	"jan": 1, "feb": 2, "mar": 3, "apr": 4, "may": 5, "jun": 6,
+	"jul": 7, "aug": 8, "sep": 9, "oct": 10, "nov": 11, "dec": 12,
+}
+
+// cronWeekdayNames 曜日フィールドに使える名前
+var cronWeekdayNames = map[string]int{
+	"sun": 0, "mon": 1, "tue": 2, "wed": 3, "thu": 4, "fri": 5, "sat": 6,
+}
+
+// CronSchedule 5フィールド（分 時 日 月 曜日）のcron形式の実行スケジュールを表す構造体
+type CronSchedule struct {
+	minute, hour, day, month, weekday uint64
+	// 日と曜日の両方を指定した場合はいずれかに一致すれば実行する（cronの慣例）
+	dayRestricted, weekdayRestricted bool
+}
+
+// ParseCron cron形式の文字列を解析する
+// 各フィールドは "*"・数値・範囲（1-5）・リスト（1,3,5）・間隔（*/15、1-30/5）を指定でき、
+// 月と曜日は英語の略称（jan、mon など）も使える。曜日の0と7は日曜日を表す
+func ParseCron(spec string) (*CronSchedule, error) {
+	spec = strings.TrimSpace(spec)
+	if macro, ok := cronMacros[strings.ToLower(spec)]; ok {
+		spec = macro
+	}
+
+	parts := strings.Fields(spec)
+	if len(parts) != 5 {
+		return nil, errors.New("cron形式は「分 時 日 月 曜日」の5つのフィールドで指定してください")
+	}
+
+	var s CronSchedule
+	var err error
+	if s.minute, err = parseCronField(parts[0], 0, 59, nil); err != nil {
+		return nil, fmt.Errorf("分の指定が不正です: %w", err)
+	}
+	if s.hour, err = parseCronField(parts[1], 0, 23, nil); err != nil {
+		return nil, fmt.Errorf("時の指定が不正です: %w", err)
+	}
+	if s.day, err = parseCronField(parts[2], 1, 31, nil); err != nil {
+		return nil, fmt.Errorf("日の指定が不正です: %w", err)
+	}
+	if s.month, err = parseCronField(parts[3], 1, 12, cronMonthNames); err != nil {
+		return nil, fmt.Errorf("月の指定が不正です: %w", err)
+	}
+	if s.weekday, err = parseCronField(parts[4], 0, 7, cronWeekdayNames); err != nil {
+		return nil, fmt.Errorf("曜日の指定が不正です: %w", err)
+	}
+	// 7（日曜日）は0として扱う
+	if s.weekday&(1<<7) != 0 {
+		s.weekday = s.weekday&^(1<<7) | 1
+	}
+	s.dayRestricted = parts[2] != "*"
+	s.weekdayRestricted = parts[4] != "*"
+
+	if s.Next(time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)).IsZero() {
+		return nil, errors.New("一致する日時が存在しません")
+	}
+	return &s, nil
+}
+
+// parseCronField 1つのフィールドを解析し、一致する値をビットで表した集合を返す
+func parseCronField(field string, min, max int, names map[string]int) (uint64, error) {
+	var bits uint64
+	for _, item := range strings.Split(field, ",") {
+		rangePart, step := item, 1
+		if i := strings.Index(item, "/"); i >= 0 {
+			n, err := strconv.Atoi(item[i+1:])
+			if err != nil || n < 1 {
+				return 0, fmt.Errorf("間隔 %q は1以上の整数で指定してください", item[i+1:])
+			}
+			rangePart, step = item[:i], n
+		}
+
+		var lo, hi int
+		switch {
+		case rangePart == "*":
+			lo, hi = min, max
+		case strings.Contains(rangePart, "-"):
+			bounds := strings.SplitN(rangePart, "-", 2)
+			var err error
+			if lo, err = parseCronValue(bounds[0], min, max, names); err != nil {
+				return 0, err
+			}
+			if hi, err = parseCronValue(bounds[1], min, max, names); err != nil {
+				return 0, err
+			}
+			if lo > hi {
+				return 0, fmt.Errorf("範囲 %q の開始が終了より大きくなっています", rangePart)
+			}
+		default:
+			v, err := parseCronValue(rangePart, min, max, names)
+			if err != nil {
+				return 0, err
+			}
+			lo, hi = v, v
+			// "5/10" は5から最大値まで10おきを表す
+			if step > 1 {
+				hi = max
+			}
+		}
+
+		for v := lo; v <= hi; v += step {
+			bits |= 1 << uint(v)
+		}
+	}
+	return bits, nil
+}
+
+// parseCronValue フィールド内の1つの値（数値または名前）を解析する
+func parseCronValue(value string, min, max int, names map[string]int) (int, error) {
+	if n, ok := names[strings.ToLower(value)]; ok {
+		return n, nil
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf("値 %q を解析できません", value)
+	}
+	if n < min || n > max {
+		return 0, fmt.Errorf("値 %d は %d〜%d の範囲で指定してください", n, min, max)
+	}
+	return n, nil
+}
+
+// Next afterより後（afterを含まない）で最初にスケジュールに一致する時刻を返す
+// 時刻はafterのタイムゾーンで評価する。一致する時刻がない場合はゼロ値を返す
+func (s *CronSchedule) Next(after time.Time) time.Time {
+	loc := after.Location()
+	t := after.Truncate(time.Minute).Add(time.Minute)
+	limit := t.Add(cronSearchLimit)
+
+	for t.Before(limit) {
+		if s.month&(1<<uint(t.Month())) == 0 {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, loc)
+			continue
+		}
+		if !s.dayMatches(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, loc)
+			continue
+		}
+		if s.hour&(1<<uint(t.Hour())) == 0 {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, loc)
+			continue
+		}
+		if s.minute&(1<<uint(t.Minute())) == 0 {
+			t = t.Truncate(time.Minute).Add(time.Minute)
+			continue
+		}
+		return t
+	}
+	return time.Time{}
+}
+
+// dayMatches 日付が日と曜日の指定に一致するかどうかを確認する
+func (s *CronSchedule) dayMatches(t time.Time) bool {
+	dayOK := s.day&(1<<uint(t.Day())) != 0
+	weekdayOK := s.weekday&(1<<uint(t.Weekday())) != 0
+	if s.dayRestricted && s.weekdayRestricted {
+		return dayOK || weekdayOK
+	}
+	return dayOK && weekdayOK
+}
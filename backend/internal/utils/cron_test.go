@@ -0,0 +1,64 @@
+package utils_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"nocode-app/backend/internal/utils"
+)
+
+func TestCronSchedule_Next(t *testing.T) {
+	tokyo := time.FixedZone("JST", 9*60*60)
+	// 2026-10-16 は金曜日
+	base := time.Date(2026, 10, 16, 10, 30, 0, 0, tokyo)
+
+	tests := []struct {
+		spec     string
+		after    time.Time
+		expected time.Time
+	}{
+		{"0 9 * * 1", base, time.Date(2026, 10, 19, 9, 0, 0, 0, tokyo)},
+		{"0 9 * * mon", base, time.Date(2026, 10, 19, 9, 0, 0, 0, tokyo)},
+		{"*/15 * * * *", base, time.Date(2026, 10, 16, 10, 45, 0, 0, tokyo)},
+		{"30 10 * * *", base, time.Date(2026, 10, 17, 10, 30, 0, 0, tokyo)},
+		{"0 0 1 * *", base, time.Date(2026, 11, 1, 0, 0, 0, 0, tokyo)},
+		{"0 8-18/2 * * 1-5", base, time.Date(2026, 10, 16, 12, 0, 0, 0, tokyo)},
+		{"0 0 * * 7", base, time.Date(2026, 10, 18, 0, 0, 0, 0, tokyo)},
+		{"0 0 29 feb *", base, time.Date(2028, 2, 29, 0, 0, 0, 0, tokyo)},
+		{"@daily", base, time.Date(2026, 10, 17, 0, 0, 0, 0, tokyo)},
+		// 日と曜日の両方を指定した場合はいずれかに一致すれば実行する
+		{"0 0 20 * 6", base, time.Date(2026, 10, 17, 0, 0, 0, 0, tokyo)},
+		// 秒があっても次の分から探す
+		{"* * * * *", base.Add(20 * time.Second), time.Date(2026, 10, 16, 10, 31, 0, 0, tokyo)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			schedule, err := utils.ParseCron(tt.spec)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, schedule.Next(tt.after))
+		})
+	}
+}
+
+func TestParseCron_Errors(t *testing.T) {
+	errorCases := map[string]string{
+		"too few fields":   "0 9 * *",
+		"out of range":     "60 * * * *",
+		"zero day":         "0 0 0 * *",
+		"reversed range":   "0 18-8 * * *",
+		"zero step":        "*/0 * * * *",
+		"unknown name":     "0 0 * * funday",
+		"never matches":    "0 0 31 2 *",
+		"empty":            "",
+		"seconds included": "0 0 9 * * 1",
+	}
+	for name, spec := range errorCases {
+		t.Run(name, func(t *testing.T) {
+			_, err := utils.ParseCron(spec)
+			assert.Error(t, err)
+		})
+	}
+}
@@ -0,0 +1,655 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"nocode-app/backend/internal/models"
+)
+
+// 計算式の上限値
+const (
+	// MaxFormulaLength 計算式の最大文字数
+	MaxFormulaLength = 1000
+	// maxFormulaDepth 計算式の入れ子の最大の深さ
+	maxFormulaDepth = 50
+)
+
+// 計算式のエラー
+var (
+	ErrFormulaSyntax = errors.New("計算式の構文が正しくありません")
+	ErrFormulaType   = errors.New("計算式の型が正しくありません")
+	ErrFormulaField  = errors.New("計算式で使用できないフィールドです")
+)
+
+// Formula 構文解析済みの計算式
+//
+// 値の型は対応するフィールドタイプで表す（数値: number、文字列: text、日付: date、日時: datetime、真偽値: checkbox）。
+// 演算子は + - * / と文字列連結の &、比較の = != <> < <= > >=。関数は IF / AND / OR / NOT / ROUND / ABS。
+// TRUE / FALSE は真偽値の予約語で、それ以外の識別子はフィールドコードとして扱う
+type Formula struct {
+	root formulaNode
+	refs []string
+}
+
+// FormulaOperand 計算式から参照するフィールドの型とSQL上の式
+// SQL が空の場合はフィールドコードと同名のカラムを参照する
+type FormulaOperand struct {
+	Type models.FieldType
+	SQL  string
+}
+
+// ParseFormula 計算式を構文解析する（フィールドの存在と型の検査は Compile で行う）
+func ParseFormula(expression string) (*Formula, error) {
+	if strings.TrimSpace(expression) == "" {
+		return nil, fmt.Errorf("%w: 計算式が空です", ErrFormulaSyntax)
+	}
+	if len([]rune(expression)) > MaxFormulaLength {
+		return nil, fmt.Errorf("%w: 計算式は%d文字以内で指定してください", ErrFormulaSyntax, MaxFormulaLength)
+	}
+
+	tokens, err := tokenizeFormula(expression)
+	if err != nil {
+		return nil, err
+	}
+	p := &formulaParser{tokens: tokens, seen: make(map[string]bool)}
+	root, err := p.parseExpression()
+	if err != nil {
+		return nil, err
+	}
+	if tok := p.peek(); tok.kind != formulaTokenEOF {
+		return nil, fmt.Errorf("%w: %d文字目の %q は不要です", ErrFormulaSyntax, tok.pos+1, tok.text)
+	}
+	return &Formula{root: root, refs: p.refs}, nil
+}
+
+// Refs 計算式が参照するフィールドコードを出現順に重複なく返す
+func (f *Formula) Refs() []string {
+	return f.refs
+}
+
+// Compile 参照するフィールドの型を検査し、計算式をPostgreSQLの式に変換する
+// 変換後の式は生成列に使えるよう IMMUTABLE な演算のみで構成し、0による除算は NULL とする。
+// 演算は括弧で囲んで返すため、他の計算式にそのまま埋め込める
+func (f *Formula) Compile(operands map[string]FormulaOperand) (string, models.FieldType, error) {
+	c := &formulaCompiler{operands: operands}
+	return c.compile(f.root)
+}
+
+// RenameFormulaFields 計算式が参照するフィールドコードを置き換える
+// 関数名・TRUE / FALSE・文字列の中の同名の語は置き換えず、それ以外の書式（空白や括弧）はそのまま残す
+func RenameFormulaFields(expression string, renames map[string]string) (string, error) {
+	tokens, err := tokenizeFormula(expression)
+	if err != nil {
+		return "", err
+	}
+
+	runes := []rune(expression)
+	var sb strings.Builder
+	last := 0
+	for i, tok := range tokens {
+		if tok.kind != formulaTokenIdent || tokens[i+1].kind == formulaTokenLParen {
+			continue
+		}
+		if upper := strings.ToUpper(tok.text); upper == "TRUE" || upper == "FALSE" {
+			continue
+		}
+		renamed, ok := renames[tok.text]
+		if !ok {
+			continue
+		}
+		sb.WriteString(string(runes[last:tok.pos]))
+		sb.WriteString(renamed)
+		last = tok.pos + len([]rune(tok.text))
+	}
+	sb.WriteString(string(runes[last:]))
+	return sb.String(), nil
+}
+
+// --- 字句解析 ---
+
+type formulaTokenKind int
+
+const (
+	formulaTokenEOF formulaTokenKind = iota
+	formulaTokenNumber
+	formulaTokenString
+	formulaTokenIdent
+	formulaTokenOperator
+	formulaTokenLParen
+	formulaTokenRParen
+	formulaTokenComma
+)
+
+type formulaToken struct {
+	kind formulaTokenKind
+	text string
+	pos  int
+}
+
+// formulaOperators 2文字の演算子を先に照合する
+var formulaOperators = []string{"<=", ">=", "!=", "<>", "+", "-", "*", "/", "&", "=", "<", ">"}
+
+func tokenizeFormula(expression string) ([]formulaToken, error) {
+	runes := []rune(expression)
+	var tokens []formulaToken
+
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r >= '0' && r <= '9' || r == '.' && i+1 < len(runes) && runes[i+1] >= '0' && runes[i+1] <= '9':
+			start := i
+			dot := false
+			for i < len(runes) && (runes[i] >= '0' && runes[i] <= '9' || runes[i] == '.' && !dot) {
+				if runes[i] == '.' {
+					dot = true
+				}
+				i++
+			}
+			tokens = append(tokens, formulaToken{kind: formulaTokenNumber, text: string(runes[start:i]), pos: start})
+		case r == '"' || r == '\'':
+			start := i
+			var sb strings.Builder
+			i++
+			closed := false
+			for i < len(runes) {
+				if runes[i] == '\\' && i+1 < len(runes) {
+					sb.WriteRune(runes[i+1])
+					i += 2
+					continue
+				}
+				if runes[i] == r {
+					closed = true
+					i++
+					break
+				}
+				sb.WriteRune(runes[i])
+				i++
+			}
+			if !closed {
+				return nil, fmt.Errorf("%w: %d文字目の文字列が閉じられていません", ErrFormulaSyntax, start+1)
+			}
+			tokens = append(tokens, formulaToken{kind: formulaTokenString, text: sb.String(), pos: start})
+		case r == '_' || r < unicode.MaxASCII && unicode.IsLetter(r):
+			start := i
+			for i < len(runes) && (runes[i] == '_' || runes[i] < unicode.MaxASCII && (unicode.IsLetter(runes[i]) || unicode.IsDigit(runes[i]))) {
+				i++
+			}
+			tokens = append(tokens, formulaToken{kind: formulaTokenIdent, text: string(runes[start:i]), pos: start})
+		case r == '(':
+			tokens = append(tokens, formulaToken{kind: formulaTokenLParen, text: "(", pos: i})
+			i++
+		case r == ')':
+			tokens = append(tokens, formulaToken{kind: formulaTokenRParen, text: ")", pos: i})
+			i++
+		case r == ',':
+			tokens = append(tokens, formulaToken{kind: formulaTokenComma, text: ",", pos: i})
+			i++
+		default:
+			matched := ""
+			for _, op := range formulaOperators {
+				if strings.HasPrefix(string(runes[i:min(i+2, len(runes))]), op) {
+					matched = op
+					break
+				}
+			}
+			if matched == "" {
+				return nil, fmt.Errorf("%w: %d文字目の %q は使用できません", ErrFormulaSyntax, i+1, string(r))
+			}
+			tokens = append(tokens, formulaToken{kind: formulaTokenOperator, text: matched, pos: i})
+			i += len(matched)
+		}
+	}
+	return append(tokens, formulaToken{kind: formulaTokenEOF, pos: len(runes)}), nil
+}
+
+// --- 構文解析 ---
+
+type formulaNode interface{}
+
+type (
+	formulaNumber struct{ text string }
+	formulaString struct{ value string }
+	formulaBool   struct{ value bool }
+	formulaField  struct{ code string }
+	formulaUnary  struct {
+		op      string
+		operand formulaNode
+	}
+	formulaBinary struct {
+		op          string
+		left, right formulaNode
+	}
+	formulaCall struct {
+		name string
+		args []formulaNode
+	}
+)
+
+type formulaParser struct {
+	tokens []formulaToken
+	pos    int
+	depth  int
+	refs   []string
+	seen   map[string]bool
+}
+
+func (p *formulaParser) peek() formulaToken {
+	return p.tokens[p.pos]
+}
+
+func (p *formulaParser) next() formulaToken {
+	tok := p.tokens[p.pos]
+	if tok.kind != formulaTokenEOF {
+		p.pos++
+	}
+	return tok
+}
+
+func (p *formulaParser) isOperator(ops ...string) bool {
+	tok := p.peek()
+	if tok.kind != formulaTokenOperator {
+		return false
+	}
+	for _, op := range ops {
+		if tok.text == op {
+			return true
+		}
+	}
+	return false
+}
+
+// parseExpression 比較（結合しない）< 連結 & < 加減 < 乗除 < 単項マイナスの順に優先度が高くなる
+func (p *formulaParser) parseExpression() (formulaNode, error) {
+	p.depth++
+	defer func() { p.depth-- }()
+	if p.depth > maxFormulaDepth {
+		return nil, fmt.Errorf("%w: 計算式の入れ子が深すぎます", ErrFormulaSyntax)
+	}
+
+	left, err := p.parseConcat()
+	if err != nil {
+		return nil, err
+	}
+	if p.isOperator("=", "!=", "<>", "<", "<=", ">", ">=") {
+		op := p.next().text
+		right, err := p.parseConcat()
+		if err != nil {
+			return nil, err
+		}
+		return &formulaBinary{op: op, left: left, right: right}, nil
+	}
+	return left, nil
+}
+
+func (p *formulaParser) parseConcat() (formulaNode, error) {
+	left, err := p.parseAdditive()
+	if err != nil {
+		return nil, err
+	}
+	for p.isOperator("&") {
+		p.next()
+		right, err := p.parseAdditive()
+		if err != nil {
+			return nil, err
+		}
+		left = &formulaBinary{op: "&", left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *formulaParser) parseAdditive() (formulaNode, error) {
+	left, err := p.parseMultiplicative()
+	if err != nil {
+		return nil, err
+	}
+	for p.isOperator("+", "-") {
+		op := p.next().text
+		right, err := p.parseMultiplicative()
+		if err != nil {
+			return nil, err
+		}
+		left = &formulaBinary{op: op, left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *formulaParser) parseMultiplicative() (formulaNode, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.isOperator("*", "/") {
+		op := p.next().text
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = &formulaBinary{op: op, left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *formulaParser) parseUnary() (formulaNode, error) {
+	if p.isOperator("-", "+") {
+		op := p.next().text
+		p.depth++
+		defer func() { p.depth-- }()
+		if p.depth > maxFormulaDepth {
+			return nil, fmt.Errorf("%w: 計算式の入れ子が深すぎます", ErrFormulaSyntax)
+		}
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return &formulaUnary{op: op, operand: operand}, nil
+	}
+	return p.parsePrimary()
+}
+
+func (p *formulaParser) parsePrimary() (formulaNode, error) {
+	tok := p.next()
+	switch tok.kind {
+	case formulaTokenNumber:
+		return &formulaNumber{text: tok.text}, nil
+	case formulaTokenString:
+		return &formulaString{value: tok.text}, nil
+	case formulaTokenLParen:
+		node, err := p.parseExpression()
+		if err != nil {
+			return nil, err
+		}
+		if p.next().kind != formulaTokenRParen {
+			return nil, fmt.Errorf("%w: %d文字目の括弧が閉じられていません", ErrFormulaSyntax, tok.pos+1)
+		}
+		return node, nil
+	case formulaTokenIdent:
+		if p.peek().kind == formulaTokenLParen {
+			return p.parseCall(tok)
+		}
+		switch strings.ToUpper(tok.text) {
+		case "TRUE":
+			return &formulaBool{value: true}, nil
+		case "FALSE":
+			return &formulaBool{value: false}, nil
+		}
+		if !p.seen[tok.text] {
+			p.seen[tok.text] = true
+			p.refs = append(p.refs, tok.text)
+		}
+		return &formulaField{code: tok.text}, nil
+	case formulaTokenEOF:
+		return nil, fmt.Errorf("%w: 計算式が途中で終わっています", ErrFormulaSyntax)
+	default:
+		return nil, fmt.Errorf("%w: %d文字目の %q は使用できません", ErrFormulaSyntax, tok.pos+1, tok.text)
+	}
+}
+
+// formulaFunctions 関数名と引数の数（最小・最大。-1は上限なし）
+var formulaFunctions = map[string][2]int{
+	"IF":    {3, 3},
+	"AND":   {1, -1},
+	"OR":    {1, -1},
+	"NOT":   {1, 1},
+	"ROUND": {1, 2},
+	"ABS":   {1, 1},
+}
+
+func (p *formulaParser) parseCall(name formulaToken) (formulaNode, error) {
+	fn := strings.ToUpper(name.text)
+	arity, ok := formulaFunctions[fn]
+	if !ok {
+		return nil, fmt.Errorf("%w: %d文字目の関数 %s は使用できません", ErrFormulaSyntax, name.pos+1, name.text)
+	}
+	p.next() // (
+
+	var args []formulaNode
+	if p.peek().kind != formulaTokenRParen {
+		for {
+			arg, err := p.parseExpression()
+			if err != nil {
+				return nil, err
+			}
+			args = append(args, arg)
+			if p.peek().kind != formulaTokenComma {
+				break
+			}
+			p.next()
+		}
+	}
+	if p.next().kind != formulaTokenRParen {
+		return nil, fmt.Errorf("%w: %d文字目の関数 %s の括弧が閉じられていません", ErrFormulaSyntax, name.pos+1, name.text)
+	}
+	if len(args) < arity[0] || arity[1] >= 0 && len(args) > arity[1] {
+		return nil, fmt.Errorf("%w: 関数 %s の引数の数が正しくありません", ErrFormulaSyntax, fn)
+	}
+	return &formulaCall{name: fn, args: args}, nil
+}
+
+// --- 型検査とSQLへの変換 ---
+
+type formulaCompiler struct {
+	operands map[string]FormulaOperand
+}
+
+// formulaTypeLabel エラーメッセージ用の型名
+func formulaTypeLabel(t models.FieldType) string {
+	switch t {
+	case models.FieldTypeNumber:
+		return "数値"
+	case models.FieldTypeText:
+		return "文字列"
+	case models.FieldTypeDate:
+		return "日付"
+	case models.FieldTypeDateTime:
+		return "日時"
+	case models.FieldTypeCheckbox:
+		return "真偽値"
+	default:
+		return string(t)
+	}
+}
+
+func formulaTypeError(format string, args ...interface{}) error {
+	return fmt.Errorf("%w: %s", ErrFormulaType, fmt.Sprintf(format, args...))
+}
+
+func (c *formulaCompiler) compile(node formulaNode) (string, models.FieldType, error) {
+	switch n := node.(type) {
+	case *formulaNumber:
+		if _, err := strconv.ParseFloat(n.text, 64); err != nil {
+			return "", "", fmt.Errorf("%w: 数値 %q が正しくありません", ErrFormulaSyntax, n.text)
+		}
+		return "CAST(" + n.text + " AS NUMERIC)", models.FieldTypeNumber, nil
+	case *formulaString:
+		return "CAST('" + strings.ReplaceAll(n.value, "'", "''") + "' AS TEXT)", models.FieldTypeText, nil
+	case *formulaBool:
+		if n.value {
+			return "TRUE", models.FieldTypeCheckbox, nil
+		}
+		return "FALSE", models.FieldTypeCheckbox, nil
+	case *formulaField:
+		operand, ok := c.operands[n.code]
+		if !ok {
+			return "", "", fmt.Errorf("%w: %s", ErrFormulaField, n.code)
+		}
+		if operand.SQL != "" {
+			return operand.SQL, operand.Type, nil
+		}
+		return `"` + strings.ReplaceAll(n.code, `"`, `""`) + `"`, operand.Type, nil
+	case *formulaUnary:
+		sql, t, err := c.compile(n.operand)
+		if err != nil {
+			return "", "", err
+		}
+		if t != models.FieldTypeNumber {
+			return "", "", formulaTypeError("単項の %s は数値にのみ使用できます（%s）", n.op, formulaTypeLabel(t))
+		}
+		if n.op == "+" {
+			return sql, t, nil
+		}
+		return "(-" + sql + ")", t, nil
+	case *formulaBinary:
+		return c.compileBinary(n)
+	case *formulaCall:
+		return c.compileCall(n)
+	default:
+		return "", "", fmt.Errorf("%w: 不明な式です", ErrFormulaSyntax)
+	}
+}
+
+func (c *formulaCompiler) compileBinary(n *formulaBinary) (string, models.FieldType, error) {
+	left, lt, err := c.compile(n.left)
+	if err != nil {
+		return "", "", err
+	}
+	right, rt, err := c.compile(n.right)
+	if err != nil {
+		return "", "", err
+	}
+
+	number := models.FieldTypeNumber
+	switch n.op {
+	case "+":
+		switch {
+		case lt == number && rt == number:
+			return "(" + left + " + " + right + ")", number, nil
+		case lt == models.FieldTypeDate && rt == number:
+			return "(" + left + " + CAST(" + right + " AS INTEGER))", lt, nil
+		case lt == number && rt == models.FieldTypeDate:
+			return "(" + right + " + CAST(" + left + " AS INTEGER))", rt, nil
+		case lt == models.FieldTypeDateTime && rt == number:
+			return "(" + left + " + CAST(" + right + " AS DOUBLE PRECISION) * INTERVAL '1 day')", lt, nil
+		case lt == number && rt == models.FieldTypeDateTime:
+			return "(" + right + " + CAST(" + left + " AS DOUBLE PRECISION) * INTERVAL '1 day')", rt, nil
+		}
+	case "-":
+		switch {
+		case lt == number && rt == number:
+			return "(" + left + " - " + right + ")", number, nil
+		case lt == models.FieldTypeDate && rt == models.FieldTypeDate:
+			// 日付の差は日数
+			return "CAST((" + left + " - " + right + ") AS NUMERIC)", number, nil
+		case lt == models.FieldTypeDateTime && rt == models.FieldTypeDateTime:
+			// 日時の差は小数を含む日数
+			return "(EXTRACT(EPOCH FROM (" + left + " - " + right + ")) / 86400)", number, nil
+		case lt == models.FieldTypeDate && rt == number:
+			return "(" + left + " - CAST(" + right + " AS INTEGER))", lt, nil
+		case lt == models.FieldTypeDateTime && rt == number:
+			return "(" + left + " - CAST(" + right + " AS DOUBLE PRECISION) * INTERVAL '1 day')", lt, nil
+		}
+	case "*":
+		if lt == number && rt == number {
+			return "(" + left + " * " + right + ")", number, nil
+		}
+	case "/":
+		if lt == number && rt == number {
+			// 0による除算はエラーにせずNULLとする
+			return "(" + left + " / NULLIF(" + right + ", 0))", number, nil
+		}
+	case "&":
+		l, err := formulaText(left, lt)
+		if err != nil {
+			return "", "", err
+		}
+		r, err := formulaText(right, rt)
+		if err != nil {
+			return "", "", err
+		}
+		return "(" + l + " || " + r + ")", models.FieldTypeText, nil
+	case "=", "!=", "<>", "<", "<=", ">", ">=":
+		if lt != rt {
+			return "", "", formulaTypeError("%sと%sは比較できません", formulaTypeLabel(lt), formulaTypeLabel(rt))
+		}
+		op := n.op
+		if op == "!=" {
+			op = "<>"
+		}
+		if lt == models.FieldTypeCheckbox && op != "=" && op != "<>" {
+			return "", "", formulaTypeError("真偽値は = と != でのみ比較できます")
+		}
+		return "(" + left + " " + op + " " + right + ")", models.FieldTypeCheckbox, nil
+	}
+	return "", "", formulaTypeError("%sと%sに %s は使用できません", formulaTypeLabel(lt), formulaTypeLabel(rt), n.op)
+}
+
+// formulaText 連結する値を文字列に変換する（NULLは空文字列とする）
+// 日付・日時の文字列表現はセッションの設定に依存し生成列に使えないため連結できない
+func formulaText(sql string, t models.FieldType) (string, error) {
+	switch t {
+	case models.FieldTypeText:
+		return "COALESCE(" + sql + ", '')", nil
+	case models.FieldTypeNumber:
+		// 末尾の0を除いて文字列にする
+		return "COALESCE(CAST(TRIM_SCALE(" + sql + ") AS TEXT), '')", nil
+	case models.FieldTypeCheckbox:
+		return "COALESCE(CAST(" + sql + " AS TEXT), '')", nil
+	default:
+		return "", formulaTypeError("%sは & で連結できません", formulaTypeLabel(t))
+	}
+}
+
+func (c *formulaCompiler) compileCall(n *formulaCall) (string, models.FieldType, error) {
+	args := make([]string, len(n.args))
+	types := make([]models.FieldType, len(n.args))
+	for i, arg := range n.args {
+		sql, t, err := c.compile(arg)
+		if err != nil {
+			return "", "", err
+		}
+		args[i] = sql
+		types[i] = t
+	}
+
+	expect := func(i int, t models.FieldType) error {
+		if types[i] != t {
+			return formulaTypeError("%s の%d番目の引数は%sを指定してください（%s）", n.name, i+1, formulaTypeLabel(t), formulaTypeLabel(types[i]))
+		}
+		return nil
+	}
+
+	switch n.name {
+	case "IF":
+		if err := expect(0, models.FieldTypeCheckbox); err != nil {
+			return "", "", err
+		}
+		if types[1] != types[2] {
+			return "", "", formulaTypeError("IF の2番目と3番目の引数は同じ型にしてください（%s / %s）", formulaTypeLabel(types[1]), formulaTypeLabel(types[2]))
+		}
+		return "(CASE WHEN " + args[0] + " THEN " + args[1] + " ELSE " + args[2] + " END)", types[1], nil
+	case "AND", "OR":
+		for i := range args {
+			if err := expect(i, models.FieldTypeCheckbox); err != nil {
+				return "", "", err
+			}
+		}
+		return "(" + strings.Join(args, " "+n.name+" ") + ")", models.FieldTypeCheckbox, nil
+	case "NOT":
+		if err := expect(0, models.FieldTypeCheckbox); err != nil {
+			return "", "", err
+		}
+		return "(NOT " + args[0] + ")", models.FieldTypeCheckbox, nil
+	case "ROUND":
+		for i := range args {
+			if err := expect(i, models.FieldTypeNumber); err != nil {
+				return "", "", err
+			}
+		}
+		if len(args) == 1 {
+			return "ROUND(" + args[0] + ")", models.FieldTypeNumber, nil
+		}
+		return "ROUND(" + args[0] + ", CAST(" + args[1] + " AS INTEGER))", models.FieldTypeNumber, nil
+	case "ABS":
+		if err := expect(0, models.FieldTypeNumber); err != nil {
+			return "", "", err
+		}
+		return "ABS(" + args[0] + ")", models.FieldTypeNumber, nil
+	default:
+		return "", "", fmt.Errorf("%w: 関数 %s は使用できません", ErrFormulaSyntax, n.name)
+	}
+}
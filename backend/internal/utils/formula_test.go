@@ -0,0 +1,199 @@
+package utils_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"nocode-app/backend/internal/models"
+	"nocode-app/backend/internal/utils"
+)
+
+// formulaOperands テストで使うフィールドの型
+var formulaOperands = map[string]utils.FormulaOperand{
+	"price":    {Type: models.FieldTypeNumber},
+	"quantity": {Type: models.FieldTypeNumber},
+	"name":     {Type: models.FieldTypeText},
+	"start":    {Type: models.FieldTypeDate},
+	"end":      {Type: models.FieldTypeDate},
+	"begin_at": {Type: models.FieldTypeDateTime},
+	"end_at":   {Type: models.FieldTypeDateTime},
+	"done":     {Type: models.FieldTypeCheckbox},
+}
+
+func compileFormula(t *testing.T, expression string) (string, models.FieldType, error) {
+	t.Helper()
+	formula, err := utils.ParseFormula(expression)
+	require.NoError(t, err)
+	return formula.Compile(formulaOperands)
+}
+
+func TestParseFormula(t *testing.T) {
+	t.Run("refs in order without duplicates", func(t *testing.T) {
+		formula, err := utils.ParseFormula("price * quantity + price")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"price", "quantity"}, formula.Refs())
+	})
+
+	t.Run("TRUE and FALSE are not field codes", func(t *testing.T) {
+		formula, err := utils.ParseFormula("IF(true, done, FALSE)")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"done"}, formula.Refs())
+	})
+
+	errorCases := map[string]string{
+		"empty":            "  ",
+		"unclosed paren":   "(price + 1",
+		"unclosed string":  "'abc",
+		"trailing token":   "price quantity",
+		"unknown function": "SUM(price)",
+		"wrong arity":      "IF(done, 1)",
+		"dangling op":      "price +",
+		"invalid char":     "price # 2",
+		"chained compare":  "1 < 2 < 3",
+		"too deep":         strings.Repeat("(", 60) + "1" + strings.Repeat(")", 60),
+		"too long":         strings.Repeat("1+", utils.MaxFormulaLength) + "1",
+	}
+	for name, expression := range errorCases {
+		t.Run(name, func(t *testing.T) {
+			_, err := utils.ParseFormula(expression)
+			assert.ErrorIs(t, err, utils.ErrFormulaSyntax)
+		})
+	}
+}
+
+func TestRenameFormulaFields(t *testing.T) {
+	renames := map[string]string{"price": "unit_price", "IF": "if_flag", "abc": "xyz"}
+
+	renamed, err := utils.RenameFormulaFields("IF(done,  price*quantity, 'abc') & price", renames)
+	require.NoError(t, err)
+	assert.Equal(t, "IF(done,  unit_price*quantity, 'abc') & unit_price", renamed)
+
+	_, err = utils.RenameFormulaFields("'abc", renames)
+	assert.ErrorIs(t, err, utils.ErrFormulaSyntax)
+}
+
+func TestFormula_Compile(t *testing.T) {
+	tests := []struct {
+		name       string
+		expression string
+		wantSQL    string
+		wantType   models.FieldType
+	}{
+		{
+			name:       "arithmetic with precedence",
+			expression: "price * quantity + 1",
+			wantSQL:    `(("price" * "quantity") + CAST(1 AS NUMERIC))`,
+			wantType:   models.FieldTypeNumber,
+		},
+		{
+			name:       "division by zero is null",
+			expression: "price / quantity",
+			wantSQL:    `("price" / NULLIF("quantity", 0))`,
+			wantType:   models.FieldTypeNumber,
+		},
+		{
+			name:       "date difference in days",
+			expression: "end - start",
+			wantSQL:    `CAST(("end" - "start") AS NUMERIC)`,
+			wantType:   models.FieldTypeNumber,
+		},
+		{
+			name:       "datetime difference in days",
+			expression: "end_at - begin_at",
+			wantSQL:    `(EXTRACT(EPOCH FROM ("end_at" - "begin_at")) / 86400)`,
+			wantType:   models.FieldTypeNumber,
+		},
+		{
+			name:       "date plus days",
+			expression: "7 + start",
+			wantSQL:    `("start" + CAST(CAST(7 AS NUMERIC) AS INTEGER))`,
+			wantType:   models.FieldTypeDate,
+		},
+		{
+			name:       "datetime minus days",
+			expression: "end_at - 0.5",
+			wantSQL:    `("end_at" - CAST(CAST(0.5 AS NUMERIC) AS DOUBLE PRECISION) * INTERVAL '1 day')`,
+			wantType:   models.FieldTypeDateTime,
+		},
+		{
+			name:       "concatenation",
+			expression: `name & " x" & quantity`,
+			wantSQL:    `(COALESCE((COALESCE("name", '') || COALESCE(CAST(' x' AS TEXT), '')), '') || COALESCE(CAST(TRIM_SCALE("quantity") AS TEXT), ''))`,
+			wantType:   models.FieldTypeText,
+		},
+		{
+			name:       "string literal is escaped",
+			expression: `"it's"`,
+			wantSQL:    `CAST('it''s' AS TEXT)`,
+			wantType:   models.FieldTypeText,
+		},
+		{
+			name:       "if with comparison",
+			expression: `if(price >= 100, "high", 'low')`,
+			wantSQL:    `(CASE WHEN ("price" >= CAST(100 AS NUMERIC)) THEN CAST('high' AS TEXT) ELSE CAST('low' AS TEXT) END)`,
+			wantType:   models.FieldTypeText,
+		},
+		{
+			name:       "logical functions",
+			expression: "AND(done, NOT(price != 0), OR(TRUE, done))",
+			wantSQL:    `("done" AND (NOT ("price" <> CAST(0 AS NUMERIC))) AND (TRUE OR "done"))`,
+			wantType:   models.FieldTypeCheckbox,
+		},
+		{
+			name:       "round and abs",
+			expression: "ROUND(ABS(-price), 2)",
+			wantSQL:    `ROUND(ABS((-"price")), CAST(CAST(2 AS NUMERIC) AS INTEGER))`,
+			wantType:   models.FieldTypeNumber,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sql, typ, err := compileFormula(t, tt.expression)
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantSQL, sql)
+			assert.Equal(t, tt.wantType, typ)
+		})
+	}
+
+	t.Run("inlined operand", func(t *testing.T) {
+		formula, err := utils.ParseFormula("subtotal * 1.1")
+		require.NoError(t, err)
+		sql, typ, err := formula.Compile(map[string]utils.FormulaOperand{
+			"subtotal": {Type: models.FieldTypeNumber, SQL: `("price" * "quantity")`},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, `(("price" * "quantity") * CAST(1.1 AS NUMERIC))`, sql)
+		assert.Equal(t, models.FieldTypeNumber, typ)
+	})
+
+	t.Run("unknown field", func(t *testing.T) {
+		_, _, err := compileFormula(t, "price * missing")
+		assert.ErrorIs(t, err, utils.ErrFormulaField)
+	})
+
+	typeErrors := map[string]string{
+		"text arithmetic":       "name * 2",
+		"add two dates":         "start + end",
+		"date and datetime":     "end_at - start",
+		"compare mixed types":   "price = name",
+		"order booleans":        "done < TRUE",
+		"concatenate date":      "name & start",
+		"if condition":          "IF(price, 1, 2)",
+		"if branches differ":    "IF(done, 1, 'a')",
+		"negate text":           "-name",
+		"round text":            "ROUND(name)",
+		"and with number":       "AND(done, 1)",
+		"multiply by checkbox":  "price * done",
+		"subtract number - day": "1 - start",
+	}
+	for name, expression := range typeErrors {
+		t.Run(name, func(t *testing.T) {
+			_, _, err := compileFormula(t, expression)
+			assert.ErrorIs(t, err, utils.ErrFormulaType)
+		})
+	}
+}
@@ -15,11 +15,16 @@ type JWTClaims struct {
 	jwt.RegisteredClaims
 }
 
+// RealtimeTokenAudience アプリの変更の購読にのみ使えるトークンの用途
+const RealtimeTokenAudience = "realtime"
+
 // JWTManagerInterface JWT操作のインターフェースを定義
 type JWTManagerInterface interface {
 	GenerateToken(userID uint64, email, role string) (string, error)
 	ValidateToken(tokenString string) (*JWTClaims, error)
 	RefreshToken(claims *JWTClaims) (string, error)
+	GenerateScopedToken(claims *JWTClaims, audience string, ttl time.Duration) (string, error)
+	ValidateScopedToken(tokenString, audience string) (*JWTClaims, error)
 }
 
 // JWTManager JWT操作を処理する構造体
@@ -58,13 +63,56 @@ func (m *JWTManager) GenerateToken(userID uint64, email, role string) (string, e
 }
 
 // ValidateToken JWTトークンを検証しクレームを返す
+// 用途を限定したトークンは通常のAPIの認証には使えない
 func (m *JWTManager) ValidateToken(tokenString string) (*JWTClaims, error) {
+	claims, err := m.parse(tokenString)
+	if err != nil {
+		return nil, err
+	}
+	if len(claims.Audience) > 0 {
+		return nil, errors.New("用途が限定されたトークンです")
+	}
+	return claims, nil
+}
+
+// GenerateScopedToken 用途を限定した有効期限の短いトークンを生成する
+// ヘッダーを指定できずURLのクエリ文字列で渡す場合など、漏れやすい経路で使う
+func (m *JWTManager) GenerateScopedToken(claims *JWTClaims, audience string, ttl time.Duration) (string, error) {
+	now := time.Now()
+	scoped := &JWTClaims{
+		UserID: claims.UserID,
+		Email:  claims.Email,
+		Role:   claims.Role,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Audience:  jwt.ClaimStrings{audience},
+			ExpiresAt: jwt.NewNumericDate(now.Add(ttl)),
+			IssuedAt:  jwt.NewNumericDate(now),
+			NotBefore: jwt.NewNumericDate(now),
+			Issuer:    "nocode-app",
+		},
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, scoped)
+	return token.SignedString(m.secret)
+}
+
+// ValidateScopedToken 指定した用途のトークンを検証しクレームを返す
+func (m *JWTManager) ValidateScopedToken(tokenString, audience string) (*JWTClaims, error) {
+	claims, err := m.parse(tokenString, jwt.WithAudience(audience))
+	if err != nil {
+		return nil, err
+	}
+	return claims, nil
+}
+
+// parse 署名と有効期限を検証しクレームを返す
+func (m *JWTManager) parse(tokenString string, opts ...jwt.ParserOption) (*JWTClaims, error) {
 	token, err := jwt.ParseWithClaims(tokenString, &JWTClaims{}, func(token *jwt.Token) (interface{}, error) {
 		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
 			return nil, errors.New("予期しない署名方式です")
 		}
 		return m.secret, nil
-	})
+	}, opts...)
 
 	if err != nil {
 		return nil, err
@@ -164,3 +164,33 @@ func TestJWTManager_ExpiredToken(t *testing.T) {
 	assert.Error(t, err)
 	assert.Nil(t, claims)
 }
+
+func TestJWTManager_ScopedToken(t *testing.T) {
+	manager := utils.NewJWTManager("test-secret", 24)
+	claims := &utils.JWTClaims{UserID: 1, Email: "test@example.com", Role: "user"}
+
+	token, err := manager.GenerateScopedToken(claims, utils.RealtimeTokenAudience, time.Minute)
+	require.NoError(t, err)
+
+	scoped, err := manager.ValidateScopedToken(token, utils.RealtimeTokenAudience)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(1), scoped.UserID)
+	assert.Equal(t, "user", scoped.Role)
+
+	// 他の用途や通常のAPIの認証には使えない
+	_, err = manager.ValidateScopedToken(token, "other")
+	assert.Error(t, err)
+	_, err = manager.ValidateToken(token)
+	assert.Error(t, err)
+
+	// 通常のトークンは用途を限定したトークンとして使えない
+	normal, err := manager.GenerateToken(1, "test@example.com", "user")
+	require.NoError(t, err)
+	_, err = manager.ValidateScopedToken(normal, utils.RealtimeTokenAudience)
+	assert.Error(t, err)
+
+	expired, err := manager.GenerateScopedToken(claims, utils.RealtimeTokenAudience, -time.Second)
+	require.NoError(t, err)
+	_, err = manager.ValidateScopedToken(expired, utils.RealtimeTokenAudience)
+	assert.Error(t, err)
+}
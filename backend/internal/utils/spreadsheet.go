@@ -0,0 +1,301 @@
+package utils
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/csv"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strconv"
+	"strings"
+
+	"golang.org/x/text/encoding/japanese"
+	"golang.org/x/text/transform"
+)
+
+// スプレッドシート読み込みのエラー
+var (
+	ErrUnsupportedEncoding = errors.New("サポートされていない文字コードです")
+	ErrInvalidXLSX         = errors.New("XLSXファイルの形式が正しくありません")
+	ErrXLSXTooLarge        = errors.New("XLSXファイルの展開後のサイズが大きすぎます")
+	ErrTooManyRows         = errors.New("行数が上限を超えています")
+)
+
+const (
+	// xlsxMaxColumns XLSXの列数の上限（XFD列）
+	xlsxMaxColumns = 16384
+	// xlsxMaxEntrySize XLSX内の1ファイルの展開後のサイズの上限
+	xlsxMaxEntrySize = 64 << 20
+	// xlsxMaxCells 読み込むセル数（空セルの補完を含む）の上限
+	xlsxMaxCells = 4 << 20
+)
+
+// utf8BOM UTF-8のバイトオーダーマーク
+var utf8BOM = []byte{0xEF, 0xBB, 0xBF}
+
+// ReadCSV CSVを読み込み、全行を文字列のスライスとして返す
+// encoding には "utf-8"（既定）または "shift_jis" を指定する
+func ReadCSV(r io.Reader, encoding string) ([][]string, error) {
+	switch strings.ToLower(strings.ReplaceAll(encoding, "-", "_")) {
+	case "", "utf8", "utf_8":
+		data, err := io.ReadAll(r)
+		if err != nil {
+			return nil, err
+		}
+		r = bytes.NewReader(bytes.TrimPrefix(data, utf8BOM))
+	case "shift_jis", "sjis", "cp932":
+		r = transform.NewReader(r, japanese.ShiftJIS.NewDecoder())
+	default:
+		return nil, ErrUnsupportedEncoding
+	}
+
+	reader := csv.NewReader(r)
+	// 行ごとに列数が異なるファイルも受け付ける
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+	return reader.ReadAll()
+}
+
+// xlsxWorkbook xl/workbook.xml の必要な部分
+type xlsxWorkbook struct {
+	Sheets []struct {
+		RID string `xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships id,attr"`
+	} `xml:"sheets>sheet"`
+}
+
+// xlsxRelationships xl/_rels/workbook.xml.rels の必要な部分
+type xlsxRelationships struct {
+	Relationships []struct {
+		ID     string `xml:"Id,attr"`
+		Target string `xml:"Target,attr"`
+	} `xml:"Relationship"`
+}
+
+// xlsxSharedStrings xl/sharedStrings.xml の必要な部分
+type xlsxSharedStrings struct {
+	Items []xlsxRichText `xml:"si"`
+}
+
+// xlsxRichText 共有文字列・インライン文字列の本文（書式付きの場合は複数のrunに分かれる）
+type xlsxRichText struct {
+	Text string `xml:"t"`
+	Runs []struct {
+		Text string `xml:"t"`
+	} `xml:"r"`
+}
+
+func (t xlsxRichText) String() string {
+	if len(t.Runs) == 0 {
+		return t.Text
+	}
+	var sb strings.Builder
+	sb.WriteString(t.Text)
+	for _, run := range t.Runs {
+		sb.WriteString(run.Text)
+	}
+	return sb.String()
+}
+
+// xlsxRow xl/worksheets/sheetN.xml の行の必要な部分
+type xlsxRow struct {
+	Cells []struct {
+		Ref       string       `xml:"r,attr"`
+		Type      string       `xml:"t,attr"`
+		Value     string       `xml:"v"`
+		InlineStr xlsxRichText `xml:"is"`
+	} `xml:"c"`
+}
+
+// ReadXLSX XLSXファイルの最初のシートを読み込み、全行を文字列のスライスとして返す
+// 数値セルは表示形式を適用しない生の値（日付はシリアル値）を返す。
+// maxRows（0の場合は無制限）を超える行がある場合は、その時点で読み込みをやめて ErrTooManyRows を返す
+func ReadXLSX(r io.ReaderAt, size int64, maxRows int) ([][]string, error) {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, ErrInvalidXLSX
+	}
+	files := make(map[string]*zip.File, len(zr.File))
+	for _, f := range zr.File {
+		files[f.Name] = f
+	}
+
+	sheetPath, err := firstSheetPath(files)
+	if err != nil {
+		return nil, err
+	}
+
+	var shared xlsxSharedStrings
+	if f, ok := files["xl/sharedStrings.xml"]; ok {
+		if err := decodeZipXML(f, &shared); err != nil {
+			return nil, err
+		}
+	}
+
+	sheetFile, ok := files[sheetPath]
+	if !ok {
+		return nil, ErrInvalidXLSX
+	}
+	return readSheetRows(sheetFile, shared.Items, maxRows)
+}
+
+// readSheetRows シートの行を1行ずつデコードし、文字列のスライスとして返す
+func readSheetRows(f *zip.File, shared []xlsxRichText, maxRows int) ([][]string, error) {
+	rc, err := openZipFile(f)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rc.Close() }()
+
+	var rows [][]string
+	cells := 0
+	dec := xml.NewDecoder(rc)
+	for {
+		tok, err := dec.Token()
+		if errors.Is(err, io.EOF) {
+			return rows, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidXLSX, err)
+		}
+		start, ok := tok.(xml.StartElement)
+		if !ok || start.Name.Local != "row" {
+			continue
+		}
+		if maxRows > 0 && len(rows) >= maxRows {
+			return nil, ErrTooManyRows
+		}
+
+		var row xlsxRow
+		if err := dec.DecodeElement(&row, &start); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidXLSX, err)
+		}
+		values, err := rowValues(&row, shared, xlsxMaxCells-cells)
+		if err != nil {
+			return nil, err
+		}
+		cells += len(values)
+		rows = append(rows, values)
+	}
+}
+
+// rowValues 行のセルの値を列位置に合わせて並べる
+// 空セルを補完したセル数が budget を超える場合は ErrXLSXTooLarge を返す
+func rowValues(row *xlsxRow, shared []xlsxRichText, budget int) ([]string, error) {
+	var values []string
+	for i, cell := range row.Cells {
+		// 空セルは省略されるため、セル参照から列位置を求める
+		col := i
+		if cell.Ref != "" {
+			c, ok := columnIndex(cell.Ref)
+			if !ok {
+				return nil, ErrInvalidXLSX
+			}
+			col = c
+		}
+		if col >= budget {
+			return nil, ErrXLSXTooLarge
+		}
+		for len(values) <= col {
+			values = append(values, "")
+		}
+
+		switch cell.Type {
+		case "s":
+			idx, err := strconv.Atoi(cell.Value)
+			if err != nil || idx < 0 || idx >= len(shared) {
+				return nil, ErrInvalidXLSX
+			}
+			values[col] = shared[idx].String()
+		case "inlineStr":
+			values[col] = cell.InlineStr.String()
+		default:
+			values[col] = cell.Value
+		}
+	}
+	return values, nil
+}
+
+// firstSheetPath ワークブックの最初のシートのファイルパスを返す
+func firstSheetPath(files map[string]*zip.File) (string, error) {
+	const fallback = "xl/worksheets/sheet1.xml"
+
+	wbFile, ok := files["xl/workbook.xml"]
+	if !ok {
+		return "", ErrInvalidXLSX
+	}
+	var wb xlsxWorkbook
+	if err := decodeZipXML(wbFile, &wb); err != nil {
+		return "", err
+	}
+	relsFile, ok := files["xl/_rels/workbook.xml.rels"]
+	if !ok || len(wb.Sheets) == 0 {
+		return fallback, nil
+	}
+	var rels xlsxRelationships
+	if err := decodeZipXML(relsFile, &rels); err != nil {
+		return "", err
+	}
+	for _, rel := range rels.Relationships {
+		if rel.ID != wb.Sheets[0].RID {
+			continue
+		}
+		if strings.HasPrefix(rel.Target, "/") {
+			return strings.TrimPrefix(rel.Target, "/"), nil
+		}
+		return path.Join("xl", rel.Target), nil
+	}
+	return fallback, nil
+}
+
+// decodeZipXML ZIP内のXMLファイルをデコードする
+func decodeZipXML(f *zip.File, v interface{}) error {
+	rc, err := openZipFile(f)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = rc.Close() }()
+	if err := xml.NewDecoder(rc).Decode(v); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidXLSX, err)
+	}
+	return nil
+}
+
+// openZipFile ZIP内のファイルを展開後のサイズの上限まで読めるように開く
+// ヘッダーのサイズが上限を超える場合は開かずにエラーを返し、ヘッダーを偽った場合も上限で読み込みを打ち切る
+func openZipFile(f *zip.File) (io.ReadCloser, error) {
+	if f.UncompressedSize64 > xlsxMaxEntrySize {
+		return nil, ErrXLSXTooLarge
+	}
+	rc, err := f.Open()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidXLSX, err)
+	}
+	return struct {
+		io.Reader
+		io.Closer
+	}{io.LimitReader(rc, xlsxMaxEntrySize), rc}, nil
+}
+
+// columnIndex "B3" のようなセル参照から0始まりの列番号を求める
+// 列がない場合やXFD列を超える場合はfalseを返す
+func columnIndex(ref string) (int, bool) {
+	col := 0
+	n := 0
+	for _, c := range ref {
+		if c < 'A' || c > 'Z' {
+			break
+		}
+		col = col*26 + int(c-'A'+1)
+		if col > xlsxMaxColumns {
+			return 0, false
+		}
+		n++
+	}
+	if n == 0 {
+		return 0, false
+	}
+	return col - 1, true
+}
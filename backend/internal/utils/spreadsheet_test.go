@@ -0,0 +1,170 @@
+package utils_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/text/encoding/japanese"
+	"golang.org/x/text/transform"
+
+	"nocode-app/backend/internal/utils"
+)
+
+func TestReadCSV(t *testing.T) {
+	t.Run("utf-8 with BOM", func(t *testing.T) {
+		input := "\xEF\xBB\xBFname,amount\n山田,100\n"
+		rows, err := utils.ReadCSV(strings.NewReader(input), "")
+		require.NoError(t, err)
+		assert.Equal(t, [][]string{{"name", "amount"}, {"山田", "100"}}, rows)
+	})
+
+	t.Run("shift_jis", func(t *testing.T) {
+		encoded, _, err := transform.String(japanese.ShiftJIS.NewEncoder(), "氏名,金額\n山田,100\n")
+		require.NoError(t, err)
+
+		rows, err := utils.ReadCSV(strings.NewReader(encoded), "Shift_JIS")
+		require.NoError(t, err)
+		assert.Equal(t, [][]string{{"氏名", "金額"}, {"山田", "100"}}, rows)
+	})
+
+	t.Run("rows with different column counts", func(t *testing.T) {
+		rows, err := utils.ReadCSV(strings.NewReader("a,b,c\n1\n"), "utf-8")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"1"}, rows[1])
+	})
+
+	t.Run("unsupported encoding", func(t *testing.T) {
+		_, err := utils.ReadCSV(strings.NewReader("a"), "euc-kr")
+		assert.ErrorIs(t, err, utils.ErrUnsupportedEncoding)
+	})
+}
+
+// buildXLSX テスト用の最小構成のXLSXファイルを作成する
+func buildXLSX(t *testing.T, files map[string]string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for name, content := range files {
+		w, err := zw.Create(name)
+		require.NoError(t, err)
+		_, err = w.Write([]byte(content))
+		require.NoError(t, err)
+	}
+	require.NoError(t, zw.Close())
+	return buf.Bytes()
+}
+
+func TestReadXLSX(t *testing.T) {
+	files := map[string]string{
+		"xl/workbook.xml": `<?xml version="1.0" encoding="UTF-8"?>
+<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">
+  <sheets><sheet name="Data" sheetId="1" r:id="rId1"/></sheets>
+</workbook>`,
+		"xl/_rels/workbook.xml.rels": `<?xml version="1.0" encoding="UTF-8"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+  <Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/data.xml"/>
+</Relationships>`,
+		"xl/sharedStrings.xml": `<?xml version="1.0" encoding="UTF-8"?>
+<sst xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">
+  <si><t>name</t></si>
+  <si><t>date</t></si>
+  <si><r><t>山</t></r><r><t>田</t></r></si>
+</sst>`,
+		"xl/worksheets/data.xml": `<?xml version="1.0" encoding="UTF-8"?>
+<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">
+  <sheetData>
+    <row r="1"><c r="A1" t="s"><v>0</v></c><c r="B1" t="s"><v>1</v></c><c r="C1" t="inlineStr"><is><t>note</t></is></c></row>
+    <row r="2"><c r="A2" t="s"><v>2</v></c><c r="C2"><v>45306</v></c></row>
+  </sheetData>
+</worksheet>`,
+	}
+	data := buildXLSX(t, files)
+
+	rows, err := utils.ReadXLSX(bytes.NewReader(data), int64(len(data)), 0)
+	require.NoError(t, err)
+	require.Len(t, rows, 2)
+	assert.Equal(t, []string{"name", "date", "note"}, rows[0])
+	// 空セルは省略されていても列位置が保たれる
+	assert.Equal(t, []string{"山田", "", "45306"}, rows[1])
+}
+
+func TestReadXLSX_Invalid(t *testing.T) {
+	_, err := utils.ReadXLSX(strings.NewReader("not a zip"), 9, 0)
+	assert.ErrorIs(t, err, utils.ErrInvalidXLSX)
+
+	data := buildXLSX(t, map[string]string{"hello.txt": "x"})
+	_, err = utils.ReadXLSX(bytes.NewReader(data), int64(len(data)), 0)
+	assert.ErrorIs(t, err, utils.ErrInvalidXLSX)
+}
+
+// sheetXLSX sheetDataの内容だけを指定したXLSXファイルを作成する
+func sheetXLSX(t *testing.T, sheetData string) []byte {
+	t.Helper()
+	return buildXLSX(t, map[string]string{
+		"xl/workbook.xml": `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheets><sheet name="Data" sheetId="1"/></sheets></workbook>`,
+		"xl/worksheets/sheet1.xml": `<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>` +
+			sheetData + `</sheetData></worksheet>`,
+	})
+}
+
+func TestReadXLSX_Limits(t *testing.T) {
+	t.Run("column beyond XFD is rejected", func(t *testing.T) {
+		for _, ref := range []string{"XFE1", "AAAAAAA1", "ZZZZZZZZZZZZZZ1"} {
+			data := sheetXLSX(t, `<row r="1"><c r="`+ref+`"><v>1</v></c></row>`)
+			_, err := utils.ReadXLSX(bytes.NewReader(data), int64(len(data)), 0)
+			assert.ErrorIs(t, err, utils.ErrInvalidXLSX, ref)
+		}
+	})
+
+	t.Run("last column XFD is accepted", func(t *testing.T) {
+		data := sheetXLSX(t, `<row r="1"><c r="XFD1"><v>1</v></c></row>`)
+		rows, err := utils.ReadXLSX(bytes.NewReader(data), int64(len(data)), 0)
+		require.NoError(t, err)
+		require.Len(t, rows, 1)
+		assert.Len(t, rows[0], 16384)
+		assert.Equal(t, "1", rows[0][16383])
+	})
+
+	t.Run("too many padded cells", func(t *testing.T) {
+		row := `<row><c r="XFD1"><v>1</v></c></row>`
+		data := sheetXLSX(t, strings.Repeat(row, 300))
+		_, err := utils.ReadXLSX(bytes.NewReader(data), int64(len(data)), 0)
+		assert.ErrorIs(t, err, utils.ErrXLSXTooLarge)
+	})
+
+	t.Run("stops reading after max rows", func(t *testing.T) {
+		row := `<row><c t="inlineStr"><is><t>x</t></is></c></row>`
+		data := sheetXLSX(t, strings.Repeat(row, 3))
+
+		rows, err := utils.ReadXLSX(bytes.NewReader(data), int64(len(data)), 3)
+		require.NoError(t, err)
+		assert.Len(t, rows, 3)
+
+		_, err = utils.ReadXLSX(bytes.NewReader(data), int64(len(data)), 2)
+		assert.ErrorIs(t, err, utils.ErrTooManyRows)
+	})
+
+	t.Run("zip bomb is rejected", func(t *testing.T) {
+		// 展開すると64MBを超えるが、圧縮後はアップロードの上限（10MB）を大きく下回る
+		var buf bytes.Buffer
+		zw := zip.NewWriter(&buf)
+		w, err := zw.Create("xl/workbook.xml")
+		require.NoError(t, err)
+		_, err = w.Write([]byte(`<workbook><sheets><sheet/></sheets></workbook>`))
+		require.NoError(t, err)
+		w, err = zw.Create("xl/worksheets/sheet1.xml")
+		require.NoError(t, err)
+		_, err = w.Write(bytes.Repeat([]byte(" "), 65<<20))
+		require.NoError(t, err)
+		require.NoError(t, zw.Close())
+		data := buf.Bytes()
+		require.Less(t, len(data), 10<<20)
+
+		_, err = utils.ReadXLSX(bytes.NewReader(data), int64(len(data)), 0)
+		assert.ErrorIs(t, err, utils.ErrXLSXTooLarge)
+	})
+}
@@ -0,0 +1,269 @@
+package utils
+
+import (
+	"archive/zip"
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// TableWriter 見出し行とデータ行を順に書き出すライター
+// WriteHeader を最初に1回呼び出し、最後に必ず Close を呼び出す
+type TableWriter interface {
+	WriteHeader(headers []string) error
+	WriteRow(values []interface{}) error
+	Close() error
+}
+
+// FormatCellValue セルの値を文字列に変換する
+// 配列（複数選択）はカンマ区切りにする。インポート時の解釈と対になっている
+func FormatCellValue(v interface{}) string {
+	switch val := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return val
+	case bool:
+		return strconv.FormatBool(val)
+	case float64:
+		return strconv.FormatFloat(val, 'f', -1, 64)
+	case float32:
+		return strconv.FormatFloat(float64(val), 'f', -1, 32)
+	case []interface{}:
+		items := make([]string, len(val))
+		for i, item := range val {
+			items[i] = FormatCellValue(item)
+		}
+		return strings.Join(items, ", ")
+	case map[string]interface{}:
+		b, err := json.Marshal(val)
+		if err != nil {
+			return fmt.Sprint(val)
+		}
+		return string(b)
+	default:
+		return fmt.Sprint(val)
+	}
+}
+
+// csvTableWriter CSV形式のTableWriter
+type csvTableWriter struct {
+	w *csv.Writer
+}
+
+// NewCSVTableWriter CSV形式で書き出すTableWriterを作成する
+// Excelで文字化けしないよう先頭にUTF-8のBOMを付ける
+func NewCSVTableWriter(w io.Writer) TableWriter {
+	return &csvTableWriter{w: csv.NewWriter(&bomWriter{w: w})}
+}
+
+func (c *csvTableWriter) WriteHeader(headers []string) error {
+	return c.w.Write(headers)
+}
+
+func (c *csvTableWriter) WriteRow(values []interface{}) error {
+	record := make([]string, len(values))
+	for i, v := range values {
+		record[i] = FormatCellValue(v)
+	}
+	return c.w.Write(record)
+}
+
+func (c *csvTableWriter) Close() error {
+	c.w.Flush()
+	return c.w.Error()
+}
+
+// bomWriter 最初の書き込みの前にUTF-8のBOMを書き出すライター
+type bomWriter struct {
+	w       io.Writer
+	written bool
+}
+
+func (b *bomWriter) Write(p []byte) (int, error) {
+	if !b.written {
+		b.written = true
+		if _, err := b.w.Write(utf8BOM); err != nil {
+			return 0, err
+		}
+	}
+	return b.w.Write(p)
+}
+
+// ndjsonTableWriter 1行1オブジェクトのJSON（NDJSON）形式のTableWriter
+type ndjsonTableWriter struct {
+	w    *bufio.Writer
+	keys [][]byte
+}
+
+// NewNDJSONTableWriter NDJSON形式で書き出すTableWriterを作成する
+// 各行は見出しをキーとするJSONオブジェクトになり、キーは見出しの順に並ぶ
+func NewNDJSONTableWriter(w io.Writer) TableWriter {
+	return &ndjsonTableWriter{w: bufio.NewWriter(w)}
+}
+
+func (n *ndjsonTableWriter) WriteHeader(headers []string) error {
+	n.keys = make([][]byte, len(headers))
+	for i, h := range headers {
+		key, err := json.Marshal(h)
+		if err != nil {
+			return err
+		}
+		n.keys[i] = key
+	}
+	return nil
+}
+
+func (n *ndjsonTableWriter) WriteRow(values []interface{}) error {
+	if err := n.w.WriteByte('{'); err != nil {
+		return err
+	}
+	for i, key := range n.keys {
+		var v interface{}
+		if i < len(values) {
+			v = values[i]
+		}
+		value, err := json.Marshal(v)
+		if err != nil {
+			return err
+		}
+		if i > 0 {
+			_ = n.w.WriteByte(',')
+		}
+		_, _ = n.w.Write(key)
+		_ = n.w.WriteByte(':')
+		if _, err := n.w.Write(value); err != nil {
+			return err
+		}
+	}
+	_, err := n.w.WriteString("}\n")
+	return err
+}
+
+func (n *ndjsonTableWriter) Close() error {
+	return n.w.Flush()
+}
+
+// xlsxTableWriter XLSX形式のTableWriter
+// シートのXMLを行ごとにZIPへ書き出し、その他の構成ファイルはCloseで書き出す
+type xlsxTableWriter struct {
+	zw    *zip.Writer
+	sheet *bufio.Writer
+	row   int
+}
+
+// XLSXの構成ファイル（シートは1枚のみ）
+const (
+	xlsxContentTypes = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types"><Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/><Default Extension="xml" ContentType="application/xml"/><Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/><Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/></Types>`
+	xlsxRootRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships"><Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/></Relationships>`
+	xlsxWorkbookXML = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"><sheets><sheet name="Sheet1" sheetId="1" r:id="rId1"/></sheets></workbook>`
+	xlsxWorkbookRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships"><Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/></Relationships>`
+	xlsxSheetHeader = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`
+	xlsxSheetFooter = `</sheetData></worksheet>`
+)
+
+// NewXLSXTableWriter XLSX形式で書き出すTableWriterを作成する
+// 数値は数値セル、それ以外はインライン文字列のセルとして書き出す
+func NewXLSXTableWriter(w io.Writer) (TableWriter, error) {
+	zw := zip.NewWriter(w)
+	sw, err := zw.Create("xl/worksheets/sheet1.xml")
+	if err != nil {
+		return nil, err
+	}
+	sheet := bufio.NewWriter(sw)
+	if _, err := sheet.WriteString(xlsxSheetHeader); err != nil {
+		return nil, err
+	}
+	return &xlsxTableWriter{zw: zw, sheet: sheet}, nil
+}
+
+func (x *xlsxTableWriter) WriteHeader(headers []string) error {
+	values := make([]interface{}, len(headers))
+	for i, h := range headers {
+		values[i] = h
+	}
+	return x.WriteRow(values)
+}
+
+func (x *xlsxTableWriter) WriteRow(values []interface{}) error {
+	x.row++
+	fmt.Fprintf(x.sheet, `<row r="%d">`, x.row)
+	for i, v := range values {
+		ref := columnName(i) + strconv.Itoa(x.row)
+		if number, ok := xlsxNumber(v); ok {
+			fmt.Fprintf(x.sheet, `<c r="%s"><v>%s</v></c>`, ref, number)
+			continue
+		}
+		text := FormatCellValue(v)
+		if text == "" {
+			continue
+		}
+		fmt.Fprintf(x.sheet, `<c r="%s" t="inlineStr"><is><t xml:space="preserve">`, ref)
+		if err := xml.EscapeText(x.sheet, []byte(text)); err != nil {
+			return err
+		}
+		_, _ = x.sheet.WriteString(`</t></is></c>`)
+	}
+	_, err := x.sheet.WriteString(`</row>`)
+	return err
+}
+
+func (x *xlsxTableWriter) Close() error {
+	if _, err := x.sheet.WriteString(xlsxSheetFooter); err != nil {
+		return err
+	}
+	if err := x.sheet.Flush(); err != nil {
+		return err
+	}
+
+	parts := []struct {
+		name    string
+		content string
+	}{
+		{"[Content_Types].xml", xlsxContentTypes},
+		{"_rels/.rels", xlsxRootRels},
+		{"xl/workbook.xml", xlsxWorkbookXML},
+		{"xl/_rels/workbook.xml.rels", xlsxWorkbookRels},
+	}
+	for _, part := range parts {
+		fw, err := x.zw.Create(part.name)
+		if err != nil {
+			return err
+		}
+		if _, err := io.WriteString(fw, part.content); err != nil {
+			return err
+		}
+	}
+	return x.zw.Close()
+}
+
+// xlsxNumber 数値型の値を数値セル用の文字列に変換する
+func xlsxNumber(v interface{}) (string, bool) {
+	switch val := v.(type) {
+	case float64, float32:
+		return FormatCellValue(val), true
+	case int, int32, int64, uint, uint32, uint64:
+		return fmt.Sprint(val), true
+	default:
+		return "", false
+	}
+}
+
+// columnName 0始まりの列番号を "A", "B", ..., "AA" のような列名に変換する（columnIndexの逆変換）
+func columnName(index int) string {
+	name := ""
+	for n := index + 1; n > 0; n = (n - 1) / 26 {
+		name = string(rune('A'+(n-1)%26)) + name
+	}
+	return name
+}
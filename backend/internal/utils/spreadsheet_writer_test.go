@@ -0,0 +1,83 @@
+package utils_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"nocode-app/backend/internal/utils"
+)
+
+func TestFormatCellValue(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    interface{}
+		expected string
+	}{
+		{name: "nil", value: nil, expected: ""},
+		{name: "string", value: "abc", expected: "abc"},
+		{name: "float", value: float64(1.5), expected: "1.5"},
+		{name: "integral float", value: float64(100), expected: "100"},
+		{name: "bool", value: true, expected: "true"},
+		{name: "int64", value: int64(42), expected: "42"},
+		{name: "array", value: []interface{}{"a", "b"}, expected: "a, b"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, utils.FormatCellValue(tt.value))
+		})
+	}
+}
+
+func TestCSVTableWriter(t *testing.T) {
+	var buf bytes.Buffer
+	w := utils.NewCSVTableWriter(&buf)
+	require.NoError(t, w.WriteHeader([]string{"氏名", "タグ"}))
+	require.NoError(t, w.WriteRow([]interface{}{"山田", []interface{}{"a", "b"}}))
+	require.NoError(t, w.Close())
+
+	assert.True(t, bytes.HasPrefix(buf.Bytes(), []byte{0xEF, 0xBB, 0xBF}))
+
+	// 書き出したCSVはインポートでそのまま読み込める
+	rows, err := utils.ReadCSV(&buf, "utf-8")
+	require.NoError(t, err)
+	assert.Equal(t, [][]string{{"氏名", "タグ"}, {"山田", "a, b"}}, rows)
+}
+
+func TestNDJSONTableWriter(t *testing.T) {
+	var buf bytes.Buffer
+	w := utils.NewNDJSONTableWriter(&buf)
+	require.NoError(t, w.WriteHeader([]string{"氏名", "金額", "タグ"}))
+	require.NoError(t, w.WriteRow([]interface{}{"山田", float64(100), []interface{}{"a"}}))
+	require.NoError(t, w.WriteRow([]interface{}{"佐藤", nil, nil}))
+	require.NoError(t, w.Close())
+
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	require.Len(t, lines, 2)
+	// キーは見出しの順に並ぶ
+	assert.Equal(t, `{"氏名":"山田","金額":100,"タグ":["a"]}`, lines[0])
+	assert.Equal(t, `{"氏名":"佐藤","金額":null,"タグ":null}`, lines[1])
+}
+
+func TestXLSXTableWriter(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := utils.NewXLSXTableWriter(&buf)
+	require.NoError(t, err)
+	require.NoError(t, w.WriteHeader([]string{"氏名", "金額", "備考"}))
+	require.NoError(t, w.WriteRow([]interface{}{"<山田> & Co.", float64(1234.5), nil}))
+	require.NoError(t, w.WriteRow([]interface{}{nil, int64(7), "memo"}))
+	require.NoError(t, w.Close())
+
+	// 書き出したXLSXはインポートでそのまま読み込める
+	rows, err := utils.ReadXLSX(bytes.NewReader(buf.Bytes()), int64(buf.Len()), 0)
+	require.NoError(t, err)
+	assert.Equal(t, [][]string{
+		{"氏名", "金額", "備考"},
+		{"<山田> & Co.", "1234.5"},
+		{"", "7", "memo"},
+	}, rows)
+}
@@ -0,0 +1,83 @@
+package utils
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"gopkg.in/yaml.v3"
+)
+
+// DecodeYAML YAMLをJSONと同じ規則でdestにデコードする
+// 一度JSONの値（数値はfloat64、日付・日時は書かれたままの文字列）に変換してからデコードするため、
+// json タグと map[string]interface{} の値の型はJSONのリクエストと同じになる
+func DecodeYAML(data []byte, dest interface{}) error {
+	var node yaml.Node
+	if err := yaml.Unmarshal(data, &node); err != nil {
+		return err
+	}
+	value, err := yamlNodeValue(&node)
+	if err != nil {
+		return err
+	}
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(raw, dest)
+}
+
+// yamlNodeValue YAMLのノードをJSONの値に変換する
+func yamlNodeValue(node *yaml.Node) (interface{}, error) {
+	switch node.Kind {
+	case yaml.DocumentNode:
+		if len(node.Content) == 0 {
+			return nil, nil
+		}
+		return yamlNodeValue(node.Content[0])
+	case yaml.AliasNode:
+		return yamlNodeValue(node.Alias)
+	case yaml.MappingNode:
+		m := make(map[string]interface{}, len(node.Content)/2)
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key := node.Content[i]
+			if key.Kind != yaml.ScalarNode {
+				return nil, fmt.Errorf("%d行目: キーは文字列で指定してください", key.Line)
+			}
+			value, err := yamlNodeValue(node.Content[i+1])
+			if err != nil {
+				return nil, err
+			}
+			m[key.Value] = value
+		}
+		return m, nil
+	case yaml.SequenceNode:
+		s := make([]interface{}, len(node.Content))
+		for i, item := range node.Content {
+			value, err := yamlNodeValue(item)
+			if err != nil {
+				return nil, err
+			}
+			s[i] = value
+		}
+		return s, nil
+	default:
+		switch node.ShortTag() {
+		case "!!null":
+			return nil, nil
+		case "!!bool":
+			var b bool
+			err := node.Decode(&b)
+			return b, err
+		case "!!int", "!!float":
+			var f float64
+			if err := node.Decode(&f); err != nil {
+				return nil, fmt.Errorf("%d行目: 数値 %q を変換できません", node.Line, node.Value)
+			}
+			return f, nil
+		case "!!str", "!!timestamp":
+			return node.Value, nil
+		default:
+			return nil, fmt.Errorf("%d行目: %q は使用できません", node.Line, node.ShortTag())
+		}
+	}
+}
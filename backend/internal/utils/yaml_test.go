@@ -0,0 +1,55 @@
+package utils_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"nocode-app/backend/internal/utils"
+)
+
+func TestDecodeYAML(t *testing.T) {
+	t.Run("values have the same types as JSON", func(t *testing.T) {
+		var dest struct {
+			Name    string                 `json:"name"`
+			Options map[string]interface{} `json:"options"`
+		}
+		data := []byte(`
+name: 在庫
+options:
+  app_id: 2
+  ratio: 0.5
+  due: 2026-10-01
+  done: true
+  tags: [a, b]
+  memo: ~
+`)
+		require.NoError(t, utils.DecodeYAML(data, &dest))
+		assert.Equal(t, "在庫", dest.Name)
+		assert.Equal(t, map[string]interface{}{
+			"app_id": float64(2),
+			"ratio":  0.5,
+			"due":    "2026-10-01",
+			"done":   true,
+			"tags":   []interface{}{"a", "b"},
+			"memo":   nil,
+		}, dest.Options)
+	})
+
+	t.Run("anchors are expanded", func(t *testing.T) {
+		var dest map[string]interface{}
+		require.NoError(t, utils.DecodeYAML([]byte("base: &b {x: 1}\ncopy: *b\n"), &dest))
+		assert.Equal(t, map[string]interface{}{"x": float64(1)}, dest["copy"])
+	})
+
+	t.Run("invalid yaml", func(t *testing.T) {
+		var dest map[string]interface{}
+		assert.Error(t, utils.DecodeYAML([]byte("a: [1, 2"), &dest))
+	})
+
+	t.Run("non-string key", func(t *testing.T) {
+		var dest map[string]interface{}
+		assert.Error(t, utils.DecodeYAML([]byte("? [a, b]\n: 1\n"), &dest))
+	})
+}
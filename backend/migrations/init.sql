@@ -1,5 +1,8 @@
 -- ノーコードアプリ 初期スキーマ (PostgreSQL)
 
+-- 全文検索のトライグラム（search_config = 'trigram'）用
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
 -- updated_at 自動更新用の共通トリガ関数
 CREATE OR REPLACE FUNCTION set_updated_at()
 RETURNS TRIGGER AS $$
@@ -59,13 +62,18 @@ CREATE TABLE IF NOT EXISTS apps (
     is_external BOOLEAN NOT NULL DEFAULT FALSE,
     data_source_id BIGINT NULL REFERENCES data_sources(id) ON DELETE SET NULL,
     source_table_name VARCHAR(255) NULL,
+    -- 全文検索用カラムの形式（テキスト検索設定名または trigram）。NULLの場合は全文検索用カラムがない
+    search_config VARCHAR(32) NULL,
     created_by BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
     created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
-    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    -- ごみ箱に移した日時。NULLでない場合はごみ箱にあり、保持期間を過ぎると動的テーブルとともに完全に削除する
+    deleted_at TIMESTAMP NULL
 );
 
 CREATE INDEX IF NOT EXISTS idx_apps_created_by ON apps(created_by);
 CREATE INDEX IF NOT EXISTS idx_apps_data_source_id ON apps(data_source_id);
+CREATE INDEX IF NOT EXISTS idx_apps_deleted_at ON apps(deleted_at) WHERE deleted_at IS NOT NULL;
 
 DROP TRIGGER IF EXISTS trg_apps_updated_at ON apps;
 CREATE TRIGGER trg_apps_updated_at
@@ -85,10 +93,13 @@ CREATE TABLE IF NOT EXISTS app_fields (
     display_order INT NOT NULL DEFAULT 0,
     created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
     updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    -- ごみ箱に移した日時。ごみ箱にあるフィールドもカラムを残し、フィールドコードを使い続ける
+    deleted_at TIMESTAMP NULL,
     CONSTRAINT uk_app_field_code UNIQUE (app_id, field_code)
 );
 
 CREATE INDEX IF NOT EXISTS idx_app_fields_app_id ON app_fields(app_id);
+CREATE INDEX IF NOT EXISTS idx_app_fields_deleted_at ON app_fields(deleted_at) WHERE deleted_at IS NOT NULL;
 
 DROP TRIGGER IF EXISTS trg_app_fields_updated_at ON app_fields;
 CREATE TRIGGER trg_app_fields_updated_at
@@ -160,6 +171,256 @@ CREATE TRIGGER trg_dashboard_widgets_updated_at
     BEFORE UPDATE ON dashboard_widgets
     FOR EACH ROW EXECUTE FUNCTION set_updated_at();
 
+-- ユーザーグループテーブル
+CREATE TABLE IF NOT EXISTS user_groups (
+    id BIGSERIAL PRIMARY KEY,
+    name VARCHAR(100) NOT NULL UNIQUE,
+    description VARCHAR(500) NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+DROP TRIGGER IF EXISTS trg_user_groups_updated_at ON user_groups;
+CREATE TRIGGER trg_user_groups_updated_at
+    BEFORE UPDATE ON user_groups
+    FOR EACH ROW EXECUTE FUNCTION set_updated_at();
+
+-- ユーザーグループ所属テーブル
+CREATE TABLE IF NOT EXISTS user_group_members (
+    group_id BIGINT NOT NULL REFERENCES user_groups(id) ON DELETE CASCADE,
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (group_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_user_group_members_user_id ON user_group_members(user_id);
+
+-- アプリ権限テーブル（ユーザーまたはグループのどちらか一方に付与）
+CREATE TABLE IF NOT EXISTS app_permissions (
+    id BIGSERIAL PRIMARY KEY,
+    app_id BIGINT NOT NULL REFERENCES apps(id) ON DELETE CASCADE,
+    user_id BIGINT REFERENCES users(id) ON DELETE CASCADE,
+    group_id BIGINT REFERENCES user_groups(id) ON DELETE CASCADE,
+    role VARCHAR(20) NOT NULL
+        CHECK (role IN ('viewer', 'editor', 'owner')),
+    own_records_only BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT chk_app_permissions_subject CHECK ((user_id IS NULL) <> (group_id IS NULL))
+);
+
+CREATE INDEX IF NOT EXISTS idx_app_permissions_app_id ON app_permissions(app_id);
+CREATE UNIQUE INDEX IF NOT EXISTS uk_app_permissions_app_user ON app_permissions(app_id, user_id) WHERE user_id IS NOT NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS uk_app_permissions_app_group ON app_permissions(app_id, group_id) WHERE group_id IS NOT NULL;
+
+DROP TRIGGER IF EXISTS trg_app_permissions_updated_at ON app_permissions;
+CREATE TRIGGER trg_app_permissions_updated_at
+    BEFORE UPDATE ON app_permissions
+    FOR EACH ROW EXECUTE FUNCTION set_updated_at();
+
+-- レコード変更履歴テーブル（動的テーブルのレコードごとのリビジョン）
+CREATE TABLE IF NOT EXISTS record_revisions (
+    id BIGSERIAL PRIMARY KEY,
+    app_id BIGINT NOT NULL REFERENCES apps(id) ON DELETE CASCADE,
+    record_id BIGINT NOT NULL,
+    action VARCHAR(10) NOT NULL
+        CHECK (action IN ('create', 'update', 'delete', 'revert', 'restore')),
+    changes JSONB NOT NULL DEFAULT '{}',
+    snapshot JSONB NOT NULL DEFAULT '{}',
+    changed_by BIGINT REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_record_revisions_app_record ON record_revisions(app_id, record_id, id);
+
+-- Webhookテーブル（アプリのイベントを外部に通知するエンドポイント）
+CREATE TABLE IF NOT EXISTS webhooks (
+    id BIGSERIAL PRIMARY KEY,
+    app_id BIGINT NOT NULL REFERENCES apps(id) ON DELETE CASCADE,
+    url VARCHAR(2000) NOT NULL,
+    secret VARCHAR(255) NOT NULL,
+    events JSONB NOT NULL DEFAULT '[]',
+    is_active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_by BIGINT REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_webhooks_app_id ON webhooks(app_id);
+
+DROP TRIGGER IF EXISTS trg_webhooks_updated_at ON webhooks;
+CREATE TRIGGER trg_webhooks_updated_at
+    BEFORE UPDATE ON webhooks
+    FOR EACH ROW EXECUTE FUNCTION set_updated_at();
+
+-- Webhook配信キュー兼配信ログテーブル
+-- アプリ削除のイベントを配信できるよう、送信先と署名を保持しWebhook削除後も残す
+CREATE TABLE IF NOT EXISTS webhook_deliveries (
+    id BIGSERIAL PRIMARY KEY,
+    webhook_id BIGINT REFERENCES webhooks(id) ON DELETE SET NULL,
+    app_id BIGINT NOT NULL,
+    event VARCHAR(50) NOT NULL,
+    url VARCHAR(2000) NOT NULL,
+    payload TEXT NOT NULL,
+    signature VARCHAR(100) NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending'
+        CHECK (status IN ('pending', 'succeeded', 'failed')),
+    attempts INTEGER NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    last_status_code INTEGER,
+    last_error TEXT NOT NULL DEFAULT '',
+    delivered_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_webhook_id ON webhook_deliveries(webhook_id, id);
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_pending ON webhook_deliveries(next_attempt_at) WHERE status = 'pending';
+
+-- 自動化ルールテーブル（契機 → 条件 → アクション）
+CREATE TABLE IF NOT EXISTS automation_rules (
+    id BIGSERIAL PRIMARY KEY,
+    app_id BIGINT NOT NULL REFERENCES apps(id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    trigger_type VARCHAR(30) NOT NULL
+        CHECK (trigger_type IN ('record_created', 'record_updated', 'field_changed', 'schedule', 'date_reached')),
+    trigger_field VARCHAR(64) NOT NULL DEFAULT '',
+    schedule VARCHAR(100) NOT NULL DEFAULT '',
+    offset_days INTEGER NOT NULL DEFAULT 0,
+    timezone VARCHAR(64) NOT NULL DEFAULT '',
+    conditions JSONB NOT NULL DEFAULT '[]',
+    actions JSONB NOT NULL DEFAULT '[]',
+    is_active BOOLEAN NOT NULL DEFAULT TRUE,
+    next_run_at TIMESTAMP,
+    last_run_at TIMESTAMP,
+    created_by BIGINT REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_automation_rules_app_id ON automation_rules(app_id);
+CREATE INDEX IF NOT EXISTS idx_automation_rules_next_run_at ON automation_rules(next_run_at) WHERE next_run_at IS NOT NULL;
+
+DROP TRIGGER IF EXISTS trg_automation_rules_updated_at ON automation_rules;
+CREATE TRIGGER trg_automation_rules_updated_at
+    BEFORE UPDATE ON automation_rules
+    FOR EACH ROW EXECUTE FUNCTION set_updated_at();
+
+-- 自動化ルールの実行ログテーブル
+CREATE TABLE IF NOT EXISTS automation_runs (
+    id BIGSERIAL PRIMARY KEY,
+    rule_id BIGINT NOT NULL REFERENCES automation_rules(id) ON DELETE CASCADE,
+    app_id BIGINT NOT NULL,
+    record_id BIGINT NOT NULL,
+    status VARCHAR(20) NOT NULL
+        CHECK (status IN ('succeeded', 'failed', 'skipped')),
+    error TEXT NOT NULL DEFAULT '',
+    depth INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_automation_runs_rule_id ON automation_runs(rule_id, id);
+
+-- 添付ファイルテーブル（添付ファイルフィールドにアップロードされたファイル）
+-- record_id が NULL のファイルはレコードに添付される前のアップロード済みファイル。
+-- orphaned_at が設定されたファイルはレコードやフィールドの削除で不要になり、ストレージからの削除を待っている
+CREATE TABLE IF NOT EXISTS attachments (
+    id BIGSERIAL PRIMARY KEY,
+    app_id BIGINT NOT NULL,
+    field_code VARCHAR(64) NOT NULL,
+    record_id BIGINT,
+    storage_key VARCHAR(255) NOT NULL UNIQUE,
+    file_name VARCHAR(255) NOT NULL,
+    content_type VARCHAR(255) NOT NULL,
+    size BIGINT NOT NULL,
+    uploaded_by BIGINT REFERENCES users(id) ON DELETE SET NULL,
+    orphaned_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_attachments_app_record ON attachments(app_id, record_id);
+CREATE INDEX IF NOT EXISTS idx_attachments_orphaned_at ON attachments(orphaned_at) WHERE orphaned_at IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_attachments_pending ON attachments(created_at) WHERE record_id IS NULL;
+
+-- 動的テーブルのインデックスの定義テーブル
+-- index_name のインデックスを動的テーブルに作成する。フィールドの削除でカラムとともに消えたインデックスの定義は一覧の取得時に削除する
+CREATE TABLE IF NOT EXISTS app_indexes (
+    id BIGSERIAL PRIMARY KEY,
+    app_id BIGINT NOT NULL REFERENCES apps(id) ON DELETE CASCADE,
+    index_name VARCHAR(63) NOT NULL UNIQUE,
+    field_codes JSONB NOT NULL,
+    is_unique BOOLEAN NOT NULL DEFAULT FALSE,
+    filter JSONB,
+    created_by BIGINT REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_app_indexes_app_id ON app_indexes(app_id);
+
+-- ごみ箱のレコードテーブル
+-- 削除したレコードの全カラムを data に保存して動的テーブルから削除し、復元時に同じIDで挿入し直す
+CREATE TABLE IF NOT EXISTS deleted_records (
+    id BIGSERIAL PRIMARY KEY,
+    app_id BIGINT NOT NULL REFERENCES apps(id) ON DELETE CASCADE,
+    record_id BIGINT NOT NULL,
+    data JSONB NOT NULL,
+    created_by BIGINT NOT NULL,
+    deleted_by BIGINT REFERENCES users(id) ON DELETE SET NULL,
+    deleted_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT uk_deleted_records_app_record UNIQUE (app_id, record_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_deleted_records_deleted_at ON deleted_records(deleted_at);
+
+-- 保存したレポートテーブル
+-- グラフ・ピボット集計・レコードの一覧の設定を config に保存し、frequency を指定したレポートを next_run_at に recipients へメールで配信する
+CREATE TABLE IF NOT EXISTS saved_reports (
+    id BIGSERIAL PRIMARY KEY,
+    app_id BIGINT NOT NULL REFERENCES apps(id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    report_type VARCHAR(20) NOT NULL
+        CHECK (report_type IN ('chart', 'pivot', 'records')),
+    config JSONB NOT NULL DEFAULT '{}',
+    frequency VARCHAR(20) NOT NULL DEFAULT ''
+        CHECK (frequency IN ('', 'daily', 'weekly', 'monthly')),
+    send_hour INTEGER NOT NULL DEFAULT 0 CHECK (send_hour BETWEEN 0 AND 23),
+    weekday INTEGER NOT NULL DEFAULT 0 CHECK (weekday BETWEEN 0 AND 6),
+    day_of_month INTEGER NOT NULL DEFAULT 1 CHECK (day_of_month BETWEEN 1 AND 28),
+    timezone VARCHAR(64) NOT NULL DEFAULT 'UTC',
+    recipients JSONB NOT NULL DEFAULT '[]',
+    is_active BOOLEAN NOT NULL DEFAULT TRUE,
+    next_run_at TIMESTAMP,
+    last_run_at TIMESTAMP,
+    created_by BIGINT REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_saved_reports_app_id ON saved_reports(app_id);
+CREATE INDEX IF NOT EXISTS idx_saved_reports_next_run_at ON saved_reports(next_run_at) WHERE next_run_at IS NOT NULL;
+
+DROP TRIGGER IF EXISTS trg_saved_reports_updated_at ON saved_reports;
+CREATE TRIGGER trg_saved_reports_updated_at
+    BEFORE UPDATE ON saved_reports
+    FOR EACH ROW EXECUTE FUNCTION set_updated_at();
+
+-- レポートの配信履歴テーブル
+CREATE TABLE IF NOT EXISTS report_deliveries (
+    id BIGSERIAL PRIMARY KEY,
+    report_id BIGINT NOT NULL REFERENCES saved_reports(id) ON DELETE CASCADE,
+    app_id BIGINT NOT NULL,
+    trigger_type VARCHAR(20) NOT NULL
+        CHECK (trigger_type IN ('schedule', 'manual')),
+    status VARCHAR(20) NOT NULL
+        CHECK (status IN ('succeeded', 'failed')),
+    recipients JSONB NOT NULL DEFAULT '[]',
+    subject VARCHAR(255) NOT NULL DEFAULT '',
+    row_count INTEGER NOT NULL DEFAULT 0,
+    error TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_report_deliveries_report_id ON report_deliveries(report_id, id);
+
 -- デフォルト管理者ユーザーを挿入（パスワード: admin123）
 INSERT INTO users (email, password_hash, name, role) VALUES
 ('admin@example.com', '$2a$10$e8i3egbnenpqzZlow/3Q0.5L6uN8vNyktEYkgRdWwP13xSkCtR1re', 'Admin', 'admin')
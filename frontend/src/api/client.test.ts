@@ -0,0 +1,54 @@
+/**
+ * APIクライアントのバージョン指定・競合判定のテスト
+ */
+
+import { AxiosError, AxiosHeaders } from "axios";
+import { describe, expect, it } from "vitest";
+import { getVersionConflict, ifMatch } from "./client";
+
+const axiosError = (status: number, data: unknown) =>
+  new AxiosError("Request failed", undefined, undefined, undefined, {
+    status,
+    statusText: "",
+    data,
+    headers: {},
+    config: { headers: new AxiosHeaders() },
+  });
+
+describe("ifMatch", () => {
+  it("should quote the version", () => {
+    expect(ifMatch(1792141200123456)).toEqual({
+      "If-Match": '"1792141200123456"',
+    });
+  });
+});
+
+describe("getVersionConflict", () => {
+  const conflict = {
+    error: "Precondition Failed",
+    message: "他のユーザーによって変更されています",
+    code: 412,
+    version: 2,
+    current: { id: 1, version: 2 },
+  };
+
+  it("should return the current resource on 412", () => {
+    expect(getVersionConflict(axiosError(412, conflict))).toEqual(conflict);
+  });
+
+  it("should return the current resource on 409 with a version", () => {
+    expect(
+      getVersionConflict(axiosError(409, { ...conflict, code: 409 }))?.current
+    ).toEqual({ id: 1, version: 2 });
+  });
+
+  it("should ignore other conflicts", () => {
+    expect(
+      getVersionConflict(
+        axiosError(409, { error: "Conflict", message: "重複", code: 409 })
+      )
+    ).toBeUndefined();
+    expect(getVersionConflict(axiosError(500, conflict))).toBeUndefined();
+    expect(getVersionConflict(new Error("network"))).toBeUndefined();
+  });
+});
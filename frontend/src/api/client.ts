@@ -4,6 +4,8 @@
 
 import axios, { AxiosInstance, InternalAxiosRequestConfig } from "axios";
 
+import type { VersionConflictResponse } from "@/types";
+
 const API_URL = import.meta.env.VITE_API_URL || "/api/v1";
 
 const client: AxiosInstance = axios.create({
@@ -45,4 +47,31 @@ client.interceptors.response.use(
   }
 );
 
+/**
+ * 更新・削除するリソースを取得したときのバージョンを If-Match ヘッダーで指定する
+ * 他のユーザーが取得後に変更していた場合、サーバーは 412 を返し何も変更しない
+ */
+export const ifMatch = (version: number): { "If-Match": string } => ({
+  "If-Match": `"${version}"`,
+});
+
+/**
+ * 更新・削除が他のユーザーの変更と競合した（409・412）場合に、サーバー上の現在の内容を返す
+ * 競合以外のエラーの場合は undefined を返す
+ */
+export const getVersionConflict = <T>(
+  error: unknown
+): VersionConflictResponse<T> | undefined => {
+  if (!axios.isAxiosError(error)) {
+    return undefined;
+  }
+  const status = error.response?.status;
+  const data = error.response?.data as VersionConflictResponse<T> | undefined;
+  // 409 は値の重複などでも返すため、バージョンを含む場合のみ競合とみなす
+  if ((status !== 409 && status !== 412) || !data?.version) {
+    return undefined;
+  }
+  return data;
+};
+
 export default client;
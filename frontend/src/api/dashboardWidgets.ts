@@ -5,7 +5,7 @@ import type {
   ReorderWidgetsRequest,
   UpdateDashboardWidgetRequest,
 } from "@/types";
-import client from "./client";
+import client, { ifMatch } from "./client";
 import type { IDashboardWidgetsApi } from "./interfaces";
 
 /**
@@ -38,18 +38,22 @@ export const dashboardWidgetsApi: IDashboardWidgetsApi = {
   // ダッシュボードウィジェットを更新
   update: async (
     widgetId: number,
-    data: UpdateDashboardWidgetRequest
+    data: UpdateDashboardWidgetRequest,
+    version: number
   ): Promise<DashboardWidget> => {
     const response = await client.put<DashboardWidget>(
       `/dashboard/widgets/${widgetId}`,
-      data
+      data,
+      { headers: ifMatch(version) }
     );
     return response.data;
   },
 
   // ダッシュボードウィジェットを削除
-  delete: async (widgetId: number): Promise<void> => {
-    await client.delete(`/dashboard/widgets/${widgetId}`);
+  delete: async (widgetId: number, version: number): Promise<void> => {
+    await client.delete(`/dashboard/widgets/${widgetId}`, {
+      headers: ifMatch(version),
+    });
   },
 
   // ウィジェットの並び替え
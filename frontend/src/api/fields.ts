@@ -4,7 +4,7 @@ import {
   UpdateFieldOrderRequest,
   UpdateFieldRequest,
 } from "@/types";
-import client from "./client";
+import client, { ifMatch } from "./client";
 
 /**
  * フィールドAPI
@@ -28,18 +28,26 @@ export const fieldsApi = {
   update: async (
     appId: number,
     fieldId: number,
-    data: UpdateFieldRequest
+    data: UpdateFieldRequest,
+    version: number
   ): Promise<Field> => {
     const response = await client.put<Field>(
       `/apps/${appId}/fields/${fieldId}`,
-      data
+      data,
+      { headers: ifMatch(version) }
     );
     return response.data;
   },
 
   // フィールドを削除
-  delete: async (appId: number, fieldId: number): Promise<void> => {
-    await client.delete(`/apps/${appId}/fields/${fieldId}`);
+  delete: async (
+    appId: number,
+    fieldId: number,
+    version: number
+  ): Promise<void> => {
+    await client.delete(`/apps/${appId}/fields/${fieldId}`, {
+      headers: ifMatch(version),
+    });
   },
 
   // フィールドの表示順序を更新
@@ -6,7 +6,7 @@
 export { appsApi } from "./apps";
 export { authApi } from "./auth";
 export { chartsApi } from "./charts";
-export { default as client } from "./client";
+export { default as client, getVersionConflict } from "./client";
 export { dashboardApi } from "./dashboard";
 export { dashboardWidgetsApi } from "./dashboardWidgets";
 export { createDataSourceApi } from "./datasources";
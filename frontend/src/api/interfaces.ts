@@ -82,9 +82,10 @@ export interface IFieldsApi {
   update(
     appId: number,
     fieldId: number,
-    data: UpdateFieldRequest
+    data: UpdateFieldRequest,
+    version: number
   ): Promise<Field>;
-  delete(appId: number, fieldId: number): Promise<void>;
+  delete(appId: number, fieldId: number, version: number): Promise<void>;
   updateOrder(appId: number, data: UpdateFieldOrderRequest): Promise<void>;
 }
 
@@ -101,9 +102,10 @@ export interface IRecordsApi {
   update(
     appId: number,
     recordId: number,
-    data: UpdateRecordRequest
+    data: UpdateRecordRequest,
+    version: number
   ): Promise<RecordItem>;
-  delete(appId: number, recordId: number): Promise<void>;
+  delete(appId: number, recordId: number, version: number): Promise<void>;
   bulkCreate(
     appId: number,
     data: BulkCreateRecordRequest
@@ -120,9 +122,10 @@ export interface IViewsApi {
   update(
     appId: number,
     viewId: number,
-    data: UpdateViewRequest
+    data: UpdateViewRequest,
+    version: number
   ): Promise<AppView>;
-  delete(appId: number, viewId: number): Promise<void>;
+  delete(appId: number, viewId: number, version: number): Promise<void>;
 }
 
 /**
@@ -169,9 +172,10 @@ export interface IDashboardWidgetsApi {
   create(data: CreateDashboardWidgetRequest): Promise<DashboardWidget>;
   update(
     widgetId: number,
-    data: UpdateDashboardWidgetRequest
+    data: UpdateDashboardWidgetRequest,
+    version: number
   ): Promise<DashboardWidget>;
-  delete(widgetId: number): Promise<void>;
+  delete(widgetId: number, version: number): Promise<void>;
   reorder(data: ReorderWidgetsRequest): Promise<{ message: string }>;
   toggleVisibility(widgetId: number): Promise<DashboardWidget>;
 }
@@ -7,7 +7,7 @@ import {
   RecordQueryOptions,
   UpdateRecordRequest,
 } from "@/types";
-import client from "./client";
+import client, { ifMatch } from "./client";
 
 /**
  * レコードAPI
@@ -72,18 +72,26 @@ export const recordsApi = {
   update: async (
     appId: number,
     recordId: number,
-    data: UpdateRecordRequest
+    data: UpdateRecordRequest,
+    version: number
   ): Promise<RecordItem> => {
     const response = await client.put<RecordItem>(
       `/apps/${appId}/records/${recordId}`,
-      data
+      data,
+      { headers: ifMatch(version) }
     );
     return response.data;
   },
 
   // レコードを削除
-  delete: async (appId: number, recordId: number): Promise<void> => {
-    await client.delete(`/apps/${appId}/records/${recordId}`);
+  delete: async (
+    appId: number,
+    recordId: number,
+    version: number
+  ): Promise<void> => {
+    await client.delete(`/apps/${appId}/records/${recordId}`, {
+      headers: ifMatch(version),
+    });
   },
 
   // レコードを一括作成
@@ -1,5 +1,5 @@
 import { AppView, CreateViewRequest, UpdateViewRequest } from "@/types";
-import client from "./client";
+import client, { ifMatch } from "./client";
 
 /**
  * ビューAPI
@@ -23,17 +23,25 @@ export const viewsApi = {
   update: async (
     appId: number,
     viewId: number,
-    data: UpdateViewRequest
+    data: UpdateViewRequest,
+    version: number
   ): Promise<AppView> => {
     const response = await client.put<AppView>(
       `/apps/${appId}/views/${viewId}`,
-      data
+      data,
+      { headers: ifMatch(version) }
     );
     return response.data;
   },
 
   // ビューを削除
-  delete: async (appId: number, viewId: number): Promise<void> => {
-    await client.delete(`/apps/${appId}/views/${viewId}`);
+  delete: async (
+    appId: number,
+    viewId: number,
+    version: number
+  ): Promise<void> => {
+    await client.delete(`/apps/${appId}/views/${viewId}`, {
+      headers: ifMatch(version),
+    });
   },
 };
@@ -40,3 +40,4 @@ export {
   useRecords,
   useUpdateRecord,
 } from "./useRecords";
+export { useVersionConflictToast } from "./useVersionConflictToast";
@@ -2,7 +2,7 @@
  * ダッシュボードウィジェット用フック
  */
 
-import { useDashboardWidgetsApi } from "@/api";
+import { getVersionConflict, useDashboardWidgetsApi } from "@/api";
 import type {
   CreateDashboardWidgetRequest,
   DashboardWidgetListResponse,
@@ -52,13 +52,21 @@ export function useUpdateDashboardWidget() {
     mutationFn: ({
       widgetId,
       data,
+      version,
     }: {
       widgetId: number;
       data: UpdateDashboardWidgetRequest;
-    }) => dashboardWidgetsApi.update(widgetId, data),
+      version: number;
+    }) => dashboardWidgetsApi.update(widgetId, data, version),
     onSuccess: () => {
       queryClient.invalidateQueries({ queryKey: ["dashboard", "widgets"] });
     },
+    onError: (error) => {
+      // 他のユーザーの変更と競合した場合は最新の内容を読み込み直す
+      if (getVersionConflict(error)) {
+        queryClient.invalidateQueries({ queryKey: ["dashboard", "widgets"] });
+      }
+    },
   });
 }
 
@@ -70,10 +78,22 @@ export function useDeleteDashboardWidget() {
   const queryClient = useQueryClient();
 
   return useMutation({
-    mutationFn: (widgetId: number) => dashboardWidgetsApi.delete(widgetId),
+    mutationFn: ({
+      widgetId,
+      version,
+    }: {
+      widgetId: number;
+      version: number;
+    }) => dashboardWidgetsApi.delete(widgetId, version),
     onSuccess: () => {
       queryClient.invalidateQueries({ queryKey: ["dashboard", "widgets"] });
     },
+    onError: (error) => {
+      // 他のユーザーの変更と競合した場合は最新の内容を読み込み直す
+      if (getVersionConflict(error)) {
+        queryClient.invalidateQueries({ queryKey: ["dashboard", "widgets"] });
+      }
+    },
   });
 }
 
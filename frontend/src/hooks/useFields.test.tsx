@@ -21,6 +21,7 @@ vi.mock("@/api", () => ({
     delete: vi.fn(),
     updateOrder: vi.fn(),
   },
+  getVersionConflict: vi.fn(),
 }));
 
 import { fieldsApi } from "@/api";
@@ -36,6 +37,7 @@ const mockField = {
   options: {},
   created_at: "2024-01-01T00:00:00Z",
   updated_at: "2024-01-01T00:00:00Z",
+  version: 1,
 };
 
 const mockFieldsResponse = {
@@ -129,13 +131,17 @@ describe("useFields hooks", () => {
         appId: 1,
         fieldId: 1,
         data: { field_name: "Updated Title" },
+        version: 1,
       });
 
       await waitFor(() => expect(result.current.isSuccess).toBe(true));
 
-      expect(fieldsApi.update).toHaveBeenCalledWith(1, 1, {
-        field_name: "Updated Title",
-      });
+      expect(fieldsApi.update).toHaveBeenCalledWith(
+        1,
+        1,
+        { field_name: "Updated Title" },
+        1
+      );
       const storeField = useAppStore
         .getState()
         .currentFields.find((f) => f.id === 1);
@@ -152,11 +158,11 @@ describe("useFields hooks", () => {
 
       const { result } = renderHook(() => useDeleteField(), { wrapper });
 
-      result.current.mutate({ appId: 1, fieldId: 1 });
+      result.current.mutate({ appId: 1, fieldId: 1, version: 1 });
 
       await waitFor(() => expect(result.current.isSuccess).toBe(true));
 
-      expect(fieldsApi.delete).toHaveBeenCalledWith(1, 1);
+      expect(fieldsApi.delete).toHaveBeenCalledWith(1, 1, 1);
       expect(useAppStore.getState().currentFields).toHaveLength(0);
     });
   });
@@ -2,7 +2,7 @@
  * フィールド操作フック
  */
 
-import { fieldsApi } from "@/api";
+import { fieldsApi, getVersionConflict } from "@/api";
 import { useAppStore } from "@/stores";
 import {
   CreateFieldRequest,
@@ -63,15 +63,23 @@ export function useUpdateField() {
       appId,
       fieldId,
       data,
+      version,
     }: {
       appId: number;
       fieldId: number;
       data: UpdateFieldRequest;
-    }) => fieldsApi.update(appId, fieldId, data),
+      version: number;
+    }) => fieldsApi.update(appId, fieldId, data, version),
     onSuccess: (field, { appId }) => {
       updateField(field);
       queryClient.invalidateQueries({ queryKey: ["fields", appId] });
     },
+    onError: (error, { appId }) => {
+      // 他のユーザーの変更と競合した場合は最新の内容を読み込み直す
+      if (getVersionConflict(error)) {
+        queryClient.invalidateQueries({ queryKey: ["fields", appId] });
+      }
+    },
   });
 }
 
@@ -83,13 +91,26 @@ export function useDeleteField() {
   const { removeField } = useAppStore();
 
   return useMutation({
-    mutationFn: ({ appId, fieldId }: { appId: number; fieldId: number }) =>
-      fieldsApi.delete(appId, fieldId),
+    mutationFn: ({
+      appId,
+      fieldId,
+      version,
+    }: {
+      appId: number;
+      fieldId: number;
+      version: number;
+    }) => fieldsApi.delete(appId, fieldId, version),
     onSuccess: (_, { appId, fieldId }) => {
       removeField(fieldId);
       queryClient.invalidateQueries({ queryKey: ["fields", appId] });
       queryClient.invalidateQueries({ queryKey: ["app", appId] });
     },
+    onError: (error, { appId }) => {
+      // 他のユーザーの変更と競合した場合は最新の内容を読み込み直す
+      if (getVersionConflict(error)) {
+        queryClient.invalidateQueries({ queryKey: ["fields", appId] });
+      }
+    },
   });
 }
 
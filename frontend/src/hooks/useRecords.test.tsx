@@ -23,9 +23,10 @@ vi.mock("@/api", () => ({
     bulkCreate: vi.fn(),
     bulkDelete: vi.fn(),
   },
+  getVersionConflict: vi.fn(),
 }));
 
-import { recordsApi } from "@/api";
+import { getVersionConflict, recordsApi } from "@/api";
 
 const mockRecord = {
   id: 1,
@@ -33,6 +34,7 @@ const mockRecord = {
   created_by: 1,
   created_at: "2024-01-01T00:00:00Z",
   updated_at: "2024-01-01T00:00:00Z",
+  version: 1,
 };
 
 const mockRecordsResponse = {
@@ -154,13 +156,46 @@ describe("useRecords hooks", () => {
         appId: 1,
         recordId: 1,
         data: { data: { title: "Updated" } },
+        version: 1,
       });
 
       await waitFor(() => expect(result.current.isSuccess).toBe(true));
 
-      expect(recordsApi.update).toHaveBeenCalledWith(1, 1, {
-        data: { title: "Updated" },
+      expect(recordsApi.update).toHaveBeenCalledWith(
+        1,
+        1,
+        { data: { title: "Updated" } },
+        1
+      );
+      expect(invalidateSpy).toHaveBeenCalledWith({ queryKey: ["records", 1] });
+      expect(invalidateSpy).toHaveBeenCalledWith({
+        queryKey: ["record", 1, 1],
       });
+    });
+
+    it("should reload records on version conflict", async () => {
+      const conflict = {
+        error: "Precondition Failed",
+        message: "他のユーザーによって変更されています",
+        code: 412,
+        version: 2,
+        current: { ...mockRecord, version: 2 },
+      };
+      vi.mocked(recordsApi.update).mockRejectedValueOnce(new Error("412"));
+      vi.mocked(getVersionConflict).mockReturnValueOnce(conflict);
+      const invalidateSpy = vi.spyOn(queryClient, "invalidateQueries");
+
+      const { result } = renderHook(() => useUpdateRecord(), { wrapper });
+
+      result.current.mutate({
+        appId: 1,
+        recordId: 1,
+        data: { data: { title: "Updated" } },
+        version: 1,
+      });
+
+      await waitFor(() => expect(result.current.isError).toBe(true));
+
       expect(invalidateSpy).toHaveBeenCalledWith({ queryKey: ["records", 1] });
       expect(invalidateSpy).toHaveBeenCalledWith({
         queryKey: ["record", 1, 1],
@@ -175,11 +210,11 @@ describe("useRecords hooks", () => {
 
       const { result } = renderHook(() => useDeleteRecord(), { wrapper });
 
-      result.current.mutate({ appId: 1, recordId: 1 });
+      result.current.mutate({ appId: 1, recordId: 1, version: 1 });
 
       await waitFor(() => expect(result.current.isSuccess).toBe(true));
 
-      expect(recordsApi.delete).toHaveBeenCalledWith(1, 1);
+      expect(recordsApi.delete).toHaveBeenCalledWith(1, 1, 1);
       expect(invalidateSpy).toHaveBeenCalledWith({ queryKey: ["records", 1] });
     });
   });
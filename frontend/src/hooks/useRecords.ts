@@ -2,7 +2,7 @@
  * レコード操作フック
  */
 
-import { recordsApi } from "@/api";
+import { getVersionConflict, recordsApi } from "@/api";
 import {
   BulkCreateRecordRequest,
   BulkDeleteRecordRequest,
@@ -70,15 +70,26 @@ export function useUpdateRecord() {
       appId,
       recordId,
       data,
+      version,
     }: {
       appId: number;
       recordId: number;
       data: UpdateRecordRequest;
-    }) => recordsApi.update(appId, recordId, data),
+      version: number;
+    }) => recordsApi.update(appId, recordId, data, version),
     onSuccess: (_, { appId, recordId }) => {
       queryClient.invalidateQueries({ queryKey: ["records", appId] });
       queryClient.invalidateQueries({ queryKey: ["record", appId, recordId] });
     },
+    onError: (error, { appId, recordId }) => {
+      // 他のユーザーの変更と競合した場合は最新の内容を読み込み直す
+      if (getVersionConflict(error)) {
+        queryClient.invalidateQueries({ queryKey: ["records", appId] });
+        queryClient.invalidateQueries({
+          queryKey: ["record", appId, recordId],
+        });
+      }
+    },
   });
 }
 
@@ -89,11 +100,24 @@ export function useDeleteRecord() {
   const queryClient = useQueryClient();
 
   return useMutation({
-    mutationFn: ({ appId, recordId }: { appId: number; recordId: number }) =>
-      recordsApi.delete(appId, recordId),
+    mutationFn: ({
+      appId,
+      recordId,
+      version,
+    }: {
+      appId: number;
+      recordId: number;
+      version: number;
+    }) => recordsApi.delete(appId, recordId, version),
     onSuccess: (_, { appId }) => {
       queryClient.invalidateQueries({ queryKey: ["records", appId] });
     },
+    onError: (error, { appId }) => {
+      // 他のユーザーの変更と競合した場合は最新の内容を読み込み直す
+      if (getVersionConflict(error)) {
+        queryClient.invalidateQueries({ queryKey: ["records", appId] });
+      }
+    },
   });
 }
 
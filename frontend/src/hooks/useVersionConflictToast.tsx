@@ -0,0 +1,55 @@
+/**
+ * 更新・削除の競合の通知フック
+ */
+
+import { Button, Text, VStack, useToast } from "@chakra-ui/react";
+import { useCallback } from "react";
+
+const VERSION_CONFLICT_TOAST_ID = "version-conflict";
+
+/**
+ * 他のユーザーの変更と競合したことを通知するフック
+ * 最新の内容は呼び出し元で読み込み直し、retry を指定した場合は最新のバージョンでやり直すボタンを表示する
+ */
+export function useVersionConflictToast() {
+  const toast = useToast();
+
+  return useCallback(
+    (retry?: () => void) => {
+      if (toast.isActive(VERSION_CONFLICT_TOAST_ID)) {
+        toast.close(VERSION_CONFLICT_TOAST_ID);
+      }
+      toast({
+        id: VERSION_CONFLICT_TOAST_ID,
+        title: "他のユーザーによって変更されています",
+        description: (
+          <VStack align="start" spacing={2}>
+            <Text>
+              {retry
+                ? "最新の内容を読み込みました。内容を確認してやり直してください"
+                : "最新の内容を読み込みました"}
+            </Text>
+            {retry && (
+              <Button
+                size="sm"
+                colorScheme="orange"
+                variant="outline"
+                bg="white"
+                onClick={() => {
+                  toast.close(VERSION_CONFLICT_TOAST_ID);
+                  retry();
+                }}
+              >
+                やり直す
+              </Button>
+            )}
+          </VStack>
+        ),
+        status: "warning",
+        duration: retry ? null : 5000,
+        isClosable: true,
+      });
+    },
+    [toast]
+  );
+}
@@ -45,6 +45,7 @@ const mockApps = [
         options: {},
         created_at: "2024-01-01T00:00:00Z",
         updated_at: "2024-01-01T00:00:00Z",
+        version: 1,
       },
     ],
     field_count: 1,
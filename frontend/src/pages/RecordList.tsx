@@ -1,3 +1,4 @@
+import { getVersionConflict } from "@/api";
 import { ChartBuilder, ChartRenderer } from "@/components/charts";
 import { Loading } from "@/components/common";
 import {
@@ -17,6 +18,7 @@ import {
   useFields,
   useRecords,
   useUpdateRecord,
+  useVersionConflictToast,
 } from "@/hooks";
 import { RecordData, RecordItem, RecordQueryOptions, ViewType } from "@/types";
 import { ChartDataRequest } from "@/types/chart";
@@ -89,6 +91,7 @@ export function RecordListPage() {
 
   const cancelRef = useRef<HTMLButtonElement>(null);
   const toast = useToast();
+  const notifyVersionConflict = useVersionConflictToast();
 
   const { data: app, isLoading: isAppLoading } = useApp(numericAppId);
   const { data: fieldsData, isLoading: isFieldsLoading } =
@@ -172,13 +175,14 @@ export function RecordListPage() {
     onFormOpen();
   }, [onFormOpen]);
 
-  const handleFormSubmit = async (data: RecordData) => {
+  const saveRecord = async (data: RecordData, record: RecordItem | null) => {
     try {
-      if (editingRecord) {
+      if (record) {
         await updateRecord.mutateAsync({
           appId: numericAppId,
-          recordId: editingRecord.id,
+          recordId: record.id,
           data: { data },
+          version: record.version,
         });
         toast({
           title: "レコードを更新しました",
@@ -198,21 +202,38 @@ export function RecordListPage() {
       }
       onFormClose();
       setEditingRecord(null);
-    } catch {
+    } catch (error) {
+      const conflict = getVersionConflict<RecordItem>(error);
+      if (record && conflict) {
+        // フォームに最新の内容を読み込み、入力した内容で上書きし直せるようにする
+        const current = conflict.current;
+        if (current) {
+          setEditingRecord(current);
+          notifyVersionConflict(() => saveRecord(data, current));
+        } else {
+          onFormClose();
+          setEditingRecord(null);
+          notifyVersionConflict();
+        }
+        return;
+      }
       toast({
-        title: editingRecord ? "更新に失敗しました" : "作成に失敗しました",
+        title: record ? "更新に失敗しました" : "作成に失敗しました",
         status: "error",
         duration: 5000,
       });
     }
   };
 
-  const handleConfirmDelete = async () => {
-    if (!selectedRecord) return;
+  const handleFormSubmit = (data: RecordData) =>
+    saveRecord(data, editingRecord);
+
+  const removeRecord = async (record: RecordItem) => {
     try {
       await deleteRecord.mutateAsync({
         appId: numericAppId,
-        recordId: selectedRecord.id,
+        recordId: record.id,
+        version: record.version,
       });
       toast({
         title: "レコードを削除しました",
@@ -221,7 +242,18 @@ export function RecordListPage() {
       });
       onDeleteClose();
       setSelectedRecord(null);
-    } catch {
+    } catch (error) {
+      const conflict = getVersionConflict<RecordItem>(error);
+      if (conflict) {
+        // 最新の内容を確認してから削除し直せるようにする
+        onDeleteClose();
+        const current = conflict.current;
+        setSelectedRecord(current ?? null);
+        notifyVersionConflict(
+          current ? () => removeRecord(current) : undefined
+        );
+        return;
+      }
       toast({
         title: "削除に失敗しました",
         status: "error",
@@ -230,6 +262,12 @@ export function RecordListPage() {
     }
   };
 
+  const handleConfirmDelete = () => {
+    if (selectedRecord) {
+      removeRecord(selectedRecord);
+    }
+  };
+
   const handleBulkDelete = async () => {
     try {
       await bulkDeleteRecords.mutateAsync({
@@ -92,6 +92,7 @@ describe("SettingsPage", () => {
             options: {},
             created_at: "2024-01-01T00:00:00Z",
             updated_at: "2024-01-01T00:00:00Z",
+            version: 1,
           },
         ],
       }),
@@ -1,6 +1,14 @@
-import { useApiClient, useDashboardWidgetsApi } from "@/api";
+import {
+  getVersionConflict,
+  useApiClient,
+  useDashboardWidgetsApi,
+} from "@/api";
 import { DataSourceList } from "@/components/datasources";
-import { useChartConfigs, useDashboardWidgets } from "@/hooks";
+import {
+  useChartConfigs,
+  useDashboardWidgets,
+  useVersionConflictToast,
+} from "@/hooks";
 import { useAuthStore } from "@/stores";
 import type {
   App,
@@ -10,6 +18,7 @@ import type {
   CreateDashboardWidgetRequest,
   CreateFieldRequest,
   CreateUserRequest,
+  DashboardWidget,
   Field,
   FieldOptions,
   FieldType,
@@ -1019,6 +1028,7 @@ function AppSettingsDetail({ app, onBack }: AppSettingsDetailProps) {
   const dashboardWidgetsApi = useDashboardWidgetsApi();
   const queryClient = useQueryClient();
   const toast = useToast();
+  const notifyVersionConflict = useVersionConflictToast();
   const cancelRef = useRef<HTMLButtonElement>(null);
 
   // Form state for basic info
@@ -1186,10 +1196,12 @@ function AppSettingsDetail({ app, onBack }: AppSettingsDetailProps) {
     mutationFn: ({
       id,
       data,
+      version,
     }: {
       id: number;
       data: UpdateDashboardWidgetRequest;
-    }) => dashboardWidgetsApi.update(id, data),
+      version: number;
+    }) => dashboardWidgetsApi.update(id, data, version),
     onSuccess: () => {
       queryClient.invalidateQueries({ queryKey: ["dashboard", "widgets"] });
       toast({
@@ -1198,7 +1210,22 @@ function AppSettingsDetail({ app, onBack }: AppSettingsDetailProps) {
         duration: 3000,
       });
     },
-    onError: () => {
+    onError: (error, variables) => {
+      const conflict = getVersionConflict<DashboardWidget>(error);
+      if (conflict) {
+        queryClient.invalidateQueries({ queryKey: ["dashboard", "widgets"] });
+        const current = conflict.current;
+        notifyVersionConflict(
+          current
+            ? () =>
+                updateWidgetMutation.mutate({
+                  ...variables,
+                  version: current.version,
+                })
+            : undefined
+        );
+        return;
+      }
       toast({
         title: "ダッシュボード設定の保存に失敗しました",
         status: "error",
@@ -1209,7 +1236,8 @@ function AppSettingsDetail({ app, onBack }: AppSettingsDetailProps) {
 
   // ダッシュボードウィジェット削除
   const deleteWidgetMutation = useMutation({
-    mutationFn: (id: number) => dashboardWidgetsApi.delete(id),
+    mutationFn: (widget: DashboardWidget) =>
+      dashboardWidgetsApi.delete(widget.id, widget.version),
     onSuccess: () => {
       queryClient.invalidateQueries({ queryKey: ["dashboard", "widgets"] });
       toast({
@@ -1218,7 +1246,16 @@ function AppSettingsDetail({ app, onBack }: AppSettingsDetailProps) {
         duration: 3000,
       });
     },
-    onError: () => {
+    onError: (error) => {
+      const conflict = getVersionConflict<DashboardWidget>(error);
+      if (conflict) {
+        queryClient.invalidateQueries({ queryKey: ["dashboard", "widgets"] });
+        const current = conflict.current;
+        notifyVersionConflict(
+          current ? () => deleteWidgetMutation.mutate(current) : undefined
+        );
+        return;
+      }
       toast({
         title: "削除に失敗しました",
         status: "error",
@@ -1229,7 +1266,8 @@ function AppSettingsDetail({ app, onBack }: AppSettingsDetailProps) {
 
   // Delete field mutation
   const deleteFieldMutation = useMutation({
-    mutationFn: (fieldId: number) => fields.delete(app.id, fieldId),
+    mutationFn: (field: Field) =>
+      fields.delete(app.id, field.id, field.version),
     onSuccess: () => {
       queryClient.invalidateQueries({ queryKey: ["fields", app.id] });
       toast({
@@ -1239,7 +1277,17 @@ function AppSettingsDetail({ app, onBack }: AppSettingsDetailProps) {
       });
       onDeleteFieldClose();
     },
-    onError: () => {
+    onError: (error) => {
+      const conflict = getVersionConflict<Field>(error);
+      if (conflict) {
+        queryClient.invalidateQueries({ queryKey: ["fields", app.id] });
+        onDeleteFieldClose();
+        const current = conflict.current;
+        notifyVersionConflict(
+          current ? () => deleteFieldMutation.mutate(current) : undefined
+        );
+        return;
+      }
       toast({
         title: "フィールドの削除に失敗しました",
         status: "error",
@@ -1272,7 +1320,7 @@ function AppSettingsDetail({ app, onBack }: AppSettingsDetailProps) {
 
   const confirmDeleteField = () => {
     if (selectedField) {
-      deleteFieldMutation.mutate(selectedField.id);
+      deleteFieldMutation.mutate(selectedField);
     }
   };
 
@@ -1293,6 +1341,7 @@ function AppSettingsDetail({ app, onBack }: AppSettingsDetailProps) {
         // 既存のウィジェットを更新
         updateWidgetMutation.mutate({
           id: existingWidget.id,
+          version: existingWidget.version,
           data: {
             view_type: viewType,
             widget_size: widgetSize,
@@ -1315,6 +1364,7 @@ function AppSettingsDetail({ app, onBack }: AppSettingsDetailProps) {
         // ウィジェットを非表示に
         updateWidgetMutation.mutate({
           id: existingWidget.id,
+          version: existingWidget.version,
           data: { is_visible: false },
         });
       }
@@ -2147,6 +2197,7 @@ function EditFieldModal({
   const { fields } = useApiClient();
   const queryClient = useQueryClient();
   const toast = useToast();
+  const notifyVersionConflict = useVersionConflictToast();
   const [formData, setFormData] = useState({
     field_name: field.field_name,
     required: field.required,
@@ -2157,8 +2208,13 @@ function EditFieldModal({
   const [error, setError] = useState("");
 
   const updateMutation = useMutation({
-    mutationFn: (data: UpdateFieldRequest) =>
-      fields.update(appId, field.id, data),
+    mutationFn: ({
+      data,
+      version,
+    }: {
+      data: UpdateFieldRequest;
+      version: number;
+    }) => fields.update(appId, field.id, data, version),
     onSuccess: () => {
       queryClient.invalidateQueries({ queryKey: ["fields", appId] });
       toast({
@@ -2168,7 +2224,19 @@ function EditFieldModal({
       });
       onClose();
     },
-    onError: () => {
+    onError: (error, { data }) => {
+      const conflict = getVersionConflict<Field>(error);
+      if (conflict) {
+        // 入力した内容は残し、最新のバージョンで保存し直せるようにする
+        queryClient.invalidateQueries({ queryKey: ["fields", appId] });
+        const current = conflict.current;
+        notifyVersionConflict(
+          current
+            ? () => updateMutation.mutate({ data, version: current.version })
+            : undefined
+        );
+        return;
+      }
       toast({
         title: "フィールドの更新に失敗しました",
         status: "error",
@@ -2199,9 +2267,12 @@ function EditFieldModal({
     }
 
     updateMutation.mutate({
-      field_name: formData.field_name.trim(),
-      required: formData.required,
-      options: Object.keys(options).length > 0 ? options : undefined,
+      data: {
+        field_name: formData.field_name.trim(),
+        required: formData.required,
+        options: Object.keys(options).length > 0 ? options : undefined,
+      },
+      version: field.version,
     });
   };
 
@@ -29,6 +29,7 @@ describe("appStore", () => {
       options: {},
       created_at: "2024-01-01T00:00:00Z",
       updated_at: "2024-01-01T00:00:00Z",
+      version: 1,
     },
     {
       id: 2,
@@ -41,6 +42,7 @@ describe("appStore", () => {
       options: {},
       created_at: "2024-01-01T00:00:00Z",
       updated_at: "2024-01-01T00:00:00Z",
+      version: 1,
     },
   ];
 
@@ -94,6 +96,7 @@ describe("appStore", () => {
           options: {},
           created_at: "2024-01-01T00:00:00Z",
           updated_at: "2024-01-01T00:00:00Z",
+          version: 1,
         },
       ];
 
@@ -125,6 +128,7 @@ describe("appStore", () => {
           options: {},
           created_at: "2024-01-01T00:00:00Z",
           updated_at: "2024-01-01T00:00:00Z",
+          version: 1,
         },
         {
           id: 21,
@@ -137,6 +141,7 @@ describe("appStore", () => {
           options: {},
           created_at: "2024-01-01T00:00:00Z",
           updated_at: "2024-01-01T00:00:00Z",
+          version: 1,
         },
       ];
 
@@ -170,6 +175,7 @@ describe("appStore", () => {
         options: { items: ["Active", "Inactive"] },
         created_at: "2024-01-01T00:00:00Z",
         updated_at: "2024-01-01T00:00:00Z",
+        version: 1,
       };
 
       useAppStore.getState().addField(newField);
@@ -36,6 +36,7 @@ export const mockField = {
   options: {},
   created_at: "2024-01-01T00:00:00Z",
   updated_at: "2024-01-01T00:00:00Z",
+  version: 1,
 };
 
 export const mockRecord = {
@@ -44,6 +45,7 @@ export const mockRecord = {
   created_by: 1,
   created_at: "2024-01-01T00:00:00Z",
   updated_at: "2024-01-01T00:00:00Z",
+  version: 1,
 };
 
 export const mockView = {
@@ -57,6 +59,7 @@ export const mockView = {
   visible_fields: null,
   created_at: "2024-01-01T00:00:00Z",
   updated_at: "2024-01-01T00:00:00Z",
+  version: 1,
 };
 
 export const handlers = [
@@ -14,6 +14,18 @@ export interface Pagination {
   total_pages: number;
 }
 
+/**
+ * 更新・削除が他のユーザーの変更と競合した場合のエラーレスポンス（409・412）
+ * current はサーバー上の現在の内容（削除済みの場合は含まれない）
+ */
+export interface VersionConflictResponse<T> {
+  error: string;
+  message: string;
+  code: number;
+  version: number;
+  current?: T;
+}
+
 /**
  * アプリケーション
  */
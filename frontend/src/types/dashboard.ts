@@ -52,6 +52,7 @@ export interface DashboardWidget {
   config?: WidgetConfig;
   created_at: string;
   updated_at: string;
+  version: number;
   app?: App;
 }
 
@@ -42,6 +42,7 @@ export interface Field {
   display_order: number;
   created_at: string;
   updated_at: string;
+  version: number;
 }
 
 /**
@@ -18,6 +18,7 @@ export interface RecordItem {
   created_by: number;
   created_at: string;
   updated_at: string;
+  version: number;
 }
 
 /**
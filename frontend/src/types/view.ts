@@ -36,6 +36,7 @@ export interface AppView {
   is_default: boolean;
   created_at: string;
   updated_at: string;
+  version: number;
 }
 
 /**